@@ -84,7 +84,8 @@ type aggregatorUnit struct {
 }
 
 // outputUnit is a group of Outputs and their source channel.  Metrics on the
-// channel are written to all outputs.
+// channel are written to all outputs, except for outputs targeted by a routing
+// output which only receive the metrics routed to them.
 
 //                            ┌────────┐
 //                       ┌──▶ │ Output │
@@ -99,6 +100,7 @@ type aggregatorUnit struct {
 type outputUnit struct {
 	src     <-chan telegraf.Metric
 	outputs []*models.RunningOutput
+	routing *outputRouting
 }
 
 // Run starts and runs the Agent until the context is done.
@@ -300,14 +302,15 @@ func (a *Agent) initPersister() error {
 	}
 
 	for _, aggregator := range a.Config.Aggregators {
-		plugin, ok := aggregator.Aggregator.(telegraf.StatefulPlugin)
-		if !ok {
+		// Register the model to persist the aggregation period along with
+		// the plugin state
+		if !aggregator.Stateful() {
 			continue
 		}
 
 		name := aggregator.LogName()
 		id := aggregator.ID()
-		if err := a.Config.Persister.Register(id, plugin); err != nil {
+		if err := a.Config.Persister.Register(id, aggregator); err != nil {
 			return fmt.Errorf("could not register aggregator %s: %w", name, err)
 		}
 	}
@@ -690,11 +693,21 @@ func (a *Agent) runAggregators(
 ) {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	interval := time.Duration(a.Config.Agent.Interval)
+	precision := time.Duration(a.Config.Agent.Precision)
+
 	// Before calling Add, initialize the aggregation window.  This ensures
-	// that any metric created after start time will be aggregated.
+	// that any metric created after start time will be aggregated.  Restored
+	// states of an elapsed window are pushed before any new metric is added.
+	accumulators := make(map[*models.RunningAggregator]telegraf.Accumulator, len(a.Config.Aggregators))
 	for _, agg := range a.Config.Aggregators {
 		since, until := updateWindow(startTime, a.Config.Agent.RoundInterval, agg.Period())
 		agg.UpdateWindow(since, until)
+
+		acc := NewAccumulator(agg, unit.aggC)
+		acc.SetPrecision(getPrecision(precision, interval))
+		agg.PushRestored(acc)
+		accumulators[agg] = acc
 	}
 
 	var wg sync.WaitGroup
@@ -722,13 +735,7 @@ func (a *Agent) runAggregators(
 		wg.Add(1)
 		go func(agg *models.RunningAggregator) {
 			defer wg.Done()
-
-			interval := time.Duration(a.Config.Agent.Interval)
-			precision := time.Duration(a.Config.Agent.Precision)
-
-			acc := NewAccumulator(agg, unit.aggC)
-			acc.SetPrecision(getPrecision(precision, interval))
-			a.push(ctx, agg, acc)
+			a.push(ctx, agg, accumulators[agg])
 		}(agg)
 	}
 
@@ -756,8 +763,12 @@ func updateWindow(start time.Time, roundInterval bool, period time.Duration) (si
 	return since, until
 }
 
-// push runs the push for a single aggregator every period.
-func (*Agent) push(ctx context.Context, aggregator *models.RunningAggregator, acc telegraf.Accumulator) {
+// push runs the push for a single aggregator every period.  On shutdown, the
+// incomplete period of stateful aggregators is kept for persisting the state
+// if a statefile is configured.
+func (a *Agent) push(ctx context.Context, aggregator *models.RunningAggregator, acc telegraf.Accumulator) {
+	persist := a.Config.Persister != nil && aggregator.Stateful()
+
 	for {
 		// Ensures that Push will be called for each period, even if it has
 		// already elapsed before this function is called.  This is guaranteed
@@ -769,7 +780,9 @@ func (*Agent) push(ctx context.Context, aggregator *models.RunningAggregator, ac
 		case <-time.After(until):
 			aggregator.Push(acc)
 		case <-ctx.Done():
-			aggregator.Push(acc)
+			if !persist {
+				aggregator.PushAll(acc)
+			}
 			return
 		}
 	}
@@ -802,6 +815,13 @@ func (a *Agent) startOutputs(
 		unit.outputs = append(unit.outputs, output)
 	}
 
+	routing, err := newOutputRouting(unit.outputs)
+	if err != nil {
+		stopRunningOutputs(unit.outputs)
+		return nil, nil, err
+	}
+	unit.routing = routing
+
 	return src, unit, nil
 }
 
@@ -862,8 +882,13 @@ func (a *Agent) runOutputs(
 	}
 
 	for metric := range unit.src {
-		for i, output := range unit.outputs {
-			if i == len(unit.outputs)-1 {
+		outputs := unit.routing.destinations(metric)
+		if len(outputs) == 0 {
+			metric.Drop()
+			continue
+		}
+		for i, output := range outputs {
+			if i == len(outputs)-1 {
 				output.AddMetricNoCopy(metric)
 			} else {
 				output.AddMetric(metric)
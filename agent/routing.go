@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+)
+
+// outputRouting determines the outputs receiving a metric. Outputs targeted
+// by a routing output only receive the metrics routed to them while all
+// other outputs receive every metric.
+type outputRouting struct {
+	direct  []*models.RunningOutput
+	routers []*models.RunningOutput
+	targets map[string]*models.RunningOutput
+}
+
+func newOutputRouting(outputs []*models.RunningOutput) (*outputRouting, error) {
+	aliases := make(map[string][]*models.RunningOutput)
+	for _, output := range outputs {
+		if output.Config.Alias != "" {
+			aliases[output.Config.Alias] = append(aliases[output.Config.Alias], output)
+		}
+	}
+
+	r := &outputRouting{targets: make(map[string]*models.RunningOutput)}
+	for _, output := range outputs {
+		router, ok := output.Output.(telegraf.RoutingOutput)
+		if !ok {
+			continue
+		}
+		r.routers = append(r.routers, output)
+
+		for _, alias := range router.Targets() {
+			candidates := aliases[alias]
+			switch len(candidates) {
+			case 0:
+				return nil, fmt.Errorf("output %s routes to unknown output %q", output.LogName(), alias)
+			case 1:
+			default:
+				return nil, fmt.Errorf("output %s routes to ambiguous output %q", output.LogName(), alias)
+			}
+			if _, ok := candidates[0].Output.(telegraf.RoutingOutput); ok {
+				return nil, fmt.Errorf("output %s cannot route to routing output %q", output.LogName(), alias)
+			}
+			r.targets[alias] = candidates[0]
+		}
+	}
+
+	for _, output := range outputs {
+		if _, ok := output.Output.(telegraf.RoutingOutput); ok {
+			continue
+		}
+		if output.Config.Alias != "" && r.targets[output.Config.Alias] != nil {
+			continue
+		}
+		r.direct = append(r.direct, output)
+	}
+
+	return r, nil
+}
+
+// destinations returns the outputs the given metric should be added to
+func (r *outputRouting) destinations(metric telegraf.Metric) []*models.RunningOutput {
+	if len(r.routers) == 0 {
+		return r.direct
+	}
+
+	outputs := slices.Clone(r.direct)
+	for _, router := range r.routers {
+		for _, alias := range router.Route(metric) {
+			output, found := r.targets[alias]
+			if !found || slices.Contains(outputs, output) {
+				continue
+			}
+			outputs = append(outputs, output)
+		}
+	}
+	return outputs
+}
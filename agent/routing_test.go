@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+)
+
+type mockOutput struct{}
+
+func (*mockOutput) SampleConfig() string          { return "" }
+func (*mockOutput) Connect() error                { return nil }
+func (*mockOutput) Close() error                  { return nil }
+func (*mockOutput) Write([]telegraf.Metric) error { return nil }
+
+type mockRouter struct {
+	mockOutput
+	targets []string
+}
+
+func (r *mockRouter) Targets() []string {
+	return r.targets
+}
+
+func (*mockRouter) Route(m telegraf.Metric) []string {
+	return []string{m.Name()}
+}
+
+func newOutput(output telegraf.Output, alias string) *models.RunningOutput {
+	return models.NewRunningOutput(output, &models.OutputConfig{Name: "mock", Alias: alias}, 1000, 10000)
+}
+
+func TestOutputRouting(t *testing.T) {
+	all := newOutput(&mockOutput{}, "")
+	cpu := newOutput(&mockOutput{}, "cpu")
+	mem := newOutput(&mockOutput{}, "mem")
+	other := newOutput(&mockOutput{}, "other")
+	router := newOutput(&mockRouter{targets: []string{"cpu", "mem"}}, "")
+
+	routing, err := newOutputRouting([]*models.RunningOutput{all, cpu, mem, other, router})
+	require.NoError(t, err)
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.Equal(t, []*models.RunningOutput{all, other, cpu}, routing.destinations(m))
+
+	// Metrics routed to unknown targets are not sent anywhere else
+	m = metric.New("disk", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.Equal(t, []*models.RunningOutput{all, other}, routing.destinations(m))
+}
+
+func TestOutputRoutingFilters(t *testing.T) {
+	all := newOutput(&mockOutput{}, "")
+	cpu := newOutput(&mockOutput{}, "cpu")
+	mem := newOutput(&mockOutput{}, "mem")
+	router := models.NewRunningOutput(
+		&mockRouter{targets: []string{"cpu", "mem"}},
+		&models.OutputConfig{
+			Name:   "mock",
+			Filter: models.Filter{NamePass: []string{"cpu"}, FieldExclude: []string{"value"}},
+		},
+		1000,
+		10000,
+	)
+	require.NoError(t, router.Config.Filter.Compile())
+
+	routing, err := newOutputRouting([]*models.RunningOutput{all, cpu, mem, router})
+	require.NoError(t, err)
+
+	// Metrics not passing the router's filters are not routed
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42, "other": 1}, time.Unix(0, 0))
+	require.Equal(t, []*models.RunningOutput{all, cpu}, routing.destinations(m))
+	require.Len(t, m.FieldList(), 2)
+
+	m = metric.New("mem", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.Equal(t, []*models.RunningOutput{all}, routing.destinations(m))
+
+	// Metrics without fields after filtering are not routed
+	m = metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.Equal(t, []*models.RunningOutput{all}, routing.destinations(m))
+}
+
+func TestOutputRoutingWithoutRouter(t *testing.T) {
+	outputs := []*models.RunningOutput{newOutput(&mockOutput{}, ""), newOutput(&mockOutput{}, "a")}
+	routing, err := newOutputRouting(outputs)
+	require.NoError(t, err)
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.Equal(t, outputs, routing.destinations(m))
+}
+
+func TestOutputRoutingInvalidTargets(t *testing.T) {
+	tests := []struct {
+		name     string
+		outputs  []*models.RunningOutput
+		expected string
+	}{
+		{
+			name: "unknown",
+			outputs: []*models.RunningOutput{
+				newOutput(&mockRouter{targets: []string{"missing"}}, ""),
+			},
+			expected: `routes to unknown output "missing"`,
+		},
+		{
+			name: "ambiguous",
+			outputs: []*models.RunningOutput{
+				newOutput(&mockOutput{}, "a"),
+				newOutput(&mockOutput{}, "a"),
+				newOutput(&mockRouter{targets: []string{"a"}}, ""),
+			},
+			expected: `routes to ambiguous output "a"`,
+		},
+		{
+			name: "router",
+			outputs: []*models.RunningOutput{
+				newOutput(&mockRouter{}, "a"),
+				newOutput(&mockRouter{targets: []string{"a"}}, ""),
+			},
+			expected: `cannot route to routing output "a"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newOutputRouting(tt.outputs)
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
@@ -2,17 +2,126 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/awnumar/memguard"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/term"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 )
 
+// secretTestResult is the outcome of resolving a secret reference
+type secretTestResult struct {
+	config.SecretReference
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// secretRotateResult is the outcome of rotating a secret in a secret-store
+type secretRotateResult struct {
+	Store  string `json:"store"`
+	Key    string `json:"key"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// testSecretReferences resolves all given references using their secret-store
+// without revealing the secret values
+func testSecretReferences(m App, refs []config.SecretReference) []secretTestResult {
+	stores := make(map[string]telegraf.SecretStore)
+	storeErrs := make(map[string]error)
+
+	results := make([]secretTestResult, 0, len(refs))
+	for _, ref := range refs {
+		result := secretTestResult{SecretReference: ref, Status: "ok"}
+
+		store, found := stores[ref.Store]
+		err, failed := storeErrs[ref.Store]
+		if !found && !failed {
+			store, err = m.GetSecretStore(ref.Store)
+			if err != nil {
+				storeErrs[ref.Store] = err
+			} else {
+				stores[ref.Store] = store
+			}
+		}
+		if err != nil {
+			result.Status = "failed"
+			result.Error = fmt.Sprintf("unable to get secret-store: %v", err)
+			results = append(results, result)
+			continue
+		}
+
+		resolver, err := store.GetResolver(ref.Key)
+		if err != nil {
+			result.Status = "failed"
+			result.Error = fmt.Sprintf("unable to get resolver: %v", err)
+			results = append(results, result)
+			continue
+		}
+		v, _, err := resolver()
+		memguard.WipeBytes(v)
+		if err != nil {
+			result.Status = "failed"
+			result.Error = fmt.Sprintf("unable to resolve secret: %v", err)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// rotateSecret replaces the value of the secret in all given secret-stores
+// containing the secret
+func rotateSecret(m App, storeIDs []string, key, value string) []secretRotateResult {
+	results := make([]secretRotateResult, 0, len(storeIDs))
+	for _, storeID := range storeIDs {
+		result := secretRotateResult{Store: storeID, Key: key, Status: "rotated"}
+
+		store, err := m.GetSecretStore(storeID)
+		if err != nil {
+			result.Status = "failed"
+			result.Error = fmt.Sprintf("unable to get secret-store: %v", err)
+			results = append(results, result)
+			continue
+		}
+		keys, err := store.List()
+		if err != nil {
+			result.Status = "failed"
+			result.Error = fmt.Sprintf("unable to list secrets: %v", err)
+			results = append(results, result)
+			continue
+		}
+		if !slices.Contains(keys, key) {
+			result.Status = "skipped"
+			results = append(results, result)
+			continue
+		}
+		if err := store.Set(key, value); err != nil {
+			result.Status = "failed"
+			result.Error = fmt.Sprintf("unable to set secret: %v", err)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
 func processFilterOnlySecretStoreFlags(ctx *cli.Context) Filters {
 	sectionFilters := []string{"inputs", "outputs", "processors", "aggregators"}
 	inputFilters := []string{"-"}
@@ -37,7 +146,7 @@ func getSecretStoreCommands(m App) []*cli.Command {
 	return []*cli.Command{
 		{
 			Name:  "secrets",
-			Usage: "commands for listing, adding and removing secrets on all known secret-stores",
+			Usage: "commands for listing, adding, rotating and checking secrets on all known secret-stores",
 			Subcommands: []*cli.Command{
 				{
 					Name:  "list",
@@ -255,6 +364,266 @@ you will be prompted to enter the value of the secret.
 						return nil
 					},
 				},
+				{
+					Name:  "references",
+					Usage: "list the secrets referenced by the configured plugins",
+					Description: `
+The 'references' command requires passing in your configuration file
+containing the plugin definitions you want to check.
+
+Assuming you use the default configuration file location, you can run
+the following command to list all secrets referenced in the plugin
+configurations together with the plugins and options using them
+
+> telegraf secrets references
+
+To get a machine-readable report pass the '--json' flag.
+`,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "json",
+							Usage: "print the report in JSON format",
+						},
+					},
+					Action: func(cCtx *cli.Context) error {
+						// Only load the secret-stores
+						filters := processFilterOnlySecretStoreFlags(cCtx)
+						g := GlobalFlags{
+							config:     cCtx.StringSlice("config"),
+							configDir:  cCtx.StringSlice("config-directory"),
+							plugindDir: cCtx.String("plugin-directory"),
+							password:   cCtx.String("password"),
+							debug:      cCtx.Bool("debug"),
+						}
+						w := WindowFlags{}
+						m.Init(nil, filters, g, w)
+
+						refs, err := m.ListSecretReferences()
+						if err != nil {
+							return fmt.Errorf("unable to determine secret references: %w", err)
+						}
+						if cCtx.Bool("json") {
+							return printJSON(refs)
+						}
+
+						// Group the plugins by the referenced secret
+						var secrets []string
+						users := make(map[string][]config.SecretReference)
+						for _, ref := range refs {
+							r := ref.Reference()
+							if _, found := users[r]; !found {
+								secrets = append(secrets, r)
+							}
+							users[r] = append(users[r], ref)
+						}
+						sort.Strings(secrets)
+
+						for _, r := range secrets {
+							fmt.Printf("Secret %s referenced by:\n", r)
+							for _, ref := range users[r] {
+								plugin := ref.Plugin
+								if ref.Alias != "" {
+									plugin += "::" + ref.Alias
+								}
+								fmt.Printf("    %-30s  %-20s  %s:%d\n", plugin, ref.Option, ref.Source, ref.Line)
+							}
+						}
+
+						return nil
+					},
+				},
+				{
+					Name:  "test",
+					Usage: "resolve all secrets referenced by the configured plugins",
+					Description: `
+The 'test' command requires passing in your configuration file
+containing the plugin and secret-store definitions you want to check.
+
+Assuming you use the default configuration file location, you can run
+the following command to check that all secrets referenced in the
+plugin configurations can be resolved using the configured secret-stores
+
+> telegraf secrets test
+
+The secret values are not revealed. The command fails if any of the
+references cannot be resolved. To get a machine-readable report pass
+the '--json' flag.
+`,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "json",
+							Usage: "print the report in JSON format",
+						},
+					},
+					Action: func(cCtx *cli.Context) error {
+						// Only load the secret-stores
+						filters := processFilterOnlySecretStoreFlags(cCtx)
+						g := GlobalFlags{
+							config:     cCtx.StringSlice("config"),
+							configDir:  cCtx.StringSlice("config-directory"),
+							plugindDir: cCtx.String("plugin-directory"),
+							password:   cCtx.String("password"),
+							debug:      cCtx.Bool("debug"),
+						}
+						w := WindowFlags{}
+						m.Init(nil, filters, g, w)
+
+						refs, err := m.ListSecretReferences()
+						if err != nil {
+							return fmt.Errorf("unable to determine secret references: %w", err)
+						}
+						results := testSecretReferences(m, refs)
+
+						var failed int
+						for _, r := range results {
+							if r.Status != "ok" {
+								failed++
+							}
+						}
+
+						if cCtx.Bool("json") {
+							if err := printJSON(results); err != nil {
+								return err
+							}
+						} else {
+							for _, r := range results {
+								fmt.Printf("%-6s  %-40s  %s (%s)", r.Status, r.Reference(), r.Plugin, r.Option)
+								if r.Error != "" {
+									fmt.Printf(": %s", r.Error)
+								}
+								fmt.Println()
+							}
+						}
+
+						if failed > 0 {
+							return fmt.Errorf("%d of %d secret references failed", failed, len(results))
+						}
+						return nil
+					},
+				},
+				{
+					Name:  "rotate",
+					Usage: "modify the value of a secret in all stores containing it",
+					Description: `
+The 'rotate' command requires passing in your configuration file
+containing the secret-store definitions you want to access.
+
+Assuming you use the default configuration file location, you can run
+the following command to replace the value of the secret with the key
+'mysecretkey' in ALL available secret-stores containing that secret
+
+> telegraf secrets rotate mysecretkey
+
+To only rotate the secret in particular stores, you can run
+
+> telegraf secrets rotate mysecretkey mystore myotherstore
+
+You will be prompted to enter the new value of the secret unless it is
+passed via the '--value' flag. If the standard input is not a terminal
+the value is read from the first line of the input. Stores not
+supporting writes are reported as failed and the command fails. To get
+a machine-readable report pass the '--json' flag.
+`,
+					ArgsUsage: "<secret key> [secret-store ID]...[secret-store ID]",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "value",
+							Usage: "new value of the secret",
+						},
+						&cli.BoolFlag{
+							Name:  "json",
+							Usage: "print the report in JSON format",
+						},
+					},
+					Action: func(cCtx *cli.Context) error {
+						// Only load the secret-stores
+						filters := processFilterOnlySecretStoreFlags(cCtx)
+						g := GlobalFlags{
+							config:     cCtx.StringSlice("config"),
+							configDir:  cCtx.StringSlice("config-directory"),
+							plugindDir: cCtx.String("plugin-directory"),
+							password:   cCtx.String("password"),
+							debug:      cCtx.Bool("debug"),
+						}
+						w := WindowFlags{}
+						m.Init(nil, filters, g, w)
+
+						args := cCtx.Args()
+						if !args.Present() {
+							return errors.New("invalid number of arguments")
+						}
+						key := args.First()
+
+						storeIDs := args.Tail()
+						if len(storeIDs) == 0 {
+							ids, err := m.ListSecretStores()
+							if err != nil {
+								return fmt.Errorf("unable to determine secret-store IDs: %w", err)
+							}
+							storeIDs = ids
+						}
+						sort.Strings(storeIDs)
+
+						value := cCtx.String("value")
+						if value == "" {
+							fd := int(os.Stdin.Fd())
+							if term.IsTerminal(fd) {
+								// Prompt on stderr to keep the report on stdout
+								// machine-readable
+								fmt.Fprint(os.Stderr, "Enter new secret value: ")
+								b, err := term.ReadPassword(fd)
+								if err != nil {
+									return err
+								}
+								fmt.Fprintln(os.Stderr)
+								value = string(b)
+							} else {
+								line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+								if err != nil && line == "" {
+									return fmt.Errorf("unable to read secret value: %w", err)
+								}
+								value = strings.TrimRight(line, "\r\n")
+							}
+						}
+						if value == "" {
+							return errors.New("empty secret value")
+						}
+
+						results := rotateSecret(m, storeIDs, key, value)
+
+						var rotated, failed int
+						for _, r := range results {
+							switch r.Status {
+							case "rotated":
+								rotated++
+							case "failed":
+								failed++
+							}
+						}
+
+						if cCtx.Bool("json") {
+							if err := printJSON(results); err != nil {
+								return err
+							}
+						} else {
+							for _, r := range results {
+								fmt.Printf("%-8s  %s", r.Status, r.Store)
+								if r.Error != "" {
+									fmt.Printf(": %s", r.Error)
+								}
+								fmt.Println()
+							}
+						}
+
+						if failed > 0 {
+							return fmt.Errorf("rotating secret %q failed for %d store(s)", key, failed)
+						}
+						if rotated == 0 {
+							return fmt.Errorf("secret %q not found in any store", key)
+						}
+						return nil
+					},
+				},
 			},
 		},
 	}
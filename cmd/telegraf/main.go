@@ -233,6 +233,7 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 			watchInterval:           cCtx.Duration("watch-interval"),
 			watchDebounceInterval:   cCtx.Duration("watch-debounce-interval"),
 			pidFile:                 cCtx.String("pidfile"),
+			reloadReport:            cCtx.String("reload-report"),
 			plugindDir:              cCtx.String("plugin-directory"),
 			password:                cCtx.String("password"),
 			oldEnvBehavior:          cCtx.Bool("old-env-behavior"),
@@ -306,6 +307,10 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 					Name:  "pidfile",
 					Usage: "file to write our pid to",
 				},
+				&cli.StringFlag{
+					Name:  "reload-report",
+					Usage: "file to write a diagnostic report to in case reloading the config fails",
+				},
 				&cli.StringFlag{
 					Name:  "password",
 					Usage: "password to unlock secret-stores",
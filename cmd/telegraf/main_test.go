@@ -74,6 +74,14 @@ func (*MockTelegraf) GetSecretStore(id string) (telegraf.SecretStore, error) {
 	return s, nil
 }
 
+func (*MockTelegraf) ListSecretReferences() ([]config.SecretReference, error) {
+	return []config.SecretReference{
+		{Plugin: "inputs.jedi", Option: "master", Store: "yoda", Key: "episode1"},
+		{Plugin: "inputs.jedi", Option: "council", Store: "mace_windu", Key: "episode4"},
+		{Plugin: "outputs.sith", Option: "master", Store: "darth_sidious", Key: "episode1"},
+	}, nil
+}
+
 type MockSecretStore struct {
 	Secrets map[string][]byte
 }
@@ -553,6 +561,7 @@ func TestFlagsAreSet(t *testing.T) {
 		"--test-wait", strconv.Itoa(expectedInt),
 		"--watch-config", expectedString,
 		"--pidfile", expectedString,
+		"--reload-report", expectedString,
 	}
 
 	buf := new(bytes.Buffer)
@@ -571,4 +580,51 @@ func TestFlagsAreSet(t *testing.T) {
 	require.Equal(t, expectedInt, m.testWait)
 	require.Equal(t, expectedString, m.watchConfig)
 	require.Equal(t, expectedString, m.pidFile)
+	require.Equal(t, expectedString, m.reloadReport)
+}
+
+func TestSecretsTestReferences(t *testing.T) {
+	m := NewMockTelegraf()
+	refs, err := m.ListSecretReferences()
+	require.NoError(t, err)
+
+	results := testSecretReferences(m, refs)
+	require.Len(t, results, 3)
+
+	require.Equal(t, "ok", results[0].Status)
+	require.Empty(t, results[0].Error)
+	require.Equal(t, "failed", results[1].Status)
+	require.Contains(t, results[1].Error, "unable to resolve secret")
+	require.Equal(t, "failed", results[2].Status)
+	require.Contains(t, results[2].Error, "unable to get secret-store")
+}
+
+func TestSecretsRotate(t *testing.T) {
+	// Restore the original secret values after the test
+	original := make(map[string][]byte)
+	for id, s := range secrets {
+		original[id] = s["episode3"]
+	}
+	defer func() {
+		for id, v := range original {
+			if v != nil {
+				secrets[id]["episode3"] = v
+			}
+		}
+	}()
+
+	m := NewMockTelegraf()
+	ids := []string{"coleman_kcaj", "darth_vader", "oppo_rancisis", "yoda"}
+	results := rotateSecret(m, ids, "episode3", "master")
+
+	expected := []secretRotateResult{
+		{Store: "coleman_kcaj", Key: "episode3", Status: "rotated"},
+		{Store: "darth_vader", Key: "episode3", Status: "failed", Error: "unable to get secret-store: unknown secret store"},
+		{Store: "oppo_rancisis", Key: "episode3", Status: "skipped"},
+		{Store: "yoda", Key: "episode3", Status: "rotated"},
+	}
+	require.Equal(t, expected, results)
+	require.Equal(t, []byte("master"), secrets["yoda"]["episode3"])
+	require.Equal(t, []byte("master"), secrets["coleman_kcaj"]["episode3"])
+	require.NotContains(t, secrets["oppo_rancisis"], "episode3")
 }
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+var (
+	// reloadStatus is zero if the requested configuration is running and one
+	// if a reload failed and the previous configuration was restored
+	reloadStatus   = selfstat.Register("agent", "reload_status", make(map[string]string))
+	reloadFailures = selfstat.Register("agent", "reload_failures", make(map[string]string))
+)
+
+// rollback restores the last known good configuration after reloading the
+// configuration failed with the given error
+func (t *Telegraf) rollback(reason error) error {
+	reloadStatus.Set(1)
+	reloadFailures.Incr(1)
+
+	log.Printf("E! Reloading config failed: %v", reason)
+	log.Printf("W! Rolling back to the last known good config")
+
+	if t.reloadReport != "" {
+		if err := t.writeReloadReport(reason); err != nil {
+			log.Printf("E! Writing reload report failed: %v", err)
+		} else {
+			log.Printf("I! Wrote reload report to %q", t.reloadReport)
+		}
+	}
+
+	c := config.NewConfig()
+	c.Agent.Quiet = t.quiet
+	c.OutputFilters = t.outputFilters
+	c.InputFilters = t.inputFilters
+	c.SecretStoreFilters = t.secretstoreFilters
+	if err := c.LoadAllSources(t.backup...); err != nil {
+		return fmt.Errorf("restoring last known good config failed: %w", err)
+	}
+	t.cfg = c
+
+	return nil
+}
+
+// writeReloadReport writes a diagnostic report for a failed reload. The
+// report does not contain the configuration content to not leak any
+// credentials.
+func (t *Telegraf) writeReloadReport(reason error) error {
+	var report strings.Builder
+	fmt.Fprintf(&report, "Telegraf config reload failed at %s\n\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&report, "Error:\n  %v\n\n", reason)
+	report.WriteString("Requested config files:\n")
+	for _, fn := range t.configFiles {
+		fmt.Fprintf(&report, "  %s\n", fn)
+	}
+	report.WriteString("\nRestored config files:\n")
+	for _, src := range t.backup {
+		fmt.Fprintf(&report, "  %s (%d bytes)\n", src.Path, len(src.Data))
+	}
+
+	return os.WriteFile(t.reloadReport, []byte(report.String()), 0640)
+}
@@ -0,0 +1,94 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+func TestReloadRollback(t *testing.T) {
+	// Other tests might leave an invalid version behind
+	version := internal.Version
+	internal.Version = "unknown"
+	defer func() { internal.Version = version }()
+
+	dir := t.TempDir()
+	cfgfile := filepath.Join(dir, "telegraf.conf")
+	outfile := filepath.Join(dir, "metrics.out")
+	reportfile := filepath.Join(dir, "reload-report.txt")
+
+	// Start with a valid config writing metrics to a file
+	valid := fmt.Sprintf(`
+[agent]
+  interval = "100ms"
+  flush_interval = "100ms"
+  omit_hostname = true
+
+[[inputs.internal]]
+
+[[outputs.file]]
+  files = [%q]
+`, outfile)
+	require.NoError(t, os.WriteFile(cfgfile, []byte(valid), 0600))
+
+	agent := &Telegraf{}
+	agent.Init(nil, Filters{}, GlobalFlags{config: []string{cfgfile}, quiet: true, reloadReport: reportfile}, WindowFlags{})
+	cfg, err := agent.loadConfiguration()
+	require.NoError(t, err)
+	agent.cfg = cfg
+
+	stop = make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- agent.reloadLoop()
+	}()
+	defer func() {
+		stop <- struct{}{}
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "agent did not stop")
+		}
+	}()
+
+	// Wait for the agent to run
+	require.Eventually(t, func() bool {
+		info, err := os.Stat(outfile)
+		return err == nil && info.Size() > 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Reload a broken config and make sure the previous config is restored
+	failures := reloadFailures.Get()
+	require.NoError(t, os.WriteFile(cfgfile, []byte("[[inputs.internal]\n"), 0600))
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	require.Eventually(t, func() bool {
+		return reloadFailures.Get() == failures+1
+	}, 5*time.Second, 50*time.Millisecond)
+	require.Equal(t, int64(1), reloadStatus.Get())
+	require.FileExists(t, reportfile)
+
+	// The previous config must still be running and produce output
+	info, err := os.Stat(outfile)
+	require.NoError(t, err)
+	size := info.Size()
+	require.Eventually(t, func() bool {
+		info, err := os.Stat(outfile)
+		return err == nil && info.Size() > size
+	}, 5*time.Second, 50*time.Millisecond)
+
+	select {
+	case err := <-done:
+		require.Failf(t, "agent stopped unexpectedly", "error: %v", err)
+	default:
+	}
+}
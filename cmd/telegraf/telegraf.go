@@ -43,6 +43,7 @@ type GlobalFlags struct {
 	watchInterval           time.Duration
 	watchDebounceInterval   time.Duration
 	pidFile                 string
+	reloadReport            string
 	plugindDir              string
 	password                string
 	oldEnvBehavior          bool
@@ -70,6 +71,7 @@ type App interface {
 	// Secret store commands
 	ListSecretStores() ([]string, error)
 	GetSecretStore(string) (telegraf.SecretStore, error)
+	ListSecretReferences() ([]config.SecretReference, error)
 }
 
 type Telegraf struct {
@@ -82,6 +84,10 @@ type Telegraf struct {
 
 	cfg *config.Config
 
+	// Configuration sources of the last configuration that was running
+	// successfully used to rollback in case reloading the config fails
+	backup []config.Source
+
 	GlobalFlags
 	WindowFlags
 }
@@ -139,6 +145,16 @@ func (t *Telegraf) GetSecretStore(id string) (telegraf.SecretStore, error) {
 	return store, nil
 }
 
+func (t *Telegraf) ListSecretReferences() ([]config.SecretReference, error) {
+	t.quiet = true
+	c, err := t.loadConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.SecretReferences()
+}
+
 func (t *Telegraf) reloadLoop() error {
 	reloadConfig := false
 	reload := make(chan bool, 1)
@@ -183,6 +199,7 @@ func (t *Telegraf) reloadLoop() error {
 		}
 		go func() {
 			select {
+			case <-ctx.Done():
 			case sig := <-signals:
 				if sig == syscall.SIGHUP {
 					log.Println("I! Reloading Telegraf config")
@@ -204,10 +221,33 @@ func (t *Telegraf) reloadLoop() error {
 			}
 		}()
 
+		if reloadConfig {
+			reloadStatus.Set(0)
+		}
 		err := t.runAgent(ctx, reloadConfig)
 		if err != nil && !errors.Is(err, context.Canceled) {
-			return fmt.Errorf("[telegraf] Error running agent: %w", err)
+			// Do not exit if a reloaded configuration fails but continue
+			// running the last known good configuration instead
+			if !reloadConfig || len(t.backup) == 0 {
+				return fmt.Errorf("[telegraf] Error running agent: %w", err)
+			}
+			cancel()
+			signal.Stop(signals)
+
+			if err := t.rollback(err); err != nil {
+				return fmt.Errorf("[telegraf] Error running agent: %w", err)
+			}
+			reloadConfig = false
+			<-reload
+			reload <- true
+			continue
 		}
+		cancel()
+		signal.Stop(signals)
+
+		// The configuration was running successfully so remember it as the
+		// last known good configuration
+		t.backup = t.cfg.Sources
 		reloadConfig = true
 	}
 
@@ -438,6 +478,7 @@ func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
 		if c, err = t.loadConfiguration(); err != nil {
 			return err
 		}
+		t.cfg = c
 	}
 
 	if !t.test && t.testWait == 0 && len(c.Outputs) == 0 {
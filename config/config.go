@@ -2,6 +2,7 @@ package config
 
 import (
 	"bytes"
+	"crypto/fips140"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -30,6 +31,7 @@ import (
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/persister"
 	"github.com/influxdata/telegraf/plugins/aggregators"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
@@ -101,8 +103,20 @@ type Config struct {
 
 	NumberSecrets uint64
 
+	// Sources contains the raw content of the loaded configuration files
+	// in the order they were loaded
+	Sources []Source
+
 	seenAgentTable     bool
 	seenAgentTableOnce sync.Once
+
+	tlsPolicy *common_tls.Policy
+}
+
+// Source contains the raw, unprocessed content of a configuration file
+type Source struct {
+	Path string
+	Data []byte
 }
 
 // Ordered plugins used to keep the order in which they appear in a file
@@ -215,6 +229,23 @@ type AgentConfig struct {
 	// not be less than 2 times MetricBatchSize.
 	MetricBufferLimit int
 
+	// MetricBufferLimitBytes is the max approximate memory footprint of the
+	// metrics each output plugin will cache, e.g. "64MiB". The limit applies
+	// in addition to MetricBufferLimit and only to the memory buffer. When
+	// reached, the oldest metrics will be overwritten.
+	MetricBufferLimitBytes Size `toml:"metric_buffer_limit_bytes"`
+
+	// LatencyBudget is the maximum time metrics are buffered in each output
+	// plugin. Older metrics are shed according to LatencyShedding to keep
+	// fresh data flowing during prolonged output slowdowns. Zero disables
+	// the budget.
+	LatencyBudget Duration `toml:"latency_budget"`
+
+	// LatencyShedding is the action applied to metrics exceeding the latency
+	// budget, either "drop" or "last" for keeping the newest metric per
+	// series and downsampling window.
+	LatencyShedding string `toml:"latency_shedding"`
+
 	// FlushBufferWhenFull tells Telegraf to flush the metric buffer whenever
 	// it fills up, regardless of FlushInterval. Setting this option to true
 	// does _not_ deactivate FlushInterval.
@@ -299,6 +330,17 @@ type AgentConfig struct {
 	// BufferDirectory is the directory to store buffer files for serialized
 	// to disk metrics when using the "disk_write_through" buffer strategy.
 	BufferDirectory string `toml:"buffer_directory"`
+
+	// TLSPolicyMinVersion is the minimal TLS version allowed for all plugins.
+	TLSPolicyMinVersion string `toml:"tls_policy_min_version"`
+
+	// TLSPolicyCipherSuites is the list of cipher suites allowed for all
+	// plugins.
+	TLSPolicyCipherSuites []string `toml:"tls_policy_cipher_suites"`
+
+	// TLSPolicyFIPS restricts the TLS settings of all plugins to FIPS 140
+	// approved algorithms.
+	TLSPolicyFIPS bool `toml:"tls_policy_fips"`
 }
 
 // InputNames returns a list of strings of the configured inputs.
@@ -548,6 +590,7 @@ func (c *Config) LoadConfig(path string) error {
 	if err = c.LoadConfigData(data, path); err != nil {
 		return fmt.Errorf("loading config file %s failed: %w", path, err)
 	}
+	c.Sources = append(c.Sources, Source{Path: path, Data: data})
 
 	return nil
 }
@@ -559,11 +602,38 @@ func (c *Config) LoadAll(configFiles ...string) error {
 		}
 	}
 
+	return c.finalize()
+}
+
+// LoadAllSources loads the configuration from previously read sources e.g.
+// to restore a former configuration without accessing the original files
+func (c *Config) LoadAllSources(sources ...Source) error {
+	for _, src := range sources {
+		if !c.Agent.Quiet {
+			log.Printf("I! Loading config: %s", src.Path)
+		}
+		if err := c.LoadConfigData(src.Data, src.Path); err != nil {
+			return fmt.Errorf("loading config file %s failed: %w", src.Path, err)
+		}
+		c.Sources = append(c.Sources, src)
+	}
+
+	return c.finalize()
+}
+
+// finalize performs the steps required after loading all configuration files
+func (c *Config) finalize() error {
 	// Sort the processors according to their `order` setting while
 	// using a stable sort to keep the file loading / file position order.
 	sort.Stable(c.Processors)
 	sort.Stable(c.AggProcessors)
 
+	// Pass the agent's TLS policy to all plugins and flag the ones with TLS
+	// settings violating the policy
+	if err := c.applyTLSPolicy(); err != nil {
+		return err
+	}
+
 	// Set snmp agent translator default
 	if c.Agent.SnmpTranslator == "" {
 		c.Agent.SnmpTranslator = "netsnmp"
@@ -581,6 +651,32 @@ func (c *Config) LoadAll(configFiles ...string) error {
 	return c.LinkSecrets()
 }
 
+// applyTLSPolicy sets the agent's TLS policy for all plugins and returns an
+// error listing all plugins with TLS settings violating the policy
+func (c *Config) applyTLSPolicy() error {
+	var errs []error
+	for _, input := range c.Inputs {
+		if err := common_tls.ApplyPluginPolicy(input.Input, c.tlsPolicy); err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", input.LogName(), err))
+		}
+	}
+	for _, output := range c.Outputs {
+		if err := common_tls.ApplyPluginPolicy(output.Output, c.tlsPolicy); err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", output.LogName(), err))
+		}
+	}
+	for _, processor := range c.Processors {
+		var plugin interface{} = processor.Processor
+		if p, ok := processor.Processor.(processors.HasUnwrap); ok {
+			plugin = p.Unwrap()
+		}
+		if err := common_tls.ApplyPluginPolicy(plugin, c.tlsPolicy); err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", processor.LogName(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 type cfgDataOptions struct {
 	sourcePath string
 }
@@ -653,6 +749,22 @@ func (c *Config) LoadConfigData(data []byte, path string) error {
 		})
 	}
 
+	// Validate the TLS policy applied to the plugins after loading all files
+	if c.Agent.TLSPolicyMinVersion != "" || len(c.Agent.TLSPolicyCipherSuites) > 0 || c.Agent.TLSPolicyFIPS {
+		policy := &common_tls.Policy{
+			MinVersion:   c.Agent.TLSPolicyMinVersion,
+			CipherSuites: c.Agent.TLSPolicyCipherSuites,
+			FIPS:         c.Agent.TLSPolicyFIPS,
+		}
+		if err := policy.Init(); err != nil {
+			return fmt.Errorf("invalid TLS policy: %w", err)
+		}
+		c.tlsPolicy = policy
+	}
+	if c.Agent.TLSPolicyFIPS && !fips140.Enabled() {
+		log.Printf("W! TLS policy restricted to FIPS algorithms but the Go cryptographic module is not in FIPS 140 mode; set GODEBUG=fips140=on to enable it")
+	}
+
 	// Set up the persister if requested
 	if c.Agent.Statefile != "" {
 		c.Persister = &persister.Persister{
@@ -1444,6 +1556,8 @@ func (c *Config) buildAggregator(name, source string, tbl *ast.Table) (*models.A
 		Delay:  time.Millisecond * 100,
 		Period: time.Second * 30,
 		Grace:  time.Second * 0,
+
+		MaxBufferedMetrics: 10000,
 	}
 
 	if period, found := c.getFieldDuration(tbl, "period"); found {
@@ -1455,6 +1569,13 @@ func (c *Config) buildAggregator(name, source string, tbl *ast.Table) (*models.A
 	if grace, found := c.getFieldDuration(tbl, "grace"); found {
 		conf.Grace = grace
 	}
+	conf.WindowMode = c.getFieldString(tbl, "window_mode")
+	if lateness, found := c.getFieldDuration(tbl, "allowed_lateness"); found {
+		conf.AllowedLateness = lateness
+	}
+	if _, found := tbl.Fields["max_buffered_metrics"]; found {
+		conf.MaxBufferedMetrics = c.getFieldInt(tbl, "max_buffered_metrics")
+	}
 
 	conf.DropOriginal = c.getFieldBool(tbl, "drop_original")
 	conf.MeasurementPrefix = c.getFieldString(tbl, "name_prefix")
@@ -1476,6 +1597,20 @@ func (c *Config) buildAggregator(name, source string, tbl *ast.Table) (*models.A
 		return nil, c.firstErr()
 	}
 
+	switch conf.WindowMode {
+	case "":
+		conf.WindowMode = "arrival"
+	case "arrival", "event":
+	default:
+		return nil, fmt.Errorf("invalid window_mode %q for aggregator %s", conf.WindowMode, name)
+	}
+	if conf.AllowedLateness < 0 {
+		return nil, fmt.Errorf("allowed_lateness must not be negative for aggregator %s", name)
+	}
+	if conf.MaxBufferedMetrics < 0 {
+		return nil, fmt.Errorf("max_buffered_metrics must not be negative for aggregator %s", name)
+	}
+
 	var err error
 	conf.Filter, err = c.buildFilter("aggregators."+name, tbl)
 	if err != nil {
@@ -1670,7 +1805,22 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 	oc.FlushInterval, _ = c.getFieldDuration(tbl, "flush_interval")
 	oc.FlushJitter, _ = c.getFieldDuration(tbl, "flush_jitter")
 	oc.MetricBufferLimit = c.getFieldInt(tbl, "metric_buffer_limit")
+	oc.MetricBufferLimitBytes = c.getFieldSize(tbl, "metric_buffer_limit_bytes")
+	if oc.MetricBufferLimitBytes == 0 {
+		oc.MetricBufferLimitBytes = int64(c.Agent.MetricBufferLimitBytes)
+	}
 	oc.MetricBatchSize = c.getFieldInt(tbl, "metric_batch_size")
+	oc.LatencyBudget, _ = c.getFieldDuration(tbl, "latency_budget")
+	if oc.LatencyBudget == 0 {
+		oc.LatencyBudget = time.Duration(c.Agent.LatencyBudget)
+	}
+	oc.LatencyShedding = c.getFieldString(tbl, "latency_shedding")
+	if oc.LatencyShedding == "" {
+		oc.LatencyShedding = c.Agent.LatencyShedding
+	}
+	oc.LatencyWindow, _ = c.getFieldDuration(tbl, "latency_window")
+	oc.LatencyProtect = c.getFieldStringSlice(tbl, "latency_protect")
+	oc.LatencyDrop = c.getFieldStringSlice(tbl, "latency_drop")
 	oc.Alias = c.getFieldString(tbl, "alias")
 	oc.NameOverride = c.getFieldString(tbl, "name_override")
 	oc.NameSuffix = c.getFieldString(tbl, "name_suffix")
@@ -1683,6 +1833,9 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 	}
 
 	if oc.BufferStrategy == "disk_write_through" {
+		if oc.MetricBufferLimitBytes > 0 {
+			return nil, fmt.Errorf("'metric_buffer_limit_bytes' is not supported by the disk-write-through buffer strategy for plugin outputs.%s", name)
+		}
 		log.Printf("W! Using disk-write-through buffer strategy for plugin outputs.%s, this is an experimental feature", name)
 	}
 
@@ -1694,19 +1847,21 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 func (c *Config) missingTomlField(_ reflect.Type, key string) error {
 	switch key {
 	// General options to ignore
-	case "alias", "always_include_local_tags",
+	case "alias", "allowed_lateness", "always_include_local_tags",
 		"buffer_strategy", "buffer_directory",
 		"collection_jitter", "collection_offset",
 		"data_format", "delay", "drop", "drop_original",
 		"fielddrop", "fieldexclude", "fieldinclude", "fieldpass", "flush_interval", "flush_jitter",
 		"grace",
 		"interval",
+		"latency_budget", "latency_drop", "latency_protect", "latency_shedding", "latency_window",
 		"log_level", "lvm", // What is this used for?
-		"metric_batch_size", "metric_buffer_limit", "metricpass",
+		"max_buffered_metrics", "metric_batch_size", "metric_buffer_limit", "metric_buffer_limit_bytes", "metricpass",
 		"name_override", "name_prefix", "name_suffix", "namedrop", "namedrop_separator", "namepass", "namepass_separator",
 		"order",
 		"pass", "period", "precision",
-		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "startup_error_behavior":
+		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "startup_error_behavior",
+		"window_mode":
 
 	// Secret-store options to ignore
 	case "id":
@@ -1849,6 +2004,31 @@ func (c *Config) getFieldInt64(tbl *ast.Table, fieldName string) int64 {
 	return 0
 }
 
+func (c *Config) getFieldSize(tbl *ast.Table, fieldName string) int64 {
+	if node, ok := tbl.Fields[fieldName]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			var raw string
+			switch v := kv.Value.(type) {
+			case *ast.Integer:
+				raw = v.Value
+			case *ast.String:
+				raw = v.Value
+			default:
+				c.addError(tbl, fmt.Errorf("found unexpected format while parsing %q, expecting size", fieldName))
+				return 0
+			}
+			var size Size
+			if err := size.UnmarshalText([]byte(raw)); err != nil {
+				c.addError(tbl, fmt.Errorf("error parsing size: %w", err))
+				return 0
+			}
+			return int64(size)
+		}
+	}
+
+	return 0
+}
+
 func (c *Config) getFieldStringSlice(tbl *ast.Table, fieldName string) []string {
 	var target []string
 	if node, ok := tbl.Fields[fieldName]; ok {
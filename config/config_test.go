@@ -146,6 +146,27 @@ func TestConfig_LoadSingleInput(t *testing.T) {
 	require.Equal(t, inputConfig, c.Inputs[0].Config, "Testdata did not produce correct memcached metadata.")
 }
 
+func TestConfig_LoadAllSources(t *testing.T) {
+	confFile := filepath.Join("testdata", "single_plugin.toml")
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll(confFile))
+	require.Len(t, c.Sources, 1)
+	require.Equal(t, confFile, c.Sources[0].Path)
+
+	// Restoring the configuration from the sources must result in the same
+	// plugin setup even if the original file is not accessible anymore
+	restored := config.NewConfig()
+	require.NoError(t, restored.LoadAllSources(c.Sources...))
+	require.Equal(t, c.Sources, restored.Sources)
+	require.Len(t, restored.Inputs, 1)
+
+	expected := c.Inputs[0].Input.(*MockupInputPlugin)
+	actual := restored.Inputs[0].Input.(*MockupInputPlugin)
+	require.Equal(t, expected.Servers, actual.Servers)
+	require.Equal(t, c.Inputs[0].Config.Interval, restored.Inputs[0].Config.Interval)
+	require.Equal(t, c.Inputs[0].Config.Source, restored.Inputs[0].Config.Source)
+}
+
 func TestConfig_LoadSingleInput_WithSeparators(t *testing.T) {
 	c := config.NewConfig()
 	confFile := filepath.Join("testdata", "single_plugin_with_separators.toml")
@@ -504,6 +525,24 @@ func TestConfig_BadOrdering(t *testing.T) {
 	)
 }
 
+func TestConfig_TLSPolicy(t *testing.T) {
+	c := config.NewConfig()
+	err := c.LoadAll("./testdata/tls_policy.toml")
+	require.ErrorContains(t, err, `plugin inputs.http_listener_v2: settings violate TLS policy: tls_min_version "TLS11" is below the minimal version allowed`)
+	require.ErrorContains(t, err, "plugin outputs.http: settings violate TLS policy: tls_cipher_suites contains suites not allowed: TLS_RSA_WITH_AES_128_CBC_SHA")
+	require.NotContains(t, err.Error(), "inputs.memcached")
+
+	// The policy is not shared with other configuration instances
+	c = config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte("[[outputs.http]]\n  url = \"https://localhost\"\n  tls_min_version = \"TLS10\"\n"), config.EmptySourcePath))
+}
+
+func TestConfig_TLSPolicyInvalid(t *testing.T) {
+	c := config.NewConfig()
+	err := c.LoadConfigData([]byte("[agent]\n  tls_policy_min_version = \"TLS11\"\n  tls_policy_fips = true\n"), config.EmptySourcePath)
+	require.ErrorContains(t, err, `invalid TLS policy: minimal version "TLS11" not allowed in FIPS mode`)
+}
+
 func TestConfig_AzureMonitorNamespacePrefix(t *testing.T) {
 	// #8256 Cannot use empty string as the namespace prefix
 	c := config.NewConfig()
@@ -518,6 +557,43 @@ func TestConfig_AzureMonitorNamespacePrefix(t *testing.T) {
 	}
 }
 
+func TestConfig_BufferLimitBytes(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/buffer_limit_bytes.toml"))
+	require.Len(t, c.Outputs, 3)
+
+	expected := []int64{1024 * 1024, 64000, 1024}
+	for i, plugin := range c.Outputs {
+		require.Equal(t, expected[i], plugin.Config.MetricBufferLimitBytes)
+	}
+}
+
+func TestConfig_BufferLimitBytesDiskBuffer(t *testing.T) {
+	c := config.NewConfig()
+	err := c.LoadConfig("./testdata/buffer_limit_bytes_disk.toml")
+	require.ErrorContains(t, err, "'metric_buffer_limit_bytes' is not supported by the disk-write-through buffer strategy")
+}
+
+func TestConfig_LatencyBudget(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/latency_budget.toml"))
+	require.Len(t, c.Outputs, 2)
+
+	cfg := c.Outputs[0].Config
+	require.Equal(t, 5*time.Minute, cfg.LatencyBudget)
+	require.Equal(t, "last", cfg.LatencyShedding)
+	require.Zero(t, cfg.LatencyWindow)
+	require.Empty(t, cfg.LatencyProtect)
+	require.Empty(t, cfg.LatencyDrop)
+
+	cfg = c.Outputs[1].Config
+	require.Equal(t, 30*time.Second, cfg.LatencyBudget)
+	require.Equal(t, "drop", cfg.LatencyShedding)
+	require.Equal(t, 10*time.Second, cfg.LatencyWindow)
+	require.Equal(t, []string{"cpu", "mem*"}, cfg.LatencyProtect)
+	require.Equal(t, []string{"debug_*"}, cfg.LatencyDrop)
+}
+
 func TestGetDefaultConfigPathFromEnvURL(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
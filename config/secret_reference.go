@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/toml/ast"
+)
+
+// SecretReference is a reference to a secret of a secret-store found in the
+// configuration of a plugin
+type SecretReference struct {
+	Plugin string `json:"plugin"`
+	Alias  string `json:"alias,omitempty"`
+	Option string `json:"option"`
+	Source string `json:"source"`
+	Line   int    `json:"line"`
+	Store  string `json:"store"`
+	Key    string `json:"key"`
+}
+
+// Reference returns the reference in the notation used in the configuration
+func (r *SecretReference) Reference() string {
+	return "@{" + r.Store + ":" + r.Key + "}"
+}
+
+// SecretReferences returns all references to secrets found in the plugin
+// configurations of the loaded sources independent of any plugin filters
+func (c *Config) SecretReferences() ([]SecretReference, error) {
+	var refs []SecretReference
+	for _, src := range c.Sources {
+		tbl, err := parseConfig(src.Data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %q failed: %w", src.Path, err)
+		}
+
+		var found []SecretReference
+		for category, val := range tbl.Fields {
+			subTable, ok := val.(*ast.Table)
+			if !ok {
+				continue
+			}
+
+			switch category {
+			case "agent", "global_tags", "tags":
+			case "inputs", "plugins", "outputs", "processors", "aggregators", "secretstores":
+				if category == "plugins" {
+					category = "inputs"
+				}
+				for name, pluginVal := range subTable.Fields {
+					switch pluginTable := pluginVal.(type) {
+					case *ast.Table:
+						found = append(found, findSecretReferences(category+"."+name, src.Path, pluginTable)...)
+					case []*ast.Table:
+						for _, t := range pluginTable {
+							found = append(found, findSecretReferences(category+"."+name, src.Path, t)...)
+						}
+					}
+				}
+			default:
+				// Legacy input definitions without category
+				found = append(found, findSecretReferences("inputs."+category, src.Path, subTable)...)
+			}
+		}
+
+		// Keep the order of the references within the file
+		sort.SliceStable(found, func(i, j int) bool {
+			if found[i].Line != found[j].Line {
+				return found[i].Line < found[j].Line
+			}
+			return found[i].Option < found[j].Option
+		})
+		refs = append(refs, found...)
+	}
+
+	return refs, nil
+}
+
+func findSecretReferences(plugin, source string, table *ast.Table) []SecretReference {
+	var alias string
+	if kv, ok := table.Fields["alias"].(*ast.KeyValue); ok {
+		if s, ok := kv.Value.(*ast.String); ok {
+			alias = s.Value
+		}
+	}
+	// Secret-stores are identified by their ID
+	if kv, ok := table.Fields["id"].(*ast.KeyValue); ok && alias == "" {
+		if s, ok := kv.Value.(*ast.String); ok {
+			alias = s.Value
+		}
+	}
+
+	var refs []SecretReference
+	var walk func(prefix string, t *ast.Table)
+	walk = func(prefix string, t *ast.Table) {
+		for k, value := range t.Fields {
+			switch v := value.(type) {
+			case *ast.KeyValue:
+				for _, ref := range secretPattern.FindAllString(v.Value.Source(), -1) {
+					store, key := splitLink(ref)
+					refs = append(refs, SecretReference{
+						Plugin: plugin,
+						Alias:  alias,
+						Option: prefix + k,
+						Source: source,
+						Line:   v.Line,
+						Store:  store,
+						Key:    key,
+					})
+				}
+			case *ast.Table:
+				walk(prefix+k+".", v)
+			case []*ast.Table:
+				for _, sub := range v {
+					walk(prefix+k+".", sub)
+				}
+			}
+		}
+	}
+	walk("", table)
+
+	return refs
+}
@@ -843,3 +843,74 @@ func init() {
 		return &MockupSecretStore{}
 	})
 }
+
+func TestSecretReferences(t *testing.T) {
+	cfg := []byte(`
+[[inputs.mockup]]
+  alias = "first"
+  secret = "@{mock:secret1}"
+[[inputs.mockup]]
+  secret = "no reference"
+
+[[outputs.mockup]]
+  secret = "user @{mock:user} with @{other:password}"
+  [outputs.mockup.headers]
+    Authorization = "@{mock:token}"
+
+[[secretstores.mockup]]
+  id = "other"
+  password = "@{mock:master}"
+`)
+
+	c := NewConfig()
+	c.Sources = append(c.Sources, Source{Path: "telegraf.conf", Data: cfg})
+	refs, err := c.SecretReferences()
+	require.NoError(t, err)
+
+	expected := []SecretReference{
+		{
+			Plugin: "inputs.mockup",
+			Alias:  "first",
+			Option: "secret",
+			Source: "telegraf.conf",
+			Line:   4,
+			Store:  "mock",
+			Key:    "secret1",
+		},
+		{
+			Plugin: "outputs.mockup",
+			Option: "secret",
+			Source: "telegraf.conf",
+			Line:   9,
+			Store:  "mock",
+			Key:    "user",
+		},
+		{
+			Plugin: "outputs.mockup",
+			Option: "secret",
+			Source: "telegraf.conf",
+			Line:   9,
+			Store:  "other",
+			Key:    "password",
+		},
+		{
+			Plugin: "outputs.mockup",
+			Option: "headers.Authorization",
+			Source: "telegraf.conf",
+			Line:   11,
+			Store:  "mock",
+			Key:    "token",
+		},
+		{
+			Plugin: "secretstores.mockup",
+			Alias:  "other",
+			Option: "password",
+			Source: "telegraf.conf",
+			Line:   15,
+			Store:  "mock",
+			Key:    "master",
+		},
+	}
+	require.Equal(t, expected, refs)
+	require.Equal(t, "@{mock:secret1}", refs[0].Reference())
+}
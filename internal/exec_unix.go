@@ -17,6 +17,14 @@ const KillGrace = 5 * time.Second
 // It assumes the command has already been started.
 // If the command times out, it attempts to kill the process.
 func WaitTimeout(c *exec.Cmd, timeout time.Duration) error {
+	return WaitTimeoutGrace(c, timeout, KillGrace)
+}
+
+// WaitTimeoutGrace waits for the given command to finish with a timeout.
+// It assumes the command has already been started.
+// If the command times out, it sends SIGTERM to the process and kills it if
+// it did not terminate within the given grace period.
+func WaitTimeoutGrace(c *exec.Cmd, timeout, grace time.Duration) error {
 	var kill *time.Timer
 	term := time.AfterFunc(timeout, func() {
 		err := syscall.Kill(-c.Process.Pid, syscall.SIGTERM)
@@ -29,7 +37,7 @@ func WaitTimeout(c *exec.Cmd, timeout time.Duration) error {
 			return
 		}
 
-		kill = time.AfterFunc(KillGrace, func() {
+		kill = time.AfterFunc(grace, func() {
 			err := syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
 			if err != nil {
 				log.Printf("E! [agent] Error terminating process children: %s", err)
@@ -12,6 +12,14 @@ import (
 // It assumes the command has already been started.
 // If the command times out, it attempts to kill the process.
 func WaitTimeout(c *exec.Cmd, timeout time.Duration) error {
+	return WaitTimeoutGrace(c, timeout, 0)
+}
+
+// WaitTimeoutGrace waits for the given command to finish with a timeout.
+// It assumes the command has already been started.
+// If the command times out, it kills the process immediately as graceful
+// termination is not supported on Windows.
+func WaitTimeoutGrace(c *exec.Cmd, timeout, _ time.Duration) error {
 	timer := time.AfterFunc(timeout, func() {
 		err := c.Process.Kill()
 		if err != nil {
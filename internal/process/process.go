@@ -7,11 +7,14 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/sandbox"
 )
 
 // Process is a long-running process manager that will restart processes if they stop.
@@ -24,6 +27,9 @@ type Process struct {
 	ReadStderrFn func(io.Reader)
 	RestartDelay time.Duration
 	StopOnError  bool
+	StopTimeout  time.Duration
+	Secrets      map[string]*config.Secret
+	Sandbox      *sandbox.Config
 	Log          telegraf.Logger
 
 	name       string
@@ -44,6 +50,7 @@ func New(command, envs []string) (*Process, error) {
 
 	p := &Process{
 		RestartDelay: 5 * time.Second,
+		StopTimeout:  5 * time.Second,
 		name:         command[0],
 		args:         make([]string, 0),
 		envs:         envs,
@@ -105,11 +112,15 @@ func (p *Process) State() (state *os.ProcessState, running bool) {
 func (p *Process) cmdStart() error {
 	p.Cmd = exec.Command(p.name, p.args...)
 
-	if len(p.envs) > 0 {
+	secretEnvs, err := ResolveEnvironment(p.Secrets)
+	if err != nil {
+		return err
+	}
+	if len(p.envs) > 0 || len(secretEnvs) > 0 {
 		p.Cmd.Env = append(os.Environ(), p.envs...)
+		p.Cmd.Env = append(p.Cmd.Env, secretEnvs...)
 	}
 
-	var err error
 	p.Stdin, err = p.Cmd.StdinPipe()
 	if err != nil {
 		return fmt.Errorf("error opening stdin pipe: %w", err)
@@ -127,7 +138,12 @@ func (p *Process) cmdStart() error {
 
 	p.Log.Infof("Starting process: %s %s", p.name, p.args)
 
-	if err := p.Cmd.Start(); err != nil {
+	if p.Sandbox != nil {
+		err = p.Sandbox.StartProcess(p.Cmd)
+	} else {
+		err = p.Cmd.Start()
+	}
+	if err != nil {
 		return fmt.Errorf("error starting process: %w", err)
 	}
 	atomic.StoreInt32(&p.pid, int32(p.Cmd.Process.Pid))
@@ -191,7 +207,7 @@ func (p *Process) cmdWait(ctx context.Context) error {
 	go func() {
 		select {
 		case <-ctx.Done():
-			p.gracefulStop(processCtx, p.Cmd, 5*time.Second)
+			p.gracefulStop(processCtx, p.Cmd, p.StopTimeout)
 		case <-processCtx.Done():
 		}
 		wg.Done()
@@ -205,6 +221,25 @@ func (p *Process) cmdWait(ctx context.Context) error {
 	return err
 }
 
+// ResolveEnvironment returns the given secrets as "key=value" pairs to be
+// passed as environment variables to a process
+func ResolveEnvironment(secrets map[string]*config.Secret) ([]string, error) {
+	envs := make([]string, 0, len(secrets))
+	for key, secret := range secrets {
+		if secret == nil {
+			continue
+		}
+		value, err := secret.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting secret for environment variable %q failed: %w", key, err)
+		}
+		envs = append(envs, key+"="+value.String())
+		value.Destroy()
+	}
+	sort.Strings(envs)
+	return envs, nil
+}
+
 func isQuitting(ctx context.Context) bool {
 	return ctx.Err() != nil
 }
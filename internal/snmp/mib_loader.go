@@ -2,9 +2,12 @@ package snmp
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/sleepinggenius2/gosmi"
 
@@ -89,6 +92,71 @@ func LoadMibsFromPath(paths []string, log telegraf.Logger, loader MibLoader) err
 	return nil
 }
 
+// ReloadMibsFromPath resets the MIB modules loaded into gosmi and loads all
+// modules found in the given folders again. As the gosmi state is global, all
+// paths loaded previously are reloaded as well.
+func ReloadMibsFromPath(paths []string, log telegraf.Logger, loader MibLoader) error {
+	once.Do(gosmi.Init)
+
+	m.Lock()
+	loaded := make([]string, 0, len(cache)+len(paths))
+	for p := range cache {
+		loaded = append(loaded, p)
+	}
+	cache = make(map[string]bool)
+	gosmi.Exit()
+	gosmi.Init()
+	m.Unlock()
+
+	slices.Sort(loaded)
+	for _, p := range paths {
+		if !slices.Contains(loaded, p) {
+			loaded = append(loaded, p)
+		}
+	}
+	return LoadMibsFromPath(loaded, log, loader)
+}
+
+// MibsModTime returns the latest modification time of all files and folders
+// found in the given paths. Adding, removing or modifying MIB files will
+// change the returned time. Non-existing paths are ignored.
+func MibsModTime(paths []string) (time.Time, error) {
+	var latest time.Time
+	for _, mibPath := range paths {
+		err := filepath.WalkDir(mibPath, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			// Use the target's info for symbolic links, dangling links are ignored
+			info, statErr := os.Stat(path)
+			if statErr != nil {
+				return nil
+			}
+			if info.ModTime().After(latest) {
+				latest = info.ModTime()
+			}
+			// Symbolic links to folders are not followed by the walk, so
+			// check the files in the target folder as well
+			if d.Type()&fs.ModeSymlink != 0 && info.IsDir() {
+				entries, _ := os.ReadDir(path)
+				for _, entry := range entries {
+					if info, err := entry.Info(); err == nil && info.ModTime().After(latest) {
+						latest = info.ModTime()
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return latest, fmt.Errorf("couldn't walk path %q: %w", mibPath, err)
+		}
+	}
+	return latest, nil
+}
+
 // should walk the paths given and find all folders
 func walkPaths(paths []string, log telegraf.Logger) ([]string, error) {
 	once.Do(gosmi.Init)
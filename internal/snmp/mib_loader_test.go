@@ -1,9 +1,11 @@
 package snmp
 
 import (
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -78,6 +80,47 @@ func TestMissingMibPath(t *testing.T) {
 	require.NoError(t, LoadMibsFromPath(path, log, &GosmiMibLoader{}))
 }
 
+func TestMibsModTime(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	require.NoError(t, os.Mkdir(sub, 0750))
+
+	initial := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(sub, initial, initial))
+	require.NoError(t, os.Chtimes(dir, initial, initial))
+
+	modTime, err := MibsModTime([]string{dir, "non-existing-directory"})
+	require.NoError(t, err)
+	require.Equal(t, initial, modTime)
+
+	// Adding a file must be detected
+	fn := filepath.Join(sub, "TEST-MIB")
+	require.NoError(t, os.WriteFile(fn, []byte("TEST-MIB DEFINITIONS ::= BEGIN\nEND\n"), 0600))
+	modified := initial.Add(time.Minute)
+	require.NoError(t, os.Chtimes(sub, initial, initial))
+	require.NoError(t, os.Chtimes(fn, modified, modified))
+
+	modTime, err = MibsModTime([]string{dir})
+	require.NoError(t, err)
+	require.Equal(t, modified, modTime)
+}
+
+func TestReloadMibsFromPath(t *testing.T) {
+	testDataPath, err := filepath.Abs("testdata/gosmi")
+	require.NoError(t, err)
+
+	require.NoError(t, LoadMibsFromPath([]string{testDataPath}, testutil.Logger{}, &GosmiMibLoader{}))
+	_, oid, _, _, err := (&gosmiTranslator{}).SnmpTranslate("RFC1213-MIB::atPhysAddress")
+	require.NoError(t, err)
+	require.Equal(t, ".1.3.6.1.2.1.3.1.1.2", oid)
+
+	// Previously loaded paths must be available after reloading
+	require.NoError(t, ReloadMibsFromPath(nil, testutil.Logger{}, &GosmiMibLoader{}))
+	_, oid, _, _, err = (&gosmiTranslator{}).SnmpTranslate("RFC1213-MIB::atPhysAddress")
+	require.NoError(t, err)
+	require.Equal(t, ".1.3.6.1.2.1.3.1.1.2", oid)
+}
+
 func BenchmarkMibLoading(b *testing.B) {
 	log := testutil.Logger{}
 	path := []string{"testdata/gosmi"}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gosnmp/gosnmp"
@@ -118,6 +119,19 @@ func (t *Table) initBuild() error {
 
 // Build retrieves all the fields specified in the table and constructs the RTable.
 func (t Table) Build(gs Connection, walk bool) (*RTable, error) {
+	return t.BuildConcurrent([]Connection{gs}, walk, 0)
+}
+
+// BuildConcurrent retrieves all the fields specified in the table using the
+// given connections and constructs the RTable. Fields are fetched in parallel
+// with each connection serving at most one request at a time, so the number of
+// connections determines the number of concurrent walks. A non-zero timeout
+// limits the time spent on walking the subtree of each field.
+func (t Table) BuildConcurrent(conns []Connection, walk bool, timeout time.Duration) (*RTable, error) {
+	if len(conns) == 0 {
+		return nil, errors.New("no connection")
+	}
+
 	rows := make(map[string]RTableRow)
 
 	// translation table for secondary index (when performing join on two tables)
@@ -133,100 +147,51 @@ func (t Table) Build(gs Connection, walk bool) (*RTable, error) {
 		}
 	}
 
-	tagCount := 0
 	for _, f := range t.Fields {
-		if f.IsTag {
-			tagCount++
-		}
-
 		if len(f.Oid) == 0 {
 			return nil, fmt.Errorf("cannot have empty OID on field %s", f.Name)
 		}
-		var oid string
-		if f.Oid[0] == '.' {
-			oid = f.Oid
-		} else {
-			// make sure OID has "." because the BulkWalkAll results do, and the prefix needs to match
-			oid = "." + f.Oid
-		}
+	}
 
-		// ifv contains a mapping of table OID index to field value
-		ifv := make(map[string]interface{})
-
-		if !walk {
-			// This is used when fetching non-table fields. Fields configured a the top
-			// scope of the plugin.
-			// We fetch the fields directly, and add them to ifv as if the index were an
-			// empty string. This results in all the non-table fields sharing the same
-			// index, and being added on the same row.
-			if pkt, err := gs.Get([]string{oid}); err != nil {
-				if errors.Is(err, gosnmp.ErrUnknownSecurityLevel) {
-					return nil, errors.New("unknown security level (sec_level)")
-				} else if errors.Is(err, gosnmp.ErrUnknownUsername) {
-					return nil, errors.New("unknown username (sec_name)")
-				} else if errors.Is(err, gosnmp.ErrWrongDigest) {
-					return nil, errors.New("wrong digest (auth_protocol, auth_password)")
-				} else if errors.Is(err, gosnmp.ErrDecryption) {
-					return nil, errors.New("decryption error (priv_protocol, priv_password)")
-				}
-				return nil, fmt.Errorf("performing get on field %s: %w", f.Name, err)
-			} else if pkt != nil && len(pkt.Variables) > 0 && pkt.Variables[0].Type != gosnmp.NoSuchObject && pkt.Variables[0].Type != gosnmp.NoSuchInstance {
-				ent := pkt.Variables[0]
-				fv, err := f.Convert(ent)
-				if err != nil {
-					return nil, fmt.Errorf("converting %q (OID %s) for field %s: %w", ent.Value, ent.Name, f.Name, err)
-				}
-				ifv[""] = fv
+	// Fetch the values of all fields first and merge them in field order
+	// afterwards as the secondary index table must be processed first.
+	values := make([]map[string]interface{}, len(t.Fields))
+	errs := make([]error, len(t.Fields))
+	if len(conns) == 1 || len(t.Fields) < 2 {
+		for i, f := range t.Fields {
+			values[i], errs[i] = f.fetch(conns[0], walk, timeout)
+			if errs[i] != nil {
+				return nil, errs[i]
 			}
-		} else {
-			err := gs.Walk(oid, func(ent gosnmp.SnmpPDU) error {
-				if len(ent.Name) <= len(oid) || ent.Name[:len(oid)+1] != oid+"." {
-					return &walkError{} // break the walk
-				}
-
-				idx := ent.Name[len(oid):]
-				if f.OidIndexSuffix != "" {
-					if !strings.HasSuffix(idx, f.OidIndexSuffix) {
-						// this entry doesn't match our OidIndexSuffix. skip it
-						return nil
-					}
-					idx = idx[:len(idx)-len(f.OidIndexSuffix)]
-				}
-				if f.OidIndexLength != 0 {
-					i := f.OidIndexLength + 1 // leading separator
-					idx = strings.Map(func(r rune) rune {
-						if r == '.' {
-							i--
-						}
-						if i < 1 {
-							return -1
-						}
-						return r
-					}, idx)
+		}
+	} else {
+		queue := make(chan int, len(t.Fields))
+		for i := range t.Fields {
+			queue <- i
+		}
+		close(queue)
+
+		var wg sync.WaitGroup
+		for _, gs := range conns[:min(len(conns), len(t.Fields))] {
+			wg.Add(1)
+			go func(gs Connection) {
+				defer wg.Done()
+				for i := range queue {
+					values[i], errs[i] = t.Fields[i].fetch(gs, walk, timeout)
 				}
+			}(gs)
+		}
+		wg.Wait()
 
-				fv, err := f.Convert(ent)
-				if err != nil {
-					return &walkError{
-						msg: fmt.Sprintf("converting %q (OID %s) for field %s", ent.Value, ent.Name, f.Name),
-						err: err,
-					}
-				}
-				ifv[idx] = fv
-				return nil
-			})
+		for _, err := range errs {
 			if err != nil {
-				// Our callback always wraps errors in a walkError.
-				// If this error isn't a walkError, we know it's not
-				// from the callback
-				var walkErr *walkError
-				if !errors.As(err, &walkErr) {
-					return nil, fmt.Errorf("performing bulk walk for field %s: %w", f.Name, err)
-				}
+				return nil, err
 			}
 		}
+	}
 
-		for idx, v := range ifv {
+	for i, f := range t.Fields {
+		for idx, v := range values[i] {
 			if f.SecondaryIndexUse {
 				if newidx, ok := secIdxTab[idx]; ok {
 					idx = newidx
@@ -290,6 +255,103 @@ func (t Table) Build(gs Connection, walk bool) (*RTable, error) {
 	return &rt, nil
 }
 
+// fetch retrieves the values of the field and returns a mapping of table OID
+// index to field value.
+func (f *Field) fetch(gs Connection, walk bool, timeout time.Duration) (map[string]interface{}, error) {
+	var oid string
+	if f.Oid[0] == '.' {
+		oid = f.Oid
+	} else {
+		// make sure OID has "." because the BulkWalkAll results do, and the prefix needs to match
+		oid = "." + f.Oid
+	}
+
+	// ifv contains a mapping of table OID index to field value
+	ifv := make(map[string]interface{})
+
+	if !walk {
+		// This is used when fetching non-table fields. Fields configured a the top
+		// scope of the plugin.
+		// We fetch the fields directly, and add them to ifv as if the index were an
+		// empty string. This results in all the non-table fields sharing the same
+		// index, and being added on the same row.
+		if pkt, err := gs.Get([]string{oid}); err != nil {
+			if errors.Is(err, gosnmp.ErrUnknownSecurityLevel) {
+				return nil, errors.New("unknown security level (sec_level)")
+			} else if errors.Is(err, gosnmp.ErrUnknownUsername) {
+				return nil, errors.New("unknown username (sec_name)")
+			} else if errors.Is(err, gosnmp.ErrWrongDigest) {
+				return nil, errors.New("wrong digest (auth_protocol, auth_password)")
+			} else if errors.Is(err, gosnmp.ErrDecryption) {
+				return nil, errors.New("decryption error (priv_protocol, priv_password)")
+			}
+			return nil, fmt.Errorf("performing get on field %s: %w", f.Name, err)
+		} else if pkt != nil && len(pkt.Variables) > 0 && pkt.Variables[0].Type != gosnmp.NoSuchObject && pkt.Variables[0].Type != gosnmp.NoSuchInstance {
+			ent := pkt.Variables[0]
+			fv, err := f.Convert(ent)
+			if err != nil {
+				return nil, fmt.Errorf("converting %q (OID %s) for field %s: %w", ent.Value, ent.Name, f.Name, err)
+			}
+			ifv[""] = fv
+		}
+		return ifv, nil
+	}
+
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	err := gs.Walk(oid, func(ent gosnmp.SnmpPDU) error {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return fmt.Errorf("walk did not finish within %s", timeout)
+		}
+		if len(ent.Name) <= len(oid) || ent.Name[:len(oid)+1] != oid+"." {
+			return &walkError{} // break the walk
+		}
+
+		idx := ent.Name[len(oid):]
+		if f.OidIndexSuffix != "" {
+			if !strings.HasSuffix(idx, f.OidIndexSuffix) {
+				// this entry doesn't match our OidIndexSuffix. skip it
+				return nil
+			}
+			idx = idx[:len(idx)-len(f.OidIndexSuffix)]
+		}
+		if f.OidIndexLength != 0 {
+			i := f.OidIndexLength + 1 // leading separator
+			idx = strings.Map(func(r rune) rune {
+				if r == '.' {
+					i--
+				}
+				if i < 1 {
+					return -1
+				}
+				return r
+			}, idx)
+		}
+
+		fv, err := f.Convert(ent)
+		if err != nil {
+			return &walkError{
+				msg: fmt.Sprintf("converting %q (OID %s) for field %s", ent.Value, ent.Name, f.Name),
+				err: err,
+			}
+		}
+		ifv[idx] = fv
+		return nil
+	})
+	if err != nil {
+		// Our callback wraps errors in a walkError except for timeouts.
+		// If this error isn't a walkError, we know it's not
+		// from the callback or the walk timed out
+		var walkErr *walkError
+		if !errors.As(err, &walkErr) {
+			return nil, fmt.Errorf("performing bulk walk for field %s: %w", f.Name, err)
+		}
+	}
+	return ifv, nil
+}
+
 type walkError struct {
 	msg string
 	err error
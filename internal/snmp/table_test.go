@@ -1,11 +1,30 @@
 package snmp
 
 import (
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/gosnmp/gosnmp"
 	"github.com/stretchr/testify/require"
 )
 
+// countingSNMPConnection records the number of walks performed and delays
+// each returned value
+type countingSNMPConnection struct {
+	*testSNMPConnection
+	delay time.Duration
+	walks atomic.Int64
+}
+
+func (c *countingSNMPConnection) Walk(oid string, wf gosnmp.WalkFunc) error {
+	c.walks.Add(1)
+	return c.testSNMPConnection.Walk(oid, func(ent gosnmp.SnmpPDU) error {
+		time.Sleep(c.delay)
+		return wf(ent)
+	})
+}
+
 func TestTableJoin_walk(t *testing.T) {
 	tbl := Table{
 		Name:       "mytable",
@@ -244,3 +263,83 @@ func TestTableJoinNoIndexAsTag_walk(t *testing.T) {
 	require.Contains(t, tb.Rows, rtr2)
 	require.Contains(t, tb.Rows, rtr3)
 }
+
+func TestTableJoinConcurrent_walk(t *testing.T) {
+	tbl := Table{
+		Name:       "mytable",
+		IndexAsTag: true,
+		Fields: []Field{
+			{
+				Name:  "myfield1",
+				Oid:   ".1.0.0.3.1.1",
+				IsTag: true,
+			},
+			{
+				Name: "myfield2",
+				Oid:  ".1.0.0.3.1.2",
+			},
+			{
+				Name:                "myfield3",
+				Oid:                 ".1.0.0.3.1.3",
+				SecondaryIndexTable: true,
+			},
+			{
+				Name:              "myfield4",
+				Oid:               ".1.0.0.0.1.1",
+				SecondaryIndexUse: true,
+				IsTag:             true,
+			},
+			{
+				Name:              "myfield5",
+				Oid:               ".1.0.0.0.1.2",
+				SecondaryIndexUse: true,
+			},
+		},
+	}
+
+	expected, err := tbl.Build(tsc, true)
+	require.NoError(t, err)
+
+	conns := []Connection{
+		&countingSNMPConnection{testSNMPConnection: tsc, delay: time.Millisecond},
+		&countingSNMPConnection{testSNMPConnection: tsc, delay: time.Millisecond},
+		&countingSNMPConnection{testSNMPConnection: tsc, delay: time.Millisecond},
+	}
+	tb, err := tbl.BuildConcurrent(conns, true, 0)
+	require.NoError(t, err)
+	require.Equal(t, "mytable", tb.Name)
+	require.ElementsMatch(t, expected.Rows, tb.Rows)
+
+	var walks int64
+	for _, c := range conns {
+		walks += c.(*countingSNMPConnection).walks.Load()
+	}
+	require.Equal(t, int64(len(tbl.Fields)), walks)
+}
+
+func TestTableWalkTimeout(t *testing.T) {
+	tbl := Table{
+		Name: "mytable",
+		Fields: []Field{
+			{
+				Name: "myfield1",
+				Oid:  ".1.0.0.0.1.1",
+			},
+			{
+				Name: "myfield2",
+				Oid:  ".1.0.0.0.1.2",
+			},
+		},
+	}
+
+	conns := []Connection{
+		&countingSNMPConnection{testSNMPConnection: tsc, delay: 50 * time.Millisecond},
+		&countingSNMPConnection{testSNMPConnection: tsc, delay: 50 * time.Millisecond},
+	}
+	_, err := tbl.BuildConcurrent(conns, true, 10*time.Millisecond)
+	require.ErrorContains(t, err, "walk did not finish within 10ms")
+
+	tb, err := tbl.BuildConcurrent(conns, true, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, tb.Rows, 3)
+}
@@ -0,0 +1,61 @@
+package metric
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"slices"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// IdempotencyKey returns a key derived from the series, fields and timestamp
+// of the given metric. The key does not depend on the batch the metric is
+// written in so it stays the same when retrying writes, even if the batch
+// changed.
+func IdempotencyKey(m telegraf.Metric) string {
+	h := sha256.New()
+	hashMetric(h, m)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// BatchIdempotencyKey returns a key for a single message containing all of
+// the given metrics, e.g. when using a batch serialization format. The key
+// is derived from the series, fields and timestamps of the metrics in order.
+func BatchIdempotencyKey(metrics []telegraf.Metric) string {
+	h := sha256.New()
+	for _, m := range metrics {
+		hashMetric(h, m)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// hashMetric writes the name, tags, fields and timestamp of the metric to the
+// given hash. Fields are sorted by key as their order is not significant.
+func hashMetric(h hash.Hash, m telegraf.Metric) {
+	h.Write([]byte(m.Name()))
+	h.Write([]byte("\n"))
+	for _, tag := range m.TagList() {
+		h.Write([]byte(tag.Key))
+		h.Write([]byte("\n"))
+		h.Write([]byte(tag.Value))
+		h.Write([]byte("\n"))
+	}
+
+	fields := slices.Clone(m.FieldList())
+	slices.SortFunc(fields, func(a, b *telegraf.Field) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	for _, field := range fields {
+		h.Write([]byte(field.Key))
+		h.Write([]byte("\n"))
+		fmt.Fprintf(h, "%T:%v\n", field.Value, field.Value)
+	}
+
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(m.Time().UnixNano()))
+	h.Write(buf[:])
+}
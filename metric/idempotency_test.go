@@ -0,0 +1,63 @@
+package metric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	m := New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 42.0}, time.Unix(10, 0))
+	key := IdempotencyKey(m)
+	require.Len(t, key, 32)
+
+	// Identical metrics result in the same key
+	same := New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 42.0}, time.Unix(10, 0))
+	require.Equal(t, key, IdempotencyKey(same))
+
+	otherSeries := New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 42.0}, time.Unix(10, 0))
+	require.NotEqual(t, key, IdempotencyKey(otherSeries))
+
+	otherTime := New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 42.0}, time.Unix(20, 0))
+	require.NotEqual(t, key, IdempotencyKey(otherTime))
+
+	otherValue := New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 23.0}, time.Unix(10, 0))
+	require.NotEqual(t, key, IdempotencyKey(otherValue))
+}
+
+func TestIdempotencyKeyFields(t *testing.T) {
+	// Metrics of the same series and timestamp with different fields, e.g.
+	// split by inputs or processors, must not be considered duplicates
+	m1 := New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage_user": 42.0}, time.Unix(10, 0))
+	m2 := New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage_system": 42.0}, time.Unix(10, 0))
+	require.NotEqual(t, IdempotencyKey(m1), IdempotencyKey(m2))
+	require.NotEqual(t, BatchIdempotencyKey([]telegraf.Metric{m1}), BatchIdempotencyKey([]telegraf.Metric{m2}))
+
+	// The same value with a different type is different data
+	m3 := New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage_user": int64(42)}, time.Unix(10, 0))
+	require.NotEqual(t, IdempotencyKey(m1), IdempotencyKey(m3))
+
+	// The order of the fields is not significant
+	m4 := New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"a": 1.0}, time.Unix(10, 0))
+	m4.AddField("b", 2.0)
+	m5 := New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"b": 2.0}, time.Unix(10, 0))
+	m5.AddField("a", 1.0)
+	require.Equal(t, IdempotencyKey(m4), IdempotencyKey(m5))
+}
+
+func TestBatchIdempotencyKey(t *testing.T) {
+	m1 := New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 42.0}, time.Unix(10, 0))
+	m2 := New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 23.0}, time.Unix(10, 0))
+	m3 := New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(20, 0))
+
+	key := BatchIdempotencyKey([]telegraf.Metric{m1, m2})
+	require.Len(t, key, 32)
+	require.Equal(t, key, BatchIdempotencyKey([]telegraf.Metric{m1, m2}))
+
+	// A batch with a different composition is a different message
+	require.NotEqual(t, key, BatchIdempotencyKey([]telegraf.Metric{m1, m2, m3}))
+	require.NotEqual(t, key, BatchIdempotencyKey([]telegraf.Metric{m1}))
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
@@ -21,6 +22,10 @@ type Transaction struct {
 	// Batch of metrics to write
 	Batch []telegraf.Metric
 
+	// Added holds the time each metric of the batch was added to the buffer,
+	// nil if the buffer does not keep track of the time
+	Added []time.Time
+
 	// Accept denotes the indices of metrics that were successfully written
 	Accept []int
 	// Reject denotes the indices of metrics that were not written but should
@@ -92,10 +97,14 @@ type BufferStats struct {
 	MetricsDropped  selfstat.Stat
 	BufferSize      selfstat.Stat
 	BufferLimit     selfstat.Stat
+	BufferBytes     selfstat.Stat
+	BufferByteLimit selfstat.Stat
 }
 
-// NewBuffer returns a new empty Buffer with the given capacity.
-func NewBuffer(name, id, alias string, capacity int, strategy, path string) (Buffer, error) {
+// NewBuffer returns a new empty Buffer with the given capacity. The byteLimit
+// additionally restricts the approximate memory footprint of the buffered
+// metrics in bytes if non-zero and is only supported by the memory buffer.
+func NewBuffer(name, id, alias string, capacity int, byteLimit int64, strategy, path string) (Buffer, error) {
 	registerGob()
 
 	tags := map[string]string{
@@ -105,18 +114,18 @@ func NewBuffer(name, id, alias string, capacity int, strategy, path string) (Buf
 	if alias != "" {
 		tags["alias"] = alias
 	}
-	bs := NewBufferStats(tags, capacity)
+	bs := NewBufferStats(tags, capacity, byteLimit)
 
 	switch strategy {
 	case "", "memory":
-		return NewMemoryBuffer(capacity, bs)
+		return NewMemoryBuffer(capacity, byteLimit, bs)
 	case "disk_write_through":
 		return NewDiskBuffer(id, path, bs)
 	}
 	return nil, fmt.Errorf("invalid buffer strategy %q", strategy)
 }
 
-func NewBufferStats(tags map[string]string, capacity int) BufferStats {
+func NewBufferStats(tags map[string]string, capacity int, byteLimit int64) BufferStats {
 	bs := BufferStats{
 		MetricsAdded: selfstat.Register(
 			"write",
@@ -148,9 +157,21 @@ func NewBufferStats(tags map[string]string, capacity int) BufferStats {
 			"buffer_limit",
 			tags,
 		),
+		BufferBytes: selfstat.Register(
+			"write",
+			"buffer_bytes",
+			tags,
+		),
+		BufferByteLimit: selfstat.Register(
+			"write",
+			"buffer_limit_bytes",
+			tags,
+		),
 	}
 	bs.BufferSize.Set(int64(0))
 	bs.BufferLimit.Set(int64(capacity))
+	bs.BufferBytes.Set(int64(0))
+	bs.BufferByteLimit.Set(byteLimit)
 	return bs
 }
 
@@ -175,3 +196,29 @@ func (b *BufferStats) metricDropped(m telegraf.Metric) {
 	b.MetricsDropped.Incr(1)
 	m.Reject()
 }
+
+// metricSize returns the approximate memory footprint of the metric in bytes.
+// The estimate accounts for the string data as well as the overhead of the
+// internal metric structures on 64-bit systems.
+func metricSize(m telegraf.Metric) int64 {
+	// Metric structure including the time, the slice headers and the name
+	size := int64(96 + len(m.Name()))
+
+	// Tags consist of a pointer and two strings
+	for _, tag := range m.TagList() {
+		size += int64(40 + len(tag.Key) + len(tag.Value))
+	}
+
+	// Fields consist of a pointer, a string and an interface plus the
+	// referenced value
+	for _, field := range m.FieldList() {
+		size += int64(40 + len(field.Key))
+		if v, ok := field.Value.(string); ok {
+			size += int64(16 + len(v))
+		} else {
+			size += 8
+		}
+	}
+
+	return size
+}
@@ -18,7 +18,7 @@ import (
 // https://github.com/influxdata/telegraf/issues/16696
 func TestDiskBufferTruncate(t *testing.T) {
 	// Create a disk buffer
-	buf, err := NewBuffer("test", "id123", "", 0, "disk_write_through", t.TempDir())
+	buf, err := NewBuffer("test", "id123", "", 0, 0, "disk_write_through", t.TempDir())
 	require.NoError(t, err)
 	defer buf.Close()
 	diskBuf, ok := buf.(*DiskBuffer)
@@ -78,7 +78,7 @@ func TestDiskBufferTruncate(t *testing.T) {
 // https://github.com/influxdata/telegraf/issues/16981
 func TestDiskBufferEmptyReuse(t *testing.T) {
 	// Create a disk buffer
-	buf, err := NewBuffer("test", "id123", "", 0, "disk_write_through", t.TempDir())
+	buf, err := NewBuffer("test", "id123", "", 0, 0, "disk_write_through", t.TempDir())
 	require.NoError(t, err)
 	defer buf.Close()
 	diskBuf, ok := buf.(*DiskBuffer)
@@ -131,7 +131,7 @@ func TestDiskBufferEmptyClose(t *testing.T) {
 	tmpdir := t.TempDir()
 
 	// Create a disk buffer
-	buf, err := NewBuffer("test", "id123", "", 0, "disk_write_through", tmpdir)
+	buf, err := NewBuffer("test", "id123", "", 0, 0, "disk_write_through", tmpdir)
 	require.NoError(t, err)
 	defer buf.Close()
 	diskBuf, ok := buf.(*DiskBuffer)
@@ -159,7 +159,7 @@ func TestDiskBufferEmptyClose(t *testing.T) {
 	require.NoError(t, diskBuf.Close())
 
 	// Reopen the buffer with the parameters above to see the same buffer
-	reopened, err := NewBuffer("test", "id123", "", 0, "disk_write_through", tmpdir)
+	reopened, err := NewBuffer("test", "id123", "", 0, 0, "disk_write_through", tmpdir)
 	require.NoError(t, err)
 	defer reopened.Close()
 	_, ok = reopened.(*DiskBuffer)
@@ -188,7 +188,7 @@ func TestDiskBufferRetainsTrackingInformation(t *testing.T) {
 	var delivered int
 	mm, _ := metric.WithTracking(m, func(telegraf.DeliveryInfo) { delivered++ })
 
-	buf, err := NewBuffer("test", "123", "", 0, "disk_write_through", t.TempDir())
+	buf, err := NewBuffer("test", "123", "", 0, 0, "disk_write_through", t.TempDir())
 	require.NoError(t, err)
 	buf.Stats().MetricsAdded.Set(0)
 	buf.Stats().MetricsWritten.Set(0)
@@ -247,7 +247,7 @@ func TestDiskBufferTrackingDroppedFromOldWal(t *testing.T) {
 	walfile.Close()
 
 	// Create a buffer
-	buf, err := NewBuffer("123", "123", "", 0, "disk_write_through", path)
+	buf, err := NewBuffer("123", "123", "", 0, 0, "disk_write_through", path)
 	require.NoError(t, err)
 	buf.Stats().MetricsAdded.Set(0)
 	buf.Stats().MetricsWritten.Set(0)
@@ -302,7 +302,7 @@ func TestDiskBufferTrackingOnOutputOutage(t *testing.T) {
 	}
 
 	// Create a disk buffer
-	buf, err := NewBuffer("test", "id123", "", 0, "disk_write_through", t.TempDir())
+	buf, err := NewBuffer("test", "id123", "", 0, 0, "disk_write_through", t.TempDir())
 	require.NoError(t, err)
 	defer buf.Close()
 	diskBuf, ok := buf.(*DiskBuffer)
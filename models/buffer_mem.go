@@ -2,6 +2,7 @@ package models
 
 import (
 	"sync"
+	"time"
 
 	"github.com/influxdata/telegraf"
 )
@@ -12,20 +13,33 @@ type MemoryBuffer struct {
 	BufferStats
 
 	buf   []telegraf.Metric
-	first int // index of the first/oldest metric
-	last  int // one after the index of the last/newest metric
-	size  int // number of metrics currently in the buffer
-	cap   int // the capacity of the buffer
-
-	batchFirst int // index of the first metric in the batch
-	batchSize  int // number of metrics currently in the batch
+	sizes []int64     // approximate size of the metrics in bytes
+	added []time.Time // time the metrics were added to the buffer
+	first int         // index of the first/oldest metric
+	last  int         // one after the index of the last/newest metric
+	size  int         // number of metrics currently in the buffer
+	cap   int         // the capacity of the buffer
+
+	bytes     int64 // approximate size of the metrics in the buffer
+	byteLimit int64 // the capacity of the buffer in bytes, zero means unlimited
+
+	batchFirst int   // index of the first metric in the batch
+	batchSize  int   // number of metrics currently in the batch
+	batchBytes int64 // approximate size of the metrics in the batch
+	batchAdded []time.Time
+
+	now func() time.Time
 }
 
-func NewMemoryBuffer(capacity int, stats BufferStats) (*MemoryBuffer, error) {
+func NewMemoryBuffer(capacity int, byteLimit int64, stats BufferStats) (*MemoryBuffer, error) {
 	return &MemoryBuffer{
 		BufferStats: stats,
 		buf:         make([]telegraf.Metric, capacity),
+		sizes:       make([]int64, capacity),
+		added:       make([]time.Time, capacity),
 		cap:         capacity,
+		byteLimit:   byteLimit,
+		now:         time.Now,
 	}, nil
 }
 
@@ -47,7 +61,7 @@ func (b *MemoryBuffer) Add(metrics ...telegraf.Metric) int {
 		}
 	}
 
-	b.BufferSize.Set(int64(b.length()))
+	b.updateSize()
 	return dropped
 }
 
@@ -64,15 +78,21 @@ func (b *MemoryBuffer) BeginTransaction(batchSize int) *Transaction {
 	b.batchSize = outLen
 	batchIndex := b.batchFirst
 	batch := make([]telegraf.Metric, outLen)
+	b.batchAdded = make([]time.Time, outLen)
 	for i := range batch {
 		batch[i] = b.buf[batchIndex]
+		b.batchAdded[i] = b.added[batchIndex]
 		b.buf[batchIndex] = nil
+		b.bytes -= b.sizes[batchIndex]
+		b.batchBytes += b.sizes[batchIndex]
+		b.sizes[batchIndex] = 0
+		b.added[batchIndex] = time.Time{}
 		batchIndex = b.next(batchIndex)
 	}
 
 	b.first = b.nextby(b.first, b.batchSize)
 	b.size -= outLen
-	return &Transaction{Batch: batch, valid: true}
+	return &Transaction{Batch: batch, Added: b.batchAdded, valid: true}
 }
 
 func (b *MemoryBuffer) EndTransaction(tx *Transaction) {
@@ -98,14 +118,33 @@ func (b *MemoryBuffer) EndTransaction(tx *Transaction) {
 	// Keep metrics
 	keep := tx.InferKeep()
 	if len(keep) > 0 {
+		// Determine the number of metrics fitting into the buffer with
+		// respect to both, the number of metrics and their size
 		restore := min(len(keep), b.cap-b.size)
+		sizes := make([]int64, 0, restore)
+		var restoreBytes int64
+		for i := 0; i < restore; i++ {
+			size := metricSize(tx.Batch[keep[i]])
+			if b.byteLimit > 0 && b.bytes+restoreBytes+size > b.byteLimit {
+				restore = i
+				break
+			}
+			sizes = append(sizes, size)
+			restoreBytes += size
+		}
 		b.first = b.prevby(b.first, restore)
 		b.size = min(b.size+restore, b.cap)
+		b.bytes += restoreBytes
 
-		// Restore the metrics that fit into the buffer
+		// Restore the metrics that fit into the buffer keeping the time
+		// they were originally added
 		current := b.first
 		for i := 0; i < restore; i++ {
 			b.buf[current] = tx.Batch[keep[i]]
+			b.sizes[current] = sizes[i]
+			if keep[i] < len(b.batchAdded) {
+				b.added[current] = b.batchAdded[keep[i]]
+			}
 			current = b.next(current)
 		}
 
@@ -116,7 +155,69 @@ func (b *MemoryBuffer) EndTransaction(tx *Transaction) {
 	}
 
 	b.resetBatch()
-	b.BufferSize.Set(int64(b.length()))
+	b.updateSize()
+}
+
+// Shed removes the metrics selected by the given function from the buffer and
+// returns them in their original order. The selector is called with all
+// buffered metrics, not including the ones of an ongoing transaction, ordered
+// from oldest to newest together with the time each metric was added to the
+// buffer and returns the indices of the metrics to remove.
+// The caller takes ownership of the removed metrics.
+func (b *MemoryBuffer) Shed(selector func([]telegraf.Metric, []time.Time) []int) []telegraf.Metric {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.size == 0 {
+		return nil
+	}
+
+	metrics := make([]telegraf.Metric, 0, b.size)
+	sizes := make([]int64, 0, b.size)
+	added := make([]time.Time, 0, b.size)
+	current := b.first
+	for range b.size {
+		metrics = append(metrics, b.buf[current])
+		sizes = append(sizes, b.sizes[current])
+		added = append(added, b.added[current])
+		current = b.next(current)
+	}
+
+	indices := selector(metrics, added)
+	if len(indices) == 0 {
+		return nil
+	}
+	remove := make([]bool, len(metrics))
+	for _, idx := range indices {
+		remove[idx] = true
+	}
+
+	// Compact the remaining metrics towards the oldest position and clear the
+	// slots freed up
+	removed := make([]telegraf.Metric, 0, len(indices))
+	current = b.first
+	for i, m := range metrics {
+		if remove[i] {
+			removed = append(removed, m)
+			b.bytes -= sizes[i]
+			continue
+		}
+		b.buf[current] = m
+		b.sizes[current] = sizes[i]
+		b.added[current] = added[i]
+		current = b.next(current)
+	}
+	b.last = current
+	for range removed {
+		b.buf[current] = nil
+		b.sizes[current] = 0
+		b.added[current] = time.Time{}
+		current = b.next(current)
+	}
+	b.size -= len(removed)
+
+	b.updateSize()
+	return removed
 }
 
 func (*MemoryBuffer) Close() error {
@@ -136,6 +237,7 @@ func (b *MemoryBuffer) addMetric(m telegraf.Metric) int {
 	// Check if Buffer is full
 	if b.size == b.cap {
 		b.metricDropped(b.buf[b.last])
+		b.bytes -= b.sizes[b.last]
 		dropped++
 
 		if b.batchSize > 0 {
@@ -146,7 +248,11 @@ func (b *MemoryBuffer) addMetric(m telegraf.Metric) int {
 
 	b.metricAdded()
 
+	size := metricSize(m)
 	b.buf[b.last] = m
+	b.sizes[b.last] = size
+	b.added[b.last] = b.now()
+	b.bytes += size
 	b.last = b.next(b.last)
 
 	if b.size == b.cap {
@@ -154,6 +260,20 @@ func (b *MemoryBuffer) addMetric(m telegraf.Metric) int {
 	}
 
 	b.size = min(b.size+1, b.cap)
+
+	// Drop the oldest metrics until the buffer fits into the byte limit but
+	// always keep the newly added metric
+	for b.byteLimit > 0 && b.bytes > b.byteLimit && b.size > 1 {
+		b.metricDropped(b.buf[b.first])
+		b.bytes -= b.sizes[b.first]
+		b.buf[b.first] = nil
+		b.sizes[b.first] = 0
+		b.added[b.first] = time.Time{}
+		b.first = b.next(b.first)
+		b.size--
+		dropped++
+	}
+
 	return dropped
 }
 
@@ -187,4 +307,11 @@ func (b *MemoryBuffer) prevby(index, count int) int {
 func (b *MemoryBuffer) resetBatch() {
 	b.batchFirst = 0
 	b.batchSize = 0
+	b.batchBytes = 0
+	b.batchAdded = nil
+}
+
+func (b *MemoryBuffer) updateSize() {
+	b.BufferSize.Set(int64(b.length()))
+	b.BufferBytes.Set(b.bytes + b.batchBytes)
 }
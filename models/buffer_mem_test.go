@@ -6,11 +6,12 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
 )
 
 func TestMemoryBufferAcceptCallsMetricAccept(t *testing.T) {
-	buf, err := NewBuffer("test", "123", "", 5, "memory", "")
+	buf, err := NewBuffer("test", "123", "", 5, 0, "memory", "")
 	require.NoError(t, err)
 	buf.Stats().MetricsAdded.Set(0)
 	buf.Stats().MetricsWritten.Set(0)
@@ -32,7 +33,7 @@ func TestMemoryBufferAcceptCallsMetricAccept(t *testing.T) {
 }
 
 func BenchmarkMemoryBufferAddMetrics(b *testing.B) {
-	buf, err := NewBuffer("test", "123", "", 10000, "memory", "")
+	buf, err := NewBuffer("test", "123", "", 10000, 0, "memory", "")
 	require.NoError(b, err)
 	buf.Stats().MetricsAdded.Set(0)
 	buf.Stats().MetricsWritten.Set(0)
@@ -44,3 +45,113 @@ func BenchmarkMemoryBufferAddMetrics(b *testing.B) {
 		buf.Add(m)
 	}
 }
+
+func TestMemoryBufferByteLimit(t *testing.T) {
+	// Each metric has an approximate size of 152 bytes so only two metrics
+	// fit into the buffer
+	buf, err := NewBuffer("test", "123", "", 5, 400, "memory", "")
+	require.NoError(t, err)
+	buf.Stats().MetricsAdded.Set(0)
+	buf.Stats().MetricsWritten.Set(0)
+	buf.Stats().MetricsDropped.Set(0)
+	defer buf.Close()
+
+	metrics := make([]telegraf.Metric, 0, 5)
+	for i := range 5 {
+		metrics = append(metrics, metric.New("cpu", map[string]string{}, map[string]interface{}{"value": float64(i)}, time.Unix(0, 0)))
+	}
+	require.Equal(t, int64(152), metricSize(metrics[0]))
+
+	// The oldest metrics must be dropped to fit into the limit
+	require.Equal(t, 3, buf.Add(metrics...))
+	require.Equal(t, 2, buf.Len())
+	require.Equal(t, int64(304), buf.Stats().BufferBytes.Get())
+	require.Equal(t, int64(400), buf.Stats().BufferByteLimit.Get())
+	require.Equal(t, int64(3), buf.Stats().MetricsDropped.Get())
+
+	// Metrics in flight are accounted for in the stats but not for the limit
+	tx := buf.BeginTransaction(2)
+	require.Equal(t, metrics[3:], tx.Batch)
+	require.Zero(t, buf.Add(metrics[0]))
+	require.Equal(t, int64(456), buf.Stats().BufferBytes.Get())
+
+	// Only the oldest metric of the batch fits into the buffer when keeping
+	// the metrics
+	tx.KeepAll()
+	buf.EndTransaction(tx)
+	require.Equal(t, 2, buf.Len())
+	require.Equal(t, int64(304), buf.Stats().BufferBytes.Get())
+	require.Equal(t, int64(4), buf.Stats().MetricsDropped.Get())
+
+	tx = buf.BeginTransaction(5)
+	require.Equal(t, []telegraf.Metric{metrics[3], metrics[0]}, tx.Batch)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.Zero(t, buf.Len())
+	require.Zero(t, buf.Stats().BufferBytes.Get())
+}
+
+func TestMemoryBufferShed(t *testing.T) {
+	buf, err := NewBuffer("test", "123", "", 5, 0, "memory", "")
+	require.NoError(t, err)
+	buf.Stats().MetricsAdded.Set(0)
+	buf.Stats().MetricsWritten.Set(0)
+	buf.Stats().MetricsDropped.Set(0)
+	defer buf.Close()
+	mbuf, ok := buf.(*MemoryBuffer)
+	require.True(t, ok)
+
+	// Use a clock advancing by one second for each added metric
+	clock := time.Unix(100, 0)
+	mbuf.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	metrics := make([]telegraf.Metric, 0, 7)
+	for i := range 7 {
+		metrics = append(metrics, metric.New("cpu", map[string]string{}, map[string]interface{}{"value": float64(i)}, time.Unix(0, 0)))
+	}
+
+	// Wrap around the circular buffer and start a transaction to make sure
+	// metrics in flight are not passed to the selector
+	buf.Add(metrics...)
+	tx := buf.BeginTransaction(1)
+	require.Equal(t, metrics[2:3], tx.Batch)
+
+	var seen []telegraf.Metric
+	var seenAdded []time.Time
+	removed := mbuf.Shed(func(ms []telegraf.Metric, added []time.Time) []int {
+		seen = ms
+		seenAdded = added
+		return []int{0, 2}
+	})
+	require.Equal(t, metrics[3:], seen)
+	require.Equal(t, []time.Time{time.Unix(104, 0), time.Unix(105, 0), time.Unix(106, 0), time.Unix(107, 0)}, seenAdded)
+	require.Equal(t, []telegraf.Metric{metrics[3], metrics[5]}, removed)
+	require.Equal(t, 3, buf.Len())
+	require.Equal(t, int64(3*152), buf.Stats().BufferBytes.Get())
+
+	// Restoring the batch must keep the order
+	tx.KeepAll()
+	buf.EndTransaction(tx)
+
+	// Restored metrics must keep the time they were added originally
+	require.Empty(t, mbuf.Shed(func(_ []telegraf.Metric, added []time.Time) []int {
+		seenAdded = added
+		return nil
+	}))
+	require.Equal(t, []time.Time{time.Unix(103, 0), time.Unix(105, 0), time.Unix(107, 0)}, seenAdded)
+
+	buf.Add(metrics[0])
+	tx = buf.BeginTransaction(5)
+	require.Equal(t, []telegraf.Metric{metrics[2], metrics[4], metrics[6], metrics[0]}, tx.Batch)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.Zero(t, buf.Len())
+
+	// Nothing selected must not modify the buffer
+	buf.Add(metrics[1])
+	require.Empty(t, mbuf.Shed(func([]telegraf.Metric, []time.Time) []int { return nil }))
+	require.Equal(t, 1, buf.Len())
+}
@@ -1,6 +1,10 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
 	"sync"
 	"time"
 
@@ -18,6 +22,22 @@ type RunningAggregator struct {
 	periodEnd   time.Time
 	log         telegraf.Logger
 
+	// Event-time windowing state with the buffered metrics per window start
+	// (in unix nanoseconds), the number of buffered metrics, the latest
+	// metric time seen, the time until which windows were already emitted
+	// and the timestamp for the metrics created during push of a window.
+	windows      map[int64][]telegraf.Metric
+	buffered     int
+	bufferFull   bool
+	maxEventTime time.Time
+	emittedUntil time.Time
+	windowTime   time.Time
+
+	// Aggregation period of a restored plugin state if that period is not
+	// the current one and the state still needs to be pushed.
+	restoredStart time.Time
+	restoredEnd   time.Time
+
 	MetricsPushed   selfstat.Stat
 	MetricsFiltered selfstat.Stat
 	MetricsDropped  selfstat.Stat
@@ -66,7 +86,8 @@ func NewRunningAggregator(aggregator telegraf.Aggregator, config *AggregatorConf
 			"push_time_ns",
 			tags,
 		),
-		log: logger,
+		log:     logger,
+		windows: make(map[int64][]telegraf.Metric),
 	}
 }
 
@@ -82,6 +103,17 @@ type AggregatorConfig struct {
 	Grace        time.Duration
 	LogLevel     string
 
+	// WindowMode is either "arrival" to aggregate metrics into the current
+	// wall-clock period or "event" to aggregate metrics into the period
+	// their timestamp belongs to. In event mode, windows are emitted after
+	// the latest metric time minus the AllowedLateness passed the window end.
+	// At most MaxBufferedMetrics metrics are buffered over all open windows
+	// in event mode, metrics exceeding the limit are dropped. A value of
+	// zero disables the limit.
+	WindowMode         string
+	AllowedLateness    time.Duration
+	MaxBufferedMetrics int
+
 	NameOverride      string
 	MeasurementPrefix string
 	MeasurementSuffix string
@@ -133,6 +165,11 @@ func (r *RunningAggregator) MakeMetric(telegrafMetric telegraf.Metric) telegraf.
 		r.Config.Tags,
 		nil)
 
+	// Use the window end as timestamp for metrics of event-time windows
+	if !r.windowTime.IsZero() {
+		m.SetTime(r.windowTime)
+	}
+
 	r.MetricsPushed.Incr(1)
 
 	return m
@@ -163,6 +200,11 @@ func (r *RunningAggregator) Add(m telegraf.Metric) bool {
 	r.Lock()
 	defer r.Unlock()
 
+	if r.Config.WindowMode == "event" {
+		r.addEvent(m)
+		return r.Config.DropOriginal
+	}
+
 	if m.Time().Before(r.periodStart.Add(-r.Config.Grace)) || m.Time().After(r.periodEnd.Add(r.Config.Delay)) {
 		r.log.Debugf("Metric is outside aggregation window; discarding. %s: m: %s e: %s g: %s",
 			m.Time(), r.periodStart, r.periodEnd, r.Config.Grace)
@@ -193,6 +235,11 @@ func (r *RunningAggregator) Push(acc telegraf.Accumulator) {
 
 	r.UpdateWindow(since, until)
 
+	if r.Config.WindowMode == "event" {
+		r.pushEvents(acc, r.maxEventTime.Add(-r.Config.AllowedLateness))
+		return
+	}
+
 	start := time.Now()
 	r.Aggregator.Push(acc)
 	elapsed := time.Since(start)
@@ -200,6 +247,190 @@ func (r *RunningAggregator) Push(acc telegraf.Accumulator) {
 	r.Aggregator.Reset()
 }
 
+// PushAll pushes the aggregates of all windows including the ones not yet
+// complete e.g. when shutting down.
+func (r *RunningAggregator) PushAll(acc telegraf.Accumulator) {
+	if r.Config.WindowMode != "event" {
+		r.Push(acc)
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	var watermark time.Time
+	for start := range r.windows {
+		end := time.Unix(0, start).Add(r.Config.Period)
+		if end.After(watermark) {
+			watermark = end
+		}
+	}
+	r.pushEvents(acc, watermark)
+}
+
+// addEvent adds the metric to the event-time window its timestamp belongs to
+func (r *RunningAggregator) addEvent(m telegraf.Metric) {
+	ts := m.Time()
+	start := ts.Truncate(r.Config.Period)
+	if !start.Add(r.Config.Period).After(r.emittedUntil) {
+		r.log.Debugf("Metric is too late for aggregation window; discarding. %s: m: %s e: %s l: %s",
+			ts, start, r.emittedUntil, r.Config.AllowedLateness)
+		r.MetricsDropped.Incr(1)
+		return
+	}
+
+	if r.Config.MaxBufferedMetrics > 0 && r.buffered >= r.Config.MaxBufferedMetrics {
+		// Only warn once until the buffer is drained to not flood the log
+		if !r.bufferFull {
+			r.log.Warnf("Buffer limit of %d metrics reached for %d open windows; dropping metrics",
+				r.Config.MaxBufferedMetrics, len(r.windows))
+			r.bufferFull = true
+		}
+		r.MetricsDropped.Incr(1)
+		return
+	}
+
+	r.windows[start.UnixNano()] = append(r.windows[start.UnixNano()], m)
+	r.buffered++
+	if ts.After(r.maxEventTime) {
+		r.maxEventTime = ts
+	}
+}
+
+// pushEvents aggregates and pushes all event-time windows ending before or at
+// the given watermark in chronological order
+func (r *RunningAggregator) pushEvents(acc telegraf.Accumulator, watermark time.Time) {
+	starts := make([]int64, 0, len(r.windows))
+	for start := range r.windows {
+		if !time.Unix(0, start).Add(r.Config.Period).After(watermark) {
+			starts = append(starts, start)
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+
+	for _, start := range starts {
+		r.Aggregator.Reset()
+		for _, m := range r.windows[start] {
+			r.Aggregator.Add(m)
+		}
+		r.buffered -= len(r.windows[start])
+		delete(r.windows, start)
+		r.bufferFull = false
+
+		r.windowTime = time.Unix(0, start).Add(r.Config.Period)
+		begin := time.Now()
+		r.Aggregator.Push(acc)
+		r.PushTime.Incr(time.Since(begin).Nanoseconds())
+		r.Aggregator.Reset()
+	}
+	r.windowTime = time.Time{}
+
+	if watermark.After(r.emittedUntil) {
+		r.emittedUntil = watermark
+	}
+}
+
+// AggregatorState is the persisted state of a stateful aggregator containing
+// the plugin state and the aggregation period the state belongs to.
+type AggregatorState struct {
+	PeriodStart time.Time       `json:"period_start"`
+	PeriodEnd   time.Time       `json:"period_end"`
+	State       json.RawMessage `json:"state"`
+}
+
+// Stateful returns true if the aggregator plugin is able to persist the state
+// of the current aggregation period. This is not supported for event-time
+// windows as those buffer the metrics of all windows in the model.
+func (r *RunningAggregator) Stateful() bool {
+	_, ok := r.Aggregator.(telegraf.StatefulPlugin)
+	return ok && r.Config.WindowMode != "event"
+}
+
+// GetState returns the state of the aggregator plugin together with the
+// current aggregation period.
+func (r *RunningAggregator) GetState() interface{} {
+	r.Lock()
+	defer r.Unlock()
+
+	state := AggregatorState{
+		PeriodStart: r.periodStart,
+		PeriodEnd:   r.periodEnd,
+	}
+
+	plugin, ok := r.Aggregator.(telegraf.StatefulPlugin)
+	if !ok {
+		return state
+	}
+	serialized, err := json.Marshal(plugin.GetState())
+	if err != nil {
+		r.log.Errorf("Marshalling state failed: %v", err)
+		return state
+	}
+	state.State = serialized
+
+	return state
+}
+
+// SetState restores the state of the aggregator plugin. The aggregation
+// period of the state is remembered to push the state in case the period
+// elapsed while Telegraf was not running, see PushRestored.
+func (r *RunningAggregator) SetState(state interface{}) error {
+	s, ok := state.(AggregatorState)
+	if !ok {
+		return fmt.Errorf("invalid type %T for state", state)
+	}
+
+	plugin, ok := r.Aggregator.(telegraf.StatefulPlugin)
+	if !ok || len(s.State) == 0 {
+		return nil
+	}
+
+	// Use the initial state of the plugin as blueprint for unmarshalling
+	nstate := reflect.New(reflect.TypeOf(plugin.GetState())).Interface()
+	if err := json.Unmarshal(s.State, &nstate); err != nil {
+		return fmt.Errorf("unmarshalling plugin state failed: %w", err)
+	}
+	if err := plugin.SetState(reflect.ValueOf(nstate).Elem().Interface()); err != nil {
+		return err
+	}
+
+	r.Lock()
+	r.restoredStart = s.PeriodStart
+	r.restoredEnd = s.PeriodEnd
+	r.Unlock()
+
+	return nil
+}
+
+// PushRestored pushes a restored state if its aggregation period differs
+// from the current period. This must be called after the initial window
+// update and before adding metrics. If the restored period is the current
+// one, the state is kept to continue the aggregation.
+func (r *RunningAggregator) PushRestored(acc telegraf.Accumulator) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.restoredEnd.IsZero() {
+		return
+	}
+	since, until := r.restoredStart, r.restoredEnd
+	r.restoredStart = time.Time{}
+	r.restoredEnd = time.Time{}
+
+	if since.Equal(r.periodStart) && until.Equal(r.periodEnd) {
+		r.log.Debugf("Continuing restored aggregation range [%s, %s]", since, until)
+		return
+	}
+
+	r.log.Debugf("Pushing restored aggregation range [%s, %s]", since, until)
+	r.windowTime = until
+	start := time.Now()
+	r.Aggregator.Push(acc)
+	r.PushTime.Incr(time.Since(start).Nanoseconds())
+	r.Aggregator.Reset()
+	r.windowTime = time.Time{}
+}
+
 func (r *RunningAggregator) Log() telegraf.Logger {
 	return r.log
 }
@@ -1,12 +1,14 @@
 package models
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -239,6 +241,198 @@ func TestRunningAggregatorAddDoesNotModifyMetric(t *testing.T) {
 	testutil.RequireMetricEqual(t, expected, m)
 }
 
+func TestRunningAggregatorEventTimeWindows(t *testing.T) {
+	ra := NewRunningAggregator(&mockAggregator{}, &AggregatorConfig{
+		Name: "TestRunningAggregator",
+		Filter: Filter{
+			NamePass: []string{"*"},
+		},
+		Period:          10 * time.Second,
+		WindowMode:      "event",
+		AllowedLateness: 5 * time.Second,
+	})
+	require.NoError(t, ra.Config.Filter.Compile())
+	acc := &makerAccumulator{maker: ra}
+
+	// Use timestamps far in the past to simulate replayed data
+	start := time.Unix(1000, 0)
+	add := func(offset time.Duration, value int64) {
+		m := metric.New("RITest", map[string]string{}, map[string]interface{}{"value": value}, start.Add(offset))
+		require.False(t, ra.Add(m))
+	}
+
+	// The watermark of 7s must not close the first window
+	add(1*time.Second, 1)
+	add(2*time.Second, 2)
+	add(12*time.Second, 4)
+	ra.Push(acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// The watermark of 11s must close the first window
+	add(16*time.Second, 8)
+	ra.Push(acc)
+
+	// Late metrics for the already emitted window must be dropped while
+	// late metrics for open windows must be accepted
+	dropped := ra.MetricsDropped.Get()
+	add(3*time.Second, 16)
+	add(11*time.Second, 32)
+	require.Equal(t, dropped+1, ra.MetricsDropped.Get())
+
+	// Shutting down must emit all remaining windows
+	ra.PushAll(acc)
+
+	expected := []telegraf.Metric{
+		metric.New("TestMetric", map[string]string{}, map[string]interface{}{"sum": int64(3)}, start.Add(10*time.Second)),
+		metric.New("TestMetric", map[string]string{}, map[string]interface{}{"sum": int64(44)}, start.Add(20*time.Second)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestRunningAggregatorEventTimeBufferLimit(t *testing.T) {
+	ra := NewRunningAggregator(&mockAggregator{}, &AggregatorConfig{
+		Name: "TestRunningAggregator",
+		Filter: Filter{
+			NamePass: []string{"*"},
+		},
+		Period:             10 * time.Second,
+		WindowMode:         "event",
+		AllowedLateness:    time.Minute,
+		MaxBufferedMetrics: 3,
+	})
+	require.NoError(t, ra.Config.Filter.Compile())
+	acc := &makerAccumulator{maker: ra}
+
+	start := time.Unix(1000, 0)
+	add := func(offset time.Duration, value int64) {
+		m := metric.New("RITest", map[string]string{}, map[string]interface{}{"value": value}, start.Add(offset))
+		require.False(t, ra.Add(m))
+	}
+
+	// Metrics exceeding the limit must be dropped independent of the window
+	dropped := ra.MetricsDropped.Get()
+	add(1*time.Second, 1)
+	add(12*time.Second, 2)
+	add(23*time.Second, 4)
+	add(2*time.Second, 8)
+	add(34*time.Second, 16)
+	require.Equal(t, dropped+2, ra.MetricsDropped.Get())
+
+	// Emitting windows must free the buffer for new metrics
+	ra.PushAll(acc)
+	add(45*time.Second, 32)
+	require.Equal(t, dropped+2, ra.MetricsDropped.Get())
+	ra.PushAll(acc)
+
+	expected := []telegraf.Metric{
+		metric.New("TestMetric", map[string]string{}, map[string]interface{}{"sum": int64(1)}, start.Add(10*time.Second)),
+		metric.New("TestMetric", map[string]string{}, map[string]interface{}{"sum": int64(2)}, start.Add(20*time.Second)),
+		metric.New("TestMetric", map[string]string{}, map[string]interface{}{"sum": int64(4)}, start.Add(30*time.Second)),
+		metric.New("TestMetric", map[string]string{}, map[string]interface{}{"sum": int64(32)}, start.Add(50*time.Second)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestRunningAggregatorStateRestoreCurrentPeriod(t *testing.T) {
+	ra := NewRunningAggregator(&mockStatefulAggregator{}, &AggregatorConfig{
+		Name:   "TestRunningAggregator",
+		Period: time.Minute,
+	})
+	require.NoError(t, ra.Config.Filter.Compile())
+	require.True(t, ra.Stateful())
+
+	now := time.Now()
+	since := now.Truncate(time.Minute)
+	until := since.Add(time.Minute)
+	ra.UpdateWindow(since, until)
+	ra.Add(testutil.MustMetric("RITest", nil, map[string]interface{}{"value": int64(42)}, now))
+
+	// Serialize the state as done by the persister
+	state, ok := ra.GetState().(AggregatorState)
+	require.True(t, ok)
+	require.Equal(t, since, state.PeriodStart)
+	require.Equal(t, until, state.PeriodEnd)
+	require.JSONEq(t, "42", string(state.State))
+
+	// Restore the state within the same period and continue aggregating
+	restored := NewRunningAggregator(&mockStatefulAggregator{}, &AggregatorConfig{
+		Name:   "TestRunningAggregator",
+		Period: time.Minute,
+	})
+	require.NoError(t, restored.Config.Filter.Compile())
+	require.NoError(t, restored.SetState(state))
+	restored.UpdateWindow(since, until)
+
+	acc := &testutil.Accumulator{}
+	restored.PushRestored(acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	restored.Add(testutil.MustMetric("RITest", nil, map[string]interface{}{"value": int64(8)}, now))
+	restored.Push(acc)
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, int64(50), acc.Metrics[0].Fields["sum"])
+}
+
+func TestRunningAggregatorStateRestoreElapsedPeriod(t *testing.T) {
+	ra := NewRunningAggregator(&mockStatefulAggregator{}, &AggregatorConfig{
+		Name:   "TestRunningAggregator",
+		Period: time.Minute,
+	})
+	require.NoError(t, ra.Config.Filter.Compile())
+
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(time.Minute)
+	state := AggregatorState{
+		PeriodStart: since,
+		PeriodEnd:   until,
+		State:       []byte("42"),
+	}
+	require.NoError(t, ra.SetState(state))
+
+	// The restored period elapsed, so the state must be pushed with the
+	// end of the restored period as timestamp before aggregating new data
+	now := time.Now()
+	ra.UpdateWindow(now.Truncate(time.Minute), now.Truncate(time.Minute).Add(time.Minute))
+	acc := &makerAccumulator{maker: ra}
+	ra.PushRestored(acc)
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	require.Equal(t, int64(42), metrics[0].Fields()["sum"])
+	require.Equal(t, until, metrics[0].Time())
+
+	// The state must only be pushed once
+	ra.PushRestored(acc)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
+
+func TestRunningAggregatorStatefulEventMode(t *testing.T) {
+	ra := NewRunningAggregator(&mockStatefulAggregator{}, &AggregatorConfig{
+		Name:       "TestRunningAggregator",
+		Period:     time.Minute,
+		WindowMode: "event",
+	})
+	require.False(t, ra.Stateful())
+
+	ra = NewRunningAggregator(&mockAggregator{}, &AggregatorConfig{
+		Name:   "TestRunningAggregator",
+		Period: time.Minute,
+	})
+	require.False(t, ra.Stateful())
+}
+
+// makerAccumulator passes the created metrics through the metric maker
+// similar to the accumulator of the agent
+type makerAccumulator struct {
+	testutil.Accumulator
+	maker *RunningAggregator
+}
+
+func (a *makerAccumulator) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, _ ...time.Time) {
+	m := metric.New(measurement, tags, fields, time.Now())
+	a.Accumulator.AddMetric(a.maker.MakeMetric(m))
+}
+
 type mockAggregator struct {
 	sum int64
 }
@@ -265,3 +459,20 @@ func (t *mockAggregator) Add(in telegraf.Metric) {
 		}
 	}
 }
+
+type mockStatefulAggregator struct {
+	mockAggregator
+}
+
+func (t *mockStatefulAggregator) GetState() interface{} {
+	return t.sum
+}
+
+func (t *mockStatefulAggregator) SetState(state interface{}) error {
+	sum, ok := state.(int64)
+	if !ok {
+		return fmt.Errorf("invalid type %T for state", state)
+	}
+	t.sum = sum
+	return nil
+}
@@ -34,6 +34,8 @@ type RunningInput struct {
 	GatherTime      selfstat.Stat
 	GatherTimeouts  selfstat.Stat
 	StartupErrors   selfstat.Stat
+
+	GatherLatency *selfstat.Histogram
 }
 
 func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
@@ -79,6 +81,11 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 			"startup_errors",
 			tags,
 		),
+		GatherLatency: selfstat.RegisterHistogram(
+			"gather_latency",
+			"gather_time_seconds",
+			tags,
+		),
 		log: logger,
 	}
 }
@@ -263,7 +270,9 @@ func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 	err := r.Input.Gather(acc)
 	r.gatherEnd = time.Now()
 
-	r.GatherTime.Incr(r.gatherEnd.Sub(r.gatherStart).Nanoseconds())
+	elapsed := r.gatherEnd.Sub(r.gatherStart)
+	r.GatherTime.Incr(elapsed.Nanoseconds())
+	r.GatherLatency.Observe(elapsed)
 	return err
 }
 
@@ -30,10 +30,11 @@ type OutputConfig struct {
 	StartupErrorBehavior string
 	Filter               Filter
 
-	FlushInterval     time.Duration
-	FlushJitter       time.Duration
-	MetricBufferLimit int
-	MetricBatchSize   int
+	FlushInterval          time.Duration
+	FlushJitter            time.Duration
+	MetricBufferLimit      int
+	MetricBufferLimitBytes int64
+	MetricBatchSize        int
 
 	NameOverride string
 	NamePrefix   string
@@ -42,6 +43,12 @@ type OutputConfig struct {
 	BufferStrategy  string
 	BufferDirectory string
 
+	LatencyBudget   time.Duration
+	LatencyShedding string
+	LatencyWindow   time.Duration
+	LatencyProtect  []string
+	LatencyDrop     []string
+
 	LogLevel string
 }
 
@@ -57,14 +64,18 @@ type RunningOutput struct {
 	MetricBufferLimit int
 	MetricBatchSize   int
 
-	MetricsFiltered selfstat.Stat
-	WriteTime       selfstat.Stat
-	StartupErrors   selfstat.Stat
+	MetricsFiltered        selfstat.Stat
+	WriteTime              selfstat.Stat
+	StartupErrors          selfstat.Stat
+	MetricsShedDropped     selfstat.Stat
+	MetricsShedDownsampled selfstat.Stat
+	QueueLatency           *selfstat.Histogram
 
 	BatchReady chan time.Time
 
-	buffer Buffer
-	log    telegraf.Logger
+	buffer  Buffer
+	shedder *latencyShedder
+	log     telegraf.Logger
 
 	started bool
 	retries uint64
@@ -104,7 +115,7 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 		batchSize = DefaultMetricBatchSize
 	}
 
-	b, err := NewBuffer(config.Name, config.ID, config.Alias, bufferLimit, config.BufferStrategy, config.BufferDirectory)
+	b, err := NewBuffer(config.Name, config.ID, config.Alias, bufferLimit, config.MetricBufferLimitBytes, config.BufferStrategy, config.BufferDirectory)
 	if err != nil {
 		panic(err)
 	}
@@ -131,6 +142,21 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 			"startup_errors",
 			tags,
 		),
+		MetricsShedDropped: selfstat.Register(
+			"write",
+			"metrics_shed_dropped",
+			tags,
+		),
+		MetricsShedDownsampled: selfstat.Register(
+			"write",
+			"metrics_shed_downsampled",
+			tags,
+		),
+		QueueLatency: selfstat.RegisterHistogram(
+			"write_latency",
+			"queue_wait_seconds",
+			tags,
+		),
 		log: logger,
 	}
 
@@ -160,6 +186,15 @@ func (r *RunningOutput) Init() error {
 		return fmt.Errorf("invalid 'startup_error_behavior' setting %q", r.Config.StartupErrorBehavior)
 	}
 
+	shedder, err := newLatencyShedder(r.Config)
+	if err != nil {
+		return err
+	}
+	r.shedder = shedder
+	if err := r.checkLatencyBudget(); err != nil {
+		return err
+	}
+
 	if p, ok := r.Output.(telegraf.Initializer); ok {
 		err := p.Init()
 		if err != nil {
@@ -238,6 +273,37 @@ func (r *RunningOutput) AddMetricNoCopy(metric telegraf.Metric) {
 	r.add(metric)
 }
 
+// Route returns the targets selected for the given metric if the output is a
+// routing output. The output's filters are applied before routing so metrics
+// not passing the filters are not routed. The metric is not modified.
+func (r *RunningOutput) Route(metric telegraf.Metric) []string {
+	router, ok := r.Output.(telegraf.RoutingOutput)
+	if !ok {
+		return nil
+	}
+
+	ok, err := r.Config.Filter.Select(metric)
+	if err != nil {
+		r.log.Errorf("filtering failed: %v", err)
+	} else if !ok {
+		r.MetricsFiltered.Incr(1)
+		return nil
+	}
+
+	// Route a filtered copy if tags or fields are removed by the filters
+	if r.Config.Filter.modifyActive {
+		metric = metric.Copy()
+		defer metric.Drop()
+		r.Config.Filter.Modify(metric)
+		if len(metric.FieldList()) == 0 {
+			r.MetricsFiltered.Incr(1)
+			return nil
+		}
+	}
+
+	return router.Route(metric)
+}
+
 func (r *RunningOutput) add(metric telegraf.Metric) {
 	r.Config.Filter.Modify(metric)
 	if len(metric.FieldList()) == 0 {
@@ -322,6 +388,9 @@ func (r *RunningOutput) Write() error {
 		r.aggMutex.Unlock()
 	}
 
+	// Shed stale metrics before writing to keep fresh data flowing
+	r.shedStale()
+
 	// Only process the metrics in the buffer now. Metrics added while we are
 	// writing will be sent on the next call. We can safely add one more write
 	// because 'doTransaction' will abort early for empty batches.
@@ -356,6 +425,7 @@ func (r *RunningOutput) WriteBatch() error {
 		r.triggerBatchCheck()
 	}()
 
+	r.shedStale()
 	return r.doTransaction()
 }
 
@@ -364,20 +434,30 @@ func (r *RunningOutput) doTransaction() error {
 	if len(tx.Batch) == 0 {
 		return nil
 	}
-	err := r.writeMetrics(tx.Batch)
+	err := r.writeMetrics(tx.Batch, tx.Added)
 	r.updateTransaction(tx, err)
 	r.buffer.EndTransaction(tx)
 
 	return err
 }
 
-func (r *RunningOutput) writeMetrics(metrics []telegraf.Metric) error {
+func (r *RunningOutput) writeMetrics(metrics []telegraf.Metric, added []time.Time) error {
 	if dropped := r.droppedMetrics.Load(); dropped > 0 {
 		r.log.Warnf("Metric buffer overflow; %d metrics have been dropped", dropped)
 		r.droppedMetrics.Add(-dropped)
 	}
 
+	// Record the time the metrics spent in the buffer before being handed
+	// to the output, as done for the latency budget
 	start := time.Now()
+	if len(added) > 0 {
+		waits := make([]time.Duration, 0, len(added))
+		for _, t := range added {
+			waits = append(waits, start.Sub(t))
+		}
+		r.QueueLatency.Observe(waits...)
+	}
+
 	err := r.Output.Write(metrics)
 	elapsed := time.Since(start)
 	r.WriteTime.Incr(elapsed.Nanoseconds())
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+)
+
+// Default window used to downsample metrics when shedding by keeping the last
+// metric per series
+const DefaultLatencyWindow = time.Minute
+
+// sheddingBuffer is implemented by buffers supporting the removal of
+// arbitrary metrics for enforcing a latency budget
+type sheddingBuffer interface {
+	Shed(selector func([]telegraf.Metric, []time.Time) []int) []telegraf.Metric
+}
+
+// latencyShedder enforces the latency budget of an output by removing
+// metrics buffered for longer than the budget
+type latencyShedder struct {
+	budget  time.Duration
+	action  string
+	window  time.Duration
+	protect filter.Filter
+	drop    filter.Filter
+}
+
+func newLatencyShedder(cfg *OutputConfig) (*latencyShedder, error) {
+	if cfg.LatencyBudget <= 0 {
+		return nil, nil
+	}
+
+	s := &latencyShedder{
+		budget: cfg.LatencyBudget,
+		action: cfg.LatencyShedding,
+		window: cfg.LatencyWindow,
+	}
+	switch s.action {
+	case "":
+		s.action = "drop"
+	case "drop", "last":
+	default:
+		return nil, fmt.Errorf("invalid 'latency_shedding' setting %q", cfg.LatencyShedding)
+	}
+	if s.window <= 0 {
+		s.window = DefaultLatencyWindow
+	}
+
+	var err error
+	if s.protect, err = filter.Compile(cfg.LatencyProtect); err != nil {
+		return nil, fmt.Errorf("compiling 'latency_protect' failed: %w", err)
+	}
+	if s.drop, err = filter.Compile(cfg.LatencyDrop); err != nil {
+		return nil, fmt.Errorf("compiling 'latency_drop' failed: %w", err)
+	}
+
+	return s, nil
+}
+
+// selectStale returns the indices of the given metrics to shed, separated into
+// metrics to drop and metrics superseded by a newer metric of the same series
+// and window when downsampling. The age of a metric is the time since it was
+// added to the buffer as given by added, not its timestamp, so delayed or
+// historic metrics are not shed right away.
+func (s *latencyShedder) selectStale(metrics []telegraf.Metric, added []time.Time, now time.Time) (dropped, downsampled []int) {
+	// Keep the newest metric per series and window when downsampling
+	newest := make(map[uint64]map[int64]int)
+	for i, m := range metrics {
+		if now.Sub(added[i]) <= s.budget {
+			continue
+		}
+
+		// Apply the priority rules on the measurement name
+		name := m.Name()
+		switch {
+		case s.protect != nil && s.protect.Match(name):
+			continue
+		case s.action == "drop" || (s.drop != nil && s.drop.Match(name)):
+			dropped = append(dropped, i)
+			continue
+		}
+
+		id := m.HashID()
+		slot := m.Time().Truncate(s.window).UnixNano()
+		if newest[id] == nil {
+			newest[id] = make(map[int64]int)
+		}
+		prev, found := newest[id][slot]
+		if !found {
+			newest[id][slot] = i
+			continue
+		}
+		if m.Time().Before(metrics[prev].Time()) {
+			downsampled = append(downsampled, i)
+			continue
+		}
+		downsampled = append(downsampled, prev)
+		newest[id][slot] = i
+	}
+	return dropped, downsampled
+}
+
+// shedStale removes metrics exceeding the latency budget from the buffer
+func (r *RunningOutput) shedStale() {
+	if r.shedder == nil {
+		return
+	}
+	buf, ok := r.buffer.(sheddingBuffer)
+	if !ok {
+		return
+	}
+
+	// Remember the selection to handle the removed metrics, returned in buffer
+	// order, according to the shedding action.
+	now := time.Now()
+	var selected []int
+	drop := make(map[int]bool)
+	removed := buf.Shed(func(metrics []telegraf.Metric, added []time.Time) []int {
+		dropped, downsampled := r.shedder.selectStale(metrics, added, now)
+		for _, idx := range dropped {
+			drop[idx] = true
+		}
+		selected = append(dropped, downsampled...)
+		slices.Sort(selected)
+		return selected
+	})
+	if len(removed) == 0 {
+		return
+	}
+
+	var dropped, downsampled int
+	for i, m := range removed {
+		if drop[selected[i]] {
+			m.Reject()
+			dropped++
+		} else {
+			m.Drop()
+			downsampled++
+		}
+	}
+	r.MetricsShedDropped.Incr(int64(dropped))
+	r.MetricsShedDownsampled.Incr(int64(downsampled))
+
+	r.log.Debugf("Latency budget of %s exceeded; shed %d metrics by dropping and %d by downsampling",
+		r.shedder.budget, dropped, downsampled)
+}
+
+// checkLatencyBudget validates that the buffer supports latency shedding
+func (r *RunningOutput) checkLatencyBudget() error {
+	if r.shedder == nil {
+		return nil
+	}
+	if _, ok := r.buffer.(sheddingBuffer); !ok {
+		return errors.New("'latency_budget' is only supported by the memory buffer strategy")
+	}
+	return nil
+}
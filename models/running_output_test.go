@@ -729,16 +729,20 @@ func TestRunningOutputInternalMetrics(t *testing.T) {
 				"alias":  "test_alias",
 			},
 			map[string]interface{}{
-				"buffer_limit":     10,
-				"buffer_size":      0,
-				"errors":           0,
-				"metrics_added":    0,
-				"metrics_rejected": 0,
-				"metrics_dropped":  0,
-				"metrics_filtered": 0,
-				"metrics_written":  0,
-				"write_time_ns":    0,
-				"startup_errors":   0,
+				"buffer_bytes":             0,
+				"buffer_limit":             10,
+				"buffer_limit_bytes":       0,
+				"buffer_size":              0,
+				"errors":                   0,
+				"metrics_added":            0,
+				"metrics_rejected":         0,
+				"metrics_dropped":          0,
+				"metrics_filtered":         0,
+				"metrics_shed_downsampled": 0,
+				"metrics_shed_dropped":     0,
+				"metrics_written":          0,
+				"write_time_ns":            0,
+				"startup_errors":           0,
 			},
 			time.Unix(0, 0),
 		),
@@ -1162,6 +1166,141 @@ func TestRunningOutputWriteBatchPartialSuccessAndLoss(t *testing.T) {
 }
 
 // Benchmark adding metrics.
+func TestRunningOutputLatencyBudgetDrop(t *testing.T) {
+	conf := &OutputConfig{
+		Name:           "test_latency_drop",
+		LatencyBudget:  time.Minute,
+		LatencyProtect: []string{"important"},
+	}
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 1000, 10000)
+	require.NoError(t, ro.Init())
+	buf, ok := ro.buffer.(*MemoryBuffer)
+	require.True(t, ok)
+
+	// Metrics buffered for longer than the budget are shed
+	now := time.Now()
+	buffered := now.Add(-2 * time.Minute)
+	buf.now = func() time.Time { return buffered }
+	ro.AddMetric(testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1}, now))
+	ro.AddMetric(testutil.MustMetric("important", map[string]string{}, map[string]interface{}{"value": 2}, now))
+
+	// Metrics with an old timestamp just added to the buffer are kept
+	historic := now.Add(-time.Hour)
+	buf.now = time.Now
+	ro.AddMetric(testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 3}, historic))
+
+	require.NoError(t, ro.Write())
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("important", map[string]string{}, map[string]interface{}{"value": 2}, now),
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 3}, historic),
+	}
+	testutil.RequireMetricsEqual(t, expected, m.Metrics())
+	require.Equal(t, int64(1), ro.MetricsShedDropped.Get())
+	require.Zero(t, ro.MetricsShedDownsampled.Get())
+}
+
+func TestRunningOutputQueueLatency(t *testing.T) {
+	conf := &OutputConfig{
+		Name: "test_queue_latency",
+	}
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 1000, 10000)
+	require.NoError(t, ro.Init())
+	buf, ok := ro.buffer.(*MemoryBuffer)
+	require.True(t, ok)
+
+	// The wait time must be based on the time the metrics were added to the
+	// buffer, not on the metric timestamps
+	added := time.Now().Add(-20 * time.Second)
+	buf.now = func() time.Time { return added }
+	historic := time.Now().Add(-time.Hour)
+	ro.AddMetric(testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1}, historic))
+	ro.AddMetric(testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 2}, historic))
+	require.NoError(t, ro.Write())
+
+	var found bool
+	for _, hm := range selfstat.HistogramMetrics() {
+		if hm.Name() != "internal_write_latency" || hm.Tags()["output"] != "test_queue_latency" {
+			continue
+		}
+		if le, ok := hm.GetTag("le"); ok {
+			count, ok := hm.GetField("queue_wait_seconds_bucket")
+			require.True(t, ok)
+			switch le {
+			case "10":
+				require.Equal(t, int64(0), count)
+			case "30":
+				require.Equal(t, int64(2), count)
+			}
+			continue
+		}
+		found = true
+		require.Equal(t, int64(2), hm.Fields()["queue_wait_seconds_count"])
+		require.Less(t, hm.Fields()["queue_wait_seconds_sum"], 60.0)
+	}
+	require.True(t, found)
+}
+
+func TestRunningOutputLatencyBudgetLast(t *testing.T) {
+	conf := &OutputConfig{
+		Name:            "test_latency_last",
+		LatencyBudget:   time.Minute,
+		LatencyShedding: "last",
+		LatencyWindow:   time.Minute,
+		LatencyDrop:     []string{"debug"},
+	}
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 1000, 10000)
+	require.NoError(t, ro.Init())
+	buf, ok := ro.buffer.(*MemoryBuffer)
+	require.True(t, ok)
+	buffered := time.Now().Add(-2 * time.Minute)
+	buf.now = func() time.Time { return buffered }
+
+	// Stale metrics of the same series and window are downsampled to the
+	// newest one while other series and windows are kept
+	window := time.Now().Add(-time.Hour).Truncate(time.Minute)
+	ro.AddMetric(testutil.MustMetric("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"value": 1}, window.Add(20*time.Second)))
+	ro.AddMetric(testutil.MustMetric("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"value": 2}, window.Add(10*time.Second)))
+	ro.AddMetric(testutil.MustMetric("cpu", map[string]string{"cpu": "1"}, map[string]interface{}{"value": 3}, window.Add(10*time.Second)))
+	ro.AddMetric(testutil.MustMetric("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"value": 4}, window.Add(70*time.Second)))
+	ro.AddMetric(testutil.MustMetric("debug", map[string]string{}, map[string]interface{}{"value": 5}, window))
+
+	require.NoError(t, ro.Write())
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"value": 1}, window.Add(20*time.Second)),
+		testutil.MustMetric("cpu", map[string]string{"cpu": "1"}, map[string]interface{}{"value": 3}, window.Add(10*time.Second)),
+		testutil.MustMetric("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"value": 4}, window.Add(70*time.Second)),
+	}
+	testutil.RequireMetricsEqual(t, expected, m.Metrics())
+	require.Equal(t, int64(1), ro.MetricsShedDropped.Get())
+	require.Equal(t, int64(1), ro.MetricsShedDownsampled.Get())
+}
+
+func TestRunningOutputLatencyBudgetInvalid(t *testing.T) {
+	conf := &OutputConfig{
+		Name:            "test_latency_invalid",
+		LatencyBudget:   time.Minute,
+		LatencyShedding: "foo",
+	}
+	ro := NewRunningOutput(&mockOutput{}, conf, 1000, 10000)
+	require.ErrorContains(t, ro.Init(), "invalid 'latency_shedding' setting")
+
+	conf = &OutputConfig{
+		Name:            "test_latency_disk",
+		ID:              "latency_disk",
+		LatencyBudget:   time.Minute,
+		BufferStrategy:  "disk_write_through",
+		BufferDirectory: t.TempDir(),
+	}
+	ro = NewRunningOutput(&mockOutput{}, conf, 1000, 10000)
+	defer ro.Close()
+	require.ErrorContains(t, ro.Init(), "only supported by the memory buffer strategy")
+}
+
 func BenchmarkRunningOutputAddWrite(b *testing.B) {
 	conf := &OutputConfig{
 		Filter: Filter{},
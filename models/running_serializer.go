@@ -25,6 +25,8 @@ type RunningSerializer struct {
 	MetricsSerialized selfstat.Stat
 	BytesSerialized   selfstat.Stat
 	SerializationTime selfstat.Stat
+
+	SerializationLatency *selfstat.Histogram
 }
 
 func NewRunningSerializer(serializer telegraf.Serializer, config *SerializerConfig) *RunningSerializer {
@@ -43,6 +45,13 @@ func NewRunningSerializer(serializer telegraf.Serializer, config *SerializerConf
 	}
 	SetLoggerOnPlugin(serializer, logger)
 
+	// The histogram is tracked per parent plugin to allow monitoring the
+	// serialization time of each output
+	htags := map[string]string{"type": config.DataFormat, "parent": config.Parent}
+	if config.Alias != "" {
+		htags["alias"] = config.Alias
+	}
+
 	return &RunningSerializer{
 		Serializer: serializer,
 		Config:     config,
@@ -61,6 +70,11 @@ func NewRunningSerializer(serializer telegraf.Serializer, config *SerializerConf
 			"serialization_time_ns",
 			tags,
 		),
+		SerializationLatency: selfstat.RegisterHistogram(
+			"serializer_latency",
+			"serialization_time_seconds",
+			htags,
+		),
 		log: logger,
 	}
 }
@@ -84,6 +98,7 @@ func (r *RunningSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 	buf, err := r.Serializer.Serialize(metric)
 	elapsed := time.Since(start)
 	r.SerializationTime.Incr(elapsed.Nanoseconds())
+	r.SerializationLatency.Observe(elapsed)
 	r.MetricsSerialized.Incr(1)
 	r.BytesSerialized.Incr(int64(len(buf)))
 
@@ -95,6 +110,7 @@ func (r *RunningSerializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, e
 	buf, err := r.Serializer.SerializeBatch(metrics)
 	elapsed := time.Since(start)
 	r.SerializationTime.Incr(elapsed.Nanoseconds())
+	r.SerializationLatency.Observe(elapsed)
 	r.MetricsSerialized.Incr(int64(len(metrics)))
 	r.BytesSerialized.Incr(int64(len(buf)))
 
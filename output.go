@@ -27,3 +27,15 @@ type AggregatingOutput interface {
 	// Reset signals that the aggregator period is completed.
 	Reset()
 }
+
+// RoutingOutput forwards metrics to other outputs instead of writing them
+// itself. Outputs referenced as targets only receive the metrics routed to
+// them and are excluded from receiving all metrics.
+type RoutingOutput interface {
+	Output
+
+	// Targets returns the aliases of all outputs metrics can be routed to
+	Targets() []string
+	// Route returns the aliases of the outputs the metric is sent to
+	Route(metric Metric) []string
+}
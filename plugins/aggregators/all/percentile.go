@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.percentile
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/percentile" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.topk
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/topk" // register plugin
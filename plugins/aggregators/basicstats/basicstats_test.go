@@ -1,6 +1,7 @@
 package basicstats
 
 import (
+	"encoding/json"
 	"math"
 	"testing"
 	"time"
@@ -824,3 +825,36 @@ func TestBasicStatsWithOnlyFirst(t *testing.T) {
 	}
 	acc.AssertContainsTaggedFields(t, "m1", expectedFields, expectedTags)
 }
+
+func TestBasicStatsState(t *testing.T) {
+	aggregator := newBasicStats()
+	aggregator.Stats = []string{"count", "min", "max", "mean", "s2", "diff", "rate", "interval", "first", "last"}
+	aggregator.Log = testutil.Logger{}
+	require.NoError(t, aggregator.Init())
+	aggregator.Add(m1)
+
+	// Round-trip the state through JSON as done by the persister
+	serialized, err := json.Marshal(aggregator.GetState())
+	require.NoError(t, err)
+	var state map[uint64]aggregateState
+	require.NoError(t, json.Unmarshal(serialized, &state))
+
+	restored := newBasicStats()
+	restored.Stats = aggregator.Stats
+	restored.Log = testutil.Logger{}
+	require.NoError(t, restored.Init())
+	require.NoError(t, restored.SetState(state))
+
+	var expected, actual testutil.Accumulator
+	aggregator.Add(m2)
+	aggregator.Push(&expected)
+	restored.Add(m2)
+	restored.Push(&actual)
+
+	testutil.RequireMetricsEqual(t, expected.GetTelegrafMetrics(), actual.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestBasicStatsSetStateInvalid(t *testing.T) {
+	aggregator := newBasicStats()
+	require.ErrorContains(t, aggregator.SetState("foo"), "invalid type string for state")
+}
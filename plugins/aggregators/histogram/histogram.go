@@ -3,6 +3,7 @@ package histogram
 
 import (
 	_ "embed"
+	"fmt"
 	"sort"
 	"strconv"
 	"time"
@@ -156,6 +157,64 @@ func (h *Histogram) Reset() {
 	}
 }
 
+// histogramState is the serializable form of a metric histogram collection
+type histogramState struct {
+	Name       string             `json:"name"`
+	Tags       map[string]string  `json:"tags"`
+	Counts     map[string][]int64 `json:"counts"`
+	ExpireTime time.Time          `json:"expire_time"`
+	Updated    bool               `json:"updated"`
+}
+
+func (h *Histogram) GetState() interface{} {
+	state := make(map[uint64]histogramState, len(h.cache))
+	for id, agr := range h.cache {
+		collection := make(map[string][]int64, len(agr.histogramCollection))
+		for field, c := range agr.histogramCollection {
+			collection[field] = c
+		}
+		state[id] = histogramState{
+			Name:       agr.name,
+			Tags:       agr.tags,
+			Counts:     collection,
+			ExpireTime: agr.expireTime,
+			Updated:    agr.updated,
+		}
+	}
+	return state
+}
+
+func (h *Histogram) SetState(state interface{}) error {
+	s, ok := state.(map[uint64]histogramState)
+	if !ok {
+		return fmt.Errorf("invalid type %T for state", state)
+	}
+
+	h.resetCache()
+	for id, agr := range s {
+		collection := make(map[string]counts, len(agr.Counts))
+		for field, c := range agr.Counts {
+			// Drop counts not matching the configured buckets e.g. due to
+			// configuration changes between runs
+			if buckets := h.getBuckets(agr.Name, field); buckets == nil || len(c) != len(buckets)+1 {
+				continue
+			}
+			collection[field] = c
+		}
+		if len(collection) == 0 {
+			continue
+		}
+		h.cache[id] = metricHistogramCollection{
+			histogramCollection: collection,
+			name:                agr.Name,
+			tags:                agr.Tags,
+			expireTime:          agr.ExpireTime,
+			updated:             agr.Updated,
+		}
+	}
+	return nil
+}
+
 // groupFieldsByBuckets groups fields by metric buckets which are represented as tags
 func (h *Histogram) groupFieldsByBuckets(
 	metricsWithGroupedFields *[]groupedByCountFields, name, field string, tags map[string]string, counts []int64,
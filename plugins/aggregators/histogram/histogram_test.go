@@ -1,6 +1,7 @@
 package histogram
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -527,3 +528,45 @@ func assertContainsTaggedField(t *testing.T, acc *testutil.Accumulator, metricNa
 
 	require.Failf(t, "Unknown measurement", "Unknown measurement %q with tags: %v, fields: %v", metricName, tags, fields)
 }
+
+// TestHistogramState tests restoring the counts from a persisted state
+func TestHistogramState(t *testing.T) {
+	var cfg []bucketConfig
+	cfg = append(cfg, bucketConfig{Metric: "first_metric_name", Fields: []string{"a"}, Buckets: []float64{0.0, 10.0, 20.0, 30.0, 40.0}})
+	histogram := newTestHistogram(cfg, false, true, false).(*Histogram)
+	histogram.Add(firstMetric1)
+
+	// Round-trip the state through JSON as done by the persister
+	serialized, err := json.Marshal(histogram.GetState())
+	require.NoError(t, err)
+	var state map[uint64]histogramState
+	require.NoError(t, json.Unmarshal(serialized, &state))
+
+	restored := newTestHistogram(cfg, false, true, false).(*Histogram)
+	require.NoError(t, restored.SetState(state))
+	restored.Add(firstMetric2)
+
+	acc := &testutil.Accumulator{}
+	restored.Push(acc)
+	require.Len(t, acc.Metrics, 6, "Incorrect number of metrics")
+	assertContainsTaggedField(t, acc, "first_metric_name", fields{"a_bucket": int64(0)}, tags{bucketRightTag: "10"})
+	assertContainsTaggedField(t, acc, "first_metric_name", fields{"a_bucket": int64(2)}, tags{bucketRightTag: "20"})
+	assertContainsTaggedField(t, acc, "first_metric_name", fields{"a_bucket": int64(2)}, tags{bucketRightTag: bucketPosInf})
+}
+
+// TestHistogramStateChangedBuckets tests dropping restored counts not matching the buckets
+func TestHistogramStateChangedBuckets(t *testing.T) {
+	var cfg []bucketConfig
+	cfg = append(cfg, bucketConfig{Metric: "first_metric_name", Fields: []string{"a"}, Buckets: []float64{0.0, 10.0, 20.0, 30.0, 40.0}})
+	histogram := newTestHistogram(cfg, false, true, false).(*Histogram)
+	histogram.Add(firstMetric1)
+	state := histogram.GetState()
+
+	cfg[0].Buckets = []float64{0.0, 20.0}
+	restored := newTestHistogram(cfg, false, true, false).(*Histogram)
+	require.NoError(t, restored.SetState(state))
+
+	acc := &testutil.Accumulator{}
+	restored.Push(acc)
+	require.Empty(t, acc.Metrics)
+}
@@ -0,0 +1,150 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package percentile
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/caio/go-tdigest"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Percentile struct {
+	Percentiles []float64       `toml:"percentiles"`
+	Compression float64         `toml:"compression"`
+	CountSuffix string          `toml:"count_suffix"`
+	Log         telegraf.Logger `toml:"-"`
+
+	quantiles []float64
+	suffixes  []string
+	cache     map[uint64]*aggregate
+}
+
+type aggregate struct {
+	name   string
+	tags   map[string]string
+	fields map[string]*tdigest.TDigest
+}
+
+func (*Percentile) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Percentile) Init() error {
+	if p.Compression < 1.0 {
+		return fmt.Errorf("invalid compression %v", p.Compression)
+	}
+
+	if len(p.Percentiles) == 0 {
+		p.Percentiles = []float64{50, 90, 99}
+	}
+
+	// Precompute the quantiles and the field suffixes, e.g. "_p99_9" for the
+	// 99.9th percentile, to avoid doing this on every push
+	p.quantiles = make([]float64, 0, len(p.Percentiles))
+	p.suffixes = make([]string, 0, len(p.Percentiles))
+	seen := make(map[string]bool, len(p.Percentiles))
+	for _, pct := range p.Percentiles {
+		if pct <= 0 || pct > 100 || math.IsNaN(pct) {
+			return fmt.Errorf("percentile %v out of range", pct)
+		}
+		suffix := "_p" + strings.ReplaceAll(strconv.FormatFloat(pct, 'f', -1, 64), ".", "_")
+		if seen[suffix] {
+			return fmt.Errorf("duplicate percentile %v", pct)
+		}
+		seen[suffix] = true
+		p.quantiles = append(p.quantiles, pct/100.0)
+		p.suffixes = append(p.suffixes, suffix)
+	}
+
+	p.Reset()
+
+	return nil
+}
+
+func (p *Percentile) Add(in telegraf.Metric) {
+	id := in.HashID()
+	a, found := p.cache[id]
+	if !found {
+		a = &aggregate{
+			name:   in.Name(),
+			tags:   in.Tags(),
+			fields: make(map[string]*tdigest.TDigest),
+		}
+		p.cache[id] = a
+	}
+
+	// Fields might not be present in every metric of the series so create
+	// the sketches on demand
+	for _, field := range in.FieldList() {
+		v, ok := convert(field.Value)
+		if !ok || math.IsNaN(v) {
+			continue
+		}
+
+		digest, found := a.fields[field.Key]
+		if !found {
+			d, err := tdigest.New(tdigest.Compression(p.Compression))
+			if err != nil {
+				p.Log.Errorf("Creating sketch for field %q failed: %v", field.Key, err)
+				continue
+			}
+			digest = d
+			a.fields[field.Key] = digest
+		}
+		if err := digest.Add(v); err != nil {
+			p.Log.Errorf("Adding value of field %q failed: %v", field.Key, err)
+		}
+	}
+}
+
+func (p *Percentile) Push(acc telegraf.Accumulator) {
+	for _, a := range p.cache {
+		// Skip series without any numeric samples in this period
+		if len(a.fields) == 0 {
+			continue
+		}
+
+		fields := make(map[string]interface{}, len(a.fields)*(len(p.quantiles)+1))
+		for k, digest := range a.fields {
+			for i, q := range p.quantiles {
+				fields[k+p.suffixes[i]] = digest.Quantile(q)
+			}
+			if p.CountSuffix != "" {
+				fields[k+p.CountSuffix] = digest.Count()
+			}
+		}
+		acc.AddFields(a.name, fields, a.tags)
+	}
+}
+
+func (p *Percentile) Reset() {
+	p.cache = make(map[uint64]*aggregate)
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("percentile", func() telegraf.Aggregator {
+		return &Percentile{Compression: 100}
+	})
+}
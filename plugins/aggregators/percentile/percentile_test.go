@@ -0,0 +1,156 @@
+package percentile
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name        string
+		percentiles []float64
+		compression float64
+		expected    string
+	}{
+		{
+			name:        "invalid compression",
+			compression: 0.5,
+			expected:    "invalid compression 0.5",
+		},
+		{
+			name:        "percentile zero",
+			percentiles: []float64{0},
+			compression: 100,
+			expected:    "percentile 0 out of range",
+		},
+		{
+			name:        "percentile too large",
+			percentiles: []float64{100.1},
+			compression: 100,
+			expected:    "percentile 100.1 out of range",
+		},
+		{
+			name:        "duplicate percentile",
+			percentiles: []float64{50, 99.9, 50},
+			compression: 100,
+			expected:    "duplicate percentile 50",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Percentile{
+				Percentiles: tt.percentiles,
+				Compression: tt.compression,
+				Log:         &testutil.Logger{},
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestPercentiles(t *testing.T) {
+	plugin := &Percentile{
+		Percentiles: []float64{25, 50, 99.5},
+		Compression: 100,
+		CountSuffix: "_count",
+		Log:         &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	for i := range 100 {
+		m := metric.New(
+			"test",
+			map[string]string{"foo": "bar"},
+			map[string]interface{}{
+				"a": int64(i),
+				"b": float64(i) / 100.0,
+				"c": uint64(100 - i),
+				"s": "string",
+				"x": true,
+			},
+			now,
+		)
+		plugin.Add(m)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{"foo": "bar"},
+			map[string]interface{}{
+				"a_p25":   24.75,
+				"a_p50":   49.5,
+				"a_p99_5": 98.505,
+				"a_count": uint64(100),
+				"b_p25":   0.2475,
+				"b_p50":   0.495,
+				"b_p99_5": 0.98505,
+				"b_count": uint64(100),
+				"c_p25":   25.75,
+				"c_p50":   50.5,
+				"c_p99_5": 99.505,
+				"c_count": uint64(100),
+			},
+			now,
+		),
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	epsilon := cmpopts.EquateApprox(0, 1e-3)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), epsilon)
+}
+
+func TestSparseSeries(t *testing.T) {
+	plugin := &Percentile{
+		Percentiles: []float64{50},
+		Compression: 100,
+		Log:         &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{"foo": "bar"}, map[string]interface{}{"a": 1.0}, now),
+		metric.New("test", map[string]string{"foo": "bar"}, map[string]interface{}{"b": 2.0}, now),
+		metric.New("test", map[string]string{"foo": "bar"}, map[string]interface{}{"a": 3.0, "b": 4.0}, now),
+		metric.New("test", map[string]string{"foo": "baz"}, map[string]interface{}{"a": math.NaN()}, now),
+		metric.New("test", map[string]string{"foo": "baz"}, map[string]interface{}{"s": "no number"}, now),
+		metric.New("test", map[string]string{"foo": "qux"}, map[string]interface{}{"a": 5.0}, now),
+	}
+
+	// The first period contains all series but "foo=baz" does not have
+	// numeric samples and must not be emitted
+	for _, m := range input {
+		plugin.Add(m)
+	}
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{"foo": "bar"}, map[string]interface{}{"a_p50": 2.0, "b_p50": 3.0}, now),
+		metric.New("test", map[string]string{"foo": "qux"}, map[string]interface{}{"a_p50": 5.0}, now),
+	}
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+
+	// The second period only contains a single series so no other series
+	// should be emitted
+	plugin.Reset()
+	acc.ClearMetrics()
+	plugin.Add(input[1])
+	expected = []telegraf.Metric{
+		metric.New("test", map[string]string{"foo": "bar"}, map[string]interface{}{"b_p50": 2.0}, now),
+	}
+	plugin.Push(&acc)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
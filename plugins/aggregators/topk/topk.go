@@ -0,0 +1,246 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package topk
+
+import (
+	"cmp"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type TopK struct {
+	K           int             `toml:"k"`
+	GroupBy     []string        `toml:"group_by"`
+	Fields      []string        `toml:"fields"`
+	Aggregation string          `toml:"aggregation"`
+	BottomK     bool            `toml:"bottomk"`
+	AddOther    bool            `toml:"add_other"`
+	OtherValue  string          `toml:"other_value"`
+	Log         telegraf.Logger `toml:"-"`
+
+	tagFilter filter.Filter
+	// Series grouped by metric name and the grouping key
+	cache map[string]map[string]*series
+}
+
+type series struct {
+	key    string
+	tags   map[string]string
+	fields map[string]*stats
+}
+
+type stats struct {
+	sum   float64
+	count int64
+	min   float64
+	max   float64
+}
+
+func (s *stats) add(v float64) {
+	if s.count == 0 || v < s.min {
+		s.min = v
+	}
+	if s.count == 0 || v > s.max {
+		s.max = v
+	}
+	s.sum += v
+	s.count++
+}
+
+func (s *stats) merge(other *stats) {
+	if s.count == 0 || other.min < s.min {
+		s.min = other.min
+	}
+	if s.count == 0 || other.max > s.max {
+		s.max = other.max
+	}
+	s.sum += other.sum
+	s.count += other.count
+}
+
+func (*TopK) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *TopK) Init() error {
+	if t.K < 1 {
+		return fmt.Errorf("invalid k %d", t.K)
+	}
+	if len(t.Fields) == 0 {
+		return errors.New("no fields specified")
+	}
+	if err := choice.Check(t.Aggregation, []string{"sum", "mean", "min", "max"}); err != nil {
+		return fmt.Errorf("config option aggregation: %w", err)
+	}
+	if t.AddOther && t.OtherValue == "" {
+		return errors.New("'other_value' required")
+	}
+
+	f, err := filter.Compile(t.GroupBy)
+	if err != nil {
+		return fmt.Errorf("creating group-by filter failed: %w", err)
+	}
+	t.tagFilter = f
+
+	t.Reset()
+
+	return nil
+}
+
+func (t *TopK) Add(in telegraf.Metric) {
+	// Determine the grouping tags and the resulting key
+	tags := make(map[string]string)
+	parts := make([]string, 0, len(in.TagList()))
+	for _, tag := range in.TagList() {
+		if t.tagFilter == nil || !t.tagFilter.Match(tag.Key) {
+			continue
+		}
+		tags[tag.Key] = tag.Value
+		parts = append(parts, tag.Key+"="+tag.Value)
+	}
+	sort.Strings(parts)
+	key := strings.Join(parts, ",")
+
+	groups, found := t.cache[in.Name()]
+	if !found {
+		groups = make(map[string]*series)
+		t.cache[in.Name()] = groups
+	}
+	s, found := groups[key]
+	if !found {
+		s = &series{
+			key:    key,
+			tags:   tags,
+			fields: make(map[string]*stats),
+		}
+		groups[key] = s
+	}
+
+	for _, name := range t.Fields {
+		raw, found := in.GetField(name)
+		if !found {
+			continue
+		}
+		v, ok := convert(raw)
+		if !ok || math.IsNaN(v) {
+			continue
+		}
+		st, found := s.fields[name]
+		if !found {
+			st = &stats{}
+			s.fields[name] = st
+		}
+		st.add(v)
+	}
+}
+
+func (t *TopK) Push(acc telegraf.Accumulator) {
+	for name, groups := range t.cache {
+		selected := make(map[string]map[string]interface{})
+		otherFields := make(map[string]interface{})
+		otherTags := make(map[string]string)
+
+		for _, field := range t.Fields {
+			candidates := make([]*series, 0, len(groups))
+			for _, s := range groups {
+				if _, found := s.fields[field]; found {
+					candidates = append(candidates, s)
+				}
+			}
+
+			// Sort the series by their aggregated value using the key as
+			// tie-breaker for stable results
+			slices.SortFunc(candidates, func(a, b *series) int {
+				va, vb := t.aggregate(a.fields[field]), t.aggregate(b.fields[field])
+				if c := cmp.Compare(va, vb); c != 0 {
+					if t.BottomK {
+						return c
+					}
+					return -c
+				}
+				return strings.Compare(a.key, b.key)
+			})
+
+			n := min(t.K, len(candidates))
+			for _, s := range candidates[:n] {
+				if selected[s.key] == nil {
+					selected[s.key] = make(map[string]interface{})
+				}
+				selected[s.key][field] = t.aggregate(s.fields[field])
+			}
+
+			if !t.AddOther || n == len(candidates) {
+				continue
+			}
+			rest := &stats{}
+			for _, s := range candidates[n:] {
+				rest.merge(s.fields[field])
+				for k := range s.tags {
+					otherTags[k] = t.OtherValue
+				}
+			}
+			otherFields[field] = t.aggregate(rest)
+		}
+
+		for key, fields := range selected {
+			acc.AddFields(name, fields, groups[key].tags)
+		}
+		if len(otherFields) > 0 {
+			acc.AddFields(name, otherFields, otherTags)
+		}
+	}
+}
+
+func (t *TopK) Reset() {
+	t.cache = make(map[string]map[string]*series)
+}
+
+func (t *TopK) aggregate(s *stats) float64 {
+	switch t.Aggregation {
+	case "sum":
+		return s.sum
+	case "min":
+		return s.min
+	case "max":
+		return s.max
+	default:
+		return s.sum / float64(s.count)
+	}
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("topk", func() telegraf.Aggregator {
+		return &TopK{
+			K:           10,
+			GroupBy:     []string{"*"},
+			Fields:      []string{"value"},
+			Aggregation: "mean",
+			OtherValue:  "other",
+		}
+	})
+}
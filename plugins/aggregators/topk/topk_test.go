@@ -0,0 +1,146 @@
+package topk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*TopK)
+		expected string
+	}{
+		{
+			name:     "invalid k",
+			modify:   func(p *TopK) { p.K = 0 },
+			expected: "invalid k 0",
+		},
+		{
+			name:     "no fields",
+			modify:   func(p *TopK) { p.Fields = nil },
+			expected: "no fields specified",
+		},
+		{
+			name:     "invalid aggregation",
+			modify:   func(p *TopK) { p.Aggregation = "median" },
+			expected: "config option aggregation",
+		},
+		{
+			name: "empty other value",
+			modify: func(p *TopK) {
+				p.AddOther = true
+				p.OtherValue = ""
+			},
+			expected: "'other_value' required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := aggregators.Aggregators["topk"]().(*TopK)
+			plugin.Log = &testutil.Logger{}
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestTopK(t *testing.T) {
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a", "dc": "x"}, map[string]interface{}{"usage": 10.0, "load": int64(1)}, now),
+		metric.New("cpu", map[string]string{"host": "a", "dc": "x"}, map[string]interface{}{"usage": 30.0, "load": int64(3)}, now),
+		metric.New("cpu", map[string]string{"host": "b", "dc": "x"}, map[string]interface{}{"usage": 50.0, "load": int64(2)}, now),
+		metric.New("cpu", map[string]string{"host": "c", "dc": "y"}, map[string]interface{}{"usage": 5.0, "load": int64(8)}, now),
+		metric.New("cpu", map[string]string{"host": "d", "dc": "y"}, map[string]interface{}{"usage": 1.0}, now),
+		metric.New("mem", map[string]string{"host": "a", "dc": "x"}, map[string]interface{}{"usage": 70.0}, now),
+	}
+
+	tests := []struct {
+		name        string
+		aggregation string
+		bottomk     bool
+		addOther    bool
+		expected    []telegraf.Metric
+	}{
+		{
+			name:        "top k mean",
+			aggregation: "mean",
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 20.0, "load": 2.0}, now),
+				metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"usage": 50.0}, now),
+				metric.New("cpu", map[string]string{"host": "c"}, map[string]interface{}{"load": 8.0}, now),
+				metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"usage": 70.0}, now),
+			},
+		},
+		{
+			name:        "top k sum with other",
+			aggregation: "sum",
+			addOther:    true,
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 40.0, "load": 4.0}, now),
+				metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"usage": 50.0}, now),
+				metric.New("cpu", map[string]string{"host": "c"}, map[string]interface{}{"load": 8.0}, now),
+				metric.New("cpu", map[string]string{"host": "other"}, map[string]interface{}{"usage": 6.0, "load": 2.0}, now),
+				metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"usage": 70.0}, now),
+			},
+		},
+		{
+			name:        "bottom k max with other",
+			aggregation: "max",
+			bottomk:     true,
+			addOther:    true,
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"load": 2.0}, now),
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"load": 3.0}, now),
+				metric.New("cpu", map[string]string{"host": "c"}, map[string]interface{}{"usage": 5.0}, now),
+				metric.New("cpu", map[string]string{"host": "d"}, map[string]interface{}{"usage": 1.0}, now),
+				metric.New("cpu", map[string]string{"host": "other"}, map[string]interface{}{"usage": 50.0, "load": 8.0}, now),
+				metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"usage": 70.0}, now),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := aggregators.Aggregators["topk"]().(*TopK)
+			plugin.K = 2
+			plugin.GroupBy = []string{"host"}
+			plugin.Fields = []string{"usage", "load"}
+			plugin.Aggregation = tt.aggregation
+			plugin.BottomK = tt.bottomk
+			plugin.AddOther = tt.addOther
+			plugin.Log = &testutil.Logger{}
+			require.NoError(t, plugin.Init())
+
+			for _, m := range input {
+				plugin.Add(m)
+			}
+
+			var acc testutil.Accumulator
+			plugin.Push(&acc)
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestReset(t *testing.T) {
+	plugin := aggregators.Aggregators["topk"]().(*TopK)
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 1.0}, time.Now()))
+	plugin.Reset()
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -0,0 +1,560 @@
+// Package carbon implements a writer for the protocols of the Graphite carbon
+// daemons shared by outputs sending data to carbon-compatible backends. The
+// metric names are generated by the graphite serializer using templates.
+package carbon
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+var ErrNotConnected = errors.New("could not write to any server in cluster")
+
+type connection struct {
+	conn      net.Conn
+	connected bool
+}
+
+// server holds the pool of connections to a carbon server
+type server struct {
+	name string
+	pool []connection
+}
+
+func (s *server) connected() bool {
+	for _, c := range s.pool {
+		if c.connected {
+			return true
+		}
+	}
+	return false
+}
+
+// Writer sends data to the given carbon servers. Connections are kept open
+// between writes. By default, servers are chosen randomly for each write with
+// a failover to the remaining servers. With consistent hashing, each metric
+// path is sent to the same server as long as the server is available.
+type Writer struct {
+	// Servers to send the data to as host:port
+	Servers []string
+	// LocalAddr to bind when connecting to the servers
+	LocalAddr string
+	// Protocol used for sending the data, either "plaintext", "tagged" or
+	// "pickle". Tagged data uses the plaintext protocol.
+	Protocol string
+	// Routing of the data to the servers, either "random" or
+	// "consistent_hashing"
+	Routing string
+	// ConnectionsPerServer is the number of connections kept open to each
+	// server. Data is split at line boundaries and written in parallel.
+	ConnectionsPerServer int
+	// HealthCheckInterval is the interval for checking idle connections and
+	// reconnecting failed servers in the background, zero disables the checks
+	HealthCheckInterval time.Duration
+	// Timeout for connecting and writing
+	Timeout time.Duration
+	// TLSConfig to use for the connections, nil for unencrypted connections
+	TLSConfig *tls.Config
+	// Handshake is called after establishing a connection, e.g. to
+	// authenticate against the server
+	Handshake func(net.Conn) error
+	Log       telegraf.Logger
+
+	dialer  net.Dialer
+	servers []*server
+	ring    *ring
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (w *Writer) Init() error {
+	switch w.Protocol {
+	case "":
+		w.Protocol = "plaintext"
+	case "plaintext", "tagged", "pickle":
+	default:
+		return fmt.Errorf("invalid protocol %q", w.Protocol)
+	}
+
+	switch w.Routing {
+	case "":
+		w.Routing = "random"
+	case "random", "consistent_hashing":
+	default:
+		return fmt.Errorf("invalid routing %q", w.Routing)
+	}
+
+	if len(w.Servers) == 0 {
+		return errors.New("no servers specified")
+	}
+	if w.ConnectionsPerServer < 0 {
+		return errors.New("number of connections per server must not be negative")
+	}
+	if w.ConnectionsPerServer == 0 {
+		w.ConnectionsPerServer = 1
+	}
+
+	w.dialer = net.Dialer{Timeout: w.Timeout}
+	if w.LocalAddr != "" {
+		// Resolve the local address into IP address and the given port if any
+		addr, sPort, err := net.SplitHostPort(w.LocalAddr)
+		if err != nil {
+			if !strings.Contains(err.Error(), "missing port") {
+				return fmt.Errorf("invalid local address: %w", err)
+			}
+			addr = w.LocalAddr
+		}
+		local, err := net.ResolveIPAddr("ip", addr)
+		if err != nil {
+			return fmt.Errorf("cannot resolve local address: %w", err)
+		}
+
+		var port int
+		if sPort != "" {
+			p, err := strconv.ParseUint(sPort, 10, 16)
+			if err != nil {
+				return fmt.Errorf("invalid port: %w", err)
+			}
+			port = int(p)
+		}
+
+		w.dialer.LocalAddr = &net.TCPAddr{IP: local.IP, Port: port, Zone: local.Zone}
+	}
+
+	w.servers = make([]*server, 0, len(w.Servers))
+	for _, name := range w.Servers {
+		w.servers = append(w.servers, &server{
+			name: name,
+			pool: make([]connection, w.ConnectionsPerServer),
+		})
+	}
+	if w.Routing == "consistent_hashing" {
+		w.ring = newRing(w.Servers)
+	}
+
+	return nil
+}
+
+// Connect tries to connect to all servers not yet connected. Failing servers
+// are logged but do not cause an error, they are retried on the next write or
+// health check.
+func (w *Writer) Connect() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.connect()
+
+	if w.HealthCheckInterval > 0 && w.cancel == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		w.cancel = cancel
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.healthCheck(ctx)
+		}()
+	}
+
+	return nil
+}
+
+func (w *Writer) connect() {
+	var newConnection bool
+	var connectedServers int
+	var failedServers []string
+	for _, s := range w.servers {
+		for i, c := range s.pool {
+			if c.connected {
+				continue
+			}
+			newConnection = true
+
+			conn, err := w.dial(s.name)
+			if err != nil {
+				w.Log.Debugf("Failed to establish connection: %v", err)
+				break
+			}
+			s.pool[i].conn = conn
+			s.pool[i].connected = true
+		}
+		if s.connected() {
+			connectedServers++
+		} else {
+			failedServers = append(failedServers, s.name)
+		}
+	}
+
+	if newConnection {
+		w.Log.Debugf("Successful connections: %d of %d", connectedServers, len(w.servers))
+	}
+	if len(failedServers) > 0 {
+		w.Log.Debugf("Failed servers: %d", len(failedServers))
+	}
+}
+
+func (w *Writer) dial(address string) (net.Conn, error) {
+	var conn net.Conn
+	var err error
+	if w.TLSConfig != nil {
+		conn, err = tls.DialWithDialer(&w.dialer, "tcp", address, w.TLSConfig)
+	} else {
+		conn, err = w.dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if w.Handshake != nil {
+		if w.Timeout > 0 {
+			if err := conn.SetDeadline(time.Now().Add(w.Timeout)); err != nil {
+				conn.Close()
+				return nil, err
+			}
+		}
+		if err := w.Handshake(conn); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("handshake with %q failed: %w", address, err)
+		}
+		if err := conn.SetDeadline(time.Time{}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// healthCheck periodically detects connections closed by the servers and
+// reconnects them so writes do not have to wait for reconnecting
+func (w *Writer) healthCheck(ctx context.Context) {
+	ticker := time.NewTicker(w.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		w.mu.Lock()
+		for _, s := range w.servers {
+			for i, c := range s.pool {
+				if c.connected && w.checkEOF(c.conn) != nil {
+					w.Log.Debugf("Health check of connection to %q failed", s.name)
+					s.pool[i].connected = false
+				}
+			}
+		}
+		w.connect()
+		w.mu.Unlock()
+	}
+}
+
+// Close stops the health checks and closes all open connections
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	cancel := w.cancel
+	w.cancel = nil
+	w.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		w.wg.Wait()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, s := range w.servers {
+		for i, c := range s.pool {
+			if c.conn != nil {
+				_ = c.conn.Close()
+			}
+			s.pool[i].conn = nil
+			s.pool[i].connected = false
+		}
+	}
+	return nil
+}
+
+// Write sends the given data in plaintext format, i.e. lines of
+// "<path> <value> <timestamp>", to the servers converting it to the
+// configured protocol. Unconnected servers are reconnected and the data not
+// sent yet is retried once if all servers failed.
+func (w *Writer) Write(data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Try to connect to all servers not yet connected if any
+	w.connect()
+
+	// Return on success of if we encounter a non-retryable error
+	pending, err := w.send(data)
+	if err == nil || !errors.Is(err, ErrNotConnected) {
+		return err
+	}
+
+	// Try to reconnect and resend
+	failedServers := make([]string, 0, len(w.servers))
+	for _, s := range w.servers {
+		if !s.connected() {
+			failedServers = append(failedServers, s.name)
+		}
+	}
+	if len(failedServers) > 0 {
+		w.Log.Debugf("Reconnecting and retrying for the following servers: %s", strings.Join(failedServers, ","))
+		w.connect()
+	}
+
+	_, err = w.send(pending)
+	return err
+}
+
+// send writes the data to the servers according to the routing and returns
+// the data that could not be sent
+func (w *Writer) send(data []byte) ([]byte, error) {
+	if w.Routing == "consistent_hashing" {
+		return w.sendHashed(data)
+	}
+
+	// Try sending the data to a server. Try them in random order
+	pending := data
+	p := rand.Perm(len(w.servers))
+	for i, n := range p {
+		s := w.servers[n]
+
+		// Skip unconnected servers
+		if !s.connected() {
+			continue
+		}
+
+		var err error
+		if pending, err = w.writeServer(s, pending); err != nil {
+			return pending, err
+		}
+		if len(pending) == 0 {
+			return nil, nil
+		}
+		if i < len(p)-1 {
+			w.Log.Info("Trying next server...")
+		}
+	}
+
+	// If we end here, none of the writes were successful
+	return pending, ErrNotConnected
+}
+
+// sendHashed distributes the lines across the servers by hashing the metric
+// path and writes to the servers in parallel. Lines of failed servers are
+// redistributed across the remaining servers.
+func (w *Writer) sendHashed(data []byte) ([]byte, error) {
+	pending := data
+	for len(pending) > 0 {
+		alive := make(map[string]bool, len(w.servers))
+		for _, s := range w.servers {
+			if s.connected() {
+				alive[s.name] = true
+			}
+		}
+		if len(alive) == 0 {
+			return pending, ErrNotConnected
+		}
+
+		batches := make(map[string][]byte, len(alive))
+		for len(pending) > 0 {
+			line := pending
+			if idx := bytes.IndexByte(pending, '\n'); idx >= 0 {
+				line = pending[:idx+1]
+			}
+			pending = pending[len(line):]
+
+			path, _, _ := bytes.Cut(line, []byte(" "))
+			name := w.ring.get(path, alive)
+			batches[name] = append(batches[name], line...)
+		}
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		var errs []error
+		for _, s := range w.servers {
+			batch, found := batches[s.name]
+			if !found {
+				continue
+			}
+			wg.Add(1)
+			go func(s *server, batch []byte) {
+				defer wg.Done()
+				failed, err := w.writeServer(s, batch)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					errs = append(errs, err)
+				}
+				pending = append(pending, failed...)
+			}(s, batch)
+		}
+		wg.Wait()
+
+		if len(errs) > 0 {
+			return pending, errors.Join(errs...)
+		}
+	}
+	return nil, nil
+}
+
+// writeServer writes the data to the given server in parallel using the
+// connected connections of the pool and returns the data that could not be
+// written
+func (w *Writer) writeServer(s *server, data []byte) ([]byte, error) {
+	conns := make([]int, 0, len(s.pool))
+	for i, c := range s.pool {
+		if c.connected {
+			conns = append(conns, i)
+		}
+	}
+	if len(conns) == 0 {
+		return data, nil
+	}
+	chunks := splitLines(data, len(conns))
+
+	payloads := make([][]byte, len(chunks))
+	for i, chunk := range chunks {
+		payloads[i] = chunk
+		if w.Protocol == "pickle" {
+			var err error
+			if payloads[i], err = encodePickle(chunk); err != nil {
+				return data, fmt.Errorf("encoding data failed: %w", err)
+			}
+		}
+	}
+
+	failed := make([]bool, len(chunks))
+	var wg sync.WaitGroup
+	for i := range chunks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			failed[i] = !w.writeConnection(s, conns[i], payloads[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var pending []byte
+	for i, chunk := range chunks {
+		if failed[i] {
+			pending = append(pending, chunk...)
+		}
+	}
+	return pending, nil
+}
+
+// writeConnection writes the payload to the given connection of the server's
+// pool and returns if the write succeeded. Failed connections are closed and
+// marked for reconnecting.
+func (w *Writer) writeConnection(s *server, idx int, payload []byte) bool {
+	c := &s.pool[idx]
+
+	if w.Timeout > 0 {
+		deadline := time.Now().Add(w.Timeout)
+		if err := c.conn.SetWriteDeadline(deadline); err != nil {
+			w.Log.Warnf("failed to set write deadline for %q: %v", s.name, err)
+			c.connected = false
+			return false
+		}
+	}
+
+	// Check the connection state
+	if err := w.checkEOF(c.conn); err != nil {
+		// Mark connection as failed so a new connection will be made
+		c.connected = false
+		return false
+	}
+	if _, err := c.conn.Write(payload); err != nil {
+		w.Log.Errorf("Writing to %q failed: %v", s.name, err)
+		// Mark connection as failed so a new connection will be made
+		if err := c.conn.Close(); err != nil {
+			w.Log.Debugf("Failed to close connection to %q: %v", s.name, err)
+		}
+		c.connected = false
+		return false
+	}
+	return true
+}
+
+// splitLines splits the data at line boundaries into at most n chunks of
+// roughly equal size
+func splitLines(data []byte, n int) [][]byte {
+	if n <= 1 || len(data) == 0 {
+		return [][]byte{data}
+	}
+
+	size := len(data) / n
+	chunks := make([][]byte, 0, n)
+	for len(data) > 0 {
+		if len(chunks) == n-1 || len(data) <= size {
+			chunks = append(chunks, data)
+			break
+		}
+		end := size
+		if idx := bytes.IndexByte(data[size:], '\n'); idx >= 0 {
+			end += idx + 1
+		} else {
+			end = len(data)
+		}
+		chunks = append(chunks, data[:end])
+		data = data[end:]
+	}
+	return chunks
+}
+
+// We need check eof as we can write to nothing without noticing anything is wrong
+// the connection stays in a close_wait
+// We can detect that by finding an eof
+// if not for this, we can happily write and flush without getting errors (in Go) but getting RST tcp packets back (!)
+// props to Tv via the authors of carbon-relay-ng` for this trick.
+func (w *Writer) checkEOF(conn net.Conn) error {
+	b := make([]byte, 1024)
+
+	if err := conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond)); err != nil {
+		w.Log.Debugf(
+			"Couldn't set read deadline for connection due to error %v with remote address %s. closing conn explicitly",
+			err,
+			conn.RemoteAddr().String(),
+		)
+		err = conn.Close()
+		w.Log.Debugf("Failed to close the connection: %v", err)
+		return err
+	}
+	num, err := conn.Read(b)
+	if errors.Is(err, io.EOF) {
+		w.Log.Debugf("Conn %s is closed. closing conn explicitly", conn.RemoteAddr().String())
+		err = conn.Close()
+		w.Log.Debugf("Failed to close the connection: %v", err)
+		return err
+	}
+	// just in case i misunderstand something or the remote behaves badly
+	if num != 0 {
+		w.Log.Infof("conn %s .conn.Read data? did not expect that. data: %s", conn, b[:num])
+	}
+	// Log non-timeout errors and close.
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		w.Log.Debugf("conn %s checkEOF .conn.Read returned err != EOF, which is unexpected.  closing conn. error: %s", conn, err)
+		err = conn.Close()
+		w.Log.Debugf("Failed to close the connection: %v", err)
+		return err
+	}
+
+	return nil
+}
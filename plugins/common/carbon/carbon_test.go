@@ -0,0 +1,407 @@
+package carbon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalidProtocol(t *testing.T) {
+	w := &Writer{
+		Servers:  []string{"localhost:2003"},
+		Protocol: "foo",
+		Log:      testutil.Logger{},
+	}
+	require.ErrorContains(t, w.Init(), `invalid protocol "foo"`)
+}
+
+func TestInitNoServers(t *testing.T) {
+	w := &Writer{Log: testutil.Logger{}}
+	require.ErrorContains(t, w.Init(), "no servers specified")
+}
+
+func TestWritePlaintext(t *testing.T) {
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := server.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf, _ := io.ReadAll(conn)
+		received <- buf
+	}()
+
+	w := &Writer{
+		Servers: []string{server.Addr().String()},
+		Timeout: 2 * time.Second,
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, w.Init())
+	require.NoError(t, w.Connect())
+
+	data := []byte("cpu.usage 42.5 1289430000\n")
+	require.NoError(t, w.Write(data))
+	require.NoError(t, w.Close())
+
+	select {
+	case buf := <-received:
+		require.Equal(t, data, buf)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for data")
+	}
+}
+
+func TestWriteFailover(t *testing.T) {
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	// Get an address nobody listens on
+	dead, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	deadAddr := dead.Addr().String()
+	require.NoError(t, dead.Close())
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := server.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf, _ := io.ReadAll(conn)
+		received <- buf
+	}()
+
+	w := &Writer{
+		Servers: []string{deadAddr, server.Addr().String()},
+		Timeout: 2 * time.Second,
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, w.Init())
+	require.NoError(t, w.Connect())
+
+	data := []byte("cpu.usage 42.5 1289430000\n")
+	require.NoError(t, w.Write(data))
+	require.NoError(t, w.Close())
+
+	select {
+	case buf := <-received:
+		require.Equal(t, data, buf)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for data")
+	}
+}
+
+func TestWriteNotConnected(t *testing.T) {
+	dead, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	deadAddr := dead.Addr().String()
+	require.NoError(t, dead.Close())
+
+	w := &Writer{
+		Servers: []string{deadAddr},
+		Timeout: time.Second,
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, w.Init())
+	require.ErrorIs(t, w.Write([]byte("cpu.usage 42.5 1289430000\n")), ErrNotConnected)
+}
+
+func TestWriteHandshake(t *testing.T) {
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := server.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf, _ := io.ReadAll(conn)
+		received <- buf
+	}()
+
+	w := &Writer{
+		Servers: []string{server.Addr().String()},
+		Timeout: 2 * time.Second,
+		Handshake: func(conn net.Conn) error {
+			_, err := conn.Write([]byte("hello\n"))
+			return err
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, w.Init())
+	require.NoError(t, w.Write([]byte("cpu.usage 42.5 1289430000\n")))
+	require.NoError(t, w.Close())
+
+	select {
+	case buf := <-received:
+		require.Equal(t, "hello\ncpu.usage 42.5 1289430000\n", string(buf))
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for data")
+	}
+}
+
+func TestEncodePickle(t *testing.T) {
+	actual, err := encodePickle([]byte("cpu.usage 42.5 1289430000\n"))
+	require.NoError(t, err)
+
+	var payload bytes.Buffer
+	payload.Write([]byte{pickleProto, 2, pickleEmptyList, pickleMark, pickleUnicode})
+	require.NoError(t, binary.Write(&payload, binary.LittleEndian, uint32(len("cpu.usage"))))
+	payload.WriteString("cpu.usage")
+	payload.WriteByte(pickleInt)
+	require.NoError(t, binary.Write(&payload, binary.LittleEndian, int32(1289430000)))
+	payload.WriteByte(pickleFloat)
+	require.NoError(t, binary.Write(&payload, binary.BigEndian, math.Float64bits(42.5)))
+	payload.Write([]byte{pickleTuple2, pickleTuple2, pickleAppends, pickleStop})
+
+	expected := make([]byte, 4, 4+payload.Len())
+	binary.BigEndian.PutUint32(expected, uint32(payload.Len()))
+	expected = append(expected, payload.Bytes()...)
+
+	require.Equal(t, expected, actual)
+}
+
+func TestEncodePickleSplit(t *testing.T) {
+	var data bytes.Buffer
+	for range maxPicklePoints + 1 {
+		data.WriteString("cpu.usage 42.5 1289430000\n")
+	}
+
+	actual, err := encodePickle(data.Bytes())
+	require.NoError(t, err)
+
+	// Count the length-prefixed messages
+	var messages int
+	for len(actual) > 0 {
+		require.GreaterOrEqual(t, len(actual), 4)
+		size := int(binary.BigEndian.Uint32(actual[:4]))
+		require.GreaterOrEqual(t, len(actual), 4+size)
+		actual = actual[4+size:]
+		messages++
+	}
+	require.Equal(t, 2, messages)
+}
+
+func TestEncodePickleInvalid(t *testing.T) {
+	_, err := encodePickle([]byte("cpu.usage abc 1289430000\n"))
+	require.ErrorContains(t, err, "invalid value")
+
+	_, err = encodePickle([]byte("cpu.usage 42\n"))
+	require.ErrorContains(t, err, "invalid line")
+}
+
+func TestInitInvalidRouting(t *testing.T) {
+	w := &Writer{
+		Servers: []string{"localhost:2003"},
+		Routing: "foo",
+		Log:     testutil.Logger{},
+	}
+	require.ErrorContains(t, w.Init(), `invalid routing "foo"`)
+}
+
+// listen starts a server collecting the data of the given number of
+// connections
+func listen(t *testing.T, connections int) (net.Listener, func() string) {
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var received bytes.Buffer
+	wg.Add(connections)
+	go func() {
+		for range connections {
+			conn, err := server.Accept()
+			if err != nil {
+				wg.Done()
+				continue
+			}
+			go func() {
+				defer wg.Done()
+				defer conn.Close()
+				buf, _ := io.ReadAll(conn)
+				mu.Lock()
+				received.Write(buf)
+				mu.Unlock()
+			}()
+		}
+	}()
+
+	return server, func() string {
+		wg.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		return received.String()
+	}
+}
+
+func TestWriteConnectionPool(t *testing.T) {
+	server, received := listen(t, 3)
+
+	w := &Writer{
+		Servers:              []string{server.Addr().String()},
+		ConnectionsPerServer: 3,
+		Timeout:              2 * time.Second,
+		Log:                  testutil.Logger{},
+	}
+	require.NoError(t, w.Init())
+	require.NoError(t, w.Connect())
+	for _, c := range w.servers[0].pool {
+		require.True(t, c.connected)
+	}
+
+	var data bytes.Buffer
+	for i := range 100 {
+		fmt.Fprintf(&data, "cpu.usage%d 42.5 1289430000\n", i)
+	}
+	require.NoError(t, w.Write(data.Bytes()))
+	require.NoError(t, w.Close())
+
+	// Each connection must only receive complete lines
+	lines := strings.SplitAfter(received(), "\n")
+	expected := strings.SplitAfter(data.String(), "\n")
+	require.ElementsMatch(t, expected, lines)
+}
+
+func TestWriteConsistentHashing(t *testing.T) {
+	first, receivedFirst := listen(t, 1)
+	second, receivedSecond := listen(t, 1)
+
+	servers := []string{first.Addr().String(), second.Addr().String()}
+	w := &Writer{
+		Servers: servers,
+		Routing: "consistent_hashing",
+		Timeout: 2 * time.Second,
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, w.Init())
+	require.NoError(t, w.Connect())
+
+	var data bytes.Buffer
+	for i := range 100 {
+		fmt.Fprintf(&data, "cpu.usage%d 42.5 1289430000\n", i)
+	}
+	require.NoError(t, w.Write(data.Bytes()))
+	require.NoError(t, w.Write(data.Bytes()))
+	require.NoError(t, w.Close())
+
+	// Each series must be sent to the server determined by the ring
+	alive := map[string]bool{servers[0]: true, servers[1]: true}
+	r := newRing(servers)
+	for _, buf := range []struct {
+		server string
+		data   string
+	}{
+		{servers[0], receivedFirst()},
+		{servers[1], receivedSecond()},
+	} {
+		require.NotEmpty(t, buf.data)
+		for _, line := range strings.Split(strings.TrimSpace(buf.data), "\n") {
+			path, _, _ := strings.Cut(line, " ")
+			require.Equal(t, buf.server, r.get([]byte(path), alive), line)
+		}
+	}
+}
+
+func TestWriteConsistentHashingFailover(t *testing.T) {
+	server, received := listen(t, 1)
+
+	// Get an address nobody listens on
+	dead, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	deadAddr := dead.Addr().String()
+	require.NoError(t, dead.Close())
+
+	w := &Writer{
+		Servers: []string{deadAddr, server.Addr().String()},
+		Routing: "consistent_hashing",
+		Timeout: 2 * time.Second,
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, w.Init())
+	require.NoError(t, w.Connect())
+
+	var data bytes.Buffer
+	for i := range 100 {
+		fmt.Fprintf(&data, "cpu.usage%d 42.5 1289430000\n", i)
+	}
+	require.NoError(t, w.Write(data.Bytes()))
+	require.NoError(t, w.Close())
+	require.Equal(t, data.String(), received())
+}
+
+func TestHealthCheckReconnect(t *testing.T) {
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := server.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	w := &Writer{
+		Servers:             []string{server.Addr().String()},
+		HealthCheckInterval: 50 * time.Millisecond,
+		Timeout:             2 * time.Second,
+		Log:                 testutil.Logger{},
+	}
+	require.NoError(t, w.Init())
+	require.NoError(t, w.Connect())
+	defer w.Close()
+
+	// Close the connection on the server side and wait for the health check
+	// to establish a new connection
+	select {
+	case conn := <-accepted:
+		require.NoError(t, conn.Close())
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for connection")
+	}
+	select {
+	case conn := <-accepted:
+		conn.Close()
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for reconnect")
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	data := []byte("a 1 1\nb 2 2\nc 3 3\nd 4 4\n")
+	require.Equal(t, [][]byte{data}, splitLines(data, 1))
+
+	chunks := splitLines(data, 2)
+	require.Len(t, chunks, 2)
+	require.Equal(t, "a 1 1\nb 2 2\nc 3 3\n", string(chunks[0]))
+	require.Equal(t, "d 4 4\n", string(chunks[1]))
+
+	require.Len(t, splitLines(data, 10), 4)
+}
@@ -0,0 +1,123 @@
+package carbon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Maximum number of data points per pickle message to stay well below the
+// message size limit of the carbon receivers
+const maxPicklePoints = 500
+
+// Opcodes of the pickle protocol version 2
+const (
+	pickleProto     = 0x80
+	pickleEmptyList = ']'
+	pickleMark      = '('
+	pickleAppends   = 'e'
+	pickleStop      = '.'
+	pickleUnicode   = 'X'
+	pickleInt       = 'J'
+	pickleFloat     = 'G'
+	pickleTuple2    = 0x86
+)
+
+// encodePickle converts plaintext lines into messages of the carbon pickle
+// protocol, i.e. length-prefixed pickled lists of (path, (timestamp, value))
+// tuples
+func encodePickle(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	var msg bytes.Buffer
+	var count int
+
+	flush := func() {
+		if count == 0 {
+			return
+		}
+		msg.WriteByte(pickleAppends)
+		msg.WriteByte(pickleStop)
+
+		var header [4]byte
+		binary.BigEndian.PutUint32(header[:], uint32(msg.Len()+2))
+		out.Write(header[:])
+		out.WriteByte(pickleProto)
+		out.WriteByte(2)
+		out.Write(msg.Bytes())
+
+		msg.Reset()
+		count = 0
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		parts := strings.Fields(line)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid line %q", line)
+		}
+		value, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value in line %q: %w", line, err)
+		}
+		timestamp, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp in line %q: %w", line, err)
+		}
+
+		if count == 0 {
+			msg.WriteByte(pickleEmptyList)
+			msg.WriteByte(pickleMark)
+		}
+		writePickleString(&msg, parts[0])
+		writePickleTimestamp(&msg, timestamp)
+		writePickleFloat(&msg, value)
+		msg.WriteByte(pickleTuple2)
+		msg.WriteByte(pickleTuple2)
+		count++
+
+		if count >= maxPicklePoints {
+			flush()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	return out.Bytes(), nil
+}
+
+func writePickleString(buf *bytes.Buffer, s string) {
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(s)))
+	buf.WriteByte(pickleUnicode)
+	buf.Write(length[:])
+	buf.WriteString(s)
+}
+
+func writePickleTimestamp(buf *bytes.Buffer, ts int64) {
+	// Use floats for timestamps not fitting into a 32-bit integer
+	if ts < math.MinInt32 || ts > math.MaxInt32 {
+		writePickleFloat(buf, float64(ts))
+		return
+	}
+	var v [4]byte
+	binary.LittleEndian.PutUint32(v[:], uint32(int32(ts)))
+	buf.WriteByte(pickleInt)
+	buf.Write(v[:])
+}
+
+func writePickleFloat(buf *bytes.Buffer, f float64) {
+	var v [8]byte
+	binary.BigEndian.PutUint64(v[:], math.Float64bits(f))
+	buf.WriteByte(pickleFloat)
+	buf.Write(v[:])
+}
@@ -0,0 +1,60 @@
+package carbon
+
+import (
+	"crypto/md5" //nolint:gosec // Used for distributing keys only, not for security
+	"encoding/binary"
+	"sort"
+	"strconv"
+)
+
+// Number of points per server on the hash ring for an even distribution
+const ringReplicas = 100
+
+type ringPoint struct {
+	hash   uint64
+	server string
+}
+
+// ring implements consistent hashing of metric paths to servers
+type ring struct {
+	points []ringPoint
+}
+
+func newRing(servers []string) *ring {
+	r := &ring{points: make([]ringPoint, 0, len(servers)*ringReplicas)}
+	for _, s := range servers {
+		for i := range ringReplicas {
+			r.points = append(r.points, ringPoint{
+				hash:   hashKey([]byte(s + "-" + strconv.Itoa(i))),
+				server: s,
+			})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		return r.points[i].hash < r.points[j].hash
+	})
+	return r
+}
+
+// get returns the server for the given key skipping servers not contained in
+// the alive set. The alive set must not be empty.
+func (r *ring) get(key []byte, alive map[string]bool) string {
+	h := hashKey(key)
+	start := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i].hash >= h
+	})
+	for i := range r.points {
+		p := r.points[(start+i)%len(r.points)]
+		if alive[p.server] {
+			return p.server
+		}
+	}
+	return ""
+}
+
+// hashKey uses MD5 like the consistent hashing of carbon-relay to get an even
+// distribution for similar keys
+func hashKey(key []byte) uint64 {
+	sum := md5.Sum(key) //nolint:gosec // Used for distributing keys only, not for security
+	return binary.BigEndian.Uint64(sum[:8])
+}
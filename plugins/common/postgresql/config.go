@@ -1,6 +1,8 @@
 package postgresql
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"net/url"
@@ -24,6 +26,7 @@ type Config struct {
 	MaxIdle       int             `toml:"max_idle"`
 	MaxOpen       int             `toml:"max_open"`
 	MaxLifetime   config.Duration `toml:"max_lifetime"`
+	SharedPool    bool            `toml:"shared_pool"`
 	IsPgBouncer   bool            `toml:"-"`
 }
 
@@ -64,14 +67,30 @@ func (c *Config) CreateService() (*Service, error) {
 		return nil, err
 	}
 
-	return &Service{
+	database := connectionDatabase(sanitizedAddr)
+	service := &Service{
 		SanitizedAddress:   sanitizedAddr,
-		ConnectionDatabase: connectionDatabase(sanitizedAddr),
+		ConnectionDatabase: database,
+		PgBouncerConsole:   c.IsPgBouncer && database == "pgbouncer",
 		maxIdle:            c.MaxIdle,
 		maxOpen:            c.MaxOpen,
 		maxLifetime:        time.Duration(c.MaxLifetime),
 		dsn:                stdlib.RegisterConnConfig(connConfig),
-	}, nil
+	}
+
+	// Instances connecting to the same server with the same protocol settings
+	// can share their connections. Use a hash of the address to avoid keeping
+	// the credentials in the key.
+	if c.SharedPool {
+		h := sha256.New()
+		h.Write([]byte(addr))
+		if c.IsPgBouncer {
+			h.Write([]byte{0})
+		}
+		service.poolKey = hex.EncodeToString(h.Sum(nil))
+	}
+
+	return service, nil
 }
 
 // connectionDatabase determines the database to which the connection was made
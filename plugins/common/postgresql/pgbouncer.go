@@ -0,0 +1,150 @@
+package postgresql
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Columns of the PgBouncer SHOW commands not used as fields
+var pgbouncerIgnoredColumns = map[string]bool{"user": true, "database": true, "pool_mode": true,
+	"avg_req": true, "avg_recv": true, "avg_sent": true, "avg_query": true,
+	"force_user": true, "host": true, "port": true, "name": true,
+}
+
+// GatherPgBouncerStats executes SHOW STATS on the PgBouncer admin console and
+// adds the statistics per database as "pgbouncer" measurement
+func (p *Service) GatherPgBouncerStats(acc telegraf.Accumulator) error {
+	rows, err := p.showRows("STATS")
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		tags, fields, err := pgbouncerStats(row)
+		if err != nil {
+			return fmt.Errorf("couldn't convert metrics 'show stats': %w", err)
+		}
+		tags["server"] = p.SanitizedAddress
+		acc.AddFields("pgbouncer", fields, tags)
+	}
+	return nil
+}
+
+// GatherPgBouncerPools executes SHOW POOLS on the PgBouncer admin console and
+// adds the statistics per pool as "pgbouncer_pools" measurement
+func (p *Service) GatherPgBouncerPools(acc telegraf.Accumulator) error {
+	rows, err := p.showRows("POOLS")
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		tags, fields, err := pgbouncerPools(row)
+		if err != nil {
+			return fmt.Errorf("couldn't convert metrics 'show pools': %w", err)
+		}
+		tags["server"] = p.SanitizedAddress
+		acc.AddFields("pgbouncer_pools", fields, tags)
+	}
+	return nil
+}
+
+// showRows executes the given SHOW command and returns the rows as maps of
+// column names to values
+func (p *Service) showRows(command string) ([]map[string]interface{}, error) {
+	rows, err := p.DB.Query("SHOW " + command)
+	if err != nil {
+		return nil, fmt.Errorf("execution error 'show %s': %w", command, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("don't get column names 'show %s': %w", command, err)
+	}
+
+	var result []map[string]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, fmt.Errorf("couldn't copy the data: %w", err)
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, c := range columns {
+			row[c] = values[i]
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// pgbouncerStats converts a row of SHOW STATS, all counters are returned as
+// integers independent of the PgBouncer version
+func pgbouncerStats(row map[string]interface{}) (map[string]string, map[string]interface{}, error) {
+	tags, err := pgbouncerTags(row)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fields := make(map[string]interface{}, len(row))
+	for col, val := range row {
+		if pgbouncerIgnoredColumns[col] {
+			continue
+		}
+		switch v := val.(type) {
+		case int64:
+			// Integer fields are returned in pgbouncer 1.5 through 1.9
+			fields[col] = v
+		case string:
+			// Numeric fields are returned as string in pgbouncer 1.12 and later
+			integer, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("column %q: %w", col, err)
+			}
+			fields[col] = integer
+		}
+	}
+	return tags, fields, nil
+}
+
+// pgbouncerPools converts a row of SHOW POOLS using the user and pool mode
+// as additional tags
+func pgbouncerPools(row map[string]interface{}) (map[string]string, map[string]interface{}, error) {
+	tags, err := pgbouncerTags(row)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s, ok := row["user"].(string); ok && s != "" {
+		tags["user"] = s
+	}
+	if s, ok := row["pool_mode"].(string); ok && s != "" {
+		tags["pool_mode"] = s
+	}
+
+	fields := make(map[string]interface{}, len(row))
+	for col, val := range row {
+		if !pgbouncerIgnoredColumns[col] {
+			fields[col] = val
+		}
+	}
+	return tags, fields, nil
+}
+
+func pgbouncerTags(row map[string]interface{}) (map[string]string, error) {
+	database, found := row["database"]
+	if !found {
+		return map[string]string{"db": "pgbouncer"}, nil
+	}
+	name, ok := database.(string)
+	if !ok {
+		return nil, fmt.Errorf("database not a string, but %T", database)
+	}
+	return map[string]string{"db": name}, nil
+}
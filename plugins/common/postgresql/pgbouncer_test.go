@@ -0,0 +1,75 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgBouncerStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		row      map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name: "integers",
+			row: map[string]interface{}{
+				"database":          "app",
+				"total_query_count": int64(42),
+				"total_xact_count":  int64(21),
+				"avg_query":         int64(5),
+			},
+			expected: map[string]interface{}{
+				"total_query_count": int64(42),
+				"total_xact_count":  int64(21),
+			},
+		},
+		{
+			name: "numeric strings",
+			row: map[string]interface{}{
+				"database":          "app",
+				"total_query_count": "42",
+				"total_xact_count":  "21",
+				"avg_query_time":    "1234",
+			},
+			expected: map[string]interface{}{
+				"total_query_count": int64(42),
+				"total_xact_count":  int64(21),
+				"avg_query_time":    int64(1234),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, fields, err := pgbouncerStats(tt.row)
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{"db": "app"}, tags)
+			require.Equal(t, tt.expected, fields)
+		})
+	}
+}
+
+func TestPgBouncerStatsInvalid(t *testing.T) {
+	_, _, err := pgbouncerStats(map[string]interface{}{"database": "app", "total_query_count": "foo"})
+	require.ErrorContains(t, err, `column "total_query_count"`)
+
+	_, _, err = pgbouncerStats(map[string]interface{}{"database": int64(1)})
+	require.ErrorContains(t, err, "database not a string, but int64")
+}
+
+func TestPgBouncerPools(t *testing.T) {
+	row := map[string]interface{}{
+		"database":  "app",
+		"user":      "telegraf",
+		"pool_mode": "transaction",
+		"cl_active": int64(3),
+		"sv_idle":   int64(1),
+		"maxwait":   int64(0),
+	}
+	tags, fields, err := pgbouncerPools(row)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"db": "app", "user": "telegraf", "pool_mode": "transaction"}, tags)
+	require.Equal(t, map[string]interface{}{"cl_active": int64(3), "sv_idle": int64(1), "maxwait": int64(0)}, fields)
+}
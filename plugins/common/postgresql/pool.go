@@ -0,0 +1,81 @@
+package postgresql
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// pools holds the connection pools shared between plugin instances
+// connecting to the same server with the same settings
+var pools = struct {
+	sync.Mutex
+	entries map[string]*sharedPool
+}{entries: make(map[string]*sharedPool)}
+
+type sharedPool struct {
+	db   *sql.DB
+	refs int
+
+	maxIdle     int
+	maxOpen     int
+	maxLifetime time.Duration
+}
+
+// acquirePool returns the pool for the given key, creating it if necessary.
+// The pool limits are widened to satisfy all users of the pool, i.e. the
+// largest number of connections and the shortest lifetime is used.
+func acquirePool(key, dsn string, maxIdle, maxOpen int, maxLifetime time.Duration) (*sql.DB, error) {
+	pools.Lock()
+	defer pools.Unlock()
+
+	p, found := pools.entries[key]
+	if !found {
+		db, err := sql.Open("pgx", dsn)
+		if err != nil {
+			return nil, err
+		}
+		p = &sharedPool{
+			db:          db,
+			maxIdle:     maxIdle,
+			maxOpen:     maxOpen,
+			maxLifetime: maxLifetime,
+		}
+		pools.entries[key] = p
+	} else {
+		p.maxIdle = max(p.maxIdle, maxIdle)
+		// Zero means unlimited connections
+		if p.maxOpen > 0 && (maxOpen <= 0 || maxOpen > p.maxOpen) {
+			p.maxOpen = maxOpen
+		}
+		// Zero means connections are reused forever
+		if maxLifetime > 0 && (p.maxLifetime == 0 || maxLifetime < p.maxLifetime) {
+			p.maxLifetime = maxLifetime
+		}
+	}
+	p.refs++
+
+	p.db.SetMaxOpenConns(p.maxOpen)
+	p.db.SetMaxIdleConns(p.maxIdle)
+	p.db.SetConnMaxLifetime(p.maxLifetime)
+
+	return p.db, nil
+}
+
+// releasePool drops a reference to the pool with the given key and closes
+// the pool if it is not used anymore
+func releasePool(key string) {
+	pools.Lock()
+	defer pools.Unlock()
+
+	p, found := pools.entries[key]
+	if !found {
+		return
+	}
+	p.refs--
+	if p.refs > 0 {
+		return
+	}
+	delete(pools.entries, key)
+	p.db.Close()
+}
@@ -0,0 +1,113 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestSharedPool(t *testing.T) {
+	cfgA := &Config{
+		Address:     config.NewSecret([]byte("host=localhost user=postgres password=secret sslmode=disable")),
+		MaxIdle:     1,
+		MaxOpen:     1,
+		MaxLifetime: config.Duration(time.Hour),
+		SharedPool:  true,
+	}
+	cfgB := &Config{
+		Address:     config.NewSecret([]byte("host=localhost user=postgres password=secret sslmode=disable")),
+		MaxIdle:     2,
+		MaxOpen:     3,
+		MaxLifetime: config.Duration(time.Minute),
+		SharedPool:  true,
+	}
+	// Different protocol settings must not share the connections
+	cfgC := &Config{
+		Address:     config.NewSecret([]byte("host=localhost user=postgres password=secret sslmode=disable")),
+		MaxIdle:     1,
+		MaxOpen:     1,
+		SharedPool:  true,
+		IsPgBouncer: true,
+	}
+
+	serviceA, err := cfgA.CreateService()
+	require.NoError(t, err)
+	serviceB, err := cfgB.CreateService()
+	require.NoError(t, err)
+	serviceC, err := cfgC.CreateService()
+	require.NoError(t, err)
+
+	require.NotEmpty(t, serviceA.poolKey)
+	require.NotContains(t, serviceA.poolKey, "secret")
+	require.Equal(t, serviceA.poolKey, serviceB.poolKey)
+	require.NotEqual(t, serviceA.poolKey, serviceC.poolKey)
+
+	require.NoError(t, serviceA.Start())
+	require.NoError(t, serviceB.Start())
+	require.NoError(t, serviceC.Start())
+	require.Same(t, serviceA.DB, serviceB.DB)
+	require.NotSame(t, serviceA.DB, serviceC.DB)
+
+	pools.Lock()
+	p := pools.entries[serviceA.poolKey]
+	require.Equal(t, 2, p.refs)
+	require.Equal(t, 2, p.maxIdle)
+	require.Equal(t, 3, p.maxOpen)
+	require.Equal(t, time.Minute, p.maxLifetime)
+	pools.Unlock()
+	require.Equal(t, 3, serviceA.DB.Stats().MaxOpenConnections)
+
+	// The pool must stay open until the last user stops
+	serviceA.Stop()
+	require.Nil(t, serviceA.DB)
+	require.NotNil(t, serviceB.DB)
+	pools.Lock()
+	require.Contains(t, pools.entries, serviceB.poolKey)
+	pools.Unlock()
+
+	serviceB.Stop()
+	serviceC.Stop()
+	pools.Lock()
+	require.Empty(t, pools.entries)
+	pools.Unlock()
+}
+
+func TestSharedPoolUnlimited(t *testing.T) {
+	p1, err := acquirePool("unlimited", "host=localhost", 1, 1, 0)
+	require.NoError(t, err)
+	p2, err := acquirePool("unlimited", "host=localhost", 1, 0, time.Minute)
+	require.NoError(t, err)
+	require.Same(t, p1, p2)
+
+	pools.Lock()
+	p := pools.entries["unlimited"]
+	require.Equal(t, 0, p.maxOpen)
+	require.Equal(t, time.Minute, p.maxLifetime)
+	pools.Unlock()
+
+	releasePool("unlimited")
+	releasePool("unlimited")
+	pools.Lock()
+	require.Empty(t, pools.entries)
+	pools.Unlock()
+}
+
+func TestNotSharedPool(t *testing.T) {
+	cfg := &Config{
+		Address: config.NewSecret([]byte("host=localhost user=postgres sslmode=disable")),
+		MaxIdle: 1,
+		MaxOpen: 1,
+	}
+	service, err := cfg.CreateService()
+	require.NoError(t, err)
+	require.Empty(t, service.poolKey)
+
+	require.NoError(t, service.Start())
+	pools.Lock()
+	require.Empty(t, pools.entries)
+	pools.Unlock()
+	service.Stop()
+}
@@ -15,13 +15,28 @@ type Service struct {
 	SanitizedAddress   string
 	ConnectionDatabase string
 
+	// PgBouncerConsole is set when connected to the admin console of PgBouncer
+	PgBouncerConsole bool
+
 	dsn         string
 	maxIdle     int
 	maxOpen     int
 	maxLifetime time.Duration
+
+	// Key of the shared pool, empty if the pool is not shared
+	poolKey string
 }
 
 func (p *Service) Start() error {
+	if p.poolKey != "" {
+		db, err := acquirePool(p.poolKey, p.dsn, p.maxIdle, p.maxOpen, p.maxLifetime)
+		if err != nil {
+			return err
+		}
+		p.DB = db
+		return nil
+	}
+
 	db, err := sql.Open("pgx", p.dsn)
 	if err != nil {
 		return err
@@ -36,7 +51,13 @@ func (p *Service) Start() error {
 }
 
 func (p *Service) Stop() {
-	if p.DB != nil {
-		p.DB.Close()
+	if p.DB == nil {
+		return
+	}
+	if p.poolKey != "" {
+		releasePool(p.poolKey)
+		p.DB = nil
+		return
 	}
+	p.DB.Close()
 }
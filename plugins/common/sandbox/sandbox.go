@@ -0,0 +1,95 @@
+package sandbox
+
+import (
+	"fmt"
+	"os/exec"
+	"os/user"
+	"strconv"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+// Config contains the settings for restricting child processes
+type Config struct {
+	RunAsUser      string          `toml:"run_as_user"`
+	LimitCPUTime   config.Duration `toml:"limit_cpu_time"`
+	LimitMemory    config.Size     `toml:"limit_memory"`
+	LimitOpenFiles uint64          `toml:"limit_open_files"`
+	NoNetwork      bool            `toml:"no_network"`
+
+	credential *credential
+}
+
+type credential struct {
+	uid    uint32
+	gid    uint32
+	groups []uint32
+}
+
+// Init checks the settings for being supported on the current platform and
+// resolves the user to run the processes as
+func (c *Config) Init() error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	if c.RunAsUser == "" {
+		return nil
+	}
+	u, err := user.Lookup(c.RunAsUser)
+	if err != nil {
+		if u, err = user.LookupId(c.RunAsUser); err != nil {
+			return fmt.Errorf("looking up user %q failed: %w", c.RunAsUser, err)
+		}
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid uid %q of user %q: %w", u.Uid, c.RunAsUser, err)
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid gid %q of user %q: %w", u.Gid, c.RunAsUser, err)
+	}
+	c.credential = &credential{uid: uint32(uid), gid: uint32(gid)}
+
+	groups, err := u.GroupIds()
+	if err != nil {
+		return fmt.Errorf("looking up groups of user %q failed: %w", c.RunAsUser, err)
+	}
+	for _, g := range groups {
+		id, err := strconv.ParseUint(g, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid group %q of user %q: %w", g, c.RunAsUser, err)
+		}
+		c.credential.groups = append(c.credential.groups, uint32(id))
+	}
+
+	return nil
+}
+
+// Enabled returns true if any restriction is configured
+func (c *Config) Enabled() bool {
+	return c.RunAsUser != "" || c.hasLimits() || c.NoNetwork
+}
+
+func (c *Config) hasLimits() bool {
+	return c.LimitCPUTime > 0 || c.LimitMemory > 0 || c.LimitOpenFiles > 0
+}
+
+// StartProcess starts the given command with the configured restrictions.
+// Resource limits are applied directly after starting the process, if applying
+// the limits fails the process is killed.
+func (c *Config) StartProcess(cmd *exec.Cmd) error {
+	c.prepare(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if err := c.restrict(cmd.Process.Pid); err != nil {
+		//nolint:errcheck // Process will be reaped by wait, error is not relevant
+		cmd.Process.Kill()
+		//nolint:errcheck // Error is expected as the process was killed
+		cmd.Wait()
+		return fmt.Errorf("applying resource limits failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+func (*Config) validate() error {
+	return nil
+}
+
+func (c *Config) prepare(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	if c.credential != nil {
+		cmd.SysProcAttr.Credential = &syscall.Credential{
+			Uid:    c.credential.uid,
+			Gid:    c.credential.gid,
+			Groups: c.credential.groups,
+		}
+	}
+	if c.NoNetwork {
+		cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWNET
+		// Creating a network namespace requires CAP_SYS_ADMIN, so unprivileged
+		// instances need to create a user namespace mapping the current user
+		if os.Geteuid() != 0 && c.credential == nil {
+			cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWUSER
+			cmd.SysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+			cmd.SysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+		}
+	}
+}
+
+func (c *Config) restrict(pid int) error {
+	if c.LimitCPUTime > 0 {
+		// The CPU limit is specified in seconds, round up to avoid a zero limit
+		seconds := uint64((c.LimitCPUTime + 999999999) / 1000000000)
+		if err := prlimit(pid, unix.RLIMIT_CPU, seconds); err != nil {
+			return err
+		}
+	}
+	if c.LimitMemory > 0 {
+		if err := prlimit(pid, unix.RLIMIT_AS, uint64(c.LimitMemory)); err != nil {
+			return err
+		}
+	}
+	if c.LimitOpenFiles > 0 {
+		if err := prlimit(pid, unix.RLIMIT_NOFILE, c.LimitOpenFiles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func prlimit(pid, resource int, value uint64) error {
+	limit := &unix.Rlimit{Cur: value, Max: value}
+	return unix.Prlimit(pid, resource, limit, nil)
+}
@@ -0,0 +1,37 @@
+//go:build !linux && !windows
+
+package sandbox
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+)
+
+func (c *Config) validate() error {
+	if c.hasLimits() {
+		return errors.New("resource limits are only supported on Linux")
+	}
+	if c.NoNetwork {
+		return errors.New("'no_network' is only supported on Linux")
+	}
+	return nil
+}
+
+func (c *Config) prepare(cmd *exec.Cmd) {
+	if c.credential == nil {
+		return
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid:    c.credential.uid,
+		Gid:    c.credential.gid,
+		Groups: c.credential.groups,
+	}
+}
+
+func (*Config) restrict(int) error {
+	return nil
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package sandbox
+
+import (
+	"errors"
+	"os/exec"
+)
+
+func (c *Config) validate() error {
+	if c.Enabled() {
+		return errors.New("sandboxing is not supported on Windows")
+	}
+	return nil
+}
+
+func (*Config) prepare(*exec.Cmd) {}
+
+func (*Config) restrict(int) error {
+	return nil
+}
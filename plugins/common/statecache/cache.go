@@ -0,0 +1,70 @@
+package statecache
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Cache keeps the last-known state of telemetry series. Devices sending
+// updates on change only transmit the modified leaves, so the cache merges
+// those partial updates to provide complete metrics for each series.
+type Cache struct {
+	expiry time.Duration
+
+	series map[uint64]*entry
+	mu     sync.Mutex
+}
+
+type entry struct {
+	metric   telegraf.Metric
+	received time.Time
+}
+
+// AddMetric merges the fields of the given metric into the state of the
+// series identified by the metric's name and tags
+func (c *Cache) AddMetric(m telegraf.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := m.HashID()
+	e, found := c.series[id]
+	if !found {
+		c.series[id] = &entry{metric: m.Copy(), received: time.Now()}
+		return
+	}
+
+	for _, field := range m.FieldList() {
+		e.metric.AddField(field.Key, field.Value)
+	}
+	e.received = time.Now()
+}
+
+// Snapshot returns the current state of all series with the given timestamp
+// and removes series without updates within the expiry duration
+func (c *Cache) Snapshot(ts time.Time) []telegraf.Metric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := make([]uint64, 0, len(c.series))
+	for id, e := range c.series {
+		if c.expiry > 0 && ts.Sub(e.received) > c.expiry {
+			delete(c.series, id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	// Sort the series to provide a stable output order
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	metrics := make([]telegraf.Metric, 0, len(ids))
+	for _, id := range ids {
+		m := c.series[id].metric.Copy()
+		m.SetTime(ts)
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
@@ -0,0 +1,100 @@
+package statecache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestDisabled(t *testing.T) {
+	cfg := &CacheConfig{}
+	cache, err := cfg.CreateCache()
+	require.NoError(t, err)
+	require.Nil(t, cache)
+}
+
+func TestInvalidExpiry(t *testing.T) {
+	cfg := &CacheConfig{Snapshot: true, Expiry: config.Duration(-time.Second)}
+	_, err := cfg.CreateCache()
+	require.ErrorContains(t, err, "invalid negative state expiry")
+}
+
+func TestSnapshot(t *testing.T) {
+	cfg := &CacheConfig{Snapshot: true}
+	cache, err := cfg.CreateCache()
+	require.NoError(t, err)
+
+	// Initial state followed by on-change updates of single leaves
+	cache.AddMetric(metric.New(
+		"ifcounters",
+		map[string]string{"source": "router1", "name": "eth0"},
+		map[string]interface{}{"in_octets": uint64(100), "out_octets": uint64(200), "oper_status": "UP"},
+		time.Unix(1, 0),
+	))
+	cache.AddMetric(metric.New(
+		"ifcounters",
+		map[string]string{"source": "router1", "name": "eth1"},
+		map[string]interface{}{"in_octets": uint64(10), "oper_status": "DOWN"},
+		time.Unix(1, 0),
+	))
+	cache.AddMetric(metric.New(
+		"ifcounters",
+		map[string]string{"source": "router1", "name": "eth0"},
+		map[string]interface{}{"in_octets": uint64(150)},
+		time.Unix(2, 0),
+	))
+	cache.AddMetric(metric.New(
+		"ifcounters",
+		map[string]string{"source": "router1", "name": "eth1"},
+		map[string]interface{}{"oper_status": "UP"},
+		time.Unix(3, 0),
+	))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ifcounters",
+			map[string]string{"source": "router1", "name": "eth0"},
+			map[string]interface{}{"in_octets": uint64(150), "out_octets": uint64(200), "oper_status": "UP"},
+			time.Unix(10, 0),
+		),
+		metric.New(
+			"ifcounters",
+			map[string]string{"source": "router1", "name": "eth1"},
+			map[string]interface{}{"in_octets": uint64(10), "oper_status": "UP"},
+			time.Unix(10, 0),
+		),
+	}
+
+	// Snapshots do not consume the state
+	for range 2 {
+		actual := cache.Snapshot(time.Unix(10, 0))
+		testutil.RequireMetricsEqual(t, expected, actual, testutil.SortMetrics())
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	cfg := &CacheConfig{Snapshot: true, Expiry: config.Duration(time.Minute)}
+	cache, err := cfg.CreateCache()
+	require.NoError(t, err)
+
+	input := metric.New(
+		"ifcounters",
+		map[string]string{"source": "router1", "name": "eth0"},
+		map[string]interface{}{"in_octets": uint64(100)},
+		time.Unix(1, 0),
+	)
+	cache.AddMetric(input)
+
+	now := time.Now()
+	require.Len(t, cache.Snapshot(now), 1)
+	require.Empty(t, cache.Snapshot(now.Add(2*time.Minute)))
+
+	// Expired series are removed from the state
+	require.Empty(t, cache.Snapshot(now))
+}
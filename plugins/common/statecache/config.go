@@ -0,0 +1,27 @@
+package statecache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+type CacheConfig struct {
+	Snapshot bool            `toml:"state_snapshot"`
+	Expiry   config.Duration `toml:"state_expiry"`
+}
+
+// CreateCache returns a new device-state cache or nil if snapshots are disabled
+func (cfg *CacheConfig) CreateCache() (*Cache, error) {
+	if !cfg.Snapshot {
+		return nil, nil
+	}
+	if cfg.Expiry < 0 {
+		return nil, errors.New("invalid negative state expiry")
+	}
+	return &Cache{
+		expiry: time.Duration(cfg.Expiry),
+		series: make(map[uint64]*entry),
+	}, nil
+}
@@ -0,0 +1,63 @@
+package submission
+
+import (
+	"errors"
+	"time"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+// Config contains the settings shared by outputs submitting metrics to
+// remote SaaS endpoints.
+type Config struct {
+	MaxPayloadSize config.Size `toml:"max_payload_size"`
+	RequestConfig
+}
+
+// RequestConfig contains the settings for rate limiting and retrying
+// requests. Use it directly for outputs not controlling the payload.
+type RequestConfig struct {
+	RequestsPerSecond float64         `toml:"requests_per_second"`
+	RequestsBurst     int             `toml:"requests_burst"`
+	MaxRetries        int             `toml:"max_retries"`
+	RetryBackoff      config.Duration `toml:"retry_backoff"`
+}
+
+// NewQueue creates a submission queue from the given settings
+func (cfg *Config) NewQueue() (*Queue, error) {
+	q, err := cfg.RequestConfig.NewQueue()
+	if err != nil {
+		return nil, err
+	}
+	q.maxPayloadSize = int(cfg.MaxPayloadSize)
+	return q, nil
+}
+
+// NewQueue creates a submission queue without payload size limit
+func (cfg *RequestConfig) NewQueue() (*Queue, error) {
+	if cfg.RequestsPerSecond < 0 {
+		return nil, errors.New("invalid negative 'requests_per_second'")
+	}
+	if cfg.RequestsBurst < 0 {
+		return nil, errors.New("invalid negative 'requests_burst'")
+	}
+	if cfg.MaxRetries < 0 {
+		return nil, errors.New("invalid negative 'max_retries'")
+	}
+	if cfg.RetryBackoff < 0 {
+		return nil, errors.New("invalid negative 'retry_backoff'")
+	}
+
+	q := &Queue{
+		maxRetries:   cfg.MaxRetries,
+		retryBackoff: time.Duration(cfg.RetryBackoff),
+	}
+	if cfg.RequestsPerSecond > 0 {
+		burst := cfg.RequestsBurst
+		if burst == 0 {
+			burst = 1
+		}
+		q.limiter = newTokenBucket(cfg.RequestsPerSecond, burst)
+	}
+	return q, nil
+}
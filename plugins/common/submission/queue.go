@@ -0,0 +1,216 @@
+package submission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// ErrPayloadTooLarge is reported for items that exceed the maximum payload
+// size even when sent on their own.
+var ErrPayloadTooLarge = errors.New("payload exceeds maximum size")
+
+// RetryableError marks a failed request as temporary so the queue retries
+// the same payload. If RetryAfter is set, it takes precedence over the
+// configured backoff, e.g. for honoring a 'Retry-After' header.
+type RetryableError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// RejectedError marks a payload as permanently refused by the remote side,
+// e.g. due to malformed data. The contained items are rejected and the
+// submission continues with the next payload.
+type RejectedError struct {
+	Err error
+}
+
+func (e *RejectedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RejectedError) Unwrap() error {
+	return e.Err
+}
+
+// Result contains the indices of the items accepted or rejected by the
+// remote side. Items not in either list were not submitted and should be
+// retried on the next write.
+type Result struct {
+	Accepted     []int
+	Rejected     []int
+	RejectErrors []error
+}
+
+// Queue submits items in payloads not exceeding the configured size while
+// limiting the request rate and retrying temporary failures.
+type Queue struct {
+	maxPayloadSize int
+	maxRetries     int
+	retryBackoff   time.Duration
+	limiter        *tokenBucket
+}
+
+type span struct {
+	start, end int
+}
+
+// Submit encodes the items into one or more payloads and sends them in
+// order. Payloads exceeding the maximum size are split in half until they
+// fit. Requests failing with a RejectedError reject the contained items and
+// processing continues with the next payload. Submission stops at the first
+// payload failing with any other error after all retries, returning the
+// error together with the result collected so far.
+func Submit[T any](
+	ctx context.Context,
+	q *Queue,
+	items []T,
+	encode func([]T) ([]byte, error),
+	send func(context.Context, []byte) error,
+) (Result, error) {
+	var result Result
+	if len(items) == 0 {
+		return result, nil
+	}
+
+	reject := func(s span, err error) {
+		for i := s.start; i < s.end; i++ {
+			result.Rejected = append(result.Rejected, i)
+			result.RejectErrors = append(result.RejectErrors, err)
+		}
+	}
+
+	pending := []span{{0, len(items)}}
+	for len(pending) > 0 {
+		s := pending[0]
+		pending = pending[1:]
+
+		payload, err := encode(items[s.start:s.end])
+		if err != nil {
+			reject(s, fmt.Errorf("encoding failed: %w", err))
+			continue
+		}
+
+		if q.maxPayloadSize > 0 && len(payload) > q.maxPayloadSize {
+			if s.end-s.start == 1 {
+				reject(s, fmt.Errorf("%w: %d > %d bytes", ErrPayloadTooLarge, len(payload), q.maxPayloadSize))
+				continue
+			}
+			mid := s.start + (s.end-s.start)/2
+			pending = append([]span{{s.start, mid}, {mid, s.end}}, pending...)
+			continue
+		}
+
+		err = q.Do(ctx, func(ctx context.Context) error { return send(ctx, payload) })
+		if err != nil {
+			var rerr *RejectedError
+			if !errors.As(err, &rerr) {
+				return result, err
+			}
+			reject(s, rerr.Err)
+			continue
+		}
+		for i := s.start; i < s.end; i++ {
+			result.Accepted = append(result.Accepted, i)
+		}
+	}
+
+	return result, nil
+}
+
+// Do executes the given request after waiting for the rate limit and retries
+// it as long as it fails with a RetryableError and retries are left.
+func (q *Queue) Do(ctx context.Context, request func(context.Context) error) error {
+	backoff := q.retryBackoff
+	for attempt := 0; ; attempt++ {
+		if q.limiter != nil {
+			if err := q.limiter.wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		err := request(ctx)
+		if err == nil {
+			return nil
+		}
+
+		var rerr *RetryableError
+		if !errors.As(err, &rerr) || attempt >= q.maxRetries {
+			return err
+		}
+
+		delay := backoff
+		if rerr.RetryAfter > 0 {
+			delay = rerr.RetryAfter
+		}
+		backoff *= 2
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// WriteError converts the result of submitting items created from a batch
+// of metrics into an error for the output model. Here, origins[i] is the
+// index of the metric item i was created from and n is the size of the
+// batch. Metrics without any items are accepted and metrics with at least
+// one item not submitted are kept for the next write.
+func (r Result) WriteError(err error, origins []int, n int) error {
+	if err != nil && len(r.Accepted) == 0 && len(r.Rejected) == 0 {
+		return err
+	}
+
+	submitted := make([]bool, len(origins))
+	for _, i := range r.Accepted {
+		submitted[i] = true
+	}
+	rejectErrors := make(map[int]error, len(r.Rejected))
+	for j, i := range r.Rejected {
+		submitted[i] = true
+		if _, found := rejectErrors[origins[i]]; !found {
+			rejectErrors[origins[i]] = r.RejectErrors[j]
+		}
+	}
+	kept := make(map[int]bool)
+	for i, done := range submitted {
+		if !done {
+			kept[origins[i]] = true
+		}
+	}
+	if len(kept) == 0 && len(rejectErrors) == 0 {
+		return nil
+	}
+
+	werr := &internal.PartialWriteError{Err: err}
+	for i := range n {
+		if kept[i] {
+			continue
+		}
+		if rerr, found := rejectErrors[i]; found {
+			werr.MetricsReject = append(werr.MetricsReject, i)
+			werr.MetricsRejectErrors = append(werr.MetricsRejectErrors, rerr)
+			continue
+		}
+		werr.MetricsAccept = append(werr.MetricsAccept, i)
+	}
+	if werr.Err == nil {
+		werr.Err = fmt.Errorf("%d metrics rejected", len(werr.MetricsReject))
+	}
+	return werr
+}
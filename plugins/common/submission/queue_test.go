@@ -0,0 +1,292 @@
+package submission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+)
+
+func encodeJSON(items []string) ([]byte, error) {
+	return json.Marshal(items)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *Config
+		expected string
+	}{
+		{
+			name:     "negative rate",
+			cfg:      &Config{RequestConfig: RequestConfig{RequestsPerSecond: -1}},
+			expected: "invalid negative 'requests_per_second'",
+		},
+		{
+			name:     "negative burst",
+			cfg:      &Config{RequestConfig: RequestConfig{RequestsBurst: -1}},
+			expected: "invalid negative 'requests_burst'",
+		},
+		{
+			name:     "negative retries",
+			cfg:      &Config{RequestConfig: RequestConfig{MaxRetries: -1}},
+			expected: "invalid negative 'max_retries'",
+		},
+		{
+			name:     "negative backoff",
+			cfg:      &Config{RequestConfig: RequestConfig{RetryBackoff: config.Duration(-time.Second)}},
+			expected: "invalid negative 'retry_backoff'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.cfg.NewQueue()
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestSubmitSingle(t *testing.T) {
+	cfg := &Config{}
+	q, err := cfg.NewQueue()
+	require.NoError(t, err)
+
+	var payloads []string
+	send := func(_ context.Context, payload []byte) error {
+		payloads = append(payloads, string(payload))
+		return nil
+	}
+
+	result, err := Submit(t.Context(), q, []string{"a", "b", "c"}, encodeJSON, send)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2}, result.Accepted)
+	require.Empty(t, result.Rejected)
+	require.Equal(t, []string{`["a","b","c"]`}, payloads)
+}
+
+func TestSubmitSplit(t *testing.T) {
+	cfg := &Config{MaxPayloadSize: config.Size(10)}
+	q, err := cfg.NewQueue()
+	require.NoError(t, err)
+
+	var payloads []string
+	send := func(_ context.Context, payload []byte) error {
+		payloads = append(payloads, string(payload))
+		return nil
+	}
+
+	items := []string{"a", "b", "c", "d", "this-is-too-large"}
+	result, err := Submit(t.Context(), q, items, encodeJSON, send)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3}, result.Accepted)
+	require.Equal(t, []int{4}, result.Rejected)
+	require.ErrorIs(t, result.RejectErrors[0], ErrPayloadTooLarge)
+	require.Equal(t, []string{`["a","b"]`, `["c"]`, `["d"]`}, payloads)
+}
+
+func TestSubmitRetry(t *testing.T) {
+	cfg := &RequestConfig{MaxRetries: 2, RetryBackoff: config.Duration(time.Millisecond)}
+	q, err := cfg.NewQueue()
+	require.NoError(t, err)
+
+	var calls int
+	send := func(context.Context, []byte) error {
+		calls++
+		if calls < 3 {
+			return &RetryableError{Err: errors.New("too many requests")}
+		}
+		return nil
+	}
+
+	result, err := Submit(t.Context(), q, []string{"a", "b"}, encodeJSON, send)
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+	require.Equal(t, []int{0, 1}, result.Accepted)
+}
+
+func TestSubmitRetryExhausted(t *testing.T) {
+	cfg := &Config{
+		MaxPayloadSize: config.Size(10),
+		RequestConfig: RequestConfig{
+			MaxRetries:   1,
+			RetryBackoff: config.Duration(time.Millisecond),
+		},
+	}
+	q, err := cfg.NewQueue()
+	require.NoError(t, err)
+
+	var calls int
+	send := func(_ context.Context, payload []byte) error {
+		calls++
+		if string(payload) == `["c","d"]` {
+			return &RetryableError{Err: errors.New("service unavailable")}
+		}
+		return nil
+	}
+
+	result, err := Submit(t.Context(), q, []string{"a", "b", "c", "d"}, encodeJSON, send)
+	require.EqualError(t, err, "service unavailable")
+	require.Equal(t, 3, calls)
+	require.Equal(t, []int{0, 1}, result.Accepted)
+	require.Empty(t, result.Rejected)
+}
+
+func TestSubmitPermanentError(t *testing.T) {
+	cfg := &Config{MaxPayloadSize: config.Size(10), RequestConfig: RequestConfig{MaxRetries: 3}}
+	q, err := cfg.NewQueue()
+	require.NoError(t, err)
+
+	var calls int
+	send := func(_ context.Context, payload []byte) error {
+		calls++
+		if string(payload) == `["b","c"]` {
+			return &RejectedError{Err: errors.New("bad request")}
+		}
+		return nil
+	}
+
+	result, err := Submit(t.Context(), q, []string{"a", "b", "c"}, encodeJSON, send)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+	require.Equal(t, []int{0}, result.Accepted)
+	require.Equal(t, []int{1, 2}, result.Rejected)
+	require.Len(t, result.RejectErrors, 2)
+	require.EqualError(t, result.RejectErrors[0], "bad request")
+}
+
+func TestSubmitFailed(t *testing.T) {
+	cfg := &Config{MaxPayloadSize: config.Size(10), RequestConfig: RequestConfig{MaxRetries: 3}}
+	q, err := cfg.NewQueue()
+	require.NoError(t, err)
+
+	var calls int
+	send := func(context.Context, []byte) error {
+		calls++
+		return errors.New("unauthorized")
+	}
+
+	result, err := Submit(t.Context(), q, []string{"a", "b", "c"}, encodeJSON, send)
+	require.EqualError(t, err, "unauthorized")
+	require.Equal(t, 1, calls)
+	require.Empty(t, result.Accepted)
+	require.Empty(t, result.Rejected)
+}
+
+func TestSubmitRateLimited(t *testing.T) {
+	cfg := &Config{
+		MaxPayloadSize: config.Size(6),
+		RequestConfig: RequestConfig{
+			RequestsPerSecond: 20,
+			RequestsBurst:     2,
+		},
+	}
+	q, err := cfg.NewQueue()
+	require.NoError(t, err)
+
+	send := func(context.Context, []byte) error { return nil }
+
+	// Four requests with a burst of two require two more tokens at a rate
+	// of one token every 50ms.
+	start := time.Now()
+	result, err := Submit(t.Context(), q, []string{"a", "b", "c", "d"}, encodeJSON, send)
+	require.NoError(t, err)
+	require.Len(t, result.Accepted, 4)
+	require.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestSubmitCancelled(t *testing.T) {
+	cfg := &RequestConfig{MaxRetries: 10, RetryBackoff: config.Duration(time.Hour)}
+	q, err := cfg.NewQueue()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	send := func(context.Context, []byte) error {
+		cancel()
+		return &RetryableError{Err: errors.New("too many requests")}
+	}
+
+	result, err := Submit(ctx, q, []string{"a"}, encodeJSON, send)
+	require.EqualError(t, err, "too many requests")
+	require.Empty(t, result.Accepted)
+	require.Empty(t, result.Rejected)
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTokenBucket(2, 3)
+	b.now = func() time.Time { return now }
+
+	for range 3 {
+		require.Zero(t, b.reserve())
+	}
+	require.Equal(t, 500*time.Millisecond, b.reserve())
+
+	now = now.Add(250 * time.Millisecond)
+	require.Equal(t, 250*time.Millisecond, b.reserve())
+
+	now = now.Add(time.Hour)
+	for range 3 {
+		require.Zero(t, b.reserve())
+	}
+	require.Positive(t, b.reserve())
+}
+
+func TestDo(t *testing.T) {
+	cfg := &RequestConfig{MaxRetries: 1, RetryBackoff: config.Duration(time.Millisecond)}
+	q, err := cfg.NewQueue()
+	require.NoError(t, err)
+
+	var calls int
+	err = q.Do(t.Context(), func(context.Context) error {
+		calls++
+		return &RetryableError{Err: errors.New("flush failed")}
+	})
+	require.EqualError(t, err, "flush failed")
+	require.Equal(t, 2, calls)
+}
+
+func TestWriteError(t *testing.T) {
+	// Six items created from four metrics where metric 3 did not produce
+	// any item
+	origins := []int{0, 0, 1, 2, 2, 2}
+
+	// Everything accepted
+	result := Result{Accepted: []int{0, 1, 2, 3, 4, 5}}
+	require.NoError(t, result.WriteError(nil, origins, 4))
+
+	// Nothing submitted at all
+	result = Result{}
+	require.EqualError(t, result.WriteError(errors.New("failed"), origins, 4), "failed")
+
+	// Partially submitted with rejections
+	result = Result{
+		Accepted:     []int{0, 1, 3},
+		Rejected:     []int{2},
+		RejectErrors: []error{errors.New("bad request")},
+	}
+	err := result.WriteError(errors.New("failed"), origins, 4)
+	var werr *internal.PartialWriteError
+	require.ErrorAs(t, err, &werr)
+	require.EqualError(t, werr, "failed")
+	require.Equal(t, []int{0, 3}, werr.MetricsAccept)
+	require.Equal(t, []int{1}, werr.MetricsReject)
+	require.EqualError(t, werr.MetricsRejectErrors[0], "bad request")
+
+	// Fully submitted with rejections
+	result = Result{
+		Accepted:     []int{0, 1, 3, 4, 5},
+		Rejected:     []int{2},
+		RejectErrors: []error{errors.New("bad request")},
+	}
+	require.ErrorAs(t, result.WriteError(nil, origins, 4), &werr)
+	require.EqualError(t, werr, "1 metrics rejected")
+	require.Equal(t, []int{0, 2, 3}, werr.MetricsAccept)
+	require.Equal(t, []int{1}, werr.MetricsReject)
+}
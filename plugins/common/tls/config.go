@@ -28,6 +28,8 @@ type ClientConfig struct {
 	ServerName          string   `toml:"tls_server_name"`
 	RenegotiationMethod string   `toml:"tls_renegotiation_method"`
 	Enable              *bool    `toml:"tls_enable"`
+
+	policy *Policy
 }
 
 // ServerConfig represents the standard server TLS config.
@@ -40,6 +42,8 @@ type ServerConfig struct {
 	TLSMinVersion      string   `toml:"tls_min_version"`
 	TLSMaxVersion      string   `toml:"tls_max_version"`
 	TLSAllowedDNSNames []string `toml:"tls_allowed_dns_names"`
+
+	policy *Policy
 }
 
 // TLSConfig returns a tls.Config, may be nil without error if TLS is not
@@ -50,6 +54,10 @@ func (c *ClientConfig) TLSConfig() (*tls.Config, error) {
 		return nil, nil
 	}
 
+	if err := c.CheckPolicy(); err != nil {
+		return nil, err
+	}
+
 	// This check returns a nil (aka "disabled") or an empty config
 	// (aka, "use the default") if no field is set that would have an effect on
 	// a TLS connection. That is, any of:
@@ -66,7 +74,9 @@ func (c *ClientConfig) TLSConfig() (*tls.Config, error) {
 		// Check if TLS config is forcefully enabled and supposed to
 		// use the system defaults.
 		if c.Enable != nil && *c.Enable {
-			return &tls.Config{}, nil
+			tlsConfig := &tls.Config{}
+			applyPolicy(tlsConfig, c.policy)
+			return tlsConfig, nil
 		}
 
 		return nil, nil
@@ -129,6 +139,8 @@ func (c *ClientConfig) TLSConfig() (*tls.Config, error) {
 		tlsConfig.CipherSuites = cipherSuites
 	}
 
+	applyPolicy(tlsConfig, c.policy)
+
 	return tlsConfig, nil
 }
 
@@ -139,6 +151,10 @@ func (c *ServerConfig) TLSConfig() (*tls.Config, error) {
 		return nil, nil
 	}
 
+	if err := c.CheckPolicy(); err != nil {
+		return nil, err
+	}
+
 	tlsConfig := &tls.Config{}
 
 	if len(c.TLSAllowedCACerts) != 0 {
@@ -197,6 +213,8 @@ func (c *ServerConfig) TLSConfig() (*tls.Config, error) {
 		tlsConfig.VerifyPeerCertificate = c.verifyPeerCertificate
 	}
 
+	applyPolicy(tlsConfig, c.policy)
+
 	return tlsConfig, nil
 }
 
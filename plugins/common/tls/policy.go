@@ -0,0 +1,223 @@
+package tls
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Policy defines the restrictions enforced for the TLS settings of all
+// plugins, e.g. for regulated environments
+type Policy struct {
+	// MinVersion is the minimal TLS version allowed
+	MinVersion string
+	// CipherSuites is the list of allowed cipher suites, all suites are
+	// allowed if empty
+	CipherSuites []string
+	// FIPS restricts the TLS settings to FIPS 140 approved algorithms
+	FIPS bool
+
+	minVersion   uint16
+	cipherSuites []uint16
+	allowed      map[uint16]bool
+	curves       []tls.CurveID
+}
+
+// Cipher suites and curves approved for FIPS 140, the TLS 1.3 suites are not
+// configurable in Go and are therefore not listed
+var (
+	fipsCipherSuites = []uint16{
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	}
+	fipsCurves = []tls.CurveID{tls.CurveP256, tls.CurveP384, tls.CurveP521}
+)
+
+// Init validates the policy settings, an empty policy does not restrict the
+// TLS settings at all
+func (p *Policy) Init() error {
+	if p.MinVersion == "" && len(p.CipherSuites) == 0 && !p.FIPS {
+		return nil
+	}
+
+	p.minVersion = TLSMinVersionDefault
+	if p.MinVersion != "" {
+		version, err := ParseTLSVersion(p.MinVersion)
+		if err != nil {
+			return fmt.Errorf("could not parse minimal version %q: %w", p.MinVersion, err)
+		}
+		p.minVersion = version
+	}
+
+	if len(p.CipherSuites) > 0 {
+		suites, err := ParseCiphers(p.CipherSuites)
+		if err != nil {
+			return fmt.Errorf("could not parse cipher suites: %w", err)
+		}
+		p.cipherSuites = suites
+	}
+
+	if p.FIPS {
+		if p.minVersion < tls.VersionTLS12 {
+			return fmt.Errorf("minimal version %q not allowed in FIPS mode", p.MinVersion)
+		}
+		if len(p.cipherSuites) == 0 {
+			p.cipherSuites = fipsCipherSuites
+		}
+		for _, id := range p.cipherSuites {
+			if !isFIPSCipherSuite(id) {
+				return fmt.Errorf("cipher suite %q not allowed in FIPS mode", tls.CipherSuiteName(id))
+			}
+		}
+		p.curves = fipsCurves
+	}
+
+	if len(p.cipherSuites) > 0 {
+		p.allowed = make(map[uint16]bool, len(p.cipherSuites))
+		for _, id := range p.cipherSuites {
+			p.allowed[id] = true
+		}
+	}
+
+	return nil
+}
+
+// SetPolicy sets the policy enforced when creating the TLS configuration
+func (c *ClientConfig) SetPolicy(p *Policy) {
+	c.policy = p
+}
+
+// SetPolicy sets the policy enforced when creating the TLS configuration
+func (c *ServerConfig) SetPolicy(p *Policy) {
+	c.policy = p
+}
+
+// CheckPolicy returns an error if the settings violate the active policy
+func (c *ClientConfig) CheckPolicy() error {
+	if c.Enable != nil && !*c.Enable {
+		return nil
+	}
+	return checkPolicy(c.policy, c.TLSMinVersion, "", c.TLSCipherSuites, c.InsecureSkipVerify)
+}
+
+// CheckPolicy returns an error if the settings violate the active policy
+func (c *ServerConfig) CheckPolicy() error {
+	return checkPolicy(c.policy, c.TLSMinVersion, c.TLSMaxVersion, c.TLSCipherSuites, false)
+}
+
+func checkPolicy(p *Policy, minVersion, maxVersion string, cipherSuites []string, insecureSkipVerify bool) error {
+	if p == nil {
+		return nil
+	}
+
+	var errs []error
+	if minVersion != "" {
+		if version, err := ParseTLSVersion(minVersion); err == nil && version < p.minVersion {
+			errs = append(errs, fmt.Errorf("tls_min_version %q is below the minimal version allowed", minVersion))
+		}
+	}
+	if maxVersion != "" {
+		if version, err := ParseTLSVersion(maxVersion); err == nil && version < p.minVersion {
+			errs = append(errs, fmt.Errorf("tls_max_version %q is below the minimal version allowed", maxVersion))
+		}
+	}
+	if len(cipherSuites) > 0 && p.allowed != nil {
+		suites, err := ParseCiphers(cipherSuites)
+		if err == nil {
+			var forbidden []string
+			for _, id := range suites {
+				if !p.allowed[id] {
+					forbidden = append(forbidden, tls.CipherSuiteName(id))
+				}
+			}
+			if len(forbidden) > 0 {
+				errs = append(errs, fmt.Errorf("tls_cipher_suites contains suites not allowed: %s", strings.Join(forbidden, ",")))
+			}
+		}
+	}
+
+	if insecureSkipVerify && p.FIPS {
+		errs = append(errs, errors.New("insecure_skip_verify is not allowed in FIPS mode"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("settings violate TLS policy: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// applyPolicy restricts the given configuration to the given policy
+func applyPolicy(cfg *tls.Config, p *Policy) {
+	if p == nil {
+		return
+	}
+
+	cfg.MinVersion = max(cfg.MinVersion, p.minVersion)
+	if len(cfg.CipherSuites) == 0 && len(p.cipherSuites) > 0 {
+		cfg.CipherSuites = p.cipherSuites
+	}
+	if len(p.curves) > 0 {
+		cfg.CurvePreferences = p.curves
+	}
+}
+
+// ApplyPluginPolicy sets the policy for all TLS settings of the given plugin
+// and returns an error if any of the settings violates the policy
+func ApplyPluginPolicy(plugin interface{}, p *Policy) error {
+	if p == nil {
+		return nil
+	}
+	return applyValuePolicy(reflect.ValueOf(plugin), p, 0)
+}
+
+func applyValuePolicy(v reflect.Value, p *Policy, depth int) error {
+	// Limit the depth to protect against cyclic references
+	if depth > 8 {
+		return nil
+	}
+
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	if v.CanAddr() {
+		switch cfg := v.Addr().Interface().(type) {
+		case *ClientConfig:
+			cfg.SetPolicy(p)
+			return cfg.CheckPolicy()
+		case *ServerConfig:
+			cfg.SetPolicy(p)
+			return cfg.CheckPolicy()
+		}
+	}
+
+	var errs []error
+	for i := range v.NumField() {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		if err := applyValuePolicy(v.Field(i), p, depth+1); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func isFIPSCipherSuite(id uint16) bool {
+	for _, s := range fipsCipherSuites {
+		if s == id {
+			return true
+		}
+	}
+	return false
+}
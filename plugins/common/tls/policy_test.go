@@ -0,0 +1,178 @@
+package tls_test
+
+import (
+	cryptotls "crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/plugins/common/tls"
+)
+
+func TestPolicyInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   tls.Policy
+		expected string
+	}{
+		{
+			name:     "invalid version",
+			policy:   tls.Policy{MinVersion: "TLS14"},
+			expected: `could not parse minimal version "TLS14"`,
+		},
+		{
+			name:     "invalid cipher suite",
+			policy:   tls.Policy{CipherSuites: []string{"foo"}},
+			expected: "could not parse cipher suites",
+		},
+		{
+			name:     "FIPS with insufficient version",
+			policy:   tls.Policy{MinVersion: "TLS10", FIPS: true},
+			expected: `minimal version "TLS10" not allowed in FIPS mode`,
+		},
+		{
+			name:     "FIPS with non-approved cipher suite",
+			policy:   tls.Policy{CipherSuites: []string{"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"}, FIPS: true},
+			expected: `cipher suite "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256" not allowed in FIPS mode`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.policy.Init(), tt.expected)
+		})
+	}
+}
+
+func TestPolicyApplied(t *testing.T) {
+	policy := newPolicy(t, tls.Policy{MinVersion: "TLS13"})
+
+	client := tls.ClientConfig{
+		TLSCA:   pki.CACertPath(),
+		TLSCert: pki.ClientCertPath(),
+		TLSKey:  pki.ClientKeyPath(),
+	}
+	client.SetPolicy(policy)
+	cfg, err := client.TLSConfig()
+	require.NoError(t, err)
+	require.Equal(t, uint16(cryptotls.VersionTLS13), cfg.MinVersion)
+
+	server := tls.ServerConfig{
+		TLSCert:           pki.ServerCertPath(),
+		TLSKey:            pki.ServerKeyPath(),
+		TLSAllowedCACerts: []string{pki.CACertPath()},
+	}
+	server.SetPolicy(policy)
+	cfg, err = server.TLSConfig()
+	require.NoError(t, err)
+	require.Equal(t, uint16(cryptotls.VersionTLS13), cfg.MinVersion)
+
+	// Explicitly allowing older versions violates the policy
+	client.TLSMinVersion = "TLS12"
+	_, err = client.TLSConfig()
+	require.ErrorContains(t, err, `tls_min_version "TLS12" is below the minimal version allowed`)
+
+	server.TLSMaxVersion = "TLS12"
+	_, err = server.TLSConfig()
+	require.ErrorContains(t, err, `tls_max_version "TLS12" is below the minimal version allowed`)
+
+	// Disabled TLS is not affected by the policy
+	disabled := false
+	client.Enable = &disabled
+	cfg, err = client.TLSConfig()
+	require.NoError(t, err)
+	require.Nil(t, cfg)
+}
+
+func TestPolicyFIPS(t *testing.T) {
+	client := tls.ClientConfig{
+		TLSCA: pki.CACertPath(),
+	}
+	client.SetPolicy(newPolicy(t, tls.Policy{FIPS: true}))
+	cfg, err := client.TLSConfig()
+	require.NoError(t, err)
+	require.Equal(t, uint16(cryptotls.VersionTLS12), cfg.MinVersion)
+	require.ElementsMatch(t, []uint16{
+		cryptotls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		cryptotls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		cryptotls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		cryptotls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	}, cfg.CipherSuites)
+	require.Equal(t, []cryptotls.CurveID{cryptotls.CurveP256, cryptotls.CurveP384, cryptotls.CurveP521}, cfg.CurvePreferences)
+
+	// Approved cipher suites are accepted, others violate the policy
+	client.TLSCipherSuites = []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}
+	cfg, err = client.TLSConfig()
+	require.NoError(t, err)
+	require.Equal(t, []uint16{cryptotls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}, cfg.CipherSuites)
+
+	client.TLSCipherSuites = []string{"secure"}
+	_, err = client.TLSConfig()
+	require.ErrorContains(t, err, "tls_cipher_suites contains suites not allowed")
+
+	// Skipping the certificate verification is not allowed
+	client.TLSCipherSuites = nil
+	client.InsecureSkipVerify = true
+	_, err = client.TLSConfig()
+	require.ErrorContains(t, err, "insecure_skip_verify is not allowed in FIPS mode")
+}
+
+func TestPolicyAppliedToDefaults(t *testing.T) {
+	// Empty settings mean no TLS configuration even with a policy
+	var client tls.ClientConfig
+	client.SetPolicy(newPolicy(t, tls.Policy{FIPS: true}))
+	cfg, err := client.TLSConfig()
+	require.NoError(t, err)
+	require.Nil(t, cfg)
+
+	// Enabling TLS with the system defaults applies the policy
+	enabled := true
+	client.Enable = &enabled
+	cfg, err = client.TLSConfig()
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	require.Equal(t, uint16(cryptotls.VersionTLS12), cfg.MinVersion)
+	require.Len(t, cfg.CipherSuites, 4)
+	require.Equal(t, []cryptotls.CurveID{cryptotls.CurveP256, cryptotls.CurveP384, cryptotls.CurveP521}, cfg.CurvePreferences)
+}
+
+func TestApplyPluginPolicy(t *testing.T) {
+	type nested struct {
+		tls.ClientConfig
+	}
+	type plugin struct {
+		Name   string
+		Client nested
+		Server *tls.ServerConfig
+		ignore tls.ClientConfig
+	}
+
+	p := &plugin{
+		Client: nested{ClientConfig: tls.ClientConfig{TLSMinVersion: "TLS10"}},
+		Server: &tls.ServerConfig{TLSMinVersion: "TLS11"},
+		ignore: tls.ClientConfig{TLSMinVersion: "TLS10"},
+	}
+
+	// Without policy all settings are fine
+	require.NoError(t, tls.ApplyPluginPolicy(p, nil))
+	require.NoError(t, tls.ApplyPluginPolicy(p, newPolicy(t, tls.Policy{})))
+
+	err := tls.ApplyPluginPolicy(p, newPolicy(t, tls.Policy{MinVersion: "TLS12"}))
+	require.ErrorContains(t, err, `tls_min_version "TLS10" is below the minimal version allowed`)
+	require.ErrorContains(t, err, `tls_min_version "TLS11" is below the minimal version allowed`)
+
+	// The policy is set for the settings of the plugin
+	cfg, err := p.Client.TLSConfig()
+	require.ErrorContains(t, err, `tls_min_version "TLS10" is below the minimal version allowed`)
+	require.Nil(t, cfg)
+
+	p.Client.TLSMinVersion = "TLS13"
+	p.Server = nil
+	require.NoError(t, p.Client.CheckPolicy())
+}
+
+func newPolicy(t *testing.T, p tls.Policy) *tls.Policy {
+	t.Helper()
+	require.NoError(t, p.Init())
+	return &p
+}
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.ble_sensors
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/ble_sensors" // register plugin
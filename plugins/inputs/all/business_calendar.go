@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.business_calendar
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/business_calendar" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.canary
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/canary" // register plugin
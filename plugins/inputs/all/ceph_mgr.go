@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.ceph_mgr
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/ceph_mgr" // register plugin
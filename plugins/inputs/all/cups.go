@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.cups
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/cups" // register plugin
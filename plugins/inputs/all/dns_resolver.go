@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.dns_resolver
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/dns_resolver" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.ebpf
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/ebpf" // register plugin
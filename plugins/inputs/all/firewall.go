@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.firewall
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/firewall" // register plugin
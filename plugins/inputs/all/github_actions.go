@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.github_actions
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/github_actions" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.hyperv
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/hyperv" // register plugin
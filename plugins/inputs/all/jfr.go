@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.jfr
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/jfr" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.jmx
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/jmx" // register plugin
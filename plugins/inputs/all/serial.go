@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.serial
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/serial" // register plugin
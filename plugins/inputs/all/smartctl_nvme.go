@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.smartctl_nvme
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/smartctl_nvme" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.strongswan
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/strongswan" // register plugin
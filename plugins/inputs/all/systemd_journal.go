@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.systemd_journal
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/systemd_journal" // register plugin
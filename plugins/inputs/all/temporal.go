@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.temporal
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/temporal" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.weather
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/weather" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.webhook_generic
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/webhook_generic" // register plugin
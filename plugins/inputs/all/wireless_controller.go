@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.wireless_controller
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/wireless_controller" // register plugin
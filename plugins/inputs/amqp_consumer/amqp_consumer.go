@@ -16,6 +16,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -45,6 +46,11 @@ type AMQPConsumer struct {
 	QueuePassive           bool                   `toml:"queue_passive"`
 	QueueArguments         map[string]interface{} `toml:"queue_arguments"`
 	QueueConsumeArguments  map[string]string      `toml:"queue_consume_arguments"`
+	QueueType              string                 `toml:"queue_type"`
+	StreamOffset           string                 `toml:"stream_offset"`
+	DeadLetterExchange     string                 `toml:"dead_letter_exchange"`
+	DeadLetterRoutingKey   string                 `toml:"dead_letter_routing_key"`
+	DeliveryLimit          int                    `toml:"delivery_limit"`
 	BindingKey             string                 `toml:"binding_key"`
 	PrefetchCount          int                    `toml:"prefetch_count"`
 	AuthMethod             string                 `toml:"auth_method"`
@@ -107,9 +113,66 @@ func (a *AMQPConsumer) Init() error {
 		a.MaxUndeliveredMessages = 1000
 	}
 
+	// Messages exceeding the number of undelivered messages are held back
+	// in the client without being processed, so limit the prefetch count to
+	// apply the backpressure of the outputs to the broker.
+	if a.PrefetchCount > a.MaxUndeliveredMessages {
+		a.Log.Warnf("Limiting 'prefetch_count' to the 'max_undelivered_messages' value of %d", a.MaxUndeliveredMessages)
+		a.PrefetchCount = a.MaxUndeliveredMessages
+	}
+
+	if a.QueueType == "" {
+		a.QueueType = "classic"
+	}
+	if err := choice.Check(a.QueueType, []string{"classic", "quorum", "stream"}); err != nil {
+		return fmt.Errorf("config option queue_type: %w", err)
+	}
+
+	switch a.QueueType {
+	case "quorum", "stream":
+		if a.QueueDurability == "transient" {
+			return fmt.Errorf("%s queues have to be durable", a.QueueType)
+		}
+	}
+
+	if a.StreamOffset != "" {
+		if a.QueueType != "stream" {
+			return errors.New("'stream_offset' can only be used with 'stream' queues")
+		}
+		if _, found := a.QueueConsumeArguments["x-stream-offset"]; found {
+			return errors.New("'stream_offset' conflicts with 'x-stream-offset' consume argument")
+		}
+		if _, err := a.streamOffset(); err != nil {
+			return err
+		}
+	}
+
+	if a.QueueType == "stream" && (a.DeadLetterExchange != "" || a.DeliveryLimit > 0) {
+		return errors.New("dead-lettering is not supported for 'stream' queues")
+	}
+	if a.DeliveryLimit > 0 && a.QueueType != "quorum" {
+		return errors.New("'delivery_limit' can only be used with 'quorum' queues")
+	}
+	if a.DeadLetterRoutingKey != "" && a.DeadLetterExchange == "" {
+		return errors.New("'dead_letter_routing_key' requires 'dead_letter_exchange'")
+	}
+
 	return nil
 }
 
+func (a *AMQPConsumer) streamOffset() (interface{}, error) {
+	switch a.StreamOffset {
+	case "first", "last", "next":
+		return a.StreamOffset, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, a.StreamOffset)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stream offset %q", a.StreamOffset)
+	}
+	return t, nil
+}
+
 func (a *AMQPConsumer) SetParser(parser telegraf.Parser) {
 	a.parser = parser
 }
@@ -305,11 +368,7 @@ func (a *AMQPConsumer) connect(amqpConf *amqp.Config) (<-chan amqp.Delivery, err
 		return nil, fmt.Errorf("failed to set QoS: %w", err)
 	}
 
-	consumeArgs := make(amqp.Table, len(a.QueueConsumeArguments))
-	for k, v := range a.QueueConsumeArguments {
-		consumeArgs[k] = v
-	}
-
+	consumeArgs := a.consumeArguments()
 	msgs, err := ch.Consume(
 		q.Name,      // queue
 		"",          // consumer
@@ -368,11 +427,7 @@ func (a *AMQPConsumer) declareQueue(channel *amqp.Channel) (*amqp.Queue, error)
 		queueDurable = false
 	}
 
-	queueArgs := make(amqp.Table, len(a.QueueArguments))
-	for k, v := range a.QueueArguments {
-		queueArgs[k] = v
-	}
-
+	queueArgs := a.queueArguments()
 	if a.QueuePassive {
 		queue, err = channel.QueueDeclarePassive(
 			a.Queue,      // queue
@@ -398,6 +453,47 @@ func (a *AMQPConsumer) declareQueue(channel *amqp.Channel) (*amqp.Queue, error)
 	return &queue, nil
 }
 
+func (a *AMQPConsumer) queueArguments() amqp.Table {
+	args := make(amqp.Table, len(a.QueueArguments)+4)
+
+	// Keep classic queues free of the type argument to stay compatible with
+	// queues declared by previous versions of the plugin
+	if a.QueueType != "classic" {
+		args["x-queue-type"] = a.QueueType
+	}
+	if a.DeadLetterExchange != "" {
+		args["x-dead-letter-exchange"] = a.DeadLetterExchange
+	}
+	if a.DeadLetterRoutingKey != "" {
+		args["x-dead-letter-routing-key"] = a.DeadLetterRoutingKey
+	}
+	if a.DeliveryLimit > 0 {
+		args["x-delivery-limit"] = int64(a.DeliveryLimit)
+	}
+
+	// Explicitly specified arguments take precedence
+	for k, v := range a.QueueArguments {
+		args[k] = v
+	}
+
+	return args
+}
+
+func (a *AMQPConsumer) consumeArguments() amqp.Table {
+	args := make(amqp.Table, len(a.QueueConsumeArguments)+1)
+	for k, v := range a.QueueConsumeArguments {
+		args[k] = v
+	}
+
+	if a.StreamOffset != "" {
+		// The offset was checked during Init so we can ignore the error here
+		offset, _ := a.streamOffset()
+		args["x-stream-offset"] = offset
+	}
+
+	return args
+}
+
 // Read messages from queue and add them to the Accumulator
 func (a *AMQPConsumer) process(ctx context.Context, msgs <-chan amqp.Delivery, ac telegraf.Accumulator) {
 	a.deliveries = make(map[telegraf.TrackingID]amqp.Delivery)
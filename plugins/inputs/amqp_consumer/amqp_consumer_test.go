@@ -67,6 +67,135 @@ func TestAutoEncoding(t *testing.T) {
 	acc.AssertContainsFields(t, "measurementName2", map[string]interface{}{"fieldKey": "identity"})
 }
 
+func TestQueueArguments(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *AMQPConsumer
+		queue    amqp091.Table
+		consume  amqp091.Table
+		prefetch int
+	}{
+		{
+			name:     "classic",
+			plugin:   &AMQPConsumer{},
+			queue:    amqp091.Table{},
+			consume:  amqp091.Table{},
+			prefetch: 50,
+		},
+		{
+			name: "quorum with dead-lettering",
+			plugin: &AMQPConsumer{
+				QueueType:            "quorum",
+				DeadLetterExchange:   "telegraf-dlx",
+				DeadLetterRoutingKey: "failed",
+				DeliveryLimit:        3,
+				QueueArguments:       map[string]interface{}{"x-max-length": int64(100)},
+			},
+			queue: amqp091.Table{
+				"x-queue-type":              "quorum",
+				"x-dead-letter-exchange":    "telegraf-dlx",
+				"x-dead-letter-routing-key": "failed",
+				"x-delivery-limit":          int64(3),
+				"x-max-length":              int64(100),
+			},
+			consume:  amqp091.Table{},
+			prefetch: 50,
+		},
+		{
+			name: "stream with timestamp offset",
+			plugin: &AMQPConsumer{
+				QueueType:              "stream",
+				StreamOffset:           "2024-06-26T12:00:00Z",
+				PrefetchCount:          500,
+				MaxUndeliveredMessages: 100,
+			},
+			queue:    amqp091.Table{"x-queue-type": "stream"},
+			consume:  amqp091.Table{"x-stream-offset": time.Date(2024, 6, 26, 12, 0, 0, 0, time.UTC)},
+			prefetch: 100,
+		},
+		{
+			name: "stream with named offset",
+			plugin: &AMQPConsumer{
+				QueueType:    "stream",
+				StreamOffset: "first",
+			},
+			queue:    amqp091.Table{"x-queue-type": "stream"},
+			consume:  amqp091.Table{"x-stream-offset": "first"},
+			prefetch: 50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = &testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+			require.Equal(t, tt.queue, tt.plugin.queueArguments())
+			require.Equal(t, tt.consume, tt.plugin.consumeArguments())
+			require.Equal(t, tt.prefetch, tt.plugin.PrefetchCount)
+		})
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *AMQPConsumer
+		expected string
+	}{
+		{
+			name:     "invalid queue type",
+			plugin:   &AMQPConsumer{QueueType: "lazy"},
+			expected: "config option queue_type",
+		},
+		{
+			name:     "transient quorum queue",
+			plugin:   &AMQPConsumer{QueueType: "quorum", QueueDurability: "transient"},
+			expected: "quorum queues have to be durable",
+		},
+		{
+			name:     "offset for classic queue",
+			plugin:   &AMQPConsumer{StreamOffset: "first"},
+			expected: "'stream_offset' can only be used with 'stream' queues",
+		},
+		{
+			name:     "invalid offset",
+			plugin:   &AMQPConsumer{QueueType: "stream", StreamOffset: "yesterday"},
+			expected: `invalid stream offset "yesterday"`,
+		},
+		{
+			name: "conflicting offset",
+			plugin: &AMQPConsumer{
+				QueueType:             "stream",
+				StreamOffset:          "first",
+				QueueConsumeArguments: map[string]string{"x-stream-offset": "last"},
+			},
+			expected: "'stream_offset' conflicts with 'x-stream-offset' consume argument",
+		},
+		{
+			name:     "dead-lettering for streams",
+			plugin:   &AMQPConsumer{QueueType: "stream", DeadLetterExchange: "dlx"},
+			expected: "dead-lettering is not supported for 'stream' queues",
+		},
+		{
+			name:     "delivery limit for classic queue",
+			plugin:   &AMQPConsumer{DeliveryLimit: 3},
+			expected: "'delivery_limit' can only be used with 'quorum' queues",
+		},
+		{
+			name:     "routing key without exchange",
+			plugin:   &AMQPConsumer{DeadLetterRoutingKey: "failed"},
+			expected: "'dead_letter_routing_key' requires 'dead_letter_exchange'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = &testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
 func TestIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
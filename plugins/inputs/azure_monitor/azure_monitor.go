@@ -3,8 +3,11 @@ package azure_monitor
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
+	"net/http"
 	"sync"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
@@ -12,6 +15,7 @@ import (
 	receiver "github.com/logzio/azure-monitor-metrics-receiver"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -20,15 +24,19 @@ type AzureMonitor struct {
 	ClientID             string                 `toml:"client_id"`
 	ClientSecret         string                 `toml:"client_secret"`
 	TenantID             string                 `toml:"tenant_id"`
+	UseManagedIdentity   bool                   `toml:"use_managed_identity"`
 	CloudOption          string                 `toml:"cloud_option,omitempty"`
 	ResourceTargets      []*resourceTarget      `toml:"resource_target"`
 	ResourceGroupTargets []*resourceGroupTarget `toml:"resource_group_target"`
 	SubscriptionTargets  []*resource            `toml:"subscription_target"`
+	BatchTargets         []*batchTarget         `toml:"batch_target"`
+	DiscoveryInterval    config.Duration        `toml:"discovery_interval"`
 	Log                  telegraf.Logger        `toml:"-"`
 
 	receiver     *receiver.AzureMonitorMetricsReceiver
 	azureManager azureClientsCreator
 	azureClients *receiver.AzureClients
+	batch        *batchCollector
 }
 
 type resourceTarget struct {
@@ -52,7 +60,7 @@ type azureClientsManager struct{}
 
 type azureClientsCreator interface {
 	createAzureClients(subscriptionID string, clientID string, clientSecret string, tenantID string,
-		clientOptions azcore.ClientOptions) (*receiver.AzureClients, error)
+		useManagedIdentity bool, clientOptions azcore.ClientOptions) (*receiver.AzureClients, error)
 }
 
 //go:embed sample.conf
@@ -75,8 +83,24 @@ func (am *AzureMonitor) Init() error {
 		return fmt.Errorf("unknown cloud option: %s", am.CloudOption)
 	}
 
+	if am.UseManagedIdentity && am.ClientSecret != "" {
+		return errors.New("'client_secret' cannot be used together with 'use_managed_identity'")
+	}
+
+	if len(am.BatchTargets) > 0 {
+		if err := am.initBatch(clientOptions); err != nil {
+			return err
+		}
+
+		// Skip the per-resource API if only batch targets are configured
+		if len(am.ResourceTargets) == 0 && len(am.ResourceGroupTargets) == 0 && len(am.SubscriptionTargets) == 0 {
+			return nil
+		}
+	}
+
 	var err error
-	am.azureClients, err = am.azureManager.createAzureClients(am.SubscriptionID, am.ClientID, am.ClientSecret, am.TenantID, clientOptions)
+	am.azureClients, err = am.azureManager.createAzureClients(am.SubscriptionID, am.ClientID, am.ClientSecret, am.TenantID,
+		am.UseManagedIdentity, clientOptions)
 	if err != nil {
 		return err
 	}
@@ -114,6 +138,13 @@ func (am *AzureMonitor) Init() error {
 }
 
 func (am *AzureMonitor) Gather(acc telegraf.Accumulator) error {
+	if am.batch != nil {
+		am.batch.gather(acc)
+	}
+	if am.receiver == nil {
+		return nil
+	}
+
 	var waitGroup sync.WaitGroup
 
 	for _, target := range am.receiver.Targets.ResourceTargets {
@@ -142,6 +173,39 @@ func (am *AzureMonitor) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+func (am *AzureMonitor) initBatch(clientOptions azcore.ClientOptions) error {
+	for i, target := range am.BatchTargets {
+		if err := target.init(am.SubscriptionID); err != nil {
+			return fmt.Errorf("invalid batch target %d: %w", i+1, err)
+		}
+	}
+
+	if am.DiscoveryInterval <= 0 {
+		am.DiscoveryInterval = config.Duration(time.Hour)
+	}
+
+	cloudOption := am.CloudOption
+	if cloudOption == "" {
+		cloudOption = "AzurePublic"
+	}
+
+	credential, err := newCredential(am.ClientID, am.ClientSecret, am.TenantID, am.UseManagedIdentity, clientOptions)
+	if err != nil {
+		return err
+	}
+
+	am.batch = &batchCollector{
+		targets:           am.BatchTargets,
+		credential:        credential,
+		endpoints:         cloudBatchEndpoints[cloudOption],
+		discoveryInterval: time.Duration(am.DiscoveryInterval),
+		client:            &http.Client{Timeout: time.Minute},
+		log:               am.Log,
+	}
+
+	return nil
+}
+
 func (am *AzureMonitor) setReceiver() error {
 	resourceTargets := make([]*receiver.ResourceTarget, 0, len(am.ResourceTargets))
 	resourceGroupTargets := make([]*receiver.ResourceGroupTarget, 0, len(am.ResourceGroupTargets))
@@ -172,20 +236,50 @@ func (am *AzureMonitor) setReceiver() error {
 
 func (*azureClientsManager) createAzureClients(
 	subscriptionID, clientID, clientSecret, tenantID string,
+	useManagedIdentity bool,
 	clientOptions azcore.ClientOptions,
 ) (*receiver.AzureClients, error) {
 	if clientSecret != "" {
 		return receiver.CreateAzureClients(subscriptionID, clientID, clientSecret, tenantID, receiver.WithAzureClientOptions(&clientOptions))
 	}
 
-	token, err := azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{TenantID: tenantID,
-		ClientOptions: clientOptions})
+	token, err := newCredential(clientID, clientSecret, tenantID, useManagedIdentity, clientOptions)
 	if err != nil {
-		return nil, fmt.Errorf("error creating Azure token: %w", err)
+		return nil, err
 	}
 	return receiver.CreateAzureClientsWithCreds(subscriptionID, token, receiver.WithAzureClientOptions(&clientOptions))
 }
 
+// newCredential creates the credential for accessing the Azure APIs, without
+// client secret the managed identity or the default credential chain is used
+func newCredential(
+	clientID, clientSecret, tenantID string,
+	useManagedIdentity bool,
+	clientOptions azcore.ClientOptions,
+) (azcore.TokenCredential, error) {
+	var token azcore.TokenCredential
+	var err error
+	switch {
+	case clientSecret != "":
+		token, err = azidentity.NewClientSecretCredential(tenantID, clientID, clientSecret,
+			&azidentity.ClientSecretCredentialOptions{ClientOptions: clientOptions})
+	case useManagedIdentity:
+		options := &azidentity.ManagedIdentityCredentialOptions{ClientOptions: clientOptions}
+		// The client ID selects a user-assigned identity
+		if clientID != "" {
+			options.ID = azidentity.ClientID(clientID)
+		}
+		token, err = azidentity.NewManagedIdentityCredential(options)
+	default:
+		token, err = azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{TenantID: tenantID,
+			ClientOptions: clientOptions})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating Azure token: %w", err)
+	}
+	return token, nil
+}
+
 func init() {
 	inputs.Add("azure_monitor", func() telegraf.Input {
 		return &AzureMonitor{
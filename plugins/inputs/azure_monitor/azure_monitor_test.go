@@ -10,6 +10,7 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/influxdata/toml"
@@ -27,7 +28,7 @@ type mockAzureMetricDefinitionsClient struct{}
 
 type mockAzureMetricsClient struct{}
 
-func (*mockAzureClientsManager) createAzureClients(_, _, _, _ string, _ azcore.ClientOptions) (*receiver.AzureClients, error) {
+func (*mockAzureClientsManager) createAzureClients(_, _, _, _ string, _ bool, _ azcore.ClientOptions) (*receiver.AzureClients, error) {
 	return &receiver.AzureClients{
 		Ctx:                     context.Background(),
 		ResourcesClient:         &mockAzureResourcesClient{},
@@ -917,7 +918,8 @@ func TestGather_Success(t *testing.T) {
 	var clientOptions = azcore.ClientOptions{Cloud: cloud.AzurePublic}
 
 	var azureClients *receiver.AzureClients
-	azureClients, err = am.azureManager.createAzureClients(am.SubscriptionID, am.ClientID, am.ClientSecret, am.TenantID, clientOptions)
+	azureClients, err = am.azureManager.createAzureClients(am.SubscriptionID, am.ClientID, am.ClientSecret, am.TenantID,
+		am.UseManagedIdentity, clientOptions)
 	require.NoError(t, err)
 	require.NotNil(t, azureClients)
 
@@ -1004,7 +1006,8 @@ func TestGather_China_Success(t *testing.T) {
 	var clientOptions = azcore.ClientOptions{Cloud: cloud.AzureChina}
 
 	var azureClients *receiver.AzureClients
-	azureClients, err = am.azureManager.createAzureClients(am.SubscriptionID, am.ClientID, am.ClientSecret, am.TenantID, clientOptions)
+	azureClients, err = am.azureManager.createAzureClients(am.SubscriptionID, am.ClientID, am.ClientSecret, am.TenantID,
+		am.UseManagedIdentity, clientOptions)
 	require.NoError(t, err)
 	require.NotNil(t, azureClients)
 
@@ -1037,7 +1040,8 @@ func TestGather_Government_Success(t *testing.T) {
 	var clientOptions = azcore.ClientOptions{Cloud: cloud.AzureGovernment}
 
 	var azureClients *receiver.AzureClients
-	azureClients, err = am.azureManager.createAzureClients(am.SubscriptionID, am.ClientID, am.ClientSecret, am.TenantID, clientOptions)
+	azureClients, err = am.azureManager.createAzureClients(am.SubscriptionID, am.ClientID, am.ClientSecret, am.TenantID,
+		am.UseManagedIdentity, clientOptions)
 	require.NoError(t, err)
 	require.NotNil(t, azureClients)
 
@@ -1070,7 +1074,8 @@ func TestGather_Public_Success(t *testing.T) {
 	var clientOptions = azcore.ClientOptions{Cloud: cloud.AzurePublic}
 
 	var azureClients *receiver.AzureClients
-	azureClients, err = am.azureManager.createAzureClients(am.SubscriptionID, am.ClientID, am.ClientSecret, am.TenantID, clientOptions)
+	azureClients, err = am.azureManager.createAzureClients(am.SubscriptionID, am.ClientID, am.ClientSecret, am.TenantID,
+		am.UseManagedIdentity, clientOptions)
 	require.NoError(t, err)
 	require.NotNil(t, azureClients)
 
@@ -1082,3 +1087,28 @@ func TestGather_Public_Success(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, am.receiver)
 }
+
+func TestNewCredentialManagedIdentity(t *testing.T) {
+	clientOptions := azcore.ClientOptions{Cloud: cloud.AzurePublic}
+
+	// System-assigned identity
+	credential, err := newCredential("", "", "tenantID", true, clientOptions)
+	require.NoError(t, err)
+	require.IsType(t, &azidentity.ManagedIdentityCredential{}, credential)
+
+	// User-assigned identity selected by the client ID
+	credential, err = newCredential("clientID", "", "tenantID", true, clientOptions)
+	require.NoError(t, err)
+	require.IsType(t, &azidentity.ManagedIdentityCredential{}, credential)
+
+	// A client secret takes precedence over the managed identity
+	credential, err = newCredential("clientID", "clientSecret", "tenantID", true, clientOptions)
+	require.NoError(t, err)
+	require.IsType(t, &azidentity.ClientSecretCredential{}, credential)
+
+	// Creating the clients does not require contacting the identity endpoint
+	manager := &azureClientsManager{}
+	azureClients, err := manager.createAzureClients("subscriptionID", "", "", "tenantID", true, clientOptions)
+	require.NoError(t, err)
+	require.NotNil(t, azureClients)
+}
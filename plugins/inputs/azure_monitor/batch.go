@@ -0,0 +1,532 @@
+package azure_monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+)
+
+// Limits of the metrics batch API, see
+// https://learn.microsoft.com/en-us/rest/api/monitor/metrics-batch/batch
+const (
+	maxBatchResources = 50
+	maxBatchMetrics   = 20
+
+	// Number of batch requests issued concurrently
+	maxBatchRequests = 10
+
+	// Number of time grains queried to cope with the ingestion latency
+	batchTimeGrains = 5
+
+	resourceGraphAPIVersion = "2022-10-01"
+	metricsBatchAPIVersion  = "2024-02-01"
+)
+
+var batchAggregations = []string{"Average", "Count", "Maximum", "Minimum", "Total"}
+
+// Time grains supported by Azure Monitor in ISO 8601 notation
+var batchTimeGrainNames = map[time.Duration]string{
+	time.Minute:      "PT1M",
+	5 * time.Minute:  "PT5M",
+	15 * time.Minute: "PT15M",
+	30 * time.Minute: "PT30M",
+	time.Hour:        "PT1H",
+	6 * time.Hour:    "PT6H",
+	12 * time.Hour:   "PT12H",
+	24 * time.Hour:   "P1D",
+}
+
+type batchTarget struct {
+	Subscriptions []string            `toml:"subscriptions"`
+	ResourceType  string              `toml:"resource_type"`
+	Regions       []string            `toml:"regions"`
+	Filter        string              `toml:"filter"`
+	Metrics       []string            `toml:"metrics"`
+	Aggregations  []string            `toml:"aggregations"`
+	Dimensions    map[string][]string `toml:"dimensions"`
+	MaxSeries     int                 `toml:"max_series"`
+	TimeGrain     config.Duration     `toml:"time_grain"`
+
+	interval string
+	filter   string
+}
+
+// batchEndpoints holds the cloud specific endpoints for resource discovery
+// and querying metrics
+type batchEndpoints struct {
+	resourceManager string
+	metrics         string
+	metricsScope    string
+}
+
+var cloudBatchEndpoints = map[string]batchEndpoints{
+	"AzurePublic": {
+		resourceManager: "https://management.azure.com",
+		metrics:         "https://%s.metrics.monitor.azure.com",
+		metricsScope:    "https://metrics.monitor.azure.com/.default",
+	},
+	"AzureChina": {
+		resourceManager: "https://management.chinacloudapi.cn",
+		metrics:         "https://%s.metrics.monitor.azure.cn",
+		metricsScope:    "https://metrics.monitor.azure.cn/.default",
+	},
+	"AzureGovernment": {
+		resourceManager: "https://management.usgovcloudapi.net",
+		metrics:         "https://%s.metrics.monitor.azure.us",
+		metricsScope:    "https://metrics.monitor.azure.us/.default",
+	},
+}
+
+// batchCollector discovers the resources of the batch targets using Azure
+// Resource Graph and queries their metrics using the metrics batch API
+type batchCollector struct {
+	targets           []*batchTarget
+	credential        azcore.TokenCredential
+	endpoints         batchEndpoints
+	discoveryInterval time.Duration
+	client            *http.Client
+	log               telegraf.Logger
+
+	discovered time.Time
+	batches    []*resourceBatch
+}
+
+// resourceBatch is a set of resources of the same subscription, region and
+// resource type queried in a single request
+type resourceBatch struct {
+	target       *batchTarget
+	subscription string
+	region       string
+	resourceIDs  []string
+}
+
+type resourceGraphRequest struct {
+	Subscriptions []string             `json:"subscriptions"`
+	Query         string               `json:"query"`
+	Options       resourceGraphOptions `json:"options"`
+}
+
+type resourceGraphOptions struct {
+	SkipToken    string `json:"$skipToken,omitempty"`
+	Top          int    `json:"$top"`
+	ResultFormat string `json:"resultFormat"`
+}
+
+type resourceGraphResponse struct {
+	SkipToken string               `json:"$skipToken"`
+	Data      []discoveredResource `json:"data"`
+}
+
+type discoveredResource struct {
+	ID             string `json:"id"`
+	Location       string `json:"location"`
+	SubscriptionID string `json:"subscriptionId"`
+}
+
+type metricsBatchResponse struct {
+	Values []struct {
+		ResourceRegion string `json:"resourceregion"`
+		ResourceID     string `json:"resourceid"`
+		Value          []struct {
+			Name struct {
+				Value          string `json:"value"`
+				LocalizedValue string `json:"localizedValue"`
+			} `json:"name"`
+			Unit       string `json:"unit"`
+			ErrorCode  string `json:"errorCode"`
+			ErrorMsg   string `json:"errorMessage"`
+			Timeseries []struct {
+				MetadataValues []struct {
+					Name struct {
+						Value string `json:"value"`
+					} `json:"name"`
+					Value string `json:"value"`
+				} `json:"metadatavalues"`
+				Data []struct {
+					TimeStamp time.Time `json:"timeStamp"`
+					Average   *float64  `json:"average"`
+					Count     *float64  `json:"count"`
+					Maximum   *float64  `json:"maximum"`
+					Minimum   *float64  `json:"minimum"`
+					Total     *float64  `json:"total"`
+				} `json:"data"`
+			} `json:"timeseries"`
+		} `json:"value"`
+	} `json:"values"`
+}
+
+type apiError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (t *batchTarget) init(subscriptionID string) error {
+	if t.ResourceType == "" {
+		return errors.New("'resource_type' required")
+	}
+	if len(t.Subscriptions) == 0 {
+		if subscriptionID == "" {
+			return errors.New("'subscriptions' or 'subscription_id' required")
+		}
+		t.Subscriptions = []string{subscriptionID}
+	}
+	if len(t.Metrics) == 0 {
+		return errors.New("'metrics' required")
+	}
+
+	if len(t.Aggregations) == 0 {
+		t.Aggregations = slices.Clone(batchAggregations)
+	}
+	for i, a := range t.Aggregations {
+		idx := slices.IndexFunc(batchAggregations, func(s string) bool { return strings.EqualFold(s, a) })
+		if idx < 0 {
+			return fmt.Errorf("invalid aggregation %q", a)
+		}
+		t.Aggregations[i] = batchAggregations[idx]
+	}
+
+	if t.TimeGrain == 0 {
+		t.TimeGrain = config.Duration(time.Minute)
+	}
+	interval, found := batchTimeGrainNames[time.Duration(t.TimeGrain)]
+	if !found {
+		return fmt.Errorf("unsupported time grain %s", time.Duration(t.TimeGrain))
+	}
+	t.interval = interval
+
+	if t.MaxSeries < 0 {
+		return errors.New("'max_series' must not be negative")
+	}
+
+	// Build the dimension filter, a wildcard value splits the metric by the
+	// dimension without restricting the values
+	names := make([]string, 0, len(t.Dimensions))
+	for name := range t.Dimensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	conditions := make([]string, 0, len(names))
+	for _, name := range names {
+		values := t.Dimensions[name]
+		if len(values) == 0 {
+			values = []string{"*"}
+		}
+		terms := make([]string, 0, len(values))
+		for _, v := range values {
+			terms = append(terms, fmt.Sprintf("%s eq '%s'", name, strings.ReplaceAll(v, "'", "''")))
+		}
+		if len(terms) == 1 {
+			conditions = append(conditions, terms[0])
+		} else {
+			conditions = append(conditions, "("+strings.Join(terms, " or ")+")")
+		}
+	}
+	t.filter = strings.Join(conditions, " and ")
+
+	return nil
+}
+
+// query returns the Resource Graph query for discovering the resources of
+// the target
+func (t *batchTarget) query() string {
+	query := "resources | where type =~ " + kqlString(t.ResourceType)
+	if len(t.Regions) > 0 {
+		regions := make([]string, 0, len(t.Regions))
+		for _, r := range t.Regions {
+			regions = append(regions, kqlString(r))
+		}
+		query += " | where location in~ (" + strings.Join(regions, ", ") + ")"
+	}
+	if t.Filter != "" {
+		query += " | where " + t.Filter
+	}
+	return query + " | project id, location, subscriptionId"
+}
+
+func kqlString(s string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), "'", `\'`) + "'"
+}
+
+func (c *batchCollector) gather(acc telegraf.Accumulator) {
+	ctx := context.Background()
+
+	if c.batches == nil || time.Since(c.discovered) >= c.discoveryInterval {
+		batches, err := c.discover(ctx)
+		if err != nil {
+			// Keep the previously discovered resources if any
+			acc.AddError(fmt.Errorf("discovering resources failed: %w", err))
+		} else {
+			c.log.Debugf("Discovered %d resource batches", len(batches))
+			c.batches = batches
+			c.discovered = time.Now()
+		}
+	}
+
+	now := time.Now()
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxBatchRequests)
+	for _, batch := range c.batches {
+		for metrics := range slices.Chunk(batch.target.Metrics, maxBatchMetrics) {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(batch *resourceBatch, metrics []string) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				if err := c.gatherBatch(ctx, acc, batch, metrics, now); err != nil {
+					acc.AddError(fmt.Errorf("querying %s metrics of subscription %q in %q failed: %w",
+						batch.target.ResourceType, batch.subscription, batch.region, err))
+				}
+			}(batch, metrics)
+		}
+	}
+	wg.Wait()
+}
+
+// discover lists the resources of all targets and splits them into batches
+// of resources sharing subscription and region as required by the API
+func (c *batchCollector) discover(ctx context.Context) ([]*resourceBatch, error) {
+	batches := make([]*resourceBatch, 0)
+	for _, target := range c.targets {
+		resources, err := c.listResources(ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("listing %s resources failed: %w", target.ResourceType, err)
+		}
+
+		type key struct{ subscription, region string }
+		groups := make(map[key][]string)
+		for _, r := range resources {
+			k := key{subscription: r.SubscriptionID, region: strings.ToLower(r.Location)}
+			groups[k] = append(groups[k], r.ID)
+		}
+
+		keys := make([]key, 0, len(groups))
+		for k := range groups {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].subscription != keys[j].subscription {
+				return keys[i].subscription < keys[j].subscription
+			}
+			return keys[i].region < keys[j].region
+		})
+		for _, k := range keys {
+			for ids := range slices.Chunk(groups[k], maxBatchResources) {
+				batches = append(batches, &resourceBatch{
+					target:       target,
+					subscription: k.subscription,
+					region:       k.region,
+					resourceIDs:  ids,
+				})
+			}
+		}
+	}
+	return batches, nil
+}
+
+func (c *batchCollector) listResources(ctx context.Context, target *batchTarget) ([]discoveredResource, error) {
+	endpoint := c.endpoints.resourceManager + "/providers/Microsoft.ResourceGraph/resources?api-version=" + resourceGraphAPIVersion
+	request := resourceGraphRequest{
+		Subscriptions: target.Subscriptions,
+		Query:         target.query(),
+		Options: resourceGraphOptions{
+			Top:          1000,
+			ResultFormat: "objectArray",
+		},
+	}
+
+	var resources []discoveredResource
+	for {
+		body, err := json.Marshal(request)
+		if err != nil {
+			return nil, err
+		}
+		var response resourceGraphResponse
+		if err := c.post(ctx, endpoint, c.endpoints.resourceManager+"/.default", body, &response); err != nil {
+			return nil, err
+		}
+		resources = append(resources, response.Data...)
+
+		if response.SkipToken == "" {
+			return resources, nil
+		}
+		request.Options.SkipToken = response.SkipToken
+	}
+}
+
+func (c *batchCollector) gatherBatch(ctx context.Context, acc telegraf.Accumulator, batch *resourceBatch, metrics []string, now time.Time) error {
+	target := batch.target
+	grain := time.Duration(target.TimeGrain)
+	end := now.UTC().Truncate(time.Minute)
+	start := end.Add(-batchTimeGrains * grain)
+
+	// Commas within metric names must be escaped
+	names := make([]string, 0, len(metrics))
+	for _, m := range metrics {
+		names = append(names, strings.ReplaceAll(m, ",", "%2"))
+	}
+
+	query := url.Values{
+		"api-version":     {metricsBatchAPIVersion},
+		"metricnamespace": {target.ResourceType},
+		"metricnames":     {strings.Join(names, ",")},
+		"aggregation":     {strings.ToLower(strings.Join(target.Aggregations, ","))},
+		"interval":        {target.interval},
+		"starttime":       {start.Format(time.RFC3339)},
+		"endtime":         {end.Format(time.RFC3339)},
+	}
+	if target.filter != "" {
+		query.Set("filter", target.filter)
+	}
+	if target.MaxSeries > 0 {
+		query.Set("top", strconv.Itoa(target.MaxSeries))
+	}
+	endpoint := fmt.Sprintf(c.endpoints.metrics, batch.region) +
+		"/subscriptions/" + url.PathEscape(batch.subscription) + "/metrics:getBatch?" + query.Encode()
+
+	body, err := json.Marshal(map[string][]string{"resourceids": batch.resourceIDs})
+	if err != nil {
+		return err
+	}
+	var response metricsBatchResponse
+	if err := c.post(ctx, endpoint, c.endpoints.metricsScope, body, &response); err != nil {
+		return err
+	}
+
+	replacer := strings.NewReplacer(".", "_", "/", "_", " ", "_", "(", "_", ")", "_")
+	for _, resource := range response.Values {
+		subscription, group, name := parseResourceID(resource.ResourceID)
+		for _, m := range resource.Value {
+			if m.ErrorCode != "" && m.ErrorCode != "Success" {
+				acc.AddError(fmt.Errorf("metric %q of resource %q: error code %s: %s", m.Name.Value, resource.ResourceID, m.ErrorCode, m.ErrorMsg))
+				continue
+			}
+
+			mname := m.Name.LocalizedValue
+			if mname == "" {
+				mname = m.Name.Value
+			}
+			measurement := "azure_monitor_" + replacer.Replace(strings.ToLower(target.ResourceType)) + "_" + replacer.Replace(strings.ToLower(mname))
+
+			for _, series := range m.Timeseries {
+				// Use the latest datapoint carrying a value
+				var fields map[string]interface{}
+				for i := len(series.Data) - 1; i >= 0 && fields == nil; i-- {
+					dp := series.Data[i]
+					values := map[string]*float64{
+						"average": dp.Average,
+						"count":   dp.Count,
+						"maximum": dp.Maximum,
+						"minimum": dp.Minimum,
+						"total":   dp.Total,
+					}
+					for k, v := range values {
+						if v == nil {
+							continue
+						}
+						if fields == nil {
+							fields = map[string]interface{}{"timeStamp": dp.TimeStamp.Format(time.RFC3339)}
+						}
+						fields[k] = *v
+					}
+				}
+				if fields == nil {
+					c.log.Debugf("No value for metric %q of resource %q", m.Name.Value, resource.ResourceID)
+					continue
+				}
+
+				tags := map[string]string{
+					"subscription_id": subscription,
+					"resource_group":  group,
+					"resource_name":   name,
+					"namespace":       target.ResourceType,
+					"resource_region": resource.ResourceRegion,
+					"unit":            m.Unit,
+				}
+				for _, dim := range series.MetadataValues {
+					tags[dim.Name.Value] = dim.Value
+				}
+				acc.AddFields(measurement, fields, tags)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *batchCollector) post(ctx context.Context, endpoint, scope string, body []byte, result interface{}) error {
+	token, err := c.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{scope}})
+	if err != nil {
+		return fmt.Errorf("getting token failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e apiError
+		if err := json.Unmarshal(buf, &e); err == nil && e.Error.Code != "" {
+			return fmt.Errorf("received status %q: %s: %s", resp.Status, e.Error.Code, e.Error.Message)
+		}
+		return fmt.Errorf("received status %q", resp.Status)
+	}
+
+	if err := json.Unmarshal(buf, result); err != nil {
+		return fmt.Errorf("decoding response failed: %w", err)
+	}
+	return nil
+}
+
+// parseResourceID extracts the subscription, resource group and resource
+// name from an ID like
+// /subscriptions/<id>/resourceGroups/<group>/providers/<namespace>/<type>/<name>
+func parseResourceID(id string) (subscription, group, name string) {
+	scope, resource, _ := strings.Cut(id, "/providers/")
+	parts := strings.Split(strings.Trim(scope, "/"), "/")
+	for i := 0; i+1 < len(parts); i += 2 {
+		switch strings.ToLower(parts[i]) {
+		case "subscriptions":
+			subscription = parts[i+1]
+		case "resourcegroups":
+			group = parts[i+1]
+		}
+	}
+	if parts := strings.SplitN(resource, "/", 3); len(parts) == 3 {
+		name = parts[2]
+	}
+	return subscription, group, name
+}
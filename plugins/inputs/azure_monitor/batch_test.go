@@ -0,0 +1,385 @@
+package azure_monitor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// mockCredential returns the requested scope as token to allow checking the
+// token used for a request
+type mockCredential struct{}
+
+func (*mockCredential) GetToken(_ context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: options.Scopes[0], ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestBatchTargetInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   batchTarget
+		expected string
+		filter   string
+	}{
+		{
+			name:     "missing resource type",
+			target:   batchTarget{Metrics: []string{"Percentage CPU"}},
+			expected: "'resource_type' required",
+		},
+		{
+			name:     "missing metrics",
+			target:   batchTarget{ResourceType: "Microsoft.Compute/virtualMachines"},
+			expected: "'metrics' required",
+		},
+		{
+			name: "invalid aggregation",
+			target: batchTarget{
+				ResourceType: "Microsoft.Compute/virtualMachines",
+				Metrics:      []string{"Percentage CPU"},
+				Aggregations: []string{"Median"},
+			},
+			expected: `invalid aggregation "Median"`,
+		},
+		{
+			name: "invalid time grain",
+			target: batchTarget{
+				ResourceType: "Microsoft.Compute/virtualMachines",
+				Metrics:      []string{"Percentage CPU"},
+				TimeGrain:    config.Duration(2 * time.Minute),
+			},
+			expected: "unsupported time grain 2m0s",
+		},
+		{
+			name: "dimension filter",
+			target: batchTarget{
+				ResourceType: "Microsoft.Compute/virtualMachines",
+				Metrics:      []string{"Data Disk Read Bytes/sec"},
+				Dimensions: map[string][]string{
+					"LUN":      {"0", "1"},
+					"Instance": {},
+					"Name":     {"o'brien"},
+				},
+			},
+			filter: "Instance eq '*' and (LUN eq '0' or LUN eq '1') and Name eq 'o''brien'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.target.init("subscription")
+			if tt.expected != "" {
+				require.ErrorContains(t, err, tt.expected)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.filter, tt.target.filter)
+			require.Equal(t, []string{"subscription"}, tt.target.Subscriptions)
+			require.Equal(t, batchAggregations, tt.target.Aggregations)
+			require.Equal(t, "PT1M", tt.target.interval)
+		})
+	}
+}
+
+func TestInitBatchTargetsOnly(t *testing.T) {
+	am := &AzureMonitor{
+		SubscriptionID: "subscriptionID",
+		ClientID:       "clientID",
+		ClientSecret:   "clientSecret",
+		TenantID:       "tenantID",
+		CloudOption:    "AzureChina",
+		BatchTargets: []*batchTarget{
+			{
+				ResourceType: "Microsoft.Compute/virtualMachines",
+				Metrics:      []string{"Percentage CPU"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, am.Init())
+	require.Nil(t, am.receiver)
+	require.NotNil(t, am.batch)
+	require.Equal(t, cloudBatchEndpoints["AzureChina"], am.batch.endpoints)
+	require.Equal(t, time.Hour, am.batch.discoveryInterval)
+	require.Equal(t, []string{"subscriptionID"}, am.BatchTargets[0].Subscriptions)
+}
+
+func TestInitBatchTargetsInvalid(t *testing.T) {
+	am := &AzureMonitor{
+		UseManagedIdentity: true,
+		BatchTargets: []*batchTarget{
+			{
+				ResourceType: "Microsoft.Compute/virtualMachines",
+				Metrics:      []string{"Percentage CPU"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.ErrorContains(t, am.Init(), "invalid batch target 1: 'subscriptions' or 'subscription_id' required")
+
+	am.ClientSecret = "clientSecret"
+	require.ErrorContains(t, am.Init(), "'client_secret' cannot be used together with 'use_managed_identity'")
+}
+
+func TestBatchTargetQuery(t *testing.T) {
+	target := batchTarget{
+		ResourceType: "Microsoft.Compute/virtualMachines",
+		Regions:      []string{"westeurope", "northeurope"},
+		Filter:       "tags.environment == 'prod'",
+	}
+	expected := "resources | where type =~ 'Microsoft.Compute/virtualMachines'" +
+		" | where location in~ ('westeurope', 'northeurope')" +
+		" | where tags.environment == 'prod'" +
+		" | project id, location, subscriptionId"
+	require.Equal(t, expected, target.query())
+}
+
+func TestParseResourceID(t *testing.T) {
+	subscription, group, name := parseResourceID(
+		"/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Sql/servers/srv1/databases/db1",
+	)
+	require.Equal(t, "sub1", subscription)
+	require.Equal(t, "rg1", group)
+	require.Equal(t, "srv1/databases/db1", name)
+}
+
+func TestBatchGather(t *testing.T) {
+	vm := func(sub, region, name string) map[string]string {
+		return map[string]string{
+			"id":             "/subscriptions/" + sub + "/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/" + name,
+			"location":       region,
+			"subscriptionId": sub,
+		}
+	}
+
+	var mu sync.Mutex
+	var batches []string
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var response interface{}
+		switch {
+		case r.URL.Path == "/providers/Microsoft.ResourceGraph/resources":
+			if r.Header.Get("Authorization") != "Bearer "+ts.URL+"/.default" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			// Return the resources in two pages
+			options := body["options"].(map[string]interface{})
+			if options["$skipToken"] == nil {
+				response = map[string]interface{}{
+					"$skipToken": "next",
+					"data": []map[string]string{
+						vm("sub1", "westeurope", "vm1"),
+						vm("sub2", "westeurope", "vm2"),
+					},
+				}
+			} else {
+				response = map[string]interface{}{
+					"data": []map[string]string{vm("sub1", "WestEurope", "vm3")},
+				}
+			}
+		case strings.HasSuffix(r.URL.Path, "/metrics:getBatch"):
+			if r.Header.Get("Authorization") != "Bearer https://metrics.example.com/.default" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			query := r.URL.Query()
+			if query.Get("metricnames") != "Percentage CPU,Disk Read Bytes" ||
+				query.Get("aggregation") != "average,maximum" ||
+				query.Get("filter") != "LUN eq '*'" ||
+				query.Get("interval") != "PT1M" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			var ids []string
+			values := make([]interface{}, 0)
+			for _, id := range body["resourceids"].([]interface{}) {
+				ids = append(ids, id.(string))
+				values = append(values, map[string]interface{}{
+					"resourceid":     id,
+					"resourceregion": "westeurope",
+					"value": []interface{}{
+						map[string]interface{}{
+							"name":      map[string]string{"value": "Percentage CPU", "localizedValue": "Percentage CPU"},
+							"unit":      "Percent",
+							"errorCode": "Success",
+							"timeseries": []interface{}{
+								map[string]interface{}{
+									"metadatavalues": []interface{}{},
+									"data": []interface{}{
+										map[string]interface{}{"timeStamp": "2024-06-10T10:00:00Z", "average": 1.5, "maximum": 3.0},
+										map[string]interface{}{"timeStamp": "2024-06-10T10:01:00Z"},
+									},
+								},
+							},
+						},
+						map[string]interface{}{
+							"name":      map[string]string{"value": "Disk Read Bytes", "localizedValue": "Disk Read Bytes"},
+							"unit":      "Bytes",
+							"errorCode": "Success",
+							"timeseries": []interface{}{
+								map[string]interface{}{
+									"metadatavalues": []interface{}{
+										map[string]interface{}{"name": map[string]string{"value": "LUN"}, "value": "0"},
+									},
+									"data": []interface{}{
+										map[string]interface{}{"timeStamp": "2024-06-10T10:00:00Z", "average": 512.0, "maximum": 1024.0},
+									},
+								},
+							},
+						},
+					},
+				})
+			}
+			mu.Lock()
+			batches = append(batches, strings.TrimSuffix(r.URL.Path, "/metrics:getBatch")+" "+strings.Join(ids, ","))
+			mu.Unlock()
+			response = map[string]interface{}{"values": values}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	target := &batchTarget{
+		ResourceType: "Microsoft.Compute/virtualMachines",
+		Subscriptions: []string{
+			"sub1",
+			"sub2",
+		},
+		Metrics:      []string{"Percentage CPU", "Disk Read Bytes"},
+		Aggregations: []string{"average", "Maximum"},
+		Dimensions:   map[string][]string{"LUN": {"*"}},
+	}
+	require.NoError(t, target.init(""))
+
+	c := &batchCollector{
+		targets:    []*batchTarget{target},
+		credential: &mockCredential{},
+		endpoints: batchEndpoints{
+			resourceManager: ts.URL,
+			metrics:         ts.URL + "/%s",
+			metricsScope:    "https://metrics.example.com/.default",
+		},
+		discoveryInterval: time.Hour,
+		client:            ts.Client(),
+		log:               testutil.Logger{},
+	}
+
+	var acc testutil.Accumulator
+	c.gather(&acc)
+	require.Empty(t, acc.Errors)
+
+	require.ElementsMatch(t, []string{
+		"/westeurope/subscriptions/sub1 /subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/vm1," +
+			"/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/vm3",
+		"/westeurope/subscriptions/sub2 /subscriptions/sub2/resourceGroups/rg1/providers/Microsoft.Compute/virtualMachines/vm2",
+	}, batches)
+
+	expected := make([]telegraf.Metric, 0, 6)
+	for _, r := range []struct{ sub, name string }{{"sub1", "vm1"}, {"sub2", "vm2"}, {"sub1", "vm3"}} {
+		tags := map[string]string{
+			"subscription_id": r.sub,
+			"resource_group":  "rg1",
+			"resource_name":   r.name,
+			"namespace":       "Microsoft.Compute/virtualMachines",
+			"resource_region": "westeurope",
+		}
+		cpuTags := map[string]string{"unit": "Percent"}
+		diskTags := map[string]string{"unit": "Bytes", "LUN": "0"}
+		for k, v := range tags {
+			cpuTags[k] = v
+			diskTags[k] = v
+		}
+		expected = append(expected,
+			metric.New(
+				"azure_monitor_microsoft_compute_virtualmachines_percentage_cpu",
+				cpuTags,
+				map[string]interface{}{
+					"timeStamp": "2024-06-10T10:00:00Z",
+					"average":   1.5,
+					"maximum":   3.0,
+				},
+				time.Unix(0, 0),
+			),
+			metric.New(
+				"azure_monitor_microsoft_compute_virtualmachines_disk_read_bytes",
+				diskTags,
+				map[string]interface{}{
+					"timeStamp": "2024-06-10T10:00:00Z",
+					"average":   512.0,
+					"maximum":   1024.0,
+				},
+				time.Unix(0, 0),
+			),
+		)
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// Resources are not discovered again within the discovery interval
+	batches = nil
+	acc.ClearMetrics()
+	c.gather(&acc)
+	require.Empty(t, acc.Errors)
+	require.Len(t, batches, 2)
+	require.Len(t, acc.GetTelegrafMetrics(), 6)
+}
+
+func TestBatchGatherError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		if _, err := w.Write([]byte(`{"error":{"code":"RateLimiting","message":"too many requests"}}`)); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	target := &batchTarget{
+		ResourceType: "Microsoft.Compute/virtualMachines",
+		Metrics:      []string{"Percentage CPU"},
+	}
+	require.NoError(t, target.init("sub1"))
+
+	c := &batchCollector{
+		targets:    []*batchTarget{target},
+		credential: &mockCredential{},
+		endpoints: batchEndpoints{
+			resourceManager: ts.URL,
+			metrics:         ts.URL + "/%s",
+		},
+		discoveryInterval: time.Hour,
+		client:            ts.Client(),
+		log:               testutil.Logger{},
+	}
+
+	var acc testutil.Accumulator
+	c.gather(&acc)
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "RateLimiting: too many requests")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -0,0 +1,344 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package ble_sensors
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const deviceInterface = "org.bluez.Device1"
+
+type BLESensors struct {
+	Adapter   string            `toml:"adapter"`
+	Sensors   []string          `toml:"sensors"`
+	Addresses []string          `toml:"addresses"`
+	Aliases   map[string]string `toml:"aliases"`
+	Log       telegraf.Logger   `toml:"-"`
+
+	addressFilter filter.Filter
+	aliases       map[string]string
+	decoders      map[string]bool
+
+	conn    *dbus.Conn
+	signals chan *dbus.Signal
+	wg      sync.WaitGroup
+
+	// Latest readings per device address
+	readings map[string]*reading
+	sync.Mutex
+}
+
+// advertisement contains the relevant data of a BLE advertisement
+type advertisement struct {
+	rssi         *int16
+	manufacturer map[uint16][]byte
+	service      map[string][]byte
+}
+
+// reading is the latest decoded data of a device
+type reading struct {
+	sensor  string
+	fields  map[string]interface{}
+	updated bool
+}
+
+func (*BLESensors) SampleConfig() string {
+	return sampleConfig
+}
+
+func (b *BLESensors) Init() error {
+	if b.Adapter == "" {
+		return errors.New("'adapter' required")
+	}
+	if len(b.Sensors) == 0 {
+		return errors.New("no sensors specified")
+	}
+	if err := choice.CheckSlice(b.Sensors, []string{"govee", "ruuvi", "xiaomi"}); err != nil {
+		return fmt.Errorf("config option sensors: %w", err)
+	}
+	b.decoders = make(map[string]bool, len(b.Sensors))
+	for _, s := range b.Sensors {
+		b.decoders[s] = true
+	}
+
+	f, err := filter.Compile(b.Addresses)
+	if err != nil {
+		return fmt.Errorf("creating address filter failed: %w", err)
+	}
+	b.addressFilter = f
+
+	// Normalize the addresses to match the format reported by BlueZ
+	b.aliases = make(map[string]string, len(b.Aliases))
+	for address, alias := range b.Aliases {
+		b.aliases[strings.ToUpper(address)] = alias
+	}
+
+	b.readings = make(map[string]*reading)
+
+	return nil
+}
+
+func (b *BLESensors) Start(telegraf.Accumulator) error {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return &internal.StartupError{
+			Err:   fmt.Errorf("connecting to system bus failed: %w", err),
+			Retry: true,
+		}
+	}
+
+	adapterPath := dbus.ObjectPath("/org/bluez/" + b.Adapter)
+	adapter := conn.Object("org.bluez", adapterPath)
+
+	// Request all advertisements of low-energy devices including duplicates
+	// to receive the updated sensor data
+	discoveryFilter := map[string]interface{}{
+		"Transport":     "le",
+		"DuplicateData": true,
+	}
+	if err := adapter.Call("org.bluez.Adapter1.SetDiscoveryFilter", 0, discoveryFilter).Err; err != nil {
+		conn.Close()
+		return &internal.StartupError{
+			Err:   fmt.Errorf("setting discovery filter on adapter %q failed: %w", b.Adapter, err),
+			Retry: true,
+		}
+	}
+
+	matches := [][]dbus.MatchOption{
+		{
+			dbus.WithMatchInterface("org.freedesktop.DBus.Properties"),
+			dbus.WithMatchMember("PropertiesChanged"),
+			dbus.WithMatchPathNamespace(adapterPath),
+		},
+		{
+			dbus.WithMatchInterface("org.freedesktop.DBus.ObjectManager"),
+			dbus.WithMatchMember("InterfacesAdded"),
+		},
+	}
+	for _, m := range matches {
+		if err := conn.AddMatchSignal(m...); err != nil {
+			conn.Close()
+			return fmt.Errorf("subscribing to signals failed: %w", err)
+		}
+	}
+
+	b.signals = make(chan *dbus.Signal, 100)
+	conn.Signal(b.signals)
+
+	if err := adapter.Call("org.bluez.Adapter1.StartDiscovery", 0).Err; err != nil {
+		conn.Close()
+		return &internal.StartupError{
+			Err:   fmt.Errorf("starting discovery on adapter %q failed: %w", b.Adapter, err),
+			Retry: true,
+		}
+	}
+	b.conn = conn
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for sig := range b.signals {
+			b.handleSignal(sig)
+		}
+	}()
+
+	return nil
+}
+
+func (b *BLESensors) Gather(acc telegraf.Accumulator) error {
+	b.Lock()
+	defer b.Unlock()
+
+	// Only report devices with new data since the last gather cycle
+	for address, r := range b.readings {
+		if !r.updated {
+			continue
+		}
+		r.updated = false
+
+		tags := map[string]string{
+			"address": address,
+			"sensor":  r.sensor,
+		}
+		if alias, found := b.aliases[address]; found {
+			tags["alias"] = alias
+		}
+		fields := make(map[string]interface{}, len(r.fields))
+		for k, v := range r.fields {
+			fields[k] = v
+		}
+		acc.AddFields("ble_sensors", fields, tags)
+	}
+
+	return nil
+}
+
+func (b *BLESensors) Stop() {
+	if b.conn == nil {
+		return
+	}
+
+	adapter := b.conn.Object("org.bluez", dbus.ObjectPath("/org/bluez/"+b.Adapter))
+	if err := adapter.Call("org.bluez.Adapter1.StopDiscovery", 0).Err; err != nil {
+		b.Log.Debugf("Stopping discovery failed: %v", err)
+	}
+
+	// Closing the connection also closes the signal channel
+	b.conn.Close()
+	b.wg.Wait()
+	b.conn = nil
+}
+
+func (b *BLESensors) handleSignal(sig *dbus.Signal) {
+	switch sig.Name {
+	case "org.freedesktop.DBus.Properties.PropertiesChanged":
+		if len(sig.Body) < 2 {
+			return
+		}
+		if iface, ok := sig.Body[0].(string); !ok || iface != deviceInterface {
+			return
+		}
+		props, ok := sig.Body[1].(map[string]dbus.Variant)
+		if !ok {
+			return
+		}
+		b.handle(addressFromPath(sig.Path), parseProperties(props))
+	case "org.freedesktop.DBus.ObjectManager.InterfacesAdded":
+		if len(sig.Body) < 2 {
+			return
+		}
+		devicePath, ok := sig.Body[0].(dbus.ObjectPath)
+		if !ok {
+			return
+		}
+		ifaces, ok := sig.Body[1].(map[string]map[string]dbus.Variant)
+		if !ok {
+			return
+		}
+		if props, found := ifaces[deviceInterface]; found {
+			b.handle(addressFromPath(devicePath), parseProperties(props))
+		}
+	}
+}
+
+// handle decodes the advertisement of the given device and stores the reading
+func (b *BLESensors) handle(address string, adv *advertisement) {
+	if address == "" || (b.addressFilter != nil && !b.addressFilter.Match(address)) {
+		return
+	}
+
+	sensor, fields, err := b.decode(adv)
+	if err != nil {
+		b.Log.Debugf("Decoding data of device %q failed: %v", address, err)
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	r, found := b.readings[address]
+	if fields == nil {
+		// Update the signal strength of known devices only
+		if found && adv.rssi != nil {
+			r.fields["rssi"] = int64(*adv.rssi)
+		}
+		return
+	}
+
+	if !found {
+		b.Log.Debugf("Discovered %s sensor %q", sensor, address)
+	}
+	if adv.rssi != nil {
+		fields["rssi"] = int64(*adv.rssi)
+	} else if found {
+		if rssi, ok := r.fields["rssi"]; ok {
+			fields["rssi"] = rssi
+		}
+	}
+	b.readings[address] = &reading{sensor: sensor, fields: fields, updated: true}
+}
+
+// decode returns the sensor type and the decoded fields of the advertisement
+// or nil if the advertisement does not contain data of a supported sensor
+func (b *BLESensors) decode(adv *advertisement) (string, map[string]interface{}, error) {
+	if data, found := adv.manufacturer[manufacturerRuuvi]; found && b.decoders["ruuvi"] {
+		fields, err := decodeRuuvi(data)
+		return "ruuvi", fields, err
+	}
+	if data, found := adv.manufacturer[manufacturerGovee]; found && b.decoders["govee"] {
+		fields, err := decodeGovee(data)
+		return "govee", fields, err
+	}
+	if data, found := adv.service[serviceXiaomiATC]; found && b.decoders["xiaomi"] {
+		fields, err := decodeXiaomi(data)
+		return "xiaomi", fields, err
+	}
+	return "", nil, nil
+}
+
+func parseProperties(props map[string]dbus.Variant) *advertisement {
+	var adv advertisement
+	if v, found := props["RSSI"]; found {
+		if rssi, ok := v.Value().(int16); ok {
+			adv.rssi = &rssi
+		}
+	}
+	if v, found := props["ManufacturerData"]; found {
+		if data, ok := v.Value().(map[uint16]dbus.Variant); ok {
+			adv.manufacturer = make(map[uint16][]byte, len(data))
+			for id, value := range data {
+				if buf, ok := value.Value().([]byte); ok {
+					adv.manufacturer[id] = buf
+				}
+			}
+		}
+	}
+	if v, found := props["ServiceData"]; found {
+		if data, ok := v.Value().(map[string]dbus.Variant); ok {
+			adv.service = make(map[string][]byte, len(data))
+			for uuid, value := range data {
+				if buf, ok := value.Value().([]byte); ok {
+					adv.service[strings.ToLower(uuid)] = buf
+				}
+			}
+		}
+	}
+	return &adv
+}
+
+// addressFromPath extracts the MAC address from BlueZ device paths like
+// "/org/bluez/hci0/dev_AA_BB_CC_DD_EE_FF"
+func addressFromPath(p dbus.ObjectPath) string {
+	name := path.Base(string(p))
+	if !strings.HasPrefix(name, "dev_") {
+		return ""
+	}
+	return strings.ReplaceAll(strings.TrimPrefix(name, "dev_"), "_", ":")
+}
+
+func init() {
+	inputs.Add("ble_sensors", func() telegraf.Input {
+		return &BLESensors{
+			Adapter:   "hci0",
+			Sensors:   []string{"govee", "ruuvi", "xiaomi"},
+			Addresses: []string{"*"},
+		}
+	})
+}
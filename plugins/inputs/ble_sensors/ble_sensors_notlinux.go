@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package ble_sensors
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type BLESensors struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*BLESensors) SampleConfig() string { return sampleConfig }
+
+func (b *BLESensors) Init() error {
+	b.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*BLESensors) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("ble_sensors", func() telegraf.Input {
+		return &BLESensors{}
+	})
+}
@@ -0,0 +1,317 @@
+//go:build linux
+
+package ble_sensors
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*BLESensors)
+		expected string
+	}{
+		{
+			name:     "no adapter",
+			modify:   func(b *BLESensors) { b.Adapter = "" },
+			expected: "'adapter' required",
+		},
+		{
+			name:     "no sensors",
+			modify:   func(b *BLESensors) { b.Sensors = nil },
+			expected: "no sensors specified",
+		},
+		{
+			name:     "invalid sensor",
+			modify:   func(b *BLESensors) { b.Sensors = []string{"foo"} },
+			expected: "config option sensors",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := inputs.Inputs["ble_sensors"]().(*BLESensors)
+			plugin.Log = &testutil.Logger{}
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestDecoders(t *testing.T) {
+	tests := []struct {
+		name     string
+		decoder  func([]byte) (map[string]interface{}, error)
+		data     string
+		expected map[string]interface{}
+	}{
+		{
+			name:    "ruuvi format 3",
+			decoder: decodeRuuvi,
+			data:    "03291a1ece1efc18f94202ca0b53",
+			expected: map[string]interface{}{
+				"humidity":        20.5,
+				"temperature":     26.3,
+				"pressure":        1027.66,
+				"acceleration_x":  -1.0,
+				"acceleration_y":  -1.726,
+				"acceleration_z":  0.714,
+				"battery_voltage": 2.899,
+			},
+		},
+		{
+			name:    "ruuvi format 5",
+			decoder: decodeRuuvi,
+			data:    "0512fc5394c37c0004fffc040cac364200cdcbb8334c884f",
+			expected: map[string]interface{}{
+				"temperature":      24.3,
+				"humidity":         53.49,
+				"pressure":         1000.44,
+				"acceleration_x":   0.004,
+				"acceleration_y":   -0.004,
+				"acceleration_z":   1.036,
+				"battery_voltage":  2.977,
+				"tx_power":         int64(4),
+				"movement_counter": int64(66),
+				"sequence":         int64(205),
+			},
+		},
+		{
+			name:     "ruuvi format 5 invalid values",
+			decoder:  decodeRuuvi,
+			data:     "058000ffffffff800080008000ffffffffffffffffffffffff",
+			expected: map[string]interface{}{},
+		},
+		{
+			name:    "govee H5075",
+			decoder: decodeGovee,
+			data:    "0003ed8a6400",
+			expected: map[string]interface{}{
+				"temperature": 25.7,
+				"humidity":    41.8,
+				"battery":     int64(100),
+			},
+		},
+		{
+			name:    "govee H5075 negative",
+			decoder: decodeGovee,
+			data:    "0080c4b64d00",
+			expected: map[string]interface{}{
+				"temperature": -5.0,
+				"humidity":    35.8,
+				"battery":     int64(77),
+			},
+		},
+		{
+			name:    "govee H5074",
+			decoder: decodeGovee,
+			data:    "00240914175a020000",
+			expected: map[string]interface{}{
+				"temperature": 23.4,
+				"humidity":    59.08,
+				"battery":     int64(90),
+			},
+		},
+		{
+			name:    "xiaomi ATC1441",
+			decoder: decodeXiaomi,
+			data:    "a4c13800112200f032550b8601",
+			expected: map[string]interface{}{
+				"temperature":     24.0,
+				"humidity":        50.0,
+				"battery":         int64(85),
+				"battery_voltage": 2.95,
+			},
+		},
+		{
+			name:    "xiaomi pvvx",
+			decoder: decodeXiaomi,
+			data:    "22110038c1a460098813860b550104",
+			expected: map[string]interface{}{
+				"temperature":     24.0,
+				"humidity":        50.0,
+				"battery":         int64(85),
+				"battery_voltage": 2.95,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.data)
+			require.NoError(t, err)
+
+			actual, err := tt.decoder(data)
+			require.NoError(t, err)
+			require.Len(t, actual, len(tt.expected))
+			for k, v := range tt.expected {
+				require.Contains(t, actual, k)
+				if f, ok := v.(float64); ok {
+					require.InDelta(t, f, actual[k], 1e-6, k)
+				} else {
+					require.Equal(t, v, actual[k], k)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodersFail(t *testing.T) {
+	_, err := decodeRuuvi([]byte{0x05, 0x01})
+	require.ErrorContains(t, err, "invalid length 2 for format 5")
+	_, err = decodeRuuvi([]byte{0x04})
+	require.ErrorContains(t, err, "unsupported data format 4")
+	_, err = decodeGovee([]byte{0x00, 0x01})
+	require.ErrorContains(t, err, "unsupported length 2")
+	_, err = decodeXiaomi([]byte{0x00})
+	require.ErrorContains(t, err, "unsupported length 1")
+}
+
+func TestHandleSignals(t *testing.T) {
+	plugin := inputs.Inputs["ble_sensors"]().(*BLESensors)
+	plugin.Sensors = []string{"govee", "xiaomi"}
+	plugin.Addresses = []string{"A4:C1:38:*", "C5:*"}
+	plugin.Aliases = map[string]string{"a4:c1:38:00:11:22": "fridge"}
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	govee, err := hex.DecodeString("0003ed8a6400")
+	require.NoError(t, err)
+	xiaomi, err := hex.DecodeString("22110038c1a460098813860b550104")
+	require.NoError(t, err)
+	ruuvi, err := hex.DecodeString("0512fc5394c37c0004fffc040cac364200cdcbb8334c884f")
+	require.NoError(t, err)
+
+	signals := []*dbus.Signal{
+		// New Xiaomi device including the signal strength
+		{
+			Path: "/",
+			Name: "org.freedesktop.DBus.ObjectManager.InterfacesAdded",
+			Body: []interface{}{
+				dbus.ObjectPath("/org/bluez/hci0/dev_A4_C1_38_00_11_22"),
+				map[string]map[string]dbus.Variant{
+					"org.bluez.Device1": {
+						"RSSI": dbus.MakeVariant(int16(-70)),
+						"ServiceData": dbus.MakeVariant(map[string]dbus.Variant{
+							"0000181A-0000-1000-8000-00805F9B34FB": dbus.MakeVariant(xiaomi),
+						}),
+					},
+				},
+			},
+		},
+		// Updated signal strength only
+		{
+			Path: "/org/bluez/hci0/dev_A4_C1_38_00_11_22",
+			Name: "org.freedesktop.DBus.Properties.PropertiesChanged",
+			Body: []interface{}{
+				"org.bluez.Device1",
+				map[string]dbus.Variant{"RSSI": dbus.MakeVariant(int16(-65))},
+				[]string{},
+			},
+		},
+		// Govee device
+		{
+			Path: "/org/bluez/hci0/dev_C5_11_22_33_44_55",
+			Name: "org.freedesktop.DBus.Properties.PropertiesChanged",
+			Body: []interface{}{
+				"org.bluez.Device1",
+				map[string]dbus.Variant{
+					"ManufacturerData": dbus.MakeVariant(map[uint16]dbus.Variant{
+						0xec88: dbus.MakeVariant(govee),
+					}),
+				},
+				[]string{},
+			},
+		},
+		// Ruuvi device not enabled
+		{
+			Path: "/org/bluez/hci0/dev_C5_00_00_00_00_01",
+			Name: "org.freedesktop.DBus.Properties.PropertiesChanged",
+			Body: []interface{}{
+				"org.bluez.Device1",
+				map[string]dbus.Variant{
+					"ManufacturerData": dbus.MakeVariant(map[uint16]dbus.Variant{
+						0x0499: dbus.MakeVariant(ruuvi),
+					}),
+				},
+				[]string{},
+			},
+		},
+		// Govee device not matching the address filter
+		{
+			Path: "/org/bluez/hci0/dev_D0_11_22_33_44_55",
+			Name: "org.freedesktop.DBus.Properties.PropertiesChanged",
+			Body: []interface{}{
+				"org.bluez.Device1",
+				map[string]dbus.Variant{
+					"ManufacturerData": dbus.MakeVariant(map[uint16]dbus.Variant{
+						0xec88: dbus.MakeVariant(govee),
+					}),
+				},
+				[]string{},
+			},
+		},
+	}
+	for _, sig := range signals {
+		plugin.handleSignal(sig)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ble_sensors",
+			map[string]string{
+				"address": "A4:C1:38:00:11:22",
+				"sensor":  "xiaomi",
+				"alias":   "fridge",
+			},
+			map[string]interface{}{
+				"temperature":     24.0,
+				"humidity":        50.0,
+				"battery":         int64(85),
+				"battery_voltage": 2.95,
+				"rssi":            int64(-65),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ble_sensors",
+			map[string]string{
+				"address": "C5:11:22:33:44:55",
+				"sensor":  "govee",
+			},
+			map[string]interface{}{
+				"temperature": 25.7,
+				"humidity":    41.8,
+				"battery":     int64(100),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	options := []cmp.Option{
+		testutil.IgnoreTime(),
+		testutil.SortMetrics(),
+		cmpopts.EquateApprox(0, 1e-6),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+
+	// Devices without new data must not be reported again
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -0,0 +1,137 @@
+//go:build linux
+
+package ble_sensors
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Manufacturer IDs and service UUIDs used by the supported sensors
+const (
+	manufacturerRuuvi = 0x0499
+	manufacturerGovee = 0xec88
+	serviceXiaomiATC  = "0000181a-0000-1000-8000-00805f9b34fb"
+)
+
+// decodeRuuvi decodes the manufacturer data of RuuviTags using the RAWv1
+// (format 3) or RAWv2 (format 5) data format, see
+// https://docs.ruuvi.com/communication/bluetooth-advertisements
+func decodeRuuvi(data []byte) (map[string]interface{}, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("invalid length %d", len(data))
+	}
+
+	switch data[0] {
+	case 3:
+		if len(data) < 14 {
+			return nil, fmt.Errorf("invalid length %d for format 3", len(data))
+		}
+		temperature := float64(data[2]&0x7f) + float64(data[3])/100.0
+		if data[2]&0x80 != 0 {
+			temperature = -temperature
+		}
+		return map[string]interface{}{
+			"humidity":        float64(data[1]) * 0.5,
+			"temperature":     temperature,
+			"pressure":        (float64(binary.BigEndian.Uint16(data[4:6])) + 50000.0) / 100.0,
+			"acceleration_x":  float64(int16(binary.BigEndian.Uint16(data[6:8]))) / 1000.0,
+			"acceleration_y":  float64(int16(binary.BigEndian.Uint16(data[8:10]))) / 1000.0,
+			"acceleration_z":  float64(int16(binary.BigEndian.Uint16(data[10:12]))) / 1000.0,
+			"battery_voltage": float64(binary.BigEndian.Uint16(data[12:14])) / 1000.0,
+		}, nil
+	case 5:
+		if len(data) < 18 {
+			return nil, fmt.Errorf("invalid length %d for format 5", len(data))
+		}
+
+		// The maximum value of each field marks the value as invalid
+		fields := make(map[string]interface{}, 10)
+		if v := int16(binary.BigEndian.Uint16(data[1:3])); v != -0x8000 {
+			fields["temperature"] = float64(v) * 0.005
+		}
+		if v := binary.BigEndian.Uint16(data[3:5]); v != 0xffff {
+			fields["humidity"] = float64(v) * 0.0025
+		}
+		if v := binary.BigEndian.Uint16(data[5:7]); v != 0xffff {
+			fields["pressure"] = (float64(v) + 50000.0) / 100.0
+		}
+		for i, axis := range []string{"x", "y", "z"} {
+			offset := 7 + 2*i
+			if v := int16(binary.BigEndian.Uint16(data[offset : offset+2])); v != -0x8000 {
+				fields["acceleration_"+axis] = float64(v) / 1000.0
+			}
+		}
+		power := binary.BigEndian.Uint16(data[13:15])
+		if v := power >> 5; v != 0x7ff {
+			fields["battery_voltage"] = float64(v+1600) / 1000.0
+		}
+		if v := power & 0x1f; v != 0x1f {
+			fields["tx_power"] = int64(v)*2 - 40
+		}
+		if v := data[15]; v != 0xff {
+			fields["movement_counter"] = int64(v)
+		}
+		if v := binary.BigEndian.Uint16(data[16:18]); v != 0xffff {
+			fields["sequence"] = int64(v)
+		}
+		return fields, nil
+	}
+
+	return nil, fmt.Errorf("unsupported data format %d", data[0])
+}
+
+// decodeGovee decodes the manufacturer data of Govee thermo-hygrometers
+func decodeGovee(data []byte) (map[string]interface{}, error) {
+	switch len(data) {
+	case 6:
+		// H5072, H5075 and similar encode temperature and humidity in a
+		// single 24-bit value with the most significant bit as sign
+		raw := uint32(data[1])<<16 | uint32(data[2])<<8 | uint32(data[3])
+		negative := raw&0x800000 != 0
+		raw &= 0x7fffff
+		temperature := float64(raw/1000) / 10.0
+		if negative {
+			temperature = -temperature
+		}
+		return map[string]interface{}{
+			"temperature": temperature,
+			"humidity":    float64(raw%1000) / 10.0,
+			"battery":     int64(data[4]),
+		}, nil
+	case 9:
+		// H5074 and similar
+		return map[string]interface{}{
+			"temperature": float64(int16(binary.LittleEndian.Uint16(data[1:3]))) / 100.0,
+			"humidity":    float64(binary.LittleEndian.Uint16(data[3:5])) / 100.0,
+			"battery":     int64(data[5]),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported length %d", len(data))
+}
+
+// decodeXiaomi decodes the service data of Xiaomi thermo-hygrometers like the
+// LYWSD03MMC running the custom ATC1441 or pvvx firmware
+func decodeXiaomi(data []byte) (map[string]interface{}, error) {
+	switch len(data) {
+	case 13:
+		// ATC1441 format using big-endian values
+		return map[string]interface{}{
+			"temperature":     float64(int16(binary.BigEndian.Uint16(data[6:8]))) / 10.0,
+			"humidity":        float64(data[8]),
+			"battery":         int64(data[9]),
+			"battery_voltage": float64(binary.BigEndian.Uint16(data[10:12])) / 1000.0,
+		}, nil
+	case 15:
+		// pvvx custom format using little-endian values
+		return map[string]interface{}{
+			"temperature":     float64(int16(binary.LittleEndian.Uint16(data[6:8]))) / 100.0,
+			"humidity":        float64(binary.LittleEndian.Uint16(data[8:10])) / 100.0,
+			"battery_voltage": float64(binary.LittleEndian.Uint16(data[10:12])) / 1000.0,
+			"battery":         int64(data[12]),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported length %d", len(data))
+}
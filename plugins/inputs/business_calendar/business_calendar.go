@@ -0,0 +1,302 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package business_calendar
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Maximum duration of a maintenance window defined by a schedule
+const maxWindowDuration = 7 * 24 * time.Hour
+
+type BusinessCalendar struct {
+	Name               string               `toml:"name"`
+	Timezone           string               `toml:"timezone"`
+	BusinessHours      []string             `toml:"business_hours"`
+	Holidays           []string             `toml:"holidays"`
+	MaintenanceWindows []*maintenanceWindow `toml:"maintenance_window"`
+	Calendars          []*calendar          `toml:"ical"`
+	Log                telegraf.Logger      `toml:"-"`
+	common_http.HTTPClientConfig
+
+	location *time.Location
+	hours    []*businessHours
+	holidays []holiday
+	client   *http.Client
+}
+
+type maintenanceWindow struct {
+	Name     string          `toml:"name"`
+	Schedule string          `toml:"schedule"`
+	Duration config.Duration `toml:"duration"`
+
+	schedule *cronSchedule
+}
+
+// calendar is an iCalendar source providing holidays or maintenance windows
+type calendar struct {
+	URL             string          `toml:"url"`
+	Type            string          `toml:"type"`
+	Events          []string        `toml:"events"`
+	RefreshInterval config.Duration `toml:"refresh_interval"`
+
+	filter    filter.Filter
+	events    []icalEvent
+	refreshed time.Time
+}
+
+// holiday is a fixed date or a date recurring every year if year is zero
+type holiday struct {
+	name  string
+	year  int
+	month time.Month
+	day   int
+}
+
+func (*BusinessCalendar) SampleConfig() string {
+	return sampleConfig
+}
+
+func (b *BusinessCalendar) Init() error {
+	if b.Name == "" {
+		b.Name = "default"
+	}
+
+	loc, err := time.LoadLocation(b.Timezone)
+	if err != nil {
+		return fmt.Errorf("loading timezone %q failed: %w", b.Timezone, err)
+	}
+	b.location = loc
+
+	for _, rule := range b.BusinessHours {
+		h, err := parseBusinessHours(rule)
+		if err != nil {
+			return fmt.Errorf("invalid business hours %q: %w", rule, err)
+		}
+		b.hours = append(b.hours, h)
+	}
+
+	for _, entry := range b.Holidays {
+		h, err := parseHoliday(entry)
+		if err != nil {
+			return fmt.Errorf("invalid holiday %q: %w", entry, err)
+		}
+		b.holidays = append(b.holidays, h)
+	}
+
+	for i, w := range b.MaintenanceWindows {
+		if w.Name == "" {
+			w.Name = fmt.Sprintf("window-%d", i+1)
+		}
+		s, err := parseCron(w.Schedule)
+		if err != nil {
+			return fmt.Errorf("invalid schedule %q of maintenance window %q: %w", w.Schedule, w.Name, err)
+		}
+		w.schedule = s
+		if w.Duration <= 0 || time.Duration(w.Duration) > maxWindowDuration {
+			return fmt.Errorf("duration of maintenance window %q must be between 0 and %s", w.Name, maxWindowDuration)
+		}
+	}
+
+	for _, c := range b.Calendars {
+		if c.URL == "" {
+			return errors.New("'url' required for calendar")
+		}
+		switch c.Type {
+		case "":
+			c.Type = "holiday"
+		case "holiday", "maintenance":
+		default:
+			return fmt.Errorf("invalid type %q for calendar %q", c.Type, c.URL)
+		}
+		if c.RefreshInterval <= 0 {
+			c.RefreshInterval = config.Duration(time.Hour)
+		}
+		f, err := filter.Compile(c.Events)
+		if err != nil {
+			return fmt.Errorf("creating event filter for calendar %q failed: %w", c.URL, err)
+		}
+		c.filter = f
+	}
+
+	if len(b.Calendars) > 0 {
+		client, err := b.HTTPClientConfig.CreateClient(context.Background(), b.Log)
+		if err != nil {
+			return fmt.Errorf("creating client failed: %w", err)
+		}
+		b.client = client
+	}
+
+	return nil
+}
+
+func (b *BusinessCalendar) Gather(acc telegraf.Accumulator) error {
+	now := time.Now().In(b.location)
+
+	for _, c := range b.Calendars {
+		if !c.refreshed.IsZero() && now.Sub(c.refreshed) < time.Duration(c.RefreshInterval) {
+			continue
+		}
+		if err := b.refresh(c); err != nil {
+			// Keep using the previously loaded events
+			acc.AddError(fmt.Errorf("loading calendar %q failed: %w", c.URL, err))
+			continue
+		}
+		c.refreshed = now
+	}
+
+	b.gatherAt(acc, now)
+	return nil
+}
+
+func (b *BusinessCalendar) Stop() {
+	if b.client != nil {
+		b.client.CloseIdleConnections()
+	}
+}
+
+func (b *BusinessCalendar) gatherAt(acc telegraf.Accumulator, now time.Time) {
+	// Holidays
+	holidays := make([]string, 0)
+	for _, h := range b.holidays {
+		if h.matches(now) {
+			holidays = append(holidays, h.name)
+		}
+	}
+
+	// Maintenance windows
+	windows := make([]string, 0)
+	for _, w := range b.MaintenanceWindows {
+		if w.schedule.active(now, time.Duration(w.Duration)) {
+			windows = append(windows, w.Name)
+		}
+	}
+
+	for _, c := range b.Calendars {
+		for i := range c.events {
+			e := &c.events[i]
+			if c.filter != nil && !c.filter.Match(e.summary) {
+				continue
+			}
+			if !e.activeAt(now) {
+				continue
+			}
+			if c.Type == "maintenance" {
+				windows = append(windows, e.summary)
+			} else {
+				holidays = append(holidays, e.summary)
+			}
+		}
+	}
+
+	isHoliday := len(holidays) > 0
+	var businessDay, businessHours bool
+	for _, h := range b.hours {
+		businessDay = businessDay || h.includesDay(now)
+		businessHours = businessHours || h.contains(now)
+	}
+
+	fields := map[string]interface{}{
+		"in_business_hours":         businessHours && !isHoliday,
+		"is_business_day":           businessDay && !isHoliday,
+		"is_holiday":                isHoliday,
+		"maintenance_window_active": len(windows) > 0,
+		"maintenance_windows":       len(windows),
+	}
+	if isHoliday {
+		fields["holiday"] = joinNames(holidays)
+	}
+	if len(windows) > 0 {
+		fields["maintenance_window"] = joinNames(windows)
+	}
+
+	acc.AddFields("business_calendar", fields, map[string]string{"calendar": b.Name}, now)
+}
+
+// refresh loads the events of the calendar from a file or URL
+func (b *BusinessCalendar) refresh(c *calendar) error {
+	var r io.ReadCloser
+	if strings.HasPrefix(c.URL, "http://") || strings.HasPrefix(c.URL, "https://") {
+		resp, err := b.client.Get(c.URL)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("received status %q", resp.Status)
+		}
+		r = resp.Body
+	} else {
+		f, err := os.Open(strings.TrimPrefix(c.URL, "file://"))
+		if err != nil {
+			return err
+		}
+		r = f
+	}
+	defer r.Close()
+
+	events, err := parseICal(r, b.location)
+	if err != nil {
+		return fmt.Errorf("parsing calendar failed: %w", err)
+	}
+	c.events = events
+	b.Log.Debugf("Loaded %d events from calendar %q", len(events), c.URL)
+
+	return nil
+}
+
+// parseHoliday parses entries like "2025-12-24 Christmas Eve" or
+// "12-25 Christmas Day" for holidays recurring every year
+func parseHoliday(entry string) (holiday, error) {
+	date, name, _ := strings.Cut(strings.TrimSpace(entry), " ")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = date
+	}
+
+	if t, err := time.Parse("2006-01-02", date); err == nil {
+		return holiday{name: name, year: t.Year(), month: t.Month(), day: t.Day()}, nil
+	}
+	// Use a leap year to allow February 29th
+	t, err := time.Parse("2006-01-02", "2000-"+date)
+	if err != nil {
+		return holiday{}, errors.New("expected date as YYYY-MM-DD or MM-DD")
+	}
+	return holiday{name: name, month: t.Month(), day: t.Day()}, nil
+}
+
+func (h *holiday) matches(t time.Time) bool {
+	return (h.year == 0 || h.year == t.Year()) && h.month == t.Month() && h.day == t.Day()
+}
+
+func joinNames(names []string) string {
+	sort.Strings(names)
+	return strings.Join(slices.Compact(names), ",")
+}
+
+func init() {
+	inputs.Add("business_calendar", func() telegraf.Input {
+		return &BusinessCalendar{
+			Timezone:      "Local",
+			BusinessHours: []string{"Mon-Fri 09:00-17:00"},
+		}
+	})
+}
@@ -0,0 +1,325 @@
+package business_calendar
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *BusinessCalendar
+		expected string
+	}{
+		{
+			name:     "invalid timezone",
+			plugin:   &BusinessCalendar{Timezone: "Mars/Olympus"},
+			expected: `loading timezone "Mars/Olympus" failed`,
+		},
+		{
+			name:     "invalid business hours",
+			plugin:   &BusinessCalendar{BusinessHours: []string{"Mon-Fri 09:00-25:00"}},
+			expected: `invalid business hours "Mon-Fri 09:00-25:00": invalid time "25:00"`,
+		},
+		{
+			name:     "invalid holiday",
+			plugin:   &BusinessCalendar{Holidays: []string{"24.12.2025"}},
+			expected: "expected date as YYYY-MM-DD or MM-DD",
+		},
+		{
+			name: "invalid schedule",
+			plugin: &BusinessCalendar{
+				MaintenanceWindows: []*maintenanceWindow{{Schedule: "0 25 * * *", Duration: config.Duration(time.Hour)}},
+			},
+			expected: `invalid schedule "0 25 * * *" of maintenance window "window-1": invalid hour field`,
+		},
+		{
+			name: "missing duration",
+			plugin: &BusinessCalendar{
+				MaintenanceWindows: []*maintenanceWindow{{Name: "patch", Schedule: "0 22 * * SAT"}},
+			},
+			expected: `duration of maintenance window "patch" must be between`,
+		},
+		{
+			name:     "invalid calendar type",
+			plugin:   &BusinessCalendar{Calendars: []*calendar{{URL: "holidays.ics", Type: "vacation"}}},
+			expected: `invalid type "vacation"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestCron(t *testing.T) {
+	tests := []struct {
+		spec     string
+		time     string
+		expected bool
+	}{
+		{spec: "* * * * *", time: "2025-06-02T10:17:00Z", expected: true},
+		{spec: "*/15 9-17 * * MON-FRI", time: "2025-06-02T10:15:00Z", expected: true},
+		{spec: "*/15 9-17 * * MON-FRI", time: "2025-06-02T10:16:00Z"},
+		{spec: "*/15 9-17 * * MON-FRI", time: "2025-06-01T10:15:00Z"},
+		{spec: "0 22 * * 7", time: "2025-06-01T22:00:00Z", expected: true},
+		{spec: "30 2 1,15 jan-mar *", time: "2025-02-15T02:30:00Z", expected: true},
+		{spec: "30 2 1,15 jan-mar *", time: "2025-04-15T02:30:00Z"},
+		// Both day fields restricted match either field
+		{spec: "0 0 13 * FRI", time: "2025-06-13T00:00:00Z", expected: true},
+		{spec: "0 0 13 * FRI", time: "2025-06-06T00:00:00Z", expected: true},
+		{spec: "0 0 13 * FRI", time: "2025-06-07T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec+" "+tt.time, func(t *testing.T) {
+			s, err := parseCron(tt.spec)
+			require.NoError(t, err)
+			ts, err := time.Parse(time.RFC3339, tt.time)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, s.matches(ts))
+		})
+	}
+
+	for _, spec := range []string{"* * * *", "60 * * * *", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		_, err := parseCron(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestBusinessHours(t *testing.T) {
+	tests := []struct {
+		rule     string
+		time     string
+		expected bool
+	}{
+		{rule: "Mon-Fri 09:00-17:00", time: "2025-06-02T09:00:00Z", expected: true},
+		{rule: "Mon-Fri 09:00-17:00", time: "2025-06-02T17:00:00Z"},
+		{rule: "Mon-Fri 09:00-17:00", time: "2025-06-01T12:00:00Z"},
+		{rule: "Sat,Sun 10:00-24:00", time: "2025-06-01T23:59:00Z", expected: true},
+		// Ranges spanning midnight belong to the starting day
+		{rule: "Fri 22:00-06:00", time: "2025-06-06T23:00:00Z", expected: true},
+		{rule: "Fri 22:00-06:00", time: "2025-06-07T05:59:00Z", expected: true},
+		{rule: "Fri 22:00-06:00", time: "2025-06-06T05:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule+" "+tt.time, func(t *testing.T) {
+			h, err := parseBusinessHours(tt.rule)
+			require.NoError(t, err)
+			ts, err := time.Parse(time.RFC3339, tt.time)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, h.contains(ts))
+		})
+	}
+}
+
+func TestParseICal(t *testing.T) {
+	f, err := os.Open("testdata/calendar.ics")
+	require.NoError(t, err)
+	defer f.Close()
+
+	events, err := parseICal(f, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	require.Equal(t, "Labour Day", events[0].summary)
+	require.Equal(t, 24*time.Hour, events[0].duration)
+	require.Equal(t, "Christmas Eve, half day", events[1].summary)
+	require.Equal(t, 24*time.Hour, events[1].duration)
+	require.Equal(t, "Database patching", events[2].summary)
+	require.Equal(t, 4*time.Hour, events[2].duration)
+	require.Equal(t, time.Date(2025, 1, 3, 22, 0, 0, 0, berlin), events[2].start)
+	require.Equal(t, &recurrence{
+		freq:     "WEEKLY",
+		interval: 1,
+		until:    time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC),
+		byday:    []time.Weekday{time.Friday, time.Saturday},
+	}, events[2].rule)
+
+	tests := []struct {
+		event    int
+		time     time.Time
+		expected bool
+	}{
+		{event: 0, time: time.Date(2025, 5, 1, 23, 59, 0, 0, time.UTC), expected: true},
+		{event: 0, time: time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC)},
+		// Friday and Saturday nights
+		{event: 2, time: time.Date(2025, 1, 4, 1, 0, 0, 0, berlin), expected: true},
+		{event: 2, time: time.Date(2025, 1, 4, 23, 0, 0, 0, berlin), expected: true},
+		{event: 2, time: time.Date(2025, 1, 5, 23, 0, 0, 0, berlin)},
+		// Excluded occurrence
+		{event: 2, time: time.Date(2025, 1, 10, 23, 0, 0, 0, berlin)},
+		{event: 2, time: time.Date(2025, 1, 11, 23, 0, 0, 0, berlin), expected: true},
+		// Occurrence after summer time change
+		{event: 2, time: time.Date(2025, 7, 4, 22, 30, 0, 0, berlin), expected: true},
+		// After the end of the recurrence
+		{event: 2, time: time.Date(2026, 1, 2, 23, 0, 0, 0, berlin)},
+		// Limited by count
+		{event: 3, time: time.Date(2025, 8, 1, 8, 30, 0, 0, time.UTC), expected: true},
+		{event: 3, time: time.Date(2025, 9, 1, 8, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, events[tt.event].activeAt(tt.time), "event %d at %s", tt.event, tt.time)
+	}
+}
+
+func TestParseICalInvalid(t *testing.T) {
+	for _, content := range []string{
+		"BEGIN:VEVENT\nSUMMARY:no start\nEND:VEVENT\n",
+		"BEGIN:VEVENT\nDTSTART:20250101T000000Z\nRRULE:FREQ=SECONDLY\nEND:VEVENT\n",
+		"BEGIN:VEVENT\nDTSTART:20250101T000000Z\nDURATION:1H\nEND:VEVENT\n",
+		"BEGIN:VEVENT\ninvalid line\nEND:VEVENT\n",
+	} {
+		_, err := parseICal(strings.NewReader(content), time.UTC)
+		require.Error(t, err, content)
+	}
+}
+
+func TestGatherAt(t *testing.T) {
+	plugin := &BusinessCalendar{
+		Name:          "office",
+		Timezone:      "UTC",
+		BusinessHours: []string{"Mon-Fri 09:00-17:00"},
+		Holidays:      []string{"12-25 Christmas Day", "2025-06-09 Whit Monday"},
+		MaintenanceWindows: []*maintenanceWindow{
+			{Name: "backup", Schedule: "0 16 * * MON-FRI", Duration: config.Duration(2 * time.Hour)},
+			{Name: "patch", Schedule: "30 16 * * *", Duration: config.Duration(time.Hour)},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	tests := []struct {
+		name     string
+		time     time.Time
+		expected map[string]interface{}
+	}{
+		{
+			name: "business hours",
+			time: time.Date(2025, 6, 10, 10, 0, 0, 0, time.UTC),
+			expected: map[string]interface{}{
+				"in_business_hours":         true,
+				"is_business_day":           true,
+				"is_holiday":                false,
+				"maintenance_window_active": false,
+				"maintenance_windows":       0,
+			},
+		},
+		{
+			name: "holiday",
+			time: time.Date(2025, 6, 9, 10, 0, 0, 0, time.UTC),
+			expected: map[string]interface{}{
+				"in_business_hours":         false,
+				"is_business_day":           false,
+				"is_holiday":                true,
+				"holiday":                   "Whit Monday",
+				"maintenance_window_active": false,
+				"maintenance_windows":       0,
+			},
+		},
+		{
+			name: "overlapping maintenance windows",
+			time: time.Date(2025, 6, 10, 17, 15, 0, 0, time.UTC),
+			expected: map[string]interface{}{
+				"in_business_hours":         false,
+				"is_business_day":           true,
+				"is_holiday":                false,
+				"maintenance_window_active": true,
+				"maintenance_windows":       2,
+				"maintenance_window":        "backup,patch",
+			},
+		},
+		{
+			name: "weekend",
+			time: time.Date(2025, 6, 14, 18, 0, 0, 0, time.UTC),
+			expected: map[string]interface{}{
+				"in_business_hours":         false,
+				"is_business_day":           false,
+				"is_holiday":                false,
+				"maintenance_window_active": false,
+				"maintenance_windows":       0,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var acc testutil.Accumulator
+			plugin.gatherAt(&acc, tt.time)
+
+			expected := []telegraf.Metric{
+				metric.New("business_calendar", map[string]string{"calendar": "office"}, tt.expected, tt.time),
+			}
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestGatherICal(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/holidays.ics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeFile(w, r, "testdata/calendar.ics")
+	}))
+	defer ts.Close()
+
+	plugin := &BusinessCalendar{
+		Timezone: "UTC",
+		Calendars: []*calendar{
+			{URL: ts.URL + "/holidays.ics", Events: []string{"Labour Day", "Christmas*"}},
+			{URL: "testdata/calendar.ics", Type: "maintenance", Events: []string{"Database*"}},
+			{URL: ts.URL + "/missing.ics"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `received status "404 Not Found"`)
+	require.Equal(t, 2, requests)
+	require.Len(t, plugin.Calendars[0].events, 4)
+	require.Len(t, plugin.Calendars[1].events, 4)
+
+	// Calendars are only reloaded after the refresh interval
+	require.NoError(t, plugin.Gather(&acc))
+	require.Equal(t, 3, requests)
+
+	acc.ClearMetrics()
+	plugin.gatherAt(&acc, time.Date(2025, 12, 24, 12, 0, 0, 0, time.UTC))
+	fields := acc.GetTelegrafMetrics()[0].Fields()
+	require.Equal(t, true, fields["is_holiday"])
+	require.Equal(t, "Christmas Eve, half day", fields["holiday"])
+	require.Equal(t, false, fields["maintenance_window_active"])
+
+	acc.ClearMetrics()
+	plugin.gatherAt(&acc, time.Date(2025, 3, 7, 22, 0, 0, 0, time.UTC))
+	fields = acc.GetTelegrafMetrics()[0].Fields()
+	require.Equal(t, false, fields["is_holiday"])
+	require.Equal(t, true, fields["maintenance_window_active"])
+	require.Equal(t, "Database patching", fields["maintenance_window"])
+}
@@ -0,0 +1,357 @@
+package business_calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Maximum number of occurrences evaluated per recurring event
+const maxOccurrences = 100000
+
+var icalDurationRe = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// icalEvent is an event of an iCalendar (RFC 5545) file
+type icalEvent struct {
+	summary  string
+	start    time.Time
+	duration time.Duration
+	rule     *recurrence
+	exdates  []time.Time
+}
+
+// recurrence is the subset of recurrence rules supported by the plugin
+type recurrence struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	byday    []time.Weekday
+}
+
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseICal extracts the events from an iCalendar file, times without zone
+// information are interpreted in the given location
+func parseICal(r io.Reader, loc *time.Location) ([]icalEvent, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []icalEvent
+	var props []icalProperty
+	var inEvent bool
+	var depth int
+	for i, line := range lines {
+		p, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		switch {
+		case p.name == "BEGIN" && p.value == "VEVENT":
+			inEvent, depth, props = true, 0, nil
+		case p.name == "BEGIN" && inEvent:
+			// Skip nested components like alarms
+			depth++
+		case p.name == "END" && inEvent && depth > 0:
+			depth--
+		case p.name == "END" && p.value == "VEVENT":
+			inEvent = false
+			e, err := newEvent(props, loc)
+			if err != nil {
+				return nil, fmt.Errorf("event ending in line %d: %w", i+1, err)
+			}
+			events = append(events, e)
+		case inEvent && depth == 0:
+			props = append(props, p)
+		}
+	}
+	return events, nil
+}
+
+// unfold joins continuation lines starting with whitespace
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+func parseProperty(line string) (icalProperty, error) {
+	// Find the value separator outside of quoted parameter values
+	var quoted bool
+	idx := -1
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return icalProperty{}, fmt.Errorf("invalid content line %q", line)
+	}
+
+	p := icalProperty{params: make(map[string]string), value: line[idx+1:]}
+	parts := strings.Split(line[:idx], ";")
+	p.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		k, v, _ := strings.Cut(param, "=")
+		p.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return p, nil
+}
+
+func newEvent(props []icalProperty, loc *time.Location) (icalEvent, error) {
+	var e icalEvent
+	var end time.Time
+	var allDay, hasDuration bool
+	for _, p := range props {
+		var err error
+		switch p.name {
+		case "SUMMARY":
+			e.summary = unescapeText(p.value)
+		case "DTSTART":
+			e.start, allDay, err = parseICalTime(p, loc)
+		case "DTEND":
+			end, _, err = parseICalTime(p, loc)
+		case "DURATION":
+			e.duration, err = parseICalDuration(p.value)
+			hasDuration = true
+		case "RRULE":
+			e.rule, err = parseRecurrence(p.value, loc)
+		case "EXDATE":
+			for _, v := range strings.Split(p.value, ",") {
+				var t time.Time
+				if t, _, err = parseICalTime(icalProperty{params: p.params, value: v}, loc); err != nil {
+					break
+				}
+				e.exdates = append(e.exdates, t)
+			}
+		}
+		if err != nil {
+			return e, fmt.Errorf("invalid %s: %w", p.name, err)
+		}
+	}
+
+	if e.start.IsZero() {
+		return e, fmt.Errorf("missing DTSTART in event %q", e.summary)
+	}
+	switch {
+	case !end.IsZero():
+		e.duration = end.Sub(e.start)
+	case hasDuration:
+	case allDay:
+		// All-day events without end last one day
+		e.duration = e.start.AddDate(0, 0, 1).Sub(e.start)
+	}
+	if e.duration < 0 {
+		return e, fmt.Errorf("event %q ends before its start", e.summary)
+	}
+	return e, nil
+}
+
+func parseICalTime(p icalProperty, loc *time.Location) (time.Time, bool, error) {
+	if p.params["VALUE"] == "DATE" || len(p.value) == 8 {
+		t, err := time.ParseInLocation("20060102", p.value, loc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(p.value, "Z") {
+		t, err := time.Parse("20060102T150405Z", p.value)
+		return t, false, err
+	}
+
+	if tzid := p.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", p.value, loc)
+	return t, false, err
+}
+
+func parseICalDuration(s string) (time.Duration, error) {
+	m := icalDurationRe.FindStringSubmatch(s)
+	if m == nil || s == "P" || strings.HasSuffix(s, "T") {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if m[i+2] == "" {
+			continue
+		}
+		v, err := strconv.Atoi(m[i+2])
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(v) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+func parseRecurrence(s string, loc *time.Location) (*recurrence, error) {
+	r := &recurrence{interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		k, v, _ := strings.Cut(part, "=")
+		var err error
+		switch strings.ToUpper(k) {
+		case "FREQ":
+			r.freq = strings.ToUpper(v)
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(v)
+			if err == nil && r.interval < 1 {
+				err = fmt.Errorf("invalid interval %d", r.interval)
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(v)
+		case "UNTIL":
+			r.until, _, err = parseICalTime(icalProperty{value: v}, loc)
+		case "BYDAY":
+			for _, d := range strings.Split(v, ",") {
+				wd, found := icalWeekdays[strings.ToUpper(d)]
+				if !found {
+					return nil, fmt.Errorf("unsupported BYDAY value %q", d)
+				}
+				r.byday = append(r.byday, wd)
+			}
+		case "WKST":
+		default:
+			return nil, fmt.Errorf("unsupported rule part %q", k)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", k, err)
+		}
+	}
+
+	switch r.freq {
+	case "DAILY", "MONTHLY", "YEARLY":
+		if len(r.byday) > 0 {
+			return nil, fmt.Errorf("BYDAY not supported for frequency %s", r.freq)
+		}
+	case "WEEKLY":
+	default:
+		return nil, fmt.Errorf("unsupported frequency %q", r.freq)
+	}
+	return r, nil
+}
+
+// activeAt checks if an occurrence of the event covers the given time
+func (e *icalEvent) activeAt(t time.Time) bool {
+	if e.rule == nil {
+		return e.covers(e.start, t)
+	}
+
+	active := false
+	e.rule.occurrences(e.start, t, func(o time.Time) {
+		if !active && e.covers(o, t) && !slices.ContainsFunc(e.exdates, o.Equal) {
+			active = true
+		}
+	})
+	return active
+}
+
+func (e *icalEvent) covers(start, t time.Time) bool {
+	if e.duration == 0 {
+		return t.Equal(start)
+	}
+	return !t.Before(start) && t.Before(start.Add(e.duration))
+}
+
+// occurrences calls the given function for each occurrence of the rule
+// starting at or before the given time
+func (r *recurrence) occurrences(start, until time.Time, fn func(time.Time)) {
+	var n int
+	emit := func(o time.Time) bool {
+		if o.After(until) || (!r.until.IsZero() && o.After(r.until)) || (r.count > 0 && n >= r.count) {
+			return false
+		}
+		n++
+		fn(o)
+		return true
+	}
+
+	for k := 0; k < maxOccurrences; k++ {
+		switch r.freq {
+		case "DAILY":
+			if !emit(start.AddDate(0, 0, k*r.interval)) {
+				return
+			}
+		case "WEEKLY":
+			if len(r.byday) == 0 {
+				if !emit(start.AddDate(0, 0, 7*k*r.interval)) {
+					return
+				}
+				continue
+			}
+			// Weeks start on Monday by default
+			offset := (int(start.Weekday()) + 6) % 7
+			week := start.AddDate(0, 0, 7*k*r.interval-offset)
+			days := make([]int, 0, len(r.byday))
+			for _, d := range r.byday {
+				days = append(days, (int(d)+6)%7)
+			}
+			slices.Sort(days)
+			for _, d := range days {
+				o := week.AddDate(0, 0, d)
+				if o.Before(start) {
+					continue
+				}
+				if !emit(o) {
+					return
+				}
+			}
+		case "MONTHLY", "YEARLY":
+			var o time.Time
+			if r.freq == "MONTHLY" {
+				o = start.AddDate(0, k*r.interval, 0)
+			} else {
+				o = start.AddDate(k*r.interval, 0, 0)
+			}
+			// Skip invalid dates like February 30th
+			if o.Day() != start.Day() {
+				if o.After(until) {
+					return
+				}
+				continue
+			}
+			if !emit(o) {
+				return
+			}
+		}
+	}
+}
+
+func unescapeText(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
@@ -0,0 +1,223 @@
+package business_calendar
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+// cronSchedule is a schedule in the five field cron syntax
+// "minute hour day-of-month month day-of-week"
+type cronSchedule struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+
+	// Cron semantics match either day field if both are restricted
+	anyDay     bool
+	anyWeekday bool
+}
+
+func parseCron(spec string) (*cronSchedule, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("expected 5 fields but got %d", len(parts))
+	}
+
+	var s cronSchedule
+	var err error
+	if s.minutes, err = parseCronField(parts[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid minute field: %w", err)
+	}
+	if s.hours, err = parseCronField(parts[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid hour field: %w", err)
+	}
+	if s.days, err = parseCronField(parts[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid day-of-month field: %w", err)
+	}
+	if s.months, err = parseCronField(parts[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("invalid month field: %w", err)
+	}
+	if s.weekdays, err = parseCronField(parts[4], 0, 7, weekdayNames); err != nil {
+		return nil, fmt.Errorf("invalid day-of-week field: %w", err)
+	}
+	// Both zero and seven denote Sunday
+	if s.weekdays&(1<<7) != 0 {
+		s.weekdays |= 1
+	}
+	s.anyDay = strings.HasPrefix(parts[2], "*")
+	s.anyWeekday = strings.HasPrefix(parts[4], "*")
+
+	return &s, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges and steps
+// into a bit set
+func parseCronField(field string, minimum, maximum int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		expr, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+		}
+
+		start, end := minimum, maximum
+		if expr != "*" {
+			from, to, isRange := strings.Cut(expr, "-")
+			var err error
+			if start, err = parseCronValue(from, minimum, maximum, names); err != nil {
+				return 0, err
+			}
+			end = start
+			if isRange {
+				if end, err = parseCronValue(to, minimum, maximum, names); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				end = maximum
+			}
+			if end < start {
+				return 0, fmt.Errorf("invalid range %q", expr)
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(s string, minimum, maximum int, names map[string]int) (int, error) {
+	if v, found := names[strings.ToLower(s)]; found {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < minimum || v > maximum {
+		return 0, fmt.Errorf("value %d out of range [%d,%d]", v, minimum, maximum)
+	}
+	return v, nil
+}
+
+// matches checks if the schedule fires at the minute of the given time
+func (s *cronSchedule) matches(t time.Time) bool {
+	if s.minutes&(1<<uint(t.Minute())) == 0 || s.hours&(1<<uint(t.Hour())) == 0 || s.months&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	day := s.days&(1<<uint(t.Day())) != 0
+	weekday := s.weekdays&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
+
+// active checks if the schedule fired within the given duration before t,
+// i.e. if a window of the given duration started by the schedule is active
+func (s *cronSchedule) active(t time.Time, duration time.Duration) bool {
+	start := t.Truncate(time.Minute)
+	for m := start; t.Sub(m) < duration; m = m.Add(-time.Minute) {
+		if s.matches(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// businessHours is a rule like "Mon-Fri 09:00-17:00"
+type businessHours struct {
+	weekdays uint64
+	start    time.Duration
+	end      time.Duration
+}
+
+func parseBusinessHours(rule string) (*businessHours, error) {
+	days, hours, found := strings.Cut(strings.TrimSpace(rule), " ")
+	if !found {
+		return nil, errors.New("expected weekdays and time range separated by space")
+	}
+
+	weekdays, err := parseCronField(strings.TrimSpace(days), 0, 7, weekdayNames)
+	if err != nil {
+		return nil, fmt.Errorf("invalid weekdays: %w", err)
+	}
+	if weekdays&(1<<7) != 0 {
+		weekdays |= 1
+	}
+
+	from, to, found := strings.Cut(strings.TrimSpace(hours), "-")
+	if !found {
+		return nil, fmt.Errorf("invalid time range %q", hours)
+	}
+	start, err := parseTimeOfDay(from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseTimeOfDay(to)
+	if err != nil {
+		return nil, err
+	}
+	if start == end {
+		return nil, fmt.Errorf("empty time range %q", hours)
+	}
+
+	return &businessHours{weekdays: weekdays, start: start, end: end}, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	hour, minute, found := strings.Cut(s, ":")
+	h, err := strconv.Atoi(hour)
+	if !found || err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	m, err := strconv.Atoi(minute)
+	if err != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// contains checks if the given time is within the business hours, ranges
+// ending before their start span midnight and belong to the starting day
+func (b *businessHours) contains(t time.Time) bool {
+	// Use the wall clock to be independent of daylight saving time changes
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	today := b.weekdays&(1<<uint(t.Weekday())) != 0
+	if b.start < b.end {
+		return today && offset >= b.start && offset < b.end
+	}
+	yesterday := b.weekdays&(1<<uint((t.Weekday()+6)%7)) != 0
+	return (today && offset >= b.start) || (yesterday && offset < b.end)
+}
+
+// includesDay checks if the business hours apply to the weekday of t
+func (b *businessHours) includesDay(t time.Time) bool {
+	return b.weekdays&(1<<uint(t.Weekday())) != 0
+}
@@ -0,0 +1,88 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package canary
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Canary struct {
+	MetricName string          `toml:"metric_name"`
+	Source     string          `toml:"source"`
+	Series     int             `toml:"series"`
+	Log        telegraf.Logger `toml:"-"`
+
+	runID    string
+	sequence uint64
+}
+
+func (*Canary) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *Canary) Init() error {
+	if c.MetricName == "" {
+		return errors.New("'metric_name' required")
+	}
+	if c.Series < 1 {
+		return fmt.Errorf("invalid number of series %d", c.Series)
+	}
+
+	if c.Source == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("determining hostname failed: %w", err)
+		}
+		c.Source = hostname
+	}
+
+	// The run ID allows the receiving end to detect restarts of the canary
+	// source and to reset the sequence tracking accordingly
+	id, err := internal.RandomString(16)
+	if err != nil {
+		return fmt.Errorf("generating run ID failed: %w", err)
+	}
+	c.runID = id
+
+	return nil
+}
+
+func (c *Canary) Gather(acc telegraf.Accumulator) error {
+	c.sequence++
+
+	now := time.Now()
+	for i := range c.Series {
+		tags := map[string]string{
+			"source": c.Source,
+			"series": strconv.Itoa(i),
+		}
+		fields := map[string]interface{}{
+			"sequence": c.sequence,
+			"run_id":   c.runID,
+			"sent":     now.UnixNano(),
+		}
+		acc.AddFields(c.MetricName, fields, tags, now)
+	}
+
+	return nil
+}
+
+func init() {
+	inputs.Add("canary", func() telegraf.Input {
+		return &Canary{
+			MetricName: "canary",
+			Series:     1,
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package canary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &Canary{Series: 1}
+	require.ErrorContains(t, plugin.Init(), "'metric_name' required")
+
+	plugin = &Canary{MetricName: "canary"}
+	require.ErrorContains(t, plugin.Init(), "invalid number of series 0")
+}
+
+func TestInitHostname(t *testing.T) {
+	plugin := &Canary{MetricName: "canary", Series: 1}
+	require.NoError(t, plugin.Init())
+	require.NotEmpty(t, plugin.Source)
+	require.Len(t, plugin.runID, 16)
+}
+
+func TestGather(t *testing.T) {
+	plugin := &Canary{
+		MetricName: "heartbeat",
+		Source:     "relay01",
+		Series:     3,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	for range 2 {
+		require.NoError(t, plugin.Gather(&acc))
+	}
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 6)
+	for i, m := range metrics {
+		require.Equal(t, "heartbeat", m.Name())
+		require.Equal(t, map[string]string{
+			"source": "relay01",
+			"series": []string{"0", "1", "2"}[i%3],
+		}, m.Tags())
+
+		seq, found := m.GetField("sequence")
+		require.True(t, found)
+		require.Equal(t, uint64(i/3+1), seq)
+
+		runID, found := m.GetField("run_id")
+		require.True(t, found)
+		require.Equal(t, plugin.runID, runID)
+
+		sent, found := m.GetField("sent")
+		require.True(t, found)
+		require.Equal(t, m.Time().UnixNano(), sent)
+	}
+}
@@ -0,0 +1,138 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package ceph_mgr
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type CephMgr struct {
+	URL      string          `toml:"url"`
+	Module   string          `toml:"module"`
+	Collect  []string        `toml:"collect"`
+	Username config.Secret   `toml:"username"`
+	Password config.Secret   `toml:"password"`
+	Keyring  string          `toml:"keyring"`
+	Token    config.Secret   `toml:"token"`
+	Log      telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client   *http.Client
+	password config.Secret
+	session  string
+}
+
+func (*CephMgr) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *CephMgr) Init() error {
+	if c.URL == "" {
+		return errors.New("no URL specified")
+	}
+	if _, err := url.Parse(c.URL); err != nil {
+		return fmt.Errorf("invalid URL %q: %w", c.URL, err)
+	}
+	c.URL = strings.TrimSuffix(c.URL, "/")
+
+	switch c.Module {
+	case "dashboard":
+		for _, s := range c.Collect {
+			switch s {
+			case "pg", "pool", "osd", "rgw_bucket":
+			default:
+				return fmt.Errorf("invalid 'collect' value %q", s)
+			}
+		}
+		if !c.Token.Empty() && (!c.Password.Empty() || c.Keyring != "") {
+			return errors.New("'token' cannot be used together with 'password' or 'keyring'")
+		}
+		if !c.Password.Empty() && c.Keyring != "" {
+			return errors.New("'password' cannot be used together with 'keyring'")
+		}
+		if c.Token.Empty() && c.Username.Empty() {
+			return errors.New("either 'token' or 'username' is required for the dashboard module")
+		}
+		c.password = c.Password
+		if c.Keyring != "" {
+			key, err := c.keyringKey()
+			if err != nil {
+				return err
+			}
+			c.password = config.NewSecret(key)
+		}
+	case "prometheus":
+		for _, s := range c.Collect {
+			switch s {
+			case "pg", "pool", "osd":
+			case "rgw_bucket":
+				return errors.New("collecting 'rgw_bucket' is not supported by the prometheus module")
+			default:
+				return fmt.Errorf("invalid 'collect' value %q", s)
+			}
+		}
+		if !c.Token.Empty() || !c.Username.Empty() || !c.Password.Empty() || c.Keyring != "" {
+			return errors.New("authentication is not supported by the prometheus module")
+		}
+	default:
+		return fmt.Errorf("invalid module %q", c.Module)
+	}
+
+	client, err := c.HTTPClientConfig.CreateClient(context.Background(), c.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	c.client = client
+
+	return nil
+}
+
+func (c *CephMgr) Gather(acc telegraf.Accumulator) error {
+	if c.Module == "prometheus" {
+		return c.gatherPrometheus(acc)
+	}
+
+	for _, s := range c.Collect {
+		var err error
+		switch s {
+		case "pg":
+			err = c.gatherPGStates(acc)
+		case "pool":
+			err = c.gatherPools(acc)
+		case "osd":
+			err = c.gatherOSDs(acc)
+		case "rgw_bucket":
+			err = c.gatherRGWBuckets(acc)
+		}
+		if err != nil {
+			acc.AddError(fmt.Errorf("gathering %q failed: %w", s, err))
+		}
+	}
+	return nil
+}
+
+func init() {
+	inputs.Add("ceph_mgr", func() telegraf.Input {
+		return &CephMgr{
+			Module:  "dashboard",
+			Collect: []string{"pg", "pool", "osd"},
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
@@ -0,0 +1,424 @@
+package ceph_mgr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// newDashboard mocks the dashboard REST API serving the responses in the
+// testdata directory. Session tokens are only valid for the given number of
+// requests.
+func newDashboard(t *testing.T, password string, validity int, logins *atomic.Int32) *httptest.Server {
+	responses := map[string]string{
+		"/api/health/minimal": "health.json",
+		"/api/pool":           "pool.json",
+		"/api/osd":            "osd.json",
+		"/api/rgw/bucket":     "bucket.json",
+	}
+
+	var remaining atomic.Int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != dashboardMediaType {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+
+		if r.URL.Path == "/api/auth" {
+			var creds map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if creds["username"] != "telegraf" || creds["password"] != password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			logins.Add(1)
+			remaining.Store(int32(validity))
+			w.WriteHeader(http.StatusCreated)
+			if _, err := w.Write([]byte(`{"token": "session", "username": "telegraf"}`)); err != nil {
+				t.Error(err)
+			}
+			return
+		}
+
+		switch r.Header.Get("Authorization") {
+		case "Bearer static":
+		case "Bearer session":
+			if remaining.Add(-1) < 0 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		fn, found := responses[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buf, err := os.ReadFile(filepath.Join("testdata", fn))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func expectedDashboardMetrics() []telegraf.Metric {
+	return []telegraf.Metric{
+		metric.New("ceph_mgr_pg",
+			map[string]string{"state": "active+clean"},
+			map[string]interface{}{"count": int64(95)},
+			time.Unix(0, 0),
+		),
+		metric.New("ceph_mgr_pg",
+			map[string]string{"state": "active+clean+scrubbing"},
+			map[string]interface{}{"count": int64(2)},
+			time.Unix(0, 0),
+		),
+		metric.New("ceph_mgr_pool",
+			map[string]string{"pool_id": "1", "pool_name": ".mgr"},
+			map[string]interface{}{
+				"stored":       int64(1048576),
+				"objects":      int64(2),
+				"max_avail":    int64(3221225472),
+				"percent_used": float64(0.0003),
+				"rd":           int64(120),
+				"wr_bytes":     int64(4096),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New("ceph_mgr_osd",
+			map[string]string{"osd_id": "0", "hostname": "node1", "device_class": "hdd"},
+			map[string]interface{}{
+				"up":                int64(1),
+				"in":                int64(1),
+				"op_w":              float64(0.5),
+				"op_r":              float64(1.25),
+				"numpg":             int64(33),
+				"stat_bytes":        int64(10737418240),
+				"stat_bytes_used":   int64(1073741824),
+				"usage":             float64(0.1),
+				"commit_latency_ms": int64(2),
+				"apply_latency_ms":  int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New("ceph_mgr_osd",
+			map[string]string{"osd_id": "1", "hostname": "node2", "device_class": "ssd"},
+			map[string]interface{}{
+				"up":              int64(0),
+				"in":              int64(1),
+				"op_w":            float64(0),
+				"op_r":            float64(0),
+				"numpg":           int64(0),
+				"stat_bytes":      int64(0),
+				"stat_bytes_used": int64(0),
+				"usage":           int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New("ceph_mgr_rgw_bucket",
+			map[string]string{"bucket": "backups", "owner": "admin"},
+			map[string]interface{}{
+				"size":        int64(2048),
+				"size_actual": int64(8192),
+				"num_objects": int64(3),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New("ceph_mgr_rgw_bucket",
+			map[string]string{"bucket": "empty", "owner": "telegraf"},
+			map[string]interface{}{
+				"size":        int64(0),
+				"size_actual": int64(0),
+				"num_objects": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *CephMgr
+		expected string
+	}{
+		{
+			name:     "no url",
+			plugin:   &CephMgr{Module: "dashboard"},
+			expected: "no URL specified",
+		},
+		{
+			name:     "invalid module",
+			plugin:   &CephMgr{URL: "http://localhost:8443", Module: "restful"},
+			expected: `invalid module "restful"`,
+		},
+		{
+			name: "invalid collect",
+			plugin: &CephMgr{
+				URL:     "http://localhost:8443",
+				Module:  "dashboard",
+				Collect: []string{"mds"},
+				Token:   config.NewSecret([]byte("token")),
+			},
+			expected: `invalid 'collect' value "mds"`,
+		},
+		{
+			name: "no credentials",
+			plugin: &CephMgr{
+				URL:    "http://localhost:8443",
+				Module: "dashboard",
+			},
+			expected: "either 'token' or 'username' is required",
+		},
+		{
+			name: "token and password",
+			plugin: &CephMgr{
+				URL:      "http://localhost:8443",
+				Module:   "dashboard",
+				Token:    config.NewSecret([]byte("token")),
+				Username: config.NewSecret([]byte("telegraf")),
+				Password: config.NewSecret([]byte("secret")),
+			},
+			expected: "'token' cannot be used together with 'password' or 'keyring'",
+		},
+		{
+			name: "unknown keyring user",
+			plugin: &CephMgr{
+				URL:      "http://localhost:8443",
+				Module:   "dashboard",
+				Username: config.NewSecret([]byte("nobody")),
+				Keyring:  filepath.Join("testdata", "keyring"),
+			},
+			expected: `no key for user "nobody" found`,
+		},
+		{
+			name: "rgw buckets with prometheus",
+			plugin: &CephMgr{
+				URL:     "http://localhost:9283",
+				Module:  "prometheus",
+				Collect: []string{"pg", "rgw_bucket"},
+			},
+			expected: "collecting 'rgw_bucket' is not supported by the prometheus module",
+		},
+		{
+			name: "credentials with prometheus",
+			plugin: &CephMgr{
+				URL:    "http://localhost:9283",
+				Module: "prometheus",
+				Token:  config.NewSecret([]byte("token")),
+			},
+			expected: "authentication is not supported by the prometheus module",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestDashboardLogin(t *testing.T) {
+	var logins atomic.Int32
+	server := newDashboard(t, "secret", 100, &logins)
+	defer server.Close()
+
+	plugin := &CephMgr{
+		URL:      server.URL,
+		Module:   "dashboard",
+		Collect:  []string{"pg", "pool", "osd", "rgw_bucket"},
+		Username: config.NewSecret([]byte("telegraf")),
+		Password: config.NewSecret([]byte("secret")),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expectedDashboardMetrics(), acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+	require.Equal(t, int32(1), logins.Load())
+}
+
+func TestDashboardSessionRenewal(t *testing.T) {
+	var logins atomic.Int32
+	server := newDashboard(t, "secret", 2, &logins)
+	defer server.Close()
+
+	plugin := &CephMgr{
+		URL:      server.URL,
+		Module:   "dashboard",
+		Collect:  []string{"pg", "pool", "osd", "rgw_bucket"},
+		Username: config.NewSecret([]byte("telegraf")),
+		Password: config.NewSecret([]byte("secret")),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expectedDashboardMetrics(), acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+	require.Equal(t, int32(2), logins.Load())
+}
+
+func TestDashboardToken(t *testing.T) {
+	var logins atomic.Int32
+	server := newDashboard(t, "secret", 0, &logins)
+	defer server.Close()
+
+	plugin := &CephMgr{
+		URL:     server.URL + "/",
+		Module:  "dashboard",
+		Collect: []string{"pg"},
+		Token:   config.NewSecret([]byte("static")),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+	require.Zero(t, logins.Load())
+
+	// Invalid tokens must not cause login attempts
+	plugin.Token = config.NewSecret([]byte("invalid"))
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "unauthorized")
+	require.Zero(t, logins.Load())
+}
+
+func TestDashboardKeyring(t *testing.T) {
+	var logins atomic.Int32
+	server := newDashboard(t, "AQBtelegrafkeytelegrafkeytelegraf==", 100, &logins)
+	defer server.Close()
+
+	plugin := &CephMgr{
+		URL:      server.URL,
+		Module:   "dashboard",
+		Collect:  []string{"pg"},
+		Username: config.NewSecret([]byte("telegraf")),
+		Keyring:  filepath.Join("testdata", "keyring"),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+	require.Equal(t, int32(1), logins.Load())
+}
+
+func TestDashboardLoginFailed(t *testing.T) {
+	var logins atomic.Int32
+	server := newDashboard(t, "secret", 100, &logins)
+	defer server.Close()
+
+	plugin := &CephMgr{
+		URL:      server.URL,
+		Module:   "dashboard",
+		Collect:  []string{"pg"},
+		Username: config.NewSecret([]byte("telegraf")),
+		Password: config.NewSecret([]byte("wrong")),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "login failed with status 401")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestPrometheus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buf, err := os.ReadFile(filepath.Join("testdata", "metrics.txt"))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if _, err := w.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &CephMgr{
+		URL:     server.URL,
+		Module:  "prometheus",
+		Collect: []string{"pg", "pool", "osd"},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New("ceph_mgr_pg",
+			map[string]string{"state": "active"},
+			map[string]interface{}{"count": int64(33)},
+			time.Unix(0, 0),
+		),
+		metric.New("ceph_mgr_pg",
+			map[string]string{"state": "clean"},
+			map[string]interface{}{"count": int64(31)},
+			time.Unix(0, 0),
+		),
+		metric.New("ceph_mgr_pool",
+			map[string]string{"pool_id": "1", "pool_name": ".mgr"},
+			map[string]interface{}{
+				"stored":    float64(1048576),
+				"max_avail": float64(3221225472),
+				"rd":        float64(120),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New("ceph_mgr_osd",
+			map[string]string{"osd_id": "0", "hostname": "node1", "device_class": "hdd"},
+			map[string]interface{}{
+				"up":               float64(1),
+				"apply_latency_ms": float64(2),
+				"op_r":             float64(1234),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
@@ -0,0 +1,350 @@
+package ceph_mgr
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Media type selecting the version of the dashboard REST API
+const dashboardMediaType = "application/vnd.ceph.api.v1.0+json"
+
+var errUnauthorized = errors.New("unauthorized")
+
+// stat is a statistic of the dashboard API containing the current value and
+// the rate of change, e.g.
+//
+//	{"latest": 1024, "rate": 0.5, "rates": [[1700000000.0, 1000.0], ...]}
+type stat struct {
+	Latest json.Number `json:"latest"`
+}
+
+// health is the part of the '/api/health/minimal' response containing the
+// number of placement groups per state
+type health struct {
+	PGInfo struct {
+		Statuses map[string]json.Number `json:"statuses"`
+	} `json:"pg_info"`
+}
+
+// pool is an entry of the '/api/pool?stats=true' response
+type pool struct {
+	ID    json.Number     `json:"pool"`
+	Name  string          `json:"pool_name"`
+	Stats map[string]stat `json:"stats"`
+}
+
+// osd is an entry of the '/api/osd' response
+type osd struct {
+	ID    json.Number            `json:"osd"`
+	Up    json.Number            `json:"up"`
+	In    json.Number            `json:"in"`
+	Stats map[string]json.Number `json:"stats"`
+	Host  struct {
+		Name string `json:"name"`
+	} `json:"host"`
+	Tree struct {
+		DeviceClass string `json:"device_class"`
+	} `json:"tree"`
+	OSDStats struct {
+		PerfStat map[string]json.Number `json:"perf_stat"`
+	} `json:"osd_stats"`
+}
+
+// bucket is an entry of the '/api/rgw/bucket?stats=true' response
+type bucket struct {
+	Name  string `json:"bucket"`
+	Owner string `json:"owner"`
+	Usage map[string]struct {
+		Size       json.Number `json:"size"`
+		SizeActual json.Number `json:"size_actual"`
+		NumObjects json.Number `json:"num_objects"`
+	} `json:"usage"`
+}
+
+func (c *CephMgr) gatherPGStates(acc telegraf.Accumulator) error {
+	var h health
+	if err := c.get("/api/health/minimal", &h); err != nil {
+		return err
+	}
+	for state, count := range h.PGInfo.Statuses {
+		v, ok := numberValue(count)
+		if !ok {
+			continue
+		}
+		tags := map[string]string{"state": state}
+		acc.AddFields("ceph_mgr_pg", map[string]interface{}{"count": v}, tags)
+	}
+	return nil
+}
+
+func (c *CephMgr) gatherPools(acc telegraf.Accumulator) error {
+	var pools []pool
+	if err := c.get("/api/pool?stats=true", &pools); err != nil {
+		return err
+	}
+	for _, p := range pools {
+		fields := make(map[string]interface{}, len(p.Stats))
+		for name, s := range p.Stats {
+			if v, ok := numberValue(s.Latest); ok {
+				fields[name] = v
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		tags := map[string]string{
+			"pool_id":   p.ID.String(),
+			"pool_name": p.Name,
+		}
+		acc.AddFields("ceph_mgr_pool", fields, tags)
+	}
+	return nil
+}
+
+func (c *CephMgr) gatherOSDs(acc telegraf.Accumulator) error {
+	var osds []osd
+	if err := c.get("/api/osd", &osds); err != nil {
+		return err
+	}
+	for _, o := range osds {
+		fields := make(map[string]interface{}, len(o.Stats)+len(o.OSDStats.PerfStat)+2)
+		for name, n := range o.Stats {
+			if v, ok := numberValue(n); ok {
+				fields[name] = v
+			}
+		}
+		for name, n := range o.OSDStats.PerfStat {
+			if v, ok := numberValue(n); ok {
+				fields[name] = v
+			}
+		}
+		if v, ok := numberValue(o.Up); ok {
+			fields["up"] = v
+		}
+		if v, ok := numberValue(o.In); ok {
+			fields["in"] = v
+		}
+		tags := map[string]string{"osd_id": o.ID.String()}
+		if o.Host.Name != "" {
+			tags["hostname"] = o.Host.Name
+		}
+		if o.Tree.DeviceClass != "" {
+			tags["device_class"] = o.Tree.DeviceClass
+		}
+		acc.AddFields("ceph_mgr_osd", fields, tags)
+	}
+	return nil
+}
+
+func (c *CephMgr) gatherRGWBuckets(acc telegraf.Accumulator) error {
+	var buckets []bucket
+	if err := c.get("/api/rgw/bucket?stats=true", &buckets); err != nil {
+		return err
+	}
+	for _, b := range buckets {
+		// Sum up the usage of all categories, e.g. "rgw.main" and
+		// "rgw.multimeta"
+		var size, sizeActual, objects int64
+		for _, u := range b.Usage {
+			v, _ := u.Size.Int64()
+			size += v
+			v, _ = u.SizeActual.Int64()
+			sizeActual += v
+			v, _ = u.NumObjects.Int64()
+			objects += v
+		}
+		fields := map[string]interface{}{
+			"size":        size,
+			"size_actual": sizeActual,
+			"num_objects": objects,
+		}
+		tags := map[string]string{
+			"bucket": b.Name,
+			"owner":  b.Owner,
+		}
+		acc.AddFields("ceph_mgr_rgw_bucket", fields, tags)
+	}
+	return nil
+}
+
+// get queries the given API path and decodes the response into v. The
+// session is renewed once if the current session token expired.
+func (c *CephMgr) get(path string, v interface{}) error {
+	if c.Token.Empty() && c.session == "" {
+		if err := c.login(); err != nil {
+			return err
+		}
+	}
+
+	err := c.request(path, v)
+	if errors.Is(err, errUnauthorized) && c.Token.Empty() {
+		c.Log.Debug("Session expired, logging in again")
+		if err := c.login(); err != nil {
+			return err
+		}
+		err = c.request(path, v)
+	}
+	return err
+}
+
+func (c *CephMgr) request(path string, v interface{}) error {
+	req, err := http.NewRequest("GET", c.URL+path, nil)
+	if err != nil {
+		return fmt.Errorf("creating request failed: %w", err)
+	}
+	req.Header.Set("Accept", dashboardMediaType)
+	if c.Token.Empty() {
+		req.Header.Set("Authorization", "Bearer "+c.session)
+	} else {
+		token, err := c.Token.Get()
+		if err != nil {
+			return fmt.Errorf("getting token failed: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.String())
+		token.Destroy()
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return errUnauthorized
+	default:
+		return fmt.Errorf("received status %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response failed: %w", err)
+	}
+
+	// Keep the numbers to be able to distinguish integer and float values
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("decoding response failed: %w", err)
+	}
+	return nil
+}
+
+// login authenticates at the dashboard with the configured credentials and
+// stores the returned session token
+func (c *CephMgr) login() error {
+	username, err := c.Username.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	defer username.Destroy()
+	password, err := c.password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	defer password.Destroy()
+
+	body, err := json.Marshal(map[string]string{
+		"username": username.String(),
+		"password": password.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", c.URL+"/api/auth", bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("creating login request failed: %w", err)
+	}
+	req.Header.Set("Accept", dashboardMediaType)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("login failed with status %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decoding login response failed: %w", err)
+	}
+	if result.Token == "" {
+		return errors.New("login response does not contain a token")
+	}
+	c.session = result.Token
+
+	return nil
+}
+
+// keyringKey reads the key of the configured user from the keyring file. The
+// user's section might be named with or without the entity type, e.g.
+// "[client.telegraf]" or "[telegraf]".
+func (c *CephMgr) keyringKey() ([]byte, error) {
+	username, err := c.Username.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting username failed: %w", err)
+	}
+	user := username.String()
+	username.Destroy()
+
+	f, err := os.Open(c.Keyring)
+	if err != nil {
+		return nil, fmt.Errorf("opening keyring failed: %w", err)
+	}
+	defer f.Close()
+
+	var section string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if section != user && section != "client."+user {
+			continue
+		}
+		k, v, found := strings.Cut(line, "=")
+		if found && strings.TrimSpace(k) == "key" {
+			return []byte(strings.TrimSpace(v)), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading keyring failed: %w", err)
+	}
+	return nil, fmt.Errorf("no key for user %q found in keyring %q", user, c.Keyring)
+}
+
+// numberValue converts the number to an integer if possible and to a float
+// otherwise. Missing or null values are reported as not ok.
+func numberValue(n json.Number) (interface{}, bool) {
+	if v, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+		return v, true
+	}
+	if v, err := n.Float64(); err == nil {
+		return v, true
+	}
+	return nil, false
+}
@@ -0,0 +1,164 @@
+package ceph_mgr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/influxdata/telegraf"
+)
+
+// series holds the fields of the prometheus module samples per pool or OSD,
+// keyed by the "pool_id" or "ceph_daemon" label value
+type series map[string]map[string]interface{}
+
+func (s series) add(key, field string, value float64) {
+	if _, found := s[key]; !found {
+		s[key] = make(map[string]interface{})
+	}
+	s[key][field] = value
+}
+
+func (c *CephMgr) gatherPrometheus(acc telegraf.Accumulator) error {
+	families, err := c.scrape()
+	if err != nil {
+		return err
+	}
+
+	pgStates := make(map[string]float64)
+	pools := make(series)
+	poolNames := make(map[string]string)
+	osds := make(series)
+	osdTags := make(map[string]map[string]string)
+	for _, mf := range families {
+		// Summaries and histograms cannot be represented as a single field
+		if t := mf.GetType(); t == dto.MetricType_SUMMARY || t == dto.MetricType_HISTOGRAM {
+			continue
+		}
+
+		name := mf.GetName()
+		switch {
+		case name == "ceph_pool_metadata":
+			for _, m := range mf.GetMetric() {
+				labels := labelMap(m)
+				poolNames[labels["pool_id"]] = labels["name"]
+			}
+		case name == "ceph_osd_metadata":
+			for _, m := range mf.GetMetric() {
+				labels := labelMap(m)
+				osdTags[labels["ceph_daemon"]] = map[string]string{
+					"hostname":     labels["hostname"],
+					"device_class": labels["device_class"],
+				}
+			}
+		case strings.HasPrefix(name, "ceph_pg_") && name != "ceph_pg_total":
+			// The module reports the number of placement groups per pool
+			// for each individual state
+			state := strings.TrimPrefix(name, "ceph_pg_")
+			for _, m := range mf.GetMetric() {
+				pgStates[state] += sampleValue(mf.GetType(), m)
+			}
+		case strings.HasPrefix(name, "ceph_pool_"):
+			field := strings.TrimPrefix(name, "ceph_pool_")
+			for _, m := range mf.GetMetric() {
+				if id, found := labelMap(m)["pool_id"]; found {
+					pools.add(id, field, sampleValue(mf.GetType(), m))
+				}
+			}
+		case strings.HasPrefix(name, "ceph_osd_"):
+			field := strings.TrimPrefix(name, "ceph_osd_")
+			for _, m := range mf.GetMetric() {
+				// Skip cluster-wide values such as the OSD flags
+				if daemon, found := labelMap(m)["ceph_daemon"]; found {
+					osds.add(daemon, field, sampleValue(mf.GetType(), m))
+				}
+			}
+		}
+	}
+
+	if slices.Contains(c.Collect, "pg") {
+		for state, count := range pgStates {
+			tags := map[string]string{"state": state}
+			acc.AddFields("ceph_mgr_pg", map[string]interface{}{"count": int64(count)}, tags)
+		}
+	}
+	if slices.Contains(c.Collect, "pool") {
+		for id, fields := range pools {
+			tags := map[string]string{"pool_id": id}
+			if name, found := poolNames[id]; found {
+				tags["pool_name"] = name
+			}
+			acc.AddFields("ceph_mgr_pool", fields, tags)
+		}
+	}
+	if slices.Contains(c.Collect, "osd") {
+		for daemon, fields := range osds {
+			tags := map[string]string{"osd_id": strings.TrimPrefix(daemon, "osd.")}
+			for k, v := range osdTags[daemon] {
+				if v != "" {
+					tags[k] = v
+				}
+			}
+			acc.AddFields("ceph_mgr_osd", fields, tags)
+		}
+	}
+
+	return nil
+}
+
+func (c *CephMgr) scrape() ([]*dto.MetricFamily, error) {
+	req, err := http.NewRequest("GET", c.URL+"/metrics", nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request failed: %w", err)
+	}
+	req.Header.Set("Accept", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received status %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	var families []*dto.MetricFamily
+	decoder := expfmt.NewDecoder(resp.Body, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for {
+		var mf dto.MetricFamily
+		if err := decoder.Decode(&mf); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("decoding response failed: %w", err)
+		}
+		families = append(families, &mf)
+	}
+	return families, nil
+}
+
+func labelMap(m *dto.Metric) map[string]string {
+	labels := make(map[string]string, len(m.GetLabel()))
+	for _, l := range m.GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	return labels
+}
+
+func sampleValue(t dto.MetricType, m *dto.Metric) float64 {
+	switch t {
+	case dto.MetricType_COUNTER:
+		return m.GetCounter().GetValue()
+	case dto.MetricType_GAUGE:
+		return m.GetGauge().GetValue()
+	default:
+		return m.GetUntyped().GetValue()
+	}
+}
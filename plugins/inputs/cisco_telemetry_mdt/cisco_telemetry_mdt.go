@@ -3,6 +3,7 @@ package cisco_telemetry_mdt
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
 	"encoding/binary"
 	"encoding/json"
@@ -56,6 +57,8 @@ type CiscoTelemetryMDT struct {
 	EnforcementPolicy  grpcEnforcementPolicy `toml:"grpc_enforcement_policy"`
 	IncludeDeleteField bool                  `toml:"include_delete_field"`
 	SourceFieldName    string                `toml:"source_field_name"`
+	GPBProtoDir        string                `toml:"gpb_proto_dir"`
+	Devices            []*device             `toml:"device"`
 
 	Log telegraf.Logger
 
@@ -65,6 +68,7 @@ type CiscoTelemetryMDT struct {
 	// Internal listener / client handle
 	grpcServer *grpc.Server
 	listener   net.Listener
+	cancel     context.CancelFunc
 
 	// Internal state
 	internalAliases map[string]string
@@ -73,6 +77,8 @@ type CiscoTelemetryMDT struct {
 	extraTags       map[string]map[string]struct{}
 	nxpathMap       map[string]map[string]string // per path map
 	propMap         map[string]func(field *telemetry.TelemetryField, value interface{}) interface{}
+	compact         *compactDecoder
+	compactWarning  sync.Once
 	mutex           sync.Mutex
 	acc             telegraf.Accumulator
 	wg              sync.WaitGroup
@@ -98,14 +104,30 @@ func (*CiscoTelemetryMDT) SampleConfig() string {
 	return sampleConfig
 }
 
+func (c *CiscoTelemetryMDT) Init() error {
+	for i, d := range c.Devices {
+		if err := d.init(); err != nil {
+			return fmt.Errorf("device %d: %w", i+1, err)
+		}
+	}
+	if c.Transport == "none" && len(c.Devices) == 0 {
+		return errors.New("no devices specified for dial-in only mode")
+	}
+
+	if c.GPBProtoDir != "" {
+		decoder, err := newCompactDecoder(c.GPBProtoDir, c.Log)
+		if err != nil {
+			return fmt.Errorf("invalid 'gpb_proto_dir': %w", err)
+		}
+		c.compact = decoder
+	}
+
+	return nil
+}
+
 // Start the Cisco MDT service
 func (c *CiscoTelemetryMDT) Start(acc telegraf.Accumulator) error {
-	var err error
 	c.acc = acc
-	c.listener, err = net.Listen("tcp", c.ServiceAddress)
-	if err != nil {
-		return err
-	}
 
 	c.propMap = make(map[string]func(field *telemetry.TelemetryField, value interface{}) interface{}, 100)
 	c.propMap["test"] = nxosValueXformUint64Toint64
@@ -167,6 +189,41 @@ func (c *CiscoTelemetryMDT) Start(acc telegraf.Accumulator) error {
 		c.extraTags[dir][path.Base(tag)] = struct{}{}
 	}
 
+	// Dial-out listener is disabled if only using dial-in subscriptions
+	if c.Transport != "none" {
+		if err := c.startListener(); err != nil {
+			return err
+		}
+	}
+
+	// Connect to the devices for dial-in subscriptions
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	for _, d := range c.Devices {
+		if err := d.connect(c.MaxMsgSize); err != nil {
+			c.Stop()
+			return err
+		}
+		for _, subscription := range d.Subscriptions {
+			c.wg.Add(1)
+			go func(d *device, subscription string) {
+				defer c.wg.Done()
+				c.runSubscription(ctx, d, subscription)
+			}(d, subscription)
+		}
+	}
+
+	return nil
+}
+
+// startListener starts the server receiving telemetry in dial-out mode
+func (c *CiscoTelemetryMDT) startListener() error {
+	var err error
+	c.listener, err = net.Listen("tcp", c.ServiceAddress)
+	if err != nil {
+		return err
+	}
+
 	switch c.Transport {
 	case "tcp":
 		// TCP dialout server accept routine
@@ -224,6 +281,15 @@ func (*CiscoTelemetryMDT) Gather(telegraf.Accumulator) error {
 
 // Stop listener and cleanup
 func (c *CiscoTelemetryMDT) Stop() {
+	if c.cancel != nil {
+		// Terminate all running dial-in subscriptions
+		c.cancel()
+	}
+	for _, d := range c.Devices {
+		if d.conn != nil {
+			d.conn.Close()
+		}
+	}
 	if c.grpcServer != nil {
 		// Stop server and terminate all running dialout routines
 		c.grpcServer.Stop()
@@ -278,6 +344,23 @@ func (c *CiscoTelemetryMDT) MdtDialout(stream mdtdialout.GRPCMdtDialout_MdtDialo
 	return nil
 }
 
+// runSubscription keeps the dial-in subscription active until the context
+// is cancelled
+func (c *CiscoTelemetryMDT) runSubscription(ctx context.Context, d *device, subscription string) {
+	for {
+		c.Log.Debugf("Subscribing to %q on Cisco MDT GRPC dial-in device %s", subscription, d.Address)
+		if err := d.subscribe(ctx, subscription, c.handleTelemetry); err != nil {
+			c.acc.AddError(fmt.Errorf("dial-in subscription %q on %s failed: %w", subscription, d.Address, err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(d.Redial)):
+		}
+	}
+}
+
 // acceptTCPClients defines the TCP dialout server main routine
 func (c *CiscoTelemetryMDT) acceptTCPClients() {
 	// Keep track of all active connections, so we can close them if necessary
@@ -380,6 +463,23 @@ func (c *CiscoTelemetryMDT) handleTelemetry(data []byte) {
 		return
 	}
 
+	// Convert compact GPB rows to key-value rows using the model-specific
+	// message definitions
+	if len(msg.GetDataGpb().GetRow()) > 0 {
+		if c.compact == nil {
+			c.compactWarning.Do(func() {
+				c.Log.Warn("Ignoring compact GPB data as 'gpb_proto_dir' is not set")
+			})
+			return
+		}
+		rows, err := c.compact.decode(msg)
+		if err != nil {
+			c.acc.AddError(fmt.Errorf("decoding compact GPB data failed: %w", err))
+			return
+		}
+		msg.DataGpbkv = append(msg.DataGpbkv, rows...)
+	}
+
 	grouper := metric.NewSeriesGrouper()
 	for _, gpbkv := range msg.DataGpbkv {
 		// Produce metadata tags
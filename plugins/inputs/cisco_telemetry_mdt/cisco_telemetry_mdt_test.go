@@ -1,6 +1,7 @@
 package cisco_telemetry_mdt
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"io"
@@ -15,9 +16,12 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -1379,3 +1383,225 @@ func TestSourceFieldRewrite(t *testing.T) {
 	fields := map[string]interface{}{"bool": false}
 	acc.AssertContainsTaggedFields(t, "alias", fields, tags)
 }
+
+func TestCompactGPB(t *testing.T) {
+	c := &CiscoTelemetryMDT{
+		Log:         testutil.Logger{},
+		Transport:   "dummy",
+		GPBProtoDir: "testdata/gpb_protos",
+		Aliases: map[string]string{
+			"counters": "Cisco-IOS-XR-test-oper:test/counters",
+		},
+	}
+	require.NoError(t, c.Init())
+	acc := &testutil.Accumulator{}
+	// error is expected since we are passing in dummy transport
+	require.Error(t, c.Start(acc))
+
+	var keys, content []byte
+	keys = protowire.AppendTag(keys, 1, protowire.BytesType)
+	keys = protowire.AppendString(keys, "GigabitEthernet0/0/0/0")
+	content = protowire.AppendTag(content, 1, protowire.VarintType)
+	content = protowire.AppendVarint(content, 4711)
+	content = protowire.AppendTag(content, 2, protowire.VarintType)
+	content = protowire.AppendVarint(content, 3)
+	content = protowire.AppendTag(content, 3, protowire.BytesType)
+	content = protowire.AppendString(content, "up")
+
+	tel := &telemetry.Telemetry{
+		MsgTimestamp: 1543236572000,
+		EncodingPath: "Cisco-IOS-XR-test-oper:test/counters",
+		NodeId:       &telemetry.Telemetry_NodeIdStr{NodeIdStr: "hostname"},
+		Subscription: &telemetry.Telemetry_SubscriptionIdStr{SubscriptionIdStr: "subscription"},
+		DataGpb: &telemetry.TelemetryGPBTable{
+			Row: []*telemetry.TelemetryRowGPB{
+				{
+					Timestamp: 1543236572000,
+					Keys:      keys,
+					Content:   content,
+				},
+			},
+		},
+	}
+	data, err := proto.Marshal(tel)
+	require.NoError(t, err)
+
+	c.handleTelemetry(data)
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"counters",
+			map[string]string{
+				"path":           "Cisco-IOS-XR-test-oper:test/counters",
+				"interface_name": "GigabitEthernet0/0/0/0",
+				"source":         "hostname",
+				"subscription":   "subscription",
+			},
+			map[string]interface{}{
+				"packets_received": uint64(4711),
+				"crc_errors":       uint32(3),
+				"state":            "up",
+			},
+			time.Unix(1543236572, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestCompactGPBMissingDefinitions(t *testing.T) {
+	c := &CiscoTelemetryMDT{
+		Log:         testutil.Logger{},
+		Transport:   "dummy",
+		GPBProtoDir: "testdata/gpb_protos",
+	}
+	require.NoError(t, c.Init())
+	acc := &testutil.Accumulator{}
+	require.Error(t, c.Start(acc))
+
+	tel := &telemetry.Telemetry{
+		EncodingPath: "Cisco-IOS-XR-unknown-oper:unknown",
+		DataGpb: &telemetry.TelemetryGPBTable{
+			Row: []*telemetry.TelemetryRowGPB{{Content: []byte{0x08, 0x01}}},
+		},
+	}
+	data, err := proto.Marshal(tel)
+	require.NoError(t, err)
+
+	c.handleTelemetry(data)
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `no message definitions for encoding path "Cisco-IOS-XR-unknown-oper:unknown"`)
+}
+
+func TestGRPCDialin(t *testing.T) {
+	tel := &telemetry.Telemetry{
+		MsgTimestamp: 1543236572000,
+		EncodingPath: "type:model/some/path",
+		NodeId:       &telemetry.Telemetry_NodeIdStr{NodeIdStr: "hostname"},
+		Subscription: &telemetry.Telemetry_SubscriptionIdStr{SubscriptionIdStr: "subscription"},
+		DataGpbkv: []*telemetry.TelemetryField{
+			{
+				Fields: []*telemetry.TelemetryField{
+					{
+						Name: "keys",
+						Fields: []*telemetry.TelemetryField{
+							{
+								Name:        "name",
+								ValueByType: &telemetry.TelemetryField_StringValue{StringValue: "str"},
+							},
+						},
+					},
+					{
+						Name: "content",
+						Fields: []*telemetry.TelemetryField{
+							{
+								Name:        "value",
+								ValueByType: &telemetry.TelemetryField_Sint64Value{Sint64Value: -1},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	data, err := proto.Marshal(tel)
+	require.NoError(t, err)
+
+	// Mock an IOS XR device serving the subscription
+	handler := func(_ interface{}, stream grpc.ServerStream) error {
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		if len(md.Get("username")) != 1 || md.Get("username")[0] != "user" || md.Get("password")[0] != "secret" {
+			return errors.New("authentication failed")
+		}
+
+		var request []byte
+		if err := stream.RecvMsg(&request); err != nil {
+			return err
+		}
+		if !bytes.Contains(request, []byte("subscription")) {
+			return errors.New("unknown subscription")
+		}
+
+		var reply []byte
+		reply = protowire.AppendTag(reply, 1, protowire.VarintType)
+		reply = protowire.AppendVarint(reply, 1)
+		reply = protowire.AppendTag(reply, 2, protowire.BytesType)
+		reply = protowire.AppendBytes(reply, data)
+		if err := stream.SendMsg(reply); err != nil {
+			return err
+		}
+		<-stream.Context().Done()
+		return nil
+	}
+	server := grpc.NewServer(grpc.ForceServerCodec(rawCodec{}))
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "IOSXRExtensibleManagabilityService.gRPCConfigOper",
+		HandlerType: (*interface{})(nil),
+		Streams: []grpc.StreamDesc{
+			{StreamName: "CreateSubs", Handler: handler, ServerStreams: true},
+		},
+	}, struct{}{})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(listener) //nolint:errcheck // Ignore the returned error as we cannot do anything about it anyway
+	defer server.Stop()
+
+	c := &CiscoTelemetryMDT{
+		Log:       testutil.Logger{},
+		Transport: "none",
+		Aliases:   map[string]string{"alias": "type:model/some/path"},
+		Devices: []*device{
+			{
+				Address:       listener.Addr().String(),
+				Username:      config.NewSecret([]byte("user")),
+				Password:      config.NewSecret([]byte("secret")),
+				Subscriptions: []string{"subscription"},
+			},
+		},
+	}
+	require.NoError(t, c.Init())
+	acc := &testutil.Accumulator{}
+	require.NoError(t, c.Start(acc))
+	defer c.Stop()
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() > 0
+	}, 5*time.Second, 100*time.Millisecond)
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"alias",
+			map[string]string{
+				"path":         "type:model/some/path",
+				"name":         "str",
+				"source":       "hostname",
+				"subscription": "subscription",
+			},
+			map[string]interface{}{"value": int64(-1)},
+			time.Unix(1543236572, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestInvalidDevice(t *testing.T) {
+	c := &CiscoTelemetryMDT{
+		Log:       testutil.Logger{},
+		Transport: "none",
+		Devices: []*device{
+			{
+				Address:       "127.0.0.1:57400",
+				Subscriptions: []string{"subscription"},
+				Encoding:      "json",
+			},
+		},
+	}
+	require.ErrorContains(t, c.Init(), `invalid encoding "json" for device "127.0.0.1:57400"`)
+
+	c = &CiscoTelemetryMDT{
+		Log:       testutil.Logger{},
+		Transport: "none",
+	}
+	require.ErrorContains(t, c.Init(), "no devices specified for dial-in only mode")
+}
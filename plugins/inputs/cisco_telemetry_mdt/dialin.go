@@ -0,0 +1,229 @@
+package cisco_telemetry_mdt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/influxdata/telegraf/config"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+)
+
+// Method of the IOS XR gRPC service for creating dial-in subscriptions,
+// see https://github.com/cisco/ios-xr-grpc/blob/master/ems_grpc.proto
+const createSubsMethod = "/IOSXRExtensibleManagabilityService.gRPCConfigOper/CreateSubs"
+
+// Encodings of the telemetry data requested in dial-in subscriptions
+var dialinEncodings = map[string]int64{
+	"gpb":   2,
+	"gpbkv": 3,
+}
+
+// device is a router the plugin connects to for subscribing to telemetry
+// data (dial-in mode)
+type device struct {
+	Address       string          `toml:"address"`
+	Username      config.Secret   `toml:"username"`
+	Password      config.Secret   `toml:"password"`
+	Subscriptions []string        `toml:"subscriptions"`
+	Encoding      string          `toml:"encoding"`
+	Redial        config.Duration `toml:"redial"`
+	common_tls.ClientConfig
+
+	conn *grpc.ClientConn
+}
+
+// request IDs must be unique per connection
+var dialinRequestID atomic.Int64
+
+func (d *device) init() error {
+	if d.Address == "" {
+		return errors.New("'address' required")
+	}
+	if len(d.Subscriptions) == 0 {
+		return fmt.Errorf("no subscriptions for device %q", d.Address)
+	}
+	if d.Encoding == "" {
+		d.Encoding = "gpbkv"
+	}
+	if _, found := dialinEncodings[d.Encoding]; !found {
+		return fmt.Errorf("invalid encoding %q for device %q", d.Encoding, d.Address)
+	}
+	if d.Redial == 0 {
+		d.Redial = config.Duration(10 * time.Second)
+	}
+	return nil
+}
+
+func (d *device) connect(maxMsgSize int) error {
+	creds := insecure.NewCredentials()
+	tlsConfig, err := d.ClientConfig.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("creating TLS config for device %q failed: %w", d.Address, err)
+	}
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if maxMsgSize > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMsgSize)))
+	}
+	d.conn, err = grpc.NewClient(d.Address, opts...)
+	if err != nil {
+		return fmt.Errorf("creating client for device %q failed: %w", d.Address, err)
+	}
+	return nil
+}
+
+// subscribe requests the given subscription configured on the device and
+// hands the received telemetry messages to the given function until the
+// stream ends or the context is cancelled
+func (d *device) subscribe(ctx context.Context, subscription string, handle func([]byte)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if !d.Username.Empty() || !d.Password.Empty() {
+		username, err := d.Username.Get()
+		if err != nil {
+			return fmt.Errorf("getting username failed: %w", err)
+		}
+		password, err := d.Password.Get()
+		if err != nil {
+			username.Destroy()
+			return fmt.Errorf("getting password failed: %w", err)
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, "username", username.String(), "password", password.String())
+		username.Destroy()
+		password.Destroy()
+	}
+
+	desc := &grpc.StreamDesc{StreamName: "CreateSubs", ServerStreams: true}
+	stream, err := d.conn.NewStream(ctx, desc, createSubsMethod, grpc.ForceCodec(rawCodec{}))
+	if err != nil {
+		return fmt.Errorf("creating stream failed: %w", err)
+	}
+
+	request := encodeCreateSubsArgs(dialinRequestID.Add(1), dialinEncodings[d.Encoding], subscription)
+	if err := stream.SendMsg(request); err != nil {
+		return fmt.Errorf("sending subscription request failed: %w", err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		return fmt.Errorf("closing send direction failed: %w", err)
+	}
+
+	for {
+		var buf []byte
+		if err := stream.RecvMsg(&buf); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		data, errmsg, err := decodeCreateSubsReply(buf)
+		if err != nil {
+			return fmt.Errorf("decoding reply failed: %w", err)
+		}
+		if errmsg != "" {
+			return fmt.Errorf("device reported error: %s", errmsg)
+		}
+		if len(data) > 0 {
+			handle(data)
+		}
+	}
+}
+
+// encodeCreateSubsArgs serializes the CreateSubsArgs message
+//
+//	message CreateSubsArgs {
+//	  int64 ReqId = 1;
+//	  int64 encode = 2;
+//	  string subidstr = 3;
+//	  ...
+//	}
+func encodeCreateSubsArgs(id, encoding int64, subscription string) []byte {
+	var buf []byte
+	buf = protowire.AppendTag(buf, 1, protowire.VarintType)
+	buf = protowire.AppendVarint(buf, uint64(id))
+	buf = protowire.AppendTag(buf, 2, protowire.VarintType)
+	buf = protowire.AppendVarint(buf, uint64(encoding))
+	buf = protowire.AppendTag(buf, 3, protowire.BytesType)
+	buf = protowire.AppendString(buf, subscription)
+	return buf
+}
+
+// decodeCreateSubsReply extracts the data and errors of the CreateSubsReply
+// message
+//
+//	message CreateSubsReply {
+//	  int64 ResReqId = 1;
+//	  bytes data = 2;
+//	  string errors = 3;
+//	}
+func decodeCreateSubsReply(buf []byte) (data []byte, errmsg string, err error) {
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if n < 0 {
+			return nil, "", protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		switch {
+		case num == 2 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(buf)
+			if n < 0 {
+				return nil, "", protowire.ParseError(n)
+			}
+			data = v
+			buf = buf[n:]
+		case num == 3 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(buf)
+			if n < 0 {
+				return nil, "", protowire.ParseError(n)
+			}
+			errmsg = v
+			buf = buf[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, buf)
+			if n < 0 {
+				return nil, "", protowire.ParseError(n)
+			}
+			buf = buf[n:]
+		}
+	}
+	return data, errmsg, nil
+}
+
+// rawCodec passes the already serialized messages to gRPC as we do not have
+// generated code for the IOS XR service
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	buf, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return buf, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	buf, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	*buf = append((*buf)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,200 @@
+package cisco_telemetry_mdt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bufbuild/protocompile"
+	telemetry "github.com/cisco-ie/nx-telemetry-proto/telemetry_bis"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/influxdata/telegraf"
+)
+
+// compactDecoder decodes compact GPB rows using the model-specific message
+// definitions found in a proto bundle, e.g. the "proto_archive" directory of
+// https://github.com/cisco/bigmuddy-network-telemetry-proto. In those
+// bundles, the definitions for an encoding path are located in the directory
+// derived from the path, e.g. the messages of
+// "Cisco-IOS-XR-infra-statsd-oper:infra-statistics/interfaces/interface/latest/generic-counters"
+// are located in "cisco_ios_xr_infra_statsd_oper/infra_statistics/interfaces/interface/latest/generic_counters".
+// Each directory contains a message for the keys, suffixed with "_KEYS", and a
+// message for the content of the rows.
+type compactDecoder struct {
+	dir string
+	log telegraf.Logger
+
+	// Message definitions by encoding path, nil if not available
+	messages map[string]*compactMessages
+	sync.Mutex
+}
+
+type compactMessages struct {
+	keys    protoreflect.MessageDescriptor
+	content protoreflect.MessageDescriptor
+}
+
+func newCompactDecoder(dir string, log telegraf.Logger) (*compactDecoder, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%q is not a directory", dir)
+	}
+	return &compactDecoder{
+		dir:      dir,
+		log:      log,
+		messages: make(map[string]*compactMessages),
+	}, nil
+}
+
+// decode converts the compact GPB rows of the message to self-describing
+// key-value rows
+func (d *compactDecoder) decode(msg *telemetry.Telemetry) ([]*telemetry.TelemetryField, error) {
+	messages := d.lookup(msg.GetEncodingPath())
+	if messages == nil {
+		return nil, fmt.Errorf("no message definitions for encoding path %q", msg.GetEncodingPath())
+	}
+
+	rows := make([]*telemetry.TelemetryField, 0, len(msg.DataGpb.Row))
+	for _, row := range msg.DataGpb.Row {
+		keys := dynamicpb.NewMessage(messages.keys)
+		if err := proto.Unmarshal(row.Keys, keys); err != nil {
+			return nil, fmt.Errorf("decoding keys failed: %w", err)
+		}
+		content := dynamicpb.NewMessage(messages.content)
+		if err := proto.Unmarshal(row.Content, content); err != nil {
+			return nil, fmt.Errorf("decoding content failed: %w", err)
+		}
+		rows = append(rows, &telemetry.TelemetryField{
+			Timestamp: row.Timestamp,
+			Fields: []*telemetry.TelemetryField{
+				{Name: "keys", Fields: messageFields(keys)},
+				{Name: "content", Fields: messageFields(content)},
+			},
+		})
+	}
+	return rows, nil
+}
+
+func (d *compactDecoder) lookup(encodingPath string) *compactMessages {
+	d.Lock()
+	defer d.Unlock()
+
+	if messages, found := d.messages[encodingPath]; found {
+		return messages
+	}
+
+	// Only try to load the definitions once per encoding path
+	messages, err := d.load(encodingPath)
+	if err != nil {
+		d.log.Errorf("Loading message definitions for %q failed: %v", encodingPath, err)
+	}
+	d.messages[encodingPath] = messages
+	return messages
+}
+
+func (d *compactDecoder) load(encodingPath string) (*compactMessages, error) {
+	relative := strings.ToLower(encodingPath)
+	relative = strings.NewReplacer("-", "_", ":", "/").Replace(relative)
+	files, err := filepath.Glob(filepath.Join(d.dir, filepath.FromSlash(relative), "*.proto"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no proto files found in %q", relative)
+	}
+	for i, f := range files {
+		files[i], err = filepath.Rel(d.dir, f)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resolver := &protocompile.SourceResolver{ImportPaths: []string{d.dir}}
+	compiler := &protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(resolver),
+	}
+	compiled, err := compiler.Compile(context.Background(), files...)
+	if err != nil {
+		return nil, fmt.Errorf("compiling proto files failed: %w", err)
+	}
+
+	messages := &compactMessages{}
+	for _, f := range compiled {
+		descriptors := f.Messages()
+		for i := 0; i < descriptors.Len(); i++ {
+			desc := descriptors.Get(i)
+			if strings.HasSuffix(string(desc.Name()), "_KEYS") {
+				messages.keys = desc
+			} else {
+				messages.content = desc
+			}
+		}
+	}
+	if messages.keys == nil || messages.content == nil {
+		return nil, errors.New("missing keys or content message")
+	}
+	return messages, nil
+}
+
+// messageFields converts the populated fields of the message to
+// self-describing fields
+func messageFields(msg protoreflect.Message) []*telemetry.TelemetryField {
+	var fields []*telemetry.TelemetryField
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+		if fd.IsList() {
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				fields = append(fields, convertValue(name, fd, list.Get(i)))
+			}
+			return true
+		}
+		fields = append(fields, convertValue(name, fd, v))
+		return true
+	})
+	return fields
+}
+
+func convertValue(name string, fd protoreflect.FieldDescriptor, v protoreflect.Value) *telemetry.TelemetryField {
+	field := &telemetry.TelemetryField{Name: name}
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		field.Fields = messageFields(v.Message())
+	case protoreflect.BoolKind:
+		field.ValueByType = &telemetry.TelemetryField_BoolValue{BoolValue: v.Bool()}
+	case protoreflect.StringKind:
+		field.ValueByType = &telemetry.TelemetryField_StringValue{StringValue: v.String()}
+	case protoreflect.BytesKind:
+		field.ValueByType = &telemetry.TelemetryField_BytesValue{BytesValue: v.Bytes()}
+	case protoreflect.EnumKind:
+		value := fd.Enum().Values().ByNumber(v.Enum())
+		if value == nil {
+			field.ValueByType = &telemetry.TelemetryField_Sint32Value{Sint32Value: int32(v.Enum())}
+		} else {
+			field.ValueByType = &telemetry.TelemetryField_StringValue{StringValue: string(value.Name())}
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		field.ValueByType = &telemetry.TelemetryField_Sint32Value{Sint32Value: int32(v.Int())}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		field.ValueByType = &telemetry.TelemetryField_Sint64Value{Sint64Value: v.Int()}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		field.ValueByType = &telemetry.TelemetryField_Uint32Value{Uint32Value: uint32(v.Uint())}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		field.ValueByType = &telemetry.TelemetryField_Uint64Value{Uint64Value: v.Uint()}
+	case protoreflect.FloatKind:
+		field.ValueByType = &telemetry.TelemetryField_FloatValue{FloatValue: float32(v.Float())}
+	case protoreflect.DoubleKind:
+		field.ValueByType = &telemetry.TelemetryField_DoubleValue{DoubleValue: v.Float()}
+	}
+	return field
+}
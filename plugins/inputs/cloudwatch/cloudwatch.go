@@ -48,8 +48,10 @@ type CloudWatch struct {
 	BatchSize             int                 `toml:"batch_size"`
 	IncludeLinkedAccounts bool                `toml:"include_linked_accounts"`
 	MetricFormat          string              `toml:"metric_format"`
+	Mode                  string              `toml:"mode"`
 	Log                   telegraf.Logger     `toml:"-"`
 	common_aws.CredentialConfig
+	streamConfig
 
 	client          cloudwatchClient
 	nsFilter        filter.Filter
@@ -58,6 +60,8 @@ type CloudWatch struct {
 	queryDimensions map[string]*map[string]string
 	windowStart     time.Time
 	windowEnd       time.Time
+	metricFilters   []filter.Filter
+	stream          *streamListener
 }
 
 type cloudwatchMetric struct {
@@ -105,6 +109,18 @@ func (c *CloudWatch) Init() error {
 		return fmt.Errorf("invalid metric_format: %s", c.MetricFormat)
 	}
 
+	switch c.Mode {
+	case "":
+		c.Mode = "poll"
+	case "poll":
+	case "stream":
+		if err := c.streamConfig.init(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid mode: %s", c.Mode)
+	}
+
 	// Setup the cloudwatch client
 	proxyFunc, err := c.HTTPProxy.Proxy()
 	if err != nil {
@@ -167,10 +183,47 @@ func (c *CloudWatch) Init() error {
 		return fmt.Errorf("creating namespace filter failed: %w", err)
 	}
 
+	// Initialize the statistics-type filters of the metric definitions
+	c.metricFilters = make([]filter.Filter, 0, len(c.Metrics))
+	for idx, cm := range c.Metrics {
+		f, err := c.metricStatisticFilter(cm)
+		if err != nil {
+			return fmt.Errorf("creating statistics filter for metric %d failed: %w", idx+1, err)
+		}
+		c.metricFilters = append(c.metricFilters, f)
+	}
+
+	return nil
+}
+
+// Start starts the listener for metric streams, this is a no-op in polling mode
+func (c *CloudWatch) Start(acc telegraf.Accumulator) error {
+	if c.Mode != "stream" {
+		return nil
+	}
+
+	listener, err := c.streamConfig.listen(c.handleRecords(acc), c.Log)
+	if err != nil {
+		return err
+	}
+	c.stream = listener
+
 	return nil
 }
 
+func (c *CloudWatch) Stop() {
+	if c.stream != nil {
+		c.stream.stop()
+		c.stream = nil
+	}
+}
+
 func (c *CloudWatch) Gather(acc telegraf.Accumulator) error {
+	// Metrics are pushed by the metric stream
+	if c.Mode == "stream" {
+		return nil
+	}
+
 	filteredMetrics, err := c.getFilteredMetrics()
 	if err != nil {
 		return err
@@ -294,24 +347,7 @@ func (c *CloudWatch) getFilteredMetrics() ([]filteredMetric, error) {
 		})
 	} else {
 		for idx, cm := range c.Metrics {
-			var entry filteredMetric
-			if cm.StatisticInclude == nil && cm.StatisticExclude == nil {
-				entry.statFilter = c.statFilter
-			} else {
-				var includeStats, excludeStats []string
-				if cm.StatisticInclude != nil {
-					includeStats = *cm.StatisticInclude
-				}
-				if cm.StatisticExclude != nil {
-					excludeStats = *cm.StatisticExclude
-				}
-				f, err := filter.NewIncludeExcludeFilter(includeStats, excludeStats)
-				if err != nil {
-					return nil, fmt.Errorf("creating statistics filter for metric %d failed: %w", idx+1, err)
-				}
-				entry.statFilter = f
-			}
-
+			entry := filteredMetric{statFilter: c.metricFilters[idx]}
 			for i, m := range metrics {
 				if metricMatch(cm, m) {
 					entry.metrics = append(entry.metrics, m)
@@ -333,6 +369,23 @@ func (c *CloudWatch) getFilteredMetrics() ([]filteredMetric, error) {
 	return filtered, nil
 }
 
+// metricStatisticFilter returns the statistics-type filter of the metric
+// definition falling back to the namespace-wide filter
+func (c *CloudWatch) metricStatisticFilter(cm *cloudwatchMetric) (filter.Filter, error) {
+	if cm.StatisticInclude == nil && cm.StatisticExclude == nil {
+		return c.statFilter, nil
+	}
+
+	var includeStats, excludeStats []string
+	if cm.StatisticInclude != nil {
+		includeStats = *cm.StatisticInclude
+	}
+	if cm.StatisticExclude != nil {
+		excludeStats = *cm.StatisticExclude
+	}
+	return filter.NewIncludeExcludeFilter(includeStats, excludeStats)
+}
+
 func (c *CloudWatch) updateWindow(relativeTo time.Time) {
 	windowEnd := relativeTo.Add(-time.Duration(c.Delay))
 
@@ -0,0 +1,385 @@
+package cloudwatch
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+)
+
+// defaultMaxBodySize is the maximum size of a Firehose request
+const defaultMaxBodySize = 64 * 1024 * 1024
+
+// streamConfig contains the settings for receiving CloudWatch Metric Streams
+// via a Kinesis Data Firehose HTTP endpoint destination, see
+// https://docs.aws.amazon.com/firehose/latest/dev/httpdeliveryrequestresponse.html
+type streamConfig struct {
+	ServiceAddress    string          `toml:"service_address"`
+	Paths             []string        `toml:"paths"`
+	FirehoseAccessKey config.Secret   `toml:"firehose_access_key"`
+	MaxBodySize       config.Size     `toml:"max_body_size"`
+	ReadTimeout       config.Duration `toml:"read_timeout"`
+	WriteTimeout      config.Duration `toml:"write_timeout"`
+	StreamFormat      string          `toml:"stream_format"`
+	common_tls.ServerConfig
+}
+
+type streamListener struct {
+	server   *http.Server
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+// firehoseRequest is the body of a request sent by Kinesis Data Firehose
+type firehoseRequest struct {
+	RequestID string `json:"requestId"`
+	Timestamp int64  `json:"timestamp"`
+	Records   []struct {
+		Data string `json:"data"`
+	} `json:"records"`
+}
+
+type firehoseResponse struct {
+	RequestID    string `json:"requestId"`
+	Timestamp    int64  `json:"timestamp"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// streamDatapoint is a single datapoint of a metric stream independent of
+// the output format of the stream
+type streamDatapoint struct {
+	accountID  string
+	region     string
+	namespace  string
+	name       string
+	dimensions map[string]string
+	timestamp  time.Time
+	statistics map[string]float64
+}
+
+// recordHandler processes the decoded records of a single request
+type recordHandler func(records [][]byte) error
+
+func (s *streamConfig) init() error {
+	if s.ServiceAddress == "" {
+		s.ServiceAddress = ":443"
+	}
+	if len(s.Paths) == 0 {
+		s.Paths = []string{"/telegraf"}
+	}
+	if s.MaxBodySize == 0 {
+		s.MaxBodySize = config.Size(defaultMaxBodySize)
+	}
+	if s.ReadTimeout < config.Duration(time.Second) {
+		s.ReadTimeout = config.Duration(10 * time.Second)
+	}
+	if s.WriteTimeout < config.Duration(time.Second) {
+		s.WriteTimeout = config.Duration(10 * time.Second)
+	}
+
+	switch s.StreamFormat {
+	case "":
+		s.StreamFormat = "json"
+	case "json", "opentelemetry0.7":
+	default:
+		return fmt.Errorf("invalid stream_format: %s", s.StreamFormat)
+	}
+
+	return nil
+}
+
+func (s *streamConfig) listen(handler recordHandler, log telegraf.Logger) (*streamListener, error) {
+	tlsCfg, err := s.ServerConfig.TLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("creating TLS config failed: %w", err)
+	}
+
+	var listener net.Listener
+	if tlsCfg != nil {
+		listener, err = tls.Listen("tcp", s.ServiceAddress, tlsCfg)
+	} else {
+		listener, err = net.Listen("tcp", s.ServiceAddress)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	l := &streamListener{
+		server: &http.Server{
+			Handler:      s.handler(handler, log),
+			ReadTimeout:  time.Duration(s.ReadTimeout),
+			WriteTimeout: time.Duration(s.WriteTimeout),
+			TLSConfig:    tlsCfg,
+		},
+		listener: listener,
+	}
+
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		if err := l.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("Serving metric streams failed: %v", err)
+		}
+	}()
+	log.Infof("Listening for metric streams on %s", listener.Addr())
+
+	return l, nil
+}
+
+func (l *streamListener) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	//nolint:errcheck // we cannot do anything if the shutdown fails
+	l.server.Shutdown(ctx)
+	l.wg.Wait()
+}
+
+func (s *streamConfig) handler(handler recordHandler, log telegraf.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Amz-Firehose-Request-Id")
+
+		if !slices.Contains(s.Paths, r.URL.Path) {
+			respond(w, http.StatusNotFound, requestID, "not found")
+			return
+		}
+		if r.Method != http.MethodPost {
+			respond(w, http.StatusMethodNotAllowed, requestID, "method not allowed")
+			return
+		}
+		if !s.FirehoseAccessKey.Empty() {
+			key, err := s.FirehoseAccessKey.Get()
+			if err != nil {
+				log.Errorf("Getting access key failed: %v", err)
+				respond(w, http.StatusInternalServerError, requestID, "internal error")
+				return
+			}
+			valid := subtle.ConstantTimeCompare(key.Bytes(), []byte(r.Header.Get("X-Amz-Firehose-Access-Key"))) == 1
+			key.Destroy()
+			if !valid {
+				respond(w, http.StatusUnauthorized, requestID, "invalid access key")
+				return
+			}
+		}
+
+		var body io.Reader = http.MaxBytesReader(w, r.Body, int64(s.MaxBodySize))
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			reader, err := gzip.NewReader(body)
+			if err != nil {
+				respond(w, http.StatusBadRequest, requestID, "invalid gzip encoding")
+				return
+			}
+			defer reader.Close()
+			body = reader
+		}
+
+		var req firehoseRequest
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				respond(w, http.StatusRequestEntityTooLarge, requestID, "request too large")
+				return
+			}
+			log.Errorf("Decoding metric stream request failed: %v", err)
+			respond(w, http.StatusBadRequest, requestID, "invalid request")
+			return
+		}
+		if req.RequestID != "" {
+			requestID = req.RequestID
+		}
+
+		records := make([][]byte, 0, len(req.Records))
+		for _, record := range req.Records {
+			buf, err := base64.StdEncoding.DecodeString(record.Data)
+			if err != nil {
+				respond(w, http.StatusBadRequest, requestID, "invalid record encoding")
+				return
+			}
+			records = append(records, buf)
+		}
+
+		if err := handler(records); err != nil {
+			log.Errorf("Processing metric stream request failed: %v", err)
+			respond(w, http.StatusBadRequest, requestID, err.Error())
+			return
+		}
+		respond(w, http.StatusOK, requestID, "")
+	}
+}
+
+// respond sends the response expected by Kinesis Data Firehose
+func respond(w http.ResponseWriter, status int, requestID, message string) {
+	buf, err := json.Marshal(firehoseResponse{
+		RequestID:    requestID,
+		Timestamp:    time.Now().UnixMilli(),
+		ErrorMessage: message,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	//nolint:errcheck // nothing we can do if the client went away
+	w.Write(buf)
+}
+
+// handleRecords returns the handler converting the records of a request to
+// metrics in the same format as produced by polling
+func (c *CloudWatch) handleRecords(acc telegraf.Accumulator) recordHandler {
+	return func(records [][]byte) error {
+		grouper := metric.NewSeriesGrouper()
+		for _, record := range records {
+			var datapoints []streamDatapoint
+			var err error
+			if c.StreamFormat == "opentelemetry0.7" {
+				datapoints, err = parseOpenTelemetryRecord(record)
+			} else {
+				datapoints, err = parseJSONRecord(record)
+			}
+			if err != nil {
+				return err
+			}
+			for _, dp := range datapoints {
+				c.addDatapoint(grouper, dp)
+			}
+		}
+
+		for _, m := range grouper.Metrics() {
+			acc.AddMetric(m)
+		}
+		return nil
+	}
+}
+
+func (c *CloudWatch) addDatapoint(grouper *metric.SeriesGrouper, dp streamDatapoint) {
+	if c.nsFilter != nil && !c.nsFilter.Match(dp.namespace) {
+		return
+	}
+
+	// Apply the metric definitions in the same way as for polled metrics
+	statFilter := c.statFilter
+	if len(c.Metrics) > 0 {
+		m := types.Metric{
+			MetricName: aws.String(dp.name),
+			Namespace:  aws.String(dp.namespace),
+			Dimensions: make([]types.Dimension, 0, len(dp.dimensions)),
+		}
+		for k, v := range dp.dimensions {
+			m.Dimensions = append(m.Dimensions, types.Dimension{Name: aws.String(k), Value: aws.String(v)})
+		}
+		idx := slices.IndexFunc(c.Metrics, func(cm *cloudwatchMetric) bool {
+			return metricMatch(cm, m)
+		})
+		if idx < 0 {
+			return
+		}
+		statFilter = c.metricFilters[idx]
+	}
+
+	tags := make(map[string]string, len(dp.dimensions)+3)
+	for k, v := range dp.dimensions {
+		tags[snakeCase(k)] = v
+	}
+	tags["region"] = dp.region
+	if c.IncludeLinkedAccounts && dp.accountID != "" {
+		tags["account"] = dp.accountID
+	}
+	if c.MetricFormat == "dense" {
+		tags["metric_name"] = snakeCase(dp.name)
+	}
+
+	measurement := sanitizeMeasurement(dp.namespace)
+	for statistic, value := range dp.statistics {
+		if statFilter != nil && !statFilter.Match(statistic) {
+			continue
+		}
+		if c.MetricFormat == "dense" {
+			grouper.Add(measurement, tags, dp.timestamp, statistic, value)
+		} else {
+			grouper.Add(measurement, tags, dp.timestamp, snakeCase(dp.name+"_"+statistic), value)
+		}
+	}
+}
+
+// statistics converts the summary values of a datapoint to the statistics
+// available when polling and adds the given percentiles
+func statistics(count, sum, minimum, maximum float64, percentiles map[float64]float64) map[string]float64 {
+	stats := map[string]float64{
+		"sample_count": count,
+		"sum":          sum,
+		"minimum":      minimum,
+		"maximum":      maximum,
+	}
+	if count > 0 {
+		stats["average"] = sum / count
+	}
+	for p, v := range percentiles {
+		name := "p" + strconv.FormatFloat(p, 'f', -1, 64)
+		stats[strings.ReplaceAll(name, ".", "_")] = v
+	}
+	return stats
+}
+
+// parseJSONRecord parses a record containing newline-delimited JSON objects
+// as described in
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch-metric-streams-formats-json.html
+func parseJSONRecord(record []byte) ([]streamDatapoint, error) {
+	var datapoints []streamDatapoint
+	for _, line := range strings.Split(string(record), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		var d struct {
+			AccountID  string             `json:"account_id"`
+			Region     string             `json:"region"`
+			Namespace  string             `json:"namespace"`
+			MetricName string             `json:"metric_name"`
+			Dimensions map[string]string  `json:"dimensions"`
+			Timestamp  int64              `json:"timestamp"`
+			Value      map[string]float64 `json:"value"`
+		}
+		if err := json.Unmarshal([]byte(line), &d); err != nil {
+			return nil, fmt.Errorf("decoding JSON record failed: %w", err)
+		}
+
+		// Additional statistics are reported as percentiles, e.g. "p99"
+		percentiles := make(map[float64]float64)
+		for k, v := range d.Value {
+			if p, err := strconv.ParseFloat(strings.TrimPrefix(k, "p"), 64); err == nil && strings.HasPrefix(k, "p") {
+				percentiles[p] = v
+			}
+		}
+
+		datapoints = append(datapoints, streamDatapoint{
+			accountID:  d.AccountID,
+			region:     d.Region,
+			namespace:  d.Namespace,
+			name:       d.MetricName,
+			dimensions: d.Dimensions,
+			timestamp:  time.UnixMilli(d.Timestamp),
+			statistics: statistics(d.Value["count"], d.Value["sum"], d.Value["min"], d.Value["max"], percentiles),
+		})
+	}
+	return datapoints, nil
+}
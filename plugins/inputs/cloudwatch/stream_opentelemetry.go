@@ -0,0 +1,336 @@
+package cloudwatch
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the OpenTelemetry 0.7.0 metrics protocol used by
+// CloudWatch Metric Streams, see
+// https://github.com/open-telemetry/opentelemetry-proto/tree/v0.7.0/opentelemetry/proto/metrics/v1
+// Only the messages and fields required for the summaries sent by CloudWatch
+// are decoded.
+const (
+	fieldRequestResourceMetrics = 1
+
+	fieldResourceMetricsResource       = 1
+	fieldResourceMetricsLibraryMetrics = 2
+	fieldResourceAttributes            = 1
+	fieldLibraryMetricsMetrics         = 2
+	fieldKeyValueKey                   = 1
+	fieldKeyValueValue                 = 2
+	fieldAnyValueString                = 1
+	fieldMetricName                    = 1
+	fieldMetricDoubleSummary           = 11
+	fieldSummaryDataPoints             = 1
+	fieldDataPointLabels               = 1
+	fieldDataPointTime                 = 3
+	fieldDataPointCount                = 4
+	fieldDataPointSum                  = 5
+	fieldDataPointQuantiles            = 6
+	fieldQuantileQuantile              = 1
+	fieldQuantileValue                 = 2
+)
+
+// Attributes and labels set by CloudWatch
+const (
+	metricNamePrefix           = "amazonaws.com/"
+	resourceAttributeAccountID = "cloud.account.id"
+	resourceAttributeRegion    = "cloud.region"
+	labelNamespace             = "Namespace"
+	labelMetricName            = "MetricName"
+)
+
+// The minimum and maximum are sent as the 0.0 and 1.0 quantiles
+const (
+	quantileMinimum = 0.0
+	quantileMaximum = 1.0
+)
+
+// protoField is a decoded field of a protobuf message
+type protoField struct {
+	num   protowire.Number
+	bytes []byte
+	value uint64
+}
+
+// decodeFields splits the protobuf message into its fields
+func decodeFields(buf []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		f := protoField{num: num}
+		switch typ {
+		case protowire.VarintType:
+			f.value, n = protowire.ConsumeVarint(buf)
+		case protowire.Fixed64Type:
+			f.value, n = protowire.ConsumeFixed64(buf)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(buf)
+			f.value = uint64(v)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(buf)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, buf)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		buf = buf[n:]
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// parseOpenTelemetryRecord parses a record containing length-delimited
+// ExportMetricsServiceRequest messages as described in
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch-metric-streams-formats-opentelemetry.html
+func parseOpenTelemetryRecord(record []byte) ([]streamDatapoint, error) {
+	var datapoints []streamDatapoint
+	for len(record) > 0 {
+		msg, n := protowire.ConsumeBytes(record)
+		if n < 0 {
+			return nil, fmt.Errorf("decoding message length failed: %w", protowire.ParseError(n))
+		}
+		record = record[n:]
+
+		fields, err := decodeFields(msg)
+		if err != nil {
+			return nil, fmt.Errorf("decoding request failed: %w", err)
+		}
+		for _, f := range fields {
+			if f.num != fieldRequestResourceMetrics {
+				continue
+			}
+			dps, err := parseResourceMetrics(f.bytes)
+			if err != nil {
+				return nil, err
+			}
+			datapoints = append(datapoints, dps...)
+		}
+	}
+	return datapoints, nil
+}
+
+func parseResourceMetrics(buf []byte) ([]streamDatapoint, error) {
+	fields, err := decodeFields(buf)
+	if err != nil {
+		return nil, fmt.Errorf("decoding resource metrics failed: %w", err)
+	}
+
+	// The resource might be sent after the metrics so decode it first
+	var accountID, region string
+	for _, f := range fields {
+		if f.num != fieldResourceMetricsResource {
+			continue
+		}
+		attributes, err := decodeFields(f.bytes)
+		if err != nil {
+			return nil, fmt.Errorf("decoding resource failed: %w", err)
+		}
+		for _, a := range attributes {
+			if a.num != fieldResourceAttributes {
+				continue
+			}
+			key, value, err := parseKeyValue(a.bytes, true)
+			if err != nil {
+				return nil, fmt.Errorf("decoding resource attribute failed: %w", err)
+			}
+			switch key {
+			case resourceAttributeAccountID:
+				accountID = value
+			case resourceAttributeRegion:
+				region = value
+			}
+		}
+	}
+
+	var datapoints []streamDatapoint
+	for _, f := range fields {
+		if f.num != fieldResourceMetricsLibraryMetrics {
+			continue
+		}
+		metrics, err := decodeFields(f.bytes)
+		if err != nil {
+			return nil, fmt.Errorf("decoding library metrics failed: %w", err)
+		}
+		for _, m := range metrics {
+			if m.num != fieldLibraryMetricsMetrics {
+				continue
+			}
+			dps, err := parseMetric(m.bytes)
+			if err != nil {
+				return nil, err
+			}
+			for i := range dps {
+				dps[i].accountID = accountID
+				dps[i].region = region
+			}
+			datapoints = append(datapoints, dps...)
+		}
+	}
+	return datapoints, nil
+}
+
+func parseMetric(buf []byte) ([]streamDatapoint, error) {
+	fields, err := decodeFields(buf)
+	if err != nil {
+		return nil, fmt.Errorf("decoding metric failed: %w", err)
+	}
+
+	var name string
+	var summaries [][]byte
+	for _, f := range fields {
+		switch f.num {
+		case fieldMetricName:
+			name = string(f.bytes)
+		case fieldMetricDoubleSummary:
+			summaries = append(summaries, f.bytes)
+		}
+	}
+
+	// The metric name is "amazonaws.com/<namespace>/<metric name>" and used
+	// if the datapoint does not carry the information as labels
+	var namespace, metricName string
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		namespace = strings.TrimPrefix(name[:idx], metricNamePrefix)
+		metricName = name[idx+1:]
+	}
+
+	var datapoints []streamDatapoint
+	for _, summary := range summaries {
+		points, err := decodeFields(summary)
+		if err != nil {
+			return nil, fmt.Errorf("decoding summary of %q failed: %w", name, err)
+		}
+		for _, p := range points {
+			if p.num != fieldSummaryDataPoints {
+				continue
+			}
+			dp, err := parseDatapoint(p.bytes)
+			if err != nil {
+				return nil, fmt.Errorf("decoding datapoint of %q failed: %w", name, err)
+			}
+			if dp.namespace == "" {
+				dp.namespace = namespace
+			}
+			if dp.name == "" {
+				dp.name = metricName
+			}
+			datapoints = append(datapoints, dp)
+		}
+	}
+	return datapoints, nil
+}
+
+func parseDatapoint(buf []byte) (streamDatapoint, error) {
+	dp := streamDatapoint{dimensions: make(map[string]string)}
+
+	fields, err := decodeFields(buf)
+	if err != nil {
+		return dp, err
+	}
+
+	var count, sum, minimum, maximum float64
+	percentiles := make(map[float64]float64)
+	for _, f := range fields {
+		switch f.num {
+		case fieldDataPointLabels:
+			key, value, err := parseKeyValue(f.bytes, false)
+			if err != nil {
+				return dp, err
+			}
+			switch key {
+			case labelNamespace:
+				dp.namespace = value
+			case labelMetricName:
+				dp.name = value
+			default:
+				dp.dimensions[key] = value
+			}
+		case fieldDataPointTime:
+			dp.timestamp = time.Unix(0, int64(f.value))
+		case fieldDataPointCount:
+			count = float64(f.value)
+		case fieldDataPointSum:
+			sum = math.Float64frombits(f.value)
+		case fieldDataPointQuantiles:
+			quantile, value, err := parseQuantile(f.bytes)
+			if err != nil {
+				return dp, err
+			}
+			switch quantile {
+			case quantileMinimum:
+				minimum = value
+			case quantileMaximum:
+				maximum = value
+			default:
+				// Round to avoid artifacts like 99.00000000000001
+				percentiles[math.Round(quantile*1e6)/1e4] = value
+			}
+		}
+	}
+	dp.statistics = statistics(count, sum, minimum, maximum, percentiles)
+
+	return dp, nil
+}
+
+// parseKeyValue decodes a key-value pair, resource attributes use an
+// AnyValue while labels are plain strings
+func parseKeyValue(buf []byte, anyValue bool) (key, value string, err error) {
+	fields, err := decodeFields(buf)
+	if err != nil {
+		return "", "", err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case fieldKeyValueKey:
+			key = string(f.bytes)
+		case fieldKeyValueValue:
+			if !anyValue {
+				value = string(f.bytes)
+				continue
+			}
+			values, err := decodeFields(f.bytes)
+			if err != nil {
+				return "", "", err
+			}
+			for _, v := range values {
+				if v.num == fieldAnyValueString {
+					value = string(v.bytes)
+				}
+			}
+		}
+	}
+	if key == "" {
+		return "", "", errors.New("missing key")
+	}
+	return key, value, nil
+}
+
+func parseQuantile(buf []byte) (quantile, value float64, err error) {
+	fields, err := decodeFields(buf)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case fieldQuantileQuantile:
+			quantile = math.Float64frombits(f.value)
+		case fieldQuantileValue:
+			value = math.Float64frombits(f.value)
+		}
+	}
+	return quantile, value, nil
+}
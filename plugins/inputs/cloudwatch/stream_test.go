@@ -0,0 +1,322 @@
+package cloudwatch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const jsonRecord = `{"metric_stream_name":"stream","account_id":"123456789012","region":"us-east-1","namespace":"AWS/EC2","metric_name":"DiskWriteOps","dimensions":{"InstanceId":"i-123456789012"},"timestamp":1611929698000,"value":{"max":3.0,"min":0.0,"sum":9.0,"count":3.0},"unit":"None"}
+{"metric_stream_name":"stream","account_id":"123456789012","region":"us-east-1","namespace":"AWS/EC2","metric_name":"CPUUtilization","dimensions":{"InstanceId":"i-123456789012"},"timestamp":1611929698000,"value":{"max":10.0,"min":5.0,"sum":15.0,"count":2.0,"p99":9.5},"unit":"Percent"}
+{"metric_stream_name":"stream","account_id":"123456789012","region":"us-east-1","namespace":"AWS/ELB","metric_name":"Latency","dimensions":{"LoadBalancerName":"p-example"},"timestamp":1611929698000,"value":{"max":0.3,"min":0.1,"sum":0.4,"count":2.0},"unit":"Seconds"}
+`
+
+func startStreamPlugin(t *testing.T, plugin *CloudWatch) (*testutil.Accumulator, string) {
+	t.Helper()
+
+	plugin.Mode = "stream"
+	plugin.ServiceAddress = "127.0.0.1:0"
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	t.Cleanup(plugin.Stop)
+
+	return &acc, "http://" + plugin.stream.listener.Addr().String() + "/telegraf"
+}
+
+func firehoseBody(t *testing.T, records ...[]byte) []byte {
+	t.Helper()
+
+	req := map[string]interface{}{
+		"requestId": "ed4acda5-034f-9f42-bba1-f29aea6d7d8f",
+		"timestamp": 1611929698000,
+	}
+	data := make([]map[string]string, 0, len(records))
+	for _, r := range records {
+		data = append(data, map[string]string{"data": base64.StdEncoding.EncodeToString(r)})
+	}
+	req["records"] = data
+
+	buf, err := json.Marshal(req)
+	require.NoError(t, err)
+	return buf
+}
+
+func post(t *testing.T, url string, body []byte, header map[string]string) (int, firehoseResponse) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var r firehoseResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&r))
+	return resp.StatusCode, r
+}
+
+func TestStreamInitFail(t *testing.T) {
+	plugin := &CloudWatch{Mode: "push", Log: testutil.Logger{}}
+	require.ErrorContains(t, plugin.Init(), "invalid mode: push")
+
+	plugin = &CloudWatch{
+		Mode:         "stream",
+		streamConfig: streamConfig{StreamFormat: "opentelemetry1.0"},
+		Log:          testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid stream_format: opentelemetry1.0")
+}
+
+func TestStreamJSON(t *testing.T) {
+	plugin := &CloudWatch{
+		Namespaces:       []string{"AWS/EC2"},
+		StatisticExclude: []string{"sample_count"},
+		streamConfig: streamConfig{
+			FirehoseAccessKey: config.NewSecret([]byte("secret")),
+		},
+	}
+	acc, url := startStreamPlugin(t, plugin)
+
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	_, err := gz.Write(firehoseBody(t, []byte(jsonRecord)))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	status, resp := post(t, url, body.Bytes(), map[string]string{
+		"Content-Encoding":          "gzip",
+		"X-Amz-Firehose-Access-Key": "secret",
+	})
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "ed4acda5-034f-9f42-bba1-f29aea6d7d8f", resp.RequestID)
+	require.Empty(t, resp.ErrorMessage)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cloudwatch_aws_ec2",
+			map[string]string{
+				"region":      "us-east-1",
+				"instance_id": "i-123456789012",
+			},
+			map[string]interface{}{
+				"disk_write_ops_maximum":  3.0,
+				"disk_write_ops_minimum":  0.0,
+				"disk_write_ops_sum":      9.0,
+				"disk_write_ops_average":  3.0,
+				"cpu_utilization_maximum": 10.0,
+				"cpu_utilization_minimum": 5.0,
+				"cpu_utilization_sum":     15.0,
+				"cpu_utilization_average": 7.5,
+				"cpu_utilization_p99":     9.5,
+			},
+			time.UnixMilli(1611929698000),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestStreamMetricDefinitions(t *testing.T) {
+	include := []string{"average"}
+	plugin := &CloudWatch{
+		Metrics: []*cloudwatchMetric{
+			{
+				MetricNames:      []string{"Latency"},
+				Dimensions:       []*dimension{{Name: "LoadBalancerName", Value: "p-*"}},
+				StatisticInclude: &include,
+			},
+		},
+		MetricFormat:          "dense",
+		IncludeLinkedAccounts: true,
+	}
+	acc, url := startStreamPlugin(t, plugin)
+
+	status, _ := post(t, url, firehoseBody(t, []byte(jsonRecord)), nil)
+	require.Equal(t, http.StatusOK, status)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cloudwatch_aws_elb",
+			map[string]string{
+				"region":             "us-east-1",
+				"account":            "123456789012",
+				"load_balancer_name": "p-example",
+				"metric_name":        "latency",
+			},
+			map[string]interface{}{
+				"average": 0.2,
+			},
+			time.UnixMilli(1611929698000),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestStreamOpenTelemetry(t *testing.T) {
+	plugin := &CloudWatch{
+		streamConfig: streamConfig{StreamFormat: "opentelemetry0.7"},
+	}
+	acc, url := startStreamPlugin(t, plugin)
+
+	ts := time.Date(2021, 1, 29, 14, 14, 58, 0, time.UTC)
+	record := otelRecord(
+		otelRequest("AWS/DynamoDB", "ConsumedReadCapacityUnits", map[string]string{"TableName": "MyTable"}, ts, 4, 10, 1, 5, 0.99, 4.5),
+		otelRequest("AWS/EC2", "CPUUtilization", map[string]string{"InstanceId": "i-123456789012"}, ts, 2, 15, 5, 10, 0, 0),
+	)
+	status, resp := post(t, url, firehoseBody(t, record), nil)
+	require.Equal(t, http.StatusOK, status, resp.ErrorMessage)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cloudwatch_aws_dynamo_db",
+			map[string]string{
+				"region":     "eu-west-1",
+				"table_name": "MyTable",
+			},
+			map[string]interface{}{
+				"consumed_read_capacity_units_sample_count": 4.0,
+				"consumed_read_capacity_units_sum":          10.0,
+				"consumed_read_capacity_units_average":      2.5,
+				"consumed_read_capacity_units_minimum":      1.0,
+				"consumed_read_capacity_units_maximum":      5.0,
+				"consumed_read_capacity_units_p99":          4.5,
+			},
+			ts,
+		),
+		metric.New(
+			"cloudwatch_aws_ec2",
+			map[string]string{
+				"region":      "eu-west-1",
+				"instance_id": "i-123456789012",
+			},
+			map[string]interface{}{
+				"cpu_utilization_sample_count": 2.0,
+				"cpu_utilization_sum":          15.0,
+				"cpu_utilization_average":      7.5,
+				"cpu_utilization_minimum":      5.0,
+				"cpu_utilization_maximum":      10.0,
+			},
+			ts,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
+
+func TestStreamInvalidRequests(t *testing.T) {
+	plugin := &CloudWatch{
+		streamConfig: streamConfig{
+			FirehoseAccessKey: config.NewSecret([]byte("secret")),
+		},
+	}
+	acc, url := startStreamPlugin(t, plugin)
+	auth := map[string]string{"X-Amz-Firehose-Access-Key": "secret"}
+
+	status, _ := post(t, url, firehoseBody(t, []byte(jsonRecord)), map[string]string{"X-Amz-Firehose-Access-Key": "wrong"})
+	require.Equal(t, http.StatusUnauthorized, status)
+
+	status, _ = post(t, strings.TrimSuffix(url, "/telegraf")+"/other", firehoseBody(t, []byte(jsonRecord)), auth)
+	require.Equal(t, http.StatusNotFound, status)
+
+	status, resp := post(t, url, firehoseBody(t, []byte("{invalid")), auth)
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Equal(t, "ed4acda5-034f-9f42-bba1-f29aea6d7d8f", resp.RequestID)
+	require.Contains(t, resp.ErrorMessage, "decoding JSON record failed")
+
+	status, _ = post(t, url, []byte(`{"records":[{"data":"not base64!"}]}`), auth)
+	require.Equal(t, http.StatusBadRequest, status)
+
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+// otelRecord creates a record of length-delimited messages
+func otelRecord(msgs ...[]byte) []byte {
+	var buf []byte
+	for _, msg := range msgs {
+		buf = protowire.AppendBytes(buf, msg)
+	}
+	return buf
+}
+
+// otelRequest creates an ExportMetricsServiceRequest in the format sent by
+// CloudWatch with a single summary datapoint
+func otelRequest(
+	namespace, name string,
+	dimensions map[string]string,
+	ts time.Time,
+	count uint64,
+	sum, minimum, maximum, quantile, quantileValue float64,
+) []byte {
+	message := func(num protowire.Number, buf, content []byte) []byte {
+		buf = protowire.AppendTag(buf, num, protowire.BytesType)
+		return protowire.AppendBytes(buf, content)
+	}
+	str := func(num protowire.Number, buf []byte, s string) []byte {
+		return message(num, buf, []byte(s))
+	}
+	double := func(num protowire.Number, buf []byte, v float64) []byte {
+		buf = protowire.AppendTag(buf, num, protowire.Fixed64Type)
+		return protowire.AppendFixed64(buf, math.Float64bits(v))
+	}
+	label := func(buf []byte, key, value string) []byte {
+		return message(fieldDataPointLabels, buf, str(2, str(1, nil, key), value))
+	}
+	attribute := func(buf []byte, key, value string) []byte {
+		return message(fieldResourceAttributes, buf, message(2, str(1, nil, key), str(1, nil, value)))
+	}
+	valueAtQuantile := func(buf []byte, q, v float64) []byte {
+		return message(fieldDataPointQuantiles, buf, double(2, double(1, nil, q), v))
+	}
+
+	var dp []byte
+	dp = label(dp, "Namespace", namespace)
+	dp = label(dp, "MetricName", name)
+	for k, v := range dimensions {
+		dp = label(dp, k, v)
+	}
+	dp = protowire.AppendTag(dp, fieldDataPointTime, protowire.Fixed64Type)
+	dp = protowire.AppendFixed64(dp, uint64(ts.UnixNano()))
+	dp = protowire.AppendTag(dp, fieldDataPointCount, protowire.Fixed64Type)
+	dp = protowire.AppendFixed64(dp, count)
+	dp = double(fieldDataPointSum, dp, sum)
+	dp = valueAtQuantile(dp, 0, minimum)
+	dp = valueAtQuantile(dp, 1, maximum)
+	if quantile > 0 {
+		dp = valueAtQuantile(dp, quantile, quantileValue)
+	}
+
+	var m []byte
+	m = str(fieldMetricName, m, "amazonaws.com/"+namespace+"/"+name)
+	m = str(3, m, "Count")
+	m = message(fieldMetricDoubleSummary, m, message(fieldSummaryDataPoints, nil, dp))
+
+	var resource []byte
+	resource = attribute(resource, "cloud.provider", "aws")
+	resource = attribute(resource, "cloud.account.id", "123456789012")
+	resource = attribute(resource, "cloud.region", "eu-west-1")
+
+	// Put the metrics before the resource to check the order independence
+	var rm []byte
+	rm = message(fieldResourceMetricsLibraryMetrics, rm, message(fieldLibraryMetricsMetrics, nil, m))
+	rm = message(fieldResourceMetricsResource, rm, resource)
+
+	return message(fieldRequestResourceMetrics, nil, rm)
+}
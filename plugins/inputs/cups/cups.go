@@ -0,0 +1,442 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cups
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Bit of the printer-type attribute denoting a class, see
+// https://www.cups.org/doc/spec-ipp.html#printer-type
+const printerTypeClass = 0x0001
+
+// IPP status returned by CUPS if no printers are configured
+const statusNotFound = 0x0406
+
+var printerStates = map[int32]string{
+	3: "idle",
+	4: "processing",
+	5: "stopped",
+}
+
+var jobStates = map[int32]string{
+	3: "pending",
+	4: "held",
+	5: "processing",
+	6: "stopped",
+	7: "canceled",
+	8: "aborted",
+	9: "completed",
+}
+
+type CUPS struct {
+	URL                 string          `toml:"url"`
+	Username            config.Secret   `toml:"username"`
+	Password            config.Secret   `toml:"password"`
+	Printers            []string        `toml:"printers"`
+	GatherCompletedJobs bool            `toml:"gather_completed_jobs"`
+	Log                 telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	server        string
+	printerURI    string
+	client        *http.Client
+	printerFilter filter.Filter
+	requestID     atomic.Uint32
+
+	// cutoff is the completion time of the newest job reported
+	cutoff time.Time
+}
+
+type printer struct {
+	name      string
+	class     bool
+	state     int32
+	reasons   []string
+	accepting bool
+	queued    int32
+	members   []string
+}
+
+type job struct {
+	id         int32
+	printer    string
+	state      int32
+	created    time.Time
+	processing time.Time
+	completed  time.Time
+}
+
+func (*CUPS) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *CUPS) Init() error {
+	if c.URL == "" {
+		return errors.New("'url' required")
+	}
+	c.URL = strings.TrimRight(c.URL, "/")
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("parsing URL %q failed: %w", c.URL, err)
+	}
+	switch u.Scheme {
+	case "http":
+		c.printerURI = "ipp://" + u.Host + "/"
+	case "https":
+		c.printerURI = "ipps://" + u.Host + "/"
+	default:
+		return fmt.Errorf("invalid scheme %q in URL", u.Scheme)
+	}
+	c.server = u.Hostname()
+
+	if c.Username.Empty() != c.Password.Empty() {
+		return errors.New("'username' and 'password' must be specified together")
+	}
+
+	f, err := filter.Compile(c.Printers)
+	if err != nil {
+		return fmt.Errorf("creating printer filter failed: %w", err)
+	}
+	c.printerFilter = f
+
+	client, err := c.HTTPClientConfig.CreateClient(context.Background(), c.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	c.client = client
+
+	return nil
+}
+
+func (c *CUPS) Gather(acc telegraf.Accumulator) error {
+	now := time.Now()
+
+	printers, err := c.getPrinters()
+	if err != nil {
+		return fmt.Errorf("getting printers failed: %w", err)
+	}
+	active, err := c.getJobs("not-completed")
+	if err != nil {
+		return fmt.Errorf("getting active jobs failed: %w", err)
+	}
+
+	// Determine the classes each printer is a member of
+	classes := make(map[string][]string)
+	for _, p := range printers {
+		if !p.class {
+			continue
+		}
+		for _, member := range p.members {
+			classes[member] = append(classes[member], p.name)
+		}
+	}
+
+	for _, p := range printers {
+		if !c.match(p.name) {
+			continue
+		}
+
+		tags := map[string]string{
+			"server":  c.server,
+			"printer": p.name,
+			"type":    "printer",
+		}
+		if p.class {
+			tags["type"] = "class"
+		}
+		if names := classes[p.name]; len(names) > 0 {
+			sort.Strings(names)
+			tags["class"] = strings.Join(names, ",")
+		}
+
+		fields := map[string]interface{}{
+			"state":           printerStates[p.state],
+			"state_code":      p.state,
+			"accepting_jobs":  p.accepting,
+			"queued_jobs":     p.queued,
+			"jobs_pending":    0,
+			"jobs_held":       0,
+			"jobs_processing": 0,
+			"jobs_stopped":    0,
+		}
+		var oldest time.Time
+		for _, j := range active {
+			if j.printer != p.name {
+				continue
+			}
+			state, found := jobStates[j.state]
+			if !found || j.state > 6 {
+				continue
+			}
+			fields["jobs_"+state] = fields["jobs_"+state].(int) + 1
+			if j.state == 3 && (oldest.IsZero() || j.created.Before(oldest)) {
+				oldest = j.created
+			}
+		}
+		if !oldest.IsZero() {
+			fields["oldest_pending_job_age"] = now.Sub(oldest).Seconds()
+		}
+		acc.AddGauge("cups_printer", fields, tags, now)
+
+		for _, reason := range p.reasons {
+			if reason == "none" {
+				continue
+			}
+			name, severity := splitReason(reason)
+			rtags := map[string]string{
+				"server":   c.server,
+				"printer":  p.name,
+				"reason":   name,
+				"severity": severity,
+			}
+			acc.AddGauge("cups_printer_state_reason", map[string]interface{}{"active": true}, rtags, now)
+		}
+	}
+
+	if c.GatherCompletedJobs {
+		if err := c.gatherCompletedJobs(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering completed jobs failed: %w", err))
+		}
+	}
+
+	return nil
+}
+
+func (c *CUPS) Stop() {
+	if c.client != nil {
+		c.client.CloseIdleConnections()
+	}
+}
+
+// gatherCompletedJobs reports the jobs completed since the previous gather
+// cycle, jobs completed before the first cycle only serve as a baseline
+func (c *CUPS) gatherCompletedJobs(acc telegraf.Accumulator) error {
+	jobs, err := c.getJobs("completed")
+	if err != nil {
+		return err
+	}
+
+	first := c.cutoff.IsZero()
+	newest := c.cutoff
+	for _, j := range jobs {
+		if j.completed.After(newest) {
+			newest = j.completed
+		}
+		if first || !j.completed.After(c.cutoff) || !c.match(j.printer) {
+			continue
+		}
+
+		tags := map[string]string{
+			"server":  c.server,
+			"printer": j.printer,
+			"state":   jobStates[j.state],
+		}
+		fields := map[string]interface{}{
+			"job_id": j.id,
+		}
+		// Jobs canceled while pending never started processing
+		if j.processing.IsZero() {
+			fields["wait_time"] = j.completed.Sub(j.created).Seconds()
+		} else {
+			fields["wait_time"] = j.processing.Sub(j.created).Seconds()
+			fields["processing_time"] = j.completed.Sub(j.processing).Seconds()
+		}
+		acc.AddFields("cups_job", fields, tags, j.completed)
+	}
+
+	// Use the current time as baseline if no completed jobs exist yet
+	if newest.IsZero() {
+		newest = time.Now()
+	}
+	c.cutoff = newest
+
+	return nil
+}
+
+func (c *CUPS) match(name string) bool {
+	return c.printerFilter == nil || c.printerFilter.Match(name)
+}
+
+func (c *CUPS) getPrinters() ([]printer, error) {
+	resp, err := c.request(opCupsGetPrinters,
+		ippAttribute{tag: tagKeyword, name: "requested-attributes", values: []string{
+			"printer-name",
+			"printer-type",
+			"printer-state",
+			"printer-state-reasons",
+			"printer-is-accepting-jobs",
+			"queued-job-count",
+			"member-names",
+		}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if resp.status == statusNotFound {
+		return nil, nil
+	}
+
+	var printers []printer
+	for _, g := range resp.groups {
+		if g.tag != tagPrinterGroup {
+			continue
+		}
+		ptype, _ := g.integer("printer-type")
+		state, _ := g.integer("printer-state")
+		queued, _ := g.integer("queued-job-count")
+		printers = append(printers, printer{
+			name:      g.str("printer-name"),
+			class:     ptype&printerTypeClass != 0,
+			state:     state,
+			reasons:   g.strings("printer-state-reasons"),
+			accepting: g.boolean("printer-is-accepting-jobs"),
+			queued:    queued,
+			members:   g.strings("member-names"),
+		})
+	}
+	return printers, nil
+}
+
+func (c *CUPS) getJobs(which string) ([]job, error) {
+	resp, err := c.request(opGetJobs,
+		ippAttribute{tag: tagURI, name: "printer-uri", values: []string{c.printerURI}},
+		ippAttribute{tag: tagName, name: "requesting-user-name", values: []string{c.user()}},
+		ippAttribute{tag: tagKeyword, name: "which-jobs", values: []string{which}},
+		ippAttribute{tag: tagKeyword, name: "requested-attributes", values: []string{
+			"job-id",
+			"job-state",
+			"job-printer-uri",
+			"time-at-creation",
+			"time-at-processing",
+			"time-at-completed",
+		}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []job
+	for _, g := range resp.groups {
+		if g.tag != tagJobGroup {
+			continue
+		}
+		id, _ := g.integer("job-id")
+		state, _ := g.integer("job-state")
+		jobs = append(jobs, job{
+			id:         id,
+			printer:    path.Base(g.str("job-printer-uri")),
+			state:      state,
+			created:    unixTime(&g, "time-at-creation"),
+			processing: unixTime(&g, "time-at-processing"),
+			completed:  unixTime(&g, "time-at-completed"),
+		})
+	}
+	return jobs, nil
+}
+
+func (c *CUPS) user() string {
+	if c.Username.Empty() {
+		return "telegraf"
+	}
+	username, err := c.Username.Get()
+	if err != nil {
+		return "telegraf"
+	}
+	defer username.Destroy()
+	return username.String()
+}
+
+func (c *CUPS) request(operation uint16, attributes ...ippAttribute) (*ippResponse, error) {
+	body := encodeRequest(operation, c.requestID.Add(1), attributes...)
+	req, err := http.NewRequest("POST", c.URL+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/ipp")
+
+	if !c.Username.Empty() {
+		username, err := c.Username.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting username failed: %w", err)
+		}
+		password, err := c.Password.Get()
+		if err != nil {
+			username.Destroy()
+			return nil, fmt.Errorf("getting password failed: %w", err)
+		}
+		req.SetBasicAuth(username.String(), password.String())
+		username.Destroy()
+		password.Destroy()
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received status %q", resp.Status)
+	}
+
+	r, err := decodeResponse(data)
+	if err != nil {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+	if !r.success() && r.status != statusNotFound {
+		return nil, fmt.Errorf("received IPP status 0x%04x", r.status)
+	}
+	return r, nil
+}
+
+// splitReason separates the severity suffix from a printer-state-reasons
+// keyword, reasons without suffix are errors as defined in RFC 8011
+func splitReason(reason string) (name, severity string) {
+	for _, s := range []string{"report", "warning", "error"} {
+		if n, found := strings.CutSuffix(reason, "-"+s); found {
+			return n, s
+		}
+	}
+	return reason, "error"
+}
+
+func unixTime(g *ippGroup, name string) time.Time {
+	if v, ok := g.integer(name); ok && v > 0 {
+		return time.Unix(int64(v), 0)
+	}
+	return time.Time{}
+}
+
+func init() {
+	inputs.Add("cups", func() telegraf.Input {
+		return &CUPS{
+			URL:                 "http://localhost:631",
+			GatherCompletedJobs: true,
+		}
+	})
+}
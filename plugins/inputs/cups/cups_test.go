@@ -0,0 +1,541 @@
+package cups
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// value is an attribute value of a test response
+type value struct {
+	tag  byte
+	data interface{}
+}
+
+type attribute struct {
+	name   string
+	values []value
+}
+
+type group struct {
+	tag        byte
+	attributes []attribute
+}
+
+func keyword(s ...string) []value {
+	values := make([]value, 0, len(s))
+	for _, v := range s {
+		values = append(values, value{tag: tagKeyword, data: v})
+	}
+	return values
+}
+
+func name(s string) []value {
+	return []value{{tag: tagName, data: s}}
+}
+
+func uri(s string) []value {
+	return []value{{tag: tagURI, data: s}}
+}
+
+func integer(v int32) []value {
+	return []value{{tag: tagInteger, data: v}}
+}
+
+func enum(v int32) []value {
+	return []value{{tag: tagEnum, data: v}}
+}
+
+func boolean(v bool) []value {
+	return []value{{tag: tagBoolean, data: v}}
+}
+
+func encodeResponse(status uint16, requestID uint32, groups ...group) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{2, 0})
+	buf.Write(binary.BigEndian.AppendUint16(nil, status))
+	buf.Write(binary.BigEndian.AppendUint32(nil, requestID))
+	for _, g := range groups {
+		buf.WriteByte(g.tag)
+		for _, a := range g.attributes {
+			for i, v := range a.values {
+				buf.WriteByte(v.tag)
+				if i == 0 {
+					writeString(&buf, a.name)
+				} else {
+					writeString(&buf, "")
+				}
+				switch d := v.data.(type) {
+				case int32:
+					buf.Write([]byte{0, 4})
+					buf.Write(binary.BigEndian.AppendUint32(nil, uint32(d)))
+				case bool:
+					buf.Write([]byte{0, 1})
+					if d {
+						buf.WriteByte(1)
+					} else {
+						buf.WriteByte(0)
+					}
+				case string:
+					writeString(&buf, d)
+				}
+			}
+		}
+	}
+	buf.WriteByte(tagEnd)
+	return buf.Bytes()
+}
+
+// server simulates a CUPS server with the given printers and jobs
+type server struct {
+	printers  []group
+	active    []group
+	completed []group
+
+	sync.Mutex
+	users []string
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/ipp" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// The request header has the same layout as the response header with
+	// the operation in place of the status
+	req, err := decodeResponse(body)
+	if err != nil || len(req.groups) != 1 || req.groups[0].str("attributes-charset") != "utf-8" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	requestID := binary.BigEndian.Uint32(body[4:8])
+	operation := []group{{tag: tagOperationGroup, attributes: []attribute{
+		{name: "attributes-charset", values: []value{{tag: tagCharset, data: "utf-8"}}},
+		{name: "attributes-natural-language", values: []value{{tag: tagLanguage, data: "en"}}},
+	}}}
+
+	var groups []group
+	switch req.status {
+	case opCupsGetPrinters:
+		if len(s.printers) == 0 {
+			if _, err := w.Write(encodeResponse(statusNotFound, requestID, operation...)); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			return
+		}
+		groups = s.printers
+	case opGetJobs:
+		s.Lock()
+		s.users = append(s.users, req.groups[0].str("requesting-user-name"))
+		s.Unlock()
+		switch req.groups[0].str("which-jobs") {
+		case "not-completed":
+			groups = s.active
+		case "completed":
+			groups = s.completed
+		}
+	default:
+		if _, err := w.Write(encodeResponse(0x0501, requestID, operation...)); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if _, err := w.Write(encodeResponse(0, requestID, append(operation, groups...)...)); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func printerGroup(pname string, ptype, state int32, accepting bool, queued int32, reasons []string, members ...string) group {
+	attributes := []attribute{
+		{name: "printer-name", values: name(pname)},
+		{name: "printer-type", values: enum(ptype)},
+		{name: "printer-state", values: enum(state)},
+		{name: "printer-state-reasons", values: keyword(reasons...)},
+		{name: "printer-is-accepting-jobs", values: boolean(accepting)},
+		{name: "queued-job-count", values: integer(queued)},
+	}
+	if len(members) > 0 {
+		values := make([]value, 0, len(members))
+		for _, m := range members {
+			values = append(values, value{tag: tagName, data: m})
+		}
+		attributes = append(attributes, attribute{name: "member-names", values: values})
+	}
+	return group{tag: tagPrinterGroup, attributes: attributes}
+}
+
+func jobGroup(id int32, printer string, state int32, created, processing, completed int32) group {
+	return group{tag: tagJobGroup, attributes: []attribute{
+		{name: "job-id", values: integer(id)},
+		{name: "job-state", values: enum(state)},
+		{name: "job-printer-uri", values: uri("ipp://localhost/printers/" + printer)},
+		{name: "time-at-creation", values: integer(created)},
+		{name: "time-at-processing", values: integer(processing)},
+		{name: "time-at-completed", values: integer(completed)},
+	}}
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *CUPS
+		expected string
+	}{
+		{
+			name:     "missing url",
+			plugin:   &CUPS{},
+			expected: "'url' required",
+		},
+		{
+			name:     "invalid scheme",
+			plugin:   &CUPS{URL: "ipp://localhost:631"},
+			expected: `invalid scheme "ipp" in URL`,
+		},
+		{
+			name: "username without password",
+			plugin: &CUPS{
+				URL:      "http://localhost:631",
+				Username: config.NewSecret([]byte("admin")),
+			},
+			expected: "'username' and 'password' must be specified together",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestIPPRoundtrip(t *testing.T) {
+	buf := encodeRequest(opGetJobs, 42,
+		ippAttribute{tag: tagKeyword, name: "requested-attributes", values: []string{"job-id", "job-state"}},
+		ippAttribute{tag: tagBoolean, name: "my-jobs", values: []string{"true"}},
+	)
+	require.Equal(t, uint32(42), binary.BigEndian.Uint32(buf[4:8]))
+
+	req, err := decodeResponse(buf)
+	require.NoError(t, err)
+	require.Equal(t, opGetJobs, req.status)
+	require.Len(t, req.groups, 1)
+	g := req.groups[0]
+	require.Equal(t, tagOperationGroup, g.tag)
+	require.Equal(t, "en", g.str("attributes-natural-language"))
+	require.Equal(t, []string{"job-id", "job-state"}, g.strings("requested-attributes"))
+	require.True(t, g.boolean("my-jobs"))
+
+	_, err = decodeResponse(buf[:len(buf)-1])
+	require.ErrorContains(t, err, "missing end tag")
+}
+
+func TestSplitReason(t *testing.T) {
+	tests := []struct {
+		reason   string
+		name     string
+		severity string
+	}{
+		{reason: "toner-low-warning", name: "toner-low", severity: "warning"},
+		{reason: "media-empty-error", name: "media-empty", severity: "error"},
+		{reason: "moving-to-paused-report", name: "moving-to-paused", severity: "report"},
+		{reason: "paused", name: "paused", severity: "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			n, s := splitReason(tt.reason)
+			require.Equal(t, tt.name, n)
+			require.Equal(t, tt.severity, s)
+		})
+	}
+}
+
+func TestGather(t *testing.T) {
+	now := time.Now()
+	created := int32(now.Add(-time.Minute).Unix())
+
+	srv := &server{
+		printers: []group{
+			printerGroup("laser", 0x0004, 4, true, 3, []string{"toner-low-warning"}),
+			printerGroup("inkjet", 0x0004, 5, false, 0, []string{"paused", "media-empty-error"}),
+			printerGroup("office", 0x0001, 3, true, 0, []string{"none"}, "laser", "inkjet"),
+		},
+		active: []group{
+			jobGroup(11, "laser", 5, created, created+5, 0),
+			jobGroup(12, "laser", 3, created, 0, 0),
+			jobGroup(13, "laser", 4, created+10, 0, 0),
+		},
+		completed: []group{
+			jobGroup(1, "laser", 9, 1717999900, 1717999910, 1717999950),
+		},
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	plugin := &CUPS{
+		URL:                 ts.URL,
+		GatherCompletedJobs: true,
+		Log:                 testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	// The first cycle only serves as baseline for completed jobs
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cups_printer",
+			map[string]string{
+				"server":  "127.0.0.1",
+				"printer": "laser",
+				"type":    "printer",
+				"class":   "office",
+			},
+			map[string]interface{}{
+				"state":           "processing",
+				"state_code":      int32(4),
+				"accepting_jobs":  true,
+				"queued_jobs":     int32(3),
+				"jobs_pending":    1,
+				"jobs_held":       1,
+				"jobs_processing": 1,
+				"jobs_stopped":    0,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"cups_printer_state_reason",
+			map[string]string{
+				"server":   "127.0.0.1",
+				"printer":  "laser",
+				"reason":   "toner-low",
+				"severity": "warning",
+			},
+			map[string]interface{}{"active": true},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"cups_printer",
+			map[string]string{
+				"server":  "127.0.0.1",
+				"printer": "inkjet",
+				"type":    "printer",
+				"class":   "office",
+			},
+			map[string]interface{}{
+				"state":           "stopped",
+				"state_code":      int32(5),
+				"accepting_jobs":  false,
+				"queued_jobs":     int32(0),
+				"jobs_pending":    0,
+				"jobs_held":       0,
+				"jobs_processing": 0,
+				"jobs_stopped":    0,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"cups_printer_state_reason",
+			map[string]string{
+				"server":   "127.0.0.1",
+				"printer":  "inkjet",
+				"reason":   "paused",
+				"severity": "error",
+			},
+			map[string]interface{}{"active": true},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"cups_printer_state_reason",
+			map[string]string{
+				"server":   "127.0.0.1",
+				"printer":  "inkjet",
+				"reason":   "media-empty",
+				"severity": "error",
+			},
+			map[string]interface{}{"active": true},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"cups_printer",
+			map[string]string{
+				"server":  "127.0.0.1",
+				"printer": "office",
+				"type":    "class",
+			},
+			map[string]interface{}{
+				"state":           "idle",
+				"state_code":      int32(3),
+				"accepting_jobs":  true,
+				"queued_jobs":     int32(0),
+				"jobs_pending":    0,
+				"jobs_held":       0,
+				"jobs_processing": 0,
+				"jobs_stopped":    0,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+
+	// Check the age of the oldest pending job separately as it depends on
+	// the current time
+	actual := acc.GetTelegrafMetrics()
+	for _, m := range actual {
+		if m.Name() != "cups_printer" {
+			continue
+		}
+		printer, _ := m.GetTag("printer")
+		age, found := m.GetField("oldest_pending_job_age")
+		require.Equal(t, printer == "laser", found, printer)
+		if found {
+			require.GreaterOrEqual(t, age, 60.0)
+			m.RemoveField("oldest_pending_job_age")
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// Jobs completed after the baseline are reported in the next cycle
+	srv.completed = append(srv.completed,
+		jobGroup(2, "laser", 9, 1718000000, 1718000010, 1718000040),
+		jobGroup(3, "inkjet", 7, 1718000000, 0, 1718000030),
+	)
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected = []telegraf.Metric{
+		metric.New(
+			"cups_job",
+			map[string]string{
+				"server":  "127.0.0.1",
+				"printer": "laser",
+				"state":   "completed",
+			},
+			map[string]interface{}{
+				"job_id":          int32(2),
+				"wait_time":       float64(10),
+				"processing_time": float64(30),
+			},
+			time.Unix(1718000040, 0),
+		),
+		metric.New(
+			"cups_job",
+			map[string]string{
+				"server":  "127.0.0.1",
+				"printer": "inkjet",
+				"state":   "canceled",
+			},
+			map[string]interface{}{
+				"job_id":    int32(3),
+				"wait_time": float64(30),
+			},
+			time.Unix(1718000030, 0),
+		),
+	}
+	var jobs []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "cups_job" {
+			jobs = append(jobs, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, jobs, testutil.SortMetrics())
+	require.Equal(t, []string{"telegraf", "telegraf", "telegraf", "telegraf"}, srv.users)
+}
+
+func TestGatherFiltered(t *testing.T) {
+	srv := &server{
+		printers: []group{
+			printerGroup("laser", 0x0004, 3, true, 0, nil),
+			printerGroup("inkjet", 0x0004, 3, true, 0, nil),
+		},
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	plugin := &CUPS{
+		URL:      ts.URL,
+		Printers: []string{"ink*"},
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, "inkjet", acc.Metrics[0].Tags["printer"])
+}
+
+func TestGatherNoPrinters(t *testing.T) {
+	ts := httptest.NewServer(&server{})
+	defer ts.Close()
+
+	plugin := &CUPS{
+		URL: ts.URL,
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Metrics)
+}
+
+func TestGatherAuthentication(t *testing.T) {
+	srv := &server{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	plugin := &CUPS{
+		URL: ts.URL,
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Gather(&acc), "401 Unauthorized")
+
+	plugin = &CUPS{
+		URL:      ts.URL,
+		Username: config.NewSecret([]byte("admin")),
+		Password: config.NewSecret([]byte("secret")),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Gather(&acc))
+	require.Equal(t, []string{"admin"}, srv.users)
+}
@@ -0,0 +1,242 @@
+package cups
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Operations of the Internet Printing Protocol used by the plugin, see
+// RFC 8011 and https://www.cups.org/doc/spec-ipp.html
+const (
+	opGetJobs         uint16 = 0x000a
+	opCupsGetPrinters uint16 = 0x4002
+)
+
+// Delimiter tags separating the attribute groups
+const (
+	tagOperationGroup byte = 0x01
+	tagJobGroup       byte = 0x02
+	tagEnd            byte = 0x03
+	tagPrinterGroup   byte = 0x04
+)
+
+// Value tags of the attributes
+const (
+	tagNoValue      byte = 0x13
+	tagInteger      byte = 0x21
+	tagBoolean      byte = 0x22
+	tagEnum         byte = 0x23
+	tagName         byte = 0x42
+	tagKeyword      byte = 0x44
+	tagURI          byte = 0x45
+	tagCharset      byte = 0x47
+	tagLanguage     byte = 0x48
+	tagTextWithLang byte = 0x35
+	tagNameWithLang byte = 0x36
+)
+
+// ippAttribute is an attribute of an IPP request
+type ippAttribute struct {
+	tag    byte
+	name   string
+	values []string
+}
+
+// ippGroup holds the attributes of a group in an IPP response with the
+// values decoded as int32, bool or string depending on the value tag
+type ippGroup struct {
+	tag        byte
+	attributes map[string][]interface{}
+}
+
+type ippResponse struct {
+	status uint16
+	groups []ippGroup
+}
+
+// encodeRequest creates an IPP/2.0 request with the given operation
+// attributes, the mandatory charset and language attributes are added
+func encodeRequest(operation uint16, requestID uint32, attributes ...ippAttribute) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{2, 0})
+	buf.Write(binary.BigEndian.AppendUint16(nil, operation))
+	buf.Write(binary.BigEndian.AppendUint32(nil, requestID))
+
+	buf.WriteByte(tagOperationGroup)
+	attributes = append([]ippAttribute{
+		{tag: tagCharset, name: "attributes-charset", values: []string{"utf-8"}},
+		{tag: tagLanguage, name: "attributes-natural-language", values: []string{"en"}},
+	}, attributes...)
+	for _, a := range attributes {
+		for i, v := range a.values {
+			buf.WriteByte(a.tag)
+			// Additional values of an attribute have an empty name
+			name := a.name
+			if i > 0 {
+				name = ""
+			}
+			writeString(&buf, name)
+			if a.tag == tagBoolean {
+				buf.Write([]byte{0, 1})
+				if v == "true" {
+					buf.WriteByte(1)
+				} else {
+					buf.WriteByte(0)
+				}
+				continue
+			}
+			writeString(&buf, v)
+		}
+	}
+	buf.WriteByte(tagEnd)
+
+	return buf.Bytes()
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	buf.Write(binary.BigEndian.AppendUint16(nil, uint16(len(s))))
+	buf.WriteString(s)
+}
+
+// decodeResponse parses an IPP response, attributes with value tags not
+// used by the plugin are decoded as raw strings
+func decodeResponse(data []byte) (*ippResponse, error) {
+	r := bytes.NewReader(data)
+
+	var header struct {
+		Version   uint16
+		Status    uint16
+		RequestID uint32
+	}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return nil, fmt.Errorf("reading header failed: %w", err)
+	}
+
+	resp := &ippResponse{status: header.Status}
+	var group *ippGroup
+	var last string
+	for {
+		tag, err := r.ReadByte()
+		if err != nil {
+			return nil, errors.New("missing end tag")
+		}
+		if tag == tagEnd {
+			break
+		}
+
+		// Delimiter tags start a new group
+		if tag < 0x10 {
+			resp.groups = append(resp.groups, ippGroup{tag: tag, attributes: make(map[string][]interface{})})
+			group = &resp.groups[len(resp.groups)-1]
+			last = ""
+			continue
+		}
+		if group == nil {
+			return nil, errors.New("attribute outside of group")
+		}
+
+		name, err := readString(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading attribute name failed: %w", err)
+		}
+		raw, err := readString(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading value of %q failed: %w", name, err)
+		}
+
+		// An empty name denotes an additional value of the previous attribute
+		if name == "" {
+			name = last
+		}
+		last = name
+
+		value, err := decodeValue(tag, []byte(raw))
+		if err != nil {
+			return nil, fmt.Errorf("decoding value of %q failed: %w", name, err)
+		}
+		if value != nil {
+			group.attributes[name] = append(group.attributes[name], value)
+		}
+	}
+
+	return resp, nil
+}
+
+func readString(r *bytes.Reader) (string, error) {
+	var length uint16
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return "", err
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+func decodeValue(tag byte, raw []byte) (interface{}, error) {
+	switch tag {
+	case tagNoValue:
+		return nil, nil
+	case tagInteger, tagEnum:
+		if len(raw) != 4 {
+			return nil, fmt.Errorf("invalid integer length %d", len(raw))
+		}
+		return int32(binary.BigEndian.Uint32(raw)), nil
+	case tagBoolean:
+		if len(raw) != 1 {
+			return nil, fmt.Errorf("invalid boolean length %d", len(raw))
+		}
+		return raw[0] != 0, nil
+	case tagTextWithLang, tagNameWithLang:
+		// The value consists of the language and the text with their lengths
+		r := bytes.NewReader(raw)
+		if _, err := readString(r); err != nil {
+			return nil, err
+		}
+		return readString(r)
+	}
+	return string(raw), nil
+}
+
+// success returns true for the successful status codes 0x0000 to 0x00ff
+func (r *ippResponse) success() bool {
+	return r.status <= 0x00ff
+}
+
+func (g *ippGroup) integer(name string) (int32, bool) {
+	if values := g.attributes[name]; len(values) > 0 {
+		v, ok := values[0].(int32)
+		return v, ok
+	}
+	return 0, false
+}
+
+func (g *ippGroup) boolean(name string) bool {
+	if values := g.attributes[name]; len(values) > 0 {
+		v, ok := values[0].(bool)
+		return ok && v
+	}
+	return false
+}
+
+func (g *ippGroup) str(name string) string {
+	if values := g.attributes[name]; len(values) > 0 {
+		v, _ := values[0].(string)
+		return v
+	}
+	return ""
+}
+
+func (g *ippGroup) strings(name string) []string {
+	values := make([]string, 0, len(g.attributes[name]))
+	for _, v := range g.attributes[name] {
+		if s, ok := v.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
@@ -0,0 +1,70 @@
+package dns_resolver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// bindStats is the part of the '/json/v1/server' response of the BIND
+// statistics channel used by the plugin
+type bindStats struct {
+	NSStats map[string]int64 `json:"nsstats"`
+	Views   map[string]struct {
+		Resolver struct {
+			Stats      map[string]int64 `json:"stats"`
+			CacheStats map[string]int64 `json:"cachestats"`
+		} `json:"resolver"`
+	} `json:"views"`
+}
+
+// bind queries the statistics from the JSON interface of the BIND
+// statistics channel
+type bind struct {
+	url    string
+	client *http.Client
+}
+
+func (b *bind) stats() (map[string]interface{}, error) {
+	resp, err := b.client.Get(b.url + "/json/v1/server")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received status %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	var stats bindStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+
+	// Sum up the resolver statistics of all views except the internal view
+	// answering CHAOS class queries
+	var forwarded, hits, misses int64
+	for name, view := range stats.Views {
+		if name == "_bind" {
+			continue
+		}
+		forwarded += view.Resolver.Stats["Queryv4"] + view.Resolver.Stats["Queryv6"]
+		hits += view.Resolver.CacheStats["QueryHits"]
+		misses += view.Resolver.CacheStats["QueryMisses"]
+	}
+
+	return map[string]interface{}{
+		"queries":           stats.NSStats["Requestv4"] + stats.NSStats["Requestv6"],
+		"queries_forwarded": forwarded,
+		"cache_hits":        hits,
+		"cache_misses":      misses,
+		"nxdomain":          stats.NSStats["QryNXDOMAIN"],
+		"servfail":          stats.NSStats["QrySERVFAIL"],
+		"queries_recursive": stats.NSStats["QryRecursion"],
+		"queries_dropped":   stats.NSStats["QryDropped"],
+	}, nil
+}
+
+func (*bind) clients(int) ([]client, error) {
+	return nil, nil
+}
@@ -0,0 +1,168 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package dns_resolver
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type DNSResolver struct {
+	Type       string          `toml:"type"`
+	Address    string          `toml:"address"`
+	Password   config.Secret   `toml:"password"`
+	TopClients int             `toml:"top_clients"`
+	Timeout    config.Duration `toml:"timeout"`
+	Log        telegraf.Logger `toml:"-"`
+	common_tls.ClientConfig
+
+	resolver resolver
+}
+
+// resolver queries the statistics of a specific resolver implementation
+type resolver interface {
+	// stats returns the normalized statistics fields
+	stats() (map[string]interface{}, error)
+	// clients returns the clients sending the most queries
+	clients(n int) ([]client, error)
+}
+
+// client is a client of the resolver with the number of queries sent
+type client struct {
+	address string
+	name    string
+	queries int64
+}
+
+func (*DNSResolver) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *DNSResolver) Init() error {
+	if d.Address == "" {
+		return errors.New("no address specified")
+	}
+	if d.TopClients < 0 {
+		return errors.New("'top_clients' must not be negative")
+	}
+
+	tlsCfg, err := d.ClientConfig.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("creating TLS config failed: %w", err)
+	}
+
+	switch d.Type {
+	case "pihole", "bind":
+		if _, err := url.Parse(d.Address); err != nil {
+			return fmt.Errorf("invalid address %q: %w", d.Address, err)
+		}
+		httpClient := &http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsCfg},
+			Timeout:   time.Duration(d.Timeout),
+		}
+		address := strings.TrimSuffix(d.Address, "/")
+		if d.Type == "pihole" {
+			d.resolver = &pihole{url: address, password: d.Password, client: httpClient}
+		} else {
+			if !d.Password.Empty() {
+				return errors.New("'password' is not supported for BIND")
+			}
+			d.resolver = &bind{url: address, client: httpClient}
+		}
+	case "unbound":
+		if !d.Password.Empty() {
+			return errors.New("'password' is not supported for Unbound")
+		}
+		u, err := url.Parse(d.Address)
+		if err != nil {
+			return fmt.Errorf("invalid address %q: %w", d.Address, err)
+		}
+		switch u.Scheme {
+		case "tcp", "tcp4", "tcp6":
+			d.resolver = &unbound{network: u.Scheme, address: u.Host, tlsCfg: tlsCfg, timeout: time.Duration(d.Timeout)}
+		case "unix":
+			d.resolver = &unbound{network: u.Scheme, address: u.Path, timeout: time.Duration(d.Timeout)}
+		default:
+			return fmt.Errorf("invalid scheme %q for Unbound control socket", u.Scheme)
+		}
+	case "":
+		return errors.New("no type specified")
+	default:
+		return fmt.Errorf("invalid type %q", d.Type)
+	}
+
+	if d.TopClients > 0 && d.Type != "pihole" {
+		d.Log.Warnf("Per-client statistics are not available for %s, ignoring 'top_clients'", d.Type)
+		d.TopClients = 0
+	}
+
+	return nil
+}
+
+func (d *DNSResolver) Gather(acc telegraf.Accumulator) error {
+	tags := map[string]string{
+		"type":   d.Type,
+		"server": d.Address,
+	}
+
+	fields, err := d.resolver.stats()
+	if err != nil {
+		return fmt.Errorf("querying statistics failed: %w", err)
+	}
+	addCacheHitRatio(fields)
+	acc.AddFields("dns_resolver", fields, tags)
+
+	if d.TopClients == 0 {
+		return nil
+	}
+	clients, err := d.resolver.clients(d.TopClients)
+	if err != nil {
+		return fmt.Errorf("querying top clients failed: %w", err)
+	}
+	for _, c := range clients {
+		ctags := map[string]string{
+			"type":   d.Type,
+			"server": d.Address,
+			"client": c.address,
+		}
+		if c.name != "" {
+			ctags["client_name"] = c.name
+		}
+		acc.AddFields("dns_resolver_client", map[string]interface{}{"queries": c.queries}, ctags)
+	}
+
+	return nil
+}
+
+// addCacheHitRatio computes the ratio of the queries answered from the cache
+func addCacheHitRatio(fields map[string]interface{}) {
+	hits, ok := fields["cache_hits"].(int64)
+	if !ok {
+		return
+	}
+	misses, ok := fields["cache_misses"].(int64)
+	if !ok || hits+misses == 0 {
+		return
+	}
+	fields["cache_hit_ratio"] = float64(hits) / float64(hits+misses)
+}
+
+func init() {
+	inputs.Add("dns_resolver", func() telegraf.Input {
+		return &DNSResolver{
+			Timeout: config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,347 @@
+package dns_resolver
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *DNSResolver
+		expected string
+	}{
+		{
+			name:     "no address",
+			plugin:   &DNSResolver{Type: "pihole"},
+			expected: "no address specified",
+		},
+		{
+			name:     "no type",
+			plugin:   &DNSResolver{Address: "http://pi.hole"},
+			expected: "no type specified",
+		},
+		{
+			name:     "invalid type",
+			plugin:   &DNSResolver{Type: "dnsmasq", Address: "http://pi.hole"},
+			expected: `invalid type "dnsmasq"`,
+		},
+		{
+			name:     "invalid unbound scheme",
+			plugin:   &DNSResolver{Type: "unbound", Address: "http://127.0.0.1:8953"},
+			expected: `invalid scheme "http"`,
+		},
+		{
+			name: "password for bind",
+			plugin: &DNSResolver{
+				Type:     "bind",
+				Address:  "http://localhost:8053",
+				Password: config.NewSecret([]byte("secret")),
+			},
+			expected: "'password' is not supported for BIND",
+		},
+		{
+			name:     "negative top clients",
+			plugin:   &DNSResolver{Type: "pihole", Address: "http://pi.hole", TopClients: -1},
+			expected: "'top_clients' must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestTopClientsIgnored(t *testing.T) {
+	plugin := &DNSResolver{
+		Type:       "bind",
+		Address:    "http://localhost:8053",
+		TopClients: 10,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.Zero(t, plugin.TopClients)
+}
+
+func newPihole(t *testing.T, password string, logins *atomic.Int32) *httptest.Server {
+	responses := map[string]string{
+		"/api/stats/summary":     "pihole_summary.json",
+		"/api/stats/top_clients": "pihole_top_clients.json",
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/auth" {
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if body["password"] != password {
+				w.WriteHeader(http.StatusUnauthorized)
+				if _, err := w.Write([]byte(`{"session": {"valid": false, "sid": null, "message": "password incorrect"}}`)); err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			logins.Add(1)
+			if _, err := w.Write([]byte(`{"session": {"valid": true, "sid": "session-id", "validity": 1800, "message": "password correct"}}`)); err != nil {
+				t.Error(err)
+			}
+			return
+		}
+
+		if password != "" && r.Header.Get("X-FTL-SID") != "session-id" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fn, found := responses[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Path == "/api/stats/top_clients" && r.URL.Query().Get("count") != "2" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		buf, err := os.ReadFile(filepath.Join("testdata", fn))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func TestPihole(t *testing.T) {
+	var logins atomic.Int32
+	server := newPihole(t, "secret", &logins)
+	defer server.Close()
+
+	plugin := &DNSResolver{
+		Type:       "pihole",
+		Address:    server.URL,
+		Password:   config.NewSecret([]byte("secret")),
+		TopClients: 2,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	tags := map[string]string{"type": "pihole", "server": server.URL}
+	expected := []telegraf.Metric{
+		metric.New("dns_resolver",
+			tags,
+			map[string]interface{}{
+				"queries":           int64(7497),
+				"queries_blocked":   int64(3465),
+				"queries_forwarded": int64(2532),
+				"cache_hits":        int64(1500),
+				"cache_misses":      int64(2532),
+				"cache_hit_ratio":   float64(1500) / float64(4032),
+				"nxdomain":          int64(42),
+				"servfail":          int64(3),
+				"unique_domains":    int64(445),
+				"clients_active":    int64(10),
+				"domains_blocked":   int64(104587),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New("dns_resolver_client",
+			map[string]string{
+				"type":        "pihole",
+				"server":      server.URL,
+				"client":      "192.168.0.44",
+				"client_name": "raspberrypi.lan",
+			},
+			map[string]interface{}{"queries": int64(5896)},
+			time.Unix(0, 0),
+		),
+		metric.New("dns_resolver_client",
+			map[string]string{
+				"type":   "pihole",
+				"server": server.URL,
+				"client": "192.168.0.17",
+			},
+			map[string]interface{}{"queries": int64(1601)},
+			time.Unix(0, 0),
+		),
+	}
+
+	// The session must be reused across gathering cycles
+	for range 2 {
+		var acc testutil.Accumulator
+		require.NoError(t, plugin.Gather(&acc))
+		testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	}
+	require.Equal(t, int32(1), logins.Load())
+}
+
+func TestPiholeWithoutPassword(t *testing.T) {
+	var logins atomic.Int32
+	server := newPihole(t, "", &logins)
+	defer server.Close()
+
+	plugin := &DNSResolver{
+		Type:    "pihole",
+		Address: server.URL + "/",
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	require.Zero(t, logins.Load())
+}
+
+func TestPiholeWrongPassword(t *testing.T) {
+	var logins atomic.Int32
+	server := newPihole(t, "secret", &logins)
+	defer server.Close()
+
+	plugin := &DNSResolver{
+		Type:     "pihole",
+		Address:  server.URL,
+		Password: config.NewSecret([]byte("wrong")),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Gather(&acc), "login failed with status 401")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestUnbound(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		cmd, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if cmd != "UBCT1 stats_noreset\n" {
+			if _, err := conn.Write([]byte("error unknown command\n")); err != nil {
+				t.Error(err)
+			}
+			return
+		}
+		buf, err := os.ReadFile(filepath.Join("testdata", "unbound_stats.txt"))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if _, err := conn.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	address := "tcp://" + listener.Addr().String()
+	plugin := &DNSResolver{
+		Type:    "unbound",
+		Address: address,
+		Timeout: config.Duration(5 * time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New("dns_resolver",
+			map[string]string{"type": "unbound", "server": address},
+			map[string]interface{}{
+				"queries":             int64(11907596),
+				"cache_hits":          int64(11489288),
+				"cache_misses":        int64(418308),
+				"cache_hit_ratio":     float64(11489288) / float64(11907596),
+				"prefetches":          int64(0),
+				"nxdomain":            int64(50116),
+				"servfail":            int64(17),
+				"recursion_time_avg":  float64(0.015020),
+				"requestlist_current": int64(2),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestBind(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/json/v1/server" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buf, err := os.ReadFile(filepath.Join("testdata", "bind_server.json"))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &DNSResolver{
+		Type:    "bind",
+		Address: server.URL,
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New("dns_resolver",
+			map[string]string{"type": "bind", "server": server.URL},
+			map[string]interface{}{
+				"queries":           int64(1302),
+				"queries_forwarded": int64(400),
+				"cache_hits":        int64(900),
+				"cache_misses":      int64(400),
+				"cache_hit_ratio":   float64(900) / float64(1300),
+				"nxdomain":          int64(90),
+				"servfail":          int64(12),
+				"queries_recursive": int64(400),
+				"queries_dropped":   int64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
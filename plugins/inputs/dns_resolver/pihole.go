@@ -0,0 +1,176 @@
+package dns_resolver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+var errUnauthorized = errors.New("unauthorized")
+
+// piholeSummary is the part of the '/api/stats/summary' response of the
+// Pi-hole v6 API used by the plugin. The values cover the last 24 hours.
+type piholeSummary struct {
+	Queries struct {
+		Total         int64            `json:"total"`
+		Blocked       int64            `json:"blocked"`
+		Forwarded     int64            `json:"forwarded"`
+		Cached        int64            `json:"cached"`
+		UniqueDomains int64            `json:"unique_domains"`
+		Replies       map[string]int64 `json:"replies"`
+	} `json:"queries"`
+	Clients struct {
+		Active int64 `json:"active"`
+	} `json:"clients"`
+	Gravity struct {
+		DomainsBeingBlocked int64 `json:"domains_being_blocked"`
+	} `json:"gravity"`
+}
+
+// piholeTopClients is the '/api/stats/top_clients' response
+type piholeTopClients struct {
+	Clients []struct {
+		IP    string `json:"ip"`
+		Name  string `json:"name"`
+		Count int64  `json:"count"`
+	} `json:"clients"`
+}
+
+// pihole queries the statistics of the Pi-hole FTL engine using the REST API
+// introduced in Pi-hole v6
+type pihole struct {
+	url      string
+	password config.Secret
+	client   *http.Client
+	sid      string
+}
+
+func (p *pihole) stats() (map[string]interface{}, error) {
+	var summary piholeSummary
+	if err := p.get("/api/stats/summary", &summary); err != nil {
+		return nil, err
+	}
+
+	q := summary.Queries
+	return map[string]interface{}{
+		"queries":           q.Total,
+		"queries_blocked":   q.Blocked,
+		"queries_forwarded": q.Forwarded,
+		"cache_hits":        q.Cached,
+		"cache_misses":      q.Forwarded,
+		"nxdomain":          q.Replies["NXDOMAIN"],
+		"servfail":          q.Replies["SERVFAIL"],
+		"unique_domains":    q.UniqueDomains,
+		"clients_active":    summary.Clients.Active,
+		"domains_blocked":   summary.Gravity.DomainsBeingBlocked,
+	}, nil
+}
+
+func (p *pihole) clients(n int) ([]client, error) {
+	var top piholeTopClients
+	if err := p.get("/api/stats/top_clients?count="+strconv.Itoa(n), &top); err != nil {
+		return nil, err
+	}
+
+	clients := make([]client, 0, len(top.Clients))
+	for _, c := range top.Clients {
+		clients = append(clients, client{address: c.IP, name: c.Name, queries: c.Count})
+	}
+	return clients, nil
+}
+
+// get queries the given API path and decodes the response into v. A new
+// session is created if the API requires authentication and the current
+// session expired.
+func (p *pihole) get(path string, v interface{}) error {
+	err := p.request(path, v)
+	if !errors.Is(err, errUnauthorized) || p.password.Empty() {
+		return err
+	}
+	if err := p.login(); err != nil {
+		return err
+	}
+	return p.request(path, v)
+}
+
+func (p *pihole) request(path string, v interface{}) error {
+	req, err := http.NewRequest("GET", p.url+path, nil)
+	if err != nil {
+		return fmt.Errorf("creating request failed: %w", err)
+	}
+	if p.sid != "" {
+		req.Header.Set("X-FTL-SID", p.sid)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return errUnauthorized
+	default:
+		return fmt.Errorf("received status %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response failed: %w", err)
+	}
+	return nil
+}
+
+// login creates a new session using the configured password. Sessions are
+// reused across gathering cycles as the number of concurrent sessions is
+// limited by Pi-hole.
+func (p *pihole) login() error {
+	password, err := p.password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	body, err := json.Marshal(map[string]string{"password": password.String()})
+	password.Destroy()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", p.url+"/api/auth", bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("creating login request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("login failed with status %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	var result struct {
+		Session struct {
+			Valid   bool   `json:"valid"`
+			SID     string `json:"sid"`
+			Message string `json:"message"`
+		} `json:"session"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decoding login response failed: %w", err)
+	}
+	if !result.Session.Valid || result.Session.SID == "" {
+		return fmt.Errorf("login failed: %s", result.Session.Message)
+	}
+	p.sid = result.Session.SID
+
+	return nil
+}
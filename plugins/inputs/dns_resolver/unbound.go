@@ -0,0 +1,95 @@
+package dns_resolver
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unbound statistics mapped to the normalized fields
+var unboundFields = map[string]string{
+	"total.num.queries":             "queries",
+	"total.num.cachehits":           "cache_hits",
+	"total.num.cachemiss":           "cache_misses",
+	"total.num.prefetch":            "prefetches",
+	"num.answer.rcode.NXDOMAIN":     "nxdomain",
+	"num.answer.rcode.SERVFAIL":     "servfail",
+	"total.recursion.time.avg":      "recursion_time_avg",
+	"total.requestlist.current.all": "requestlist_current",
+}
+
+// unbound queries the statistics via the remote control protocol of the
+// Unbound control socket, i.e. the protocol used by 'unbound-control'
+type unbound struct {
+	network string
+	address string
+	tlsCfg  *tls.Config
+	timeout time.Duration
+}
+
+func (u *unbound) stats() (map[string]interface{}, error) {
+	dialer := &net.Dialer{Timeout: u.timeout}
+	var conn net.Conn
+	var err error
+	if u.tlsCfg != nil {
+		conn, err = tls.DialWithDialer(dialer, u.network, u.address, u.tlsCfg)
+	} else {
+		conn, err = dialer.Dial(u.network, u.address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connecting failed: %w", err)
+	}
+	defer conn.Close()
+
+	if u.timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(u.timeout)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Do not reset the statistics to not interfere with other consumers
+	if _, err := conn.Write([]byte("UBCT1 stats_noreset\n")); err != nil {
+		return nil, fmt.Errorf("sending command failed: %w", err)
+	}
+
+	fields := make(map[string]interface{}, len(unboundFields))
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "error") {
+			return nil, errors.New(line)
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		name, found := unboundFields[key]
+		if !found {
+			continue
+		}
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			fields[name] = v
+		} else if v, err := strconv.ParseFloat(value, 64); err == nil {
+			fields[name] = v
+		} else {
+			return nil, fmt.Errorf("parsing value %q of %q failed: %w", value, key, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading response failed: %w", err)
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("no statistics received")
+	}
+
+	return fields, nil
+}
+
+func (*unbound) clients(int) ([]client, error) {
+	return nil, nil
+}
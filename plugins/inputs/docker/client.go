@@ -7,6 +7,7 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/swarm"
 	"github.com/docker/docker/api/types/system"
 	"github.com/docker/docker/client"
@@ -33,6 +34,8 @@ type dockerClient interface {
 	NodeList(ctx context.Context, options swarm.NodeListOptions) ([]swarm.Node, error)
 	// DiskUsage retrieves disk usage information.
 	DiskUsage(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error)
+	// Events streams the events of the Docker server based on the specified options.
+	Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error)
 	// ClientVersion retrieves the version of the Docker client.
 	ClientVersion() string
 	// Close releases any resources held by the client.
@@ -109,6 +112,11 @@ func (c *socketClient) DiskUsage(ctx context.Context, options types.DiskUsageOpt
 	return c.client.DiskUsage(ctx, options)
 }
 
+// Events streams the events of the Docker server based on the specified options.
+func (c *socketClient) Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error) {
+	return c.client.Events(ctx, options)
+}
+
 // ClientVersion retrieves the version of the Docker client.
 func (c *socketClient) ClientVersion() string {
 	return c.client.ClientVersion()
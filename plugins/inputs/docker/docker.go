@@ -77,6 +77,11 @@ type Docker struct {
 
 	IncludeSourceTag bool `toml:"source_tag"`
 
+	ContainerHealthTag bool `toml:"container_health_tag"`
+
+	GatherEvents bool     `toml:"gather_events"`
+	EventActions []string `toml:"event_actions"`
+
 	Log telegraf.Logger `toml:"-"`
 
 	common_tls.ClientConfig
@@ -92,6 +97,12 @@ type Docker struct {
 	containerFilter filter.Filter
 	stateFilter     filter.Filter
 	objectTypes     []types.DiskUsageObject
+
+	// Event streaming
+	cancelEvents context.CancelFunc
+	eventsDone   chan struct{}
+	oomMu        sync.Mutex
+	oomKills     map[string]int64
 }
 
 func (*Docker) SampleConfig() string {
@@ -124,6 +135,8 @@ func (d *Docker) Init() error {
 		}
 	}
 
+	d.oomKills = make(map[string]int64)
+
 	return nil
 }
 
@@ -549,6 +562,9 @@ func (d *Docker) gatherContainerInspect(
 
 	if info.State != nil {
 		tags["container_status"] = info.State.Status
+		if d.ContainerHealthTag && info.State.Health != nil {
+			tags["container_health"] = info.State.Health.Status
+		}
 		statefields := map[string]interface{}{
 			"oomkilled":     info.State.OOMKilled,
 			"pid":           info.State.Pid,
@@ -556,6 +572,9 @@ func (d *Docker) gatherContainerInspect(
 			"restart_count": info.RestartCount,
 			"container_id":  cntnr.ID,
 		}
+		if d.GatherEvents {
+			statefields["oom_kills"] = d.oomKillCount(cntnr.ID)
+		}
 
 		finished, err := time.Parse(time.RFC3339, info.State.FinishedAt)
 		if err == nil && !finished.IsZero() {
@@ -1052,6 +1071,10 @@ func init() {
 			newEnvClient:     newEnvClient,
 			newClient:        newClient,
 			filtersCreated:   false,
+			EventActions: []string{
+				"create", "start", "stop", "restart", "die", "kill", "oom",
+				"pause", "unpause", "destroy", "health_status",
+			},
 		}
 	})
 }
@@ -12,12 +12,15 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/swarm"
 	"github.com/docker/docker/api/types/system"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -30,6 +33,7 @@ type mockClient struct {
 	TaskListF         func() ([]swarm.Task, error)
 	NodeListF         func() ([]swarm.Node, error)
 	DiskUsageF        func() (types.DiskUsage, error)
+	EventsF           func() (<-chan events.Message, <-chan error)
 	ClientVersionF    func() string
 	CloseF            func() error
 }
@@ -66,6 +70,10 @@ func (c *mockClient) DiskUsage(context.Context, types.DiskUsageOptions) (types.D
 	return c.DiskUsageF()
 }
 
+func (c *mockClient) Events(context.Context, events.ListOptions) (<-chan events.Message, <-chan error) {
+	return c.EventsF()
+}
+
 func (c *mockClient) ClientVersion() string {
 	return c.ClientVersionF()
 }
@@ -910,6 +918,176 @@ func TestContainerStatus(t *testing.T) {
 	}
 }
 
+func TestContainerHealthTag(t *testing.T) {
+	newClientFunc := func(string, *tls.Config) (dockerClient, error) {
+		client := baseClient
+		client.ContainerListF = func(container.ListOptions) ([]container.Summary, error) {
+			return containerList[:1], nil
+		}
+		return &client, nil
+	}
+
+	var acc testutil.Accumulator
+	d := Docker{
+		Log:                testutil.Logger{},
+		newClient:          newClientFunc,
+		ContainerHealthTag: true,
+	}
+	require.NoError(t, d.Init())
+	require.NoError(t, d.Gather(&acc))
+
+	var found bool
+	for _, m := range acc.GetTelegrafMetrics() {
+		if !strings.HasPrefix(m.Name(), "docker_container_") {
+			continue
+		}
+		found = true
+		status, ok := m.GetTag("container_health")
+		require.Truef(t, ok, "missing health tag in %q", m.Name())
+		require.Equal(t, "Unhealthy", status)
+	}
+	require.True(t, found, "no container metrics found")
+}
+
+func TestGatherEvents(t *testing.T) {
+	const id = "e2173b9478a6ae55e237d4d74f8bbb753f0817192b5081334dc78476296b7dfb"
+	attributes := map[string]string{"name": "etcd", "image": "quay.io/coreos/etcd:v3.3.25"}
+	messages := []events.Message{
+		{
+			Type:     events.ContainerEventType,
+			Action:   events.ActionExecStart,
+			Actor:    events.Actor{ID: id, Attributes: attributes},
+			TimeNano: 1,
+		},
+		{
+			Type:     events.ContainerEventType,
+			Action:   events.ActionOOM,
+			Actor:    events.Actor{ID: id, Attributes: attributes},
+			TimeNano: 2,
+		},
+		{
+			Type:   events.ContainerEventType,
+			Action: events.ActionKill,
+			Actor: events.Actor{
+				ID:         id,
+				Attributes: map[string]string{"name": "etcd", "image": "quay.io/coreos/etcd:v3.3.25", "signal": "9"},
+			},
+			TimeNano: 3,
+		},
+		{
+			Type:   events.ContainerEventType,
+			Action: events.ActionDie,
+			Actor: events.Actor{
+				ID:         id,
+				Attributes: map[string]string{"name": "etcd", "image": "quay.io/coreos/etcd:v3.3.25", "exitCode": "137"},
+			},
+			TimeNano: 4,
+		},
+		{
+			Type:     events.ContainerEventType,
+			Action:   events.ActionHealthStatusUnhealthy,
+			Actor:    events.Actor{ID: id, Attributes: attributes},
+			TimeNano: 5,
+		},
+		{
+			Type:     events.ContainerEventType,
+			Action:   events.ActionStart,
+			Actor:    events.Actor{ID: "ignored", Attributes: map[string]string{"name": "ignored", "image": "alpine"}},
+			TimeNano: 6,
+		},
+	}
+
+	newClientFunc := func(string, *tls.Config) (dockerClient, error) {
+		client := baseClient
+		client.ContainerListF = func(container.ListOptions) ([]container.Summary, error) {
+			return containerList[:1], nil
+		}
+		client.EventsF = func() (<-chan events.Message, <-chan error) {
+			msgs := make(chan events.Message, len(messages))
+			for _, m := range messages {
+				msgs <- m
+			}
+			return msgs, make(chan error)
+		}
+		return &client, nil
+	}
+
+	plugin := &Docker{
+		Log:              testutil.Logger{},
+		newClient:        newClientFunc,
+		Timeout:          config.Duration(5 * time.Second),
+		ContainerExclude: []string{"ignored"},
+		GatherEvents:     true,
+		EventActions:     []string{"oom", "kill", "die", "health_status", "start"},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	tags := map[string]string{
+		"engine_host":       "absol",
+		"server_version":    "17.09.0-ce",
+		"container_name":    "etcd",
+		"container_image":   "quay.io/coreos/etcd",
+		"container_version": "v3.3.25",
+	}
+	withAction := func(action string) map[string]string {
+		m := make(map[string]string, len(tags)+1)
+		for k, v := range tags {
+			m[k] = v
+		}
+		m["action"] = action
+		return m
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"docker_container_event",
+			withAction("oom"),
+			map[string]interface{}{"container_id": id},
+			time.Unix(0, 2),
+		),
+		metric.New(
+			"docker_container_event",
+			withAction("kill"),
+			map[string]interface{}{"container_id": id, "signal": "9"},
+			time.Unix(0, 3),
+		),
+		metric.New(
+			"docker_container_event",
+			withAction("die"),
+			map[string]interface{}{"container_id": id, "exitcode": int64(137)},
+			time.Unix(0, 4),
+		),
+		metric.New(
+			"docker_container_event",
+			withAction("health_status"),
+			map[string]interface{}{"container_id": id, "health_status": "unhealthy"},
+			time.Unix(0, 5),
+		),
+	}
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= uint64(len(expected))
+	}, 3*time.Second, 100*time.Millisecond)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// The observed OOM kills should be reported with the container status
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	var found bool
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() != "docker_container_status" {
+			continue
+		}
+		found = true
+		count, ok := m.GetField("oom_kills")
+		require.True(t, ok, "missing OOM-kill count")
+		require.Equal(t, int64(1), count)
+	}
+	require.True(t, found, "no status metric found")
+}
+
 func TestDockerGatherInfo(t *testing.T) {
 	var acc testutil.Accumulator
 	d := Docker{
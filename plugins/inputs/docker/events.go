@@ -0,0 +1,162 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/docker"
+)
+
+// Delay before reconnecting to the event stream after an error
+var eventReconnectDelay = 5 * time.Second
+
+func (d *Docker) Start(acc telegraf.Accumulator) error {
+	if !d.GatherEvents {
+		return nil
+	}
+
+	// Use dedicated filters and client to not interfere with gathering
+	containerFilter, err := filter.NewIncludeExcludeFilter(d.ContainerInclude, d.ContainerExclude)
+	if err != nil {
+		return fmt.Errorf("creating container filter failed: %w", err)
+	}
+	actionFilter, err := filter.Compile(d.EventActions)
+	if err != nil {
+		return fmt.Errorf("creating event action filter failed: %w", err)
+	}
+	client, err := d.getNewClient()
+	if err != nil {
+		return fmt.Errorf("creating event client failed: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancelEvents = cancel
+	d.eventsDone = make(chan struct{})
+	go func() {
+		defer close(d.eventsDone)
+		defer client.Close()
+		d.streamEvents(ctx, client, acc, containerFilter, actionFilter)
+	}()
+
+	return nil
+}
+
+func (d *Docker) Stop() {
+	if d.cancelEvents != nil {
+		d.cancelEvents()
+		<-d.eventsDone
+	}
+}
+
+// streamEvents reports the container events of the daemon until the context
+// is cancelled and reconnects to the daemon on errors
+func (d *Docker) streamEvents(ctx context.Context, client dockerClient, acc telegraf.Accumulator, containerFilter, actionFilter filter.Filter) {
+	options := events.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("type", string(events.ContainerEventType))),
+	}
+
+	for {
+		infoCtx, cancel := context.WithTimeout(ctx, time.Duration(d.Timeout))
+		info, err := client.Info(infoCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			acc.AddError(fmt.Errorf("getting engine info for events failed: %w", err))
+		} else {
+			messages, errs := client.Events(ctx, options)
+			err = d.processEvents(ctx, acc, messages, errs, info.Name, info.ServerVersion, containerFilter, actionFilter)
+			if ctx.Err() != nil {
+				return
+			}
+			acc.AddError(fmt.Errorf("streaming events failed: %w", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(eventReconnectDelay):
+		}
+	}
+}
+
+func (d *Docker) processEvents(
+	ctx context.Context,
+	acc telegraf.Accumulator,
+	messages <-chan events.Message,
+	errs <-chan error,
+	engineHost, serverVersion string,
+	containerFilter, actionFilter filter.Filter,
+) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case msg := <-messages:
+			name := msg.Actor.Attributes["name"]
+			if name == "" || !containerFilter.Match(name) {
+				continue
+			}
+
+			// Some actions carry details, e.g. "health_status: healthy"
+			action, detail, _ := strings.Cut(string(msg.Action), ": ")
+			if action == string(events.ActionOOM) {
+				d.oomMu.Lock()
+				d.oomKills[msg.Actor.ID]++
+				d.oomMu.Unlock()
+			}
+			if actionFilter != nil && !actionFilter.Match(action) {
+				continue
+			}
+
+			imageName, imageVersion := docker.ParseImage(msg.Actor.Attributes["image"])
+			tags := map[string]string{
+				"engine_host":       engineHost,
+				"server_version":    serverVersion,
+				"container_name":    name,
+				"container_image":   imageName,
+				"container_version": imageVersion,
+				"action":            action,
+			}
+			if d.IncludeSourceTag {
+				tags["source"] = hostnameFromID(msg.Actor.ID)
+			}
+			fields := map[string]interface{}{
+				"container_id": msg.Actor.ID,
+			}
+			switch events.Action(action) {
+			case events.ActionDie:
+				if code, err := strconv.ParseInt(msg.Actor.Attributes["exitCode"], 10, 64); err == nil {
+					fields["exitcode"] = code
+				}
+			case events.ActionKill:
+				if signal, found := msg.Actor.Attributes["signal"]; found {
+					fields["signal"] = signal
+				}
+			case events.ActionHealthStatus:
+				fields["health_status"] = detail
+			}
+
+			acc.AddFields("docker_container_event", fields, tags, time.Unix(0, msg.TimeNano))
+		}
+	}
+}
+
+// oomKillCount returns the number of OOM kills of the given container
+// observed in the event stream
+func (d *Docker) oomKillCount(id string) int64 {
+	d.oomMu.Lock()
+	defer d.oomMu.Unlock()
+	return d.oomKills[id]
+}
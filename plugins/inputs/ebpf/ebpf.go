@@ -0,0 +1,378 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package ebpf
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/rlimit"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var availableProbes = []string{"tcp_retransmit", "tcp_connect", "exec"}
+
+type EBPF struct {
+	Probes                 []string        `toml:"probes"`
+	TCPRetransmitPerRemote bool            `toml:"tcp_retransmit_per_remote"`
+	ShortLivedThreshold    config.Duration `toml:"short_lived_threshold"`
+	ExecPerCommand         bool            `toml:"exec_per_command"`
+	Log                    telegraf.Logger `toml:"-"`
+
+	retransmits *ebpf.Map
+	connects    *ebpf.Map
+	execs       *ebpf.Map
+	shortLived  *ebpf.Map
+	resources   []io.Closer
+}
+
+func (*EBPF) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *EBPF) Init() error {
+	if len(e.Probes) == 0 {
+		e.Probes = availableProbes
+	}
+	if err := choice.CheckSlice(e.Probes, availableProbes); err != nil {
+		return fmt.Errorf("config option probes: %w", err)
+	}
+	if e.ShortLivedThreshold <= 0 {
+		return errors.New("'short_lived_threshold' must be positive")
+	}
+	return nil
+}
+
+func (e *EBPF) Start(telegraf.Accumulator) error {
+	// Kernels before v5.11 account the memory of eBPF objects against the
+	// locked memory limit
+	if err := rlimit.RemoveMemlock(); err != nil {
+		e.Log.Debugf("Removing memlock limit failed: %v", err)
+	}
+
+	r, err := newResolver()
+	if err != nil {
+		return err
+	}
+	if r.spec == nil {
+		e.Log.Info("Kernel BTF not available, using tracefs format descriptions")
+	}
+
+	// Disable probes that cannot be loaded e.g. due to missing tracepoints
+	// and only fail if none of the probes is available
+	for _, probe := range e.Probes {
+		var err error
+		switch probe {
+		case "tcp_retransmit":
+			err = e.startRetransmit(r)
+		case "tcp_connect":
+			err = e.startConnect(r)
+		case "exec":
+			err = e.startExec(r)
+		}
+		if err != nil {
+			e.Log.Warnf("Disabling probe %q: %v", probe, err)
+		}
+	}
+	if e.retransmits == nil && e.connects == nil && e.execs == nil {
+		e.Stop()
+		return errors.New("none of the probes could be started")
+	}
+
+	return nil
+}
+
+func (e *EBPF) Stop() {
+	// Close the links before the programs and maps
+	for i := len(e.resources) - 1; i >= 0; i-- {
+		e.resources[i].Close()
+	}
+	e.resources = nil
+	e.retransmits = nil
+	e.connects = nil
+	e.execs = nil
+	e.shortLived = nil
+}
+
+func (e *EBPF) Gather(acc telegraf.Accumulator) error {
+	if e.retransmits != nil {
+		if err := e.gatherRetransmits(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering TCP retransmits failed: %w", err))
+		}
+	}
+	if e.connects != nil {
+		if err := e.gatherConnects(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering TCP connects failed: %w", err))
+		}
+	}
+	if e.execs != nil {
+		if err := e.gatherExecs(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering process executions failed: %w", err))
+		}
+	}
+	return nil
+}
+
+func (e *EBPF) startRetransmit(r *resolver) error {
+	l, err := r.layout("tcp", "tcp_retransmit_skb")
+	if err != nil {
+		return err
+	}
+
+	counts, err := e.newMap(ebpf.Hash, retransmitKeyLength, maxEntries)
+	if err != nil {
+		return err
+	}
+	insns, err := retransmitProgram(l, counts)
+	if err != nil {
+		return err
+	}
+	if err := e.attach("tcp", "tcp_retransmit_skb", insns); err != nil {
+		return err
+	}
+	e.retransmits = counts
+
+	return nil
+}
+
+func (e *EBPF) startConnect(r *resolver) error {
+	l, err := r.layout("sock", "inet_sock_set_state")
+	if err != nil {
+		return err
+	}
+
+	starts, err := e.newMap(ebpf.Hash, 8, maxEntries)
+	if err != nil {
+		return err
+	}
+	stats, err := e.newMap(ebpf.Array, 4, connectStatsCount)
+	if err != nil {
+		return err
+	}
+	insns, err := connectProgram(l, starts, stats)
+	if err != nil {
+		return err
+	}
+	if err := e.attach("sock", "inet_sock_set_state", insns); err != nil {
+		return err
+	}
+	e.connects = stats
+
+	return nil
+}
+
+func (e *EBPF) startExec(r *resolver) error {
+	execLayout, err := r.layout("sched", "sched_process_exec")
+	if err != nil {
+		return err
+	}
+	exitLayout, err := r.layout("sched", "sched_process_exit")
+	if err != nil {
+		return err
+	}
+
+	starts, err := e.newMap(ebpf.Hash, 4, maxEntries)
+	if err != nil {
+		return err
+	}
+	stats, err := e.newMap(ebpf.Array, 4, 1)
+	if err != nil {
+		return err
+	}
+	shortLived, err := e.newMap(ebpf.Hash, commLength, maxCommands)
+	if err != nil {
+		return err
+	}
+
+	insns, err := execProgram(execLayout, starts, stats)
+	if err != nil {
+		return err
+	}
+	if err := e.attach("sched", "sched_process_exec", insns); err != nil {
+		return err
+	}
+	insns, err = exitProgram(exitLayout, starts, shortLived, time.Duration(e.ShortLivedThreshold))
+	if err != nil {
+		return err
+	}
+	if err := e.attach("sched", "sched_process_exit", insns); err != nil {
+		return err
+	}
+	e.execs = stats
+	e.shortLived = shortLived
+
+	return nil
+}
+
+// newMap creates a map with the given key size and counters as values
+func (e *EBPF) newMap(typ ebpf.MapType, keySize, entries uint32) (*ebpf.Map, error) {
+	m, err := ebpf.NewMap(&ebpf.MapSpec{
+		Type:       typ,
+		KeySize:    keySize,
+		ValueSize:  8,
+		MaxEntries: entries,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating map failed: %w", err)
+	}
+	e.resources = append(e.resources, m)
+	return m, nil
+}
+
+// attach loads the program and attaches it to the given tracepoint
+func (e *EBPF) attach(group, name string, insns asm.Instructions) error {
+	prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+		Name:         name,
+		Type:         ebpf.TracePoint,
+		License:      "GPL",
+		Instructions: insns,
+	})
+	if err != nil {
+		return fmt.Errorf("loading program failed: %w", err)
+	}
+	e.resources = append(e.resources, prog)
+
+	l, err := link.Tracepoint(group, name, prog, nil)
+	if err != nil {
+		return fmt.Errorf("attaching to tracepoint %s/%s failed: %w", group, name, err)
+	}
+	e.resources = append(e.resources, l)
+
+	return nil
+}
+
+func (e *EBPF) gatherRetransmits(acc telegraf.Accumulator) error {
+	type remote struct {
+		family string
+		addr   string
+		port   uint16
+	}
+	counts := make(map[remote]uint64)
+
+	var key [retransmitKeyLength]byte
+	var value uint64
+	iter := e.retransmits.Iterate()
+	for iter.Next(&key, &value) {
+		var r remote
+		switch binary.NativeEndian.Uint16(key[18:]) {
+		case afInet:
+			r.family = "ipv4"
+			if e.TCPRetransmitPerRemote {
+				r.addr = net.IP(key[:4]).String()
+			}
+		case afInet6:
+			r.family = "ipv6"
+			if e.TCPRetransmitPerRemote {
+				r.addr = net.IP(key[:16]).String()
+			}
+		default:
+			continue
+		}
+		if e.TCPRetransmitPerRemote {
+			r.port = binary.NativeEndian.Uint16(key[16:])
+		}
+		counts[r] += value
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	for r, count := range counts {
+		tags := map[string]string{"family": r.family}
+		if e.TCPRetransmitPerRemote {
+			tags["remote_address"] = r.addr
+			tags["remote_port"] = strconv.Itoa(int(r.port))
+		}
+		acc.AddCounter("ebpf_tcp_retransmit", map[string]interface{}{"retransmits": count}, tags)
+	}
+
+	return nil
+}
+
+func (e *EBPF) gatherConnects(acc telegraf.Accumulator) error {
+	stats := make([]uint64, connectStatsCount)
+	for i := range stats {
+		if err := e.connects.Lookup(uint32(i), &stats[i]); err != nil {
+			return err
+		}
+	}
+
+	// Report the histogram with cumulative buckets, the upper bound of the
+	// bucket of slot i is 2^(i+1) microseconds
+	now := time.Now()
+	var count uint64
+	for i, v := range stats[:connectSlots] {
+		count += v
+		le := "+Inf"
+		if i < connectSlots-1 {
+			le = strconv.FormatUint(1<<(i+1), 10)
+		}
+		acc.AddCounter("ebpf_tcp_connect", map[string]interface{}{"latency_bucket": count}, map[string]string{"le": le}, now)
+	}
+
+	fields := map[string]interface{}{
+		"established":    count,
+		"failed":         stats[connectFailIndex],
+		"latency_sum_us": stats[connectSumIndex],
+	}
+	acc.AddCounter("ebpf_tcp_connect", fields, nil, now)
+
+	return nil
+}
+
+func (e *EBPF) gatherExecs(acc telegraf.Accumulator) error {
+	var execs uint64
+	if err := e.execs.Lookup(uint32(0), &execs); err != nil {
+		return err
+	}
+
+	commands := make(map[string]uint64)
+	var total uint64
+	var key [commLength]byte
+	var value uint64
+	iter := e.shortLived.Iterate()
+	for iter.Next(&key, &value) {
+		command, _, _ := bytes.Cut(key[:], []byte{0})
+		commands[string(command)] += value
+		total += value
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	acc.AddCounter("ebpf_exec", map[string]interface{}{"execs": execs, "short_lived": total}, nil, now)
+	if e.ExecPerCommand {
+		for command, count := range commands {
+			acc.AddCounter("ebpf_exec", map[string]interface{}{"short_lived": count}, map[string]string{"command": command}, now)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	inputs.Add("ebpf", func() telegraf.Input {
+		return &EBPF{
+			ShortLivedThreshold: config.Duration(time.Second),
+		}
+	})
+}
@@ -0,0 +1,37 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package ebpf
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type EBPF struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*EBPF) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *EBPF) Init() error {
+	e.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*EBPF) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func init() {
+	inputs.Add("ebpf", func() telegraf.Input {
+		return &EBPF{}
+	})
+}
@@ -0,0 +1,175 @@
+//go:build linux
+
+package ebpf
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *EBPF
+		expected string
+	}{
+		{
+			name:     "invalid probe",
+			plugin:   &EBPF{Probes: []string{"foo"}, ShortLivedThreshold: config.Duration(time.Second)},
+			expected: "config option probes",
+		},
+		{
+			name:     "invalid threshold",
+			plugin:   &EBPF{},
+			expected: "'short_lived_threshold' must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = &testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected map[string]field
+	}{
+		{
+			name: "tcp_retransmit_skb",
+			expected: map[string]field{
+				"family":   {offset: 32, size: 2},
+				"dport":    {offset: 30, size: 2},
+				"daddr":    {offset: 38, size: 4},
+				"daddr_v6": {offset: 58, size: 16},
+			},
+		},
+		{
+			name: "inet_sock_set_state",
+			expected: map[string]field{
+				"skaddr":   {offset: 8, size: 8},
+				"oldstate": {offset: 16, size: 4},
+				"newstate": {offset: 20, size: 4},
+				"protocol": {offset: 30, size: 2},
+			},
+		},
+		{
+			name: "sched_process_exec",
+			expected: map[string]field{
+				"pid": {offset: 12, size: 4},
+			},
+		},
+		{
+			name: "sched_process_exit",
+			expected: map[string]field{
+				"comm": {offset: 8, size: 16},
+				"pid":  {offset: 24, size: 4},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", tt.name+".format"))
+			require.NoError(t, err)
+			defer f.Close()
+
+			l, err := parseFormat(bufio.NewScanner(f))
+			require.NoError(t, err)
+			for name, expected := range tt.expected {
+				require.Equalf(t, expected, l[name], "field %q", name)
+			}
+		})
+	}
+}
+
+func TestLayoutRequire(t *testing.T) {
+	l := layout{
+		"pid":  {offset: 24, size: 4},
+		"comm": {offset: 8, size: 16},
+	}
+	require.NoError(t, l.require(map[string]int{"pid": 4, "comm": 16}))
+	require.ErrorContains(t, l.require(map[string]int{"pid": 8}), `unexpected size 4 of field "pid", expected 8`)
+	require.ErrorContains(t, l.require(map[string]int{"family": 2}), `missing field "family"`)
+}
+
+func TestProbes(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("Skipping test as loading eBPF programs requires root privileges")
+	}
+
+	plugin := &EBPF{
+		ShortLivedThreshold: config.Duration(time.Minute),
+		ExecPerCommand:      true,
+		Log:                 testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	if err := plugin.Start(&acc); err != nil {
+		t.Skipf("Skipping test as eBPF programs cannot be loaded: %v", err)
+	}
+	defer plugin.Stop()
+	require.NotNil(t, plugin.connects, "tcp_connect probe not loaded")
+	require.NotNil(t, plugin.execs, "exec probe not loaded")
+
+	// Cause a successful and a failed connection
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	conn.Close()
+	addr := listener.Addr().String()
+	listener.Close()
+	_, err = net.Dial("tcp", addr)
+	require.Error(t, err)
+
+	// Cause a short-lived process
+	path, err := exec.LookPath("true")
+	if errors.Is(err, exec.ErrNotFound) {
+		t.Skip("Skipping test as 'true' is not available")
+	}
+	require.NoError(t, exec.Command(path).Run())
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	var connects, failed, execs, shortLived uint64
+	for _, m := range acc.GetTelegrafMetrics() {
+		switch m.Name() {
+		case "ebpf_tcp_connect":
+			if v, ok := m.GetField("established"); ok {
+				connects = v.(uint64)
+			}
+			if v, ok := m.GetField("failed"); ok {
+				failed = v.(uint64)
+			}
+		case "ebpf_exec":
+			if m.HasTag("command") {
+				if cmd, _ := m.GetTag("command"); cmd == "true" {
+					shortLived = m.Fields()["short_lived"].(uint64)
+				}
+				continue
+			}
+			execs = m.Fields()["execs"].(uint64)
+		}
+	}
+	require.GreaterOrEqual(t, connects, uint64(1))
+	require.GreaterOrEqual(t, failed, uint64(1))
+	require.GreaterOrEqual(t, execs, uint64(1))
+	require.GreaterOrEqual(t, shortLived, uint64(1))
+}
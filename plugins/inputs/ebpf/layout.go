@@ -0,0 +1,162 @@
+//go:build linux
+
+package ebpf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/cilium/ebpf/btf"
+)
+
+// Locations of the tracing filesystem, the latter is used on older kernels
+var tracefsPaths = []string{"/sys/kernel/tracing", "/sys/kernel/debug/tracing"}
+
+// field describes the location of a field in a tracepoint record
+type field struct {
+	offset int
+	size   int
+}
+
+// layout maps the field names of a tracepoint record to their location
+type layout map[string]field
+
+// require checks the existence and size of the given fields
+func (l layout) require(sizes map[string]int) error {
+	for name, size := range sizes {
+		f, found := l[name]
+		if !found {
+			return fmt.Errorf("missing field %q", name)
+		}
+		if f.size != size {
+			return fmt.Errorf("unexpected size %d of field %q, expected %d", f.size, name, size)
+		}
+	}
+	return nil
+}
+
+// resolver determines the record layout of tracepoints using the kernel's
+// BTF information if available and the format descriptions of the tracing
+// filesystem otherwise
+type resolver struct {
+	spec    *btf.Spec
+	tracefs string
+}
+
+func newResolver() (*resolver, error) {
+	r := &resolver{}
+
+	// Kernel BTF information is optional
+	spec, btfErr := btf.LoadKernelSpec()
+	if btfErr == nil {
+		r.spec = spec
+	}
+
+	for _, path := range tracefsPaths {
+		if _, err := os.Stat(filepath.Join(path, "events")); err == nil {
+			r.tracefs = path
+			break
+		}
+	}
+
+	if r.spec == nil && r.tracefs == "" {
+		return nil, fmt.Errorf("neither kernel BTF nor tracefs available: %w", btfErr)
+	}
+	return r, nil
+}
+
+func (r *resolver) layout(group, name string) (layout, error) {
+	if r.spec != nil {
+		l, err := r.fromBTF(name)
+		if err == nil {
+			return l, nil
+		}
+		if r.tracefs == "" {
+			return nil, err
+		}
+	}
+
+	f, err := os.Open(filepath.Join(r.tracefs, "events", group, name, "format"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseFormat(bufio.NewScanner(f))
+}
+
+// fromBTF determines the layout from the type describing the raw record of
+// the tracepoint
+func (r *resolver) fromBTF(name string) (layout, error) {
+	var s *btf.Struct
+	if err := r.spec.TypeByName("trace_event_raw_"+name, &s); err != nil {
+		return nil, err
+	}
+
+	l := make(layout, len(s.Members))
+	for _, m := range s.Members {
+		size, err := btf.Sizeof(m.Type)
+		if err != nil {
+			return nil, fmt.Errorf("determining size of field %q failed: %w", m.Name, err)
+		}
+		l[m.Name] = field{offset: int(m.Offset.Bytes()), size: size}
+	}
+	return l, nil
+}
+
+// parseFormat parses a tracepoint format description containing lines like
+//
+//	field:__u16 family;	offset:32;	size:2;	signed:0;
+func parseFormat(scanner *bufio.Scanner) (layout, error) {
+	l := make(layout)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "field:") {
+			continue
+		}
+
+		var name string
+		f := field{offset: -1, size: -1}
+		for _, part := range strings.Split(line, ";") {
+			key, value, found := strings.Cut(strings.TrimSpace(part), ":")
+			if !found {
+				continue
+			}
+			switch key {
+			case "field":
+				// The name is the last word of the declaration, arrays carry
+				// their length as suffix
+				decl := strings.Fields(value)
+				name, _, _ = strings.Cut(decl[len(decl)-1], "[")
+			case "offset":
+				v, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("parsing offset in %q failed: %w", line, err)
+				}
+				f.offset = v
+			case "size":
+				v, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("parsing size in %q failed: %w", line, err)
+				}
+				f.size = v
+			}
+		}
+		if name == "" || f.offset < 0 || f.size < 0 {
+			return nil, fmt.Errorf("invalid field description %q", line)
+		}
+		l[name] = f
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(l) == 0 {
+		return nil, errors.New("no fields found")
+	}
+	return l, nil
+}
@@ -0,0 +1,326 @@
+//go:build linux
+
+package ebpf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+)
+
+// Constants of the kernel
+const (
+	afInet         = 2
+	afInet6        = 10
+	ipprotoTCP     = 6
+	tcpEstablished = 1
+	tcpSynSent     = 2
+	bpfAny         = 0
+	bpfNoExist     = 1
+	commLength     = 16
+)
+
+// Sizes of the maps
+const (
+	maxEntries          = 16384
+	maxCommands         = 1024
+	retransmitKeyLength = 20
+)
+
+// Indices of the connect statistics, the indices below are the slots of the
+// latency histogram holding the number of connections with a latency in
+// the range of [2^i, 2^(i+1)) microseconds
+const (
+	connectSlots      = 32
+	connectSumIndex   = connectSlots
+	connectFailIndex  = connectSlots + 1
+	connectStatsCount = connectSlots + 2
+)
+
+// Temporary register used when loading fields
+const tmp = asm.R9
+
+// The programs below are assembled at runtime using the record layout of the
+// running kernel's tracepoints so no compiler is required and the programs
+// are portable across kernel versions. The context is held in R6.
+
+// retransmitProgram counts the retransmitted TCP segments per remote address
+// and port in a hash map keyed by the address (16 bytes, IPv4 addresses
+// use the first four bytes), the port (2 bytes) and the family (2 bytes)
+func retransmitProgram(l layout, counts *ebpf.Map) (asm.Instructions, error) {
+	err := l.require(map[string]int{"family": 2, "dport": 2, "daddr": 4, "daddr_v6": 16})
+	if err != nil {
+		return nil, err
+	}
+
+	insns := asm.Instructions{
+		asm.Mov.Reg(asm.R6, asm.R1),
+		asm.StoreImm(asm.RFP, -24, 0, asm.DWord),
+		asm.StoreImm(asm.RFP, -16, 0, asm.DWord),
+		asm.StoreImm(asm.RFP, -8, 0, asm.DWord),
+	}
+	insns = append(insns, load(asm.R7, l["family"])...)
+	insns = append(insns,
+		asm.JEq.Imm(asm.R7, afInet, "ipv4"),
+		asm.JNE.Imm(asm.R7, afInet6, "exit"),
+	)
+	insns = append(insns, copyBytes(l["daddr_v6"], -24)...)
+	insns = append(insns, asm.Ja.Label("key"))
+	insns = append(insns, label("ipv4", copyBytes(l["daddr"], -24))...)
+	insns = append(insns, label("key", copyBytes(l["dport"], -8))...)
+	insns = append(insns, asm.StoreMem(asm.RFP, -6, asm.R7, asm.Half))
+	insns = append(insns, increment(counts, -24, "count")...)
+	insns = append(insns, exit()...)
+
+	return insns, nil
+}
+
+// connectProgram tracks the state changes of TCP sockets to measure the
+// latency from sending the SYN to the connection being established
+func connectProgram(l layout, starts, stats *ebpf.Map) (asm.Instructions, error) {
+	err := l.require(map[string]int{"protocol": 2, "skaddr": 8, "oldstate": 4, "newstate": 4})
+	if err != nil {
+		return nil, err
+	}
+
+	insns := asm.Instructions{asm.Mov.Reg(asm.R6, asm.R1)}
+	insns = append(insns, load(asm.R7, l["protocol"])...)
+	insns = append(insns, asm.JNE.Imm(asm.R7, ipprotoTCP, "exit"))
+	insns = append(insns, load(asm.R7, l["skaddr"])...)
+	insns = append(insns, asm.StoreMem(asm.RFP, -8, asm.R7, asm.DWord))
+	insns = append(insns, load(asm.R7, l["newstate"])...)
+	insns = append(insns, load(asm.R8, l["oldstate"])...)
+
+	// Remember the time of sending the SYN
+	insns = append(insns,
+		asm.JNE.Imm(asm.R7, tcpSynSent, "state"),
+		asm.FnKtimeGetNs.Call(),
+		asm.StoreMem(asm.RFP, -16, asm.R0, asm.DWord),
+		asm.LoadMapPtr(asm.R1, starts.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -8),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, -16),
+		asm.Mov.Imm(asm.R4, bpfAny),
+		asm.FnMapUpdateElem.Call(),
+		asm.Ja.Label("exit"),
+	)
+
+	// Leaving the SYN-SENT state either establishes the connection or the
+	// connection attempt failed
+	insns = append(insns,
+		asm.JNE.Imm(asm.R8, tcpSynSent, "exit").WithSymbol("state"),
+		asm.Mov.Reg(asm.R8, asm.R7),
+		asm.LoadMapPtr(asm.R1, starts.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -8),
+		asm.FnMapLookupElem.Call(),
+		asm.JEq.Imm(asm.R0, 0, "exit"),
+		asm.LoadMem(asm.R7, asm.R0, 0, asm.DWord),
+		asm.LoadMapPtr(asm.R1, starts.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -8),
+		asm.FnMapDeleteElem.Call(),
+		asm.JNE.Imm(asm.R8, tcpEstablished, "failed"),
+		asm.FnKtimeGetNs.Call(),
+		asm.Sub.Reg(asm.R0, asm.R7),
+		asm.Div.Imm(asm.R0, 1000),
+		asm.Mov.Reg(asm.R7, asm.R0),
+	)
+	insns = append(insns, add(stats, connectSumIndex, asm.R7, "sum")...)
+
+	// Determine the histogram slot as the binary logarithm of the latency
+	// limited to the last slot
+	insns = append(insns,
+		asm.Mov.Imm(asm.R8, 0),
+		asm.LoadImm(tmp, 1<<32-1, asm.DWord),
+		asm.JLE.Reg(asm.R7, tmp, "log2_0"),
+		asm.Mov.Reg(asm.R7, tmp),
+	)
+	for i, shift := range []int32{16, 8, 4, 2, 1} {
+		insns = append(insns,
+			asm.JLT.Imm(asm.R7, 1<<shift, fmt.Sprintf("log2_%d", i+1)).WithSymbol(fmt.Sprintf("log2_%d", i)),
+			asm.RSh.Imm(asm.R7, shift),
+			asm.Add.Imm(asm.R8, shift),
+		)
+	}
+	insns = append(insns, asm.Mov.Imm(asm.R7, 1).WithSymbol("log2_5"))
+	insns = append(insns, addIndex(stats, asm.R8, asm.R7, "slot")...)
+	insns = append(insns, asm.Ja.Label("exit"))
+
+	insns = append(insns, label("failed", asm.Instructions{asm.Mov.Imm(asm.R7, 1)})...)
+	insns = append(insns, add(stats, connectFailIndex, asm.R7, "fail")...)
+	insns = append(insns, exit()...)
+
+	return insns, nil
+}
+
+// execProgram counts the executed processes and remembers their start time
+func execProgram(l layout, starts, stats *ebpf.Map) (asm.Instructions, error) {
+	if err := l.require(map[string]int{"pid": 4}); err != nil {
+		return nil, err
+	}
+
+	insns := asm.Instructions{
+		asm.Mov.Reg(asm.R6, asm.R1),
+		asm.Mov.Imm(asm.R7, 1),
+	}
+	insns = append(insns, add(stats, 0, asm.R7, "count")...)
+	insns = append(insns, load(asm.R7, l["pid"])...)
+	insns = append(insns,
+		asm.StoreMem(asm.RFP, -4, asm.R7, asm.Word),
+		asm.FnKtimeGetNs.Call(),
+		asm.StoreMem(asm.RFP, -16, asm.R0, asm.DWord),
+		asm.LoadMapPtr(asm.R1, starts.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -4),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, -16),
+		asm.Mov.Imm(asm.R4, bpfAny),
+		asm.FnMapUpdateElem.Call(),
+	)
+	insns = append(insns, exit()...)
+
+	return insns, nil
+}
+
+// exitProgram counts the processes exiting within the threshold after being
+// executed per command name
+func exitProgram(l layout, starts, shortLived *ebpf.Map, threshold time.Duration) (asm.Instructions, error) {
+	if err := l.require(map[string]int{"pid": 4, "comm": commLength}); err != nil {
+		return nil, err
+	}
+
+	insns := asm.Instructions{asm.Mov.Reg(asm.R6, asm.R1)}
+	insns = append(insns, load(asm.R7, l["pid"])...)
+	insns = append(insns,
+		asm.StoreMem(asm.RFP, -4, asm.R7, asm.Word),
+		asm.LoadMapPtr(asm.R1, starts.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -4),
+		asm.FnMapLookupElem.Call(),
+		asm.JEq.Imm(asm.R0, 0, "exit"),
+		asm.LoadMem(asm.R7, asm.R0, 0, asm.DWord),
+		asm.LoadMapPtr(asm.R1, starts.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -4),
+		asm.FnMapDeleteElem.Call(),
+		asm.FnKtimeGetNs.Call(),
+		asm.Sub.Reg(asm.R0, asm.R7),
+		asm.LoadImm(tmp, int64(threshold), asm.DWord),
+		asm.JGT.Reg(asm.R0, tmp, "exit"),
+	)
+	insns = append(insns, copyBytes(l["comm"], -32)...)
+	insns = append(insns, increment(shortLived, -32, "count")...)
+	insns = append(insns, exit()...)
+
+	return insns, nil
+}
+
+// load emits instructions to load the unsigned field of the context into the
+// destination register, fields not aligned to their size are composed of
+// single bytes as the verifier only allows aligned context accesses
+func load(dst asm.Register, f field) asm.Instructions {
+	var size asm.Size
+	switch f.size {
+	case 1:
+		size = asm.Byte
+	case 2:
+		size = asm.Half
+	case 4:
+		size = asm.Word
+	case 8:
+		size = asm.DWord
+	}
+	if f.offset%f.size == 0 {
+		return asm.Instructions{asm.LoadMem(dst, asm.R6, int16(f.offset), size)}
+	}
+
+	insns := asm.Instructions{asm.Mov.Imm(dst, 0)}
+	for i := range f.size {
+		shift := 8 * i
+		if binary.NativeEndian.Uint16([]byte{0, 1}) == 1 {
+			// Big endian
+			shift = 8 * (f.size - 1 - i)
+		}
+		insns = append(insns,
+			asm.LoadMem(tmp, asm.R6, int16(f.offset+i), asm.Byte),
+			asm.LSh.Imm(tmp, int32(shift)),
+			asm.Or.Reg(dst, tmp),
+		)
+	}
+	return insns
+}
+
+// copyBytes emits instructions copying the field of the context to the stack
+func copyBytes(f field, offset int16) asm.Instructions {
+	insns := make(asm.Instructions, 0, 2*f.size)
+	for i := range f.size {
+		insns = append(insns,
+			asm.LoadMem(tmp, asm.R6, int16(f.offset+i), asm.Byte),
+			asm.StoreMem(asm.RFP, offset+int16(i), tmp, asm.Byte),
+		)
+	}
+	return insns
+}
+
+// increment emits instructions incrementing the counter of the hash map
+// with the key at the given stack offset, creating the counter if necessary
+func increment(m *ebpf.Map, key int16, name string) asm.Instructions {
+	return asm.Instructions{
+		asm.LoadMapPtr(asm.R1, m.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, int32(key)),
+		asm.FnMapLookupElem.Call(),
+		asm.JEq.Imm(asm.R0, 0, name+"_new"),
+		asm.Mov.Imm(asm.R1, 1),
+		asm.StoreXAdd(asm.R0, asm.R1, asm.DWord),
+		asm.Ja.Label("exit"),
+		asm.StoreImm(asm.RFP, -40, 1, asm.DWord).WithSymbol(name + "_new"),
+		asm.LoadMapPtr(asm.R1, m.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, int32(key)),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, -40),
+		asm.Mov.Imm(asm.R4, bpfNoExist),
+		asm.FnMapUpdateElem.Call(),
+	}
+}
+
+// add emits instructions adding the value of the register to the element of
+// the array map with the given index
+func add(m *ebpf.Map, index int32, value asm.Register, name string) asm.Instructions {
+	insns := asm.Instructions{asm.Mov.Imm(tmp, index)}
+	return append(insns, addIndex(m, tmp, value, name)...)
+}
+
+// addIndex emits instructions adding the value of the register to the
+// element of the array map with the index given by the index register
+func addIndex(m *ebpf.Map, index, value asm.Register, name string) asm.Instructions {
+	return asm.Instructions{
+		asm.StoreMem(asm.RFP, -44, index, asm.Word),
+		asm.LoadMapPtr(asm.R1, m.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -44),
+		asm.FnMapLookupElem.Call(),
+		asm.JEq.Imm(asm.R0, 0, name+"_done"),
+		asm.StoreXAdd(asm.R0, value, asm.DWord),
+		asm.Mov.Imm(asm.R0, 0).WithSymbol(name + "_done"),
+	}
+}
+
+func exit() asm.Instructions {
+	return asm.Instructions{
+		asm.Mov.Imm(asm.R0, 0).WithSymbol("exit"),
+		asm.Return(),
+	}
+}
+
+// label attaches the symbol to the first instruction
+func label(name string, insns asm.Instructions) asm.Instructions {
+	insns[0] = insns[0].WithSymbol(name)
+	return insns
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -50,6 +51,9 @@ type Elasticsearch struct {
 	Username                   string            `toml:"username"`
 	Password                   string            `toml:"password"`
 	NumMostRecentIndices       int               `toml:"num_most_recent_indices"`
+	ShardsTopN                 int               `toml:"shards_top_n"`
+	ShardsSortField            string            `toml:"shards_sort_field"`
+	ILMStats                   bool              `toml:"ilm_stats"`
 
 	Log telegraf.Logger `toml:"-"`
 
@@ -137,6 +141,25 @@ type indexStat struct {
 	Total     interface{}              `json:"total"`
 	Shards    map[string][]interface{} `json:"shards"`
 }
+
+type shardStat struct {
+	fields map[string]interface{}
+	tags   map[string]string
+}
+
+type ilmExplain struct {
+	Indices map[string]struct {
+		Managed              bool   `json:"managed"`
+		Policy               string `json:"policy"`
+		Age                  string `json:"age"`
+		Phase                string `json:"phase"`
+		Action               string `json:"action"`
+		Step                 string `json:"step"`
+		FailedStep           string `json:"failed_step"`
+		FailedStepRetryCount int    `json:"failed_step_retry_count"`
+	} `json:"indices"`
+}
+
 type serverInfo struct {
 	nodeID   string
 	masterID string
@@ -155,6 +178,10 @@ func (e *Elasticsearch) Init() error {
 
 	e.indexMatchers = indexMatchers
 
+	if e.ShardsTopN < 0 {
+		return errors.New("'shards_top_n' must not be negative")
+	}
+
 	return nil
 }
 
@@ -172,7 +199,7 @@ func (e *Elasticsearch) Gather(acc telegraf.Accumulator) error {
 		e.client = client
 	}
 
-	if e.ClusterStats || len(e.IndicesInclude) > 0 || len(e.IndicesLevel) > 0 {
+	if e.ClusterStats || e.ILMStats || len(e.IndicesInclude) > 0 || len(e.IndicesLevel) > 0 {
 		var wgC sync.WaitGroup
 		wgC.Add(len(e.Servers))
 
@@ -251,6 +278,17 @@ func (e *Elasticsearch) Gather(acc telegraf.Accumulator) error {
 				}
 			}
 
+			if e.ILMStats && (e.serverInfo[s].isMaster() || !e.ClusterStatsOnlyFromMaster || !e.Local) {
+				indices := "_all"
+				if len(e.IndicesInclude) > 0 {
+					indices = strings.Join(e.IndicesInclude, ",")
+				}
+				if err := e.gatherILMStats(s+"/"+indices+"/_ilm/explain", acc); err != nil {
+					acc.AddError(errors.New(mask.ReplaceAllString(err.Error(), "http(s)://XXX:XXX@")))
+					return
+				}
+			}
+
 			if e.EnrichStats {
 				if err := e.gatherEnrichStats(s+"/_enrich/_stats", acc); err != nil {
 					acc.AddError(errors.New(mask.ReplaceAllString(err.Error(), "http(s)://XXX:XXX@")))
@@ -453,6 +491,48 @@ func (e *Elasticsearch) gatherEnrichStats(url string, acc telegraf.Accumulator)
 	return nil
 }
 
+func (e *Elasticsearch) gatherILMStats(url string, acc telegraf.Accumulator) error {
+	explain := &ilmExplain{}
+	if err := e.gatherJSONData(url, explain); err != nil {
+		return err
+	}
+	now := time.Now()
+
+	for name, index := range explain.Indices {
+		// Indices without a lifecycle policy have no ILM state
+		if !index.Managed {
+			continue
+		}
+
+		tags := map[string]string{
+			"index_name": name,
+			"policy":     index.Policy,
+			"phase":      index.Phase,
+			"action":     index.Action,
+			"step":       index.Step,
+		}
+		fields := map[string]interface{}{
+			"phase_code":              mapILMPhaseToCode(index.Phase),
+			"rollover_pending":        index.Action == "rollover" && index.Step != "ERROR",
+			"failed":                  index.Step == "ERROR",
+			"failed_step_retry_count": index.FailedStepRetryCount,
+		}
+		if index.FailedStep != "" {
+			tags["failed_step"] = index.FailedStep
+		}
+		if index.Age != "" {
+			age, err := parseTimeValue(index.Age)
+			if err != nil {
+				return fmt.Errorf("parsing age of index %q failed: %w", name, err)
+			}
+			fields["age_ms"] = age.Milliseconds()
+		}
+		acc.AddFields("elasticsearch_ilm", fields, tags, now)
+	}
+
+	return nil
+}
+
 func (e *Elasticsearch) gatherClusterStats(url string, acc telegraf.Accumulator) error {
 	clusterStats := &clusterStats{}
 	if err := e.gatherJSONData(url, clusterStats); err != nil {
@@ -523,6 +603,7 @@ func (e *Elasticsearch) gatherIndicesStats(url string, acc telegraf.Accumulator)
 func (e *Elasticsearch) gatherIndividualIndicesStats(indices map[string]indexStat, now time.Time, acc telegraf.Accumulator) error {
 	// Sort indices into buckets based on their configured prefix, if any matches.
 	categorizedIndexNames := e.categorizeIndices(indices)
+	var shards []shardStat
 	for _, matchingIndices := range categorizedIndexNames {
 		// Establish the number of each category of indices to use. User can configure to use only the latest 'X' amount.
 		indicesCount := len(matchingIndices)
@@ -540,13 +621,28 @@ func (e *Elasticsearch) gatherIndividualIndicesStats(indices map[string]indexSta
 		for i := indicesCount - 1; i >= indicesCount-indicesToTrackCount; i-- {
 			indexName := matchingIndices[i]
 
-			err := e.gatherSingleIndexStats(indexName, indices[indexName], now, acc)
+			indexShards, err := e.gatherSingleIndexStats(indexName, indices[indexName], now, acc)
 			if err != nil {
 				return err
 			}
+			shards = append(shards, indexShards...)
 		}
 	}
 
+	// Only keep the shards with the highest value of the sort field across
+	// all indices to limit the number of series on large clusters.
+	if e.ShardsTopN > 0 && len(shards) > e.ShardsTopN {
+		sort.SliceStable(shards, func(i, j int) bool {
+			vi, _ := shards[i].fields[e.ShardsSortField].(float64)
+			vj, _ := shards[j].fields[e.ShardsSortField].(float64)
+			return vi > vj
+		})
+		shards = shards[:e.ShardsTopN]
+	}
+	for _, shard := range shards {
+		acc.AddFields("elasticsearch_indices_stats_shards", shard.fields, shard.tags, now)
+	}
+
 	return nil
 }
 
@@ -580,7 +676,7 @@ func (e *Elasticsearch) categorizeIndices(indices map[string]indexStat) map[stri
 	return categorizedIndexNames
 }
 
-func (e *Elasticsearch) gatherSingleIndexStats(name string, index indexStat, now time.Time, acc telegraf.Accumulator) error {
+func (e *Elasticsearch) gatherSingleIndexStats(name string, index indexStat, now time.Time, acc telegraf.Accumulator) ([]shardStat, error) {
 	indexTag := map[string]string{"index_name": name}
 	stats := map[string]interface{}{
 		"primaries": index.Primaries,
@@ -591,11 +687,12 @@ func (e *Elasticsearch) gatherSingleIndexStats(name string, index indexStat, now
 		// parse Json, getting strings and bools
 		err := f.FullFlattenJSON("", s, true, true)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		acc.AddFields("elasticsearch_indices_stats_"+m, f.Fields, indexTag, now)
 	}
 
+	var shardStats []shardStat
 	if e.IndicesLevel == "shards" {
 		for shardNumber, shards := range index.Shards {
 			for _, shard := range shards {
@@ -603,7 +700,7 @@ func (e *Elasticsearch) gatherSingleIndexStats(name string, index indexStat, now
 				flattened := parsers_json.JSONFlattener{}
 				err := flattened.FullFlattenJSON("", shard, true, true)
 				if err != nil {
-					return err
+					return nil, err
 				}
 
 				// determine shard tag and primary/replica designation
@@ -634,15 +731,12 @@ func (e *Elasticsearch) gatherSingleIndexStats(name string, index indexStat, now
 					}
 				}
 
-				acc.AddFields("elasticsearch_indices_stats_shards",
-					flattened.Fields,
-					shardTags,
-					now)
+				shardStats = append(shardStats, shardStat{fields: flattened.Fields, tags: shardTags})
 			}
 		}
 	}
 
-	return nil
+	return shardStats, nil
 }
 
 func (e *Elasticsearch) getCatMaster(url string) (string, error) {
@@ -764,10 +858,58 @@ func mapShardStatusToCode(s string) int {
 	return 0
 }
 
+// perform ILM phase mapping
+func mapILMPhaseToCode(s string) int {
+	switch strings.ToLower(s) {
+	case "new":
+		return 1
+	case "hot":
+		return 2
+	case "warm":
+		return 3
+	case "cold":
+		return 4
+	case "frozen":
+		return 5
+	case "delete":
+		return 6
+	}
+	return 0
+}
+
+// parseTimeValue parses the human readable time values of Elasticsearch
+// such as "1.5d" or "250ms"
+func parseTimeValue(s string) (time.Duration, error) {
+	// Longer suffixes must be checked first as e.g. "ms" ends with "s"
+	units := []struct {
+		suffix string
+		unit   time.Duration
+	}{
+		{"nanos", time.Nanosecond},
+		{"micros", time.Microsecond},
+		{"ms", time.Millisecond},
+		{"s", time.Second},
+		{"m", time.Minute},
+		{"h", time.Hour},
+		{"d", 24 * time.Hour},
+	}
+	for _, u := range units {
+		if v, found := strings.CutSuffix(s, u.suffix); found {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid time value %q: %w", s, err)
+			}
+			return time.Duration(f * float64(u.unit)), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid time value %q: unknown unit", s)
+}
+
 func newElasticsearch() *Elasticsearch {
 	return &Elasticsearch{
 		ClusterStatsOnlyFromMaster: true,
 		ClusterHealthLevel:         "indices",
+		ShardsSortField:            "store_size_in_bytes",
 		HTTPClientConfig: common_http.HTTPClientConfig{
 			ResponseHeaderTimeout: config.Duration(5 * time.Second),
 			Timeout:               config.Duration(5 * time.Second),
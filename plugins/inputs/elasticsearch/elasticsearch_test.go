@@ -3,11 +3,15 @@ package elasticsearch
 import (
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -371,3 +375,140 @@ func newElasticsearchWithClient() *Elasticsearch {
 	es.client = &http.Client{}
 	return es
 }
+
+func TestGatherClusterIndiceShardsStatsTopN(t *testing.T) {
+	es := newElasticsearchWithClient()
+	es.IndicesLevel = "shards"
+	es.client.Transport = newTransportMock(clusterIndicesShardsResponse)
+
+	// Collect the sizes of all shards as reference
+	var all testutil.Accumulator
+	require.NoError(t, es.gatherIndicesStats("junk", &all))
+	sizes := make([]float64, 0)
+	for _, m := range all.GetTelegrafMetrics() {
+		if m.Name() == "elasticsearch_indices_stats_shards" {
+			v, found := m.GetField("store_size_in_bytes")
+			require.True(t, found)
+			sizes = append(sizes, v.(float64))
+		}
+	}
+	require.Greater(t, len(sizes), 2)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sizes)))
+
+	es.ShardsTopN = 2
+	var acc testutil.Accumulator
+	require.NoError(t, es.gatherIndicesStats("junk", &acc))
+
+	actual := make([]float64, 0, 2)
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "elasticsearch_indices_stats_shards" {
+			v, found := m.GetField("store_size_in_bytes")
+			require.True(t, found)
+			actual = append(actual, v.(float64))
+		}
+	}
+	require.Equal(t, sizes[:2], actual)
+
+	// Index level stats must not be affected by the limit
+	acc.AssertContainsTaggedFields(t, "elasticsearch_indices_stats_primaries",
+		clusterIndicesExpected,
+		map[string]string{"index_name": "twitter"})
+}
+
+func TestGatherILMStats(t *testing.T) {
+	es := newElasticsearchWithClient()
+	es.client.Transport = newTransportMock(ilmExplainResponse)
+
+	var acc testutil.Accumulator
+	require.NoError(t, es.gatherILMStats("junk", &acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"elasticsearch_ilm",
+			map[string]string{
+				"index_name": "logs-000002",
+				"policy":     "logs",
+				"phase":      "hot",
+				"action":     "rollover",
+				"step":       "check-rollover-ready",
+			},
+			map[string]interface{}{
+				"age_ms":                  int64(5400000),
+				"phase_code":              2,
+				"rollover_pending":        true,
+				"failed":                  false,
+				"failed_step_retry_count": 0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"elasticsearch_ilm",
+			map[string]string{
+				"index_name": "logs-000001",
+				"policy":     "logs",
+				"phase":      "warm",
+				"action":     "complete",
+				"step":       "complete",
+			},
+			map[string]interface{}{
+				"age_ms":                  int64(129600000),
+				"phase_code":              3,
+				"rollover_pending":        false,
+				"failed":                  false,
+				"failed_step_retry_count": 0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"elasticsearch_ilm",
+			map[string]string{
+				"index_name":  "metrics-000001",
+				"policy":      "metrics",
+				"phase":       "hot",
+				"action":      "rollover",
+				"step":        "ERROR",
+				"failed_step": "check-rollover-ready",
+			},
+			map[string]interface{}{
+				"age_ms":                  int64(250),
+				"phase_code":              2,
+				"rollover_pending":        false,
+				"failed":                  true,
+				"failed_step_retry_count": 3,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestParseTimeValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"15nanos", 15 * time.Nanosecond},
+		{"15micros", 15 * time.Microsecond},
+		{"250ms", 250 * time.Millisecond},
+		{"15s", 15 * time.Second},
+		{"2m", 2 * time.Minute},
+		{"1.5h", 90 * time.Minute},
+		{"1.5d", 36 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			actual, err := parseTimeValue(tt.value)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+
+	_, err := parseTimeValue("15y")
+	require.ErrorContains(t, err, "unknown unit")
+}
+
+func TestInitFail(t *testing.T) {
+	es := newElasticsearch()
+	es.ShardsTopN = -1
+	require.ErrorContains(t, es.Init(), "'shards_top_n' must not be negative")
+}
@@ -5914,3 +5914,55 @@ var clusterIndicesReplicaShardsExpected = map[string]interface{}{
 	"warmer_total":                           float64(3),
 	"warmer_total_time_in_millis":            float64(0),
 }
+
+const ilmExplainResponse = `
+{
+  "indices": {
+    "logs-000002": {
+      "index": "logs-000002",
+      "managed": true,
+      "policy": "logs",
+      "index_creation_date_millis": 1700000000000,
+      "time_since_index_creation": "1.5h",
+      "lifecycle_date_millis": 1700000000000,
+      "age": "1.5h",
+      "phase": "hot",
+      "phase_time_millis": 1700000000000,
+      "action": "rollover",
+      "action_time_millis": 1700000000000,
+      "step": "check-rollover-ready",
+      "step_time_millis": 1700000000000
+    },
+    "logs-000001": {
+      "index": "logs-000001",
+      "managed": true,
+      "policy": "logs",
+      "lifecycle_date_millis": 1699870400000,
+      "age": "1.5d",
+      "phase": "warm",
+      "action": "complete",
+      "step": "complete"
+    },
+    "metrics-000001": {
+      "index": "metrics-000001",
+      "managed": true,
+      "policy": "metrics",
+      "age": "250ms",
+      "phase": "hot",
+      "action": "rollover",
+      "step": "ERROR",
+      "failed_step": "check-rollover-ready",
+      "is_auto_retryable_error": true,
+      "failed_step_retry_count": 3,
+      "step_info": {
+        "type": "illegal_argument_exception",
+        "reason": "rollover target [metrics] does not point to a write index"
+      }
+    },
+    "unmanaged": {
+      "index": "unmanaged",
+      "managed": false
+    }
+  }
+}
+`
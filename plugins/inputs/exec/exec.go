@@ -4,7 +4,10 @@ package exec
 import (
 	"bytes"
 	_ "embed"
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -13,7 +16,9 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/process"
 	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/common/sandbox"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/nagios"
 )
@@ -26,12 +31,15 @@ var once sync.Once
 const maxStderrBytes int = 512
 
 type Exec struct {
-	Commands    []string        `toml:"commands"`
-	Command     string          `toml:"command"`
-	Environment []string        `toml:"environment"`
-	IgnoreError bool            `toml:"ignore_error"`
-	Timeout     config.Duration `toml:"timeout"`
-	Log         telegraf.Logger `toml:"-"`
+	Commands           []string                  `toml:"commands"`
+	Command            string                    `toml:"command"`
+	Environment        []string                  `toml:"environment"`
+	EnvironmentSecrets map[string]*config.Secret `toml:"environment_secrets"`
+	IgnoreError        bool                      `toml:"ignore_error"`
+	Timeout            config.Duration           `toml:"timeout"`
+	KillDelay          config.Duration           `toml:"kill_delay"`
+	Log                telegraf.Logger           `toml:"-"`
+	sandbox.Config
 
 	parser telegraf.Parser
 
@@ -50,7 +58,10 @@ type runner interface {
 
 type commandRunner struct {
 	environment []string
+	secrets     map[string]*config.Secret
 	timeout     time.Duration
+	killDelay   time.Duration
+	sandbox     *sandbox.Config
 	debug       bool
 }
 
@@ -64,9 +75,20 @@ func (e *Exec) Init() error {
 		e.Commands = append(e.Commands, e.Command)
 	}
 
+	if e.KillDelay < 0 {
+		return errors.New("'kill_delay' must not be negative")
+	}
+
+	if err := e.Config.Init(); err != nil {
+		return fmt.Errorf("invalid sandbox settings: %w", err)
+	}
+
 	e.runner = &commandRunner{
 		environment: e.Environment,
+		secrets:     e.EnvironmentSecrets,
 		timeout:     time.Duration(e.Timeout),
+		killDelay:   time.Duration(e.KillDelay),
+		sandbox:     &e.Config,
 		debug:       e.Log.Level().Includes(telegraf.Debug),
 	}
 
@@ -164,6 +186,29 @@ func (e *Exec) processCommand(acc telegraf.Accumulator, cmd string) error {
 	return nil
 }
 
+// prepare sets the environment of the command and resolves the secrets at
+// spawn time so updated secrets are used by the next run
+func (c *commandRunner) prepare(cmd *exec.Cmd) error {
+	secretEnvs, err := process.ResolveEnvironment(c.secrets)
+	if err != nil {
+		return err
+	}
+	if len(c.environment) > 0 || len(secretEnvs) > 0 {
+		cmd.Env = append(os.Environ(), c.environment...)
+		cmd.Env = append(cmd.Env, secretEnvs...)
+	}
+	return nil
+}
+
+// execute starts the command in the configured sandbox and waits for it to
+// finish, terminating the process on timeout
+func (c *commandRunner) execute(cmd *exec.Cmd) error {
+	if err := c.sandbox.StartProcess(cmd); err != nil {
+		return err
+	}
+	return internal.WaitTimeoutGrace(cmd, c.timeout, c.killDelay)
+}
+
 func truncate(buf *bytes.Buffer) {
 	// Limit the number of bytes.
 	didTruncate := false
@@ -186,7 +231,8 @@ func truncate(buf *bytes.Buffer) {
 func init() {
 	inputs.Add("exec", func() telegraf.Input {
 		return &Exec{
-			Timeout: config.Duration(5 * time.Second),
+			Timeout:   config.Duration(5 * time.Second),
+			KillDelay: config.Duration(5 * time.Second),
 		}
 	})
 }
@@ -8,6 +8,7 @@ package exec
 import (
 	"bytes"
 	"errors"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -18,6 +19,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/sandbox"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/csv"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
@@ -312,6 +314,118 @@ func TestExecCommandWithEnv(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
 }
 
+func TestExecCommandWithSecretEnv(t *testing.T) {
+	// Setup parser
+	parser := value.Parser{
+		MetricName: "metric",
+		DataType:   "string",
+	}
+	require.NoError(t, parser.Init())
+
+	// Setup plugin
+	secret := config.NewSecret([]byte("secret_value"))
+	plugin := &Exec{
+		Commands:           []string{"/bin/sh -c 'echo ${METRIC_NAME}-${METRIC_SECRET}'"},
+		Environment:        []string{"METRIC_NAME=metric_value"},
+		EnvironmentSecrets: map[string]*config.Secret{"METRIC_SECRET": &secret},
+		Timeout:            config.Duration(5 * time.Second),
+		Log:                testutil.Logger{},
+	}
+	plugin.SetParser(&parser)
+	require.NoError(t, plugin.Init())
+
+	// Gather the metrics and check the result
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"metric",
+			map[string]string{},
+			map[string]interface{}{
+				"value": "metric_value-secret_value",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestKillDelay(t *testing.T) {
+	// Setup parser
+	parser := value.Parser{
+		MetricName: "metric",
+		DataType:   "string",
+	}
+	require.NoError(t, parser.Init())
+
+	// Setup plugin with a command ignoring SIGTERM
+	plugin := &Exec{
+		Commands:  []string{"/bin/sh -c 'trap \"\" TERM; sleep 10'"},
+		Timeout:   config.Duration(100 * time.Millisecond),
+		KillDelay: config.Duration(100 * time.Millisecond),
+		Log:       testutil.Logger{},
+	}
+	plugin.SetParser(&parser)
+	require.NoError(t, plugin.Init())
+
+	// The command must be killed after the delay
+	start := time.Now()
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "command timed out")
+}
+
+func TestLimitOpenFiles(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Resource limits are only supported on Linux")
+	}
+
+	// Setup parser
+	parser := value.Parser{
+		MetricName: "metric",
+		DataType:   "integer",
+	}
+	require.NoError(t, parser.Init())
+
+	// Setup plugin, delay the output to make sure the limit is applied
+	plugin := &Exec{
+		Commands: []string{"/bin/sh -c 'sleep 0.2; ulimit -n'"},
+		Timeout:  config.Duration(5 * time.Second),
+		Config:   sandbox.Config{LimitOpenFiles: 42},
+		Log:      testutil.Logger{},
+	}
+	plugin.SetParser(&parser)
+	require.NoError(t, plugin.Init())
+
+	// Gather the metrics and check the result
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"metric",
+			map[string]string{},
+			map[string]interface{}{
+				"value": int64(42),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestInvalidRunAsUser(t *testing.T) {
+	plugin := &Exec{
+		Commands: []string{"/bin/true"},
+		Config:   sandbox.Config{RunAsUser: "nonexistent-telegraf-user"},
+		Log:      testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), `looking up user "nonexistent-telegraf-user" failed`)
+}
+
 func TestTruncate(t *testing.T) {
 	tests := []struct {
 		name     string
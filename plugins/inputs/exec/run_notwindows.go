@@ -5,13 +5,10 @@ package exec
 import (
 	"bytes"
 	"fmt"
-	"os"
 	"os/exec"
 	"syscall"
 
 	"github.com/kballard/go-shellquote"
-
-	"github.com/influxdata/telegraf/internal"
 )
 
 func (c *commandRunner) run(command string) (out, errout []byte, err error) {
@@ -23,15 +20,15 @@ func (c *commandRunner) run(command string) (out, errout []byte, err error) {
 	cmd := exec.Command(splitCmd[0], splitCmd[1:]...)
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 
-	if len(c.environment) > 0 {
-		cmd.Env = append(os.Environ(), c.environment...)
+	if err := c.prepare(cmd); err != nil {
+		return nil, nil, err
 	}
 
 	var outbuf, stderr bytes.Buffer
 	cmd.Stdout = &outbuf
 	cmd.Stderr = &stderr
 
-	runErr := internal.RunTimeout(cmd, c.timeout)
+	runErr := c.execute(cmd)
 
 	if stderr.Len() > 0 && !c.debug {
 		truncate(&stderr)
@@ -7,13 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 	"os/exec"
 	"syscall"
 
 	"github.com/kballard/go-shellquote"
-
-	"github.com/influxdata/telegraf/internal"
 )
 
 func (c *commandRunner) run(command string) (out, errout []byte, err error) {
@@ -27,15 +24,15 @@ func (c *commandRunner) run(command string) (out, errout []byte, err error) {
 		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
 	}
 
-	if len(c.environment) > 0 {
-		cmd.Env = append(os.Environ(), c.environment...)
+	if err := c.prepare(cmd); err != nil {
+		return nil, nil, err
 	}
 
 	var outbuf, stderr bytes.Buffer
 	cmd.Stdout = &outbuf
 	cmd.Stderr = &stderr
 
-	runErr := internal.RunTimeout(cmd, c.timeout)
+	runErr := c.execute(cmd)
 
 	outbuf = removeWindowsCarriageReturns(outbuf)
 	stderr = removeWindowsCarriageReturns(stderr)
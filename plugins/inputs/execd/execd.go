@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/process"
 	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/common/sandbox"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 )
@@ -27,13 +28,16 @@ var sampleConfig string
 var once sync.Once
 
 type Execd struct {
-	Command      []string        `toml:"command"`
-	Environment  []string        `toml:"environment"`
-	BufferSize   config.Size     `toml:"buffer_size"`
-	Signal       string          `toml:"signal"`
-	RestartDelay config.Duration `toml:"restart_delay"`
-	StopOnError  bool            `toml:"stop_on_error"`
-	Log          telegraf.Logger `toml:"-"`
+	Command            []string                  `toml:"command"`
+	Environment        []string                  `toml:"environment"`
+	EnvironmentSecrets map[string]*config.Secret `toml:"environment_secrets"`
+	BufferSize         config.Size               `toml:"buffer_size"`
+	Signal             string                    `toml:"signal"`
+	RestartDelay       config.Duration           `toml:"restart_delay"`
+	StopOnError        bool                      `toml:"stop_on_error"`
+	StopTimeout        config.Duration           `toml:"stop_timeout"`
+	Log                telegraf.Logger           `toml:"-"`
+	sandbox.Config
 
 	process      *process.Process
 	acc          telegraf.Accumulator
@@ -49,6 +53,12 @@ func (e *Execd) Init() error {
 	if len(e.Command) == 0 {
 		return errors.New("no command specified")
 	}
+	if e.StopTimeout < 0 {
+		return errors.New("'stop_timeout' must not be negative")
+	}
+	if err := e.Config.Init(); err != nil {
+		return fmt.Errorf("invalid sandbox settings: %w", err)
+	}
 	return nil
 }
 
@@ -75,6 +85,13 @@ func (e *Execd) Start(acc telegraf.Accumulator) error {
 	e.process.ReadStderrFn = e.cmdReadErr
 	e.process.RestartDelay = time.Duration(e.RestartDelay)
 	e.process.StopOnError = e.StopOnError
+	if e.StopTimeout > 0 {
+		e.process.StopTimeout = time.Duration(e.StopTimeout)
+	}
+	e.process.Secrets = e.EnvironmentSecrets
+	if e.Config.Enabled() {
+		e.process.Sandbox = &e.Config
+	}
 	e.process.Log = e.Log
 
 	if err = e.process.Start(); err != nil {
@@ -181,6 +198,7 @@ func init() {
 			Signal:       "none",
 			RestartDelay: config.Duration(10 * time.Second),
 			BufferSize:   config.Size(64 * 1024),
+			StopTimeout:  config.Duration(5 * time.Second),
 		}
 	})
 }
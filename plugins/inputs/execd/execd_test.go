@@ -30,6 +30,8 @@ func TestSettingConfigWorks(t *testing.T) {
 		environment = ["d=e", "f=1"]
 		restart_delay = "1m"
 		signal = "SIGHUP"
+		stop_timeout = "30s"
+		no_network = true
 	`
 	conf := config.NewConfig()
 	require.NoError(t, conf.LoadConfigData([]byte(cfg), config.EmptySourcePath))
@@ -41,6 +43,8 @@ func TestSettingConfigWorks(t *testing.T) {
 	require.EqualValues(t, []string{"d=e", "f=1"}, inp.Environment)
 	require.EqualValues(t, 1*time.Minute, inp.RestartDelay)
 	require.EqualValues(t, "SIGHUP", inp.Signal)
+	require.EqualValues(t, 30*time.Second, inp.StopTimeout)
+	require.True(t, inp.NoNetwork)
 }
 
 func TestExternalInputWorks(t *testing.T) {
@@ -77,6 +81,38 @@ func TestExternalInputWorks(t *testing.T) {
 	require.EqualValues(t, 0, val)
 }
 
+func TestExternalInputWithSecretEnv(t *testing.T) {
+	influxParser := models.NewRunningParser(&influx.Parser{}, &models.ParserConfig{})
+	require.NoError(t, influxParser.Init())
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	secret := config.NewSecret([]byte("secret_counter"))
+	e := &Execd{
+		Command:            []string{exe, "-mode", "counter"},
+		Environment:        []string{"PLUGINS_INPUTS_EXECD_MODE=application"},
+		EnvironmentSecrets: map[string]*config.Secret{"METRIC_NAME": &secret},
+		RestartDelay:       config.Duration(5 * time.Second),
+		Signal:             "STDIN",
+		Log:                testutil.Logger{},
+	}
+	e.SetParser(influxParser)
+
+	metrics := make(chan telegraf.Metric, 10)
+	defer close(metrics)
+	acc := agent.NewAccumulator(&TestMetricMaker{}, metrics)
+
+	require.NoError(t, e.Start(acc))
+	require.NoError(t, e.Gather(acc))
+
+	m := readChanWithTimeout(t, metrics, 10*time.Second)
+
+	e.Stop()
+
+	require.Equal(t, "secret_counter", m.Name())
+}
+
 func TestParsesLinesContainingNewline(t *testing.T) {
 	parser := models.NewRunningParser(&influx.Parser{}, &models.ParserConfig{})
 	require.NoError(t, parser.Init())
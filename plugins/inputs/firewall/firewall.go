@@ -0,0 +1,300 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package firewall
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Firewall struct {
+	URL            string          `toml:"url"`
+	Vendor         string          `toml:"vendor"`
+	APIKey         config.Secret   `toml:"api_key"`
+	VirtualSystems []string        `toml:"virtual_systems"`
+	Collect        []string        `toml:"collect"`
+	Log            telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	device    string
+	domainTag string
+	client    *http.Client
+}
+
+// snapshot holds the statistics of a firewall in a vendor independent way,
+// the domain of the statistics is the VDOM or virtual system if any
+type snapshot struct {
+	sessions   []sessionStats
+	vpns       []vpnStats
+	interfaces []interfaceStats
+	threats    []threatStats
+	ha         *haStats
+}
+
+type sessionStats struct {
+	domain         string
+	active         uint64
+	maximum        *uint64
+	tcp            *uint64
+	udp            *uint64
+	icmp           *uint64
+	setupRate      *float64
+	throughputKbps *float64
+	packetRate     *float64
+}
+
+type vpnStats struct {
+	domain         string
+	sslVPNUsers    *int64
+	ipsecTunnels   *int64
+	ipsecTunnelsUp *int64
+}
+
+type interfaceStats struct {
+	domain    string
+	name      string
+	rxBytes   uint64
+	txBytes   uint64
+	rxPackets uint64
+	txPackets uint64
+	rxErrors  *uint64
+	txErrors  *uint64
+	rxDrops   *uint64
+	link      *bool
+	speed     *float64
+}
+
+type threatStats struct {
+	domain   string
+	name     string
+	severity string
+	count    uint64
+	rate     *float64
+}
+
+type haStats struct {
+	enabled       bool
+	mode          string
+	members       *int64
+	localState    string
+	peerState     string
+	peerConnected *bool
+	synchronized  *bool
+}
+
+func (*Firewall) SampleConfig() string {
+	return sampleConfig
+}
+
+func (f *Firewall) Init() error {
+	if f.URL == "" {
+		return errors.New("'url' required")
+	}
+	f.URL = strings.TrimRight(f.URL, "/")
+	u, err := url.Parse(f.URL)
+	if err != nil {
+		return fmt.Errorf("parsing URL %q failed: %w", f.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid scheme %q in URL", u.Scheme)
+	}
+	f.device = u.Hostname()
+
+	switch f.Vendor {
+	case "fortigate":
+		f.domainTag = "vdom"
+	case "panos":
+		f.domainTag = "vsys"
+	case "":
+		return errors.New("'vendor' required")
+	default:
+		return fmt.Errorf("invalid vendor %q", f.Vendor)
+	}
+
+	if f.APIKey.Empty() {
+		return errors.New("'api_key' required")
+	}
+
+	if len(f.Collect) == 0 {
+		f.Collect = []string{"sessions", "vpn", "interfaces", "threats", "ha"}
+	}
+	for _, c := range f.Collect {
+		switch c {
+		case "sessions", "vpn", "interfaces", "threats", "ha":
+		default:
+			return fmt.Errorf("invalid value %q in 'collect'", c)
+		}
+	}
+
+	client, err := f.HTTPClientConfig.CreateClient(context.Background(), f.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	f.client = client
+
+	return nil
+}
+
+func (f *Firewall) Gather(acc telegraf.Accumulator) error {
+	var s *snapshot
+	var err error
+	switch f.Vendor {
+	case "fortigate":
+		s, err = f.gatherFortigate()
+	case "panos":
+		s, err = f.gatherPanos()
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, v := range s.sessions {
+		fields := map[string]interface{}{"active": v.active}
+		if v.maximum != nil {
+			fields["max"] = *v.maximum
+		}
+		if v.tcp != nil {
+			fields["tcp"] = *v.tcp
+		}
+		if v.udp != nil {
+			fields["udp"] = *v.udp
+		}
+		if v.icmp != nil {
+			fields["icmp"] = *v.icmp
+		}
+		if v.setupRate != nil {
+			fields["setup_rate"] = *v.setupRate
+		}
+		if v.throughputKbps != nil {
+			fields["throughput_kbps"] = *v.throughputKbps
+		}
+		if v.packetRate != nil {
+			fields["packet_rate"] = *v.packetRate
+		}
+		acc.AddFields("firewall_sessions", fields, f.tags(v.domain))
+	}
+
+	for _, v := range s.vpns {
+		fields := make(map[string]interface{}, 3)
+		if v.sslVPNUsers != nil {
+			fields["ssl_vpn_users"] = *v.sslVPNUsers
+		}
+		if v.ipsecTunnels != nil {
+			fields["ipsec_tunnels"] = *v.ipsecTunnels
+		}
+		if v.ipsecTunnelsUp != nil {
+			fields["ipsec_tunnels_up"] = *v.ipsecTunnelsUp
+		}
+		if len(fields) > 0 {
+			acc.AddFields("firewall_vpn", fields, f.tags(v.domain))
+		}
+	}
+
+	for _, v := range s.interfaces {
+		tags := f.tags(v.domain)
+		tags["interface"] = v.name
+		fields := map[string]interface{}{
+			"rx_bytes":   v.rxBytes,
+			"tx_bytes":   v.txBytes,
+			"rx_packets": v.rxPackets,
+			"tx_packets": v.txPackets,
+		}
+		if v.rxErrors != nil {
+			fields["rx_errors"] = *v.rxErrors
+		}
+		if v.txErrors != nil {
+			fields["tx_errors"] = *v.txErrors
+		}
+		if v.rxDrops != nil {
+			fields["rx_drops"] = *v.rxDrops
+		}
+		if v.link != nil {
+			fields["link"] = *v.link
+		}
+		if v.speed != nil {
+			fields["speed_mbps"] = *v.speed
+		}
+		acc.AddFields("firewall_interface", fields, tags)
+	}
+
+	for _, v := range s.threats {
+		tags := f.tags(v.domain)
+		tags["name"] = v.name
+		if v.severity != "" {
+			tags["severity"] = v.severity
+		}
+		fields := map[string]interface{}{"count": v.count}
+		if v.rate != nil {
+			fields["rate"] = *v.rate
+		}
+		acc.AddFields("firewall_threats", fields, tags)
+	}
+
+	if v := s.ha; v != nil {
+		tags := f.tags("")
+		if v.mode != "" {
+			tags["mode"] = v.mode
+		}
+		fields := map[string]interface{}{"enabled": v.enabled}
+		if v.members != nil {
+			fields["members"] = *v.members
+		}
+		if v.localState != "" {
+			fields["local_state"] = v.localState
+		}
+		if v.peerState != "" {
+			fields["peer_state"] = v.peerState
+		}
+		if v.peerConnected != nil {
+			fields["peer_connected"] = *v.peerConnected
+		}
+		if v.synchronized != nil {
+			fields["synchronized"] = *v.synchronized
+		}
+		acc.AddFields("firewall_ha", fields, tags)
+	}
+
+	return nil
+}
+
+func (f *Firewall) Stop() {
+	if f.client != nil {
+		f.client.CloseIdleConnections()
+	}
+}
+
+func (f *Firewall) collects(what string) bool {
+	return slices.Contains(f.Collect, what)
+}
+
+func (f *Firewall) tags(domain string) map[string]string {
+	tags := map[string]string{"device": f.device}
+	if domain != "" {
+		tags[f.domainTag] = domain
+	}
+	return tags
+}
+
+func init() {
+	inputs.Add("firewall", func() telegraf.Input {
+		return &Firewall{
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
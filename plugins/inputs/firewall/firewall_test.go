@@ -0,0 +1,388 @@
+package firewall
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Firewall
+		expected string
+	}{
+		{
+			name:     "missing url",
+			plugin:   &Firewall{Vendor: "fortigate"},
+			expected: "'url' required",
+		},
+		{
+			name:     "invalid scheme",
+			plugin:   &Firewall{URL: "ftp://localhost", Vendor: "fortigate"},
+			expected: `invalid scheme "ftp" in URL`,
+		},
+		{
+			name:     "missing vendor",
+			plugin:   &Firewall{URL: "https://localhost"},
+			expected: "'vendor' required",
+		},
+		{
+			name:     "invalid vendor",
+			plugin:   &Firewall{URL: "https://localhost", Vendor: "foo"},
+			expected: `invalid vendor "foo"`,
+		},
+		{
+			name:     "missing api key",
+			plugin:   &Firewall{URL: "https://localhost", Vendor: "panos"},
+			expected: "'api_key' required",
+		},
+		{
+			name: "invalid collect",
+			plugin: &Firewall{
+				URL:     "https://localhost",
+				Vendor:  "panos",
+				APIKey:  config.NewSecret([]byte("secret")),
+				Collect: []string{"sessions", "foo"},
+			},
+			expected: `invalid value "foo" in 'collect'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = &testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestFortigate(t *testing.T) {
+	endpoints := map[string]string{
+		"/api/v2/monitor/system/resource/usage": "fortigate_resource_usage.json",
+		"/api/v2/monitor/vpn/ssl":               "fortigate_vpn_ssl.json",
+		"/api/v2/monitor/vpn/ipsec":             "fortigate_vpn_ipsec.json",
+		"/api/v2/monitor/system/interface":      "fortigate_interface.json",
+		"/api/v2/monitor/fortiview/statistics":  "fortigate_fortiview_threat.json",
+		"/api/v2/monitor/system/ha-peer":        "fortigate_ha_peer.json",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// HA is global and must not be requested per VDOM
+		vdom := r.URL.Query().Get("vdom")
+		if r.URL.Path == "/api/v2/monitor/system/ha-peer" && vdom != "" || r.URL.Path != "/api/v2/monitor/system/ha-peer" && vdom != "root,dmz" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		fn, found := endpoints[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buf, err := os.ReadFile(filepath.Join("testdata", fn))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(buf)
+	}))
+	defer server.Close()
+
+	plugin := &Firewall{
+		URL:            server.URL,
+		Vendor:         "fortigate",
+		APIKey:         config.NewSecret([]byte("secret")),
+		VirtualSystems: []string{"root", "dmz"},
+		Log:            &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"firewall_sessions",
+			map[string]string{"device": "127.0.0.1", "vdom": "root"},
+			map[string]interface{}{"active": uint64(1520), "setup_rate": float64(37)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_sessions",
+			map[string]string{"device": "127.0.0.1", "vdom": "dmz"},
+			map[string]interface{}{"active": uint64(212), "setup_rate": float64(4)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_vpn",
+			map[string]string{"device": "127.0.0.1", "vdom": "root"},
+			map[string]interface{}{"ssl_vpn_users": int64(2), "ipsec_tunnels": int64(2), "ipsec_tunnels_up": int64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_vpn",
+			map[string]string{"device": "127.0.0.1", "vdom": "dmz"},
+			map[string]interface{}{"ssl_vpn_users": int64(0), "ipsec_tunnels": int64(0), "ipsec_tunnels_up": int64(0)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_interface",
+			map[string]string{"device": "127.0.0.1", "vdom": "root", "interface": "port1"},
+			map[string]interface{}{
+				"rx_bytes":   uint64(420000),
+				"tx_bytes":   uint64(150000),
+				"rx_packets": uint64(3400),
+				"tx_packets": uint64(1200),
+				"rx_errors":  uint64(2),
+				"tx_errors":  uint64(0),
+				"link":       true,
+				"speed_mbps": float64(1000),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_interface",
+			map[string]string{"device": "127.0.0.1", "vdom": "dmz", "interface": "port3"},
+			map[string]interface{}{
+				"rx_bytes":   uint64(0),
+				"tx_bytes":   uint64(0),
+				"rx_packets": uint64(0),
+				"tx_packets": uint64(0),
+				"rx_errors":  uint64(0),
+				"tx_errors":  uint64(0),
+				"link":       false,
+				"speed_mbps": float64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_threats",
+			map[string]string{"device": "127.0.0.1", "vdom": "root", "name": "Eicar.Virus.Test.File", "severity": "critical"},
+			map[string]interface{}{"count": uint64(3)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_threats",
+			map[string]string{"device": "127.0.0.1", "vdom": "root", "name": "Botnet.C2", "severity": "high"},
+			map[string]interface{}{"count": uint64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_ha",
+			map[string]string{"device": "127.0.0.1"},
+			map[string]interface{}{
+				"enabled":     true,
+				"members":     int64(2),
+				"local_state": "primary",
+				"peer_state":  "secondary",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestFortigateUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	plugin := &Firewall{
+		URL:     server.URL,
+		Vendor:  "fortigate",
+		APIKey:  config.NewSecret([]byte("wrong")),
+		Collect: []string{"sessions"},
+		Log:     &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Gather(&acc), `querying "/api/v2/monitor/system/resource/usage" failed: received status "401 Unauthorized"`)
+}
+
+func TestPanos(t *testing.T) {
+	commands := map[string]string{
+		"<show><session><info></info></session></show>":                                               "panos_session_info.xml",
+		"<show><session><meter></meter></session></show>":                                             "panos_session_meter.xml",
+		"<show><global-protect-gateway><current-user></current-user></global-protect-gateway></show>": "panos_gp_users.xml",
+		"<show><vpn><flow></flow></vpn></show>":                                                       "panos_vpn_flow.xml",
+		"<show><counter><interface>all</interface></counter></show>":                                  "panos_counter_interface.xml",
+		"<show><counter><global><filter><category>ctd</category></filter></global></counter></show>":  "panos_counter_ctd.xml",
+		"<show><high-availability><state></state></high-availability></show>":                         "panos_ha_state.xml",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/" || r.URL.Query().Get("type") != "op" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("X-PAN-KEY") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		fn, found := commands[r.URL.Query().Get("cmd")]
+		if !found {
+			_, _ = w.Write([]byte(`<response status="error"><msg><line>unknown command</line></msg></response>`))
+			return
+		}
+		buf, err := os.ReadFile(filepath.Join("testdata", fn))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(buf)
+	}))
+	defer server.Close()
+
+	plugin := &Firewall{
+		URL:            server.URL,
+		Vendor:         "panos",
+		APIKey:         config.NewSecret([]byte("secret")),
+		VirtualSystems: []string{"vsys1", "vsys2"},
+		Log:            &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"firewall_sessions",
+			map[string]string{"device": "127.0.0.1"},
+			map[string]interface{}{
+				"active":          uint64(1843),
+				"max":             uint64(262142),
+				"tcp":             uint64(1422),
+				"udp":             uint64(412),
+				"icmp":            uint64(9),
+				"setup_rate":      float64(58),
+				"throughput_kbps": float64(25311),
+				"packet_rate":     float64(4120),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_sessions",
+			map[string]string{"device": "127.0.0.1", "vsys": "vsys1"},
+			map[string]interface{}{"active": uint64(1500), "max": uint64(100000)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_sessions",
+			map[string]string{"device": "127.0.0.1", "vsys": "vsys2"},
+			map[string]interface{}{"active": uint64(343), "max": uint64(50000)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_vpn",
+			map[string]string{"device": "127.0.0.1"},
+			map[string]interface{}{"ipsec_tunnels": int64(2), "ipsec_tunnels_up": int64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_vpn",
+			map[string]string{"device": "127.0.0.1", "vsys": "vsys1"},
+			map[string]interface{}{"ssl_vpn_users": int64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_vpn",
+			map[string]string{"device": "127.0.0.1", "vsys": "vsys2"},
+			map[string]interface{}{"ssl_vpn_users": int64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_interface",
+			map[string]string{"device": "127.0.0.1", "interface": "ethernet1/1"},
+			map[string]interface{}{
+				"rx_bytes":   uint64(420000),
+				"tx_bytes":   uint64(150000),
+				"rx_packets": uint64(3400),
+				"tx_packets": uint64(1200),
+				"rx_errors":  uint64(2),
+				"rx_drops":   uint64(5),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_interface",
+			map[string]string{"device": "127.0.0.1", "interface": "ethernet1/2"},
+			map[string]interface{}{
+				"rx_bytes":   uint64(0),
+				"tx_bytes":   uint64(0),
+				"rx_packets": uint64(0),
+				"tx_packets": uint64(0),
+				"rx_errors":  uint64(0),
+				"rx_drops":   uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_threats",
+			map[string]string{"device": "127.0.0.1", "name": "ctd_threat_drop", "severity": "drop"},
+			map[string]interface{}{"count": uint64(17), "rate": float64(0)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_threats",
+			map[string]string{"device": "127.0.0.1", "name": "ctd_pkt_scanned", "severity": "info"},
+			map[string]interface{}{"count": uint64(9231), "rate": float64(12)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewall_ha",
+			map[string]string{"device": "127.0.0.1", "mode": "Active-Passive"},
+			map[string]interface{}{
+				"enabled":        true,
+				"local_state":    "active",
+				"peer_state":     "passive",
+				"peer_connected": true,
+				"synchronized":   true,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestPanosCommandFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<response status="error"><msg><line>Invalid credentials.</line></msg></response>`))
+	}))
+	defer server.Close()
+
+	plugin := &Firewall{
+		URL:     server.URL,
+		Vendor:  "panos",
+		APIKey:  config.NewSecret([]byte("wrong")),
+		Collect: []string{"ha"},
+		Log:     &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Gather(&acc), "Invalid credentials.")
+}
@@ -0,0 +1,300 @@
+package firewall
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// fortigateResponse is the envelope of the FortiOS REST API responses, the
+// API returns an array of envelopes if multiple VDOMs are requested
+type fortigateResponse struct {
+	Status  string          `json:"status"`
+	VDOM    string          `json:"vdom"`
+	Serial  string          `json:"serial"`
+	Results json.RawMessage `json:"results"`
+}
+
+type fortigateUsage struct {
+	Current float64 `json:"current"`
+}
+
+type fortigateInterface struct {
+	Name      string   `json:"name"`
+	Link      *bool    `json:"link"`
+	Speed     *float64 `json:"speed"`
+	RxBytes   uint64   `json:"rx_bytes"`
+	TxBytes   uint64   `json:"tx_bytes"`
+	RxPackets uint64   `json:"rx_packets"`
+	TxPackets uint64   `json:"tx_packets"`
+	RxErrors  *uint64  `json:"rx_errors"`
+	TxErrors  *uint64  `json:"tx_errors"`
+}
+
+type fortigateTunnel struct {
+	Name    string `json:"name"`
+	ProxyID []struct {
+		Status string `json:"status"`
+	} `json:"proxyid"`
+}
+
+type fortigateThreat struct {
+	Threat string `json:"threat"`
+	Level  string `json:"level"`
+	Count  uint64 `json:"count"`
+}
+
+type fortigateHAPeer struct {
+	SerialNo string `json:"serial_no"`
+	Hostname string `json:"hostname"`
+	Primary  bool   `json:"primary"`
+}
+
+// gatherFortigate collects the statistics from a FortiGate using the
+// monitor endpoints of the FortiOS REST API
+func (f *Firewall) gatherFortigate() (*snapshot, error) {
+	var s snapshot
+
+	if f.collects("sessions") {
+		responses, err := f.fortigateGet("/api/v2/monitor/system/resource/usage", nil, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range responses {
+			var usage map[string][]fortigateUsage
+			if err := json.Unmarshal(r.Results, &usage); err != nil {
+				return nil, fmt.Errorf("decoding resource usage failed: %w", err)
+			}
+			stats := sessionStats{domain: r.VDOM}
+			if v := usage["session"]; len(v) > 0 {
+				stats.active = uint64(v[0].Current)
+			}
+			if v := usage["setuprate"]; len(v) > 0 {
+				rate := v[0].Current
+				stats.setupRate = &rate
+			}
+			s.sessions = append(s.sessions, stats)
+		}
+	}
+
+	if f.collects("vpn") {
+		users := make(map[string]int64)
+		responses, err := f.fortigateGet("/api/v2/monitor/vpn/ssl", nil, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range responses {
+			var sessions []json.RawMessage
+			if err := json.Unmarshal(r.Results, &sessions); err != nil {
+				return nil, fmt.Errorf("decoding SSL-VPN sessions failed: %w", err)
+			}
+			users[r.VDOM] = int64(len(sessions))
+		}
+
+		responses, err = f.fortigateGet("/api/v2/monitor/vpn/ipsec", nil, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range responses {
+			var tunnels []fortigateTunnel
+			if err := json.Unmarshal(r.Results, &tunnels); err != nil {
+				return nil, fmt.Errorf("decoding IPsec tunnels failed: %w", err)
+			}
+
+			// A tunnel is considered up if any of its phase 2 selectors is up
+			total, up := int64(len(tunnels)), int64(0)
+			for _, t := range tunnels {
+				for _, p := range t.ProxyID {
+					if p.Status == "up" {
+						up++
+						break
+					}
+				}
+			}
+			stats := vpnStats{
+				domain:         r.VDOM,
+				ipsecTunnels:   &total,
+				ipsecTunnelsUp: &up,
+			}
+			if n, found := users[r.VDOM]; found {
+				stats.sslVPNUsers = &n
+				delete(users, r.VDOM)
+			}
+			s.vpns = append(s.vpns, stats)
+		}
+		for _, vdom := range slices.Sorted(maps.Keys(users)) {
+			n := users[vdom]
+			s.vpns = append(s.vpns, vpnStats{domain: vdom, sslVPNUsers: &n})
+		}
+	}
+
+	if f.collects("interfaces") {
+		responses, err := f.fortigateGet("/api/v2/monitor/system/interface", nil, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range responses {
+			var interfaces map[string]fortigateInterface
+			if err := json.Unmarshal(r.Results, &interfaces); err != nil {
+				return nil, fmt.Errorf("decoding interfaces failed: %w", err)
+			}
+			for _, name := range slices.Sorted(maps.Keys(interfaces)) {
+				i := interfaces[name]
+				if i.Name != "" {
+					name = i.Name
+				}
+				s.interfaces = append(s.interfaces, interfaceStats{
+					domain:    r.VDOM,
+					name:      name,
+					rxBytes:   i.RxBytes,
+					txBytes:   i.TxBytes,
+					rxPackets: i.RxPackets,
+					txPackets: i.TxPackets,
+					rxErrors:  i.RxErrors,
+					txErrors:  i.TxErrors,
+					link:      i.Link,
+					speed:     i.Speed,
+				})
+			}
+		}
+	}
+
+	if f.collects("threats") {
+		query := url.Values{
+			"realtime":  {"true"},
+			"report_by": {"threat"},
+		}
+		responses, err := f.fortigateGet("/api/v2/monitor/fortiview/statistics", query, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range responses {
+			var result struct {
+				Details []fortigateThreat `json:"details"`
+			}
+			if err := json.Unmarshal(r.Results, &result); err != nil {
+				return nil, fmt.Errorf("decoding threat statistics failed: %w", err)
+			}
+			for _, t := range result.Details {
+				s.threats = append(s.threats, threatStats{
+					domain:   r.VDOM,
+					name:     t.Threat,
+					severity: t.Level,
+					count:    t.Count,
+				})
+			}
+		}
+	}
+
+	if f.collects("ha") {
+		// The HA state is global so do not query per VDOM
+		responses, err := f.fortigateGet("/api/v2/monitor/system/ha-peer", nil, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(responses) > 0 {
+			var peers []fortigateHAPeer
+			if err := json.Unmarshal(responses[0].Results, &peers); err != nil {
+				return nil, fmt.Errorf("decoding HA peers failed: %w", err)
+			}
+			s.ha = fortigateHA(responses[0].Serial, peers)
+		}
+	}
+
+	return &s, nil
+}
+
+// fortigateHA determines the HA state of the cluster from the perspective
+// of the device with the given serial number
+func fortigateHA(serial string, peers []fortigateHAPeer) *haStats {
+	members := int64(len(peers))
+	stats := &haStats{
+		enabled: members > 1,
+		members: &members,
+	}
+	if !stats.enabled {
+		return stats
+	}
+
+	state := func(primary bool) string {
+		if primary {
+			return "primary"
+		}
+		return "secondary"
+	}
+	for _, p := range peers {
+		if p.SerialNo == serial {
+			stats.localState = state(p.Primary)
+		} else if stats.peerState == "" {
+			stats.peerState = state(p.Primary)
+		}
+	}
+	return stats
+}
+
+// fortigateGet queries the given endpoint, the configured VDOMs are
+// requested if perVDOM is set
+func (f *Firewall) fortigateGet(endpoint string, query url.Values, perVDOM bool) ([]fortigateResponse, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	if perVDOM && len(f.VirtualSystems) > 0 {
+		query.Set("vdom", strings.Join(f.VirtualSystems, ","))
+	}
+	address := f.URL + endpoint
+	if len(query) > 0 {
+		address += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return nil, err
+	}
+	key, err := f.APIKey.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting API key failed: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+key.String())
+	key.Destroy()
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("querying %q failed: received status %q", endpoint, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response failed: %w", err)
+	}
+
+	var responses []fortigateResponse
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		err = json.Unmarshal(body, &responses)
+	} else {
+		var r fortigateResponse
+		err = json.Unmarshal(body, &r)
+		responses = append(responses, r)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decoding response of %q failed: %w", endpoint, err)
+	}
+
+	for _, r := range responses {
+		if r.Status != "success" {
+			return nil, fmt.Errorf("querying %q for VDOM %q failed with status %q", endpoint, r.VDOM, r.Status)
+		}
+	}
+	return responses, nil
+}
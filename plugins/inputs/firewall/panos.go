@@ -0,0 +1,294 @@
+package firewall
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// panosResponse is the envelope of the PAN-OS XML API responses
+type panosResponse struct {
+	Status  string `xml:"status,attr"`
+	Message struct {
+		Text  string   `xml:",chardata"`
+		Lines []string `xml:"line"`
+	} `xml:"msg"`
+	Result struct {
+		Inner []byte `xml:",innerxml"`
+	} `xml:"result"`
+}
+
+type panosSessionInfo struct {
+	Maximum uint64  `xml:"num-max"`
+	Active  uint64  `xml:"num-active"`
+	TCP     uint64  `xml:"num-tcp"`
+	UDP     uint64  `xml:"num-udp"`
+	ICMP    uint64  `xml:"num-icmp"`
+	CPS     float64 `xml:"cps"`
+	Kbps    float64 `xml:"kbps"`
+	PPS     float64 `xml:"pps"`
+}
+
+type panosSessionMeter struct {
+	Entries []struct {
+		VSys    string `xml:"vsys"`
+		Current uint64 `xml:"current"`
+		Maximum uint64 `xml:"maximum"`
+	} `xml:"entry"`
+}
+
+type panosGlobalProtectUsers struct {
+	Entries []struct {
+		VSys     string `xml:"vsys"`
+		Username string `xml:"username"`
+	} `xml:"entry"`
+}
+
+type panosVPNFlow struct {
+	Tunnels []struct {
+		Name  string `xml:"name"`
+		State string `xml:"state"`
+	} `xml:"IPSec>entry"`
+}
+
+type panosInterfaceCounters struct {
+	Entries []struct {
+		Name     string `xml:"name"`
+		RxBytes  uint64 `xml:"ibytes"`
+		TxBytes  uint64 `xml:"obytes"`
+		RxPacket uint64 `xml:"ipackets"`
+		TxPacket uint64 `xml:"opackets"`
+		RxErrors uint64 `xml:"ierrors"`
+		RxDrops  uint64 `xml:"idrops"`
+	} `xml:"ifnet>ifnet>entry"`
+}
+
+type panosGlobalCounters struct {
+	Entries []struct {
+		Name     string  `xml:"name"`
+		Value    uint64  `xml:"value"`
+		Rate     float64 `xml:"rate"`
+		Severity string  `xml:"severity"`
+	} `xml:"global>counters>entry"`
+}
+
+type panosHAState struct {
+	Enabled string `xml:"enabled"`
+	Group   struct {
+		Mode      string `xml:"mode"`
+		LocalInfo struct {
+			State string `xml:"state"`
+		} `xml:"local-info"`
+		PeerInfo struct {
+			State      string `xml:"state"`
+			ConnStatus string `xml:"conn-status"`
+		} `xml:"peer-info"`
+		RunningSync string `xml:"running-sync"`
+	} `xml:"group"`
+}
+
+// gatherPanos collects the statistics from a PAN-OS firewall using
+// operational commands of the XML API
+func (f *Firewall) gatherPanos() (*snapshot, error) {
+	var s snapshot
+
+	if f.collects("sessions") {
+		var info panosSessionInfo
+		if err := f.panosOp("<show><session><info></info></session></show>", &info); err != nil {
+			return nil, err
+		}
+		s.sessions = append(s.sessions, sessionStats{
+			active:         info.Active,
+			maximum:        &info.Maximum,
+			tcp:            &info.TCP,
+			udp:            &info.UDP,
+			icmp:           &info.ICMP,
+			setupRate:      &info.CPS,
+			throughputKbps: &info.Kbps,
+			packetRate:     &info.PPS,
+		})
+
+		// Sessions per virtual system are only available on multi-vsys
+		// firewalls, so only query them if virtual systems are configured
+		if len(f.VirtualSystems) > 0 {
+			var meter panosSessionMeter
+			if err := f.panosOp("<show><session><meter></meter></session></show>", &meter); err != nil {
+				return nil, err
+			}
+			for _, e := range meter.Entries {
+				vsys := panosVSys(e.VSys)
+				if !slices.Contains(f.VirtualSystems, vsys) {
+					continue
+				}
+				maximum := e.Maximum
+				s.sessions = append(s.sessions, sessionStats{
+					domain:  vsys,
+					active:  e.Current,
+					maximum: &maximum,
+				})
+			}
+		}
+	}
+
+	if f.collects("vpn") {
+		var users panosGlobalProtectUsers
+		cmd := "<show><global-protect-gateway><current-user></current-user></global-protect-gateway></show>"
+		if err := f.panosOp(cmd, &users); err != nil {
+			return nil, err
+		}
+		var flow panosVPNFlow
+		if err := f.panosOp("<show><vpn><flow></flow></vpn></show>", &flow); err != nil {
+			return nil, err
+		}
+
+		total, up := int64(len(flow.Tunnels)), int64(0)
+		for _, t := range flow.Tunnels {
+			if t.State == "active" {
+				up++
+			}
+		}
+		global := vpnStats{
+			ipsecTunnels:   &total,
+			ipsecTunnelsUp: &up,
+		}
+
+		if len(f.VirtualSystems) == 0 {
+			n := int64(len(users.Entries))
+			global.sslVPNUsers = &n
+			s.vpns = append(s.vpns, global)
+		} else {
+			s.vpns = append(s.vpns, global)
+			for _, vsys := range f.VirtualSystems {
+				var n int64
+				for _, e := range users.Entries {
+					if panosVSys(e.VSys) == vsys {
+						n++
+					}
+				}
+				s.vpns = append(s.vpns, vpnStats{domain: vsys, sslVPNUsers: &n})
+			}
+		}
+	}
+
+	if f.collects("interfaces") {
+		var counters panosInterfaceCounters
+		if err := f.panosOp("<show><counter><interface>all</interface></counter></show>", &counters); err != nil {
+			return nil, err
+		}
+		for _, e := range counters.Entries {
+			rxErrors, rxDrops := e.RxErrors, e.RxDrops
+			s.interfaces = append(s.interfaces, interfaceStats{
+				name:      e.Name,
+				rxBytes:   e.RxBytes,
+				txBytes:   e.TxBytes,
+				rxPackets: e.RxPacket,
+				txPackets: e.TxPacket,
+				rxErrors:  &rxErrors,
+				rxDrops:   &rxDrops,
+			})
+		}
+	}
+
+	if f.collects("threats") {
+		// Use the counters of the content and threat detection (CTD) engine
+		var counters panosGlobalCounters
+		cmd := "<show><counter><global><filter><category>ctd</category></filter></global></counter></show>"
+		if err := f.panosOp(cmd, &counters); err != nil {
+			return nil, err
+		}
+		for _, e := range counters.Entries {
+			rate := e.Rate
+			s.threats = append(s.threats, threatStats{
+				name:     e.Name,
+				severity: e.Severity,
+				count:    e.Value,
+				rate:     &rate,
+			})
+		}
+	}
+
+	if f.collects("ha") {
+		var state panosHAState
+		if err := f.panosOp("<show><high-availability><state></state></high-availability></show>", &state); err != nil {
+			return nil, err
+		}
+		stats := &haStats{enabled: state.Enabled == "yes"}
+		if stats.enabled {
+			connected := state.Group.PeerInfo.ConnStatus == "up"
+			synchronized := state.Group.RunningSync == "synchronized"
+			stats.mode = state.Group.Mode
+			stats.localState = state.Group.LocalInfo.State
+			stats.peerState = state.Group.PeerInfo.State
+			stats.peerConnected = &connected
+			stats.synchronized = &synchronized
+		}
+		s.ha = stats
+	}
+
+	return &s, nil
+}
+
+// panosOp executes the given operational command and decodes the result
+func (f *Firewall) panosOp(cmd string, result interface{}) error {
+	query := url.Values{
+		"type": {"op"},
+		"cmd":  {cmd},
+	}
+	req, err := http.NewRequest("GET", f.URL+"/api/?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	key, err := f.APIKey.Get()
+	if err != nil {
+		return fmt.Errorf("getting API key failed: %w", err)
+	}
+	req.Header.Set("X-PAN-KEY", key.String())
+	key.Destroy()
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("executing %q failed: received status %q", cmd, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response failed: %w", err)
+	}
+
+	var r panosResponse
+	if err := xml.Unmarshal(body, &r); err != nil {
+		return fmt.Errorf("decoding response of %q failed: %w", cmd, err)
+	}
+	if r.Status != "success" {
+		msg := strings.TrimSpace(r.Message.Text)
+		if len(r.Message.Lines) > 0 {
+			msg = strings.Join(r.Message.Lines, " ")
+		}
+		return fmt.Errorf("executing %q failed: %s", cmd, msg)
+	}
+
+	inner := append(append([]byte("<result>"), r.Result.Inner...), "</result>"...)
+	if err := xml.Unmarshal(inner, result); err != nil {
+		return fmt.Errorf("decoding result of %q failed: %w", cmd, err)
+	}
+	return nil
+}
+
+// panosVSys converts the numeric virtual system IDs reported by some
+// commands into the "vsysN" form
+func panosVSys(id string) string {
+	if _, err := strconv.Atoi(id); err == nil {
+		return "vsys" + id
+	}
+	return id
+}
@@ -0,0 +1,208 @@
+package github_actions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// get queries the given API URL and decodes the response into the given
+// value, the returned string is the URL of the next page if any
+func (g *GitHubActions) get(address string, v interface{}) (string, error) {
+	if err := g.checkRateLimit(); err != nil {
+		return "", err
+	}
+
+	authorization, err := g.authorization()
+	if err != nil {
+		return "", err
+	}
+
+	request, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return "", fmt.Errorf("creating request failed: %w", err)
+	}
+	setHeaders(request, authorization)
+
+	response, err := g.client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	g.updateRateLimit(response)
+	if response.StatusCode != http.StatusOK {
+		if err := g.checkRateLimit(); err != nil {
+			g.rateLimitBlocks.Incr(1)
+			return "", err
+		}
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return "", fmt.Errorf("request returned %q: %s", response.Status, string(body))
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(v); err != nil {
+		return "", fmt.Errorf("decoding response failed: %w", err)
+	}
+
+	return nextLink(response.Header.Get("Link")), nil
+}
+
+// list queries all pages of the given API endpoint and collects the items
+// listed under the given key of the responses
+func list[T any](g *GitHubActions, endpoint string, query url.Values, key string) ([]T, error) {
+	q := make(url.Values, len(query)+1)
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("per_page", "100")
+
+	var result []T
+	for address := g.URL + endpoint + "?" + q.Encode(); address != ""; {
+		var page map[string]json.RawMessage
+		next, err := g.get(address, &page)
+		if err != nil {
+			return nil, err
+		}
+		if raw, found := page[key]; found {
+			var items []T
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil, fmt.Errorf("decoding %q failed: %w", key, err)
+			}
+			result = append(result, items...)
+		}
+		address = next
+	}
+
+	return result, nil
+}
+
+// checkRateLimit returns an error if the remaining requests of the current
+// rate-limit window fell to the configured reserve
+func (g *GitHubActions) checkRateLimit() error {
+	if g.remaining < 0 || g.remaining > g.RateLimitReserve || !time.Now().Before(g.reset) {
+		return nil
+	}
+	return fmt.Errorf("rate limit reached with %d requests remaining, resets at %s", g.remaining, g.reset.Format(time.RFC3339))
+}
+
+// updateRateLimit tracks the rate-limit state reported by the server
+func (g *GitHubActions) updateRateLimit(response *http.Response) {
+	header := response.Header
+	if v, err := strconv.ParseInt(header.Get("X-RateLimit-Limit"), 10, 64); err == nil {
+		g.rateLimit.Set(v)
+	}
+	if v, err := strconv.ParseInt(header.Get("X-RateLimit-Remaining"), 10, 64); err == nil {
+		g.remaining = v
+		g.rateLimitRemaining.Set(v)
+	}
+	if v, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		g.reset = time.Unix(v, 0)
+	}
+
+	// Secondary rate limits are signaled by a retry-after header
+	if response.StatusCode == http.StatusForbidden || response.StatusCode == http.StatusTooManyRequests {
+		if v, err := strconv.ParseInt(header.Get("Retry-After"), 10, 64); err == nil {
+			g.remaining = 0
+			g.reset = time.Now().Add(time.Duration(v) * time.Second)
+		}
+	}
+}
+
+// authorization returns the authorization header value, for app
+// authentication an installation token is created or renewed if necessary
+func (g *GitHubActions) authorization() (string, error) {
+	if g.appKey == nil {
+		if g.Token.Empty() {
+			return "", nil
+		}
+		token, err := g.Token.Get()
+		if err != nil {
+			return "", fmt.Errorf("getting token failed: %w", err)
+		}
+		defer token.Destroy()
+		return "Bearer " + strings.TrimSpace(token.String()), nil
+	}
+
+	// Renew the installation token shortly before it expires
+	if g.installationToken.Token == "" || time.Until(g.installationToken.ExpiresAt) < time.Minute {
+		token, err := g.createInstallationToken()
+		if err != nil {
+			return "", fmt.Errorf("creating installation token failed: %w", err)
+		}
+		g.installationToken = token
+	}
+
+	return "Bearer " + g.installationToken.Token, nil
+}
+
+// createInstallationToken requests an access token for the app installation
+// using a JSON web token signed with the private key of the app
+func (g *GitHubActions) createInstallationToken() (installationToken, error) {
+	// Backdate the token to allow for clock drift as recommended by GitHub
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Issuer:    strconv.FormatInt(g.AppID, 10),
+		IssuedAt:  jwt.NewNumericDate(now.Add(-time.Minute)),
+		ExpiresAt: jwt.NewNumericDate(now.Add(9 * time.Minute)),
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(g.appKey)
+	if err != nil {
+		return installationToken{}, fmt.Errorf("signing web token failed: %w", err)
+	}
+
+	address := fmt.Sprintf("%s/app/installations/%d/access_tokens", g.URL, g.InstallationID)
+	request, err := http.NewRequest("POST", address, nil)
+	if err != nil {
+		return installationToken{}, fmt.Errorf("creating request failed: %w", err)
+	}
+	setHeaders(request, "Bearer "+signed)
+
+	response, err := g.client.Do(request)
+	if err != nil {
+		return installationToken{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return installationToken{}, fmt.Errorf("request returned %q: %s", response.Status, string(body))
+	}
+
+	var token installationToken
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return installationToken{}, fmt.Errorf("decoding response failed: %w", err)
+	}
+	if token.Token == "" {
+		return installationToken{}, errors.New("empty token received")
+	}
+
+	return token, nil
+}
+
+func setHeaders(request *http.Request, authorization string) {
+	request.Header.Set("Accept", "application/vnd.github+json")
+	request.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+}
+
+// nextLink extracts the URL of the next page from the given link header
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		address, params, found := strings.Cut(link, ";")
+		if !found || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(address), "<>")
+	}
+	return ""
+}
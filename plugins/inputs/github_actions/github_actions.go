@@ -0,0 +1,292 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package github_actions
+
+import (
+	"context"
+	"crypto/rsa"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type GitHubActions struct {
+	URL              string          `toml:"url"`
+	Token            config.Secret   `toml:"token"`
+	AppID            int64           `toml:"app_id"`
+	InstallationID   int64           `toml:"installation_id"`
+	AppKeyFile       string          `toml:"app_key_file"`
+	Repositories     []string        `toml:"repositories"`
+	WorkflowInclude  []string        `toml:"workflow_include"`
+	WorkflowExclude  []string        `toml:"workflow_exclude"`
+	MaxRunAge        config.Duration `toml:"max_run_age"`
+	MaxRunDuration   config.Duration `toml:"max_run_duration"`
+	GatherJobs       bool            `toml:"gather_jobs"`
+	GatherBilling    bool            `toml:"gather_billing"`
+	RateLimitReserve int64           `toml:"rate_limit_reserve"`
+	Log              telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	server         string
+	client         *http.Client
+	workflowFilter filter.Filter
+
+	// App authentication
+	appKey            *rsa.PrivateKey
+	installationToken installationToken
+
+	// Rate-limit state, remaining is negative if unknown
+	remaining          int64
+	reset              time.Time
+	rateLimit          selfstat.Stat
+	rateLimitRemaining selfstat.Stat
+	rateLimitBlocks    selfstat.Stat
+
+	// cutoffs contains the end of the last gathered time window per repository
+	cutoffs map[string]time.Time
+}
+
+func (*GitHubActions) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GitHubActions) Init() error {
+	if g.URL == "" {
+		return errors.New("'url' required")
+	}
+	g.URL = strings.TrimRight(g.URL, "/")
+	u, err := url.Parse(g.URL)
+	if err != nil {
+		return fmt.Errorf("parsing URL %q failed: %w", g.URL, err)
+	}
+	g.server = u.Hostname()
+
+	if len(g.Repositories) == 0 {
+		return errors.New("'repositories' required")
+	}
+	for _, repo := range g.Repositories {
+		owner, name, found := strings.Cut(repo, "/")
+		if !found || owner == "" || name == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("invalid repository %q, expected <owner>/<name>", repo)
+		}
+	}
+
+	if g.AppID != 0 || g.InstallationID != 0 || g.AppKeyFile != "" {
+		if !g.Token.Empty() {
+			return errors.New("'token' cannot be used together with app authentication")
+		}
+		if g.AppID == 0 {
+			return errors.New("'app_id' required for app authentication")
+		}
+		if g.InstallationID == 0 {
+			return errors.New("'installation_id' required for app authentication")
+		}
+		if g.AppKeyFile == "" {
+			return errors.New("'app_key_file' required for app authentication")
+		}
+		buf, err := os.ReadFile(g.AppKeyFile)
+		if err != nil {
+			return fmt.Errorf("reading app key failed: %w", err)
+		}
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(buf)
+		if err != nil {
+			return fmt.Errorf("parsing app key failed: %w", err)
+		}
+		g.appKey = key
+	}
+
+	if g.RateLimitReserve < 0 {
+		return errors.New("'rate_limit_reserve' must not be negative")
+	}
+
+	f, err := filter.NewIncludeExcludeFilter(g.WorkflowInclude, g.WorkflowExclude)
+	if err != nil {
+		return fmt.Errorf("creating workflow filter failed: %w", err)
+	}
+	g.workflowFilter = f
+
+	client, err := g.HTTPClientConfig.CreateClient(context.Background(), g.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	g.client = client
+	g.cutoffs = make(map[string]time.Time)
+	g.remaining = -1
+
+	tags := map[string]string{"server": g.server}
+	g.rateLimit = selfstat.Register("github_actions", "rate_limit_limit", tags)
+	g.rateLimitRemaining = selfstat.Register("github_actions", "rate_limit_remaining", tags)
+	g.rateLimitBlocks = selfstat.Register("github_actions", "rate_limit_blocks", tags)
+
+	return nil
+}
+
+func (g *GitHubActions) Gather(acc telegraf.Accumulator) error {
+	now := time.Now()
+
+	// Query the repositories one after another as GitHub discourages
+	// concurrent requests and might apply secondary rate limits otherwise
+	for _, repo := range g.Repositories {
+		if err := g.gatherRepository(acc, repo, now); err != nil {
+			acc.AddError(fmt.Errorf("gathering repository %q failed: %w", repo, err))
+		}
+	}
+
+	return nil
+}
+
+func (g *GitHubActions) Stop() {
+	if g.client != nil {
+		g.client.CloseIdleConnections()
+	}
+}
+
+// gatherRepository collects the workflow runs completed since the last
+// gather cycle including their jobs and billable time if enabled
+func (g *GitHubActions) gatherRepository(acc telegraf.Accumulator, repo string, now time.Time) error {
+	since, found := g.cutoffs[repo]
+	if !found {
+		since = now.Add(-time.Duration(g.MaxRunAge))
+	}
+
+	// Runs can only be filtered by creation time so include runs created
+	// before the time window but still running at its start
+	created := since.Add(-time.Duration(g.MaxRunDuration))
+	query := url.Values{
+		"status":  {"completed"},
+		"created": {">=" + created.UTC().Format(time.RFC3339)},
+	}
+	runs, err := list[workflowRun](g, "/repos/"+repo+"/actions/runs", query, "workflow_runs")
+	if err != nil {
+		return fmt.Errorf("listing workflow runs failed: %w", err)
+	}
+
+	for _, run := range runs {
+		// The update time of a completed run is its completion time
+		if run.Status != "completed" || !run.UpdatedAt.After(since) || run.UpdatedAt.After(now) {
+			continue
+		}
+		if !g.workflowFilter.Match(run.Name) {
+			continue
+		}
+
+		tags := map[string]string{
+			"server":     g.server,
+			"repository": repo,
+			"workflow":   run.Name,
+			"event":      run.Event,
+			"branch":     run.HeadBranch,
+			"conclusion": run.Conclusion,
+		}
+		fields := map[string]interface{}{
+			"id":          run.ID,
+			"run_number":  run.RunNumber,
+			"run_attempt": run.RunAttempt,
+			"duration":    run.UpdatedAt.Sub(run.RunStartedAt).Seconds(),
+		}
+		// Re-runs start long after the creation of the run so the queue time
+		// is only meaningful for the first attempt
+		if run.RunAttempt <= 1 {
+			fields["queue_time"] = run.RunStartedAt.Sub(run.CreatedAt).Seconds()
+		}
+
+		if g.GatherJobs {
+			jobs, failed, err := g.gatherJobs(acc, repo, &run)
+			if err != nil {
+				return fmt.Errorf("gathering jobs of run %d failed: %w", run.ID, err)
+			}
+			fields["jobs"] = jobs
+			fields["jobs_failed"] = failed
+		}
+
+		if g.GatherBilling {
+			var timing runTiming
+			address := fmt.Sprintf("%s/repos/%s/actions/runs/%d/timing", g.URL, repo, run.ID)
+			if _, err := g.get(address, &timing); err != nil {
+				return fmt.Errorf("getting timing of run %d failed: %w", run.ID, err)
+			}
+			for platform, billable := range timing.Billable {
+				fields["billable_"+strings.ToLower(platform)+"_ms"] = billable.TotalMS
+			}
+		}
+
+		acc.AddFields("github_actions_run", fields, tags, run.UpdatedAt)
+	}
+
+	g.cutoffs[repo] = now
+
+	return nil
+}
+
+// gatherJobs collects the completed jobs of the latest attempt of the given
+// run and returns the number of total and failed jobs
+func (g *GitHubActions) gatherJobs(acc telegraf.Accumulator, repo string, run *workflowRun) (total, failed int64, err error) {
+	endpoint := fmt.Sprintf("/repos/%s/actions/runs/%d/jobs", repo, run.ID)
+	jobs, err := list[job](g, endpoint, url.Values{"filter": {"latest"}}, "jobs")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, j := range jobs {
+		if j.Status != "completed" || j.CompletedAt == nil {
+			continue
+		}
+		total++
+		if j.Conclusion == "failure" {
+			failed++
+		}
+
+		tags := map[string]string{
+			"server":     g.server,
+			"repository": repo,
+			"workflow":   run.Name,
+			"job":        j.Name,
+			"conclusion": j.Conclusion,
+		}
+		if j.RunnerName != "" {
+			tags["runner"] = j.RunnerName
+		}
+		if j.RunnerGroup != "" {
+			tags["runner_group"] = j.RunnerGroup
+		}
+		fields := map[string]interface{}{
+			"id":       j.ID,
+			"run_id":   j.RunID,
+			"duration": j.CompletedAt.Sub(j.StartedAt).Seconds(),
+		}
+		// Skipped jobs never start and thus are not queued
+		if !j.StartedAt.IsZero() && !j.StartedAt.Before(j.CreatedAt) {
+			fields["queue_time"] = j.StartedAt.Sub(j.CreatedAt).Seconds()
+		}
+		acc.AddFields("github_actions_job", fields, tags, *j.CompletedAt)
+	}
+
+	return total, failed, nil
+}
+
+func init() {
+	inputs.Add("github_actions", func() telegraf.Input {
+		return &GitHubActions{
+			URL:            "https://api.github.com",
+			MaxRunAge:      config.Duration(time.Hour),
+			MaxRunDuration: config.Duration(6 * time.Hour),
+			GatherJobs:     true,
+		}
+	})
+}
@@ -0,0 +1,399 @@
+package github_actions
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*GitHubActions)
+		expected string
+	}{
+		{
+			name:     "empty url",
+			modify:   func(g *GitHubActions) { g.URL = "" },
+			expected: "'url' required",
+		},
+		{
+			name:     "no repositories",
+			modify:   func(g *GitHubActions) { g.Repositories = nil },
+			expected: "'repositories' required",
+		},
+		{
+			name:     "invalid repository",
+			modify:   func(g *GitHubActions) { g.Repositories = []string{"telegraf"} },
+			expected: `invalid repository "telegraf"`,
+		},
+		{
+			name: "token with app authentication",
+			modify: func(g *GitHubActions) {
+				g.Token = config.NewSecret([]byte("mytoken"))
+				g.AppID = 42
+			},
+			expected: "'token' cannot be used together with app authentication",
+		},
+		{
+			name:     "missing installation id",
+			modify:   func(g *GitHubActions) { g.AppID = 42; g.AppKeyFile = "key.pem" },
+			expected: "'installation_id' required for app authentication",
+		},
+		{
+			name:     "missing app key",
+			modify:   func(g *GitHubActions) { g.AppID = 42; g.InstallationID = 7 },
+			expected: "'app_key_file' required for app authentication",
+		},
+		{
+			name: "invalid app key",
+			modify: func(g *GitHubActions) {
+				g.AppID = 42
+				g.InstallationID = 7
+				g.AppKeyFile = filepath.Join("testdata", "runs_1.json")
+			},
+			expected: "parsing app key failed",
+		},
+		{
+			name:     "invalid workflow filter",
+			modify:   func(g *GitHubActions) { g.WorkflowInclude = []string{"[abc"} },
+			expected: "creating workflow filter failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &GitHubActions{
+				URL:            "https://api.github.com",
+				Repositories:   []string{"influxdata/telegraf"},
+				MaxRunAge:      config.Duration(time.Hour),
+				MaxRunDuration: config.Duration(6 * time.Hour),
+				Log:            testutil.Logger{},
+			}
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGather(t *testing.T) {
+	server := httptest.NewServer(newHandler(t, "Bearer mytoken"))
+	defer server.Close()
+
+	plugin := &GitHubActions{
+		URL:             server.URL,
+		Token:           config.NewSecret([]byte("mytoken")),
+		Repositories:    []string{"influxdata/telegraf"},
+		WorkflowExclude: []string{"Nightly"},
+		MaxRunAge:       config.Duration(time.Hour),
+		MaxRunDuration:  config.Duration(6 * time.Hour),
+		GatherJobs:      true,
+		GatherBilling:   true,
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	// Fake a previous gather cycle to get a time window matching the test data
+	plugin.cutoffs["influxdata/telegraf"] = time.Date(2024, 6, 10, 6, 0, 0, 0, time.UTC)
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"github_actions_job",
+			map[string]string{
+				"server":       "127.0.0.1",
+				"repository":   "influxdata/telegraf",
+				"workflow":     "CI",
+				"job":          "build",
+				"conclusion":   "success",
+				"runner":       "GitHub Actions 2",
+				"runner_group": "GitHub Actions",
+			},
+			map[string]interface{}{
+				"id":         int64(5001),
+				"run_id":     int64(101),
+				"duration":   float64(130),
+				"queue_time": float64(10),
+			},
+			time.Date(2024, 6, 10, 6, 3, 0, 0, time.UTC),
+		),
+		metric.New(
+			"github_actions_job",
+			map[string]string{
+				"server":       "127.0.0.1",
+				"repository":   "influxdata/telegraf",
+				"workflow":     "CI",
+				"job":          "test",
+				"conclusion":   "failure",
+				"runner":       "GitHub Actions 3",
+				"runner_group": "GitHub Actions",
+			},
+			map[string]interface{}{
+				"id":         int64(5002),
+				"run_id":     int64(101),
+				"duration":   float64(140),
+				"queue_time": float64(10),
+			},
+			time.Date(2024, 6, 10, 6, 5, 30, 0, time.UTC),
+		),
+		metric.New(
+			"github_actions_run",
+			map[string]string{
+				"server":     "127.0.0.1",
+				"repository": "influxdata/telegraf",
+				"workflow":   "CI",
+				"event":      "push",
+				"branch":     "main",
+				"conclusion": "failure",
+			},
+			map[string]interface{}{
+				"id":                 int64(101),
+				"run_number":         int64(1501),
+				"run_attempt":        int64(1),
+				"duration":           float64(300),
+				"queue_time":         float64(10),
+				"jobs":               int64(2),
+				"jobs_failed":        int64(1),
+				"billable_ubuntu_ms": int64(300000),
+			},
+			time.Date(2024, 6, 10, 6, 5, 40, 0, time.UTC),
+		),
+		metric.New(
+			"github_actions_job",
+			map[string]string{
+				"server":       "127.0.0.1",
+				"repository":   "influxdata/telegraf",
+				"workflow":     "Docs",
+				"job":          "lint",
+				"conclusion":   "success",
+				"runner":       "builder-1",
+				"runner_group": "Default",
+			},
+			map[string]interface{}{
+				"id":         int64(5003),
+				"run_id":     int64(102),
+				"duration":   float64(110),
+				"queue_time": float64(5),
+			},
+			time.Date(2024, 6, 10, 6, 2, 55, 0, time.UTC),
+		),
+		metric.New(
+			"github_actions_run",
+			map[string]string{
+				"server":     "127.0.0.1",
+				"repository": "influxdata/telegraf",
+				"workflow":   "Docs",
+				"event":      "pull_request",
+				"branch":     "feature",
+				"conclusion": "success",
+			},
+			map[string]interface{}{
+				"id":                int64(102),
+				"run_number":        int64(230),
+				"run_attempt":       int64(2),
+				"duration":          float64(120),
+				"jobs":              int64(1),
+				"jobs_failed":       int64(0),
+				"billable_macos_ms": int64(120000),
+			},
+			time.Date(2024, 6, 10, 6, 3, 0, 0, time.UTC),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// The runs must only be reported once
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestAppAuthentication(t *testing.T) {
+	// Create a private key for the app
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyfile := filepath.Join(t.TempDir(), "app.pem")
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	require.NoError(t, os.WriteFile(keyfile, pem.EncodeToMemory(block), 0600))
+
+	var tokenRequests atomic.Int64
+	handler := newHandler(t, "Bearer ghs_installation")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/app/installations/7/access_tokens" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		tokenRequests.Add(1)
+
+		// Verify the web token signed by the app
+		signed := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		token, err := jwt.ParseWithClaims(signed, &jwt.RegisteredClaims{}, func(*jwt.Token) (interface{}, error) {
+			return &key.PublicKey, nil
+		}, jwt.WithValidMethods([]string{"RS256"}))
+		if err != nil || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if issuer, err := token.Claims.GetIssuer(); err != nil || issuer != "42" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		expires := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		if _, err := fmt.Fprintf(w, `{"token": "ghs_installation", "expires_at": %q}`, expires); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &GitHubActions{
+		URL:            server.URL,
+		AppID:          42,
+		InstallationID: 7,
+		AppKeyFile:     keyfile,
+		Repositories:   []string{"influxdata/telegraf"},
+		MaxRunAge:      config.Duration(time.Hour),
+		MaxRunDuration: config.Duration(6 * time.Hour),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	// The installation token must be reused across gather cycles
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Equal(t, int64(1), tokenRequests.Load())
+}
+
+func TestRateLimit(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+
+	var requests atomic.Int64
+	var secondary atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		if secondary.Load() {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "10")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		if _, err := w.Write([]byte(`{"total_count": 0, "workflow_runs": []}`)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &GitHubActions{
+		URL:              server.URL,
+		Repositories:     []string{"influxdata/telegraf"},
+		MaxRunAge:        config.Duration(time.Hour),
+		MaxRunDuration:   config.Duration(6 * time.Hour),
+		RateLimitReserve: 10,
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	// The first request succeeds but exhausts the requests outside the reserve
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	acc.Errors = nil
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "rate limit reached with 10 requests remaining")
+	require.Equal(t, int64(1), requests.Load())
+
+	// Secondary rate limits must block further requests
+	plugin.RateLimitReserve = 0
+	plugin.remaining = -1
+	secondary.Store(true)
+	acc.Errors = nil
+	require.NoError(t, plugin.Gather(&acc))
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 2)
+	require.ErrorContains(t, acc.Errors[0], "rate limit reached with 0 requests remaining")
+	require.ErrorContains(t, acc.Errors[1], "rate limit reached with 0 requests remaining")
+	require.Equal(t, int64(2), requests.Load())
+}
+
+func newHandler(t *testing.T, authorization string) http.Handler {
+	files := map[string]string{
+		"/repos/influxdata/telegraf/actions/runs/101/jobs":   "jobs_101.json",
+		"/repos/influxdata/telegraf/actions/runs/102/jobs":   "jobs_102.json",
+		"/repos/influxdata/telegraf/actions/runs/101/timing": "timing_101.json",
+		"/repos/influxdata/telegraf/actions/runs/102/timing": "timing_102.json",
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != authorization {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		query := r.URL.Query()
+		fn, found := files[r.URL.Path]
+		if r.URL.Path == "/repos/influxdata/telegraf/actions/runs" {
+			if query.Get("status") != "completed" || !strings.HasPrefix(query.Get("created"), ">=") {
+				t.Errorf("unexpected query %v", query)
+			}
+
+			// Simulate a paginated response
+			fn, found = "runs_2.json", true
+			if query.Get("page") == "" {
+				fn = "runs_1.json"
+				query.Set("page", "2")
+				next := "http://" + r.Host + r.URL.Path + "?" + query.Encode()
+				w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next", <%s>; rel="last"`, next, next))
+			}
+		}
+		if strings.HasSuffix(r.URL.Path, "/jobs") && query.Get("filter") != "latest" {
+			t.Errorf("unexpected job filter %q", query.Get("filter"))
+		}
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		buf, err := os.ReadFile(filepath.Join("testdata", fn))
+		if err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(buf); err != nil {
+			t.Error(err)
+		}
+	})
+}
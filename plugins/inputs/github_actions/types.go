@@ -0,0 +1,43 @@
+package github_actions
+
+import "time"
+
+type workflowRun struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	RunNumber    int64     `json:"run_number"`
+	RunAttempt   int64     `json:"run_attempt"`
+	Event        string    `json:"event"`
+	HeadBranch   string    `json:"head_branch"`
+	Status       string    `json:"status"`
+	Conclusion   string    `json:"conclusion"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	RunStartedAt time.Time `json:"run_started_at"`
+}
+
+type job struct {
+	ID          int64      `json:"id"`
+	RunID       int64      `json:"run_id"`
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	Conclusion  string     `json:"conclusion"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	RunnerName  string     `json:"runner_name"`
+	RunnerGroup string     `json:"runner_group_name"`
+}
+
+// runTiming contains the billable time of a run per runner operating system
+type runTiming struct {
+	Billable map[string]struct {
+		TotalMS int64 `json:"total_ms"`
+		Jobs    int64 `json:"jobs"`
+	} `json:"billable"`
+}
+
+type installationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
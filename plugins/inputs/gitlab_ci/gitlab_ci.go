@@ -0,0 +1,413 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package gitlab_ci
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type GitLabCI struct {
+	URL            string          `toml:"url"`
+	Token          config.Secret   `toml:"token"`
+	Projects       []string        `toml:"projects"`
+	Groups         []string        `toml:"groups"`
+	ProjectInclude []string        `toml:"project_include"`
+	ProjectExclude []string        `toml:"project_exclude"`
+	MaxPipelineAge config.Duration `toml:"max_pipeline_age"`
+	MaxConnections int             `toml:"max_connections"`
+	Log            telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	server        string
+	client        *http.Client
+	projectFilter filter.Filter
+
+	// cutoffs contains the end of the last gathered time window per project
+	cutoffs map[int64]time.Time
+	mu      sync.Mutex
+}
+
+func (*GitLabCI) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GitLabCI) Init() error {
+	if g.URL == "" {
+		return errors.New("'url' required")
+	}
+	g.URL = strings.TrimRight(g.URL, "/")
+	u, err := url.Parse(g.URL)
+	if err != nil {
+		return fmt.Errorf("parsing URL %q failed: %w", g.URL, err)
+	}
+	g.server = u.Hostname()
+
+	if g.MaxConnections < 1 {
+		return errors.New("'max_connections' must be positive")
+	}
+
+	f, err := filter.NewIncludeExcludeFilter(g.ProjectInclude, g.ProjectExclude)
+	if err != nil {
+		return fmt.Errorf("creating project filter failed: %w", err)
+	}
+	g.projectFilter = f
+
+	client, err := g.HTTPClientConfig.CreateClient(context.Background(), g.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	g.client = client
+	g.cutoffs = make(map[int64]time.Time)
+
+	return nil
+}
+
+func (g *GitLabCI) Gather(acc telegraf.Accumulator) error {
+	now := time.Now()
+
+	projects := g.resolveProjects(acc)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	runners := make(map[int64]*runnerStats)
+	sem := make(chan struct{}, g.MaxConnections)
+	for _, p := range projects {
+		wg.Add(1)
+		go func(p project) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			stats, err := g.gatherProject(acc, p, now)
+			if err != nil {
+				acc.AddError(fmt.Errorf("gathering project %q failed: %w", p.PathWithNamespace, err))
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for id, s := range stats {
+				if r, found := runners[id]; found {
+					r.running += s.running
+					r.finished += s.finished
+					r.busy += s.busy
+				} else {
+					runners[id] = s
+				}
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	for id, r := range runners {
+		tags := map[string]string{
+			"server":      g.server,
+			"runner_id":   strconv.FormatInt(id, 10),
+			"runner":      r.Description,
+			"runner_type": r.RunnerType,
+		}
+		fields := map[string]interface{}{
+			"jobs_running":  r.running,
+			"jobs_finished": r.finished,
+			"busy_time":     r.busy,
+		}
+		acc.AddFields("gitlab_ci_runner", fields, tags, now)
+	}
+
+	return nil
+}
+
+func (g *GitLabCI) Stop() {
+	if g.client != nil {
+		g.client.CloseIdleConnections()
+	}
+}
+
+// resolveProjects determines the projects to gather from the configured
+// projects and groups or, if none are configured, the projects the token
+// owner is a member of
+func (g *GitLabCI) resolveProjects(acc telegraf.Accumulator) []project {
+	var projects []project
+	seen := make(map[int64]bool)
+	add := func(candidates ...project) {
+		for _, p := range candidates {
+			if seen[p.ID] || !g.projectFilter.Match(p.PathWithNamespace) {
+				continue
+			}
+			seen[p.ID] = true
+			projects = append(projects, p)
+		}
+	}
+
+	for _, name := range g.Projects {
+		var p project
+		if _, err := g.get("/projects/"+url.PathEscape(name), nil, &p); err != nil {
+			acc.AddError(fmt.Errorf("getting project %q failed: %w", name, err))
+			continue
+		}
+		add(p)
+	}
+
+	query := url.Values{"archived": {"false"}, "include_subgroups": {"true"}}
+	for _, name := range g.Groups {
+		candidates, err := list[project](g, "/groups/"+url.PathEscape(name)+"/projects", query)
+		if err != nil {
+			acc.AddError(fmt.Errorf("getting projects of group %q failed: %w", name, err))
+			continue
+		}
+		add(candidates...)
+	}
+
+	if len(g.Projects) == 0 && len(g.Groups) == 0 {
+		candidates, err := list[project](g, "/projects", url.Values{"archived": {"false"}, "membership": {"true"}})
+		if err != nil {
+			acc.AddError(fmt.Errorf("getting projects failed: %w", err))
+		}
+		add(candidates...)
+	}
+
+	return projects
+}
+
+// gatherProject collects the pipelines and jobs finished since the last
+// gather cycle as well as the currently active jobs of the project
+func (g *GitLabCI) gatherProject(acc telegraf.Accumulator, p project, now time.Time) (map[int64]*runnerStats, error) {
+	g.mu.Lock()
+	since, found := g.cutoffs[p.ID]
+	g.mu.Unlock()
+	if !found {
+		since = now.Add(-time.Duration(g.MaxPipelineAge))
+	}
+
+	runners := make(map[int64]*runnerStats)
+	runnerOf := func(j *job) *runnerStats {
+		if j.Runner == nil {
+			return nil
+		}
+		r, found := runners[j.Runner.ID]
+		if !found {
+			r = &runnerStats{runner: *j.Runner}
+			runners[j.Runner.ID] = r
+		}
+		return r
+	}
+
+	// Pipelines finished in the time window, the pipelines are filtered by
+	// update time as finished pipelines might still be modified e.g. by
+	// retrying jobs.
+	query := url.Values{
+		"updated_after": {since.UTC().Format(time.RFC3339)},
+		"order_by":      {"updated_at"},
+	}
+	pipelines, err := list[pipeline](g, fmt.Sprintf("/projects/%d/pipelines", p.ID), query)
+	if err != nil {
+		return nil, fmt.Errorf("listing pipelines failed: %w", err)
+	}
+	for _, pl := range pipelines {
+		if !finishedStates[pl.Status] {
+			continue
+		}
+
+		var details pipeline
+		if _, err := g.get(fmt.Sprintf("/projects/%d/pipelines/%d", p.ID, pl.ID), nil, &details); err != nil {
+			return nil, fmt.Errorf("getting pipeline %d failed: %w", pl.ID, err)
+		}
+		if details.FinishedAt == nil || !details.FinishedAt.After(since) || details.FinishedAt.After(now) {
+			continue
+		}
+
+		jobs, err := list[job](g, fmt.Sprintf("/projects/%d/pipelines/%d/jobs", p.ID, pl.ID), nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing jobs of pipeline %d failed: %w", pl.ID, err)
+		}
+
+		var finished, failed int64
+		for i := range jobs {
+			j := &jobs[i]
+			if !finishedStates[j.Status] || j.FinishedAt == nil {
+				continue
+			}
+			finished++
+			if j.Status == "failed" {
+				failed++
+			}
+
+			tags := map[string]string{
+				"server":  g.server,
+				"project": p.PathWithNamespace,
+				"ref":     j.Ref,
+				"stage":   j.Stage,
+				"name":    j.Name,
+				"status":  j.Status,
+			}
+			fields := map[string]interface{}{
+				"id":          j.ID,
+				"pipeline_id": details.ID,
+			}
+			if j.Duration != nil {
+				fields["duration"] = *j.Duration
+			}
+			if j.QueuedDuration != nil {
+				fields["queued_duration"] = *j.QueuedDuration
+			}
+			if r := runnerOf(j); r != nil {
+				tags["runner"] = r.Description
+				r.finished++
+				if j.Duration != nil {
+					r.busy += *j.Duration
+				}
+			}
+			acc.AddFields("gitlab_ci_job", fields, tags, *j.FinishedAt)
+		}
+
+		tags := map[string]string{
+			"server":  g.server,
+			"project": p.PathWithNamespace,
+			"ref":     details.Ref,
+			"source":  details.Source,
+			"status":  details.Status,
+		}
+		fields := map[string]interface{}{
+			"id":          details.ID,
+			"jobs":        finished,
+			"jobs_failed": failed,
+		}
+		if details.Duration != nil {
+			fields["duration"] = *details.Duration
+		}
+		if details.QueuedDuration != nil {
+			fields["queued_duration"] = *details.QueuedDuration
+		}
+		acc.AddFields("gitlab_ci_pipeline", fields, tags, *details.FinishedAt)
+	}
+
+	// Currently active jobs
+	query = url.Values{"scope[]": {"pending", "running"}}
+	active, err := list[job](g, fmt.Sprintf("/projects/%d/jobs", p.ID), query)
+	if err != nil {
+		return nil, fmt.Errorf("listing active jobs failed: %w", err)
+	}
+	var running, pending int64
+	var queued float64
+	for i := range active {
+		j := &active[i]
+		switch j.Status {
+		case "running":
+			running++
+			if r := runnerOf(j); r != nil {
+				r.running++
+			}
+		case "pending":
+			pending++
+			queued = max(queued, now.Sub(j.CreatedAt).Seconds())
+		}
+	}
+	tags := map[string]string{
+		"server":  g.server,
+		"project": p.PathWithNamespace,
+	}
+	fields := map[string]interface{}{
+		"jobs_running":        running,
+		"jobs_pending":        pending,
+		"queued_duration_max": queued,
+	}
+	acc.AddFields("gitlab_ci_project", fields, tags, now)
+
+	g.mu.Lock()
+	g.cutoffs[p.ID] = now
+	g.mu.Unlock()
+
+	return runners, nil
+}
+
+// get queries the given API endpoint and decodes the response into the given
+// value, the returned string is the next page of a paginated response if any
+func (g *GitLabCI) get(endpoint string, query url.Values, v interface{}) (string, error) {
+	address := g.URL + "/api/v4" + endpoint
+	if len(query) > 0 {
+		address += "?" + query.Encode()
+	}
+	request, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return "", fmt.Errorf("creating request failed: %w", err)
+	}
+	if !g.Token.Empty() {
+		token, err := g.Token.Get()
+		if err != nil {
+			return "", fmt.Errorf("getting token failed: %w", err)
+		}
+		request.Header.Set("PRIVATE-TOKEN", strings.TrimSpace(token.String()))
+		token.Destroy()
+	}
+	request.Header.Set("Accept", "application/json")
+
+	response, err := g.client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return "", fmt.Errorf("request returned %q: %s", response.Status, string(body))
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(v); err != nil {
+		return "", fmt.Errorf("decoding response failed: %w", err)
+	}
+
+	return response.Header.Get("X-Next-Page"), nil
+}
+
+// list queries all pages of the given API endpoint
+func list[T any](g *GitLabCI, endpoint string, query url.Values) ([]T, error) {
+	q := make(url.Values, len(query)+2)
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("per_page", "100")
+
+	var result []T
+	for page := "1"; page != ""; {
+		q.Set("page", page)
+
+		var items []T
+		next, err := g.get(endpoint, q, &items)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+		page = next
+	}
+
+	return result, nil
+}
+
+func init() {
+	inputs.Add("gitlab_ci", func() telegraf.Input {
+		return &GitLabCI{
+			URL:            "https://gitlab.com",
+			MaxPipelineAge: config.Duration(time.Hour),
+			MaxConnections: 5,
+		}
+	})
+}
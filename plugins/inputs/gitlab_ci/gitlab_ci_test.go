@@ -0,0 +1,278 @@
+package gitlab_ci
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*GitLabCI)
+		expected string
+	}{
+		{
+			name:     "empty url",
+			modify:   func(g *GitLabCI) { g.URL = "" },
+			expected: "'url' required",
+		},
+		{
+			name:     "invalid max connections",
+			modify:   func(g *GitLabCI) { g.MaxConnections = 0 },
+			expected: "'max_connections' must be positive",
+		},
+		{
+			name:     "invalid project filter",
+			modify:   func(g *GitLabCI) { g.ProjectInclude = []string{"mygroup/[abc"} },
+			expected: "creating project filter failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &GitLabCI{
+				URL:            "https://gitlab.com",
+				MaxPipelineAge: config.Duration(time.Hour),
+				MaxConnections: 5,
+				Log:            testutil.Logger{},
+			}
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGather(t *testing.T) {
+	server := httptest.NewServer(newHandler(t))
+	defer server.Close()
+
+	plugin := &GitLabCI{
+		URL:            server.URL,
+		Token:          config.NewSecret([]byte("mytoken")),
+		Projects:       []string{"mygroup/myproject"},
+		Groups:         []string{"mygroup"},
+		ProjectExclude: []string{"mygroup/docs"},
+		MaxPipelineAge: config.Duration(time.Hour),
+		MaxConnections: 5,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	// Fake a previous gather cycle to get a time window matching the test data
+	plugin.cutoffs[42] = time.Date(2024, 6, 10, 6, 0, 0, 0, time.UTC)
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"gitlab_ci_job",
+			map[string]string{
+				"server":  "127.0.0.1",
+				"project": "mygroup/myproject",
+				"ref":     "main",
+				"stage":   "build",
+				"name":    "build",
+				"status":  "success",
+				"runner":  "shared-runner-1",
+			},
+			map[string]interface{}{
+				"id":              int64(1001),
+				"pipeline_id":     int64(501),
+				"duration":        62.5,
+				"queued_duration": 1.2,
+			},
+			time.Date(2024, 6, 10, 6, 1, 7, 500000000, time.UTC),
+		),
+		metric.New(
+			"gitlab_ci_job",
+			map[string]string{
+				"server":  "127.0.0.1",
+				"project": "mygroup/myproject",
+				"ref":     "main",
+				"stage":   "test",
+				"name":    "test",
+				"status":  "failed",
+				"runner":  "shared-runner-2",
+			},
+			map[string]interface{}{
+				"id":              int64(1002),
+				"pipeline_id":     int64(501),
+				"duration":        120.1,
+				"queued_duration": 3.4,
+			},
+			time.Date(2024, 6, 10, 6, 3, 10, 100000000, time.UTC),
+		),
+		metric.New(
+			"gitlab_ci_pipeline",
+			map[string]string{
+				"server":  "127.0.0.1",
+				"project": "mygroup/myproject",
+				"ref":     "main",
+				"source":  "push",
+				"status":  "failed",
+			},
+			map[string]interface{}{
+				"id":              int64(501),
+				"jobs":            int64(2),
+				"jobs_failed":     int64(1),
+				"duration":        float64(185),
+				"queued_duration": 1.2,
+			},
+			time.Date(2024, 6, 10, 6, 3, 10, 0, time.UTC),
+		),
+		metric.New(
+			"gitlab_ci_project",
+			map[string]string{
+				"server":  "127.0.0.1",
+				"project": "mygroup/myproject",
+			},
+			map[string]interface{}{
+				"jobs_running": int64(1),
+				"jobs_pending": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"gitlab_ci_runner",
+			map[string]string{
+				"server":      "127.0.0.1",
+				"runner_id":   "11",
+				"runner":      "shared-runner-1",
+				"runner_type": "instance_type",
+			},
+			map[string]interface{}{
+				"jobs_running":  int64(1),
+				"jobs_finished": int64(1),
+				"busy_time":     62.5,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"gitlab_ci_runner",
+			map[string]string{
+				"server":      "127.0.0.1",
+				"runner_id":   "12",
+				"runner":      "shared-runner-2",
+				"runner_type": "instance_type",
+			},
+			map[string]interface{}{
+				"jobs_running":  int64(0),
+				"jobs_finished": int64(1),
+				"busy_time":     120.1,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	// Only the timestamps of pipelines and jobs are predictable
+	options := []cmp.Option{testutil.SortMetrics(), testutil.IgnoreFields("queued_duration_max")}
+	actual := acc.GetTelegrafMetrics()
+	for _, m := range actual {
+		if m.Name() == "gitlab_ci_project" || m.Name() == "gitlab_ci_runner" {
+			m.SetTime(time.Unix(0, 0))
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, options...)
+
+	// The pipelines must only be reported once
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.NotEqual(t, "gitlab_ci_pipeline", m.Name())
+		require.NotEqual(t, "gitlab_ci_job", m.Name())
+	}
+}
+
+func TestGatherError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() == "/api/v4/projects/mygroup%2Fmyproject" {
+			http.ServeFile(w, r, filepath.Join("testdata", "project.json"))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	plugin := &GitLabCI{
+		URL:            server.URL,
+		Projects:       []string{"mygroup/myproject", "mygroup/unknown"},
+		MaxPipelineAge: config.Duration(time.Hour),
+		MaxConnections: 5,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 2)
+	require.ErrorContains(t, acc.Errors[0], `getting project "mygroup/unknown" failed`)
+	require.ErrorContains(t, acc.Errors[1], `gathering project "mygroup/myproject" failed`)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func newHandler(t *testing.T) http.Handler {
+	files := map[string]string{
+		"/api/v4/projects/mygroup%2Fmyproject":   "project.json",
+		"/api/v4/projects/42/pipelines":          "pipelines.json",
+		"/api/v4/projects/42/pipelines/499":      "pipeline_499.json",
+		"/api/v4/projects/42/pipelines/501":      "pipeline_501.json",
+		"/api/v4/projects/42/pipelines/501/jobs": "pipeline_501_jobs.json",
+		"/api/v4/projects/42/jobs":               "active_jobs.json",
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "mytoken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		path := r.URL.EscapedPath()
+		query := r.URL.Query()
+		fn, found := files[path]
+		switch path {
+		case "/api/v4/groups/mygroup/projects":
+			// Simulate a paginated response
+			fn, found = "group_projects_1.json", true
+			if query.Get("page") == "2" {
+				fn = "group_projects_2.json"
+			} else {
+				w.Header().Set("X-Next-Page", "2")
+			}
+		case "/api/v4/projects/42/jobs":
+			if len(query["scope[]"]) != 2 {
+				t.Errorf("unexpected job scope %v", query["scope[]"])
+			}
+		}
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buf, err := os.ReadFile(filepath.Join("testdata", fn))
+		if err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(buf); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package gitlab_ci
+
+import "time"
+
+// Finished states of pipelines and jobs
+var finishedStates = map[string]bool{
+	"success":  true,
+	"failed":   true,
+	"canceled": true,
+	"skipped":  true,
+}
+
+type project struct {
+	ID                int64  `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+}
+
+type pipeline struct {
+	ID             int64      `json:"id"`
+	Status         string     `json:"status"`
+	Source         string     `json:"source"`
+	Ref            string     `json:"ref"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+	Duration       *float64   `json:"duration"`
+	QueuedDuration *float64   `json:"queued_duration"`
+}
+
+type runner struct {
+	ID          int64  `json:"id"`
+	Description string `json:"description"`
+	RunnerType  string `json:"runner_type"`
+}
+
+type job struct {
+	ID             int64      `json:"id"`
+	Name           string     `json:"name"`
+	Stage          string     `json:"stage"`
+	Status         string     `json:"status"`
+	Ref            string     `json:"ref"`
+	CreatedAt      time.Time  `json:"created_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+	Duration       *float64   `json:"duration"`
+	QueuedDuration *float64   `json:"queued_duration"`
+	Runner         *runner    `json:"runner"`
+	Pipeline       struct {
+		ID int64 `json:"id"`
+	} `json:"pipeline"`
+}
+
+// runnerStats accumulates the job statistics of a runner over all projects
+type runnerStats struct {
+	runner
+	running  int64
+	finished int64
+	busy     float64
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/common/statecache"
 	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/common/yangmodel"
 	"github.com/influxdata/telegraf/plugins/inputs"
@@ -54,6 +55,7 @@ type GNMI struct {
 	Username                      config.Secret     `toml:"username"`
 	Password                      config.Secret     `toml:"password"`
 	Redial                        config.Duration   `toml:"redial"`
+	MaxRedial                     config.Duration   `toml:"max_redial"`
 	MaxMsgSize                    config.Size       `toml:"max_msg_size"`
 	Depth                         int32             `toml:"depth"`
 	Trace                         bool              `toml:"dump_responses"`
@@ -65,12 +67,16 @@ type GNMI struct {
 	KeepaliveTimeout              config.Duration   `toml:"keepalive_timeout"`
 	YangModelPaths                []string          `toml:"yang_model_paths"`
 	EnforceFirstNamespaceAsOrigin bool              `toml:"enforce_first_namespace_as_origin"`
+	PathTemplates                 []pathTemplate    `toml:"path_template"`
 	Log                           telegraf.Logger   `toml:"-"`
 	common_tls.ClientConfig
+	statecache.CacheConfig
 
 	// Internal state
 	internalAliases map[*pathInfo]string
+	templates       []*pathTemplate
 	decoder         *yangmodel.Decoder
+	cache           *statecache.Cache
 	cancel          context.CancelFunc
 	wg              sync.WaitGroup
 }
@@ -112,6 +118,9 @@ func (c *GNMI) Init() error {
 	if time.Duration(c.Redial) <= 0 {
 		return errors.New("redial duration must be positive")
 	}
+	if c.MaxRedial > 0 && c.MaxRedial < c.Redial {
+		return errors.New("'max_redial' must not be smaller than 'redial'")
+	}
 
 	// Check vendor_specific options configured by user
 	if err := choice.CheckSlice(c.VendorSpecific, supportedExtensions); err != nil {
@@ -130,6 +139,19 @@ func (c *GNMI) Init() error {
 	enable := c.ClientConfig.Enable != nil && *c.ClientConfig.Enable
 	c.ClientConfig.Enable = &enable
 
+	// Check the subscription modes, sample and on-change subscriptions can be
+	// mixed in the same session
+	for i := range c.Subscriptions {
+		if err := c.Subscriptions[i].checkMode(c.Log); err != nil {
+			return err
+		}
+	}
+	for i := range c.TagSubscriptions {
+		if err := c.TagSubscriptions[i].checkMode(c.Log); err != nil {
+			return err
+		}
+	}
+
 	// Split the subscriptions into "normal" and "tag" subscription
 	// and prepare them.
 	for i := len(c.Subscriptions) - 1; i >= 0; i-- {
@@ -204,6 +226,15 @@ func (c *GNMI) Init() error {
 	}
 	c.Log.Debugf("Internal alias mapping: %+v", c.internalAliases)
 
+	// Prepare the templates for measurement and field names
+	c.templates = make([]*pathTemplate, 0, len(c.PathTemplates))
+	for i := range c.PathTemplates {
+		if err := c.PathTemplates[i].init(); err != nil {
+			return fmt.Errorf("invalid path template %d: %w", i+1, err)
+		}
+		c.templates = append(c.templates, &c.PathTemplates[i])
+	}
+
 	// Warn about configures insecure cipher suites
 	insecure := common_tls.InsecureCiphers(c.ClientConfig.TLSCipherSuites)
 	if len(insecure) > 0 {
@@ -235,6 +266,13 @@ func (c *GNMI) Init() error {
 		c.decoder = decoder
 	}
 
+	// Setup the device-state cache if snapshots are requested
+	cache, err := c.CacheConfig.CreateCache()
+	if err != nil {
+		return fmt.Errorf("creating state cache failed: %w", err)
+	}
+	c.cache = cache
+
 	return nil
 }
 
@@ -288,6 +326,7 @@ func (c *GNMI) Start(acc telegraf.Accumulator) error {
 				host:                          host,
 				port:                          port,
 				aliases:                       c.internalAliases,
+				templates:                     c.templates,
 				tagsubs:                       c.TagSubscriptions,
 				maxMsgSize:                    int(c.MaxMsgSize),
 				vendorExt:                     c.VendorSpecific,
@@ -298,6 +337,7 @@ func (c *GNMI) Start(acc telegraf.Accumulator) error {
 				tagPathPrefix:                 c.PrefixTagKeyWithPath,
 				guessPathStrategy:             c.GuessPathStrategy,
 				decoder:                       c.decoder,
+				cache:                         c.cache,
 				enforceFirstNamespaceAsOrigin: c.EnforceFirstNamespaceAsOrigin,
 				log:                           c.Log,
 				ClientParameters: keepalive.ClientParameters{
@@ -306,14 +346,17 @@ func (c *GNMI) Start(acc telegraf.Accumulator) error {
 					PermitWithoutStream: false,
 				},
 			}
+			var delay time.Duration
 			for ctx.Err() == nil {
 				if err := h.subscribeGNMI(ctx, acc, tlscfg, request); err != nil && ctx.Err() == nil {
 					acc.AddError(err)
 				}
 
+				delay = c.nextRedial(delay, h.received)
+				c.Log.Debugf("Resubscribing to %s in %s", addr, delay)
 				select {
 				case <-ctx.Done():
-				case <-time.After(time.Duration(c.Redial)):
+				case <-time.After(delay):
 				}
 			}
 		}(addr)
@@ -321,7 +364,14 @@ func (c *GNMI) Start(acc telegraf.Accumulator) error {
 	return nil
 }
 
-func (*GNMI) Gather(telegraf.Accumulator) error {
+func (c *GNMI) Gather(acc telegraf.Accumulator) error {
+	if c.cache == nil {
+		return nil
+	}
+
+	for _, m := range c.cache.Snapshot(time.Now()) {
+		acc.AddMetric(m)
+	}
 	return nil
 }
 
@@ -330,6 +380,35 @@ func (c *GNMI) Stop() {
 	c.wg.Wait()
 }
 
+// nextRedial returns the delay before resubscribing to a device. Without
+// 'max_redial' the delay is constant, otherwise it is doubled for each
+// subscription not receiving any response, e.g. while the device reboots,
+// up to the maximum and reset as soon as a subscription succeeds.
+func (c *GNMI) nextRedial(current time.Duration, received bool) time.Duration {
+	redial := time.Duration(c.Redial)
+	if c.MaxRedial <= 0 || received || current < redial {
+		return redial
+	}
+	return min(2*current, time.Duration(c.MaxRedial))
+}
+
+// checkMode validates the subscription mode and removes settings not
+// applicable to the mode
+func (s *subscription) checkMode(log telegraf.Logger) error {
+	switch strings.ToLower(s.SubscriptionMode) {
+	case "", "target_defined", "sample":
+	case "on_change":
+		// The sample interval must not be set for on-change subscriptions
+		if s.SampleInterval > 0 {
+			log.Warnf("Ignoring 'sample_interval' for on-change subscription %q", s.Name)
+			s.SampleInterval = 0
+		}
+	default:
+		return fmt.Errorf("invalid subscription mode %q for subscription %q", s.SubscriptionMode, s.Name)
+	}
+	return nil
+}
+
 func (s *subscription) buildSubscription() (*gnmi.Subscription, error) {
 	gnmiPath, err := parsePath(s.Origin, s.Path, "")
 	if err != nil {
@@ -367,6 +446,14 @@ func (c *GNMI) newSubscribeRequest() (*gnmi.SubscribeRequest, error) {
 		subscriptions = append(subscriptions, sub)
 	}
 
+	// Remove subscriptions covered by other subscriptions as the device would
+	// send the same updates multiple times otherwise
+	var dropped []*gnmi.Subscription
+	subscriptions, dropped = deduplicateSubscriptions(subscriptions)
+	for _, sub := range dropped {
+		c.Log.Debugf("Skipping subscription to %q covered by another subscription", newInfoFromPath(sub.Path).String())
+	}
+
 	// Construct subscribe request
 	gnmiPath, err := parsePath(c.Origin, c.Prefix, c.Target)
 	if err != nil {
@@ -410,6 +497,63 @@ func (c *GNMI) newSubscribeRequest() (*gnmi.SubscribeRequest, error) {
 	}, nil
 }
 
+// deduplicateSubscriptions splits the subscriptions into the ones to request
+// and the ones being equal to or below the path of another subscription with
+// the same mode and intervals. For equal subscriptions the first one is kept.
+func deduplicateSubscriptions(subscriptions []*gnmi.Subscription) (unique, dropped []*gnmi.Subscription) {
+	unique = make([]*gnmi.Subscription, 0, len(subscriptions))
+	for i, sub := range subscriptions {
+		var covered bool
+		for j, other := range subscriptions {
+			if i == j || !coversSubscription(other, sub) {
+				continue
+			}
+			// Keep the first one of two equal subscriptions
+			if coversSubscription(sub, other) && i < j {
+				continue
+			}
+			covered = true
+			break
+		}
+		if covered {
+			dropped = append(dropped, sub)
+			continue
+		}
+		unique = append(unique, sub)
+	}
+	return unique, dropped
+}
+
+// coversSubscription checks if subscription a receives all updates of
+// subscription b, i.e. both have the same mode and intervals and the path of
+// b is equal to or below the path of a with a only filtering on keys also
+// used by b.
+func coversSubscription(a, b *gnmi.Subscription) bool {
+	if a.Mode != b.Mode ||
+		a.SampleInterval != b.SampleInterval ||
+		a.HeartbeatInterval != b.HeartbeatInterval ||
+		a.SuppressRedundant != b.SuppressRedundant {
+		return false
+	}
+
+	pa, pb := a.GetPath(), b.GetPath()
+	if pa.GetOrigin() != pb.GetOrigin() || len(pa.GetElem()) > len(pb.GetElem()) {
+		return false
+	}
+	for i, ea := range pa.GetElem() {
+		eb := pb.GetElem()[i]
+		if ea.Name != eb.Name {
+			return false
+		}
+		for k, v := range ea.Key {
+			if v != "*" && eb.Key[k] != v {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // ParsePath from XPath-like string to gNMI path structure
 func parsePath(origin, pathToParse, target string) (*gnmi.Path, error) {
 	gnmiPath, err := xpath.ToGNMIPath(pathToParse)
@@ -21,6 +21,8 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/statecache"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/inputs/gnmi/extensions/jnpr_gnmi_extention"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
@@ -1101,6 +1103,236 @@ func TestRedial(t *testing.T) {
 	wg.Wait()
 }
 
+func TestRedialBackoff(t *testing.T) {
+	plugin := &GNMI{
+		Redial:    config.Duration(time.Second),
+		MaxRedial: config.Duration(5 * time.Second),
+	}
+
+	// The delay is doubled as long as no response is received
+	var delay time.Duration
+	var delays []time.Duration
+	for range 5 {
+		delay = plugin.nextRedial(delay, false)
+		delays = append(delays, delay)
+	}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	require.Equal(t, expected, delays)
+
+	// Reset on successful subscriptions
+	require.Equal(t, time.Second, plugin.nextRedial(delay, true))
+
+	// Constant delay without maximum
+	plugin.MaxRedial = 0
+	require.Equal(t, time.Second, plugin.nextRedial(4*time.Second, false))
+}
+
+func TestInvalidMaxRedial(t *testing.T) {
+	plugin := &GNMI{
+		Log:       testutil.Logger{},
+		Addresses: []string{"127.0.0.1:57500"},
+		Encoding:  "proto",
+		Redial:    config.Duration(10 * time.Second),
+		MaxRedial: config.Duration(time.Second),
+	}
+	require.ErrorContains(t, plugin.Init(), "'max_redial' must not be smaller than 'redial'")
+}
+
+func TestSubscriptionModes(t *testing.T) {
+	plugin := &GNMI{
+		Log:       testutil.Logger{},
+		Addresses: []string{"127.0.0.1:57500"},
+		Encoding:  "proto",
+		Redial:    config.Duration(10 * time.Second),
+		Subscriptions: []subscription{
+			{
+				Name:             "counters",
+				Origin:           "openconfig",
+				Path:             "/interfaces/interface/state/counters",
+				SubscriptionMode: "sample",
+				SampleInterval:   config.Duration(10 * time.Second),
+			},
+			{
+				Name:             "status",
+				Origin:           "openconfig",
+				Path:             "/interfaces/interface/state/oper-status",
+				SubscriptionMode: "on_change",
+				SampleInterval:   config.Duration(10 * time.Second),
+			},
+		},
+	}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, config.Duration(10*time.Second), plugin.Subscriptions[0].SampleInterval)
+	require.Zero(t, plugin.Subscriptions[1].SampleInterval)
+
+	request, err := plugin.newSubscribeRequest()
+	require.NoError(t, err)
+	subscriptions := request.GetSubscribe().GetSubscription()
+	require.Len(t, subscriptions, 2)
+	require.Equal(t, gnmi.SubscriptionMode_SAMPLE, subscriptions[0].Mode)
+	require.Equal(t, gnmi.SubscriptionMode_ON_CHANGE, subscriptions[1].Mode)
+
+	plugin.Subscriptions[1].SubscriptionMode = "polling"
+	require.ErrorContains(t, plugin.Init(), `invalid subscription mode "polling"`)
+}
+
+func TestDeduplicateSubscriptions(t *testing.T) {
+	newSub := func(path string, mode gnmi.SubscriptionMode) *gnmi.Subscription {
+		p, err := parsePath("openconfig", path, "")
+		require.NoError(t, err)
+		return &gnmi.Subscription{Path: p, Mode: mode}
+	}
+
+	subscriptions := []*gnmi.Subscription{
+		newSub("/interfaces/interface/state/counters", gnmi.SubscriptionMode_SAMPLE),
+		newSub("/interfaces/interface/state", gnmi.SubscriptionMode_SAMPLE),
+		newSub("/interfaces/interface/state", gnmi.SubscriptionMode_SAMPLE),
+		newSub("/interfaces/interface[name=eth0]/state", gnmi.SubscriptionMode_SAMPLE),
+		newSub("/interfaces/interface[name=*]/config", gnmi.SubscriptionMode_SAMPLE),
+		newSub("/interfaces/interface/config", gnmi.SubscriptionMode_SAMPLE),
+		newSub("/interfaces/interface/state/oper-status", gnmi.SubscriptionMode_ON_CHANGE),
+	}
+	unique, dropped := deduplicateSubscriptions(subscriptions)
+	require.Equal(t, []*gnmi.Subscription{subscriptions[1], subscriptions[4], subscriptions[6]}, unique)
+	require.Equal(t, []*gnmi.Subscription{subscriptions[0], subscriptions[2], subscriptions[3], subscriptions[5]}, dropped)
+}
+
+func TestPathTemplates(t *testing.T) {
+	templates := []*pathTemplate{
+		{Path: "/interfaces/interface/state", Measurement: "interface_{{ .Name }}"},
+		{Path: "/interfaces/*/state/counters", Field: `{{ join .Relative "_" }}`},
+	}
+	for _, tmpl := range templates {
+		require.NoError(t, tmpl.init())
+	}
+
+	path := newInfoFromString("openconfig:/interfaces/interface/state/counters/errors/crc")
+	tmpl := lookupTemplate(templates, path)
+	require.Same(t, templates[1], tmpl)
+	name, field, err := tmpl.apply(path, "ifcounters", "crc")
+	require.NoError(t, err)
+	require.Equal(t, "ifcounters", name)
+	require.Equal(t, "errors_crc", field)
+
+	path = newInfoFromString("openconfig:/interfaces/interface/state/oper-status")
+	tmpl = lookupTemplate(templates, path)
+	require.Same(t, templates[0], tmpl)
+	name, field, err = tmpl.apply(path, "state", "oper_status")
+	require.NoError(t, err)
+	require.Equal(t, "interface_state", name)
+	require.Equal(t, "oper_status", field)
+
+	require.Nil(t, lookupTemplate(templates, newInfoFromString("/system/state")))
+
+	invalid := &pathTemplate{Path: "/system"}
+	require.ErrorContains(t, invalid.init(), "neither 'measurement' nor 'field' template set")
+}
+
+func TestStateSnapshot(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	plugin := &GNMI{
+		Log:       testutil.Logger{},
+		Addresses: []string{listener.Addr().String()},
+		Encoding:  "proto",
+		Redial:    config.Duration(1 * time.Second),
+		Aliases:   map[string]string{"alias": "type:/model"},
+		CacheConfig: statecache.CacheConfig{
+			Snapshot: true,
+		},
+	}
+
+	grpcServer := grpc.NewServer()
+	gnmiServer := &mockServer{
+		subscribeF: func(server gnmi.GNMI_SubscribeServer) error {
+			// Send the initial state followed by an on-change update of a
+			// single leaf
+			notification := mockGNMINotification()
+			err := server.Send(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: notification}})
+			if err != nil {
+				return err
+			}
+			notification = mockGNMINotification()
+			notification.Update = []*gnmi.Update{notification.Update[1]}
+			notification.Update[0].Val = &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "changed"}}
+			err = server.Send(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: notification}})
+			if err != nil {
+				return err
+			}
+			<-server.Context().Done()
+			return nil
+		},
+		grpcServer: grpcServer,
+	}
+	gnmi.RegisterGNMIServer(grpcServer, gnmiServer)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := grpcServer.Serve(listener); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer func() {
+		grpcServer.Stop()
+		wg.Wait()
+	}()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"alias",
+			map[string]string{
+				"path":   "type:/model",
+				"source": "127.0.0.1",
+				"foo":    "bar",
+				"name":   "str",
+				"uint64": "1234",
+			},
+			map[string]interface{}{
+				"some/path": int64(5678),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"alias",
+			map[string]string{
+				"path":   "type:/model",
+				"source": "127.0.0.1",
+				"foo":    "bar",
+			},
+			map[string]interface{}{
+				"other/path": "changed",
+				"other/this": "that",
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	// Wait for the update to be merged into the device state
+	require.Eventually(t, func() bool {
+		var snapshot testutil.Accumulator
+		require.NoError(t, plugin.Gather(&snapshot))
+		for _, m := range snapshot.GetTelegrafMetrics() {
+			if v, found := m.GetField("other/path"); found && v == "changed" {
+				return true
+			}
+		}
+		return false
+	}, 3*time.Second, 100*time.Millisecond)
+
+	// Updates are not emitted directly but only as complete snapshots
+	require.Zero(t, acc.NMetrics())
+	require.NoError(t, plugin.Gather(&acc))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
 func TestCases(t *testing.T) {
 	// Get all testcase directories
 	folders, err := os.ReadDir("testcases")
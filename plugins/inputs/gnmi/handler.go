@@ -25,6 +25,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/statecache"
 	"github.com/influxdata/telegraf/plugins/common/yangmodel"
 	"github.com/influxdata/telegraf/plugins/inputs/gnmi/extensions/jnpr_gnmi_extention"
 	"github.com/influxdata/telegraf/selfstat"
@@ -36,6 +37,7 @@ type handler struct {
 	host                          string
 	port                          string
 	aliases                       map[*pathInfo]string
+	templates                     []*pathTemplate
 	tagsubs                       []tagSubscription
 	maxMsgSize                    int
 	emptyNameWarnShown            bool
@@ -47,8 +49,11 @@ type handler struct {
 	tagPathPrefix                 bool
 	guessPathStrategy             string
 	decoder                       *yangmodel.Decoder
+	cache                         *statecache.Cache
 	enforceFirstNamespaceAsOrigin bool
 	log                           telegraf.Logger
+	// at least one response was received in the last subscription
+	received bool
 	keepalive.ClientParameters
 }
 
@@ -99,6 +104,7 @@ func (h *handler) subscribeGNMI(ctx context.Context, acc telegraf.Accumulator, t
 	}
 	connectStat.Set(1)
 	h.log.Debugf("Connection to gNMI device %s established", address)
+	h.received = false
 
 	defer h.log.Debugf("Connection to gNMI device %s closed", address)
 	for ctx.Err() == nil {
@@ -109,6 +115,7 @@ func (h *handler) subscribeGNMI(ctx context.Context, acc telegraf.Accumulator, t
 			}
 			break
 		}
+		h.received = true
 
 		if h.trace {
 			buf, err := protojson.Marshal(reply)
@@ -299,6 +306,14 @@ func (h *handler) handleSubscribeResponseUpdate(acc telegraf.Accumulator, respon
 		if h.trimSlash {
 			key = strings.TrimLeft(key, "/.")
 		}
+
+		// Apply the user-defined templates for measurement and field names
+		if tmpl := lookupTemplate(h.templates, field.path); tmpl != nil {
+			var err error
+			if name, key, err = tmpl.apply(field.path, name, key); err != nil {
+				h.log.Errorf("Applying template for path %q failed: %v", field.path.String(), err)
+			}
+		}
 		if key == "" {
 			h.log.Errorf("Invalid empty path %q with alias %q", field.path.String(), aliasPath)
 			continue
@@ -306,8 +321,13 @@ func (h *handler) handleSubscribeResponseUpdate(acc telegraf.Accumulator, respon
 		grouper.Add(name, tags, timestamp, key, field.value)
 	}
 
-	// Add grouped measurements
+	// Add grouped measurements or merge them into the device state if
+	// snapshots are requested
 	for _, metricToAdd := range grouper.Metrics() {
+		if h.cache != nil {
+			h.cache.AddMetric(metricToAdd)
+			continue
+		}
 		acc.AddMetric(metricToAdd)
 	}
 }
@@ -0,0 +1,138 @@
+package gnmi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Functions available in the path templates in addition to the builtins
+var templateFuncs = template.FuncMap{
+	"join":    strings.Join,
+	"replace": strings.ReplaceAll,
+}
+
+type pathTemplate struct {
+	Path        string `toml:"path"`
+	Measurement string `toml:"measurement"`
+	Field       string `toml:"field"`
+
+	info        *pathInfo
+	measurement *template.Template
+	field       *template.Template
+}
+
+// templateData is passed to the measurement and field templates
+type templateData struct {
+	// Origin of the update path
+	Origin string
+	// Path of the update without keys
+	Path string
+	// Names of all path elements
+	Elements []string
+	// Names of the path elements below the template path
+	Relative []string
+	// Measurement and field name as determined by the aliases
+	Name  string
+	Field string
+}
+
+func (t *pathTemplate) init() error {
+	if t.Path == "" {
+		return errors.New("empty 'path'")
+	}
+	if t.Measurement == "" && t.Field == "" {
+		return errors.New("neither 'measurement' nor 'field' template set")
+	}
+	t.info = newInfoFromString(t.Path)
+
+	if t.Measurement != "" {
+		tmpl, err := template.New("measurement").Funcs(templateFuncs).Parse(t.Measurement)
+		if err != nil {
+			return fmt.Errorf("parsing measurement template failed: %w", err)
+		}
+		t.measurement = tmpl
+	}
+	if t.Field != "" {
+		tmpl, err := template.New("field").Funcs(templateFuncs).Parse(t.Field)
+		if err != nil {
+			return fmt.Errorf("parsing field template failed: %w", err)
+		}
+		t.field = tmpl
+	}
+	return nil
+}
+
+// matches checks if the given path is equal to or below the template path,
+// elements of the template path set to "*" match any element
+func (t *pathTemplate) matches(path *pathInfo) bool {
+	if t.info.origin != "" && path.origin != "" && t.info.origin != path.origin {
+		return false
+	}
+	if len(t.info.segments) > len(path.segments) {
+		return false
+	}
+	for i, s := range t.info.segments {
+		ps := path.segments[i]
+		if s.id == "*" {
+			continue
+		}
+		if s.namespace != "" && ps.namespace != "" && s.namespace != ps.namespace {
+			return false
+		}
+		if s.id != ps.id {
+			return false
+		}
+	}
+	return true
+}
+
+// apply renders the measurement and field name for the given path, names
+// without template are kept
+func (t *pathTemplate) apply(path *pathInfo, name, field string) (string, string, error) {
+	elements := make([]string, 0, len(path.segments))
+	for _, s := range path.segments {
+		elements = append(elements, s.id)
+	}
+	_, p := path.path()
+	data := templateData{
+		Origin:   path.origin,
+		Path:     p,
+		Elements: elements,
+		Relative: elements[len(t.info.segments):],
+		Name:     name,
+		Field:    field,
+	}
+
+	if t.measurement != nil {
+		var buf strings.Builder
+		if err := t.measurement.Execute(&buf, data); err != nil {
+			return name, field, fmt.Errorf("executing measurement template failed: %w", err)
+		}
+		name = buf.String()
+	}
+	if t.field != nil {
+		var buf strings.Builder
+		if err := t.field.Execute(&buf, data); err != nil {
+			return name, field, fmt.Errorf("executing field template failed: %w", err)
+		}
+		field = buf.String()
+	}
+	return name, field, nil
+}
+
+// lookupTemplate returns the template with the longest path matching the
+// given path or nil if no template matches
+func lookupTemplate(templates []*pathTemplate, path *pathInfo) *pathTemplate {
+	var found *pathTemplate
+	for _, t := range templates {
+		if !t.matches(path) {
+			continue
+		}
+		if found == nil || len(t.info.segments) > len(found.info.segments) {
+			found = t
+		}
+	}
+	return found
+}
@@ -21,6 +21,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang/snappy"
 
 	"github.com/influxdata/telegraf"
@@ -62,6 +63,14 @@ type HTTPListenerV2 struct {
 	BasicPassword  string            `toml:"basic_password"`
 	HTTPHeaderTags map[string]string `toml:"http_header_tags"`
 
+	JWTJWKSURL             string            `toml:"jwt_jwks_url"`
+	JWTJWKSRefreshInterval config.Duration   `toml:"jwt_jwks_refresh_interval"`
+	JWTAudience            []string          `toml:"jwt_audience"`
+	JWTIssuer              string            `toml:"jwt_issuer"`
+	JWTClockSkew           config.Duration   `toml:"jwt_clock_skew"`
+	JWTScopeClaim          string            `toml:"jwt_scope_claim"`
+	JWTPathScopes          map[string]string `toml:"jwt_path_scopes"`
+
 	common_tls.ServerConfig
 	tlsConf *tls.Config
 
@@ -73,6 +82,7 @@ type HTTPListenerV2 struct {
 
 	listener net.Listener
 	url      *url.URL
+	jwt      *jwtValidator
 
 	telegraf.Parser
 	acc telegraf.Accumulator
@@ -108,6 +118,49 @@ func (h *HTTPListenerV2) Init() error {
 		h.SuccessCode = http.StatusNoContent
 	}
 
+	if h.JWTJWKSURL != "" {
+		if h.BasicUsername != "" || h.BasicPassword != "" {
+			return errors.New("basic authentication cannot be used together with JWT validation")
+		}
+		if _, err := url.Parse(h.JWTJWKSURL); err != nil {
+			return fmt.Errorf("parsing JWKS URL failed: %w", err)
+		}
+		if h.JWTJWKSRefreshInterval <= 0 {
+			h.JWTJWKSRefreshInterval = config.Duration(time.Hour)
+		}
+		if h.JWTScopeClaim == "" {
+			h.JWTScopeClaim = "scope"
+		}
+		for path := range h.JWTPathScopes {
+			if !choice.Contains(path, h.Paths) {
+				return fmt.Errorf("scope configured for unknown path %q", path)
+			}
+		}
+
+		options := []jwt.ParserOption{
+			jwt.WithValidMethods(jwtMethods),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(time.Duration(h.JWTClockSkew)),
+		}
+		if len(h.JWTAudience) > 0 {
+			options = append(options, jwt.WithAudience(h.JWTAudience...))
+		}
+		if h.JWTIssuer != "" {
+			options = append(options, jwt.WithIssuer(h.JWTIssuer))
+		}
+		h.jwt = &jwtValidator{
+			url:             h.JWTJWKSURL,
+			refreshInterval: time.Duration(h.JWTJWKSRefreshInterval),
+			scopeClaim:      h.JWTScopeClaim,
+			pathScopes:      h.JWTPathScopes,
+			client:          &http.Client{Timeout: 10 * time.Second},
+			parser:          jwt.NewParser(options...),
+			log:             h.Log,
+		}
+	} else if len(h.JWTPathScopes) > 0 {
+		return errors.New("'jwt_path_scopes' requires 'jwt_jwks_url'")
+	}
+
 	return nil
 }
 
@@ -133,6 +186,16 @@ func (h *HTTPListenerV2) Start(acc telegraf.Accumulator) error {
 		return fmt.Errorf("unknown protocol %q", u.Scheme)
 	}
 
+	// Get the keys for validating tokens before accepting any requests
+	if h.jwt != nil {
+		h.jwt.Lock()
+		err := h.jwt.refresh()
+		h.jwt.Unlock()
+		if err != nil {
+			return &internal.StartupError{Err: err, Retry: true}
+		}
+	}
+
 	var listener net.Listener
 	var err error
 	if h.tlsConf != nil {
@@ -398,7 +461,14 @@ func badRequest(res http.ResponseWriter) error {
 }
 
 func (h *HTTPListenerV2) authenticateIfSet(handler http.HandlerFunc, res http.ResponseWriter, req *http.Request) {
-	if h.BasicUsername != "" && h.BasicPassword != "" {
+	if h.jwt != nil {
+		if code, err := h.jwt.validate(req); err != nil {
+			h.Log.Debugf("Rejecting request from %s: %v", req.RemoteAddr, err)
+			http.Error(res, http.StatusText(code)+".", code)
+			return
+		}
+		handler(res, req)
+	} else if h.BasicUsername != "" && h.BasicPassword != "" {
 		reqUsername, reqPassword, ok := req.BasicAuth()
 		if !ok ||
 			subtle.ConstantTimeCompare([]byte(reqUsername), []byte(h.BasicUsername)) != 1 ||
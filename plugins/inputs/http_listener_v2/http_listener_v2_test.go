@@ -3,11 +3,20 @@ package http_listener_v2
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"runtime"
@@ -17,10 +26,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang/snappy"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/parsers/form_urlencoded"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -776,3 +787,287 @@ func mustReadHugeMetric() []byte {
 
 // The term 'master_repl' used here is archaic language from redis
 var hugeMetric = mustReadHugeMetric()
+
+func TestJWTInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*HTTPListenerV2)
+		expected string
+	}{
+		{
+			name: "basic auth",
+			modify: func(h *HTTPListenerV2) {
+				h.JWTJWKSURL = "http://localhost/jwks.json"
+				h.BasicUsername = basicUsername
+				h.BasicPassword = basicPassword
+			},
+			expected: "basic authentication cannot be used together with JWT validation",
+		},
+		{
+			name: "unknown path",
+			modify: func(h *HTTPListenerV2) {
+				h.JWTJWKSURL = "http://localhost/jwks.json"
+				h.JWTPathScopes = map[string]string{"/foo": "write"}
+			},
+			expected: `scope configured for unknown path "/foo"`,
+		},
+		{
+			name: "scopes without key set",
+			modify: func(h *HTTPListenerV2) {
+				h.JWTPathScopes = map[string]string{"/write": "write"}
+			},
+			expected: "'jwt_path_scopes' requires 'jwt_jwks_url'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := newTestHTTPListenerV2()
+			require.NoError(t, err)
+			tt.modify(listener)
+			require.ErrorContains(t, listener.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWriteHTTPJWT(t *testing.T) {
+	// Create the signing keys and the key set served by the mock server
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	encode := func(b []byte) string {
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa",
+				"use": "sig",
+				"n":   encode(rsaKey.N.Bytes()),
+				"e":   encode(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC",
+				"kid": "ec",
+				"crv": "P-256",
+				"x":   encode(ecKey.X.FillBytes(make([]byte, 32))),
+				"y":   encode(ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+			{
+				"kty": "OKP",
+				"kid": "ed",
+				"crv": "Ed25519",
+				"x":   encode(edKey.Public().(ed25519.PublicKey)),
+			},
+			{
+				"kty": "RSA",
+				"kid": "encryption",
+				"use": "enc",
+				"n":   encode(rsaKey.N.Bytes()),
+				"e":   encode(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if err := json.NewEncoder(w).Encode(jwks); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.Paths = []string{"/write", "/open"}
+	listener.JWTJWKSURL = server.URL
+	listener.JWTAudience = []string{"telegraf"}
+	listener.JWTIssuer = "https://idp.example.com"
+	listener.JWTClockSkew = config.Duration(time.Minute)
+	listener.JWTPathScopes = map[string]string{"/write": "metrics:write"}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"aud":   "telegraf",
+			"iss":   "https://idp.example.com",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"scope": "metrics:read metrics:write",
+		}
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		token    func() string
+		expected int
+	}{
+		{
+			name:     "rsa",
+			path:     "/write",
+			token:    func() string { return sign(jwt.SigningMethodRS256, "rsa", rsaKey, valid()) },
+			expected: http.StatusNoContent,
+		},
+		{
+			name:     "ecdsa",
+			path:     "/write",
+			token:    func() string { return sign(jwt.SigningMethodES256, "ec", ecKey, valid()) },
+			expected: http.StatusNoContent,
+		},
+		{
+			name:     "eddsa",
+			path:     "/write",
+			token:    func() string { return sign(jwt.SigningMethodEdDSA, "ed", edKey, valid()) },
+			expected: http.StatusNoContent,
+		},
+		{
+			name: "scope list",
+			path: "/write",
+			token: func() string {
+				claims := valid()
+				claims["scope"] = []string{"metrics:write"}
+				return sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims)
+			},
+			expected: http.StatusNoContent,
+		},
+		{
+			name: "expired within clock skew",
+			path: "/write",
+			token: func() string {
+				claims := valid()
+				claims["exp"] = time.Now().Add(-30 * time.Second).Unix()
+				return sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims)
+			},
+			expected: http.StatusNoContent,
+		},
+		{
+			name:     "missing token",
+			path:     "/write",
+			token:    func() string { return "" },
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name: "expired",
+			path: "/write",
+			token: func() string {
+				claims := valid()
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+				return sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims)
+			},
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name: "no expiration",
+			path: "/write",
+			token: func() string {
+				claims := valid()
+				delete(claims, "exp")
+				return sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims)
+			},
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name: "wrong audience",
+			path: "/write",
+			token: func() string {
+				claims := valid()
+				claims["aud"] = "someone-else"
+				return sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims)
+			},
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name: "wrong issuer",
+			path: "/write",
+			token: func() string {
+				claims := valid()
+				claims["iss"] = "https://evil.example.com"
+				return sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims)
+			},
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "wrong key",
+			path:     "/write",
+			token:    func() string { return sign(jwt.SigningMethodES256, "rsa", ecKey, valid()) },
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "encryption key",
+			path:     "/write",
+			token:    func() string { return sign(jwt.SigningMethodRS256, "encryption", rsaKey, valid()) },
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "symmetric key",
+			path:     "/write",
+			token:    func() string { return sign(jwt.SigningMethodHS256, "rsa", []byte("secret"), valid()) },
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name: "missing scope",
+			path: "/write",
+			token: func() string {
+				claims := valid()
+				claims["scope"] = "metrics:read"
+				return sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims)
+			},
+			expected: http.StatusForbidden,
+		},
+		{
+			name: "path without scope",
+			path: "/open",
+			token: func() string {
+				claims := valid()
+				delete(claims, "scope")
+				return sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims)
+			},
+			expected: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", createURL(listener, "http", tt.path, ""), bytes.NewBufferString(testMsg))
+			require.NoError(t, err)
+			if token := tt.token(); token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, tt.expected, resp.StatusCode)
+		})
+	}
+}
+
+func TestJWTStartFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.JWTJWKSURL = server.URL
+	require.NoError(t, listener.Init())
+
+	var serr *internal.StartupError
+	require.ErrorAs(t, listener.Start(&testutil.Accumulator{}), &serr)
+	require.True(t, serr.Retry)
+}
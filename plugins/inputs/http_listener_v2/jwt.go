@@ -0,0 +1,237 @@
+package http_listener_v2
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Minimum time between two key set refreshes triggered by unknown key IDs to
+// avoid flooding the key server with requests for invalid tokens
+const jwksMinRefreshInterval = 10 * time.Second
+
+// Signing methods accepted for tokens, symmetric methods are not supported as
+// the key set only contains public keys
+var jwtMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// jsonWebKey contains the parameters of a public key as defined in RFC 7517
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	Curve   string `json:"crv"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+type jwtValidator struct {
+	url             string
+	refreshInterval time.Duration
+	scopeClaim      string
+	pathScopes      map[string]string
+
+	client *http.Client
+	parser *jwt.Parser
+	log    telegraf.Logger
+
+	keys        map[string]crypto.PublicKey
+	lastRefresh time.Time
+	sync.Mutex
+}
+
+// refresh downloads the key set and replaces the known keys
+func (v *jwtValidator) refresh() error {
+	v.lastRefresh = time.Now()
+
+	resp, err := v.client.Get(v.url)
+	if err != nil {
+		return fmt.Errorf("requesting key set failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("requesting key set failed with status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("decoding key set failed: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			v.log.Warnf("Ignoring key %q: %v", k.KeyID, err)
+			continue
+		}
+		keys[k.KeyID] = key
+	}
+	if len(keys) == 0 {
+		return errors.New("no usable keys in key set")
+	}
+	v.keys = keys
+
+	return nil
+}
+
+// key returns the public key used to verify the signature of the given token
+func (v *jwtValidator) key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	v.Lock()
+	defer v.Unlock()
+
+	key, found := v.keys[kid]
+	stale := time.Since(v.lastRefresh) > v.refreshInterval
+	if stale || (!found && time.Since(v.lastRefresh) > jwksMinRefreshInterval) {
+		if err := v.refresh(); err != nil {
+			// Continue with the known keys as the key server might only be
+			// temporarily unavailable
+			v.log.Errorf("Refreshing key set failed: %v", err)
+		}
+		key, found = v.keys[kid]
+	}
+	if !found {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	return key, nil
+}
+
+// validate checks the bearer token of the request and returns the HTTP status
+// code to send if the request is not authorized
+func (v *jwtValidator) validate(req *http.Request) (int, error) {
+	raw, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !found || raw == "" {
+		return http.StatusUnauthorized, errors.New("missing bearer token")
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(raw, claims, v.key); err != nil {
+		return http.StatusUnauthorized, err
+	}
+
+	scope, found := v.pathScopes[req.URL.Path]
+	if !found {
+		return 0, nil
+	}
+	for _, s := range scopes(claims[v.scopeClaim]) {
+		if s == scope {
+			return 0, nil
+		}
+	}
+	return http.StatusForbidden, fmt.Errorf("token lacks scope %q", scope)
+}
+
+// scopes returns the scopes of the claim given either as space-delimited
+// string (RFC 8693) or as list of strings
+func scopes(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, s := range v {
+			if str, ok := s.(string); ok {
+				result = append(result, str)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func (k *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("decoding modulus failed: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("decoding exponent failed: %w", err)
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("decoding x coordinate failed: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("decoding y coordinate failed: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("decoding public key failed: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid public key size %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(buf), nil
+}
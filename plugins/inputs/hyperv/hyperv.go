@@ -0,0 +1,334 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package hyperv
+
+import (
+	_ "embed"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Separator between the virtual machine name and the processor index in the
+// instance names of the virtual processor counters, e.g. "vm01:Hv VP 0"
+const processorSeparator = ":Hv VP "
+
+// wmiQuery describes the WMI class and properties providing a kind of metrics
+type wmiQuery struct {
+	namespace  string
+	class      string
+	properties []string
+	filter     string
+}
+
+// The performance counters are queried as raw data to get cumulative values
+// instead of rates computed by WMI between two subsequent queries
+var queries = map[string]wmiQuery{
+	"vm": {
+		namespace: `root\virtualization\v2`,
+		class:     "Msvm_ComputerSystem",
+		properties: []string{
+			"Name",
+			"ElementName",
+			"EnabledState",
+			"HealthState",
+			"OnTimeInMilliseconds",
+			"ReplicationMode",
+			"ReplicationState",
+			"ReplicationHealth",
+		},
+		// Exclude the host computer system
+		filter: `Caption = "Virtual Machine"`,
+	},
+	"cpu": {
+		namespace: `root\cimv2`,
+		class:     "Win32_PerfRawData_HvStats_HyperVHypervisorVirtualProcessor",
+		properties: []string{
+			"Name",
+			"PercentGuestRunTime",
+			"PercentHypervisorRunTime",
+			"PercentTotalRunTime",
+		},
+	},
+	"memory": {
+		namespace: `root\cimv2`,
+		class:     "Win32_PerfRawData_BalancerStats_HyperVDynamicMemoryVM",
+		properties: []string{
+			"Name",
+			"PhysicalMemory",
+			"GuestVisiblePhysicalMemory",
+			"AddedMemory",
+			"RemovedMemory",
+			"CurrentPressure",
+			"AveragePressure",
+			"MinimumPressure",
+			"MaximumPressure",
+		},
+	},
+	"vswitch": {
+		namespace: `root\cimv2`,
+		class:     "Win32_PerfRawData_NvspSwitchStats_HyperVVirtualSwitch",
+		properties: []string{
+			"Name",
+			"BytesReceivedPersec",
+			"BytesSentPersec",
+			"PacketsReceivedPersec",
+			"PacketsSentPersec",
+			"DroppedPacketsIncomingPersec",
+			"DroppedPacketsOutgoingPersec",
+		},
+	},
+}
+
+// States of the virtual machines as reported in the EnabledState property
+var vmStates = map[uint64]string{
+	2:     "running",
+	3:     "off",
+	6:     "saved",
+	9:     "paused",
+	10:    "starting",
+	32768: "paused",
+	32769: "saved",
+	32770: "starting",
+	32773: "saving",
+	32774: "stopping",
+	32776: "pausing",
+	32777: "resuming",
+}
+
+// Replication health as reported in the ReplicationHealth property
+var replicationHealth = map[uint64]string{
+	0: "not_applicable",
+	1: "ok",
+	2: "warning",
+	3: "critical",
+}
+
+// row contains the properties of a WMI object
+type row map[string]interface{}
+
+type HyperV struct {
+	Collect   []string        `toml:"collect"`
+	VMInclude []string        `toml:"vm_include"`
+	VMExclude []string        `toml:"vm_exclude"`
+	Log       telegraf.Logger `toml:"-"`
+
+	vmFilter filter.Filter
+}
+
+func (*HyperV) SampleConfig() string {
+	return sampleConfig
+}
+
+func (h *HyperV) Init() error {
+	if runtime.GOOS != "windows" {
+		h.Log.Warn("Current platform is not supported")
+	}
+
+	if len(h.Collect) == 0 {
+		h.Collect = []string{"vm", "cpu", "memory", "vswitch"}
+	}
+	available := make([]string, 0, len(queries))
+	for kind := range queries {
+		available = append(available, kind)
+	}
+	if err := choice.CheckSlice(h.Collect, available); err != nil {
+		return fmt.Errorf("invalid 'collect' setting: %w", err)
+	}
+
+	f, err := filter.NewIncludeExcludeFilter(h.VMInclude, h.VMExclude)
+	if err != nil {
+		return fmt.Errorf("creating virtual machine filter failed: %w", err)
+	}
+	h.vmFilter = f
+
+	return nil
+}
+
+// wql returns the WQL statement of the query
+func (q *wmiQuery) wql() string {
+	stmt := fmt.Sprintf("SELECT %s FROM %s", strings.Join(q.properties, ", "), q.class)
+	if q.filter != "" {
+		stmt += " WHERE " + q.filter
+	}
+	return stmt
+}
+
+// process converts the queried objects of the given kind to metrics
+func (h *HyperV) process(acc telegraf.Accumulator, kind string, rows []row) {
+	switch kind {
+	case "vm":
+		h.processVMs(acc, rows)
+	case "cpu":
+		h.processProcessors(acc, rows)
+	case "memory":
+		h.processMemory(acc, rows)
+	case "vswitch":
+		processSwitches(acc, rows)
+	}
+}
+
+func (h *HyperV) processVMs(acc telegraf.Accumulator, rows []row) {
+	for _, r := range rows {
+		name := toString(r["ElementName"])
+		if !h.vmFilter.Match(name) {
+			continue
+		}
+		tags := map[string]string{
+			"vm_name": name,
+			"vm_id":   toString(r["Name"]),
+		}
+
+		fields := make(map[string]interface{})
+		if v, ok := toUint64(r["EnabledState"]); ok {
+			fields["enabled_state"] = v
+			state, found := vmStates[v]
+			if !found {
+				state = "unknown"
+			}
+			fields["state"] = state
+		}
+		addUint64(fields, "health_state", r["HealthState"], 1)
+		addUint64(fields, "uptime_ms", r["OnTimeInMilliseconds"], 1)
+		addUint64(fields, "replication_mode", r["ReplicationMode"], 1)
+		addUint64(fields, "replication_state", r["ReplicationState"], 1)
+		if v, ok := toUint64(r["ReplicationHealth"]); ok {
+			fields["replication_health"] = v
+			status, found := replicationHealth[v]
+			if !found {
+				status = "unknown"
+			}
+			fields["replication_health_status"] = status
+		}
+		acc.AddFields("hyperv_vm", fields, tags)
+	}
+}
+
+func (h *HyperV) processProcessors(acc telegraf.Accumulator, rows []row) {
+	for _, r := range rows {
+		// Skip the total and all other instances not belonging to a
+		// virtual processor
+		name := toString(r["Name"])
+		idx := strings.LastIndex(name, processorSeparator)
+		if idx < 0 {
+			continue
+		}
+		vm, vcpu := name[:idx], name[idx+len(processorSeparator):]
+		if !h.vmFilter.Match(vm) {
+			continue
+		}
+		tags := map[string]string{
+			"vm_name": vm,
+			"vcpu":    vcpu,
+		}
+
+		// The raw values are in units of 100 nanoseconds
+		fields := make(map[string]interface{})
+		addUint64(fields, "guest_run_time_ns", r["PercentGuestRunTime"], 100)
+		addUint64(fields, "hypervisor_run_time_ns", r["PercentHypervisorRunTime"], 100)
+		addUint64(fields, "total_run_time_ns", r["PercentTotalRunTime"], 100)
+		acc.AddFields("hyperv_vm_cpu", fields, tags)
+	}
+}
+
+func (h *HyperV) processMemory(acc telegraf.Accumulator, rows []row) {
+	for _, r := range rows {
+		name := toString(r["Name"])
+		if name == "_Total" || !h.vmFilter.Match(name) {
+			continue
+		}
+		tags := map[string]string{"vm_name": name}
+
+		fields := make(map[string]interface{})
+		addUint64(fields, "physical_memory_mb", r["PhysicalMemory"], 1)
+		addUint64(fields, "guest_visible_memory_mb", r["GuestVisiblePhysicalMemory"], 1)
+		addUint64(fields, "added_memory_mb", r["AddedMemory"], 1)
+		addUint64(fields, "removed_memory_mb", r["RemovedMemory"], 1)
+		addUint64(fields, "current_pressure", r["CurrentPressure"], 1)
+		addUint64(fields, "average_pressure", r["AveragePressure"], 1)
+		addUint64(fields, "minimum_pressure", r["MinimumPressure"], 1)
+		addUint64(fields, "maximum_pressure", r["MaximumPressure"], 1)
+		acc.AddFields("hyperv_vm_memory", fields, tags)
+	}
+}
+
+func processSwitches(acc telegraf.Accumulator, rows []row) {
+	for _, r := range rows {
+		name := toString(r["Name"])
+		if name == "_Total" {
+			continue
+		}
+		tags := map[string]string{"switch": name}
+
+		fields := make(map[string]interface{})
+		addUint64(fields, "bytes_received", r["BytesReceivedPersec"], 1)
+		addUint64(fields, "bytes_sent", r["BytesSentPersec"], 1)
+		addUint64(fields, "packets_received", r["PacketsReceivedPersec"], 1)
+		addUint64(fields, "packets_sent", r["PacketsSentPersec"], 1)
+		addUint64(fields, "dropped_packets_incoming", r["DroppedPacketsIncomingPersec"], 1)
+		addUint64(fields, "dropped_packets_outgoing", r["DroppedPacketsOutgoingPersec"], 1)
+		acc.AddFields("hyperv_vswitch", fields, tags)
+	}
+}
+
+// addUint64 adds the value scaled by the given factor as field if it is a
+// valid unsigned integer and skips it otherwise, e.g. if the property is not
+// available on the Windows version
+func addUint64(fields map[string]interface{}, name string, value interface{}, factor uint64) {
+	if v, ok := toUint64(value); ok {
+		fields[name] = v * factor
+	}
+}
+
+// toUint64 converts the property value to an unsigned integer. WMI returns
+// 64-bit integers as strings.
+func toUint64(value interface{}) (uint64, bool) {
+	switch v := value.(type) {
+	case uint8:
+		return uint64(v), true
+	case uint16:
+		return uint64(v), true
+	case uint32:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	case int16:
+		return fromInt64(int64(v))
+	case int32:
+		return fromInt64(int64(v))
+	case int64:
+		return fromInt64(v)
+	case string:
+		u, err := strconv.ParseUint(v, 10, 64)
+		return u, err == nil
+	}
+	return 0, false
+}
+
+func fromInt64(v int64) (uint64, bool) {
+	if v < 0 {
+		return 0, false
+	}
+	return uint64(v), true
+}
+
+func toString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
+}
+
+func init() {
+	inputs.Add("hyperv", func() telegraf.Input {
+		return &HyperV{}
+	})
+}
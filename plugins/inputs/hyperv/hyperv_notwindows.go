@@ -0,0 +1,9 @@
+//go:build !windows
+
+package hyperv
+
+import "github.com/influxdata/telegraf"
+
+func (*HyperV) Gather(telegraf.Accumulator) error {
+	return nil
+}
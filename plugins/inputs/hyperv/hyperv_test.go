@@ -0,0 +1,196 @@
+package hyperv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalidCollect(t *testing.T) {
+	plugin := &HyperV{
+		Collect: []string{"vm", "disk"},
+		Log:     testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid 'collect' setting")
+}
+
+func TestWQL(t *testing.T) {
+	q := queries["vm"]
+	require.Equal(t,
+		"SELECT Name, ElementName, EnabledState, HealthState, OnTimeInMilliseconds, ReplicationMode, ReplicationState, ReplicationHealth "+
+			`FROM Msvm_ComputerSystem WHERE Caption = "Virtual Machine"`,
+		q.wql(),
+	)
+
+	q = queries["vswitch"]
+	require.NotContains(t, q.wql(), "WHERE")
+}
+
+func TestProcess(t *testing.T) {
+	rows := map[string][]row{
+		"vm": {
+			{
+				"Name":                 "B4B7E7AD-5F5C-4A1B-9C3E-1D0A3B5C7E90",
+				"ElementName":          "web01",
+				"EnabledState":         uint16(2),
+				"HealthState":          uint16(5),
+				"OnTimeInMilliseconds": "86400000",
+				"ReplicationMode":      uint16(1),
+				"ReplicationState":     uint16(3),
+				"ReplicationHealth":    uint16(2),
+			},
+			{
+				// Properties missing on older versions of Windows
+				"Name":         "0C2B6E1F-3A4D-4E5F-8A9B-7C6D5E4F3A2B",
+				"ElementName":  "db01",
+				"EnabledState": uint16(3),
+			},
+			{
+				"Name":         "5E6F7A8B-9C0D-4E1F-A2B3-C4D5E6F7A8B9",
+				"ElementName":  "test01",
+				"EnabledState": uint16(2),
+			},
+		},
+		"cpu": {
+			{"Name": "_Total", "PercentGuestRunTime": "300"},
+			{
+				"Name":                     "web01:Hv VP 0",
+				"PercentGuestRunTime":      "1000",
+				"PercentHypervisorRunTime": "20",
+				"PercentTotalRunTime":      "1020",
+			},
+			{
+				"Name":                     "test01:Hv VP 0",
+				"PercentGuestRunTime":      "5",
+				"PercentHypervisorRunTime": "1",
+				"PercentTotalRunTime":      "6",
+			},
+		},
+		"memory": {
+			{
+				"Name":                       "web01",
+				"PhysicalMemory":             uint32(4096),
+				"GuestVisiblePhysicalMemory": uint32(4096),
+				"AddedMemory":                uint32(3072),
+				"RemovedMemory":              uint32(0),
+				"CurrentPressure":            uint32(81),
+				"AveragePressure":            uint32(78),
+				"MinimumPressure":            uint32(60),
+				"MaximumPressure":            uint32(95),
+			},
+		},
+		"vswitch": {
+			{
+				"Name":                         "External",
+				"BytesReceivedPersec":          "123456789",
+				"BytesSentPersec":              "987654321",
+				"PacketsReceivedPersec":        "1000",
+				"PacketsSentPersec":            "2000",
+				"DroppedPacketsIncomingPersec": "3",
+				"DroppedPacketsOutgoingPersec": "0",
+			},
+		},
+	}
+
+	plugin := &HyperV{
+		VMExclude: []string{"test*"},
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	for _, kind := range plugin.Collect {
+		plugin.process(&acc, kind, rows[kind])
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"hyperv_vm",
+			map[string]string{
+				"vm_name": "web01",
+				"vm_id":   "B4B7E7AD-5F5C-4A1B-9C3E-1D0A3B5C7E90",
+			},
+			map[string]interface{}{
+				"enabled_state":             uint64(2),
+				"state":                     "running",
+				"health_state":              uint64(5),
+				"uptime_ms":                 uint64(86400000),
+				"replication_mode":          uint64(1),
+				"replication_state":         uint64(3),
+				"replication_health":        uint64(2),
+				"replication_health_status": "warning",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"hyperv_vm",
+			map[string]string{
+				"vm_name": "db01",
+				"vm_id":   "0C2B6E1F-3A4D-4E5F-8A9B-7C6D5E4F3A2B",
+			},
+			map[string]interface{}{
+				"enabled_state": uint64(3),
+				"state":         "off",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"hyperv_vm_cpu",
+			map[string]string{
+				"vm_name": "web01",
+				"vcpu":    "0",
+			},
+			map[string]interface{}{
+				"guest_run_time_ns":      uint64(100000),
+				"hypervisor_run_time_ns": uint64(2000),
+				"total_run_time_ns":      uint64(102000),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"hyperv_vm_memory",
+			map[string]string{"vm_name": "web01"},
+			map[string]interface{}{
+				"physical_memory_mb":      uint64(4096),
+				"guest_visible_memory_mb": uint64(4096),
+				"added_memory_mb":         uint64(3072),
+				"removed_memory_mb":       uint64(0),
+				"current_pressure":        uint64(81),
+				"average_pressure":        uint64(78),
+				"minimum_pressure":        uint64(60),
+				"maximum_pressure":        uint64(95),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"hyperv_vswitch",
+			map[string]string{"switch": "External"},
+			map[string]interface{}{
+				"bytes_received":           uint64(123456789),
+				"bytes_sent":               uint64(987654321),
+				"packets_received":         uint64(1000),
+				"packets_sent":             uint64(2000),
+				"dropped_packets_incoming": uint64(3),
+				"dropped_packets_outgoing": uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestProcessorNameWithColon(t *testing.T) {
+	plugin := &HyperV{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	plugin.process(&acc, "cpu", []row{{"Name": "app:blue:Hv VP 3", "PercentGuestRunTime": "1"}})
+	require.True(t, acc.HasTag("hyperv_vm_cpu", "vm_name"))
+	require.Equal(t, "app:blue", acc.TagValue("hyperv_vm_cpu", "vm_name"))
+	require.Equal(t, "3", acc.TagValue("hyperv_vm_cpu", "vcpu"))
+}
@@ -0,0 +1,109 @@
+//go:build windows
+
+package hyperv
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+
+	"github.com/influxdata/telegraf"
+)
+
+// S_FALSE is returned by CoInitializeEx if it was already called on this thread.
+const sFalse = 0x00000001
+
+func (h *HyperV) Gather(acc telegraf.Accumulator) error {
+	// COM must be initialized on the OS thread executing the queries
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := ole.CoInitializeEx(0, ole.COINIT_MULTITHREADED); err != nil {
+		var oleCode *ole.OleError
+		if errors.As(err, &oleCode) && oleCode.Code() != ole.S_OK && oleCode.Code() != sFalse {
+			return err
+		}
+	}
+	defer ole.CoUninitialize()
+
+	unknown, err := oleutil.CreateObject("WbemScripting.SWbemLocator")
+	if err != nil {
+		return err
+	}
+	if unknown == nil {
+		return errors.New("failed to create WbemScripting.SWbemLocator, maybe WMI is broken")
+	}
+	defer unknown.Release()
+
+	locator, err := unknown.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return fmt.Errorf("failed to QueryInterface: %w", err)
+	}
+	defer locator.Release()
+
+	for _, kind := range h.Collect {
+		q := queries[kind]
+		rows, err := q.execute(locator)
+		if err != nil {
+			acc.AddError(fmt.Errorf("querying %s failed: %w", q.class, err))
+			continue
+		}
+		h.process(acc, kind, rows)
+	}
+
+	return nil
+}
+
+// execute runs the query on the local computer and returns the properties of
+// the resulting objects
+func (q *wmiQuery) execute(locator *ole.IDispatch) ([]row, error) {
+	// service is a SWbemServices
+	serviceRaw, err := oleutil.CallMethod(locator, "ConnectServer", nil, q.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed calling method ConnectServer: %w", err)
+	}
+	service := serviceRaw.ToIDispatch()
+	defer serviceRaw.Clear()
+
+	// result is a SWBemObjectSet
+	resultRaw, err := oleutil.CallMethod(service, "ExecQuery", q.wql())
+	if err != nil {
+		return nil, fmt.Errorf("failed calling method ExecQuery: %w", err)
+	}
+	result := resultRaw.ToIDispatch()
+	defer resultRaw.Clear()
+
+	countRaw, err := oleutil.GetProperty(result, "Count")
+	if err != nil {
+		return nil, fmt.Errorf("failed getting Count: %w", err)
+	}
+	count := countRaw.Val
+	defer countRaw.Clear()
+
+	rows := make([]row, 0, count)
+	for i := range count {
+		itemRaw, err := oleutil.CallMethod(result, "ItemIndex", i)
+		if err != nil {
+			return nil, fmt.Errorf("failed calling method ItemIndex: %w", err)
+		}
+		item := itemRaw.ToIDispatch()
+
+		r := make(row, len(q.properties))
+		for _, name := range q.properties {
+			// Skip properties not available on this version of Windows
+			propertyRaw, err := oleutil.GetProperty(item, name)
+			if err != nil {
+				continue
+			}
+			r[name] = propertyRaw.Value()
+			propertyRaw.Clear()
+		}
+		item.Release()
+
+		rows = append(rows, r)
+	}
+	return rows, nil
+}
@@ -21,9 +21,10 @@ var sampleConfig string
 var replacer = strings.NewReplacer("/", "_", ":", "_", "-", "_")
 
 type Internal struct {
-	CollectMemstats bool `toml:"collect_memstats"`
-	CollectGostats  bool `toml:"collect_gostats"`
-	PerInstance     bool `toml:"per_instance"`
+	CollectMemstats   bool `toml:"collect_memstats"`
+	CollectGostats    bool `toml:"collect_gostats"`
+	CollectHistograms bool `toml:"collect_histograms"`
+	PerInstance       bool `toml:"per_instance"`
 }
 
 func (*Internal) SampleConfig() string {
@@ -37,6 +38,10 @@ func (s *Internal) Gather(acc telegraf.Accumulator) error {
 		collectAccumulatedPluginStat(acc)
 	}
 
+	if s.CollectHistograms {
+		collectHistograms(acc, s.PerInstance)
+	}
+
 	if s.CollectMemstats {
 		collectMemStat(acc)
 	}
@@ -86,6 +91,44 @@ func collectAccumulatedPluginStat(acc telegraf.Accumulator) {
 	}
 }
 
+func collectHistograms(acc telegraf.Accumulator, perInstance bool) {
+	if perInstance {
+		for _, m := range selfstat.HistogramMetrics() {
+			m.AddTag("version", inter.Version)
+			acc.AddMetric(m)
+		}
+		return
+	}
+
+	// Merge the histograms of all instances of a plugin type by summing up the
+	// bucket counts, the counts and the sums
+	accumulated := make(map[uint64]telegraf.Metric)
+	for _, m := range selfstat.HistogramMetrics() {
+		m.AddTag("version", inter.Version)
+		key := m.HashIDWithFieldsFiltered([]string{"_id"}, nil)
+		am, found := accumulated[key]
+		if !found {
+			accumulated[key] = m.Copy()
+			accumulated[key].RemoveTag("_id")
+			continue
+		}
+		for _, f := range m.FieldList() {
+			switch v := f.Value.(type) {
+			case int64:
+				av, _ := am.GetField(f.Key)
+				am.AddField(f.Key, av.(int64)+v)
+			case float64:
+				av, _ := am.GetField(f.Key)
+				am.AddField(f.Key, av.(float64)+v)
+			}
+		}
+	}
+
+	for _, m := range accumulated {
+		acc.AddMetric(m)
+	}
+}
+
 func collectMemStat(acc telegraf.Accumulator) {
 	m := &runtime.MemStats{}
 	runtime.ReadMemStats(m)
@@ -257,3 +257,124 @@ func TestAccumulatedPerType(t *testing.T) {
 	}
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
 }
+
+func TestHistograms(t *testing.T) {
+	buckets := selfstat.HistogramBuckets
+	selfstat.HistogramBuckets = []time.Duration{time.Second}
+	defer func() { selfstat.HistogramBuckets = buckets }()
+
+	// Setup histograms for two instances of the same plugin type
+	for i := range 2 {
+		tags := map[string]string{"input": "foo", "_id": "id-" + strconv.Itoa(i)}
+		h := selfstat.RegisterHistogram("mytest_latency", "gather_time_seconds", tags)
+		defer selfstat.UnregisterHistogram("mytest_latency", "gather_time_seconds", tags)
+		h.Observe(time.Duration(i+1) * 500 * time.Millisecond)
+	}
+
+	tests := []struct {
+		name        string
+		perInstance bool
+		expected    []telegraf.Metric
+	}{
+		{
+			name: "per type",
+			expected: []telegraf.Metric{
+				metric.New(
+					"internal_mytest_latency",
+					map[string]string{"input": "foo", "le": "1", "version": "unknown"},
+					map[string]interface{}{"gather_time_seconds_bucket": int64(2)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New(
+					"internal_mytest_latency",
+					map[string]string{"input": "foo", "le": "+Inf", "version": "unknown"},
+					map[string]interface{}{"gather_time_seconds_bucket": int64(2)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New(
+					"internal_mytest_latency",
+					map[string]string{"input": "foo", "version": "unknown"},
+					map[string]interface{}{
+						"gather_time_seconds_sum":   float64(1.5),
+						"gather_time_seconds_count": int64(2),
+					},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+			},
+		},
+		{
+			name:        "per instance",
+			perInstance: true,
+			expected: []telegraf.Metric{
+				metric.New(
+					"internal_mytest_latency",
+					map[string]string{"_id": "id-0", "input": "foo", "le": "1", "version": "unknown"},
+					map[string]interface{}{"gather_time_seconds_bucket": int64(1)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New(
+					"internal_mytest_latency",
+					map[string]string{"_id": "id-0", "input": "foo", "le": "+Inf", "version": "unknown"},
+					map[string]interface{}{"gather_time_seconds_bucket": int64(1)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New(
+					"internal_mytest_latency",
+					map[string]string{"_id": "id-0", "input": "foo", "version": "unknown"},
+					map[string]interface{}{
+						"gather_time_seconds_sum":   float64(0.5),
+						"gather_time_seconds_count": int64(1),
+					},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New(
+					"internal_mytest_latency",
+					map[string]string{"_id": "id-1", "input": "foo", "le": "1", "version": "unknown"},
+					map[string]interface{}{"gather_time_seconds_bucket": int64(1)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New(
+					"internal_mytest_latency",
+					map[string]string{"_id": "id-1", "input": "foo", "le": "+Inf", "version": "unknown"},
+					map[string]interface{}{"gather_time_seconds_bucket": int64(1)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New(
+					"internal_mytest_latency",
+					map[string]string{"_id": "id-1", "input": "foo", "version": "unknown"},
+					map[string]interface{}{
+						"gather_time_seconds_sum":   float64(1),
+						"gather_time_seconds_count": int64(1),
+					},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := Internal{CollectHistograms: true, PerInstance: tt.perInstance}
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Gather(&acc))
+
+			var actual []telegraf.Metric
+			for _, m := range acc.GetTelegrafMetrics() {
+				if m.Name() == "internal_mytest_latency" {
+					actual = append(actual, m)
+				}
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+		})
+	}
+}
@@ -0,0 +1,701 @@
+package jfr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"unicode/utf16"
+)
+
+// Layout of the chunk header, see jdk.jfr.internal.consumer.ChunkHeader
+const (
+	headerSize       = 68
+	headerStateIndex = 64
+	headerFlagsIndex = 67
+	stateFinished    = 0
+	stateUpdating    = 0xff
+	flagCompressed   = 1
+)
+
+// Identifiers of the special events
+const (
+	metadataEventID   = 0
+	checkpointEventID = 1
+)
+
+// Limit the nesting of metadata elements and values to protect against
+// malformed chunks
+const maxDepth = 32
+
+var magic = []byte{'F', 'L', 'R', 0}
+
+type header struct {
+	major          uint16
+	size           int64
+	metadataOffset int64
+	ticksPerSecond int64
+	state          byte
+	flags          byte
+}
+
+func parseHeader(buf []byte) (*header, error) {
+	if len(buf) < headerSize {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if !bytes.Equal(buf[:4], magic) {
+		return nil, errors.New("not a flight recording")
+	}
+
+	h := &header{
+		major:          binary.BigEndian.Uint16(buf[4:]),
+		size:           int64(binary.BigEndian.Uint64(buf[8:])),
+		metadataOffset: int64(binary.BigEndian.Uint64(buf[24:])),
+		ticksPerSecond: int64(binary.BigEndian.Uint64(buf[56:])),
+		state:          buf[headerStateIndex],
+		flags:          buf[headerFlagsIndex],
+	}
+	if h.major != 1 && h.major != 2 {
+		return nil, fmt.Errorf("unsupported format version %d", h.major)
+	}
+	if h.ticksPerSecond <= 0 {
+		return nil, fmt.Errorf("invalid ticks per second %d", h.ticksPerSecond)
+	}
+	return h, nil
+}
+
+// class describes a type of the recording's metadata, events are classes
+// as well as the types of their fields
+type class struct {
+	id     int64
+	name   string
+	fields []classField
+	index  map[string]int
+}
+
+type classField struct {
+	name         string
+	class        int64
+	constantPool bool
+	array        bool
+}
+
+// object is a parsed instance of a class
+type object struct {
+	class  *class
+	values []interface{}
+}
+
+// ref references an entry of the constant pool of the given class
+type ref struct {
+	class int64
+	id    int64
+}
+
+// chunk holds the state of reading a chunk file of a JFR repository. The
+// recording JVM appends data to the current chunk and updates the header on
+// each flush, so chunks are read incrementally.
+type chunk struct {
+	path     string
+	offset   int64
+	finished bool
+
+	compressed     bool
+	ticksPerSecond int64
+	metadataOffset int64
+	classes        map[int64]*class
+	stringClass    int64
+	pools          map[int64]map[int64]interface{}
+}
+
+func newChunk(path string) *chunk {
+	return &chunk{
+		path:        path,
+		offset:      headerSize,
+		stringClass: -1,
+		pools:       make(map[int64]map[int64]interface{}),
+	}
+}
+
+// read processes the data flushed since the last call and passes the events
+// with a class accepted by the filter to the handler. When skipping events
+// only the metadata and constant pools are read.
+func (c *chunk) read(skipEvents bool, filter func(string) bool, handler func(*object)) error {
+	f, err := os.Open(c.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	buf := make([]byte, headerSize)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		// The header is not yet written completely
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+	h, err := parseHeader(buf)
+	if err != nil {
+		return err
+	}
+	// Retry with the next read if the JVM is currently updating the header
+	if h.state == stateUpdating {
+		return nil
+	}
+	c.compressed = h.flags&flagCompressed != 0
+	c.ticksPerSecond = h.ticksPerSecond
+
+	if h.metadataOffset >= headerSize && h.metadataOffset != c.metadataOffset {
+		data, err := readEvent(f, h.metadataOffset, c.compressed)
+		if err != nil {
+			return fmt.Errorf("reading metadata failed: %w", err)
+		}
+		if err := c.parseMetadata(data); err != nil {
+			return fmt.Errorf("parsing metadata failed: %w", err)
+		}
+		c.metadataOffset = h.metadataOffset
+	}
+
+	if h.size > c.offset {
+		buf := make([]byte, h.size-c.offset)
+		if _, err := f.ReadAt(buf, c.offset); err != nil {
+			return err
+		}
+
+		// The constant pools of the events are written after the events
+		// so read the pools of the whole region first
+		err := c.events(buf, func(typ int64, d *decoder) error {
+			if typ != checkpointEventID {
+				return nil
+			}
+			return c.parseCheckpoint(d)
+		})
+		if err != nil {
+			return fmt.Errorf("parsing constant pools failed: %w", err)
+		}
+
+		if !skipEvents {
+			err := c.events(buf, func(typ int64, d *decoder) error {
+				cls, found := c.classes[typ]
+				if typ <= checkpointEventID || !found || !filter(cls.name) {
+					return nil
+				}
+				v, err := c.parseClass(d, cls, 0)
+				if err != nil {
+					return fmt.Errorf("parsing event %q failed: %w", cls.name, err)
+				}
+				handler(v.(*object))
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		c.offset = h.size
+	}
+	c.finished = h.state == stateFinished
+
+	return nil
+}
+
+// events iterates over the events in the given buffer and calls the function
+// with the event type and a decoder positioned at the event's payload
+func (c *chunk) events(buf []byte, fn func(int64, *decoder) error) error {
+	for len(buf) > 0 {
+		d := &decoder{buf: buf, compressed: c.compressed}
+		size, err := d.integer(4)
+		if err != nil {
+			return err
+		}
+		if size <= 0 || size > int64(len(buf)) {
+			return fmt.Errorf("invalid event size %d", size)
+		}
+		d.buf = buf[:size]
+		typ, err := d.integer(8)
+		if err != nil {
+			return err
+		}
+		if err := fn(typ, d); err != nil {
+			return err
+		}
+		buf = buf[size:]
+	}
+	return nil
+}
+
+// readEvent reads the event starting at the given offset of the file
+func readEvent(f *os.File, offset int64, compressed bool) ([]byte, error) {
+	buf := make([]byte, 9)
+	n, err := f.ReadAt(buf, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	d := &decoder{buf: buf[:n], compressed: compressed}
+	size, err := d.integer(4)
+	if err != nil {
+		return nil, err
+	}
+	if size <= 0 || size > math.MaxInt32 {
+		return nil, fmt.Errorf("invalid event size %d", size)
+	}
+
+	buf = make([]byte, size)
+	if _, err := f.ReadAt(buf, offset); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+type element struct {
+	name     string
+	attrs    map[string]string
+	children []*element
+}
+
+// parseMetadata parses the metadata event describing all classes of the
+// chunk, the given buffer contains the whole event
+func (c *chunk) parseMetadata(buf []byte) error {
+	var typ int64
+	err := c.events(buf, func(t int64, d *decoder) error {
+		typ = t
+		if t != metadataEventID {
+			return nil
+		}
+
+		// Skip the start time, duration and metadata id
+		for range 3 {
+			if _, err := d.integer(8); err != nil {
+				return err
+			}
+		}
+
+		count, err := d.integer(4)
+		if err != nil {
+			return err
+		}
+		if count < 0 || count > int64(len(d.buf)) {
+			return fmt.Errorf("invalid string count %d", count)
+		}
+		strs := make([]string, 0, count)
+		for range count {
+			s, err := d.string()
+			if err != nil {
+				return err
+			}
+			str, ok := s.(string)
+			if !ok {
+				return errors.New("invalid string in string table")
+			}
+			strs = append(strs, str)
+		}
+
+		root, err := parseElement(d, strs, 0)
+		if err != nil {
+			return err
+		}
+		return c.setClasses(root)
+	})
+	if err != nil {
+		return err
+	}
+	if typ != metadataEventID {
+		return fmt.Errorf("unexpected event type %d", typ)
+	}
+	return nil
+}
+
+func parseElement(d *decoder, strs []string, depth int) (*element, error) {
+	if depth > maxDepth {
+		return nil, errors.New("elements nested too deeply")
+	}
+	lookup := func() (string, error) {
+		idx, err := d.integer(4)
+		if err != nil {
+			return "", err
+		}
+		if idx < 0 || idx >= int64(len(strs)) {
+			return "", fmt.Errorf("invalid string index %d", idx)
+		}
+		return strs[idx], nil
+	}
+
+	name, err := lookup()
+	if err != nil {
+		return nil, err
+	}
+	e := &element{name: name, attrs: make(map[string]string)}
+
+	count, err := d.integer(4)
+	if err != nil {
+		return nil, err
+	}
+	for range count {
+		key, err := lookup()
+		if err != nil {
+			return nil, err
+		}
+		value, err := lookup()
+		if err != nil {
+			return nil, err
+		}
+		e.attrs[key] = value
+	}
+
+	count, err = d.integer(4)
+	if err != nil {
+		return nil, err
+	}
+	for range count {
+		child, err := parseElement(d, strs, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		e.children = append(e.children, child)
+	}
+
+	return e, nil
+}
+
+func (c *chunk) setClasses(root *element) error {
+	classes := make(map[int64]*class)
+	for _, md := range root.children {
+		if md.name != "metadata" {
+			continue
+		}
+		for _, e := range md.children {
+			if e.name != "class" {
+				continue
+			}
+			id, err := strconv.ParseInt(e.attrs["id"], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid id of class %q: %w", e.attrs["name"], err)
+			}
+			cls := &class{id: id, name: e.attrs["name"], index: make(map[string]int)}
+			for _, fe := range e.children {
+				if fe.name != "field" {
+					continue
+				}
+				fid, err := strconv.ParseInt(fe.attrs["class"], 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid class of field %q: %w", fe.attrs["name"], err)
+				}
+				cls.index[fe.attrs["name"]] = len(cls.fields)
+				cls.fields = append(cls.fields, classField{
+					name:         fe.attrs["name"],
+					class:        fid,
+					constantPool: fe.attrs["constantPool"] == "true",
+					array:        fe.attrs["dimension"] == "1",
+				})
+			}
+			classes[id] = cls
+			if cls.name == "java.lang.String" {
+				c.stringClass = id
+			}
+		}
+	}
+	c.classes = classes
+
+	return nil
+}
+
+// parseCheckpoint parses a checkpoint event containing constant pool entries
+func (c *chunk) parseCheckpoint(d *decoder) error {
+	// Skip the start time, duration and the delta to the previous checkpoint
+	for range 3 {
+		if _, err := d.integer(8); err != nil {
+			return err
+		}
+	}
+	// Skip the checkpoint type
+	if _, err := d.byte(); err != nil {
+		return err
+	}
+
+	count, err := d.integer(4)
+	if err != nil {
+		return err
+	}
+	for range count {
+		id, err := d.integer(8)
+		if err != nil {
+			return err
+		}
+		cls, found := c.classes[id]
+		if !found {
+			return fmt.Errorf("unknown class %d in constant pool", id)
+		}
+		pool, found := c.pools[id]
+		if !found {
+			pool = make(map[int64]interface{})
+			c.pools[id] = pool
+		}
+
+		entries, err := d.integer(4)
+		if err != nil {
+			return err
+		}
+		for range entries {
+			key, err := d.integer(8)
+			if err != nil {
+				return err
+			}
+			v, err := c.parseClass(d, cls, 0)
+			if err != nil {
+				return fmt.Errorf("parsing constant of class %q failed: %w", cls.name, err)
+			}
+			pool[key] = v
+		}
+	}
+	return nil
+}
+
+// parseClass parses a value of the given class, primitives are returned as
+// Go values and all other classes as objects
+func (c *chunk) parseClass(d *decoder, cls *class, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, errors.New("values nested too deeply")
+	}
+
+	switch cls.name {
+	case "boolean":
+		b, err := d.byte()
+		return b != 0, err
+	case "byte":
+		b, err := d.byte()
+		return int64(int8(b)), err
+	case "char", "short":
+		return d.integer(2)
+	case "int":
+		return d.integer(4)
+	case "long":
+		return d.integer(8)
+	case "float":
+		v, err := d.raw(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(v))), nil
+	case "double":
+		v, err := d.raw(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(v)), nil
+	case "java.lang.String":
+		s, err := d.string()
+		if r, ok := s.(ref); ok {
+			r.class = c.stringClass
+			return r, err
+		}
+		return s, err
+	}
+
+	obj := &object{class: cls, values: make([]interface{}, 0, len(cls.fields))}
+	for _, f := range cls.fields {
+		v, err := c.parseField(d, f, depth)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", f.name, err)
+		}
+		obj.values = append(obj.values, v)
+	}
+	return obj, nil
+}
+
+func (c *chunk) parseField(d *decoder, f classField, depth int) (interface{}, error) {
+	if !f.array {
+		return c.parseSingle(d, f, depth)
+	}
+
+	count, err := d.integer(4)
+	if err != nil {
+		return nil, err
+	}
+	if count < 0 || count > int64(len(d.buf)) {
+		return nil, fmt.Errorf("invalid array length %d", count)
+	}
+	values := make([]interface{}, 0, count)
+	for range count {
+		v, err := c.parseSingle(d, f, depth)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func (c *chunk) parseSingle(d *decoder, f classField, depth int) (interface{}, error) {
+	if f.constantPool {
+		id, err := d.integer(8)
+		return ref{class: f.class, id: id}, err
+	}
+	cls, found := c.classes[f.class]
+	if !found {
+		return nil, fmt.Errorf("unknown class %d", f.class)
+	}
+	return c.parseClass(d, cls, depth+1)
+}
+
+// get returns the value of the given path of fields, constant pool
+// references are resolved and nil is returned for non-existing fields
+func (c *chunk) get(v interface{}, path ...string) interface{} {
+	for _, name := range path {
+		obj, ok := c.resolve(v).(*object)
+		if !ok {
+			return nil
+		}
+		idx, found := obj.class.index[name]
+		if !found {
+			return nil
+		}
+		v = obj.values[idx]
+	}
+	return c.resolve(v)
+}
+
+func (c *chunk) resolve(v interface{}) interface{} {
+	for range maxDepth {
+		r, ok := v.(ref)
+		if !ok {
+			return v
+		}
+		v = c.pools[r.class][r.id]
+	}
+	return nil
+}
+
+// integer returns the integer value of the given field path or zero
+func (c *chunk) integer(v interface{}, path ...string) int64 {
+	i, _ := c.get(v, path...).(int64)
+	return i
+}
+
+// millis returns the value in milliseconds of the given field path holding a
+// duration in ticks
+func (c *chunk) millis(v interface{}, path ...string) float64 {
+	return float64(c.integer(v, path...)) * 1000 / float64(c.ticksPerSecond)
+}
+
+// decoder reads the values of a chunk, integers are either compressed using
+// a variable-length encoding or stored as big-endian values
+type decoder struct {
+	buf        []byte
+	pos        int
+	compressed bool
+}
+
+func (d *decoder) byte() (byte, error) {
+	if d.pos >= len(d.buf) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := d.buf[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *decoder) raw(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.buf) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// integer reads an integer of the given size in bytes, compressed integers
+// consist of up to eight bytes carrying seven bits each followed by a byte
+// carrying the remaining eight bits
+func (d *decoder) integer(size int) (int64, error) {
+	if !d.compressed {
+		b, err := d.raw(size)
+		if err != nil {
+			return 0, err
+		}
+		switch size {
+		case 2:
+			return int64(int16(binary.BigEndian.Uint16(b))), nil
+		case 4:
+			return int64(int32(binary.BigEndian.Uint32(b))), nil
+		default:
+			return int64(binary.BigEndian.Uint64(b)), nil
+		}
+	}
+
+	var v uint64
+	for i := range 9 {
+		b, err := d.byte()
+		if err != nil {
+			return 0, err
+		}
+		if i == 8 {
+			v |= uint64(b) << 56
+			break
+		}
+		v |= uint64(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	return int64(v), nil
+}
+
+// string reads a string returning either the string, nil for null strings
+// or a reference to the string constant pool
+func (d *decoder) string() (interface{}, error) {
+	enc, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch enc {
+	case 0:
+		return nil, nil
+	case 1:
+		return "", nil
+	case 2:
+		id, err := d.integer(8)
+		return ref{id: id}, err
+	case 3, 5:
+		// UTF-8 and Latin-1 encoded bytes
+		n, err := d.integer(4)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.raw(int(n))
+		if err != nil {
+			return nil, err
+		}
+		if enc == 3 {
+			return string(b), nil
+		}
+		runes := make([]rune, 0, len(b))
+		for _, c := range b {
+			runes = append(runes, rune(c))
+		}
+		return string(runes), nil
+	case 4:
+		// UTF-16 code units
+		n, err := d.integer(4)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 || n > int64(len(d.buf)) {
+			return nil, fmt.Errorf("invalid string length %d", n)
+		}
+		units := make([]uint16, 0, n)
+		for range n {
+			u, err := d.integer(2)
+			if err != nil {
+				return nil, err
+			}
+			units = append(units, uint16(u))
+		}
+		return string(utf16.Decode(units)), nil
+	}
+	return nil, fmt.Errorf("unknown string encoding %d", enc)
+}
@@ -0,0 +1,282 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package jfr
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/globpath"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Events evaluated by the plugin
+var eventTypes = map[string]bool{
+	"jdk.GarbageCollection":      true,
+	"jdk.ObjectAllocationSample": true,
+	"jdk.ZAllocationStall":       true,
+	"jdk.JavaMonitorEnter":       true,
+	"jdk.ThreadPark":             true,
+}
+
+type JFR struct {
+	Repositories []string        `toml:"repositories"`
+	Log          telegraf.Logger `toml:"-"`
+
+	globs       []*globpath.GlobPath
+	jvms        map[string]*jvm
+	initialized bool
+}
+
+// jvm holds the state of a recording JVM identified by its repository
+type jvm struct {
+	repository string
+	pid        string
+	chunks     map[string]*chunk
+
+	gc            map[string]*gcStats
+	allocated     int64
+	stalls        int64
+	stallTime     float64
+	contention    map[string]*contentionStats
+	lastAllocated int64
+	lastGather    time.Time
+}
+
+type gcStats struct {
+	collections int64
+	pauseTime   float64
+	gcTime      float64
+}
+
+type contentionStats struct {
+	count int64
+	time  float64
+}
+
+func (*JFR) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *JFR) Init() error {
+	if len(j.Repositories) == 0 {
+		j.Repositories = []string{filepath.Join(os.TempDir(), "*_*")}
+	}
+
+	j.globs = make([]*globpath.GlobPath, 0, len(j.Repositories))
+	for _, pattern := range j.Repositories {
+		g, err := globpath.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("compiling repository pattern %q failed: %w", pattern, err)
+		}
+		j.globs = append(j.globs, g)
+	}
+	j.jvms = make(map[string]*jvm)
+
+	return nil
+}
+
+func (j *JFR) Gather(acc telegraf.Accumulator) error {
+	found := make(map[string]bool)
+	for _, g := range j.globs {
+		for _, path := range g.Match() {
+			if found[path] {
+				continue
+			}
+			if info, err := os.Stat(path); err != nil || !info.IsDir() {
+				continue
+			}
+			found[path] = true
+
+			v, ok := j.jvms[path]
+			if !ok {
+				v = newJVM(path)
+				j.jvms[path] = v
+			}
+
+			// Skip the events recorded before starting the plugin to not
+			// report the history of already running JVMs
+			active, err := v.update(!ok && !j.initialized, j.Log)
+			if err != nil {
+				acc.AddError(fmt.Errorf("reading repository %q failed: %w", path, err))
+				continue
+			}
+			if active {
+				v.collect(acc)
+			}
+		}
+	}
+	j.initialized = true
+
+	// Forget about JVMs that terminated
+	for path := range j.jvms {
+		if !found[path] {
+			delete(j.jvms, path)
+		}
+	}
+
+	return nil
+}
+
+func newJVM(repository string) *jvm {
+	// The JVM names its repository after the start time and its process id,
+	// e.g. 2024_06_10_12_00_00_4242
+	var pid string
+	if idx := strings.LastIndex(repository, "_"); idx >= 0 {
+		if _, err := strconv.ParseUint(repository[idx+1:], 10, 32); err == nil {
+			pid = repository[idx+1:]
+		}
+	}
+
+	return &jvm{
+		repository: repository,
+		pid:        pid,
+		chunks:     make(map[string]*chunk),
+		gc:         make(map[string]*gcStats),
+		contention: map[string]*contentionStats{
+			"monitor_enter": {},
+			"thread_park":   {},
+		},
+	}
+}
+
+// update reads the chunks of the repository and returns true if the
+// repository contains any chunk
+func (v *jvm) update(skipExisting bool, log telegraf.Logger) (bool, error) {
+	files, err := filepath.Glob(filepath.Join(v.repository, "*.jfr"))
+	if err != nil {
+		return false, err
+	}
+	// Chunks are named after their start time so sorting them by name
+	// processes them in order
+	sort.Strings(files)
+
+	current := make(map[string]bool, len(files))
+	for i, fn := range files {
+		current[fn] = true
+
+		c, found := v.chunks[fn]
+		if !found {
+			c = newChunk(fn)
+			v.chunks[fn] = c
+			if skipExisting && i < len(files)-1 {
+				c.finished = true
+			}
+		}
+		if c.finished {
+			continue
+		}
+
+		skipEvents := skipExisting && !found
+		err := c.read(skipEvents, func(name string) bool { return eventTypes[name] }, func(e *object) { v.handle(c, e) })
+		if err != nil {
+			// Do not retry broken chunks but continue with the next one
+			log.Errorf("Reading chunk %q failed: %v", fn, err)
+			c.finished = true
+		}
+	}
+
+	// Forget about chunks removed by the JVM
+	for fn := range v.chunks {
+		if !current[fn] {
+			delete(v.chunks, fn)
+		}
+	}
+
+	return len(files) > 0, nil
+}
+
+func (v *jvm) handle(c *chunk, e *object) {
+	switch e.class.name {
+	case "jdk.GarbageCollection":
+		name, _ := c.get(e, "name", "name").(string)
+		if name == "" {
+			name = "unknown"
+		}
+		s, found := v.gc[name]
+		if !found {
+			s = &gcStats{}
+			v.gc[name] = s
+		}
+		s.collections++
+		s.pauseTime += c.millis(e, "sumOfPauses")
+		s.gcTime += c.millis(e, "duration")
+	case "jdk.ObjectAllocationSample":
+		v.allocated += c.integer(e, "weight")
+	case "jdk.ZAllocationStall":
+		v.stalls++
+		v.stallTime += c.millis(e, "duration")
+	case "jdk.JavaMonitorEnter":
+		s := v.contention["monitor_enter"]
+		s.count++
+		s.time += c.millis(e, "duration")
+	case "jdk.ThreadPark":
+		s := v.contention["thread_park"]
+		s.count++
+		s.time += c.millis(e, "duration")
+	}
+}
+
+func (v *jvm) collect(acc telegraf.Accumulator) {
+	now := time.Now()
+
+	tags := map[string]string{"repository": v.repository}
+	if v.pid != "" {
+		tags["pid"] = v.pid
+	}
+
+	for name, s := range v.gc {
+		fields := map[string]interface{}{
+			"collections":   s.collections,
+			"pause_time_ms": s.pauseTime,
+			"gc_time_ms":    s.gcTime,
+		}
+		acc.AddCounter("jfr_gc", fields, withTag(tags, "name", name), now)
+	}
+
+	fields := map[string]interface{}{
+		"allocated_bytes": v.allocated,
+		"stalls":          v.stalls,
+		"stall_time_ms":   v.stallTime,
+	}
+	if !v.lastGather.IsZero() {
+		if elapsed := now.Sub(v.lastGather).Seconds(); elapsed > 0 {
+			fields["allocation_rate"] = float64(v.allocated-v.lastAllocated) / elapsed
+		}
+	}
+	acc.AddFields("jfr_allocation", fields, tags, now)
+	v.lastAllocated = v.allocated
+	v.lastGather = now
+
+	for typ, s := range v.contention {
+		fields := map[string]interface{}{
+			"count":   s.count,
+			"time_ms": s.time,
+		}
+		acc.AddCounter("jfr_contention", fields, withTag(tags, "type", typ), now)
+	}
+}
+
+func withTag(tags map[string]string, key, value string) map[string]string {
+	t := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		t[k] = v
+	}
+	t[key] = value
+	return t
+}
+
+func init() {
+	inputs.Add("jfr", func() telegraf.Input {
+		return &JFR{}
+	})
+}
@@ -0,0 +1,482 @@
+package jfr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// Classes of the test recording
+const (
+	classLong = iota + 1
+	classInt
+	classDouble
+	classString
+	classThread
+	classGCName
+	classGarbageCollection
+	classAllocationSample
+	classMonitorEnter
+	classThreadPark
+	classAllocationStall
+	classCPULoad
+)
+
+type testField struct {
+	name         string
+	class        int
+	constantPool bool
+	array        bool
+}
+
+type testClass struct {
+	id     int
+	name   string
+	fields []testField
+}
+
+var testClasses = []testClass{
+	{id: classLong, name: "long"},
+	{id: classInt, name: "int"},
+	{id: classDouble, name: "double"},
+	{id: classString, name: "java.lang.String"},
+	{id: classThread, name: "java.lang.Thread", fields: []testField{
+		{name: "javaName", class: classString},
+		{name: "javaThreadId", class: classLong},
+	}},
+	{id: classGCName, name: "jdk.types.GCName", fields: []testField{
+		{name: "name", class: classString},
+	}},
+	{id: classGarbageCollection, name: "jdk.GarbageCollection", fields: []testField{
+		{name: "startTime", class: classLong},
+		{name: "duration", class: classLong},
+		{name: "gcId", class: classInt},
+		{name: "name", class: classGCName, constantPool: true},
+		{name: "sumOfPauses", class: classLong},
+		{name: "longestPause", class: classLong},
+	}},
+	{id: classAllocationSample, name: "jdk.ObjectAllocationSample", fields: []testField{
+		{name: "startTime", class: classLong},
+		{name: "eventThread", class: classThread, constantPool: true},
+		{name: "frames", class: classLong, array: true},
+		{name: "weight", class: classLong},
+	}},
+	{id: classMonitorEnter, name: "jdk.JavaMonitorEnter", fields: []testField{
+		{name: "startTime", class: classLong},
+		{name: "duration", class: classLong},
+		{name: "eventThread", class: classThread, constantPool: true},
+	}},
+	{id: classThreadPark, name: "jdk.ThreadPark", fields: []testField{
+		{name: "startTime", class: classLong},
+		{name: "duration", class: classLong},
+		{name: "eventThread", class: classThread, constantPool: true},
+	}},
+	{id: classAllocationStall, name: "jdk.ZAllocationStall", fields: []testField{
+		{name: "startTime", class: classLong},
+		{name: "duration", class: classLong},
+		{name: "size", class: classLong},
+	}},
+	{id: classCPULoad, name: "jdk.CPULoad", fields: []testField{
+		{name: "startTime", class: classLong},
+		{name: "jvmUser", class: classDouble},
+	}},
+}
+
+// encoder writes values in the compressed format used by the JVM
+type encoder struct {
+	bytes.Buffer
+}
+
+func (e *encoder) integer(v int64) {
+	u := uint64(v)
+	for i := 0; i < 8; i++ {
+		if u < 0x80 {
+			e.WriteByte(byte(u))
+			return
+		}
+		e.WriteByte(byte(u&0x7f) | 0x80)
+		u >>= 7
+	}
+	e.WriteByte(byte(u))
+}
+
+func (e *encoder) string(s string) {
+	e.WriteByte(3)
+	e.integer(int64(len(s)))
+	e.WriteString(s)
+}
+
+func (e *encoder) double(v float64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], math.Float64bits(v))
+	e.Write(b[:])
+}
+
+// event prefixes the payload with its size and type
+func event(typ int64, payload func(*encoder)) []byte {
+	var body encoder
+	body.integer(typ)
+	payload(&body)
+
+	// Use a fixed-length size as the JVM does
+	var e encoder
+	size := uint64(body.Len() + 4)
+	e.Write([]byte{byte(size&0x7f | 0x80), byte(size>>7&0x7f | 0x80), byte(size>>14&0x7f | 0x80), byte(size >> 21 & 0x7f)})
+	e.Write(body.Bytes())
+	return e.Bytes()
+}
+
+func metadataEvent() []byte {
+	var strs []string
+	index := make(map[string]int64)
+	str := func(s string) int64 {
+		if idx, found := index[s]; found {
+			return idx
+		}
+		index[s] = int64(len(strs))
+		strs = append(strs, s)
+		return index[s]
+	}
+
+	var tree encoder
+	element := func(name string, attrs [][2]string, children int) {
+		tree.integer(str(name))
+		tree.integer(int64(len(attrs)))
+		for _, a := range attrs {
+			tree.integer(str(a[0]))
+			tree.integer(str(a[1]))
+		}
+		tree.integer(int64(children))
+	}
+
+	element("root", nil, 2)
+	element("metadata", nil, len(testClasses))
+	for _, c := range testClasses {
+		element("class", [][2]string{{"id", strconv.Itoa(c.id)}, {"name", c.name}}, len(c.fields)+1)
+		element("annotation", [][2]string{{"class", "200"}}, 0)
+		for _, f := range c.fields {
+			attrs := [][2]string{{"name", f.name}, {"class", strconv.Itoa(f.class)}}
+			if f.constantPool {
+				attrs = append(attrs, [2]string{"constantPool", "true"})
+			}
+			if f.array {
+				attrs = append(attrs, [2]string{"dimension", "1"})
+			}
+			element("field", attrs, 0)
+		}
+	}
+	element("region", [][2]string{{"locale", "en_US"}}, 0)
+
+	return event(metadataEventID, func(e *encoder) {
+		e.integer(0)
+		e.integer(0)
+		e.integer(1)
+		e.integer(int64(len(strs)))
+		for _, s := range strs {
+			e.string(s)
+		}
+		e.Write(tree.Bytes())
+	})
+}
+
+// checkpointEvent writes the constant pools mapping the class to the
+// encoded entries
+func checkpointEvent(pools map[int64][]func(*encoder)) []byte {
+	return event(checkpointEventID, func(e *encoder) {
+		e.integer(0)
+		e.integer(0)
+		e.integer(0)
+		e.WriteByte(1)
+		e.integer(int64(len(pools)))
+		for _, id := range []int64{classString, classGCName, classThread} {
+			entries, found := pools[id]
+			if !found {
+				continue
+			}
+			e.integer(id)
+			e.integer(int64(len(entries)))
+			for i, entry := range entries {
+				e.integer(int64(i + 1))
+				entry(e)
+			}
+		}
+	})
+}
+
+// writeChunk writes a chunk file consisting of the header, the metadata and
+// the given events
+func writeChunk(t *testing.T, path string, finished bool, events ...[]byte) {
+	t.Helper()
+
+	meta := metadataEvent()
+	var data []byte
+	data = append(data, meta...)
+	for _, e := range events {
+		data = append(data, e...)
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	binary.BigEndian.PutUint16(header[4:], 2)
+	binary.BigEndian.PutUint64(header[8:], uint64(headerSize+len(data)))
+	binary.BigEndian.PutUint64(header[24:], headerSize)
+	binary.BigEndian.PutUint64(header[56:], 1_000_000)
+	if !finished {
+		header[headerStateIndex] = 1
+	}
+	header[headerFlagsIndex] = flagCompressed
+
+	require.NoError(t, os.WriteFile(path, append(header, data...), 0o640))
+}
+
+func gcEvent(name, duration, pauses int64) []byte {
+	return event(classGarbageCollection, func(e *encoder) {
+		e.integer(1234)
+		e.integer(duration)
+		e.integer(1)
+		e.integer(name)
+		e.integer(pauses)
+		e.integer(pauses)
+	})
+}
+
+func durationEvent(typ, duration int64) []byte {
+	return event(typ, func(e *encoder) {
+		e.integer(1234)
+		e.integer(duration)
+		e.integer(1)
+	})
+}
+
+var testPools = checkpointEvent(map[int64][]func(*encoder){
+	classString: {
+		func(e *encoder) { e.string("G1Old") },
+	},
+	classGCName: {
+		func(e *encoder) { e.string("G1New") },
+		func(e *encoder) {
+			// Reference to the string constant pool
+			e.WriteByte(2)
+			e.integer(1)
+		},
+	},
+	classThread: {
+		func(e *encoder) {
+			e.string("main")
+			e.integer(1)
+		},
+	},
+})
+
+func testEvents() [][]byte {
+	return [][]byte{
+		gcEvent(1, 5000, 4000),
+		event(classAllocationSample, func(e *encoder) {
+			e.integer(1234)
+			e.integer(1)
+			e.integer(2)
+			e.integer(10)
+			e.integer(20)
+			e.integer(1 << 20)
+		}),
+		event(classCPULoad, func(e *encoder) {
+			e.integer(1234)
+			e.double(0.5)
+		}),
+		gcEvent(2, 150000, 20000),
+		gcEvent(1, 3000, 3000),
+		durationEvent(classMonitorEnter, 25000),
+		durationEvent(classThreadPark, 1500),
+		durationEvent(classThreadPark, 500),
+		event(classAllocationStall, func(e *encoder) {
+			e.integer(1234)
+			e.integer(12000)
+			e.integer(2 << 20)
+		}),
+		event(classAllocationSample, func(e *encoder) {
+			e.integer(1234)
+			e.integer(1)
+			e.integer(0)
+			e.integer(512)
+		}),
+		testPools,
+	}
+}
+
+func TestChunk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.jfr")
+	writeChunk(t, path, true, testEvents()...)
+
+	c := newChunk(path)
+	var events []*object
+	require.NoError(t, c.read(false, func(string) bool { return true }, func(e *object) { events = append(events, e) }))
+	require.True(t, c.finished)
+	require.Len(t, events, 10)
+
+	require.Equal(t, "jdk.GarbageCollection", events[0].class.name)
+	require.Equal(t, "G1New", c.get(events[0], "name", "name"))
+	require.Equal(t, "G1Old", c.get(events[3], "name", "name"))
+	require.InDelta(t, 4.0, c.millis(events[0], "sumOfPauses"), 1e-9)
+	require.InDelta(t, 150.0, c.millis(events[3], "duration"), 1e-9)
+
+	require.Equal(t, "jdk.ObjectAllocationSample", events[1].class.name)
+	require.Equal(t, "main", c.get(events[1], "eventThread", "javaName"))
+	require.Equal(t, []interface{}{int64(10), int64(20)}, c.get(events[1], "frames"))
+	require.Equal(t, int64(1<<20), c.integer(events[1], "weight"))
+
+	require.Equal(t, "jdk.CPULoad", events[2].class.name)
+	require.InDelta(t, 0.5, c.get(events[2], "jvmUser"), 1e-9)
+
+	require.Nil(t, c.get(events[2], "nonexisting"))
+	require.Nil(t, c.get(events[2], "jvmUser", "nonexisting"))
+}
+
+func TestChunkInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{
+			name:     "invalid magic",
+			data:     append([]byte("FOO\x00"), make([]byte, headerSize)...),
+			expected: "not a flight recording",
+		},
+		{
+			name: "invalid version",
+			data: func() []byte {
+				buf := make([]byte, headerSize)
+				copy(buf, magic)
+				binary.BigEndian.PutUint16(buf[4:], 3)
+				return buf
+			}(),
+			expected: "unsupported format version 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.jfr")
+			require.NoError(t, os.WriteFile(path, tt.data, 0o640))
+
+			c := newChunk(path)
+			err := c.read(false, func(string) bool { return true }, func(*object) {})
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+
+	// Incomplete headers are retried later
+	path := filepath.Join(t.TempDir(), "test.jfr")
+	require.NoError(t, os.WriteFile(path, magic, 0o640))
+	c := newChunk(path)
+	require.NoError(t, c.read(false, func(string) bool { return true }, func(*object) {}))
+	require.False(t, c.finished)
+}
+
+func TestGather(t *testing.T) {
+	repository := filepath.Join(t.TempDir(), "2024_06_10_12_00_00_4242")
+	require.NoError(t, os.Mkdir(repository, 0o750))
+	writeChunk(t, filepath.Join(repository, "2024_06_10_12_00_00.jfr"), false, testEvents()...)
+
+	plugin := &JFR{
+		Repositories: []string{filepath.Join(filepath.Dir(repository), "*_*")},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Events recorded before the first gather are skipped
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	tags := map[string]string{
+		"repository": repository,
+		"pid":        "4242",
+	}
+	expected := []telegraf.Metric{
+		metric.New("jfr_allocation", tags, map[string]interface{}{
+			"allocated_bytes": int64(0),
+			"stalls":          int64(0),
+			"stall_time_ms":   float64(0),
+		}, time.Unix(0, 0)),
+		metric.New("jfr_contention", withTag(tags, "type", "monitor_enter"), map[string]interface{}{
+			"count":   int64(0),
+			"time_ms": float64(0),
+		}, time.Unix(0, 0), telegraf.Counter),
+		metric.New("jfr_contention", withTag(tags, "type", "thread_park"), map[string]interface{}{
+			"count":   int64(0),
+			"time_ms": float64(0),
+		}, time.Unix(0, 0), telegraf.Counter),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// Flush more events to the chunk and finish it
+	events := append(testEvents(), testEvents()...)
+	writeChunk(t, filepath.Join(repository, "2024_06_10_12_00_00.jfr"), true, events...)
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected = []telegraf.Metric{
+		metric.New("jfr_gc", withTag(tags, "name", "G1New"), map[string]interface{}{
+			"collections":   int64(2),
+			"pause_time_ms": float64(7),
+			"gc_time_ms":    float64(8),
+		}, time.Unix(0, 0), telegraf.Counter),
+		metric.New("jfr_gc", withTag(tags, "name", "G1Old"), map[string]interface{}{
+			"collections":   int64(1),
+			"pause_time_ms": float64(20),
+			"gc_time_ms":    float64(150),
+		}, time.Unix(0, 0), telegraf.Counter),
+		metric.New("jfr_allocation", tags, map[string]interface{}{
+			"allocated_bytes": int64(1<<20 + 512),
+			"allocation_rate": float64(0),
+			"stalls":          int64(1),
+			"stall_time_ms":   float64(12),
+		}, time.Unix(0, 0)),
+		metric.New("jfr_contention", withTag(tags, "type", "monitor_enter"), map[string]interface{}{
+			"count":   int64(1),
+			"time_ms": float64(25),
+		}, time.Unix(0, 0), telegraf.Counter),
+		metric.New("jfr_contention", withTag(tags, "type", "thread_park"), map[string]interface{}{
+			"count":   int64(2),
+			"time_ms": float64(2),
+		}, time.Unix(0, 0), telegraf.Counter),
+	}
+	options := []cmp.Option{
+		testutil.IgnoreTime(),
+		testutil.SortMetrics(),
+		testutil.IgnoreFields("allocation_rate"),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+
+	// A new chunk is read completely
+	writeChunk(t, filepath.Join(repository, "2024_06_10_12_01_00.jfr"), false, gcEvent(1, 1000, 1000), testPools)
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	require.True(t, acc.HasPoint("jfr_gc", withTag(tags, "name", "G1New"), "collections", int64(3)))
+	require.Len(t, plugin.jvms[repository].chunks, 2)
+
+	// Terminated JVMs are removed
+	require.NoError(t, os.RemoveAll(repository))
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Empty(t, plugin.jvms)
+}
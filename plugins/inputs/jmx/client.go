@@ -0,0 +1,114 @@
+package jmx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+// request is a Jolokia request object, e.g.
+//
+//	{"type": "search", "mbean": "java.lang:type=GarbageCollector,*"}
+type request struct {
+	Type  string `json:"type"`
+	MBean string `json:"mbean"`
+}
+
+// response is a Jolokia response object, e.g.
+//
+//	{
+//	  "request": {"type": "read", "mbean": "java.lang:type=Runtime"},
+//	  "value": {"Uptime": 1214083, ...},
+//	  "timestamp": 1488059309,
+//	  "status": 200
+//	}
+type response struct {
+	Value  interface{} `json:"value"`
+	Status int         `json:"status"`
+	Error  string      `json:"error"`
+}
+
+type client struct {
+	url       string
+	username  config.Secret
+	password  config.Secret
+	origin    string
+	batchSize int
+	client    *http.Client
+}
+
+// bulk sends the given requests in batches of at most the configured batch
+// size and returns the responses in the order of the requests
+func (c *client) bulk(requests []request) ([]response, error) {
+	responses := make([]response, 0, len(requests))
+	for start := 0; start < len(requests); start += c.batchSize {
+		end := min(start+c.batchSize, len(requests))
+		batch, err := c.send(requests[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("received %d responses for %d requests", len(batch), end-start)
+		}
+		responses = append(responses, batch...)
+	}
+	return responses, nil
+}
+
+func (c *client) send(requests []request) ([]response, error) {
+	body, err := json.Marshal(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", c.url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.origin != "" {
+		req.Header.Set("Origin", c.origin)
+	}
+	if !c.username.Empty() || !c.password.Empty() {
+		username, err := c.username.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting username failed: %w", err)
+		}
+		password, err := c.password.Get()
+		if err != nil {
+			username.Destroy()
+			return nil, fmt.Errorf("getting password failed: %w", err)
+		}
+		req.SetBasicAuth(username.String(), password.String())
+		username.Destroy()
+		password.Destroy()
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received status %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response failed: %w", err)
+	}
+
+	// Keep the numbers to be able to distinguish integer and float values
+	var responses []response
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&responses); err != nil {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+	return responses, nil
+}
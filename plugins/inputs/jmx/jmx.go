@@ -0,0 +1,206 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package jmx
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type JMX struct {
+	URLs           []string        `toml:"urls"`
+	Username       config.Secret   `toml:"username"`
+	Password       config.Secret   `toml:"password"`
+	Origin         string          `toml:"origin"`
+	Templates      []string        `toml:"templates"`
+	BatchSize      int             `toml:"batch_size"`
+	FieldSeparator string          `toml:"field_separator"`
+	MBeans         []*mbean        `toml:"mbean"`
+	Log            telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	mbeans  []*mbean
+	clients []*client
+}
+
+func (*JMX) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *JMX) Init() error {
+	if len(j.URLs) == 0 {
+		return errors.New("no URLs specified")
+	}
+	if j.BatchSize < 1 {
+		return errors.New("'batch_size' must be positive")
+	}
+
+	j.mbeans = make([]*mbean, 0, len(j.MBeans))
+	for i, m := range j.MBeans {
+		if err := m.init(); err != nil {
+			return fmt.Errorf("mbean %d: %w", i+1, err)
+		}
+		j.mbeans = append(j.mbeans, m)
+	}
+	for _, name := range j.Templates {
+		tmpl, found := templates[name]
+		if !found {
+			return fmt.Errorf("unknown template %q", name)
+		}
+		for _, m := range tmpl {
+			// Copy the template definition to not modify the global instance
+			m = &mbean{
+				Name:       m.Name,
+				Pattern:    m.Pattern,
+				Attributes: slices.Clone(m.Attributes),
+				TagKeys:    slices.Clone(m.TagKeys),
+			}
+			if err := m.init(); err != nil {
+				return fmt.Errorf("template %q mbean %q: %w", name, m.Name, err)
+			}
+			j.mbeans = append(j.mbeans, m)
+		}
+	}
+	if len(j.mbeans) == 0 {
+		return errors.New("neither 'mbean' nor 'templates' specified")
+	}
+
+	j.clients = make([]*client, 0, len(j.URLs))
+	for _, u := range j.URLs {
+		httpClient, err := j.HTTPClientConfig.CreateClient(context.Background(), j.Log)
+		if err != nil {
+			return fmt.Errorf("creating client for %q failed: %w", u, err)
+		}
+		j.clients = append(j.clients, &client{
+			url:       u,
+			username:  j.Username,
+			password:  j.Password,
+			origin:    j.Origin,
+			batchSize: j.BatchSize,
+			client:    httpClient,
+		})
+	}
+
+	return nil
+}
+
+func (j *JMX) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, c := range j.clients {
+		wg.Add(1)
+		go func(c *client) {
+			defer wg.Done()
+			if err := j.gatherAgent(acc, c); err != nil {
+				acc.AddError(fmt.Errorf("gathering from %q failed: %w", c.url, err))
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// target is a concrete MBean object name to read
+type target struct {
+	def  *mbean
+	name string
+}
+
+func (j *JMX) gatherAgent(acc telegraf.Accumulator, c *client) error {
+	// Resolve the patterns to the object names currently registered with the
+	// agent and collect the concrete names directly
+	targets := make([]target, 0, len(j.mbeans))
+	searches := make([]request, 0, len(j.mbeans))
+	searchDefs := make([]*mbean, 0, len(j.mbeans))
+	for _, m := range j.mbeans {
+		if !m.isPattern {
+			targets = append(targets, target{def: m, name: m.Pattern})
+			continue
+		}
+		searches = append(searches, request{Type: "search", MBean: m.Pattern})
+		searchDefs = append(searchDefs, m)
+	}
+
+	responses, err := c.bulk(searches)
+	if err != nil {
+		return fmt.Errorf("searching mbeans failed: %w", err)
+	}
+	for i, resp := range responses {
+		m := searchDefs[i]
+		if resp.Status != http.StatusOK {
+			acc.AddError(fmt.Errorf("searching %q on %q failed with status %d: %s", m.Pattern, c.url, resp.Status, resp.Error))
+			continue
+		}
+		names, ok := resp.Value.([]interface{})
+		if !ok {
+			acc.AddError(fmt.Errorf("unexpected search result type %T for %q on %q", resp.Value, m.Pattern, c.url))
+			continue
+		}
+		for _, n := range names {
+			if name, ok := n.(string); ok {
+				targets = append(targets, target{def: m, name: name})
+			}
+		}
+	}
+
+	// Read all attributes of the objects in bulk. Filtering the attributes
+	// is done locally as Jolokia rejects the whole request if one of the
+	// requested attributes does not exist for the object.
+	reads := make([]request, 0, len(targets))
+	for _, t := range targets {
+		reads = append(reads, request{Type: "read", MBean: t.name})
+	}
+	responses, err = c.bulk(reads)
+	if err != nil {
+		return fmt.Errorf("reading mbeans failed: %w", err)
+	}
+	for i, resp := range responses {
+		t := targets[i]
+		switch resp.Status {
+		case http.StatusOK:
+		case http.StatusNotFound:
+			// The object might have been unregistered in the meantime
+			j.Log.Debugf("Object %q not found on %q: %s", t.name, c.url, resp.Error)
+			continue
+		default:
+			acc.AddError(fmt.Errorf("reading %q on %q failed with status %d: %s", t.name, c.url, resp.Status, resp.Error))
+			continue
+		}
+
+		values, ok := resp.Value.(map[string]interface{})
+		if !ok {
+			acc.AddError(fmt.Errorf("unexpected read result type %T for %q on %q", resp.Value, t.name, c.url))
+			continue
+		}
+		fields := t.def.fields(values, j.FieldSeparator)
+		if len(fields) == 0 {
+			continue
+		}
+		tags := t.def.tags(t.name)
+		tags["jolokia_agent_url"] = c.url
+		acc.AddFields(t.def.Name, fields, tags)
+	}
+
+	return nil
+}
+
+func init() {
+	inputs.Add("jmx", func() telegraf.Input {
+		return &JMX{
+			BatchSize:      100,
+			FieldSeparator: "_",
+		}
+	})
+}
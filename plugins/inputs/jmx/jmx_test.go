@@ -0,0 +1,270 @@
+package jmx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// Objects registered with the mocked Jolokia agent
+var objects = map[string]string{
+	"java.lang:type=Memory": `{
+		"HeapMemoryUsage": {"init": 1024, "used": 512, "committed": 2048, "max": 4096},
+		"ObjectPendingFinalizationCount": 0,
+		"Verbose": false
+	}`,
+	"java.lang:name=G1 Young Generation,type=GarbageCollector": `{
+		"CollectionCount": 10,
+		"CollectionTime": 123,
+		"Name": "G1 Young Generation",
+		"MemoryPoolNames": ["G1 Eden Space", "G1 Survivor Space"]
+	}`,
+	"java.lang:name=G1 Old Generation,type=GarbageCollector": `{
+		"CollectionCount": 1,
+		"CollectionTime": 42,
+		"Name": "G1 Old Generation",
+		"MemoryPoolNames": ["G1 Old Gen"]
+	}`,
+	"kafka.server:name=BytesInPerSec,topic=test,type=BrokerTopicMetrics": `{
+		"Count": 4711,
+		"OneMinuteRate": 1.5,
+		"RateUnit": "SECONDS"
+	}`,
+	"com.example:type=Custom": `{
+		"Counter": "42",
+		"Ratio": 0.25
+	}`,
+}
+
+func newAgent(t *testing.T, batches *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		batches.Add(1)
+
+		var requests []request
+		if err := json.NewDecoder(r.Body).Decode(&requests); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			t.Error(err)
+			return
+		}
+
+		responses := make([]map[string]interface{}, 0, len(requests))
+		for _, req := range requests {
+			switch req.Type {
+			case "search":
+				domain, _, _ := strings.Cut(req.MBean, ":")
+				names := make([]string, 0)
+				for name := range objects {
+					if strings.HasPrefix(name, domain+":") && strings.Contains(req.MBean, "*") {
+						if strings.Contains(req.MBean, "GarbageCollector") && !strings.Contains(name, "GarbageCollector") {
+							continue
+						}
+						names = append(names, name)
+					}
+				}
+				responses = append(responses, map[string]interface{}{"status": 200, "value": names})
+			case "read":
+				value, found := objects[req.MBean]
+				if !found {
+					responses = append(responses, map[string]interface{}{
+						"status": 404,
+						"error":  "javax.management.InstanceNotFoundException : " + req.MBean,
+					})
+					continue
+				}
+				responses = append(responses, map[string]interface{}{"status": 200, "value": json.RawMessage(value)})
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(responses); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func TestGather(t *testing.T) {
+	var batches atomic.Int32
+	server := newAgent(t, &batches)
+	defer server.Close()
+
+	plugin := &JMX{
+		URLs:           []string{server.URL},
+		BatchSize:      2,
+		FieldSeparator: "_",
+		MBeans: []*mbean{
+			{
+				Name:       "jvm_memory",
+				Pattern:    "java.lang:type=Memory",
+				Attributes: []string{"HeapMemoryUsage", "ObjectPendingFinalizationCount"},
+			},
+			{
+				Name:    "jvm_garbage_collector",
+				Pattern: "java.lang:type=GarbageCollector,name=*",
+			},
+			{
+				Name:       "kafka_broker_topic",
+				Pattern:    "kafka.server:type=BrokerTopicMetrics,*",
+				Attributes: []string{"Count", "OneMinuteRate"},
+			},
+			{
+				Name:    "custom",
+				Pattern: "com.example:type=Custom",
+				Types:   map[string]string{"Counter": "int", "Ratio": "string"},
+			},
+			{
+				Name:    "missing",
+				Pattern: "com.example:type=Missing",
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	// Two search requests in one batch and six read requests in three batches
+	require.Equal(t, int32(4), batches.Load())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"jvm_memory",
+			map[string]string{"jolokia_agent_url": server.URL},
+			map[string]interface{}{
+				"HeapMemoryUsage_init":           int64(1024),
+				"HeapMemoryUsage_used":           int64(512),
+				"HeapMemoryUsage_committed":      int64(2048),
+				"HeapMemoryUsage_max":            int64(4096),
+				"ObjectPendingFinalizationCount": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"jvm_garbage_collector",
+			map[string]string{"jolokia_agent_url": server.URL, "name": "G1 Young Generation"},
+			map[string]interface{}{
+				"CollectionCount": int64(10),
+				"CollectionTime":  int64(123),
+				"Name":            "G1 Young Generation",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"jvm_garbage_collector",
+			map[string]string{"jolokia_agent_url": server.URL, "name": "G1 Old Generation"},
+			map[string]interface{}{
+				"CollectionCount": int64(1),
+				"CollectionTime":  int64(42),
+				"Name":            "G1 Old Generation",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"kafka_broker_topic",
+			map[string]string{"jolokia_agent_url": server.URL, "name": "BytesInPerSec", "topic": "test"},
+			map[string]interface{}{
+				"Count":         int64(4711),
+				"OneMinuteRate": float64(1.5),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"custom",
+			map[string]string{"jolokia_agent_url": server.URL},
+			map[string]interface{}{
+				"Counter": int64(42),
+				"Ratio":   "0.25",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestTemplates(t *testing.T) {
+	plugin := &JMX{
+		URLs:      []string{"http://localhost:8778/jolokia"},
+		BatchSize: 100,
+		Templates: []string{"jvm", "kafka", "cassandra", "tomcat"},
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var count int
+	for _, tmpl := range templates {
+		count += len(tmpl)
+	}
+	require.Len(t, plugin.mbeans, count)
+}
+
+func TestInvalidSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *JMX
+		expected string
+	}{
+		{
+			name:     "no urls",
+			plugin:   &JMX{BatchSize: 100},
+			expected: "no URLs specified",
+		},
+		{
+			name:     "invalid batch size",
+			plugin:   &JMX{URLs: []string{"http://localhost"}},
+			expected: "'batch_size' must be positive",
+		},
+		{
+			name:     "no mbeans",
+			plugin:   &JMX{URLs: []string{"http://localhost"}, BatchSize: 100},
+			expected: "neither 'mbean' nor 'templates' specified",
+		},
+		{
+			name: "unknown template",
+			plugin: &JMX{
+				URLs:      []string{"http://localhost"},
+				BatchSize: 100,
+				Templates: []string{"weblogic"},
+			},
+			expected: `unknown template "weblogic"`,
+		},
+		{
+			name: "invalid pattern",
+			plugin: &JMX{
+				URLs:      []string{"http://localhost"},
+				BatchSize: 100,
+				MBeans:    []*mbean{{Name: "test", Pattern: "java.lang"}},
+			},
+			expected: `invalid pattern "java.lang"`,
+		},
+		{
+			name: "invalid type",
+			plugin: &JMX{
+				URLs:      []string{"http://localhost"},
+				BatchSize: 100,
+				MBeans: []*mbean{{
+					Name:    "test",
+					Pattern: "java.lang:type=Memory",
+					Types:   map[string]string{"Verbose": "boolean"},
+				}},
+			},
+			expected: `invalid type "boolean" for field "Verbose"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
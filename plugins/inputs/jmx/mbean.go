@@ -0,0 +1,133 @@
+package jmx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
+)
+
+type mbean struct {
+	Name       string            `toml:"name"`
+	Pattern    string            `toml:"pattern"`
+	Attributes []string          `toml:"attributes"`
+	TagKeys    []string          `toml:"tag_keys"`
+	Types      map[string]string `toml:"types"`
+
+	isPattern bool
+	fixed     map[string]bool
+}
+
+func (m *mbean) init() error {
+	if m.Name == "" {
+		return errors.New("'name' required")
+	}
+	domain, properties, found := strings.Cut(m.Pattern, ":")
+	if !found || domain == "" || properties == "" {
+		return fmt.Errorf("invalid pattern %q", m.Pattern)
+	}
+	for field, t := range m.Types {
+		switch t {
+		case "int", "uint", "float", "string", "bool":
+		default:
+			return fmt.Errorf("invalid type %q for field %q", t, field)
+		}
+	}
+
+	// Remember the properties with a fixed value, all other properties are
+	// used as tags if not specified otherwise
+	m.isPattern = strings.ContainsAny(m.Pattern, "*?")
+	m.fixed = make(map[string]bool)
+	for _, property := range strings.Split(properties, ",") {
+		key, value, found := strings.Cut(property, "=")
+		if found && !strings.ContainsAny(value, "*?") {
+			m.fixed[key] = true
+		}
+	}
+
+	return nil
+}
+
+// tags returns the key properties of the given object name to be used as tags
+func (m *mbean) tags(name string) map[string]string {
+	tags := make(map[string]string)
+	_, properties, _ := strings.Cut(name, ":")
+	for _, property := range strings.Split(properties, ",") {
+		key, value, found := strings.Cut(property, "=")
+		if !found {
+			continue
+		}
+		if m.TagKeys != nil {
+			if !choice.Contains(key, m.TagKeys) {
+				continue
+			}
+		} else if m.fixed[key] {
+			continue
+		}
+		tags[key] = strings.Trim(value, `"`)
+	}
+	return tags
+}
+
+// fields flattens the selected attributes into fields and converts the values
+// to the configured types
+func (m *mbean) fields(values map[string]interface{}, separator string) map[string]interface{} {
+	fields := make(map[string]interface{})
+	for attribute, value := range values {
+		if len(m.Attributes) > 0 && !choice.Contains(attribute, m.Attributes) {
+			continue
+		}
+		flatten(fields, attribute, value, separator)
+	}
+
+	for name, value := range fields {
+		t, found := m.Types[name]
+		if !found {
+			continue
+		}
+		converted, err := convert(value, t)
+		if err != nil {
+			delete(fields, name)
+			continue
+		}
+		fields[name] = converted
+	}
+	return fields
+}
+
+func flatten(fields map[string]interface{}, name string, value interface{}, separator string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range v {
+			flatten(fields, name+separator+key, inner, separator)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			fields[name] = i
+		} else if f, err := v.Float64(); err == nil {
+			fields[name] = f
+		}
+	case string, bool:
+		fields[name] = v
+	}
+	// Arrays and null values are ignored
+}
+
+func convert(value interface{}, t string) (interface{}, error) {
+	switch t {
+	case "int":
+		return internal.ToInt64(value)
+	case "uint":
+		return internal.ToUint64(value)
+	case "float":
+		return internal.ToFloat64(value)
+	case "string":
+		return internal.ToString(value)
+	case "bool":
+		return internal.ToBool(value)
+	}
+	return nil, fmt.Errorf("invalid type %q", t)
+}
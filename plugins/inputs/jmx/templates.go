@@ -0,0 +1,120 @@
+package jmx
+
+// templates contains the MBean definitions for commonly monitored
+// applications
+var templates = map[string][]*mbean{
+	"jvm": {
+		{
+			Name:       "jvm_memory",
+			Pattern:    "java.lang:type=Memory",
+			Attributes: []string{"HeapMemoryUsage", "NonHeapMemoryUsage", "ObjectPendingFinalizationCount"},
+		},
+		{
+			Name:       "jvm_memory_pool",
+			Pattern:    "java.lang:type=MemoryPool,name=*",
+			Attributes: []string{"Usage", "PeakUsage", "CollectionUsage"},
+		},
+		{
+			Name:       "jvm_garbage_collector",
+			Pattern:    "java.lang:type=GarbageCollector,name=*",
+			Attributes: []string{"CollectionTime", "CollectionCount"},
+		},
+		{
+			Name:       "jvm_threading",
+			Pattern:    "java.lang:type=Threading",
+			Attributes: []string{"ThreadCount", "DaemonThreadCount", "PeakThreadCount", "TotalStartedThreadCount"},
+		},
+		{
+			Name:       "jvm_class_loading",
+			Pattern:    "java.lang:type=ClassLoading",
+			Attributes: []string{"LoadedClassCount", "UnloadedClassCount", "TotalLoadedClassCount"},
+		},
+		{
+			Name:       "jvm_runtime",
+			Pattern:    "java.lang:type=Runtime",
+			Attributes: []string{"Uptime"},
+		},
+	},
+	"kafka": {
+		{
+			Name:       "kafka_broker_topic",
+			Pattern:    "kafka.server:type=BrokerTopicMetrics,*",
+			Attributes: []string{"Count", "OneMinuteRate"},
+		},
+		{
+			Name:       "kafka_replica_manager",
+			Pattern:    "kafka.server:type=ReplicaManager,name=*",
+			Attributes: []string{"Value", "Count", "OneMinuteRate"},
+		},
+		{
+			Name:       "kafka_controller",
+			Pattern:    "kafka.controller:type=KafkaController,name=*",
+			Attributes: []string{"Value"},
+		},
+		{
+			Name:       "kafka_request",
+			Pattern:    "kafka.network:type=RequestMetrics,name=*,request=*",
+			Attributes: []string{"Count", "Mean", "99thPercentile"},
+		},
+		{
+			Name:       "kafka_purgatory",
+			Pattern:    "kafka.server:type=DelayedOperationPurgatory,name=*,delayedOperation=*",
+			Attributes: []string{"Value"},
+		},
+	},
+	"cassandra": {
+		{
+			Name:       "cassandra_client_request",
+			Pattern:    "org.apache.cassandra.metrics:type=ClientRequest,scope=*,name=*",
+			Attributes: []string{"Count", "Mean", "99thPercentile", "OneMinuteRate"},
+		},
+		{
+			Name:       "cassandra_compaction",
+			Pattern:    "org.apache.cassandra.metrics:type=Compaction,name=*",
+			Attributes: []string{"Value", "Count"},
+		},
+		{
+			Name:       "cassandra_storage",
+			Pattern:    "org.apache.cassandra.metrics:type=Storage,name=*",
+			Attributes: []string{"Count"},
+		},
+		{
+			Name:       "cassandra_thread_pools",
+			Pattern:    "org.apache.cassandra.metrics:type=ThreadPools,path=*,scope=*,name=*",
+			Attributes: []string{"Value", "Count"},
+		},
+		{
+			Name:       "cassandra_dropped_message",
+			Pattern:    "org.apache.cassandra.metrics:type=DroppedMessage,scope=*,name=*",
+			Attributes: []string{"Count", "OneMinuteRate"},
+		},
+		{
+			Name:       "cassandra_cache",
+			Pattern:    "org.apache.cassandra.metrics:type=Cache,scope=*,name=*",
+			Attributes: []string{"Value", "Count"},
+		},
+	},
+	"tomcat": {
+		{
+			Name:       "tomcat_global_request_processor",
+			Pattern:    "Catalina:type=GlobalRequestProcessor,name=*",
+			Attributes: []string{"requestCount", "errorCount", "bytesReceived", "bytesSent", "processingTime", "maxTime"},
+		},
+		{
+			Name:       "tomcat_thread_pool",
+			Pattern:    "Catalina:type=ThreadPool,name=*",
+			Attributes: []string{"maxThreads", "currentThreadCount", "currentThreadsBusy", "connectionCount"},
+		},
+		{
+			Name:       "tomcat_servlet",
+			Pattern:    "Catalina:j2eeType=Servlet,WebModule=*,name=*,J2EEApplication=*,J2EEServer=*",
+			Attributes: []string{"requestCount", "errorCount", "processingTime"},
+			TagKeys:    []string{"WebModule", "name"},
+		},
+		{
+			Name:       "tomcat_session",
+			Pattern:    "Catalina:type=Manager,host=*,context=*",
+			Attributes: []string{"activeSessions", "sessionCounter", "expiredSessions", "rejectedSessions"},
+		},
+	},
+}
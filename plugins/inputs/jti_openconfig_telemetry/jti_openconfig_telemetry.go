@@ -1,3 +1,4 @@
+//go:generate ../../../tools/config_includer/generator
 //go:generate ../../../tools/readme_config_includer/generator
 package jti_openconfig_telemetry
 
@@ -22,6 +23,8 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/statecache"
 	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	authentication "github.com/influxdata/telegraf/plugins/inputs/jti_openconfig_telemetry/auth"
@@ -49,9 +52,11 @@ type OpenConfigTelemetry struct {
 	EnableTLS       bool            `toml:"enable_tls"`
 	KeepAlivePeriod config.Duration `toml:"keep_alive_period"`
 	common_tls.ClientConfig
+	statecache.CacheConfig
 
 	Log telegraf.Logger `toml:"-"`
 
+	cache           *statecache.Cache
 	sensorsConfig   []sensorConfig
 	grpcClientConns []grpcConnection
 	wg              *sync.WaitGroup
@@ -85,6 +90,13 @@ func (m *OpenConfigTelemetry) Init() error {
 		return fmt.Errorf("unknown option for timestamp_source: %q", m.TimestampSource)
 	}
 
+	// Setup the device-state cache if snapshots are requested
+	cache, err := m.CacheConfig.CreateCache()
+	if err != nil {
+		return fmt.Errorf("creating state cache failed: %w", err)
+	}
+	m.cache = cache
+
 	return nil
 }
 
@@ -172,7 +184,14 @@ func (m *OpenConfigTelemetry) Start(acc telegraf.Accumulator) error {
 	return nil
 }
 
-func (*OpenConfigTelemetry) Gather(telegraf.Accumulator) error {
+func (m *OpenConfigTelemetry) Gather(acc telegraf.Accumulator) error {
+	if m.cache == nil {
+		return nil
+	}
+
+	for _, series := range m.cache.Snapshot(time.Now()) {
+		acc.AddMetric(series)
+	}
 	return nil
 }
 
@@ -415,11 +434,17 @@ func (m *OpenConfigTelemetry) collectData(
 							}
 						}
 
-						if len(group.tags) == 0 {
-							acc.AddFields(sensor.measurementName, group.data, tags, timestamp)
-						} else {
-							acc.AddFields(sensor.measurementName, group.data, group.tags, timestamp)
+						groupTags := group.tags
+						if len(groupTags) == 0 {
+							groupTags = tags
+						}
+
+						// Merge the data into the device state if snapshots are requested
+						if m.cache != nil {
+							m.cache.AddMetric(metric.New(sensor.measurementName, groupTags, group.data, timestamp))
+							continue
 						}
+						acc.AddFields(sensor.measurementName, group.data, groupTags, timestamp)
 					}
 				}
 			}
@@ -11,7 +11,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/statecache"
 	telemetry "github.com/influxdata/telegraf/plugins/inputs/jti_openconfig_telemetry/oc"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -54,6 +57,23 @@ var dataWithTimestamp = &telemetry.OpenConfigData{
 	Timestamp: 1676560510002,
 }
 
+var dataOnChange = []*telemetry.OpenConfigData{
+	{
+		Path: "/sensor_on_change",
+		Kv: []*telemetry.KeyValue{
+			{Key: "/sensor[tag='tagValue']/intKey", Value: &telemetry.KeyValue_IntValue{IntValue: 10}},
+			{Key: "/sensor[tag='tagValue']/strKey", Value: &telemetry.KeyValue_StrValue{StrValue: "up"}},
+		},
+	},
+	{
+		Path: "/sensor_on_change",
+		Kv: []*telemetry.KeyValue{
+			{Key: "/sensor[tag='tagValue']/intKey", Value: &telemetry.KeyValue_IntValue{IntValue: 20}},
+		},
+		SequenceNumber: 1,
+	},
+}
+
 type openConfigTelemetryServer struct {
 	telemetry.UnimplementedOpenConfigTelemetryServer
 }
@@ -71,6 +91,13 @@ func (*openConfigTelemetryServer) TelemetrySubscribe(req *telemetry.Subscription
 		return stream.Send(dataWithStringValues)
 	case "/sensor_with_timestamp":
 		return stream.Send(dataWithTimestamp)
+	case "/sensor_on_change":
+		for _, d := range dataOnChange {
+			if err := stream.Send(d); err != nil {
+				return err
+			}
+		}
+		<-stream.Context().Done()
 	}
 	return nil
 }
@@ -244,6 +271,51 @@ func TestOpenConfigTelemetryDataWithStringValues(t *testing.T) {
 	acc.AssertContainsTaggedFields(t, "/sensor_with_string_values", fields, tags)
 }
 
+func TestOpenConfigTelemetryStateSnapshot(t *testing.T) {
+	plugin := &OpenConfigTelemetry{
+		Log:             testutil.Logger{},
+		Servers:         cfg.Servers,
+		SampleFrequency: config.Duration(time.Millisecond * 10),
+		Sensors:         []string{"/sensor_on_change"},
+		CacheConfig:     statecache.CacheConfig{Snapshot: true},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"/sensor_on_change",
+			map[string]string{
+				"device":       "127.0.0.1",
+				"/sensor/@tag": "tagValue",
+				"system_id":    "",
+				"path":         "/sensor_on_change",
+			},
+			map[string]interface{}{
+				"/sensor/intKey":   int64(20),
+				"/sensor/strKey":   "up",
+				"_sequence":        uint64(1),
+				"_timestamp":       uint64(0),
+				"_component_id":    uint32(0),
+				"_subcomponent_id": uint32(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	// Wait for the update to be merged into the device state
+	require.Eventually(t, func() bool {
+		acc.ClearMetrics()
+		require.NoError(t, plugin.Gather(&acc))
+		metrics := acc.GetTelegrafMetrics()
+		return len(metrics) == 1 && metrics[0].HasField("/sensor/strKey") && metrics[0].Fields()["_sequence"] == uint64(1)
+	}, 5*time.Second, 10*time.Millisecond)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
 func TestMain(m *testing.M) {
 	lis, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,6 +20,7 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/kafka"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 //go:embed sample.conf
@@ -48,6 +50,7 @@ type KafkaConsumer struct {
 	MsgHeadersAsTags                     []string        `toml:"msg_headers_as_tags"`
 	MsgHeaderAsMetricName                string          `toml:"msg_header_as_metric_name"`
 	TimestampSource                      string          `toml:"timestamp_source"`
+	CommitStrategy                       string          `toml:"commit_strategy"`
 	ConsumerFetchDefault                 config.Size     `toml:"consumer_fetch_default"`
 	ConnectionStrategy                   string          `toml:"connection_strategy" deprecated:"1.33.0;1.40.0;use 'startup_error_behavior' instead"`
 	ResolveCanonicalBootstrapServersOnly bool            `toml:"resolve_canonical_bootstrap_servers_only"`
@@ -67,6 +70,15 @@ type KafkaConsumer struct {
 	topicLock sync.Mutex
 	wg        sync.WaitGroup
 	cancel    context.CancelFunc
+
+	stats *consumerStats
+}
+
+// consumerStats are the internal statistics of the consumer group
+type consumerStats struct {
+	group              string
+	rebalances         selfstat.Stat
+	assignedPartitions selfstat.Stat
 }
 
 // consumerGroupHandler is a sarama.ConsumerGroupHandler implementation.
@@ -76,6 +88,12 @@ type consumerGroupHandler struct {
 	msgHeadersToTags      map[string]bool
 	msgHeaderToMetricName string
 	timestampSource       string
+	commitStrategy        string
+	stats                 *consumerStats
+
+	// restart ends the current session to rejoin the group and re-consume
+	// all messages starting from the last committed offset
+	restart context.CancelFunc
 
 	acc    telegraf.TrackingAccumulator
 	sem    semaphore
@@ -85,6 +103,7 @@ type consumerGroupHandler struct {
 
 	mu          sync.Mutex
 	undelivered map[telegraf.TrackingID]message
+	pending     map[partitionKey][]*pendingOffset
 
 	log telegraf.Logger
 }
@@ -93,6 +112,19 @@ type consumerGroupHandler struct {
 type message struct {
 	message *sarama.ConsumerMessage
 	session sarama.ConsumerGroupSession
+	pending *pendingOffset
+}
+
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+// pendingOffset is the offset of a message not yet committed when using the
+// "on_delivery" commit strategy
+type pendingOffset struct {
+	offset int64
+	done   bool
 }
 
 type (
@@ -141,6 +173,14 @@ func (k *KafkaConsumer) Init() error {
 		return fmt.Errorf("invalid timestamp source %q", k.TimestampSource)
 	}
 
+	switch k.CommitStrategy {
+	case "":
+		k.CommitStrategy = "auto"
+	case "auto", "on_delivery":
+	default:
+		return fmt.Errorf("invalid commit strategy %q", k.CommitStrategy)
+	}
+
 	cfg := sarama.NewConfig()
 
 	// Kafka version 0.10.2.0 is required for consumer groups.
@@ -198,6 +238,13 @@ func (k *KafkaConsumer) Init() error {
 
 	k.config = cfg
 
+	tags := map[string]string{"consumer_group": k.ConsumerGroup}
+	k.stats = &consumerStats{
+		group:              k.ConsumerGroup,
+		rebalances:         selfstat.Register("kafka_consumer", "rebalances", tags),
+		assignedPartitions: selfstat.Register("kafka_consumer", "assigned_partitions", tags),
+	}
+
 	if len(k.TopicRegexps) == 0 {
 		k.allWantedTopics = k.Topics
 	} else {
@@ -276,6 +323,8 @@ func (k *KafkaConsumer) Start(acc telegraf.Accumulator) error {
 			}
 			handler.msgHeadersToTags = msgHeadersMap
 			handler.timestampSource = k.TimestampSource
+			handler.commitStrategy = k.CommitStrategy
+			handler.stats = k.stats
 
 			// We need to copy allWantedTopics; the Consume() is
 			// long-running and we can easily deadlock if our
@@ -284,7 +333,13 @@ func (k *KafkaConsumer) Start(acc telegraf.Accumulator) error {
 			k.topicLock.Lock()
 			copy(topics, k.allWantedTopics)
 			k.topicLock.Unlock()
-			err := k.consumer.Consume(ctx, topics, handler)
+
+			// Use a separate context for the session so the handler can
+			// end the session without stopping the plugin
+			sessionCtx, restart := context.WithCancel(ctx)
+			handler.restart = restart
+			err := k.consumer.Consume(sessionCtx, topics, handler)
+			restart()
 			if err != nil {
 				acc.AddError(fmt.Errorf("consume: %w", err))
 				internal.SleepContext(ctx, reconnectDelay) //nolint:errcheck // ignore returned error as we cannot do anything about it anyway
@@ -426,8 +481,18 @@ func newConsumerGroupHandler(acc telegraf.Accumulator, maxUndelivered int, parse
 }
 
 // Setup is called once when a new session is opened. It setups up the handler and begins processing delivered messages.
-func (h *consumerGroupHandler) Setup(sarama.ConsumerGroupSession) error {
+func (h *consumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
 	h.undelivered = make(map[telegraf.TrackingID]message)
+	h.pending = make(map[partitionKey][]*pendingOffset)
+
+	if h.stats != nil {
+		var assigned int
+		for _, partitions := range session.Claims() {
+			assigned += len(partitions)
+		}
+		h.stats.rebalances.Incr(1)
+		h.stats.assignedPartitions.Set(int64(assigned))
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	h.cancel = cancel
@@ -444,6 +509,21 @@ func (h *consumerGroupHandler) Setup(sarama.ConsumerGroupSession) error {
 func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	ctx := session.Context()
 
+	// Statistics of the partition are only valid as long as the partition
+	// is assigned to this consumer
+	var lag, offset selfstat.Stat
+	if h.stats != nil {
+		tags := map[string]string{
+			"consumer_group": h.stats.group,
+			"topic":          claim.Topic(),
+			"partition":      strconv.FormatInt(int64(claim.Partition()), 10),
+		}
+		lag = selfstat.Register("kafka_consumer", "lag", tags)
+		offset = selfstat.Register("kafka_consumer", "offset", tags)
+		defer selfstat.Unregister("kafka_consumer", "lag", tags)
+		defer selfstat.Unregister("kafka_consumer", "offset", tags)
+	}
+
 	for {
 		err := h.reserve(ctx)
 		if err != nil {
@@ -457,6 +537,10 @@ func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 			if !ok {
 				return nil
 			}
+			if h.stats != nil {
+				lag.Set(max(claim.HighWaterMarkOffset()-msg.Offset-1, 0))
+				offset.Set(msg.Offset)
+			}
 			err := h.handle(session, msg)
 			if err != nil {
 				h.acc.AddError(err)
@@ -494,14 +578,71 @@ func (h *consumerGroupHandler) onDelivery(track telegraf.DeliveryInfo) {
 		return
 	}
 
-	if track.Delivered() {
-		msg.session.MarkMessage(msg.message, "")
+	switch {
+	case h.commitStrategy != "on_delivery":
+		if track.Delivered() {
+			msg.session.MarkMessage(msg.message, "")
+		}
+	case track.Delivered():
+		msg.pending.done = true
+		h.commit(msg.session, msg.message.Topic, msg.message.Partition)
+	default:
+		// Do not commit any offset beyond the undelivered message but
+		// re-consume it to not lose any data
+		h.log.Warnf("Message at offset %d of partition %d of topic %q was not delivered, restarting session",
+			msg.message.Offset, msg.message.Partition, msg.message.Topic)
+		if h.restart != nil {
+			h.restart()
+		}
 	}
 
 	delete(h.undelivered, track.ID())
 	<-h.sem
 }
 
+// commit marks the offset of the partition up to the first message that was
+// not yet processed, must be called with the lock held
+func (h *consumerGroupHandler) commit(session sarama.ConsumerGroupSession, topic string, partition int32) {
+	key := partitionKey{topic: topic, partition: partition}
+	queue := h.pending[key]
+
+	var n int
+	for n < len(queue) && queue[n].done {
+		n++
+	}
+	if n == 0 {
+		return
+	}
+
+	session.MarkOffset(topic, partition, queue[n-1].offset+1, "")
+	h.pending[key] = queue[n:]
+}
+
+// track adds the message to the pending offsets of the partition when using
+// the "on_delivery" commit strategy, must be called with the lock held
+func (h *consumerGroupHandler) track(msg *sarama.ConsumerMessage) *pendingOffset {
+	if h.commitStrategy != "on_delivery" {
+		return nil
+	}
+	key := partitionKey{topic: msg.Topic, partition: msg.Partition}
+	p := &pendingOffset{offset: msg.Offset}
+	h.pending[key] = append(h.pending[key], p)
+	return p
+}
+
+// skip marks a message not producing any metrics as processed
+func (h *consumerGroupHandler) skip(session sarama.ConsumerGroupSession, msg *sarama.ConsumerMessage) {
+	if h.commitStrategy != "on_delivery" {
+		session.MarkMessage(msg, "")
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.track(msg).done = true
+	h.commit(session, msg.Topic, msg.Partition)
+}
+
 // reserve blocks until there is an available slot for a new message.
 func (h *consumerGroupHandler) reserve(ctx context.Context) error {
 	select {
@@ -519,7 +660,7 @@ func (h *consumerGroupHandler) release() {
 // handle processes a message and if successful saves it to be acknowledged after delivery.
 func (h *consumerGroupHandler) handle(session sarama.ConsumerGroupSession, msg *sarama.ConsumerMessage) error {
 	if h.maxMessageLen != 0 && len(msg.Value) > h.maxMessageLen {
-		session.MarkMessage(msg, "")
+		h.skip(session, msg)
 		h.release()
 		return fmt.Errorf("message exceeds max_message_len (actual %d, max %d)",
 			len(msg.Value), h.maxMessageLen)
@@ -527,7 +668,7 @@ func (h *consumerGroupHandler) handle(session sarama.ConsumerGroupSession, msg *
 
 	metrics, err := h.parser.Parse(msg.Value)
 	if err != nil {
-		session.MarkMessage(msg, "")
+		h.skip(session, msg)
 		h.release()
 		return err
 	}
@@ -579,7 +720,7 @@ func (h *consumerGroupHandler) handle(session sarama.ConsumerGroupSession, msg *
 
 	h.mu.Lock()
 	id := h.acc.AddTrackingMetricGroup(metrics)
-	h.undelivered[id] = message{session: session, message: msg}
+	h.undelivered[id] = message{session: session, message: msg, pending: h.track(msg)}
 	h.mu.Unlock()
 	return nil
 }
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -25,6 +28,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/value"
 	serializers_influx "github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -193,6 +197,21 @@ func TestInit(t *testing.T) {
 				require.True(t, plugin.config.Net.TLS.Enable)
 			},
 		},
+		{
+			name:   "default commit strategy",
+			plugin: &KafkaConsumer{Log: testutil.Logger{}},
+			check: func(t *testing.T, plugin *KafkaConsumer) {
+				require.Equal(t, "auto", plugin.CommitStrategy)
+			},
+		},
+		{
+			name: "invalid commit strategy",
+			plugin: &KafkaConsumer{
+				CommitStrategy: "foo",
+				Log:            testutil.Logger{},
+			},
+			initError: true,
+		},
 		{
 			name: "custom max_processing_time",
 			plugin: &KafkaConsumer{
@@ -237,11 +256,15 @@ func TestStartStop(t *testing.T) {
 }
 
 type FakeConsumerGroupSession struct {
-	ctx context.Context
+	ctx    context.Context
+	claims map[string][]int32
+
+	marked []int64
+	sync.Mutex
 }
 
-func (*FakeConsumerGroupSession) Claims() map[string][]int32 {
-	panic("not implemented")
+func (s *FakeConsumerGroupSession) Claims() map[string][]int32 {
+	return s.claims
 }
 
 func (*FakeConsumerGroupSession) MemberID() string {
@@ -252,8 +275,16 @@ func (*FakeConsumerGroupSession) GenerationID() int32 {
 	panic("not implemented")
 }
 
-func (*FakeConsumerGroupSession) MarkOffset(string, int32, int64, string) {
-	panic("not implemented")
+func (s *FakeConsumerGroupSession) MarkOffset(_ string, _ int32, offset int64, _ string) {
+	s.Lock()
+	defer s.Unlock()
+	s.marked = append(s.marked, offset)
+}
+
+func (s *FakeConsumerGroupSession) markedOffsets() []int64 {
+	s.Lock()
+	defer s.Unlock()
+	return append([]int64(nil), s.marked...)
 }
 
 func (*FakeConsumerGroupSession) ResetOffset(string, int32, int64, string) {
@@ -271,23 +302,26 @@ func (*FakeConsumerGroupSession) Commit() {
 }
 
 type FakeConsumerGroupClaim struct {
-	messages chan *sarama.ConsumerMessage
+	messages  chan *sarama.ConsumerMessage
+	topic     string
+	partition int32
+	highWater int64
 }
 
-func (*FakeConsumerGroupClaim) Topic() string {
-	panic("not implemented")
+func (c *FakeConsumerGroupClaim) Topic() string {
+	return c.topic
 }
 
-func (*FakeConsumerGroupClaim) Partition() int32 {
-	panic("not implemented")
+func (c *FakeConsumerGroupClaim) Partition() int32 {
+	return c.partition
 }
 
 func (*FakeConsumerGroupClaim) InitialOffset() int64 {
 	panic("not implemented")
 }
 
-func (*FakeConsumerGroupClaim) HighWaterMarkOffset() int64 {
-	panic("not implemented")
+func (c *FakeConsumerGroupClaim) HighWaterMarkOffset() int64 {
+	return c.highWater
 }
 
 func (c *FakeConsumerGroupClaim) Messages() <-chan *sarama.ConsumerMessage {
@@ -477,6 +511,140 @@ func TestConsumerGroupHandlerHandle(t *testing.T) {
 	}
 }
 
+func TestConsumerGroupHandlerCommitOnDelivery(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	parser := value.Parser{
+		MetricName: "cpu",
+		DataType:   "int",
+	}
+	require.NoError(t, parser.Init())
+	cg := newConsumerGroupHandler(acc, 5, &parser, testutil.Logger{})
+	cg.commitStrategy = "on_delivery"
+	var restarted atomic.Bool
+	cg.restart = func() { restarted.Store(true) }
+
+	session := &FakeConsumerGroupSession{ctx: t.Context()}
+	require.NoError(t, cg.Setup(session))
+	defer cg.Cleanup(session) //nolint:errcheck // ignore error in test cleanup
+
+	for i, v := range []string{"0", "1", "invalid", "3", "4"} {
+		require.NoError(t, cg.reserve(t.Context()))
+		msg := &sarama.ConsumerMessage{
+			Topic:  "telegraf",
+			Offset: int64(i),
+			Value:  []byte(v),
+		}
+		// Unparsable messages are skipped and counted as processed
+		err := cg.handle(session, msg)
+		if v == "invalid" {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 4)
+
+	// Delivering out of order must not commit before the oldest message
+	metrics[1].Accept()
+	require.Never(t, func() bool {
+		return len(session.markedOffsets()) > 0
+	}, 100*time.Millisecond, 10*time.Millisecond)
+
+	// Offsets up to the next pending message are committed at once
+	metrics[0].Accept()
+	require.Eventually(t, func() bool {
+		return reflect.DeepEqual(session.markedOffsets(), []int64{3})
+	}, time.Second, 10*time.Millisecond)
+
+	// Undelivered messages stop committing and restart the session
+	metrics[2].Reject()
+	metrics[3].Accept()
+	require.Eventually(t, restarted.Load, time.Second, 10*time.Millisecond)
+	require.Never(t, func() bool {
+		return len(session.markedOffsets()) > 1
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestConsumerGroupHandlerStatistics(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	parser := value.Parser{
+		MetricName: "cpu",
+		DataType:   "int",
+	}
+	require.NoError(t, parser.Init())
+
+	plugin := &KafkaConsumer{
+		ConsumerGroup: "stats_group",
+		Log:           testutil.Logger{},
+	}
+	plugin.consumerCreator = &fakeCreator{consumerGroup: &fakeConsumerGroup{}}
+	require.NoError(t, plugin.Init())
+
+	cg := newConsumerGroupHandler(acc, 1, &parser, testutil.Logger{})
+	cg.stats = plugin.stats
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	session := &FakeConsumerGroupSession{
+		ctx:    ctx,
+		claims: map[string][]int32{"telegraf": {0, 3}},
+	}
+	claim := &FakeConsumerGroupClaim{
+		messages:  make(chan *sarama.ConsumerMessage, 1),
+		topic:     "telegraf",
+		partition: 3,
+		highWater: 50,
+	}
+	// The registry is global, so only check the increment of the counter
+	rebalances := plugin.stats.rebalances.Get()
+	require.NoError(t, cg.Setup(session))
+	require.Equal(t, rebalances+1, plugin.stats.rebalances.Get())
+
+	claim.messages <- &sarama.ConsumerMessage{
+		Topic:     "telegraf",
+		Partition: 3,
+		Offset:    41,
+		Value:     []byte("42"),
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cg.ConsumeClaim(session, claim) //nolint:errcheck // context is canceled below
+	}()
+	acc.Wait(1)
+
+	fields := make(map[string]interface{})
+	for _, m := range selfstat.Metrics() {
+		if m.Name() != "internal_kafka_consumer" {
+			continue
+		}
+		if group, _ := m.GetTag("consumer_group"); group != "stats_group" {
+			continue
+		}
+		for k, v := range m.Fields() {
+			fields[k] = v
+		}
+	}
+	require.Equal(t, map[string]interface{}{
+		"rebalances":          rebalances + 1,
+		"assigned_partitions": int64(2),
+		"lag":                 int64(8),
+		"offset":              int64(41),
+	}, fields)
+
+	// Partition statistics are removed once the claim ends
+	cancel()
+	<-done
+	require.NoError(t, cg.Cleanup(session))
+	for _, m := range selfstat.Metrics() {
+		if _, found := m.GetTag("partition"); found && m.Name() == "internal_kafka_consumer" {
+			require.Failf(t, "unexpected partition statistics", "%v", m)
+		}
+	}
+}
+
 func TestExponentialBackoff(t *testing.T) {
 	var err error
 
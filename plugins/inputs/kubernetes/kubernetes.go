@@ -33,18 +33,21 @@ const (
 
 // Kubernetes represents the config object for the plugin
 type Kubernetes struct {
-	URL             string          `toml:"url"`
-	BearerToken     string          `toml:"bearer_token"`
-	NodeMetricName  string          `toml:"node_metric_name"`
-	LabelInclude    []string        `toml:"label_include"`
-	LabelExclude    []string        `toml:"label_exclude"`
-	ResponseTimeout config.Duration `toml:"response_timeout"`
-	Log             telegraf.Logger `toml:"-"`
+	URL              string          `toml:"url"`
+	BearerToken      string          `toml:"bearer_token"`
+	NodeMetricName   string          `toml:"node_metric_name"`
+	LabelInclude     []string        `toml:"label_include"`
+	LabelExclude     []string        `toml:"label_exclude"`
+	NamespaceInclude []string        `toml:"namespace_include"`
+	NamespaceExclude []string        `toml:"namespace_exclude"`
+	ResponseTimeout  config.Duration `toml:"response_timeout"`
+	Log              telegraf.Logger `toml:"-"`
 
 	tls.ClientConfig
 
-	labelFilter filter.Filter
-	httpClient  *http.Client
+	labelFilter     filter.Filter
+	namespaceFilter filter.Filter
+	httpClient      *http.Client
 }
 
 func (*Kubernetes) SampleConfig() string {
@@ -63,6 +66,12 @@ func (k *Kubernetes) Init() error {
 	}
 	k.labelFilter = labelFilter
 
+	namespaceFilter, err := filter.NewIncludeExcludeFilter(k.NamespaceInclude, k.NamespaceExclude)
+	if err != nil {
+		return err
+	}
+	k.namespaceFilter = namespaceFilter
+
 	if k.URL == "" {
 		k.InsecureSkipVerify = true
 	}
@@ -157,7 +166,7 @@ func (k *Kubernetes) gatherSummary(baseURL string, acc telegraf.Accumulator) err
 	}
 	buildSystemContainerMetrics(summaryMetrics, acc)
 	buildNodeMetrics(summaryMetrics, acc, k.NodeMetricName)
-	buildPodMetrics(summaryMetrics, podInfos, k.labelFilter, acc)
+	buildPodMetrics(summaryMetrics, podInfos, k.labelFilter, k.namespaceFilter, acc)
 	return nil
 }
 
@@ -274,8 +283,15 @@ func (k *Kubernetes) loadJSON(url string, v interface{}) error {
 	return nil
 }
 
-func buildPodMetrics(summaryMetrics *summaryMetrics, podInfo []item, labelFilter filter.Filter, acc telegraf.Accumulator) {
+func buildPodMetrics(summaryMetrics *summaryMetrics, podInfo []item, labelFilter, namespaceFilter filter.Filter, acc telegraf.Accumulator) {
+	// Claims might be mounted by multiple pods so only report them once
+	claims := make(map[pvcReference]bool)
+
 	for _, pod := range summaryMetrics.Pods {
+		if namespaceFilter != nil && !namespaceFilter.Match(pod.PodRef.Namespace) {
+			continue
+		}
+
 		podLabels := make(map[string]string)
 		containerImages := make(map[string]string)
 		for _, info := range podInfo {
@@ -342,6 +358,45 @@ func buildPodMetrics(summaryMetrics *summaryMetrics, podInfo []item, labelFilter
 			fields["capacity_bytes"] = volume.CapacityBytes
 			fields["used_bytes"] = volume.UsedBytes
 			acc.AddFields("kubernetes_pod_volume", fields, tags)
+
+			if volume.PVCRef == nil || claims[*volume.PVCRef] {
+				continue
+			}
+			claims[*volume.PVCRef] = true
+			pvcTags := map[string]string{
+				"node_name": summaryMetrics.Node.NodeName,
+				"namespace": volume.PVCRef.Namespace,
+				"pvc_name":  volume.PVCRef.Name,
+			}
+			pvcFields := map[string]interface{}{
+				"available_bytes": volume.AvailableBytes,
+				"capacity_bytes":  volume.CapacityBytes,
+				"used_bytes":      volume.UsedBytes,
+				"inodes":          volume.Inodes,
+				"inodes_free":     volume.InodesFree,
+				"inodes_used":     volume.InodesUsed,
+			}
+			acc.AddFields("kubernetes_persistent_volume_claim", pvcFields, pvcTags)
+		}
+
+		if pod.EphemeralStorage != nil {
+			tags := map[string]string{
+				"node_name": summaryMetrics.Node.NodeName,
+				"pod_name":  pod.PodRef.Name,
+				"namespace": pod.PodRef.Namespace,
+			}
+			for k, v := range podLabels {
+				tags[k] = v
+			}
+			fields := map[string]interface{}{
+				"available_bytes": pod.EphemeralStorage.AvailableBytes,
+				"capacity_bytes":  pod.EphemeralStorage.CapacityBytes,
+				"used_bytes":      pod.EphemeralStorage.UsedBytes,
+				"inodes":          pod.EphemeralStorage.Inodes,
+				"inodes_free":     pod.EphemeralStorage.InodesFree,
+				"inodes_used":     pod.EphemeralStorage.InodesUsed,
+			}
+			acc.AddFields("kubernetes_pod_ephemeral_storage", fields, tags)
 		}
 
 		tags := map[string]string{
@@ -49,6 +49,9 @@ type podMetrics struct {
 	Containers []containerMetrics `json:"containers"`
 	Network    networkMetrics     `json:"network"`
 	Volumes    []volumeMetrics    `json:"volume"`
+	// EphemeralStorage is the usage of the pod's ephemeral storage, i.e.
+	// the container root and log filesystems and the local volumes
+	EphemeralStorage *fileSystemMetrics `json:"ephemeral-storage"`
 }
 
 // podReference is how a pod is identified
@@ -73,6 +76,9 @@ type fileSystemMetrics struct {
 	AvailableBytes int64 `json:"availableBytes"`
 	CapacityBytes  int64 `json:"capacityBytes"`
 	UsedBytes      int64 `json:"usedBytes"`
+	InodesFree     int64 `json:"inodesFree"`
+	Inodes         int64 `json:"inodes"`
+	InodesUsed     int64 `json:"inodesUsed"`
 }
 
 // networkMetrics represents network usage data for a pod or node
@@ -86,8 +92,18 @@ type networkMetrics struct {
 
 // volumeMetrics represents the disk usage data for a given volume
 type volumeMetrics struct {
-	Name           string `json:"name"`
-	AvailableBytes int64  `json:"availableBytes"`
-	CapacityBytes  int64  `json:"capacityBytes"`
-	UsedBytes      int64  `json:"usedBytes"`
+	Name           string        `json:"name"`
+	AvailableBytes int64         `json:"availableBytes"`
+	CapacityBytes  int64         `json:"capacityBytes"`
+	UsedBytes      int64         `json:"usedBytes"`
+	InodesFree     int64         `json:"inodesFree"`
+	Inodes         int64         `json:"inodes"`
+	InodesUsed     int64         `json:"inodesUsed"`
+	PVCRef         *pvcReference `json:"pvcRef"`
+}
+
+// pvcReference identifies the persistent volume claim backing a volume
+type pvcReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
 }
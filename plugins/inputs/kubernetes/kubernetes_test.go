@@ -169,6 +169,71 @@ func TestKubernetesStats(t *testing.T) {
 		"superkey":  "foobar",
 	}
 	acc.AssertContainsTaggedFields(t, "kubernetes_pod_network", fields, tags)
+
+	fields = map[string]interface{}{
+		"available_bytes": int64(84379979776),
+		"capacity_bytes":  int64(105553100800),
+		"used_bytes":      int64(94208),
+		"inodes":          int64(6553600),
+		"inodes_free":     int64(6525226),
+		"inodes_used":     int64(28),
+	}
+	acc.AssertContainsTaggedFields(t, "kubernetes_pod_ephemeral_storage", fields, tags)
+
+	fields = map[string]interface{}{
+		"available_bytes": int64(7903948800),
+		"capacity_bytes":  int64(7903961088),
+		"used_bytes":      int64(12288),
+		"inodes":          int64(1048576),
+		"inodes_free":     int64(1048560),
+		"inodes_used":     int64(16),
+	}
+	tags = map[string]string{
+		"node_name": "node1",
+		"namespace": "foons",
+		"pvc_name":  "foopvc",
+	}
+	acc.AssertContainsTaggedFields(t, "kubernetes_persistent_volume_claim", fields, tags)
+}
+
+func TestKubernetesNamespaceFilter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/stats/summary":
+			if _, err := fmt.Fprintln(w, responseStatsSummery); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+			}
+		case "/pods":
+			if _, err := fmt.Fprintln(w, responsePods); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+			}
+		}
+	}))
+	defer ts.Close()
+
+	labelFilter, err := filter.NewIncludeExcludeFilter(nil, []string{"*"})
+	require.NoError(t, err)
+	namespaceFilter, err := filter.NewIncludeExcludeFilter(nil, []string{"foo*"})
+	require.NoError(t, err)
+
+	k := &Kubernetes{
+		URL:             ts.URL,
+		labelFilter:     labelFilter,
+		namespaceFilter: namespaceFilter,
+		NodeMetricName:  "kubernetes_node",
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(k.Gather))
+
+	// Node-level metrics are unaffected by the namespace filter
+	require.True(t, acc.HasMeasurement("kubernetes_node"))
+	require.True(t, acc.HasMeasurement("kubernetes_system_container"))
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.NotEqualf(t, "foons", m.Tags()["namespace"], "unexpected metric %v", m)
+	}
 }
 
 var responsePods = `
@@ -358,7 +423,14 @@ var responseStatsSummery = `
       "availableBytes": 7903948800,
       "capacityBytes": 7903961088,
       "usedBytes": 12288,
-      "name": "volume3"
+      "inodesFree": 1048560,
+      "inodes": 1048576,
+      "inodesUsed": 16,
+      "name": "volume3",
+      "pvcRef": {
+       "name": "foopvc",
+       "namespace": "foons"
+      }
      },
      {
       "availableBytes": 7903952896,
@@ -366,7 +438,16 @@ var responseStatsSummery = `
       "usedBytes": 8192,
       "name": "volume4"
      }
-    ]
+    ],
+    "ephemeral-storage": {
+     "time": "2016-09-27T16:57:34Z",
+     "availableBytes": 84379979776,
+     "capacityBytes": 105553100800,
+     "usedBytes": 94208,
+     "inodesFree": 6525226,
+     "inodes": 6553600,
+     "inodesUsed": 28
+    }
    },
    {
     "podRef": {
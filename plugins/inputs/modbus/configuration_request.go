@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"hash/maphash"
 	"math"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
@@ -33,12 +34,15 @@ type requestDefinition struct {
 	Measurement       string                   `toml:"measurement"`
 	Optimization      string                   `toml:"optimization"`
 	MaxExtraRegisters uint16                   `toml:"optimization_max_register_fill"`
+	Interval          config.Duration          `toml:"interval"`
+	PollPause         config.Duration          `toml:"pause_between_requests"`
 	Fields            []requestFieldDefinition `toml:"fields"`
 	Tags              map[string]string        `toml:"tags"`
 }
 
 type configurationPerRequest struct {
-	Requests []requestDefinition `toml:"request"`
+	CoalesceRequests bool                `toml:"coalesce_requests"`
+	Requests         []requestDefinition `toml:"request"`
 
 	workarounds         workarounds
 	excludeRegisterType bool
@@ -114,6 +118,14 @@ func (c *configurationPerRequest) check() error {
 		default:
 			return fmt.Errorf("unknown optimization %q", def.Optimization)
 		}
+		// Check the scheduling settings
+		if def.Interval < 0 {
+			return fmt.Errorf("negative interval for request of slave %d", def.SlaveID)
+		}
+		if def.PollPause < 0 {
+			return fmt.Errorf("negative pause between requests for slave %d", def.SlaveID)
+		}
+
 		// Set the default for measurement if required
 		if def.Measurement == "" {
 			def.Measurement = "modbus"
@@ -234,6 +246,9 @@ func (c *configurationPerRequest) process() (map[byte]requestSet, error) {
 			set = requestSet{}
 		}
 
+		// Use the largest pause of all requests for the slave
+		set.pause = max(set.pause, time.Duration(def.PollPause))
+
 		params := groupingParams{
 			maxExtraRegisters: def.MaxExtraRegisters,
 			optimization:      def.Optimization,
@@ -248,6 +263,7 @@ func (c *configurationPerRequest) process() (map[byte]requestSet, error) {
 			}
 			params.enforceFromZero = c.workarounds.ReadCoilsStartingAtZero
 			requests := groupFieldsToRequests(fields, params)
+			setRequestInterval(requests, time.Duration(def.Interval))
 			set.coil = append(set.coil, requests...)
 		case "discrete":
 			params.maxBatchSize = maxQuantityDiscreteInput
@@ -255,6 +271,7 @@ func (c *configurationPerRequest) process() (map[byte]requestSet, error) {
 				params.maxBatchSize = 1
 			}
 			requests := groupFieldsToRequests(fields, params)
+			setRequestInterval(requests, time.Duration(def.Interval))
 			set.discrete = append(set.discrete, requests...)
 		case "holding":
 			params.maxBatchSize = maxQuantityHoldingRegisters
@@ -262,6 +279,7 @@ func (c *configurationPerRequest) process() (map[byte]requestSet, error) {
 				params.maxBatchSize = 1
 			}
 			requests := groupFieldsToRequests(fields, params)
+			setRequestInterval(requests, time.Duration(def.Interval))
 			set.holding = append(set.holding, requests...)
 		case "input":
 			params.maxBatchSize = maxQuantityInputRegisters
@@ -269,6 +287,7 @@ func (c *configurationPerRequest) process() (map[byte]requestSet, error) {
 				params.maxBatchSize = 1
 			}
 			requests := groupFieldsToRequests(fields, params)
+			setRequestInterval(requests, time.Duration(def.Interval))
 			set.input = append(set.input, requests...)
 		default:
 			return nil, fmt.Errorf("unknown register type %q", def.RegisterType)
@@ -278,9 +297,26 @@ func (c *configurationPerRequest) process() (map[byte]requestSet, error) {
 		}
 	}
 
+	// Merge adjacent requests of different request definitions
+	if c.CoalesceRequests && !c.workarounds.OnRequestPerField {
+		for slaveID, set := range result {
+			set.coil = coalesceRequests(set.coil, maxQuantityCoils)
+			set.discrete = coalesceRequests(set.discrete, maxQuantityDiscreteInput)
+			set.holding = coalesceRequests(set.holding, maxQuantityHoldingRegisters)
+			set.input = coalesceRequests(set.input, maxQuantityInputRegisters)
+			result[slaveID] = set
+		}
+	}
+
 	return result, nil
 }
 
+func setRequestInterval(requests []request, interval time.Duration) {
+	for i := range requests {
+		requests[i].interval = interval
+	}
+}
+
 func (c *configurationPerRequest) initFields(fieldDefs []requestFieldDefinition, typed bool, byteOrder string) ([]field, error) {
 	// Construct the fields from the field definitions
 	fields := make([]field, 0, len(fieldDefs))
@@ -3,6 +3,7 @@ package modbus
 import (
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -11,6 +12,8 @@ import (
 	"github.com/tbrandon/mbserver"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -3337,3 +3340,246 @@ func TestRequestAddressOverflow(t *testing.T) {
 	}
 	require.ErrorIs(t, plugin.Init(), errAddressOverflow)
 }
+
+func TestRequestCoalesce(t *testing.T) {
+	plugin := Modbus{
+		Name:              "Test",
+		Controller:        "tcp://localhost:1502",
+		ConfigurationType: "request",
+		Log:               testutil.Logger{},
+	}
+	plugin.CoalesceRequests = true
+	plugin.Requests = []requestDefinition{
+		{
+			SlaveID:      1,
+			RegisterType: "holding",
+			Measurement:  "voltage",
+			Fields: []requestFieldDefinition{
+				{Name: "L1", InputType: "UINT16", Address: uint16(0)},
+				{Name: "L2", InputType: "UINT16", Address: uint16(1)},
+			},
+		},
+		{
+			SlaveID:      1,
+			RegisterType: "holding",
+			Measurement:  "current",
+			Fields: []requestFieldDefinition{
+				{Name: "L1", InputType: "UINT32", Address: uint16(2)},
+				{Name: "L2", InputType: "UINT32", Address: uint16(4)},
+			},
+		},
+		{
+			SlaveID:      1,
+			RegisterType: "holding",
+			Measurement:  "energy",
+			Interval:     config.Duration(time.Minute),
+			Fields: []requestFieldDefinition{
+				{Name: "total", InputType: "UINT64", Address: uint16(6)},
+			},
+		},
+		{
+			SlaveID:      1,
+			RegisterType: "holding",
+			Measurement:  "status",
+			Fields: []requestFieldDefinition{
+				{Name: "state", InputType: "UINT16", Address: uint16(20)},
+			},
+		},
+		{
+			SlaveID:      2,
+			RegisterType: "holding",
+			Measurement:  "voltage",
+			Fields: []requestFieldDefinition{
+				{Name: "L1", InputType: "UINT16", Address: uint16(6)},
+			},
+		},
+	}
+	require.NoError(t, plugin.Init())
+	require.Len(t, plugin.requests, 2)
+
+	// The first two definitions are adjacent and get merged, the energy
+	// request is adjacent but uses a different interval and the status
+	// request is not adjacent
+	holding := plugin.requests[1].holding
+	require.Len(t, holding, 3)
+	require.Equal(t, uint16(0), holding[0].address)
+	require.Equal(t, uint16(6), holding[0].length)
+	require.Len(t, holding[0].fields, 4)
+	require.Equal(t, uint16(20), holding[1].address)
+	require.Equal(t, uint16(1), holding[1].length)
+	require.Equal(t, uint16(6), holding[2].address)
+	require.Equal(t, uint16(4), holding[2].length)
+	require.Equal(t, time.Minute, holding[2].interval)
+
+	// Requests of different slaves are never merged
+	require.Len(t, plugin.requests[2].holding, 1)
+}
+
+func TestRequestCoalesceMaxBatchSize(t *testing.T) {
+	plugin := Modbus{
+		Name:              "Test",
+		Controller:        "tcp://localhost:1502",
+		ConfigurationType: "request",
+		Log:               testutil.Logger{},
+	}
+	plugin.CoalesceRequests = true
+	plugin.Requests = []requestDefinition{
+		{
+			SlaveID:      1,
+			RegisterType: "input",
+			Fields: []requestFieldDefinition{
+				{Name: "first", InputType: "STRING", Length: 100, Address: uint16(0)},
+			},
+		},
+		{
+			SlaveID:      1,
+			RegisterType: "input",
+			Fields: []requestFieldDefinition{
+				{Name: "second", InputType: "STRING", Length: 100, Address: uint16(100)},
+			},
+		},
+	}
+	require.NoError(t, plugin.Init())
+	require.Len(t, plugin.requests[1].input, 2)
+}
+
+func TestRequestPauseBetweenRequests(t *testing.T) {
+	plugin := Modbus{
+		Name:              "Test",
+		Controller:        "tcp://localhost:1502",
+		ConfigurationType: "request",
+		Log:               testutil.Logger{},
+	}
+	plugin.Requests = []requestDefinition{
+		{
+			SlaveID:   1,
+			PollPause: config.Duration(10 * time.Millisecond),
+			Fields: []requestFieldDefinition{
+				{Name: "field-0", InputType: "UINT16", Address: uint16(0)},
+			},
+		},
+		{
+			SlaveID:   1,
+			PollPause: config.Duration(50 * time.Millisecond),
+			Fields: []requestFieldDefinition{
+				{Name: "field-10", InputType: "UINT16", Address: uint16(10)},
+			},
+		},
+		{
+			SlaveID: 2,
+			Fields: []requestFieldDefinition{
+				{Name: "field-0", InputType: "UINT16", Address: uint16(0)},
+			},
+		},
+	}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, 50*time.Millisecond, plugin.requests[1].pause)
+	require.Zero(t, plugin.requests[2].pause)
+
+	plugin.Requests[0].PollPause = config.Duration(-time.Second)
+	require.ErrorContains(t, plugin.Init(), "negative pause between requests for slave 1")
+}
+
+func TestRequestInterval(t *testing.T) {
+	plugin := Modbus{
+		Name:              "Test",
+		Controller:        "tcp://localhost:1502",
+		ConfigurationType: "request",
+		Log:               testutil.Logger{},
+	}
+	plugin.Requests = []requestDefinition{
+		{
+			SlaveID:      1,
+			RegisterType: "holding",
+			Fields: []requestFieldDefinition{
+				{Name: "power", InputType: "UINT16", Address: uint16(0)},
+			},
+		},
+		{
+			SlaveID:      1,
+			RegisterType: "holding",
+			Interval:     config.Duration(time.Hour),
+			Fields: []requestFieldDefinition{
+				{Name: "serial", InputType: "UINT16", Address: uint16(10)},
+			},
+		},
+		{
+			SlaveID:      2,
+			RegisterType: "holding",
+			Interval:     config.Duration(time.Hour),
+			Fields: []requestFieldDefinition{
+				{Name: "serial", InputType: "UINT16", Address: uint16(10)},
+			},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	serv := mbserver.NewServer()
+	require.NoError(t, serv.ListenTCP("localhost:1502"))
+	defer serv.Close()
+
+	var requests atomic.Int32
+	serv.RegisterFunctionHandler(3,
+		func(_ *mbserver.Server, frame mbserver.Framer) ([]byte, *mbserver.Exception) {
+			requests.Add(1)
+			data := frame.GetData()
+			if data[1] == 10 {
+				return []byte{0x02, 0x00, 0x2a}, &mbserver.Success
+			}
+			return []byte{0x02, 0x01, 0x00}, &mbserver.Success
+		},
+	)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"modbus",
+			map[string]string{
+				"type":     cHoldingRegisters,
+				"slave_id": "1",
+				"name":     plugin.Name,
+			},
+			map[string]interface{}{"power": uint64(256), "serial": uint64(42)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"modbus",
+			map[string]string{
+				"type":     cHoldingRegisters,
+				"slave_id": "2",
+				"name":     plugin.Name,
+			},
+			map[string]interface{}{"serial": uint64(42)},
+			time.Unix(0, 0),
+		),
+	}
+
+	// The first gather cycle reads all requests
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+	require.Equal(t, int32(3), requests.Load())
+
+	// Subsequent cycles within the interval only read the request without
+	// interval and skip slave 2 completely
+	expected = []telegraf.Metric{
+		metric.New(
+			"modbus",
+			map[string]string{
+				"type":     cHoldingRegisters,
+				"slave_id": "1",
+				"name":     plugin.Name,
+			},
+			map[string]interface{}{"power": uint64(256)},
+			time.Unix(0, 0),
+		),
+	}
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	require.Equal(t, int32(4), requests.Load())
+
+	plugin.Requests[1].Interval = config.Duration(-time.Second)
+	require.ErrorContains(t, plugin.Init(), "negative interval for request of slave 1")
+}
@@ -90,6 +90,9 @@ type requestSet struct {
 	discrete []request
 	holding  []request
 	input    []request
+
+	// Pause between requests overriding the workaround setting if non-zero
+	pause time.Duration
 }
 
 func (r requestSet) empty() bool {
@@ -100,6 +103,25 @@ func (r requestSet) empty() bool {
 	return l == 0
 }
 
+// due returns the requests of the set to be read at the given time
+func (r requestSet) due(now time.Time) requestSet {
+	return requestSet{
+		coil:     dueRequests(r.coil, now),
+		discrete: dueRequests(r.discrete, now),
+		holding:  dueRequests(r.holding, now),
+		input:    dueRequests(r.input, now),
+		pause:    r.pause,
+	}
+}
+
+// markRead records the successful read of the requests due at the given time
+func (r requestSet) markRead(now time.Time) {
+	markRequestsRead(r.coil, now)
+	markRequestsRead(r.discrete, now)
+	markRequestsRead(r.holding, now)
+	markRequestsRead(r.input, now)
+}
+
 type field struct {
 	measurement string
 	name        string
@@ -224,7 +246,14 @@ func (m *Modbus) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
-	for slaveID, requests := range m.requests {
+	now := time.Now()
+	for slaveID, set := range m.requests {
+		// Only read the requests with their polling interval elapsed
+		requests := set.due(now)
+		if requests.empty() {
+			continue
+		}
+
 		m.Log.Debugf("Reading slave %d for %s...", slaveID, m.Controller)
 		if err := m.readSlaveData(slaveID, requests); err != nil {
 			acc.AddError(fmt.Errorf("slave %d on controller %q: %w", slaveID, m.Controller, err))
@@ -240,6 +269,7 @@ func (m *Modbus) Gather(acc telegraf.Accumulator) error {
 			}
 			continue
 		}
+		set.markRead(now)
 		timestamp := time.Now()
 
 		grouper := metric.NewSeriesGrouper()
@@ -412,26 +442,31 @@ func (m *Modbus) readSlaveData(slaveID byte, requests requestSet) error {
 }
 
 func (m *Modbus) gatherFields(requests requestSet) error {
-	if err := m.gatherRequestsCoil(requests.coil); err != nil {
+	pause := time.Duration(m.Workarounds.PollPause)
+	if requests.pause > 0 {
+		pause = requests.pause
+	}
+
+	if err := m.gatherRequestsCoil(requests.coil, pause); err != nil {
 		return err
 	}
-	if err := m.gatherRequestsDiscrete(requests.discrete); err != nil {
+	if err := m.gatherRequestsDiscrete(requests.discrete, pause); err != nil {
 		return err
 	}
-	if err := m.gatherRequestsHolding(requests.holding); err != nil {
+	if err := m.gatherRequestsHolding(requests.holding, pause); err != nil {
 		return err
 	}
-	return m.gatherRequestsInput(requests.input)
+	return m.gatherRequestsInput(requests.input, pause)
 }
 
-func (m *Modbus) gatherRequestsCoil(requests []request) error {
+func (m *Modbus) gatherRequestsCoil(requests []request, pause time.Duration) error {
 	for _, request := range requests {
 		m.Log.Debugf("trying to read coil@%v[%v]...", request.address, request.length)
 		bytes, err := m.client.ReadCoils(request.address, request.length)
 		if err != nil {
 			return err
 		}
-		nextRequest := time.Now().Add(time.Duration(m.Workarounds.PollPause))
+		nextRequest := time.Now().Add(pause)
 		m.Log.Debugf("got coil@%v[%v]: %v", request.address, request.length, bytes)
 
 		// Bit value handling
@@ -451,14 +486,14 @@ func (m *Modbus) gatherRequestsCoil(requests []request) error {
 	return nil
 }
 
-func (m *Modbus) gatherRequestsDiscrete(requests []request) error {
+func (m *Modbus) gatherRequestsDiscrete(requests []request, pause time.Duration) error {
 	for _, request := range requests {
 		m.Log.Debugf("trying to read discrete@%v[%v]...", request.address, request.length)
 		bytes, err := m.client.ReadDiscreteInputs(request.address, request.length)
 		if err != nil {
 			return err
 		}
-		nextRequest := time.Now().Add(time.Duration(m.Workarounds.PollPause))
+		nextRequest := time.Now().Add(pause)
 		m.Log.Debugf("got discrete@%v[%v]: %v", request.address, request.length, bytes)
 
 		// Bit value handling
@@ -478,14 +513,14 @@ func (m *Modbus) gatherRequestsDiscrete(requests []request) error {
 	return nil
 }
 
-func (m *Modbus) gatherRequestsHolding(requests []request) error {
+func (m *Modbus) gatherRequestsHolding(requests []request, pause time.Duration) error {
 	for _, request := range requests {
 		m.Log.Debugf("trying to read holding@%v[%v]...", request.address, request.length)
 		bytes, err := m.client.ReadHoldingRegisters(request.address, request.length)
 		if err != nil {
 			return err
 		}
-		nextRequest := time.Now().Add(time.Duration(m.Workarounds.PollPause))
+		nextRequest := time.Now().Add(pause)
 		m.Log.Debugf("got holding@%v[%v]: %v", request.address, request.length, bytes)
 
 		// Non-bit value handling
@@ -505,14 +540,14 @@ func (m *Modbus) gatherRequestsHolding(requests []request) error {
 	return nil
 }
 
-func (m *Modbus) gatherRequestsInput(requests []request) error {
+func (m *Modbus) gatherRequestsInput(requests []request, pause time.Duration) error {
 	for _, request := range requests {
 		m.Log.Debugf("trying to read input@%v[%v]...", request.address, request.length)
 		bytes, err := m.client.ReadInputRegisters(request.address, request.length)
 		if err != nil {
 			return err
 		}
-		nextRequest := time.Now().Add(time.Duration(m.Workarounds.PollPause))
+		nextRequest := time.Now().Add(pause)
 		m.Log.Debugf("got input@%v[%v]: %v", request.address, request.length, bytes)
 
 		// Non-bit value handling
@@ -2,7 +2,9 @@ package modbus
 
 import (
 	"math"
+	"slices"
 	"sort"
+	"time"
 
 	"github.com/influxdata/telegraf"
 )
@@ -11,6 +13,35 @@ type request struct {
 	address uint16
 	length  uint16
 	fields  []field
+
+	// Polling interval of the request, zero means reading every gather cycle
+	interval time.Duration
+	// Interval slot of the last successful read
+	slot time.Time
+}
+
+// isDue checks if the request has to be read at the given time. Requests with
+// an interval are read once per interval-slot aligned to the clock.
+func (r *request) isDue(now time.Time) bool {
+	return r.interval <= 0 || now.Truncate(r.interval).After(r.slot)
+}
+
+func dueRequests(requests []request, now time.Time) []request {
+	due := make([]request, 0, len(requests))
+	for _, r := range requests {
+		if r.isDue(now) {
+			due = append(due, r)
+		}
+	}
+	return due
+}
+
+func markRequestsRead(requests []request, now time.Time) {
+	for i := range requests {
+		if requests[i].interval > 0 && requests[i].isDue(now) {
+			requests[i].slot = now.Truncate(requests[i].interval)
+		}
+	}
 }
 
 func countRegisters(requests []request) uint64 {
@@ -303,3 +334,33 @@ func groupFieldsToRequests(fields []field, params groupingParams) []request {
 
 	return requests
 }
+
+// Merge requests with the same polling interval if their address ranges are
+// directly adjacent and the merged request does not exceed the maximum size.
+// This allows to combine the requests of multiple request definitions.
+func coalesceRequests(requests []request, maxBatchSize uint16) []request {
+	if len(requests) < 2 {
+		return requests
+	}
+
+	sort.SliceStable(requests, func(i, j int) bool {
+		if requests[i].interval != requests[j].interval {
+			return requests[i].interval < requests[j].interval
+		}
+		return requests[i].address < requests[j].address
+	})
+
+	result := make([]request, 0, len(requests))
+	current := requests[0]
+	for _, r := range requests[1:] {
+		adjacent := r.interval == current.interval && uint32(current.address)+uint32(current.length) == uint32(r.address)
+		if adjacent && uint32(current.length)+uint32(r.length) <= uint32(maxBatchSize) {
+			current.length += r.length
+			current.fields = append(slices.Clip(current.fields), r.fields...)
+			continue
+		}
+		result = append(result, current)
+		current = r
+	}
+	return append(result, current)
+}
@@ -50,6 +50,9 @@ type Mysql struct {
 	GatherSlaveStatus                   bool             `toml:"gather_slave_status"`
 	GatherReplicaStatus                 bool             `toml:"gather_replica_status"`
 	GatherAllSlaveChannels              bool             `toml:"gather_all_slave_channels"`
+	DiscoverReplicas                    bool             `toml:"discover_replicas"`
+	ReplicationRoleTag                  bool             `toml:"replication_role_tag"`
+	GatherGroupReplication              bool             `toml:"gather_group_replication"`
 	MariadbDialect                      bool             `toml:"mariadb_dialect"`
 	GatherBinaryLogs                    bool             `toml:"gather_binary_logs"`
 	GatherTableIOWaits                  bool             `toml:"gather_table_io_waits"`
@@ -69,6 +72,7 @@ type Mysql struct {
 
 	lastT               time.Time
 	getStatusQuery      string
+	replicaHostsQuery   string
 	loggedConvertFields map[string]bool
 }
 
@@ -87,6 +91,14 @@ func (m *Mysql) Init() error {
 	default:
 		m.getStatusQuery = slaveStatusQuery
 	}
+	switch {
+	case m.MariadbDialect && m.GatherReplicaStatus:
+		m.replicaHostsQuery = replicaHostsQueryMariadb
+	case m.GatherReplicaStatus:
+		m.replicaHostsQuery = replicaHostsQuery
+	default:
+		m.replicaHostsQuery = slaveHostsQuery
+	}
 	// Default to localhost if nothing specified.
 	if len(m.Servers) == 0 {
 		s := config.NewSecret([]byte(localhost))
@@ -149,18 +161,54 @@ func (m *Mysql) Init() error {
 }
 
 func (m *Mysql) Gather(acc telegraf.Accumulator) error {
-	var wg sync.WaitGroup
+	dsns := make([]string, 0, len(m.Servers))
+	for i, server := range m.Servers {
+		dsnSecret, err := server.Get()
+		if err != nil {
+			acc.AddError(fmt.Errorf("getting server %d failed: %w", i, err))
+			continue
+		}
+		dsns = append(dsns, dsnSecret.String())
+		dsnSecret.Destroy()
+	}
+
+	// Keep track of the gathered servers to collect discovered replicas only
+	// once, even in circular replication setups
+	seen := make(map[string]bool, len(dsns))
+	for _, dsn := range dsns {
+		seen[getDSNTag(dsn)] = true
+	}
+
+	for len(dsns) > 0 {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var discovered []string
+
+		// Loop through each server and collect metrics
+		for _, dsn := range dsns {
+			wg.Add(1)
+			go func(dsn string) {
+				defer wg.Done()
+				replicas, err := m.gatherServer(dsn, acc)
+				acc.AddError(err)
 
-	// Loop through each server and collect metrics
-	for _, server := range m.Servers {
-		wg.Add(1)
-		go func(s *config.Secret) {
-			defer wg.Done()
-			acc.AddError(m.gatherServer(s, acc))
-		}(server)
+				mu.Lock()
+				discovered = append(discovered, replicas...)
+				mu.Unlock()
+			}(dsn)
+		}
+		wg.Wait()
+
+		// Continue with the replicas not gathered yet
+		dsns = make([]string, 0, len(discovered))
+		for _, dsn := range discovered {
+			if addr := getDSNTag(dsn); !seen[addr] {
+				seen[addr] = true
+				dsns = append(dsns, dsn)
+			}
+		}
 	}
 
-	wg.Wait()
 	return nil
 }
 
@@ -429,22 +477,42 @@ const (
 	`
 )
 
-func (m *Mysql) gatherServer(server *config.Secret, acc telegraf.Accumulator) error {
-	dsnSecret, err := server.Get()
-	if err != nil {
-		return err
-	}
-	dsn := dsnSecret.String()
-	dsnSecret.Destroy()
+// gatherServer collects the metrics of the server and returns the connection
+// strings of the replicas discovered on the server
+func (m *Mysql) gatherServer(dsn string, acc telegraf.Accumulator) ([]string, error) {
 	servtag := getDSNTag(dsn)
 
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer db.Close()
 
-	err = m.gatherGlobalStatuses(db, servtag, acc)
+	var replicas []string
+	if m.DiscoverReplicas || m.ReplicationRoleTag {
+		hosts, err := m.replicaHosts(db)
+		if err != nil {
+			return nil, fmt.Errorf("querying replicas failed: %w", err)
+		}
+		if m.DiscoverReplicas {
+			if replicas, err = replicaDSNs(dsn, hosts); err != nil {
+				return nil, fmt.Errorf("creating replica connection strings failed: %w", err)
+			}
+		}
+		if m.ReplicationRoleTag {
+			role, err := m.replicationRole(db, len(hosts))
+			if err != nil {
+				return replicas, fmt.Errorf("determining replication role failed: %w", err)
+			}
+			acc = &roleAccumulator{Accumulator: acc, role: role}
+		}
+	}
+
+	return replicas, m.gatherServerMetrics(db, servtag, acc)
+}
+
+func (m *Mysql) gatherServerMetrics(db *sql.DB, servtag string, acc telegraf.Accumulator) error {
+	err := m.gatherGlobalStatuses(db, servtag, acc)
 	if err != nil {
 		return err
 	}
@@ -488,6 +556,13 @@ func (m *Mysql) gatherServer(server *config.Secret, acc telegraf.Accumulator) er
 		}
 	}
 
+	if m.GatherGroupReplication {
+		err = m.gatherGroupReplication(db, servtag, acc)
+		if err != nil {
+			return err
+		}
+	}
+
 	if m.GatherInfoSchemaAutoInc {
 		err = m.gatherInfoSchemaAutoIncStatuses(db, servtag, acc)
 		if err != nil {
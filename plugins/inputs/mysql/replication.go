@@ -0,0 +1,278 @@
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/influxdata/telegraf"
+)
+
+// replication queries
+const (
+	replicaHostsQuery         = `SHOW REPLICAS`
+	slaveHostsQuery           = `SHOW SLAVE HOSTS`
+	replicaHostsQueryMariadb  = `SHOW REPLICA HOSTS`
+	groupReplicationRoleQuery = `
+        SELECT MEMBER_ROLE
+        FROM performance_schema.replication_group_members
+        WHERE MEMBER_ID = @@server_uuid AND MEMBER_STATE = 'ONLINE'
+    `
+	groupReplicationMembersQuery = `
+        SELECT *
+        FROM performance_schema.replication_group_members
+    `
+	groupReplicationMemberStatsQuery = `
+        SELECT *
+        FROM performance_schema.replication_group_member_stats
+    `
+	innoDBClusterNameQuery = `
+        SELECT cluster_name
+        FROM mysql_innodb_cluster_metadata.v2_this_instance
+    `
+)
+
+// Error numbers of the server if the InnoDB cluster metadata does not exist
+const (
+	errUnknownDatabase = 1049
+	errNoSuchTable     = 1146
+)
+
+// replicaHosts returns the addresses of the replicas registered at the
+// server. Replicas only report their address if 'report_host' is set.
+func (m *Mysql) replicaHosts(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(m.replicaHostsQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := columnsToLower(rows.Columns())
+	if err != nil {
+		return nil, err
+	}
+
+	var hosts []string
+	for rows.Next() {
+		vals := make([]sql.RawBytes, len(cols))
+		valPtrs := make([]interface{}, len(cols))
+		for i := range vals {
+			valPtrs[i] = &vals[i]
+		}
+		if err := rows.Scan(valPtrs...); err != nil {
+			return nil, err
+		}
+
+		var host, port string
+		for i, col := range cols {
+			switch col {
+			case "host":
+				host = string(vals[i])
+			case "port":
+				port = string(vals[i])
+			}
+		}
+		if host == "" {
+			continue
+		}
+		if port == "" || port == "0" {
+			port = "3306"
+		}
+		hosts = append(hosts, net.JoinHostPort(host, port))
+	}
+	return hosts, rows.Err()
+}
+
+// replicaDSNs derives the connection strings of the replicas from the one of
+// the source server by replacing the address
+func replicaDSNs(dsn string, hosts []string) ([]string, error) {
+	dsns := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		conf, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+		conf.Net = "tcp"
+		conf.Addr = host
+		dsns = append(dsns, conf.FormatDSN())
+	}
+	return dsns, nil
+}
+
+// replicationRole determines the role of the server in the replication
+// topology. Online group replication members report their member role, other
+// servers are replicas if they replicate from a source, primaries if replicas
+// are connected and standalone otherwise.
+func (m *Mysql) replicationRole(db *sql.DB, replicas int) (string, error) {
+	if m.GatherGroupReplication {
+		var role string
+		err := db.QueryRow(groupReplicationRoleQuery).Scan(&role)
+		switch {
+		case err == nil && role != "":
+			if strings.EqualFold(role, "PRIMARY") {
+				return "primary", nil
+			}
+			return "replica", nil
+		case err != nil && !errors.Is(err, sql.ErrNoRows):
+			return "", err
+		}
+	}
+
+	rows, err := db.Query(m.getStatusQuery)
+	if err != nil {
+		return "", err
+	}
+	isReplica := rows.Next()
+	rows.Close()
+	switch {
+	case isReplica:
+		return "replica", nil
+	case replicas > 0:
+		return "primary", nil
+	}
+	return "standalone", nil
+}
+
+// gatherGroupReplication collects the state of the group replication
+// respectively InnoDB cluster members and the statistics of the member
+// including the queues used for flow control
+func (m *Mysql) gatherGroupReplication(db *sql.DB, servtag string, acc telegraf.Accumulator) error {
+	tags := map[string]string{"server": servtag}
+
+	// The cluster name is only available for InnoDB clusters
+	var cluster string
+	err := db.QueryRow(innoDBClusterNameQuery).Scan(&cluster)
+	var mysqlErr *mysql.MySQLError
+	switch {
+	case err == nil:
+		tags["cluster"] = cluster
+	case errors.Is(err, sql.ErrNoRows):
+	case errors.As(err, &mysqlErr) && (mysqlErr.Number == errUnknownDatabase || mysqlErr.Number == errNoSuchTable):
+	default:
+		return err
+	}
+
+	if err := m.gatherGroupReplicationMembers(db, tags, acc); err != nil {
+		return err
+	}
+	return m.gatherGroupReplicationMemberStats(db, tags, acc)
+}
+
+func (*Mysql) gatherGroupReplicationMembers(db *sql.DB, baseTags map[string]string, acc telegraf.Accumulator) error {
+	rows, err := db.Query(groupReplicationMembersQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	cols, err := columnsToLower(rows.Columns())
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		vals := make([]sql.RawBytes, len(cols))
+		valPtrs := make([]interface{}, len(cols))
+		for i := range vals {
+			valPtrs[i] = &vals[i]
+		}
+		if err := rows.Scan(valPtrs...); err != nil {
+			return err
+		}
+
+		tags := make(map[string]string, len(baseTags)+4)
+		for k, v := range baseTags {
+			tags[k] = v
+		}
+		fields := make(map[string]interface{}, 3)
+		for i, col := range cols {
+			value := string(vals[i])
+			switch col {
+			case "channel_name":
+				tags["channel"] = value
+			case "member_id", "member_host", "member_port":
+				tags[col] = value
+			case "member_state", "member_role", "member_version":
+				fields[col] = value
+			}
+		}
+		if state, ok := fields["member_state"]; ok {
+			online := int64(0)
+			if state == "ONLINE" {
+				online = 1
+			}
+			fields["member_online"] = online
+		}
+		acc.AddFields("mysql_group_replication_member", fields, tags)
+	}
+	return rows.Err()
+}
+
+func (*Mysql) gatherGroupReplicationMemberStats(db *sql.DB, baseTags map[string]string, acc telegraf.Accumulator) error {
+	rows, err := db.Query(groupReplicationMemberStatsQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	cols, err := columnsToLower(rows.Columns())
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		vals := make([]sql.RawBytes, len(cols))
+		valPtrs := make([]interface{}, len(cols))
+		for i := range vals {
+			valPtrs[i] = &vals[i]
+		}
+		if err := rows.Scan(valPtrs...); err != nil {
+			return err
+		}
+
+		tags := make(map[string]string, len(baseTags)+2)
+		for k, v := range baseTags {
+			tags[k] = v
+		}
+		fields := make(map[string]interface{}, len(cols))
+		for i, col := range cols {
+			switch {
+			case col == "channel_name":
+				tags["channel"] = string(vals[i])
+			case col == "member_id":
+				tags["member_id"] = string(vals[i])
+			case strings.HasPrefix(col, "count_"):
+				v, err := strconv.ParseInt(string(vals[i]), 10, 64)
+				if err != nil {
+					acc.AddError(fmt.Errorf("error parsing group replication member stats %q=%q: %w", col, string(vals[i]), err))
+					continue
+				}
+				fields[col] = v
+			}
+		}
+		if len(fields) > 0 {
+			acc.AddFields("mysql_group_replication_member_stats", fields, tags)
+		}
+	}
+	return rows.Err()
+}
+
+// roleAccumulator adds the replication role of the server to all metrics
+type roleAccumulator struct {
+	telegraf.Accumulator
+	role string
+}
+
+func (a *roleAccumulator) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	if tags == nil {
+		tags = make(map[string]string, 1)
+	}
+	tags["replication_role"] = a.role
+	a.Accumulator.AddFields(measurement, fields, tags, t...)
+}
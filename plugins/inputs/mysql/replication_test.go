@@ -0,0 +1,333 @@
+package mysql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestReplicaHosts(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	m := &Mysql{Log: testutil.Logger{}}
+	require.NoError(t, m.Init())
+
+	rows := sqlmock.NewRows([]string{"Server_id", "Host", "Port", "Master_id", "Slave_UUID"}).
+		AddRow("2", "10.0.0.2", "3306", "1", "8a94f357-aab4-11df-86ab-c80aa9429562").
+		AddRow("3", "", "3306", "1", "8a94f357-aab4-11df-86ab-c80aa9429563").
+		AddRow("4", "replica3", "3307", "1", "8a94f357-aab4-11df-86ab-c80aa9429564").
+		AddRow("5", "fd00::5", "0", "1", "8a94f357-aab4-11df-86ab-c80aa9429565")
+	mock.ExpectQuery(slaveHostsQuery).WillReturnRows(rows)
+
+	hosts, err := m.replicaHosts(db)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.2:3306", "replica3:3307", "[fd00::5]:3306"}, hosts)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplicaHostsQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Mysql
+		expected string
+	}{
+		{
+			name:     "slave hosts",
+			plugin:   &Mysql{},
+			expected: slaveHostsQuery,
+		},
+		{
+			name:     "replicas",
+			plugin:   &Mysql{GatherReplicaStatus: true},
+			expected: replicaHostsQuery,
+		},
+		{
+			name:     "mariadb slave hosts",
+			plugin:   &Mysql{MariadbDialect: true},
+			expected: slaveHostsQuery,
+		},
+		{
+			name:     "mariadb replica hosts",
+			plugin:   &Mysql{MariadbDialect: true, GatherReplicaStatus: true},
+			expected: replicaHostsQueryMariadb,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+			require.Equal(t, tt.expected, tt.plugin.replicaHostsQuery)
+		})
+	}
+}
+
+func TestReplicaDSNs(t *testing.T) {
+	dsns, err := replicaDSNs("telegraf:secret@tcp(10.0.0.1:3306)/?tls=false&timeout=5s", []string{"10.0.0.2:3306", "replica3:3307"})
+	require.NoError(t, err)
+	require.Len(t, dsns, 2)
+
+	conf, err := mysql.ParseDSN(dsns[0])
+	require.NoError(t, err)
+	require.Equal(t, "tcp", conf.Net)
+	require.Equal(t, "10.0.0.2:3306", conf.Addr)
+	require.Equal(t, "telegraf", conf.User)
+	require.Equal(t, "secret", conf.Passwd)
+	require.Equal(t, 5*time.Second, conf.Timeout)
+	require.Equal(t, "replica3:3307", getDSNTag(dsns[1]))
+
+	// Replicas of servers connected via unix sockets are reached via TCP
+	dsns, err = replicaDSNs("telegraf@unix(/var/run/mysqld/mysqld.sock)/", []string{"10.0.0.2:3306"})
+	require.NoError(t, err)
+	conf, err = mysql.ParseDSN(dsns[0])
+	require.NoError(t, err)
+	require.Equal(t, "tcp", conf.Net)
+	require.Equal(t, "10.0.0.2:3306", conf.Addr)
+}
+
+func TestReplicationRole(t *testing.T) {
+	tests := []struct {
+		name             string
+		groupReplication bool
+		memberRole       string
+		replicating      bool
+		replicas         int
+		expected         string
+	}{
+		{
+			name:     "standalone",
+			expected: "standalone",
+		},
+		{
+			name:     "primary",
+			replicas: 2,
+			expected: "primary",
+		},
+		{
+			name:        "replica",
+			replicating: true,
+			expected:    "replica",
+		},
+		{
+			name:        "intermediate replica",
+			replicating: true,
+			replicas:    1,
+			expected:    "replica",
+		},
+		{
+			name:             "group primary",
+			groupReplication: true,
+			memberRole:       "PRIMARY",
+			expected:         "primary",
+		},
+		{
+			name:             "group secondary",
+			groupReplication: true,
+			memberRole:       "SECONDARY",
+			expected:         "replica",
+		},
+		{
+			name:             "no group member",
+			groupReplication: true,
+			replicas:         1,
+			expected:         "primary",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer db.Close()
+
+			m := &Mysql{
+				GatherGroupReplication: tt.groupReplication,
+				Log:                    testutil.Logger{},
+			}
+			require.NoError(t, m.Init())
+
+			if tt.groupReplication {
+				rows := sqlmock.NewRows([]string{"MEMBER_ROLE"})
+				if tt.memberRole != "" {
+					rows.AddRow(tt.memberRole)
+				}
+				mock.ExpectQuery(groupReplicationRoleQuery).WillReturnRows(rows)
+			}
+			if tt.memberRole == "" {
+				rows := sqlmock.NewRows([]string{"Slave_IO_State", "Master_Host"})
+				if tt.replicating {
+					rows.AddRow("Waiting for source to send event", "10.0.0.1")
+				}
+				mock.ExpectQuery(slaveStatusQuery).WillReturnRows(rows)
+			}
+
+			role, err := m.replicationRole(db, tt.replicas)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, role)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGatherGroupReplication(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	m := &Mysql{
+		GatherGroupReplication: true,
+		Log:                    testutil.Logger{},
+	}
+	require.NoError(t, m.Init())
+
+	mock.ExpectQuery(innoDBClusterNameQuery).WillReturnRows(sqlmock.NewRows([]string{"cluster_name"}).AddRow("prod"))
+	mock.ExpectQuery(groupReplicationMembersQuery).WillReturnRows(
+		sqlmock.NewRows([]string{
+			"CHANNEL_NAME", "MEMBER_ID", "MEMBER_HOST", "MEMBER_PORT", "MEMBER_STATE", "MEMBER_ROLE", "MEMBER_VERSION",
+		}).
+			AddRow("group_replication_applier", "uuid-1", "db1", "3306", "ONLINE", "PRIMARY", "8.0.36").
+			AddRow("group_replication_applier", "uuid-2", "db2", "3306", "RECOVERING", "SECONDARY", "8.0.36"),
+	)
+	mock.ExpectQuery(groupReplicationMemberStatsQuery).WillReturnRows(
+		sqlmock.NewRows([]string{
+			"CHANNEL_NAME", "VIEW_ID", "MEMBER_ID", "COUNT_TRANSACTIONS_IN_QUEUE", "COUNT_TRANSACTIONS_CHECKED",
+			"COUNT_CONFLICTS_DETECTED", "TRANSACTIONS_COMMITTED_ALL_MEMBERS", "COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE",
+		}).
+			AddRow("group_replication_applier", "17000000:1", "uuid-1", "0", "1042", "3", "aaaa:1-1042", "12"),
+	)
+
+	var acc testutil.Accumulator
+	require.NoError(t, m.gatherGroupReplication(db, "127.0.0.1:3306", &acc))
+	require.Empty(t, acc.Errors)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	expected := []telegraf.Metric{
+		metric.New("mysql_group_replication_member",
+			map[string]string{
+				"server":      "127.0.0.1:3306",
+				"cluster":     "prod",
+				"channel":     "group_replication_applier",
+				"member_id":   "uuid-1",
+				"member_host": "db1",
+				"member_port": "3306",
+			},
+			map[string]interface{}{
+				"member_state":   "ONLINE",
+				"member_role":    "PRIMARY",
+				"member_version": "8.0.36",
+				"member_online":  int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New("mysql_group_replication_member",
+			map[string]string{
+				"server":      "127.0.0.1:3306",
+				"cluster":     "prod",
+				"channel":     "group_replication_applier",
+				"member_id":   "uuid-2",
+				"member_host": "db2",
+				"member_port": "3306",
+			},
+			map[string]interface{}{
+				"member_state":   "RECOVERING",
+				"member_role":    "SECONDARY",
+				"member_version": "8.0.36",
+				"member_online":  int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New("mysql_group_replication_member_stats",
+			map[string]string{
+				"server":    "127.0.0.1:3306",
+				"cluster":   "prod",
+				"channel":   "group_replication_applier",
+				"member_id": "uuid-1",
+			},
+			map[string]interface{}{
+				"count_transactions_in_queue":                int64(0),
+				"count_transactions_checked":                 int64(1042),
+				"count_conflicts_detected":                   int64(3),
+				"count_transactions_remote_in_applier_queue": int64(12),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherGroupReplicationWithoutCluster(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	m := &Mysql{
+		GatherGroupReplication: true,
+		Log:                    testutil.Logger{},
+	}
+	require.NoError(t, m.Init())
+
+	mock.ExpectQuery(innoDBClusterNameQuery).WillReturnError(&mysql.MySQLError{
+		Number:  errUnknownDatabase,
+		Message: "Unknown database 'mysql_innodb_cluster_metadata'",
+	})
+	mock.ExpectQuery(groupReplicationMembersQuery).WillReturnRows(
+		sqlmock.NewRows([]string{"CHANNEL_NAME", "MEMBER_ID", "MEMBER_HOST", "MEMBER_PORT", "MEMBER_STATE"}).
+			AddRow("group_replication_applier", "uuid-1", "db1", "3306", "ONLINE"),
+	)
+	mock.ExpectQuery(groupReplicationMemberStatsQuery).WillReturnRows(
+		sqlmock.NewRows([]string{"CHANNEL_NAME", "MEMBER_ID", "COUNT_TRANSACTIONS_IN_QUEUE"}),
+	)
+
+	var acc testutil.Accumulator
+	require.NoError(t, m.gatherGroupReplication(db, "127.0.0.1:3306", &acc))
+	require.Empty(t, acc.Errors)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	expected := []telegraf.Metric{
+		metric.New("mysql_group_replication_member",
+			map[string]string{
+				"server":      "127.0.0.1:3306",
+				"channel":     "group_replication_applier",
+				"member_id":   "uuid-1",
+				"member_host": "db1",
+				"member_port": "3306",
+			},
+			map[string]interface{}{
+				"member_state":  "ONLINE",
+				"member_online": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestRoleAccumulator(t *testing.T) {
+	var acc testutil.Accumulator
+	wrapped := &roleAccumulator{Accumulator: &acc, role: "replica"}
+	wrapped.AddFields("mysql", map[string]interface{}{"uptime": int64(42)}, map[string]string{"server": "127.0.0.1:3306"})
+	wrapped.AddFields("mysql", map[string]interface{}{"uptime": int64(43)}, nil)
+
+	expected := []telegraf.Metric{
+		metric.New("mysql",
+			map[string]string{"server": "127.0.0.1:3306", "replication_role": "replica"},
+			map[string]interface{}{"uptime": int64(42)},
+			time.Unix(0, 0),
+		),
+		metric.New("mysql",
+			map[string]string{"replication_role": "replica"},
+			map[string]interface{}{"uptime": int64(43)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
@@ -0,0 +1,326 @@
+package netflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/snmp"
+)
+
+// OID of the 'ifName' column of the IF-MIB interface table
+const oidIfName = ".1.3.6.1.2.1.31.1.1.1.1"
+
+// Minimum time between SNMP queries to the same agent on failures
+const minTimeBetweenQueries = 5 * time.Minute
+
+// Fields containing the sampling interval of a flow record or options record
+var samplingIntervalFields = []string{"sampling_interval", "flow_sampler_interval", "sampling_packet_interval"}
+
+// Fields containing the ID of the sampler referenced by a flow record
+var samplerIDFields = []string{"flow_sampler_id", "selector_id"}
+
+// Counter fields scaled by the sampling rate
+var sampledFields = []string{"in_bytes", "in_packets", "out_bytes", "out_packets"}
+
+// enricher adds information not contained in the flow records
+type enricher struct {
+	samplingCorrection  bool
+	defaultSamplingRate uint64
+	applications        map[string]string
+	interfaces          *interfaceResolver
+
+	// Sampling intervals announced in options records by exporter and
+	// sampler ID
+	samplers map[string]map[string]uint64
+}
+
+func (e *enricher) enrich(metrics []telegraf.Metric) {
+	for _, m := range metrics {
+		exporter, _ := m.GetTag("source")
+		if m.Name() == "netflow_options" {
+			e.learn(exporter, m)
+			continue
+		}
+
+		if e.samplingCorrection {
+			e.correctSampling(exporter, m)
+		}
+		if len(e.applications) > 0 {
+			e.addApplication(m)
+		}
+		if e.interfaces != nil {
+			// sFlow exporters might be proxies reporting for other agents
+			agent := exporter
+			if v, found := m.GetField("agent_ip"); found {
+				agent = fmt.Sprint(v)
+			}
+			for _, prefix := range []string{"in", "out"} {
+				index, found := fieldUint(m, prefix+"_snmp")
+				if !found {
+					continue
+				}
+				if name, found := e.interfaces.lookup(agent, index); found {
+					m.AddField(prefix+"_ifname", name)
+				}
+			}
+		}
+	}
+}
+
+// learn stores the sampling intervals and interface names announced by the
+// exporter in options records
+func (e *enricher) learn(exporter string, m telegraf.Metric) {
+	if e.samplingCorrection {
+		for _, name := range samplingIntervalFields {
+			interval, found := fieldUint(m, name)
+			if !found || interval == 0 {
+				continue
+			}
+			var id string
+			for _, idName := range samplerIDFields {
+				if v, found := fieldUint(m, idName); found {
+					id = strconv.FormatUint(v, 10)
+					break
+				}
+			}
+			if _, found := e.samplers[exporter]; !found {
+				e.samplers[exporter] = make(map[string]uint64)
+			}
+			e.samplers[exporter][id] = interval
+			break
+		}
+	}
+
+	if e.interfaces != nil {
+		index, found := fieldUint(m, "in_snmp")
+		if !found {
+			return
+		}
+		if name, ok := m.GetField("interface"); ok {
+			e.interfaces.store(exporter, index, fmt.Sprint(name))
+		}
+	}
+}
+
+// samplingRate determines the sampling rate of the flow record by checking
+// the record itself, the options announced by the exporter and finally the
+// configured default
+func (e *enricher) samplingRate(exporter string, m telegraf.Metric) uint64 {
+	version, _ := m.GetTag("version")
+	for _, name := range samplingIntervalFields {
+		interval, found := fieldUint(m, name)
+		if !found || interval == 0 {
+			continue
+		}
+		// The upper two bits of the Netflow v5 header contain the sampling mode
+		if version == "NetFlowV5" {
+			interval &= 0x3fff
+		}
+		return interval
+	}
+
+	if samplers, found := e.samplers[exporter]; found {
+		for _, idName := range samplerIDFields {
+			if id, found := fieldUint(m, idName); found {
+				if interval, found := samplers[strconv.FormatUint(id, 10)]; found {
+					return interval
+				}
+			}
+		}
+		if interval, found := samplers[""]; found {
+			return interval
+		}
+	}
+	return e.defaultSamplingRate
+}
+
+func (e *enricher) correctSampling(exporter string, m telegraf.Metric) {
+	// sFlow samples describe individual packets, the interface counters are
+	// not sampled at all
+	if version, _ := m.GetTag("version"); version == "sFlowV5" {
+		return
+	}
+
+	rate := e.samplingRate(exporter, m)
+	if rate <= 1 {
+		return
+	}
+	for _, name := range sampledFields {
+		if v, found := fieldUint(m, name); found {
+			m.AddField(name, v*rate)
+		}
+	}
+	m.AddField("sampling_rate", rate)
+}
+
+// addApplication maps the destination port, or if not found the source port,
+// of the flow to an application name
+func (e *enricher) addApplication(m telegraf.Metric) {
+	var protocol string
+	if v, found := m.GetField("protocol"); found {
+		protocol = strings.ToLower(fmt.Sprint(v))
+	}
+	for _, name := range []string{"dst_port", "src_port"} {
+		port, found := fieldUint(m, name)
+		if !found {
+			continue
+		}
+		key := strconv.FormatUint(port, 10)
+		if app, found := e.applications[protocol+"/"+key]; found {
+			m.AddField("application", app)
+			return
+		}
+		if app, found := e.applications["*/"+key]; found {
+			m.AddField("application", app)
+			return
+		}
+	}
+}
+
+func fieldUint(m telegraf.Metric, name string) (uint64, bool) {
+	raw, found := m.GetField(name)
+	if !found {
+		return 0, false
+	}
+	v, err := internal.ToUint64(raw)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// interfaceResolver caches interface names by agent and interface index.
+// Names are either announced by the exporter in options records or queried
+// in the background via SNMP.
+type interfaceResolver struct {
+	ttl   time.Duration
+	query func(agent string) (map[uint64]string, error)
+	log   telegraf.Logger
+
+	agents map[string]*interfaceTable
+	wg     sync.WaitGroup
+	sync.Mutex
+}
+
+type interfaceTable struct {
+	names   map[uint64]string
+	updated time.Time
+	queried time.Time
+	pending bool
+}
+
+func newInterfaceResolver(ttl time.Duration, log telegraf.Logger) *interfaceResolver {
+	return &interfaceResolver{
+		ttl:    ttl,
+		log:    log,
+		agents: make(map[string]*interfaceTable),
+	}
+}
+
+func (r *interfaceResolver) store(agent string, index uint64, name string) {
+	r.Lock()
+	defer r.Unlock()
+
+	table, found := r.agents[agent]
+	if !found {
+		table = &interfaceTable{names: make(map[uint64]string)}
+		r.agents[agent] = table
+	}
+	table.names[index] = name
+	table.updated = time.Now()
+}
+
+// lookup returns the cached interface name and triggers a background update
+// of the agent's interface table if the name is unknown or outdated
+func (r *interfaceResolver) lookup(agent string, index uint64) (string, bool) {
+	r.Lock()
+	defer r.Unlock()
+
+	table, found := r.agents[agent]
+	if !found {
+		table = &interfaceTable{names: make(map[uint64]string)}
+		r.agents[agent] = table
+	}
+	name, found := table.names[index]
+
+	if r.query == nil || table.pending {
+		return name, found
+	}
+	now := time.Now()
+	outdated := r.ttl > 0 && now.Sub(table.updated) > r.ttl
+	if (found && !outdated) || now.Sub(table.queried) < minTimeBetweenQueries {
+		return name, found
+	}
+
+	table.pending = true
+	table.queried = now
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.update(agent, table)
+	}()
+
+	return name, found
+}
+
+func (r *interfaceResolver) update(agent string, table *interfaceTable) {
+	names, err := r.query(agent)
+
+	r.Lock()
+	defer r.Unlock()
+	table.pending = false
+	if err != nil {
+		r.log.Errorf("Querying interface names of %q failed: %v", agent, err)
+		return
+	}
+	for index, name := range names {
+		table.names[index] = name
+	}
+	table.updated = time.Now()
+}
+
+// wait blocks until all pending queries are finished
+func (r *interfaceResolver) wait() {
+	r.wg.Wait()
+}
+
+// snmpQuery returns a function walking the interface names of an agent via
+// SNMP using the given client settings
+func snmpQuery(cfg snmp.ClientConfig) func(agent string) (map[uint64]string, error) {
+	return func(agent string) (map[uint64]string, error) {
+		conn, err := snmp.NewWrapper(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("parsing SNMP client config failed: %w", err)
+		}
+		if err := conn.SetAgent(agent); err != nil {
+			return nil, fmt.Errorf("setting agent failed: %w", err)
+		}
+		if err := conn.Connect(); err != nil {
+			return nil, fmt.Errorf("connecting failed: %w", err)
+		}
+		defer conn.Conn.Close()
+
+		names := make(map[uint64]string)
+		err = conn.Walk(oidIfName, func(pdu gosnmp.SnmpPDU) error {
+			index, err := strconv.ParseUint(pdu.Name[strings.LastIndex(pdu.Name, ".")+1:], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid index in OID %q: %w", pdu.Name, err)
+			}
+			switch v := pdu.Value.(type) {
+			case []byte:
+				names[index] = string(v)
+			case string:
+				names[index] = v
+			}
+			return nil
+		})
+		return names, err
+	}
+}
@@ -0,0 +1,130 @@
+package netflow
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestEnrichSamplingFromOptions(t *testing.T) {
+	e := &enricher{
+		samplingCorrection: true,
+		samplers:           make(map[string]map[string]uint64),
+	}
+
+	tags := map[string]string{"source": "10.0.0.1", "version": "NetFlowV9"}
+	now := time.Unix(0, 0)
+	metrics := []telegraf.Metric{
+		metric.New("netflow_options", tags, map[string]interface{}{
+			"flow_sampler_id":       uint64(2),
+			"flow_sampler_interval": uint64(100),
+		}, now),
+		metric.New("netflow", tags, map[string]interface{}{
+			"flow_sampler_id": uint64(2),
+			"in_bytes":        uint64(1500),
+			"in_packets":      uint64(1),
+		}, now),
+		metric.New("netflow", tags, map[string]interface{}{
+			"flow_sampler_id": uint64(3),
+			"in_bytes":        uint64(1500),
+			"in_packets":      uint64(1),
+		}, now),
+	}
+	e.enrich(metrics)
+
+	expected := []telegraf.Metric{
+		metric.New("netflow_options", tags, map[string]interface{}{
+			"flow_sampler_id":       uint64(2),
+			"flow_sampler_interval": uint64(100),
+		}, now),
+		metric.New("netflow", tags, map[string]interface{}{
+			"flow_sampler_id": uint64(2),
+			"in_bytes":        uint64(150000),
+			"in_packets":      uint64(100),
+			"sampling_rate":   uint64(100),
+		}, now),
+		metric.New("netflow", tags, map[string]interface{}{
+			"flow_sampler_id": uint64(3),
+			"in_bytes":        uint64(1500),
+			"in_packets":      uint64(1),
+		}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestEnrichInterfaceNames(t *testing.T) {
+	var queries atomic.Int32
+	resolver := newInterfaceResolver(0, testutil.Logger{})
+	resolver.query = func(agent string) (map[uint64]string, error) {
+		queries.Add(1)
+		if agent != "10.0.0.1" {
+			return nil, errors.New("unknown agent")
+		}
+		return map[uint64]string{1: "eth0", 2: "eth1"}, nil
+	}
+	e := &enricher{interfaces: resolver}
+
+	tags := map[string]string{"source": "10.0.0.1", "version": "IPFIX"}
+	now := time.Unix(0, 0)
+	fields := map[string]interface{}{"in_snmp": uint64(1), "out_snmp": uint64(2)}
+
+	// The first lookup triggers the query in the background
+	first := metric.New("netflow", tags, fields, now)
+	e.enrich([]telegraf.Metric{first})
+	require.False(t, first.HasField("in_ifname"))
+	resolver.wait()
+
+	second := metric.New("netflow", tags, fields, now)
+	e.enrich([]telegraf.Metric{second})
+	resolver.wait()
+	expected := metric.New("netflow", tags, map[string]interface{}{
+		"in_snmp":    uint64(1),
+		"out_snmp":   uint64(2),
+		"in_ifname":  "eth0",
+		"out_ifname": "eth1",
+	}, now)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, []telegraf.Metric{second})
+	require.Equal(t, int32(1), queries.Load())
+
+	// Unknown interfaces must not trigger queries more often than allowed
+	unknown := metric.New("netflow", tags, map[string]interface{}{"in_snmp": uint64(5)}, now)
+	e.enrich([]telegraf.Metric{unknown})
+	resolver.wait()
+	require.False(t, unknown.HasField("in_ifname"))
+	require.Equal(t, int32(1), queries.Load())
+}
+
+func TestEnrichInterfaceNamesFromOptions(t *testing.T) {
+	e := &enricher{interfaces: newInterfaceResolver(0, testutil.Logger{})}
+
+	tags := map[string]string{"source": "10.0.0.1", "version": "NetFlowV9"}
+	now := time.Unix(0, 0)
+	metrics := []telegraf.Metric{
+		metric.New("netflow_options", tags, map[string]interface{}{
+			"in_snmp":   uint64(7),
+			"interface": "Gi0",
+		}, now),
+		metric.New("netflow", tags, map[string]interface{}{"in_snmp": uint64(7)}, now),
+	}
+	e.enrich(metrics)
+
+	name, found := metrics[1].GetField("in_ifname")
+	require.True(t, found)
+	require.Equal(t, "Gi0", name)
+}
+
+func TestInvalidInterfaceNames(t *testing.T) {
+	plugin := &NetFlow{
+		ServiceAddress: "udp://:2055",
+		InterfaceNames: "gnmi",
+		Log:            testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid 'interface_names' setting "gnmi"`)
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/csv"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 )
 
 var funcMapping = map[string]decoderFunc{
@@ -42,3 +44,37 @@ func loadMapping(filename string) (map[string]fieldMapping, error) {
 
 	return mappings, nil
 }
+
+// loadApplicationMapping reads a CSV file mapping ports to application names
+// with each line containing the port, the layer 4 protocol name or '*' and
+// the application name
+func loadApplicationMapping(filename string, mapping map[string]string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("opening %q failed: %w", filename, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comma = ','
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = 3
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("reading csv failed: %w", err)
+	}
+
+	for _, r := range records {
+		port, protocol, name := r[0], strings.ToLower(r[1]), r[2]
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return fmt.Errorf("invalid port %q for application %q", port, name)
+		}
+		if protocol == "" {
+			protocol = "*"
+		}
+		mapping[protocol+"/"+port] = name
+	}
+
+	return nil
+}
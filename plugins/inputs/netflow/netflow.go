@@ -10,9 +10,11 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/snmp"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -20,16 +22,25 @@ import (
 var sampleConfig string
 
 type NetFlow struct {
-	ServiceAddress string          `toml:"service_address"`
-	ReadBufferSize config.Size     `toml:"read_buffer_size"`
-	Protocol       string          `toml:"protocol"`
-	DumpPackets    bool            `toml:"dump_packets" deprecated:"1.35.0;use 'log_level' 'trace' instead"`
-	PENFiles       []string        `toml:"private_enterprise_number_files"`
-	Log            telegraf.Logger `toml:"-"`
-
-	conn    *net.UDPConn
-	decoder protocolDecoder
-	wg      sync.WaitGroup
+	ServiceAddress string      `toml:"service_address"`
+	ReadBufferSize config.Size `toml:"read_buffer_size"`
+	Protocol       string      `toml:"protocol"`
+	DumpPackets    bool        `toml:"dump_packets" deprecated:"1.35.0;use 'log_level' 'trace' instead"`
+	PENFiles       []string    `toml:"private_enterprise_number_files"`
+
+	SamplingCorrection  bool              `toml:"sampling_correction"`
+	DefaultSamplingRate uint64            `toml:"default_sampling_rate"`
+	ApplicationFiles    []string          `toml:"application_files"`
+	InterfaceNames      string            `toml:"interface_names"`
+	InterfaceNamesTTL   config.Duration   `toml:"interface_names_ttl"`
+	SNMP                snmp.ClientConfig `toml:"snmp"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	conn     *net.UDPConn
+	decoder  protocolDecoder
+	enricher *enricher
+	wg       sync.WaitGroup
 }
 
 type protocolDecoder interface {
@@ -79,7 +90,47 @@ func (n *NetFlow) Init() error {
 		return fmt.Errorf("invalid protocol %q, only supports 'sflow', 'netflow v5', 'netflow v9' and 'ipfix'", n.Protocol)
 	}
 
-	return n.decoder.init()
+	if err := n.decoder.init(); err != nil {
+		return err
+	}
+
+	return n.initEnrichment()
+}
+
+func (n *NetFlow) initEnrichment() error {
+	e := &enricher{
+		samplingCorrection:  n.SamplingCorrection,
+		defaultSamplingRate: n.DefaultSamplingRate,
+		samplers:            make(map[string]map[string]uint64),
+	}
+
+	if len(n.ApplicationFiles) > 0 {
+		e.applications = make(map[string]string)
+		for _, fn := range n.ApplicationFiles {
+			if err := loadApplicationMapping(fn, e.applications); err != nil {
+				return fmt.Errorf("loading application mapping %q failed: %w", fn, err)
+			}
+		}
+	}
+
+	switch n.InterfaceNames {
+	case "", "none":
+	case "options":
+		e.interfaces = newInterfaceResolver(time.Duration(n.InterfaceNamesTTL), n.Log)
+	case "snmp":
+		if _, err := snmp.NewWrapper(n.SNMP); err != nil {
+			return fmt.Errorf("parsing SNMP client config failed: %w", err)
+		}
+		e.interfaces = newInterfaceResolver(time.Duration(n.InterfaceNamesTTL), n.Log)
+		e.interfaces.query = snmpQuery(n.SNMP)
+	default:
+		return fmt.Errorf("invalid 'interface_names' setting %q", n.InterfaceNames)
+	}
+
+	if e.samplingCorrection || e.applications != nil || e.interfaces != nil {
+		n.enricher = e
+	}
+	return nil
 }
 
 func (n *NetFlow) Start(acc telegraf.Accumulator) error {
@@ -123,6 +174,9 @@ func (n *NetFlow) Stop() {
 		_ = n.conn.Close()
 	}
 	n.wg.Wait()
+	if n.enricher != nil && n.enricher.interfaces != nil {
+		n.enricher.interfaces.wait()
+	}
 }
 
 func (n *NetFlow) read(acc telegraf.Accumulator) {
@@ -148,6 +202,9 @@ func (n *NetFlow) read(acc telegraf.Accumulator) {
 			acc.AddError(errWithData)
 			continue
 		}
+		if n.enricher != nil {
+			n.enricher.enrich(metrics)
+		}
 		for _, m := range metrics {
 			acc.AddMetric(m)
 		}
@@ -157,6 +214,9 @@ func (n *NetFlow) read(acc telegraf.Accumulator) {
 // Register the plugin
 func init() {
 	inputs.Add("netflow", func() telegraf.Input {
-		return &NetFlow{}
+		return &NetFlow{
+			InterfaceNamesTTL: config.Duration(8 * time.Hour),
+			SNMP:              *snmp.DefaultClientConfig(),
+		}
 	})
 }
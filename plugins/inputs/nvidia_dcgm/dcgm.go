@@ -0,0 +1,119 @@
+package nvidia_dcgm
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// dcgmField is a DCGM field queried via "dcgmi dmon" and the name of the
+// corresponding telegraf field, see dcgm_fields.h for the identifiers
+type dcgmField struct {
+	id        int
+	name      string
+	profiling bool
+}
+
+var dcgmFields = []dcgmField{
+	{id: 150, name: "temperature"},
+	{id: 155, name: "power"},
+	{id: 203, name: "gpu_utilization"},
+	{id: 204, name: "memory_copy_utilization"},
+	{id: 250, name: "fb_total"},
+	{id: 251, name: "fb_free"},
+	{id: 252, name: "fb_used"},
+	{id: 310, name: "ecc_sbe_volatile"},
+	{id: 311, name: "ecc_dbe_volatile"},
+	{id: 312, name: "ecc_sbe_aggregate"},
+	{id: 313, name: "ecc_dbe_aggregate"},
+	{id: 1002, name: "sm_active", profiling: true},
+	{id: 1003, name: "sm_occupancy", profiling: true},
+	{id: 1004, name: "tensor_active", profiling: true},
+	{id: 1005, name: "dram_active", profiling: true},
+	{id: 1009, name: "pcie_tx_bytes", profiling: true},
+	{id: 1010, name: "pcie_rx_bytes", profiling: true},
+	{id: 1011, name: "nvlink_tx_bytes", profiling: true},
+	{id: 1012, name: "nvlink_rx_bytes", profiling: true},
+}
+
+// Entity types reported by "dcgmi dmon" and the corresponding tag values
+var dcgmEntities = map[string]string{
+	"GPU":    "gpu",
+	"GPU-I":  "gpu_instance",
+	"GPU-CI": "compute_instance",
+}
+
+func (n *NvidiaDCGM) dmonArgs() []string {
+	ids := make([]string, 0, len(n.fields))
+	for _, f := range n.fields {
+		ids = append(ids, strconv.Itoa(f.id))
+	}
+
+	args := []string{"dmon", "-e", strings.Join(ids, ","), "-c", "1"}
+	if n.Host != "" {
+		args = append(args, "--host", n.Host)
+	}
+	if len(n.Entities) > 0 {
+		args = append(args, "-i", strings.Join(n.Entities, ","))
+	}
+	return args
+}
+
+// parseDmon parses the output of "dcgmi dmon" containing one line per entity
+// with the values of the queried fields in the requested order, e.g.
+//
+//	#Entity   TMPTR  POWER
+//	ID
+//	GPU 0     34     61.230
+func (n *NvidiaDCGM) parseDmon(acc telegraf.Accumulator, data []byte) error {
+	now := time.Now()
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		columns := strings.Fields(scanner.Text())
+		if len(columns) < 2 || strings.HasPrefix(columns[0], "#") {
+			continue
+		}
+		entity, found := dcgmEntities[columns[0]]
+		if !found {
+			continue
+		}
+		if _, err := strconv.ParseUint(columns[1], 10, 32); err != nil {
+			continue
+		}
+		values := columns[2:]
+		if len(values) != len(n.fields) {
+			return fmt.Errorf("expected %d values but got %d for %s %s", len(n.fields), len(values), columns[0], columns[1])
+		}
+
+		fields := make(map[string]interface{}, len(values))
+		for i, raw := range values {
+			if raw == "N/A" {
+				continue
+			}
+			if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				fields[n.fields[i].name] = v
+			} else if v, err := strconv.ParseFloat(raw, 64); err == nil {
+				fields[n.fields[i].name] = v
+			} else {
+				n.Log.Debugf("Cannot parse value %q of field %q", raw, n.fields[i].name)
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		tags := map[string]string{
+			"entity":    entity,
+			"entity_id": columns[1],
+		}
+		acc.AddFields("nvidia_dcgm", fields, tags, now)
+	}
+
+	return scanner.Err()
+}
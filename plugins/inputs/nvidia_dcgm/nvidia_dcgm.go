@@ -0,0 +1,165 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package nvidia_dcgm
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type NvidiaDCGM struct {
+	Backend       string          `toml:"backend"`
+	DCGMIPath     string          `toml:"dcgmi_path"`
+	Host          string          `toml:"host"`
+	Entities      []string        `toml:"entities"`
+	Profiling     bool            `toml:"profiling"`
+	NvidiaSMIPath string          `toml:"nvidia_smi_path"`
+	Processes     bool            `toml:"processes"`
+	Timeout       config.Duration `toml:"timeout"`
+	Log           telegraf.Logger `toml:"-"`
+
+	backend string
+	fields  []dcgmField
+}
+
+func (*NvidiaDCGM) SampleConfig() string {
+	return sampleConfig
+}
+
+func (n *NvidiaDCGM) Init() error {
+	switch n.Backend {
+	case "":
+		n.Backend = "auto"
+	case "auto", "dcgm", "nvml":
+	default:
+		return fmt.Errorf("invalid backend %q", n.Backend)
+	}
+
+	if len(n.Entities) > 0 && n.Backend == "nvml" {
+		return errors.New("'entities' requires the dcgm backend")
+	}
+
+	n.fields = make([]dcgmField, 0, len(dcgmFields))
+	for _, f := range dcgmFields {
+		if f.profiling && !n.Profiling {
+			continue
+		}
+		n.fields = append(n.fields, f)
+	}
+
+	return nil
+}
+
+func (n *NvidiaDCGM) Start(telegraf.Accumulator) error {
+	// Locate the required binaries, checking the PATH if the configured
+	// binaries do not exist
+	var dcgmiErr, smiErr error
+	n.DCGMIPath, dcgmiErr = locate(n.DCGMIPath, "dcgmi")
+	if n.Backend == "nvml" || n.Processes || n.Backend == "auto" {
+		n.NvidiaSMIPath, smiErr = locate(n.NvidiaSMIPath, "nvidia-smi")
+	}
+
+	switch n.Backend {
+	case "dcgm":
+		if dcgmiErr != nil {
+			return &internal.StartupError{Err: dcgmiErr}
+		}
+		n.backend = "dcgm"
+	case "nvml":
+		if smiErr != nil {
+			return &internal.StartupError{Err: smiErr}
+		}
+		n.backend = "nvml"
+	case "auto":
+		if dcgmiErr == nil {
+			n.backend = "dcgm"
+		} else if smiErr == nil {
+			n.Log.Infof("Cannot find dcgmi, falling back to nvml backend: %v", dcgmiErr)
+			n.backend = "nvml"
+		} else {
+			return &internal.StartupError{Err: fmt.Errorf("neither dcgmi nor nvidia-smi found: %w", errors.Join(dcgmiErr, smiErr))}
+		}
+	}
+
+	if n.Processes && smiErr != nil {
+		return &internal.StartupError{Err: fmt.Errorf("gathering processes requires nvidia-smi: %w", smiErr)}
+	}
+
+	return nil
+}
+
+func (*NvidiaDCGM) Stop() {}
+
+func (n *NvidiaDCGM) Gather(acc telegraf.Accumulator) error {
+	switch n.backend {
+	case "dcgm":
+		out, err := n.run(n.DCGMIPath, n.dmonArgs()...)
+		if err != nil {
+			return err
+		}
+		if err := n.parseDmon(acc, out); err != nil {
+			return fmt.Errorf("parsing DCGM output failed: %w", err)
+		}
+	case "nvml":
+		out, err := n.run(n.NvidiaSMIPath, nvmlGPUArgs()...)
+		if err != nil {
+			return err
+		}
+		if err := n.parseGPUs(acc, out); err != nil {
+			return fmt.Errorf("parsing GPU query output failed: %w", err)
+		}
+	}
+
+	if n.Processes {
+		out, err := n.run(n.NvidiaSMIPath, nvmlProcessArgs...)
+		if err != nil {
+			return err
+		}
+		if err := n.parseProcesses(acc, out); err != nil {
+			return fmt.Errorf("parsing process query output failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (n *NvidiaDCGM) run(bin string, args ...string) ([]byte, error) {
+	out, err := internal.StdOutputTimeout(exec.Command(bin, args...), time.Duration(n.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("calling %q failed: %w", bin, err)
+	}
+	return out, nil
+}
+
+// locate returns the given binary if it exists or searches for the binary
+// with the given name on the PATH
+func locate(path, name string) (string, error) {
+	if path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return exec.LookPath(name)
+}
+
+func init() {
+	inputs.Add("nvidia_dcgm", func() telegraf.Input {
+		return &NvidiaDCGM{
+			DCGMIPath:     "/usr/bin/dcgmi",
+			NvidiaSMIPath: "/usr/bin/nvidia-smi",
+			Profiling:     true,
+			Timeout:       config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package nvidia_dcgm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &NvidiaDCGM{Backend: "foo"}
+	require.ErrorContains(t, plugin.Init(), `invalid backend "foo"`)
+
+	plugin = &NvidiaDCGM{Backend: "nvml", Entities: []string{"i:0"}}
+	require.ErrorContains(t, plugin.Init(), "'entities' requires the dcgm backend")
+}
+
+func TestDmonArgs(t *testing.T) {
+	plugin := &NvidiaDCGM{
+		Host:     "gpu-node:5555",
+		Entities: []string{"0", "i:1"},
+	}
+	require.NoError(t, plugin.Init())
+	require.Equal(t,
+		[]string{"dmon", "-e", "150,155,203,204,250,251,252,310,311,312,313", "-c", "1", "--host", "gpu-node:5555", "-i", "0,i:1"},
+		plugin.dmonArgs(),
+	)
+}
+
+func TestParseDmon(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "dmon.txt"))
+	require.NoError(t, err)
+
+	plugin := &NvidiaDCGM{Profiling: true, Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.parseDmon(&acc, data))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nvidia_dcgm",
+			map[string]string{"entity": "gpu", "entity_id": "0"},
+			map[string]interface{}{
+				"temperature":             int64(34),
+				"power":                   61.23,
+				"gpu_utilization":         int64(45),
+				"memory_copy_utilization": int64(12),
+				"fb_total":                int64(40960),
+				"fb_free":                 int64(39936),
+				"fb_used":                 int64(1024),
+				"ecc_sbe_volatile":        int64(0),
+				"ecc_dbe_volatile":        int64(0),
+				"ecc_sbe_aggregate":       int64(3),
+				"ecc_dbe_aggregate":       int64(0),
+				"sm_active":               0.512,
+				"sm_occupancy":            0.231,
+				"tensor_active":           0.104,
+				"dram_active":             0.387,
+				"pcie_tx_bytes":           int64(12345678),
+				"pcie_rx_bytes":           int64(23456789),
+				"nvlink_tx_bytes":         int64(1073741824),
+				"nvlink_rx_bytes":         int64(536870912),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nvidia_dcgm",
+			map[string]string{"entity": "gpu", "entity_id": "1"},
+			map[string]interface{}{
+				"temperature":             int64(36),
+				"power":                   212.5,
+				"gpu_utilization":         int64(98),
+				"memory_copy_utilization": int64(65),
+				"fb_total":                int64(40960),
+				"fb_free":                 int64(2048),
+				"fb_used":                 int64(38912),
+				"sm_active":               0.953,
+				"sm_occupancy":            0.601,
+				"tensor_active":           0.712,
+				"dram_active":             0.802,
+				"pcie_tx_bytes":           int64(34567890),
+				"pcie_rx_bytes":           int64(45678901),
+				"nvlink_tx_bytes":         int64(2147483648),
+				"nvlink_rx_bytes":         int64(1073741824),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nvidia_dcgm",
+			map[string]string{"entity": "gpu_instance", "entity_id": "7"},
+			map[string]interface{}{
+				"fb_total":      int64(9728),
+				"fb_free":       int64(9216),
+				"fb_used":       int64(512),
+				"sm_active":     0.25,
+				"sm_occupancy":  0.125,
+				"tensor_active": 0.0,
+				"dram_active":   0.1,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestParseDmonMismatch(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "dmon.txt"))
+	require.NoError(t, err)
+
+	// Without profiling less fields are expected
+	plugin := &NvidiaDCGM{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.parseDmon(&acc, data), "expected 11 values but got 19 for GPU 0")
+}
+
+func TestParseGPUs(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "query_gpu.csv"))
+	require.NoError(t, err)
+
+	plugin := &NvidiaDCGM{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.parseGPUs(&acc, data))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nvidia_dcgm",
+			map[string]string{
+				"entity":    "gpu",
+				"entity_id": "0",
+				"uuid":      "GPU-b1a2c3d4-e5f6-7890-abcd-ef1234567890",
+				"name":      "NVIDIA A100-SXM4-40GB",
+			},
+			map[string]interface{}{
+				"temperature":             int64(34),
+				"power":                   61.23,
+				"gpu_utilization":         int64(45),
+				"memory_copy_utilization": int64(12),
+				"fb_total":                int64(40960),
+				"fb_free":                 int64(39936),
+				"fb_used":                 int64(1024),
+				"ecc_sbe_volatile":        int64(0),
+				"ecc_dbe_volatile":        int64(0),
+				"ecc_sbe_aggregate":       int64(3),
+				"ecc_dbe_aggregate":       int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nvidia_dcgm",
+			map[string]string{
+				"entity":    "gpu",
+				"entity_id": "1",
+				"uuid":      "GPU-c2b3d4e5-f6a7-8901-bcde-f12345678901",
+				"name":      "Tesla T4",
+			},
+			map[string]interface{}{
+				"temperature":             int64(41),
+				"power":                   27.5,
+				"gpu_utilization":         int64(0),
+				"memory_copy_utilization": int64(0),
+				"fb_total":                int64(15360),
+				"fb_free":                 int64(15101),
+				"fb_used":                 int64(259),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestParseProcesses(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "query_apps.csv"))
+	require.NoError(t, err)
+
+	plugin := &NvidiaDCGM{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.parseProcesses(&acc, data))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nvidia_dcgm_process",
+			map[string]string{
+				"uuid":         "GPU-b1a2c3d4-e5f6-7890-abcd-ef1234567890",
+				"process_name": "/usr/bin/python3",
+			},
+			map[string]interface{}{
+				"pid":         int64(4242),
+				"used_memory": int64(1016),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nvidia_dcgm_process",
+			map[string]string{
+				"uuid":         "GPU-c2b3d4e5-f6a7-8901-bcde-f12345678901",
+				"process_name": "tritonserver",
+			},
+			map[string]interface{}{
+				"pid": int64(4343),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// No processes running
+	acc.ClearMetrics()
+	require.NoError(t, plugin.parseProcesses(&acc, nil))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -0,0 +1,144 @@
+package nvidia_dcgm
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Properties queried from NVML via nvidia-smi and the corresponding field
+// names, the first three properties are used as tags
+var nvmlGPUProperties = []struct {
+	query string
+	name  string
+}{
+	{query: "index", name: "entity_id"},
+	{query: "uuid", name: "uuid"},
+	{query: "name", name: "name"},
+	{query: "temperature.gpu", name: "temperature"},
+	{query: "power.draw", name: "power"},
+	{query: "utilization.gpu", name: "gpu_utilization"},
+	{query: "utilization.memory", name: "memory_copy_utilization"},
+	{query: "memory.total", name: "fb_total"},
+	{query: "memory.free", name: "fb_free"},
+	{query: "memory.used", name: "fb_used"},
+	{query: "ecc.errors.corrected.volatile.total", name: "ecc_sbe_volatile"},
+	{query: "ecc.errors.uncorrected.volatile.total", name: "ecc_dbe_volatile"},
+	{query: "ecc.errors.corrected.aggregate.total", name: "ecc_sbe_aggregate"},
+	{query: "ecc.errors.uncorrected.aggregate.total", name: "ecc_dbe_aggregate"},
+}
+
+var nvmlProcessArgs = []string{"--query-compute-apps=gpu_uuid,pid,process_name,used_memory", "--format=csv,noheader,nounits"}
+
+func nvmlGPUArgs() []string {
+	queries := make([]string, 0, len(nvmlGPUProperties))
+	for _, p := range nvmlGPUProperties {
+		queries = append(queries, p.query)
+	}
+	return []string{"--query-gpu=" + strings.Join(queries, ","), "--format=csv,noheader,nounits"}
+}
+
+// parseGPUs parses the CSV output of the GPU query
+func (n *NvidiaDCGM) parseGPUs(acc telegraf.Accumulator, data []byte) error {
+	now := time.Now()
+
+	records, err := readCSV(data, len(nvmlGPUProperties))
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		tags := map[string]string{"entity": "gpu"}
+		fields := make(map[string]interface{}, len(record))
+		for i, raw := range record {
+			name := nvmlGPUProperties[i].name
+			if i < 3 {
+				tags[name] = raw
+				continue
+			}
+			if v, ok := n.parseValue(name, raw); ok {
+				fields[name] = v
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		acc.AddFields("nvidia_dcgm", fields, tags, now)
+	}
+
+	return nil
+}
+
+// parseProcesses parses the CSV output of the compute application query
+func (n *NvidiaDCGM) parseProcesses(acc telegraf.Accumulator, data []byte) error {
+	now := time.Now()
+
+	records, err := readCSV(data, 4)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		pid, err := strconv.ParseInt(record[1], 10, 32)
+		if err != nil {
+			n.Log.Debugf("Cannot parse pid %q: %v", record[1], err)
+			continue
+		}
+		tags := map[string]string{
+			"uuid":         record[0],
+			"process_name": record[2],
+		}
+		fields := map[string]interface{}{
+			"pid": pid,
+		}
+		if v, ok := n.parseValue("used_memory", record[3]); ok {
+			fields["used_memory"] = v
+		}
+		acc.AddFields("nvidia_dcgm_process", fields, tags, now)
+	}
+
+	return nil
+}
+
+// parseValue converts a value to an integer or float, values not supported
+// by the device are reported as e.g. "[N/A]" or "[Not Supported]"
+func (n *NvidiaDCGM) parseValue(name, raw string) (interface{}, bool) {
+	if raw == "" || strings.HasPrefix(raw, "[") {
+		return nil, false
+	}
+	if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return v, true
+	}
+	if v, err := strconv.ParseFloat(raw, 64); err == nil {
+		return v, true
+	}
+	n.Log.Debugf("Cannot parse value %q of field %q", raw, name)
+	return nil, false
+}
+
+func readCSV(data []byte, columns int) ([][]string, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = columns
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV failed: %w", err)
+		}
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
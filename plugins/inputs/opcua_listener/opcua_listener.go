@@ -4,6 +4,7 @@ package opcua_listener
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
 	"time"
 
@@ -18,6 +19,8 @@ type OpcUaListener struct {
 	subscribeClientConfig
 	client *subscribeClient
 	Log    telegraf.Logger `toml:"-"`
+
+	forwarding bool
 }
 
 //go:embed sample.conf
@@ -36,6 +39,14 @@ func (o *OpcUaListener) Init() (err error) {
 	default:
 		return fmt.Errorf("unknown setting %q for 'connect_fail_behavior'", o.ConnectFailBehavior)
 	}
+	if o.KeepaliveInterval < 0 {
+		return errors.New("'keepalive_interval' cannot be negative")
+	}
+	if o.DataChangeFilter != nil {
+		if err := checkDataChangeFilterParameters(o.DataChangeFilter); err != nil {
+			return fmt.Errorf("invalid 'data_change_filter': %w", err)
+		}
+	}
 	o.client, err = o.subscribeClientConfig.createSubscribeClient(o.Log)
 	return err
 }
@@ -45,7 +56,18 @@ func (o *OpcUaListener) Start(acc telegraf.Accumulator) error {
 }
 
 func (o *OpcUaListener) Gather(acc telegraf.Accumulator) error {
-	if o.client.State() == opcua.Connected || o.subscribeClientConfig.ConnectFailBehavior == "ignore" {
+	if o.subscribeClientConfig.ConnectFailBehavior == "ignore" {
+		return nil
+	}
+
+	if o.client.needsReconnect() {
+		o.Log.Infof("Reconnecting to %s and resubscribing", o.Endpoint)
+		return o.connect(acc)
+	}
+
+	switch o.client.State() {
+	case opcua.Connected, opcua.Connecting, opcua.Reconnecting:
+		// The client restores the subscription itself while reconnecting
 		return nil
 	}
 	return o.connect(acc)
@@ -69,6 +91,13 @@ func (o *OpcUaListener) connect(acc telegraf.Accumulator) error {
 		return err
 	}
 
+	// The metrics channel is the same for all connections, so only forward
+	// the metrics once
+	if ch == nil || o.forwarding {
+		return nil
+	}
+	o.forwarding = true
+
 	go func() {
 		for {
 			m, ok := <-ch
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/docker/go-connections/nat"
+	gopcua "github.com/gopcua/opcua"
 	"github.com/gopcua/opcua/ua"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -1293,3 +1294,131 @@ func TestSubscribeClientConfigValidEventStreamingDefaultNodeParams(t *testing.T)
 	require.Equal(t, "i", o.IdentifierType)
 	require.Equal(t, "3", o.Namespace)
 }
+
+func TestSubscribeClientConfigDefaultDataChangeFilter(t *testing.T) {
+	deadbandValue := 0.5
+	nodeDeadbandValue := 10.0
+	subscribeConfig := subscribeClientConfig{
+		InputClientConfig: input.InputClientConfig{
+			OpcUAClientConfig: opcua.OpcUAClientConfig{
+				Endpoint:       "opc.tcp://localhost:4840",
+				SecurityPolicy: "None",
+				SecurityMode:   "None",
+				AuthMethod:     "Anonymous",
+				ConnectTimeout: config.Duration(10 * time.Second),
+				RequestTimeout: config.Duration(1 * time.Second),
+			},
+			MetricName: "testing",
+			RootNodes: []input.NodeSettings{
+				{
+					FieldName:      "foo",
+					Namespace:      "3",
+					Identifier:     "1",
+					IdentifierType: "i",
+				},
+				{
+					FieldName:      "bar",
+					Namespace:      "3",
+					Identifier:     "2",
+					IdentifierType: "i",
+					MonitoringParams: input.MonitoringParameters{
+						DataChangeFilter: &input.DataChangeFilter{
+							Trigger:       "StatusValue",
+							DeadbandType:  "Percent",
+							DeadbandValue: &nodeDeadbandValue,
+						},
+					},
+				},
+			},
+		},
+		DataChangeFilter: &input.DataChangeFilter{
+			Trigger:       "StatusValue",
+			DeadbandType:  "Absolute",
+			DeadbandValue: &deadbandValue,
+		},
+	}
+
+	subClient, err := subscribeConfig.createSubscribeClient(testutil.Logger{})
+	require.NoError(t, err)
+	require.Len(t, subClient.monitoredItemsReqs, 2)
+
+	// The node without filter uses the plugin-wide filter
+	require.Equal(t, ua.NewExtensionObject(
+		&ua.DataChangeFilter{
+			Trigger:       ua.DataChangeTriggerStatusValue,
+			DeadbandType:  uint32(ua.DeadbandTypeAbsolute),
+			DeadbandValue: deadbandValue,
+		},
+	), subClient.monitoredItemsReqs[0].RequestedParameters.Filter)
+
+	// The node's filter takes precedence
+	require.Equal(t, ua.NewExtensionObject(
+		&ua.DataChangeFilter{
+			Trigger:       ua.DataChangeTriggerStatusValue,
+			DeadbandType:  uint32(ua.DeadbandTypePercent),
+			DeadbandValue: nodeDeadbandValue,
+		},
+	), subClient.monitoredItemsReqs[1].RequestedParameters.Filter)
+}
+
+func TestInitPluginInvalidSubscriptionSettings(t *testing.T) {
+	deadbandValue := -1.0
+	plugin := &OpcUaListener{
+		subscribeClientConfig: subscribeClientConfig{
+			InputClientConfig: input.InputClientConfig{
+				OpcUAClientConfig: opcua.OpcUAClientConfig{
+					Endpoint:       "opc.tcp://localhost:4840",
+					SecurityPolicy: "None",
+					SecurityMode:   "None",
+					AuthMethod:     "Anonymous",
+				},
+				MetricName: "testing",
+			},
+			KeepaliveInterval: config.Duration(-time.Second),
+		},
+		Log: testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "'keepalive_interval' cannot be negative")
+
+	plugin.KeepaliveInterval = config.Duration(time.Second)
+	plugin.DataChangeFilter = &input.DataChangeFilter{
+		Trigger:       "Status",
+		DeadbandType:  "Absolute",
+		DeadbandValue: &deadbandValue,
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid 'data_change_filter': negative deadband_value not supported")
+}
+
+func TestSubscribeClientKeepaliveFailure(t *testing.T) {
+	subscribeConfig := subscribeClientConfig{
+		InputClientConfig: input.InputClientConfig{
+			OpcUAClientConfig: opcua.OpcUAClientConfig{
+				Endpoint:       "opc.tcp://127.0.0.1:1",
+				SecurityPolicy: "None",
+				SecurityMode:   "None",
+				AuthMethod:     "Anonymous",
+				RequestTimeout: config.Duration(time.Second),
+			},
+			MetricName: "testing",
+			RootNodes: []input.NodeSettings{
+				{
+					FieldName:      "foo",
+					Namespace:      "3",
+					Identifier:     "1",
+					IdentifierType: "i",
+				},
+			},
+		},
+		KeepaliveInterval: config.Duration(10 * time.Millisecond),
+	}
+	subClient, err := subscribeConfig.createSubscribeClient(testutil.Logger{})
+	require.NoError(t, err)
+	require.False(t, subClient.needsReconnect())
+
+	// Reading from a client without session fails and marks the
+	// subscription for reconnection
+	client, err := gopcua.NewClient(subscribeConfig.Endpoint)
+	require.NoError(t, err)
+	subClient.keepalive(t.Context(), client, 10*time.Millisecond)
+	require.True(t, subClient.needsReconnect())
+}
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync/atomic"
 	"time"
 
 	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/id"
 	"github.com/gopcua/opcua/ua"
 
 	"github.com/influxdata/telegraf"
@@ -18,8 +20,10 @@ import (
 
 type subscribeClientConfig struct {
 	input.InputClientConfig
-	SubscriptionInterval config.Duration `toml:"subscription_interval"`
-	ConnectFailBehavior  string          `toml:"connect_fail_behavior"`
+	SubscriptionInterval config.Duration         `toml:"subscription_interval"`
+	ConnectFailBehavior  string                  `toml:"connect_fail_behavior"`
+	KeepaliveInterval    config.Duration         `toml:"keepalive_interval"`
+	DataChangeFilter     *input.DataChangeFilter `toml:"data_change_filter"`
 }
 
 type subscribeClient struct {
@@ -34,6 +38,10 @@ type subscribeClient struct {
 
 	ctx    context.Context
 	cancel context.CancelFunc
+
+	processing      bool
+	keepaliveCancel context.CancelFunc
+	keepaliveFailed atomic.Bool
 }
 
 func checkDataChangeFilterParameters(params *input.DataChangeFilter) error {
@@ -116,7 +124,12 @@ func (sc *subscribeClientConfig) createSubscribeClient(log telegraf.Logger) (*su
 	for i, nodeID := range client.NodeIDs {
 		// The node id index (i) is used as the handle for the monitored item
 		req := opcua.NewMonitoredItemCreateRequestWithDefaults(nodeID, ua.AttributeIDValue, uint32(i))
-		if err := assignConfigValuesToRequest(req, &client.NodeMetricMapping[i].Tag.MonitoringParams); err != nil {
+		// Use the plugin-wide data change filter unless the node defines one
+		params := client.NodeMetricMapping[i].Tag.MonitoringParams
+		if params.DataChangeFilter == nil {
+			params.DataChangeFilter = sc.DataChangeFilter
+		}
+		if err := assignConfigValuesToRequest(req, &params); err != nil {
 			return nil, err
 		}
 		subClient.monitoredItemsReqs[i] = req
@@ -163,6 +176,9 @@ func (o *subscribeClient) connect() error {
 
 func (o *subscribeClient) stop(ctx context.Context) <-chan struct{} {
 	o.Log.Debugf("Stopping OPC subscription...")
+	if o.keepaliveCancel != nil {
+		o.keepaliveCancel()
+	}
 	if o.State() != opcuaclient.Connected {
 		return nil
 	}
@@ -225,11 +241,94 @@ func (o *subscribeClient) startMonitoring(ctx context.Context) (<-chan telegraf.
 		}
 	}
 
-	go o.processReceivedNotifications()
+	// The notifications of all subscriptions, including the ones created
+	// when reconnecting, arrive on the same channel
+	if !o.processing {
+		o.processing = true
+		go o.processReceivedNotifications()
+	}
+	o.startKeepalive()
 
 	return o.metrics, nil
 }
 
+// needsReconnect checks if the session was found to be dead by the keepalive
+func (o *subscribeClient) needsReconnect() bool {
+	return o.keepaliveFailed.Load()
+}
+
+func (o *subscribeClient) startKeepalive() {
+	if o.keepaliveCancel != nil {
+		o.keepaliveCancel()
+	}
+	o.keepaliveFailed.Store(false)
+
+	interval := time.Duration(o.Config.KeepaliveInterval)
+	if interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(o.ctx)
+	o.keepaliveCancel = cancel
+	go o.keepalive(ctx, o.Client, interval)
+}
+
+// keepalive periodically reads the server state to keep the session alive
+// and to detect broken connections not reporting any data changes. The client
+// is passed in as it is replaced when reconnecting.
+func (o *subscribeClient) keepalive(ctx context.Context, client *opcua.Client, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	req := &ua.ReadRequest{
+		NodesToRead: []*ua.ReadValueID{
+			{
+				NodeID:      ua.NewNumericNodeID(0, id.Server_ServerStatus_State),
+				AttributeID: ua.AttributeIDValue,
+			},
+		},
+		TimestampsToReturn: ua.TimestampsToReturnNeither,
+	}
+
+	timeout := time.Duration(o.Config.RequestTimeout)
+	if timeout <= 0 {
+		timeout = interval
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// The client restores the session itself while reconnecting
+		if client.State() == opcua.Reconnecting {
+			continue
+		}
+
+		rctx, cancel := context.WithTimeout(ctx, timeout)
+		resp, err := client.Read(rctx, req)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil && len(resp.Results) > 0 && resp.Results[0].Status != ua.StatusOK {
+			err = resp.Results[0].Status
+		}
+		if err == nil && len(resp.Results) > 0 {
+			if state, ok := resp.Results[0].Value.Value().(int32); ok && ua.ServerState(state) != ua.ServerStateRunning {
+				err = fmt.Errorf("server in state %s", ua.ServerState(state))
+			}
+		}
+		if err != nil {
+			o.Log.Warnf("Keepalive for %s failed, reconnecting at next interval: %v", o.Config.Endpoint, err)
+			o.keepaliveFailed.Store(true)
+			return
+		}
+	}
+}
+
 func (o *subscribeClient) processReceivedNotifications() {
 	for {
 		select {
@@ -248,7 +347,7 @@ func (o *subscribeClient) processReceivedNotifications() {
 			}
 			if res.Value == nil {
 				o.Log.Error("Received nil notification")
-				return
+				continue
 			}
 
 			switch notif := res.Value.(type) {
@@ -6,7 +6,6 @@ import (
 	"database/sql"
 	_ "embed"
 	"fmt"
-	"strconv"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/common/postgresql"
@@ -62,11 +61,11 @@ func (p *PgBouncer) Gather(acc telegraf.Accumulator) error {
 	for _, cmd := range p.ShowCommands {
 		switch cmd {
 		case "stats":
-			if err := p.showStats(acc); err != nil {
+			if err := p.service.GatherPgBouncerStats(acc); err != nil {
 				return err
 			}
 		case "pools":
-			if err := p.showPools(acc); err != nil {
+			if err := p.service.GatherPgBouncerPools(acc); err != nil {
 				return err
 			}
 		case "lists":
@@ -122,100 +121,6 @@ func (p *PgBouncer) accRow(row *sql.Rows, columns []string) (map[string]string,
 	return map[string]string{"server": p.service.SanitizedAddress, "db": dbname.String()}, columnMap, nil
 }
 
-func (p *PgBouncer) showStats(acc telegraf.Accumulator) error {
-	// STATS
-	rows, err := p.service.DB.Query(`SHOW STATS`)
-	if err != nil {
-		return fmt.Errorf("execution error 'show stats': %w", err)
-	}
-
-	defer rows.Close()
-
-	// grab the column information from the result
-	columns, err := rows.Columns()
-	if err != nil {
-		return fmt.Errorf("don't get column names 'show stats': %w", err)
-	}
-
-	for rows.Next() {
-		tags, columnMap, err := p.accRow(rows, columns)
-		if err != nil {
-			return err
-		}
-
-		fields := make(map[string]interface{})
-		for col, val := range columnMap {
-			_, ignore := ignoredColumns[col]
-			if ignore {
-				continue
-			}
-
-			switch v := (*val).(type) {
-			case int64:
-				// Integer fields are returned in pgbouncer 1.5 through 1.9
-				fields[col] = v
-			case string:
-				// Integer fields are returned in pgbouncer 1.12
-				integer, err := strconv.ParseInt(v, 10, 64)
-				if err != nil {
-					return fmt.Errorf("couldn't convert metrics 'show stats': %w", err)
-				}
-
-				fields[col] = integer
-			}
-		}
-		acc.AddFields("pgbouncer", fields, tags)
-	}
-
-	return rows.Err()
-}
-
-func (p *PgBouncer) showPools(acc telegraf.Accumulator) error {
-	// POOLS
-	poolRows, err := p.service.DB.Query(`SHOW POOLS`)
-	if err != nil {
-		return fmt.Errorf("execution error 'show pools': %w", err)
-	}
-
-	defer poolRows.Close()
-
-	// grab the column information from the result
-	columns, err := poolRows.Columns()
-	if err != nil {
-		return fmt.Errorf("don't get column names 'show pools': %w", err)
-	}
-
-	for poolRows.Next() {
-		tags, columnMap, err := p.accRow(poolRows, columns)
-		if err != nil {
-			return err
-		}
-
-		if user, ok := columnMap["user"]; ok {
-			if s, ok := (*user).(string); ok && s != "" {
-				tags["user"] = s
-			}
-		}
-
-		if poolMode, ok := columnMap["pool_mode"]; ok {
-			if s, ok := (*poolMode).(string); ok && s != "" {
-				tags["pool_mode"] = s
-			}
-		}
-
-		fields := make(map[string]interface{})
-		for col, val := range columnMap {
-			_, ignore := ignoredColumns[col]
-			if !ignore {
-				fields[col] = *val
-			}
-		}
-		acc.AddFields("pgbouncer_pools", fields, tags)
-	}
-
-	return poolRows.Err()
-}
-
 func (p *PgBouncer) showLists(acc telegraf.Accumulator) error {
 	// LISTS
 	rows, err := p.service.DB.Query(`SHOW LISTS`)
@@ -0,0 +1,617 @@
+package ping
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	protocolICMP     = 1
+	protocolIPv6ICMP = 58
+)
+
+// icmpConn is the socket used by the engine to send and receive ICMP
+// messages of one address family
+type icmpConn interface {
+	readFrom(b []byte) (n, ttl int, peer net.Addr, err error)
+	writeTo(b []byte, dst net.Addr) (int, error)
+	close() error
+}
+
+// engine continuously pings all targets with their individual intervals
+// using a single socket per address family and keeps the results of the
+// latest probes of each target in a sliding window
+type engine struct {
+	network      string
+	source       string
+	timeout      time.Duration
+	size         int
+	dscp         int
+	window       int
+	log          telegraf.Logger
+	conn4        icmpConn
+	conn6        icmpConn
+	unprivileged bool
+
+	id      int
+	targets []*engineTarget
+	queue   targetQueue
+
+	// Probes waiting for a reply identified by address and sequence number
+	// and all probes in order of sending for detecting timeouts
+	pending     map[probeKey]*probe
+	outstanding []*probe
+	seqs        map[string]uint16
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	sync.Mutex
+}
+
+type engineTarget struct {
+	url      string
+	interval time.Duration
+	next     time.Time
+	index    int
+
+	// Fields below are protected by the engine lock
+	addr       net.IP
+	resolving  bool
+	resolveErr error
+	results    []result
+	pos        int
+	ttl        int
+}
+
+type probeKey struct {
+	addr string
+	seq  uint16
+}
+
+type probe struct {
+	key    probeKey
+	target *engineTarget
+	sent   time.Time
+	done   bool
+}
+
+// result of a single probe, a zero round-trip time denotes a lost probe
+type result struct {
+	rtt time.Duration
+}
+
+func newEngine(p *Ping) *engine {
+	e := &engine{
+		source:  p.sourceAddress,
+		timeout: p.calcTimeout,
+		size:    defaultPingDataBytesSize,
+		dscp:    p.DSCP,
+		window:  p.WindowSize,
+		log:     p.Log,
+		id:      rand.Intn(math.MaxUint16),
+		pending: make(map[probeKey]*probe),
+		seqs:    make(map[string]uint16),
+	}
+	if p.Size != nil {
+		e.size = *p.Size
+	}
+
+	switch {
+	case p.IPv4 && !p.IPv6:
+		e.network = "ip4"
+	case p.IPv6 && !p.IPv4:
+		e.network = "ip6"
+	default:
+		e.network = "ip"
+	}
+
+	for _, u := range p.Urls {
+		e.addTarget(u, p.calcInterval)
+	}
+	for _, t := range p.Targets {
+		interval := time.Duration(t.Interval)
+		if interval <= 0 {
+			interval = p.calcInterval
+		}
+		e.addTarget(t.URL, interval)
+	}
+
+	return e
+}
+
+func (e *engine) addTarget(u string, interval time.Duration) {
+	e.targets = append(e.targets, &engineTarget{
+		url:      u,
+		interval: interval,
+		results:  make([]result, 0, e.window),
+		ttl:      -1,
+	})
+}
+
+// listen opens the sockets required for the targets, preferring privileged
+// raw sockets and falling back to unprivileged datagram sockets if raw
+// sockets are not permitted
+func (e *engine) listen() error {
+	err := e.listenWith(false)
+	if err == nil {
+		return nil
+	}
+	e.log.Debugf("Cannot open privileged ICMP sockets: %v", err)
+
+	if err := e.listenWith(true); err != nil {
+		return fmt.Errorf("opening unprivileged ICMP sockets failed: %w", err)
+	}
+	e.unprivileged = true
+	return nil
+}
+
+func (e *engine) listenWith(unprivileged bool) error {
+	network4, network6 := "ip4:icmp", "ip6:ipv6-icmp"
+	if unprivileged {
+		network4, network6 = "udp4", "udp6"
+	}
+
+	source4, source6 := "0.0.0.0", "::"
+	if ip := net.ParseIP(e.source); ip != nil {
+		if ip.To4() != nil {
+			source4 = e.source
+		} else {
+			source6 = e.source
+		}
+	}
+
+	var err4, err6 error
+	if e.network != "ip6" {
+		e.conn4, err4 = e.open(network4, source4)
+	}
+	if e.network != "ip4" {
+		e.conn6, err6 = e.open(network6, source6)
+	}
+
+	switch {
+	case err4 != nil && e.network == "ip4", err6 != nil && e.network == "ip6", err4 != nil && err6 != nil:
+		e.close()
+		return errors.Join(err4, err6)
+	case err4 != nil:
+		e.log.Debugf("IPv4 not available: %v", err4)
+	case err6 != nil:
+		e.log.Debugf("IPv6 not available: %v", err6)
+	}
+	return nil
+}
+
+func (e *engine) open(network, address string) (icmpConn, error) {
+	c, err := icmp.ListenPacket(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mark the packets with the given differentiated services code point
+	// and request the TTL of received packets
+	tos := e.dscp << 2
+	if p := c.IPv4PacketConn(); p != nil {
+		if tos > 0 {
+			if err := p.SetTOS(tos); err != nil {
+				e.log.Warnf("Setting DSCP failed: %v", err)
+			}
+		}
+		if err := p.SetControlMessage(ipv4.FlagTTL, true); err != nil {
+			e.log.Debugf("Cannot request TTL: %v", err)
+		}
+	}
+	if p := c.IPv6PacketConn(); p != nil {
+		if tos > 0 {
+			if err := p.SetTrafficClass(tos); err != nil {
+				e.log.Warnf("Setting DSCP failed: %v", err)
+			}
+		}
+		if err := p.SetControlMessage(ipv6.FlagHopLimit, true); err != nil {
+			e.log.Debugf("Cannot request hop limit: %v", err)
+		}
+	}
+
+	return &packetConn{c}, nil
+}
+
+func (e *engine) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	// Spread the first probes of the targets across their interval to avoid
+	// sending bursts of packets
+	now := time.Now()
+	e.queue = make(targetQueue, 0, len(e.targets))
+	for _, t := range e.targets {
+		t.resolving = true
+		go e.resolve(t)
+
+		h := fnv.New32a()
+		h.Write([]byte(t.url))
+		t.next = now.Add(time.Duration(float64(t.interval) * float64(h.Sum32()) / math.MaxUint32))
+		heap.Push(&e.queue, t)
+	}
+
+	for _, c := range []icmpConn{e.conn4, e.conn6} {
+		if c == nil {
+			continue
+		}
+		e.wg.Add(1)
+		go func(c icmpConn) {
+			defer e.wg.Done()
+			e.receive(c)
+		}(c)
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.schedule(ctx)
+	}()
+}
+
+func (e *engine) stop() {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.close()
+	e.wg.Wait()
+}
+
+func (e *engine) close() {
+	if e.conn4 != nil {
+		e.conn4.close()
+	}
+	if e.conn6 != nil {
+		e.conn6.close()
+	}
+}
+
+// schedule sends the probes of the targets at their individual intervals
+func (e *engine) schedule(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for len(e.queue) > 0 {
+		t := e.queue[0]
+		if wait := time.Until(t.next); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		now := time.Now()
+		e.send(t, now)
+
+		// Skip intervals missed e.g. due to a suspended system
+		t.next = t.next.Add(t.interval)
+		if t.next.Before(now) {
+			t.next = now.Add(t.interval)
+		}
+		heap.Fix(&e.queue, 0)
+	}
+}
+
+func (e *engine) send(t *engineTarget, now time.Time) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.expire(now)
+
+	if t.addr == nil {
+		if !t.resolving {
+			t.resolving = true
+			go e.resolve(t)
+		}
+		return
+	}
+
+	conn, typ := e.conn4, icmp.Type(ipv4.ICMPTypeEcho)
+	if t.addr.To4() == nil {
+		conn, typ = e.conn6, ipv6.ICMPTypeEchoRequest
+	}
+	if conn == nil {
+		return
+	}
+
+	addr := t.addr.String()
+	seq := e.seqs[addr] + 1
+	e.seqs[addr] = seq
+
+	msg := icmp.Message{
+		Type: typ,
+		Body: &icmp.Echo{ID: e.id, Seq: int(seq), Data: make([]byte, e.size)},
+	}
+	buf, err := msg.Marshal(nil)
+	if err != nil {
+		e.log.Errorf("Creating message for %q failed: %v", t.url, err)
+		return
+	}
+
+	var dst net.Addr = &net.IPAddr{IP: t.addr}
+	if e.unprivileged {
+		dst = &net.UDPAddr{IP: t.addr}
+	}
+	if _, err := conn.writeTo(buf, dst); err != nil {
+		// Unreachable networks and the like are accounted as lost probes
+		e.log.Debugf("Sending to %q failed: %v", t.url, err)
+		t.add(result{}, e.window)
+		return
+	}
+	p := &probe{key: probeKey{addr, seq}, target: t, sent: now}
+	e.pending[p.key] = p
+	e.outstanding = append(e.outstanding, p)
+}
+
+func (e *engine) resolve(t *engineTarget) {
+	addr, err := net.ResolveIPAddr(e.network, t.url)
+
+	e.Lock()
+	defer e.Unlock()
+	t.resolving = false
+	t.resolveErr = err
+	if err == nil {
+		t.addr = addr.IP
+	}
+}
+
+// expire accounts all probes without reply within the timeout as lost, the
+// engine lock must be held
+func (e *engine) expire(now time.Time) {
+	// All probes share the same timeout so they expire in order of sending
+	for len(e.outstanding) > 0 && now.Sub(e.outstanding[0].sent) > e.timeout {
+		p := e.outstanding[0]
+		e.outstanding[0] = nil
+		e.outstanding = e.outstanding[1:]
+		if !p.done {
+			p.target.add(result{}, e.window)
+			delete(e.pending, p.key)
+		}
+	}
+}
+
+// receive reads the echo replies from the given socket until it is closed
+func (e *engine) receive(c icmpConn) {
+	buf := make([]byte, 65536)
+	for {
+		n, ttl, peer, err := c.readFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			e.log.Debugf("Reading reply failed: %v", err)
+			continue
+		}
+		now := time.Now()
+
+		var ip net.IP
+		switch addr := peer.(type) {
+		case *net.IPAddr:
+			ip = addr.IP
+		case *net.UDPAddr:
+			ip = addr.IP
+		default:
+			continue
+		}
+
+		proto := protocolICMP
+		if ip.To4() == nil {
+			proto = protocolIPv6ICMP
+		}
+		msg, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil {
+			e.log.Debugf("Parsing reply from %s failed: %v", ip, err)
+			continue
+		}
+		if msg.Type != ipv4.ICMPTypeEchoReply && msg.Type != ipv6.ICMPTypeEchoReply {
+			continue
+		}
+		echo, ok := msg.Body.(*icmp.Echo)
+		if !ok {
+			continue
+		}
+
+		// The kernel replaces the identifier of unprivileged sockets by the
+		// local port so only replies to privileged sockets can be checked
+		if !e.unprivileged && echo.ID != e.id {
+			continue
+		}
+
+		e.Lock()
+		key := probeKey{ip.String(), uint16(echo.Seq)}
+		if p, found := e.pending[key]; found {
+			delete(e.pending, key)
+			p.done = true
+			p.target.add(result{rtt: max(now.Sub(p.sent), time.Nanosecond)}, e.window)
+			if ttl >= 0 {
+				p.target.ttl = ttl
+			}
+		}
+		e.Unlock()
+	}
+}
+
+func (e *engine) gather(acc telegraf.Accumulator, percentiles []int) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.expire(time.Now())
+
+	for _, t := range e.targets {
+		tags := map[string]string{"url": t.url}
+
+		if t.addr == nil {
+			if t.resolveErr != nil {
+				acc.AddError(fmt.Errorf("host %q: %w", t.url, t.resolveErr))
+				acc.AddFields("ping", map[string]interface{}{"result_code": 1}, tags)
+			}
+			continue
+		}
+
+		// Wait for the first probe to finish
+		if len(t.results) == 0 {
+			continue
+		}
+		acc.AddFields("ping", t.fields(percentiles), tags)
+	}
+}
+
+// add appends the result to the sliding window of the target replacing the
+// oldest result if the window is full
+func (t *engineTarget) add(r result, size int) {
+	if len(t.results) < size {
+		t.results = append(t.results, r)
+		return
+	}
+	t.results[t.pos] = r
+	t.pos = (t.pos + 1) % size
+}
+
+// fields computes the statistics of the results in the sliding window
+func (t *engineTarget) fields(percentiles []int) map[string]interface{} {
+	// Collect the round-trip times in chronological order
+	rtts := make([]time.Duration, 0, len(t.results))
+	for i := range t.results {
+		r := t.results[(t.pos+i)%len(t.results)]
+		if r.rtt > 0 {
+			rtts = append(rtts, r.rtt)
+		}
+	}
+
+	fields := map[string]interface{}{
+		"result_code":         0,
+		"packets_transmitted": len(t.results),
+		"packets_received":    len(rtts),
+		"percent_packet_loss": float64(len(t.results)-len(rtts)) / float64(len(t.results)) * 100,
+	}
+	if len(rtts) == 0 {
+		fields["result_code"] = 1
+		return fields
+	}
+	if t.ttl >= 0 {
+		fields["ttl"] = t.ttl
+	}
+
+	// The jitter is the mean deviation of consecutive round-trip times
+	var sum, jitter float64
+	minimum, maximum := rtts[0], rtts[0]
+	for i, rtt := range rtts {
+		sum += float64(rtt)
+		minimum = min(minimum, rtt)
+		maximum = max(maximum, rtt)
+		if i > 0 {
+			jitter += math.Abs(float64(rtt - rtts[i-1]))
+		}
+	}
+	mean := sum / float64(len(rtts))
+	var variance float64
+	for _, rtt := range rtts {
+		variance += (float64(rtt) - mean) * (float64(rtt) - mean)
+	}
+	variance /= float64(len(rtts))
+	if len(rtts) > 1 {
+		jitter /= float64(len(rtts) - 1)
+	}
+
+	fields["minimum_response_ms"] = float64(minimum) / float64(time.Millisecond)
+	fields["average_response_ms"] = mean / float64(time.Millisecond)
+	fields["maximum_response_ms"] = float64(maximum) / float64(time.Millisecond)
+	fields["standard_deviation_ms"] = math.Sqrt(variance) / float64(time.Millisecond)
+	fields["jitter_ms"] = jitter / float64(time.Millisecond)
+
+	sort.Sort(durationSlice(rtts))
+	for _, perc := range percentiles {
+		value := percentile(rtts, perc)
+		fields[fmt.Sprintf("percentile%v_ms", perc)] = float64(value.Nanoseconds()) / float64(time.Millisecond)
+	}
+
+	return fields
+}
+
+// targetQueue is a priority queue of targets ordered by the time of their
+// next probe
+type targetQueue []*engineTarget
+
+func (q targetQueue) Len() int { return len(q) }
+
+func (q targetQueue) Less(i, j int) bool { return q[i].next.Before(q[j].next) }
+
+func (q targetQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *targetQueue) Push(x interface{}) {
+	t := x.(*engineTarget)
+	t.index = len(*q)
+	*q = append(*q, t)
+}
+
+func (q *targetQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	t := old[n-1]
+	*q = old[:n-1]
+	return t
+}
+
+// packetConn reads ICMP messages including the TTL or hop limit of the
+// received packets
+type packetConn struct {
+	*icmp.PacketConn
+}
+
+func (c *packetConn) readFrom(b []byte) (n, ttl int, peer net.Addr, err error) {
+	ttl = -1
+	if p := c.IPv4PacketConn(); p != nil {
+		var cm *ipv4.ControlMessage
+		n, cm, peer, err = p.ReadFrom(b)
+		if cm != nil {
+			ttl = cm.TTL
+		}
+		return n, ttl, peer, err
+	}
+	if p := c.IPv6PacketConn(); p != nil {
+		var cm *ipv6.ControlMessage
+		n, cm, peer, err = p.ReadFrom(b)
+		if cm != nil {
+			ttl = cm.HopLimit
+		}
+		return n, ttl, peer, err
+	}
+	n, peer, err = c.ReadFrom(b)
+	return n, ttl, peer, err
+}
+
+func (c *packetConn) writeTo(b []byte, dst net.Addr) (int, error) {
+	return c.WriteTo(b, dst)
+}
+
+func (c *packetConn) close() error {
+	return c.Close()
+}
@@ -0,0 +1,208 @@
+package ping
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestEngineInitFail(t *testing.T) {
+	p := &Ping{Method: "engine", Count: 1}
+	require.ErrorContains(t, p.Init(), "'window_size' must be positive")
+
+	p = &Ping{Method: "engine", Count: 1, WindowSize: 10, DSCP: 64}
+	require.ErrorContains(t, p.Init(), "invalid DSCP 64")
+
+	p = &Ping{Method: "engine", Count: 1, WindowSize: 10, Targets: []target{{Interval: config.Duration(time.Second)}}}
+	require.ErrorContains(t, p.Init(), "missing URL for target")
+
+	p = &Ping{Method: "native", Count: 1, Targets: []target{{URL: "192.0.2.1"}}}
+	require.ErrorContains(t, p.Init(), "targets are only supported with the engine method")
+}
+
+func TestEngineWindowStatistics(t *testing.T) {
+	tgt := &engineTarget{ttl: 64}
+	for _, rtt := range []time.Duration{10, 20, 0, 15} {
+		tgt.add(result{rtt: rtt * time.Millisecond}, 3)
+	}
+
+	// The oldest result must be dropped from the window
+	expected := map[string]interface{}{
+		"result_code":           0,
+		"packets_transmitted":   3,
+		"packets_received":      2,
+		"percent_packet_loss":   float64(1) / 3 * 100,
+		"ttl":                   64,
+		"minimum_response_ms":   15.0,
+		"average_response_ms":   17.5,
+		"maximum_response_ms":   20.0,
+		"standard_deviation_ms": 2.5,
+		"jitter_ms":             5.0,
+		"percentile50_ms":       17.5,
+	}
+	require.Equal(t, expected, tgt.fields([]int{50}))
+
+	// Lost probes only
+	tgt = &engineTarget{ttl: -1}
+	tgt.add(result{}, 3)
+	expected = map[string]interface{}{
+		"result_code":         1,
+		"packets_transmitted": 1,
+		"packets_received":    0,
+		"percent_packet_loss": 100.0,
+	}
+	require.Equal(t, expected, tgt.fields(nil))
+}
+
+func TestEngine(t *testing.T) {
+	p := &Ping{
+		Method:     "engine",
+		Count:      1,
+		WindowSize: 5,
+		Targets: []target{
+			{URL: "192.0.2.1", Interval: config.Duration(5 * time.Millisecond)},
+			{URL: "192.0.2.2", Interval: config.Duration(5 * time.Millisecond)},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	conn := newFakeConn("192.0.2.2")
+	e := newEngine(p)
+	e.timeout = 500 * time.Millisecond
+	e.conn4 = conn
+	e.start()
+	defer e.stop()
+
+	require.Eventually(t, func() bool {
+		e.Lock()
+		defer e.Unlock()
+		e.expire(time.Now())
+		for _, tgt := range e.targets {
+			if len(tgt.results) < p.WindowSize {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var acc testutil.Accumulator
+	e.gather(&acc, nil)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ping",
+			map[string]string{"url": "192.0.2.1"},
+			map[string]interface{}{
+				"result_code":           0,
+				"packets_transmitted":   5,
+				"packets_received":      5,
+				"percent_packet_loss":   0.0,
+				"ttl":                   64,
+				"minimum_response_ms":   0.0,
+				"average_response_ms":   0.0,
+				"maximum_response_ms":   0.0,
+				"standard_deviation_ms": 0.0,
+				"jitter_ms":             0.0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ping",
+			map[string]string{"url": "192.0.2.2"},
+			map[string]interface{}{
+				"result_code":         1,
+				"packets_transmitted": 5,
+				"packets_received":    0,
+				"percent_packet_loss": 100.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	// Response times depend on the system so only check their existence
+	options := []cmp.Option{
+		testutil.IgnoreTime(),
+		testutil.SortMetrics(),
+		testutil.IgnoreFields("minimum_response_ms", "average_response_ms", "maximum_response_ms", "standard_deviation_ms", "jitter_ms"),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Tags()["url"] == "192.0.2.1" {
+			require.True(t, m.HasField("jitter_ms"))
+		}
+	}
+}
+
+// fakeConn replies to all echo requests except for the dropped addresses
+type fakeConn struct {
+	drop    map[string]bool
+	replies chan fakeReply
+	done    chan struct{}
+	once    sync.Once
+}
+
+type fakeReply struct {
+	data []byte
+	peer net.Addr
+}
+
+func newFakeConn(drop ...string) *fakeConn {
+	c := &fakeConn{
+		drop:    make(map[string]bool, len(drop)),
+		replies: make(chan fakeReply, 1024),
+		done:    make(chan struct{}),
+	}
+	for _, addr := range drop {
+		c.drop[addr] = true
+	}
+	return c
+}
+
+func (c *fakeConn) readFrom(b []byte) (n, ttl int, peer net.Addr, err error) {
+	select {
+	case <-c.done:
+		return 0, -1, nil, net.ErrClosed
+	case r := <-c.replies:
+		return copy(b, r.data), 64, r.peer, nil
+	}
+}
+
+func (c *fakeConn) writeTo(b []byte, dst net.Addr) (int, error) {
+	addr := dst.(*net.IPAddr)
+	if c.drop[addr.IP.String()] {
+		return len(b), nil
+	}
+
+	msg, err := icmp.ParseMessage(protocolICMP, b)
+	if err != nil {
+		return 0, err
+	}
+	reply := icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: msg.Body}
+	data, err := reply.Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	select {
+	case c.replies <- fakeReply{data: data, peer: addr}:
+	default:
+	}
+	return len(b), nil
+}
+
+func (c *fakeConn) close() error {
+	c.once.Do(func() { close(c.done) })
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 	ping "github.com/prometheus-community/pro-bing"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -39,11 +40,15 @@ type Ping struct {
 	Percentiles  []int    `toml:"percentiles"`   // Calculate the given percentiles when using native method
 	Binary       string   `toml:"binary"`        // Ping executable binary
 	// Arguments for ping command. When arguments are not empty, system binary will be used and other options (ping_interval, timeout, etc.) will be ignored
-	Arguments []string        `toml:"arguments"`
-	IPv4      bool            `toml:"ipv4"` // Whether to resolve addresses using ipv4 or not.
-	IPv6      bool            `toml:"ipv6"` // Whether to resolve addresses using ipv6 or not.
-	Size      *int            `toml:"size"` // Packet size
-	Log       telegraf.Logger `toml:"-"`
+	Arguments []string `toml:"arguments"`
+	IPv4      bool     `toml:"ipv4"` // Whether to resolve addresses using ipv4 or not.
+	IPv6      bool     `toml:"ipv6"` // Whether to resolve addresses using ipv6 or not.
+	Size      *int     `toml:"size"` // Packet size
+	// Settings of the ICMP engine
+	Targets    []target        `toml:"target"`
+	WindowSize int             `toml:"window_size"`
+	DSCP       int             `toml:"dscp"`
+	Log        telegraf.Logger `toml:"-"`
 
 	wg             sync.WaitGroup // wg is used to wait for ping with multiple URLs
 	calcInterval   time.Duration  // Pre-calculated interval and timeout
@@ -51,6 +56,13 @@ type Ping struct {
 	sourceAddress  string
 	pingHost       hostPingerFunc // host ping function
 	nativePingFunc nativePingFunc
+	engine         *engine
+}
+
+// target of the ICMP engine with an individual ping interval
+type target struct {
+	URL      string          `toml:"url"`
+	Interval config.Duration `toml:"interval"`
 }
 
 // hostPingerFunc is a function that runs the "ping" function using a list of
@@ -90,10 +102,67 @@ func (p *Ping) Init() error {
 		p.calcTimeout = time.Duration(p.Timeout) * time.Second
 	}
 
+	if p.Method == "engine" {
+		if p.WindowSize < 1 {
+			return errors.New("'window_size' must be positive")
+		}
+		if p.DSCP < 0 || p.DSCP > 63 {
+			return fmt.Errorf("invalid DSCP %d, must be between 0 and 63", p.DSCP)
+		}
+		for _, t := range p.Targets {
+			if t.URL == "" {
+				return errors.New("missing URL for target")
+			}
+		}
+	} else if len(p.Targets) > 0 {
+		return errors.New("targets are only supported with the engine method")
+	}
+
 	return nil
 }
 
+func (p *Ping) Start(telegraf.Accumulator) error {
+	if p.Method != "engine" {
+		return nil
+	}
+
+	if err := p.resolveSourceAddress(); err != nil {
+		return err
+	}
+
+	// Fall back to executing the ping command if the ICMP sockets cannot be
+	// opened e.g. due to missing permissions
+	e := newEngine(p)
+	if err := e.listen(); err != nil {
+		if len(p.Targets) > 0 {
+			return fmt.Errorf("starting ICMP engine failed: %w", err)
+		}
+		p.Log.Warnf("Cannot start ICMP engine, falling back to exec method: %v", err)
+		p.Method = "exec"
+		return nil
+	}
+	if e.unprivileged {
+		p.Log.Info("Using unprivileged ICMP sockets")
+	}
+	e.start()
+	p.engine = e
+
+	return nil
+}
+
+func (p *Ping) Stop() {
+	if p.engine != nil {
+		p.engine.stop()
+		p.engine = nil
+	}
+}
+
 func (p *Ping) Gather(acc telegraf.Accumulator) error {
+	if p.engine != nil {
+		p.engine.gather(acc, p.Percentiles)
+		return nil
+	}
+
 	for _, host := range p.Urls {
 		p.wg.Add(1)
 		go func(host string) {
@@ -138,24 +207,8 @@ func (p *Ping) nativePing(destination string) (*pingStats, error) {
 		}
 	}
 
-	// Support either an IP address or interface name
-	if p.Interface != "" && p.sourceAddress == "" {
-		if addr := net.ParseIP(p.Interface); addr != nil {
-			p.sourceAddress = p.Interface
-		} else {
-			i, err := net.InterfaceByName(p.Interface)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get interface: %w", err)
-			}
-			addrs, err := i.Addrs()
-			if err != nil {
-				return nil, fmt.Errorf("failed to get the address of interface: %w", err)
-			}
-			if len(addrs) == 0 {
-				return nil, fmt.Errorf("no address found for interface %s", p.Interface)
-			}
-			p.sourceAddress = addrs[0].(*net.IPNet).IP.String()
-		}
+	if err := p.resolveSourceAddress(); err != nil {
+		return nil, err
 	}
 
 	pinger.Source = p.sourceAddress
@@ -191,6 +244,33 @@ func (p *Ping) nativePing(destination string) (*pingStats, error) {
 	return ps, nil
 }
 
+// resolveSourceAddress determines the source address from the interface
+// setting supporting either an IP address or interface name
+func (p *Ping) resolveSourceAddress() error {
+	if p.Interface == "" || p.sourceAddress != "" {
+		return nil
+	}
+
+	if addr := net.ParseIP(p.Interface); addr != nil {
+		p.sourceAddress = p.Interface
+		return nil
+	}
+
+	i, err := net.InterfaceByName(p.Interface)
+	if err != nil {
+		return fmt.Errorf("failed to get interface: %w", err)
+	}
+	addrs, err := i.Addrs()
+	if err != nil {
+		return fmt.Errorf("failed to get the address of interface: %w", err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("no address found for interface %s", p.Interface)
+	}
+	p.sourceAddress = addrs[0].(*net.IPNet).IP.String()
+	return nil
+}
+
 func (p *Ping) pingToURLNative(destination string, acc telegraf.Accumulator) {
 	tags := map[string]string{"url": destination}
 
@@ -306,6 +386,7 @@ func init() {
 			Binary:       "ping",
 			Arguments:    make([]string, 0),
 			Percentiles:  make([]int, 0),
+			WindowSize:   60,
 		}
 		p.nativePingFunc = p.nativePing
 		return p
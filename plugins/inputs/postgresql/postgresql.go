@@ -48,6 +48,14 @@ func (p *Postgresql) Start(_ telegraf.Accumulator) error {
 }
 
 func (p *Postgresql) Gather(acc telegraf.Accumulator) error {
+	// The admin console of PgBouncer does not provide the statistics views
+	if p.service.PgBouncerConsole {
+		if err := p.service.GatherPgBouncerStats(acc); err != nil {
+			return err
+		}
+		return p.service.GatherPgBouncerPools(acc)
+	}
+
 	var query string
 	if len(p.Databases) == 0 && len(p.IgnoredDatabases) == 0 {
 		query = `SELECT * FROM pg_stat_database`
@@ -107,6 +107,15 @@ func (p *Postgresql) Start(_ telegraf.Accumulator) error {
 }
 
 func (p *Postgresql) Gather(acc telegraf.Accumulator) error {
+	// Report the PgBouncer statistics if connected to the admin console
+	// without custom queries
+	if p.service.PgBouncerConsole && len(p.Query) == 0 {
+		if err := p.service.GatherPgBouncerStats(acc); err != nil {
+			return err
+		}
+		return p.service.GatherPgBouncerPools(acc)
+	}
+
 	// Retrieving the database version
 	query := `SELECT setting::integer / 100 AS version FROM pg_settings WHERE name = 'server_version_num'`
 	var dbVersion int
@@ -0,0 +1,438 @@
+package procstat
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// Suffixes of systemd units that can contain processes
+var unitSuffixes = []string{".service", ".scope", ".socket", ".mount", ".swap"}
+
+// cgroupAggregator groups the matched processes by their cgroup v2 or
+// systemd unit and reports the resource usage accounted by the kernel for
+// the group. This includes the usage of processes exited in the meantime.
+type cgroupAggregator struct {
+	byUnit   bool
+	procPath string
+	root     string
+	tracker  *processTracker
+
+	groups   map[string]*cgroupGroup
+	previous map[string]cpuSample
+}
+
+type cgroupGroup struct {
+	path      string
+	tags      map[string]string
+	processes int
+}
+
+type cpuSample struct {
+	usage float64
+	time  time.Time
+}
+
+func newCgroupAggregator(by string) (*cgroupAggregator, error) {
+	// Use the unified hierarchy which is either mounted at the cgroup root
+	// or, in hybrid setups, below the "unified" directory
+	base := filepath.Join(internal.GetSysPath(), "fs", "cgroup")
+	var root string
+	for _, dir := range []string{base, filepath.Join(base, "unified")} {
+		if _, err := os.Stat(filepath.Join(dir, "cgroup.procs")); err == nil {
+			root = dir
+			break
+		}
+	}
+	if root == "" {
+		return nil, fmt.Errorf("no cgroup v2 hierarchy found in %q", base)
+	}
+
+	return &cgroupAggregator{
+		byUnit:   by == "systemd_unit",
+		procPath: internal.GetProcPath(),
+		root:     root,
+		groups:   make(map[string]*cgroupGroup),
+		previous: make(map[string]cpuSample),
+	}, nil
+}
+
+// add assigns the process to the group of its cgroup, the given tags
+// distinguish between groups of e.g. different filters
+func (a *cgroupAggregator) add(id pid, tags map[string]string) {
+	path, err := cgroupOf(a.procPath, id)
+	if err != nil {
+		// The process might have exited in the meantime
+		return
+	}
+
+	var unit string
+	if a.byUnit {
+		path, unit = unitOf(path)
+	}
+
+	key := path
+	if f, found := tags["filter"]; found {
+		key = f + "\x00" + path
+	}
+
+	g, found := a.groups[key]
+	if !found {
+		t := make(map[string]string, len(tags)+2)
+		for k, v := range tags {
+			t[k] = v
+		}
+		t["cgroup"] = path
+		if unit != "" {
+			t["systemd_unit"] = unit
+		}
+		g = &cgroupGroup{path: path, tags: t}
+		a.groups[key] = g
+	}
+	g.processes++
+}
+
+// emit adds a metric for each group and resets the groups
+func (a *cgroupAggregator) emit(acc telegraf.Accumulator, now time.Time) {
+	current := make(map[string]cpuSample, len(a.groups))
+	for key, g := range a.groups {
+		fields, err := readCgroupStats(filepath.Join(a.root, g.path))
+		if err != nil {
+			// The cgroup was removed in the meantime
+			continue
+		}
+		fields["num_processes"] = g.processes
+
+		if usage, ok := fields["cpu_time"].(float64); ok {
+			if prev, found := a.previous[key]; found {
+				if elapsed := now.Sub(prev.time).Seconds(); elapsed > 0 {
+					fields["cpu_usage"] = 100 * (usage - prev.usage) / elapsed
+				}
+			}
+			current[key] = cpuSample{usage: usage, time: now}
+		}
+
+		if a.tracker != nil {
+			started, exited := a.tracker.counts(g.path)
+			fields["processes_started"] = started
+			fields["processes_exited"] = exited
+		}
+
+		acc.AddFields("procstat_cgroup", fields, g.tags, now)
+	}
+	a.previous = current
+	a.groups = make(map[string]*cgroupGroup)
+
+	if a.tracker != nil {
+		a.tracker.prune(a.root)
+	}
+}
+
+// cgroupOf returns the cgroup v2 path of the given process
+func cgroupOf(procPath string, id pid) (string, error) {
+	buf, err := os.ReadFile(filepath.Join(procPath, strconv.Itoa(int(id)), "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range bytes.Split(buf, []byte{'\n'}) {
+		if path, found := bytes.CutPrefix(line, []byte("0::")); found {
+			return string(path), nil
+		}
+	}
+	return "", errors.New("no cgroup v2 membership")
+}
+
+// unitOf returns the path and name of the innermost systemd unit containing
+// the given cgroup or the cgroup itself if it does not belong to a unit
+func unitOf(path string) (string, string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		for _, suffix := range unitSuffixes {
+			if strings.HasSuffix(parts[i], suffix) {
+				return "/" + strings.Join(parts[:i+1], "/"), parts[i]
+			}
+		}
+	}
+	return path, ""
+}
+
+// readCgroupStats reads the statistics of the given cgroup directory, the
+// statistics of controllers not enabled for the cgroup are omitted
+func readCgroupStats(dir string) (map[string]interface{}, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+
+	if stats, err := readKeyValues(filepath.Join(dir, "cpu.stat")); err == nil {
+		for key, name := range map[string]string{
+			"usage_usec":     "cpu_time",
+			"user_usec":      "cpu_time_user",
+			"system_usec":    "cpu_time_system",
+			"throttled_usec": "cpu_throttled_time",
+		} {
+			if v, found := stats[key]; found {
+				fields[name] = float64(v) / 1e6
+			}
+		}
+		if v, found := stats["nr_throttled"]; found {
+			fields["cpu_throttled_count"] = v
+		}
+	}
+
+	for file, name := range map[string]string{
+		"memory.current":      "memory_current",
+		"memory.swap.current": "memory_swap_current",
+		"memory.max":          "memory_max",
+		"pids.current":        "tasks",
+	} {
+		if v, err := readValue(filepath.Join(dir, file)); err == nil {
+			fields[name] = v
+		}
+	}
+	if stats, err := readKeyValues(filepath.Join(dir, "memory.stat")); err == nil {
+		for _, key := range []string{"anon", "file", "kernel", "sock", "shmem"} {
+			if v, found := stats[key]; found {
+				fields["memory_"+key] = v
+			}
+		}
+	}
+
+	if stats, err := readIOStats(filepath.Join(dir, "io.stat")); err == nil {
+		for key, name := range map[string]string{
+			"rbytes": "io_read_bytes",
+			"wbytes": "io_write_bytes",
+			"rios":   "io_read_ops",
+			"wios":   "io_write_ops",
+		} {
+			fields[name] = stats[key]
+		}
+	}
+
+	// Pressure stall information is only available if enabled in the kernel
+	for _, resource := range []string{"cpu", "memory", "io"} {
+		readPressure(filepath.Join(dir, resource+".pressure"), resource, fields)
+	}
+
+	return fields, nil
+}
+
+// readValue reads a file containing a single value, this fails for the
+// value "max" of unlimited resources
+func readValue(path string) (uint64, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(bytes.TrimSpace(buf)), 10, 64)
+}
+
+// readKeyValues reads a flat-keyed file with lines like "usage_usec 1234"
+func readKeyValues(path string) (map[string]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), " ")
+		if !found {
+			continue
+		}
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		values[key] = v
+	}
+	return values, scanner.Err()
+}
+
+// readIOStats reads the IO statistics summed over all devices from a file
+// with lines like "8:0 rbytes=1 wbytes=2 rios=3 wios=4 dbytes=0 dios=0"
+func readIOStats(path string) (map[string]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 2 {
+			continue
+		}
+		for _, kv := range parts[1:] {
+			key, value, found := strings.Cut(kv, "=")
+			if !found {
+				continue
+			}
+			v, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			values[key] += v
+		}
+	}
+	return values, scanner.Err()
+}
+
+// readPressure reads the pressure stall information from a file with lines
+// like "some avg10=0.00 avg60=0.00 avg300=0.00 total=0"
+func readPressure(path, resource string, fields map[string]interface{}) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 2 || (parts[0] != "some" && parts[0] != "full") {
+			continue
+		}
+		prefix := resource + "_pressure_" + parts[0] + "_"
+		for _, kv := range parts[1:] {
+			key, value, found := strings.Cut(kv, "=")
+			if !found {
+				continue
+			}
+			if key == "total" {
+				if v, err := strconv.ParseUint(value, 10, 64); err == nil {
+					fields[prefix+key] = v
+				}
+				continue
+			}
+			if v, err := strconv.ParseFloat(value, 64); err == nil {
+				fields[prefix+key] = v
+			}
+		}
+	}
+}
+
+// processTracker counts the processes started and exited per cgroup based
+// on the process events of the kernel, this allows to account for
+// short-lived processes not seen when gathering
+type processTracker struct {
+	procPath string
+	cgroups  map[pid]string
+	starts   map[string]uint64
+	exits    map[string]uint64
+	done     chan struct{}
+	wg       sync.WaitGroup
+	log      telegraf.Logger
+	sync.Mutex
+}
+
+func newProcessTracker(procPath string, log telegraf.Logger) *processTracker {
+	return &processTracker{
+		procPath: procPath,
+		cgroups:  make(map[pid]string),
+		starts:   make(map[string]uint64),
+		exits:    make(map[string]uint64),
+		log:      log,
+	}
+}
+
+// forked records a new process and its cgroup
+func (t *processTracker) forked(id pid) {
+	path, err := cgroupOf(t.procPath, id)
+	if err != nil {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	t.cgroups[id] = path
+	t.starts[path]++
+}
+
+// executed updates the cgroup of the process as service managers move the
+// processes to the target cgroup before executing them
+func (t *processTracker) executed(id pid) {
+	path, err := cgroupOf(t.procPath, id)
+	if err != nil {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	previous, found := t.cgroups[id]
+	if found && previous == path {
+		return
+	}
+	if found && t.starts[previous] > 0 {
+		t.starts[previous]--
+	}
+	t.cgroups[id] = path
+	t.starts[path]++
+}
+
+// exited records the exit of the process in the cgroup recorded for the
+// process or, for processes started before the tracking, its current cgroup
+func (t *processTracker) exited(id pid) {
+	t.Lock()
+	path, found := t.cgroups[id]
+	delete(t.cgroups, id)
+	t.Unlock()
+
+	if !found {
+		var err error
+		if path, err = cgroupOf(t.procPath, id); err != nil {
+			return
+		}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	t.exits[path]++
+}
+
+// counts returns the number of processes started and exited in the given
+// cgroup including its descendants
+func (t *processTracker) counts(path string) (started, exited uint64) {
+	prefix := strings.TrimSuffix(path, "/") + "/"
+
+	t.Lock()
+	defer t.Unlock()
+	for p, n := range t.starts {
+		if p == path || strings.HasPrefix(p, prefix) {
+			started += n
+		}
+	}
+	for p, n := range t.exits {
+		if p == path || strings.HasPrefix(p, prefix) {
+			exited += n
+		}
+	}
+	return started, exited
+}
+
+// prune removes the counts of cgroups not existing anymore
+func (t *processTracker) prune(root string) {
+	t.Lock()
+	defer t.Unlock()
+	for _, m := range []map[string]uint64{t.starts, t.exits} {
+		for path := range m {
+			if _, err := os.Stat(filepath.Join(root, path)); errors.Is(err, os.ErrNotExist) {
+				delete(m, path)
+			}
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package procstat
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitAggregationFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Procstat
+		expected string
+	}{
+		{
+			name:     "invalid aggregation",
+			plugin:   &Procstat{AggregateBy: "foo"},
+			expected: `invalid 'aggregate_by' setting "foo"`,
+		},
+		{
+			name:     "events without aggregation",
+			plugin:   &Procstat{CaptureProcessEvents: true},
+			expected: "'capture_process_events' requires 'aggregate_by' to be set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Exe = exe
+			tt.plugin.PidFinder = "test"
+			tt.plugin.Log = testutil.Logger{}
+			tt.plugin.createProcess = newTestProc
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestUnitOf(t *testing.T) {
+	tests := []struct {
+		path         string
+		expectedPath string
+		expectedUnit string
+	}{
+		{
+			path:         "/system.slice/nginx.service",
+			expectedPath: "/system.slice/nginx.service",
+			expectedUnit: "nginx.service",
+		},
+		{
+			path:         "/system.slice/nginx.service/worker",
+			expectedPath: "/system.slice/nginx.service",
+			expectedUnit: "nginx.service",
+		},
+		{
+			path:         "/user.slice/user-1000.slice/user@1000.service/app.slice/app-foo.scope",
+			expectedPath: "/user.slice/user-1000.slice/user@1000.service/app.slice/app-foo.scope",
+			expectedUnit: "app-foo.scope",
+		},
+		{
+			path:         "/kubepods/burstable",
+			expectedPath: "/kubepods/burstable",
+		},
+		{
+			path:         "/",
+			expectedPath: "/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, unit := unitOf(tt.path)
+			require.Equal(t, tt.expectedPath, path)
+			require.Equal(t, tt.expectedUnit, unit)
+		})
+	}
+}
+
+func TestGatherAggregation(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Skipping test on non-linux platform")
+	}
+	t.Setenv("HOST_PROC", filepath.Join("testdata", "cgroup", "proc"))
+	t.Setenv("HOST_SYS", filepath.Join("testdata", "cgroup", "sys"))
+
+	nginx := map[string]interface{}{
+		"cpu_time":                    2.5,
+		"cpu_time_user":               2.0,
+		"cpu_time_system":             0.5,
+		"cpu_throttled_count":         uint64(2),
+		"cpu_throttled_time":          0.0015,
+		"memory_current":              uint64(52428800),
+		"memory_swap_current":         uint64(0),
+		"memory_max":                  uint64(104857600),
+		"memory_anon":                 uint64(41943040),
+		"memory_file":                 uint64(8388608),
+		"memory_kernel":               uint64(1048576),
+		"memory_sock":                 uint64(4096),
+		"memory_shmem":                uint64(0),
+		"tasks":                       uint64(5),
+		"io_read_bytes":               uint64(1049600),
+		"io_write_bytes":              uint64(2097152),
+		"io_read_ops":                 uint64(101),
+		"io_write_ops":                uint64(200),
+		"cpu_pressure_some_avg10":     1.5,
+		"cpu_pressure_some_avg60":     0.75,
+		"cpu_pressure_some_avg300":    0.25,
+		"cpu_pressure_some_total":     uint64(123456),
+		"cpu_pressure_full_avg10":     0.0,
+		"cpu_pressure_full_avg60":     0.0,
+		"cpu_pressure_full_avg300":    0.0,
+		"cpu_pressure_full_total":     uint64(0),
+		"memory_pressure_some_avg10":  0.0,
+		"memory_pressure_some_avg60":  0.0,
+		"memory_pressure_some_avg300": 0.0,
+		"memory_pressure_some_total":  uint64(42),
+		"memory_pressure_full_avg10":  0.0,
+		"memory_pressure_full_avg60":  0.0,
+		"memory_pressure_full_avg300": 0.0,
+		"memory_pressure_full_total":  uint64(21),
+		"num_processes":               2,
+	}
+
+	tests := []struct {
+		name     string
+		by       string
+		expected []telegraf.Metric
+	}{
+		{
+			name: "systemd unit",
+			by:   "systemd_unit",
+			expected: []telegraf.Metric{
+				metric.New(
+					"procstat_cgroup",
+					map[string]string{
+						"cgroup":       "/system.slice/nginx.service",
+						"systemd_unit": "nginx.service",
+					},
+					nginx,
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"procstat_cgroup",
+					map[string]string{
+						"cgroup":       "/user.slice/user-1000.slice/session-2.scope",
+						"systemd_unit": "session-2.scope",
+					},
+					map[string]interface{}{
+						"cpu_time":        1.0,
+						"cpu_time_user":   0.75,
+						"cpu_time_system": 0.25,
+						"memory_current":  uint64(4096),
+						"num_processes":   1,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "cgroup",
+			by:   "cgroup",
+			expected: []telegraf.Metric{
+				metric.New(
+					"procstat_cgroup",
+					map[string]string{"cgroup": "/system.slice/nginx.service"},
+					func() map[string]interface{} {
+						fields := make(map[string]interface{}, len(nginx))
+						for k, v := range nginx {
+							fields[k] = v
+						}
+						fields["num_processes"] = 1
+						return fields
+					}(),
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"procstat_cgroup",
+					map[string]string{"cgroup": "/system.slice/nginx.service/worker"},
+					map[string]interface{}{
+						"cpu_time":        0.001,
+						"cpu_time_user":   0.0006,
+						"cpu_time_system": 0.0004,
+						"num_processes":   1,
+					},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"procstat_cgroup",
+					map[string]string{"cgroup": "/user.slice/user-1000.slice/session-2.scope"},
+					map[string]interface{}{
+						"cpu_time":        1.0,
+						"cpu_time_user":   0.75,
+						"cpu_time_system": 0.25,
+						"memory_current":  uint64(4096),
+						"num_processes":   1,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Procstat{
+				Exe:           exe,
+				PidFinder:     "test",
+				AggregateBy:   tt.by,
+				Properties:    []string{"cpu", "memory"},
+				Log:           testutil.Logger{},
+				finder:        newTestFinder([]pid{42, 43, 44}),
+				createProcess: newTestProc,
+			}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Gather(&acc))
+
+			var actual []telegraf.Metric
+			for _, m := range acc.GetTelegrafMetrics() {
+				if m.Name() == "procstat_cgroup" {
+					actual = append(actual, m)
+				}
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+
+			// The CPU usage is computed starting from the second gather
+			acc.ClearMetrics()
+			require.NoError(t, plugin.Gather(&acc))
+			require.True(t, acc.HasFloatField("procstat_cgroup", "cpu_usage"))
+		})
+	}
+}
+
+func TestProcessTracker(t *testing.T) {
+	tracker := newProcessTracker(filepath.Join("testdata", "cgroup", "proc"), testutil.Logger{})
+
+	tracker.forked(42)
+	tracker.forked(43)
+	tracker.executed(43)
+	tracker.exited(42)
+	// The cgroup of processes started before tracking is determined on exit
+	tracker.exited(44)
+	// Processes exited before their cgroup can be determined are ignored
+	tracker.forked(1)
+	tracker.exited(1)
+
+	started, exited := tracker.counts("/system.slice/nginx.service")
+	require.Equal(t, uint64(2), started)
+	require.Equal(t, uint64(1), exited)
+
+	started, exited = tracker.counts("/system.slice/nginx.service/worker")
+	require.Equal(t, uint64(1), started)
+	require.Equal(t, uint64(0), exited)
+
+	started, exited = tracker.counts("/user.slice/user-1000.slice/session-2.scope")
+	require.Equal(t, uint64(0), started)
+	require.Equal(t, uint64(1), exited)
+
+	// Counts of removed cgroups are dropped
+	tracker.prune(filepath.Join("testdata", "cgroup", "sys", "fs", "cgroup", "system.slice"))
+	started, exited = tracker.counts("/user.slice/user-1000.slice/session-2.scope")
+	require.Equal(t, uint64(0), started)
+	require.Equal(t, uint64(0), exited)
+	started, _ = tracker.counts("/system.slice/nginx.service")
+	require.Equal(t, uint64(0), started)
+}
@@ -407,3 +407,53 @@ func statsUnix(conns []gopsnet.ConnectionStat) ([]map[string]interface{}, error)
 
 	return fieldslist, nil
 }
+
+// start subscribes to the process events of the kernel's process connector
+func (t *processTracker) start() error {
+	events := make(chan netlink.ProcEvent, 1024)
+	errs := make(chan error, 1)
+	t.done = make(chan struct{})
+	if err := netlink.ProcEventMonitor(events, t.done, errs); err != nil {
+		return fmt.Errorf("subscribing to process events failed: %w", err)
+	}
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				// Ignore the events of threads
+				switch msg := e.Msg.(type) {
+				case *netlink.ForkProcEvent:
+					if msg.ChildPid == msg.ChildTgid {
+						t.forked(pid(msg.ChildTgid))
+					}
+				case *netlink.ExecProcEvent:
+					t.executed(pid(msg.ProcessTgid))
+				case *netlink.ExitProcEvent:
+					if msg.ProcessPid == msg.ProcessTgid {
+						t.exited(pid(msg.ProcessTgid))
+					}
+				}
+			case err := <-errs:
+				select {
+				case <-t.done:
+				default:
+					t.log.Errorf("Receiving process events failed: %v", err)
+				}
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (t *processTracker) stop() {
+	close(t.done)
+	t.wg.Wait()
+}
@@ -131,3 +131,9 @@ func statsUDP(conns []gopsnet.ConnectionStat, _ uint8) ([]map[string]interface{}
 func statsUnix([]gopsnet.ConnectionStat) ([]map[string]interface{}, error) {
 	return nil, errors.ErrUnsupported
 }
+
+func (*processTracker) start() error {
+	return errors.New("process events not supported on this platform")
+}
+
+func (*processTracker) stop() {}
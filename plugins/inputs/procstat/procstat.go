@@ -51,12 +51,15 @@ type Procstat struct {
 	SocketProtocols        []string        `toml:"socket_protocols"`
 	TagWith                []string        `toml:"tag_with"`
 	Filter                 []filter        `toml:"filter"`
+	AggregateBy            string          `toml:"aggregate_by"`
+	CaptureProcessEvents   bool            `toml:"capture_process_events"`
 	Log                    telegraf.Logger `toml:"-"`
 
-	finder    pidFinder
-	processes map[pid]process
-	cfg       collectionConfig
-	oldMode   bool
+	finder     pidFinder
+	processes  map[pid]process
+	cfg        collectionConfig
+	oldMode    bool
+	aggregator *cgroupAggregator
 
 	createProcess func(pid) (process, error)
 }
@@ -203,12 +206,48 @@ func (p *Procstat) Init() error {
 		}
 	}
 
+	// Setup the aggregation of the processes per cgroup
+	switch p.AggregateBy {
+	case "":
+		if p.CaptureProcessEvents {
+			return errors.New("'capture_process_events' requires 'aggregate_by' to be set")
+		}
+	case "cgroup", "systemd_unit":
+		if runtime.GOOS != "linux" {
+			return errors.New("'aggregate_by' is only supported on Linux")
+		}
+		aggregator, err := newCgroupAggregator(p.AggregateBy)
+		if err != nil {
+			return fmt.Errorf("setting up aggregation failed: %w", err)
+		}
+		if p.CaptureProcessEvents {
+			aggregator.tracker = newProcessTracker(aggregator.procPath, p.Log)
+		}
+		p.aggregator = aggregator
+	default:
+		return fmt.Errorf("invalid 'aggregate_by' setting %q", p.AggregateBy)
+	}
+
 	// Initialize the running process cache
 	p.processes = make(map[pid]process)
 
 	return nil
 }
 
+func (p *Procstat) Start(telegraf.Accumulator) error {
+	if p.aggregator == nil || p.aggregator.tracker == nil {
+		return nil
+	}
+	return p.aggregator.tracker.start()
+}
+
+func (p *Procstat) Stop() {
+	if p.aggregator == nil || p.aggregator.tracker == nil {
+		return
+	}
+	p.aggregator.tracker.stop()
+}
+
 func (p *Procstat) Gather(acc telegraf.Accumulator) error {
 	if p.oldMode {
 		return p.gatherOld(acc)
@@ -281,6 +320,9 @@ func (p *Procstat) gatherOld(acc telegraf.Accumulator) error {
 				p.processes[pid] = proc
 			}
 			running[pid] = true
+			if p.aggregator != nil {
+				p.aggregator.add(pid, nil)
+			}
 			metrics, err := proc.metrics(p.Prefix, &p.cfg, now)
 			if err != nil {
 				// Continue after logging an error as there might still be
@@ -300,6 +342,10 @@ func (p *Procstat) gatherOld(acc telegraf.Accumulator) error {
 		}
 	}
 
+	if p.aggregator != nil {
+		p.aggregator.emit(acc, now)
+	}
+
 	// Add lookup statistics-metric
 	fields := map[string]interface{}{
 		"pid_count":   count,
@@ -389,6 +435,9 @@ func (p *Procstat) gatherNew(acc telegraf.Accumulator) error {
 					p.processes[pid] = process
 				}
 				running[pid] = true
+				if p.aggregator != nil {
+					p.aggregator.add(pid, map[string]string{"filter": f.Name})
+				}
 				metrics, err := process.metrics(p.Prefix, &p.cfg, now)
 				if err != nil {
 					// Continue after logging an error as there might still be
@@ -440,6 +489,10 @@ func (p *Procstat) gatherNew(acc telegraf.Accumulator) error {
 			delete(p.processes, pid)
 		}
 	}
+
+	if p.aggregator != nil {
+		p.aggregator.emit(acc, now)
+	}
 	return nil
 }
 
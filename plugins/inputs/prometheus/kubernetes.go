@@ -51,30 +51,42 @@ func loadConfig(kubeconfigPath string) (*rest.Config, error) {
 	return clientcmd.BuildConfigFromFlags("", kubeconfigPath)
 }
 
-func (p *Prometheus) startK8s(ctx context.Context) error {
-	config, err := loadConfig(p.KubeConfig)
+// newKubernetesClient creates a client from the given kubeconfig falling back
+// to the configuration in the home directory of the current user
+func newKubernetesClient(kubeConfig string) (*rest.Config, *kubernetes.Clientset, error) {
+	config, err := loadConfig(kubeConfig)
 	if err != nil {
-		return fmt.Errorf("failed to get rest.Config from %q: %w", p.KubeConfig, err)
+		return nil, nil, fmt.Errorf("failed to get rest.Config from %q: %w", kubeConfig, err)
 	}
 
 	client, err := kubernetes.NewForConfig(config)
+	if err == nil {
+		return config, client, nil
+	}
+
+	u, err := user.Current()
 	if err != nil {
-		u, err := user.Current()
-		if err != nil {
-			return fmt.Errorf("failed to get current user: %w", err)
-		}
+		return nil, nil, fmt.Errorf("failed to get current user: %w", err)
+	}
 
-		kubeconfig := filepath.Join(u.HomeDir, ".kube", "config")
+	kubeconfig := filepath.Join(u.HomeDir, ".kube", "config")
 
-		config, err = loadConfig(kubeconfig)
-		if err != nil {
-			return fmt.Errorf("failed to get rest.Config from %q: %w", kubeconfig, err)
-		}
+	config, err = loadConfig(kubeconfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get rest.Config from %q: %w", kubeconfig, err)
+	}
 
-		client, err = kubernetes.NewForConfig(config)
-		if err != nil {
-			return fmt.Errorf("failed to get kubernetes client: %w", err)
-		}
+	client, err = kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get kubernetes client: %w", err)
+	}
+	return config, client, nil
+}
+
+func (p *Prometheus) startK8s(ctx context.Context) error {
+	config, client, err := newKubernetesClient(p.KubeConfig)
+	if err != nil {
+		return err
 	}
 
 	if !p.isNodeScrapeScope {
@@ -0,0 +1,371 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	listerscorev1 "k8s.io/client-go/listers/core/v1"
+	listersdiscoveryv1 "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const metaPrefix = "__meta_kubernetes_"
+
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// relabelRule is a Prometheus-style relabel rule deciding if a discovered
+// target should be kept or dropped based on its discovery labels
+type relabelRule struct {
+	SourceLabels []string `toml:"source_labels"`
+	Separator    string   `toml:"separator"`
+	Regex        string   `toml:"regex"`
+	Action       string   `toml:"action"`
+
+	regex *regexp.Regexp
+}
+
+func (r *relabelRule) init() error {
+	switch r.Action {
+	case "keep", "drop":
+	default:
+		return fmt.Errorf("invalid action %q, must be 'keep' or 'drop'", r.Action)
+	}
+	if len(r.SourceLabels) == 0 {
+		return errors.New("no source labels specified")
+	}
+	if r.Separator == "" {
+		r.Separator = ";"
+	}
+	if r.Regex == "" {
+		r.Regex = "(.*)"
+	}
+
+	// Regular expressions are anchored at both ends as in Prometheus
+	re, err := regexp.Compile("^(?:" + r.Regex + ")$")
+	if err != nil {
+		return fmt.Errorf("compiling regex %q failed: %w", r.Regex, err)
+	}
+	r.regex = re
+	return nil
+}
+
+// keep returns true if the target with the given labels passes the rule
+func (r *relabelRule) keep(targetLabels map[string]string) bool {
+	values := make([]string, 0, len(r.SourceLabels))
+	for _, l := range r.SourceLabels {
+		values = append(values, targetLabels[l])
+	}
+	match := r.regex.MatchString(strings.Join(values, r.Separator))
+	if r.Action == "drop" {
+		return !match
+	}
+	return match
+}
+
+// defaultRelabelRules keep the targets of services annotated for scraping if
+// no rules are configured
+func defaultRelabelRules() []relabelRule {
+	r := relabelRule{
+		SourceLabels: []string{metaPrefix + "service_annotation_prometheus_io_scrape"},
+		Regex:        "true",
+		Action:       "keep",
+	}
+	//nolint:errcheck // static rule known to be valid
+	r.init()
+	return []relabelRule{r}
+}
+
+// endpointSliceTarget is a scrape target discovered from an EndpointSlice
+// along with its discovery labels
+type endpointSliceTarget struct {
+	labels map[string]string
+	target urlAndAddress
+}
+
+// startEndpointSlices starts the informer based discovery of scrape targets
+// from the EndpointSlices of Kubernetes services.
+func (p *Prometheus) startEndpointSlices(ctx context.Context) error {
+	_, client, err := newKubernetesClient(p.KubeConfig)
+	if err != nil {
+		return err
+	}
+	return p.watchEndpointSlices(ctx, client)
+}
+
+func (p *Prometheus) watchEndpointSlices(ctx context.Context, client kubernetes.Interface) error {
+	resyncinterval := 60 * time.Minute
+	if p.CacheRefreshInterval != 0 {
+		resyncinterval = time.Duration(p.CacheRefreshInterval) * time.Minute
+	}
+
+	var options []informers.SharedInformerOption
+	if p.PodNamespace != "" {
+		options = append(options, informers.WithNamespace(p.PodNamespace))
+	}
+	f := informers.NewSharedInformerFactoryWithOptions(client, resyncinterval, options...)
+
+	if p.nsAnnotationPass != nil || p.nsAnnotationDrop != nil {
+		p.nsStore = f.Core().V1().Namespaces().Informer().GetStore()
+	}
+
+	services := f.Core().V1().Services()
+	slices := f.Discovery().V1().EndpointSlices()
+	serviceLister := services.Lister()
+	sliceLister := slices.Lister()
+
+	// Register the informers with the factory before starting it
+	serviceInformer := services.Informer()
+	sliceInformer := slices.Informer()
+
+	_, err := sliceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			p.updateEndpointSlice(obj, serviceLister)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			p.updateEndpointSlice(obj, serviceLister)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj); err == nil {
+				p.unregisterEndpointSlice(key)
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Services carry the annotations and labels used for discovery so update
+	// all slices of a service on changes
+	_, err = serviceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			p.updateServiceSlices(obj, serviceLister, sliceLister)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			p.updateServiceSlices(obj, serviceLister, sliceLister)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			p.updateServiceSlices(obj, serviceLister, sliceLister)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	f.Start(ctx.Done())
+	for typ, synced := range f.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("syncing cache for %v failed", typ)
+		}
+	}
+	return nil
+}
+
+func (p *Prometheus) updateServiceSlices(obj interface{}, serviceLister listerscorev1.ServiceLister, sliceLister listersdiscoveryv1.EndpointSliceLister) {
+	svc, ok := obj.(*corev1.Service)
+	if !ok {
+		p.Log.Errorf("[BUG] received unexpected object: %v", obj)
+		return
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: svc.Name})
+	slices, err := sliceLister.EndpointSlices(svc.Namespace).List(selector)
+	if err != nil {
+		p.Log.Errorf("Listing endpoint slices of service %s/%s failed: %v", svc.Namespace, svc.Name, err)
+		return
+	}
+	for _, slice := range slices {
+		p.updateEndpointSlice(slice, serviceLister)
+	}
+}
+
+func (p *Prometheus) updateEndpointSlice(obj interface{}, serviceLister listerscorev1.ServiceLister) {
+	slice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok {
+		p.Log.Errorf("[BUG] received unexpected object: %v", obj)
+		return
+	}
+	key, err := cache.MetaNamespaceKeyFunc(slice)
+	if err != nil {
+		p.Log.Errorf("Getting key of endpoint slice failed: %v", err)
+		return
+	}
+
+	// Slices might be seen before their service, in this case the targets are
+	// updated once the service is added
+	var svc *corev1.Service
+	if name := slice.Labels[discoveryv1.LabelServiceName]; name != "" {
+		svc, err = serviceLister.Services(slice.Namespace).Get(name)
+		if err != nil {
+			p.Log.Debugf("Service %s/%s of endpoint slice %s not found: %v", slice.Namespace, name, key, err)
+			svc = nil
+		}
+	}
+
+	targets := make(map[string]urlAndAddress)
+	for _, t := range p.endpointSliceTargets(slice, svc) {
+		if p.keepTarget(t.labels) {
+			targets[t.target.url.String()] = t.target
+		}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if len(targets) == 0 {
+		delete(p.kubernetesEndpoints, key)
+		return
+	}
+	p.kubernetesEndpoints[key] = targets
+}
+
+func (p *Prometheus) unregisterEndpointSlice(key string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if _, found := p.kubernetesEndpoints[key]; found {
+		p.Log.Debugf("Will stop scraping targets of endpoint slice %s", key)
+		delete(p.kubernetesEndpoints, key)
+	}
+}
+
+// keepTarget applies the relabel rules to the discovery labels of a target
+func (p *Prometheus) keepTarget(targetLabels map[string]string) bool {
+	for i := range p.relabelRules {
+		if !p.relabelRules[i].keep(targetLabels) {
+			return false
+		}
+	}
+	return true
+}
+
+// endpointSliceTargets returns the scrape targets for all endpoints and ports
+// of the given slice. Scheme, path and port can be overridden by the
+// "prometheus.io/..." annotations of the service.
+func (p *Prometheus) endpointSliceTargets(slice *discoveryv1.EndpointSlice, svc *corev1.Service) []endpointSliceTarget {
+	common := map[string]string{
+		metaPrefix + "namespace":          slice.Namespace,
+		metaPrefix + "endpointslice_name": slice.Name,
+	}
+	scheme, path, portOverride := "http", "/metrics", ""
+	if svc != nil {
+		common[metaPrefix+"service_name"] = svc.Name
+		for k, v := range svc.Labels {
+			common[metaPrefix+"service_label_"+sanitizeLabelName(k)] = v
+		}
+		for k, v := range svc.Annotations {
+			common[metaPrefix+"service_annotation_"+sanitizeLabelName(k)] = v
+		}
+		if v := svc.Annotations["prometheus.io/scheme"]; v != "" {
+			scheme = v
+		}
+		if v := svc.Annotations["prometheus.io/path"]; v != "" {
+			path = v
+		}
+		portOverride = svc.Annotations["prometheus.io/port"]
+	}
+
+	// Use the annotated port for all endpoints if any
+	type slicePort struct {
+		number   string
+		name     string
+		protocol string
+	}
+	var ports []slicePort
+	if portOverride != "" {
+		ports = append(ports, slicePort{number: portOverride})
+	} else {
+		for _, port := range slice.Ports {
+			if port.Port == nil {
+				continue
+			}
+			sp := slicePort{number: strconv.Itoa(int(*port.Port))}
+			if port.Name != nil {
+				sp.name = *port.Name
+			}
+			if port.Protocol != nil {
+				sp.protocol = string(*port.Protocol)
+			}
+			ports = append(ports, sp)
+		}
+	}
+
+	var targets []endpointSliceTarget
+	for _, endpoint := range slice.Endpoints {
+		// Skip endpoints not ready to serve traffic
+		if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+			continue
+		}
+		for _, address := range endpoint.Addresses {
+			for _, port := range ports {
+				hostport := net.JoinHostPort(address, port.number)
+				targetURL, err := url.Parse(path)
+				if err != nil {
+					p.Log.Errorf("Could not parse path %q of endpoint slice %s/%s: %v", path, slice.Namespace, slice.Name, err)
+					return nil
+				}
+				targetURL.Scheme = scheme
+				targetURL.Host = hostport
+
+				targetLabels := make(map[string]string, len(common)+8)
+				for k, v := range common {
+					targetLabels[k] = v
+				}
+				targetLabels["__address__"] = hostport
+				targetLabels[metaPrefix+"endpointslice_port"] = port.number
+				targetLabels[metaPrefix+"endpointslice_port_name"] = port.name
+				targetLabels[metaPrefix+"endpointslice_port_protocol"] = port.protocol
+				targetLabels[metaPrefix+"endpointslice_endpoint_conditions_ready"] = "true"
+				if endpoint.NodeName != nil {
+					targetLabels[metaPrefix+"endpointslice_endpoint_node_name"] = *endpoint.NodeName
+				}
+
+				namespaceTag := "namespace"
+				if p.PodNamespaceLabelName != "" {
+					namespaceTag = p.PodNamespaceLabelName
+				}
+				tags := map[string]string{namespaceTag: slice.Namespace}
+				if svc != nil {
+					tags["service_name"] = svc.Name
+				}
+				if ref := endpoint.TargetRef; ref != nil {
+					targetLabels[metaPrefix+"endpointslice_address_target_kind"] = ref.Kind
+					targetLabels[metaPrefix+"endpointslice_address_target_name"] = ref.Name
+					if ref.Kind == "Pod" {
+						tags["pod_name"] = ref.Name
+					}
+				}
+
+				targets = append(targets, endpointSliceTarget{
+					labels: targetLabels,
+					target: urlAndAddress{
+						url:         targetURL,
+						originalURL: targetURL,
+						address:     address,
+						tags:        tags,
+						namespace:   slice.Namespace,
+					},
+				})
+			}
+		}
+	}
+	return targets
+}
+
+// sanitizeLabelName replaces all characters invalid in a Prometheus label
+// name by underscores
+func sanitizeLabelName(name string) string {
+	return invalidLabelChars.ReplaceAllString(name, "_")
+}
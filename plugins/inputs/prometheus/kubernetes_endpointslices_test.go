@@ -0,0 +1,253 @@
+package prometheus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestRelabelRuleInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     relabelRule
+		expected string
+	}{
+		{
+			name:     "missing action",
+			rule:     relabelRule{SourceLabels: []string{"__address__"}},
+			expected: `invalid action ""`,
+		},
+		{
+			name:     "unsupported action",
+			rule:     relabelRule{SourceLabels: []string{"__address__"}, Action: "replace"},
+			expected: `invalid action "replace"`,
+		},
+		{
+			name:     "no source labels",
+			rule:     relabelRule{Action: "keep"},
+			expected: "no source labels specified",
+		},
+		{
+			name:     "invalid regex",
+			rule:     relabelRule{SourceLabels: []string{"__address__"}, Regex: "(", Action: "keep"},
+			expected: `compiling regex "(" failed`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.rule.init(), tt.expected)
+		})
+	}
+}
+
+func TestRelabelRuleKeepDrop(t *testing.T) {
+	targetLabels := map[string]string{
+		metaPrefix + "namespace":    "monitoring",
+		metaPrefix + "service_name": "node-exporter",
+	}
+
+	keep := relabelRule{
+		SourceLabels: []string{metaPrefix + "namespace", metaPrefix + "service_name"},
+		Regex:        "monitoring;node-.*",
+		Action:       "keep",
+	}
+	require.NoError(t, keep.init())
+	require.True(t, keep.keep(targetLabels))
+
+	// Regular expressions are anchored
+	partial := relabelRule{
+		SourceLabels: []string{metaPrefix + "service_name"},
+		Regex:        "node",
+		Action:       "keep",
+	}
+	require.NoError(t, partial.init())
+	require.False(t, partial.keep(targetLabels))
+
+	drop := relabelRule{
+		SourceLabels: []string{metaPrefix + "service_name"},
+		Regex:        "node-exporter|kube-.*",
+		Action:       "drop",
+	}
+	require.NoError(t, drop.init())
+	require.False(t, drop.keep(targetLabels))
+
+	// Missing labels are treated as empty values
+	missing := relabelRule{
+		SourceLabels: []string{metaPrefix + "service_label_team"},
+		Action:       "drop",
+		Regex:        "",
+	}
+	require.NoError(t, missing.init())
+	require.False(t, missing.keep(targetLabels))
+}
+
+func TestMutuallyExclusiveKubernetesDiscovery(t *testing.T) {
+	p := &Prometheus{
+		MonitorPods:           true,
+		MonitorEndpointSlices: true,
+		Log:                   testutil.Logger{},
+	}
+	require.ErrorContains(t, p.Init(), "mutually exclusive")
+}
+
+func TestEndpointSliceTargets(t *testing.T) {
+	p := &Prometheus{Log: testutil.Logger{}}
+
+	svc := newService("default", "myapp", map[string]string{
+		"prometheus.io/scrape": "true",
+		"prometheus.io/scheme": "https",
+		"prometheus.io/path":   "/custom",
+	})
+	svc.Labels = map[string]string{"app.kubernetes.io/name": "myapp"}
+	slice := newEndpointSlice("default", "myapp-abc", "myapp")
+
+	targets := p.endpointSliceTargets(slice, svc)
+	require.Len(t, targets, 2)
+
+	actual := make([]string, 0, len(targets))
+	for _, target := range targets {
+		actual = append(actual, target.target.url.String())
+	}
+	require.ElementsMatch(t, []string{"https://10.0.0.1:8080/custom", "https://10.0.0.1:9090/custom"}, actual)
+
+	first := targets[0]
+	require.Equal(t, "10.0.0.1:8080", first.labels["__address__"])
+	require.Equal(t, "default", first.labels[metaPrefix+"namespace"])
+	require.Equal(t, "myapp", first.labels[metaPrefix+"service_name"])
+	require.Equal(t, "myapp", first.labels[metaPrefix+"service_label_app_kubernetes_io_name"])
+	require.Equal(t, "true", first.labels[metaPrefix+"service_annotation_prometheus_io_scrape"])
+	require.Equal(t, "myapp-abc", first.labels[metaPrefix+"endpointslice_name"])
+	require.Equal(t, "http", first.labels[metaPrefix+"endpointslice_port_name"])
+	require.Equal(t, "TCP", first.labels[metaPrefix+"endpointslice_port_protocol"])
+	require.Equal(t, "node-1", first.labels[metaPrefix+"endpointslice_endpoint_node_name"])
+	require.Equal(t, "Pod", first.labels[metaPrefix+"endpointslice_address_target_kind"])
+	require.Equal(t, "myapp-0", first.labels[metaPrefix+"endpointslice_address_target_name"])
+	require.Equal(t, map[string]string{
+		"namespace":    "default",
+		"service_name": "myapp",
+		"pod_name":     "myapp-0",
+	}, first.target.tags)
+}
+
+func TestEndpointSliceTargetsPortAnnotation(t *testing.T) {
+	p := &Prometheus{Log: testutil.Logger{}, PodNamespaceLabelName: "pod_namespace"}
+
+	svc := newService("default", "myapp", map[string]string{"prometheus.io/port": "9102"})
+	slice := newEndpointSlice("default", "myapp-abc", "myapp")
+	slice.Endpoints[0].Addresses = []string{"fd00::1"}
+
+	targets := p.endpointSliceTargets(slice, svc)
+	require.Len(t, targets, 1)
+	require.Equal(t, "http://[fd00::1]:9102/metrics", targets[0].target.url.String())
+	require.Equal(t, "default", targets[0].target.tags["pod_namespace"])
+}
+
+func TestEndpointSliceTargetsNotReady(t *testing.T) {
+	p := &Prometheus{Log: testutil.Logger{}}
+
+	ready := false
+	slice := newEndpointSlice("default", "myapp-abc", "myapp")
+	slice.Endpoints[0].Conditions.Ready = &ready
+
+	require.Empty(t, p.endpointSliceTargets(slice, nil))
+}
+
+func TestWatchEndpointSlices(t *testing.T) {
+	svc := newService("default", "myapp", map[string]string{"prometheus.io/scrape": "true"})
+	ignored := newService("default", "other", nil)
+	other := newEndpointSlice("default", "other-abc", "other")
+	other.Endpoints[0].Addresses = []string{"10.0.0.2"}
+	client := fake.NewSimpleClientset(
+		svc,
+		ignored,
+		newEndpointSlice("default", "myapp-abc", "myapp"),
+		other,
+	)
+
+	p := &Prometheus{
+		MonitorEndpointSlices: true,
+		Log:                   testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, p.watchEndpointSlices(ctx, client))
+
+	// Only the targets of the annotated service are kept
+	require.Eventually(t, func() bool {
+		urls, err := p.getAllURLs()
+		require.NoError(t, err)
+		return len(urls) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	urls, err := p.getAllURLs()
+	require.NoError(t, err)
+	require.Contains(t, urls, "http://10.0.0.1:8080/metrics")
+	require.Contains(t, urls, "http://10.0.0.1:9090/metrics")
+
+	// Annotating the other service adds its targets
+	ignored.Annotations = map[string]string{"prometheus.io/scrape": "true"}
+	_, err = client.CoreV1().Services("default").Update(ctx, ignored, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		urls, err := p.getAllURLs()
+		require.NoError(t, err)
+		return len(urls) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Deleting a slice removes its targets
+	err = client.DiscoveryV1().EndpointSlices("default").Delete(ctx, "myapp-abc", metav1.DeleteOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		urls, err := p.getAllURLs()
+		require.NoError(t, err)
+		return len(urls) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func newService(namespace, name string, annotations map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Annotations: annotations,
+		},
+	}
+}
+
+func newEndpointSlice(namespace, name, service string) *discoveryv1.EndpointSlice {
+	ready := true
+	nodeName := "node-1"
+	httpName, metricsName := "http", "metrics"
+	httpPort, metricsPort := int32(8080), int32(9090)
+	protocol := corev1.ProtocolTCP
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{
+				Addresses:  []string{"10.0.0.1"},
+				Conditions: discoveryv1.EndpointConditions{Ready: &ready},
+				NodeName:   &nodeName,
+				TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: service + "-0"},
+			},
+		},
+		Ports: []discoveryv1.EndpointPort{
+			{Name: &httpName, Port: &httpPort, Protocol: &protocol},
+			{Name: &metricsName, Port: &metricsPort, Protocol: &protocol},
+		},
+	}
+}
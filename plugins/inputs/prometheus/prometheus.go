@@ -37,6 +37,10 @@ var sampleConfig string
 const (
 	acceptHeader = `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,text/plain;version=0.0.4;q=0.3`
 
+	// Native histograms are only available in the protobuf format so strongly
+	// prefer it over the text format
+	acceptHeaderNative = `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited,text/plain;version=0.0.4;q=0.1`
+
 	monitorMethodNone                   monitorMethod = ""
 	monitorMethodAnnotations            monitorMethod = "annotations"
 	monitorMethodSettings               monitorMethod = "settings"
@@ -56,6 +60,8 @@ type Prometheus struct {
 	MetricVersion        int               `toml:"metric_version"`
 	URLTag               string            `toml:"url_tag"`
 	IgnoreTimestamp      bool              `toml:"ignore_timestamp"`
+	UnitPolicy           string            `toml:"unit_policy"`
+	HistogramPreference  string            `toml:"histogram_preference"`
 
 	// Kubernetes service discovery
 	MonitorPods                 bool                `toml:"monitor_kubernetes_pods"`
@@ -80,6 +86,10 @@ type Prometheus struct {
 	PodLabelExclude             []string            `toml:"pod_label_exclude"`
 	CacheRefreshInterval        int                 `toml:"cache_refresh_interval"`
 
+	// Kubernetes EndpointSlice discovery
+	MonitorEndpointSlices bool          `toml:"monitor_kubernetes_endpointslices"`
+	KubernetesRelabel     []relabelRule `toml:"kubernetes_relabel"`
+
 	// Consul discovery
 	ConsulConfig consulConfig `toml:"consul"`
 
@@ -98,10 +108,12 @@ type Prometheus struct {
 	nsAnnotationDrop []models.TagFilter
 
 	// Should we scrape Kubernetes services for prometheus annotations
-	lock           sync.Mutex
-	kubernetesPods map[podID]urlAndAddress
-	cancel         context.CancelFunc
-	wg             sync.WaitGroup
+	lock                sync.Mutex
+	kubernetesPods      map[podID]urlAndAddress
+	kubernetesEndpoints map[string]map[string]urlAndAddress
+	relabelRules        []relabelRule
+	cancel              context.CancelFunc
+	wg                  sync.WaitGroup
 
 	// Only for monitor_kubernetes_pods=true and pod_scrape_scope="node"
 	podLabelSelector           labels.Selector
@@ -224,6 +236,19 @@ func (p *Prometheus) Init() error {
 		p.nsAnnotationDrop = append(p.nsAnnotationDrop, tagFilter)
 	}
 
+	if p.MonitorPods && p.MonitorEndpointSlices {
+		return errors.New("'monitor_kubernetes_pods' and 'monitor_kubernetes_endpointslices' are mutually exclusive")
+	}
+	for i := range p.KubernetesRelabel {
+		if err := p.KubernetesRelabel[i].init(); err != nil {
+			return fmt.Errorf("invalid 'kubernetes_relabel' rule %d: %w", i+1, err)
+		}
+	}
+	p.relabelRules = p.KubernetesRelabel
+	if len(p.relabelRules) == 0 {
+		p.relabelRules = defaultRelabelRules()
+	}
+
 	if err := p.initFilters(); err != nil {
 		return err
 	}
@@ -232,6 +257,26 @@ func (p *Prometheus) Init() error {
 		p.MetricVersion = 1
 	}
 
+	switch p.UnitPolicy {
+	case "", "tag", "suffix", "ignore":
+	default:
+		return fmt.Errorf("invalid unit policy %q", p.UnitPolicy)
+	}
+
+	accept := acceptHeader
+	switch p.HistogramPreference {
+	case "", "classic":
+	case "native":
+		switch p.ContentTypeOverride {
+		case "", "protobuf-delimiter", "protobuf-compact", "protobuf-text":
+		default:
+			return fmt.Errorf("native histograms require a protobuf format but 'content_type_override' is %q", p.ContentTypeOverride)
+		}
+		accept = acceptHeaderNative
+	default:
+		return fmt.Errorf("invalid histogram preference %q", p.HistogramPreference)
+	}
+
 	ctx := context.Background()
 
 	client, err := p.HTTPClientConfig.CreateClient(ctx, p.Log)
@@ -250,10 +295,11 @@ func (p *Prometheus) Init() error {
 
 	p.headers = map[string]string{
 		"User-Agent": internal.ProductToken(),
-		"Accept":     acceptHeader,
+		"Accept":     accept,
 	}
 
 	p.kubernetesPods = make(map[podID]urlAndAddress)
+	p.kubernetesEndpoints = make(map[string]map[string]urlAndAddress)
 
 	return nil
 }
@@ -274,6 +320,11 @@ func (p *Prometheus) Start(_ telegraf.Accumulator) error {
 			return err
 		}
 	}
+	if p.MonitorEndpointSlices {
+		if err := p.startEndpointSlices(ctx); err != nil {
+			return err
+		}
+	}
 	if p.HTTPSDConfig.Enabled {
 		if err := p.startHTTPSD(ctx); err != nil {
 			return err
@@ -395,6 +446,14 @@ func (p *Prometheus) getAllURLs() (map[string]urlAndAddress, error) {
 			allURLs[v.url.String()] = v
 		}
 	}
+	// add all targets discovered via endpoint slices
+	for _, targets := range p.kubernetesEndpoints {
+		for k, v := range targets {
+			if namespaceAnnotationMatch(v.namespace, p) {
+				allURLs[k] = v
+			}
+		}
+	}
 
 	for _, service := range p.KubernetesServices {
 		address, err := url.Parse(service)
@@ -566,14 +625,20 @@ func (p *Prometheus) gatherURL(u urlAndAddress, acc telegraf.Accumulator) (map[s
 			Header:          resp.Header,
 			MetricVersion:   p.MetricVersion,
 			IgnoreTimestamp: p.IgnoreTimestamp,
+			UnitPolicy:      p.UnitPolicy,
 			Log:             p.Log,
 		}
 	} else {
+		if p.HistogramPreference == "native" && !isProtobuf(resp.Header) {
+			p.Log.Debugf("%s did not respond in protobuf format; native histograms are unavailable", u.url)
+		}
 		metricParser = &parsers_prometheus.Parser{
-			Header:          resp.Header,
-			MetricVersion:   p.MetricVersion,
-			IgnoreTimestamp: p.IgnoreTimestamp,
-			Log:             p.Log,
+			Header:              resp.Header,
+			MetricVersion:       p.MetricVersion,
+			IgnoreTimestamp:     p.IgnoreTimestamp,
+			UnitPolicy:          p.UnitPolicy,
+			HistogramPreference: p.HistogramPreference,
+			Log:                 p.Log,
 		}
 	}
 	metrics, err := metricParser.Parse(body)
@@ -642,13 +707,23 @@ func fieldSelectorIsSupported(fieldSelector fields.Selector) (bool, string) {
 	return true, ""
 }
 
+// isProtobuf returns true if the response is in one of the protobuf formats
+func isProtobuf(header http.Header) bool {
+	switch expfmt.ResponseFormat(header).FormatType() {
+	case expfmt.TypeProtoDelim, expfmt.TypeProtoCompact, expfmt.TypeProtoText:
+		return true
+	}
+	return false
+}
+
 func init() {
 	inputs.Add("prometheus", func() telegraf.Input {
 		return &Prometheus{
-			kubernetesPods: make(map[podID]urlAndAddress),
-			consulServices: make(map[string]urlAndAddress),
-			httpServices:   make(map[string]urlAndAddress),
-			URLTag:         "url",
+			kubernetesPods:      make(map[podID]urlAndAddress),
+			kubernetesEndpoints: make(map[string]map[string]urlAndAddress),
+			consulServices:      make(map[string]urlAndAddress),
+			httpServices:        make(map[string]urlAndAddress),
+			URLTag:              "url",
 		}
 	})
 }
@@ -12,7 +12,11 @@ import (
 	"testing"
 	"time"
 
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
 	"k8s.io/apimachinery/pkg/fields"
 
 	"github.com/influxdata/telegraf"
@@ -940,3 +944,80 @@ go_memstats_heap_alloc_bytes 1.581062048e+09
 
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
 }
+
+func TestNativeHistogramNegotiation(t *testing.T) {
+	mf := &dto.MetricFamily{
+		Name: proto.String("rpc_duration"),
+		Type: dto.MetricType_HISTOGRAM.Enum(),
+		Metric: []*dto.Metric{
+			{
+				Histogram: &dto.Histogram{
+					SampleCount:   proto.Uint64(3),
+					SampleSum:     proto.Float64(3.5),
+					Schema:        proto.Int32(0),
+					PositiveSpan:  []*dto.BucketSpan{{Offset: proto.Int32(0), Length: proto.Uint32(2)}},
+					PositiveDelta: []int64{1, 1},
+					Bucket: []*dto.Bucket{
+						{UpperBound: proto.Float64(5), CumulativeCount: proto.Uint64(3)},
+					},
+				},
+			},
+		},
+	}
+
+	var accept string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", string(expfmt.NewFormat(expfmt.TypeProtoDelim)))
+		if _, err := protodelim.MarshalTo(w, mf); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+	}))
+	defer ts.Close()
+
+	p := &Prometheus{
+		Log:                 testutil.Logger{},
+		URLs:                []string{ts.URL},
+		URLTag:              "url",
+		HistogramPreference: "native",
+	}
+	require.NoError(t, p.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(p.Gather))
+	require.Equal(t, acceptHeaderNative, accept)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"rpc_duration",
+			map[string]string{"url": ts.URL + "/metrics"},
+			map[string]interface{}{
+				"count": float64(3),
+				"sum":   float64(3.5),
+				"1":     float64(1),
+				"2":     float64(3),
+				"+Inf":  float64(3),
+			},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestHistogramPreferenceInvalid(t *testing.T) {
+	p := &Prometheus{
+		Log:                 testutil.Logger{},
+		HistogramPreference: "foo",
+	}
+	require.ErrorContains(t, p.Init(), "invalid histogram preference")
+
+	p = &Prometheus{
+		Log:                 testutil.Logger{},
+		HistogramPreference: "native",
+		ContentTypeOverride: "text",
+	}
+	require.ErrorContains(t, p.Init(), "native histograms require a protobuf format")
+}
@@ -0,0 +1,331 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package ptp
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Queries sent to ptp4l via pmc
+var queries = []string{
+	"GET CURRENT_DATA_SET",
+	"GET TIME_STATUS_NP",
+	"GET PORT_DATA_SET",
+}
+
+// Port states as defined in IEEE 1588 with linuxptp extensions
+var portStates = map[string]int64{
+	"NONE":         0,
+	"INITIALIZING": 1,
+	"FAULTY":       2,
+	"DISABLED":     3,
+	"LISTENING":    4,
+	"PRE_MASTER":   5,
+	"MASTER":       6,
+	"PASSIVE":      7,
+	"UNCALIBRATED": 8,
+	"SLAVE":        9,
+	"GRAND_MASTER": 10,
+}
+
+// Numeric attributes of the PTP hardware clock devices
+var deviceAttributes = []string{
+	"max_adjustment",
+	"n_alarms",
+	"n_external_timestamps",
+	"n_periodic_outputs",
+	"n_programmable_pins",
+	"pps_available",
+}
+
+type PTP struct {
+	Collect      []string        `toml:"collect"`
+	Binary       string          `toml:"binary"`
+	UseSudo      bool            `toml:"use_sudo"`
+	Socket       string          `toml:"socket"`
+	DomainNumber uint8           `toml:"domain_number"`
+	Timeout      config.Duration `toml:"timeout"`
+	Devices      []string        `toml:"devices"`
+	Log          telegraf.Logger `toml:"-"`
+
+	collectPMC     bool
+	collectDevices bool
+	deviceFilter   filter.Filter
+	sysPath        string
+	run            func() ([]byte, error)
+}
+
+// response contains the attributes of a single management message response
+type response struct {
+	identity string
+	id       string
+	values   map[string]string
+}
+
+func (*PTP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *PTP) Init() error {
+	if err := choice.CheckSlice(p.Collect, []string{"pmc", "devices"}); err != nil {
+		return fmt.Errorf("config option collect: %w", err)
+	}
+	p.collectPMC = choice.Contains("pmc", p.Collect)
+	p.collectDevices = choice.Contains("devices", p.Collect)
+
+	if p.collectPMC && p.Binary == "" {
+		return errors.New("'binary' required")
+	}
+
+	f, err := filter.Compile(p.Devices)
+	if err != nil {
+		return fmt.Errorf("creating device filter failed: %w", err)
+	}
+	p.deviceFilter = f
+
+	if p.sysPath == "" {
+		p.sysPath = internal.GetSysPath()
+	}
+	if p.run == nil {
+		p.run = p.runPMC
+	}
+
+	return nil
+}
+
+func (p *PTP) Gather(acc telegraf.Accumulator) error {
+	if p.collectPMC {
+		if err := p.gatherPMC(acc); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	if p.collectDevices {
+		if err := p.gatherDevices(acc); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	return nil
+}
+
+func (p *PTP) runPMC() ([]byte, error) {
+	args := []string{"-u", "-b", "0", "-d", strconv.Itoa(int(p.DomainNumber))}
+	if p.Socket != "" {
+		args = append(args, "-s", p.Socket)
+	}
+	args = append(args, queries...)
+
+	cmd := exec.Command(p.Binary, args...)
+	if p.UseSudo {
+		cmd = exec.Command("sudo", append([]string{"-n", p.Binary}, args...)...)
+	}
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := internal.RunTimeout(cmd, time.Duration(p.Timeout)); err != nil {
+		return nil, fmt.Errorf("running %q failed: %w", p.Binary, err)
+	}
+	return out.Bytes(), nil
+}
+
+func (p *PTP) gatherPMC(acc telegraf.Accumulator) error {
+	out, err := p.run()
+	if err != nil {
+		return err
+	}
+	responses, err := parsePMC(out)
+	if err != nil {
+		return fmt.Errorf("parsing pmc output failed: %w", err)
+	}
+	if len(responses) == 0 {
+		return errors.New("no response from ptp4l")
+	}
+
+	tags := make(map[string]string)
+	fields := make(map[string]interface{})
+	for _, r := range responses {
+		switch r.id {
+		case "CURRENT_DATA_SET":
+			tags["clock_identity"], _, _ = strings.Cut(r.identity, "-")
+			p.addFloat(fields, "offset_from_master_ns", r.values["offsetFromMaster"])
+			p.addFloat(fields, "mean_path_delay_ns", r.values["meanPathDelay"])
+			p.addInt(fields, "steps_removed", r.values["stepsRemoved"])
+		case "TIME_STATUS_NP":
+			if v := r.values["gmIdentity"]; v != "" {
+				tags["gm_identity"] = v
+			}
+			p.addInt(fields, "master_offset_ns", r.values["master_offset"])
+			p.addFloat(fields, "cumulative_scaled_rate_offset", r.values["cumulativeScaledRateOffset"])
+			if v, found := r.values["gmPresent"]; found {
+				fields["gm_present"] = v == "true"
+			}
+		case "PORT_DATA_SET":
+			p.addPort(acc, r)
+		}
+	}
+
+	if len(fields) > 0 {
+		acc.AddFields("ptp", fields, tags)
+	}
+
+	return nil
+}
+
+func (p *PTP) addPort(acc telegraf.Accumulator, r *response) {
+	state := r.values["portState"]
+	tags := map[string]string{
+		"port_identity": r.values["portIdentity"],
+	}
+	fields := map[string]interface{}{
+		"state": state,
+	}
+	if code, found := portStates[state]; found {
+		fields["state_code"] = code
+	}
+	p.addFloat(fields, "peer_mean_path_delay_ns", r.values["peerMeanPathDelay"])
+	p.addInt(fields, "log_announce_interval", r.values["logAnnounceInterval"])
+	p.addInt(fields, "log_sync_interval", r.values["logSyncInterval"])
+	p.addInt(fields, "log_min_delay_req_interval", r.values["logMinDelayReqInterval"])
+	p.addInt(fields, "log_min_pdelay_req_interval", r.values["logMinPdelayReqInterval"])
+	p.addInt(fields, "announce_receipt_timeout", r.values["announceReceiptTimeout"])
+	p.addInt(fields, "delay_mechanism", r.values["delayMechanism"])
+	p.addInt(fields, "version_number", r.values["versionNumber"])
+
+	acc.AddFields("ptp_port", fields, tags)
+}
+
+func (p *PTP) addFloat(fields map[string]interface{}, name, raw string) {
+	if raw == "" {
+		return
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		p.Log.Debugf("Cannot parse value %q of %q: %v", raw, name, err)
+		return
+	}
+	fields[name] = v
+}
+
+func (p *PTP) addInt(fields map[string]interface{}, name, raw string) {
+	if raw == "" {
+		return
+	}
+	v, err := strconv.ParseInt(strings.TrimPrefix(raw, "+"), 10, 64)
+	if err != nil {
+		p.Log.Debugf("Cannot parse value %q of %q: %v", raw, name, err)
+		return
+	}
+	fields[name] = v
+}
+
+// parsePMC parses the output of pmc consisting of a header line per response
+// followed by indented "<key> <value>" lines, e.g.
+//
+//	sending: GET CURRENT_DATA_SET
+//		001122.fffe.334455-0 seq 0 RESPONSE MANAGEMENT CURRENT_DATA_SET
+//			stepsRemoved     1
+//			offsetFromMaster -8.0
+func parsePMC(buf []byte) ([]*response, error) {
+	var responses []*response
+	var current *response
+
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "sending:") {
+			continue
+		}
+
+		parts := strings.Fields(line)
+		if len(parts) >= 6 && parts[1] == "seq" && parts[3] == "RESPONSE" && parts[4] == "MANAGEMENT" {
+			current = &response{
+				identity: parts[0],
+				id:       parts[5],
+				values:   make(map[string]string),
+			}
+			responses = append(responses, current)
+			continue
+		}
+
+		// Ignore error responses and other non-value lines
+		if current == nil || len(parts) != 2 {
+			continue
+		}
+		current.values[parts[0]] = parts[1]
+	}
+
+	return responses, scanner.Err()
+}
+
+func (p *PTP) gatherDevices(acc telegraf.Accumulator) error {
+	dirs, err := filepath.Glob(filepath.Join(p.sysPath, "class", "ptp", "ptp*"))
+	if err != nil {
+		return fmt.Errorf("listing PTP devices failed: %w", err)
+	}
+
+	for _, dir := range dirs {
+		device := filepath.Base(dir)
+		if p.deviceFilter != nil && !p.deviceFilter.Match(device) {
+			continue
+		}
+
+		tags := map[string]string{"device": device}
+		if name, err := os.ReadFile(filepath.Join(dir, "clock_name")); err == nil {
+			tags["clock_name"] = strings.TrimSpace(string(name))
+		}
+
+		fields := make(map[string]interface{}, len(deviceAttributes))
+		for _, attr := range deviceAttributes {
+			raw, err := os.ReadFile(filepath.Join(dir, attr))
+			if err != nil {
+				// Not all attributes are available on all kernels
+				continue
+			}
+			v, err := strconv.ParseInt(strings.TrimSpace(string(raw)), 10, 64)
+			if err != nil {
+				acc.AddError(fmt.Errorf("parsing %q of device %q failed: %w", attr, device, err))
+				continue
+			}
+			fields[attr] = v
+		}
+		if len(fields) > 0 {
+			acc.AddFields("ptp_device", fields, tags)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	inputs.Add("ptp", func() telegraf.Input {
+		return &PTP{
+			Collect: []string{"pmc", "devices"},
+			Binary:  "/usr/sbin/pmc",
+			Socket:  "/var/run/ptp4l",
+			Timeout: config.Duration(5 * time.Second),
+			Devices: []string{"*"},
+		}
+	})
+}
@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package ptp
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type PTP struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*PTP) SampleConfig() string { return sampleConfig }
+
+func (p *PTP) Init() error {
+	p.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*PTP) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("ptp", func() telegraf.Input {
+		return &PTP{}
+	})
+}
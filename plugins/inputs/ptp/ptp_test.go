@@ -0,0 +1,124 @@
+//go:build linux
+
+package ptp
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := inputs.Inputs["ptp"]().(*PTP)
+	plugin.Collect = []string{"foo"}
+	plugin.Log = &testutil.Logger{}
+	require.ErrorContains(t, plugin.Init(), "config option collect")
+}
+
+func TestGather(t *testing.T) {
+	out, err := os.ReadFile(filepath.Join("testdata", "pmc.txt"))
+	require.NoError(t, err)
+
+	plugin := inputs.Inputs["ptp"]().(*PTP)
+	plugin.Log = &testutil.Logger{}
+	plugin.sysPath = filepath.Join("testdata", "sys")
+	plugin.run = func() ([]byte, error) { return out, nil }
+	require.NoError(t, plugin.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ptp",
+			map[string]string{
+				"clock_identity": "90e2ba.fffe.0ff2a8",
+				"gm_identity":    "001122.fffe.334455",
+			},
+			map[string]interface{}{
+				"offset_from_master_ns":         float64(-8),
+				"mean_path_delay_ns":            float64(627),
+				"steps_removed":                 int64(1),
+				"master_offset_ns":              int64(-8),
+				"cumulative_scaled_rate_offset": float64(0),
+				"gm_present":                    true,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ptp_port",
+			map[string]string{"port_identity": "90e2ba.fffe.0ff2a8-1"},
+			map[string]interface{}{
+				"state":                       "SLAVE",
+				"state_code":                  int64(9),
+				"peer_mean_path_delay_ns":     float64(0),
+				"log_announce_interval":       int64(1),
+				"log_sync_interval":           int64(0),
+				"log_min_delay_req_interval":  int64(0),
+				"log_min_pdelay_req_interval": int64(0),
+				"announce_receipt_timeout":    int64(3),
+				"delay_mechanism":             int64(1),
+				"version_number":              int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ptp_port",
+			map[string]string{"port_identity": "90e2ba.fffe.0ff2a8-2"},
+			map[string]interface{}{
+				"state":                       "PASSIVE",
+				"state_code":                  int64(7),
+				"peer_mean_path_delay_ns":     float64(0),
+				"log_announce_interval":       int64(1),
+				"log_sync_interval":           int64(0),
+				"log_min_delay_req_interval":  int64(0),
+				"log_min_pdelay_req_interval": int64(0),
+				"announce_receipt_timeout":    int64(3),
+				"delay_mechanism":             int64(1),
+				"version_number":              int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ptp_device",
+			map[string]string{
+				"device":     "ptp0",
+				"clock_name": "igb",
+			},
+			map[string]interface{}{
+				"max_adjustment":        int64(62499999),
+				"n_alarms":              int64(0),
+				"n_external_timestamps": int64(2),
+				"n_periodic_outputs":    int64(2),
+				"n_programmable_pins":   int64(4),
+				"pps_available":         int64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherPMCFailure(t *testing.T) {
+	plugin := inputs.Inputs["ptp"]().(*PTP)
+	plugin.Log = &testutil.Logger{}
+	plugin.sysPath = filepath.Join("testdata", "sys")
+	plugin.run = func() ([]byte, error) { return nil, errors.New("socket not found") }
+	require.NoError(t, plugin.Init())
+
+	// Device metrics must be collected even if ptp4l is not reachable
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "socket not found")
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
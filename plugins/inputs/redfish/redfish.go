@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"net/http"
 	"net/url"
@@ -44,6 +45,22 @@ type Redfish struct {
 	client http.Client
 	tls.ClientConfig
 	baseURL *url.URL
+	cache   map[string]cachedResponse
+}
+
+// cachedResponse is the last response of a resource used to avoid
+// transferring unchanged resources using the ETag of the resource
+type cachedResponse struct {
+	etag string
+	body []byte
+}
+
+type link struct {
+	Ref string `json:"@odata.id"`
+}
+
+type collection struct {
+	Members []link
 }
 
 type system struct {
@@ -53,6 +70,7 @@ type system struct {
 			Ref string `json:"@odata.id"`
 		}
 	}
+	Storage link
 }
 
 type chassis struct {
@@ -71,6 +89,8 @@ type chassis struct {
 	Thermal      struct {
 		Ref string `json:"@odata.id"`
 	}
+	PowerSubsystem   link
+	ThermalSubsystem link
 }
 
 type power struct {
@@ -137,6 +157,61 @@ type thermal struct {
 	}
 }
 
+type powerSubsystem struct {
+	PowerSupplies link
+}
+
+type powerSupply struct {
+	ID                 string `json:"Id"`
+	Name               string
+	PowerCapacityWatts *float64
+	Status             status
+	Metrics            link
+}
+
+type powerSupplyMetrics struct {
+	InputPowerWatts  sensorReading
+	OutputPowerWatts sensorReading
+	InputVoltage     sensorReading
+}
+
+type thermalSubsystem struct {
+	Fans link
+}
+
+type fan struct {
+	ID           string `json:"Id"`
+	Name         string
+	Status       status
+	SpeedPercent struct {
+		Reading  *float64
+		SpeedRPM *float64
+	}
+}
+
+type sensorReading struct {
+	Reading *float64
+}
+
+type storage struct {
+	Drives []link
+}
+
+type drive struct {
+	ID                            string `json:"Id"`
+	Name                          string
+	Manufacturer                  string
+	Model                         string
+	SerialNumber                  string
+	MediaType                     string
+	Protocol                      string
+	CapacityBytes                 *int64
+	FailurePredicted              *bool
+	PredictedMediaLifeLeftPercent *float64
+	NegotiatedSpeedGbs            *float64
+	Status                        status
+}
+
 type location struct {
 	PostalAddress struct {
 		DataCenter string
@@ -166,16 +241,12 @@ func (r *Redfish) Init() error {
 		return errors.New("did not provide username and password")
 	}
 
-	if r.ComputerSystemID == "" {
-		return errors.New("did not provide the computer system ID of the resource")
-	}
-
 	if len(r.IncludeMetrics) == 0 {
 		return errors.New("no metrics specified to collect")
 	}
 	for _, metric := range r.IncludeMetrics {
 		switch metric {
-		case "thermal", "power":
+		case "thermal", "power", "drives":
 		default:
 			return fmt.Errorf("unknown metric requested: %s", metric)
 		}
@@ -211,6 +282,7 @@ func (r *Redfish) Init() error {
 		},
 		Timeout: time.Duration(r.Timeout),
 	}
+	r.cache = make(map[string]cachedResponse)
 
 	return nil
 }
@@ -221,12 +293,43 @@ func (r *Redfish) Gather(acc telegraf.Accumulator) error {
 		address = r.baseURL.Host
 	}
 
-	system, err := r.getComputerSystem(r.ComputerSystemID)
-	if err != nil {
-		return err
+	// Walk all systems if no system is configured
+	refs := []string{path.Join("/redfish/v1/Systems/", r.ComputerSystemID)}
+	if r.ComputerSystemID == "" {
+		refs, err = r.getMembers("/redfish/v1/Systems")
+		if err != nil {
+			return err
+		}
+	}
+
+	// Chassis might be shared by multiple systems so only collect them once
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		system, err := r.getComputerSystem(ref)
+		if err != nil {
+			return err
+		}
+
+		if err := r.gatherSystem(acc, address, system, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Redfish) gatherSystem(acc telegraf.Accumulator, address string, system *system, seen map[string]bool) error {
+	if slices.Contains(r.IncludeMetrics, "drives") {
+		if err := r.gatherDrives(acc, address, system); err != nil {
+			return err
+		}
 	}
 
 	for _, link := range system.Links.Chassis {
+		if seen[link.Ref] {
+			continue
+		}
+		seen[link.Ref] = true
+
 		chassis, err := r.getChassis(link.Ref)
 		if err != nil {
 			return err
@@ -239,6 +342,8 @@ func (r *Redfish) Gather(acc telegraf.Accumulator) error {
 				err = r.gatherThermal(acc, address, system, chassis)
 			case "power":
 				err = r.gatherPower(acc, address, system, chassis)
+			case "drives":
+				// Drives are collected per system
 			default:
 				return fmt.Errorf("unknown metric requested: %s", metric)
 			}
@@ -280,24 +385,39 @@ func (r *Redfish) getData(address string, payload interface{}) error {
 		req.Header.Del("OData-Version")
 	}
 
+	// Only transfer the resource if it changed since the last request
+	cached, found := r.cache[address]
+	if found {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
 	resp, err := r.client.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
+	var body []byte
+	switch {
+	case resp.StatusCode == http.StatusNotModified && found:
+		body = cached.body
+	case resp.StatusCode == http.StatusOK:
+		body, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			r.cache[address] = cachedResponse{etag: etag, body: body}
+		} else {
+			delete(r.cache, address)
+		}
+	default:
 		return fmt.Errorf("received status code %d (%s) for address %s, expected 200",
 			resp.StatusCode,
 			http.StatusText(resp.StatusCode),
 			address)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
-
 	err = json.Unmarshal(body, &payload)
 	if err != nil {
 		return fmt.Errorf("error parsing input: %w", err)
@@ -306,8 +426,23 @@ func (r *Redfish) getData(address string, payload interface{}) error {
 	return nil
 }
 
-func (r *Redfish) getComputerSystem(id string) (*system, error) {
-	loc := r.baseURL.ResolveReference(&url.URL{Path: path.Join("/redfish/v1/Systems/", id)})
+func (r *Redfish) getMembers(ref string) ([]string, error) {
+	loc := r.baseURL.ResolveReference(&url.URL{Path: ref})
+	collection := &collection{}
+	err := r.getData(loc.String(), collection)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]string, 0, len(collection.Members))
+	for _, member := range collection.Members {
+		refs = append(refs, member.Ref)
+	}
+	return refs, nil
+}
+
+func (r *Redfish) getComputerSystem(ref string) (*system, error) {
+	loc := r.baseURL.ResolveReference(&url.URL{Path: ref})
 	system := &system{}
 	err := r.getData(loc.String(), system)
 	if err != nil {
@@ -346,6 +481,19 @@ func (r *Redfish) getThermal(ref string) (*thermal, error) {
 	return thermal, nil
 }
 
+// setTagSets adds the tags of the configured tag sets of the chassis
+func (r *Redfish) setTagSets(chassis *chassis, tags map[string]string) {
+	if _, ok := r.tagSet[tagSetChassisLocation]; ok && chassis.Location != nil {
+		tags["datacenter"] = chassis.Location.PostalAddress.DataCenter
+		tags["room"] = chassis.Location.PostalAddress.Room
+		tags["rack"] = chassis.Location.Placement.Rack
+		tags["row"] = chassis.Location.Placement.Row
+	}
+	if _, ok := r.tagSet[tagSetChassis]; ok {
+		setChassisTags(chassis, tags)
+	}
+}
+
 func setChassisTags(chassis *chassis, tags map[string]string) {
 	tags["chassis_chassistype"] = chassis.ChassisType
 	tags["chassis_manufacturer"] = chassis.Manufacturer
@@ -359,6 +507,11 @@ func setChassisTags(chassis *chassis, tags map[string]string) {
 }
 
 func (r *Redfish) gatherThermal(acc telegraf.Accumulator, address string, system *system, chassis *chassis) error {
+	// Newer implementations might only provide the thermal subsystem
+	if chassis.Thermal.Ref == "" && chassis.ThermalSubsystem.Ref != "" {
+		return r.gatherThermalSubsystem(acc, address, system, chassis)
+	}
+
 	thermal, err := r.getThermal(chassis.Thermal.Ref)
 	if err != nil {
 		return err
@@ -372,15 +525,7 @@ func (r *Redfish) gatherThermal(acc telegraf.Accumulator, address string, system
 		tags["source"] = system.Hostname
 		tags["state"] = j.Status.State
 		tags["health"] = j.Status.Health
-		if _, ok := r.tagSet[tagSetChassisLocation]; ok && chassis.Location != nil {
-			tags["datacenter"] = chassis.Location.PostalAddress.DataCenter
-			tags["room"] = chassis.Location.PostalAddress.Room
-			tags["rack"] = chassis.Location.Placement.Rack
-			tags["row"] = chassis.Location.Placement.Row
-		}
-		if _, ok := r.tagSet[tagSetChassis]; ok {
-			setChassisTags(chassis, tags)
-		}
+		r.setTagSets(chassis, tags)
 
 		fields := make(map[string]interface{})
 		fields["reading_celsius"] = j.ReadingCelsius
@@ -403,15 +548,7 @@ func (r *Redfish) gatherThermal(acc telegraf.Accumulator, address string, system
 		tags["source"] = system.Hostname
 		tags["state"] = j.Status.State
 		tags["health"] = j.Status.Health
-		if _, ok := r.tagSet[tagSetChassisLocation]; ok && chassis.Location != nil {
-			tags["datacenter"] = chassis.Location.PostalAddress.DataCenter
-			tags["room"] = chassis.Location.PostalAddress.Room
-			tags["rack"] = chassis.Location.Placement.Rack
-			tags["row"] = chassis.Location.Placement.Row
-		}
-		if _, ok := r.tagSet[tagSetChassis]; ok {
-			setChassisTags(chassis, tags)
-		}
+		r.setTagSets(chassis, tags)
 
 		if j.ReadingUnits != nil && *j.ReadingUnits == "RPM" {
 			fields["upper_threshold_critical"] = j.UpperThresholdCritical
@@ -431,6 +568,11 @@ func (r *Redfish) gatherThermal(acc telegraf.Accumulator, address string, system
 }
 
 func (r *Redfish) gatherPower(acc telegraf.Accumulator, address string, system *system, chassis *chassis) error {
+	// Newer implementations might only provide the power subsystem
+	if chassis.Power.Ref == "" && chassis.PowerSubsystem.Ref != "" {
+		return r.gatherPowerSubsystem(acc, address, system, chassis)
+	}
+
 	power, err := r.getPower(chassis.Power.Ref)
 	if err != nil {
 		return err
@@ -443,15 +585,7 @@ func (r *Redfish) gatherPower(acc telegraf.Accumulator, address string, system *
 			"name":      j.Name,
 			"source":    system.Hostname,
 		}
-		if _, ok := r.tagSet[tagSetChassisLocation]; ok && chassis.Location != nil {
-			tags["datacenter"] = chassis.Location.PostalAddress.DataCenter
-			tags["room"] = chassis.Location.PostalAddress.Room
-			tags["rack"] = chassis.Location.Placement.Rack
-			tags["row"] = chassis.Location.Placement.Row
-		}
-		if _, ok := r.tagSet[tagSetChassis]; ok {
-			setChassisTags(chassis, tags)
-		}
+		r.setTagSets(chassis, tags)
 
 		fields := map[string]interface{}{
 			"power_allocated_watts":  j.PowerAllocatedWatts,
@@ -476,15 +610,7 @@ func (r *Redfish) gatherPower(acc telegraf.Accumulator, address string, system *
 		tags["source"] = system.Hostname
 		tags["state"] = j.Status.State
 		tags["health"] = j.Status.Health
-		if _, ok := r.tagSet[tagSetChassisLocation]; ok && chassis.Location != nil {
-			tags["datacenter"] = chassis.Location.PostalAddress.DataCenter
-			tags["room"] = chassis.Location.PostalAddress.Room
-			tags["rack"] = chassis.Location.Placement.Rack
-			tags["row"] = chassis.Location.Placement.Row
-		}
-		if _, ok := r.tagSet[tagSetChassis]; ok {
-			setChassisTags(chassis, tags)
-		}
+		r.setTagSets(chassis, tags)
 
 		fields := make(map[string]interface{})
 		fields["power_input_watts"] = j.PowerInputWatts
@@ -503,15 +629,7 @@ func (r *Redfish) gatherPower(acc telegraf.Accumulator, address string, system *
 		tags["source"] = system.Hostname
 		tags["state"] = j.Status.State
 		tags["health"] = j.Status.Health
-		if _, ok := r.tagSet[tagSetChassisLocation]; ok && chassis.Location != nil {
-			tags["datacenter"] = chassis.Location.PostalAddress.DataCenter
-			tags["room"] = chassis.Location.PostalAddress.Room
-			tags["rack"] = chassis.Location.Placement.Rack
-			tags["row"] = chassis.Location.Placement.Row
-		}
-		if _, ok := r.tagSet[tagSetChassis]; ok {
-			setChassisTags(chassis, tags)
-		}
+		r.setTagSets(chassis, tags)
 
 		fields := make(map[string]interface{})
 		fields["reading_volts"] = j.ReadingVolts
@@ -525,6 +643,152 @@ func (r *Redfish) gatherPower(acc telegraf.Accumulator, address string, system *
 	return nil
 }
 
+func (r *Redfish) gatherThermalSubsystem(acc telegraf.Accumulator, address string, system *system, chassis *chassis) error {
+	loc := r.baseURL.ResolveReference(&url.URL{Path: chassis.ThermalSubsystem.Ref})
+	subsystem := &thermalSubsystem{}
+	if err := r.getData(loc.String(), subsystem); err != nil {
+		return err
+	}
+	if subsystem.Fans.Ref == "" {
+		return nil
+	}
+
+	refs, err := r.getMembers(subsystem.Fans.Ref)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		loc := r.baseURL.ResolveReference(&url.URL{Path: ref})
+		j := &fan{}
+		if err := r.getData(loc.String(), j); err != nil {
+			return err
+		}
+
+		tags := map[string]string{
+			"member_id": j.ID,
+			"address":   address,
+			"name":      j.Name,
+			"source":    system.Hostname,
+			"state":     j.Status.State,
+			"health":    j.Status.Health,
+		}
+		r.setTagSets(chassis, tags)
+
+		// Use integer values to be compatible with the thermal resource
+		fields := make(map[string]interface{}, 2)
+		if j.SpeedPercent.Reading != nil {
+			fields["reading_percent"] = int64(math.Round(*j.SpeedPercent.Reading))
+		}
+		if j.SpeedPercent.SpeedRPM != nil {
+			fields["reading_rpm"] = int64(math.Round(*j.SpeedPercent.SpeedRPM))
+		}
+		acc.AddFields("redfish_thermal_fans", fields, tags)
+	}
+
+	return nil
+}
+
+func (r *Redfish) gatherPowerSubsystem(acc telegraf.Accumulator, address string, system *system, chassis *chassis) error {
+	loc := r.baseURL.ResolveReference(&url.URL{Path: chassis.PowerSubsystem.Ref})
+	subsystem := &powerSubsystem{}
+	if err := r.getData(loc.String(), subsystem); err != nil {
+		return err
+	}
+	if subsystem.PowerSupplies.Ref == "" {
+		return nil
+	}
+
+	refs, err := r.getMembers(subsystem.PowerSupplies.Ref)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		loc := r.baseURL.ResolveReference(&url.URL{Path: ref})
+		j := &powerSupply{}
+		if err := r.getData(loc.String(), j); err != nil {
+			return err
+		}
+
+		metrics := &powerSupplyMetrics{}
+		if j.Metrics.Ref != "" {
+			loc := r.baseURL.ResolveReference(&url.URL{Path: j.Metrics.Ref})
+			if err := r.getData(loc.String(), metrics); err != nil {
+				return err
+			}
+		}
+
+		tags := map[string]string{
+			"member_id": j.ID,
+			"address":   address,
+			"name":      j.Name,
+			"source":    system.Hostname,
+			"state":     j.Status.State,
+			"health":    j.Status.Health,
+		}
+		r.setTagSets(chassis, tags)
+
+		fields := map[string]interface{}{
+			"power_input_watts":    metrics.InputPowerWatts.Reading,
+			"power_output_watts":   metrics.OutputPowerWatts.Reading,
+			"line_input_voltage":   metrics.InputVoltage.Reading,
+			"power_capacity_watts": j.PowerCapacityWatts,
+		}
+		acc.AddFields("redfish_power_powersupplies", fields, tags)
+	}
+
+	return nil
+}
+
+func (r *Redfish) gatherDrives(acc telegraf.Accumulator, address string, system *system) error {
+	if system.Storage.Ref == "" {
+		return nil
+	}
+
+	refs, err := r.getMembers(system.Storage.Ref)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		loc := r.baseURL.ResolveReference(&url.URL{Path: ref})
+		storage := &storage{}
+		if err := r.getData(loc.String(), storage); err != nil {
+			return err
+		}
+
+		for _, link := range storage.Drives {
+			loc := r.baseURL.ResolveReference(&url.URL{Path: link.Ref})
+			j := &drive{}
+			if err := r.getData(loc.String(), j); err != nil {
+				return err
+			}
+
+			tags := map[string]string{
+				"member_id":     j.ID,
+				"address":       address,
+				"name":          j.Name,
+				"source":        system.Hostname,
+				"manufacturer":  j.Manufacturer,
+				"model":         j.Model,
+				"serial_number": j.SerialNumber,
+				"media_type":    j.MediaType,
+				"protocol":      j.Protocol,
+				"state":         j.Status.State,
+				"health":        j.Status.Health,
+			}
+
+			fields := map[string]interface{}{
+				"capacity_bytes":                    j.CapacityBytes,
+				"failure_predicted":                 j.FailurePredicted,
+				"predicted_media_life_left_percent": j.PredictedMediaLifeLeftPercent,
+				"negotiated_speed_gbs":              j.NegotiatedSpeedGbs,
+			}
+			acc.AddFields("redfish_drives", fields, tags)
+		}
+	}
+
+	return nil
+}
+
 func init() {
 	inputs.Add("redfish", func() telegraf.Input {
 		return &Redfish{
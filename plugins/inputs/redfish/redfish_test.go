@@ -12,6 +12,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -1212,3 +1213,136 @@ func TestIncludeTagSetsConfiguration(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expectedMetricsHp, hpAcc.GetTelegrafMetrics(),
 		testutil.IgnoreTime())
 }
+
+func newGenericServer(notModified *int) *httptest.Server {
+	files := map[string]string{
+		"/redfish/v1/Systems":                                                     "testdata/generic_systems.json",
+		"/redfish/v1/Systems/node1":                                               "testdata/generic_system_node1.json",
+		"/redfish/v1/Systems/node2":                                               "testdata/generic_system_node2.json",
+		"/redfish/v1/Systems/node1/Storage":                                       "testdata/generic_storages.json",
+		"/redfish/v1/Systems/node1/Storage/RAID1":                                 "testdata/generic_storage.json",
+		"/redfish/v1/Systems/node1/Storage/RAID1/Drives/Disk0":                    "testdata/generic_drive.json",
+		"/redfish/v1/Chassis/enclosure":                                           "testdata/generic_chassis.json",
+		"/redfish/v1/Chassis/enclosure/PowerSubsystem":                            "testdata/generic_powersubsystem.json",
+		"/redfish/v1/Chassis/enclosure/PowerSubsystem/PowerSupplies":              "testdata/generic_powersupplies.json",
+		"/redfish/v1/Chassis/enclosure/PowerSubsystem/PowerSupplies/PSU1":         "testdata/generic_powersupply.json",
+		"/redfish/v1/Chassis/enclosure/PowerSubsystem/PowerSupplies/PSU1/Metrics": "testdata/generic_powersupply_metrics.json",
+		"/redfish/v1/Chassis/enclosure/ThermalSubsystem":                          "testdata/generic_thermalsubsystem.json",
+		"/redfish/v1/Chassis/enclosure/ThermalSubsystem/Fans":                     "testdata/generic_fans.json",
+		"/redfish/v1/Chassis/enclosure/ThermalSubsystem/Fans/Fan1":                "testdata/generic_fan.json",
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !checkAuth(r, "test", "test") {
+			http.Error(w, "Unauthorized.", http.StatusUnauthorized)
+			return
+		}
+
+		fn, found := files[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		// Use the path as ETag as the resources never change
+		etag := `"` + r.URL.Path + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			*notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		http.ServeFile(w, r, fn)
+	}))
+}
+
+func TestGenericDiscovery(t *testing.T) {
+	var notModified int
+	ts := newGenericServer(&notModified)
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	address, _, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"redfish_drives",
+			map[string]string{
+				"member_id":     "Disk0",
+				"address":       address,
+				"name":          "Solid State Disk 0",
+				"source":        "node1.example.com",
+				"manufacturer":  "Contoso",
+				"model":         "SSD-960",
+				"serial_number": "S3EWNX0K",
+				"media_type":    "SSD",
+				"protocol":      "SATA",
+				"state":         "Enabled",
+				"health":        "OK",
+			},
+			map[string]interface{}{
+				"capacity_bytes":                    int64(960197124096),
+				"failure_predicted":                 false,
+				"predicted_media_life_left_percent": 97.0,
+				"negotiated_speed_gbs":              6.0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"redfish_thermal_fans",
+			map[string]string{
+				"member_id": "Fan1",
+				"address":   address,
+				"name":      "Fan 1",
+				"source":    "node1.example.com",
+				"state":     "Enabled",
+				"health":    "OK",
+			},
+			map[string]interface{}{
+				"reading_percent": int64(43),
+				"reading_rpm":     int64(5120),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"redfish_power_powersupplies",
+			map[string]string{
+				"member_id": "PSU1",
+				"address":   address,
+				"name":      "Power Supply 1",
+				"source":    "node1.example.com",
+				"state":     "Enabled",
+				"health":    "OK",
+			},
+			map[string]interface{}{
+				"power_input_watts":    412.0,
+				"power_output_watts":   385.25,
+				"line_input_voltage":   230.5,
+				"power_capacity_watts": 1600.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	plugin := &Redfish{
+		Address:        ts.URL,
+		Username:       config.NewSecret([]byte("test")),
+		Password:       config.NewSecret([]byte("test")),
+		IncludeMetrics: []string{"drives", "thermal", "power"},
+	}
+	require.NoError(t, plugin.Init())
+
+	// The chassis is shared by both systems and must only be collected once
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	require.Zero(t, notModified)
+
+	// Unchanged resources must be served from the cache
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	require.Equal(t, 14, notModified)
+}
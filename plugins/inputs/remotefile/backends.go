@@ -0,0 +1,8 @@
+package remotefile
+
+import (
+	// Register backends
+	_ "github.com/rclone/rclone/backend/ftp"
+	_ "github.com/rclone/rclone/backend/local"
+	_ "github.com/rclone/rclone/backend/sftp"
+)
@@ -0,0 +1,337 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package remotefile
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/fspath"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var once sync.Once
+
+type RemoteFile struct {
+	Remote           config.Secret   `toml:"remote"`
+	Files            []string        `toml:"files"`
+	Recursive        bool            `toml:"recursive"`
+	MinAge           config.Duration `toml:"min_age"`
+	AfterProcessing  string          `toml:"after_processing"`
+	ArchiveDirectory string          `toml:"archive_directory"`
+	RenameSuffix     string          `toml:"rename_suffix"`
+	ErrorDirectory   string          `toml:"error_directory"`
+	FileTag          string          `toml:"file_tag"`
+	Log              telegraf.Logger `toml:"-"`
+
+	root       *vfs.VFS
+	fscancel   context.CancelFunc
+	filter     filter.Filter
+	parserFunc telegraf.ParserFunc
+
+	// State of the plugin mapping the filename to the size and modification
+	// time of the file when it was processed
+	processed    map[string]fileState
+	processedMtx sync.Mutex
+}
+
+type fileState struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modtime"`
+}
+
+func (*RemoteFile) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *RemoteFile) SetParserFunc(fn telegraf.ParserFunc) {
+	r.parserFunc = fn
+}
+
+func (r *RemoteFile) Init() error {
+	if r.Remote.Empty() {
+		return errors.New("'remote' required")
+	}
+
+	if r.AfterProcessing == "" {
+		r.AfterProcessing = "keep"
+	}
+	if err := choice.Check(r.AfterProcessing, []string{"keep", "delete", "move", "rename"}); err != nil {
+		return fmt.Errorf("config option after_processing: %w", err)
+	}
+
+	switch r.AfterProcessing {
+	case "move":
+		if r.ArchiveDirectory == "" {
+			return errors.New("'archive_directory' required for 'move' action")
+		}
+	case "rename":
+		if r.RenameSuffix == "" {
+			return errors.New("'rename_suffix' required for 'rename' action")
+		}
+	}
+
+	f, err := filter.Compile(r.Files)
+	if err != nil {
+		return fmt.Errorf("creating file filter failed: %w", err)
+	}
+	r.filter = f
+
+	fs.LogOutput = func(level fs.LogLevel, text string) {
+		r.Log.Tracef("[%s] %s", level.String(), text)
+	}
+
+	r.processed = make(map[string]fileState)
+
+	return nil
+}
+
+func (r *RemoteFile) GetState() interface{} {
+	r.processedMtx.Lock()
+	defer r.processedMtx.Unlock()
+
+	state := make(map[string]fileState, len(r.processed))
+	for k, v := range r.processed {
+		state[k] = v
+	}
+	return state
+}
+
+func (r *RemoteFile) SetState(state interface{}) error {
+	processed, ok := state.(map[string]fileState)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", state)
+	}
+
+	r.processedMtx.Lock()
+	defer r.processedMtx.Unlock()
+	for k, v := range processed {
+		r.processed[k] = v
+	}
+	return nil
+}
+
+func (r *RemoteFile) Start(telegraf.Accumulator) error {
+	remoteRaw, err := r.Remote.Get()
+	if err != nil {
+		return fmt.Errorf("getting remote secret failed: %w", err)
+	}
+	remote := remoteRaw.String()
+	remoteRaw.Destroy()
+
+	// Construct the underlying filesystem config
+	parsed, err := fspath.Parse(remote)
+	if err != nil {
+		return fmt.Errorf("parsing remote failed: %w", err)
+	}
+	info, err := fs.Find(parsed.Name)
+	if err != nil {
+		return fmt.Errorf("cannot find remote type %q: %w", parsed.Name, err)
+	}
+
+	// Setup the remote virtual filesystem without caching as we only read
+	// each file once
+	ctx, cancel := context.WithCancel(context.Background())
+	rootfs, err := info.NewFs(ctx, parsed.Name, parsed.Path, fs.ConfigMap(info.Prefix, info.Options, parsed.Name, parsed.Config))
+	if err != nil {
+		cancel()
+		return fmt.Errorf("creating remote failed: %w", err)
+	}
+	opts := vfscommon.Opt
+	opts.CacheMode = vfscommon.CacheModeOff
+
+	r.fscancel = cancel
+	r.root = vfs.New(rootfs, &opts)
+	r.Log.Debugf("Connected to %s", r.root.Fs().String())
+
+	return nil
+}
+
+func (r *RemoteFile) Stop() {
+	if r.root != nil {
+		r.root.Shutdown()
+		r.root = nil
+	}
+
+	if r.fscancel != nil {
+		r.fscancel()
+		r.fscancel = nil
+	}
+}
+
+func (r *RemoteFile) Gather(acc telegraf.Accumulator) error {
+	// Make sure we see the current content of the remote directory
+	r.root.FlushDirCache()
+
+	files, err := r.list("")
+	if err != nil {
+		return fmt.Errorf("listing files failed: %w", err)
+	}
+
+	r.processedMtx.Lock()
+	defer r.processedMtx.Unlock()
+
+	present := make(map[string]bool, len(files))
+	for _, file := range files {
+		fn := file.name
+		present[fn] = true
+
+		// Skip files we already handled and that did not change since then
+		current := fileState{Size: file.info.Size(), ModTime: file.info.ModTime()}
+		if previous, found := r.processed[fn]; found && previous.Size == current.Size && previous.ModTime.Equal(current.ModTime) {
+			continue
+		}
+
+		// Skip files that might still be written to
+		if r.MinAge > 0 && time.Since(current.ModTime) < time.Duration(r.MinAge) {
+			continue
+		}
+
+		if err := r.process(acc, fn); err != nil {
+			acc.AddError(fmt.Errorf("processing file %q failed: %w", fn, err))
+			if r.ErrorDirectory != "" {
+				if err := r.move(fn, r.ErrorDirectory); err != nil {
+					acc.AddError(fmt.Errorf("moving file %q to error directory failed: %w", fn, err))
+				}
+			}
+		} else if err := r.archive(fn); err != nil {
+			acc.AddError(fmt.Errorf("archiving file %q failed: %w", fn, err))
+		}
+
+		// Remember the file to avoid processing it again even if an error
+		// occurred as the file will fail again unless it is changed.
+		r.processed[fn] = current
+	}
+
+	// Cleanup the state for files that do not exist anymore
+	for fn := range r.processed {
+		if !present[fn] {
+			delete(r.processed, fn)
+		}
+	}
+
+	return nil
+}
+
+type remoteFileInfo struct {
+	name string
+	info os.FileInfo
+}
+
+func (r *RemoteFile) list(dir string) ([]remoteFileInfo, error) {
+	entries, err := r.root.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]remoteFileInfo, 0, len(entries))
+	for _, entry := range entries {
+		fn := path.Join(dir, entry.Name())
+		if entry.IsDir() {
+			// Do not descend into the archive or error directory to avoid
+			// processing the files again
+			if !r.Recursive || fn == path.Clean(r.ArchiveDirectory) || fn == path.Clean(r.ErrorDirectory) {
+				continue
+			}
+			subfiles, err := r.list(fn)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, subfiles...)
+			continue
+		}
+
+		if r.filter != nil && !r.filter.Match(fn) {
+			continue
+		}
+		// Skip files renamed after processing to avoid processing them again
+		if r.AfterProcessing == "rename" && strings.HasSuffix(fn, r.RenameSuffix) {
+			continue
+		}
+		files = append(files, remoteFileInfo{name: fn, info: entry})
+	}
+
+	return files, nil
+}
+
+func (r *RemoteFile) process(acc telegraf.Accumulator, fn string) error {
+	file, err := r.root.Open(fn)
+	if err != nil {
+		return err
+	}
+	buf, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("reading failed: %w", err)
+	}
+
+	parser, err := r.parserFunc()
+	if err != nil {
+		return fmt.Errorf("creating parser failed: %w", err)
+	}
+	metrics, err := parser.Parse(buf)
+	if err != nil && !errors.Is(err, parsers.ErrEOF) {
+		return fmt.Errorf("parsing failed: %w", err)
+	}
+	if len(metrics) == 0 {
+		once.Do(func() {
+			r.Log.Debug(internal.NoMetricsCreatedMsg)
+		})
+	}
+
+	for _, m := range metrics {
+		if r.FileTag != "" {
+			m.AddTag(r.FileTag, path.Base(fn))
+		}
+		acc.AddMetric(m)
+	}
+
+	return nil
+}
+
+func (r *RemoteFile) archive(fn string) error {
+	switch r.AfterProcessing {
+	case "delete":
+		return r.root.Remove(fn)
+	case "move":
+		return r.move(fn, r.ArchiveDirectory)
+	case "rename":
+		return r.root.Rename(fn, fn+r.RenameSuffix)
+	}
+	return nil
+}
+
+func (r *RemoteFile) move(fn, dir string) error {
+	// Keep the directory hierarchy of the source in the destination
+	dst := path.Join(dir, fn)
+	if err := r.root.MkdirAll(path.Dir(dst), os.FileMode(r.root.Opt.DirPerms)); err != nil {
+		return fmt.Errorf("creating directory %q failed: %w", path.Dir(dst), err)
+	}
+	return r.root.Rename(fn, dst)
+}
+
+func init() {
+	inputs.Add("remotefile", func() telegraf.Input {
+		return &RemoteFile{}
+	})
+}
@@ -0,0 +1,242 @@
+package remotefile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *RemoteFile
+		expected string
+	}{
+		{
+			name:     "missing remote",
+			plugin:   &RemoteFile{},
+			expected: "'remote' required",
+		},
+		{
+			name: "invalid action",
+			plugin: &RemoteFile{
+				Remote:          config.NewSecret([]byte("local:")),
+				AfterProcessing: "foo",
+			},
+			expected: "config option after_processing",
+		},
+		{
+			name: "move without directory",
+			plugin: &RemoteFile{
+				Remote:          config.NewSecret([]byte("local:")),
+				AfterProcessing: "move",
+			},
+			expected: "'archive_directory' required",
+		},
+		{
+			name: "rename without suffix",
+			plugin: &RemoteFile{
+				Remote:          config.NewSecret([]byte("local:")),
+				AfterProcessing: "rename",
+			},
+			expected: "'rename_suffix' required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = &testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestAfterProcessing(t *testing.T) {
+	tests := []struct {
+		name      string
+		action    string
+		remaining []string
+	}{
+		{
+			name:      "keep",
+			action:    "keep",
+			remaining: []string{"data.influx", "ignored.txt"},
+		},
+		{
+			name:      "delete",
+			action:    "delete",
+			remaining: []string{"ignored.txt"},
+		},
+		{
+			name:      "move",
+			action:    "move",
+			remaining: []string{"archive/data.influx", "ignored.txt"},
+		},
+		{
+			name:      "rename",
+			action:    "rename",
+			remaining: []string{"data.influx.done", "ignored.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpdir := t.TempDir()
+			content := "test,source=localhost value=42i 1719410485000000000\n"
+			require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "data.influx"), []byte(content), 0600))
+			require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "ignored.txt"), []byte("foo"), 0600))
+
+			plugin := &RemoteFile{
+				Remote:           config.NewSecret([]byte("local:" + tmpdir)),
+				Files:            []string{"*.influx"},
+				Recursive:        true,
+				AfterProcessing:  tt.action,
+				ArchiveDirectory: "archive",
+				RenameSuffix:     ".done",
+				FileTag:          "file",
+				Log:              &testutil.Logger{},
+			}
+			plugin.SetParserFunc(newInfluxParser)
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			defer plugin.Stop()
+
+			// Gather twice to make sure the files are not processed again
+			require.NoError(t, plugin.Gather(&acc))
+			require.NoError(t, plugin.Gather(&acc))
+			require.Empty(t, acc.Errors)
+
+			expected := []telegraf.Metric{
+				metric.New(
+					"test",
+					map[string]string{"source": "localhost", "file": "data.influx"},
+					map[string]interface{}{"value": 42},
+					time.Unix(1719410485, 0),
+				),
+			}
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+			var remaining []string
+			require.NoError(t, filepath.WalkDir(tmpdir, func(fn string, d os.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				rel, err := filepath.Rel(tmpdir, fn)
+				remaining = append(remaining, filepath.ToSlash(rel))
+				return err
+			}))
+			require.ElementsMatch(t, tt.remaining, remaining)
+		})
+	}
+}
+
+func TestRenameWithoutFilter(t *testing.T) {
+	tmpdir := t.TempDir()
+	content := "test,source=localhost value=42i 1719410485000000000\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "data.influx"), []byte(content), 0600))
+
+	plugin := &RemoteFile{
+		Remote:          config.NewSecret([]byte("local:" + tmpdir)),
+		AfterProcessing: "rename",
+		RenameSuffix:    ".done",
+		Log:             &testutil.Logger{},
+	}
+	plugin.SetParserFunc(newInfluxParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// The renamed file must not be processed again in the next gather cycle
+	require.NoError(t, plugin.Gather(&acc))
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	require.NoFileExists(t, filepath.Join(tmpdir, "data.influx"))
+	require.FileExists(t, filepath.Join(tmpdir, "data.influx.done"))
+}
+
+func TestErrorDirectory(t *testing.T) {
+	tmpdir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "invalid.influx"), []byte("garbage\n"), 0600))
+
+	plugin := &RemoteFile{
+		Remote:          config.NewSecret([]byte("local:" + tmpdir)),
+		AfterProcessing: "delete",
+		ErrorDirectory:  "failed",
+		Log:             &testutil.Logger{},
+	}
+	plugin.SetParserFunc(newInfluxParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.NoFileExists(t, filepath.Join(tmpdir, "invalid.influx"))
+	require.FileExists(t, filepath.Join(tmpdir, "failed", "invalid.influx"))
+}
+
+func TestStatePersistence(t *testing.T) {
+	tmpdir := t.TempDir()
+	content := "test,source=localhost value=42i 1719410485000000000\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "data.influx"), []byte(content), 0600))
+
+	// Process the file with a first instance of the plugin
+	plugin := &RemoteFile{
+		Remote: config.NewSecret([]byte("local:" + tmpdir)),
+		Log:    &testutil.Logger{},
+	}
+	plugin.SetParserFunc(newInfluxParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Gather(&acc))
+	plugin.Stop()
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	state := plugin.GetState()
+
+	// Restore the state in a second instance and make sure the file is not
+	// processed again
+	restarted := &RemoteFile{
+		Remote: config.NewSecret([]byte("local:" + tmpdir)),
+		Log:    &testutil.Logger{},
+	}
+	restarted.SetParserFunc(newInfluxParser)
+	require.NoError(t, restarted.Init())
+	require.NoError(t, restarted.SetState(state))
+
+	acc.ClearMetrics()
+	require.NoError(t, restarted.Start(&acc))
+	defer restarted.Stop()
+	require.NoError(t, restarted.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// Modifying the file should trigger processing again
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(tmpdir, "data.influx"), future, future))
+	require.NoError(t, restarted.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
+
+func newInfluxParser() (telegraf.Parser, error) {
+	parser := &influx.Parser{}
+	err := parser.Init()
+	return parser, err
+}
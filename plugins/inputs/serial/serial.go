@@ -0,0 +1,288 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package serial
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"go.bug.st/serial"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/socket"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var once sync.Once
+
+var parities = map[string]serial.Parity{
+	"none":  serial.NoParity,
+	"odd":   serial.OddParity,
+	"even":  serial.EvenParity,
+	"mark":  serial.MarkParity,
+	"space": serial.SpaceParity,
+}
+
+var flowControls = []string{"none", "hardware", "software"}
+
+var stopBits = map[string]serial.StopBits{
+	"1":   serial.OneStopBit,
+	"1.5": serial.OnePointFiveStopBits,
+	"2":   serial.TwoStopBits,
+}
+
+type Serial struct {
+	Port              string          `toml:"port"`
+	BaudRate          int             `toml:"baud_rate"`
+	DataBits          int             `toml:"data_bits"`
+	Parity            string          `toml:"parity"`
+	StopBits          string          `toml:"stop_bits"`
+	FlowControl       string          `toml:"flow_control"`
+	RTS               bool            `toml:"rts"`
+	DTR               bool            `toml:"dtr"`
+	ReconnectInterval config.Duration `toml:"reconnect_interval"`
+	MaxMessageSize    config.Size     `toml:"max_message_size"`
+	Log               telegraf.Logger `toml:"-"`
+	socket.SplitConfig
+
+	mode     *serial.Mode
+	splitter bufio.SplitFunc
+	parser   telegraf.Parser
+
+	// Function for opening the port, can be replaced for testing
+	open func() (io.ReadCloser, error)
+
+	port    io.ReadCloser
+	portMtx sync.Mutex
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func (*Serial) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Serial) SetParser(parser telegraf.Parser) {
+	s.parser = parser
+}
+
+func (s *Serial) Init() error {
+	if s.Port == "" {
+		return errors.New("'port' required")
+	}
+
+	if s.BaudRate <= 0 {
+		return fmt.Errorf("invalid baud rate %d", s.BaudRate)
+	}
+	if s.DataBits < 5 || s.DataBits > 8 {
+		return fmt.Errorf("invalid data bits %d", s.DataBits)
+	}
+	parity, found := parities[s.Parity]
+	if !found {
+		return fmt.Errorf("invalid parity %q", s.Parity)
+	}
+	stop, found := stopBits[s.StopBits]
+	if !found {
+		return fmt.Errorf("invalid stop bits %q", s.StopBits)
+	}
+	if !slices.Contains(flowControls, s.FlowControl) {
+		return fmt.Errorf("invalid flow control %q", s.FlowControl)
+	}
+	if s.FlowControl != "none" && !flowControlSupported {
+		return fmt.Errorf("flow control %q is not supported on this platform", s.FlowControl)
+	}
+	if s.ReconnectInterval <= 0 {
+		return errors.New("'reconnect_interval' must be positive")
+	}
+
+	splitter, err := s.SplitConfig.NewSplitter()
+	if err != nil {
+		return err
+	}
+	s.splitter = splitter
+
+	s.mode = &serial.Mode{
+		BaudRate: s.BaudRate,
+		DataBits: s.DataBits,
+		Parity:   parity,
+		StopBits: stop,
+		InitialStatusBits: &serial.ModemOutputBits{
+			RTS: s.RTS,
+			DTR: s.DTR,
+		},
+	}
+
+	if s.open == nil {
+		s.open = func() (io.ReadCloser, error) {
+			port, err := serial.Open(s.Port, s.mode)
+			if err != nil {
+				return nil, err
+			}
+			if err := setFlowControl(s.Port, s.FlowControl); err != nil {
+				port.Close()
+				return nil, fmt.Errorf("setting flow control failed: %w", err)
+			}
+			return port, nil
+		}
+	}
+
+	return nil
+}
+
+func (s *Serial) Start(acc telegraf.Accumulator) error {
+	port, err := s.open()
+	if err != nil {
+		return &internal.StartupError{
+			Err:   fmt.Errorf("opening port %q failed: %w", s.Port, err),
+			Retry: true,
+		}
+	}
+	s.setPort(port)
+	s.Log.Debugf("Opened port %q", s.Port)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx, acc, port)
+	}()
+
+	return nil
+}
+
+func (*Serial) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (s *Serial) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	// Closing the port will unblock any pending read
+	s.setPort(nil)
+	s.wg.Wait()
+}
+
+func (s *Serial) setPort(port io.ReadCloser) {
+	s.portMtx.Lock()
+	defer s.portMtx.Unlock()
+
+	if s.port != nil {
+		s.port.Close()
+	}
+	s.port = port
+}
+
+func (s *Serial) run(ctx context.Context, acc telegraf.Accumulator, port io.ReadCloser) {
+	for {
+		if err := s.read(acc, port); err != nil && ctx.Err() == nil {
+			acc.AddError(fmt.Errorf("reading from port %q failed: %w", s.Port, err))
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		s.Log.Warnf("Lost connection to port %q, reconnecting...", s.Port)
+		s.setPort(nil)
+
+		// Try to reopen the port until we succeed or are stopped e.g. when
+		// the device is unplugged
+		port = s.reconnect(ctx)
+		if port == nil {
+			return
+		}
+	}
+}
+
+func (s *Serial) reconnect(ctx context.Context) io.ReadCloser {
+	ticker := time.NewTicker(time.Duration(s.ReconnectInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		port, err := s.open()
+		if err != nil {
+			s.Log.Debugf("Reopening port %q failed: %v", s.Port, err)
+			continue
+		}
+
+		// Protect against a concurrent stop to not leak the port
+		s.portMtx.Lock()
+		if ctx.Err() != nil {
+			s.portMtx.Unlock()
+			port.Close()
+			return nil
+		}
+		s.port = port
+		s.portMtx.Unlock()
+
+		s.Log.Infof("Reconnected to port %q", s.Port)
+		return port
+	}
+}
+
+func (s *Serial) read(acc telegraf.Accumulator, port io.Reader) error {
+	scanner := bufio.NewScanner(port)
+	if size := int(s.MaxMessageSize); size > bufio.MaxScanTokenSize {
+		scanner.Buffer(make([]byte, size), size)
+	}
+	scanner.Split(s.splitter)
+
+	for scanner.Scan() {
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+
+		metrics, err := s.parser.Parse(data)
+		if err != nil {
+			acc.AddError(fmt.Errorf("parsing data from port %q failed: %w", s.Port, err))
+			continue
+		}
+		if len(metrics) == 0 {
+			once.Do(func() {
+				s.Log.Debug(internal.NoMetricsCreatedMsg)
+			})
+		}
+		for _, m := range metrics {
+			m.AddTag("port", s.Port)
+			acc.AddMetric(m)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func init() {
+	inputs.Add("serial", func() telegraf.Input {
+		return &Serial{
+			BaudRate:          9600,
+			DataBits:          8,
+			Parity:            "none",
+			StopBits:          "1",
+			FlowControl:       "none",
+			RTS:               true,
+			DTR:               true,
+			ReconnectInterval: config.Duration(5 * time.Second),
+			MaxMessageSize:    config.Size(bufio.MaxScanTokenSize),
+		}
+	})
+}
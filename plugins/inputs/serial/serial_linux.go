@@ -0,0 +1,48 @@
+//go:build linux
+
+package serial
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+const flowControlSupported = true
+
+// setFlowControl enables flow-control on the given device. The serial library
+// always disables flow-control when opening the port, so we need to adapt
+// the terminal settings afterwards. Those settings are bound to the device,
+// not the file descriptor, so they apply to the already opened port.
+func setFlowControl(device, mode string) error {
+	if mode == "none" {
+		return nil
+	}
+
+	f, err := os.OpenFile(device, os.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK, 0)
+	if err != nil {
+		return fmt.Errorf("opening device failed: %w", err)
+	}
+	defer f.Close()
+
+	fd := int(f.Fd())
+	settings, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return fmt.Errorf("getting terminal settings failed: %w", err)
+	}
+
+	switch mode {
+	case "hardware":
+		settings.Cflag |= unix.CRTSCTS
+	case "software":
+		settings.Iflag |= unix.IXON | unix.IXOFF
+	default:
+		return fmt.Errorf("invalid flow control %q", mode)
+	}
+
+	if err := unix.IoctlSetTermios(fd, unix.TCSETS, settings); err != nil {
+		return fmt.Errorf("setting terminal settings failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+//go:build linux
+
+package serial
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestSetFlowControl(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  string
+		check func(*testing.T, *unix.Termios)
+	}{
+		{
+			name: "none",
+			mode: "none",
+			check: func(t *testing.T, settings *unix.Termios) {
+				require.Zero(t, settings.Cflag&unix.CRTSCTS)
+				require.Zero(t, settings.Iflag&(unix.IXON|unix.IXOFF))
+			},
+		},
+		{
+			name: "hardware",
+			mode: "hardware",
+			check: func(t *testing.T, settings *unix.Termios) {
+				require.NotZero(t, settings.Cflag&unix.CRTSCTS)
+				require.Zero(t, settings.Iflag&(unix.IXON|unix.IXOFF))
+			},
+		},
+		{
+			name: "software",
+			mode: "software",
+			check: func(t *testing.T, settings *unix.Termios) {
+				require.Zero(t, settings.Cflag&unix.CRTSCTS)
+				require.Equal(t, uint32(unix.IXON|unix.IXOFF), settings.Iflag&(unix.IXON|unix.IXOFF))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device, port := openPseudoTerminal(t)
+
+			// Start from a state without flow-control like the serial
+			// library does when opening the port
+			fd := int(port.Fd())
+			settings, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+			require.NoError(t, err)
+			settings.Cflag &^= unix.CRTSCTS
+			settings.Iflag &^= unix.IXON | unix.IXOFF
+			require.NoError(t, unix.IoctlSetTermios(fd, unix.TCSETS, settings))
+
+			require.NoError(t, setFlowControl(device, tt.mode))
+
+			// The settings must be visible via the already opened port
+			settings, err = unix.IoctlGetTermios(fd, unix.TCGETS)
+			require.NoError(t, err)
+			tt.check(t, settings)
+		})
+	}
+}
+
+func TestSetFlowControlFail(t *testing.T) {
+	device, _ := openPseudoTerminal(t)
+	require.ErrorContains(t, setFlowControl(device, "foo"), `invalid flow control "foo"`)
+	require.ErrorContains(t, setFlowControl("/dev/does-not-exist", "hardware"), "opening device failed")
+}
+
+// openPseudoTerminal creates a pseudo-terminal and returns the device name
+// and the opened port of the terminal side
+func openPseudoTerminal(t *testing.T) (string, *os.File) {
+	t.Helper()
+
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pseudo-terminals not available: %v", err)
+	}
+	t.Cleanup(func() { ptmx.Close() })
+
+	fd := int(ptmx.Fd())
+	require.NoError(t, unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0))
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	require.NoError(t, err)
+
+	device := fmt.Sprintf("/dev/pts/%d", n)
+	port, err := os.OpenFile(device, os.O_RDWR|unix.O_NOCTTY, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { port.Close() })
+
+	return device, port
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package serial
+
+import "errors"
+
+const flowControlSupported = false
+
+func setFlowControl(_, mode string) error {
+	if mode == "none" {
+		return nil
+	}
+	return errors.New("flow control is not supported on this platform")
+}
@@ -0,0 +1,176 @@
+package serial
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/socket"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*Serial)
+		expected string
+	}{
+		{
+			name:     "no port",
+			modify:   func(s *Serial) { s.Port = "" },
+			expected: "'port' required",
+		},
+		{
+			name:     "invalid baud rate",
+			modify:   func(s *Serial) { s.BaudRate = 0 },
+			expected: "invalid baud rate 0",
+		},
+		{
+			name:     "invalid data bits",
+			modify:   func(s *Serial) { s.DataBits = 9 },
+			expected: "invalid data bits 9",
+		},
+		{
+			name:     "invalid parity",
+			modify:   func(s *Serial) { s.Parity = "foo" },
+			expected: `invalid parity "foo"`,
+		},
+		{
+			name:     "invalid stop bits",
+			modify:   func(s *Serial) { s.StopBits = "3" },
+			expected: `invalid stop bits "3"`,
+		},
+		{
+			name:     "invalid flow control",
+			modify:   func(s *Serial) { s.FlowControl = "foo" },
+			expected: `invalid flow control "foo"`,
+		},
+		{
+			name:     "invalid splitting strategy",
+			modify:   func(s *Serial) { s.SplittingStrategy = "foo" },
+			expected: "unknown 'splitting_strategy'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := inputs.Inputs["serial"]().(*Serial)
+			plugin.Port = "/dev/ttyUSB0"
+			plugin.Log = &testutil.Logger{}
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestStartFail(t *testing.T) {
+	plugin := inputs.Inputs["serial"]().(*Serial)
+	plugin.Port = "/dev/ttyUSB0"
+	plugin.Log = &testutil.Logger{}
+	plugin.open = func() (io.ReadCloser, error) {
+		return nil, errors.New("device not found")
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Start(&acc), "device not found")
+}
+
+func TestReadAndReconnect(t *testing.T) {
+	// Create a pipe for each connection to simulate unplugging the device
+	// by closing the writing side
+	readers := make(chan io.ReadCloser, 2)
+	writers := make([]*io.PipeWriter, 0, 2)
+	for range 2 {
+		r, w := io.Pipe()
+		readers <- r
+		writers = append(writers, w)
+	}
+
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := inputs.Inputs["serial"]().(*Serial)
+	plugin.Port = "/dev/ttyUSB0"
+	plugin.ReconnectInterval = config.Duration(10 * time.Millisecond)
+	plugin.Log = &testutil.Logger{}
+	plugin.open = func() (io.ReadCloser, error) {
+		select {
+		case r := <-readers:
+			return r, nil
+		default:
+			return nil, errors.New("device not found")
+		}
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	_, err := writers[0].Write([]byte("test value=1i 1\ntest value=2i 2\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 2
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// Simulate unplugging and plugging-in the device again
+	require.NoError(t, writers[0].Close())
+	_, err = writers[1].Write([]byte("test value=3i 3\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 3
+	}, 3*time.Second, 10*time.Millisecond)
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{"port": "/dev/ttyUSB0"}, map[string]interface{}{"value": int64(1)}, time.Unix(0, 1)),
+		metric.New("test", map[string]string{"port": "/dev/ttyUSB0"}, map[string]interface{}{"value": int64(2)}, time.Unix(0, 2)),
+		metric.New("test", map[string]string{"port": "/dev/ttyUSB0"}, map[string]interface{}{"value": int64(3)}, time.Unix(0, 3)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestSplittingDelimiter(t *testing.T) {
+	r, w := io.Pipe()
+
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := inputs.Inputs["serial"]().(*Serial)
+	plugin.Port = "COM3"
+	plugin.SplitConfig = socket.SplitConfig{
+		SplittingStrategy:  "delimiter",
+		SplittingDelimiter: "0x03",
+	}
+	plugin.Log = &testutil.Logger{}
+	plugin.open = func() (io.ReadCloser, error) {
+		return r, nil
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	_, err := w.Write([]byte("test value=1i 1\x03test value=2i 2\x03"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 2
+	}, 3*time.Second, 10*time.Millisecond)
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{"port": "COM3"}, map[string]interface{}{"value": int64(1)}, time.Unix(0, 1)),
+		metric.New("test", map[string]string{"port": "COM3"}, map[string]interface{}{"value": int64(2)}, time.Unix(0, 2)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
@@ -0,0 +1,230 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package smartctl_nvme
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/globpath"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Controller character devices, namespaces block devices like "nvme0n1" are
+// queried through their controller
+var reController = regexp.MustCompile(`^nvme\d+$`)
+
+const (
+	// Size of the SMART / health information log page
+	healthLogSize = 512
+	// Size of the identify data structures
+	identifySize = 4096
+	// Size of a data unit in the health log, i.e. thousand 512 byte units
+	dataUnitSize = 512 * 1000
+)
+
+type SmartctlNVMe struct {
+	Devices    []string        `toml:"devices"`
+	Namespaces bool            `toml:"namespaces"`
+	Log        telegraf.Logger `toml:"-"`
+
+	globs []*globpath.GlobPath
+	known []string
+}
+
+func (*SmartctlNVMe) SampleConfig() string {
+	return sampleConfig
+}
+
+func (n *SmartctlNVMe) Init() error {
+	if runtime.GOOS != "linux" {
+		n.Log.Warn("Current platform is not supported")
+	}
+
+	if len(n.Devices) == 0 {
+		n.Devices = []string{"/dev/nvme*"}
+	}
+	for _, pattern := range n.Devices {
+		g, err := globpath.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("compiling device pattern %q failed: %w", pattern, err)
+		}
+		n.globs = append(n.globs, g)
+	}
+	return nil
+}
+
+// discover returns the currently available controller devices and logs
+// devices appearing or disappearing since the last call to handle hot-plugging
+func (n *SmartctlNVMe) discover() []string {
+	var devices []string
+	for _, g := range n.globs {
+		for _, device := range g.Match() {
+			if !reController.MatchString(filepath.Base(device)) || slices.Contains(devices, device) {
+				continue
+			}
+			devices = append(devices, device)
+		}
+	}
+	slices.Sort(devices)
+
+	for _, device := range devices {
+		if !slices.Contains(n.known, device) {
+			n.Log.Debugf("Discovered device %q", device)
+		}
+	}
+	for _, device := range n.known {
+		if !slices.Contains(devices, device) {
+			n.Log.Debugf("Device %q removed", device)
+		}
+	}
+	n.known = devices
+
+	return devices
+}
+
+// controllerInfo contains the identifying information of a controller
+type controllerInfo struct {
+	serial   string
+	model    string
+	firmware string
+}
+
+func parseIdentifyController(data []byte) (controllerInfo, error) {
+	if len(data) < identifySize {
+		return controllerInfo{}, fmt.Errorf("identify data too short (%d bytes)", len(data))
+	}
+	return controllerInfo{
+		serial:   strings.TrimSpace(string(data[4:24])),
+		model:    strings.TrimSpace(string(data[24:64])),
+		firmware: strings.TrimSpace(string(data[64:72])),
+	}, nil
+}
+
+// parseHealthLog converts the SMART / health information log page as defined
+// in the NVMe base specification to fields
+func parseHealthLog(data []byte) (map[string]interface{}, error) {
+	if len(data) < healthLogSize {
+		return nil, fmt.Errorf("health log too short (%d bytes)", len(data))
+	}
+
+	le := binary.LittleEndian
+	fields := map[string]interface{}{
+		"critical_warning":               uint64(data[0]),
+		"temperature":                    kelvinToCelsius(le.Uint16(data[1:3])),
+		"available_spare":                uint64(data[3]),
+		"available_spare_threshold":      uint64(data[4]),
+		"percentage_used":                uint64(data[5]),
+		"data_read_bytes":                saturatingMultiply(uint128(data[32:48]), dataUnitSize),
+		"data_written_bytes":             saturatingMultiply(uint128(data[48:64]), dataUnitSize),
+		"host_read_commands":             uint128(data[64:80]),
+		"host_write_commands":            uint128(data[80:96]),
+		"controller_busy_time_minutes":   uint128(data[96:112]),
+		"power_cycles":                   uint128(data[112:128]),
+		"power_on_hours":                 uint128(data[128:144]),
+		"unsafe_shutdowns":               uint128(data[144:160]),
+		"media_errors":                   uint128(data[160:176]),
+		"error_log_entries":              uint128(data[176:192]),
+		"warning_temperature_minutes":    uint64(le.Uint32(data[192:196])),
+		"critical_temperature_minutes":   uint64(le.Uint32(data[196:200])),
+		"thermal_throttle_1_transitions": uint64(le.Uint32(data[216:220])),
+		"thermal_throttle_2_transitions": uint64(le.Uint32(data[220:224])),
+		"thermal_throttle_1_seconds":     uint64(le.Uint32(data[224:228])),
+		"thermal_throttle_2_seconds":     uint64(le.Uint32(data[228:232])),
+	}
+
+	// Additional temperature sensors are only reported if implemented
+	for i := range 8 {
+		offset := 200 + 2*i
+		if raw := le.Uint16(data[offset : offset+2]); raw != 0 {
+			fields["temperature_sensor_"+strconv.Itoa(i+1)] = kelvinToCelsius(raw)
+		}
+	}
+
+	return fields, nil
+}
+
+// parseActiveNamespaces returns the namespace IDs of the active namespace list
+func parseActiveNamespaces(data []byte) []uint32 {
+	var ids []uint32
+	for offset := 0; offset+4 <= len(data); offset += 4 {
+		id := binary.LittleEndian.Uint32(data[offset : offset+4])
+		if id == 0 {
+			break
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// parseIdentifyNamespace converts the identify namespace data to size and
+// utilization fields in bytes
+func parseIdentifyNamespace(data []byte) (map[string]interface{}, error) {
+	if len(data) < identifySize {
+		return nil, fmt.Errorf("identify data too short (%d bytes)", len(data))
+	}
+
+	le := binary.LittleEndian
+	size := le.Uint64(data[0:8])
+	capacity := le.Uint64(data[8:16])
+	utilization := le.Uint64(data[16:24])
+
+	// The lower four bits of the formatted LBA size select the active LBA
+	// format, the upper bits extend the index for more than 16 formats
+	flbas := data[26]
+	format := int(flbas&0x0f) | int(flbas&0x60)>>1
+	offset := 128 + 4*format
+	lbads := data[offset+2]
+	if lbads < 9 || lbads > 63 {
+		return nil, errors.New("unsupported LBA data size")
+	}
+	blockSize := uint64(1) << lbads
+
+	fields := map[string]interface{}{
+		"block_size":        blockSize,
+		"size_bytes":        saturatingMultiply(size, blockSize),
+		"capacity_bytes":    saturatingMultiply(capacity, blockSize),
+		"utilization_bytes": saturatingMultiply(utilization, blockSize),
+	}
+	if size > 0 {
+		fields["utilization_percent"] = 100 * float64(utilization) / float64(size)
+	}
+	return fields, nil
+}
+
+func kelvinToCelsius(kelvin uint16) int64 {
+	return int64(kelvin) - 273
+}
+
+// uint128 returns the little-endian 128-bit value saturated to 64 bits
+func uint128(data []byte) uint64 {
+	if binary.LittleEndian.Uint64(data[8:16]) != 0 {
+		return math.MaxUint64
+	}
+	return binary.LittleEndian.Uint64(data[0:8])
+}
+
+func saturatingMultiply(a, b uint64) uint64 {
+	if a != 0 && b > math.MaxUint64/a {
+		return math.MaxUint64
+	}
+	return a * b
+}
+
+func init() {
+	inputs.Add("smartctl_nvme", func() telegraf.Input {
+		return &SmartctlNVMe{Namespaces: true}
+	})
+}
@@ -0,0 +1,151 @@
+//go:build linux
+
+package smartctl_nvme
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	// NVME_IOCTL_ADMIN_CMD, i.e. _IOWR('N', 0x41, struct nvme_admin_cmd)
+	ioctlAdminCommand = 0xc0484e41
+
+	opcodeGetLogPage = 0x02
+	opcodeIdentify   = 0x06
+
+	logPageHealth = 0x02
+
+	identifyNamespace        = 0x00
+	identifyController       = 0x01
+	identifyActiveNamespaces = 0x02
+
+	// Namespace ID addressing the controller as a whole
+	namespaceAll = 0xffffffff
+)
+
+// adminCommand mirrors the kernel's 'struct nvme_passthru_cmd', unused
+// fields are left blank
+type adminCommand struct {
+	opcode  uint8
+	_       uint8
+	_       uint16
+	nsid    uint32
+	_       [2]uint32
+	_       uint64
+	addr    uint64
+	_       uint32
+	dataLen uint32
+	cdw10   uint32
+	_       [5]uint32
+	_       uint32
+	_       uint32
+}
+
+func (n *SmartctlNVMe) Gather(acc telegraf.Accumulator) error {
+	for _, device := range n.discover() {
+		if err := n.gatherDevice(acc, device); err != nil {
+			acc.AddError(fmt.Errorf("device %q: %w", device, err))
+		}
+	}
+	return nil
+}
+
+func (n *SmartctlNVMe) gatherDevice(acc telegraf.Accumulator, device string) error {
+	f, err := os.Open(device)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fd := f.Fd()
+
+	data := make([]byte, identifySize)
+	if err := submit(fd, &adminCommand{opcode: opcodeIdentify, cdw10: identifyController}, data); err != nil {
+		return fmt.Errorf("identifying controller failed: %w", err)
+	}
+	info, err := parseIdentifyController(data)
+	if err != nil {
+		return err
+	}
+
+	// Request the health log for the whole controller, the number of dwords
+	// to transfer is zero-based
+	health := make([]byte, healthLogSize)
+	cmd := &adminCommand{
+		opcode: opcodeGetLogPage,
+		nsid:   namespaceAll,
+		cdw10:  uint32(healthLogSize/4-1)<<16 | logPageHealth,
+	}
+	if err := submit(fd, cmd, health); err != nil {
+		return fmt.Errorf("reading health log failed: %w", err)
+	}
+	fields, err := parseHealthLog(health)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(device)
+	tags := map[string]string{
+		"device":   name,
+		"model":    info.model,
+		"serial":   info.serial,
+		"firmware": info.firmware,
+	}
+	acc.AddFields("smartctl_nvme", fields, tags)
+
+	if !n.Namespaces {
+		return nil
+	}
+
+	list := make([]byte, identifySize)
+	if err := submit(fd, &adminCommand{opcode: opcodeIdentify, cdw10: identifyActiveNamespaces}, list); err != nil {
+		return fmt.Errorf("listing namespaces failed: %w", err)
+	}
+	for _, nsid := range parseActiveNamespaces(list) {
+		data := make([]byte, identifySize)
+		if err := submit(fd, &adminCommand{opcode: opcodeIdentify, nsid: nsid, cdw10: identifyNamespace}, data); err != nil {
+			acc.AddError(fmt.Errorf("device %q: identifying namespace %d failed: %w", device, nsid, err))
+			continue
+		}
+		fields, err := parseIdentifyNamespace(data)
+		if err != nil {
+			acc.AddError(fmt.Errorf("device %q: namespace %d: %w", device, nsid, err))
+			continue
+		}
+		tags := map[string]string{
+			"device":    name,
+			"namespace": strconv.FormatUint(uint64(nsid), 10),
+			"model":     info.model,
+			"serial":    info.serial,
+		}
+		acc.AddFields("smartctl_nvme_namespace", fields, tags)
+	}
+
+	return nil
+}
+
+// submit passes the admin command to the device transferring the result to
+// the given buffer
+func submit(fd uintptr, cmd *adminCommand, data []byte) error {
+	cmd.addr = uint64(uintptr(unsafe.Pointer(&data[0])))
+	cmd.dataLen = uint32(len(data))
+
+	// A positive return value contains the NVMe status of a failed command
+	status, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, ioctlAdminCommand, uintptr(unsafe.Pointer(cmd)))
+	runtime.KeepAlive(data)
+	if errno != 0 {
+		return errno
+	}
+	if status != 0 {
+		return fmt.Errorf("command failed with status %#x", status)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package smartctl_nvme
+
+import "github.com/influxdata/telegraf"
+
+func (*SmartctlNVMe) Gather(telegraf.Accumulator) error {
+	return nil
+}
@@ -0,0 +1,172 @@
+package smartctl_nvme
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestParseHealthLog(t *testing.T) {
+	data := make([]byte, healthLogSize)
+	le := binary.LittleEndian
+	data[0] = 0x02
+	le.PutUint16(data[1:3], 318)
+	data[3] = 100
+	data[4] = 10
+	data[5] = 3
+	le.PutUint64(data[32:40], 1000)
+	le.PutUint64(data[48:56], 2000)
+	le.PutUint64(data[64:72], 123456)
+	le.PutUint64(data[80:88], 654321)
+	le.PutUint64(data[96:104], 42)
+	le.PutUint64(data[112:120], 17)
+	le.PutUint64(data[128:136], 8760)
+	le.PutUint64(data[144:152], 5)
+	le.PutUint64(data[160:168], 2)
+	le.PutUint64(data[176:184], 9)
+	le.PutUint32(data[192:196], 60)
+	le.PutUint32(data[196:200], 1)
+	le.PutUint16(data[200:202], 313)
+	le.PutUint16(data[202:204], 323)
+	le.PutUint32(data[216:220], 4)
+	le.PutUint32(data[220:224], 1)
+	le.PutUint32(data[224:228], 120)
+	le.PutUint32(data[228:232], 30)
+
+	fields, err := parseHealthLog(data)
+	require.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"critical_warning":               uint64(2),
+		"temperature":                    int64(45),
+		"available_spare":                uint64(100),
+		"available_spare_threshold":      uint64(10),
+		"percentage_used":                uint64(3),
+		"data_read_bytes":                uint64(512000000),
+		"data_written_bytes":             uint64(1024000000),
+		"host_read_commands":             uint64(123456),
+		"host_write_commands":            uint64(654321),
+		"controller_busy_time_minutes":   uint64(42),
+		"power_cycles":                   uint64(17),
+		"power_on_hours":                 uint64(8760),
+		"unsafe_shutdowns":               uint64(5),
+		"media_errors":                   uint64(2),
+		"error_log_entries":              uint64(9),
+		"warning_temperature_minutes":    uint64(60),
+		"critical_temperature_minutes":   uint64(1),
+		"thermal_throttle_1_transitions": uint64(4),
+		"thermal_throttle_2_transitions": uint64(1),
+		"thermal_throttle_1_seconds":     uint64(120),
+		"thermal_throttle_2_seconds":     uint64(30),
+		"temperature_sensor_1":           int64(40),
+		"temperature_sensor_2":           int64(50),
+	}
+	require.Equal(t, expected, fields)
+}
+
+func TestParseHealthLogSaturation(t *testing.T) {
+	data := make([]byte, healthLogSize)
+	le := binary.LittleEndian
+	// Values exceeding 64 bits
+	le.PutUint64(data[168:176], 1)
+	// Data units overflowing when converted to bytes
+	le.PutUint64(data[32:40], math.MaxUint64/1000)
+
+	fields, err := parseHealthLog(data)
+	require.NoError(t, err)
+	require.Equal(t, uint64(math.MaxUint64), fields["media_errors"])
+	require.Equal(t, uint64(math.MaxUint64), fields["data_read_bytes"])
+}
+
+func TestParseHealthLogTooShort(t *testing.T) {
+	_, err := parseHealthLog(make([]byte, 100))
+	require.ErrorContains(t, err, "health log too short")
+}
+
+func TestParseIdentifyController(t *testing.T) {
+	data := make([]byte, identifySize)
+	copy(data[4:24], "S4EWNX0N123456      ")
+	copy(data[24:64], "Samsung SSD 970 EVO Plus 1TB            ")
+	copy(data[64:72], "2B2QEXM7")
+
+	info, err := parseIdentifyController(data)
+	require.NoError(t, err)
+	require.Equal(t, controllerInfo{
+		serial:   "S4EWNX0N123456",
+		model:    "Samsung SSD 970 EVO Plus 1TB",
+		firmware: "2B2QEXM7",
+	}, info)
+}
+
+func TestParseActiveNamespaces(t *testing.T) {
+	data := make([]byte, identifySize)
+	binary.LittleEndian.PutUint32(data[0:4], 1)
+	binary.LittleEndian.PutUint32(data[4:8], 3)
+	require.Equal(t, []uint32{1, 3}, parseActiveNamespaces(data))
+	require.Empty(t, parseActiveNamespaces(make([]byte, identifySize)))
+}
+
+func TestParseIdentifyNamespace(t *testing.T) {
+	data := make([]byte, identifySize)
+	le := binary.LittleEndian
+	le.PutUint64(data[0:8], 1000)
+	le.PutUint64(data[8:16], 1000)
+	le.PutUint64(data[16:24], 250)
+	// Select the second LBA format using 4096 byte blocks
+	data[26] = 0x01
+	data[128+2] = 9
+	data[132+2] = 12
+
+	fields, err := parseIdentifyNamespace(data)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"block_size":          uint64(4096),
+		"size_bytes":          uint64(4096000),
+		"capacity_bytes":      uint64(4096000),
+		"utilization_bytes":   uint64(1024000),
+		"utilization_percent": float64(25),
+	}, fields)
+}
+
+func TestParseIdentifyNamespaceExtendedFormat(t *testing.T) {
+	data := make([]byte, identifySize)
+	binary.LittleEndian.PutUint64(data[0:8], 8)
+	// The upper bits select formats above 15, i.e. format 17 here
+	data[26] = 0x21
+	data[128+4*17+2] = 9
+
+	fields, err := parseIdentifyNamespace(data)
+	require.NoError(t, err)
+	require.Equal(t, uint64(512), fields["block_size"])
+	require.Equal(t, uint64(4096), fields["size_bytes"])
+}
+
+func TestParseIdentifyNamespaceInvalidBlockSize(t *testing.T) {
+	_, err := parseIdentifyNamespace(make([]byte, identifySize))
+	require.ErrorContains(t, err, "unsupported LBA data size")
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	for _, fn := range []string{"nvme0", "nvme0n1", "nvme1", "nvme1n1", "nvme-fabrics"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fn), nil, 0600))
+	}
+
+	plugin := &SmartctlNVMe{
+		Devices: []string{filepath.Join(dir, "nvme*")},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, []string{filepath.Join(dir, "nvme0"), filepath.Join(dir, "nvme1")}, plugin.discover())
+
+	// Simulate hot-plugging devices
+	require.NoError(t, os.Remove(filepath.Join(dir, "nvme0")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nvme2"), nil, 0600))
+	require.Equal(t, []string{filepath.Join(dir, "nvme1"), filepath.Join(dir, "nvme2")}, plugin.discover())
+}
@@ -5,6 +5,7 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -25,6 +26,14 @@ type Snmp struct {
 	// The tag used to name the agent host
 	AgentHostTag string `toml:"agent_host_tag"`
 
+	// Number of table columns walked concurrently per agent and the maximum
+	// time spent walking a single column.
+	MaxConcurrentWalks int             `toml:"max_concurrent_walks"`
+	WalkTimeout        config.Duration `toml:"walk_timeout"`
+
+	// Interval for checking the MIB paths for changes
+	MibReloadInterval config.Duration `toml:"mib_reload_interval"`
+
 	snmp.ClientConfig
 
 	Tables []snmp.Table `toml:"table"`
@@ -37,9 +46,16 @@ type Snmp struct {
 
 	Log telegraf.Logger `toml:"-"`
 
-	connectionCache []snmp.Connection
+	connectionCache     []snmp.Connection
+	walkConnectionCache [][]snmp.Connection
 
 	translator snmp.Translator
+
+	// Configured tables and fields used for re-initialization on MIB reload
+	tableConfigs []snmp.Table
+	fieldConfigs []snmp.Field
+	mibModTime   time.Time
+	lastMibCheck time.Time
 }
 
 func (*Snmp) SampleConfig() string {
@@ -64,18 +80,29 @@ func (s *Snmp) Init() error {
 		return errors.New("invalid translator value")
 	}
 
-	s.connectionCache = make([]snmp.Connection, len(s.Agents))
-
-	for i := range s.Tables {
-		if err := s.Tables[i].Init(s.translator); err != nil {
-			return fmt.Errorf("initializing table %s: %w", s.Tables[i].Name, err)
+	if s.MibReloadInterval > 0 {
+		if s.Translator != "gosmi" {
+			return errors.New("'mib_reload_interval' requires the gosmi translator")
+		}
+		s.mibModTime, err = snmp.MibsModTime(s.Path)
+		if err != nil {
+			return fmt.Errorf("checking MIB paths: %w", err)
 		}
+		s.lastMibCheck = time.Now()
 	}
 
-	for i := range s.Fields {
-		if err := s.Fields[i].Init(s.translator); err != nil {
-			return fmt.Errorf("initializing field %s: %w", s.Fields[i].Name, err)
-		}
+	if s.MaxConcurrentWalks < 1 {
+		s.MaxConcurrentWalks = 1
+	}
+
+	s.connectionCache = make([]snmp.Connection, len(s.Agents))
+	s.walkConnectionCache = make([][]snmp.Connection, len(s.Agents))
+
+	s.tableConfigs = cloneTables(s.Tables)
+	s.fieldConfigs = slices.Clone(s.Fields)
+	s.Tables, s.Fields, err = s.initTables(s.tableConfigs, s.fieldConfigs)
+	if err != nil {
+		return err
 	}
 
 	if len(s.AgentHostTag) == 0 {
@@ -95,7 +122,41 @@ func (s *Snmp) Init() error {
 	return nil
 }
 
+// initTables returns initialized copies of the given tables and fields
+func (s *Snmp) initTables(tableConfigs []snmp.Table, fieldConfigs []snmp.Field) ([]snmp.Table, []snmp.Field, error) {
+	tables := cloneTables(tableConfigs)
+	for i := range tables {
+		if err := tables[i].Init(s.translator); err != nil {
+			return nil, nil, fmt.Errorf("initializing table %s: %w", tables[i].Name, err)
+		}
+	}
+
+	fields := slices.Clone(fieldConfigs)
+	for i := range fields {
+		if err := fields[i].Init(s.translator); err != nil {
+			return nil, nil, fmt.Errorf("initializing field %s: %w", fields[i].Name, err)
+		}
+	}
+
+	return tables, fields, nil
+}
+
+func cloneTables(tables []snmp.Table) []snmp.Table {
+	clones := make([]snmp.Table, 0, len(tables))
+	for _, t := range tables {
+		t.Fields = slices.Clone(t.Fields)
+		clones = append(clones, t)
+	}
+	return clones
+}
+
 func (s *Snmp) Gather(acc telegraf.Accumulator) error {
+	if s.MibReloadInterval > 0 && time.Since(s.lastMibCheck) >= time.Duration(s.MibReloadInterval) {
+		if err := s.reloadMibs(); err != nil {
+			acc.AddError(fmt.Errorf("reloading MIBs: %w", err))
+		}
+	}
+
 	var wg sync.WaitGroup
 	for i, agent := range s.Agents {
 		wg.Add(1)
@@ -106,6 +167,11 @@ func (s *Snmp) Gather(acc telegraf.Accumulator) error {
 				acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
 				return
 			}
+			conns, err := s.getWalkConnections(i, gs)
+			if err != nil {
+				acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
+				return
+			}
 
 			// First is the top-level fields. We treat the fields as table prefixes with an empty index.
 			t := snmp.Table{
@@ -113,13 +179,13 @@ func (s *Snmp) Gather(acc telegraf.Accumulator) error {
 				Fields: s.Fields,
 			}
 			topTags := make(map[string]string)
-			if err := s.gatherTable(acc, gs, t, topTags, false); err != nil {
+			if err := s.gatherTable(acc, conns, t, topTags, false); err != nil {
 				acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
 			}
 
 			// Now is the real tables.
 			for _, t := range s.Tables {
-				if err := s.gatherTable(acc, gs, t, topTags, true); err != nil {
+				if err := s.gatherTable(acc, conns, t, topTags, true); err != nil {
 					acc.AddError(fmt.Errorf("agent %s: gathering table %s: %w", agent, t.Name, err))
 				}
 			}
@@ -130,8 +196,8 @@ func (s *Snmp) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
-func (s *Snmp) gatherTable(acc telegraf.Accumulator, gs snmp.Connection, t snmp.Table, topTags map[string]string, walk bool) error {
-	rt, err := t.Build(gs, walk)
+func (s *Snmp) gatherTable(acc telegraf.Accumulator, conns []snmp.Connection, t snmp.Table, topTags map[string]string, walk bool) error {
+	rt, err := t.BuildConcurrent(conns, walk, time.Duration(s.WalkTimeout))
 	if err != nil {
 		return err
 	}
@@ -151,7 +217,7 @@ func (s *Snmp) gatherTable(acc telegraf.Accumulator, gs snmp.Connection, t snmp.
 			}
 		}
 		if _, ok := tr.Tags[s.AgentHostTag]; !ok {
-			tr.Tags[s.AgentHostTag] = gs.Host()
+			tr.Tags[s.AgentHostTag] = conns[0].Host()
 		}
 		acc.AddFields(rt.Name, tr.Fields, tr.Tags, rt.Time)
 	}
@@ -172,14 +238,12 @@ func (s *Snmp) getConnection(idx int) (snmp.Connection, error) {
 		return gs, nil
 	}
 
-	agent := s.Agents[idx]
-
 	gs, err := snmp.NewWrapper(s.ClientConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	err = gs.SetAgent(agent)
+	err = gs.SetAgent(s.Agents[idx])
 	if err != nil {
 		return nil, err
 	}
@@ -193,6 +257,70 @@ func (s *Snmp) getConnection(idx int) (snmp.Connection, error) {
 	return gs, nil
 }
 
+// getWalkConnections returns the connections used for walking the tables of
+// the agent with index `idx`. The first connection is the given one, further
+// connections for concurrent walks are created and cached as required.
+func (s *Snmp) getWalkConnections(idx int, gs snmp.Connection) ([]snmp.Connection, error) {
+	conns := make([]snmp.Connection, 0, s.MaxConcurrentWalks)
+	conns = append(conns, gs)
+	for i := 1; i < s.MaxConcurrentWalks; i++ {
+		if i <= len(s.walkConnectionCache[idx]) {
+			c := s.walkConnectionCache[idx][i-1]
+			if err := c.Reconnect(); err != nil {
+				return nil, fmt.Errorf("reconnecting: %w", err)
+			}
+			conns = append(conns, c)
+			continue
+		}
+
+		c, err := snmp.NewWrapper(s.ClientConfig)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.SetAgent(s.Agents[idx]); err != nil {
+			return nil, err
+		}
+		s.walkConnectionCache[idx] = append(s.walkConnectionCache[idx], c)
+
+		if err := c.Connect(); err != nil {
+			return nil, fmt.Errorf("setting up connection: %w", err)
+		}
+		conns = append(conns, c)
+	}
+
+	return conns, nil
+}
+
+// reloadMibs reloads the MIB files and re-initializes the tables and fields
+// if the files in the MIB paths changed since the last check.
+func (s *Snmp) reloadMibs() error {
+	s.lastMibCheck = time.Now()
+
+	modTime, err := snmp.MibsModTime(s.Path)
+	if err != nil {
+		return fmt.Errorf("checking MIB paths: %w", err)
+	}
+	if !modTime.After(s.mibModTime) {
+		return nil
+	}
+
+	s.Log.Info("MIB files changed, reloading")
+	if err := snmp.ReloadMibsFromPath(s.Path, s.Log, &snmp.GosmiMibLoader{}); err != nil {
+		return err
+	}
+	s.mibModTime = modTime
+
+	// Keep the current tables and fields if the new MIBs cannot be resolved
+	tables, fields, err := s.initTables(s.tableConfigs, s.fieldConfigs)
+	if err != nil {
+		return err
+	}
+	s.Tables = tables
+	s.Fields = fields
+
+	return nil
+}
+
 func init() {
 	inputs.Add("snmp", func() telegraf.Input {
 		return &Snmp{
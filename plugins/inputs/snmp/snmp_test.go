@@ -3,6 +3,7 @@ package snmp
 import (
 	"fmt"
 	"net"
+	"os"
 	"path/filepath"
 	"sync"
 	"testing"
@@ -11,8 +12,10 @@ import (
 	"github.com/gosnmp/gosnmp"
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/snmp"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -799,3 +802,117 @@ func TestGather_hostGosmi(t *testing.T) {
 	m := acc.Metrics[0]
 	require.Equal(t, "baz", m.Tags["host"])
 }
+
+func TestGatherConcurrentWalks(t *testing.T) {
+	s := &Snmp{
+		Agents:             []string{"TestGather"},
+		AgentHostTag:       "agent_host",
+		MaxConcurrentWalks: 3,
+		WalkTimeout:        config.Duration(time.Minute),
+		Tables: []snmp.Table{
+			{
+				Name: "myOtherTable",
+				Fields: []snmp.Field{
+					{
+						Name:  "myOtherField1",
+						Oid:   ".1.0.0.0.1.1",
+						IsTag: true,
+					},
+					{
+						Name: "myOtherField2",
+						Oid:  ".1.0.0.0.1.2",
+					},
+					{
+						Name: "myOtherField3",
+						Oid:  ".1.0.0.0.1.5",
+					},
+				},
+			},
+		},
+
+		connectionCache:     []snmp.Connection{tsc},
+		walkConnectionCache: [][]snmp.Connection{{tsc, tsc}},
+	}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Gather(acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"myOtherTable",
+			map[string]string{"agent_host": "tsc", "myOtherField1": "foo"},
+			map[string]interface{}{"myOtherField2": 1, "myOtherField3": 123456},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"myOtherTable",
+			map[string]string{"agent_host": "tsc", "myOtherField1": "bar"},
+			map[string]interface{}{"myOtherField2": 2},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"myOtherTable",
+			map[string]string{"agent_host": "tsc"},
+			map[string]interface{}{"myOtherField2": 0},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestMibReloadGosmi(t *testing.T) {
+	testDataPath, err := filepath.Abs("../../../internal/snmp/testdata/gosmi")
+	require.NoError(t, err)
+
+	// Use a private copy of the MIBs to be able to modify them
+	dir := t.TempDir()
+	entries, err := os.ReadDir(testDataPath)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		buf, err := os.ReadFile(filepath.Join(testDataPath, entry.Name()))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, entry.Name()), buf, 0600))
+	}
+
+	s := &Snmp{
+		Tables: []snmp.Table{
+			{Oid: "RFC1213-MIB::atTable"},
+		},
+		Fields: []snmp.Field{
+			{Oid: "RFC1213-MIB::atPhysAddress"},
+		},
+		MibReloadInterval: config.Duration(time.Minute),
+		ClientConfig: snmp.ClientConfig{
+			Path:       []string{dir},
+			Translator: "gosmi",
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+	initial := s.mibModTime
+
+	// Nothing changed, so nothing should be reloaded
+	require.NoError(t, s.reloadMibs())
+	require.Equal(t, initial, s.mibModTime)
+
+	// Modify the MIB files and check the tables are re-initialized
+	modified := initial.Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "tableMib"), modified, modified))
+
+	var acc testutil.Accumulator
+	s.lastMibCheck = time.Time{}
+	require.NoError(t, s.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Equal(t, modified, s.mibModTime)
+
+	require.Len(t, s.Tables[0].Fields, 3)
+	require.Equal(t, ".1.3.6.1.2.1.3.1.1.1", s.Tables[0].Fields[0].Oid)
+	require.Equal(t, "atIfIndex", s.Tables[0].Fields[0].Name)
+	require.Equal(t, ".1.3.6.1.2.1.3.1.1.2", s.Fields[0].Oid)
+	require.Equal(t, "atPhysAddress", s.Fields[0].Name)
+
+	// The configured tables must stay untouched
+	require.Empty(t, s.tableConfigs[0].Fields)
+	require.Equal(t, "RFC1213-MIB::atPhysAddress", s.fieldConfigs[0].Oid)
+}
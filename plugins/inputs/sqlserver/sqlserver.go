@@ -295,6 +295,10 @@ func (s *SQLServer) initQueries() error {
 		queries["SQLServerRecentBackups"] = query{ScriptName: "SQLServerRecentBackups", Script: sqlServerRecentBackups, ResultByRow: false}
 		queries["SQLServerPersistentVersionStore"] =
 			query{ScriptName: "SQLServerPersistentVersionStore", Script: sqlServerPersistentVersionStore, ResultByRow: false}
+		queries["SQLServerAlwaysOnReplicas"] = query{ScriptName: "SQLServerAlwaysOnReplicas", Script: sqlServerAlwaysOnReplicas, ResultByRow: false}
+		queries["SQLServerAlwaysOnDatabases"] = query{ScriptName: "SQLServerAlwaysOnDatabases", Script: sqlServerAlwaysOnDatabases, ResultByRow: false}
+		queries["SQLServerAlwaysOnHealthEvents"] =
+			query{ScriptName: "SQLServerAlwaysOnHealthEvents", Script: sqlServerAlwaysOnHealthEvents, ResultByRow: false}
 	} else {
 		return fmt.Errorf("unsupported database_type: %s. Supported types are: %s, %s, %s, %s, %s",
 			s.DatabaseType, typeAzureSQLDB, typeAzureSQLManagedInstance, typeAzureSQLPool, typeAzureArcSQLManagedInstance, typeSQLServer)
@@ -19,7 +19,8 @@ func TestSqlServer_QueriesInclusionExclusion(t *testing.T) {
 			"IncludeQuery": make([]string, 0),
 			"ExcludeQuery": []string{"SQLServerWaitStatsCategorized", "SQLServerDatabaseIO", "SQLServerProperties", "SQLServerMemoryClerks",
 				"SQLServerSchedulers", "SQLServerVolumeSpace", "SQLServerCpu", "SQLServerAvailabilityReplicaStates", "SQLServerDatabaseReplicaStates",
-				"SQLServerRecentBackups", "SQLServerPersistentVersionStore"},
+				"SQLServerRecentBackups", "SQLServerPersistentVersionStore", "SQLServerAlwaysOnReplicas", "SQLServerAlwaysOnDatabases",
+				"SQLServerAlwaysOnHealthEvents"},
 			"queries":      []string{"SQLServerPerformanceCounters", "SQLServerRequests"},
 			"queriesTotal": 2,
 		},
@@ -462,6 +463,62 @@ func TestSqlServerIntegration_AGQueryFieldsOutputBasedOnSQLServerVersion(t *test
 	s2012.Stop()
 }
 
+func TestSqlServer_AlwaysOnQueriesDatabaseType(t *testing.T) {
+	alwaysOn := []string{"SQLServerAlwaysOnReplicas", "SQLServerAlwaysOnDatabases", "SQLServerAlwaysOnHealthEvents"}
+
+	s := SQLServer{
+		DatabaseType: "SQLServer",
+		IncludeQuery: alwaysOn,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, s.initQueries())
+	require.Len(t, s.queries, len(alwaysOn))
+	for _, name := range alwaysOn {
+		require.Contains(t, s.queries, name)
+	}
+
+	// The suite is only available for SQL Server instances
+	s2 := SQLServer{
+		DatabaseType: "AzureSQLDB",
+		IncludeQuery: alwaysOn,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, s2.initQueries())
+	require.Empty(t, s2.queries)
+}
+
+func TestSqlServerIntegration_AlwaysOnQueries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	if os.Getenv("AZURESQL_POOL_CONNECTION_STRING_2019") == "" {
+		t.Skip("Missing environment variable AZURESQL_POOL_CONNECTION_STRING_2019")
+	}
+	testServer := os.Getenv("AZURESQL_POOL_CONNECTION_STRING_2019")
+
+	sl := config.NewSecret([]byte(testServer))
+	s := &SQLServer{
+		Servers:      []*config.Secret{&sl},
+		DatabaseType: "SQLServer",
+		IncludeQuery: []string{"SQLServerAlwaysOnReplicas", "SQLServerAlwaysOnDatabases", "SQLServerAlwaysOnHealthEvents"},
+		Log:          testutil.Logger{},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, s.Start(&acc))
+	require.NoError(t, s.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	require.True(t, acc.HasMeasurement("sqlserver_alwayson_replica"))
+	require.True(t, acc.HasMeasurement("sqlserver_alwayson_database"))
+	require.True(t, acc.HasField("sqlserver_alwayson_database", "log_send_rate_kb_per_sec"))
+	require.True(t, acc.HasField("sqlserver_alwayson_database", "redo_queue_size_kb"))
+	require.True(t, acc.HasField("sqlserver_alwayson_database", "secondary_lag_seconds"))
+	require.True(t, acc.HasTag("sqlserver_alwayson_database", "group_name"))
+	s.Stop()
+}
+
 const mockPerformanceMetrics = `measurement;servername;type;Point In Time Recovery;Available physical memory (bytes);Average pending disk IO;` +
 	`Average runnable tasks;Average tasks;Buffer pool rate (bytes/sec);Connection memory per connection (bytes);Memory grant pending;` +
 	`Page File Usage (%);Page lookup per batch request;Page split per batch request;Readahead per page read;Signal wait (%);` +
@@ -0,0 +1,185 @@
+//nolint:lll // conditionally long lines allowed
+package sqlserver
+
+import (
+	_ "github.com/microsoft/go-mssqldb" // go-mssqldb initialization
+)
+
+// The Always On queries form a dedicated suite for availability groups. All
+// queries require SQL Server 2012 (@MajorMinorVersion >= 1100) with HADR
+// enabled and return no rows otherwise. Columns introduced in later versions
+// are added based on @MajorMinorVersion.
+
+// Collects the state and health of each availability replica visible on the
+// instance. Primary replicas see all replicas of the group, secondaries only
+// report themselves.
+const sqlServerAlwaysOnReplicas string = `
+SET DEADLOCK_PRIORITY -10;
+IF SERVERPROPERTY('EngineEdition') NOT IN (2,3,4) BEGIN /*NOT IN Standard,Enterpris,Express*/
+	DECLARE @ErrorMessage AS nvarchar(500) = 'Telegraf - Connection string Server:'+ @@ServerName + ',Database:' + DB_NAME() +' is not a SQL Server Standard,Enterprise or Express. Check the database_type parameter in the telegraf configuration.';
+	RAISERROR (@ErrorMessage,11,1)
+	RETURN
+END
+
+DECLARE
+	 @SqlStatement AS nvarchar(max)
+	,@MajorMinorVersion AS int = CAST(PARSENAME(CAST(SERVERPROPERTY('ProductVersion') AS nvarchar),4) AS int)*100 + CAST(PARSENAME(CAST(SERVERPROPERTY('ProductVersion') AS nvarchar),3) AS int)
+	,@Columns AS nvarchar(MAX) = ''
+
+IF @MajorMinorVersion < 1100 OR SERVERPROPERTY('IsHadrEnabled') <> 1 BEGIN
+	RETURN
+END
+
+IF @MajorMinorVersion >= 1300 BEGIN
+	SET @Columns += N'
+	,ar.seeding_mode_desc
+	,ag.is_distributed'
+END
+
+SET @SqlStatement = N'
+SELECT
+	''sqlserver_alwayson_replica'' AS [measurement]
+	,REPLACE(@@SERVERNAME, ''\'', '':'') AS [sql_instance]
+	,ag.name AS [group_name]
+	,ar.replica_server_name
+	,ar.availability_mode_desc
+	,ar.failover_mode_desc
+	,ISNULL(hars.role_desc, ''UNKNOWN'') AS [role_desc]
+	,hars.is_local
+	,hars.role
+	,hars.operational_state
+	,hars.connected_state
+	,hars.recovery_health
+	,hars.synchronization_health
+	,ISNULL(hars.synchronization_health_desc, ''UNKNOWN'') AS [synchronization_health_desc]
+	,hags.synchronization_health AS [group_synchronization_health]
+	,ISNULL(hars.last_connect_error_number, 0) AS [last_connect_error_number]
+	,(SELECT COUNT(*) FROM sys.dm_hadr_database_replica_states AS drs WHERE drs.replica_id = ar.replica_id) AS [database_count]
+	,(SELECT COUNT(*) FROM sys.dm_hadr_database_replica_states AS drs WHERE drs.replica_id = ar.replica_id AND drs.is_suspended = 1) AS [suspended_database_count]
+	,(SELECT COUNT(*) FROM sys.dm_hadr_database_replica_states AS drs WHERE drs.replica_id = ar.replica_id AND drs.synchronization_health <> 2) AS [unhealthy_database_count]'
+	+ @Columns + N'
+FROM sys.availability_replicas AS ar
+INNER JOIN sys.availability_groups AS ag ON ar.group_id = ag.group_id
+INNER JOIN sys.dm_hadr_availability_group_states AS hags ON hags.group_id = ag.group_id
+LEFT JOIN sys.dm_hadr_availability_replica_states AS hars ON hars.replica_id = ar.replica_id'
+
+EXEC sp_executesql @SqlStatement
+`
+
+// Collects the synchronization lag, log send and redo queues as well as the
+// corresponding rates per database and replica. The estimated data loss is
+// the difference of the last commit time on the primary and the replica.
+const sqlServerAlwaysOnDatabases string = `
+SET DEADLOCK_PRIORITY -10;
+IF SERVERPROPERTY('EngineEdition') NOT IN (2,3,4) BEGIN /*NOT IN Standard,Enterpris,Express*/
+	DECLARE @ErrorMessage AS nvarchar(500) = 'Telegraf - Connection string Server:'+ @@ServerName + ',Database:' + DB_NAME() +' is not a SQL Server Standard,Enterprise or Express. Check the database_type parameter in the telegraf configuration.';
+	RAISERROR (@ErrorMessage,11,1)
+	RETURN
+END
+
+DECLARE
+	 @SqlStatement AS nvarchar(max)
+	,@MajorMinorVersion AS int = CAST(PARSENAME(CAST(SERVERPROPERTY('ProductVersion') AS nvarchar),4) AS int)*100 + CAST(PARSENAME(CAST(SERVERPROPERTY('ProductVersion') AS nvarchar),3) AS int)
+	,@Columns AS nvarchar(MAX) = ''
+
+IF @MajorMinorVersion < 1100 OR SERVERPROPERTY('IsHadrEnabled') <> 1 BEGIN
+	RETURN
+END
+
+IF @MajorMinorVersion >= 1300 BEGIN
+	SET @Columns += N'
+	,drs.secondary_lag_seconds'
+END
+
+SET @SqlStatement = N'
+SELECT
+	''sqlserver_alwayson_database'' AS [measurement]
+	,REPLACE(@@SERVERNAME, ''\'', '':'') AS [sql_instance]
+	,ag.name AS [group_name]
+	,ar.replica_server_name
+	,DB_NAME(drs.database_id) AS [database_name]
+	,ISNULL(hars.role_desc, ''UNKNOWN'') AS [role_desc]
+	,ISNULL(drs.synchronization_state_desc, ''UNKNOWN'') AS [synchronization_state_desc]
+	,drs.is_local
+	,drs.is_suspended
+	,drs.synchronization_state
+	,drs.synchronization_health
+	,ISNULL(drs.log_send_queue_size, 0) AS [log_send_queue_size_kb]
+	,ISNULL(drs.log_send_rate, 0) AS [log_send_rate_kb_per_sec]
+	,ISNULL(drs.redo_queue_size, 0) AS [redo_queue_size_kb]
+	,ISNULL(drs.redo_rate, 0) AS [redo_rate_kb_per_sec]
+	,CASE
+		WHEN ISNULL(drs.redo_rate, 0) = 0 THEN 0
+		ELSE CAST(drs.redo_queue_size AS float) / drs.redo_rate
+	END AS [estimated_recovery_time_seconds]
+	,CASE
+		WHEN pdrs.last_commit_time IS NULL OR drs.last_commit_time IS NULL THEN 0
+		ELSE DATEDIFF(second, drs.last_commit_time, pdrs.last_commit_time)
+	END AS [estimated_data_loss_seconds]'
+	+ @Columns + N'
+FROM sys.dm_hadr_database_replica_states AS drs
+INNER JOIN sys.availability_replicas AS ar ON drs.replica_id = ar.replica_id
+INNER JOIN sys.availability_groups AS ag ON ar.group_id = ag.group_id
+LEFT JOIN sys.dm_hadr_availability_replica_states AS hars ON hars.replica_id = drs.replica_id
+OUTER APPLY (
+	SELECT TOP(1) p.last_commit_time
+	FROM sys.dm_hadr_database_replica_states AS p
+	INNER JOIN sys.dm_hadr_availability_replica_states AS phars ON phars.replica_id = p.replica_id
+	WHERE p.group_database_id = drs.group_database_id AND phars.role = 1
+) AS pdrs'
+
+EXEC sp_executesql @SqlStatement
+`
+
+// Collects the number of events recorded per event type and replica by the
+// 'AlwaysOn_health' extended events session together with the time since the
+// latest event. Reading the event files is expensive so the query should be
+// run at a low frequency.
+const sqlServerAlwaysOnHealthEvents string = `
+SET DEADLOCK_PRIORITY -10;
+IF SERVERPROPERTY('EngineEdition') NOT IN (2,3,4) BEGIN /*NOT IN Standard,Enterpris,Express*/
+	DECLARE @ErrorMessage AS nvarchar(500) = 'Telegraf - Connection string Server:'+ @@ServerName + ',Database:' + DB_NAME() +' is not a SQL Server Standard,Enterprise or Express. Check the database_type parameter in the telegraf configuration.';
+	RAISERROR (@ErrorMessage,11,1)
+	RETURN
+END
+
+DECLARE
+	 @MajorMinorVersion AS int = CAST(PARSENAME(CAST(SERVERPROPERTY('ProductVersion') AS nvarchar),4) AS int)*100 + CAST(PARSENAME(CAST(SERVERPROPERTY('ProductVersion') AS nvarchar),3) AS int)
+	,@FileName AS nvarchar(260)
+
+IF @MajorMinorVersion < 1100 OR SERVERPROPERTY('IsHadrEnabled') <> 1 BEGIN
+	RETURN
+END
+
+/* Older versions require the full path of the event files so derive it from the running session */
+SELECT @FileName = CAST(t.target_data AS xml).value('(EventFileTarget/File/@name)[1]', 'nvarchar(260)')
+FROM sys.dm_xe_session_targets AS t
+INNER JOIN sys.dm_xe_sessions AS s ON s.address = t.event_session_address
+WHERE s.name = 'AlwaysOn_health' AND t.target_name = 'event_file'
+
+IF @FileName IS NULL BEGIN
+	RETURN
+END
+SET @FileName = LEFT(@FileName, LEN(@FileName) - CHARINDEX('_', REVERSE(@FileName))) + '*.xel'
+
+SELECT
+	'sqlserver_alwayson_health_events' AS [measurement]
+	,REPLACE(@@SERVERNAME, '\', ':') AS [sql_instance]
+	,ev.event_name
+	,ev.group_name
+	,ev.replica_name
+	,COUNT(*) AS [event_count]
+	,DATEDIFF(second, MAX(ev.event_time), SYSUTCDATETIME()) AS [seconds_since_last_event]
+FROM (
+	SELECT
+		x.event_data.value('(event/@name)[1]', 'nvarchar(128)') AS [event_name]
+		,ISNULL(x.event_data.value('(event/data[@name="availability_group_name"]/value)[1]', 'nvarchar(128)'), '') AS [group_name]
+		,ISNULL(x.event_data.value('(event/data[@name="availability_replica_name"]/value)[1]', 'nvarchar(256)'), '') AS [replica_name]
+		,x.event_data.value('(event/@timestamp)[1]', 'datetime2') AS [event_time]
+	FROM (
+		SELECT CAST(event_data AS xml) AS [event_data]
+		FROM sys.fn_xe_file_target_read_file(@FileName, NULL, NULL, NULL)
+	) AS x
+) AS ev
+GROUP BY ev.event_name, ev.group_name, ev.replica_name
+`
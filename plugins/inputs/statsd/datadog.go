@@ -24,6 +24,12 @@ const (
 var uncommenter = strings.NewReplacer("\\n", "\n")
 
 func (s *Statsd) parseEventMessage(now time.Time, message, defaultHostname string) error {
+	return s.parseEvent(now, message, defaultHostname, "")
+}
+
+// parseEvent parses the event message received from a sender running in the
+// given container. The container is empty if unknown.
+func (s *Statsd) parseEvent(now time.Time, message, defaultHostname, container string) error {
 	// _e{title.length,text.length}:title|text
 	//  [
 	//   |d:date_happened
@@ -31,6 +37,7 @@ func (s *Statsd) parseEventMessage(now time.Time, message, defaultHostname strin
 	//   |h:hostname
 	//   |t:alert_type
 	//   |s:source_type_name
+	//   |c:container_id
 	//   |#tag1,tag2
 	//  ]
 	//
@@ -79,6 +86,9 @@ func (s *Statsd) parseEventMessage(now time.Time, message, defaultHostname strin
 	if defaultHostname != "" {
 		tags["source"] = defaultHostname
 	}
+	if container != "" {
+		tags["container"] = container
+	}
 	fields["priority"] = priorityNormal
 	ts := now
 	if len(message) < 2 {
@@ -119,6 +129,10 @@ func (s *Statsd) parseEventMessage(now time.Time, message, defaultHostname strin
 			tags["aggregation_key"] = rawMetadataFields[i][2:]
 		case "s:":
 			fields["source_type_name"] = rawMetadataFields[i][2:]
+		case "c:":
+			if s.DataDogKeepContainerTag {
+				tags["container"] = rawMetadataFields[i][2:]
+			}
 		default:
 			if rawMetadataFields[i][0] != '#' {
 				return fmt.Errorf("unknown metadata type: %q", rawMetadataFields[i])
@@ -380,6 +380,23 @@ func TestEvents(t *testing.T) {
 				checkTags:      map[string]string{"aggregation_key": "aggKey", "tag1": "true", "tag2": "test", "source": "some.host"},
 			},
 		},
+		{
+			name: "event metadata container id dropped",
+			args: args{
+				now:      now.Add(11),
+				message:  "_e{10,9}:test title|test text|c:f76b5a1c03caa192580874b253c158010ade668cf03080a57aa8283919d56e75|#tag1",
+				hostname: "default-hostname",
+			},
+			expected: expected{
+				title:     "test title",
+				text:      "test text",
+				now:       now.Add(11),
+				priority:  priorityNormal,
+				source:    "default-hostname",
+				alertType: eventInfo,
+				checkTags: map[string]string{"tag1": "true", "source": "default-hostname"},
+			},
+		},
 	}
 	s := newTestStatsd()
 	acc := &testutil.Accumulator{}
@@ -407,6 +424,22 @@ func TestEvents(t *testing.T) {
 	}
 }
 
+func TestEventContainer(t *testing.T) {
+	now := time.Now()
+	s := newTestStatsd()
+	s.DataDogKeepContainerTag = true
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	// The container id field takes precedence over the detected origin
+	require.NoError(t, s.parseEvent(now, "_e{5,4}:title|text|c:client", "", "detected"))
+	require.NoError(t, s.parseEvent(now, "_e{5,4}:title|text", "", "detected"))
+	require.Len(t, acc.Metrics, 2)
+	require.Equal(t, map[string]string{"container": "client"}, acc.Metrics[0].Tags)
+	require.Equal(t, map[string]string{"container": "detected"}, acc.Metrics[1].Tags)
+}
+
 func TestEventError(t *testing.T) {
 	now := time.Now()
 	s := newTestStatsd()
@@ -0,0 +1,60 @@
+package statsd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// containerIDPattern matches the container ID as last element of a cgroup
+// path, e.g. for docker ("/docker/<id>"), containerd or cri-o
+// ("/kubepods/.../cri-containerd-<id>.scope") managed containers.
+var containerIDPattern = regexp.MustCompile(`([0-9a-f]{64})(?:\.scope)?$`)
+
+// originContainer returns the container ID of the process with the given pid
+// using the cached value if any. An empty string is returned for processes
+// not running in a container.
+func (s *Statsd) originContainer(pid int32) string {
+	s.originLock.Lock()
+	defer s.originLock.Unlock()
+
+	if id, found := s.origins[pid]; found {
+		return id
+	}
+
+	fn := filepath.Join(s.procRoot, strconv.Itoa(int(pid)), "cgroup")
+	id, err := containerIDFromCgroup(fn)
+	if err != nil {
+		s.Log.Debugf("Resolving container of pid %d failed: %v", pid, err)
+	}
+	s.origins[pid] = id
+
+	return id
+}
+
+// containerIDFromCgroup extracts the container ID from the given cgroup file
+// in the format of "/proc/<pid>/cgroup".
+func containerIDFromCgroup(fn string) (string, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Each line has the form "hierarchy-ID:controller-list:cgroup-path"
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if match := containerIDPattern.FindStringSubmatch(parts[2]); match != nil {
+			return match[1], nil
+		}
+	}
+
+	return "", scanner.Err()
+}
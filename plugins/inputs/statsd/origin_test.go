@@ -0,0 +1,40 @@
+package statsd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerIDFromCgroup(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{
+			name:     "docker",
+			expected: "3726184226f5d3147c25fdeab5b60097e378e8a720503a5e19ecfdf29f869860",
+		},
+		{
+			name:     "containerd",
+			expected: "f76b5a1c03caa192580874b253c158010ade668cf03080a57aa8283919d56e75",
+		},
+		{
+			name: "host",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := containerIDFromCgroup(filepath.Join("testdata", "cgroup_"+tt.name))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, id)
+		})
+	}
+}
+
+func TestContainerIDFromCgroupMissing(t *testing.T) {
+	_, err := containerIDFromCgroup(filepath.Join("testdata", "non_existing"))
+	require.Error(t, err)
+}
@@ -0,0 +1,115 @@
+package statsd
+
+import (
+	"math"
+	"slices"
+)
+
+// minIndexableValue is the smallest magnitude tracked in a logarithmic bin,
+// smaller values are accounted to the zero bucket.
+const minIndexableValue = 1e-9
+
+// ddSketch is a minimal implementation of the DDSketch quantile sketch as used
+// by DogStatsD for distributions. Values are mapped to logarithmically sized
+// bins so that every quantile estimate is within the configured relative
+// accuracy of the true value while the memory usage only depends on the range
+// of the values and not on the number of samples.
+// See https://arxiv.org/abs/1908.10693 for details.
+type ddSketch struct {
+	gamma      float64
+	multiplier float64
+
+	positive map[int]float64
+	negative map[int]float64
+	zero     float64
+
+	count float64
+	sum   float64
+	min   float64
+	max   float64
+}
+
+func newDDSketch(relativeAccuracy float64) *ddSketch {
+	gamma := (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	return &ddSketch{
+		gamma:      gamma,
+		multiplier: 1 / math.Log(gamma),
+		positive:   make(map[int]float64),
+		negative:   make(map[int]float64),
+		min:        math.Inf(1),
+		max:        math.Inf(-1),
+	}
+}
+
+// add inserts the value with the given weight into the sketch
+func (s *ddSketch) add(value, weight float64) {
+	switch {
+	case value > minIndexableValue:
+		s.positive[s.index(value)] += weight
+	case value < -minIndexableValue:
+		s.negative[s.index(-value)] += weight
+	default:
+		s.zero += weight
+	}
+
+	s.count += weight
+	s.sum += value * weight
+	s.min = math.Min(s.min, value)
+	s.max = math.Max(s.max, value)
+}
+
+// quantile returns the estimated value at the given quantile in [0, 1]
+func (s *ddSketch) quantile(q float64) float64 {
+	if s.count == 0 || q < 0 || q > 1 {
+		return math.NaN()
+	}
+	rank := q * (s.count - 1)
+
+	// Walk the bins from the smallest to the largest value, i.e. the negative
+	// bins in descending index order followed by the zero and positive bins.
+	var seen float64
+	for _, idx := range sortedKeys(s.negative, true) {
+		seen += s.negative[idx]
+		if seen > rank {
+			return s.clamp(-s.value(idx))
+		}
+	}
+	seen += s.zero
+	if seen > rank {
+		return s.clamp(0)
+	}
+	for _, idx := range sortedKeys(s.positive, false) {
+		seen += s.positive[idx]
+		if seen > rank {
+			return s.clamp(s.value(idx))
+		}
+	}
+	return s.max
+}
+
+func (s *ddSketch) index(value float64) int {
+	return int(math.Ceil(math.Log(value) * s.multiplier))
+}
+
+// value returns the representative value of the bin with the given index
+// which has a relative error of at most the sketch accuracy to all values
+// falling into the bin.
+func (s *ddSketch) value(idx int) float64 {
+	return 2 * math.Pow(s.gamma, float64(idx)) / (1 + s.gamma)
+}
+
+func (s *ddSketch) clamp(value float64) float64 {
+	return math.Max(s.min, math.Min(s.max, value))
+}
+
+func sortedKeys(m map[int]float64, reverse bool) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	if reverse {
+		slices.Reverse(keys)
+	}
+	return keys
+}
@@ -0,0 +1,54 @@
+package statsd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSketchQuantiles(t *testing.T) {
+	const accuracy = 0.01
+
+	sketch := newDDSketch(accuracy)
+	for i := 1; i <= 1000; i++ {
+		sketch.add(float64(i), 1)
+	}
+
+	require.InDelta(t, 1000.0, sketch.count, 1e-9)
+	require.InDelta(t, 500500.0, sketch.sum, 1e-9)
+	require.InDelta(t, 1.0, sketch.quantile(0), 1e-9)
+	require.InDelta(t, 1000.0, sketch.quantile(1), 1e-9)
+	for _, q := range []float64{0.25, 0.5, 0.9, 0.99} {
+		expected := math.Floor(q*999) + 1
+		require.InEpsilonf(t, expected, sketch.quantile(q), accuracy, "quantile %v", q)
+	}
+}
+
+func TestSketchNegativeAndZero(t *testing.T) {
+	sketch := newDDSketch(0.01)
+	for _, v := range []float64{-100, -10, 0, 0, 10, 100} {
+		sketch.add(v, 1)
+	}
+
+	require.InDelta(t, -100.0, sketch.quantile(0), 1e-9)
+	require.InEpsilon(t, -10.0, sketch.quantile(0.2), 0.01)
+	require.InDelta(t, 0.0, sketch.quantile(0.5), 1e-9)
+	require.InEpsilon(t, 10.0, sketch.quantile(0.8), 0.01)
+	require.InDelta(t, 100.0, sketch.quantile(1), 1e-9)
+}
+
+func TestSketchWeights(t *testing.T) {
+	sketch := newDDSketch(0.01)
+	sketch.add(1, 9)
+	sketch.add(1000, 1)
+
+	require.InDelta(t, 10.0, sketch.count, 1e-9)
+	require.InDelta(t, 1009.0, sketch.sum, 1e-9)
+	require.InEpsilon(t, 1.0, sketch.quantile(0.5), 0.01)
+	require.InDelta(t, 1000.0, sketch.quantile(1), 1e-9)
+}
+
+func TestSketchEmpty(t *testing.T) {
+	require.True(t, math.IsNaN(newDDSketch(0.01).quantile(0.5)))
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"os"
 	"regexp"
 	"sort"
 	"strconv"
@@ -33,14 +34,19 @@ const (
 	// https://en.wikipedia.org/wiki/User_Datagram_Protocol#Packet_structure
 	udpMaxPacketSize int = 64 * 1024
 
+	// unixgramMaxPacketSize is the maximum datagram size used by DogStatsD
+	// clients for Unix domain sockets
+	unixgramMaxPacketSize int = 8 * 1024
+
 	defaultFieldName           = "value"
 	defaultProtocol            = "udp"
 	defaultSeparator           = "_"
 	defaultAllowPendingMessage = 10000
+	defaultSketchAccuracy      = 0.01
 )
 
 type Statsd struct {
-	// Protocol used on listener - udp or tcp
+	// Protocol used on listener - udp, tcp or unixgram
 	Protocol string `toml:"protocol"`
 
 	// Address & Port to serve from
@@ -84,6 +90,17 @@ type Statsd struct {
 	// https://docs.datadoghq.com/developers/dogstatsd/datagram_shell/?tab=metrics#dogstatsd-protocol-v12
 	DataDogKeepContainerTag bool `toml:"datadog_keep_container_tag"`
 
+	// Aggregate distributions in a DDSketch per interval and report
+	// statistics and percentiles instead of publishing each raw value.
+	// Requires the DataDogDistributions flag to be enabled.
+	DataDogDistributionSketches   bool    `toml:"datadog_distribution_sketches"`
+	DataDogSketchRelativeAccuracy float64 `toml:"datadog_sketch_relative_accuracy"`
+
+	// Tag metrics received on a unixgram socket with the container id of the
+	// sending process. Requires the DataDogExtension flag to be enabled.
+	// https://docs.datadoghq.com/developers/dogstatsd/unix_socket/#origin-detection
+	DataDogOriginDetection bool `toml:"datadog_origin_detection"`
+
 	ReadBufferSize      int              `toml:"read_buffer_size"`
 	SanitizeNamesMethod string           `toml:"sanitize_name_method"`
 	Templates           []string         `toml:"templates"` // bucket -> influx templates
@@ -114,15 +131,23 @@ type Statsd struct {
 	// gauges and counters map measurement/tags hash -> field name -> metrics
 	// sets and timings map measurement/tags hash -> metrics
 	// distributions aggregate measurement/tags and are published directly
+	// sketches map measurement/tags hash -> distribution sketches
 	gauges        map[string]cachedgauge
 	counters      map[string]cachedcounter
 	sets          map[string]cachedset
 	timings       map[string]cachedtimings
 	distributions []cacheddistributions
+	sketches      map[string]cachedsketches
 
 	// Protocol listeners
-	UDPlistener *net.UDPConn
-	TCPlistener *net.TCPListener
+	UDPlistener  *net.UDPConn
+	TCPlistener  *net.TCPListener
+	unixListener *net.UnixConn
+
+	// Cache of sender pid -> container id used for origin detection
+	originLock sync.Mutex
+	origins    map[int32]string
+	procRoot   string
 
 	// track current connections so we can close them in Stop()
 	conns          map[string]*net.TCPConn
@@ -167,7 +192,8 @@ func (n *number) UnmarshalTOML(b []byte) error {
 type input struct {
 	*bytes.Buffer
 	time.Time
-	Addr string
+	Addr      string
+	Container string
 }
 
 // One statsd metric, form is <bucket>:<value>|<mtype>|@<samplerate>
@@ -219,6 +245,12 @@ type cacheddistributions struct {
 	tags  map[string]string
 }
 
+type cachedsketches struct {
+	name   string
+	fields map[string]*ddSketch
+	tags   map[string]string
+}
+
 func (*Statsd) SampleConfig() string {
 	return sampleConfig
 }
@@ -233,6 +265,17 @@ func (s *Statsd) Start(ac telegraf.Accumulator) error {
 	s.sets = make(map[string]cachedset)
 	s.timings = make(map[string]cachedtimings)
 	s.distributions = make([]cacheddistributions, 0)
+	s.sketches = make(map[string]cachedsketches)
+	s.origins = make(map[int32]string)
+	if s.procRoot == "" {
+		s.procRoot = "/proc"
+	}
+	if s.DataDogDistributionSketches && (s.DataDogSketchRelativeAccuracy <= 0 || s.DataDogSketchRelativeAccuracy >= 1) {
+		return fmt.Errorf("invalid sketch relative accuracy %v, must be in (0, 1)", s.DataDogSketchRelativeAccuracy)
+	}
+	if s.DataDogOriginDetection && s.Protocol != "unixgram" {
+		return errors.New("origin detection requires the unixgram protocol")
+	}
 
 	s.Lock()
 	defer s.Unlock()
@@ -272,7 +315,35 @@ func (s *Statsd) Start(ac telegraf.Accumulator) error {
 		s.MetricSeparator = defaultSeparator
 	}
 
-	if s.isUDP() {
+	switch {
+	case s.Protocol == "unixgram":
+		// Remove a stale socket of a previous run
+		if err := os.Remove(s.ServiceAddress); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing stale socket failed: %w", err)
+		}
+		address := &net.UnixAddr{Name: s.ServiceAddress, Net: "unixgram"}
+		conn, err := net.ListenUnixgram("unixgram", address)
+		if err != nil {
+			return err
+		}
+		if s.DataDogOriginDetection {
+			if err := enableCredentials(conn); err != nil {
+				conn.Close()
+				return fmt.Errorf("enabling origin detection failed: %w", err)
+			}
+		}
+
+		s.Log.Infof("Unixgram listening on %q", s.ServiceAddress)
+		s.unixListener = conn
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			if err := s.unixgramListen(conn); err != nil {
+				ac.AddError(err)
+			}
+		}()
+	case s.isUDP():
 		address, err := net.ResolveUDPAddr(s.Protocol, s.ServiceAddress)
 		if err != nil {
 			return err
@@ -293,7 +364,7 @@ func (s *Statsd) Start(ac telegraf.Accumulator) error {
 				ac.AddError(err)
 			}
 		}()
-	} else {
+	default:
 		address, err := net.ResolveTCPAddr("tcp", s.ServiceAddress)
 		if err != nil {
 			return err
@@ -345,6 +416,35 @@ func (s *Statsd) Gather(acc telegraf.Accumulator) error {
 	}
 	s.distributions = make([]cacheddistributions, 0)
 
+	for _, m := range s.sketches {
+		fields := make(map[string]interface{})
+		for fieldName, sketch := range m.fields {
+			var prefix string
+			if fieldName != defaultFieldName {
+				prefix = fieldName + "_"
+			}
+			fields[prefix+"count"] = sketch.count
+			fields[prefix+"sum"] = sketch.sum
+			fields[prefix+"lower"] = sketch.min
+			fields[prefix+"upper"] = sketch.max
+			fields[prefix+"median"] = sketch.quantile(0.5)
+			for _, percentile := range s.Percentiles {
+				name := fmt.Sprintf("%s%v_percentile", prefix, percentile)
+				fields[name] = sketch.quantile(float64(percentile) / 100)
+			}
+		}
+		if s.EnableAggregationTemporality {
+			fields["start_time"] = s.lastGatherTime.Format(time.RFC3339)
+		}
+		acc.AddFields(m.name, fields, m.tags, now)
+	}
+	s.sketches = make(map[string]cachedsketches)
+
+	// Forget the container of the senders as the pids might get reused
+	s.originLock.Lock()
+	s.origins = make(map[int32]string)
+	s.originLock.Unlock()
+
 	for _, m := range s.timings {
 		// Defining a template to parse field names for timers allows us to split
 		// out multiple fields per timer. In this case we prefix each stat with the
@@ -437,11 +537,17 @@ func (s *Statsd) Stop() {
 	s.Lock()
 	s.Log.Infof("Stopping the statsd service")
 	close(s.done)
-	if s.isUDP() {
+	switch {
+	case s.Protocol == "unixgram":
+		if s.unixListener != nil {
+			s.unixListener.Close()
+			os.Remove(s.ServiceAddress)
+		}
+	case s.isUDP():
 		if s.UDPlistener != nil {
 			s.UDPlistener.Close()
 		}
-	} else {
+	default:
 		if s.TCPlistener != nil {
 			s.TCPlistener.Close()
 		}
@@ -537,31 +643,78 @@ func (s *Statsd) udpListen(conn *net.UDPConn) error {
 				}
 				return nil
 			}
-			s.Stats.UDPPacketsRecv.Incr(1)
-			s.Stats.UDPBytesRecv.Incr(int64(n))
-			b, ok := s.bufPool.Get().(*bytes.Buffer)
-			if !ok {
-				return errors.New("bufPool is not a bytes buffer")
+			if err := s.queueDatagram(buf[:n], addr.IP.String(), ""); err != nil {
+				return err
 			}
-			b.Reset()
-			b.Write(buf[:n])
-			select {
-			case s.in <- input{
-				Buffer: b,
-				Time:   time.Now(),
-				Addr:   addr.IP.String()}:
-				s.Stats.PendingMessages.Set(int64(len(s.in)))
-			default:
-				s.Stats.UDPPacketsDrop.Incr(1)
-				s.drops++
-				if s.drops == 1 || s.AllowedPendingMessages == 0 || s.drops%s.AllowedPendingMessages == 0 {
-					s.Log.Errorf("Statsd message queue full. "+
-						"We have dropped %d messages so far. "+
-						"You may want to increase allowed_pending_messages in the config", s.drops)
+		}
+	}
+}
+
+// unixgramListen starts listening for datagrams on the configured Unix socket.
+func (s *Statsd) unixgramListen(conn *net.UnixConn) error {
+	if s.ReadBufferSize > 0 {
+		if err := conn.SetReadBuffer(s.ReadBufferSize); err != nil {
+			return err
+		}
+	}
+
+	buf := make([]byte, unixgramMaxPacketSize)
+	oob := make([]byte, oobSize)
+	for {
+		select {
+		case <-s.done:
+			return nil
+		default:
+			n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+			if err != nil {
+				if !strings.Contains(err.Error(), "closed network") {
+					s.Log.Errorf("Error reading: %s", err.Error())
+					continue
+				}
+				return nil
+			}
+
+			var container string
+			if s.DataDogOriginDetection {
+				if pid, ok := parseCredentials(oob[:oobn]); ok {
+					container = s.originContainer(pid)
 				}
 			}
+			if err := s.queueDatagram(buf[:n], "", container); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// queueDatagram passes the received datagram on to the parsers or drops it if
+// the message queue is full.
+func (s *Statsd) queueDatagram(data []byte, addr, container string) error {
+	s.Stats.UDPPacketsRecv.Incr(1)
+	s.Stats.UDPBytesRecv.Incr(int64(len(data)))
+	b, ok := s.bufPool.Get().(*bytes.Buffer)
+	if !ok {
+		return errors.New("bufPool is not a bytes buffer")
+	}
+	b.Reset()
+	b.Write(data)
+	select {
+	case s.in <- input{
+		Buffer:    b,
+		Time:      time.Now(),
+		Addr:      addr,
+		Container: container}:
+		s.Stats.PendingMessages.Set(int64(len(s.in)))
+	default:
+		s.Stats.UDPPacketsDrop.Incr(1)
+		s.drops++
+		if s.drops == 1 || s.AllowedPendingMessages == 0 || s.drops%s.AllowedPendingMessages == 0 {
+			s.Log.Errorf("Statsd message queue full. "+
+				"We have dropped %d messages so far. "+
+				"You may want to increase allowed_pending_messages in the config", s.drops)
 		}
 	}
+	return nil
 }
 
 // parser monitors the s.in channel, if there is a packet ready, it parses the
@@ -582,7 +735,7 @@ func (s *Statsd) parser() error {
 				switch {
 				case line == "":
 				case s.DataDogExtensions && strings.HasPrefix(line, "_e"):
-					if err := s.parseEventMessage(in.Time, line, in.Addr); err != nil {
+					if err := s.parseEvent(in.Time, line, in.Addr, in.Container); err != nil {
 						// Log the line causing the parsing error and continue
 						// with the next line to not stop the whole gathering
 						// process.
@@ -590,7 +743,7 @@ func (s *Statsd) parser() error {
 						s.Log.Debugf("  line was: %s", line)
 					}
 				default:
-					if err := s.parseStatsdLine(line); err != nil {
+					if err := s.parseLine(line, in.Container); err != nil {
 						if !errors.Is(err, errParsing) {
 							// Ignore parsing errors but error out on
 							// everything else...
@@ -608,8 +761,19 @@ func (s *Statsd) parser() error {
 // parseStatsdLine will parse the given statsd line, validating it as it goes.
 // If the line is valid, it will be cached for the next call to Gather()
 func (s *Statsd) parseStatsdLine(line string) error {
+	return s.parseLine(line, "")
+}
+
+// parseLine parses the given statsd line received from a sender running in
+// the given container. The container is empty if unknown.
+func (s *Statsd) parseLine(line, container string) error {
 	lineTags := make(map[string]string)
 	if s.DataDogExtensions {
+		// The container detected via the origin of the packet is overridden
+		// by the container ID field sent by the client
+		if container != "" {
+			lineTags["container"] = container
+		}
 		recombinedSegments := make([]string, 0)
 		// datadog tags look like this:
 		// users.online:1|c|@0.5|#country:china,environment:production
@@ -850,7 +1014,30 @@ func (s *Statsd) aggregate(m metric) {
 
 	switch m.mtype {
 	case "d":
-		if s.DataDogExtensions && s.DataDogDistributions {
+		if !s.DataDogExtensions || !s.DataDogDistributions {
+			return
+		}
+		if s.DataDogDistributionSketches {
+			cached, ok := s.sketches[m.hash]
+			if !ok {
+				cached = cachedsketches{
+					name:   m.name,
+					fields: make(map[string]*ddSketch),
+					tags:   m.tags,
+				}
+				s.sketches[m.hash] = cached
+			}
+			sketch, ok := cached.fields[m.field]
+			if !ok {
+				sketch = newDDSketch(s.DataDogSketchRelativeAccuracy)
+				cached.fields[m.field] = sketch
+			}
+			weight := 1.0
+			if m.samplerate > 0 {
+				weight = 1.0 / m.samplerate
+			}
+			sketch.add(m.floatvalue, weight)
+		} else {
 			cached := cacheddistributions{
 				name:  m.name,
 				value: m.floatvalue,
@@ -1077,6 +1264,8 @@ func init() {
 			DeleteSets:             true,
 			DeleteTimings:          true,
 			NumberWorkerThreads:    5,
+
+			DataDogSketchRelativeAccuracy: defaultSketchAccuracy,
 		}
 	})
 }
@@ -0,0 +1,46 @@
+//go:build linux
+
+package statsd
+
+import (
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// oobSize is the size of the out-of-band buffer required to receive the
+// credentials of the sending process
+var oobSize = unix.CmsgSpace(unix.SizeofUcred)
+
+// enableCredentials requests the kernel to pass the credentials of the
+// sending process with each datagram received on the socket.
+func enableCredentials(conn *net.UnixConn) error {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var serr error
+	if err := raw.Control(func(fd uintptr) {
+		serr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_PASSCRED, 1)
+	}); err != nil {
+		return err
+	}
+	return serr
+}
+
+// parseCredentials returns the pid of the sending process from the given
+// out-of-band data.
+func parseCredentials(oob []byte) (int32, bool) {
+	msgs, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return 0, false
+	}
+	for i := range msgs {
+		creds, err := unix.ParseUnixCredentials(&msgs[i])
+		if err == nil && creds.Pid > 0 {
+			return creds.Pid, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,114 @@
+//go:build linux
+
+package statsd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestUnixgramOriginDetection(t *testing.T) {
+	// Fake the cgroup of the current process to be running in a container
+	procRoot := t.TempDir()
+	piddir := filepath.Join(procRoot, strconv.Itoa(os.Getpid()))
+	require.NoError(t, os.MkdirAll(piddir, 0750))
+	cgroup, err := os.ReadFile(filepath.Join("testdata", "cgroup_containerd"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(piddir, "cgroup"), cgroup, 0600))
+
+	sock := filepath.Join(t.TempDir(), "statsd.sock")
+	plugin := &Statsd{
+		Log:                    testutil.Logger{},
+		Protocol:               "unixgram",
+		ServiceAddress:         sock,
+		AllowedPendingMessages: 100,
+		NumberWorkerThreads:    1,
+		DataDogExtensions:      true,
+		DataDogOriginDetection: true,
+		procRoot:               procRoot,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	conn, err := net.Dial("unixgram", sock)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("cpu.time_idle:42|c|#host:localhost\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	require.Eventually(t, func() bool {
+		require.NoError(t, plugin.Gather(&acc))
+		return acc.NMetrics() > 0
+	}, 3*time.Second, 100*time.Millisecond)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu_time_idle",
+			map[string]string{
+				"metric_type": "counter",
+				"host":        "localhost",
+				"container":   "f76b5a1c03caa192580874b253c158010ade668cf03080a57aa8283919d56e75",
+			},
+			map[string]interface{}{
+				"value": 42,
+			},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestUnixgram(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "statsd.sock")
+
+	// Stale sockets of previous runs must not prevent startup
+	require.NoError(t, os.WriteFile(sock, nil, 0600))
+
+	plugin := &Statsd{
+		Log:                    testutil.Logger{},
+		Protocol:               "unixgram",
+		ServiceAddress:         sock,
+		AllowedPendingMessages: 100,
+		NumberWorkerThreads:    1,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	conn, err := net.Dial("unixgram", sock)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("cpu.time_idle:42|g\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	require.Eventually(t, func() bool {
+		require.NoError(t, plugin.Gather(&acc))
+		return acc.NMetrics() > 0
+	}, 3*time.Second, 100*time.Millisecond)
+
+	plugin.Stop()
+	require.NoFileExists(t, sock)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu_time_idle",
+			map[string]string{"metric_type": "gauge"},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
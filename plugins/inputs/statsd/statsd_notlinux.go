@@ -0,0 +1,18 @@
+//go:build !linux
+
+package statsd
+
+import (
+	"errors"
+	"net"
+)
+
+const oobSize = 0
+
+func enableCredentials(*net.UnixConn) error {
+	return errors.New("origin detection is only supported on Linux")
+}
+
+func parseCredentials([]byte) (int32, bool) {
+	return 0, false
+}
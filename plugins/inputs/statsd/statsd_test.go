@@ -33,8 +33,11 @@ func newTestStatsd() *Statsd {
 	s.sets = make(map[string]cachedset)
 	s.timings = make(map[string]cachedtimings)
 	s.distributions = make([]cacheddistributions, 0)
+	s.sketches = make(map[string]cachedsketches)
+	s.origins = make(map[int32]string)
 
 	s.MetricSeparator = "_"
+	s.DataDogSketchRelativeAccuracy = defaultSketchAccuracy
 
 	return &s
 }
@@ -812,6 +815,83 @@ func TestParse_Distributions(t *testing.T) {
 	}
 }
 
+func TestParse_DistributionSketches(t *testing.T) {
+	s := newTestStatsd()
+	s.DataDogExtensions = true
+	s.DataDogDistributions = true
+	s.DataDogDistributionSketches = true
+	s.Percentiles = []number{90}
+
+	for i := 1; i <= 10; i++ {
+		line := fmt.Sprintf("test.distribution:%d|d|#env:prod", i)
+		require.NoErrorf(t, s.parseStatsdLine(line), "Parsing line %s should not have resulted in an error", line)
+	}
+	require.NoError(t, s.parseStatsdLine("test.distribution:100|d|@0.5|#env:prod"))
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Gather(acc))
+	require.Len(t, acc.Metrics, 1)
+
+	m := acc.Metrics[0]
+	require.Equal(t, "test_distribution", m.Measurement)
+	require.Equal(t, map[string]string{"env": "prod", "metric_type": "distribution"}, m.Tags)
+	require.InDelta(t, 12.0, m.Fields["count"], 1e-9)
+	require.InDelta(t, 255.0, m.Fields["sum"], 1e-9)
+	require.InDelta(t, 1.0, m.Fields["lower"], 1e-9)
+	require.InDelta(t, 100.0, m.Fields["upper"], 1e-9)
+	require.InEpsilon(t, 6.0, m.Fields["median"], 0.01)
+	require.InEpsilon(t, 10.0, m.Fields["90_percentile"], 0.01)
+
+	// Sketches are reset after each interval
+	acc.ClearMetrics()
+	require.NoError(t, s.Gather(acc))
+	require.Empty(t, acc.Metrics)
+}
+
+func TestParse_DataDogOriginContainer(t *testing.T) {
+	s := newTestStatsd()
+	s.DataDogExtensions = true
+
+	// The container id field sent by the client takes precedence
+	s.DataDogKeepContainerTag = true
+	require.NoError(t, s.parseLine("origin:1|c|c:client", "detected"))
+	require.NoError(t, s.parseLine("detected:1|c", "detected"))
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Gather(acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"origin",
+			map[string]string{"metric_type": "counter", "container": "client"},
+			map[string]interface{}{"value": 1},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+		testutil.MustMetric(
+			"detected",
+			map[string]string{"metric_type": "counter", "container": "detected"},
+			map[string]interface{}{"value": 1},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestOriginDetectionRequiresUnixgram(t *testing.T) {
+	plugin := &Statsd{
+		Log:                    testutil.Logger{},
+		Protocol:               "udp",
+		ServiceAddress:         "localhost:0",
+		DataDogExtensions:      true,
+		DataDogOriginDetection: true,
+	}
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Start(&acc), "origin detection requires the unixgram protocol")
+}
+
 func TestParseScientificNotation(t *testing.T) {
 	s := newTestStatsd()
 	sciNotationLines := []string{
@@ -0,0 +1,215 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package strongswan
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Strongswan struct {
+	Server      string          `toml:"server"`
+	Timeout     config.Duration `toml:"timeout"`
+	Connections []string        `toml:"connections"`
+	Log         telegraf.Logger `toml:"-"`
+
+	network string
+	address string
+	filter  filter.Filter
+}
+
+func (*Strongswan) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Strongswan) Init() error {
+	if s.Server == "" {
+		s.Server = "unix:///var/run/charon.vici"
+	}
+	u, err := url.Parse(s.Server)
+	if err != nil {
+		return fmt.Errorf("parsing server address failed: %w", err)
+	}
+	switch u.Scheme {
+	case "unix":
+		if u.Path == "" {
+			return fmt.Errorf("missing socket path in %q", s.Server)
+		}
+		s.network, s.address = "unix", u.Path
+	case "tcp":
+		if u.Host == "" {
+			return fmt.Errorf("missing host in %q", s.Server)
+		}
+		s.network, s.address = "tcp", u.Host
+	default:
+		return errors.New("unknown or missing address scheme")
+	}
+
+	if s.Timeout <= 0 {
+		s.Timeout = config.Duration(5 * time.Second)
+	}
+
+	f, err := filter.Compile(s.Connections)
+	if err != nil {
+		return fmt.Errorf("creating connection filter failed: %w", err)
+	}
+	s.filter = f
+
+	return nil
+}
+
+func (s *Strongswan) Gather(acc telegraf.Accumulator) error {
+	conn, err := net.DialTimeout(s.network, s.address, time.Duration(s.Timeout))
+	if err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", s.Server, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(time.Duration(s.Timeout))); err != nil {
+		return fmt.Errorf("setting deadline failed: %w", err)
+	}
+
+	client := &viciClient{conn: conn}
+	sas, err := client.listSAs()
+	if err != nil {
+		return fmt.Errorf("listing SAs failed: %w", err)
+	}
+
+	// Each event contains a single IKE SA keyed by the connection name
+	for _, event := range sas {
+		for name, v := range event {
+			ike, ok := v.(message)
+			if !ok {
+				continue
+			}
+			if s.filter != nil && !s.filter.Match(name) {
+				continue
+			}
+			s.gatherIKESA(acc, name, ike)
+		}
+	}
+
+	return nil
+}
+
+func (s *Strongswan) gatherIKESA(acc telegraf.Accumulator, name string, ike message) {
+	now := time.Now()
+
+	tags := map[string]string{
+		"connection": name,
+	}
+	for key, tag := range map[string]string{
+		"local-host":  "local_host",
+		"remote-host": "remote_host",
+		"remote-id":   "remote_id",
+		"version":     "version",
+	} {
+		if v, ok := ike[key].(string); ok && v != "" {
+			tags[tag] = v
+		}
+	}
+
+	fields := make(map[string]interface{})
+	if v, ok := ike["state"].(string); ok {
+		fields["state"] = v
+	}
+	s.addIntegers(fields, ike, "established", "rekey-time", "reauth-time")
+
+	var childCount, installed int64
+	var bytesIn, bytesOut, packetsIn, packetsOut int64
+	children, _ := ike["child-sas"].(message)
+	for _, v := range children {
+		child, ok := v.(message)
+		if !ok {
+			continue
+		}
+		childCount++
+
+		childTags := map[string]string{
+			"connection": name,
+		}
+		for _, key := range []string{"local_host", "remote_host", "remote_id"} {
+			if v, found := tags[key]; found {
+				childTags[key] = v
+			}
+		}
+		for key, tag := range map[string]string{
+			"name":     "child",
+			"mode":     "mode",
+			"protocol": "protocol",
+		} {
+			if v, ok := child[key].(string); ok && v != "" {
+				childTags[tag] = v
+			}
+		}
+
+		childFields := make(map[string]interface{})
+		if v, ok := child["state"].(string); ok {
+			childFields["state"] = v
+			if v == "INSTALLED" {
+				installed++
+			}
+		}
+		s.addIntegers(childFields, child,
+			"bytes-in", "bytes-out", "packets-in", "packets-out",
+			"use-in", "use-out", "rekey-time", "life-time", "install-time",
+		)
+		for field, sum := range map[string]*int64{
+			"bytes_in":    &bytesIn,
+			"bytes_out":   &bytesOut,
+			"packets_in":  &packetsIn,
+			"packets_out": &packetsOut,
+		} {
+			if v, ok := childFields[field].(int64); ok {
+				*sum += v
+			}
+		}
+		acc.AddFields("strongswan_child_sa", childFields, childTags, now)
+	}
+
+	fields["child_sas"] = childCount
+	fields["child_sas_installed"] = installed
+	fields["bytes_in"] = bytesIn
+	fields["bytes_out"] = bytesOut
+	fields["packets_in"] = packetsIn
+	fields["packets_out"] = packetsOut
+	acc.AddFields("strongswan_ike_sa", fields, tags, now)
+}
+
+// addIntegers adds the given keys of the message as integer fields with the
+// dashes in the key replaced by underscores
+func (s *Strongswan) addIntegers(fields map[string]interface{}, msg message, keys ...string) {
+	for _, key := range keys {
+		raw, ok := msg[key].(string)
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			s.Log.Debugf("Parsing %q value %q failed: %v", key, raw, err)
+			continue
+		}
+		fields[strings.ReplaceAll(key, "-", "_")] = v
+	}
+}
+
+func init() {
+	inputs.Add("strongswan", func() telegraf.Input {
+		return &Strongswan{
+			Timeout: config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,301 @@
+package strongswan
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   string
+		expected string
+	}{
+		{
+			name:     "invalid scheme",
+			server:   "udp://127.0.0.1:4502",
+			expected: "unknown or missing address scheme",
+		},
+		{
+			name:     "missing path",
+			server:   "unix://",
+			expected: "missing socket path",
+		},
+		{
+			name:     "missing host",
+			server:   "tcp://",
+			expected: "missing host",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Strongswan{Server: tt.server, Log: testutil.Logger{}}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestMessageEncoding(t *testing.T) {
+	msg := message{
+		"key":  "value",
+		"list": []string{"a", "bb"},
+		"section": message{
+			"nested": message{"empty": ""},
+			"list":   []string{},
+		},
+	}
+
+	buf, err := msg.encode(nil)
+	require.NoError(t, err)
+	decoded, err := decodeMessage(buf)
+	require.NoError(t, err)
+	require.Equal(t, msg, decoded)
+
+	_, err = decodeMessage(buf[:len(buf)-1])
+	require.Error(t, err)
+}
+
+func TestGather(t *testing.T) {
+	sas := []message{
+		{
+			"site-a": message{
+				"uniqueid":    "1",
+				"version":     "2",
+				"state":       "ESTABLISHED",
+				"local-host":  "192.0.2.1",
+				"remote-host": "198.51.100.1",
+				"remote-id":   "site-a.example.com",
+				"established": "3600",
+				"rekey-time":  "10800",
+				"child-sas": message{
+					"net-1": message{
+						"name":         "net",
+						"uniqueid":     "1",
+						"state":        "INSTALLED",
+						"mode":         "TUNNEL",
+						"protocol":     "ESP",
+						"bytes-in":     "1000",
+						"packets-in":   "10",
+						"use-in":       "2",
+						"bytes-out":    "2000",
+						"packets-out":  "20",
+						"use-out":      "1",
+						"rekey-time":   "3000",
+						"life-time":    "3400",
+						"install-time": "600",
+						"local-ts":     []string{"10.0.1.0/24"},
+						"remote-ts":    []string{"10.0.2.0/24"},
+					},
+					"voip-2": message{
+						"name":       "voip",
+						"uniqueid":   "2",
+						"state":      "REKEYING",
+						"mode":       "TUNNEL",
+						"protocol":   "ESP",
+						"bytes-in":   "50",
+						"packets-in": "1",
+						"bytes-out":  "60",
+						// Counters not available
+						"packets-out": "",
+					},
+				},
+			},
+		},
+		{
+			"site-b": message{
+				"uniqueid":    "2",
+				"version":     "2",
+				"state":       "CONNECTING",
+				"local-host":  "192.0.2.1",
+				"remote-host": "203.0.113.1",
+			},
+		},
+		{
+			"excluded": message{
+				"uniqueid": "3",
+				"state":    "ESTABLISHED",
+			},
+		},
+	}
+	server := newServer(t, sas)
+
+	plugin := &Strongswan{
+		Server:      "unix://" + server,
+		Connections: []string{"site-*"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"strongswan_child_sa",
+			map[string]string{
+				"connection":  "site-a",
+				"child":       "net",
+				"local_host":  "192.0.2.1",
+				"remote_host": "198.51.100.1",
+				"remote_id":   "site-a.example.com",
+				"mode":        "TUNNEL",
+				"protocol":    "ESP",
+			},
+			map[string]interface{}{
+				"state":        "INSTALLED",
+				"bytes_in":     int64(1000),
+				"packets_in":   int64(10),
+				"use_in":       int64(2),
+				"bytes_out":    int64(2000),
+				"packets_out":  int64(20),
+				"use_out":      int64(1),
+				"rekey_time":   int64(3000),
+				"life_time":    int64(3400),
+				"install_time": int64(600),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"strongswan_child_sa",
+			map[string]string{
+				"connection":  "site-a",
+				"child":       "voip",
+				"local_host":  "192.0.2.1",
+				"remote_host": "198.51.100.1",
+				"remote_id":   "site-a.example.com",
+				"mode":        "TUNNEL",
+				"protocol":    "ESP",
+			},
+			map[string]interface{}{
+				"state":      "REKEYING",
+				"bytes_in":   int64(50),
+				"packets_in": int64(1),
+				"bytes_out":  int64(60),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"strongswan_ike_sa",
+			map[string]string{
+				"connection":  "site-a",
+				"local_host":  "192.0.2.1",
+				"remote_host": "198.51.100.1",
+				"remote_id":   "site-a.example.com",
+				"version":     "2",
+			},
+			map[string]interface{}{
+				"state":               "ESTABLISHED",
+				"established":         int64(3600),
+				"rekey_time":          int64(10800),
+				"child_sas":           int64(2),
+				"child_sas_installed": int64(1),
+				"bytes_in":            int64(1050),
+				"bytes_out":           int64(2060),
+				"packets_in":          int64(11),
+				"packets_out":         int64(20),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"strongswan_ike_sa",
+			map[string]string{
+				"connection":  "site-b",
+				"local_host":  "192.0.2.1",
+				"remote_host": "203.0.113.1",
+				"version":     "2",
+			},
+			map[string]interface{}{
+				"state":               "CONNECTING",
+				"child_sas":           int64(0),
+				"child_sas_installed": int64(0),
+				"bytes_in":            int64(0),
+				"bytes_out":           int64(0),
+				"packets_in":          int64(0),
+				"packets_out":         int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherUnknownCommand(t *testing.T) {
+	server := newServer(t, nil)
+
+	plugin := &Strongswan{
+		Server: "unix://" + server,
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Gather(&acc), "command list-sas unknown")
+}
+
+// newServer starts a vici server on a unix socket answering the list-sas
+// command with the given SAs. If no SAs are given, the command is unknown.
+func newServer(t *testing.T, sas []message) string {
+	t.Helper()
+
+	addr := filepath.Join(t.TempDir(), "charon.vici")
+	listener, err := net.Listen("unix", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if err := serve(conn, sas); err != nil {
+				t.Error(err)
+			}
+			conn.Close()
+		}
+	}()
+
+	return addr
+}
+
+func serve(conn net.Conn, sas []message) error {
+	client := &viciClient{conn: conn}
+
+	p, err := client.read()
+	if err != nil {
+		return err
+	}
+	if p.ptype != packetEventRegister || p.name != "list-sa" {
+		return errors.New("expected list-sa event registration")
+	}
+	if err := client.write(packet{ptype: packetEventConfirm}); err != nil {
+		return err
+	}
+
+	p, err = client.read()
+	if err != nil {
+		return err
+	}
+	if p.ptype != packetCmdRequest || p.name != "list-sas" {
+		return errors.New("expected list-sas command")
+	}
+	if sas == nil {
+		return client.write(packet{ptype: packetCmdUnknown})
+	}
+	for _, sa := range sas {
+		if err := client.write(packet{ptype: packetEvent, name: "list-sa", msg: sa}); err != nil {
+			return err
+		}
+	}
+	return client.write(packet{ptype: packetCmdResponse})
+}
@@ -0,0 +1,295 @@
+package strongswan
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+)
+
+// Packet types of the vici protocol, see
+// https://github.com/strongswan/strongswan/blob/master/src/libcharon/plugins/vici/README.md
+const (
+	packetCmdRequest      byte = 0
+	packetCmdResponse     byte = 1
+	packetCmdUnknown      byte = 2
+	packetEventRegister   byte = 3
+	packetEventUnregister byte = 4
+	packetEventConfirm    byte = 5
+	packetEventUnknown    byte = 6
+	packetEvent           byte = 7
+)
+
+// Element types of vici messages
+const (
+	elementSectionStart byte = 1
+	elementSectionEnd   byte = 2
+	elementKeyValue     byte = 3
+	elementListStart    byte = 4
+	elementListItem     byte = 5
+	elementListEnd      byte = 6
+)
+
+// Maximum size of a vici packet as defined by the protocol
+const maxPacketSize = 512 * 1024
+
+// message is a decoded vici message, values are either strings, lists of
+// strings or nested sections
+type message map[string]interface{}
+
+type packet struct {
+	ptype byte
+	name  string
+	msg   message
+}
+
+type viciClient struct {
+	conn net.Conn
+}
+
+// listSAs queries all IKE SAs including their child SAs. The SAs are streamed
+// as "list-sa" events before the command response is sent.
+func (c *viciClient) listSAs() ([]message, error) {
+	if err := c.write(packet{ptype: packetEventRegister, name: "list-sa"}); err != nil {
+		return nil, err
+	}
+	p, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+	switch p.ptype {
+	case packetEventConfirm:
+	case packetEventUnknown:
+		return nil, errors.New("event list-sa unknown")
+	default:
+		return nil, fmt.Errorf("unexpected packet type %d", p.ptype)
+	}
+
+	// Do not block on IKE SAs currently in use
+	request := packet{ptype: packetCmdRequest, name: "list-sas", msg: message{"noblock": "yes"}}
+	if err := c.write(request); err != nil {
+		return nil, err
+	}
+
+	var sas []message
+	for {
+		p, err := c.read()
+		if err != nil {
+			return nil, err
+		}
+		switch p.ptype {
+		case packetEvent:
+			if p.name == "list-sa" {
+				sas = append(sas, p.msg)
+			}
+		case packetCmdResponse:
+			return sas, nil
+		case packetCmdUnknown:
+			return nil, errors.New("command list-sas unknown")
+		default:
+			return nil, fmt.Errorf("unexpected packet type %d", p.ptype)
+		}
+	}
+}
+
+func (c *viciClient) write(p packet) error {
+	buf := []byte{p.ptype}
+	switch p.ptype {
+	case packetCmdRequest, packetEventRegister, packetEventUnregister, packetEvent:
+		if len(p.name) > 255 {
+			return fmt.Errorf("name %q too long", p.name)
+		}
+		buf = append(buf, byte(len(p.name)))
+		buf = append(buf, p.name...)
+	}
+	buf, err := p.msg.encode(buf)
+	if err != nil {
+		return err
+	}
+	if len(buf) > maxPacketSize {
+		return fmt.Errorf("packet size %d exceeds limit", len(buf))
+	}
+
+	header := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(buf)), uint32(len(buf)))
+	_, err = c.conn.Write(append(header, buf...))
+	return err
+}
+
+func (c *viciClient) read() (packet, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(c.conn, header[:]); err != nil {
+		return packet{}, fmt.Errorf("reading packet header failed: %w", err)
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size == 0 || size > maxPacketSize {
+		return packet{}, fmt.Errorf("invalid packet size %d", size)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(c.conn, buf); err != nil {
+		return packet{}, fmt.Errorf("reading packet failed: %w", err)
+	}
+
+	p := packet{ptype: buf[0]}
+	buf = buf[1:]
+	switch p.ptype {
+	case packetCmdRequest, packetEventRegister, packetEventUnregister, packetEvent:
+		name, remainder, err := decodeName(buf)
+		if err != nil {
+			return packet{}, err
+		}
+		p.name = name
+		buf = remainder
+	}
+
+	msg, err := decodeMessage(buf)
+	if err != nil {
+		return packet{}, fmt.Errorf("decoding message failed: %w", err)
+	}
+	p.msg = msg
+
+	return p, nil
+}
+
+// encode appends the encoded message to the given buffer. Keys are sorted to
+// get a deterministic encoding.
+func (m message) encode(buf []byte) ([]byte, error) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if len(k) > 255 {
+			return nil, fmt.Errorf("key %q too long", k)
+		}
+		switch v := m[k].(type) {
+		case string:
+			if len(v) > 65535 {
+				return nil, fmt.Errorf("value of %q too long", k)
+			}
+			buf = append(buf, elementKeyValue, byte(len(k)))
+			buf = append(buf, k...)
+			buf = binary.BigEndian.AppendUint16(buf, uint16(len(v)))
+			buf = append(buf, v...)
+		case []string:
+			buf = append(buf, elementListStart, byte(len(k)))
+			buf = append(buf, k...)
+			for _, item := range v {
+				if len(item) > 65535 {
+					return nil, fmt.Errorf("list item of %q too long", k)
+				}
+				buf = append(buf, elementListItem)
+				buf = binary.BigEndian.AppendUint16(buf, uint16(len(item)))
+				buf = append(buf, item...)
+			}
+			buf = append(buf, elementListEnd)
+		case message:
+			buf = append(buf, elementSectionStart, byte(len(k)))
+			buf = append(buf, k...)
+			var err error
+			if buf, err = v.encode(buf); err != nil {
+				return nil, err
+			}
+			buf = append(buf, elementSectionEnd)
+		default:
+			return nil, fmt.Errorf("unsupported type %T for %q", v, k)
+		}
+	}
+	return buf, nil
+}
+
+func decodeMessage(buf []byte) (message, error) {
+	root := make(message)
+	sections := []message{root}
+
+	var list []string
+	var listName string
+	inList := false
+	for len(buf) > 0 {
+		element := buf[0]
+		buf = buf[1:]
+
+		current := sections[len(sections)-1]
+		switch element {
+		case elementSectionStart:
+			name, remainder, err := decodeName(buf)
+			if err != nil {
+				return nil, err
+			}
+			buf = remainder
+			section := make(message)
+			current[name] = section
+			sections = append(sections, section)
+		case elementSectionEnd:
+			if len(sections) < 2 {
+				return nil, errors.New("unexpected section end")
+			}
+			sections = sections[:len(sections)-1]
+		case elementKeyValue:
+			name, remainder, err := decodeName(buf)
+			if err != nil {
+				return nil, err
+			}
+			value, remainder, err := decodeValue(remainder)
+			if err != nil {
+				return nil, err
+			}
+			buf = remainder
+			current[name] = value
+		case elementListStart:
+			name, remainder, err := decodeName(buf)
+			if err != nil {
+				return nil, err
+			}
+			buf = remainder
+			list = make([]string, 0)
+			listName = name
+			inList = true
+		case elementListItem:
+			if !inList {
+				return nil, errors.New("list item outside of list")
+			}
+			value, remainder, err := decodeValue(buf)
+			if err != nil {
+				return nil, err
+			}
+			buf = remainder
+			list = append(list, value)
+		case elementListEnd:
+			if !inList {
+				return nil, errors.New("unexpected list end")
+			}
+			current[listName] = list
+			inList = false
+		default:
+			return nil, fmt.Errorf("unknown element type %d", element)
+		}
+	}
+	if len(sections) != 1 || inList {
+		return nil, errors.New("incomplete message")
+	}
+
+	return root, nil
+}
+
+func decodeName(buf []byte) (name string, remainder []byte, err error) {
+	if len(buf) < 1 || len(buf) < 1+int(buf[0]) {
+		return "", nil, errors.New("truncated name")
+	}
+	n := int(buf[0])
+	return string(buf[1 : 1+n]), buf[1+n:], nil
+}
+
+func decodeValue(buf []byte) (value string, remainder []byte, err error) {
+	if len(buf) < 2 {
+		return "", nil, errors.New("truncated value")
+	}
+	n := int(binary.BigEndian.Uint16(buf))
+	if len(buf) < 2+n {
+		return "", nil, errors.New("truncated value")
+	}
+	return string(buf[2 : 2+n]), buf[2+n:], nil
+}
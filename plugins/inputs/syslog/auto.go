@@ -0,0 +1,170 @@
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+
+	"github.com/leodido/go-syslog/v4"
+	"github.com/leodido/go-syslog/v4/octetcounting"
+	"github.com/leodido/go-syslog/v4/rfc3164"
+	"github.com/leodido/go-syslog/v4/rfc5424"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/socket"
+)
+
+// Maximum size of a message with non-transparent framing
+const maxNonTransparentSize = 64 * 1024
+
+// autoMachine detects the syslog standard of each message and uses the
+// corresponding parser
+type autoMachine struct {
+	rfc5424 syslog.Machine
+	rfc3164 syslog.Machine
+}
+
+func newAutoMachine() *autoMachine {
+	return &autoMachine{
+		rfc5424: rfc5424.NewParser(),
+		rfc3164: rfc3164.NewParser(rfc3164.WithYear(rfc3164.CurrentYear{})),
+	}
+}
+
+func (m *autoMachine) Parse(input []byte) (syslog.Message, error) {
+	if isRFC5424(input) {
+		return m.rfc5424.Parse(input)
+	}
+	return m.rfc3164.Parse(input)
+}
+
+func (m *autoMachine) WithBestEffort() {
+	m.rfc5424.WithBestEffort()
+	m.rfc3164.WithBestEffort()
+}
+
+func (m *autoMachine) HasBestEffort() bool {
+	return m.rfc5424.HasBestEffort()
+}
+
+// isRFC5424 checks if the message header contains a version after the
+// priority as required by RFC5424 but not present in legacy BSD messages
+// according to RFC3164
+func isRFC5424(msg []byte) bool {
+	if len(msg) == 0 || msg[0] != '<' {
+		return false
+	}
+	end := bytes.IndexByte(msg, '>')
+	if end < 2 || end > 4 {
+		return false
+	}
+
+	// The version is a non-zero number with up to three digits followed by
+	// a space
+	version := msg[end+1:]
+	var digits int
+	for digits < len(version) && digits < 3 && version[digits] >= '0' && version[digits] <= '9' {
+		digits++
+	}
+	return digits > 0 && version[0] != '0' && len(version) > digits && version[digits] == ' '
+}
+
+// createAutoStreamDataHandler splits the stream into messages according to
+// the framing and parses each message using the detected syslog standard
+func (s *Syslog) createAutoStreamDataHandler(acc telegraf.Accumulator) socket.CallbackConnection {
+	return func(src net.Addr, reader io.ReadCloser) {
+		parser := newAutoMachine()
+		if s.BestEffort {
+			parser.WithBestEffort()
+		}
+
+		// Remove port from address
+		var addr string
+		if src.Network() != "unix" {
+			var err error
+			if addr, _, err = net.SplitHostPort(src.String()); err != nil {
+				addr = src.String()
+			}
+		}
+
+		limiter := s.limiters.connection()
+		onMessage := func(data []byte) {
+			message, err := parser.Parse(data)
+			if err != nil {
+				acc.AddError(err)
+			}
+			if message == nil || !limiter.accept(addr) {
+				return
+			}
+			s.addMessage(acc, message, addr)
+		}
+
+		var err error
+		switch s.Framing {
+		case "octet-counting":
+			err = splitOctetCounting(reader, onMessage)
+		case "non-transparent":
+			// The trailer type is checked when parsing the configuration
+			trailer, _ := s.Trailer.Value()
+			err = splitNonTransparent(reader, byte(trailer), onMessage)
+		}
+		if err != nil && !errors.Is(err, net.ErrClosed) {
+			acc.AddError(err)
+		}
+	}
+}
+
+// splitOctetCounting splits the stream according to RFC5425#section-4.3.1
+// where each message is prefixed by its length
+func splitOctetCounting(r io.Reader, onMessage func([]byte)) error {
+	reader := bufio.NewReader(r)
+	for {
+		prefix, err := reader.ReadString(' ')
+		if err != nil {
+			if errors.Is(err, io.EOF) && prefix == "" {
+				return nil
+			}
+			return fmt.Errorf("reading message length failed: %w", err)
+		}
+		length, err := strconv.Atoi(prefix[:len(prefix)-1])
+		if err != nil || length <= 0 {
+			return fmt.Errorf("invalid message length %q", prefix[:len(prefix)-1])
+		}
+		if length > octetcounting.DefaultMaxSize {
+			return fmt.Errorf("message length %d exceeds maximum of %d", length, octetcounting.DefaultMaxSize)
+		}
+
+		msg := make([]byte, length)
+		if _, err := io.ReadFull(reader, msg); err != nil {
+			return fmt.Errorf("reading message failed: %w", err)
+		}
+		onMessage(msg)
+	}
+}
+
+// splitNonTransparent splits the stream at the trailer according to
+// RFC6587#section-3.4.2
+func splitNonTransparent(r io.Reader, trailer byte, onMessage func([]byte)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxNonTransparentSize)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexByte(data, trailer); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		onMessage(bytes.Clone(scanner.Bytes()))
+	}
+	return scanner.Err()
+}
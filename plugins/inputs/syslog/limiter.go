@@ -0,0 +1,113 @@
+package syslog
+
+import (
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/ratelimiter"
+)
+
+// Number of datagram sources to keep before removing idle ones
+const maxIdleSources = 1024
+
+// Time after which an idle datagram source is removed
+const sourceIdleTimeout = time.Minute
+
+// messageLimiter restricts the number of messages per second accepted from a
+// single connection or datagram source
+type messageLimiter struct {
+	limiter  *ratelimiter.RateLimiter
+	log      telegraf.Logger
+	warned   time.Time
+	lastSeen time.Time
+	sync.Mutex
+}
+
+// accept checks if the message can be accepted and logs a warning once per
+// second if messages are dropped. A nil limiter accepts all messages.
+func (l *messageLimiter) accept(src string) bool {
+	if l == nil {
+		return true
+	}
+
+	t := time.Now()
+	l.Lock()
+	l.lastSeen = t
+	l.Unlock()
+
+	if l.limiter.Remaining(t) <= 0 {
+		l.Lock()
+		defer l.Unlock()
+		if t.Sub(l.warned) >= time.Second {
+			l.log.Warnf("Rate limit exceeded for %q, dropping messages", src)
+			l.warned = t
+		}
+		return false
+	}
+	l.limiter.Accept(t, 1)
+	return true
+}
+
+// limiterStore creates the message limiters for stream connections and keeps
+// the ones of datagram sources as those do not have a connection state
+type limiterStore struct {
+	cfg     ratelimiter.RateLimitConfig
+	log     telegraf.Logger
+	sources map[string]*messageLimiter
+	sync.Mutex
+}
+
+func newLimiterStore(limit uint64, log telegraf.Logger) *limiterStore {
+	return &limiterStore{
+		cfg: ratelimiter.RateLimitConfig{
+			Limit:  config.Size(limit),
+			Period: config.Duration(time.Second),
+		},
+		log:     log,
+		sources: make(map[string]*messageLimiter),
+	}
+}
+
+// connection returns a new limiter for a stream connection
+func (s *limiterStore) connection() *messageLimiter {
+	if s == nil {
+		return nil
+	}
+
+	// The error can be ignored as the period is always valid
+	limiter, _ := s.cfg.CreateRateLimiter()
+	return &messageLimiter{limiter: limiter, log: s.log}
+}
+
+// source returns the limiter for the given datagram source
+func (s *limiterStore) source(src string) *messageLimiter {
+	if s == nil {
+		return nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if l, found := s.sources[src]; found {
+		return l
+	}
+
+	// Remove idle sources to limit memory usage
+	if len(s.sources) >= maxIdleSources {
+		now := time.Now()
+		for k, l := range s.sources {
+			l.Lock()
+			idle := now.Sub(l.lastSeen) > sourceIdleTimeout
+			l.Unlock()
+			if idle {
+				delete(s.sources, k)
+			}
+		}
+	}
+
+	l := s.connection()
+	s.sources[src] = l
+	return l
+}
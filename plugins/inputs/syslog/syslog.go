@@ -20,6 +20,7 @@ import (
 	"github.com/leodido/go-syslog/v4/rfc5424"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/plugins/common/socket"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -36,14 +37,20 @@ type Syslog struct {
 	Trailer        nontransparent.TrailerType `toml:"trailer"`
 	BestEffort     bool                       `toml:"best_effort"`
 	Separator      string                     `toml:"sdparam_separator"`
+	SDParamInclude []string                   `toml:"sdparam_include"`
+	SDParamAsTags  []string                   `toml:"sdparam_as_tags"`
+	RateLimit      uint64                     `toml:"connection_rate_limit"`
 	Log            telegraf.Logger            `toml:"-"`
 	socket.Config
 
 	mu sync.Mutex
 	wg sync.WaitGroup
 
-	url    *url.URL
-	socket *socket.Socket
+	url       *url.URL
+	socket    *socket.Socket
+	sdInclude filter.Filter
+	sdTags    filter.Filter
+	limiters  *limiterStore
 }
 
 func (*Syslog) SampleConfig() string {
@@ -63,7 +70,7 @@ func (s *Syslog) Init() error {
 	switch s.SyslogStandard {
 	case "":
 		s.SyslogStandard = "RFC5424"
-	case "RFC3164", "RFC5424":
+	case "RFC3164", "RFC5424", "auto":
 	default:
 		return fmt.Errorf("invalid 'syslog_standard' %q", s.SyslogStandard)
 	}
@@ -72,6 +79,15 @@ func (s *Syslog) Init() error {
 		s.Separator = "_"
 	}
 
+	// Setup the structured-data filters
+	var err error
+	if s.sdInclude, err = filter.Compile(s.SDParamInclude); err != nil {
+		return fmt.Errorf("creating 'sdparam_include' filter failed: %w", err)
+	}
+	if s.sdTags, err = filter.Compile(s.SDParamAsTags); err != nil {
+		return fmt.Errorf("creating 'sdparam_as_tags' filter failed: %w", err)
+	}
+
 	// Check and parse address, set default if necessary
 	if s.Address == "" {
 		s.Address = "tcp://127.0.0.1:6514"
@@ -123,6 +139,11 @@ func (s *Syslog) Start(acc telegraf.Accumulator) error {
 	addr := s.socket.Address()
 	s.Log.Infof("Listening on %s://%s", addr.Network(), addr.String())
 
+	// Setup the per-connection rate limiting
+	if s.RateLimit > 0 {
+		s.limiters = newLimiterStore(s.RateLimit, s.Log)
+	}
+
 	// Setup the callbacks and start listening
 	onError := func(err error) {
 		acc.AddError(err)
@@ -168,6 +189,8 @@ func (s *Syslog) createStreamDataHandler(acc telegraf.Accumulator) socket.Callba
 		if s.BestEffort {
 			machineOpts = append(machineOpts, rfc5424.WithBestEffort())
 		}
+	case "auto":
+		return s.createAutoStreamDataHandler(acc)
 	}
 
 	if s.Framing == "non-transparent" {
@@ -206,16 +229,17 @@ func (s *Syslog) createStreamDataHandler(acc telegraf.Accumulator) socket.Callba
 			}
 		}
 
+		limiter := s.limiters.connection()
 		parser.WithListener(func(r *syslog.Result) {
 			if r.Error != nil {
 				acc.AddError(r.Error)
 			}
-			if r.Message == nil {
+			if r.Message == nil || !limiter.accept(addr) {
 				return
 			}
 
 			// Extract message information
-			acc.AddFields("syslog", fields(r.Message, s.Separator), tags(r.Message, addr))
+			s.addMessage(acc, r.Message, addr)
 		})
 		parser.Parse(reader)
 	}
@@ -229,6 +253,8 @@ func (s *Syslog) createDatagramDataHandler(acc telegraf.Accumulator) socket.Call
 		parser = rfc3164.NewParser(rfc3164.WithYear(rfc3164.CurrentYear{}))
 	case "RFC5424":
 		parser = rfc5424.NewParser()
+	case "auto":
+		parser = newAutoMachine()
 	}
 	if s.BestEffort {
 		parser.WithBestEffort()
@@ -254,8 +280,43 @@ func (s *Syslog) createDatagramDataHandler(acc telegraf.Accumulator) socket.Call
 				addr = src.String()
 			}
 		}
-		acc.AddFields("syslog", fields(message, s.Separator), tags(message, addr))
+		if !s.limiters.source(addr).accept(addr) {
+			return
+		}
+		s.addMessage(acc, message, addr)
+	}
+}
+
+func (s *Syslog) addMessage(acc telegraf.Accumulator, msg syslog.Message, src string) {
+	fields := fields(msg)
+	tags := tags(msg, src)
+
+	// Add the structured data if any, the parameters are either added as
+	// fields or promoted to tags if allowed.
+	if msg, ok := msg.(*rfc5424.SyslogMessage); ok && msg.StructuredData != nil {
+		for sdid, sdparams := range *msg.StructuredData {
+			if len(sdparams) == 0 {
+				// When SD-ID does not have params we indicate its presence with a bool
+				if s.sdInclude == nil || s.sdInclude.Match(sdid) {
+					fields[sdid] = true
+				}
+				continue
+			}
+			for k, v := range sdparams {
+				name := sdid + s.Separator + k
+				if s.sdInclude != nil && !s.sdInclude.Match(name) {
+					continue
+				}
+				if s.sdTags != nil && s.sdTags.Match(name) {
+					tags[name] = v
+					continue
+				}
+				fields[name] = v
+			}
+		}
 	}
+
+	acc.AddFields("syslog", fields, tags)
 }
 
 func tags(msg syslog.Message, src string) map[string]string {
@@ -289,7 +350,7 @@ func tags(msg syslog.Message, src string) map[string]string {
 	return tags
 }
 
-func fields(msg syslog.Message, separator string) map[string]interface{} {
+func fields(msg syslog.Message) map[string]interface{} {
 	var fields map[string]interface{}
 	switch msg := msg.(type) {
 	case *rfc5424.SyslogMessage:
@@ -312,18 +373,6 @@ func fields(msg syslog.Message, separator string) map[string]interface{} {
 				return unicode.IsSpace(r)
 			})
 		}
-		if msg.StructuredData != nil {
-			for sdid, sdparams := range *msg.StructuredData {
-				if len(sdparams) == 0 {
-					// When SD-ID does not have params we indicate its presence with a bool
-					fields[sdid] = true
-					continue
-				}
-				for k, v := range sdparams {
-					fields[sdid+separator+k] = v
-				}
-			}
-		}
 	case *rfc3164.SyslogMessage:
 		fields = map[string]interface{}{
 			"facility_code": int(*msg.Facility),
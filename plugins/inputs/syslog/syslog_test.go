@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/leodido/go-syslog/v4/nontransparent"
 	"github.com/stretchr/testify/require"
 
@@ -355,3 +356,122 @@ func TestIssue10121(t *testing.T) {
 		return err != nil
 	}, 3*time.Second, 250*time.Millisecond)
 }
+
+func TestDetectStandard(t *testing.T) {
+	tests := []struct {
+		msg      string
+		expected bool
+	}{
+		{msg: "<29>1 2016-02-21T04:32:57+00:00 web1 app - - - A", expected: true},
+		{msg: "<1>1 - - - - - - A", expected: true},
+		{msg: "<13>Dec  2 16:31:03 host app: Test"},
+		{msg: "<13>0 - - - - - - A"},
+		{msg: "13>1 - - - - - - A"},
+		{msg: "<13>1"},
+		{msg: ""},
+	}
+	for _, tt := range tests {
+		require.Equalf(t, tt.expected, isRFC5424([]byte(tt.msg)), "message %q", tt.msg)
+	}
+}
+
+func TestAutoDetectionDatagram(t *testing.T) {
+	plugin := &Syslog{
+		Address:        "udp://127.0.0.1:0",
+		SyslogStandard: "auto",
+		Trailer:        nontransparent.LF,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	client, err := net.Dial("udp", plugin.socket.Address().String())
+	require.NoError(t, err)
+	defer client.Close()
+	for _, msg := range []string{"<13>Dec  2 16:31:03 host app: Test", "<1>1 - host app - - - A"} {
+		_, err = client.Write([]byte(msg))
+		require.NoError(t, err)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"syslog",
+			map[string]string{
+				"severity": "notice",
+				"facility": "user",
+				"hostname": "host",
+				"appname":  "app",
+				"source":   "127.0.0.1",
+			},
+			map[string]interface{}{
+				"message":       "Test",
+				"severity_code": 5,
+				"facility_code": 1,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"syslog",
+			map[string]string{
+				"severity": "alert",
+				"facility": "kern",
+				"hostname": "host",
+				"appname":  "app",
+				"source":   "127.0.0.1",
+			},
+			map[string]interface{}{
+				"version":       uint16(1),
+				"message":       "A",
+				"severity_code": 1,
+				"facility_code": 0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	require.Eventually(t, func() bool {
+		return int(acc.NMetrics()) >= len(expected)
+	}, 3*time.Second, 100*time.Millisecond)
+	plugin.Stop()
+
+	// The timestamp of RFC3164 messages depends on the current year
+	options := []cmp.Option{
+		testutil.IgnoreTime(),
+		testutil.IgnoreFields("timestamp"),
+		testutil.SortMetrics(),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+	require.Empty(t, acc.Errors)
+}
+
+func TestConnectionRateLimit(t *testing.T) {
+	plugin := &Syslog{
+		Address:   "tcp://127.0.0.1:0",
+		Framing:   "non-transparent",
+		Trailer:   nontransparent.LF,
+		RateLimit: 2,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	client, err := net.Dial("tcp", plugin.socket.Address().String())
+	require.NoError(t, err)
+	defer client.Close()
+	_, err = client.Write([]byte(strings.Repeat("<1>1 - - - - - - A\n", 5)))
+	require.NoError(t, err)
+	client.Close()
+
+	// Only the first messages within a second must be accepted
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 2
+	}, 3*time.Second, 100*time.Millisecond)
+	plugin.Stop()
+	require.Equal(t, uint64(2), acc.NMetrics())
+}
@@ -0,0 +1,264 @@
+//go:build linux && (amd64 || arm64)
+
+package systemd_journal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/ebitengine/purego"
+)
+
+// Flag for opening the journal files of the local machine only
+const sdJournalLocalOnly = 1
+
+// Functions of libsystemd loaded at runtime to avoid requiring cgo
+var (
+	sdJournalOpen          func(ret *uintptr, flags int32) int32
+	sdJournalOpenDirectory func(ret *uintptr, path string, flags int32) int32
+	sdJournalClose         func(j uintptr)
+	sdJournalAddMatch      func(j uintptr, data string, size uint64) int32
+	sdJournalSeekHead      func(j uintptr) int32
+	sdJournalSeekTail      func(j uintptr) int32
+	sdJournalSeekCursor    func(j uintptr, cursor string) int32
+	sdJournalTestCursor    func(j uintptr, cursor string) int32
+	sdJournalNext          func(j uintptr) int32
+	sdJournalPrevious      func(j uintptr) int32
+	sdJournalGetCursor     func(j uintptr, cursor **byte) int32
+	sdJournalGetRealtime   func(j uintptr, usec *uint64) int32
+	sdJournalRestartData   func(j uintptr)
+	sdJournalEnumerateData func(j uintptr, data **byte, length *uint64) int32
+	sdJournalWait          func(j uintptr, timeout uint64) int32
+	libcFree               func(ptr *byte)
+)
+
+var (
+	loadLibrary    sync.Once
+	errLoadLibrary error
+
+	libsystemdNames = []string{"libsystemd.so.0", "libsystemd.so"}
+	libcNames       = []string{"libc.so.6", "libc.so"}
+	libsystemdFuncs = map[string]interface{}{
+		"sd_journal_open":              &sdJournalOpen,
+		"sd_journal_open_directory":    &sdJournalOpenDirectory,
+		"sd_journal_close":             &sdJournalClose,
+		"sd_journal_add_match":         &sdJournalAddMatch,
+		"sd_journal_seek_head":         &sdJournalSeekHead,
+		"sd_journal_seek_tail":         &sdJournalSeekTail,
+		"sd_journal_seek_cursor":       &sdJournalSeekCursor,
+		"sd_journal_test_cursor":       &sdJournalTestCursor,
+		"sd_journal_next":              &sdJournalNext,
+		"sd_journal_previous":          &sdJournalPrevious,
+		"sd_journal_get_cursor":        &sdJournalGetCursor,
+		"sd_journal_get_realtime_usec": &sdJournalGetRealtime,
+		"sd_journal_restart_data":      &sdJournalRestartData,
+		"sd_journal_enumerate_data":    &sdJournalEnumerateData,
+		"sd_journal_wait":              &sdJournalWait,
+	}
+)
+
+func dlopen(names []string) (uintptr, error) {
+	var errs []error
+	for _, name := range names {
+		handle, err := purego.Dlopen(name, purego.RTLD_NOW|purego.RTLD_GLOBAL)
+		if err == nil {
+			return handle, nil
+		}
+		errs = append(errs, err)
+	}
+	return 0, errors.Join(errs...)
+}
+
+func loadLibsystemd() error {
+	loadLibrary.Do(func() {
+		handle, err := dlopen(libsystemdNames)
+		if err != nil {
+			errLoadLibrary = fmt.Errorf("loading libsystemd failed: %w", err)
+			return
+		}
+		for name, fptr := range libsystemdFuncs {
+			sym, err := purego.Dlsym(handle, name)
+			if err != nil {
+				errLoadLibrary = fmt.Errorf("loading symbol %q failed: %w", name, err)
+				return
+			}
+			purego.RegisterFunc(fptr, sym)
+		}
+
+		libc, err := dlopen(libcNames)
+		if err != nil {
+			errLoadLibrary = fmt.Errorf("loading libc failed: %w", err)
+			return
+		}
+		sym, err := purego.Dlsym(libc, "free")
+		if err != nil {
+			errLoadLibrary = fmt.Errorf("loading symbol \"free\" failed: %w", err)
+			return
+		}
+		purego.RegisterFunc(&libcFree, sym)
+	})
+	return errLoadLibrary
+}
+
+// journalReader provides sequential access to the journal entries
+type journalReader interface {
+	addMatch(match string) error
+	seekHead() error
+	seekTail() error
+	seekCursor(cursor string) error
+	process() error
+	next() (bool, error)
+	cursor() (string, error)
+	realtime() (time.Time, error)
+	data() (map[string]string, error)
+	close()
+}
+
+// journal implements the journalReader using the native libsystemd API
+type journal struct {
+	handle uintptr
+}
+
+func openJournal(directory string) (*journal, error) {
+	if err := loadLibsystemd(); err != nil {
+		return nil, err
+	}
+
+	var handle uintptr
+	var r int32
+	if directory != "" {
+		r = sdJournalOpenDirectory(&handle, directory, 0)
+	} else {
+		r = sdJournalOpen(&handle, sdJournalLocalOnly)
+	}
+	if r < 0 {
+		return nil, fmt.Errorf("opening journal failed: %w", syscall.Errno(-r))
+	}
+	return &journal{handle: handle}, nil
+}
+
+func (j *journal) addMatch(match string) error {
+	if r := sdJournalAddMatch(j.handle, match, uint64(len(match))); r < 0 {
+		return fmt.Errorf("adding match %q failed: %w", match, syscall.Errno(-r))
+	}
+	return nil
+}
+
+func (j *journal) seekHead() error {
+	if r := sdJournalSeekHead(j.handle); r < 0 {
+		return fmt.Errorf("seeking head failed: %w", syscall.Errno(-r))
+	}
+	return nil
+}
+
+func (j *journal) seekTail() error {
+	if r := sdJournalSeekTail(j.handle); r < 0 {
+		return fmt.Errorf("seeking tail failed: %w", syscall.Errno(-r))
+	}
+	// Position on the last entry so the next call returns new entries only
+	if r := sdJournalPrevious(j.handle); r < 0 {
+		return fmt.Errorf("moving to last entry failed: %w", syscall.Errno(-r))
+	}
+	return nil
+}
+
+func (j *journal) seekCursor(cursor string) error {
+	if r := sdJournalSeekCursor(j.handle, cursor); r < 0 {
+		return fmt.Errorf("seeking cursor failed: %w", syscall.Errno(-r))
+	}
+
+	// Position on the entry referenced by the cursor. If that entry vanished,
+	// e.g. due to journal rotation, step back to not skip the entry following
+	// the cursor position.
+	r := sdJournalNext(j.handle)
+	if r < 0 {
+		return fmt.Errorf("moving to cursor failed: %w", syscall.Errno(-r))
+	}
+	if r == 0 {
+		return nil
+	}
+	if r := sdJournalTestCursor(j.handle, cursor); r < 0 {
+		return fmt.Errorf("testing cursor failed: %w", syscall.Errno(-r))
+	} else if r == 0 {
+		if r := sdJournalPrevious(j.handle); r < 0 {
+			return fmt.Errorf("moving before cursor failed: %w", syscall.Errno(-r))
+		}
+	}
+	return nil
+}
+
+func (j *journal) process() error {
+	// Process pending journal changes such as new or rotated files without
+	// blocking
+	if r := sdJournalWait(j.handle, 0); r < 0 {
+		return fmt.Errorf("processing journal changes failed: %w", syscall.Errno(-r))
+	}
+	return nil
+}
+
+func (j *journal) next() (bool, error) {
+	r := sdJournalNext(j.handle)
+	if r < 0 {
+		return false, fmt.Errorf("reading next entry failed: %w", syscall.Errno(-r))
+	}
+	return r > 0, nil
+}
+
+func (j *journal) cursor() (string, error) {
+	var ptr *byte
+	if r := sdJournalGetCursor(j.handle, &ptr); r < 0 {
+		return "", fmt.Errorf("getting cursor failed: %w", syscall.Errno(-r))
+	}
+	defer libcFree(ptr)
+
+	var length int
+	for *(*byte)(unsafe.Add(unsafe.Pointer(ptr), length)) != 0 {
+		length++
+	}
+	return strings.Clone(unsafe.String(ptr, length)), nil
+}
+
+func (j *journal) realtime() (time.Time, error) {
+	var usec uint64
+	if r := sdJournalGetRealtime(j.handle, &usec); r < 0 {
+		return time.Time{}, fmt.Errorf("getting timestamp failed: %w", syscall.Errno(-r))
+	}
+	return time.UnixMicro(int64(usec)), nil
+}
+
+func (j *journal) data() (map[string]string, error) {
+	sdJournalRestartData(j.handle)
+
+	entries := make(map[string]string)
+	for {
+		var ptr *byte
+		var length uint64
+		r := sdJournalEnumerateData(j.handle, &ptr, &length)
+		if r < 0 {
+			return nil, fmt.Errorf("reading entry data failed: %w", syscall.Errno(-r))
+		}
+		if r == 0 {
+			break
+		}
+
+		// The data is owned by the journal and only valid until the next
+		// call, so copy it before splitting into name and value
+		raw := strings.Clone(unsafe.String(ptr, int(length)))
+		name, value, found := strings.Cut(raw, "=")
+		if !found {
+			continue
+		}
+		entries[name] = value
+	}
+	return entries, nil
+}
+
+func (j *journal) close() {
+	sdJournalClose(j.handle)
+	j.handle = 0
+}
@@ -0,0 +1,277 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux && (amd64 || arm64)
+
+package systemd_journal
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var priorityNames = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+type SystemdJournal struct {
+	Directory         string          `toml:"directory"`
+	Units             []string        `toml:"units"`
+	Priority          string          `toml:"priority"`
+	Matches           []string        `toml:"matches"`
+	InitialReadOffset string          `toml:"initial_read_offset"`
+	TagFields         []string        `toml:"tag_fields"`
+	Fields            []string        `toml:"fields"`
+	MaxEntries        int             `toml:"max_entries"`
+	Log               telegraf.Logger `toml:"-"`
+
+	fieldFilter filter.Filter
+	maxPriority int
+	open        func(directory string) (journalReader, error)
+	reader      journalReader
+	lastCursor  string
+	sync.Mutex
+}
+
+func (*SystemdJournal) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SystemdJournal) Init() error {
+	if s.Priority == "" {
+		s.Priority = "debug"
+	}
+	s.maxPriority = -1
+	for i, name := range priorityNames {
+		if s.Priority == name {
+			s.maxPriority = i
+			break
+		}
+	}
+	if s.maxPriority < 0 {
+		return fmt.Errorf("invalid priority %q", s.Priority)
+	}
+
+	for _, m := range s.Matches {
+		name, _, found := strings.Cut(m, "=")
+		if !found || name == "" {
+			return fmt.Errorf("invalid match %q, expected FIELD=value", m)
+		}
+	}
+
+	if s.InitialReadOffset == "" {
+		s.InitialReadOffset = "saved-or-end"
+	}
+	if err := choice.Check(s.InitialReadOffset, []string{"beginning", "end", "saved-or-beginning", "saved-or-end"}); err != nil {
+		return fmt.Errorf("invalid setting for initial_read_offset: %w", err)
+	}
+
+	if s.MaxEntries <= 0 {
+		return errors.New("max_entries must be positive")
+	}
+
+	f, err := filter.Compile(s.Fields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	s.fieldFilter = f
+
+	if s.open == nil {
+		s.open = func(directory string) (journalReader, error) {
+			return openJournal(directory)
+		}
+	}
+
+	return nil
+}
+
+func (s *SystemdJournal) GetState() interface{} {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.lastCursor
+}
+
+func (s *SystemdJournal) SetState(state interface{}) error {
+	cursor, ok := state.(string)
+	if !ok {
+		return fmt.Errorf("invalid type %T for state", state)
+	}
+
+	s.Lock()
+	s.lastCursor = cursor
+	s.Unlock()
+
+	return nil
+}
+
+func (s *SystemdJournal) Start(telegraf.Accumulator) error {
+	reader, err := s.open(s.Directory)
+	if err != nil {
+		return err
+	}
+
+	// Matches of the same field are OR'ed by the journal while matches of
+	// different fields are AND'ed
+	matches := make([]string, 0, len(s.Units)+s.maxPriority+1+len(s.Matches))
+	for _, unit := range s.Units {
+		matches = append(matches, "_SYSTEMD_UNIT="+unit)
+	}
+	if s.maxPriority < len(priorityNames)-1 {
+		for i := 0; i <= s.maxPriority; i++ {
+			matches = append(matches, "PRIORITY="+strconv.Itoa(i))
+		}
+	}
+	matches = append(matches, s.Matches...)
+	for _, m := range matches {
+		if err := reader.addMatch(m); err != nil {
+			reader.close()
+			return err
+		}
+	}
+
+	if err := s.seek(reader); err != nil {
+		reader.close()
+		return err
+	}
+	s.reader = reader
+
+	return nil
+}
+
+func (s *SystemdJournal) seek(reader journalReader) error {
+	s.Lock()
+	cursor := s.lastCursor
+	s.Unlock()
+
+	switch s.InitialReadOffset {
+	case "beginning":
+		return reader.seekHead()
+	case "end":
+		return reader.seekTail()
+	case "saved-or-beginning":
+		if cursor != "" {
+			return reader.seekCursor(cursor)
+		}
+		return reader.seekHead()
+	case "saved-or-end":
+		if cursor != "" {
+			return reader.seekCursor(cursor)
+		}
+		return reader.seekTail()
+	}
+	return nil
+}
+
+func (s *SystemdJournal) Gather(acc telegraf.Accumulator) error {
+	acc.SetPrecision(time.Microsecond)
+
+	if err := s.reader.process(); err != nil {
+		return err
+	}
+
+	for range s.MaxEntries {
+		ok, err := s.reader.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+
+		cursor, err := s.reader.cursor()
+		if err != nil {
+			return err
+		}
+		if err := s.gatherEntry(acc); err != nil {
+			acc.AddError(err)
+		}
+
+		s.Lock()
+		s.lastCursor = cursor
+		s.Unlock()
+	}
+	s.Log.Debugf("Reached maximum of %d entries, continuing in next gather cycle", s.MaxEntries)
+
+	return nil
+}
+
+func (s *SystemdJournal) gatherEntry(acc telegraf.Accumulator) error {
+	timestamp, err := s.reader.realtime()
+	if err != nil {
+		return err
+	}
+	data, err := s.reader.data()
+	if err != nil {
+		return err
+	}
+
+	tags := make(map[string]string, len(s.TagFields)+1)
+	for _, name := range s.TagFields {
+		if v, found := data[name]; found && v != "" && utf8.ValidString(v) {
+			tags[fieldName(name)] = v
+		}
+	}
+
+	fields := make(map[string]interface{}, len(data))
+	for name, v := range data {
+		if s.fieldFilter == nil || !s.fieldFilter.Match(name) || !utf8.ValidString(v) {
+			continue
+		}
+		fields[fieldName(name)] = v
+	}
+
+	if v, found := data["PRIORITY"]; found {
+		if p, err := strconv.Atoi(v); err == nil && p >= 0 && p < len(priorityNames) {
+			tags["severity"] = priorityNames[p]
+			fields["severity_code"] = p
+		}
+	}
+
+	// Messages might contain binary data, so drop those as we cannot
+	// represent them in a string field
+	if v, found := data["MESSAGE"]; found && utf8.ValidString(v) {
+		fields["message"] = v
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	acc.AddFields("systemd_journal", fields, tags, timestamp)
+
+	return nil
+}
+
+func (s *SystemdJournal) Stop() {
+	if s.reader != nil {
+		s.reader.close()
+		s.reader = nil
+	}
+}
+
+// fieldName converts journal field names like "_SYSTEMD_UNIT" into metric
+// field names like "systemd_unit"
+func fieldName(name string) string {
+	return strings.ToLower(strings.TrimLeft(name, "_"))
+}
+
+func init() {
+	inputs.Add("systemd_journal", func() telegraf.Input {
+		return &SystemdJournal{
+			InitialReadOffset: "saved-or-end",
+			TagFields:         []string{"_SYSTEMD_UNIT", "SYSLOG_IDENTIFIER", "_HOSTNAME"},
+			Fields:            []string{"_PID", "_UID", "_COMM"},
+			MaxEntries:        1000,
+		}
+	})
+}
@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux || !(amd64 || arm64)
+
+package systemd_journal
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type SystemdJournal struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*SystemdJournal) SampleConfig() string { return sampleConfig }
+
+func (s *SystemdJournal) Init() error {
+	s.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*SystemdJournal) Gather(telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("systemd_journal", func() telegraf.Input {
+		return &SystemdJournal{}
+	})
+}
@@ -0,0 +1,311 @@
+//go:build linux && (amd64 || arm64)
+
+package systemd_journal
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type entry struct {
+	timestamp time.Time
+	data      map[string]string
+}
+
+// fakeJournal mocks a journal with the given entries, positions are the
+// index of the current entry
+type fakeJournal struct {
+	entries []entry
+	matches []string
+	pos     int
+	closed  bool
+}
+
+func (f *fakeJournal) addMatch(match string) error {
+	f.matches = append(f.matches, match)
+	return nil
+}
+
+func (f *fakeJournal) seekHead() error {
+	f.pos = -1
+	return nil
+}
+
+func (f *fakeJournal) seekTail() error {
+	f.pos = len(f.entries) - 1
+	return nil
+}
+
+func (f *fakeJournal) seekCursor(cursor string) error {
+	pos, err := strconv.Atoi(cursor)
+	if err != nil {
+		return err
+	}
+	f.pos = pos
+	return nil
+}
+
+func (*fakeJournal) process() error {
+	return nil
+}
+
+func (f *fakeJournal) next() (bool, error) {
+	if f.pos+1 >= len(f.entries) {
+		return false, nil
+	}
+	f.pos++
+	return true, nil
+}
+
+func (f *fakeJournal) cursor() (string, error) {
+	return strconv.Itoa(f.pos), nil
+}
+
+func (f *fakeJournal) realtime() (time.Time, error) {
+	return f.entries[f.pos].timestamp, nil
+}
+
+func (f *fakeJournal) data() (map[string]string, error) {
+	return f.entries[f.pos].data, nil
+}
+
+func (f *fakeJournal) close() {
+	f.closed = true
+}
+
+func newPlugin(j *fakeJournal) *SystemdJournal {
+	return &SystemdJournal{
+		InitialReadOffset: "saved-or-end",
+		TagFields:         []string{"_SYSTEMD_UNIT", "SYSLOG_IDENTIFIER", "_HOSTNAME"},
+		Fields:            []string{"_PID", "_UID", "_COMM"},
+		MaxEntries:        1000,
+		Log:               testutil.Logger{},
+		open: func(string) (journalReader, error) {
+			return j, nil
+		},
+	}
+}
+
+var testEntries = []entry{
+	{
+		timestamp: time.Unix(1700000000, 123000),
+		data: map[string]string{
+			"MESSAGE":           "Started telegraf.service",
+			"PRIORITY":          "6",
+			"_SYSTEMD_UNIT":     "init.scope",
+			"SYSLOG_IDENTIFIER": "systemd",
+			"_HOSTNAME":         "server01",
+			"_PID":              "1",
+			"_COMM":             "systemd",
+			"_BOOT_ID":          "0c1a2b3c",
+		},
+	},
+	{
+		timestamp: time.Unix(1700000001, 0),
+		data: map[string]string{
+			"MESSAGE":           "connection refused",
+			"PRIORITY":          "3",
+			"_SYSTEMD_UNIT":     "telegraf.service",
+			"SYSLOG_IDENTIFIER": "telegraf",
+			"_HOSTNAME":         "server01",
+			"_PID":              "4242",
+			"_UID":              "998",
+		},
+	},
+	{
+		timestamp: time.Unix(1700000002, 0),
+		data: map[string]string{
+			"MESSAGE":       "\xff\xfe",
+			"PRIORITY":      "7",
+			"_SYSTEMD_UNIT": "telegraf.service",
+		},
+	},
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *SystemdJournal
+		expected string
+	}{
+		{
+			name:     "invalid priority",
+			plugin:   &SystemdJournal{Priority: "verbose", MaxEntries: 1},
+			expected: `invalid priority "verbose"`,
+		},
+		{
+			name:     "invalid match",
+			plugin:   &SystemdJournal{Matches: []string{"_PID"}, MaxEntries: 1},
+			expected: `invalid match "_PID"`,
+		},
+		{
+			name:     "invalid offset",
+			plugin:   &SystemdJournal{InitialReadOffset: "middle", MaxEntries: 1},
+			expected: "invalid setting for initial_read_offset",
+		},
+		{
+			name:     "invalid max entries",
+			plugin:   &SystemdJournal{},
+			expected: "max_entries must be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	j := &fakeJournal{}
+	plugin := newPlugin(j)
+	plugin.Units = []string{"telegraf.service", "sshd.service"}
+	plugin.Priority = "err"
+	plugin.Matches = []string{"_TRANSPORT=journal"}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	expected := []string{
+		"_SYSTEMD_UNIT=telegraf.service",
+		"_SYSTEMD_UNIT=sshd.service",
+		"PRIORITY=0",
+		"PRIORITY=1",
+		"PRIORITY=2",
+		"PRIORITY=3",
+		"_TRANSPORT=journal",
+	}
+	require.Equal(t, expected, j.matches)
+}
+
+func TestGatherFromBeginning(t *testing.T) {
+	j := &fakeJournal{entries: testEntries}
+	plugin := newPlugin(j)
+	plugin.InitialReadOffset = "beginning"
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Gather(&acc))
+	plugin.Stop()
+	require.True(t, j.closed)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"systemd_journal",
+			map[string]string{
+				"systemd_unit":      "init.scope",
+				"syslog_identifier": "systemd",
+				"hostname":          "server01",
+				"severity":          "info",
+			},
+			map[string]interface{}{
+				"message":       "Started telegraf.service",
+				"severity_code": 6,
+				"pid":           "1",
+				"comm":          "systemd",
+			},
+			time.Unix(1700000000, 123000),
+		),
+		metric.New(
+			"systemd_journal",
+			map[string]string{
+				"systemd_unit":      "telegraf.service",
+				"syslog_identifier": "telegraf",
+				"hostname":          "server01",
+				"severity":          "err",
+			},
+			map[string]interface{}{
+				"message":       "connection refused",
+				"severity_code": 3,
+				"pid":           "4242",
+				"uid":           "998",
+			},
+			time.Unix(1700000001, 0),
+		),
+		metric.New(
+			"systemd_journal",
+			map[string]string{
+				"systemd_unit": "telegraf.service",
+				"severity":     "debug",
+			},
+			map[string]interface{}{
+				"severity_code": 7,
+			},
+			time.Unix(1700000002, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+	require.Equal(t, "2", plugin.GetState())
+}
+
+func TestGatherFromEnd(t *testing.T) {
+	j := &fakeJournal{entries: testEntries[:1]}
+	plugin := newPlugin(j)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Existing entries must be skipped
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// New entries must be collected
+	j.entries = testEntries
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+}
+
+func TestGatherFromState(t *testing.T) {
+	j := &fakeJournal{entries: testEntries}
+	plugin := newPlugin(j)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.SetState("0"))
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 2)
+	require.Equal(t, time.Unix(1700000001, 0), metrics[0].Time())
+	require.Equal(t, "2", plugin.GetState())
+}
+
+func TestGatherMaxEntries(t *testing.T) {
+	j := &fakeJournal{entries: testEntries}
+	plugin := newPlugin(j)
+	plugin.InitialReadOffset = "beginning"
+	plugin.MaxEntries = 2
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+	require.Equal(t, "1", plugin.GetState())
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 3)
+	require.Equal(t, "2", plugin.GetState())
+}
+
+func TestSetStateInvalid(t *testing.T) {
+	plugin := &SystemdJournal{}
+	require.ErrorContains(t, plugin.SetState(42), "invalid type int for state")
+}
@@ -3,6 +3,7 @@ package tail
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"regexp"
 	"strings"
 	"time"
@@ -24,8 +25,11 @@ type multiline struct {
 	config        *multilineConfig
 	enabled       bool
 	patternRegexp *regexp.Regexp
+	startRegexp   *regexp.Regexp
+	endRegexp     *regexp.Regexp
 	quote         byte
 	inQuote       bool
+	lines         int
 }
 
 type multilineConfig struct {
@@ -34,6 +38,9 @@ type multilineConfig struct {
 	InvertMatch     bool                    `toml:"invert_match"`
 	PreserveNewline bool                    `toml:"preserve_newline"`
 	Quotation       string                  `toml:"quotation"`
+	StartPattern    string                  `toml:"start_pattern"`
+	EndPattern      string                  `toml:"end_pattern"`
+	MaxLines        int                     `toml:"max_lines"`
 	Timeout         *config.Duration        `toml:"timeout"`
 }
 
@@ -41,13 +48,60 @@ func (m *multiline) isEnabled() bool {
 	return m.enabled
 }
 
+// clone returns a copy of the multiline handler with an independent state for
+// use by a single file
+func (m *multiline) clone() *multiline {
+	c := *m
+	c.inQuote = false
+	c.lines = 0
+	return &c
+}
+
+// process handles the given line and returns the completed multiline events
+func (m *multiline) process(text string, buffer *bytes.Buffer) []string {
+	// The line count is only valid for the current buffer content as the
+	// buffer might have been flushed on timeout in the meantime
+	if buffer.Len() == 0 {
+		m.lines = 0
+	}
+
+	if m.startRegexp != nil || m.endRegexp != nil {
+		return m.processBoundaries(text, buffer)
+	}
+
+	if text = m.processLine(text, buffer); text != "" {
+		return []string{text}
+	}
+	return nil
+}
+
+// processBoundaries groups lines into events using the start and end patterns
+func (m *multiline) processBoundaries(text string, buffer *bytes.Buffer) []string {
+	var events []string
+
+	// A start line completes the previous event
+	if m.startRegexp != nil && m.startRegexp.MatchString(text) && buffer.Len() > 0 {
+		events = append(events, flush(buffer))
+		m.lines = 0
+	}
+	m.append(text, buffer)
+
+	// An end line or reaching the line limit completes the current event
+	if (m.endRegexp != nil && m.endRegexp.MatchString(text)) || m.limitReached() {
+		events = append(events, flush(buffer))
+		m.lines = 0
+	}
+
+	return events
+}
+
 func (m *multiline) processLine(text string, buffer *bytes.Buffer) string {
 	if m.matchQuotation(text) || m.matchString(text) {
-		// Restore the newline removed by tail's scanner
-		if buffer.Len() > 0 && m.config.PreserveNewline {
-			buffer.WriteString("\n")
+		m.append(text, buffer)
+		if m.limitReached() {
+			m.lines = 0
+			return flush(buffer)
 		}
-		buffer.WriteString(text)
 		return ""
 	}
 
@@ -55,6 +109,7 @@ func (m *multiline) processLine(text string, buffer *bytes.Buffer) string {
 		previousText := buffer.String()
 		buffer.Reset()
 		buffer.WriteString(text)
+		m.lines = 1
 		text = previousText
 	} else {
 		// next
@@ -66,11 +121,25 @@ func (m *multiline) processLine(text string, buffer *bytes.Buffer) string {
 			text = buffer.String()
 			buffer.Reset()
 		}
+		m.lines = 0
 	}
 
 	return text
 }
 
+func (m *multiline) append(text string, buffer *bytes.Buffer) {
+	// Restore the newline removed by tail's scanner
+	if buffer.Len() > 0 && m.config.PreserveNewline {
+		buffer.WriteString("\n")
+	}
+	buffer.WriteString(text)
+	m.lines++
+}
+
+func (m *multiline) limitReached() bool {
+	return m.config.MaxLines > 0 && m.lines >= m.config.MaxLines
+}
+
 func (m *multiline) matchQuotation(text string) bool {
 	if m.config.Quotation == "ignore" {
 		return false
@@ -107,14 +176,32 @@ func (m *multiline) matchString(text string) bool {
 }
 
 func (m *multilineConfig) newMultiline() (*multiline, error) {
-	var r *regexp.Regexp
+	var r, start, end *regexp.Regexp
 
 	if m.Pattern != "" {
+		if m.StartPattern != "" || m.EndPattern != "" {
+			return nil, errors.New("'pattern' cannot be combined with 'start_pattern' or 'end_pattern'")
+		}
 		var err error
 		if r, err = regexp.Compile(m.Pattern); err != nil {
 			return nil, err
 		}
 	}
+	if m.StartPattern != "" {
+		var err error
+		if start, err = regexp.Compile(m.StartPattern); err != nil {
+			return nil, fmt.Errorf("compiling start pattern failed: %w", err)
+		}
+	}
+	if m.EndPattern != "" {
+		var err error
+		if end, err = regexp.Compile(m.EndPattern); err != nil {
+			return nil, fmt.Errorf("compiling end pattern failed: %w", err)
+		}
+	}
+	if m.MaxLines < 0 {
+		return nil, errors.New("'max_lines' cannot be negative")
+	}
 
 	var quote byte
 	switch m.Quotation {
@@ -130,7 +217,7 @@ func (m *multilineConfig) newMultiline() (*multiline, error) {
 		return nil, errors.New("invalid 'quotation' setting")
 	}
 
-	enabled := m.Pattern != "" || quote != 0
+	enabled := m.Pattern != "" || quote != 0 || start != nil || end != nil
 	if m.Timeout == nil || time.Duration(*m.Timeout).Nanoseconds() == int64(0) {
 		d := config.Duration(5 * time.Second)
 		m.Timeout = &d
@@ -140,6 +227,8 @@ func (m *multilineConfig) newMultiline() (*multiline, error) {
 		config:        m,
 		enabled:       enabled,
 		patternRegexp: r,
+		startRegexp:   start,
+		endRegexp:     end,
 		quote:         quote,
 	}, nil
 }
@@ -473,3 +473,87 @@ func TestMultiLineQuotedAndPattern(t *testing.T) {
 	require.Equal(t, "5", text)
 	require.Zero(t, buffer.Len())
 }
+
+func TestMultiLineInvalidPatternCombination(t *testing.T) {
+	c := &multilineConfig{
+		Pattern:      "^=>",
+		StartPattern: "^\\d",
+	}
+	_, err := c.newMultiline()
+	require.ErrorContains(t, err, "'pattern' cannot be combined")
+}
+
+func TestMultiLineStartEndPattern(t *testing.T) {
+	c := &multilineConfig{
+		StartPattern:    "^BEGIN",
+		EndPattern:      "END$",
+		PreserveNewline: true,
+	}
+	m, err := c.newMultiline()
+	require.NoError(t, err)
+	require.True(t, m.isEnabled())
+	var buffer bytes.Buffer
+
+	require.Empty(t, m.process("BEGIN 1", &buffer))
+	require.Empty(t, m.process("a", &buffer))
+	require.Equal(t, []string{"BEGIN 1\na\nEND"}, m.process("END", &buffer))
+	require.Zero(t, buffer.Len())
+
+	// Start line completing the previous event without end line and a
+	// single-line event
+	require.Empty(t, m.process("BEGIN 2", &buffer))
+	require.Equal(t, []string{"BEGIN 2", "BEGIN 3 END"}, m.process("BEGIN 3 END", &buffer))
+	require.Zero(t, buffer.Len())
+}
+
+func TestMultiLineStartPattern(t *testing.T) {
+	c := &multilineConfig{
+		StartPattern: "^\\d{4}-\\d{2}-\\d{2}",
+	}
+	m, err := c.newMultiline()
+	require.NoError(t, err)
+	var buffer bytes.Buffer
+
+	require.Empty(t, m.process("2024-01-01 error", &buffer))
+	require.Empty(t, m.process(" at foo()", &buffer))
+	require.Empty(t, m.process(" at bar()", &buffer))
+	require.Equal(t, []string{"2024-01-01 error at foo() at bar()"}, m.process("2024-01-02 info", &buffer))
+	require.Equal(t, "2024-01-02 info", flush(&buffer))
+}
+
+func TestMultiLineMaxLines(t *testing.T) {
+	c := &multilineConfig{
+		StartPattern: "^BEGIN",
+		MaxLines:     3,
+	}
+	m, err := c.newMultiline()
+	require.NoError(t, err)
+	var buffer bytes.Buffer
+
+	require.Empty(t, m.process("BEGIN", &buffer))
+	require.Empty(t, m.process("1", &buffer))
+	require.Equal(t, []string{"BEGIN12"}, m.process("2", &buffer))
+	require.Empty(t, m.process("3", &buffer))
+
+	// Flushing due to timeout must reset the line count
+	require.Equal(t, "3", flush(&buffer))
+	require.Empty(t, m.process("BEGIN", &buffer))
+	require.Empty(t, m.process("4", &buffer))
+	require.Equal(t, []string{"BEGIN45"}, m.process("5", &buffer))
+}
+
+func TestMultiLineMaxLinesPattern(t *testing.T) {
+	c := &multilineConfig{
+		Pattern:        "^=>",
+		MatchWhichLine: previous,
+		MaxLines:       2,
+	}
+	m, err := c.newMultiline()
+	require.NoError(t, err)
+	var buffer bytes.Buffer
+
+	require.Empty(t, m.process("1", &buffer))
+	require.Equal(t, []string{"1=>2"}, m.process("=>2", &buffer))
+	require.Empty(t, m.process("=>3", &buffer))
+	require.Equal(t, []string{"=>3"}, m.process("4", &buffer))
+}
@@ -9,7 +9,10 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
+	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -24,11 +27,15 @@ import (
 	"github.com/influxdata/telegraf/plugins/common/encoding"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 //go:embed sample.conf
 var sampleConfig string
 
+// Prefix of the offset keys for files identified by fingerprint
+const fingerprintPrefix = "fingerprint:"
+
 var (
 	once sync.Once
 
@@ -45,14 +52,18 @@ type Tail struct {
 	MaxUndeliveredLines int      `toml:"max_undelivered_lines"`
 	CharacterEncoding   string   `toml:"character_encoding"`
 	PathTag             string   `toml:"path_tag"`
+	FileIdentity        string   `toml:"file_identity"`
+	FingerprintSize     int      `toml:"fingerprint_size"`
 
 	Filters      []string `toml:"filters"`
 	filterColors bool
 
 	Log          telegraf.Logger `toml:"-"`
 	tailers      map[string]*tail.Tail
+	stats        map[string]*fileStats
 	tailersMutex sync.RWMutex
 	offsets      map[string]int64
+	seen         map[string]bool
 	parserFunc   telegraf.ParserFunc
 	wg           sync.WaitGroup
 
@@ -70,6 +81,36 @@ type Tail struct {
 type empty struct{}
 type semaphore chan empty
 
+// fileStats contains the ingestion statistics of a single file
+type fileStats struct {
+	tags        map[string]string
+	lines       selfstat.Stat
+	bytes       selfstat.Stat
+	events      selfstat.Stat
+	metrics     selfstat.Stat
+	parseErrors selfstat.Stat
+	offset      selfstat.Stat
+}
+
+func newFileStats(file string) *fileStats {
+	tags := map[string]string{"file": file}
+	return &fileStats{
+		tags:        tags,
+		lines:       selfstat.Register("tail", "lines_read", tags),
+		bytes:       selfstat.Register("tail", "bytes_read", tags),
+		events:      selfstat.Register("tail", "events", tags),
+		metrics:     selfstat.Register("tail", "metrics_created", tags),
+		parseErrors: selfstat.Register("tail", "parse_errors", tags),
+		offset:      selfstat.Register("tail", "offset", tags),
+	}
+}
+
+func (s *fileStats) unregister() {
+	for _, field := range []string{"lines_read", "bytes_read", "events", "metrics_created", "parse_errors", "offset"} {
+		selfstat.Unregister("tail", field, s.tags)
+	}
+}
+
 func (*Tail) SampleConfig() string {
 	return sampleConfig
 }
@@ -97,6 +138,18 @@ func (t *Tail) Init() error {
 		return fmt.Errorf("invalid 'initial_read_offset' setting %q", t.InitialReadOffset)
 	}
 
+	switch t.FileIdentity {
+	case "":
+		t.FileIdentity = "path"
+	case "path":
+	case "fingerprint":
+		if t.FingerprintSize < 1 {
+			return errors.New("'fingerprint_size' must be positive")
+		}
+	default:
+		return fmt.Errorf("invalid 'file_identity' setting %q", t.FileIdentity)
+	}
+
 	if t.MaxUndeliveredLines == 0 {
 		return errors.New("max_undelivered_lines must be positive")
 	}
@@ -110,6 +163,7 @@ func (t *Tail) Init() error {
 
 	// init offsets
 	t.offsets = make(map[string]int64)
+	t.seen = make(map[string]bool)
 
 	dec, err := encoding.NewDecoder(t.CharacterEncoding)
 	if err != nil {
@@ -146,6 +200,7 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 	}
 
 	t.tailers = make(map[string]*tail.Tail)
+	t.stats = make(map[string]*fileStats)
 
 	err = t.tailNewFiles()
 	if err != nil {
@@ -173,13 +228,13 @@ func (t *Tail) getSeekInfo(file string) (*tail.SeekInfo, error) {
 	case "end":
 		return &tail.SeekInfo{Whence: 2, Offset: 0}, nil
 	case "", "saved-or-end":
-		if offset, ok := t.offsets[file]; ok {
+		if offset, ok := t.savedOffset(file); ok {
 			t.Log.Debugf("Using offset %d for %q", offset, file)
 			return &tail.SeekInfo{Whence: 0, Offset: offset}, nil
 		}
 		return &tail.SeekInfo{Whence: 2, Offset: 0}, nil
 	case "saved-or-beginning":
-		if offset, ok := t.offsets[file]; ok {
+		if offset, ok := t.savedOffset(file); ok {
 			t.Log.Debugf("Using offset %d for %q", offset, file)
 			return &tail.SeekInfo{Whence: 0, Offset: offset}, nil
 		}
@@ -189,6 +244,92 @@ func (t *Tail) getSeekInfo(file string) (*tail.SeekInfo, error) {
 	}
 }
 
+// savedOffset returns the persisted offset of the given file if any. When
+// identifying files by fingerprint, the offset is only used if the content at
+// the beginning of the file did not change, e.g. due to rotation.
+func (t *Tail) savedOffset(file string) (int64, bool) {
+	if t.FileIdentity != "fingerprint" {
+		offset, ok := t.offsets[file]
+		return offset, ok
+	}
+
+	fp, err := fingerprint(file, t.FingerprintSize)
+	if err != nil {
+		t.Log.Debugf("Computing fingerprint of %q failed: %v", file, err)
+		return 0, false
+	}
+	if fp != "" {
+		if offset, ok := t.offsets[fp]; ok {
+			t.seen[fp] = true
+			return offset, true
+		}
+	}
+
+	// Files too short for a fingerprint at the time of recording are
+	// identified by path, so only use the offset if the file did not shrink
+	offset, ok := t.offsets[file]
+	if !ok {
+		return 0, false
+	}
+	delete(t.offsets, file)
+	if info, err := os.Stat(file); err != nil || info.Size() < offset {
+		return 0, false
+	}
+	return offset, true
+}
+
+// recordOffset stores the offset of the given file for resuming
+func (t *Tail) recordOffset(tailer *tail.Tail) {
+	if t.Pipe {
+		return
+	}
+
+	offset, err := tailer.Tell()
+	if err != nil {
+		t.Log.Errorf("Recording offset for %q: %s", tailer.Filename, err.Error())
+		return
+	}
+	t.Log.Debugf("Recording offset %d for %q", offset, tailer.Filename)
+
+	if t.FileIdentity != "fingerprint" {
+		t.offsets[tailer.Filename] = offset
+		return
+	}
+
+	key := tailer.Filename
+	fp, err := fingerprint(tailer.Filename, t.FingerprintSize)
+	if err != nil {
+		t.Log.Debugf("Computing fingerprint of %q failed: %v", tailer.Filename, err)
+	} else if fp != "" {
+		key = fp
+		delete(t.offsets, tailer.Filename)
+	}
+	t.offsets[key] = offset
+	t.seen[key] = true
+}
+
+// fingerprint returns an identifier of the file computed from its first bytes
+// or an empty string if the file is too short
+func fingerprint(file string, size int) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	h := fnv.New64a()
+	h.Write(buf)
+	return fingerprintPrefix + strconv.FormatUint(h.Sum64(), 16), nil
+}
+
 func (t *Tail) GetState() interface{} {
 	return t.offsets
 }
@@ -205,7 +346,27 @@ func (t *Tail) SetState(state interface{}) error {
 }
 
 func (t *Tail) Gather(_ telegraf.Accumulator) error {
-	return t.tailNewFiles()
+	if err := t.tailNewFiles(); err != nil {
+		return err
+	}
+
+	// Update the current offsets of the files
+	if t.Pipe {
+		return nil
+	}
+	t.tailersMutex.RLock()
+	defer t.tailersMutex.RUnlock()
+	for file, tailer := range t.tailers {
+		stats, found := t.stats[file]
+		if !found {
+			continue
+		}
+		if offset, err := tailer.Tell(); err == nil {
+			stats.offset.Set(offset)
+		}
+	}
+
+	return nil
 }
 
 func (t *Tail) Stop() {
@@ -213,16 +374,8 @@ func (t *Tail) Stop() {
 	defer t.tailersMutex.Unlock()
 
 	for filename, tailer := range t.tailers {
-		if !t.Pipe {
-			// store offset for resume
-			offset, err := tailer.Tell()
-			if err == nil {
-				t.Log.Debugf("Recording offset %d for %q", offset, tailer.Filename)
-				t.offsets[tailer.Filename] = offset
-			} else {
-				t.Log.Errorf("Recording offset for %q: %s", tailer.Filename, err.Error())
-			}
-		}
+		// store offset for resume
+		t.recordOffset(tailer)
 		err := tailer.Stop()
 		if err != nil {
 			t.Log.Errorf("Stopping tail on %q: %s", tailer.Filename, err.Error())
@@ -231,10 +384,24 @@ func (t *Tail) Stop() {
 		// Explicitly delete the tailer from the map to avoid memory leaks
 		delete(t.tailers, filename)
 	}
+	for filename, stats := range t.stats {
+		stats.unregister()
+		delete(t.stats, filename)
+	}
 
 	t.cancel()
 	t.wg.Wait()
 
+	// Forget about fingerprints of files not seen during this run, e.g. of
+	// rotated files that got deleted
+	if t.FileIdentity == "fingerprint" {
+		for k := range t.offsets {
+			if strings.HasPrefix(k, fingerprintPrefix) && !t.seen[k] {
+				delete(t.offsets, k)
+			}
+		}
+	}
+
 	// persist offsets
 	offsetsMutex.Lock()
 	for k, v := range t.offsets {
@@ -311,13 +478,15 @@ func (t *Tail) tailNewFiles() error {
 			t.wg.Add(1)
 
 			// Store the tailer in the map before starting the goroutine
+			stats := newFileStats(tailer.Filename)
 			t.tailersMutex.Lock()
 			t.tailers[tailer.Filename] = tailer
+			t.stats[tailer.Filename] = stats
 			t.tailersMutex.Unlock()
 
 			go func(tl *tail.Tail) {
 				defer t.wg.Done()
-				t.receiver(parser, tl)
+				t.receiver(parser, tl, stats)
 
 				t.Log.Debugf("Tail removed for %q", tl.Filename)
 
@@ -326,6 +495,10 @@ func (t *Tail) tailNewFiles() error {
 						t.Log.Errorf("Deleting tailer for %q due to: %v", tl.Filename, err)
 						t.tailersMutex.Lock()
 						delete(t.tailers, tl.Filename)
+						if s, found := t.stats[tl.Filename]; found {
+							s.unregister()
+							delete(t.stats, tl.Filename)
+						}
 						t.tailersMutex.Unlock()
 					} else {
 						t.Log.Errorf("Tailing %q: %s", tl.Filename, err.Error())
@@ -352,15 +525,7 @@ func (t *Tail) cleanupUnusedTailers(currentFiles map[string]bool) error {
 			t.Log.Debugf("Removing tailer for %q as it's no longer in the glob pattern", file)
 
 			// Save the current offset for potential future use
-			if !t.Pipe {
-				offset, err := tailer.Tell()
-				if err == nil {
-					t.Log.Debugf("Recording offset %d for %q", offset, tailer.Filename)
-					t.offsets[tailer.Filename] = offset
-				} else {
-					t.Log.Errorf("Recording offset for %q: %s", tailer.Filename, err.Error())
-				}
-			}
+			t.recordOffset(tailer)
 
 			// Stop the tailer
 			err := tailer.Stop()
@@ -370,6 +535,10 @@ func (t *Tail) cleanupUnusedTailers(currentFiles map[string]bool) error {
 
 			// Remove from our map
 			delete(t.tailers, file)
+			if s, found := t.stats[file]; found {
+				s.unregister()
+				delete(t.stats, file)
+			}
 		}
 	}
 
@@ -389,7 +558,7 @@ func parseLine(parser telegraf.Parser, line string) ([]telegraf.Metric, error) {
 
 // receiver is launched as a goroutine to continuously watch a tailed logfile
 // for changes, parse any incoming messages, and add to the accumulator.
-func (t *Tail) receiver(parser telegraf.Parser, tailer *tail.Tail) {
+func (t *Tail) receiver(parser telegraf.Parser, tailer *tail.Tail, stats *fileStats) {
 	// holds the individual lines of multi-line log entries.
 	var buffer bytes.Buffer
 
@@ -397,8 +566,10 @@ func (t *Tail) receiver(parser telegraf.Parser, tailer *tail.Tail) {
 	var timeout <-chan time.Time
 
 	// The multiline mode requires a timer in order to flush the multiline buffer
-	// if no new lines are incoming.
+	// if no new lines are incoming. Each file requires its own multiline state.
+	var ml *multiline
 	if t.multiline.isEnabled() {
+		ml = t.multiline.clone()
 		timer = time.NewTimer(time.Duration(*t.MultilineConfig.Timeout))
 		timeout = timer.C
 	}
@@ -424,20 +595,28 @@ func (t *Tail) receiver(parser telegraf.Parser, tailer *tail.Tail) {
 		case <-timeout:
 		}
 
-		var text string
+		var texts []string
 
 		if line != nil {
+			stats.lines.Incr(1)
+			stats.bytes.Incr(int64(len(line.Text)) + 1)
+
 			// Fix up files with Windows line endings.
-			text = strings.TrimRight(line.Text, "\r")
+			text := strings.TrimRight(line.Text, "\r")
 
-			if t.multiline.isEnabled() {
-				if text = t.multiline.processLine(text, &buffer); text == "" {
+			if ml != nil {
+				if texts = ml.process(text, &buffer); len(texts) == 0 {
 					continue
 				}
+			} else {
+				texts = []string{text}
 			}
 		}
 		if line == nil || !channelOpen || !tailerOpen {
-			if text += flush(&buffer); text == "" {
+			if text := flush(&buffer); text != "" {
+				texts = append(texts, text)
+			}
+			if len(texts) == 0 {
 				if !channelOpen {
 					return
 				}
@@ -451,56 +630,68 @@ func (t *Tail) receiver(parser telegraf.Parser, tailer *tail.Tail) {
 			continue
 		}
 
-		if t.filterColors {
-			out, err := ansi.Strip([]byte(text))
-			if err != nil {
-				t.Log.Errorf("Cannot strip ansi colors from %s: %s", text, err)
+		for _, text := range texts {
+			if !t.processText(parser, tailer, stats, text) {
+				return
 			}
-			text = string(out)
 		}
+	}
+}
+
+// processText parses the given text and adds the resulting metrics to the
+// accumulator. It returns false if the receiver should exit.
+func (t *Tail) processText(parser telegraf.Parser, tailer *tail.Tail, stats *fileStats, text string) bool {
+	stats.events.Incr(1)
 
-		metrics, err := parseLine(parser, text)
+	if t.filterColors {
+		out, err := ansi.Strip([]byte(text))
 		if err != nil {
-			t.Log.Errorf("Malformed log line in %q: [%q]: %s",
-				tailer.Filename, text, err.Error())
-			continue
-		}
-		if len(metrics) == 0 {
-			once.Do(func() {
-				t.Log.Debug(internal.NoMetricsCreatedMsg)
-			})
-		}
-		if t.PathTag != "" {
-			for _, metric := range metrics {
-				metric.AddTag(t.PathTag, tailer.Filename)
-			}
+			t.Log.Errorf("Cannot strip ansi colors from %s: %s", text, err)
 		}
+		text = string(out)
+	}
 
-		// try writing out metric first without blocking
-		select {
-		case t.sem <- empty{}:
-			t.acc.AddTrackingMetricGroup(metrics)
-			if t.ctx.Err() != nil {
-				return // exit!
-			}
-			continue // next loop
-		default:
-			// no room. switch to blocking write.
+	metrics, err := parseLine(parser, text)
+	if err != nil {
+		stats.parseErrors.Incr(1)
+		t.Log.Errorf("Malformed log line in %q: [%q]: %s",
+			tailer.Filename, text, err.Error())
+		return true
+	}
+	if len(metrics) == 0 {
+		once.Do(func() {
+			t.Log.Debug(internal.NoMetricsCreatedMsg)
+		})
+	}
+	stats.metrics.Incr(int64(len(metrics)))
+	if t.PathTag != "" {
+		for _, metric := range metrics {
+			metric.AddTag(t.PathTag, tailer.Filename)
 		}
+	}
 
-		// Block until plugin is stopping or room is available to add metrics.
-		select {
-		case <-t.ctx.Done():
-			return
-		// Tail is trying to close so drain the sem to allow the receiver
-		// to exit. This condition is hit when the tailer may have hit the
-		// maximum undelivered lines and is trying to close.
-		case <-tailer.Dying():
-			<-t.sem
-		case t.sem <- empty{}:
-			t.acc.AddTrackingMetricGroup(metrics)
-		}
+	// try writing out metric first without blocking
+	select {
+	case t.sem <- empty{}:
+		t.acc.AddTrackingMetricGroup(metrics)
+		return t.ctx.Err() == nil
+	default:
+		// no room. switch to blocking write.
+	}
+
+	// Block until plugin is stopping or room is available to add metrics.
+	select {
+	case <-t.ctx.Done():
+		return false
+	// Tail is trying to close so drain the sem to allow the receiver
+	// to exit. This condition is hit when the tailer may have hit the
+	// maximum undelivered lines and is trying to close.
+	case <-tailer.Dying():
+		<-t.sem
+	case t.sem <- empty{}:
+		t.acc.AddTrackingMetricGroup(metrics)
 	}
+	return true
 }
 
 func newTail() *Tail {
@@ -515,6 +706,8 @@ func newTail() *Tail {
 		MaxUndeliveredLines: 1000,
 		offsets:             offsetsCopy,
 		PathTag:             "path",
+		FileIdentity:        "path",
+		FingerprintSize:     1024,
 	}
 }
 
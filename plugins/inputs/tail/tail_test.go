@@ -19,6 +19,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/parsers/grok"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
+	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -1341,3 +1342,161 @@ func TestTailCleanupGlobPatternChange(t *testing.T) {
 	tt2.tailersMutex.RUnlock()
 	require.True(t, hasFile2, "Expected to have tailer for .txt file")
 }
+
+func TestStatePersistenceFingerprint(t *testing.T) {
+	lines := []string{
+		"metric,tag=value foo=1i 1730478201000000000\n",
+		"metric,tag=value foo=2i 1730478211000000000\n",
+		"metric,tag=value foo=3i 1730478221000000000\n",
+	}
+	content := []byte(strings.Join(lines, ""))
+
+	// The current file was rotated and renamed, a new file took its place
+	dir := t.TempDir()
+	rotatedFilename := filepath.Join(dir, "input.influx.1")
+	require.NoError(t, os.WriteFile(rotatedFilename, content, 0600))
+	inputFilename := filepath.Join(dir, "input.influx")
+	newLine := "other,tag=value foo=4i 1730478231000000000\n"
+	require.NoError(t, os.WriteFile(inputFilename, []byte(newLine), 0600))
+
+	fp, err := fingerprint(rotatedFilename, 16)
+	require.NoError(t, err)
+	require.NotEmpty(t, fp)
+
+	// State recorded before the rotation, referencing the old file by
+	// fingerprint and a stale entry of a no longer existing file
+	state := map[string]int64{
+		fp:                             int64(len(lines[0])),
+		fingerprintPrefix + "deadbeef": 42,
+	}
+
+	plugin := &Tail{
+		Files:               []string{filepath.Join(dir, "input.influx*")},
+		InitialReadOffset:   "saved-or-beginning",
+		FileIdentity:        "fingerprint",
+		FingerprintSize:     16,
+		MaxUndeliveredLines: 1000,
+		PathTag:             "path",
+		Log:                 testutil.Logger{},
+	}
+	plugin.SetParserFunc(newInfluxParser)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.SetState(state))
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// The rotated file must resume at the recorded offset while the new file
+	// must be read from the beginning
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 3
+	}, 5*time.Second, 100*time.Millisecond)
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New("metric",
+			map[string]string{"tag": "value", "path": rotatedFilename},
+			map[string]interface{}{"foo": 2},
+			time.Unix(1730478211, 0),
+		),
+		metric.New("metric",
+			map[string]string{"tag": "value", "path": rotatedFilename},
+			map[string]interface{}{"foo": 3},
+			time.Unix(1730478221, 0),
+		),
+		metric.New("other",
+			map[string]string{"tag": "value", "path": inputFilename},
+			map[string]interface{}{"foo": 4},
+			time.Unix(1730478231, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+	// The new file is identified by fingerprint and stale entries are removed
+	fpInput, err := fingerprint(inputFilename, 16)
+	require.NoError(t, err)
+	expectedState := map[string]int64{
+		fp:      int64(len(content)),
+		fpInput: int64(len(newLine)),
+	}
+	require.Equal(t, expectedState, plugin.GetState())
+}
+
+func TestFingerprintShortFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "input.influx")
+	require.NoError(t, os.WriteFile(filename, []byte("short"), 0600))
+
+	fp, err := fingerprint(filename, 1024)
+	require.NoError(t, err)
+	require.Empty(t, fp)
+
+	// Offsets recorded by path must not be used if the file shrank
+	plugin := &Tail{
+		FileIdentity:    "fingerprint",
+		FingerprintSize: 1024,
+		offsets:         map[string]int64{filename: 100},
+		Log:             testutil.Logger{},
+	}
+	_, found := plugin.savedOffset(filename)
+	require.False(t, found)
+
+	plugin.offsets = map[string]int64{filename: 3}
+	offset, found := plugin.savedOffset(filename)
+	require.True(t, found)
+	require.Equal(t, int64(3), offset)
+}
+
+func TestInvalidFileIdentity(t *testing.T) {
+	plugin := newTestTail()
+	plugin.FileIdentity = "inode"
+	require.ErrorContains(t, plugin.Init(), "invalid 'file_identity' setting")
+
+	plugin = newTestTail()
+	plugin.FileIdentity = "fingerprint"
+	require.ErrorContains(t, plugin.Init(), "'fingerprint_size' must be positive")
+}
+
+func TestFileStatistics(t *testing.T) {
+	content := "metric,tag=value foo=1i 1730478201000000000\nmalformed\n"
+	filename := filepath.Join(t.TempDir(), "input.influx")
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0600))
+
+	plugin := newTestTail()
+	plugin.Log = testutil.Logger{}
+	plugin.InitialReadOffset = "beginning"
+	plugin.Files = []string{filename}
+	plugin.SetParserFunc(newInfluxParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 1
+	}, 5*time.Second, 100*time.Millisecond)
+
+	var stats map[string]interface{}
+	require.Eventually(t, func() bool {
+		require.NoError(t, plugin.Gather(&acc))
+		for _, m := range selfstat.Metrics() {
+			if m.Name() == "internal_tail" && m.Tags()["file"] == filename {
+				stats = m.Fields()
+				return stats["parse_errors"] == int64(1)
+			}
+		}
+		return false
+	}, 5*time.Second, 100*time.Millisecond)
+
+	require.Equal(t, int64(2), stats["lines_read"])
+	require.Equal(t, int64(len(content)), stats["bytes_read"])
+	require.Equal(t, int64(2), stats["events"])
+	require.Equal(t, int64(1), stats["metrics_created"])
+	require.Equal(t, int64(len(content)), stats["offset"])
+
+	// Statistics must be removed when stopping
+	plugin.Stop()
+	for _, m := range selfstat.Metrics() {
+		require.False(t, m.Name() == "internal_tail" && m.Tags()["file"] == filename)
+	}
+}
@@ -0,0 +1,270 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package temporal
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var taskQueueTypes = []struct {
+	kind  string
+	value string
+}{
+	{"workflow", "TASK_QUEUE_TYPE_WORKFLOW"},
+	{"activity", "TASK_QUEUE_TYPE_ACTIVITY"},
+}
+
+type Temporal struct {
+	URL            string          `toml:"url"`
+	APIKey         config.Secret   `toml:"api_key"`
+	Namespaces     []string        `toml:"namespaces"`
+	TaskQueues     []string        `toml:"task_queues"`
+	WorkflowCounts bool            `toml:"workflow_counts"`
+	Log            telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	server          string
+	client          *http.Client
+	namespaceFilter filter.Filter
+}
+
+func (*Temporal) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *Temporal) Init() error {
+	if t.URL == "" {
+		return errors.New("'url' required")
+	}
+	t.URL = strings.TrimRight(t.URL, "/")
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return fmt.Errorf("parsing URL %q failed: %w", t.URL, err)
+	}
+	t.server = u.Host
+
+	f, err := filter.Compile(t.Namespaces)
+	if err != nil {
+		return fmt.Errorf("creating namespace filter failed: %w", err)
+	}
+	t.namespaceFilter = f
+
+	client, err := t.HTTPClientConfig.CreateClient(context.Background(), t.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	t.client = client
+
+	return nil
+}
+
+func (t *Temporal) Gather(acc telegraf.Accumulator) error {
+	namespaces, err := t.listNamespaces()
+	if err != nil {
+		return fmt.Errorf("listing namespaces failed: %w", err)
+	}
+
+	for _, ns := range namespaces.Namespaces {
+		name := ns.NamespaceInfo.Name
+		if t.namespaceFilter != nil && !t.namespaceFilter.Match(name) {
+			continue
+		}
+
+		tags := map[string]string{
+			"server":    t.server,
+			"namespace": name,
+			"state":     strings.ToLower(strings.TrimPrefix(ns.NamespaceInfo.State, "NAMESPACE_STATE_")),
+		}
+		fields := map[string]interface{}{
+			"global": ns.IsGlobalNamespace,
+		}
+		if ttl := ns.Config.WorkflowExecutionRetentionTTL; ttl != "" {
+			retention, err := time.ParseDuration(ttl)
+			if err != nil {
+				acc.AddError(fmt.Errorf("parsing retention %q of namespace %q failed: %w", ttl, name, err))
+			} else {
+				fields["retention_seconds"] = int64(retention.Seconds())
+			}
+		}
+		if t.WorkflowCounts {
+			if err := t.gatherWorkflowCounts(name, fields); err != nil {
+				acc.AddError(fmt.Errorf("counting workflows of namespace %q failed: %w", name, err))
+			}
+		}
+		acc.AddFields("temporal_namespace", fields, tags)
+
+		for _, queue := range t.TaskQueues {
+			for _, qt := range taskQueueTypes {
+				if err := t.gatherTaskQueue(acc, name, queue, qt.kind, qt.value); err != nil {
+					acc.AddError(fmt.Errorf("describing %s task queue %q of namespace %q failed: %w", qt.kind, queue, name, err))
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *Temporal) Stop() {
+	if t.client != nil {
+		t.client.CloseIdleConnections()
+	}
+}
+
+// listNamespaces queries all pages of registered namespaces
+func (t *Temporal) listNamespaces() (*listNamespacesResponse, error) {
+	var result listNamespacesResponse
+	var token string
+	for {
+		query := url.Values{"pageSize": []string{"100"}}
+		if token != "" {
+			query.Set("nextPageToken", token)
+		}
+
+		var page listNamespacesResponse
+		if err := t.get("/api/v1/namespaces", query, &page); err != nil {
+			return nil, err
+		}
+		result.Namespaces = append(result.Namespaces, page.Namespaces...)
+
+		if page.NextPageToken == "" || page.NextPageToken == token {
+			return &result, nil
+		}
+		token = page.NextPageToken
+	}
+}
+
+// gatherWorkflowCounts adds the total number of workflow executions and the
+// number per execution status to the given fields. This requires advanced
+// visibility to be enabled on the server.
+func (t *Temporal) gatherWorkflowCounts(namespace string, fields map[string]interface{}) error {
+	query := url.Values{"query": []string{"GROUP BY ExecutionStatus"}}
+
+	var response countWorkflowExecutionsResponse
+	if err := t.get("/api/v1/namespaces/"+url.PathEscape(namespace)+"/workflow-count", query, &response); err != nil {
+		return err
+	}
+
+	fields["workflows"] = response.Count
+	for _, group := range response.Groups {
+		if len(group.GroupValues) != 1 {
+			continue
+		}
+		status, err := group.GroupValues[0].decodeString()
+		if err != nil {
+			return fmt.Errorf("decoding execution status failed: %w", err)
+		}
+		fields["workflows_"+strings.ToLower(strings.ReplaceAll(status, " ", "_"))] = group.Count
+	}
+
+	return nil
+}
+
+func (t *Temporal) gatherTaskQueue(acc telegraf.Accumulator, namespace, queue, kind, queueType string) error {
+	endpoint := "/api/v1/namespaces/" + url.PathEscape(namespace) + "/task-queues/" + url.PathEscape(queue)
+	query := url.Values{
+		"taskQueueType":          []string{queueType},
+		"reportStats":            []string{"true"},
+		"includeTaskQueueStatus": []string{"true"},
+	}
+
+	var response describeTaskQueueResponse
+	if err := t.get(endpoint, query, &response); err != nil {
+		return err
+	}
+
+	tags := map[string]string{
+		"server":          t.server,
+		"namespace":       namespace,
+		"task_queue":      queue,
+		"task_queue_type": kind,
+	}
+	fields := map[string]interface{}{
+		"pollers": len(response.Pollers),
+	}
+
+	// Prefer the statistics of newer servers over the deprecated status
+	switch {
+	case response.Stats != nil:
+		fields["backlog_count"] = response.Stats.ApproximateBacklogCount
+		fields["tasks_add_rate"] = response.Stats.TasksAddRate
+		fields["tasks_dispatch_rate"] = response.Stats.TasksDispatchRate
+		if s := response.Stats.ApproximateBacklogAge; s != "" {
+			age, err := time.ParseDuration(s)
+			if err != nil {
+				return fmt.Errorf("parsing backlog age %q failed: %w", s, err)
+			}
+			fields["backlog_age_ms"] = age.Milliseconds()
+		}
+	case response.TaskQueueStatus != nil:
+		fields["backlog_count"] = response.TaskQueueStatus.BacklogCountHint
+	}
+
+	acc.AddFields("temporal_task_queue", fields, tags)
+	return nil
+}
+
+func (t *Temporal) get(endpoint string, query url.Values, v interface{}) error {
+	address := t.URL + endpoint
+	if len(query) > 0 {
+		address += "?" + query.Encode()
+	}
+	request, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return fmt.Errorf("creating request failed: %w", err)
+	}
+	if !t.APIKey.Empty() {
+		key, err := t.APIKey.Get()
+		if err != nil {
+			return fmt.Errorf("getting API key failed: %w", err)
+		}
+		request.Header.Set("Authorization", "Bearer "+strings.TrimSpace(key.String()))
+		key.Destroy()
+	}
+	request.Header.Set("Accept", "application/json")
+
+	response, err := t.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("request returned %q: %s", response.Status, string(body))
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response failed: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	inputs.Add("temporal", func() telegraf.Input {
+		return &Temporal{
+			URL:            "http://localhost:7243",
+			WorkflowCounts: true,
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
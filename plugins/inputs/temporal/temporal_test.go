@@ -0,0 +1,151 @@
+package temporal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &Temporal{}
+	require.ErrorContains(t, plugin.Init(), "'url' required")
+
+	plugin = &Temporal{URL: "http://localhost:7243", Namespaces: []string{"["}}
+	require.ErrorContains(t, plugin.Init(), "creating namespace filter failed")
+}
+
+func TestGather(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var fn string
+		switch r.URL.Path {
+		case "/api/v1/namespaces":
+			fn = "namespaces.json"
+		case "/api/v1/namespaces/orders/workflow-count":
+			if r.URL.Query().Get("query") != "GROUP BY ExecutionStatus" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fn = "workflow_count.json"
+		case "/api/v1/namespaces/orders/task-queues/checkout":
+			switch r.URL.Query().Get("taskQueueType") {
+			case "TASK_QUEUE_TYPE_WORKFLOW":
+				fn = "task_queue_workflow.json"
+			case "TASK_QUEUE_TYPE_ACTIVITY":
+				fn = "task_queue_activity.json"
+			}
+		}
+		if fn == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		buf, err := os.ReadFile(filepath.Join("testdata", fn))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := inputs.Inputs["temporal"]().(*Temporal)
+	plugin.URL = server.URL
+	plugin.APIKey = config.NewSecret([]byte("secret"))
+	plugin.Namespaces = []string{"orders"}
+	plugin.TaskQueues = []string{"checkout"}
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	host := server.Listener.Addr().String()
+	expected := []telegraf.Metric{
+		metric.New(
+			"temporal_namespace",
+			map[string]string{
+				"server":    host,
+				"namespace": "orders",
+				"state":     "registered",
+			},
+			map[string]interface{}{
+				"global":              false,
+				"retention_seconds":   int64(259200),
+				"workflows":           int64(12),
+				"workflows_running":   int64(3),
+				"workflows_completed": int64(9),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"temporal_task_queue",
+			map[string]string{
+				"server":          host,
+				"namespace":       "orders",
+				"task_queue":      "checkout",
+				"task_queue_type": "workflow",
+			},
+			map[string]interface{}{
+				"pollers":             2,
+				"backlog_count":       int64(2),
+				"backlog_age_ms":      int64(1500),
+				"tasks_add_rate":      float64(1.5),
+				"tasks_dispatch_rate": float64(1.25),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"temporal_task_queue",
+			map[string]string{
+				"server":          host,
+				"namespace":       "orders",
+				"task_queue":      "checkout",
+				"task_queue_type": "activity",
+			},
+			map[string]interface{}{
+				"pollers":       1,
+				"backlog_count": int64(4),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	plugin := inputs.Inputs["temporal"]().(*Temporal)
+	plugin.URL = server.URL
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Gather(&acc), "401 Unauthorized")
+}
@@ -0,0 +1,68 @@
+package temporal
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// The types reflect the JSON representation of the Temporal WorkflowService
+// API messages, see https://github.com/temporalio/api for the definitions.
+// Note that 64-bit integers are encoded as strings.
+
+type listNamespacesResponse struct {
+	Namespaces []struct {
+		NamespaceInfo struct {
+			Name  string `json:"name"`
+			State string `json:"state"`
+		} `json:"namespaceInfo"`
+		Config struct {
+			WorkflowExecutionRetentionTTL string `json:"workflowExecutionRetentionTtl"`
+		} `json:"config"`
+		IsGlobalNamespace bool `json:"isGlobalNamespace"`
+	} `json:"namespaces"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+type countWorkflowExecutionsResponse struct {
+	Count  int64 `json:"count,string"`
+	Groups []struct {
+		GroupValues []payload `json:"groupValues"`
+		Count       int64     `json:"count,string"`
+	} `json:"groups"`
+}
+
+// payload is a Temporal payload with the data being base64 encoded
+type payload struct {
+	Metadata map[string]string `json:"metadata"`
+	Data     string            `json:"data"`
+}
+
+// decodeString decodes a JSON encoded string payload
+func (p *payload) decodeString() (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(p.Data)
+	if err != nil {
+		return "", err
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+type describeTaskQueueResponse struct {
+	Pollers []struct {
+		LastAccessTime string  `json:"lastAccessTime"`
+		Identity       string  `json:"identity"`
+		RatePerSecond  float64 `json:"ratePerSecond"`
+	} `json:"pollers"`
+	Stats *struct {
+		ApproximateBacklogCount int64   `json:"approximateBacklogCount,string"`
+		ApproximateBacklogAge   string  `json:"approximateBacklogAge"`
+		TasksAddRate            float64 `json:"tasksAddRate"`
+		TasksDispatchRate       float64 `json:"tasksDispatchRate"`
+	} `json:"stats"`
+	TaskQueueStatus *struct {
+		BacklogCountHint int64 `json:"backlogCountHint,string"`
+	} `json:"taskQueueStatus"`
+}
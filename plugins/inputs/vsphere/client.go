@@ -3,6 +3,7 @@ package vsphere
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -14,6 +15,8 @@ import (
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/performance"
 	"github.com/vmware/govmomi/session"
+	"github.com/vmware/govmomi/vapi/rest"
+	"github.com/vmware/govmomi/vapi/tags"
 	"github.com/vmware/govmomi/view"
 	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/methods"
@@ -43,6 +46,9 @@ type client struct {
 	views     *view.Manager
 	root      *view.ContainerView
 	perf      *performance.Manager
+	user      *url.Userinfo
+	rest      *rest.Client
+	restMux   sync.Mutex
 	valid     bool
 	timeout   time.Duration
 	closeGate sync.Once
@@ -221,6 +227,7 @@ func newClient(ctx context.Context, vSphereURL *url.URL, vs *VSphere) (*client,
 		views:   m,
 		root:    v,
 		perf:    p,
+		user:    vSphereURL.User,
 		valid:   true,
 		timeout: time.Duration(vs.Timeout),
 	}
@@ -254,6 +261,13 @@ func (c *client) close() {
 	c.closeGate.Do(func() {
 		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
 		defer cancel()
+		c.restMux.Lock()
+		if c.rest != nil {
+			if err := c.rest.Logout(ctx); err != nil {
+				c.log.Errorf("Logout of REST session: %s", err.Error())
+			}
+		}
+		c.restMux.Unlock()
 		if c.client != nil {
 			if err := c.client.Logout(ctx); err != nil {
 				c.log.Errorf("Logout: %s", err.Error())
@@ -345,6 +359,26 @@ func (c *client) counterInfoByKey(ctx context.Context) (map[int32]*types.PerfCou
 	return c.perf.CounterInfoByKey(ctx1)
 }
 
+// getTagManager returns a manager for the tagging API creating a REST session
+// on first use
+func (c *client) getTagManager(ctx context.Context) (*tags.Manager, error) {
+	c.restMux.Lock()
+	defer c.restMux.Unlock()
+	if c.rest == nil {
+		if c.user == nil {
+			return nil, errors.New("tagging API requires username and password")
+		}
+		rc := rest.NewClient(c.client.Client)
+		ctx1, cancel1 := context.WithTimeout(ctx, c.timeout)
+		defer cancel1()
+		if err := rc.Login(ctx1, c.user); err != nil {
+			return nil, fmt.Errorf("logging in to REST API failed: %w", err)
+		}
+		c.rest = rc
+	}
+	return tags.NewManager(c.rest), nil
+}
+
 func (c *client) getCustomFields(ctx context.Context) (map[int32]string, error) {
 	ctx1, cancel1 := context.WithTimeout(ctx, c.timeout)
 	defer cancel1()
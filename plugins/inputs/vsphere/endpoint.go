@@ -56,6 +56,9 @@ type endpoint struct {
 	customFields      map[int32]string
 	customAttrFilter  filter.Filter
 	customAttrEnabled bool
+	tagCategoryFilter filter.Filter
+	tagsEnabled       bool
+	tagCache          *tagCache
 	metricNameLookup  map[int32]string
 	metricNameMux     sync.RWMutex
 	log               telegraf.Logger
@@ -104,6 +107,7 @@ type objectRef struct {
 	dcname            string
 	rpname            string
 	customValues      map[string]string
+	tags              map[string]string
 	lookup            map[string]string
 }
 
@@ -128,6 +132,9 @@ func newEndpoint(ctx context.Context, parent *VSphere, address *url.URL, log tel
 		clientFactory:     newClientFactory(address, parent),
 		customAttrFilter:  newFilterOrPanic(parent.CustomAttributeInclude, parent.CustomAttributeExclude),
 		customAttrEnabled: anythingEnabled(parent.CustomAttributeExclude),
+		tagCategoryFilter: newFilterOrPanic(parent.TagCategoryInclude, parent.TagCategoryExclude),
+		tagsEnabled:       anythingEnabled(parent.TagCategoryExclude),
+		tagCache:          newTagCache(time.Duration(parent.TagCacheTTL)),
 		log:               log,
 	}
 
@@ -538,6 +545,19 @@ func (e *endpoint) discover(ctx context.Context) error {
 		}
 	}
 
+	// Load vSphere tags attached to the objects
+	if e.tagsEnabled {
+		objectTags, err := e.loadTags(ctx, client, newObjects)
+		if err != nil {
+			e.log.Warnf("Could not load tags: %v", err)
+		}
+		for _, objects := range newObjects {
+			for moid, obj := range objects {
+				obj.tags = objectTags[moid]
+			}
+		}
+	}
+
 	// Atomically swap maps
 	e.collectMux.Lock()
 	defer e.collectMux.Unlock()
@@ -1421,6 +1441,11 @@ func (e *endpoint) populateTags(objectRef *objectRef, resourceType string, resou
 			t[k] = v
 		}
 	}
+
+	// Fill in vSphere tags using the category as key
+	for k, v := range objectRef.tags {
+		t[k] = v
+	}
 }
 
 func (e *endpoint) populateGlobalFields(objectRef *objectRef, resourceType, prefix string) map[string]interface{} {
@@ -0,0 +1,143 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/vapi/tags"
+	"github.com/vmware/govmomi/vim25/mo"
+)
+
+// Maximum number of objects to query attached tags for in one request
+const maxTagQueryObjects = 1000
+
+type tagInfo struct {
+	name     string
+	category string
+}
+
+// tagCache caches the definitions of tags and categories to avoid querying the
+// tagging API for every tag attached to an object. The definitions are reloaded
+// if the cache expired or if an unknown tag is encountered.
+type tagCache struct {
+	ttl     time.Duration
+	expires time.Time
+	tags    map[string]tagInfo
+}
+
+func newTagCache(ttl time.Duration) *tagCache {
+	return &tagCache{
+		ttl:  ttl,
+		tags: make(map[string]tagInfo),
+	}
+}
+
+func (tc *tagCache) lookup(id string) (tagInfo, bool) {
+	if time.Now().After(tc.expires) {
+		return tagInfo{}, false
+	}
+	t, ok := tc.tags[id]
+	return t, ok
+}
+
+func (tc *tagCache) reload(ctx context.Context, m *tags.Manager) error {
+	categories, err := m.GetCategories(ctx)
+	if err != nil {
+		return fmt.Errorf("getting categories failed: %w", err)
+	}
+	categoryNames := make(map[string]string, len(categories))
+	for _, c := range categories {
+		categoryNames[c.ID] = c.Name
+	}
+
+	list, err := m.GetTags(ctx)
+	if err != nil {
+		return fmt.Errorf("getting tags failed: %w", err)
+	}
+	tc.tags = make(map[string]tagInfo, len(list))
+	for _, t := range list {
+		tc.tags[t.ID] = tagInfo{name: t.Name, category: categoryNames[t.CategoryID]}
+	}
+	tc.expires = time.Now().Add(tc.ttl)
+
+	return nil
+}
+
+// loadTags returns the tags of the selected categories attached to the given
+// objects keyed by the MOID of the object. Multiple tags of the same category
+// are joined by comma.
+func (e *endpoint) loadTags(ctx context.Context, client *client, objects map[string]objectMap) (map[string]map[string]string, error) {
+	m, err := client.getTagManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The same object might be discovered for different resource kinds
+	refs := make([]mo.Reference, 0)
+	seen := make(map[string]bool)
+	for _, objs := range objects {
+		for moid, obj := range objs {
+			if !seen[moid] {
+				seen[moid] = true
+				refs = append(refs, obj.ref)
+			}
+		}
+	}
+
+	attached := make([]tags.AttachedTags, 0, len(refs))
+	for start := 0; start < len(refs); start += maxTagQueryObjects {
+		end := min(start+maxTagQueryObjects, len(refs))
+		ctx1, cancel1 := context.WithTimeout(ctx, time.Duration(e.parent.Timeout))
+		res, err := m.ListAttachedTagsOnObjects(ctx1, refs[start:end])
+		cancel1()
+		if err != nil {
+			return nil, fmt.Errorf("listing attached tags failed: %w", err)
+		}
+		attached = append(attached, res...)
+	}
+
+	// Reload the definitions at most once to not hammer the API with
+	// requests for tags deleted in the meantime
+	var reloaded bool
+	values := make(map[string]map[string][]string)
+	for _, a := range attached {
+		moid := a.ObjectID.Reference().Value
+		for _, id := range a.TagIDs {
+			t, found := e.tagCache.lookup(id)
+			if !found && !reloaded {
+				reloaded = true
+				ctx1, cancel1 := context.WithTimeout(ctx, time.Duration(e.parent.Timeout))
+				err := e.tagCache.reload(ctx1, m)
+				cancel1()
+				if err != nil {
+					return nil, err
+				}
+				t, found = e.tagCache.lookup(id)
+			}
+			if !found {
+				e.log.Debugf("Tag %q attached to %q not found", id, moid)
+				continue
+			}
+			if t.category == "" || !e.tagCategoryFilter.Match(t.category) {
+				continue
+			}
+			if _, ok := values[moid]; !ok {
+				values[moid] = make(map[string][]string)
+			}
+			values[moid][t.category] = append(values[moid][t.category], t.name)
+		}
+	}
+
+	result := make(map[string]map[string]string, len(values))
+	for moid, categories := range values {
+		result[moid] = make(map[string]string, len(categories))
+		for category, names := range categories {
+			sort.Strings(names)
+			result[moid][category] = strings.Join(names, ",")
+		}
+	}
+	return result, nil
+}
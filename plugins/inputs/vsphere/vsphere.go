@@ -62,6 +62,9 @@ type VSphere struct {
 	Separator                   string          `toml:"separator"`
 	CustomAttributeInclude      []string        `toml:"custom_attribute_include"`
 	CustomAttributeExclude      []string        `toml:"custom_attribute_exclude"`
+	TagCategoryInclude          []string        `toml:"tag_category_include"`
+	TagCategoryExclude          []string        `toml:"tag_category_exclude"`
+	TagCacheTTL                 config.Duration `toml:"tag_cache_ttl"`
 	UseIntSamples               bool            `toml:"use_int_samples"`
 	IPAddresses                 []string        `toml:"ip_addresses"`
 	MetricLookback              int             `toml:"metric_lookback"`
@@ -162,6 +165,8 @@ func init() {
 			VSANClusterInclude:          []string{"/*/host/**"},
 			Separator:                   "_",
 			CustomAttributeExclude:      []string{"*"},
+			TagCategoryExclude:          []string{"*"},
+			TagCacheTTL:                 config.Duration(time.Hour),
 			UseIntSamples:               true,
 			MaxQueryObjects:             256,
 			MaxQueryMetrics:             256,
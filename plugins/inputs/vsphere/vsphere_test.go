@@ -10,8 +10,13 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/performance"
 	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vapi/rest"
+	_ "github.com/vmware/govmomi/vapi/simulator"
+	"github.com/vmware/govmomi/vapi/tags"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 
@@ -155,6 +160,7 @@ func createSim(folders int) (*simulator.Model, *simulator.Server, error) {
 	}
 
 	model.Service.TLS = new(tls.Config)
+	model.Service.RegisterEndpoints = true
 
 	s := model.Service.NewServer()
 	return model, s, nil
@@ -449,6 +455,56 @@ func TestVsanTags(t *testing.T) {
 	require.Len(t, tags, 2)
 }
 
+func TestImportTags(t *testing.T) {
+	m, s, err := createSim(0)
+	require.NoError(t, err)
+	defer m.Remove()
+	defer s.Close()
+
+	// Create the tags in vCenter and attach them to a VM
+	c, err := govmomi.NewClient(t.Context(), s.URL, true)
+	require.NoError(t, err)
+	rc := rest.NewClient(c.Client)
+	require.NoError(t, rc.Login(t.Context(), s.URL.User))
+	manager := tags.NewManager(rc)
+
+	vm := m.Map().Any("VirtualMachine").(*simulator.VirtualMachine)
+	for category, names := range map[string][]string{"BusinessUnit": {"finance", "hr"}, "Owner": {"alice"}} {
+		categoryID, err := manager.CreateCategory(t.Context(), &tags.Category{
+			Name:            category,
+			Cardinality:     "MULTIPLE",
+			AssociableTypes: []string{"VirtualMachine"},
+		})
+		require.NoError(t, err)
+		for _, name := range names {
+			tagID, err := manager.CreateTag(t.Context(), &tags.Tag{Name: name, CategoryID: categoryID})
+			require.NoError(t, err)
+			require.NoError(t, manager.AttachTag(t.Context(), tagID, vm.Reference()))
+		}
+	}
+
+	v := defaultVSphere()
+	v.Vcenters = []string{s.URL.String()}
+	v.TagCategoryInclude = []string{"BusinessUnit"}
+	v.TagCategoryExclude = make([]string, 0)
+	v.TagCacheTTL = config.Duration(time.Hour)
+
+	var acc testutil.Accumulator
+	require.NoError(t, v.Start(&acc))
+	defer v.Stop()
+
+	e := v.endpoints[0]
+	obj, found := e.resourceKinds["vm"].objects[vm.Reference().Value]
+	require.True(t, found)
+	require.Equal(t, map[string]string{"BusinessUnit": "finance,hr"}, obj.tags)
+
+	// The tags are added to the metrics of the object
+	tagMap := map[string]string{}
+	e.populateTags(obj, "vm", e.resourceKinds["vm"], tagMap, performance.MetricSeries{Name: "cpu.usage.average"})
+	require.Equal(t, "finance,hr", tagMap["BusinessUnit"])
+	require.NotContains(t, tagMap, "Owner")
+}
+
 func TestCollectionNoClusterMetrics(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping long test in short mode")
@@ -0,0 +1,132 @@
+package weather
+
+import (
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	knotsToMetersPerSecond = 0.514444
+	milesToMeters          = 1609.344
+	inchesToMillimeters    = 25.4
+)
+
+// metarReport is a METAR report as returned by the aviationweather.gov API,
+// wind direction and visibility might be reported as strings like "VRB" or
+// "10+" so those fields are decoded dynamically
+type metarReport struct {
+	Station        string      `json:"icaoId"`
+	Name           string      `json:"name"`
+	ObservedAt     int64       `json:"obsTime"`
+	Temperature    *float64    `json:"temp"`
+	Dewpoint       *float64    `json:"dewp"`
+	WindDirection  interface{} `json:"wdir"`
+	WindSpeed      *float64    `json:"wspd"`
+	WindGust       *float64    `json:"wgst"`
+	Visibility     interface{} `json:"visib"`
+	Altimeter      *float64    `json:"altim"`
+	SeaLevelPress  *float64    `json:"slp"`
+	Precipitation  *float64    `json:"precip"`
+	RawObservation string      `json:"rawOb"`
+}
+
+func (w *Weather) gatherMETAR(acc telegraf.Accumulator) error {
+	query := url.Values{
+		"ids":    {strings.Join(w.Stations, ",")},
+		"format": {"json"},
+	}
+	var reports []metarReport
+	if err := w.get(w.URL+"?"+query.Encode(), &reports); err != nil {
+		return err
+	}
+
+	// Only report the latest observation of each station
+	latest := make(map[string]metarReport, len(reports))
+	for _, r := range reports {
+		if prev, found := latest[r.Station]; !found || r.ObservedAt > prev.ObservedAt {
+			latest[r.Station] = r
+		}
+	}
+
+	for _, station := range w.Stations {
+		r, found := latest[station]
+		if !found {
+			w.Log.Debugf("No report for station %q", station)
+			continue
+		}
+
+		fields := make(map[string]interface{})
+		if r.Temperature != nil {
+			fields["temperature"] = *r.Temperature
+		}
+		if r.Dewpoint != nil {
+			fields["dewpoint"] = *r.Dewpoint
+		}
+		if r.Temperature != nil && r.Dewpoint != nil {
+			fields["humidity"] = relativeHumidity(*r.Temperature, *r.Dewpoint)
+		}
+		// Variable wind directions are reported as "VRB"
+		if dir, ok := r.WindDirection.(float64); ok {
+			fields["wind_direction"] = int64(dir)
+		}
+		if r.WindSpeed != nil {
+			fields["wind_speed"] = *r.WindSpeed * knotsToMetersPerSecond
+		}
+		if r.WindGust != nil {
+			fields["wind_gust"] = *r.WindGust * knotsToMetersPerSecond
+		}
+		if v, ok := parseVisibility(r.Visibility); ok {
+			fields["visibility"] = v * milesToMeters
+		}
+		if r.Altimeter != nil {
+			fields["altimeter"] = *r.Altimeter
+		}
+		if r.SeaLevelPress != nil {
+			fields["sea_level_pressure"] = *r.SeaLevelPress
+		}
+		if r.Precipitation != nil {
+			fields["precipitation"] = *r.Precipitation * inchesToMillimeters
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		fields["raw"] = r.RawObservation
+
+		tags := map[string]string{
+			"provider": "metar",
+			"station":  r.Station,
+		}
+		if r.Name != "" {
+			tags["station_name"] = r.Name
+		}
+		acc.AddFields("weather", fields, tags, time.Unix(r.ObservedAt, 0))
+	}
+
+	return nil
+}
+
+// parseVisibility returns the visibility in statute miles, values above the
+// reporting limit are reported with a trailing plus, e.g. "10+"
+func parseVisibility(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSuffix(v, "+"), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// relativeHumidity computes the relative humidity in percent from the
+// temperature and dewpoint in degree Celsius using the Magnus formula
+func relativeHumidity(temperature, dewpoint float64) float64 {
+	const b, c = 17.625, 243.04
+	rh := 100 * math.Exp(b*dewpoint/(c+dewpoint)-b*temperature/(c+temperature))
+	return math.Round(rh*10) / 10
+}
@@ -0,0 +1,103 @@
+package weather
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// nwsValue is a quantity reported by the NWS API including its WMO unit code,
+// missing values are reported as null
+type nwsValue struct {
+	Unit  string   `json:"unitCode"`
+	Value *float64 `json:"value"`
+}
+
+type nwsObservation struct {
+	Properties struct {
+		StationID          string    `json:"stationId"`
+		StationName        string    `json:"stationName"`
+		Timestamp          time.Time `json:"timestamp"`
+		Temperature        nwsValue  `json:"temperature"`
+		Dewpoint           nwsValue  `json:"dewpoint"`
+		RelativeHumidity   nwsValue  `json:"relativeHumidity"`
+		WindDirection      nwsValue  `json:"windDirection"`
+		WindSpeed          nwsValue  `json:"windSpeed"`
+		WindGust           nwsValue  `json:"windGust"`
+		BarometricPressure nwsValue  `json:"barometricPressure"`
+		SeaLevelPressure   nwsValue  `json:"seaLevelPressure"`
+		Visibility         nwsValue  `json:"visibility"`
+		PrecipitationHour  nwsValue  `json:"precipitationLastHour"`
+		RawMessage         string    `json:"rawMessage"`
+	} `json:"properties"`
+}
+
+func (w *Weather) gatherNWS(acc telegraf.Accumulator, station string) error {
+	var observation nwsObservation
+	address := w.URL + "/stations/" + url.PathEscape(station) + "/observations/latest"
+	if err := w.get(address, &observation); err != nil {
+		return err
+	}
+	p := &observation.Properties
+
+	fields := make(map[string]interface{})
+	for name, v := range map[string]nwsValue{
+		"temperature":        p.Temperature,
+		"dewpoint":           p.Dewpoint,
+		"humidity":           p.RelativeHumidity,
+		"wind_direction":     p.WindDirection,
+		"wind_speed":         p.WindSpeed,
+		"wind_gust":          p.WindGust,
+		"pressure":           p.BarometricPressure,
+		"sea_level_pressure": p.SeaLevelPressure,
+		"visibility":         p.Visibility,
+		"precipitation":      p.PrecipitationHour,
+	} {
+		if v.Value == nil {
+			continue
+		}
+		value, ok := convertNWSValue(v)
+		if !ok {
+			w.Log.Debugf("Unknown unit %q for %q of station %q", v.Unit, name, station)
+			continue
+		}
+		if name == "wind_direction" {
+			fields[name] = int64(value)
+		} else {
+			fields[name] = value
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	if p.RawMessage != "" {
+		fields["raw"] = p.RawMessage
+	}
+
+	tags := map[string]string{
+		"provider": "nws",
+		"station":  station,
+	}
+	if p.StationName != "" {
+		tags["station_name"] = p.StationName
+	}
+	acc.AddFields("weather", fields, tags, p.Timestamp)
+
+	return nil
+}
+
+// convertNWSValue converts the value to the units used by the plugin
+func convertNWSValue(v nwsValue) (float64, bool) {
+	value := *v.Value
+	switch strings.TrimPrefix(v.Unit, "wmoUnit:") {
+	case "degC", "percent", "degree_(angle)", "m", "mm", "m_s-1":
+		return value, true
+	case "km_h-1":
+		return value / 3.6, true
+	case "Pa":
+		return value / 100, true
+	}
+	return 0, false
+}
@@ -0,0 +1,79 @@
+package weather
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Variables of the current conditions queried from open-meteo and the
+// corresponding field names
+var openMeteoVariables = map[string]string{
+	"temperature_2m":       "temperature",
+	"dew_point_2m":         "dewpoint",
+	"relative_humidity_2m": "humidity",
+	"wind_direction_10m":   "wind_direction",
+	"wind_speed_10m":       "wind_speed",
+	"wind_gusts_10m":       "wind_gust",
+	"surface_pressure":     "pressure",
+	"pressure_msl":         "sea_level_pressure",
+	"precipitation":        "precipitation",
+	"cloud_cover":          "cloud_cover",
+}
+
+type openMeteoResponse struct {
+	Current map[string]*float64 `json:"current"`
+}
+
+func (w *Weather) gatherOpenMeteo(acc telegraf.Accumulator, loc location) error {
+	variables := make([]string, 0, len(openMeteoVariables))
+	for v := range openMeteoVariables {
+		variables = append(variables, v)
+	}
+	sort.Strings(variables)
+	query := url.Values{
+		"latitude":        {strconv.FormatFloat(loc.Latitude, 'f', -1, 64)},
+		"longitude":       {strconv.FormatFloat(loc.Longitude, 'f', -1, 64)},
+		"current":         {strings.Join(variables, ",")},
+		"wind_speed_unit": {"ms"},
+		"timeformat":      {"unixtime"},
+	}
+
+	var response openMeteoResponse
+	if err := w.get(w.URL+"?"+query.Encode(), &response); err != nil {
+		return err
+	}
+
+	ts, found := response.Current["time"]
+	if !found || ts == nil {
+		return nil
+	}
+
+	fields := make(map[string]interface{}, len(openMeteoVariables))
+	for variable, name := range openMeteoVariables {
+		v := response.Current[variable]
+		if v == nil {
+			continue
+		}
+		if name == "wind_direction" {
+			fields[name] = int64(*v)
+		} else {
+			fields[name] = *v
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	tags := map[string]string{
+		"provider": "open-meteo",
+		"location": loc.Name,
+	}
+	acc.AddFields("weather", fields, tags, time.Unix(int64(*ts), 0))
+
+	return nil
+}
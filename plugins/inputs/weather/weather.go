@@ -0,0 +1,175 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package weather
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Default API endpoints of the providers
+var defaultURLs = map[string]string{
+	"metar":      "https://aviationweather.gov/api/data/metar",
+	"nws":        "https://api.weather.gov",
+	"open-meteo": "https://api.open-meteo.com/v1/forecast",
+}
+
+type Weather struct {
+	Provider  string          `toml:"provider"`
+	URL       string          `toml:"url"`
+	Stations  []string        `toml:"stations"`
+	Locations []location      `toml:"location"`
+	UserAgent string          `toml:"user_agent"`
+	Log       telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client *http.Client
+}
+
+type location struct {
+	Name      string  `toml:"name"`
+	Latitude  float64 `toml:"latitude"`
+	Longitude float64 `toml:"longitude"`
+}
+
+func (*Weather) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *Weather) Init() error {
+	switch w.Provider {
+	case "":
+		return errors.New("'provider' required")
+	case "metar", "nws":
+		if len(w.Stations) == 0 {
+			return fmt.Errorf("'stations' required for provider %q", w.Provider)
+		}
+		for i, station := range w.Stations {
+			station = strings.ToUpper(strings.TrimSpace(station))
+			if station == "" {
+				return errors.New("empty station identifier")
+			}
+			w.Stations[i] = station
+		}
+	case "open-meteo":
+		if len(w.Locations) == 0 {
+			return errors.New("'location' required for provider \"open-meteo\"")
+		}
+		for _, loc := range w.Locations {
+			if loc.Name == "" {
+				return errors.New("location without name")
+			}
+			if loc.Latitude < -90 || loc.Latitude > 90 || loc.Longitude < -180 || loc.Longitude > 180 {
+				return fmt.Errorf("invalid coordinates for location %q", loc.Name)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown provider %q", w.Provider)
+	}
+
+	if w.URL == "" {
+		w.URL = defaultURLs[w.Provider]
+	}
+	w.URL = strings.TrimRight(w.URL, "/")
+
+	if w.UserAgent == "" {
+		w.UserAgent = internal.ProductToken()
+	}
+
+	client, err := w.HTTPClientConfig.CreateClient(context.Background(), w.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	w.client = client
+
+	return nil
+}
+
+func (w *Weather) Gather(acc telegraf.Accumulator) error {
+	switch w.Provider {
+	case "metar":
+		// All stations are queried with a single request
+		if err := w.gatherMETAR(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering METAR reports failed: %w", err))
+		}
+	case "nws":
+		var wg sync.WaitGroup
+		for _, station := range w.Stations {
+			wg.Add(1)
+			go func(station string) {
+				defer wg.Done()
+				if err := w.gatherNWS(acc, station); err != nil {
+					acc.AddError(fmt.Errorf("gathering station %q failed: %w", station, err))
+				}
+			}(station)
+		}
+		wg.Wait()
+	case "open-meteo":
+		var wg sync.WaitGroup
+		for _, loc := range w.Locations {
+			wg.Add(1)
+			go func(loc location) {
+				defer wg.Done()
+				if err := w.gatherOpenMeteo(acc, loc); err != nil {
+					acc.AddError(fmt.Errorf("gathering location %q failed: %w", loc.Name, err))
+				}
+			}(loc)
+		}
+		wg.Wait()
+	}
+
+	return nil
+}
+
+func (w *Weather) Stop() {
+	if w.client != nil {
+		w.client.CloseIdleConnections()
+	}
+}
+
+// get queries the given URL and decodes the JSON response into the given value
+func (w *Weather) get(address string, v interface{}) error {
+	request, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return fmt.Errorf("creating request failed: %w", err)
+	}
+	request.Header.Set("Accept", "application/json, application/geo+json")
+	request.Header.Set("User-Agent", w.UserAgent)
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("request returned %q: %s", response.Status, string(body))
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response failed: %w", err)
+	}
+
+	return nil
+}
+
+func init() {
+	inputs.Add("weather", func() telegraf.Input {
+		return &Weather{}
+	})
+}
@@ -0,0 +1,253 @@
+package weather
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Weather
+		expected string
+	}{
+		{
+			name:     "no provider",
+			plugin:   &Weather{},
+			expected: "'provider' required",
+		},
+		{
+			name:     "unknown provider",
+			plugin:   &Weather{Provider: "foo"},
+			expected: `unknown provider "foo"`,
+		},
+		{
+			name:     "no stations",
+			plugin:   &Weather{Provider: "metar"},
+			expected: `'stations' required for provider "metar"`,
+		},
+		{
+			name:     "empty station",
+			plugin:   &Weather{Provider: "nws", Stations: []string{" "}},
+			expected: "empty station identifier",
+		},
+		{
+			name:     "no locations",
+			plugin:   &Weather{Provider: "open-meteo"},
+			expected: "'location' required",
+		},
+		{
+			name: "invalid coordinates",
+			plugin: &Weather{
+				Provider:  "open-meteo",
+				Locations: []location{{Name: "nowhere", Latitude: 91}},
+			},
+			expected: `invalid coordinates for location "nowhere"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestMETAR(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "metar.json"))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ids") != "KJFK,EDDF,KLAX" || r.URL.Query().Get("format") != "json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Weather{
+		Provider: "metar",
+		URL:      server.URL,
+		Stations: []string{"kjfk", "EDDF", "KLAX"},
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"weather",
+			map[string]string{
+				"provider":     "metar",
+				"station":      "KJFK",
+				"station_name": "New York/JF Kennedy Intl, NY, US",
+			},
+			map[string]interface{}{
+				"temperature":        22.2,
+				"dewpoint":           12.8,
+				"humidity":           55.2,
+				"wind_direction":     int64(200),
+				"wind_speed":         5.14444,
+				"wind_gust":          9.259992,
+				"visibility":         16093.44,
+				"altimeter":          1016.9,
+				"sea_level_pressure": 1016.8,
+				"raw":                "KJFK 101251Z 20010G18KT 10SM FEW050 22/13 A3003 RMK AO2 SLP168 T02220128",
+			},
+			time.Unix(1718023860, 0),
+		),
+		metric.New(
+			"weather",
+			map[string]string{
+				"provider":     "metar",
+				"station":      "EDDF",
+				"station_name": "Frankfurt/Main Intl, HE, DE",
+			},
+			map[string]interface{}{
+				"temperature":   15.0,
+				"dewpoint":      9.0,
+				"humidity":      67.4,
+				"wind_speed":    1.028888,
+				"visibility":    9994.02624,
+				"altimeter":     1012.0,
+				"precipitation": 0.508,
+				"raw":           "EDDF 101300Z VRB02KT 9999 -RA SCT030 15/09 Q1012 NOSIG",
+			},
+			time.Unix(1718024400, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), cmpopts.EquateApprox(0, 1e-9))
+}
+
+func TestNWS(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "nws.json"))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "telegraf-test (ops@example.com)" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/stations/KSEA/observations/latest" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/geo+json")
+		if _, err := w.Write(buf); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Weather{
+		Provider:  "nws",
+		URL:       server.URL,
+		Stations:  []string{"KSEA", "KXXX"},
+		UserAgent: "telegraf-test (ops@example.com)",
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `gathering station "KXXX" failed`)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"weather",
+			map[string]string{
+				"provider":     "nws",
+				"station":      "KSEA",
+				"station_name": "Seattle, Seattle-Tacoma International Airport",
+			},
+			map[string]interface{}{
+				"temperature":        13.3,
+				"dewpoint":           8.3,
+				"humidity":           71.5,
+				"wind_direction":     int64(170),
+				"wind_speed":         4.0,
+				"pressure":           1019.9,
+				"sea_level_pressure": 1019.6,
+				"visibility":         16090.0,
+				"raw":                "KSEA 101253Z 17008KT 10SM BKN045 13/08 A3012",
+			},
+			time.Date(2024, 6, 10, 12, 53, 0, 0, time.UTC),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), cmpopts.EquateApprox(0, 1e-9))
+}
+
+func TestOpenMeteo(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "open_meteo.json"))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("latitude") != "52.52" || query.Get("longitude") != "13.41" || query.Get("wind_speed_unit") != "ms" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Weather{
+		Provider:  "open-meteo",
+		URL:       server.URL,
+		Locations: []location{{Name: "berlin", Latitude: 52.52, Longitude: 13.41}},
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"weather",
+			map[string]string{
+				"provider": "open-meteo",
+				"location": "berlin",
+			},
+			map[string]interface{}{
+				"temperature":        16.1,
+				"dewpoint":           10.4,
+				"humidity":           68.0,
+				"wind_direction":     int64(245),
+				"wind_speed":         3.9,
+				"wind_gust":          8.2,
+				"pressure":           1008.6,
+				"sea_level_pressure": 1013.2,
+				"precipitation":      0.1,
+				"cloud_cover":        75.0,
+			},
+			time.Unix(1718024400, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
@@ -0,0 +1,226 @@
+package webhook_generic
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // Required by services signing with HMAC-SHA1
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+type endpoint struct {
+	Path         string            `toml:"path"`
+	Methods      []string          `toml:"methods"`
+	SuccessCode  int               `toml:"success_code"`
+	Measurement  string            `toml:"measurement"`
+	Auth         string            `toml:"auth"`
+	Username     config.Secret     `toml:"username"`
+	Password     config.Secret     `toml:"password"`
+	Token        config.Secret     `toml:"token"`
+	TokenHeader  string            `toml:"token_header"`
+	HMACSecret   config.Secret     `toml:"hmac_secret"`
+	HMACHeader   string            `toml:"hmac_header"`
+	HMACHash     string            `toml:"hmac_hash"`
+	HMACPrefix   string            `toml:"hmac_prefix"`
+	HeaderTags   map[string]string `toml:"header_tags"`
+	HeaderFields map[string]string `toml:"header_fields"`
+	QueryTags    map[string]string `toml:"query_tags"`
+	Parser       parserConfig      `toml:"parser"`
+
+	parser  telegraf.Parser
+	newHash func() hash.Hash
+	once    sync.Once
+}
+
+// parserConfig creates the parser for an endpoint from the 'parser' table
+// using the 'data_format' setting to select the parser
+type parserConfig struct {
+	dataFormat string
+	parser     telegraf.Parser
+}
+
+// UnmarshalTOML implements the toml.UnmarshalerRec interface to decode the
+// parser table into the parser selected by the data format
+func (p *parserConfig) UnmarshalTOML(decode func(interface{}) error) error {
+	var raw map[string]interface{}
+	if err := decode(&raw); err != nil {
+		return err
+	}
+	dataFormat, ok := raw["data_format"].(string)
+	if !ok || dataFormat == "" {
+		return errors.New("missing 'data_format' for parser")
+	}
+
+	creator, found := parsers.Parsers[dataFormat]
+	if !found {
+		return fmt.Errorf("undefined but requested parser: %s", dataFormat)
+	}
+	parser := creator("webhook_generic")
+	if err := decode(parser); err != nil {
+		return fmt.Errorf("decoding %q parser settings failed: %w", dataFormat, err)
+	}
+	p.dataFormat = dataFormat
+	p.parser = parser
+
+	return nil
+}
+
+func (e *endpoint) init(log telegraf.Logger) error {
+	if e.Path == "" || !strings.HasPrefix(e.Path, "/") {
+		return fmt.Errorf("invalid path %q", e.Path)
+	}
+	if len(e.Methods) == 0 {
+		e.Methods = []string{http.MethodPost}
+	}
+	if e.SuccessCode == 0 {
+		e.SuccessCode = http.StatusNoContent
+	}
+
+	switch e.Auth {
+	case "", "none":
+	case "basic":
+		if e.Username.Empty() || e.Password.Empty() {
+			return errors.New("basic authentication requires 'username' and 'password'")
+		}
+	case "token":
+		if e.Token.Empty() {
+			return errors.New("token authentication requires 'token'")
+		}
+		if e.TokenHeader == "" {
+			e.TokenHeader = "Authorization"
+		}
+	case "hmac":
+		if e.HMACSecret.Empty() || e.HMACHeader == "" {
+			return errors.New("HMAC authentication requires 'hmac_secret' and 'hmac_header'")
+		}
+		switch e.HMACHash {
+		case "sha1":
+			e.newHash = sha1.New
+		case "", "sha256":
+			e.newHash = sha256.New
+		case "sha512":
+			e.newHash = sha512.New
+		default:
+			return fmt.Errorf("invalid 'hmac_hash' %q", e.HMACHash)
+		}
+	default:
+		return fmt.Errorf("invalid 'auth' method %q", e.Auth)
+	}
+
+	// Setup the parser
+	if e.Parser.parser == nil {
+		return errors.New("missing 'parser' settings")
+	}
+	e.parser = e.Parser.parser
+	models.SetLoggerOnPlugin(e.parser, log)
+	if p, ok := e.parser.(telegraf.Initializer); ok {
+		if err := p.Init(); err != nil {
+			return fmt.Errorf("initializing %q parser failed: %w", e.Parser.dataFormat, err)
+		}
+	}
+
+	return nil
+}
+
+func (e *endpoint) acceptsMethod(method string) bool {
+	return slices.Contains(e.Methods, method)
+}
+
+// authenticate checks the request according to the configured method
+func (e *endpoint) authenticate(req *http.Request, body []byte) error {
+	switch e.Auth {
+	case "basic":
+		username, password, ok := req.BasicAuth()
+		if !ok {
+			return errors.New("missing basic authentication")
+		}
+		userOK, err := e.Username.EqualTo([]byte(username))
+		if err != nil {
+			return err
+		}
+		passOK, err := e.Password.EqualTo([]byte(password))
+		if err != nil {
+			return err
+		}
+		if !userOK || !passOK {
+			return errors.New("invalid credentials")
+		}
+	case "token":
+		value := req.Header.Get(e.TokenHeader)
+		if strings.EqualFold(e.TokenHeader, "Authorization") {
+			var found bool
+			if value, found = strings.CutPrefix(value, "Bearer "); !found {
+				return errors.New("missing bearer token")
+			}
+		}
+		ok, err := e.Token.EqualTo([]byte(value))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("invalid token")
+		}
+	case "hmac":
+		signature, found := strings.CutPrefix(req.Header.Get(e.HMACHeader), e.HMACPrefix)
+		if !found || signature == "" {
+			return errors.New("missing signature")
+		}
+		expected, err := e.sign(body)
+		if err != nil {
+			return err
+		}
+		if subtle.ConstantTimeCompare([]byte(strings.ToLower(signature)), []byte(expected)) != 1 {
+			return errors.New("invalid signature")
+		}
+	}
+	return nil
+}
+
+// sign computes the hex-encoded HMAC of the body
+func (e *endpoint) sign(body []byte) (string, error) {
+	secret, err := e.HMACSecret.Get()
+	if err != nil {
+		return "", fmt.Errorf("getting secret failed: %w", err)
+	}
+	defer secret.Destroy()
+
+	mac := hmac.New(e.newHash, secret.Bytes())
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// extract adds the tags and fields extracted from the request to the metric
+func (e *endpoint) extract(m telegraf.Metric, req *http.Request) {
+	if e.Measurement != "" {
+		m.SetName(e.Measurement)
+	}
+	for header, tag := range e.HeaderTags {
+		if value := req.Header.Get(header); value != "" {
+			m.AddTag(tag, value)
+		}
+	}
+	for header, field := range e.HeaderFields {
+		if value := req.Header.Get(header); value != "" {
+			m.AddField(field, value)
+		}
+	}
+	query := req.URL.Query()
+	for param, tag := range e.QueryTags {
+		if value := query.Get(param); value != "" {
+			m.AddTag(tag, value)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package webhook_generic
+
+import (
+	"crypto/tls"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type WebhookGeneric struct {
+	ServiceAddress string          `toml:"service_address"`
+	ReadTimeout    config.Duration `toml:"read_timeout"`
+	WriteTimeout   config.Duration `toml:"write_timeout"`
+	MaxBodySize    config.Size     `toml:"max_body_size"`
+	Endpoints      []*endpoint     `toml:"endpoint"`
+	Log            telegraf.Logger `toml:"-"`
+	common_tls.ServerConfig
+
+	tlsConf  *tls.Config
+	handlers map[string]*endpoint
+	listener net.Listener
+	server   *http.Server
+	acc      telegraf.Accumulator
+	wg       sync.WaitGroup
+}
+
+func (*WebhookGeneric) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WebhookGeneric) Init() error {
+	if w.ServiceAddress == "" {
+		w.ServiceAddress = ":8080"
+	}
+	if len(w.Endpoints) == 0 {
+		return errors.New("no endpoint configured")
+	}
+
+	tlsConf, err := w.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	w.tlsConf = tlsConf
+
+	w.handlers = make(map[string]*endpoint, len(w.Endpoints))
+	for i, ep := range w.Endpoints {
+		if err := ep.init(w.Log); err != nil {
+			return fmt.Errorf("endpoint %d: %w", i+1, err)
+		}
+		if _, found := w.handlers[ep.Path]; found {
+			return fmt.Errorf("duplicate endpoint for path %q", ep.Path)
+		}
+		w.handlers[ep.Path] = ep
+	}
+
+	return nil
+}
+
+func (w *WebhookGeneric) Start(acc telegraf.Accumulator) error {
+	w.acc = acc
+
+	var err error
+	if w.tlsConf != nil {
+		w.listener, err = tls.Listen("tcp", w.ServiceAddress, w.tlsConf)
+	} else {
+		w.listener, err = net.Listen("tcp", w.ServiceAddress)
+	}
+	if err != nil {
+		return err
+	}
+
+	w.server = &http.Server{
+		Handler:      w,
+		ReadTimeout:  time.Duration(w.ReadTimeout),
+		WriteTimeout: time.Duration(w.WriteTimeout),
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		if err := w.server.Serve(w.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			w.Log.Errorf("Serve failed: %v", err)
+		}
+	}()
+	w.Log.Infof("Listening on %s", w.listener.Addr().String())
+
+	return nil
+}
+
+func (*WebhookGeneric) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (w *WebhookGeneric) Stop() {
+	if w.server != nil {
+		if err := w.server.Close(); err != nil {
+			w.Log.Errorf("Closing server failed: %v", err)
+		}
+	}
+	w.wg.Wait()
+}
+
+// ServeHTTP implements [http.Handler]
+func (w *WebhookGeneric) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	ep, found := w.handlers[req.URL.Path]
+	if !found {
+		http.NotFound(res, req)
+		return
+	}
+
+	if !ep.acceptsMethod(req.Method) {
+		http.Error(res, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Read the body first as some authentication methods sign the payload
+	if req.ContentLength > int64(w.MaxBodySize) {
+		http.Error(res, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(res, req.Body, int64(w.MaxBodySize)))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(res, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if err := ep.authenticate(req, body); err != nil {
+		w.Log.Debugf("Rejecting request from %s to %q: %v", req.RemoteAddr, ep.Path, err)
+		http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	metrics, err := ep.parser.Parse(body)
+	if err != nil {
+		w.Log.Debugf("Parsing request to %q failed: %v", ep.Path, err)
+		http.Error(res, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if len(metrics) == 0 {
+		ep.once.Do(func() {
+			w.Log.Debugf("Path %q: %s", ep.Path, internal.NoMetricsCreatedMsg)
+		})
+	}
+
+	for _, m := range metrics {
+		ep.extract(m, req)
+		w.acc.AddMetric(m)
+	}
+	res.WriteHeader(ep.SuccessCode)
+}
+
+func init() {
+	inputs.Add("webhook_generic", func() telegraf.Input {
+		return &WebhookGeneric{
+			ServiceAddress: ":8080",
+			ReadTimeout:    config.Duration(10 * time.Second),
+			WriteTimeout:   config.Duration(10 * time.Second),
+			MaxBodySize:    config.Size(10 * 1024 * 1024),
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package webhook_generic
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	_ "github.com/influxdata/telegraf/plugins/parsers/all"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestSampleConfig(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(sampleConfig), config.EmptySourcePath))
+	require.Len(t, cfg.Inputs, 1)
+
+	plugin := cfg.Inputs[0].Input.(*WebhookGeneric)
+	require.Len(t, plugin.Endpoints, 1)
+	require.Equal(t, "json_v2", plugin.Endpoints[0].Parser.dataFormat)
+}
+
+func TestUnknownParserOption(t *testing.T) {
+	conf := `
+[[inputs.webhook_generic]]
+  [[inputs.webhook_generic.endpoint]]
+    path = "/test"
+    [inputs.webhook_generic.endpoint.parser]
+      data_format = "json"
+      json_unknown_option = true
+`
+	cfg := config.NewConfig()
+	require.ErrorContains(t, cfg.LoadConfigData([]byte(conf), config.EmptySourcePath), "json_unknown_option")
+}
+
+func TestInvalidSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint *endpoint
+		expected string
+	}{
+		{
+			name:     "invalid path",
+			endpoint: &endpoint{Path: "test"},
+			expected: `invalid path "test"`,
+		},
+		{
+			name:     "missing parser",
+			endpoint: &endpoint{Path: "/test"},
+			expected: "missing 'parser' settings",
+		},
+		{
+			name:     "invalid auth",
+			endpoint: &endpoint{Path: "/test", Auth: "oauth"},
+			expected: `invalid 'auth' method "oauth"`,
+		},
+		{
+			name:     "incomplete basic auth",
+			endpoint: &endpoint{Path: "/test", Auth: "basic"},
+			expected: "basic authentication requires 'username' and 'password'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &WebhookGeneric{
+				Endpoints: []*endpoint{tt.endpoint},
+				Log:       testutil.Logger{},
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	conf := `
+[[inputs.webhook_generic]]
+  service_address = "127.0.0.1:0"
+
+  [[inputs.webhook_generic.endpoint]]
+    path = "/github"
+    auth = "hmac"
+    hmac_secret = "secret"
+    hmac_header = "X-Hub-Signature-256"
+    hmac_prefix = "sha256="
+    header_tags = {"X-GitHub-Event" = "event"}
+    [inputs.webhook_generic.endpoint.parser]
+      data_format = "json_v2"
+      [[inputs.webhook_generic.endpoint.parser.json_v2]]
+        measurement_name = "github"
+        [[inputs.webhook_generic.endpoint.parser.json_v2.tag]]
+          path = "repository"
+        [[inputs.webhook_generic.endpoint.parser.json_v2.field]]
+          path = "stars"
+          type = "int"
+
+  [[inputs.webhook_generic.endpoint]]
+    path = "/influx"
+    auth = "token"
+    token = "mytoken"
+    measurement = "custom"
+    query_tags = {"source" = "source"}
+    [inputs.webhook_generic.endpoint.parser]
+      data_format = "influx"
+`
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(conf), config.EmptySourcePath))
+	require.Len(t, cfg.Inputs, 1)
+	plugin := cfg.Inputs[0].Input.(*WebhookGeneric)
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	addr := "http://" + plugin.listener.Addr().String()
+
+	// Send a signed JSON payload
+	payload := []byte(`{"repository": "influxdata/telegraf", "stars": 42}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(payload)
+	req, err := http.NewRequest(http.MethodPost, addr+"/github", bytes.NewReader(payload))
+	require.NoError(t, err)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("X-GitHub-Event", "star")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// Send an invalid signature
+	req, err = http.NewRequest(http.MethodPost, addr+"/github", bytes.NewReader(payload))
+	require.NoError(t, err)
+	req.Header.Set("X-Hub-Signature-256", "sha256=0123")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// Send line protocol with a bearer token
+	req, err = http.NewRequest(http.MethodPost, addr+"/influx?source=test", bytes.NewBufferString("cpu value=1 1700000000000000000\n"))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer mytoken")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// Wrong method and unknown path
+	resp, err = http.Get(addr + "/influx")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	resp, err = http.Post(addr+"/unknown", "text/plain", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"github",
+			map[string]string{"repository": "influxdata/telegraf", "event": "star"},
+			map[string]interface{}{"stars": int64(42)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"custom",
+			map[string]string{"source": "test"},
+			map[string]interface{}{"value": float64(1)},
+			time.Unix(0, 1700000000000000000),
+		),
+	}
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= uint64(len(expected))
+	}, 3*time.Second, 100*time.Millisecond)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
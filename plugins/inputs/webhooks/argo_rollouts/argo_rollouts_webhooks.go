@@ -0,0 +1,124 @@
+package argo_rollouts
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/auth"
+)
+
+// Status of the deployment for the notification triggers of Argo Rollouts
+var triggerStatus = map[string]string{
+	"on-rollout-updated":        "started",
+	"on-rollout-step-completed": "progressing",
+	"on-rollout-paused":         "paused",
+	"on-rollout-completed":      "succeeded",
+	"on-rollout-aborted":        "aborted",
+	"on-analysis-run-failed":    "failed",
+	"on-analysis-run-error":     "failed",
+}
+
+// event is the payload sent by the webhook notification template described
+// in the README
+type event struct {
+	Trigger     string `json:"trigger"`
+	Rollout     string `json:"rollout"`
+	Namespace   string `json:"namespace"`
+	Environment string `json:"environment"`
+	Revision    string `json:"revision"`
+	Image       string `json:"image"`
+	Phase       string `json:"phase"`
+	Message     string `json:"message"`
+	StartedAt   string `json:"started_at"`
+	FinishedAt  string `json:"finished_at"`
+}
+
+type Webhook struct {
+	Path string
+	acc  telegraf.Accumulator
+	log  telegraf.Logger
+	auth.BasicAuth
+}
+
+// Register registers the webhook with the provided router
+func (ar *Webhook) Register(router *mux.Router, acc telegraf.Accumulator, log telegraf.Logger) {
+	router.HandleFunc(ar.Path, ar.eventHandler).Methods("POST")
+	ar.log = log
+	ar.log.Infof("Started the webhooks_argo_rollouts on %s", ar.Path)
+	ar.acc = acc
+}
+
+func (ar *Webhook) eventHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if !ar.Verify(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var e event
+	if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	if e.Rollout == "" {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	status, found := triggerStatus[e.Trigger]
+	if !found {
+		status = strings.ToLower(e.Phase)
+	}
+
+	tags := map[string]string{
+		"source":  "argo_rollouts",
+		"service": e.Rollout,
+		"status":  status,
+	}
+	if e.Namespace != "" {
+		tags["namespace"] = e.Namespace
+	}
+	if e.Environment != "" {
+		tags["environment"] = e.Environment
+	}
+
+	fields := map[string]interface{}{
+		"version": imageTag(e.Image),
+	}
+	if e.Revision != "" {
+		fields["revision"] = e.Revision
+	}
+	if e.Message != "" {
+		fields["message"] = e.Message
+	}
+
+	ts := time.Now()
+	if t, err := time.Parse(time.RFC3339, e.FinishedAt); err == nil {
+		ts = t
+		if start, err := time.Parse(time.RFC3339, e.StartedAt); err == nil {
+			fields["duration"] = t.Sub(start).Seconds()
+		}
+	}
+
+	ar.acc.AddFields("deployment", fields, tags, ts)
+	w.WriteHeader(http.StatusOK)
+}
+
+// imageTag returns the tag or digest of the given container image reference
+// or the full reference if neither is given
+func imageTag(image string) string {
+	if _, digest, found := strings.Cut(image, "@"); found {
+		return digest
+	}
+	// The registry might contain a port so only consider the last element
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		return image[idx+1:]
+	}
+	return image
+}
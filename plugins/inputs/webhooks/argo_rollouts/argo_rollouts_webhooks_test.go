@@ -0,0 +1,107 @@
+package argo_rollouts
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func postWebhooks(t *testing.T, ar *Webhook, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("POST", "/argo_rollouts", strings.NewReader(body))
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+
+	ar.eventHandler(w, req)
+
+	return w
+}
+
+func TestEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		expected telegraf.Metric
+	}{
+		{
+			name: "completed",
+			file: "testdata/completed.json",
+			expected: metric.New(
+				"deployment",
+				map[string]string{
+					"source":      "argo_rollouts",
+					"service":     "checkout",
+					"namespace":   "shop",
+					"environment": "production",
+					"status":      "succeeded",
+				},
+				map[string]interface{}{
+					"version":  "1.4.2",
+					"revision": "7",
+					"message":  "Rollout completed update to revision 7",
+					"duration": 750.0,
+				},
+				time.Date(2024, 6, 10, 8, 12, 30, 0, time.UTC),
+			),
+		},
+		{
+			name: "updated",
+			file: "testdata/updated.json",
+			expected: metric.New(
+				"deployment",
+				map[string]string{
+					"source":    "argo_rollouts",
+					"service":   "checkout",
+					"namespace": "shop",
+					"status":    "started",
+				},
+				map[string]interface{}{
+					"version":  "sha256:0f3e2d",
+					"revision": "8",
+				},
+				time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := os.ReadFile(tt.file)
+			require.NoError(t, err)
+
+			var acc testutil.Accumulator
+			ar := &Webhook{Path: "/argo_rollouts", acc: &acc}
+			resp := postWebhooks(t, ar, string(body))
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestInvalidEvent(t *testing.T) {
+	var acc testutil.Accumulator
+	ar := &Webhook{Path: "/argo_rollouts", acc: &acc}
+
+	resp := postWebhooks(t, ar, "not json")
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = postWebhooks(t, ar, `{"trigger": "on-rollout-completed"}`)
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestImageTag(t *testing.T) {
+	require.Equal(t, "1.2.3", imageTag("nginx:1.2.3"))
+	require.Equal(t, "latest", imageTag("localhost:5000/app:latest"))
+	require.Equal(t, "localhost:5000/app", imageTag("localhost:5000/app"))
+	require.Equal(t, "sha256:abc", imageTag("app@sha256:abc"))
+}
@@ -0,0 +1,164 @@
+package octopus
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/auth"
+)
+
+// Status of the deployment for the event categories of Octopus Deploy
+var categoryStatus = map[string]string{
+	"DeploymentQueued":    "queued",
+	"DeploymentStarted":   "started",
+	"DeploymentSucceeded": "succeeded",
+	"DeploymentFailed":    "failed",
+	"DeploymentCanceled":  "canceled",
+}
+
+// Maximum time to remember the start of a deployment for computing the
+// duration when receiving its completion
+const maxDeploymentDuration = 24 * time.Hour
+
+type Webhook struct {
+	Path string
+	acc  telegraf.Accumulator
+	log  telegraf.Logger
+	auth.BasicAuth
+
+	// Start times of running deployments by deployment ID
+	started map[string]time.Time
+	sync.Mutex
+}
+
+// Register registers the webhook with the provided router
+func (oc *Webhook) Register(router *mux.Router, acc telegraf.Accumulator, log telegraf.Logger) {
+	router.HandleFunc(oc.Path, oc.eventHandler).Methods("POST")
+	oc.log = log
+	oc.log.Infof("Started the webhooks_octopus on %s", oc.Path)
+	oc.acc = acc
+}
+
+func (oc *Webhook) eventHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if !oc.Verify(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var e event
+	if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	// Only deployment events are of interest, acknowledge all other events
+	// without recording them
+	details := e.Payload.Event
+	status, found := categoryStatus[details.Category]
+	if !found {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	refs := details.references()
+	tags := map[string]string{
+		"source":  "octopus",
+		"service": refs["Projects"],
+		"status":  status,
+	}
+	if env := refs["Environments"]; env != "" {
+		tags["environment"] = env
+	}
+	if tenant := refs["Tenants"]; tenant != "" {
+		tags["tenant"] = tenant
+	}
+
+	fields := map[string]interface{}{
+		"version": refs["Releases"],
+		"message": details.Message,
+	}
+	if details.Username != "" {
+		fields["user"] = details.Username
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, details.Occurred)
+	if err != nil {
+		ts = time.Now()
+	}
+	if duration, found := oc.duration(details.deploymentID(), status, ts); found {
+		fields["duration"] = duration.Seconds()
+	}
+
+	oc.acc.AddFields("deployment", fields, tags, ts)
+	w.WriteHeader(http.StatusOK)
+}
+
+// duration remembers the start of a deployment and returns the duration of
+// the deployment on completion
+func (oc *Webhook) duration(id, status string, ts time.Time) (time.Duration, bool) {
+	if id == "" {
+		return 0, false
+	}
+
+	oc.Lock()
+	defer oc.Unlock()
+
+	if oc.started == nil {
+		oc.started = make(map[string]time.Time)
+	}
+
+	switch status {
+	case "queued":
+		return 0, false
+	case "started":
+		// Forget deployments we never received a completion event for
+		for k, start := range oc.started {
+			if ts.Sub(start) > maxDeploymentDuration {
+				delete(oc.started, k)
+			}
+		}
+		oc.started[id] = ts
+		return 0, false
+	}
+
+	start, found := oc.started[id]
+	if !found {
+		return 0, false
+	}
+	delete(oc.started, id)
+	return ts.Sub(start), true
+}
+
+// references returns the names of the documents referenced in the message by
+// document type, e.g. the project name for the "Projects" type
+func (e *eventDetails) references() map[string]string {
+	refs := make(map[string]string, len(e.MessageReferences))
+	for _, ref := range e.MessageReferences {
+		end := ref.StartIndex + ref.Length
+		if ref.StartIndex < 0 || end > len(e.Message) {
+			continue
+		}
+		kind, _, _ := strings.Cut(ref.ReferencedDocumentID, "-")
+		if _, found := refs[kind]; !found {
+			refs[kind] = e.Message[ref.StartIndex:end]
+		}
+	}
+	return refs
+}
+
+func (e *eventDetails) deploymentID() string {
+	for _, id := range e.RelatedDocumentIDs {
+		if strings.HasPrefix(id, "Deployments-") {
+			return id
+		}
+	}
+	return ""
+}
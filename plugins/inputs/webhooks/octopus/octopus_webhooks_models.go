@@ -0,0 +1,32 @@
+package octopus
+
+// event is the payload sent by Octopus Deploy subscriptions
+type event struct {
+	Timestamp string  `json:"Timestamp"`
+	EventType string  `json:"EventType"`
+	Payload   payload `json:"Payload"`
+}
+
+type payload struct {
+	ServerURI string       `json:"ServerUri"`
+	Event     eventDetails `json:"Event"`
+}
+
+type eventDetails struct {
+	ID                 string             `json:"Id"`
+	Category           string             `json:"Category"`
+	Occurred           string             `json:"Occurred"`
+	Message            string             `json:"Message"`
+	Username           string             `json:"Username"`
+	SpaceID            string             `json:"SpaceId"`
+	RelatedDocumentIDs []string           `json:"RelatedDocumentIds"`
+	MessageReferences  []messageReference `json:"MessageReferences"`
+}
+
+// messageReference denotes the part of the message naming the referenced
+// document, e.g. the project name for a project document
+type messageReference struct {
+	ReferencedDocumentID string `json:"ReferencedDocumentId"`
+	StartIndex           int    `json:"StartIndex"`
+	Length               int    `json:"Length"`
+}
@@ -0,0 +1,84 @@
+package octopus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func postWebhooks(t *testing.T, oc *Webhook, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("POST", "/octopus", strings.NewReader(body))
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+
+	oc.eventHandler(w, req)
+
+	return w
+}
+
+func TestDeployment(t *testing.T) {
+	var acc testutil.Accumulator
+	oc := &Webhook{Path: "/octopus", acc: &acc}
+
+	for _, fn := range []string{"deployment_started.json", "machine_healthy.json", "deployment_succeeded.json"} {
+		body, err := os.ReadFile("testdata/" + fn)
+		require.NoError(t, err)
+		resp := postWebhooks(t, oc, string(body))
+		require.Equal(t, http.StatusOK, resp.Code)
+	}
+
+	tags := map[string]string{
+		"source":      "octopus",
+		"service":     "Checkout",
+		"environment": "Production",
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"deployment",
+			map[string]string{"status": "started"},
+			map[string]interface{}{
+				"version": "1.4.2",
+				"message": "Deploy to Production started for Checkout release 1.4.2 to Production",
+				"user":    "jane",
+			},
+			time.Date(2024, 6, 10, 8, 0, 0, 0, time.UTC),
+		),
+		metric.New(
+			"deployment",
+			map[string]string{"status": "succeeded"},
+			map[string]interface{}{
+				"version":  "1.4.2",
+				"message":  "Deploy to Production succeeded for Checkout release 1.4.2 to Production",
+				"user":     "jane",
+				"duration": 330.0,
+			},
+			time.Date(2024, 6, 10, 8, 5, 30, 0, time.UTC),
+		),
+	}
+	for _, m := range expected {
+		for k, v := range tags {
+			m.AddTag(k, v)
+		}
+	}
+
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+	require.Empty(t, oc.started)
+}
+
+func TestInvalidEvent(t *testing.T) {
+	var acc testutil.Accumulator
+	oc := &Webhook{Path: "/octopus", acc: &acc}
+
+	resp := postWebhooks(t, oc, "not json")
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
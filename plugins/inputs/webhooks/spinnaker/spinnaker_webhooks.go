@@ -0,0 +1,116 @@
+package spinnaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/auth"
+)
+
+// Status of the deployment for the pipeline event types of Spinnaker
+var eventStatus = map[string]string{
+	"orca:pipeline:starting": "started",
+	"orca:pipeline:complete": "succeeded",
+	"orca:pipeline:failed":   "failed",
+}
+
+type Webhook struct {
+	Path                 string
+	VersionParameter     string `toml:"version_parameter"`
+	EnvironmentParameter string `toml:"environment_parameter"`
+	acc                  telegraf.Accumulator
+	log                  telegraf.Logger
+	auth.BasicAuth
+}
+
+// Register registers the webhook with the provided router
+func (sp *Webhook) Register(router *mux.Router, acc telegraf.Accumulator, log telegraf.Logger) {
+	router.HandleFunc(sp.Path, sp.eventHandler).Methods("POST")
+	sp.log = log
+	sp.log.Infof("Started the webhooks_spinnaker on %s", sp.Path)
+	sp.acc = acc
+
+	if sp.VersionParameter == "" {
+		sp.VersionParameter = "version"
+	}
+	if sp.EnvironmentParameter == "" {
+		sp.EnvironmentParameter = "environment"
+	}
+}
+
+func (sp *Webhook) eventHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if !sp.Verify(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var e event
+	if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	// Only pipeline events denote deployments, acknowledge stage and task
+	// events without recording them
+	status, found := eventStatus[e.Details.Type]
+	if !found {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	exec := e.Content.Execution
+	application := exec.Application
+	if application == "" {
+		application = e.Details.Application
+	}
+
+	tags := map[string]string{
+		"source":   "spinnaker",
+		"service":  application,
+		"pipeline": exec.Name,
+		"status":   status,
+	}
+	if env := parameter(exec.Trigger.Parameters, sp.EnvironmentParameter); env != "" {
+		tags["environment"] = env
+	}
+
+	fields := map[string]interface{}{
+		"execution_id": exec.ID,
+	}
+	if version := parameter(exec.Trigger.Parameters, sp.VersionParameter); version != "" {
+		fields["version"] = version
+	}
+	if exec.Trigger.User != "" {
+		fields["user"] = exec.Trigger.User
+	}
+
+	ts := time.Now()
+	if exec.EndTime > 0 {
+		ts = time.UnixMilli(exec.EndTime)
+		if exec.StartTime > 0 {
+			fields["duration"] = float64(exec.EndTime-exec.StartTime) / 1000.0
+		}
+	} else if exec.StartTime > 0 && status == "started" {
+		ts = time.UnixMilli(exec.StartTime)
+	}
+
+	sp.acc.AddFields("deployment", fields, tags, ts)
+	w.WriteHeader(http.StatusOK)
+}
+
+// parameter returns the value of the given trigger parameter as string
+func parameter(params map[string]interface{}, name string) string {
+	v, found := params[name]
+	if !found || v == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprint(v))
+}
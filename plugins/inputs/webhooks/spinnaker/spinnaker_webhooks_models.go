@@ -0,0 +1,36 @@
+package spinnaker
+
+// event is the payload sent by the Spinnaker echo service to REST endpoints
+type event struct {
+	Details details `json:"details"`
+	Content content `json:"content"`
+}
+
+type details struct {
+	Source      string `json:"source"`
+	Type        string `json:"type"`
+	Created     string `json:"created"`
+	Application string `json:"application"`
+}
+
+type content struct {
+	ExecutionID string    `json:"executionId"`
+	Execution   execution `json:"execution"`
+}
+
+type execution struct {
+	ID          string  `json:"id"`
+	Type        string  `json:"type"`
+	Name        string  `json:"name"`
+	Application string  `json:"application"`
+	Status      string  `json:"status"`
+	StartTime   int64   `json:"startTime"`
+	EndTime     int64   `json:"endTime"`
+	Trigger     trigger `json:"trigger"`
+}
+
+type trigger struct {
+	Type       string                 `json:"type"`
+	User       string                 `json:"user"`
+	Parameters map[string]interface{} `json:"parameters"`
+}
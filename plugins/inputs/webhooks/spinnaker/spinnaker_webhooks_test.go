@@ -0,0 +1,110 @@
+package spinnaker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func postWebhooks(t *testing.T, sp *Webhook, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("POST", "/spinnaker", strings.NewReader(body))
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+
+	sp.eventHandler(w, req)
+
+	return w
+}
+
+func TestEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		version  string
+		expected []telegraf.Metric
+	}{
+		{
+			name: "pipeline complete",
+			file: "testdata/pipeline_complete.json",
+			expected: []telegraf.Metric{
+				metric.New(
+					"deployment",
+					map[string]string{
+						"source":      "spinnaker",
+						"service":     "checkout",
+						"pipeline":    "Deploy to production",
+						"environment": "production",
+						"status":      "succeeded",
+					},
+					map[string]interface{}{
+						"execution_id": "01HZX7Q6K8",
+						"version":      "1.4.2",
+						"user":         "jane@example.com",
+						"duration":     750.0,
+					},
+					time.UnixMilli(1718010750000),
+				),
+			},
+		},
+		{
+			name:    "pipeline starting",
+			file:    "testdata/pipeline_starting.json",
+			version: "release",
+			expected: []telegraf.Metric{
+				metric.New(
+					"deployment",
+					map[string]string{
+						"source":   "spinnaker",
+						"service":  "checkout",
+						"pipeline": "Deploy to production",
+						"status":   "started",
+					},
+					map[string]interface{}{
+						"execution_id": "01HZX7Q6K8",
+						"version":      "1.4.2",
+					},
+					time.UnixMilli(1718010000000),
+				),
+			},
+		},
+		{
+			name: "stage complete",
+			file: "testdata/stage_complete.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := os.ReadFile(tt.file)
+			require.NoError(t, err)
+
+			var acc testutil.Accumulator
+			sp := &Webhook{Path: "/spinnaker", VersionParameter: tt.version}
+			sp.Register(mux.NewRouter(), &acc, testutil.Logger{})
+			resp := postWebhooks(t, sp, string(body))
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestInvalidEvent(t *testing.T) {
+	var acc testutil.Accumulator
+	sp := &Webhook{Path: "/spinnaker"}
+	sp.Register(mux.NewRouter(), &acc, testutil.Logger{})
+
+	resp := postWebhooks(t, sp, "not json")
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
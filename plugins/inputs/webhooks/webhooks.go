@@ -14,13 +14,16 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/argo_rollouts"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/artifactory"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/filestack"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/github"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/mandrill"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/octopus"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/papertrail"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/particle"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/rollbar"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/spinnaker"
 )
 
 //go:embed sample.conf
@@ -36,13 +39,16 @@ type Webhooks struct {
 	ReadTimeout    config.Duration `toml:"read_timeout"`
 	WriteTimeout   config.Duration `toml:"write_timeout"`
 
-	Artifactory *artifactory.Webhook `toml:"artifactory"`
-	Filestack   *filestack.Webhook   `toml:"filestack"`
-	Github      *github.Webhook      `toml:"github"`
-	Mandrill    *mandrill.Webhook    `toml:"mandrill"`
-	Papertrail  *papertrail.Webhook  `toml:"papertrail"`
-	Particle    *particle.Webhook    `toml:"particle"`
-	Rollbar     *rollbar.Webhook     `toml:"rollbar"`
+	ArgoRollouts *argo_rollouts.Webhook `toml:"argo_rollouts"`
+	Artifactory  *artifactory.Webhook   `toml:"artifactory"`
+	Filestack    *filestack.Webhook     `toml:"filestack"`
+	Github       *github.Webhook        `toml:"github"`
+	Mandrill     *mandrill.Webhook      `toml:"mandrill"`
+	Octopus      *octopus.Webhook       `toml:"octopus"`
+	Papertrail   *papertrail.Webhook    `toml:"papertrail"`
+	Particle     *particle.Webhook      `toml:"particle"`
+	Rollbar      *rollbar.Webhook       `toml:"rollbar"`
+	Spinnaker    *spinnaker.Webhook     `toml:"spinnaker"`
 
 	Log telegraf.Logger `toml:"-"`
 
@@ -4,13 +4,16 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/argo_rollouts"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/artifactory"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/filestack"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/github"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/mandrill"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/octopus"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/papertrail"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/particle"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/rollbar"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/spinnaker"
 )
 
 func TestAvailableWebhooks(t *testing.T) {
@@ -20,6 +23,12 @@ func TestAvailableWebhooks(t *testing.T) {
 		t.Errorf("expected to %v.\nGot %v", expected, wb.availableWebhooks())
 	}
 
+	wb.ArgoRollouts = &argo_rollouts.Webhook{Path: "/argo_rollouts"}
+	expected = append(expected, wb.ArgoRollouts)
+	if !reflect.DeepEqual(wb.availableWebhooks(), expected) {
+		t.Errorf("expected to be %v.\nGot %v", expected, wb.availableWebhooks())
+	}
+
 	wb.Artifactory = &artifactory.Webhook{Path: "/artifactory"}
 	expected = append(expected, wb.Artifactory)
 	if !reflect.DeepEqual(wb.availableWebhooks(), expected) {
@@ -44,6 +53,12 @@ func TestAvailableWebhooks(t *testing.T) {
 		t.Errorf("expected to be %v.\nGot %v", expected, wb.availableWebhooks())
 	}
 
+	wb.Octopus = &octopus.Webhook{Path: "/octopus"}
+	expected = append(expected, wb.Octopus)
+	if !reflect.DeepEqual(wb.availableWebhooks(), expected) {
+		t.Errorf("expected to be %v.\nGot %v", expected, wb.availableWebhooks())
+	}
+
 	wb.Papertrail = &papertrail.Webhook{Path: "/papertrail"}
 	expected = append(expected, wb.Papertrail)
 	if !reflect.DeepEqual(wb.availableWebhooks(), expected) {
@@ -61,4 +76,10 @@ func TestAvailableWebhooks(t *testing.T) {
 	if !reflect.DeepEqual(wb.availableWebhooks(), expected) {
 		t.Errorf("expected to be %v.\nGot %v", expected, wb.availableWebhooks())
 	}
+
+	wb.Spinnaker = &spinnaker.Webhook{Path: "/spinnaker"}
+	expected = append(expected, wb.Spinnaker)
+	if !reflect.DeepEqual(wb.availableWebhooks(), expected) {
+		t.Errorf("expected to be %v.\nGot %v", expected, wb.availableWebhooks())
+	}
 }
@@ -246,6 +246,7 @@ const (
 	pdhFmtNocap100     = 0x00008000 // can be OR-ed: do not cap values > 100.
 	perfDetailCostly   = 0x00010000
 	perfDetailStandard = 0x0000FFFF
+	perfDetailWizard   = 400 // Include all counters and instances when enumerating object items.
 )
 
 type (
@@ -270,6 +271,11 @@ var (
 	pdhGetCounterInfoWProc           *syscall.Proc
 	pdhGetRawCounterValueProc        *syscall.Proc
 	pdhGetRawCounterArrayWProc       *syscall.Proc
+	pdhRemoveCounterProc             *syscall.Proc
+	pdhEnumObjectsWProc              *syscall.Proc
+	pdhEnumObjectItemsWProc          *syscall.Proc
+	pdhLookupPerfIndexByNameWProc    *syscall.Proc
+	pdhLookupPerfNameByIndexWProc    *syscall.Proc
 )
 
 func init() {
@@ -289,6 +295,11 @@ func init() {
 	pdhGetCounterInfoWProc = libPdhDll.MustFindProc("PdhGetCounterInfoW")
 	pdhGetRawCounterValueProc = libPdhDll.MustFindProc("PdhGetRawCounterValue")
 	pdhGetRawCounterArrayWProc = libPdhDll.MustFindProc("PdhGetRawCounterArrayW")
+	pdhRemoveCounterProc = libPdhDll.MustFindProc("PdhRemoveCounter")
+	pdhEnumObjectsWProc = libPdhDll.MustFindProc("PdhEnumObjectsW")
+	pdhEnumObjectItemsWProc = libPdhDll.MustFindProc("PdhEnumObjectItemsW")
+	pdhLookupPerfIndexByNameWProc = libPdhDll.MustFindProc("PdhLookupPerfIndexByNameW")
+	pdhLookupPerfNameByIndexWProc = libPdhDll.MustFindProc("PdhLookupPerfNameByIndexW")
 }
 
 // pdhAddCounter adds the specified counter to the query. This is the internationalized version. Preferably, use the
@@ -634,3 +645,130 @@ func pdhGetRawCounterArray(hCounter pdhCounterHandle, lpdwBufferSize, lpdwBuffer
 		uintptr(unsafe.Pointer(itemBuffer)))      //nolint:gosec // G103: Valid use of unsafe call to pass itemBuffer
 	return uint32(ret)
 }
+
+// pdhRemoveCounter removes a counter from a query.
+//
+// hCounter [in]
+// Handle of the counter to remove from its query. The pdhAddCounter function returns this handle.
+func pdhRemoveCounter(hCounter pdhCounterHandle) uint32 {
+	ret, _, _ := pdhRemoveCounterProc.Call(uintptr(hCounter))
+
+	return uint32(ret)
+}
+
+// pdhRefreshObjects refreshes the cached list of performance objects of the specified computer. PDH caches
+// the objects when first enumerating them, so objects registered later (e.g. by newly installed services) are
+// only visible to pdhEnumObjectItems after refreshing the list.
+//
+// szMachineName [in]
+// Name of the computer, or an empty string for the local computer.
+func pdhRefreshObjects(szMachineName string) uint32 {
+	var pmachine *uint16
+	if szMachineName != "" {
+		pmachine, _ = syscall.UTF16PtrFromString(szMachineName)
+	}
+	// Passing an empty buffer only refreshes the list and returns PdhMoreData
+	var size uint32
+	ret, _, _ := pdhEnumObjectsWProc.Call(
+		0,                                 // use the current real-time data source
+		uintptr(unsafe.Pointer(pmachine)), //nolint:gosec // G103: Valid use of unsafe call to pass pmachine
+		0,
+		uintptr(unsafe.Pointer(&size)), //nolint:gosec // G103: Valid use of unsafe call to pass size
+		perfDetailWizard,
+		1) // refresh the list
+
+	return uint32(ret)
+}
+
+// pdhEnumObjectItems returns the counters and instances of the specified object on the specified computer.
+//
+// szMachineName [in]
+// Name of the computer, or an empty string for the local computer.
+//
+// szObjectName [in]
+// Localized name of the object whose counters and instances should be returned.
+//
+// mszCounterList, mszInstanceList [out]
+// Caller-allocated buffers receiving the list of NULL terminated counter and instance names. The lists are
+// terminated by an additional NULL character.
+//
+// pcchCounterListLength, pcchInstanceListLength [in, out]
+// Sizes of the buffers in characters. If the buffers are too small, the function returns PdhMoreData and sets
+// these parameters to the required sizes.
+func pdhEnumObjectItems(
+	szMachineName, szObjectName string,
+	mszCounterList *uint16,
+	pcchCounterListLength *uint32,
+	mszInstanceList *uint16,
+	pcchInstanceListLength *uint32,
+) uint32 {
+	var pmachine *uint16
+	if szMachineName != "" {
+		pmachine, _ = syscall.UTF16PtrFromString(szMachineName)
+	}
+	pobject, _ := syscall.UTF16PtrFromString(szObjectName)
+	ret, _, _ := pdhEnumObjectItemsWProc.Call(
+		0,                                       // use the current real-time data source
+		uintptr(unsafe.Pointer(pmachine)),       //nolint:gosec // G103: Valid use of unsafe call to pass pmachine
+		uintptr(unsafe.Pointer(pobject)),        //nolint:gosec // G103: Valid use of unsafe call to pass pobject
+		uintptr(unsafe.Pointer(mszCounterList)), //nolint:gosec // G103: Valid use of unsafe call to pass mszCounterList
+		uintptr(unsafe.Pointer(pcchCounterListLength)),  //nolint:gosec // G103: Valid use of unsafe call to pass pcchCounterListLength
+		uintptr(unsafe.Pointer(mszInstanceList)),        //nolint:gosec // G103: Valid use of unsafe call to pass mszInstanceList
+		uintptr(unsafe.Pointer(pcchInstanceListLength)), //nolint:gosec // G103: Valid use of unsafe call to pass pcchInstanceListLength
+		perfDetailWizard,
+		0)
+
+	return uint32(ret)
+}
+
+// pdhLookupPerfIndexByName returns the index of the specified English or localized object or counter name.
+//
+// szMachineName [in]
+// Name of the computer, or an empty string for the local computer.
+//
+// szNameBuffer [in]
+// Name of the object or counter.
+//
+// pdwIndex [out]
+// Receives the index of the name.
+func pdhLookupPerfIndexByName(szMachineName, szNameBuffer string, pdwIndex *uint32) uint32 {
+	var pmachine *uint16
+	if szMachineName != "" {
+		pmachine, _ = syscall.UTF16PtrFromString(szMachineName)
+	}
+	pname, _ := syscall.UTF16PtrFromString(szNameBuffer)
+	ret, _, _ := pdhLookupPerfIndexByNameWProc.Call(
+		uintptr(unsafe.Pointer(pmachine)), //nolint:gosec // G103: Valid use of unsafe call to pass pmachine
+		uintptr(unsafe.Pointer(pname)),    //nolint:gosec // G103: Valid use of unsafe call to pass pname
+		uintptr(unsafe.Pointer(pdwIndex))) //nolint:gosec // G103: Valid use of unsafe call to pass pdwIndex
+
+	return uint32(ret)
+}
+
+// pdhLookupPerfNameByIndex returns the localized name of the object or counter with the specified index.
+//
+// szMachineName [in]
+// Name of the computer, or an empty string for the local computer.
+//
+// dwNameIndex [in]
+// Index of the object or counter.
+//
+// szNameBuffer [out]
+// Caller-allocated buffer receiving the NULL terminated name.
+//
+// pcchNameBufferSize [in, out]
+// Size of the buffer in characters. If the buffer is too small, the function returns PdhMoreData and sets this
+// parameter to the required size.
+func pdhLookupPerfNameByIndex(szMachineName string, dwNameIndex uint32, szNameBuffer *uint16, pcchNameBufferSize *uint32) uint32 {
+	var pmachine *uint16
+	if szMachineName != "" {
+		pmachine, _ = syscall.UTF16PtrFromString(szMachineName)
+	}
+	ret, _, _ := pdhLookupPerfNameByIndexWProc.Call(
+		uintptr(unsafe.Pointer(pmachine)), //nolint:gosec // G103: Valid use of unsafe call to pass pmachine
+		uintptr(dwNameIndex),
+		uintptr(unsafe.Pointer(szNameBuffer)),       //nolint:gosec // G103: Valid use of unsafe call to pass szNameBuffer
+		uintptr(unsafe.Pointer(pcchNameBufferSize))) //nolint:gosec // G103: Valid use of unsafe call to pass pcchNameBufferSize
+
+	return uint32(ret)
+}
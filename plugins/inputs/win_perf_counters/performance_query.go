@@ -29,8 +29,10 @@ type performanceQuery interface {
 	close() error
 	addCounterToQuery(counterPath string) (pdhCounterHandle, error)
 	addEnglishCounterToQuery(counterPath string) (pdhCounterHandle, error)
+	removeCounterFromQuery(counterHandle pdhCounterHandle) error
 	getCounterPath(counterHandle pdhCounterHandle) (string, error)
 	expandWildCardPath(counterPath string) ([]string, error)
+	enumObjectItems(objectName string, english bool) (counters []string, instances []string, err error)
 	getFormattedCounterValueDouble(hCounter pdhCounterHandle) (float64, error)
 	getRawCounterValue(hCounter pdhCounterHandle) (int64, error)
 	getFormattedCounterArrayDouble(hCounter pdhCounterHandle) ([]counterValue, error)
@@ -63,14 +65,17 @@ func newPdhError(code uint32) error {
 
 // performanceQueryImpl is implementation of performanceQuery interface, which calls phd.dll functions
 type performanceQueryImpl struct {
+	computer      string
 	maxBufferSize uint32
 	query         pdhQueryHandle
+	// the cached list of objects was refreshed
+	objectsRefreshed bool
 }
 
 type performanceQueryCreatorImpl struct{}
 
-func (performanceQueryCreatorImpl) newPerformanceQuery(_ string, maxBufferSize uint32) performanceQuery {
-	return &performanceQueryImpl{maxBufferSize: maxBufferSize}
+func (performanceQueryCreatorImpl) newPerformanceQuery(computer string, maxBufferSize uint32) performanceQuery {
+	return &performanceQueryImpl{computer: computer, maxBufferSize: maxBufferSize}
 }
 
 // open creates a new counterPath that is used to manage the collection of performance data.
@@ -127,6 +132,17 @@ func (m *performanceQueryImpl) addEnglishCounterToQuery(counterPath string) (pdh
 	return counterHandle, nil
 }
 
+// removeCounterFromQuery removes the counter with the given handle from the query
+func (m *performanceQueryImpl) removeCounterFromQuery(counterHandle pdhCounterHandle) error {
+	if m.query == 0 {
+		return errors.New("uninitialized query")
+	}
+	if ret := pdhRemoveCounter(counterHandle); ret != errorSuccess {
+		return newPdhError(ret)
+	}
+	return nil
+}
+
 // getCounterPath returns counter information for given handle
 func (m *performanceQueryImpl) getCounterPath(counterHandle pdhCounterHandle) (string, error) {
 	for buflen := initialBufferSize; buflen <= m.maxBufferSize; buflen *= 2 {
@@ -180,6 +196,76 @@ func (m *performanceQueryImpl) expandWildCardPath(counterPath string) ([]string,
 	return nil, errBufferLimitReached
 }
 
+// enumObjectItems returns the localized counter and instance names of the given object. English object names are
+// translated to the localized name first. The list of objects is refreshed once per query to discover objects
+// registered after the previous query, e.g. by a newly installed service.
+func (m *performanceQueryImpl) enumObjectItems(objectName string, english bool) (counters, instances []string, err error) {
+	machine := m.machineName()
+	if !m.objectsRefreshed {
+		if ret := pdhRefreshObjects(machine); ret != errorSuccess && ret != pdhMoreData {
+			return nil, nil, newPdhError(ret)
+		}
+		m.objectsRefreshed = true
+	}
+
+	if english {
+		// Keep the name as is if it cannot be found in the English names
+		if localized, err := m.localizedName(objectName); err == nil {
+			objectName = localized
+		}
+	}
+
+	for buflen := initialBufferSize; buflen <= m.maxBufferSize; buflen *= 2 {
+		counterBuf := make([]uint16, buflen)
+		instanceBuf := make([]uint16, buflen)
+
+		// Get the items with the current buffer size
+		counterSize, instanceSize := buflen, buflen
+		ret := pdhEnumObjectItems(machine, objectName, &counterBuf[0], &counterSize, &instanceBuf[0], &instanceSize)
+		if ret == errorSuccess {
+			return utf16ToStringArray(counterBuf), utf16ToStringArray(instanceBuf), nil
+		}
+
+		// Use the sizes as a hint if they exceed the current buffer size
+		if size := max(counterSize, instanceSize); size > buflen {
+			buflen = size
+		}
+
+		// We got a non-recoverable error so exit here
+		if ret != pdhMoreData {
+			return nil, nil, newPdhError(ret)
+		}
+	}
+
+	return nil, nil, errBufferLimitReached
+}
+
+// localizedName translates the English name of an object or counter to the localized name via its index
+func (m *performanceQueryImpl) localizedName(name string) (string, error) {
+	machine := m.machineName()
+
+	var index uint32
+	if ret := pdhLookupPerfIndexByName(machine, name, &index); ret != errorSuccess {
+		return "", newPdhError(ret)
+	}
+
+	// Names are limited to PDH_MAX_COUNTER_NAME characters
+	buf := make([]uint16, initialBufferSize)
+	size := initialBufferSize
+	if ret := pdhLookupPerfNameByIndex(machine, index, &buf[0], &size); ret != errorSuccess {
+		return "", newPdhError(ret)
+	}
+	return utf16PtrToString(&buf[0]), nil
+}
+
+// machineName returns the computer name in the form expected by the enumeration functions
+func (m *performanceQueryImpl) machineName() string {
+	if m.computer == "" || m.computer == "localhost" {
+		return ""
+	}
+	return `\\` + m.computer
+}
+
 // getFormattedCounterValueDouble computes a displayable value for the specified counter
 func (*performanceQueryImpl) getFormattedCounterValueDouble(hCounter pdhCounterHandle) (float64, error) {
 	var counterType uint32
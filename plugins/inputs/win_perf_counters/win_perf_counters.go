@@ -34,6 +34,7 @@ type WinPerfCounters struct {
 	UsePerfCounterTime         bool            `toml:"UsePerfCounterTime"`
 	Object                     []perfObject    `toml:"object"`
 	CountersRefreshInterval    config.Duration `toml:"CountersRefreshInterval"`
+	InstancesRefreshInterval   config.Duration `toml:"InstancesRefreshInterval"`
 	UseWildcardsExpansion      bool            `toml:"UseWildcardsExpansion"`
 	LocalizeWildcardsExpansion bool            `toml:"LocalizeWildcardsExpansion"`
 	IgnoredErrors              []string        `toml:"IgnoredErrors"`
@@ -42,9 +43,10 @@ type WinPerfCounters struct {
 
 	Log telegraf.Logger `toml:"-"`
 
-	lastRefreshed time.Time
-	queryCreator  performanceQueryCreator
-	hostCounters  map[string]*hostCountersInfo
+	lastRefreshed          time.Time
+	lastInstancesRefreshed time.Time
+	queryCreator           performanceQueryCreator
+	hostCounters           map[string]*hostCountersInfo
 	// cached os.Hostname()
 	cachedHostname string
 }
@@ -69,6 +71,22 @@ type hostCountersInfo struct {
 	counters  []*counter
 	query     performanceQuery
 	timestamp time.Time
+	// wildcard paths expanded to the individual counters
+	expansions []*wildcardExpansion
+}
+
+// wildcardExpansion is a counter path containing wildcards which is expanded
+// to the individual counters when UseWildcardsExpansion is set
+type wildcardExpansion struct {
+	// localized counter path containing the wildcards
+	counterPath string
+	// configured object, instance and counter names
+	objectName   string
+	instance     string
+	counterName  string
+	measurement  string
+	includeTotal bool
+	useRawValue  bool
 }
 
 type counter struct {
@@ -81,6 +99,10 @@ type counter struct {
 	includeTotal  bool
 	useRawValue   bool
 	counterHandle pdhCounterHandle
+	// expansion the counter originates from and the localized path of the
+	// counter as returned by the expansion
+	expansion    *wildcardExpansion
+	expandedPath string
 }
 
 type instanceGrouping struct {
@@ -104,6 +126,10 @@ func (m *WinPerfCounters) Init() error {
 		return fmt.Errorf("maximum buffer size should be smaller than %d", uint32(math.MaxUint32))
 	}
 
+	if m.InstancesRefreshInterval > 0 && !m.UseWildcardsExpansion {
+		return errors.New("InstancesRefreshInterval requires UseWildcardsExpansion to be enabled")
+	}
+
 	if m.UseWildcardsExpansion && !m.LocalizeWildcardsExpansion {
 		// Counters must not have wildcards with this option
 		found := false
@@ -152,8 +178,14 @@ func (m *WinPerfCounters) Gather(acc telegraf.Accumulator) error {
 			}
 		}
 		m.lastRefreshed = time.Now()
+		m.lastInstancesRefreshed = m.lastRefreshed
 		// minimum time between collecting two samples
 		time.Sleep(time.Second)
+	} else if m.InstancesRefreshInterval > 0 && m.lastInstancesRefreshed.Add(time.Duration(m.InstancesRefreshInterval)).Before(time.Now()) {
+		if err := m.refreshInstances(); err != nil {
+			return m.checkError(err)
+		}
+		m.lastInstancesRefreshed = time.Now()
 	}
 
 	for _, hostCounterSet := range m.hostCounters {
@@ -286,8 +318,17 @@ func newCounter(
 	if useRawValue {
 		newCounterName += "_Raw"
 	}
-	return &counter{counterPath, computer, objectName, newCounterName, instance, measurementName,
-		includeTotal, useRawValue, counterHandle}
+	return &counter{
+		counterPath:   counterPath,
+		computer:      computer,
+		objectName:    objectName,
+		counter:       newCounterName,
+		instance:      instance,
+		measurement:   measurementName,
+		includeTotal:  includeTotal,
+		useRawValue:   useRawValue,
+		counterHandle: counterHandle,
+	}
 }
 
 //nolint:revive //argument-limit conditionally more arguments allowed
@@ -296,22 +337,9 @@ func (m *WinPerfCounters) addItem(counterPath, computer, objectName, instance, c
 	var err error
 	var counterHandle pdhCounterHandle
 
-	sourceTag := computer
-	if computer == "localhost" {
-		sourceTag = m.hostname()
-	}
-	if m.hostCounters == nil {
-		m.hostCounters = make(map[string]*hostCountersInfo)
-	}
-	hostCounter, ok := m.hostCounters[computer]
-	if !ok {
-		hostCounter = &hostCountersInfo{computer: computer, tag: sourceTag}
-		m.hostCounters[computer] = hostCounter
-		hostCounter.query = m.queryCreator.newPerformanceQuery(computer, uint32(m.MaxBufferSize))
-		if err := hostCounter.query.open(); err != nil {
-			return err
-		}
-		hostCounter.counters = make([]*counter, 0)
+	hostCounter, err := m.hostQuery(computer)
+	if err != nil {
+		return err
 	}
 
 	if !hostCounter.query.isVistaOrNewer() {
@@ -327,7 +355,6 @@ func (m *WinPerfCounters) addItem(counterPath, computer, objectName, instance, c
 	}
 
 	if m.UseWildcardsExpansion {
-		origInstance := instance
 		counterPath, err = hostCounter.query.getCounterPath(counterHandle)
 		if err != nil {
 			return err
@@ -342,74 +369,21 @@ func (m *WinPerfCounters) addItem(counterPath, computer, objectName, instance, c
 			return err
 		}
 
+		expansion := &wildcardExpansion{
+			counterPath:  counterPath,
+			objectName:   origObjectName,
+			instance:     instance,
+			counterName:  origCounterName,
+			measurement:  measurement,
+			includeTotal: includeTotal,
+			useRawValue:  useRawValue,
+		}
 		for _, counterPath := range counters {
-			_, err := hostCounter.query.addCounterToQuery(counterPath)
-			if err != nil {
-				return err
-			}
-
-			computer, objectName, instance, counterName, err = extractCounterInfoFromCounterPath(counterPath)
-			if err != nil {
+			if err := m.addExpandedCounter(hostCounter, expansion, counterPath); err != nil {
 				return err
 			}
-
-			var newItem *counter
-			if !m.LocalizeWildcardsExpansion {
-				// On localized installations of Windows, Telegraf
-				// should return English metrics, but
-				// expandWildCardPath returns localized counters. Undo
-				// that by using the original object and counter
-				// names, along with the expanded instance.
-
-				var newInstance string
-				if instance == "" {
-					newInstance = emptyInstance
-				} else {
-					newInstance = instance
-				}
-				counterPath = formatPath(computer, origObjectName, newInstance, origCounterName)
-				counterHandle, err = hostCounter.query.addEnglishCounterToQuery(counterPath)
-				if err != nil {
-					return err
-				}
-				newItem = newCounter(
-					counterHandle,
-					counterPath,
-					computer,
-					origObjectName, instance,
-					origCounterName,
-					measurement,
-					includeTotal,
-					useRawValue,
-				)
-			} else {
-				counterHandle, err = hostCounter.query.addCounterToQuery(counterPath)
-				if err != nil {
-					return err
-				}
-				newItem = newCounter(
-					counterHandle,
-					counterPath,
-					computer,
-					objectName,
-					instance,
-					counterName,
-					measurement,
-					includeTotal,
-					useRawValue,
-				)
-			}
-
-			if instance == "_Total" && origInstance == "*" && !includeTotal {
-				continue
-			}
-
-			hostCounter.counters = append(hostCounter.counters, newItem)
-
-			if m.PrintValid {
-				m.Log.Infof("Valid: %s", counterPath)
-			}
 		}
+		hostCounter.expansions = append(hostCounter.expansions, expansion)
 	} else {
 		newItem := newCounter(
 			counterHandle,
@@ -431,6 +405,184 @@ func (m *WinPerfCounters) addItem(counterPath, computer, objectName, instance, c
 	return nil
 }
 
+// hostQuery returns the counters of the given computer opening a new query if necessary
+func (m *WinPerfCounters) hostQuery(computer string) (*hostCountersInfo, error) {
+	if m.hostCounters == nil {
+		m.hostCounters = make(map[string]*hostCountersInfo)
+	}
+	if hostCounter, ok := m.hostCounters[computer]; ok {
+		return hostCounter, nil
+	}
+
+	sourceTag := computer
+	if computer == "localhost" {
+		sourceTag = m.hostname()
+	}
+	hostCounter := &hostCountersInfo{computer: computer, tag: sourceTag}
+	m.hostCounters[computer] = hostCounter
+	hostCounter.query = m.queryCreator.newPerformanceQuery(computer, uint32(m.MaxBufferSize))
+	if err := hostCounter.query.open(); err != nil {
+		return nil, err
+	}
+	hostCounter.counters = make([]*counter, 0)
+	return hostCounter, nil
+}
+
+// addExpandedCounter adds the counter with the given localized path resulting
+// from expanding the wildcards of the given expansion
+func (m *WinPerfCounters) addExpandedCounter(hostCounter *hostCountersInfo, expansion *wildcardExpansion, expandedPath string) error {
+	computer, objectName, instance, counterName, err := extractCounterInfoFromCounterPath(expandedPath)
+	if err != nil {
+		return err
+	}
+
+	if instance == "_Total" && expansion.instance == "*" && !expansion.includeTotal {
+		return nil
+	}
+
+	counterPath := expandedPath
+	var newItem *counter
+	if !m.LocalizeWildcardsExpansion {
+		// On localized installations of Windows, Telegraf
+		// should return English metrics, but
+		// expandWildCardPath returns localized counters. Undo
+		// that by using the original object and counter
+		// names, along with the expanded instance.
+
+		var newInstance string
+		if instance == "" {
+			newInstance = emptyInstance
+		} else {
+			newInstance = instance
+		}
+		counterPath = formatPath(computer, expansion.objectName, newInstance, expansion.counterName)
+		counterHandle, err := hostCounter.query.addEnglishCounterToQuery(counterPath)
+		if err != nil {
+			return err
+		}
+		newItem = newCounter(
+			counterHandle,
+			counterPath,
+			computer,
+			expansion.objectName, instance,
+			expansion.counterName,
+			expansion.measurement,
+			expansion.includeTotal,
+			expansion.useRawValue,
+		)
+	} else {
+		counterHandle, err := hostCounter.query.addCounterToQuery(counterPath)
+		if err != nil {
+			return err
+		}
+		newItem = newCounter(
+			counterHandle,
+			counterPath,
+			computer,
+			objectName,
+			instance,
+			counterName,
+			expansion.measurement,
+			expansion.includeTotal,
+			expansion.useRawValue,
+		)
+	}
+	newItem.expansion = expansion
+	newItem.expandedPath = expandedPath
+
+	hostCounter.counters = append(hostCounter.counters, newItem)
+
+	if m.PrintValid {
+		m.Log.Infof("Valid: %s", counterPath)
+	}
+	return nil
+}
+
+// refreshInstances expands the wildcard paths again to add counters for new
+// instances and to remove the counters of vanished instances. In contrast to
+// refreshing all counters, the queries and the remaining counters are kept.
+func (m *WinPerfCounters) refreshInstances() error {
+	for _, hostCounter := range m.hostCounters {
+		var changed bool
+		for _, expansion := range hostCounter.expansions {
+			paths, err := hostCounter.query.expandWildCardPath(expansion.counterPath)
+			if err != nil {
+				// Keep the current counters, they are updated on the next refresh
+				m.Log.Warnf("Refreshing instances of %q on %q failed: %v", expansion.counterPath, hostCounter.computer, err)
+				continue
+			}
+
+			current := make(map[string]bool, len(paths))
+			for _, path := range paths {
+				current[path] = true
+			}
+
+			known := make(map[string]bool, len(paths))
+			counters := make([]*counter, 0, len(hostCounter.counters))
+			for _, c := range hostCounter.counters {
+				if c.expansion != expansion {
+					counters = append(counters, c)
+					continue
+				}
+				if !current[c.expandedPath] {
+					m.Log.Debugf("Removing counter %q of vanished instance on %q", c.counterPath, hostCounter.computer)
+					if err := hostCounter.query.removeCounterFromQuery(c.counterHandle); err != nil {
+						return err
+					}
+					changed = true
+					continue
+				}
+				known[c.expandedPath] = true
+				counters = append(counters, c)
+			}
+			hostCounter.counters = counters
+
+			for _, path := range paths {
+				if known[path] {
+					continue
+				}
+				m.Log.Debugf("Adding counter %q of new instance on %q", path, hostCounter.computer)
+				if err := m.addExpandedCounter(hostCounter, expansion, path); err != nil {
+					m.Log.Warnf("Adding counter %q on %q failed: %v", path, hostCounter.computer, err)
+					continue
+				}
+				changed = true
+			}
+		}
+
+		// Collect a first sample for the new counters as some counters need
+		// two samples before computing a value
+		if changed {
+			if err := hostCounter.query.collectData(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkObject probes the existence of the configured counter set (performance
+// object) on the computer to report missing objects once instead of failing
+// for each counter. Objects appearing later, e.g. when installing a service,
+// are picked up on the next refresh of the counters.
+func (m *WinPerfCounters) checkObject(hostCounter *hostCountersInfo, objectName string) error {
+	// Object names are in English if the English names are supported
+	english := hostCounter.query.isVistaOrNewer()
+	counters, instances, err := hostCounter.query.enumObjectItems(objectName, english)
+	if err != nil {
+		var pdhErr *pdhError
+		if errors.As(err, &pdhErr) && pdhErr.errorCode == pdhCstatusNoObject {
+			return fmt.Errorf("counter set %q does not exist on %q", objectName, hostCounter.computer)
+		}
+		// Probing is best-effort as e.g. enumerating objects might be denied on
+		// remote computers so continue with adding the counters
+		m.Log.Debugf("Probing counter set %q on %q failed: %v", objectName, hostCounter.computer, err)
+		return nil
+	}
+	m.Log.Debugf("Counter set %q on %q has %d counters and %d instances", objectName, hostCounter.computer, len(counters), len(instances))
+	return nil
+}
+
 func formatPath(computer, objectName, instance, counter string) string {
 	path := ""
 	if instance == emptyInstance {
@@ -466,6 +618,23 @@ func (m *WinPerfCounters) parseConfig() error {
 				// localhost as a computer name in counter path doesn't work
 				computer = "localhost"
 			}
+
+			hostCounter, err := m.hostQuery(computer)
+			if err != nil {
+				return err
+			}
+			if err := m.checkObject(hostCounter, PerfObject.ObjectName); err != nil {
+				if PerfObject.FailOnMissing {
+					return err
+				}
+				if PerfObject.WarnOnMissing {
+					m.Log.Warn(err.Error())
+				} else {
+					m.Log.Debug(err.Error())
+				}
+				continue
+			}
+
 			for _, counter := range PerfObject.Counters {
 				if len(PerfObject.Instances) == 0 {
 					m.Log.Warnf("Missing 'Instances' param for object %q", PerfObject.ObjectName)
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
@@ -86,6 +87,40 @@ func (m *fakePerformanceQuery) addEnglishCounterToQuery(counterPath string) (pdh
 	return 0, fmt.Errorf("in addEnglishCounterToQuery: invalid counter path: %q", counterPath)
 }
 
+func (m *fakePerformanceQuery) removeCounterFromQuery(counterHandle pdhCounterHandle) error {
+	if !m.openCalled {
+		return errors.New("in removeCounterFromQuery: uninitialized query")
+	}
+	for _, counter := range m.counters {
+		if counter.handle == counterHandle {
+			return nil
+		}
+	}
+	return fmt.Errorf("in removeCounterFromQuery: invalid handle: %q", counterHandle)
+}
+
+func (m *fakePerformanceQuery) enumObjectItems(objectName string, _ bool) (counters, instances []string, err error) {
+	var found bool
+	for _, c := range m.counters {
+		//nolint:errcheck // counter paths of the tests are valid
+		_, object, instance, counter, _ := extractCounterInfoFromCounterPath(c.path)
+		if object != objectName {
+			continue
+		}
+		found = true
+		if counter != "*" && !slices.Contains(counters, counter) {
+			counters = append(counters, counter)
+		}
+		if instance != "" && instance != "*" && !slices.Contains(instances, instance) {
+			instances = append(instances, instance)
+		}
+	}
+	if !found {
+		return nil, nil, newPdhError(pdhCstatusNoObject)
+	}
+	return counters, instances, nil
+}
+
 func (m *fakePerformanceQuery) getCounterPath(counterHandle pdhCounterHandle) (string, error) {
 	for _, counter := range m.counters {
 		if counter.handle == counterHandle {
@@ -1540,6 +1575,85 @@ func TestGatherRefreshingWithExpansion(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestRefreshInstances(t *testing.T) {
+	perfObjects := createPerfObject("", "test", "O", []string{"*"}, []string{"C1"}, true, false, false)
+	cps := []string{"\\O(*)\\C1", "\\O(I1)\\C1", "\\O(I2)\\C1", "\\O(I3)\\C1"}
+	fpm := &fakePerformanceQuery{
+		counters: createCounterMap(cps, []float64{0, 1.1, 1.2, 1.3}, []uint32{0, 0, 0, 0}),
+		expandPaths: map[string][]string{
+			cps[0]: {cps[1], cps[2]},
+		},
+		vistaAndNewer: true,
+	}
+	m := WinPerfCounters{
+		Log:                        testutil.Logger{},
+		Object:                     perfObjects,
+		UseWildcardsExpansion:      true,
+		LocalizeWildcardsExpansion: true,
+		InstancesRefreshInterval:   config.Duration(time.Minute),
+		MaxBufferSize:              defaultMaxBufferSize,
+		queryCreator: &fakePerformanceQueryCreator{
+			fakeQueries: map[string]*fakePerformanceQuery{"localhost": fpm},
+		},
+	}
+	require.NoError(t, m.Init())
+	require.NoError(t, m.parseConfig())
+
+	instances := func() []string {
+		var names []string
+		for _, c := range m.hostCounters["localhost"].counters {
+			names = append(names, c.instance)
+		}
+		return names
+	}
+	require.Equal(t, []string{"I1", "I2"}, instances())
+
+	// Instance I2 vanished and I3 appeared
+	fpm.expandPaths[cps[0]] = []string{cps[1], cps[3]}
+	require.NoError(t, m.refreshInstances())
+	require.Equal(t, []string{"I1", "I3"}, instances())
+
+	// The query must be kept
+	require.True(t, fpm.openCalled)
+	require.NoError(t, m.cleanQueries())
+}
+
+func TestInstancesRefreshRequiresExpansion(t *testing.T) {
+	m := WinPerfCounters{
+		Log:                      testutil.Logger{},
+		MaxBufferSize:            defaultMaxBufferSize,
+		InstancesRefreshInterval: config.Duration(time.Minute),
+	}
+	require.ErrorContains(t, m.Init(), "InstancesRefreshInterval requires UseWildcardsExpansion")
+}
+
+func TestParseConfigMissingObject(t *testing.T) {
+	cps := []string{"\\O(I)\\C"}
+	perfObjects := append(
+		createPerfObject("", "m", "O", []string{"I"}, []string{"C"}, false, false, false),
+		createPerfObject("", "m", "Missing", []string{"I"}, []string{"C"}, false, false, false)...,
+	)
+	m := WinPerfCounters{
+		Log:    testutil.Logger{},
+		Object: perfObjects,
+		queryCreator: &fakePerformanceQueryCreator{
+			fakeQueries: map[string]*fakePerformanceQuery{"localhost": {
+				counters:      createCounterMap(cps, []float64{1.1}, []uint32{0}),
+				vistaAndNewer: true,
+			}},
+		},
+	}
+
+	// Missing counter sets are skipped
+	require.NoError(t, m.parseConfig())
+	require.Len(t, m.hostCounters["localhost"].counters, 1)
+	require.NoError(t, m.cleanQueries())
+
+	m.Object[1].FailOnMissing = true
+	require.ErrorContains(t, m.parseConfig(), `counter set "Missing" does not exist on "localhost"`)
+	require.NoError(t, m.cleanQueries())
+}
+
 func TestGatherRefreshingWithoutExpansion(t *testing.T) {
 	var err error
 	if testing.Short() {
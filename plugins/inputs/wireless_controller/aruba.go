@@ -0,0 +1,292 @@
+package wireless_controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var errUnauthorized = errors.New("unauthorized")
+
+// arubaTable is a table returned by the show command API of ArubaOS
+type arubaTable []map[string]interface{}
+
+// gatherAruba collects the statistics from an ArubaOS 8 mobility controller
+// or conductor using the show command API
+func (w *WirelessController) gatherAruba() (*snapshot, error) {
+	if w.token == "" {
+		if err := w.arubaLogin(); err != nil {
+			return nil, fmt.Errorf("logging in failed: %w", err)
+		}
+	}
+
+	var s snapshot
+	if w.collects("ap") {
+		table, err := w.arubaShow("show ap active")
+		if err != nil {
+			return nil, err
+		}
+		s.aps = parseArubaAPs(table)
+	}
+	if w.collects("radio") {
+		table, err := w.arubaShow("show ap radio-summary")
+		if err != nil {
+			return nil, err
+		}
+		s.radios = parseArubaRadios(table)
+	}
+	if w.collects("ssid") {
+		table, err := w.arubaShow("show ap essid")
+		if err != nil {
+			return nil, err
+		}
+		s.ssids = parseArubaSSIDs(table)
+	}
+
+	return &s, nil
+}
+
+func (w *WirelessController) arubaLogin() error {
+	username, err := w.Username.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	defer username.Destroy()
+	password, err := w.Password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	defer password.Destroy()
+
+	form := url.Values{
+		"username": {username.String()},
+		"password": {password.String()},
+	}
+	resp, err := w.client.PostForm(w.URL+"/v1/api/login", form)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received status %q", resp.Status)
+	}
+
+	var result struct {
+		Global struct {
+			Status    string `json:"status"`
+			StatusStr string `json:"status_str"`
+			Token     string `json:"UIDARUBA"`
+		} `json:"_global_result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decoding response failed: %w", err)
+	}
+	if result.Global.Status != "0" || result.Global.Token == "" {
+		return fmt.Errorf("login rejected: %s", result.Global.StatusStr)
+	}
+	w.token = result.Global.Token
+
+	return nil
+}
+
+func (w *WirelessController) arubaLogout() error {
+	req, err := http.NewRequest("GET", w.URL+"/v1/api/logout", nil)
+	if err != nil {
+		return err
+	}
+	req.AddCookie(&http.Cookie{Name: "SESSION", Value: w.token})
+	w.token = ""
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// arubaShow executes the given show command and returns the first table of
+// the result, the session is renewed once if it expired
+func (w *WirelessController) arubaShow(command string) (arubaTable, error) {
+	table, err := w.arubaShowCommand(command)
+	if errors.Is(err, errUnauthorized) {
+		w.Log.Debug("Session expired, logging in again")
+		if err := w.arubaLogin(); err != nil {
+			w.token = ""
+			return nil, fmt.Errorf("logging in failed: %w", err)
+		}
+		table, err = w.arubaShowCommand(command)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("executing %q failed: %w", command, err)
+	}
+	return table, nil
+}
+
+func (w *WirelessController) arubaShowCommand(command string) (arubaTable, error) {
+	query := url.Values{
+		"command":  {command},
+		"UIDARUBA": {w.token},
+	}
+	req, err := http.NewRequest("GET", w.URL+"/v1/configuration/showcommand?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "SESSION", Value: w.token})
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return nil, errUnauthorized
+	default:
+		return nil, fmt.Errorf("received status %q", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response failed: %w", err)
+	}
+
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+
+	// The table is named after the command output, so use the first entry
+	// not being meta information
+	for k, v := range result {
+		if strings.HasPrefix(k, "_") {
+			continue
+		}
+		var table arubaTable
+		if err := json.Unmarshal(v, &table); err != nil {
+			continue
+		}
+		return table, nil
+	}
+	return nil, nil
+}
+
+func parseArubaAPs(table arubaTable) []apStats {
+	aps := make([]apStats, 0, len(table))
+	for _, row := range table {
+		name := arubaColumn(row, "Name")
+		if name == "" {
+			continue
+		}
+		// Clients are reported per radio type, e.g. "11g Clients"
+		var clients int64
+		for k, v := range row {
+			if !strings.HasSuffix(k, "Clients") {
+				continue
+			}
+			if n, ok := parseNumber(arubaString(v)); ok {
+				clients += int64(n)
+			}
+		}
+		aps = append(aps, apStats{
+			name:    name,
+			group:   arubaColumn(row, "Group"),
+			clients: clients,
+		})
+	}
+	return aps
+}
+
+func parseArubaRadios(table arubaTable) []radioStats {
+	radios := make([]radioStats, 0, len(table))
+	for _, row := range table {
+		name := arubaColumn(row, "Name")
+		if name == "" {
+			continue
+		}
+		r := radioStats{
+			ap:   name,
+			band: normalizeBand(arubaColumn(row, "Band")),
+		}
+		if v, ok := parseNumber(arubaColumn(row, "Channel", "Ch", "CH")); ok {
+			c := int64(v)
+			r.channel = &c
+		}
+		if v, ok := parseNumber(arubaColumn(row, "Clients", "Cl")); ok {
+			c := int64(v)
+			r.clients = &c
+		}
+		if v, ok := parseNumber(arubaColumn(row, "Ch Util(%)", "CU(%)", "Channel Utilization")); ok {
+			r.channelUtilization = &v
+		}
+		if v, ok := parseNumber(arubaColumn(row, "NF", "NF(dBm)", "Noise Floor")); ok {
+			r.noiseFloor = &v
+		}
+
+		// Noise floor, utilization and interference might be combined
+		if nui := arubaColumn(row, "NF/U/I"); nui != "" {
+			parts := strings.Split(nui, "/")
+			if v, ok := parseNumber(parts[0]); ok && r.noiseFloor == nil {
+				r.noiseFloor = &v
+			}
+			if len(parts) > 1 {
+				if v, ok := parseNumber(parts[1]); ok && r.channelUtilization == nil {
+					r.channelUtilization = &v
+				}
+			}
+		}
+		radios = append(radios, r)
+	}
+	return radios
+}
+
+func parseArubaSSIDs(table arubaTable) []ssidStats {
+	ssids := make([]ssidStats, 0, len(table))
+	for _, row := range table {
+		name := arubaColumn(row, "ESSID")
+		if name == "" {
+			continue
+		}
+		s := ssidStats{ssid: name}
+		if v, ok := parseNumber(arubaColumn(row, "Clients")); ok {
+			s.clients = int64(v)
+		}
+		if v, ok := parseNumber(arubaColumn(row, "APs")); ok {
+			aps := int64(v)
+			s.aps = &aps
+		}
+		ssids = append(ssids, s)
+	}
+	return ssids
+}
+
+// arubaColumn returns the value of the first existing column
+func arubaColumn(row map[string]interface{}, columns ...string) string {
+	for _, c := range columns {
+		if v, found := row[c]; found && v != nil {
+			return arubaString(v)
+		}
+	}
+	return ""
+}
+
+func arubaString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,290 @@
+package wireless_controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	ciscoAPOper     = "Cisco-IOS-XE-wireless-access-point-oper:access-point-oper-data"
+	ciscoRRMOper    = "Cisco-IOS-XE-wireless-rrm-oper:rrm-oper-data"
+	ciscoClientOper = "Cisco-IOS-XE-wireless-client-oper:client-oper-data"
+)
+
+// ciscoNumber accepts numbers encoded as JSON numbers or strings as YANG
+// 64-bit integers are transported as strings
+type ciscoNumber float64
+
+func (n *ciscoNumber) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q: %w", s, err)
+	}
+	*n = ciscoNumber(v)
+	return nil
+}
+
+// ciscoCounter is an unsigned 64-bit counter encoded as string
+type ciscoCounter uint64
+
+func (c *ciscoCounter) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid counter %q: %w", s, err)
+	}
+	*c = ciscoCounter(v)
+	return nil
+}
+
+type ciscoCapwapData struct {
+	MAC  string `json:"wtp-mac"`
+	Name string `json:"name"`
+}
+
+type ciscoRadioOperData struct {
+	MAC       string      `json:"wtp-mac"`
+	Slot      ciscoNumber `json:"radio-slot-id"`
+	RadioType string      `json:"radio-type"`
+	PhyHtCfg  struct {
+		CfgData struct {
+			CurrentFrequency *ciscoNumber `json:"curr-freq"`
+		} `json:"cfg-data"`
+	} `json:"phy-ht-cfg"`
+}
+
+type ciscoRRMMeasurement struct {
+	MAC  string      `json:"wtp-mac"`
+	Slot ciscoNumber `json:"radio-slot-id"`
+	Load struct {
+		Utilization *ciscoNumber `json:"cca-util-percentage"`
+		Stations    *ciscoNumber `json:"stations"`
+	} `json:"load"`
+	Noise struct {
+		Noise struct {
+			NoiseData []struct {
+				Channel ciscoNumber `json:"chan"`
+				Noise   ciscoNumber `json:"noise"`
+			} `json:"noise-data"`
+		} `json:"noise"`
+	} `json:"noise"`
+}
+
+type ciscoDot11OperData struct {
+	ClientMAC string `json:"ms-mac-address"`
+	APMAC     string `json:"ap-mac-address"`
+	SSID      string `json:"vap-ssid"`
+}
+
+type ciscoTrafficStats struct {
+	ClientMAC string       `json:"ms-mac-address"`
+	BytesRx   ciscoCounter `json:"bytes-rx"`
+	BytesTx   ciscoCounter `json:"bytes-tx"`
+}
+
+// gatherCisco collects the statistics from a Catalyst 9800 controller using
+// the RESTCONF operational data models
+func (w *WirelessController) gatherCisco() (*snapshot, error) {
+	// The access-point information is required for resolving the AP names
+	var capwap []ciscoCapwapData
+	if err := w.ciscoGet(ciscoAPOper+"/capwap-data", &capwap); err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(capwap))
+	for _, ap := range capwap {
+		names[ap.MAC] = ap.Name
+	}
+	apName := func(mac string) string {
+		if name, found := names[mac]; found && name != "" {
+			return name
+		}
+		return mac
+	}
+
+	var dot11 []ciscoDot11OperData
+	if w.collects("ap") || w.collects("ssid") {
+		if err := w.ciscoGet(ciscoClientOper+"/dot11-oper-data", &dot11); err != nil {
+			return nil, err
+		}
+	}
+
+	var s snapshot
+	if w.collects("ap") {
+		clients := make(map[string]int64, len(capwap))
+		for _, c := range dot11 {
+			clients[c.APMAC]++
+		}
+		for _, ap := range capwap {
+			s.aps = append(s.aps, apStats{name: apName(ap.MAC), clients: clients[ap.MAC]})
+		}
+	}
+
+	if w.collects("radio") {
+		var radios []ciscoRadioOperData
+		if err := w.ciscoGet(ciscoAPOper+"/radio-oper-data", &radios); err != nil {
+			return nil, err
+		}
+		var measurements []ciscoRRMMeasurement
+		if err := w.ciscoGet(ciscoRRMOper+"/rrm-measurement", &measurements); err != nil {
+			return nil, err
+		}
+		s.radios = ciscoRadios(radios, measurements, apName)
+	}
+
+	if w.collects("ssid") {
+		var traffic []ciscoTrafficStats
+		if err := w.ciscoGet(ciscoClientOper+"/traffic-stats", &traffic); err != nil {
+			return nil, err
+		}
+		s.ssids = ciscoSSIDs(dot11, traffic)
+	}
+
+	return &s, nil
+}
+
+func ciscoRadios(radios []ciscoRadioOperData, measurements []ciscoRRMMeasurement, apName func(string) string) []radioStats {
+	type radioKey struct {
+		mac  string
+		slot int64
+	}
+	rrm := make(map[radioKey]*ciscoRRMMeasurement, len(measurements))
+	for i := range measurements {
+		m := &measurements[i]
+		rrm[radioKey{m.MAC, int64(m.Slot)}] = m
+	}
+
+	result := make([]radioStats, 0, len(radios))
+	for _, radio := range radios {
+		r := radioStats{
+			ap:   apName(radio.MAC),
+			band: normalizeBand(radio.RadioType),
+			slot: strconv.FormatInt(int64(radio.Slot), 10),
+		}
+		if ch := radio.PhyHtCfg.CfgData.CurrentFrequency; ch != nil {
+			c := int64(*ch)
+			r.channel = &c
+		}
+		if m, found := rrm[radioKey{radio.MAC, int64(radio.Slot)}]; found {
+			if m.Load.Stations != nil {
+				c := int64(*m.Load.Stations)
+				r.clients = &c
+			}
+			if m.Load.Utilization != nil {
+				u := float64(*m.Load.Utilization)
+				r.channelUtilization = &u
+			}
+			// Use the noise measured on the current channel
+			if r.channel != nil {
+				for _, n := range m.Noise.Noise.NoiseData {
+					if int64(n.Channel) == *r.channel {
+						nf := float64(n.Noise)
+						r.noiseFloor = &nf
+						break
+					}
+				}
+			}
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+func ciscoSSIDs(dot11 []ciscoDot11OperData, traffic []ciscoTrafficStats) []ssidStats {
+	stats := make(map[string]*ciscoTrafficStats, len(traffic))
+	for i := range traffic {
+		stats[traffic[i].ClientMAC] = &traffic[i]
+	}
+
+	var order []string
+	ssids := make(map[string]*ssidStats)
+	aps := make(map[string]map[string]bool)
+	for _, c := range dot11 {
+		if c.SSID == "" {
+			continue
+		}
+		s, found := ssids[c.SSID]
+		if !found {
+			var rx, tx uint64
+			s = &ssidStats{ssid: c.SSID, bytesRx: &rx, bytesTx: &tx}
+			ssids[c.SSID] = s
+			aps[c.SSID] = make(map[string]bool)
+			order = append(order, c.SSID)
+		}
+		s.clients++
+		aps[c.SSID][c.APMAC] = true
+		if t, found := stats[c.ClientMAC]; found {
+			*s.bytesRx += uint64(t.BytesRx)
+			*s.bytesTx += uint64(t.BytesTx)
+		}
+	}
+
+	result := make([]ssidStats, 0, len(order))
+	for _, name := range order {
+		s := ssids[name]
+		n := int64(len(aps[name]))
+		s.aps = &n
+		result = append(result, *s)
+	}
+	return result
+}
+
+// ciscoGet queries the given RESTCONF data path and decodes the list
+// contained in the response into the given value
+func (w *WirelessController) ciscoGet(path string, v interface{}) error {
+	req, err := http.NewRequest("GET", w.URL+"/restconf/data/"+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/yang-data+json")
+
+	username, err := w.Username.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	password, err := w.Password.Get()
+	if err != nil {
+		username.Destroy()
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	req.SetBasicAuth(username.String(), password.String())
+	username.Destroy()
+	password.Destroy()
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("querying %q failed: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent, http.StatusNotFound:
+		// No data available, e.g. no clients connected
+		return nil
+	default:
+		return fmt.Errorf("querying %q failed: received status %q", path, resp.Status)
+	}
+
+	// The data is wrapped in an object named after the queried node
+	var result map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decoding response of %q failed: %w", path, err)
+	}
+	for _, raw := range result {
+		if err := json.Unmarshal(raw, v); err != nil {
+			return fmt.Errorf("decoding response of %q failed: %w", path, err)
+		}
+		break
+	}
+	return nil
+}
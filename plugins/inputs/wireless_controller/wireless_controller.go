@@ -0,0 +1,248 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package wireless_controller
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var numberRe = regexp.MustCompile(`^-?[0-9]+(?:\.[0-9]+)?`)
+
+type WirelessController struct {
+	URL      string          `toml:"url"`
+	Vendor   string          `toml:"vendor"`
+	Username config.Secret   `toml:"username"`
+	Password config.Secret   `toml:"password"`
+	Collect  []string        `toml:"collect"`
+	Log      telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	controller string
+	client     *http.Client
+
+	// Session token of the Aruba API
+	token string
+}
+
+// snapshot holds the statistics of a controller in a vendor independent way
+type snapshot struct {
+	aps    []apStats
+	radios []radioStats
+	ssids  []ssidStats
+}
+
+type apStats struct {
+	name    string
+	group   string
+	clients int64
+}
+
+type radioStats struct {
+	ap                 string
+	band               string
+	slot               string
+	channel            *int64
+	clients            *int64
+	channelUtilization *float64
+	noiseFloor         *float64
+}
+
+type ssidStats struct {
+	ssid    string
+	clients int64
+	aps     *int64
+	bytesRx *uint64
+	bytesTx *uint64
+}
+
+func (*WirelessController) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WirelessController) Init() error {
+	if w.URL == "" {
+		return errors.New("'url' required")
+	}
+	w.URL = strings.TrimRight(w.URL, "/")
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return fmt.Errorf("parsing URL %q failed: %w", w.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid scheme %q in URL", u.Scheme)
+	}
+	w.controller = u.Hostname()
+
+	switch w.Vendor {
+	case "aruba", "cisco_9800":
+	case "":
+		return errors.New("'vendor' required")
+	default:
+		return fmt.Errorf("invalid vendor %q", w.Vendor)
+	}
+
+	if w.Username.Empty() || w.Password.Empty() {
+		return errors.New("'username' and 'password' required")
+	}
+
+	if len(w.Collect) == 0 {
+		w.Collect = []string{"ap", "radio", "ssid"}
+	}
+	for _, c := range w.Collect {
+		switch c {
+		case "ap", "radio", "ssid":
+		default:
+			return fmt.Errorf("invalid value %q in 'collect'", c)
+		}
+	}
+
+	client, err := w.HTTPClientConfig.CreateClient(context.Background(), w.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	w.client = client
+
+	return nil
+}
+
+func (w *WirelessController) Gather(acc telegraf.Accumulator) error {
+	var s *snapshot
+	var err error
+	switch w.Vendor {
+	case "aruba":
+		s, err = w.gatherAruba()
+	case "cisco_9800":
+		s, err = w.gatherCisco()
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, ap := range s.aps {
+		tags := map[string]string{
+			"controller": w.controller,
+			"ap_name":    ap.name,
+		}
+		if ap.group != "" {
+			tags["ap_group"] = ap.group
+		}
+		acc.AddFields("wireless_controller_ap", map[string]interface{}{"clients": ap.clients}, tags)
+	}
+
+	for _, r := range s.radios {
+		tags := map[string]string{
+			"controller": w.controller,
+			"ap_name":    r.ap,
+		}
+		if r.band != "" {
+			tags["band"] = r.band
+		}
+		if r.slot != "" {
+			tags["slot"] = r.slot
+		}
+		fields := make(map[string]interface{}, 4)
+		if r.channel != nil {
+			fields["channel"] = *r.channel
+		}
+		if r.clients != nil {
+			fields["clients"] = *r.clients
+		}
+		if r.channelUtilization != nil {
+			fields["channel_utilization"] = *r.channelUtilization
+		}
+		if r.noiseFloor != nil {
+			fields["noise_floor"] = *r.noiseFloor
+		}
+		if len(fields) > 0 {
+			acc.AddFields("wireless_controller_radio", fields, tags)
+		}
+	}
+
+	for _, ssid := range s.ssids {
+		tags := map[string]string{
+			"controller": w.controller,
+			"ssid":       ssid.ssid,
+		}
+		fields := map[string]interface{}{"clients": ssid.clients}
+		if ssid.aps != nil {
+			fields["aps"] = *ssid.aps
+		}
+		if ssid.bytesRx != nil {
+			fields["bytes_rx"] = *ssid.bytesRx
+		}
+		if ssid.bytesTx != nil {
+			fields["bytes_tx"] = *ssid.bytesTx
+		}
+		acc.AddFields("wireless_controller_ssid", fields, tags)
+	}
+
+	return nil
+}
+
+func (w *WirelessController) Stop() {
+	if w.client == nil {
+		return
+	}
+	if w.Vendor == "aruba" && w.token != "" {
+		if err := w.arubaLogout(); err != nil {
+			w.Log.Debugf("Logging out failed: %v", err)
+		}
+	}
+	w.client.CloseIdleConnections()
+}
+
+func (w *WirelessController) collects(what string) bool {
+	return slices.Contains(w.Collect, what)
+}
+
+// parseNumber parses the leading number of values like "36E", "12%" or "-95"
+func parseNumber(s string) (float64, bool) {
+	m := numberRe.FindString(strings.TrimSpace(s))
+	if m == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(m, 64)
+	return v, err == nil
+}
+
+// normalizeBand converts the band notations of the vendors into the form
+// "2.4GHz", "5GHz" or "6GHz"
+func normalizeBand(band string) string {
+	b := strings.ToLower(band)
+	switch {
+	case strings.Contains(b, "6ghz") || strings.Contains(b, "6 ghz") || strings.Contains(b, "-6g"):
+		return "6GHz"
+	case strings.Contains(b, "2.4") || strings.Contains(b, "24ghz") || strings.HasSuffix(b, "bg") || strings.HasSuffix(b, "11b") || strings.HasSuffix(b, "11g"):
+		return "2.4GHz"
+	case strings.Contains(b, "5ghz") || strings.Contains(b, "5 ghz") || strings.HasSuffix(b, "11a"):
+		return "5GHz"
+	}
+	return band
+}
+
+func init() {
+	inputs.Add("wireless_controller", func() telegraf.Input {
+		return &WirelessController{
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
@@ -0,0 +1,360 @@
+package wireless_controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *WirelessController
+		expected string
+	}{
+		{
+			name:     "missing url",
+			plugin:   &WirelessController{Vendor: "aruba"},
+			expected: "'url' required",
+		},
+		{
+			name:     "invalid scheme",
+			plugin:   &WirelessController{URL: "ftp://localhost", Vendor: "aruba"},
+			expected: `invalid scheme "ftp" in URL`,
+		},
+		{
+			name:     "missing vendor",
+			plugin:   &WirelessController{URL: "https://localhost"},
+			expected: "'vendor' required",
+		},
+		{
+			name:     "invalid vendor",
+			plugin:   &WirelessController{URL: "https://localhost", Vendor: "foo"},
+			expected: `invalid vendor "foo"`,
+		},
+		{
+			name:     "missing credentials",
+			plugin:   &WirelessController{URL: "https://localhost", Vendor: "aruba"},
+			expected: "'username' and 'password' required",
+		},
+		{
+			name: "invalid collect",
+			plugin: &WirelessController{
+				URL:      "https://localhost",
+				Vendor:   "cisco_9800",
+				Username: config.NewSecret([]byte("admin")),
+				Password: config.NewSecret([]byte("secret")),
+				Collect:  []string{"ap", "foo"},
+			},
+			expected: `invalid value "foo" in 'collect'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = &testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestAruba(t *testing.T) {
+	commands := map[string]string{
+		"show ap active":        "aruba_ap_active.json",
+		"show ap radio-summary": "aruba_radio_summary.json",
+		"show ap essid":         "aruba_essid.json",
+	}
+
+	var logins, logouts int
+	var expired bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/api/login":
+			if r.Method != http.MethodPost || r.FormValue("username") != "admin" || r.FormValue("password") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			logins++
+			_, _ = w.Write([]byte(`{"_global_result": {"status": "0", "status_str": "You've logged in successfully.", "UIDARUBA": "token"}}`))
+		case "/v1/api/logout":
+			logouts++
+		case "/v1/configuration/showcommand":
+			if r.URL.Query().Get("UIDARUBA") != "token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			// Expire the session once to test the renewal
+			if !expired && r.URL.Query().Get("command") == "show ap radio-summary" {
+				expired = true
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fn, found := commands[r.URL.Query().Get("command")]
+			if !found {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			buf, err := os.ReadFile(filepath.Join("testdata", fn))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write(buf)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &WirelessController{
+		URL:      server.URL,
+		Vendor:   "aruba",
+		Username: config.NewSecret([]byte("admin")),
+		Password: config.NewSecret([]byte("secret")),
+		Log:      &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	plugin.Stop()
+	require.Equal(t, 2, logins)
+	require.Equal(t, 1, logouts)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"wireless_controller_ap",
+			map[string]string{"controller": "127.0.0.1", "ap_name": "ap-101", "ap_group": "floor1"},
+			map[string]interface{}{"clients": int64(15)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"wireless_controller_ap",
+			map[string]string{"controller": "127.0.0.1", "ap_name": "ap-102", "ap_group": "floor2"},
+			map[string]interface{}{"clients": int64(5)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"wireless_controller_radio",
+			map[string]string{"controller": "127.0.0.1", "ap_name": "ap-101", "band": "5GHz"},
+			map[string]interface{}{
+				"channel":             int64(36),
+				"clients":             int64(12),
+				"channel_utilization": float64(12),
+				"noise_floor":         float64(-95),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"wireless_controller_radio",
+			map[string]string{"controller": "127.0.0.1", "ap_name": "ap-101", "band": "2.4GHz"},
+			map[string]interface{}{
+				"channel":             int64(6),
+				"clients":             int64(3),
+				"channel_utilization": float64(40),
+				"noise_floor":         float64(-90),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"wireless_controller_ssid",
+			map[string]string{"controller": "127.0.0.1", "ssid": "corp"},
+			map[string]interface{}{"clients": int64(17), "aps": int64(2)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"wireless_controller_ssid",
+			map[string]string{"controller": "127.0.0.1", "ssid": "guest"},
+			map[string]interface{}{"clients": int64(3), "aps": int64(1)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestArubaLoginFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"_global_result": {"status": "1", "status_str": "Invalid credentials"}}`))
+	}))
+	defer server.Close()
+
+	plugin := &WirelessController{
+		URL:      server.URL,
+		Vendor:   "aruba",
+		Username: config.NewSecret([]byte("admin")),
+		Password: config.NewSecret([]byte("wrong")),
+		Log:      &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Gather(&acc), "login rejected: Invalid credentials")
+}
+
+func TestCisco(t *testing.T) {
+	paths := map[string]string{
+		"/restconf/data/" + ciscoAPOper + "/capwap-data":         "cisco_capwap_data.json",
+		"/restconf/data/" + ciscoAPOper + "/radio-oper-data":     "cisco_radio_oper_data.json",
+		"/restconf/data/" + ciscoRRMOper + "/rrm-measurement":    "cisco_rrm_measurement.json",
+		"/restconf/data/" + ciscoClientOper + "/dot11-oper-data": "cisco_dot11_oper_data.json",
+		"/restconf/data/" + ciscoClientOper + "/traffic-stats":   "cisco_traffic_stats.json",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("Accept") != "application/yang-data+json" {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		fn, found := paths[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buf, err := os.ReadFile(filepath.Join("testdata", fn))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/yang-data+json")
+		_, _ = w.Write(buf)
+	}))
+	defer server.Close()
+
+	plugin := &WirelessController{
+		URL:      server.URL,
+		Vendor:   "cisco_9800",
+		Username: config.NewSecret([]byte("admin")),
+		Password: config.NewSecret([]byte("secret")),
+		Log:      &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"wireless_controller_ap",
+			map[string]string{"controller": "127.0.0.1", "ap_name": "ap-101"},
+			map[string]interface{}{"clients": int64(3)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"wireless_controller_ap",
+			map[string]string{"controller": "127.0.0.1", "ap_name": "ap-102"},
+			map[string]interface{}{"clients": int64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"wireless_controller_radio",
+			map[string]string{"controller": "127.0.0.1", "ap_name": "ap-101", "band": "2.4GHz", "slot": "0"},
+			map[string]interface{}{
+				"channel":             int64(6),
+				"clients":             int64(1),
+				"channel_utilization": float64(40),
+				"noise_floor":         float64(-90),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"wireless_controller_radio",
+			map[string]string{"controller": "127.0.0.1", "ap_name": "ap-101", "band": "5GHz", "slot": "1"},
+			map[string]interface{}{
+				"channel":             int64(36),
+				"clients":             int64(2),
+				"channel_utilization": float64(12),
+				"noise_floor":         float64(-95),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"wireless_controller_ssid",
+			map[string]string{"controller": "127.0.0.1", "ssid": "corp"},
+			map[string]interface{}{
+				"clients":  int64(3),
+				"aps":      int64(2),
+				"bytes_rx": uint64(1600),
+				"bytes_tx": uint64(3000),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"wireless_controller_ssid",
+			map[string]string{"controller": "127.0.0.1", "ssid": "guest"},
+			map[string]interface{}{
+				"clients":  int64(1),
+				"aps":      int64(1),
+				"bytes_rx": uint64(12345678901234567890),
+				"bytes_tx": uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestCiscoNoClients(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/restconf/data/"+ciscoAPOper+"/capwap-data" {
+			_, _ = w.Write([]byte(`{"Cisco-IOS-XE-wireless-access-point-oper:capwap-data": [{"wtp-mac": "00:11:22:33:44:50", "name": "ap-101"}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	plugin := &WirelessController{
+		URL:      server.URL,
+		Vendor:   "cisco_9800",
+		Username: config.NewSecret([]byte("admin")),
+		Password: config.NewSecret([]byte("secret")),
+		Collect:  []string{"ap", "ssid"},
+		Log:      &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"wireless_controller_ap",
+			map[string]string{"controller": "127.0.0.1", "ap_name": "ap-101"},
+			map[string]interface{}{"clients": int64(0)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestNormalizeBand(t *testing.T) {
+	tests := map[string]string{
+		"2.4GHz":          "2.4GHz",
+		"5GHz":            "5GHz",
+		"6GHz":            "6GHz",
+		"client-dot11bg":  "2.4GHz",
+		"client-dot11a":   "5GHz",
+		"client-6ghz":     "6GHz",
+		"dot11-6ghz":      "6GHz",
+		"something-weird": "something-weird",
+	}
+	for input, expected := range tests {
+		require.Equal(t, expected, normalizeBand(input), input)
+	}
+}
@@ -0,0 +1,178 @@
+package x509_cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// Time to cache revocation lists not specifying a next update
+const defaultCRLCacheDuration = time.Hour
+
+// revocationStatus holds the result of checking the revocation status of a
+// certificate with the responsible OCSP responder or CRL.
+type revocationStatus struct {
+	method    string
+	status    int
+	revokedAt time.Time
+}
+
+// revocationChecker queries the revocation status of certificates via OCSP
+// and falls back to the certificate revocation lists of the issuer. CRLs are
+// cached until their next update is due.
+type revocationChecker struct {
+	client *http.Client
+
+	sync.Mutex
+	crls map[string]*x509.RevocationList
+}
+
+func newRevocationChecker(timeout time.Duration) *revocationChecker {
+	return &revocationChecker{
+		client: &http.Client{Timeout: timeout},
+		crls:   make(map[string]*x509.RevocationList),
+	}
+}
+
+// check determines the revocation status of the certificate issued by the
+// given issuer. The status follows the OCSP convention of 0=good, 1=revoked
+// and 2=unknown.
+func (r *revocationChecker) check(cert, issuer *x509.Certificate) (revocationStatus, error) {
+	if len(cert.OCSPServer) == 0 && len(cert.CRLDistributionPoints) == 0 {
+		return revocationStatus{method: "none", status: ocsp.Unknown}, nil
+	}
+
+	var errs []error
+	for _, server := range cert.OCSPServer {
+		status, err := r.checkOCSP(server, cert, issuer)
+		if err == nil {
+			return status, nil
+		}
+		errs = append(errs, fmt.Errorf("OCSP responder %q: %w", server, err))
+	}
+	for _, location := range cert.CRLDistributionPoints {
+		status, err := r.checkCRL(location, cert, issuer)
+		if err == nil {
+			return status, nil
+		}
+		errs = append(errs, fmt.Errorf("CRL %q: %w", location, err))
+	}
+	return revocationStatus{method: "none", status: ocsp.Unknown}, errors.Join(errs...)
+}
+
+func (r *revocationChecker) checkOCSP(server string, cert, issuer *x509.Certificate) (revocationStatus, error) {
+	request, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return revocationStatus{}, fmt.Errorf("creating request failed: %w", err)
+	}
+
+	resp, err := r.client.Post(server, "application/ocsp-request", bytes.NewReader(request))
+	if err != nil {
+		return revocationStatus{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return revocationStatus{}, fmt.Errorf("unexpected status %q", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return revocationStatus{}, err
+	}
+
+	response, err := ocsp.ParseResponseForCert(body, cert, issuer)
+	if err != nil {
+		return revocationStatus{}, fmt.Errorf("parsing response failed: %w", err)
+	}
+	return revocationStatus{method: "ocsp", status: response.Status, revokedAt: response.RevokedAt}, nil
+}
+
+func (r *revocationChecker) checkCRL(location string, cert, issuer *x509.Certificate) (revocationStatus, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return revocationStatus{}, errors.New("unsupported distribution point")
+	}
+
+	crl, err := r.revocationList(location, issuer)
+	if err != nil {
+		return revocationStatus{}, err
+	}
+	for _, entry := range crl.RevokedCertificateEntries {
+		if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+			return revocationStatus{method: "crl", status: ocsp.Revoked, revokedAt: entry.RevocationTime}, nil
+		}
+	}
+	return revocationStatus{method: "crl", status: ocsp.Good}, nil
+}
+
+// revocationList returns the cached CRL for the location or downloads it if
+// the cached list is outdated
+func (r *revocationChecker) revocationList(location string, issuer *x509.Certificate) (*x509.RevocationList, error) {
+	now := time.Now()
+
+	r.Lock()
+	crl, found := r.crls[location]
+	r.Unlock()
+	if found && now.Before(crlExpiry(crl)) {
+		return crl, nil
+	}
+
+	resp, err := r.client.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q", resp.Status)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lists are usually DER encoded but some servers provide PEM
+	if block, _ := pem.Decode(content); block != nil {
+		content = block.Bytes
+	}
+	crl, err = x509.ParseRevocationList(content)
+	if err != nil {
+		return nil, fmt.Errorf("parsing list failed: %w", err)
+	}
+	if err := crl.CheckSignatureFrom(issuer); err != nil {
+		return nil, fmt.Errorf("verifying list failed: %w", err)
+	}
+
+	r.Lock()
+	r.crls[location] = crl
+	r.Unlock()
+
+	return crl, nil
+}
+
+func crlExpiry(crl *x509.RevocationList) time.Time {
+	if crl.NextUpdate.IsZero() {
+		return crl.ThisUpdate.Add(defaultCRLCacheDuration)
+	}
+	return crl.NextUpdate
+}
+
+// findIssuer returns the certificate that issued the given certificate from
+// the list of candidates or nil if the issuer is unknown
+func findIssuer(cert *x509.Certificate, candidates []*x509.Certificate) *x509.Certificate {
+	for _, candidate := range candidates {
+		if !bytes.Equal(cert.RawIssuer, candidate.RawSubject) {
+			continue
+		}
+		if cert.CheckSignatureFrom(candidate) == nil {
+			return candidate
+		}
+	}
+	return nil
+}
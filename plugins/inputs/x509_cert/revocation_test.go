@@ -0,0 +1,252 @@
+package x509_cert
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// testCA is a certificate authority issuing certificates for testing the
+// revocation checks
+type testCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) issue(t *testing.T, serial int64, ocspServer, crlServer string) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "leaf"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if ocspServer != "" {
+		tmpl.OCSPServer = []string{ocspServer}
+	}
+	if crlServer != "" {
+		tmpl.CRLDistributionPoints = []string{crlServer}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, key.Public(), ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func (ca *testCA) crl(t *testing.T, revoked ...int64) []byte {
+	t.Helper()
+
+	entries := make([]x509.RevocationListEntry, 0, len(revoked))
+	for _, serial := range revoked {
+		entries = append(entries, x509.RevocationListEntry{
+			SerialNumber:   big.NewInt(serial),
+			RevocationTime: time.Unix(1700000000, 0),
+		})
+	}
+	tmpl := &x509.RevocationList{
+		Number:                    big.NewInt(1),
+		ThisUpdate:                time.Now().Add(-time.Minute),
+		NextUpdate:                time.Now().Add(time.Hour),
+		RevokedCertificateEntries: entries,
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, tmpl, ca.cert, ca.key)
+	require.NoError(t, err)
+	return der
+}
+
+func TestRevocationCRL(t *testing.T) {
+	ca := newTestCA(t)
+
+	var requests int
+	crl := ca.crl(t, 42)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		if _, err := w.Write(crl); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer ts.Close()
+
+	checker := newRevocationChecker(5 * time.Second)
+
+	status, err := checker.check(ca.issue(t, 42, "", ts.URL), ca.cert)
+	require.NoError(t, err)
+	require.Equal(t, "crl", status.method)
+	require.Equal(t, ocsp.Revoked, status.status)
+	require.Equal(t, int64(1700000000), status.revokedAt.Unix())
+
+	status, err = checker.check(ca.issue(t, 43, "", ts.URL), ca.cert)
+	require.NoError(t, err)
+	require.Equal(t, "crl", status.method)
+	require.Equal(t, ocsp.Good, status.status)
+
+	// The list must be cached until its next update
+	require.Equal(t, 1, requests)
+}
+
+func TestRevocationCRLInvalidSignature(t *testing.T) {
+	ca := newTestCA(t)
+	other := newTestCA(t)
+
+	crl := other.crl(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if _, err := w.Write(crl); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer ts.Close()
+
+	checker := newRevocationChecker(5 * time.Second)
+	status, err := checker.check(ca.issue(t, 42, "", ts.URL), ca.cert)
+	require.ErrorContains(t, err, "verifying list failed")
+	require.Equal(t, ocsp.Unknown, status.status)
+}
+
+func TestRevocationOCSP(t *testing.T) {
+	ca := newTestCA(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		req, err := ocsp.ParseRequest(body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		status := ocsp.Good
+		if req.SerialNumber.Int64() == 42 {
+			status = ocsp.Revoked
+		}
+		resp, err := ocsp.CreateResponse(ca.cert, ca.cert, ocsp.Response{
+			Status:       status,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+			RevokedAt:    time.Unix(1700000000, 0),
+		}, ca.key)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer ts.Close()
+
+	checker := newRevocationChecker(5 * time.Second)
+
+	status, err := checker.check(ca.issue(t, 42, ts.URL, ""), ca.cert)
+	require.NoError(t, err)
+	require.Equal(t, "ocsp", status.method)
+	require.Equal(t, ocsp.Revoked, status.status)
+
+	status, err = checker.check(ca.issue(t, 43, ts.URL, ""), ca.cert)
+	require.NoError(t, err)
+	require.Equal(t, "ocsp", status.method)
+	require.Equal(t, ocsp.Good, status.status)
+}
+
+func TestRevocationNoSources(t *testing.T) {
+	ca := newTestCA(t)
+
+	checker := newRevocationChecker(5 * time.Second)
+	status, err := checker.check(ca.issue(t, 42, "", ""), ca.cert)
+	require.NoError(t, err)
+	require.Equal(t, "none", status.method)
+	require.Equal(t, ocsp.Unknown, status.status)
+}
+
+func TestGatherRevocation(t *testing.T) {
+	ca := newTestCA(t)
+
+	crl := ca.crl(t, 42)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if _, err := w.Write(crl); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer ts.Close()
+
+	// Write the chain of the revoked certificate
+	leaf := ca.issue(t, 42, "", ts.URL)
+	fn := filepath.Join(t.TempDir(), "cert.pem")
+	content := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw})
+	content = append(content, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})...)
+	require.NoError(t, os.WriteFile(fn, content, 0600))
+
+	plugin := &X509Cert{
+		Sources:         []string{fn},
+		CheckRevocation: true,
+		Timeout:         config.Duration(5 * time.Second),
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 2)
+	for _, m := range metrics {
+		class, _ := m.GetTag("type")
+		status, hasStatus := m.GetTag("revocation_status")
+		if class == "root" {
+			require.False(t, hasStatus)
+			continue
+		}
+		require.Equal(t, "revoked", status)
+		code, _ := m.GetField("revocation_status_code")
+		require.Equal(t, int64(ocsp.Revoked), code)
+		revokedAt, _ := m.GetField("revocation_revoked_at")
+		require.Equal(t, int64(1700000000), revokedAt)
+	}
+}
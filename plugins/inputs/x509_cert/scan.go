@@ -0,0 +1,59 @@
+package x509_cert
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Maximum number of addresses per network to prevent accidentally scanning
+// huge ranges like IPv6 /64 networks
+const maxScanAddresses = 65536
+
+// expandNetworks returns a TCP location for each address of the given
+// networks and each of the given ports. Networks can be specified as CIDR
+// ranges or single addresses.
+func expandNetworks(networks []string, ports []uint16) ([]*url.URL, error) {
+	var locations []*url.URL
+	for _, network := range networks {
+		addresses, err := networkAddresses(network)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addresses {
+			for _, port := range ports {
+				host := net.JoinHostPort(addr.String(), strconv.Itoa(int(port)))
+				locations = append(locations, &url.URL{Scheme: "tcp", Host: host})
+			}
+		}
+	}
+	return locations, nil
+}
+
+func networkAddresses(network string) ([]netip.Addr, error) {
+	if !strings.Contains(network, "/") {
+		addr, err := netip.ParseAddr(network)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", network, err)
+		}
+		return []netip.Addr{addr}, nil
+	}
+
+	prefix, err := netip.ParsePrefix(network)
+	if err != nil {
+		return nil, fmt.Errorf("invalid network %q: %w", network, err)
+	}
+	prefix = prefix.Masked()
+	if hostBits := prefix.Addr().BitLen() - prefix.Bits(); hostBits > 16 {
+		return nil, fmt.Errorf("network %q exceeds the maximum of %d addresses", network, maxScanAddresses)
+	}
+
+	var addresses []netip.Addr
+	for addr := prefix.Addr(); prefix.Contains(addr); addr = addr.Next() {
+		addresses = append(addresses, addr)
+	}
+	return addresses, nil
+}
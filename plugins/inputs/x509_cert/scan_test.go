@@ -0,0 +1,132 @@
+package x509_cert
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestExpandNetworks(t *testing.T) {
+	tests := []struct {
+		name     string
+		networks []string
+		ports    []uint16
+		expected []string
+	}{
+		{
+			name:     "single address",
+			networks: []string{"192.168.1.10"},
+			ports:    []uint16{443},
+			expected: []string{"tcp://192.168.1.10:443"},
+		},
+		{
+			name:     "cidr with multiple ports",
+			networks: []string{"10.0.0.1/31"},
+			ports:    []uint16{443, 8443},
+			expected: []string{
+				"tcp://10.0.0.0:443",
+				"tcp://10.0.0.0:8443",
+				"tcp://10.0.0.1:443",
+				"tcp://10.0.0.1:8443",
+			},
+		},
+		{
+			name:     "ipv6",
+			networks: []string{"fd00::/127"},
+			ports:    []uint16{443},
+			expected: []string{"tcp://[fd00::]:443", "tcp://[fd00::1]:443"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locations, err := expandNetworks(tt.networks, tt.ports)
+			require.NoError(t, err)
+
+			actual := make([]string, 0, len(locations))
+			for _, u := range locations {
+				actual = append(actual, u.String())
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestExpandNetworksInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		network  string
+		expected string
+	}{
+		{
+			name:     "invalid address",
+			network:  "10.0.0.256",
+			expected: `invalid address "10.0.0.256"`,
+		},
+		{
+			name:     "invalid network",
+			network:  "10.0.0.0/33",
+			expected: `invalid network "10.0.0.0/33"`,
+		},
+		{
+			name:     "network too large",
+			network:  "10.0.0.0/8",
+			expected: "exceeds the maximum of 65536 addresses",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := expandNetworks([]string{tt.network}, []uint16{443})
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestScanPortsRequired(t *testing.T) {
+	plugin := &X509Cert{
+		ScanNetworks: []string{"127.0.0.1/32"},
+		Log:          testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "'scan_ports' required")
+}
+
+func TestGatherScan(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	port, err := strconv.ParseUint(u.Port(), 10, 16)
+	require.NoError(t, err)
+
+	// Scan the server port and a second address not serving TLS which must be
+	// skipped without errors
+	plugin := &X509Cert{
+		ScanNetworks:       []string{"127.0.0.1", "127.0.0.2"},
+		ScanPorts:          []uint16{uint16(port)},
+		Timeout:            config.Duration(time.Second),
+		MaxParallelSources: 2,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.True(t, acc.HasTag("x509_cert", "source"))
+	for _, m := range acc.GetTelegrafMetrics() {
+		source, _ := m.GetTag("source")
+		require.Equal(t, "tcp://127.0.0.1:"+u.Port(), source)
+	}
+}
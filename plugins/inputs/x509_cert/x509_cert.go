@@ -18,7 +18,9 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pion/dtls/v2"
@@ -39,21 +41,26 @@ var sampleConfig string
 var reDriveLetter = regexp.MustCompile(`^/([a-zA-Z]:/)`)
 
 type X509Cert struct {
-	Sources          []string        `toml:"sources"`
-	Timeout          config.Duration `toml:"timeout"`
-	ServerName       string          `toml:"server_name"`
-	Password         config.Secret   `toml:"password"`
-	ExcludeRootCerts bool            `toml:"exclude_root_certs"`
-	PadSerial        bool            `toml:"pad_serial_with_zeroes"`
-	Log              telegraf.Logger `toml:"-"`
+	Sources            []string        `toml:"sources"`
+	ScanNetworks       []string        `toml:"scan_networks"`
+	ScanPorts          []uint16        `toml:"scan_ports"`
+	Timeout            config.Duration `toml:"timeout"`
+	ServerName         string          `toml:"server_name"`
+	Password           config.Secret   `toml:"password"`
+	ExcludeRootCerts   bool            `toml:"exclude_root_certs"`
+	PadSerial          bool            `toml:"pad_serial_with_zeroes"`
+	ChainExpiry        bool            `toml:"chain_expiry"`
+	CheckRevocation    bool            `toml:"check_revocation"`
+	MaxParallelSources int             `toml:"max_parallel_sources"`
+	Log                telegraf.Logger `toml:"-"`
 	common_tls.ClientConfig
 	proxy.TCPProxy
 
-	tlsCfg    *tls.Config
-	locations []*url.URL
-	globpaths []*globpath.GlobPath
-
-	classification map[string]string
+	tlsCfg        *tls.Config
+	locations     []*url.URL
+	scanLocations []*url.URL
+	globpaths     []*globpath.GlobPath
+	revocation    *revocationChecker
 }
 
 func (*X509Cert) SampleConfig() string {
@@ -62,9 +69,15 @@ func (*X509Cert) SampleConfig() string {
 
 func (c *X509Cert) Init() error {
 	// Check if we do have at least one source
-	if len(c.Sources) == 0 {
+	if len(c.Sources) == 0 && len(c.ScanNetworks) == 0 {
 		return errors.New("no source configured")
 	}
+	if len(c.ScanNetworks) > 0 && len(c.ScanPorts) == 0 {
+		return errors.New("'scan_ports' required when scanning networks")
+	}
+	if c.MaxParallelSources < 1 {
+		c.MaxParallelSources = 1
+	}
 
 	// Check the server name and transfer it if necessary
 	if c.ClientConfig.ServerName != "" && c.ServerName != "" {
@@ -79,6 +92,17 @@ func (c *X509Cert) Init() error {
 		return err
 	}
 
+	// Expand the networks to scan
+	locations, err := expandNetworks(c.ScanNetworks, c.ScanPorts)
+	if err != nil {
+		return err
+	}
+	c.scanLocations = locations
+
+	if c.CheckRevocation {
+		c.revocation = newRevocationChecker(time.Duration(c.Timeout))
+	}
+
 	// Create the TLS configuration
 	tlsCfg, err := c.ClientConfig.TLSConfig()
 	if err != nil {
@@ -95,124 +119,163 @@ func (c *X509Cert) Init() error {
 func (c *X509Cert) Gather(acc telegraf.Accumulator) error {
 	now := time.Now()
 
+	// Limit the number of sources processed in parallel
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, c.MaxParallelSources)
+	gather := func(location *url.URL, scanned bool) {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			c.gatherLocation(acc, location, now, scanned)
+		}()
+	}
+
 	collectedUrls := append(c.locations, c.collectCertURLs()...)
 	for _, location := range collectedUrls {
-		certs, ocspresp, err := c.getCert(location, time.Duration(c.Timeout))
-		if err != nil {
-			acc.AddError(fmt.Errorf("cannot get SSL cert %q: %w", location, err))
+		gather(location, false)
+	}
+	for _, location := range c.scanLocations {
+		gather(location, true)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (c *X509Cert) gatherLocation(acc telegraf.Accumulator, location *url.URL, now time.Time, scanned bool) {
+	certs, ocspresp, err := c.getCert(location, time.Duration(c.Timeout))
+	if err != nil {
+		// Most addresses of scanned networks usually do not serve TLS so
+		// do not flood the log with errors
+		if scanned {
+			c.Log.Debugf("Cannot get SSL cert %q: %v", location, err)
+			return
 		}
+		acc.AddError(fmt.Errorf("cannot get SSL cert %q: %w", location, err))
+	}
 
-		// Add all returned certs to the pool of intermediates except for
-		// the leaf node which has to come first
-		intermediates := x509.NewCertPool()
-		if len(certs) > 1 {
-			for _, c := range certs[1:] {
-				intermediates.AddCert(c)
-			}
+	// Add all returned certs to the pool of intermediates except for
+	// the leaf node which has to come first
+	intermediates := x509.NewCertPool()
+	if len(certs) > 1 {
+		for _, c := range certs[1:] {
+			intermediates.AddCert(c)
 		}
+	}
 
-		dnsName := c.serverName(location)
-		results := make([]error, 0, len(certs))
-		c.classification = make(map[string]string)
-		for _, cert := range certs {
-			// The first certificate is the leaf/end-entity certificate which
-			// needs DNS name validation against the URL hostname.
-			opts := x509.VerifyOptions{
-				Intermediates: intermediates,
-				KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
-				Roots:         c.tlsCfg.RootCAs,
-				DNSName:       dnsName,
-			}
-			// Reset DNS name to only use it for the leaf node
-			dnsName = ""
+	dnsName := c.serverName(location)
+	results := make([]error, 0, len(certs))
+	classification := make(map[string]string)
+	var leafChains [][]*x509.Certificate
+	for i, cert := range certs {
+		// The first certificate is the leaf/end-entity certificate which
+		// needs DNS name validation against the URL hostname.
+		opts := x509.VerifyOptions{
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+			Roots:         c.tlsCfg.RootCAs,
+			DNSName:       dnsName,
+		}
+		// Reset DNS name to only use it for the leaf node
+		dnsName = ""
 
-			// Do the processing
-			results = append(results, c.processCertificate(cert, opts))
+		// Do the processing
+		chains, err := c.processCertificate(cert, opts, classification)
+		if i == 0 {
+			leafChains = chains
 		}
+		results = append(results, err)
+	}
 
-		for i, cert := range certs {
-			fields := getFields(cert, now)
-			tags := c.getTags(cert, location.String())
+	for i, cert := range certs {
+		fields := getFields(cert, now)
+		tags := c.getTags(cert, location.String())
 
-			// Extract the verification result
-			err := results[i]
-			if err == nil {
-				tags["verification"] = "valid"
-				fields["verification_code"] = 0
-			} else {
-				tags["verification"] = "invalid"
-				fields["verification_code"] = 1
-				fields["verification_error"] = err.Error()
-			}
-			// OCSPResponse only for leaf cert
-			if i == 0 && ocspresp != nil && len(*ocspresp) > 0 {
-				var ocspissuer *x509.Certificate
-				for _, chaincert := range certs[1:] {
-					if cert.Issuer.CommonName == chaincert.Subject.CommonName &&
-						cert.Issuer.SerialNumber == chaincert.Subject.SerialNumber {
-						ocspissuer = chaincert
-						break
-					}
-				}
-				resp, err := ocsp.ParseResponse(*ocspresp, ocspissuer)
-				if err != nil {
-					if ocspissuer == nil {
-						tags["ocsp_stapled"] = "no"
-						fields["ocsp_error"] = err.Error()
-					} else {
-						ocspissuer = nil // retry parsing w/out issuer cert
-						resp, err = ocsp.ParseResponse(*ocspresp, ocspissuer)
-					}
+		// Extract the verification result
+		err := results[i]
+		if err == nil {
+			tags["verification"] = "valid"
+			fields["verification_code"] = 0
+		} else {
+			tags["verification"] = "invalid"
+			fields["verification_code"] = 1
+			fields["verification_error"] = err.Error()
+		}
+		// OCSPResponse only for leaf cert
+		if i == 0 && ocspresp != nil && len(*ocspresp) > 0 {
+			var ocspissuer *x509.Certificate
+			for _, chaincert := range certs[1:] {
+				if cert.Issuer.CommonName == chaincert.Subject.CommonName &&
+					cert.Issuer.SerialNumber == chaincert.Subject.SerialNumber {
+					ocspissuer = chaincert
+					break
 				}
-				if err != nil {
+			}
+			resp, err := ocsp.ParseResponse(*ocspresp, ocspissuer)
+			if err != nil {
+				if ocspissuer == nil {
 					tags["ocsp_stapled"] = "no"
 					fields["ocsp_error"] = err.Error()
 				} else {
-					tags["ocsp_stapled"] = "yes"
-					if ocspissuer != nil {
-						tags["ocsp_verified"] = "yes"
-					} else {
-						tags["ocsp_verified"] = "no"
-					}
-					// resp.Status: 0=Good 1=Revoked 2=Unknown
-					fields["ocsp_status_code"] = resp.Status
-					switch resp.Status {
-					case 0:
-						tags["ocsp_status"] = "good"
-					case 1:
-						tags["ocsp_status"] = "revoked"
-						// Status=Good: revoked_at always = -62135596800
-						fields["ocsp_revoked_at"] = resp.RevokedAt.Unix()
-					default:
-						tags["ocsp_status"] = "unknown"
-					}
-					fields["ocsp_produced_at"] = resp.ProducedAt.Unix()
-					fields["ocsp_this_update"] = resp.ThisUpdate.Unix()
-					fields["ocsp_next_update"] = resp.NextUpdate.Unix()
+					ocspissuer = nil // retry parsing w/out issuer cert
+					resp, err = ocsp.ParseResponse(*ocspresp, ocspissuer)
 				}
-			} else {
-				tags["ocsp_stapled"] = "no"
 			}
-
-			// Determine the classification
-			sig := hex.EncodeToString(cert.Signature)
-			if class, found := c.classification[sig]; found {
-				tags["type"] = class
+			if err != nil {
+				tags["ocsp_stapled"] = "no"
+				fields["ocsp_error"] = err.Error()
 			} else {
-				tags["type"] = "leaf"
+				tags["ocsp_stapled"] = "yes"
+				if ocspissuer != nil {
+					tags["ocsp_verified"] = "yes"
+				} else {
+					tags["ocsp_verified"] = "no"
+				}
+				// resp.Status: 0=Good 1=Revoked 2=Unknown
+				fields["ocsp_status_code"] = resp.Status
+				switch resp.Status {
+				case 0:
+					tags["ocsp_status"] = "good"
+				case 1:
+					tags["ocsp_status"] = "revoked"
+					// Status=Good: revoked_at always = -62135596800
+					fields["ocsp_revoked_at"] = resp.RevokedAt.Unix()
+				default:
+					tags["ocsp_status"] = "unknown"
+				}
+				fields["ocsp_produced_at"] = resp.ProducedAt.Unix()
+				fields["ocsp_this_update"] = resp.ThisUpdate.Unix()
+				fields["ocsp_next_update"] = resp.NextUpdate.Unix()
 			}
+		} else {
+			tags["ocsp_stapled"] = "no"
+		}
 
-			acc.AddFields("x509_cert", fields, tags)
-			if c.ExcludeRootCerts {
-				break
-			}
+		// Determine the classification
+		sig := hex.EncodeToString(cert.Signature)
+		if class, found := classification[sig]; found {
+			tags["type"] = class
+		} else {
+			tags["type"] = "leaf"
 		}
-	}
 
-	return nil
+		if i == 0 && c.ChainExpiry {
+			addChainExpiry(fields, certs, leafChains, classification, now)
+		}
+		if c.revocation != nil && tags["type"] != "root" {
+			c.addRevocationStatus(fields, tags, cert, certs, leafChains)
+		}
+
+		acc.AddFields("x509_cert", fields, tags)
+		if c.ExcludeRootCerts {
+			break
+		}
+	}
 }
 
-func (c *X509Cert) processCertificate(certificate *x509.Certificate, opts x509.VerifyOptions) error {
+func (c *X509Cert) processCertificate(certificate *x509.Certificate, opts x509.VerifyOptions, classification map[string]string) ([][]*x509.Certificate, error) {
 	chains, err := certificate.Verify(opts)
 	if err != nil {
 		c.Log.Debugf("Invalid certificate %v", c.getSerialNumberString(certificate))
@@ -234,7 +297,7 @@ func (c *X509Cert) processCertificate(certificate *x509.Certificate, opts x509.V
 	rootErr := certificate.CheckSignature(certificate.SignatureAlgorithm, certificate.RawTBSCertificate, certificate.Signature)
 	if rootErr == nil {
 		sig := hex.EncodeToString(certificate.Signature)
-		c.classification[sig] = "root"
+		classification[sig] = "root"
 	}
 
 	// Identify intermediate certificates
@@ -246,14 +309,14 @@ func (c *X509Cert) processCertificate(certificate *x509.Certificate, opts x509.V
 		for _, cert := range chain[1:] {
 			// Never change a classification if we already have one
 			sig := hex.EncodeToString(cert.Signature)
-			if _, found := c.classification[sig]; found {
+			if _, found := classification[sig]; found {
 				continue
 			}
 
 			// We found an intermediate certificate which is not a CA. This
 			// should never happen actually.
 			if !cert.IsCA {
-				c.classification[sig] = "unknown"
+				classification[sig] = "unknown"
 				continue
 			}
 
@@ -262,14 +325,79 @@ func (c *X509Cert) processCertificate(certificate *x509.Certificate, opts x509.V
 			// i.e. you can verify the certificate with its own public key.
 			rootErr := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature)
 			if rootErr != nil {
-				c.classification[sig] = "intermediate"
+				classification[sig] = "intermediate"
 			} else {
-				c.classification[sig] = "root"
+				classification[sig] = "root"
 			}
 		}
 	}
 
-	return err
+	return chains, err
+}
+
+// addChainExpiry adds the earliest expiry of the intermediate certificates and
+// the expiry of the root certificate to the leaf fields. The verified chain
+// is used if available as the root might not be sent by the server.
+func addChainExpiry(fields map[string]interface{}, certs []*x509.Certificate, chains [][]*x509.Certificate, classification map[string]string, now time.Time) {
+	chain := certs
+	if len(chains) > 0 {
+		chain = chains[0]
+	}
+	if len(chain) < 2 {
+		return
+	}
+
+	var intermediateExpiry, rootExpiry *int
+	for _, cert := range chain[1:] {
+		expiry := int(cert.NotAfter.Sub(now).Seconds())
+		switch classification[hex.EncodeToString(cert.Signature)] {
+		case "intermediate":
+			if intermediateExpiry == nil || expiry < *intermediateExpiry {
+				intermediateExpiry = &expiry
+			}
+		case "root":
+			rootExpiry = &expiry
+		}
+	}
+	if intermediateExpiry != nil {
+		fields["intermediate_expiry"] = *intermediateExpiry
+	}
+	if rootExpiry != nil {
+		fields["root_expiry"] = *rootExpiry
+	}
+}
+
+// addRevocationStatus checks the revocation status of the certificate against
+// its issuer's OCSP responder or CRL
+func (c *X509Cert) addRevocationStatus(fields map[string]interface{}, tags map[string]string, cert *x509.Certificate, certs []*x509.Certificate, chains [][]*x509.Certificate) {
+	candidates := certs
+	if len(chains) > 0 {
+		candidates = slices.Concat(certs, chains[0])
+	}
+	issuer := findIssuer(cert, candidates)
+	if issuer == nil {
+		tags["revocation_method"] = "none"
+		tags["revocation_status"] = "unknown"
+		fields["revocation_status_code"] = ocsp.Unknown
+		fields["revocation_error"] = "issuer certificate not found"
+		return
+	}
+
+	status, err := c.revocation.check(cert, issuer)
+	tags["revocation_method"] = status.method
+	fields["revocation_status_code"] = status.status
+	switch status.status {
+	case ocsp.Good:
+		tags["revocation_status"] = "good"
+	case ocsp.Revoked:
+		tags["revocation_status"] = "revoked"
+		fields["revocation_revoked_at"] = status.revokedAt.Unix()
+	default:
+		tags["revocation_status"] = "unknown"
+	}
+	if err != nil {
+		fields["revocation_error"] = err.Error()
+	}
 }
 
 func (c *X509Cert) sourcesToURLs() error {
@@ -540,7 +668,8 @@ func (c *X509Cert) getSerialNumberString(cert *x509.Certificate) string {
 func init() {
 	inputs.Add("x509_cert", func() telegraf.Input {
 		return &X509Cert{
-			Timeout: config.Duration(5 * time.Second),
+			Timeout:            config.Duration(5 * time.Second),
+			MaxParallelSources: 10,
 		}
 	})
 }
@@ -298,6 +298,43 @@ func TestGatherChain(t *testing.T) {
 	}
 }
 
+func TestGatherChainExpiry(t *testing.T) {
+	// Only provide the leaf, the root must be taken from the trusted CAs
+	f, err := os.CreateTemp(t.TempDir(), "x509_cert")
+	require.NoError(t, err)
+	_, err = f.WriteString(pki.ReadServerCert())
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	sc := X509Cert{
+		Sources:     []string{f.Name()},
+		ServerName:  "localhost",
+		ChainExpiry: true,
+		ClientConfig: common_tls.ClientConfig{
+			TLSCA: pki.CACertPath(),
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, sc.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, sc.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	verification, _ := metrics[0].GetTag("verification")
+	require.Equal(t, "valid", verification)
+
+	block, _ := pem.Decode([]byte(pki.ReadCACert()))
+	ca, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	expiry, found := metrics[0].GetField("root_expiry")
+	require.True(t, found)
+	require.InDelta(t, time.Until(ca.NotAfter).Seconds(), expiry, 60)
+	require.False(t, metrics[0].HasField("intermediate_expiry"))
+}
+
 func TestGatherUDPCertIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
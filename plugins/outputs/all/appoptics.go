@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.appoptics
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/appoptics" // register plugin
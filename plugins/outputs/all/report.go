@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.report
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/report" // register plugin
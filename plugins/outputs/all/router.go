@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.router
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/router" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.timescaledb
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/timescaledb" // register plugin
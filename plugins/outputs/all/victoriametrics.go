@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.victoriametrics
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/victoriametrics" // register plugin
@@ -0,0 +1,512 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package appoptics
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/proxy"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Limits of the measurements API, see
+// https://docs.appoptics.com/api/#create-a-measurement
+const (
+	maxNameLength     = 255
+	maxTagKeyLength   = 64
+	maxTagValueLength = 255
+	maxTags           = 50
+)
+
+var (
+	reInvalidName     = regexp.MustCompile(`[^-.:_\w]`)
+	reInvalidTagValue = regexp.MustCompile(`[^-.:_?\\/\w ]`)
+)
+
+type AppOptics struct {
+	Token           config.Secret   `toml:"token"`
+	URL             string          `toml:"url"`
+	Timeout         config.Duration `toml:"timeout"`
+	ContentEncoding string          `toml:"content_encoding"`
+	BatchSize       int             `toml:"batch_size"`
+	Log             telegraf.Logger `toml:"-"`
+	proxy.HTTPProxy
+
+	client  *http.Client
+	encoder internal.ContentEncoder
+}
+
+type payload struct {
+	Measurements []*measurement `json:"measurements"`
+}
+
+type measurement struct {
+	Name  string            `json:"name"`
+	Value float64           `json:"value"`
+	Time  int64             `json:"time"`
+	Tags  map[string]string `json:"tags"`
+}
+
+// batch holds the measurements of a set of metrics sent in one request
+type batch struct {
+	indices      []int
+	measurements []*measurement
+	owners       []int
+}
+
+// apiError is returned for requests not accepted by the API, the failures
+// contain the per-measurement errors reported in the response if any
+type apiError struct {
+	StatusCode int
+	Failures   []failure
+	Body       string
+}
+
+type failure struct {
+	path   string
+	reason string
+}
+
+func (e *apiError) Error() string {
+	if len(e.Failures) == 0 {
+		return fmt.Sprintf("received status code %d: %s", e.StatusCode, e.Body)
+	}
+	msgs := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		msgs = append(msgs, f.String())
+	}
+	return fmt.Sprintf("received status code %d: %s", e.StatusCode, strings.Join(msgs, "; "))
+}
+
+// retryable returns true if the request should be repeated later on, this
+// includes authentication errors to not drop metrics of a misconfigured token
+func (e *apiError) retryable() bool {
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	}
+	return e.StatusCode >= 500
+}
+
+func (f failure) String() string {
+	if f.path == "" {
+		return f.reason
+	}
+	return f.path + ": " + f.reason
+}
+
+// measurement returns the index of the measurement in the request the
+// failure refers to or -1 if the failure is not specific to a measurement
+func (f failure) measurement() int {
+	parts := strings.Split(f.path, ".")
+	for i := 0; i < len(parts)-1; i++ {
+		if parts[i] != "measurements" {
+			continue
+		}
+		if idx, err := strconv.Atoi(parts[i+1]); err == nil {
+			return idx
+		}
+	}
+	return -1
+}
+
+func (*AppOptics) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *AppOptics) Init() error {
+	if a.Token.Empty() {
+		return errors.New("token required")
+	}
+	if a.BatchSize < 1 {
+		return fmt.Errorf("invalid batch size %d", a.BatchSize)
+	}
+
+	switch a.ContentEncoding {
+	case "", "identity":
+		a.ContentEncoding = "identity"
+	case "gzip":
+	default:
+		return fmt.Errorf("invalid content encoding %q", a.ContentEncoding)
+	}
+
+	encoder, err := internal.NewContentEncoder(a.ContentEncoding)
+	if err != nil {
+		return fmt.Errorf("creating encoder failed: %w", err)
+	}
+	a.encoder = encoder
+
+	return nil
+}
+
+func (a *AppOptics) Connect() error {
+	proxyFunc, err := a.Proxy()
+	if err != nil {
+		return err
+	}
+
+	a.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy: proxyFunc,
+		},
+		Timeout: time.Duration(a.Timeout),
+	}
+	return nil
+}
+
+func (*AppOptics) Close() error {
+	return nil
+}
+
+func (a *AppOptics) Write(metrics []telegraf.Metric) error {
+	var writeErr internal.PartialWriteError
+
+	// Convert the metrics and batch them by the number of measurements,
+	// metrics that cannot be represented are rejected right away
+	var batches []*batch
+	current := &batch{}
+	for i, m := range metrics {
+		ms, err := convert(m)
+		if err != nil {
+			a.Log.Errorf("Rejecting metric %q: %v", m.Name(), err)
+			writeErr.MetricsReject = append(writeErr.MetricsReject, i)
+			writeErr.MetricsRejectErrors = append(writeErr.MetricsRejectErrors, err)
+			continue
+		}
+		if len(ms) == 0 {
+			writeErr.MetricsAccept = append(writeErr.MetricsAccept, i)
+			continue
+		}
+
+		if len(current.indices) > 0 && len(current.measurements)+len(ms) > a.BatchSize {
+			batches = append(batches, current)
+			current = &batch{}
+		}
+		current.add(i, ms)
+	}
+	if len(current.indices) > 0 {
+		batches = append(batches, current)
+	}
+
+	for _, b := range batches {
+		if err := a.writeBatch(b, &writeErr, true); err != nil {
+			// Keep the remaining metrics for the next write as the service
+			// is not able to accept data at the moment
+			writeErr.Err = err
+			break
+		}
+	}
+
+	if writeErr.Err == nil && len(writeErr.MetricsReject) > 0 {
+		writeErr.Err = fmt.Errorf("rejected %d metric(s)", len(writeErr.MetricsReject))
+	}
+	if writeErr.Err != nil {
+		return &writeErr
+	}
+	return nil
+}
+
+// writeBatch sends the batch and records the accepted and rejected metrics,
+// an error is returned if the metrics should be retried later on
+func (a *AppOptics) writeBatch(b *batch, writeErr *internal.PartialWriteError, resend bool) error {
+	err := a.send(b.measurements)
+	if err == nil {
+		writeErr.MetricsAccept = append(writeErr.MetricsAccept, b.indices...)
+		return nil
+	}
+
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.retryable() {
+		return err
+	}
+
+	// Split batches rejected for their size until they are accepted
+	if apiErr.StatusCode == http.StatusRequestEntityTooLarge && len(b.indices) > 1 {
+		first, second := b.split()
+		a.Log.Debugf("Request too large, splitting batch of %d metrics", len(b.indices))
+		if err := a.writeBatch(first, writeErr, resend); err != nil {
+			return err
+		}
+		return a.writeBatch(second, writeErr, resend)
+	}
+
+	// Only reject the metrics of the failed measurements if the API tells us
+	// which ones failed and resend the remaining metrics
+	rejected := make(map[int]bool)
+	for _, f := range apiErr.Failures {
+		if idx := f.measurement(); idx >= 0 && idx < len(b.owners) {
+			rejected[b.owners[idx]] = true
+		}
+	}
+	if !resend || len(rejected) == 0 || len(rejected) == len(b.indices) {
+		a.Log.Errorf("Rejecting %d metric(s): %v", len(b.indices), err)
+		writeErr.MetricsReject = append(writeErr.MetricsReject, b.indices...)
+		for range b.indices {
+			writeErr.MetricsRejectErrors = append(writeErr.MetricsRejectErrors, err)
+		}
+		return nil
+	}
+
+	a.Log.Errorf("Rejecting %d metric(s): %v", len(rejected), err)
+	remaining := &batch{}
+	for i, idx := range b.indices {
+		if rejected[idx] {
+			writeErr.MetricsReject = append(writeErr.MetricsReject, idx)
+			writeErr.MetricsRejectErrors = append(writeErr.MetricsRejectErrors, err)
+			continue
+		}
+		remaining.add(idx, b.measurementsOf(i))
+	}
+	return a.writeBatch(remaining, writeErr, false)
+}
+
+func (a *AppOptics) send(measurements []*measurement) error {
+	body, err := json.Marshal(payload{Measurements: measurements})
+	if err != nil {
+		return fmt.Errorf("serializing measurements failed: %w", err)
+	}
+	body, err = a.encoder.Encode(body)
+	if err != nil {
+		return fmt.Errorf("encoding request failed: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, a.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", internal.ProductToken())
+	if a.ContentEncoding == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	token, err := a.Token.Get()
+	if err != nil {
+		return fmt.Errorf("getting token failed: %w", err)
+	}
+	req.SetBasicAuth(token.String(), "")
+	token.Destroy()
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	//nolint:errcheck // the body is only used for error reporting
+	buf, _ := io.ReadAll(resp.Body)
+	failures := parseFailures(buf)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		// The API accepts the request even if some of the measurements are
+		// filtered or invalid, so at least let the user know
+		for _, f := range failures {
+			a.Log.Warnf("Measurement not accepted: %v", f)
+		}
+		return nil
+	}
+
+	return &apiError{
+		StatusCode: resp.StatusCode,
+		Failures:   failures,
+		Body:       strings.TrimSpace(string(buf)),
+	}
+}
+
+// parseFailures extracts the errors from a response body, those are either
+// reported as a list of parameter errors like
+//
+//	{"errors": [{"param": "name", "value": "foo bar", "reason": "invalid"}]}
+//
+// or as a tree of parameters like
+//
+//	{"errors": {"params": {"measurements": {"0": {"name": ["is invalid"]}}}}}
+func parseFailures(buf []byte) []failure {
+	var resp struct {
+		Errors json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(buf, &resp); err != nil || len(resp.Errors) == 0 {
+		return nil
+	}
+
+	var list []struct {
+		Param  string          `json:"param"`
+		Value  json.RawMessage `json:"value"`
+		Reason string          `json:"reason"`
+	}
+	if err := json.Unmarshal(resp.Errors, &list); err == nil {
+		failures := make([]failure, 0, len(list))
+		for _, e := range list {
+			reason := e.Reason
+			if len(e.Value) > 0 {
+				reason += " (value " + string(e.Value) + ")"
+			}
+			failures = append(failures, failure{path: e.Param, reason: reason})
+		}
+		return failures
+	}
+
+	var tree interface{}
+	if err := json.Unmarshal(resp.Errors, &tree); err != nil {
+		return nil
+	}
+	return flatten("", tree)
+}
+
+func flatten(path string, node interface{}) []failure {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var failures []failure
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			failures = append(failures, flatten(p, v[k])...)
+		}
+		return failures
+	case []interface{}:
+		var failures []failure
+		for i, e := range v {
+			// Arrays of objects are indexed, arrays of strings are reasons
+			if _, ok := e.(string); ok {
+				failures = append(failures, flatten(path, e)...)
+				continue
+			}
+			failures = append(failures, flatten(path+"."+strconv.Itoa(i), e)...)
+		}
+		return failures
+	case string:
+		return []failure{{path: path, reason: v}}
+	}
+	return []failure{{path: path, reason: fmt.Sprintf("%v", node)}}
+}
+
+func (b *batch) add(idx int, ms []*measurement) {
+	b.indices = append(b.indices, idx)
+	b.measurements = append(b.measurements, ms...)
+	for range ms {
+		b.owners = append(b.owners, idx)
+	}
+}
+
+// measurementsOf returns the measurements of the i-th metric in the batch
+func (b *batch) measurementsOf(i int) []*measurement {
+	idx := b.indices[i]
+	var ms []*measurement
+	for j, owner := range b.owners {
+		if owner == idx {
+			ms = append(ms, b.measurements[j])
+		}
+	}
+	return ms
+}
+
+func (b *batch) split() (first, second *batch) {
+	first, second = &batch{}, &batch{}
+	half := len(b.indices) / 2
+	for i, idx := range b.indices {
+		if i < half {
+			first.add(idx, b.measurementsOf(i))
+		} else {
+			second.add(idx, b.measurementsOf(i))
+		}
+	}
+	return first, second
+}
+
+// convert creates a measurement for each numeric field of the metric
+func convert(m telegraf.Metric) ([]*measurement, error) {
+	tagList := m.TagList()
+	if len(tagList) == 0 {
+		return nil, errors.New("measurements require at least one tag")
+	}
+	if len(tagList) > maxTags {
+		return nil, fmt.Errorf("too many tags (%d > %d)", len(tagList), maxTags)
+	}
+
+	tags := make(map[string]string, len(tagList))
+	for _, tag := range tagList {
+		key := truncate(reInvalidName.ReplaceAllString(tag.Key, "_"), maxTagKeyLength)
+		tags[key] = truncate(reInvalidTagValue.ReplaceAllString(tag.Value, "_"), maxTagValueLength)
+	}
+
+	ms := make([]*measurement, 0, len(m.FieldList()))
+	for _, field := range m.FieldList() {
+		value, ok := toFloat(field.Value)
+		if !ok {
+			continue
+		}
+
+		name := m.Name()
+		if field.Key != "value" {
+			name += "." + field.Key
+		}
+		ms = append(ms, &measurement{
+			Name:  truncate(reInvalidName.ReplaceAllString(name, "_"), maxNameLength),
+			Value: value,
+			Time:  m.Time().Unix(),
+			Tags:  tags,
+		})
+	}
+	return ms, nil
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		// The payload is encoded as JSON which does not allow NaN or Inf
+		return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+func init() {
+	outputs.Add("appoptics", func() telegraf.Output {
+		return &AppOptics{
+			URL:             "https://api.appoptics.com/v1/measurements",
+			Timeout:         config.Duration(5 * time.Second),
+			ContentEncoding: "gzip",
+			BatchSize:       300,
+		}
+	})
+}
@@ -0,0 +1,399 @@
+package appoptics
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// server records the measurements of the received requests and replies
+// using the given handler
+type server struct {
+	*httptest.Server
+
+	requests [][]*measurement
+	reply    func(w http.ResponseWriter, ms []*measurement)
+	sync.Mutex
+}
+
+func newServer(t *testing.T, reply func(w http.ResponseWriter, ms []*measurement)) *server {
+	s := &server{reply: reply}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "secret" || password != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Error(err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = gz
+		}
+		var p payload
+		if err := json.NewDecoder(body).Decode(&p); err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s.Lock()
+		s.requests = append(s.requests, p.Measurements)
+		s.Unlock()
+
+		s.reply(w, p.Measurements)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newPlugin(url string) *AppOptics {
+	return &AppOptics{
+		Token:           config.NewSecret([]byte("secret")),
+		URL:             url,
+		Timeout:         config.Duration(5 * time.Second),
+		ContentEncoding: "gzip",
+		BatchSize:       300,
+		Log:             testutil.Logger{},
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *AppOptics
+		expected string
+	}{
+		{
+			name:     "missing token",
+			plugin:   &AppOptics{BatchSize: 300},
+			expected: "token required",
+		},
+		{
+			name: "invalid batch size",
+			plugin: &AppOptics{
+				Token: config.NewSecret([]byte("secret")),
+			},
+			expected: "invalid batch size 0",
+		},
+		{
+			name: "invalid encoding",
+			plugin: &AppOptics{
+				Token:           config.NewSecret([]byte("secret")),
+				BatchSize:       300,
+				ContentEncoding: "zstd",
+			},
+			expected: `invalid content encoding "zstd"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	for _, encoding := range []string{"gzip", "identity"} {
+		t.Run(encoding, func(t *testing.T) {
+			srv := newServer(t, func(w http.ResponseWriter, _ []*measurement) {
+				w.WriteHeader(http.StatusAccepted)
+			})
+
+			plugin := newPlugin(srv.URL)
+			plugin.ContentEncoding = encoding
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Connect())
+			defer plugin.Close()
+
+			metrics := []telegraf.Metric{
+				metric.New(
+					"cpu",
+					map[string]string{"host": "server 01", "cpu": "cpu#0"},
+					map[string]interface{}{
+						"usage_idle": 98.5,
+						"running":    true,
+						"state":      "ok",
+						"invalid":    math.NaN(),
+					},
+					time.Unix(1700000000, 0),
+				),
+				metric.New(
+					"temperature",
+					map[string]string{"sensor": "a/b"},
+					map[string]interface{}{"value": int64(42)},
+					time.Unix(1700000000, 0),
+				),
+			}
+			require.NoError(t, plugin.Write(metrics))
+			require.Len(t, srv.requests, 1)
+			sort.Slice(srv.requests[0], func(i, j int) bool {
+				return srv.requests[0][i].Name < srv.requests[0][j].Name
+			})
+
+			expected := [][]*measurement{
+				{
+					{
+						Name:  "cpu.running",
+						Value: 1,
+						Time:  1700000000,
+						Tags:  map[string]string{"host": "server 01", "cpu": "cpu_0"},
+					},
+					{
+						Name:  "cpu.usage_idle",
+						Value: 98.5,
+						Time:  1700000000,
+						Tags:  map[string]string{"host": "server 01", "cpu": "cpu_0"},
+					},
+					{
+						Name:  "temperature",
+						Value: 42,
+						Time:  1700000000,
+						Tags:  map[string]string{"sensor": "a/b"},
+					},
+				},
+			}
+			require.Equal(t, expected, srv.requests)
+		})
+	}
+}
+
+func TestWriteBatches(t *testing.T) {
+	srv := newServer(t, func(w http.ResponseWriter, _ []*measurement) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	plugin := newPlugin(srv.URL)
+	plugin.BatchSize = 3
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	metrics := make([]telegraf.Metric, 0, 5)
+	for i := 0; i < 5; i++ {
+		metrics = append(metrics, metric.New(
+			"test",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"x": i, "y": i},
+			time.Unix(0, 0),
+		))
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// Metrics are not split across batches
+	require.Len(t, srv.requests, 5)
+	for _, r := range srv.requests {
+		require.Len(t, r, 2)
+	}
+}
+
+func TestWriteSplitTooLarge(t *testing.T) {
+	srv := newServer(t, func(w http.ResponseWriter, ms []*measurement) {
+		if len(ms) > 2 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	plugin := newPlugin(srv.URL)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	metrics := make([]telegraf.Metric, 0, 5)
+	for i := 0; i < 5; i++ {
+		metrics = append(metrics, metric.New(
+			"test",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"value": i},
+			time.Unix(0, 0),
+		))
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// The initial request is split into [0 1] and [2 3 4] where the latter
+	// is split again into [2] and [3 4]
+	var sent []float64
+	for _, r := range srv.requests {
+		if len(r) > 2 {
+			continue
+		}
+		for _, m := range r {
+			sent = append(sent, m.Value)
+		}
+	}
+	require.Equal(t, []float64{0, 1, 2, 3, 4}, sent)
+	require.Len(t, srv.requests, 5)
+}
+
+func TestWriteRejectMeasurements(t *testing.T) {
+	srv := newServer(t, func(w http.ResponseWriter, ms []*measurement) {
+		for i, m := range ms {
+			if strings.HasPrefix(m.Name, "bad") {
+				w.WriteHeader(http.StatusBadRequest)
+				//nolint:errcheck // ignore the error in the test server
+				w.Write([]byte(`{"errors":{"params":{"measurements":{"` + strconv.Itoa(i) + `":{"value":["is too large"]}}}}}`))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	plugin := newPlugin(srv.URL)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		metric.New("good", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("bad", map[string]string{"host": "a"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("notags", map[string]string{}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+		metric.New("good", map[string]string{"host": "b"}, map[string]interface{}{"value": 4}, time.Unix(0, 0)),
+		metric.New("strings", map[string]string{"host": "a"}, map[string]interface{}{"value": "foo"}, time.Unix(0, 0)),
+	}
+	err := plugin.Write(metrics)
+
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.ElementsMatch(t, []int{0, 3, 4}, writeErr.MetricsAccept)
+	require.ElementsMatch(t, []int{1, 2}, writeErr.MetricsReject)
+	require.Len(t, writeErr.MetricsRejectErrors, 2)
+	require.ErrorContains(t, errors.Join(writeErr.MetricsRejectErrors...), "params.measurements.1.value: is too large")
+	require.ErrorContains(t, errors.Join(writeErr.MetricsRejectErrors...), "at least one tag")
+
+	// The remaining metrics are resent
+	require.Len(t, srv.requests, 2)
+	require.Len(t, srv.requests[1], 2)
+}
+
+func TestWriteRetry(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			var count int
+			srv := newServer(t, func(w http.ResponseWriter, _ []*measurement) {
+				count++
+				if count > 1 {
+					w.WriteHeader(status)
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+			})
+
+			plugin := newPlugin(srv.URL)
+			plugin.BatchSize = 1
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Connect())
+
+			metrics := make([]telegraf.Metric, 0, 3)
+			for i := 0; i < 3; i++ {
+				metrics = append(metrics, metric.New(
+					"test",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"value": i},
+					time.Unix(0, 0),
+				))
+			}
+
+			// The first metric is accepted, the writing stops at the second
+			// one and all others are kept for retrying
+			var writeErr *internal.PartialWriteError
+			require.ErrorAs(t, plugin.Write(metrics), &writeErr)
+			require.ErrorContains(t, writeErr, "received status code")
+			require.Equal(t, []int{0}, writeErr.MetricsAccept)
+			require.Empty(t, writeErr.MetricsReject)
+			require.Len(t, srv.requests, 2)
+		})
+	}
+}
+
+func TestParseFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []failure
+	}{
+		{
+			name: "list",
+			body: `{"errors":[{"param":"name","value":"foo bar","reason":"invalid characters"}]}`,
+			expected: []failure{
+				{path: "name", reason: `invalid characters (value "foo bar")`},
+			},
+		},
+		{
+			name: "tree",
+			body: `{"errors":{"params":{"measurements":{"2":{"name":["is invalid","is too long"]}}},"request":["bad"]}}`,
+			expected: []failure{
+				{path: "params.measurements.2.name", reason: "is invalid"},
+				{path: "params.measurements.2.name", reason: "is too long"},
+				{path: "request", reason: "bad"},
+			},
+		},
+		{
+			name: "indexed array",
+			body: `{"errors":{"measurements":[{"tags":["missing"]}]}}`,
+			expected: []failure{
+				{path: "measurements.0.tags", reason: "missing"},
+			},
+		},
+		{
+			name: "no errors",
+			body: `{"measurements":{"summary":{"total":1,"accepted":1}}}`,
+		},
+		{
+			name: "invalid",
+			body: `<html>Bad Gateway</html>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, parseFailures([]byte(tt.body)))
+		})
+	}
+
+	require.Equal(t, 2, failure{path: "params.measurements.2.name"}.measurement())
+	require.Equal(t, -1, failure{path: "request"}.measurement())
+}
+
+func TestConvert(t *testing.T) {
+	tags := make(map[string]string, maxTags+1)
+	for i := 0; i <= maxTags; i++ {
+		tags["tag"+strconv.Itoa(i)] = "value"
+	}
+	_, err := convert(metric.New("test", tags, map[string]interface{}{"value": 1}, time.Unix(0, 0)))
+	require.ErrorContains(t, err, "too many tags")
+
+	m := metric.New(
+		"disk io",
+		map[string]string{"path!": strings.Repeat("x", 300)},
+		map[string]interface{}{"read bytes": uint64(10)},
+		time.Unix(0, 0),
+	)
+	ms, err := convert(m)
+	require.NoError(t, err)
+	require.Len(t, ms, 1)
+	require.Equal(t, "disk_io.read_bytes", ms[0].Name)
+	require.Equal(t, map[string]string{"path_": strings.Repeat("x", maxTagValueLength)}, ms[0].Tags)
+}
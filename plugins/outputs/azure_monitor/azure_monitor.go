@@ -14,10 +14,14 @@ import (
 	"io"
 	"net/http"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
 
 	"github.com/influxdata/telegraf"
@@ -36,6 +40,7 @@ const (
 	resourceIDTemplate         = "/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachines/%s"
 	resourceIDScaleSetTemplate = "/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/%s"
 	maxRequestBodySize         = 4000000
+	tokenCheckInterval         = time.Minute
 )
 
 var invalidNameCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
@@ -64,11 +69,17 @@ type AzureMonitor struct {
 	EndpointURL          string          `toml:"endpoint_url"`
 	TimestampLimitPast   config.Duration `toml:"timestamp_limit_past"`
 	TimestampLimitFuture config.Duration `toml:"timestamp_limit_future"`
+	MaxDimensions        int             `toml:"max_dimensions"`
+	DimensionOverflow    string          `toml:"dimension_overflow"`
+	TokenRefreshMargin   config.Duration `toml:"token_refresh_margin"`
 	Log                  telegraf.Logger `toml:"-"`
 
 	url      string
 	preparer autorest.Preparer
 	client   *http.Client
+	token    tokenRefresher
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
 
 	cache    map[time.Time]map[uint64]*aggregate
 	timeFunc func() time.Time
@@ -76,6 +87,11 @@ type AzureMonitor struct {
 	MetricOutsideWindow selfstat.Stat
 }
 
+// tokenRefresher refreshes a cached token if it is about to expire
+type tokenRefresher interface {
+	EnsureFreshWithContext(ctx context.Context) error
+}
+
 func (*AzureMonitor) SampleConfig() string {
 	return sampleConfig
 }
@@ -83,12 +99,37 @@ func (*AzureMonitor) SampleConfig() string {
 func (a *AzureMonitor) Init() error {
 	a.cache = make(map[time.Time]map[uint64]*aggregate, 36)
 
+	// Azure custom metrics service supports up to 10 dimensions
+	switch {
+	case a.MaxDimensions == 0:
+		a.MaxDimensions = 10
+	case a.MaxDimensions < 0:
+		return fmt.Errorf("invalid max_dimensions %d", a.MaxDimensions)
+	}
+	switch a.DimensionOverflow {
+	case "":
+		a.DimensionOverflow = "truncate"
+	case "truncate", "split":
+	default:
+		return fmt.Errorf("invalid dimension_overflow %q", a.DimensionOverflow)
+	}
+
 	authorizer, err := auth.NewAuthorizerFromEnvironmentWithResource("https://monitoring.azure.com/")
 	if err != nil {
 		return fmt.Errorf("creating authorizer failed: %w", err)
 	}
 	a.preparer = autorest.CreatePreparer(authorizer.WithAuthorization())
 
+	// The token is cached by the authorizer and refreshed on use if it expires
+	// within the refresh window. Refresh it in the background as well to avoid
+	// delaying writes.
+	if ba, ok := authorizer.(*autorest.BearerAuthorizer); ok {
+		if spt, ok := ba.TokenProvider().(*adal.ServicePrincipalToken); ok {
+			spt.SetRefreshWithin(time.Duration(a.TokenRefreshMargin))
+			a.token = spt
+		}
+	}
+
 	return nil
 }
 
@@ -141,11 +182,42 @@ func (a *AzureMonitor) Connect() error {
 
 	a.Reset()
 
+	if a.token != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		a.cancel = cancel
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			a.refreshToken(ctx, tokenCheckInterval)
+		}()
+	}
+
 	return nil
 }
 
+// refreshToken proactively refreshes the token before it expires
+func (a *AzureMonitor) refreshToken(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.token.EnsureFreshWithContext(ctx); err != nil && ctx.Err() == nil {
+				a.Log.Warnf("Refreshing token failed: %v", err)
+			}
+		}
+	}
+}
+
 // Close shuts down an any active connections
 func (a *AzureMonitor) Close() error {
+	if a.cancel != nil {
+		a.cancel()
+		a.wg.Wait()
+	}
 	a.client.CloseIdleConnections()
 	a.client = nil
 	return nil
@@ -297,7 +369,7 @@ func (a *AzureMonitor) Write(metrics []telegraf.Metric) error {
 			continue
 		}
 
-		amm, err := translate(m, a.NamespacePrefix)
+		amms, err := translate(m, a.NamespacePrefix, a.MaxDimensions, a.DimensionOverflow == "split")
 		if err != nil {
 			a.Log.Errorf("Could not create azure metric for %q; discarding point", m.Name())
 			if writeErr.Err == nil {
@@ -307,16 +379,21 @@ func (a *AzureMonitor) Write(metrics []telegraf.Metric) error {
 			continue
 		}
 
-		id := hashIDWithTagKeysOnly(m)
-		if azm, ok := azmetrics[id]; !ok {
-			azmetrics[id] = amm
-			azmetrics[id].index = i
-		} else {
-			azmetrics[id].Data.BaseData.Series = append(
-				azm.Data.BaseData.Series,
-				amm.Data.BaseData.Series...,
-			)
-			azmetrics[id].index = i
+		for part, amm := range amms {
+			id := hashIDWithTagKeysOnly(m)
+			if part > 0 {
+				id = hashIDWithField(id, strconv.Itoa(part))
+			}
+			if azm, ok := azmetrics[id]; !ok {
+				azmetrics[id] = amm
+				azmetrics[id].index = i
+			} else {
+				azmetrics[id].Data.BaseData.Series = append(
+					azm.Data.BaseData.Series,
+					amm.Data.BaseData.Series...,
+				)
+				azmetrics[id].index = i
+			}
 		}
 	}
 
@@ -339,7 +416,9 @@ func (a *AzureMonitor) Write(metrics []telegraf.Metric) error {
 			writeErr.Err = err
 			continue
 		}
-		batchIndices = append(batchIndices, m.index)
+		if !slices.Contains(batchIndices, m.index) {
+			batchIndices = append(batchIndices, m.index)
+		}
 
 		// Azure Monitor's maximum request body size of 4MB. Send batches that
 		// exceed this size via separate write requests.
@@ -495,15 +574,13 @@ func hashIDWithTagKeysOnly(m telegraf.Metric) uint64 {
 	return h.Sum64()
 }
 
-func translate(m telegraf.Metric, prefix string) (*azureMonitorMetric, error) {
+// translate converts the aggregate to Azure metrics. Dimensions exceeding the
+// given limit are either dropped or, if split is enabled, sent as additional
+// metrics named "<metric>_part<n>" carrying the remaining dimensions.
+func translate(m telegraf.Metric, prefix string, maxDimensions int, split bool) ([]*azureMonitorMetric, error) {
 	dimensionNames := make([]string, 0, len(m.TagList()))
 	dimensionValues := make([]string, 0, len(m.TagList()))
 	for _, tag := range m.TagList() {
-		// Azure custom metrics service supports up to 10 dimensions
-		if len(dimensionNames) >= 10 {
-			continue
-		}
-
 		if tag.Key == "" || tag.Value == "" {
 			continue
 		}
@@ -511,6 +588,10 @@ func translate(m telegraf.Metric, prefix string) (*azureMonitorMetric, error) {
 		dimensionNames = append(dimensionNames, tag.Key)
 		dimensionValues = append(dimensionValues, tag.Value)
 	}
+	if len(dimensionNames) > maxDimensions && !split {
+		dimensionNames = dimensionNames[:maxDimensions]
+		dimensionValues = dimensionValues[:maxDimensions]
+	}
 
 	vmin, err := getFloatField(m, "min")
 	if err != nil {
@@ -539,25 +620,34 @@ func translate(m telegraf.Metric, prefix string) (*azureMonitorMetric, error) {
 	}
 	ns = prefix + ns
 
-	return &azureMonitorMetric{
-		Time: m.Time(),
-		Data: &azureMonitorData{
-			BaseData: &azureMonitorBaseData{
-				Metric:         mn,
-				Namespace:      ns,
-				DimensionNames: dimensionNames,
-				Series: []*azureMonitorSeries{
-					{
-						DimensionValues: dimensionValues,
-						Min:             vmin,
-						Max:             vmax,
-						Sum:             vsum,
-						Count:           vcount,
+	parts := make([]*azureMonitorMetric, 0, len(dimensionNames)/maxDimensions+1)
+	for start := 0; start == 0 || start < len(dimensionNames); start += maxDimensions {
+		end := min(start+maxDimensions, len(dimensionNames))
+		name := mn
+		if start > 0 {
+			name += "_part" + strconv.Itoa(len(parts)+1)
+		}
+		parts = append(parts, &azureMonitorMetric{
+			Time: m.Time(),
+			Data: &azureMonitorData{
+				BaseData: &azureMonitorBaseData{
+					Metric:         name,
+					Namespace:      ns,
+					DimensionNames: dimensionNames[start:end],
+					Series: []*azureMonitorSeries{
+						{
+							DimensionValues: dimensionValues[start:end],
+							Min:             vmin,
+							Max:             vmax,
+							Sum:             vsum,
+							Count:           vcount,
+						},
 					},
 				},
 			},
-		},
-	}, nil
+		})
+	}
+	return parts, nil
 }
 
 func getFloatField(m telegraf.Metric, key string) (float64, error) {
@@ -591,6 +681,7 @@ func init() {
 			TimestampLimitPast:   config.Duration(20 * time.Minute),
 			TimestampLimitFuture: config.Duration(-1 * time.Minute),
 			Timeout:              config.Duration(5 * time.Second),
+			TokenRefreshMargin:   config.Duration(5 * time.Minute),
 			timeFunc:             time.Now,
 		}
 	})
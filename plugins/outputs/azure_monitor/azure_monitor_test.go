@@ -3,7 +3,9 @@ package azure_monitor
 import (
 	"bufio"
 	"compress/gzip"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"sync/atomic"
@@ -617,3 +619,156 @@ func TestWriteTimelimits(t *testing.T) {
 		})
 	}
 }
+
+func TestTranslateDimensions(t *testing.T) {
+	tags := make(map[string]string, 12)
+	for i := range 12 {
+		tags[fmt.Sprintf("tag%02d", i)] = fmt.Sprintf("value%02d", i)
+	}
+	m := testutil.MustMetric(
+		"cpu-value",
+		tags,
+		map[string]interface{}{
+			"min":   float64(1),
+			"max":   float64(3),
+			"sum":   float64(4),
+			"count": int64(2),
+		},
+		time.Unix(0, 0),
+	)
+
+	// Truncate the dimensions exceeding the limit
+	actual, err := translate(m, "Telegraf/", 5, false)
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Equal(t, "value", actual[0].Data.BaseData.Metric)
+	require.Equal(t, []string{"tag00", "tag01", "tag02", "tag03", "tag04"}, actual[0].Data.BaseData.DimensionNames)
+	require.Equal(t, []string{"value00", "value01", "value02", "value03", "value04"}, actual[0].Data.BaseData.Series[0].DimensionValues)
+
+	// Split the dimensions into multiple metrics
+	actual, err = translate(m, "Telegraf/", 5, true)
+	require.NoError(t, err)
+	require.Len(t, actual, 3)
+	for i, expected := range []string{"value", "value_part2", "value_part3"} {
+		base := actual[i].Data.BaseData
+		require.Equal(t, expected, base.Metric)
+		require.Equal(t, "Telegraf/cpu", base.Namespace)
+		require.InDelta(t, float64(4), base.Series[0].Sum, testutil.DefaultDelta)
+		require.Equal(t, int64(2), base.Series[0].Count)
+	}
+	require.Equal(t, []string{"tag05", "tag06", "tag07", "tag08", "tag09"}, actual[1].Data.BaseData.DimensionNames)
+	require.Equal(t, []string{"tag10", "tag11"}, actual[2].Data.BaseData.DimensionNames)
+	require.Equal(t, []string{"value10", "value11"}, actual[2].Data.BaseData.Series[0].DimensionValues)
+
+	// Metrics within the limit are never split
+	actual, err = translate(m, "Telegraf/", 12, true)
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Len(t, actual[0].Data.BaseData.DimensionNames, 12)
+}
+
+func TestWriteSplitDimensions(t *testing.T) {
+	t.Setenv("AZURE_CLIENT_ID", "fake")
+	t.Setenv("AZURE_USERNAME", "fake")
+	t.Setenv("AZURE_PASSWORD", "fake")
+
+	var received []azureMonitorMetric
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gz, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		scanner := bufio.NewScanner(gz)
+		for scanner.Scan() {
+			var m azureMonitorMetric
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &m))
+			received = append(received, m)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	plugin := AzureMonitor{
+		EndpointURL:          "http://" + ts.Listener.Addr().String(),
+		Region:               "test",
+		ResourceID:           "/test",
+		TimestampLimitPast:   config.Duration(30 * time.Minute),
+		TimestampLimitFuture: config.Duration(-1 * time.Minute),
+		MaxDimensions:        2,
+		DimensionOverflow:    "split",
+		Log:                  testutil.Logger{},
+		timeFunc:             func() time.Time { return time.Unix(120, 0) },
+	}
+	require.NoError(t, plugin.Init())
+	plugin.preparer = autorest.CreatePreparer(autorest.NullAuthorizer{}.WithAuthorization())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	m := testutil.MustMetric(
+		"cpu-value",
+		map[string]string{"a": "1", "b": "2", "c": "3"},
+		map[string]interface{}{
+			"min":   float64(42),
+			"max":   float64(42),
+			"sum":   float64(42),
+			"count": int64(1),
+		},
+		time.Unix(60, 0),
+	)
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+
+	require.Len(t, received, 2)
+	names := []string{received[0].Data.BaseData.Metric, received[1].Data.BaseData.Metric}
+	require.ElementsMatch(t, []string{"value", "value_part2"}, names)
+}
+
+func TestInitInvalidDimensionSettings(t *testing.T) {
+	t.Setenv("AZURE_CLIENT_ID", "fake")
+	t.Setenv("AZURE_USERNAME", "fake")
+	t.Setenv("AZURE_PASSWORD", "fake")
+
+	plugin := &AzureMonitor{MaxDimensions: -1}
+	require.ErrorContains(t, plugin.Init(), "invalid max_dimensions -1")
+
+	plugin = &AzureMonitor{DimensionOverflow: "drop"}
+	require.ErrorContains(t, plugin.Init(), `invalid dimension_overflow "drop"`)
+}
+
+type fakeRefresher struct {
+	calls atomic.Uint64
+}
+
+func (f *fakeRefresher) EnsureFreshWithContext(context.Context) error {
+	f.calls.Add(1)
+	return nil
+}
+
+func TestTokenRefresh(t *testing.T) {
+	t.Setenv("AZURE_CLIENT_ID", "fake")
+	t.Setenv("AZURE_USERNAME", "fake")
+	t.Setenv("AZURE_PASSWORD", "fake")
+
+	plugin := &AzureMonitor{
+		TokenRefreshMargin: config.Duration(10 * time.Minute),
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// The token of the authorizer must be picked up for refreshing
+	spt, ok := plugin.token.(*adal.ServicePrincipalToken)
+	require.True(t, ok)
+	require.NotNil(t, spt)
+
+	refresher := &fakeRefresher{}
+	plugin.token = refresher
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		plugin.refreshToken(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		return refresher.calls.Load() >= 2
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+}
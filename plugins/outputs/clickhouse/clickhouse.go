@@ -0,0 +1,341 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package clickhouse
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	ch "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type ClickHouse struct {
+	Addresses          []string        `toml:"addresses"`
+	Database           string          `toml:"database"`
+	Username           config.Secret   `toml:"username"`
+	Password           config.Secret   `toml:"password"`
+	TimestampColumn    string          `toml:"timestamp_column"`
+	CreateTables       bool            `toml:"create_tables"`
+	TableEngine        string          `toml:"table_engine"`
+	TTL                config.Duration `toml:"ttl"`
+	AsyncInsert        bool            `toml:"async_insert"`
+	WaitForAsyncInsert bool            `toml:"wait_for_async_insert"`
+	Compression        string          `toml:"compression"`
+	Timeout            config.Duration `toml:"timeout"`
+	Log                telegraf.Logger `toml:"-"`
+	common_tls.ClientConfig
+
+	conn        driver.Conn
+	compression ch.CompressionMethod
+	tables      map[string]*table
+}
+
+func (*ClickHouse) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *ClickHouse) Init() error {
+	if len(c.Addresses) == 0 {
+		return errors.New("no address specified")
+	}
+	if c.Database == "" {
+		return errors.New("no database specified")
+	}
+	if c.TimestampColumn == "" {
+		return errors.New("no timestamp column specified")
+	}
+	if c.TableEngine == "" {
+		return errors.New("no table engine specified")
+	}
+	if c.TTL < 0 {
+		return errors.New("'ttl' must not be negative")
+	}
+
+	switch c.Compression {
+	case "", "none":
+		c.compression = ch.CompressionNone
+	case "lz4":
+		c.compression = ch.CompressionLZ4
+	case "zstd":
+		c.compression = ch.CompressionZSTD
+	default:
+		return fmt.Errorf("invalid compression %q", c.Compression)
+	}
+
+	if !c.AsyncInsert && c.WaitForAsyncInsert {
+		c.Log.Debug("Ignoring 'wait_for_async_insert' as asynchronous inserts are disabled")
+	}
+
+	c.tables = make(map[string]*table)
+
+	return nil
+}
+
+func (c *ClickHouse) Connect() error {
+	tlsCfg, err := c.ClientConfig.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("creating TLS config failed: %w", err)
+	}
+
+	username, err := c.Username.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	defer username.Destroy()
+	password, err := c.Password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	defer password.Destroy()
+
+	settings := ch.Settings{}
+	if c.AsyncInsert {
+		settings["async_insert"] = 1
+		settings["wait_for_async_insert"] = 0
+		if c.WaitForAsyncInsert {
+			settings["wait_for_async_insert"] = 1
+		}
+	}
+
+	conn, err := ch.Open(&ch.Options{
+		Protocol: ch.Native,
+		Addr:     c.Addresses,
+		Auth: ch.Auth{
+			Database: c.Database,
+			Username: username.String(),
+			Password: password.String(),
+		},
+		TLS:         tlsCfg,
+		Settings:    settings,
+		Compression: &ch.Compression{Method: c.compression},
+		DialTimeout: time.Duration(c.Timeout),
+		ReadTimeout: time.Duration(c.Timeout),
+		ClientInfo: ch.ClientInfo{
+			Products: []struct {
+				Name    string
+				Version string
+			}{{Name: "telegraf", Version: internal.Version}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.Timeout))
+	defer cancel()
+	if err := conn.Ping(ctx); err != nil {
+		conn.Close()
+		return &internal.StartupError{Err: fmt.Errorf("connecting failed: %w", err), Retry: true}
+	}
+	c.conn = conn
+
+	return nil
+}
+
+func (c *ClickHouse) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+func (c *ClickHouse) Write(metrics []telegraf.Metric) error {
+	// Group the metrics by table while keeping the indices into the batch
+	// to be able to report partial writes
+	order := make([]string, 0)
+	batches := make(map[string][]int)
+	for i, m := range metrics {
+		name := m.Name()
+		if _, found := batches[name]; !found {
+			order = append(order, name)
+		}
+		batches[name] = append(batches[name], i)
+	}
+
+	accepted := make([]int, 0, len(metrics))
+	rejected := make([]int, 0)
+	var errs []error
+	for _, name := range order {
+		indices := batches[name]
+		batch := make([]telegraf.Metric, 0, len(indices))
+		for _, i := range indices {
+			batch = append(batch, metrics[i])
+		}
+
+		invalid, err := c.writeTable(name, batch)
+		if err != nil {
+			// Force reloading the schema in case the table was modified
+			delete(c.tables, name)
+			errs = append(errs, fmt.Errorf("writing to table %q failed: %w", name, err))
+			continue
+		}
+		for j, i := range indices {
+			if invalid[j] {
+				rejected = append(rejected, i)
+			} else {
+				accepted = append(accepted, i)
+			}
+		}
+	}
+
+	if len(accepted) == len(metrics) {
+		return nil
+	}
+	err := errors.Join(errs...)
+	if err == nil {
+		err = fmt.Errorf("%d metrics cannot be stored due to conflicting column types", len(rejected))
+	}
+	if len(accepted) == 0 && len(rejected) == 0 {
+		return err
+	}
+
+	return &internal.PartialWriteError{
+		Err:           err,
+		MetricsAccept: accepted,
+		MetricsReject: rejected,
+	}
+}
+
+// writeTable inserts the given metrics into the table as one batch and
+// returns the metrics rejected due to values not matching the column types
+func (c *ClickHouse) writeTable(name string, metrics []telegraf.Metric) (map[int]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.Timeout))
+	defer cancel()
+
+	t, err := c.ensureTable(ctx, name, metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	batch, err := c.conn.PrepareBatch(ctx, t.insertStatement(c.Database))
+	if err != nil {
+		return nil, fmt.Errorf("preparing batch failed: %w", err)
+	}
+
+	invalid := make(map[int]bool)
+	for i, m := range metrics {
+		row, err := t.row(m)
+		if err != nil {
+			c.Log.Errorf("Rejecting metric for table %q: %v", name, err)
+			invalid[i] = true
+			continue
+		}
+		if err := batch.Append(row...); err != nil {
+			batch.Abort()
+			return nil, fmt.Errorf("appending row failed: %w", err)
+		}
+	}
+
+	if batch.Rows() == 0 {
+		return invalid, batch.Abort()
+	}
+	if err := batch.Send(); err != nil {
+		return nil, fmt.Errorf("sending batch failed: %w", err)
+	}
+
+	return invalid, nil
+}
+
+// ensureTable returns the table for the given name with all columns required
+// by the metrics, creating the table or missing columns if enabled
+func (c *ClickHouse) ensureTable(ctx context.Context, name string, metrics []telegraf.Metric) (*table, error) {
+	t, found := c.tables[name]
+	if !found {
+		columns, err := c.queryColumns(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		t = &table{name: name, timestampColumn: c.TimestampColumn, exists: len(columns) > 0}
+		for _, col := range columns {
+			if !supportedType(col.datatype) {
+				c.Log.Debugf("Skipping column %q of table %q with unsupported type %q", col.name, name, col.datatype)
+				t.ignore([]column{col})
+				continue
+			}
+			t.add([]column{col})
+		}
+		c.tables[name] = t
+	}
+
+	missing := t.missingColumns(metrics)
+	if len(missing) == 0 {
+		return t, nil
+	}
+
+	if !c.CreateTables {
+		if !t.exists {
+			delete(c.tables, name)
+			return nil, errors.New("table does not exist")
+		}
+		// Skip the columns not existing in the table
+		for _, col := range missing {
+			c.Log.Warnf("Ignoring %q as column does not exist in table %q", col.name, name)
+		}
+		t.ignore(missing)
+		return t, nil
+	}
+
+	if !t.exists {
+		query := t.createStatement(c.Database, c.TableEngine, time.Duration(c.TTL), missing)
+		c.Log.Debugf("Creating table %q: %s", name, query)
+		if err := c.conn.Exec(ctx, query); err != nil {
+			return nil, fmt.Errorf("creating table failed: %w", err)
+		}
+	} else {
+		query := t.alterStatement(c.Database, missing)
+		c.Log.Debugf("Adding columns to table %q: %s", name, query)
+		if err := c.conn.Exec(ctx, query); err != nil {
+			return nil, fmt.Errorf("adding columns failed: %w", err)
+		}
+	}
+	t.exists = true
+	t.add(missing)
+
+	return t, nil
+}
+
+// queryColumns returns the existing columns of the given table, an empty
+// result means the table does not exist
+func (c *ClickHouse) queryColumns(ctx context.Context, name string) ([]column, error) {
+	var result []columnInfo
+	// Materialized and alias columns cannot be inserted
+	query := "SELECT name, type FROM system.columns WHERE database = ? AND table = ?" +
+		" AND default_kind NOT IN ('MATERIALIZED', 'ALIAS') ORDER BY position"
+	if err := c.conn.Select(ctx, &result, query, c.Database, name); err != nil {
+		return nil, fmt.Errorf("querying columns failed: %w", err)
+	}
+
+	columns := make([]column, 0, len(result))
+	for _, r := range result {
+		columns = append(columns, column{name: r.Name, datatype: r.Type})
+	}
+	return columns, nil
+}
+
+func init() {
+	outputs.Add("clickhouse", func() telegraf.Output {
+		return &ClickHouse{
+			Addresses:          []string{"localhost:9000"},
+			Database:           "default",
+			TimestampColumn:    "timestamp",
+			CreateTables:       true,
+			TableEngine:        "MergeTree",
+			AsyncInsert:        true,
+			WaitForAsyncInsert: true,
+			Compression:        "lz4",
+			Timeout:            config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,290 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeConn records the statements and batches sent to the server
+type fakeConn struct {
+	driver.Conn
+	columns    map[string][]columnInfo
+	statements []string
+	inserts    map[string][][]interface{}
+	sendErr    error
+}
+
+func (c *fakeConn) Select(_ context.Context, dest any, _ string, args ...any) error {
+	*dest.(*[]columnInfo) = c.columns[args[1].(string)]
+	return nil
+}
+
+func (c *fakeConn) Exec(_ context.Context, query string, _ ...any) error {
+	c.statements = append(c.statements, query)
+	return nil
+}
+
+func (c *fakeConn) PrepareBatch(_ context.Context, query string, _ ...driver.PrepareBatchOption) (driver.Batch, error) {
+	return &fakeBatch{conn: c, query: query}, nil
+}
+
+type fakeBatch struct {
+	driver.Batch
+	conn  *fakeConn
+	query string
+	rows  [][]interface{}
+}
+
+func (b *fakeBatch) Append(v ...any) error {
+	b.rows = append(b.rows, v)
+	return nil
+}
+
+func (b *fakeBatch) Rows() int {
+	return len(b.rows)
+}
+
+func (*fakeBatch) Abort() error {
+	return nil
+}
+
+func (b *fakeBatch) Send() error {
+	if b.conn.sendErr != nil {
+		return b.conn.sendErr
+	}
+	if b.conn.inserts == nil {
+		b.conn.inserts = make(map[string][][]interface{})
+	}
+	b.conn.inserts[b.query] = append(b.conn.inserts[b.query], b.rows...)
+	return nil
+}
+
+func newPlugin(conn *fakeConn) *ClickHouse {
+	plugin := outputs.Outputs["clickhouse"]().(*ClickHouse)
+	plugin.Log = testutil.Logger{}
+	plugin.conn = conn
+	return plugin
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*ClickHouse)
+		expected string
+	}{
+		{
+			name:     "no address",
+			modify:   func(c *ClickHouse) { c.Addresses = nil },
+			expected: "no address specified",
+		},
+		{
+			name:     "no database",
+			modify:   func(c *ClickHouse) { c.Database = "" },
+			expected: "no database specified",
+		},
+		{
+			name:     "no timestamp column",
+			modify:   func(c *ClickHouse) { c.TimestampColumn = "" },
+			expected: "no timestamp column specified",
+		},
+		{
+			name:     "negative ttl",
+			modify:   func(c *ClickHouse) { c.TTL = config.Duration(-time.Hour) },
+			expected: "'ttl' must not be negative",
+		},
+		{
+			name:     "invalid compression",
+			modify:   func(c *ClickHouse) { c.Compression = "gzip" },
+			expected: `invalid compression "gzip"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newPlugin(nil)
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWriteCreateTables(t *testing.T) {
+	conn := &fakeConn{}
+	plugin := newPlugin(conn)
+	plugin.TTL = config.Duration(24 * time.Hour)
+	require.NoError(t, plugin.Init())
+
+	ts := time.Unix(1700000000, 0)
+	metrics := []telegraf.Metric{
+		metric.New("cpu",
+			map[string]string{"host": "a", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 90.5, "count": int64(3)},
+			ts,
+		),
+		metric.New("mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"used": uint64(42), "ok": true, "state": "fine"},
+			ts,
+		),
+		metric.New("cpu",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"usage_idle": 80.0},
+			ts,
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	expectedStatements := []string{
+		"CREATE TABLE IF NOT EXISTS `default`.`cpu` (" +
+			"`timestamp` DateTime64(9), `cpu` LowCardinality(String), `host` LowCardinality(String), " +
+			"`count` Nullable(Int64), `usage_idle` Nullable(Float64)) " +
+			"ENGINE = MergeTree ORDER BY (`cpu`, `host`, `timestamp`) TTL toDateTime(`timestamp`) + INTERVAL 86400 SECOND",
+		"CREATE TABLE IF NOT EXISTS `default`.`mem` (" +
+			"`timestamp` DateTime64(9), `host` LowCardinality(String), " +
+			"`ok` Nullable(Bool), `state` Nullable(String), `used` Nullable(UInt64)) " +
+			"ENGINE = MergeTree ORDER BY (`host`, `timestamp`) TTL toDateTime(`timestamp`) + INTERVAL 86400 SECOND",
+	}
+	require.Equal(t, expectedStatements, conn.statements)
+
+	expectedInserts := map[string][][]interface{}{
+		"INSERT INTO `default`.`cpu` (`timestamp`, `cpu`, `host`, `count`, `usage_idle`)": {
+			{ts, "cpu0", "a", int64(3), 90.5},
+			{ts, "", "b", nil, 80.0},
+		},
+		"INSERT INTO `default`.`mem` (`timestamp`, `host`, `ok`, `state`, `used`)": {
+			{ts, "a", true, "fine", uint64(42)},
+		},
+	}
+	require.Equal(t, expectedInserts, conn.inserts)
+
+	// Writing again must not modify the schema
+	conn.inserts = nil
+	require.NoError(t, plugin.Write(metrics))
+	require.Len(t, conn.statements, 2)
+	require.Len(t, conn.inserts, 2)
+}
+
+func TestWriteExistingTable(t *testing.T) {
+	conn := &fakeConn{
+		columns: map[string][]columnInfo{
+			"cpu": {
+				{Name: "timestamp", Type: "DateTime64(9)"},
+				{Name: "host", Type: "LowCardinality(String)"},
+				{Name: "usage_idle", Type: "Float32"},
+				{Name: "samples", Type: "Array(Int64)"},
+			},
+		},
+	}
+	plugin := newPlugin(conn)
+	require.NoError(t, plugin.Init())
+
+	ts := time.Unix(1700000000, 0)
+	metrics := []telegraf.Metric{
+		metric.New("cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": int64(90), "usage_user": 5.5},
+			ts,
+		),
+		metric.New("cpu",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"usage_idle": "invalid"},
+			ts,
+		),
+	}
+
+	err := plugin.Write(metrics)
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Equal(t, []int{0}, writeErr.MetricsAccept)
+	require.Equal(t, []int{1}, writeErr.MetricsReject)
+
+	require.Equal(t, []string{"ALTER TABLE `default`.`cpu` ADD COLUMN IF NOT EXISTS `usage_user` Nullable(Float64)"}, conn.statements)
+	expected := map[string][][]interface{}{
+		"INSERT INTO `default`.`cpu` (`timestamp`, `host`, `usage_idle`, `usage_user`)": {
+			{ts, "a", float32(90), 5.5},
+		},
+	}
+	require.Equal(t, expected, conn.inserts)
+}
+
+func TestWriteWithoutCreateTables(t *testing.T) {
+	conn := &fakeConn{
+		columns: map[string][]columnInfo{
+			"cpu": {
+				{Name: "timestamp", Type: "DateTime"},
+				{Name: "host", Type: "String"},
+			},
+		},
+	}
+	plugin := newPlugin(conn)
+	plugin.CreateTables = false
+	require.NoError(t, plugin.Init())
+
+	ts := time.Unix(1700000000, 0)
+	metrics := []telegraf.Metric{
+		metric.New("cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 90.0},
+			ts,
+		),
+		metric.New("mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"used": int64(1)},
+			ts,
+		),
+	}
+
+	// Metrics for missing tables must be kept for retrying
+	err := plugin.Write(metrics)
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.ErrorContains(t, err, `writing to table "mem" failed: table does not exist`)
+	require.Equal(t, []int{0}, writeErr.MetricsAccept)
+	require.Empty(t, writeErr.MetricsReject)
+
+	require.Empty(t, conn.statements)
+	expected := map[string][][]interface{}{
+		"INSERT INTO `default`.`cpu` (`timestamp`, `host`)": {
+			{ts, "a"},
+		},
+	}
+	require.Equal(t, expected, conn.inserts)
+}
+
+func TestWriteFailed(t *testing.T) {
+	conn := &fakeConn{sendErr: errors.New("connection reset")}
+	plugin := newPlugin(conn)
+	require.NoError(t, plugin.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 90.0},
+			time.Unix(1700000000, 0),
+		),
+	}
+	err := plugin.Write(metrics)
+	require.ErrorContains(t, err, "connection reset")
+	var writeErr *internal.PartialWriteError
+	require.NotErrorAs(t, err, &writeErr)
+
+	// The schema must be reloaded on the next write
+	require.Empty(t, plugin.tables)
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	require.Equal(t, "`metric three`", quoteIdentifier("metric three"))
+	require.Equal(t, "`a\\`b\\\\c`", quoteIdentifier("a`b\\c"))
+}
@@ -0,0 +1,286 @@
+package clickhouse
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// Column type used for tags and the timestamp when creating columns
+const (
+	tagType       = "LowCardinality(String)"
+	timestampType = "DateTime64(9)"
+)
+
+var identifierEscaper = strings.NewReplacer("\\", "\\\\", "`", "\\`")
+
+// columnInfo is a row of the 'system.columns' table
+type columnInfo struct {
+	Name string `ch:"name"`
+	Type string `ch:"type"`
+}
+
+type column struct {
+	name     string
+	datatype string
+}
+
+// table is the known schema of a table. Tags and fields are stored in
+// separate columns named after the tag or field key.
+type table struct {
+	name            string
+	timestampColumn string
+	exists          bool
+	columns         []column
+	ignored         map[string]bool
+}
+
+// missingColumns returns the columns required for storing the given metrics
+// but not existing in the table
+func (t *table) missingColumns(metrics []telegraf.Metric) []column {
+	seen := make(map[string]bool, len(t.columns))
+	for _, c := range t.columns {
+		seen[c.name] = true
+	}
+	for name := range t.ignored {
+		seen[name] = true
+	}
+
+	var missing, tags, fields []column
+	if !seen[t.timestampColumn] {
+		seen[t.timestampColumn] = true
+		missing = append(missing, column{name: t.timestampColumn, datatype: timestampType})
+	}
+
+	for _, m := range metrics {
+		for _, tag := range m.TagList() {
+			if seen[tag.Key] {
+				continue
+			}
+			seen[tag.Key] = true
+			tags = append(tags, column{name: tag.Key, datatype: tagType})
+		}
+		for _, field := range m.FieldList() {
+			// Tags take precedence over fields with the same name
+			if seen[field.Key] || m.HasTag(field.Key) {
+				continue
+			}
+			datatype, ok := fieldType(field.Value)
+			if !ok {
+				continue
+			}
+			seen[field.Key] = true
+			fields = append(fields, column{name: field.Key, datatype: datatype})
+		}
+	}
+
+	// Sort the columns to get a deterministic order independent of the metrics
+	byName := func(a, b column) int { return strings.Compare(a.name, b.name) }
+	slices.SortFunc(tags, byName)
+	slices.SortFunc(fields, byName)
+	missing = append(missing, tags...)
+	return append(missing, fields...)
+}
+
+// add adds the given columns to the known schema
+func (t *table) add(columns []column) {
+	t.columns = append(t.columns, columns...)
+}
+
+// ignore marks the given columns to be skipped when inserting metrics
+func (t *table) ignore(columns []column) {
+	if t.ignored == nil {
+		t.ignored = make(map[string]bool, len(columns))
+	}
+	for _, c := range columns {
+		t.ignored[c.name] = true
+	}
+}
+
+// createStatement returns the statement creating the table with the given
+// columns. Tags are used as sorting key for the MergeTree engine family.
+func (t *table) createStatement(database, engine string, ttl time.Duration, columns []column) string {
+	definitions := make([]string, 0, len(columns))
+	keys := make([]string, 0, len(columns))
+	for _, c := range columns {
+		definitions = append(definitions, quoteIdentifier(c.name)+" "+c.datatype)
+		if c.datatype == tagType {
+			keys = append(keys, quoteIdentifier(c.name))
+		}
+	}
+	slices.Sort(keys)
+
+	var sb strings.Builder
+	sb.WriteString("CREATE TABLE IF NOT EXISTS ")
+	sb.WriteString(quoteIdentifier(database) + "." + quoteIdentifier(t.name))
+	sb.WriteString(" (" + strings.Join(definitions, ", ") + ")")
+	sb.WriteString(" ENGINE = " + engine)
+	if strings.Contains(engine, "MergeTree") {
+		sb.WriteString(" ORDER BY (" + strings.Join(append(keys, quoteIdentifier(t.timestampColumn)), ", ") + ")")
+		if ttl > 0 {
+			sb.WriteString(" TTL toDateTime(" + quoteIdentifier(t.timestampColumn) + ")")
+			sb.WriteString(" + INTERVAL " + strconv.FormatInt(int64(ttl.Seconds()), 10) + " SECOND")
+		}
+	}
+
+	return sb.String()
+}
+
+// alterStatement returns the statement adding the given columns to the table
+func (t *table) alterStatement(database string, columns []column) string {
+	definitions := make([]string, 0, len(columns))
+	for _, c := range columns {
+		definitions = append(definitions, "ADD COLUMN IF NOT EXISTS "+quoteIdentifier(c.name)+" "+c.datatype)
+	}
+	return "ALTER TABLE " + quoteIdentifier(database) + "." + quoteIdentifier(t.name) + " " + strings.Join(definitions, ", ")
+}
+
+// insertStatement returns the statement for inserting a batch into all
+// known columns of the table
+func (t *table) insertStatement(database string) string {
+	names := make([]string, 0, len(t.columns))
+	for _, c := range t.columns {
+		names = append(names, quoteIdentifier(c.name))
+	}
+	return "INSERT INTO " + quoteIdentifier(database) + "." + quoteIdentifier(t.name) + " (" + strings.Join(names, ", ") + ")"
+}
+
+// row returns the values of the metric in the order of the table columns
+// converted to the column type
+func (t *table) row(m telegraf.Metric) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(t.columns))
+	for _, c := range t.columns {
+		var value interface{}
+		if c.name == t.timestampColumn {
+			value = m.Time()
+		} else if v, found := m.GetTag(c.name); found {
+			value = v
+		} else if v, found := m.GetField(c.name); found {
+			value = v
+		}
+
+		converted, err := convert(value, c.datatype)
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", c.name, err)
+		}
+		values = append(values, converted)
+	}
+	return values, nil
+}
+
+// fieldType returns the column type for the given field value. Fields are
+// nullable as not every metric of a table contains all fields.
+func fieldType(value interface{}) (string, bool) {
+	switch value.(type) {
+	case int64:
+		return "Nullable(Int64)", true
+	case uint64:
+		return "Nullable(UInt64)", true
+	case float64:
+		return "Nullable(Float64)", true
+	case string:
+		return "Nullable(String)", true
+	case bool:
+		return "Nullable(Bool)", true
+	}
+	return "", false
+}
+
+// convert converts the value to the type expected by the client library for
+// the given column type. Missing values are stored as NULL for nullable
+// columns and as the default value of the type otherwise.
+func convert(value interface{}, datatype string) (interface{}, error) {
+	base, nullable := baseType(datatype)
+	if value == nil {
+		if nullable {
+			return nil, nil
+		}
+		value = zeroValue(base)
+	}
+
+	switch base {
+	case "String":
+		return internal.ToString(value)
+	case "Bool":
+		return internal.ToBool(value)
+	case "Int8":
+		return internal.ToInt8(value)
+	case "Int16":
+		return internal.ToInt16(value)
+	case "Int32":
+		return internal.ToInt32(value)
+	case "Int64":
+		return internal.ToInt64(value)
+	case "UInt8":
+		return internal.ToUint8(value)
+	case "UInt16":
+		return internal.ToUint16(value)
+	case "UInt32":
+		return internal.ToUint32(value)
+	case "UInt64":
+		return internal.ToUint64(value)
+	case "Float32":
+		return internal.ToFloat32(value)
+	case "Float64":
+		return internal.ToFloat64(value)
+	}
+
+	if strings.HasPrefix(base, "DateTime") {
+		if v, ok := value.(time.Time); ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("cannot convert %T to %s", value, base)
+	}
+	return nil, fmt.Errorf("unsupported column type %q", datatype)
+}
+
+// supportedType reports if values can be converted to the given column type
+func supportedType(datatype string) bool {
+	base, _ := baseType(datatype)
+	switch base {
+	case "String", "Bool",
+		"Int8", "Int16", "Int32", "Int64",
+		"UInt8", "UInt16", "UInt32", "UInt64",
+		"Float32", "Float64":
+		return true
+	}
+	return strings.HasPrefix(base, "DateTime")
+}
+
+// baseType strips the 'LowCardinality' and 'Nullable' modifiers from the
+// column type and reports if the column is nullable
+func baseType(datatype string) (string, bool) {
+	var nullable bool
+	for {
+		switch {
+		case strings.HasPrefix(datatype, "LowCardinality(") && strings.HasSuffix(datatype, ")"):
+			datatype = strings.TrimSuffix(strings.TrimPrefix(datatype, "LowCardinality("), ")")
+		case strings.HasPrefix(datatype, "Nullable(") && strings.HasSuffix(datatype, ")"):
+			datatype = strings.TrimSuffix(strings.TrimPrefix(datatype, "Nullable("), ")")
+			nullable = true
+		default:
+			return datatype, nullable
+		}
+	}
+}
+
+func zeroValue(base string) interface{} {
+	switch {
+	case base == "String":
+		return ""
+	case base == "Bool":
+		return false
+	case strings.HasPrefix(base, "DateTime"):
+		return time.Unix(0, 0)
+	}
+	return int64(0)
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + identifierEscaper.Replace(name) + "`"
+}
@@ -3,6 +3,7 @@ package datadog
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
 	"encoding/json"
 	"errors"
@@ -11,6 +12,7 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,6 +20,7 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/proxy"
+	"github.com/influxdata/telegraf/plugins/common/submission"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
 
@@ -32,8 +35,11 @@ type Datadog struct {
 	RateInterval config.Duration `toml:"rate_interval"`
 	Log          telegraf.Logger `toml:"-"`
 
-	client *http.Client
+	client  *http.Client
+	encoder internal.ContentEncoder
+	queue   *submission.Queue
 	proxy.HTTPProxy
+	submission.Config
 }
 
 type TimeSeries struct {
@@ -67,6 +73,23 @@ func (d *Datadog) Connect() error {
 		return err
 	}
 
+	switch c := strings.ToLower(d.Compression); c {
+	case "zlib":
+		encoder, err := internal.NewContentEncoder(c)
+		if err != nil {
+			return err
+		}
+		d.encoder = encoder
+	default:
+		d.encoder = nil
+	}
+
+	queue, err := d.Config.NewQueue()
+	if err != nil {
+		return err
+	}
+	d.queue = queue
+
 	d.client = &http.Client{
 		Transport: &http.Transport{
 			Proxy: proxyFunc,
@@ -79,120 +102,146 @@ func (d *Datadog) Connect() error {
 func (d *Datadog) convertToDatadogMetric(metrics []telegraf.Metric) []*Metric {
 	tempSeries := make([]*Metric, 0, len(metrics))
 	for _, m := range metrics {
-		if dogMs, err := buildMetrics(m); err == nil {
-			metricTags := buildTags(m.TagList())
-			host, _ := m.GetTag("host")
-			// Retrieve the metric_type tag created by inputs.statsd
-			statsDMetricType, _ := m.GetTag("metric_type")
-
-			if len(dogMs) == 0 {
-				continue
-			}
+		tempSeries = append(tempSeries, d.convert(m)...)
+	}
+	return tempSeries
+}
 
-			for fieldName, dogM := range dogMs {
-				// name of the datadog measurement
-				var dname string
-				if fieldName == "value" {
-					// adding .value seems redundant here
-					dname = m.Name()
-				} else {
-					dname = m.Name() + "." + fieldName
-				}
-				var tname string
-				var interval int64
-				interval = 1
-				switch m.Type() {
-				case telegraf.Counter, telegraf.Untyped:
-					if d.RateInterval > 0 && isRateable(statsDMetricType, fieldName) {
-						// interval is expected to be in seconds
-						rateIntervalSeconds := time.Duration(d.RateInterval).Seconds()
-						interval = int64(rateIntervalSeconds)
-						dogM[1] = dogM[1] / rateIntervalSeconds
-						tname = "rate"
-					} else if m.Type() == telegraf.Counter {
-						tname = "count"
-					} else {
-						tname = ""
-					}
-				case telegraf.Gauge:
-					tname = "gauge"
-				default:
-					tname = ""
-				}
-				metric := &Metric{
-					Metric:   dname,
-					Tags:     metricTags,
-					Host:     host,
-					Type:     tname,
-					Interval: interval,
-				}
-				metric.Points[0] = dogM
-				tempSeries = append(tempSeries, metric)
-			}
+func (d *Datadog) convert(m telegraf.Metric) []*Metric {
+	dogMs, err := buildMetrics(m)
+	if err != nil {
+		d.Log.Infof("Unable to build Metric for %s due to error '%v', skipping", m.Name(), err)
+		return nil
+	}
+	if len(dogMs) == 0 {
+		return nil
+	}
+
+	metricTags := buildTags(m.TagList())
+	host, _ := m.GetTag("host")
+	// Retrieve the metric_type tag created by inputs.statsd
+	statsDMetricType, _ := m.GetTag("metric_type")
+
+	series := make([]*Metric, 0, len(dogMs))
+	for fieldName, dogM := range dogMs {
+		// name of the datadog measurement
+		var dname string
+		if fieldName == "value" {
+			// adding .value seems redundant here
+			dname = m.Name()
 		} else {
-			d.Log.Infof("Unable to build Metric for %s due to error '%v', skipping", m.Name(), err)
+			dname = m.Name() + "." + fieldName
+		}
+		var tname string
+		var interval int64
+		interval = 1
+		switch m.Type() {
+		case telegraf.Counter, telegraf.Untyped:
+			if d.RateInterval > 0 && isRateable(statsDMetricType, fieldName) {
+				// interval is expected to be in seconds
+				rateIntervalSeconds := time.Duration(d.RateInterval).Seconds()
+				interval = int64(rateIntervalSeconds)
+				dogM[1] = dogM[1] / rateIntervalSeconds
+				tname = "rate"
+			} else if m.Type() == telegraf.Counter {
+				tname = "count"
+			} else {
+				tname = ""
+			}
+		case telegraf.Gauge:
+			tname = "gauge"
+		default:
+			tname = ""
+		}
+		metric := &Metric{
+			Metric:   dname,
+			Tags:     metricTags,
+			Host:     host,
+			Type:     tname,
+			Interval: interval,
 		}
+		metric.Points[0] = dogM
+		series = append(series, metric)
 	}
-	return tempSeries
+	return series
 }
 
 func (d *Datadog) Write(metrics []telegraf.Metric) error {
-	ts := TimeSeries{}
-	tempSeries := d.convertToDatadogMetric(metrics)
+	// Remember the originating metric of each series to be able to accept
+	// or reject metrics if only parts of the series are submitted.
+	series := make([]*Metric, 0, len(metrics))
+	origins := make([]int, 0, len(metrics))
+	for i, m := range metrics {
+		for _, s := range d.convert(m) {
+			series = append(series, s)
+			origins = append(origins, i)
+		}
+	}
 
-	if len(tempSeries) == 0 {
+	if len(series) == 0 {
 		return nil
 	}
 
-	redactedAPIKey := "****************"
-	ts.Series = make([]*Metric, len(tempSeries))
-	copy(ts.Series, tempSeries[0:])
-	tsBytes, err := json.Marshal(ts)
+	result, err := submission.Submit(context.Background(), d.queue, series, d.encode, d.send)
+	return result.WriteError(err, origins, len(metrics))
+}
+
+func (d *Datadog) encode(series []*Metric) ([]byte, error) {
+	tsBytes, err := json.Marshal(TimeSeries{Series: series})
 	if err != nil {
-		return fmt.Errorf("unable to marshal TimeSeries: %w", err)
+		return nil, fmt.Errorf("unable to marshal TimeSeries: %w", err)
+	}
+	if d.encoder == nil {
+		return tsBytes, nil
 	}
 
-	var req *http.Request
-	c := strings.ToLower(d.Compression)
-	switch c {
-	case "zlib":
-		encoder, err := internal.NewContentEncoder(c)
-		if err != nil {
-			return err
-		}
-		buf, err := encoder.Encode(tsBytes)
-		if err != nil {
-			return err
-		}
-		req, err = http.NewRequest("POST", d.authenticatedURL(), bytes.NewBuffer(buf))
-		if err != nil {
-			return err
-		}
-		req.Header.Set("Content-Encoding", "deflate")
-	case "none":
-		fallthrough
-	default:
-		req, err = http.NewRequest("POST", d.authenticatedURL(), bytes.NewBuffer(tsBytes))
+	buf, err := d.encoder.Encode(tsBytes)
+	if err != nil {
+		return nil, err
 	}
+	// The encoder reuses its buffer so we need to copy the payload
+	return bytes.Clone(buf), nil
+}
+
+func (d *Datadog) send(ctx context.Context, payload []byte) error {
+	redactedAPIKey := "****************"
 
+	req, err := http.NewRequestWithContext(ctx, "POST", d.authenticatedURL(), bytes.NewBuffer(payload))
 	if err != nil {
 		return fmt.Errorf("unable to create http.Request, %s", strings.ReplaceAll(err.Error(), d.Apikey, redactedAPIKey))
 	}
 	req.Header.Add("Content-Type", "application/json")
+	if d.encoder != nil {
+		req.Header.Set("Content-Encoding", "deflate")
+	}
 
 	resp, err := d.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("error POSTing metrics, %s", strings.ReplaceAll(err.Error(), d.Apikey, redactedAPIKey))
+		return &submission.RetryableError{
+			Err: fmt.Errorf("error POSTing metrics, %s", strings.ReplaceAll(err.Error(), d.Apikey, redactedAPIKey)),
+		}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode > 209 {
-		//nolint:errcheck // err can be ignored since it is just for logging
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("received bad status code, %d: %s", resp.StatusCode, string(body))
+	if resp.StatusCode >= 200 && resp.StatusCode <= 209 {
+		return nil
 	}
 
-	return nil
+	//nolint:errcheck // err can be ignored since it is just for logging
+	body, _ := io.ReadAll(resp.Body)
+	err = fmt.Errorf("received bad status code, %d: %s", resp.StatusCode, string(body))
+	switch {
+	case resp.StatusCode == http.StatusBadRequest:
+		// The payload was malformed, sending it again will not help
+		return &submission.RejectedError{Err: err}
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		var retryAfter time.Duration
+		if seconds, perr := strconv.Atoi(resp.Header.Get("Retry-After")); perr == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return &submission.RetryableError{Err: err, RetryAfter: retryAfter}
+	}
+	return err
 }
 
 func (d *Datadog) authenticatedURL() string {
@@ -279,6 +328,13 @@ func init() {
 		return &Datadog{
 			URL:         datadogAPI,
 			Compression: "none",
+			Config: submission.Config{
+				MaxPayloadSize: config.Size(3200000),
+				RequestConfig: submission.RequestConfig{
+					MaxRetries:   3,
+					RetryBackoff: config.Duration(time.Second),
+				},
+			},
 		}
 	})
 }
@@ -14,6 +14,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -86,6 +87,102 @@ func TestBadStatusCode(t *testing.T) {
 	}
 }
 
+func TestPayloadSplitting(t *testing.T) {
+	var requests []TimeSeries
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var series TimeSeries
+		if err := json.NewDecoder(r.Body).Decode(&series); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		requests = append(requests, series)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	d := NewDatadog(ts.URL)
+	d.Apikey = "123456"
+	d.MaxPayloadSize = config.Size(200)
+	require.NoError(t, d.Connect())
+
+	metrics := make([]telegraf.Metric, 0, 8)
+	for i := range 8 {
+		metrics = append(metrics, testutil.MustMetric(
+			"cpu",
+			map[string]string{"cpu": fmt.Sprintf("cpu%d", i)},
+			map[string]interface{}{"value": float64(i)},
+			time.Unix(0, 0),
+		))
+	}
+	require.NoError(t, d.Write(metrics))
+
+	require.Greater(t, len(requests), 1)
+	var count int
+	for _, r := range requests {
+		count += len(r.Series)
+	}
+	require.Equal(t, 8, count)
+}
+
+func TestRetryTooManyRequests(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	d := NewDatadog(ts.URL)
+	d.Apikey = "123456"
+	d.MaxRetries = 1
+	d.RetryBackoff = config.Duration(time.Millisecond)
+	require.NoError(t, d.Connect())
+
+	require.NoError(t, d.Write(testutil.MockMetrics()))
+	require.Equal(t, 2, calls)
+}
+
+func TestRejectBadRequest(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var series TimeSeries
+		if err := json.NewDecoder(r.Body).Decode(&series); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		for _, s := range series.Series {
+			if s.Metric == "invalid" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	d := NewDatadog(ts.URL)
+	d.Apikey = "123456"
+	d.MaxPayloadSize = config.Size(100)
+	require.NoError(t, d.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("valid", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("invalid", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+		testutil.MustMetric("valid", map[string]string{}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+	}
+	err := d.Write(metrics)
+
+	var werr *internal.PartialWriteError
+	require.ErrorAs(t, err, &werr)
+	require.Equal(t, []int{0, 2}, werr.MetricsAccept)
+	require.Equal(t, []int{1}, werr.MetricsReject)
+}
+
 func TestAuthenticatedUrl(t *testing.T) {
 	d := fakeDatadog()
 
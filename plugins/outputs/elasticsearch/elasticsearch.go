@@ -21,6 +21,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -30,6 +31,9 @@ var sampleConfig string
 
 type Elasticsearch struct {
 	AuthBearerToken     config.Secret          `toml:"auth_bearer_token"`
+	BulkRetries         int                    `toml:"bulk_retries"`
+	BulkRetryBackoff    config.Duration        `toml:"bulk_retry_backoff"`
+	DataStream          bool                   `toml:"data_stream"`
 	DefaultPipeline     string                 `toml:"default_pipeline"`
 	DefaultTagValue     string                 `toml:"default_tag_value"`
 	EnableGzip          bool                   `toml:"enable_gzip"`
@@ -39,6 +43,7 @@ type Elasticsearch struct {
 	ForceDocumentID     bool                   `toml:"force_document_id"`
 	HealthCheckInterval config.Duration        `toml:"health_check_interval"`
 	HealthCheckTimeout  config.Duration        `toml:"health_check_timeout"`
+	ILMPolicy           string                 `toml:"ilm_policy"`
 	IndexName           string                 `toml:"index_name"`
 	IndexTemplate       map[string]interface{} `toml:"template_index_settings"`
 	ManageTemplate      bool                   `toml:"manage_template"`
@@ -56,6 +61,7 @@ type Elasticsearch struct {
 	pipelineName        string
 	pipelineTagKeys     []string
 	tagKeys             []string
+	dataStreams         map[string]bool
 	tls.ClientConfig
 
 	Client *elastic.Client
@@ -126,6 +132,62 @@ const telegrafTemplate = `
 	}
 }`
 
+const dataStreamTemplate = `
+{
+	"index_patterns" : [ "{{.TemplatePattern}}" ],
+	"data_stream": {},
+	"priority": 200,
+	"template": {
+		"settings": {
+			"index": {{.IndexTemplate}}
+		},
+		"mappings" : {
+			"properties" : {
+				"@timestamp" : { "type" : "date" },
+				"measurement_name" : { "type" : "keyword" }
+			},
+			"dynamic_templates": [
+				{
+					"tags": {
+						"match_mapping_type": "string",
+						"path_match": "tag.*",
+						"mapping": {
+							"ignore_above": 512,
+							"type": "keyword"
+						}
+					}
+				},
+				{
+					"metrics_long": {
+						"match_mapping_type": "long",
+						"mapping": {
+							"type": "float",
+							"index": false
+						}
+					}
+				},
+				{
+					"metrics_double": {
+						"match_mapping_type": "double",
+						"mapping": {
+							"type": "float",
+							"index": false
+						}
+					}
+				},
+				{
+					"text_fields": {
+						"match": "*",
+						"mapping": {
+							"norms": false
+						}
+					}
+				}
+			]
+		}
+	}
+}`
+
 const defaultTemplateIndexSettings = `
 {
 	"refresh_interval": "10s",
@@ -158,6 +220,14 @@ func (a *Elasticsearch) Connect() error {
 		return fmt.Errorf("invalid float_handling type %q", a.FloatHandling)
 	}
 
+	// Data streams are time-based by themselves
+	if a.DataStream && strings.Contains(a.IndexName, "%") {
+		return errors.New("date specifiers in index_name are not supported for data streams")
+	}
+	if a.BulkRetries < 0 {
+		return fmt.Errorf("invalid bulk_retries %d", a.BulkRetries)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.Timeout))
 	defer cancel()
 
@@ -228,15 +298,26 @@ func (a *Elasticsearch) Connect() error {
 	}
 
 	// quit if ES version is not supported
-	majorReleaseNumber, err := strconv.Atoi(strings.Split(esVersion, ".")[0])
+	versionParts := strings.Split(esVersion, ".")
+	majorReleaseNumber, err := strconv.Atoi(versionParts[0])
 	if err != nil || majorReleaseNumber < 5 {
 		return fmt.Errorf("elasticsearch version not supported: %s", esVersion)
 	}
+	if a.DataStream {
+		var minorReleaseNumber int
+		if len(versionParts) > 1 {
+			minorReleaseNumber, err = strconv.Atoi(versionParts[1])
+		}
+		if err != nil || majorReleaseNumber < 7 || (majorReleaseNumber == 7 && minorReleaseNumber < 9) {
+			return fmt.Errorf("data streams require Elasticsearch 7.9 or later, found %s", esVersion)
+		}
+	}
 
 	a.Log.Infof("Elasticsearch version: %q", esVersion)
 
 	a.Client = client
 	a.majorReleaseNumber = majorReleaseNumber
+	a.dataStreams = make(map[string]bool)
 
 	if a.ManageTemplate {
 		err := a.manageTemplate(ctx)
@@ -268,8 +349,8 @@ func (a *Elasticsearch) Write(metrics []telegraf.Metric) error {
 		return nil
 	}
 
-	bulkRequest := a.Client.Bulk()
-
+	requests := make([]elastic.BulkableRequest, 0, len(metrics))
+	streams := make(map[string]bool)
 	for _, metric := range metrics {
 		var name = metric.Name()
 
@@ -305,9 +386,13 @@ func (a *Elasticsearch) Write(metrics []telegraf.Metric) error {
 
 		br := elastic.NewBulkIndexRequest().Index(indexName).Doc(m)
 
-		if a.UseOpTypeCreate {
+		// Data streams only support appending documents
+		if a.UseOpTypeCreate || a.DataStream {
 			br.OpType("create")
 		}
+		if a.DataStream && !a.dataStreams[indexName] {
+			streams[indexName] = true
+		}
 
 		if a.ForceDocumentID {
 			id := GetPointID(metric)
@@ -324,32 +409,124 @@ func (a *Elasticsearch) Write(metrics []telegraf.Metric) error {
 			}
 		}
 
-		bulkRequest.Add(br)
+		requests = append(requests, br)
+	}
+
+	for name := range streams {
+		if err := a.createDataStream(name); err != nil {
+			return err
+		}
+	}
+
+	return a.sendBulk(requests)
+}
+
+// sendBulk sends the requests in a bulk and retries documents rejected due to
+// backpressure. Documents failing permanently are dropped while documents
+// still rejected after all retries are kept for the next write.
+func (a *Elasticsearch) sendBulk(requests []elastic.BulkableRequest) error {
+	pending := make([]int, 0, len(requests))
+	for i := range requests {
+		pending = append(pending, i)
+	}
+
+	var accepted, rejected []int
+	var rejectErrors []error
+	backoff := time.Duration(a.BulkRetryBackoff)
+	for attempt := 0; ; attempt++ {
+		bulkRequest := a.Client.Bulk()
+		for _, idx := range pending {
+			bulkRequest.Add(requests[idx])
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.Timeout))
+		res, err := bulkRequest.Do(ctx)
+		cancel()
+		if err != nil {
+			err = fmt.Errorf("error sending bulk request to Elasticsearch: %w", err)
+			if attempt == 0 {
+				return err
+			}
+			a.Log.Errorf("Retrying rejected documents failed: %v", err)
+			break
+		}
+		if !res.Errors {
+			accepted = append(accepted, pending...)
+			break
+		}
+		if len(res.Items) != len(pending) {
+			return fmt.Errorf("received %d bulk response items for %d documents", len(res.Items), len(pending))
+		}
+
+		retry := make([]int, 0)
+		for i, item := range res.Items {
+			idx := pending[i]
+			for _, result := range item {
+				switch {
+				case result.Error == nil && result.Status < 300:
+					accepted = append(accepted, idx)
+				case result.Status == http.StatusTooManyRequests:
+					retry = append(retry, idx)
+				case result.Status >= 500:
+					// Temporary failure, keep the document for the next write
+				default:
+					details := result.Error
+					if details == nil {
+						details = &elastic.ErrorDetails{Reason: "unknown"}
+					}
+					if len(rejected) == 0 {
+						a.Log.Errorf(
+							"Elasticsearch indexing failure, id: %d, status: %d, error: %s, caused by: %s, %s",
+							idx,
+							result.Status,
+							details.Reason,
+							details.CausedBy["reason"],
+							details.CausedBy["type"],
+						)
+					}
+					rejected = append(rejected, idx)
+					rejectErrors = append(rejectErrors, fmt.Errorf("status %d: %s", result.Status, details.Reason))
+				}
+			}
+		}
+
+		pending = retry
+		if len(pending) == 0 || attempt >= a.BulkRetries {
+			break
+		}
+		a.Log.Debugf("Elasticsearch rejected %d documents due to too many requests, retrying in %s", len(pending), backoff)
+		time.Sleep(backoff)
+		backoff *= 2
 	}
 
+	if len(accepted) == len(requests) {
+		return nil
+	}
+	return &internal.PartialWriteError{
+		Err:                 fmt.Errorf("elasticsearch failed to index %d metrics", len(requests)-len(accepted)),
+		MetricsAccept:       accepted,
+		MetricsReject:       rejected,
+		MetricsRejectErrors: rejectErrors,
+	}
+}
+
+// createDataStream explicitly creates the given data stream, existing streams
+// are ignored. A matching index template with data streams enabled must exist.
+func (a *Elasticsearch) createDataStream(name string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.Timeout))
 	defer cancel()
 
-	res, err := bulkRequest.Do(ctx)
-
+	_, err := a.Client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: http.MethodPut,
+		Path:   "/_data_stream/" + url.PathEscape(name),
+	})
 	if err != nil {
-		return fmt.Errorf("error sending bulk request to Elasticsearch: %w", err)
-	}
-
-	if res.Errors {
-		for id, err := range res.Failed() {
-			a.Log.Errorf(
-				"Elasticsearch indexing failure, id: %d, status: %d, error: %s, caused by: %s, %s",
-				id,
-				err.Status,
-				err.Error.Reason,
-				err.Error.CausedBy["reason"],
-				err.Error.CausedBy["type"],
-			)
-			break
+		var eerr *elastic.Error
+		if !errors.As(err, &eerr) || eerr.Details == nil || eerr.Details.Type != "resource_already_exists_exception" {
+			return fmt.Errorf("creating data stream %q failed: %w", name, err)
 		}
-		return fmt.Errorf("elasticsearch failed to index %d metrics", len(res.Failed()))
 	}
+	a.dataStreams[name] = true
 
 	return nil
 }
@@ -359,7 +536,7 @@ func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 		return errors.New("elasticsearch template_name configuration not defined")
 	}
 
-	templateExists, errExists := a.Client.IndexTemplateExists(a.TemplateName).Do(ctx)
+	templateExists, errExists := a.templateExists(ctx)
 
 	if errExists != nil {
 		return fmt.Errorf("elasticsearch template check failed, template name: %s, error: %w", a.TemplateName, errExists)
@@ -385,7 +562,16 @@ func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 			return err
 		}
 
-		_, errCreateTemplate := a.Client.IndexPutTemplate(a.TemplateName).BodyString(data.String()).Do(ctx)
+		var errCreateTemplate error
+		if a.DataStream {
+			_, errCreateTemplate = a.Client.PerformRequest(ctx, elastic.PerformRequestOptions{
+				Method: http.MethodPut,
+				Path:   "/_index_template/" + url.PathEscape(a.TemplateName),
+				Body:   data.String(),
+			})
+		} else {
+			_, errCreateTemplate = a.Client.IndexPutTemplate(a.TemplateName).BodyString(data.String()).Do(ctx)
+		}
 		if errCreateTemplate != nil {
 			return fmt.Errorf("elasticsearch failed to create index template %s: %w", a.TemplateName, errCreateTemplate)
 		}
@@ -397,25 +583,58 @@ func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 	return nil
 }
 
+// templateExists checks for the legacy index template or, for data streams,
+// for the composable index template
+func (a *Elasticsearch) templateExists(ctx context.Context) (bool, error) {
+	if !a.DataStream {
+		return a.Client.IndexTemplateExists(a.TemplateName).Do(ctx)
+	}
+
+	res, err := a.Client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method:       http.MethodHead,
+		Path:         "/_index_template/" + url.PathEscape(a.TemplateName),
+		IgnoreErrors: []int{http.StatusNotFound},
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.StatusCode == http.StatusOK, nil
+}
+
 func (a *Elasticsearch) createNewTemplate(templatePattern string) (*bytes.Buffer, error) {
-	var indexTemplate string
-	if a.IndexTemplate != nil {
-		data, err := json.Marshal(&a.IndexTemplate)
-		if err != nil {
-			return nil, fmt.Errorf("elasticsearch failed to create index settings for template %s: %w", a.TemplateName, err)
+	indexSettings := a.IndexTemplate
+	if indexSettings == nil {
+		if err := json.Unmarshal([]byte(defaultTemplateIndexSettings), &indexSettings); err != nil {
+			return nil, err
 		}
-		indexTemplate = string(data)
-	} else {
-		indexTemplate = defaultTemplateIndexSettings
+	}
+	if a.ILMPolicy != "" {
+		settings := make(map[string]interface{}, len(indexSettings)+1)
+		for k, v := range indexSettings {
+			settings[k] = v
+		}
+		settings["lifecycle.name"] = a.ILMPolicy
+		indexSettings = settings
 	}
 
+	data, err := json.Marshal(indexSettings)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch failed to create index settings for template %s: %w", a.TemplateName, err)
+	}
+	indexTemplate := string(data)
+
 	tp := templatePart{
 		TemplatePattern: templatePattern + "*",
 		Version:         a.majorReleaseNumber,
 		IndexTemplate:   indexTemplate,
 	}
 
-	t := template.Must(template.New("template").Parse(telegrafTemplate))
+	templateText := telegrafTemplate
+	if a.DataStream {
+		templateText = dataStreamTemplate
+	}
+
+	t := template.Must(template.New("template").Parse(templateText))
 	var tmpl bytes.Buffer
 
 	if err := t.Execute(&tmpl, tp); err != nil {
@@ -541,6 +760,8 @@ func init() {
 			Timeout:             config.Duration(time.Second * 5),
 			HealthCheckInterval: config.Duration(time.Second * 10),
 			HealthCheckTimeout:  config.Duration(time.Second * 1),
+			BulkRetries:         3,
+			BulkRetryBackoff:    config.Duration(time.Second),
 		}
 	})
 }
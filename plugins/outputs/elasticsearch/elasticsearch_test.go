@@ -1,6 +1,7 @@
 package elasticsearch
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,6 +19,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -848,3 +851,221 @@ type esTemplate struct {
 type esSettings struct {
 	Index map[string]interface{} `json:"index"`
 }
+
+// bulkActions returns the action of each document in the given bulk request
+func bulkActions(t *testing.T, r *http.Request) []string {
+	var actions []string
+	scanner := bufio.NewScanner(r.Body)
+	for i := 0; scanner.Scan(); i++ {
+		if i%2 != 0 {
+			continue
+		}
+		var action map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &action))
+		for k := range action {
+			actions = append(actions, k)
+		}
+	}
+	return actions
+}
+
+func TestBulkBackpressure(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" {
+			_, err := w.Write([]byte(`{"version": {"number": "7.8"}}`))
+			require.NoError(t, err)
+			return
+		}
+
+		requests++
+		var response string
+		switch requests {
+		case 1:
+			require.Len(t, bulkActions(t, r), 3)
+			response = `{"errors": true, "items": [
+				{"index": {"status": 201}},
+				{"index": {"status": 429, "error": {"type": "es_rejected_execution_exception", "reason": "rejected"}}},
+				{"index": {"status": 400, "error": {"type": "mapper_parsing_exception", "reason": "failed to parse"}}}
+			]}`
+		default:
+			require.Len(t, bulkActions(t, r), 1)
+			response = `{"errors": false, "items": [{"index": {"status": 201}}]}`
+		}
+		_, err := w.Write([]byte(response))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:             []string{ts.URL},
+		IndexName:        "test",
+		Timeout:          config.Duration(5 * time.Second),
+		BulkRetries:      3,
+		BulkRetryBackoff: config.Duration(time.Millisecond),
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, e.Connect())
+
+	metrics := []telegraf.Metric{testutil.TestMetric(1), testutil.TestMetric(2), testutil.TestMetric(3)}
+	err := e.Write(metrics)
+	var perr *internal.PartialWriteError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, []int{0, 1}, perr.MetricsAccept)
+	require.Equal(t, []int{2}, perr.MetricsReject)
+	require.Equal(t, 2, requests)
+}
+
+func TestBulkBackpressureRequeue(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" {
+			_, err := w.Write([]byte(`{"version": {"number": "7.8"}}`))
+			require.NoError(t, err)
+			return
+		}
+
+		requests++
+		items := make([]string, 0, 2)
+		for i := range bulkActions(t, r) {
+			if requests == 1 && i == 0 {
+				items = append(items, `{"index": {"status": 201}}`)
+				continue
+			}
+			items = append(items, `{"index": {"status": 429, "error": {"type": "es_rejected_execution_exception", "reason": "rejected"}}}`)
+		}
+		_, err := fmt.Fprintf(w, `{"errors": true, "items": [%s]}`, strings.Join(items, ","))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:             []string{ts.URL},
+		IndexName:        "test",
+		Timeout:          config.Duration(5 * time.Second),
+		BulkRetries:      2,
+		BulkRetryBackoff: config.Duration(time.Millisecond),
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, e.Connect())
+
+	err := e.Write([]telegraf.Metric{testutil.TestMetric(1), testutil.TestMetric(2)})
+	var perr *internal.PartialWriteError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, []int{0}, perr.MetricsAccept)
+	require.Empty(t, perr.MetricsReject)
+	require.Equal(t, 3, requests)
+}
+
+func TestDataStream(t *testing.T) {
+	var streams []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/_bulk":
+			for _, action := range bulkActions(t, r) {
+				require.Equal(t, "create", action)
+			}
+			_, err := w.Write([]byte(`{"errors": false}`))
+			require.NoError(t, err)
+		case strings.HasPrefix(r.URL.Path, "/_data_stream/"):
+			require.Equal(t, http.MethodPut, r.Method)
+			name := strings.TrimPrefix(r.URL.Path, "/_data_stream/")
+			streams = append(streams, name)
+			if name == "metrics-existing" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, err := w.Write([]byte(`{"error": {"type": "resource_already_exists_exception", "reason": "exists"}, "status": 400}`))
+				require.NoError(t, err)
+				return
+			}
+			_, err := w.Write([]byte(`{"acknowledged": true}`))
+			require.NoError(t, err)
+		default:
+			_, err := w.Write([]byte(`{"version": {"number": "7.10.2"}}`))
+			require.NoError(t, err)
+		}
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:       []string{ts.URL},
+		IndexName:  "metrics-{{dataset}}",
+		DataStream: true,
+		Timeout:    config.Duration(5 * time.Second),
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, e.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"dataset": "system"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"dataset": "existing"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"dataset": "system"}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+	}
+	require.NoError(t, e.Write(metrics))
+	require.ElementsMatch(t, []string{"metrics-system", "metrics-existing"}, streams)
+
+	// Data streams must only be created once
+	require.NoError(t, e.Write(metrics))
+	require.Len(t, streams, 2)
+}
+
+func TestDataStreamUnsupported(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(`{"version": {"number": "7.8.1"}}`))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:       []string{ts.URL},
+		IndexName:  "metrics-telegraf",
+		DataStream: true,
+		Timeout:    config.Duration(5 * time.Second),
+		Log:        testutil.Logger{},
+	}
+	require.ErrorContains(t, e.Connect(), "data streams require Elasticsearch 7.9 or later")
+
+	e.IndexName = "metrics-%Y.%m.%d"
+	require.ErrorContains(t, e.Connect(), "date specifiers in index_name are not supported for data streams")
+}
+
+func TestDataStreamTemplate(t *testing.T) {
+	e := &Elasticsearch{
+		TemplateName:       "test",
+		IndexName:          "metrics-{{dataset}}",
+		DataStream:         true,
+		ILMPolicy:          "telegraf-30d",
+		majorReleaseNumber: 8,
+		Log:                testutil.Logger{},
+	}
+	buf, err := e.createNewTemplate("metrics-")
+	require.NoError(t, err)
+
+	var jsonData struct {
+		IndexPatterns []string               `json:"index_patterns"`
+		DataStream    map[string]interface{} `json:"data_stream"`
+		Template      esTemplate             `json:"template"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &jsonData))
+	require.Equal(t, []string{"metrics-*"}, jsonData.IndexPatterns)
+	require.NotNil(t, jsonData.DataStream)
+	index := jsonData.Template.Settings.Index
+	require.Equal(t, "telegraf-30d", index["lifecycle.name"])
+	require.Equal(t, "best_compression", index["codec"])
+}
+
+func TestILMPolicyLegacyTemplate(t *testing.T) {
+	e := &Elasticsearch{
+		TemplateName:       "test",
+		IndexName:          "telegraf-%Y.%m.%d",
+		ILMPolicy:          "telegraf-30d",
+		majorReleaseNumber: 7,
+		Log:                testutil.Logger{},
+	}
+	buf, err := e.createNewTemplate("telegraf-")
+	require.NoError(t, err)
+
+	var jsonData esTemplate
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &jsonData))
+	require.Equal(t, "telegraf-30d", jsonData.Settings.Index["lifecycle.name"])
+	require.Equal(t, "10s", jsonData.Settings.Index["refresh_interval"])
+}
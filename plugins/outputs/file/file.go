@@ -2,10 +2,12 @@
 package file
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"io"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -18,6 +20,9 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+// Interval for checking the rotation and idle timeout of templated files
+const checkInterval = time.Second
+
 type File struct {
 	Files                []string        `toml:"files"`
 	RotationInterval     config.Duration `toml:"rotation_interval"`
@@ -26,12 +31,19 @@ type File struct {
 	UseBatchFormat       bool            `toml:"use_batch_format"`
 	CompressionAlgorithm string          `toml:"compression_algorithm"`
 	CompressionLevel     int             `toml:"compression_level"`
+	IdleTimeout          config.Duration `toml:"idle_timeout"`
 	Log                  telegraf.Logger `toml:"-"`
 
 	encoder    internal.ContentEncoder
 	writer     io.Writer
 	closers    []io.Closer
 	serializer telegraf.Serializer
+	templates  []*filenameTemplate
+	files      map[string]*templatedFile
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 func (*File) SampleConfig() string {
@@ -57,14 +69,32 @@ func (f *File) Init() error {
 		options = append(options, internal.WithCompressionLevel(f.CompressionLevel))
 	}
 	f.encoder, err = internal.NewContentEncoder(f.CompressionAlgorithm, options...)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// Setup the templates for files with names depending on the metrics
+	for _, file := range f.Files {
+		if file == "stdout" || !isTemplate(file) {
+			continue
+		}
+		tmpl, err := parseFilenameTemplate(file)
+		if err != nil {
+			return fmt.Errorf("parsing file template %q failed: %w", file, err)
+		}
+		f.templates = append(f.templates, tmpl)
+	}
+
+	return nil
 }
 
 func (f *File) Connect() error {
 	var writers []io.Writer
 
 	for _, file := range f.Files {
+		if file != "stdout" && isTemplate(file) {
+			continue
+		}
 		if file == "stdout" {
 			writers = append(writers, os.Stdout)
 		} else {
@@ -79,10 +109,46 @@ func (f *File) Connect() error {
 		}
 	}
 	f.writer = io.MultiWriter(writers...)
+	f.files = make(map[string]*templatedFile)
+
+	// Finalize the templated files exceeding the rotation interval or idle
+	// timeout even if no metrics arrive
+	if len(f.templates) > 0 && (f.RotationInterval > 0 || f.IdleTimeout > 0) {
+		ctx, cancel := context.WithCancel(context.Background())
+		f.cancel = cancel
+		f.wg.Add(1)
+		go func() {
+			defer f.wg.Done()
+			ticker := time.NewTicker(checkInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case now := <-ticker.C:
+					f.mu.Lock()
+					if err := f.rotateTemplated(now); err != nil {
+						f.Log.Error(err)
+					}
+					f.mu.Unlock()
+				}
+			}
+		}()
+	}
+
 	return nil
 }
 
 func (f *File) Close() error {
+	if f.cancel != nil {
+		f.cancel()
+		f.wg.Wait()
+		f.cancel = nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
 	var err error
 	for _, c := range f.closers {
 		errClose := c.Close()
@@ -90,12 +156,28 @@ func (f *File) Close() error {
 			err = errClose
 		}
 	}
+	for filename, tf := range f.files {
+		if errClose := tf.finalize(); errClose != nil {
+			err = fmt.Errorf("finalizing %q failed: %w", filename, errClose)
+		}
+	}
+	f.files = nil
 	return err
 }
 
 func (f *File) Write(metrics []telegraf.Metric) error {
 	var writeErr error
 
+	if len(f.templates) > 0 {
+		if err := f.writeTemplated(metrics); err != nil {
+			writeErr = err
+		}
+	}
+
+	if len(f.templates) == len(f.Files) {
+		return writeErr
+	}
+
 	if f.UseBatchFormat {
 		octets, err := f.serializer.SerializeBatch(metrics)
 		if err != nil {
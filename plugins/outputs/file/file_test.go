@@ -4,11 +4,16 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -291,6 +296,159 @@ func TestFileStdout(t *testing.T) {
 	require.Equal(t, expNewFile, out.str)
 }
 
+func TestTemplatedFiles(t *testing.T) {
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+
+	dir := t.TempDir()
+	f := File{
+		Files:            []string{filepath.Join(dir, "%Y", "%m", "%d", `{{ .Tag "host" }}.lp`)},
+		serializer:       s,
+		CompressionLevel: -1,
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, f.Init())
+	require.NoError(t, f.Connect())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 2}, time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC)),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 3}, time.Date(2025, 1, 3, 3, 0, 0, 0, time.UTC)),
+	}
+	require.NoError(t, f.Write(metrics))
+
+	// Files are only available under their final name after closing
+	fileA := filepath.Join(dir, "2025", "01", "02", "a.lp")
+	require.NoFileExists(t, fileA)
+	require.FileExists(t, fileA+".tmp")
+
+	require.NoError(t, f.Close())
+	require.NoFileExists(t, fileA+".tmp")
+	validateFile(t, fileA, "cpu,host=a value=1i 1735786800000000000\n")
+	validateFile(t, filepath.Join(dir, "2025", "01", "02", "b.lp"), "cpu,host=b value=2i 1735786800000000000\n")
+	validateFile(t, filepath.Join(dir, "2025", "01", "03", "a.lp"), "cpu,host=a value=3i 1735873200000000000\n")
+}
+
+func TestTemplatedFilesTrackingMetric(t *testing.T) {
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+
+	dir := t.TempDir()
+	f := File{
+		Files:            []string{filepath.Join(dir, `{{ .Tag "host" }}.lp`)},
+		serializer:       s,
+		CompressionLevel: -1,
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, f.Init())
+	require.NoError(t, f.Connect())
+
+	var delivered bool
+	input := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	m, _ := metric.WithTracking(input, func(info telegraf.DeliveryInfo) { delivered = info.Delivered() })
+	require.NoError(t, f.Write([]telegraf.Metric{m}))
+	m.Accept()
+	require.True(t, delivered)
+
+	require.NoError(t, f.Close())
+	validateFile(t, filepath.Join(dir, "a.lp"), "cpu,host=a value=1i 0\n")
+}
+
+func TestTemplatedFilesCompressedRotation(t *testing.T) {
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+
+	dir := t.TempDir()
+	f := File{
+		Files:                []string{filepath.Join(dir, "{{ .Name }}.lp.zst")},
+		RotationMaxSize:      config.Size(10),
+		CompressionAlgorithm: "zstd",
+		CompressionLevel:     -1,
+		serializer:           s,
+		Log:                  testutil.Logger{},
+	}
+	require.NoError(t, f.Init())
+	require.NoError(t, f.Connect())
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.NoError(t, f.Write([]telegraf.Metric{m}))
+	require.NoError(t, f.Write([]telegraf.Metric{m}))
+	require.NoError(t, f.Close())
+
+	// The second file must not overwrite the first one
+	validateZstdCompressedFile(t, filepath.Join(dir, "cpu.lp.zst"), "cpu value=1i 0\n")
+	matches, err := filepath.Glob(filepath.Join(dir, "cpu.lp.*-*.zst"))
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	validateZstdCompressedFile(t, matches[0], "cpu value=1i 0\n")
+}
+
+func TestTemplatedFilesIdleTimeout(t *testing.T) {
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+
+	dir := t.TempDir()
+	f := File{
+		Files:            []string{filepath.Join(dir, "{{ .Name }}.lp")},
+		IdleTimeout:      config.Duration(100 * time.Millisecond),
+		CompressionLevel: -1,
+		serializer:       s,
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, f.Init())
+	require.NoError(t, f.Connect())
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.NoError(t, f.Write([]telegraf.Metric{m}))
+
+	// The file must be finalized without further writes
+	filename := filepath.Join(dir, "cpu.lp")
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filename)
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond)
+	require.NoFileExists(t, filename+".tmp")
+	validateFile(t, filename, "cpu value=1i 0\n")
+	require.NoError(t, f.Close())
+}
+
+func TestTemplatedFilesOutsideDirectory(t *testing.T) {
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "metrics")
+	f := File{
+		Files:            []string{filepath.Join(base, `{{ .Tag "host" }}.lp`)},
+		CompressionLevel: -1,
+		serializer:       s,
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, f.Init())
+	require.NoError(t, f.Connect())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "../escaped"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "../../escaped"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "a/../b"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+	}
+	require.NoError(t, f.Write(metrics))
+	require.NoError(t, f.Close())
+
+	// Only the file within the directory must be written
+	require.NoFileExists(t, filepath.Join(dir, "escaped.lp"))
+	require.NoFileExists(t, filepath.Join(filepath.Dir(dir), "escaped.lp"))
+	validateFile(t, filepath.Join(base, "b.lp"), "cpu,host=a/../b value=3i 0\n")
+}
+
+func TestTemplatedFilesInvalid(t *testing.T) {
+	f := File{
+		Files:            []string{"/tmp/%Q.out"},
+		CompressionLevel: -1,
+	}
+	require.ErrorContains(t, f.Init(), "unsupported time verb %Q")
+}
+
 func createFile(t *testing.T) *os.File {
 	f, err := os.CreateTemp(t.TempDir(), "")
 	require.NoError(t, err)
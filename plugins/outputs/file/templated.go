@@ -0,0 +1,347 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/rotate"
+)
+
+// Mapping of the supported strftime verbs to Golang time layouts
+var strftimeLayouts = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'H': "15",
+	'M': "04",
+	'S': "05",
+	'j': "002",
+}
+
+var strftimeRe = regexp.MustCompile(`%[A-Za-z%]`)
+
+// isTemplate checks if the given filename requires rendering per metric
+func isTemplate(filename string) bool {
+	return strings.Contains(filename, "{{") || strftimeRe.MatchString(filename)
+}
+
+// filenameTemplate is a parsed filename template restricted to the directory
+// given by the static part of the filename.
+type filenameTemplate struct {
+	*template.Template
+	dir string
+}
+
+// parseFilenameTemplate converts the strftime verbs outside of template
+// actions into actions formatting the metric time and parses the result.
+func parseFilenameTemplate(filename string) (*filenameTemplate, error) {
+	var b strings.Builder
+	var depth int
+	for i := 0; i < len(filename); i++ {
+		switch {
+		case strings.HasPrefix(filename[i:], "{{"):
+			depth++
+			b.WriteString("{{")
+			i++
+		case strings.HasPrefix(filename[i:], "}}") && depth > 0:
+			depth--
+			b.WriteString("}}")
+			i++
+		case filename[i] == '%' && depth == 0 && i+1 < len(filename):
+			verb := filename[i+1]
+			if verb == '%' {
+				b.WriteByte('%')
+				i++
+				continue
+			}
+			layout, found := strftimeLayouts[verb]
+			if !found {
+				return nil, fmt.Errorf("unsupported time verb %%%c", verb)
+			}
+			b.WriteString(`{{.Time.Format "` + layout + `"}}`)
+			i++
+		default:
+			b.WriteByte(filename[i])
+		}
+	}
+
+	tmpl, err := template.New(filename).Funcs(sprig.TxtFuncMap()).Parse(b.String())
+	if err != nil {
+		return nil, err
+	}
+
+	// Rendered values must not escape the directory of the static prefix,
+	// e.g. using tags containing path separators and parent references
+	prefix := filename
+	if i := strings.Index(prefix, "{{"); i >= 0 {
+		prefix = prefix[:i]
+	}
+	if loc := strftimeRe.FindStringIndex(prefix); loc != nil {
+		prefix = prefix[:loc[0]]
+	}
+
+	return &filenameTemplate{Template: tmpl, dir: filepath.Dir(prefix)}, nil
+}
+
+// render executes the template for the given metric and returns the cleaned
+// filename, which must be located within the directory of the template.
+func (t *filenameTemplate) render(m telegraf.Metric) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, m); err != nil {
+		return "", err
+	}
+	filename := filepath.Clean(b.String())
+
+	rel, err := filepath.Rel(t.dir, filename)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("filename %q is outside of directory %q", b.String(), t.dir)
+	}
+	return filename, nil
+}
+
+// templatedFile is a file with a name rendered from the metrics. Data is
+// written to a temporary file which is atomically renamed to the final name
+// when the file is finalized.
+type templatedFile struct {
+	filename   string
+	file       *os.File
+	compressor io.WriteCloser
+	created    time.Time
+	lastWrite  time.Time
+	size       int64
+}
+
+func openTemplatedFile(filename, algorithm string, level int) (*templatedFile, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), 0750); err != nil {
+		return nil, fmt.Errorf("creating directory failed: %w", err)
+	}
+
+	// A left-over temporary file, e.g. due to a crash, is moved aside
+	// before starting a new one as it might contain an incomplete stream.
+	tmpname := filename + ".tmp"
+	if _, err := os.Stat(tmpname); err == nil {
+		if err := os.Rename(tmpname, uniqueFilename(filename)); err != nil {
+			return nil, fmt.Errorf("moving left-over temporary file failed: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(tmpname, os.O_RDWR|os.O_CREATE|os.O_EXCL, rotate.FilePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	compressor, err := newCompressor(file, algorithm, level)
+	if err != nil {
+		file.Close()
+		os.Remove(tmpname)
+		return nil, err
+	}
+
+	now := time.Now()
+	return &templatedFile{
+		filename:   filename,
+		file:       file,
+		compressor: compressor,
+		created:    now,
+		lastWrite:  now,
+	}, nil
+}
+
+func (tf *templatedFile) Write(p []byte) (int, error) {
+	var n int
+	var err error
+	if tf.compressor != nil {
+		n, err = tf.compressor.Write(p)
+	} else {
+		n, err = tf.file.Write(p)
+	}
+	tf.size += int64(n)
+	tf.lastWrite = time.Now()
+	return n, err
+}
+
+// finalize completes the compression stream, closes the file and renames it
+// to its final name. If a file with the final name already exists, e.g.
+// after rotation, a unique name is used instead.
+func (tf *templatedFile) finalize() error {
+	var errs []error
+	if tf.compressor != nil {
+		if err := tf.compressor.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing compressor failed: %w", err))
+		}
+	}
+	if err := tf.file.Sync(); err != nil {
+		errs = append(errs, fmt.Errorf("syncing file failed: %w", err))
+	}
+	if err := tf.file.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("closing file failed: %w", err))
+	}
+
+	filename := tf.filename
+	if _, err := os.Stat(filename); err == nil {
+		filename = uniqueFilename(filename)
+	}
+	if err := os.Rename(tf.file.Name(), filename); err != nil {
+		errs = append(errs, fmt.Errorf("renaming file failed: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// uniqueFilename inserts the current time in front of the extension using
+// the same scheme as the rotation of static files.
+func uniqueFilename(filename string) string {
+	ext := filepath.Ext(filename)
+	stem := strings.TrimSuffix(filename, ext)
+
+	now := time.Now()
+	base := fmt.Sprintf("%s.%s-%s", stem, now.Format(rotate.DateFormat), strconv.FormatInt(now.Unix(), 10))
+	candidate := base + ext
+	for i := 1; ; i++ {
+		if _, err := os.Stat(candidate); errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+}
+
+func newCompressor(w io.Writer, algorithm string, level int) (io.WriteCloser, error) {
+	switch algorithm {
+	case "", "identity":
+		return nil, nil
+	case "gzip":
+		if level < 0 {
+			level = gzip.DefaultCompression
+		}
+		return gzip.NewWriterLevel(w, level)
+	case "zlib":
+		if level < 0 {
+			level = zlib.DefaultCompression
+		}
+		return zlib.NewWriterLevel(w, level)
+	case "zstd":
+		var l zstd.EncoderLevel
+		switch level {
+		case 1:
+			l = zstd.SpeedFastest
+		case -1, 3:
+			l = zstd.SpeedDefault
+		case 7:
+			l = zstd.SpeedBetterCompression
+		case 11:
+			l = zstd.SpeedBestCompression
+		default:
+			return nil, errors.New("invalid compression level, only 1, 3, 7 and 11 are supported")
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(l))
+	}
+	return nil, fmt.Errorf("unsupported compression algorithm %q", algorithm)
+}
+
+// writeTemplated renders the filenames for the metrics and writes the
+// metrics to the corresponding files, opening new files as required.
+func (f *File) writeTemplated(metrics []telegraf.Metric) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, tmpl := range f.templates {
+		// Group the metrics by filename keeping the order of the files
+		var filenames []string
+		groups := make(map[string][]telegraf.Metric)
+		for _, m := range metrics {
+			// Render the filename from the underlying metric as tracking
+			// metrics don't provide the tag and field accessors
+			var rm telegraf.Metric = m
+			if wm, ok := m.(telegraf.UnwrappableMetric); ok {
+				rm = wm.Unwrap()
+			}
+
+			filename, err := tmpl.render(rm)
+			if err != nil {
+				f.Log.Errorf("Rendering filename %q failed: %v", tmpl.Name(), err)
+				continue
+			}
+			if _, found := groups[filename]; !found {
+				filenames = append(filenames, filename)
+			}
+			groups[filename] = append(groups[filename], m)
+		}
+
+		for _, filename := range filenames {
+			if err := f.writeFile(filename, groups[filename]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return f.rotateTemplated(time.Now())
+}
+
+func (f *File) writeFile(filename string, metrics []telegraf.Metric) error {
+	tf, found := f.files[filename]
+	if !found {
+		var err error
+		tf, err = openTemplatedFile(filename, f.CompressionAlgorithm, f.CompressionLevel)
+		if err != nil {
+			return fmt.Errorf("opening file %q failed: %w", filename, err)
+		}
+		f.files[filename] = tf
+	}
+
+	if f.UseBatchFormat {
+		octets, err := f.serializer.SerializeBatch(metrics)
+		if err != nil {
+			f.Log.Errorf("Could not serialize metric: %v", err)
+			return nil
+		}
+		if _, err := tf.Write(octets); err != nil {
+			return fmt.Errorf("failed to write to %q: %w", filename, err)
+		}
+		return nil
+	}
+
+	for _, metric := range metrics {
+		b, err := f.serializer.Serialize(metric)
+		if err != nil {
+			f.Log.Debugf("Could not serialize metric: %v", err)
+			continue
+		}
+		if _, err := tf.Write(b); err != nil {
+			return fmt.Errorf("failed to write to %q: %w", filename, err)
+		}
+	}
+	return nil
+}
+
+// rotateTemplated finalizes the files exceeding the rotation limits or not
+// being written to for longer than the idle timeout.
+func (f *File) rotateTemplated(now time.Time) error {
+	var errs []error
+	for filename, tf := range f.files {
+		expired := f.RotationInterval > 0 && now.Sub(tf.created) >= time.Duration(f.RotationInterval)
+		oversized := f.RotationMaxSize > 0 && tf.size >= int64(f.RotationMaxSize)
+		idle := f.IdleTimeout > 0 && now.Sub(tf.lastWrite) >= time.Duration(f.IdleTimeout)
+		if !expired && !oversized && !idle {
+			continue
+		}
+		delete(f.files, filename)
+		if err := tf.finalize(); err != nil {
+			errs = append(errs, fmt.Errorf("finalizing %q failed: %w", filename, err))
+		}
+	}
+	return errors.Join(errs...)
+}
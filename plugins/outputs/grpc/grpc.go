@@ -0,0 +1,408 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package grpc
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+var userAgent = internal.ProductToken()
+
+//go:embed sample.conf
+var sampleConfig string
+
+type GRPC struct {
+	Address             string            `toml:"address"`
+	DescriptorSet       string            `toml:"descriptor_set"`
+	Method              string            `toml:"method"`
+	Mode                string            `toml:"mode"`
+	BatchField          string            `toml:"batch_field"`
+	Mapping             map[string]string `toml:"mapping"`
+	TimePrecision       config.Duration   `toml:"time_precision"`
+	Timeout             config.Duration   `toml:"timeout"`
+	MaxAttempts         int               `toml:"max_attempts"`
+	RetryInitialBackoff config.Duration   `toml:"retry_initial_backoff"`
+	RetryMaxBackoff     config.Duration   `toml:"retry_max_backoff"`
+	RetryCodes          []string          `toml:"retry_codes"`
+	Headers             map[string]string `toml:"headers"`
+	Log                 telegraf.Logger   `toml:"-"`
+	tls.ClientConfig
+
+	fullMethod    string
+	input         protoreflect.MessageDescriptor
+	output        protoreflect.MessageDescriptor
+	batch         protoreflect.FieldDescriptor
+	mappings      []*fieldMapping
+	serviceConfig string
+	conn          *grpc.ClientConn
+}
+
+func (*GRPC) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GRPC) Init() error {
+	if g.Address == "" {
+		return errors.New("'address' required")
+	}
+	if g.DescriptorSet == "" {
+		return errors.New("'descriptor_set' required")
+	}
+	if len(g.Mapping) == 0 {
+		return errors.New("'mapping' required")
+	}
+	if g.TimePrecision <= 0 {
+		return errors.New("'time_precision' must be positive")
+	}
+	if g.MaxAttempts < 1 || g.MaxAttempts > 5 {
+		return errors.New("'max_attempts' must be between 1 and 5")
+	}
+
+	// Lookup the method in the descriptor set
+	serviceName, methodName, found := strings.Cut(strings.TrimPrefix(g.Method, "/"), "/")
+	if !found || serviceName == "" || methodName == "" {
+		return fmt.Errorf("invalid method %q, expected <package>.<service>/<method>", g.Method)
+	}
+	method, err := g.findMethod(serviceName, methodName)
+	if err != nil {
+		return err
+	}
+	g.fullMethod = "/" + serviceName + "/" + methodName
+	g.input = method.Input()
+	g.output = method.Output()
+
+	switch g.Mode {
+	case "", "unary":
+		g.Mode = "unary"
+		if method.IsStreamingClient() || method.IsStreamingServer() {
+			return fmt.Errorf("method %q is not a unary method", g.Method)
+		}
+	case "client_streaming":
+		if !method.IsStreamingClient() || method.IsStreamingServer() {
+			return fmt.Errorf("method %q is not a client-streaming method", g.Method)
+		}
+		if g.BatchField != "" {
+			return errors.New("'batch_field' is not supported in client-streaming mode")
+		}
+	default:
+		return fmt.Errorf("invalid mode %q", g.Mode)
+	}
+
+	// Determine the message the metrics are mapped to
+	target := g.input
+	if g.BatchField != "" {
+		fd := g.input.Fields().ByName(protoreflect.Name(g.BatchField))
+		if fd == nil || !fd.IsList() || fd.Kind() != protoreflect.MessageKind {
+			return fmt.Errorf("batch field %q is not a repeated message field of %q", g.BatchField, g.input.FullName())
+		}
+		g.batch = fd
+		target = fd.Message()
+	}
+	for field, source := range g.Mapping {
+		m, err := newFieldMapping(target, field, source)
+		if err != nil {
+			return fmt.Errorf("invalid mapping for field %q: %w", field, err)
+		}
+		g.mappings = append(g.mappings, m)
+	}
+
+	// Setup the retry policy of the method
+	if g.MaxAttempts > 1 {
+		if len(g.RetryCodes) == 0 {
+			return errors.New("'retry_codes' required for retries")
+		}
+		for _, name := range g.RetryCodes {
+			var c codes.Code
+			if err := c.UnmarshalJSON([]byte(`"` + name + `"`)); err != nil {
+				return fmt.Errorf("invalid retry code %q", name)
+			}
+		}
+		if g.RetryInitialBackoff <= 0 || g.RetryMaxBackoff <= 0 {
+			return errors.New("retry backoffs must be positive")
+		}
+		serviceConfig, err := g.buildServiceConfig(serviceName, methodName)
+		if err != nil {
+			return fmt.Errorf("creating service config failed: %w", err)
+		}
+		g.serviceConfig = serviceConfig
+	}
+
+	return nil
+}
+
+func (g *GRPC) Connect() error {
+	tlsCfg, err := g.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	creds := insecure.NewCredentials()
+	if tlsCfg != nil {
+		creds = credentials.NewTLS(tlsCfg)
+	}
+
+	options := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUserAgent(userAgent),
+	}
+	if g.serviceConfig != "" {
+		options = append(options, grpc.WithDefaultServiceConfig(g.serviceConfig))
+	}
+
+	conn, err := grpc.NewClient(g.Address, options...)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	g.conn = conn
+
+	return nil
+}
+
+func (g *GRPC) Close() error {
+	if g.conn == nil {
+		return nil
+	}
+	err := g.conn.Close()
+	g.conn = nil
+	return err
+}
+
+func (g *GRPC) Write(metrics []telegraf.Metric) error {
+	switch {
+	case g.Mode == "client_streaming":
+		return g.writeStream(metrics)
+	case g.batch != nil:
+		return g.writeBatch(metrics)
+	}
+	return g.writeUnary(metrics)
+}
+
+// writeUnary sends one request per metric
+func (g *GRPC) writeUnary(metrics []telegraf.Metric) error {
+	var accepted, rejected []int
+	for i, m := range metrics {
+		request := dynamicpb.NewMessage(g.input)
+		if err := g.fill(request, m); err != nil {
+			g.Log.Errorf("Mapping metric %v failed: %v", m, err)
+			rejected = append(rejected, i)
+			continue
+		}
+
+		ctx, cancel := g.context()
+		err := g.conn.Invoke(ctx, g.fullMethod, request, dynamicpb.NewMessage(g.output))
+		cancel()
+		if err != nil {
+			// Stop at the first failing call as the remaining calls are
+			// likely to fail as well and retry the metrics later
+			return writeResult(err, accepted, rejected)
+		}
+		accepted = append(accepted, i)
+	}
+
+	return writeResult(nil, accepted, rejected)
+}
+
+// writeBatch sends a single request containing all metrics in the batch field
+func (g *GRPC) writeBatch(metrics []telegraf.Metric) error {
+	var accepted, rejected []int
+	request := dynamicpb.NewMessage(g.input)
+	entries := request.Mutable(g.batch).List()
+	for i, m := range metrics {
+		entry := entries.NewElement()
+		if err := g.fill(entry.Message(), m); err != nil {
+			g.Log.Errorf("Mapping metric %v failed: %v", m, err)
+			rejected = append(rejected, i)
+			continue
+		}
+		entries.Append(entry)
+		accepted = append(accepted, i)
+	}
+	if len(accepted) == 0 {
+		return writeResult(nil, nil, rejected)
+	}
+
+	ctx, cancel := g.context()
+	defer cancel()
+	if err := g.conn.Invoke(ctx, g.fullMethod, request, dynamicpb.NewMessage(g.output)); err != nil {
+		return writeResult(err, nil, rejected)
+	}
+
+	return writeResult(nil, accepted, rejected)
+}
+
+// writeStream sends one message per metric in a single client stream
+func (g *GRPC) writeStream(metrics []telegraf.Metric) error {
+	ctx, cancel := g.context()
+	defer cancel()
+
+	stream, err := g.conn.NewStream(ctx, &grpc.StreamDesc{ClientStreams: true}, g.fullMethod)
+	if err != nil {
+		return fmt.Errorf("opening stream failed: %w", err)
+	}
+
+	var accepted, rejected []int
+	for i, m := range metrics {
+		request := dynamicpb.NewMessage(g.input)
+		if err := g.fill(request, m); err != nil {
+			g.Log.Errorf("Mapping metric %v failed: %v", m, err)
+			rejected = append(rejected, i)
+			continue
+		}
+		// The actual error is returned when receiving the response
+		if err := stream.SendMsg(request); err != nil {
+			break
+		}
+		accepted = append(accepted, i)
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		return writeResult(fmt.Errorf("closing stream failed: %w", err), nil, rejected)
+	}
+	if err := stream.RecvMsg(dynamicpb.NewMessage(g.output)); err != nil {
+		return writeResult(err, nil, rejected)
+	}
+
+	return writeResult(nil, accepted, rejected)
+}
+
+// fill populates the given message from the metric using the mappings
+func (g *GRPC) fill(msg protoreflect.Message, m telegraf.Metric) error {
+	for _, mapping := range g.mappings {
+		if err := mapping.apply(msg, m, time.Duration(g.TimePrecision)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// context returns the context for a call including the deadline and headers
+func (g *GRPC) context() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(g.Timeout))
+	if len(g.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(g.Headers))
+	}
+	return ctx, cancel
+}
+
+func (g *GRPC) findMethod(serviceName, methodName string) (protoreflect.MethodDescriptor, error) {
+	buf, err := os.ReadFile(g.DescriptorSet)
+	if err != nil {
+		return nil, fmt.Errorf("reading descriptor set failed: %w", err)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(buf, &set); err != nil {
+		return nil, fmt.Errorf("decoding descriptor set failed: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("resolving descriptor set failed: %w", err)
+	}
+
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("looking up service %q failed: %w", serviceName, err)
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a service", serviceName)
+	}
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil, fmt.Errorf("unknown method %q in service %q", methodName, serviceName)
+	}
+
+	return method, nil
+}
+
+// buildServiceConfig creates a gRPC service config containing the retry
+// policy for the configured method
+func (g *GRPC) buildServiceConfig(serviceName, methodName string) (string, error) {
+	type retryPolicy struct {
+		MaxAttempts          int      `json:"maxAttempts"`
+		InitialBackoff       string   `json:"initialBackoff"`
+		MaxBackoff           string   `json:"maxBackoff"`
+		BackoffMultiplier    float64  `json:"backoffMultiplier"`
+		RetryableStatusCodes []string `json:"retryableStatusCodes"`
+	}
+	type methodID struct {
+		Service string `json:"service"`
+		Method  string `json:"method"`
+	}
+	type methodConfig struct {
+		Name        []methodID  `json:"name"`
+		RetryPolicy retryPolicy `json:"retryPolicy"`
+	}
+	type serviceConfig struct {
+		MethodConfig []methodConfig `json:"methodConfig"`
+	}
+
+	cfg := serviceConfig{
+		MethodConfig: []methodConfig{
+			{
+				Name: []methodID{{Service: serviceName, Method: methodName}},
+				RetryPolicy: retryPolicy{
+					MaxAttempts:          g.MaxAttempts,
+					InitialBackoff:       fmt.Sprintf("%gs", time.Duration(g.RetryInitialBackoff).Seconds()),
+					MaxBackoff:           fmt.Sprintf("%gs", time.Duration(g.RetryMaxBackoff).Seconds()),
+					BackoffMultiplier:    2,
+					RetryableStatusCodes: g.RetryCodes,
+				},
+			},
+		},
+	}
+	buf, err := json.Marshal(cfg)
+	return string(buf), err
+}
+
+// writeResult creates the error to return for a write with the given
+// accepted and rejected metric indices, metrics neither accepted nor rejected
+// are kept for the next write
+func writeResult(err error, accepted, rejected []int) error {
+	if len(rejected) == 0 && (err == nil || len(accepted) == 0) {
+		return err
+	}
+	if err == nil {
+		err = fmt.Errorf("mapping %d metric(s) failed", len(rejected))
+	}
+	return &internal.PartialWriteError{
+		Err:           err,
+		MetricsAccept: accepted,
+		MetricsReject: rejected,
+	}
+}
+
+func init() {
+	outputs.Add("grpc", func() telegraf.Output {
+		return &GRPC{
+			TimePrecision:       config.Duration(time.Nanosecond),
+			Timeout:             config.Duration(5 * time.Second),
+			MaxAttempts:         3,
+			RetryInitialBackoff: config.Duration(100 * time.Millisecond),
+			RetryMaxBackoff:     config.Duration(5 * time.Second),
+			RetryCodes:          []string{"UNAVAILABLE"},
+		}
+	})
+}
@@ -0,0 +1,450 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	descriptors := createDescriptorSet(t)
+
+	tests := []struct {
+		name     string
+		modify   func(*GRPC)
+		expected string
+	}{
+		{
+			name:     "empty address",
+			modify:   func(g *GRPC) { g.Address = "" },
+			expected: "'address' required",
+		},
+		{
+			name:     "missing descriptor set",
+			modify:   func(g *GRPC) { g.DescriptorSet = filepath.Join(t.TempDir(), "missing.pb") },
+			expected: "reading descriptor set failed",
+		},
+		{
+			name:     "invalid method",
+			modify:   func(g *GRPC) { g.Method = "test.MetricService" },
+			expected: "invalid method",
+		},
+		{
+			name:     "unknown service",
+			modify:   func(g *GRPC) { g.Method = "test.Unknown/Send" },
+			expected: `looking up service "test.Unknown" failed`,
+		},
+		{
+			name:     "unknown method",
+			modify:   func(g *GRPC) { g.Method = "test.MetricService/Unknown" },
+			expected: `unknown method "Unknown"`,
+		},
+		{
+			name:     "streaming method in unary mode",
+			modify:   func(g *GRPC) { g.Method = "test.MetricService/Stream" },
+			expected: "is not a unary method",
+		},
+		{
+			name:     "unary method in streaming mode",
+			modify:   func(g *GRPC) { g.Mode = "client_streaming" },
+			expected: "is not a client-streaming method",
+		},
+		{
+			name:     "invalid mode",
+			modify:   func(g *GRPC) { g.Mode = "bidi" },
+			expected: `invalid mode "bidi"`,
+		},
+		{
+			name:     "invalid batch field",
+			modify:   func(g *GRPC) { g.Method = "test.MetricService/SendBatch"; g.BatchField = "count" },
+			expected: `batch field "count" is not a repeated message field`,
+		},
+		{
+			name:     "unknown field",
+			modify:   func(g *GRPC) { g.Mapping = map[string]string{"unknown": "name"} },
+			expected: `unknown field "unknown"`,
+		},
+		{
+			name:     "nested non-message field",
+			modify:   func(g *GRPC) { g.Mapping = map[string]string{"host.value": "name"} },
+			expected: "is not a singular message",
+		},
+		{
+			name:     "invalid source",
+			modify:   func(g *GRPC) { g.Mapping = map[string]string{"host": "measurement"} },
+			expected: `invalid source "measurement"`,
+		},
+		{
+			name:     "tags to non-map field",
+			modify:   func(g *GRPC) { g.Mapping = map[string]string{"host": "tags"} },
+			expected: "must be a map of strings",
+		},
+		{
+			name:     "time to unsupported field",
+			modify:   func(g *GRPC) { g.Mapping = map[string]string{"sample": "time"} },
+			expected: "must be a google.protobuf.Timestamp",
+		},
+		{
+			name:     "invalid retry code",
+			modify:   func(g *GRPC) { g.RetryCodes = []string{"FOO"} },
+			expected: `invalid retry code "FOO"`,
+		},
+		{
+			name:     "invalid max attempts",
+			modify:   func(g *GRPC) { g.MaxAttempts = 6 },
+			expected: "'max_attempts' must be between 1 and 5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &GRPC{
+				Address:             "localhost:50051",
+				DescriptorSet:       descriptors,
+				Method:              "test.MetricService/Send",
+				Mapping:             map[string]string{"name": "name"},
+				TimePrecision:       config.Duration(time.Nanosecond),
+				Timeout:             config.Duration(5 * time.Second),
+				MaxAttempts:         3,
+				RetryInitialBackoff: config.Duration(100 * time.Millisecond),
+				RetryMaxBackoff:     config.Duration(5 * time.Second),
+				RetryCodes:          []string{"UNAVAILABLE"},
+				Log:                 testutil.Logger{},
+			}
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		mode       string
+		batchField string
+		expected   []string
+	}{
+		{
+			name:   "unary",
+			method: "test.MetricService/Send",
+			expected: []string{
+				"/test.MetricService/Send",
+				`{"name":"cpu","timestamp":"2024-06-10T06:00:00.500Z","timeMs":"1717999200500","host":"server01",` +
+					`"labels":{"host":"server01","cpu":"cpu0","level":"CRITICAL"},"values":{"usage":42.5,"level":2},"sample":{"value":42.5,"level":"CRITICAL"}}`,
+				"/test.MetricService/Send",
+				`{"name":"mem","timestamp":"2024-06-10T06:00:01Z","timeMs":"1717999201000","host":"server02",` +
+					`"labels":{"host":"server02"},"values":{"used":1024}}`,
+			},
+		},
+		{
+			name:       "unary batch",
+			method:     "test.MetricService/SendBatch",
+			batchField: "metrics",
+			expected: []string{
+				"/test.MetricService/SendBatch",
+				`{"metrics":[{"name":"cpu","timestamp":"2024-06-10T06:00:00.500Z","timeMs":"1717999200500","host":"server01",` +
+					`"labels":{"host":"server01","cpu":"cpu0","level":"CRITICAL"},"values":{"usage":42.5,"level":2},"sample":{"value":42.5,"level":"CRITICAL"}},` +
+					`{"name":"mem","timestamp":"2024-06-10T06:00:01Z","timeMs":"1717999201000","host":"server02",` +
+					`"labels":{"host":"server02"},"values":{"used":1024}}]}`,
+			},
+		},
+		{
+			name:   "client streaming",
+			method: "test.MetricService/Stream",
+			mode:   "client_streaming",
+			expected: []string{
+				"/test.MetricService/Stream",
+				`{"name":"cpu","timestamp":"2024-06-10T06:00:00.500Z","timeMs":"1717999200500","host":"server01",` +
+					`"labels":{"host":"server01","cpu":"cpu0","level":"CRITICAL"},"values":{"usage":42.5,"level":2},"sample":{"value":42.5,"level":"CRITICAL"}}`,
+				`{"name":"mem","timestamp":"2024-06-10T06:00:01Z","timeMs":"1717999201000","host":"server02",` +
+					`"labels":{"host":"server02"},"values":{"used":1024}}`,
+			},
+		},
+	}
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "server01", "cpu": "cpu0", "level": "CRITICAL"},
+			map[string]interface{}{"usage": 42.5, "level": int64(2)},
+			time.Date(2024, 6, 10, 6, 0, 0, 500000000, time.UTC),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "server02"},
+			map[string]interface{}{"used": int64(1024)},
+			time.Date(2024, 6, 10, 6, 0, 1, 0, time.UTC),
+		),
+	}
+
+	descriptors := createDescriptorSet(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, descriptors, nil)
+
+			plugin := &GRPC{
+				Address:       server.address,
+				DescriptorSet: descriptors,
+				Method:        tt.method,
+				Mode:          tt.mode,
+				BatchField:    tt.batchField,
+				Mapping: map[string]string{
+					"name":         "name",
+					"timestamp":    "time",
+					"time_ms":      "time",
+					"host":         "tag:host",
+					"labels":       "tags",
+					"values":       "fields",
+					"sample.value": "field:usage",
+					"sample.level": "tag:level",
+				},
+				TimePrecision: config.Duration(time.Millisecond),
+				Timeout:       config.Duration(5 * time.Second),
+				MaxAttempts:   1,
+				Log:           testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Connect())
+			defer plugin.Close()
+
+			require.NoError(t, plugin.Write(metrics))
+
+			actual := server.received()
+			require.Len(t, actual, len(tt.expected))
+			for i, expected := range tt.expected {
+				if expected[0] == '/' {
+					require.Equal(t, expected, actual[i])
+					continue
+				}
+				require.JSONEq(t, expected, actual[i])
+			}
+		})
+	}
+}
+
+func TestWriteMappingError(t *testing.T) {
+	descriptors := createDescriptorSet(t)
+	server := newTestServer(t, descriptors, nil)
+
+	plugin := &GRPC{
+		Address:       server.address,
+		DescriptorSet: descriptors,
+		Method:        "test.MetricService/Send",
+		Mapping: map[string]string{
+			"name":         "name",
+			"sample.level": "field:level",
+		},
+		TimePrecision: config.Duration(time.Nanosecond),
+		Timeout:       config.Duration(5 * time.Second),
+		MaxAttempts:   1,
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("ok", map[string]string{}, map[string]interface{}{"level": "OK"}, time.Unix(0, 0)),
+		metric.New("invalid", map[string]string{}, map[string]interface{}{"level": "BOGUS"}, time.Unix(0, 0)),
+	}
+	err := plugin.Write(metrics)
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Equal(t, []int{0}, writeErr.MetricsAccept)
+	require.Equal(t, []int{1}, writeErr.MetricsReject)
+	require.Len(t, server.received(), 2)
+}
+
+func TestWriteRetry(t *testing.T) {
+	descriptors := createDescriptorSet(t)
+
+	var mu sync.Mutex
+	var attempts int
+	server := newTestServer(t, descriptors, func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 3 {
+			return status.Error(codes.Unavailable, "try again")
+		}
+		return nil
+	})
+
+	plugin := &GRPC{
+		Address:             server.address,
+		DescriptorSet:       descriptors,
+		Method:              "test.MetricService/Send",
+		Mapping:             map[string]string{"name": "name"},
+		TimePrecision:       config.Duration(time.Nanosecond),
+		Timeout:             config.Duration(5 * time.Second),
+		MaxAttempts:         3,
+		RetryInitialBackoff: config.Duration(10 * time.Millisecond),
+		RetryMaxBackoff:     config.Duration(50 * time.Millisecond),
+		RetryCodes:          []string{"UNAVAILABLE"},
+		Log:                 testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	mu.Lock()
+	require.Equal(t, 3, attempts)
+	mu.Unlock()
+
+	// Non-retryable errors must be returned immediately and the metrics kept
+	server.Lock()
+	server.fail = func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		return status.Error(codes.InvalidArgument, "invalid")
+	}
+	server.Unlock()
+	err := plugin.Write([]telegraf.Metric{m})
+	require.ErrorContains(t, err, "invalid")
+	var writeErr *internal.PartialWriteError
+	require.False(t, errors.As(err, &writeErr))
+	mu.Lock()
+	require.Equal(t, 4, attempts)
+	mu.Unlock()
+}
+
+type testServer struct {
+	address  string
+	fail     func() error
+	messages []string
+	sync.Mutex
+}
+
+func (s *testServer) received() []string {
+	s.Lock()
+	defer s.Unlock()
+	return append([]string(nil), s.messages...)
+}
+
+func newTestServer(t *testing.T, descriptors string, fail func() error) *testServer {
+	t.Helper()
+
+	// Resolve the message types of the methods from the descriptor set
+	buf, err := os.ReadFile(descriptors)
+	require.NoError(t, err)
+	var set descriptorpb.FileDescriptorSet
+	require.NoError(t, proto.Unmarshal(buf, &set))
+	files, err := protodesc.NewFiles(&set)
+	require.NoError(t, err)
+	desc, err := files.FindDescriptorByName("test.MetricService")
+	require.NoError(t, err)
+	methods := desc.(protoreflect.ServiceDescriptor).Methods()
+
+	s := &testServer{fail: fail}
+	handler := func(_ interface{}, stream grpc.ServerStream) error {
+		name, _ := grpc.MethodFromServerStream(stream)
+		s.Lock()
+		s.messages = append(s.messages, name)
+		fail := s.fail
+		s.Unlock()
+
+		method := methods.ByName(protoreflect.Name(filepath.Base(name)))
+		if method == nil {
+			return status.Error(codes.Unimplemented, "unknown method")
+		}
+		for {
+			msg := dynamicpb.NewMessage(method.Input())
+			if err := stream.RecvMsg(msg); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return err
+			}
+			encoded, err := protojson.Marshal(msg)
+			if err != nil {
+				return err
+			}
+			s.Lock()
+			s.messages = append(s.messages, string(encoded))
+			s.Unlock()
+			if !method.IsStreamingClient() {
+				break
+			}
+		}
+		if fail != nil {
+			if err := fail(); err != nil {
+				return err
+			}
+		}
+		return stream.SendMsg(dynamicpb.NewMessage(method.Output()))
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer(grpc.UnknownServiceHandler(handler))
+	go server.Serve(listener) //nolint:errcheck // Ignore the returned error as we cannot do anything about it anyway
+	t.Cleanup(server.Stop)
+	s.address = listener.Addr().String()
+
+	return s
+}
+
+// createDescriptorSet compiles the test service definition into a file
+// descriptor set including all imports
+func createDescriptorSet(t *testing.T) string {
+	t.Helper()
+
+	compiler := &protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: []string{"testdata"}}),
+	}
+	files, err := compiler.Compile(context.Background(), "service.proto")
+	require.NoError(t, err)
+
+	var set descriptorpb.FileDescriptorSet
+	seen := make(map[string]bool)
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, fd := range files {
+		add(fd)
+	}
+
+	buf, err := proto.Marshal(&set)
+	require.NoError(t, err)
+	fn := filepath.Join(t.TempDir(), "service.pb")
+	require.NoError(t, os.WriteFile(fn, buf, 0600))
+
+	return fn
+}
@@ -0,0 +1,213 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// fieldMapping describes how to fill a message field from a metric
+type fieldMapping struct {
+	// path contains the field descriptors from the message down to the
+	// target field, all but the last field are singular message fields
+	path   []protoreflect.FieldDescriptor
+	source string
+	key    string
+}
+
+// newFieldMapping resolves the dot-separated field path in the given message
+// and checks if the field is compatible with the given metric source
+func newFieldMapping(msg protoreflect.MessageDescriptor, field, source string) (*fieldMapping, error) {
+	m := &fieldMapping{source: source}
+	if kind, key, found := strings.Cut(source, ":"); found {
+		m.source, m.key = kind, key
+	}
+
+	current := msg
+	parts := strings.Split(field, ".")
+	for i, name := range parts {
+		fd := current.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return nil, fmt.Errorf("unknown field %q in message %q", name, current.FullName())
+		}
+		m.path = append(m.path, fd)
+		if i == len(parts)-1 {
+			break
+		}
+		if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+			return nil, fmt.Errorf("field %q is not a singular message", fd.FullName())
+		}
+		current = fd.Message()
+	}
+
+	fd := m.path[len(m.path)-1]
+	switch m.source {
+	case "tags":
+		if !fd.IsMap() || fd.MapKey().Kind() != protoreflect.StringKind || fd.MapValue().Kind() != protoreflect.StringKind {
+			return nil, fmt.Errorf("field %q must be a map of strings for source %q", fd.FullName(), source)
+		}
+	case "fields":
+		if !fd.IsMap() || fd.MapKey().Kind() != protoreflect.StringKind || !isScalar(fd.MapValue()) {
+			return nil, fmt.Errorf("field %q must be a map with string keys and scalar values for source %q", fd.FullName(), source)
+		}
+	case "time":
+		if fd.IsList() || fd.IsMap() {
+			return nil, fmt.Errorf("field %q must be singular for source %q", fd.FullName(), source)
+		}
+		switch fd.Kind() {
+		case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+			protoreflect.Uint64Kind, protoreflect.Fixed64Kind,
+			protoreflect.DoubleKind, protoreflect.StringKind:
+		case protoreflect.MessageKind:
+			if fd.Message().FullName() != "google.protobuf.Timestamp" {
+				return nil, fmt.Errorf("message field %q must be a google.protobuf.Timestamp for source %q", fd.FullName(), source)
+			}
+		default:
+			return nil, fmt.Errorf("field %q of kind %s is not supported for source %q", fd.FullName(), fd.Kind(), source)
+		}
+	case "name", "tag", "field":
+		if (m.source != "name" && m.key == "") || (m.source == "name" && m.key != "") {
+			return nil, fmt.Errorf("invalid source %q", source)
+		}
+		if fd.IsList() || fd.IsMap() || !isScalar(fd) {
+			return nil, fmt.Errorf("field %q must be a singular scalar for source %q", fd.FullName(), source)
+		}
+	default:
+		return nil, fmt.Errorf("invalid source %q", source)
+	}
+
+	return m, nil
+}
+
+// apply sets the mapped field of the message from the given metric
+func (m *fieldMapping) apply(msg protoreflect.Message, metric telegraf.Metric, precision time.Duration) error {
+	// Skip missing values to avoid creating empty parent messages
+	if (m.source == "tag" && !metric.HasTag(m.key)) || (m.source == "field" && !metric.HasField(m.key)) {
+		return nil
+	}
+
+	target := msg
+	for _, fd := range m.path[:len(m.path)-1] {
+		target = target.Mutable(fd).Message()
+	}
+	fd := m.path[len(m.path)-1]
+
+	switch m.source {
+	case "name":
+		v, err := convert(fd, metric.Name())
+		if err != nil {
+			return err
+		}
+		target.Set(fd, v)
+	case "tag":
+		value, _ := metric.GetTag(m.key)
+		v, err := convert(fd, value)
+		if err != nil {
+			return fmt.Errorf("converting tag %q failed: %w", m.key, err)
+		}
+		target.Set(fd, v)
+	case "field":
+		value, _ := metric.GetField(m.key)
+		v, err := convert(fd, value)
+		if err != nil {
+			return fmt.Errorf("converting field %q failed: %w", m.key, err)
+		}
+		target.Set(fd, v)
+	case "tags":
+		entries := target.Mutable(fd).Map()
+		for _, tag := range metric.TagList() {
+			entries.Set(protoreflect.ValueOfString(tag.Key).MapKey(), protoreflect.ValueOfString(tag.Value))
+		}
+	case "fields":
+		entries := target.Mutable(fd).Map()
+		for _, field := range metric.FieldList() {
+			v, err := convert(fd.MapValue(), field.Value)
+			if err != nil {
+				return fmt.Errorf("converting field %q failed: %w", field.Key, err)
+			}
+			entries.Set(protoreflect.ValueOfString(field.Key).MapKey(), v)
+		}
+	case "time":
+		t := metric.Time()
+		switch fd.Kind() {
+		case protoreflect.MessageKind:
+			v := target.NewField(fd)
+			ts := v.Message()
+			fields := ts.Descriptor().Fields()
+			ts.Set(fields.ByName("seconds"), protoreflect.ValueOfInt64(t.Unix()))
+			ts.Set(fields.ByName("nanos"), protoreflect.ValueOfInt32(int32(t.Nanosecond())))
+			target.Set(fd, v)
+		case protoreflect.DoubleKind:
+			target.Set(fd, protoreflect.ValueOfFloat64(float64(t.UnixNano())/float64(time.Second)))
+		case protoreflect.StringKind:
+			target.Set(fd, protoreflect.ValueOfString(t.UTC().Format(time.RFC3339Nano)))
+		default:
+			v, err := convert(fd, t.UnixNano()/int64(precision))
+			if err != nil {
+				return fmt.Errorf("converting time failed: %w", err)
+			}
+			target.Set(fd, v)
+		}
+	}
+
+	return nil
+}
+
+func isScalar(fd protoreflect.FieldDescriptor) bool {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return false
+	}
+	return true
+}
+
+// convert converts the given value to the kind of the field
+func convert(fd protoreflect.FieldDescriptor, value interface{}) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		v, err := internal.ToBool(value)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := internal.ToInt32(value)
+		return protoreflect.ValueOfInt32(v), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := internal.ToInt64(value)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := internal.ToUint32(value)
+		return protoreflect.ValueOfUint32(v), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := internal.ToUint64(value)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := internal.ToFloat32(value)
+		return protoreflect.ValueOfFloat32(v), err
+	case protoreflect.DoubleKind:
+		v, err := internal.ToFloat64(value)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.StringKind:
+		v, err := internal.ToString(value)
+		return protoreflect.ValueOfString(v), err
+	case protoreflect.BytesKind:
+		v, err := internal.ToString(value)
+		return protoreflect.ValueOfBytes([]byte(v)), err
+	case protoreflect.EnumKind:
+		if s, ok := value.(string); ok {
+			ev := fd.Enum().Values().ByName(protoreflect.Name(s))
+			if ev == nil {
+				return protoreflect.Value{}, fmt.Errorf("unknown value %q of enum %q", s, fd.Enum().FullName())
+			}
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		v, err := internal.ToInt32(value)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), err
+	}
+
+	return protoreflect.Value{}, errors.New("unsupported field kind " + fd.Kind().String())
+}
@@ -8,10 +8,16 @@ import (
 	"crypto/sha256"
 	_ "embed"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -22,9 +28,11 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
 	common_http "github.com/influxdata/telegraf/plugins/common/http"
 	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 //go:embed sample.conf
@@ -51,12 +59,20 @@ type HTTP struct {
 	UseBatchFormat          bool                      `toml:"use_batch_format"`
 	AwsService              string                    `toml:"aws_service"`
 	NonRetryableStatusCodes []int                     `toml:"non_retryable_statuscodes"`
+	IdempotencyKeyHeader    string                    `toml:"idempotency_key_header"`
+	RetryPolicy             map[string]string         `toml:"retry_policy"`
+	CaptureResponseBody     bool                      `toml:"capture_response_body"`
+	HeaderTemplates         map[string]string         `toml:"header_templates"`
 	common_http.HTTPClientConfig
 	Log telegraf.Logger `toml:"-"`
 
 	client     *http.Client
 	serializer telegraf.Serializer
 
+	urlTemplate     *template.Template
+	headerTemplates map[string]*template.Template
+	failures        map[failureKey]selfstat.Stat
+
 	awsCfg *aws.Config
 	common_aws.CredentialConfig
 
@@ -69,10 +85,57 @@ func (*HTTP) SampleConfig() string {
 	return sampleConfig
 }
 
+// errDropped indicates a request failing with a status code not to be retried
+var errDropped = errors.New("non-retryable status")
+
+// target is the destination of a request derived from the URL and header
+// templates
+type target struct {
+	url     string
+	headers map[string]string
+}
+
+type failureKey struct {
+	url        string
+	statusCode int
+}
+
 func (h *HTTP) SetSerializer(serializer telegraf.Serializer) {
 	h.serializer = serializer
 }
 
+func (h *HTTP) Init() error {
+	for k, v := range h.RetryPolicy {
+		if v != "retry" && v != "drop" {
+			return fmt.Errorf("invalid retry policy %q for %q", v, k)
+		}
+		if !validStatusPattern(k) {
+			return fmt.Errorf("invalid status code %q in retry policy, use a code like \"404\" or a class like \"4xx\"", k)
+		}
+	}
+
+	// Only use templating if required to avoid the overhead of partitioning
+	if strings.Contains(h.URL, "{{") {
+		tmpl, err := template.New("url").Parse(h.URL)
+		if err != nil {
+			return fmt.Errorf("parsing URL template failed: %w", err)
+		}
+		h.urlTemplate = tmpl
+	}
+	if len(h.HeaderTemplates) > 0 {
+		h.headerTemplates = make(map[string]*template.Template, len(h.HeaderTemplates))
+		for k, v := range h.HeaderTemplates {
+			tmpl, err := template.New(k).Parse(v)
+			if err != nil {
+				return fmt.Errorf("parsing template for header %q failed: %w", k, err)
+			}
+			h.headerTemplates[k] = tmpl
+		}
+	}
+
+	return nil
+}
+
 func (h *HTTP) Connect() error {
 	if h.AwsService != "" {
 		cfg, err := h.CredentialConfig.Credentials()
@@ -105,33 +168,224 @@ func (h *HTTP) Close() error {
 		h.client.CloseIdleConnections()
 	}
 
+	for _, stat := range h.failures {
+		selfstat.Unregister(stat.Name(), stat.FieldName(), stat.Tags())
+	}
+	h.failures = nil
+
 	return nil
 }
 
 func (h *HTTP) Write(metrics []telegraf.Metric) error {
+	if h.urlTemplate == nil && h.headerTemplates == nil {
+		indices := make([]int, 0, len(metrics))
+		for i := range metrics {
+			indices = append(indices, i)
+		}
+		_, _, err := h.send(&target{url: h.URL}, metrics, indices)
+		return err
+	}
+
+	// Group the metrics by the request target while keeping the indices into
+	// the batch to be able to report partial writes
+	targets := make([]*target, 0)
+	batches := make(map[string][]int)
+	rejected := make([]int, 0)
+	for i, m := range metrics {
+		t, err := h.target(m)
+		if err != nil {
+			h.Log.Errorf("Cannot create request target for metric %v: %v", m, err)
+			rejected = append(rejected, i)
+			continue
+		}
+		k := t.key()
+		if _, found := batches[k]; !found {
+			targets = append(targets, t)
+		}
+		batches[k] = append(batches[k], i)
+	}
+
+	accepted := make([]int, 0, len(metrics))
+	var errs []error
+	for _, t := range targets {
+		indices := batches[t.key()]
+		batch := make([]telegraf.Metric, 0, len(indices))
+		for _, i := range indices {
+			batch = append(batch, metrics[i])
+		}
+
+		written, dropped, err := h.send(t, batch, indices)
+		accepted = append(accepted, written...)
+		rejected = append(rejected, dropped...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	err := errors.Join(errs...)
+	if len(accepted) == 0 && len(rejected) == 0 {
+		return err
+	}
+
+	// Dropped and unroutable metrics were already logged so only report them
+	// as rejected along with the accepted ones
+	return &internal.PartialWriteError{
+		Err:           err,
+		MetricsAccept: accepted,
+		MetricsReject: rejected,
+	}
+}
+
+// send writes the metrics to the given target and returns the indices of the
+// written metrics and of the metrics dropped due to a non-retryable status
+func (h *HTTP) send(t *target, metrics []telegraf.Metric, indices []int) (written, dropped []int, err error) {
 	if h.UseBatchFormat {
 		reqBody, err := h.serializer.SerializeBatch(metrics)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
-		return h.writeMetric(reqBody)
+		var key string
+		if h.IdempotencyKeyHeader != "" {
+			key = metric.BatchIdempotencyKey(metrics)
+		}
+		if err := h.writeMetric(t, reqBody, key); err != nil {
+			if errors.Is(err, errDropped) {
+				h.Log.Errorf("%v. Metrics are lost.", err)
+				return nil, indices, nil
+			}
+			return nil, nil, err
+		}
+		return indices, nil, nil
 	}
 
-	for _, metric := range metrics {
-		reqBody, err := h.serializer.Serialize(metric)
+	for j, m := range metrics {
+		reqBody, err := h.serializer.Serialize(m)
 		if err != nil {
-			return err
+			return written, dropped, err
 		}
 
-		if err := h.writeMetric(reqBody); err != nil {
-			return err
+		var key string
+		if h.IdempotencyKeyHeader != "" {
+			key = metric.IdempotencyKey(m)
+		}
+		if err := h.writeMetric(t, reqBody, key); err != nil {
+			if errors.Is(err, errDropped) {
+				h.Log.Errorf("%v. Metric is lost.", err)
+				dropped = append(dropped, indices[j])
+				continue
+			}
+			return written, dropped, err
 		}
+		written = append(written, indices[j])
 	}
-	return nil
+	return written, dropped, nil
 }
 
-func (h *HTTP) writeMetric(reqBody []byte) error {
+// target returns the request target for the given metric
+func (h *HTTP) target(m telegraf.Metric) (*target, error) {
+	if wm, ok := m.(telegraf.UnwrappableMetric); ok {
+		m = wm.Unwrap()
+	}
+
+	t := &target{url: h.URL}
+	var buf strings.Builder
+	if h.urlTemplate != nil {
+		if err := h.urlTemplate.Execute(&buf, m); err != nil {
+			return nil, fmt.Errorf("executing URL template failed: %w", err)
+		}
+		t.url = buf.String()
+	}
+	if len(h.headerTemplates) > 0 {
+		t.headers = make(map[string]string, len(h.headerTemplates))
+		for k, tmpl := range h.headerTemplates {
+			buf.Reset()
+			if err := tmpl.Execute(&buf, m); err != nil {
+				return nil, fmt.Errorf("executing template for header %q failed: %w", k, err)
+			}
+			t.headers[k] = buf.String()
+		}
+	}
+	return t, nil
+}
+
+// key returns a unique identifier of the target for grouping metrics
+func (t *target) key() string {
+	var sb strings.Builder
+	sb.WriteString(t.url)
+	for _, k := range slices.Sorted(maps.Keys(t.headers)) {
+		sb.WriteString("\n" + k + ":" + t.headers[k])
+	}
+	return sb.String()
+}
+
+// retryable returns if the request should be retried for the given status
+// code. Policies for a specific code take precedence over the ones for the
+// class of the code.
+func (h *HTTP) retryable(statusCode int) bool {
+	code := strconv.Itoa(statusCode)
+	if policy, found := h.RetryPolicy[code]; found {
+		return policy == "retry"
+	}
+	if slices.Contains(h.NonRetryableStatusCodes, statusCode) {
+		return false
+	}
+	if policy, found := h.RetryPolicy[code[:1]+"xx"]; found {
+		return policy == "retry"
+	}
+	return true
+}
+
+func validStatusPattern(pattern string) bool {
+	if len(pattern) != 3 || pattern[0] < '1' || pattern[0] > '5' {
+		return false
+	}
+	if pattern[1:] == "xx" {
+		return true
+	}
+	_, err := strconv.Atoi(pattern)
+	return err == nil
+}
+
+// recordFailure counts the failed request in the internal metrics with the
+// response body added as tag if enabled
+func (h *HTTP) recordFailure(rawURL string, statusCode int, body string) {
+	if h.failures == nil {
+		h.failures = make(map[failureKey]selfstat.Stat)
+	}
+
+	// Avoid leaking credentials contained in the URL
+	if u, err := url.Parse(rawURL); err == nil {
+		rawURL = u.Redacted()
+	}
+	tags := map[string]string{
+		"url":         rawURL,
+		"status_code": strconv.Itoa(statusCode),
+	}
+	if h.CaptureResponseBody {
+		tags["response_body"] = body
+	}
+
+	k := failureKey{url: rawURL, statusCode: statusCode}
+	stat, found := h.failures[k]
+	if found && !maps.Equal(stat.Tags(), tags) {
+		// Replace the stat to only keep the tag of the latest response body
+		count := stat.Get()
+		selfstat.Unregister(stat.Name(), stat.FieldName(), stat.Tags())
+		stat = selfstat.Register("http_output", "requests_failed", tags)
+		stat.Set(count)
+		h.failures[k] = stat
+	} else if !found {
+		stat = selfstat.Register("http_output", "requests_failed", tags)
+		h.failures[k] = stat
+	}
+	stat.Incr(1)
+}
+
+func (h *HTTP) writeMetric(t *target, reqBody []byte, key string) error {
 	var reqBodyBuffer io.Reader = bytes.NewBuffer(reqBody)
 
 	var err error
@@ -158,7 +412,7 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 		payloadHash = &hash
 	}
 
-	req, err := http.NewRequest(h.Method, h.URL, reqBodyBuffer)
+	req, err := http.NewRequest(h.Method, t.url, reqBodyBuffer)
 	if err != nil {
 		return err
 	}
@@ -195,7 +449,7 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 
 	// google api auth
 	if h.CredentialsFile != "" {
-		token, err := h.getAccessToken(context.Background(), h.URL)
+		token, err := h.getAccessToken(context.Background(), t.url)
 		if err != nil {
 			return err
 		}
@@ -223,6 +477,17 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 		secret.Destroy()
 	}
 
+	for k, v := range t.headers {
+		if strings.EqualFold(k, "host") {
+			req.Host = v
+		}
+		req.Header.Set(k, v)
+	}
+
+	if key != "" {
+		req.Header.Set(h.IdempotencyKeyHeader, key)
+	}
+
 	resp, err := h.client.Do(req)
 	if err != nil {
 		return err
@@ -236,19 +501,18 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 			errorLine = scanner.Text()
 		}
 
-		for _, nonRetryableStatusCode := range h.NonRetryableStatusCodes {
-			if resp.StatusCode == nonRetryableStatusCode {
-				h.Log.Errorf("Received non-retryable status %v. Metrics are lost. body: %s", resp.StatusCode, errorLine)
-				return nil
-			}
+		h.recordFailure(t.url, resp.StatusCode, errorLine)
+
+		if !h.retryable(resp.StatusCode) {
+			return fmt.Errorf("when writing to [%s] received %w %d, body: %s", t.url, errDropped, resp.StatusCode, errorLine)
 		}
 
-		return fmt.Errorf("when writing to [%s] received status code: %d. body: %s", h.URL, resp.StatusCode, errorLine)
+		return fmt.Errorf("when writing to [%s] received status code: %d. body: %s", t.url, resp.StatusCode, errorLine)
 	}
 
 	_, err = io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("when writing to [%s] received error: %w", h.URL, err)
+		return fmt.Errorf("when writing to [%s] received error: %w", t.url, err)
 	}
 
 	return nil
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -767,6 +768,75 @@ func TestBatchedUnbatched(t *testing.T) {
 	}
 }
 
+func TestIdempotencyKeyHeader(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 23.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(10, 0)),
+	}
+
+	tests := []struct {
+		name     string
+		batch    bool
+		header   string
+		expected []string
+	}{
+		{
+			name:     "batched",
+			batch:    true,
+			header:   "Idempotency-Key",
+			expected: []string{metric.BatchIdempotencyKey(input)},
+		},
+		{
+			name:   "unbatched",
+			header: "Idempotency-Key",
+			expected: []string{
+				metric.IdempotencyKey(input[0]),
+				metric.IdempotencyKey(input[1]),
+				metric.IdempotencyKey(input[2]),
+			},
+		},
+		{
+			name:     "disabled",
+			batch:    true,
+			expected: []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys = nil
+
+			serializer := &influx.Serializer{}
+			require.NoError(t, serializer.Init())
+
+			plugin := &HTTP{
+				URL:                  ts.URL,
+				Method:               defaultMethod,
+				UseBatchFormat:       tt.batch,
+				IdempotencyKeyHeader: tt.header,
+			}
+			plugin.SetSerializer(serializer)
+			require.NoError(t, plugin.Connect())
+			require.NoError(t, plugin.Write(input))
+
+			mu.Lock()
+			defer mu.Unlock()
+			require.Equal(t, tt.expected, keys)
+		})
+	}
+}
+
 func TestAwsCredentials(t *testing.T) {
 	ts := httptest.NewServer(http.NotFoundHandler())
 	defer ts.Close()
@@ -817,3 +887,165 @@ func TestAwsCredentials(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestTemplating(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string][]string)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		k := r.URL.Path + " " + r.Header.Get("X-Tenant")
+		mu.Lock()
+		received[k] = append(received[k], strings.TrimSpace(string(body)))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	plugin := &HTTP{
+		URL:             ts.URL + "/{{.Name}}",
+		Method:          defaultMethod,
+		UseBatchFormat:  true,
+		HeaderTemplates: map[string]string{"X-Tenant": `{{.Tag "tenant"}}`},
+		Log:             testutil.Logger{},
+	}
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"tenant": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"tenant": "b"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"tenant": "a"}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"tenant": "a"}, map[string]interface{}{"value": 4.0}, time.Unix(0, 0)),
+	}
+	require.NoError(t, plugin.Write(input))
+
+	expected := map[string][]string{
+		"/cpu a": {"cpu,tenant=a value=1 0\ncpu,tenant=a value=4 0"},
+		"/cpu b": {"cpu,tenant=b value=2 0"},
+		"/mem a": {"mem,tenant=a value=3 0"},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, expected, received)
+}
+
+func TestRequestTemplatingPartialWrite(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			w.WriteHeader(http.StatusOK)
+		case "/b":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer ts.Close()
+
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	plugin := &HTTP{
+		URL:         ts.URL + `/{{.Tag "tenant"}}`,
+		Method:      defaultMethod,
+		RetryPolicy: map[string]string{"4xx": "drop"},
+		Log:         testutil.Logger{},
+	}
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"tenant": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"tenant": "b"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"tenant": "c"}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"tenant": "a"}, map[string]interface{}{"value": 4.0}, time.Unix(0, 0)),
+	}
+	err := plugin.Write(input)
+	require.Error(t, err)
+
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.ElementsMatch(t, []int{0, 3}, writeErr.MetricsAccept)
+	require.ElementsMatch(t, []int{2}, writeErr.MetricsReject)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    map[string]string
+		nonRetry  []int
+		status    int
+		retryable bool
+	}{
+		{
+			name:      "default",
+			status:    http.StatusBadRequest,
+			retryable: true,
+		},
+		{
+			name:      "class drop",
+			policy:    map[string]string{"4xx": "drop"},
+			status:    http.StatusBadRequest,
+			retryable: false,
+		},
+		{
+			name:      "code overrides class",
+			policy:    map[string]string{"4xx": "drop", "429": "retry"},
+			status:    http.StatusTooManyRequests,
+			retryable: true,
+		},
+		{
+			name:      "code overrides non-retryable codes",
+			policy:    map[string]string{"409": "retry"},
+			nonRetry:  []int{409},
+			status:    http.StatusConflict,
+			retryable: true,
+		},
+		{
+			name:      "non-retryable codes override class",
+			policy:    map[string]string{"4xx": "retry"},
+			nonRetry:  []int{413},
+			status:    http.StatusRequestEntityTooLarge,
+			retryable: false,
+		},
+		{
+			name:      "other class",
+			policy:    map[string]string{"4xx": "drop"},
+			status:    http.StatusInternalServerError,
+			retryable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &HTTP{
+				RetryPolicy:             tt.policy,
+				NonRetryableStatusCodes: tt.nonRetry,
+			}
+			require.NoError(t, plugin.Init())
+			require.Equal(t, tt.retryable, plugin.retryable(tt.status))
+		})
+	}
+}
+
+func TestRetryPolicyInvalid(t *testing.T) {
+	for _, policy := range []map[string]string{
+		{"4xx": "ignore"},
+		{"4x": "drop"},
+		{"600": "drop"},
+		{"abc": "retry"},
+	} {
+		plugin := &HTTP{RetryPolicy: policy}
+		require.Error(t, plugin.Init())
+	}
+}
@@ -4,16 +4,16 @@ package instrumental
 import (
 	"bytes"
 	_ "embed"
-	"errors"
 	"fmt"
-	"io"
 	"net"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/carbon"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers/graphite"
 )
@@ -39,7 +39,7 @@ type Instrumental struct {
 
 	Log telegraf.Logger `toml:"-"`
 
-	conn       net.Conn
+	writer     *carbon.Writer
 	serializer *graphite.Serializer
 }
 
@@ -68,41 +68,29 @@ func (i *Instrumental) Init() error {
 	}
 	i.serializer = s
 
-	return nil
-}
-
-func (i *Instrumental) Connect() error {
-	addr := fmt.Sprintf("%s:%d", i.Host, i.Port)
-	connection, err := net.DialTimeout("tcp", addr, time.Duration(i.Timeout))
-
-	if err != nil {
-		i.conn = nil
-		return err
+	w := &carbon.Writer{
+		Servers:   []string{net.JoinHostPort(i.Host, strconv.Itoa(i.Port))},
+		Timeout:   time.Duration(i.Timeout),
+		Handshake: i.authenticate,
+		Log:       i.Log,
 	}
-
-	err = i.authenticate(connection)
-	if err != nil {
-		i.conn = nil
+	if err := w.Init(); err != nil {
 		return err
 	}
+	i.writer = w
 
 	return nil
 }
 
+func (i *Instrumental) Connect() error {
+	return i.writer.Connect()
+}
+
 func (i *Instrumental) Close() error {
-	err := i.conn.Close()
-	i.conn = nil
-	return err
+	return i.writer.Close()
 }
 
 func (i *Instrumental) Write(metrics []telegraf.Metric) error {
-	if i.conn == nil {
-		err := i.Connect()
-		if err != nil {
-			return fmt.Errorf("failed to (re)connect to Instrumental. Error: %w", err)
-		}
-	}
-
 	var points []string
 	var metricType string
 
@@ -159,21 +147,15 @@ func (i *Instrumental) Write(metrics []telegraf.Metric) error {
 		}
 	}
 
-	allPoints := strings.Join(points, "")
-	if _, err := fmt.Fprint(i.conn, allPoints); err != nil {
-		if errors.Is(err, io.EOF) {
-			_ = i.Close()
-		}
-
-		return err
+	if err := i.writer.Write([]byte(strings.Join(points, ""))); err != nil {
+		_ = i.Close()
+		return fmt.Errorf("failed to write to Instrumental: %w", err)
 	}
 
 	// force the connection closed after sending data
 	// to deal with various disconnection scenarios and eschew holding
 	// open idle connections en masse
-	_ = i.Close()
-
-	return nil
+	return i.Close()
 }
 
 func (i *Instrumental) authenticate(conn net.Conn) error {
@@ -197,7 +179,6 @@ func (i *Instrumental) authenticate(conn net.Conn) error {
 		return fmt.Errorf("authentication failed: %s", responses)
 	}
 
-	i.conn = conn
 	return nil
 }
 
@@ -15,6 +15,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
 )
 
 func TestWrite(t *testing.T) {
@@ -27,6 +28,7 @@ func TestWrite(t *testing.T) {
 		Port:     port,
 		APIToken: config.NewSecret([]byte("abc123token")),
 		Prefix:   "my.prefix",
+		Log:      testutil.Logger{},
 	}
 	require.NoError(t, i.Init())
 
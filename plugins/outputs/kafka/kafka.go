@@ -12,7 +12,9 @@ import (
 	"github.com/gofrs/uuid/v5"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/common/kafka"
 	"github.com/influxdata/telegraf/plugins/common/proxy"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -30,16 +32,22 @@ var ValidTopicSuffixMethods = []string{
 var zeroTime = time.Unix(0, 0)
 
 type Kafka struct {
-	Brokers           []string        `toml:"brokers"`
-	Topic             string          `toml:"topic"`
-	TopicTag          string          `toml:"topic_tag"`
-	ExcludeTopicTag   bool            `toml:"exclude_topic_tag"`
-	TopicSuffix       TopicSuffix     `toml:"topic_suffix"`
-	RoutingTag        string          `toml:"routing_tag"`
-	RoutingKey        string          `toml:"routing_key"`
-	ProducerTimestamp string          `toml:"producer_timestamp"`
-	MetricNameHeader  string          `toml:"metric_name_header"`
-	Log               telegraf.Logger `toml:"-"`
+	Brokers                []string        `toml:"brokers"`
+	Topic                  string          `toml:"topic"`
+	TopicTag               string          `toml:"topic_tag"`
+	ExcludeTopicTag        bool            `toml:"exclude_topic_tag"`
+	AllowedTopics          []string        `toml:"allowed_topics"`
+	TopicCreation          string          `toml:"topic_creation"`
+	TopicPartitions        int32           `toml:"topic_partitions"`
+	TopicReplicationFactor int16           `toml:"topic_replication_factor"`
+	TopicSuffix            TopicSuffix     `toml:"topic_suffix"`
+	RoutingTag             string          `toml:"routing_tag"`
+	RoutingKey             string          `toml:"routing_key"`
+	ProducerTimestamp      string          `toml:"producer_timestamp"`
+	MetricNameHeader       string          `toml:"metric_name_header"`
+	IdempotencyHeader      string          `toml:"idempotency_key_header"`
+	TransactionalID        string          `toml:"transactional_id"`
+	Log                    telegraf.Logger `toml:"-"`
 	proxy.Socks5ProxyConfig
 	kafka.WriteConfig
 
@@ -54,6 +62,11 @@ type Kafka struct {
 	saramaConfig *sarama.Config
 	producerFunc func(addrs []string, config *sarama.Config) (sarama.SyncProducer, error)
 	producer     sarama.SyncProducer
+	adminFunc    func(addrs []string, config *sarama.Config) (sarama.ClusterAdmin, error)
+	admin        sarama.ClusterAdmin
+
+	topicFilter filter.Filter
+	knownTopics map[string]bool
 
 	serializer telegraf.Serializer
 }
@@ -80,7 +93,9 @@ func (*Kafka) SampleConfig() string {
 func (k *Kafka) GetTopicName(metric telegraf.Metric) (telegraf.Metric, string) {
 	topic := k.Topic
 	if k.TopicTag != "" {
-		if t, ok := metric.GetTag(k.TopicTag); ok {
+		if t, ok := metric.GetTag(k.TopicTag); ok && k.topicFilter != nil && !k.topicFilter.Match(t) {
+			k.Log.Debugf("Topic %q is not allowed, using %q instead", t, k.Topic)
+		} else if ok {
 			topic = t
 
 			// If excluding the topic tag, a copy is required to avoid modifying
@@ -145,8 +160,64 @@ func (k *Kafka) Init() error {
 		}
 		config.Net.Proxy.Dialer = dialer
 	}
+
+	// Transactions require idempotent writes acknowledged by all replicas
+	if k.TransactionalID != "" {
+		if config.Producer.RequiredAcks != sarama.WaitForAll {
+			return errors.New("'transactional_id' requires 'required_acks = -1'")
+		}
+		config.Producer.Idempotent = true
+		config.Producer.Transaction.ID = k.TransactionalID
+		config.Net.MaxOpenRequests = 1
+	}
+
+	// Check the requirements of the producer early as the settings are
+	// otherwise only validated when connecting
+	if config.Producer.Idempotent {
+		if config.Producer.RequiredAcks != sarama.WaitForAll {
+			return errors.New("'idempotent_writes' requires 'required_acks = -1'")
+		}
+		if config.Producer.Retry.Max < 1 {
+			return errors.New("'idempotent_writes' requires 'max_retry' to be at least 1")
+		}
+		if !config.Version.IsAtLeast(sarama.V0_11_0_0) {
+			return fmt.Errorf("'idempotent_writes' requires Kafka version 0.11.0 or later but %q is configured", k.Version)
+		}
+	}
+	if config.Producer.Compression == sarama.CompressionZSTD && !config.Version.IsAtLeast(sarama.V2_1_0_0) {
+		return fmt.Errorf("zstd compression requires Kafka version 2.1.0 or later but %q is configured", k.Version)
+	}
+
+	switch k.TopicCreation {
+	case "", "broker":
+		k.TopicCreation = "broker"
+	case "none":
+		config.Metadata.AllowAutoTopicCreation = false
+	case "explicit":
+		// Topics are created with the configured settings before writing
+		config.Metadata.AllowAutoTopicCreation = false
+		if k.TopicPartitions == 0 || k.TopicPartitions < -1 {
+			return errors.New("'topic_partitions' must be positive or -1 for the broker default")
+		}
+		if k.TopicReplicationFactor == 0 || k.TopicReplicationFactor < -1 {
+			return errors.New("'topic_replication_factor' must be positive or -1 for the broker default")
+		}
+	default:
+		return fmt.Errorf("unknown topic_creation option: %s", k.TopicCreation)
+	}
 	k.saramaConfig = config
 
+	if len(k.AllowedTopics) > 0 {
+		if k.TopicTag == "" {
+			k.Log.Warn("Option 'allowed_topics' has no effect without 'topic_tag'")
+		}
+		f, err := filter.Compile(k.AllowedTopics)
+		if err != nil {
+			return fmt.Errorf("compiling allowed topics failed: %w", err)
+		}
+		k.topicFilter = f
+	}
+
 	switch k.ProducerTimestamp {
 	case "":
 		k.ProducerTimestamp = "metric"
@@ -164,16 +235,53 @@ func (k *Kafka) Connect() error {
 		return &internal.StartupError{Err: err, Retry: true}
 	}
 	k.producer = producer
+
+	if k.TopicCreation == "explicit" {
+		admin, err := k.adminFunc(k.Brokers, k.saramaConfig)
+		if err != nil {
+			producer.Close()
+			return &internal.StartupError{Err: fmt.Errorf("creating cluster admin failed: %w", err), Retry: true}
+		}
+		k.admin = admin
+		k.knownTopics = make(map[string]bool)
+	}
 	return nil
 }
 
 func (k *Kafka) Close() error {
+	if k.admin != nil {
+		if err := k.admin.Close(); err != nil {
+			k.Log.Errorf("Closing cluster admin failed: %v", err)
+		}
+	}
 	if k.producer == nil {
 		return nil
 	}
 	return k.producer.Close()
 }
 
+// createTopics creates the topics of the messages not yet known to exist
+func (k *Kafka) createTopics(msgs []*sarama.ProducerMessage) error {
+	detail := &sarama.TopicDetail{
+		NumPartitions:     k.TopicPartitions,
+		ReplicationFactor: k.TopicReplicationFactor,
+	}
+	for _, m := range msgs {
+		if k.knownTopics[m.Topic] {
+			continue
+		}
+		err := k.admin.CreateTopic(m.Topic, detail, false)
+		if err != nil && !errors.Is(err, sarama.ErrTopicAlreadyExists) {
+			return fmt.Errorf("creating topic %q failed: %w", m.Topic, err)
+		}
+		if err == nil {
+			k.Log.Infof("Created topic %q", m.Topic)
+		}
+		k.knownTopics[m.Topic] = true
+	}
+	return nil
+}
+
 func (k *Kafka) routingKey(metric telegraf.Metric) (string, error) {
 	if k.RoutingTag != "" {
 		key, ok := metric.GetTag(k.RoutingTag)
@@ -194,8 +302,17 @@ func (k *Kafka) routingKey(metric telegraf.Metric) (string, error) {
 }
 
 func (k *Kafka) Write(metrics []telegraf.Metric) error {
+	// Derive the keys before the topic tag is possibly removed
+	var keys []string
+	if k.IdempotencyHeader != "" {
+		keys = make([]string, 0, len(metrics))
+		for _, m := range metrics {
+			keys = append(keys, metric.IdempotencyKey(m))
+		}
+	}
+
 	msgs := make([]*sarama.ProducerMessage, 0, len(metrics))
-	for _, metric := range metrics {
+	for i, metric := range metrics {
 		metric, topic := k.GetTopicName(metric)
 
 		buf, err := k.serializer.Serialize(metric)
@@ -210,12 +327,18 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 		}
 
 		if k.MetricNameHeader != "" {
-			m.Headers = []sarama.RecordHeader{
-				{
-					Key:   []byte(k.MetricNameHeader),
-					Value: []byte(metric.Name()),
-				},
-			}
+			m.Headers = append(m.Headers, sarama.RecordHeader{
+				Key:   []byte(k.MetricNameHeader),
+				Value: []byte(metric.Name()),
+			})
+		}
+
+		// Allow consumers to deduplicate messages of retried batches
+		if keys != nil {
+			m.Headers = append(m.Headers, sarama.RecordHeader{
+				Key:   []byte(k.IdempotencyHeader),
+				Value: []byte(keys[i]),
+			})
 		}
 
 		// Negative timestamps are not allowed by the Kafka protocol.
@@ -234,32 +357,70 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 		msgs = append(msgs, m)
 	}
 
-	err := k.producer.SendMessages(msgs)
-	if err != nil {
-		// We could have many errors, return only the first encountered.
-		var errs sarama.ProducerErrors
-		if errors.As(err, &errs) && len(errs) > 0 {
-			// Just return the first error encountered
-			firstErr := errs[0]
-			if errors.Is(firstErr.Err, sarama.ErrMessageSizeTooLarge) {
-				k.Log.Error("Message too large, consider increasing `max_message_bytes`; dropping batch")
-				return nil
-			}
-			if errors.Is(firstErr.Err, sarama.ErrInvalidTimestamp) {
-				k.Log.Error(
-					"The timestamp of the message is out of acceptable range, consider increasing broker `message.timestamp.difference.max.ms`; " +
-						"dropping batch",
-				)
-				return nil
-			}
-			return firstErr
+	if k.admin != nil {
+		if err := k.createTopics(msgs); err != nil {
+			return err
 		}
-		return err
+	}
+
+	if k.TransactionalID != "" {
+		return k.sendTransactional(msgs)
+	}
+	return k.handleSendError(k.producer.SendMessages(msgs))
+}
+
+// sendTransactional writes the messages within a transaction so either all
+// or none of the messages become visible to read-committed consumers
+func (k *Kafka) sendTransactional(msgs []*sarama.ProducerMessage) error {
+	if err := k.producer.BeginTxn(); err != nil {
+		return fmt.Errorf("beginning transaction failed: %w", err)
+	}
+
+	if err := k.producer.SendMessages(msgs); err != nil {
+		if abortErr := k.producer.AbortTxn(); abortErr != nil {
+			k.Log.Errorf("Aborting transaction failed: %v", abortErr)
+		}
+		return k.handleSendError(err)
+	}
+
+	if err := k.producer.CommitTxn(); err != nil {
+		if abortErr := k.producer.AbortTxn(); abortErr != nil {
+			k.Log.Errorf("Aborting transaction failed: %v", abortErr)
+		}
+		return fmt.Errorf("committing transaction failed: %w", err)
 	}
 
 	return nil
 }
 
+// handleSendError drops batches that can never be written and returns the
+// first error encountered otherwise
+func (k *Kafka) handleSendError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// We could have many errors, return only the first encountered.
+	var errs sarama.ProducerErrors
+	if errors.As(err, &errs) && len(errs) > 0 {
+		// Just return the first error encountered
+		firstErr := errs[0]
+		if errors.Is(firstErr.Err, sarama.ErrMessageSizeTooLarge) {
+			k.Log.Error("Message too large, consider increasing `max_message_bytes`; dropping batch")
+			return nil
+		}
+		if errors.Is(firstErr.Err, sarama.ErrInvalidTimestamp) {
+			k.Log.Error(
+				"The timestamp of the message is out of acceptable range, consider increasing broker `message.timestamp.difference.max.ms`; " +
+					"dropping batch",
+			)
+			return nil
+		}
+		return firstErr
+	}
+	return err
+}
+
 func init() {
 	outputs.Add("kafka", func() telegraf.Output {
 		return &Kafka{
@@ -267,7 +428,10 @@ func init() {
 				MaxRetry:     3,
 				RequiredAcks: -1,
 			},
-			producerFunc: sarama.NewSyncProducer,
+			TopicPartitions:        -1,
+			TopicReplicationFactor: -1,
+			producerFunc:           sarama.NewSyncProducer,
+			adminFunc:              sarama.NewClusterAdmin,
 		}
 	})
 }
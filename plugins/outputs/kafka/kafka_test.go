@@ -1,6 +1,7 @@
 package kafka
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/kafka"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -166,7 +168,9 @@ func TestRoutingKey(t *testing.T) {
 }
 
 type MockProducer struct {
-	sent []*sarama.ProducerMessage
+	sent         []*sarama.ProducerMessage
+	transactions []string
+	sendErr      error
 	sarama.SyncProducer
 }
 
@@ -176,10 +180,28 @@ func (p *MockProducer) SendMessage(msg *sarama.ProducerMessage) (partition int32
 }
 
 func (p *MockProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	if p.sendErr != nil {
+		return p.sendErr
+	}
 	p.sent = append(p.sent, msgs...)
 	return nil
 }
 
+func (p *MockProducer) BeginTxn() error {
+	p.transactions = append(p.transactions, "begin")
+	return nil
+}
+
+func (p *MockProducer) CommitTxn() error {
+	p.transactions = append(p.transactions, "commit")
+	return nil
+}
+
+func (p *MockProducer) AbortTxn() error {
+	p.transactions = append(p.transactions, "abort")
+	return nil
+}
+
 func (*MockProducer) Close() error {
 	return nil
 }
@@ -260,6 +282,54 @@ func TestTopicTag(t *testing.T) {
 			topic: "telegraf",
 			value: "cpu time_idle=42 0\n",
 		},
+		{
+			name: "disallowed topic tag falls back to static topic",
+			plugin: &Kafka{
+				Brokers:       []string{"127.0.0.1"},
+				Topic:         "telegraf",
+				TopicTag:      "topic",
+				AllowedTopics: []string{"metrics-*"},
+				producerFunc:  NewMockProducer,
+			},
+			input: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{
+						"topic": "xyzzy",
+					},
+					map[string]interface{}{
+						"time_idle": 42.0,
+					},
+					time.Unix(0, 0),
+				),
+			},
+			topic: "telegraf",
+			value: "cpu,topic=xyzzy time_idle=42 0\n",
+		},
+		{
+			name: "allowed topic tag overrides static topic",
+			plugin: &Kafka{
+				Brokers:       []string{"127.0.0.1"},
+				Topic:         "telegraf",
+				TopicTag:      "topic",
+				AllowedTopics: []string{"metrics-*"},
+				producerFunc:  NewMockProducer,
+			},
+			input: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{
+						"topic": "metrics-cpu",
+					},
+					map[string]interface{}{
+						"time_idle": 42.0,
+					},
+					time.Unix(0, 0),
+				),
+			},
+			topic: "metrics-cpu",
+			value: "cpu,topic=metrics-cpu time_idle=42 0\n",
+		},
 		{
 			name: "exclude topic tag removes tag",
 			plugin: &Kafka{
@@ -288,6 +358,7 @@ func TestTopicTag(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
 
 			s := &influx.Serializer{}
 			require.NoError(t, s.Init())
@@ -310,3 +381,265 @@ func TestTopicTag(t *testing.T) {
 		})
 	}
 }
+
+func TestIdempotencyKeyHeader(t *testing.T) {
+	plugin := &Kafka{
+		Brokers:           []string{"127.0.0.1"},
+		Topic:             "telegraf",
+		IdempotencyHeader: "idempotency-key",
+		producerFunc:      NewMockProducer,
+		Log:               testutil.Logger{},
+	}
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+	plugin.SetSerializer(s)
+	require.NoError(t, plugin.Connect())
+
+	producer := &MockProducer{}
+	plugin.producer = producer
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"time_idle": 42.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"time_idle": 23.0}, time.Unix(10, 0)),
+	}
+	require.NoError(t, plugin.Write(input))
+	require.Len(t, producer.sent, 2)
+	for i, m := range input {
+		require.Len(t, producer.sent[i].Headers, 1)
+		require.Equal(t, "idempotency-key", string(producer.sent[i].Headers[0].Key))
+		require.Equal(t, metric.IdempotencyKey(m), string(producer.sent[i].Headers[0].Value))
+	}
+
+	// The key of a metric must not depend on its position in the batch
+	producer.sent = nil
+	require.NoError(t, plugin.Write(input[1:]))
+	require.Len(t, producer.sent, 1)
+	require.Equal(t, metric.IdempotencyKey(input[1]), string(producer.sent[0].Headers[0].Value))
+
+	// Without a header configured no header must be added
+	plugin.IdempotencyHeader = ""
+	producer.sent = nil
+	require.NoError(t, plugin.Write(input))
+	require.Len(t, producer.sent, 2)
+	require.Empty(t, producer.sent[0].Headers)
+}
+
+func TestTransactionalInit(t *testing.T) {
+	plugin := &Kafka{
+		Brokers:         []string{"127.0.0.1"},
+		Topic:           "telegraf",
+		TransactionalID: "telegraf-1",
+		WriteConfig:     kafka.WriteConfig{RequiredAcks: 1, MaxRetry: 3},
+		Log:             testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "requires 'required_acks = -1'")
+
+	plugin.RequiredAcks = -1
+	require.NoError(t, plugin.Init())
+	require.True(t, plugin.saramaConfig.Producer.Idempotent)
+	require.Equal(t, "telegraf-1", plugin.saramaConfig.Producer.Transaction.ID)
+	require.Equal(t, 1, plugin.saramaConfig.Net.MaxOpenRequests)
+}
+
+func TestTransactionalWrite(t *testing.T) {
+	input := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"time_idle": 42.0}, time.Unix(0, 0)),
+	}
+
+	tests := []struct {
+		name         string
+		sendErr      error
+		expectedErr  string
+		transactions []string
+	}{
+		{
+			name:         "success",
+			transactions: []string{"begin", "commit"},
+		},
+		{
+			name:         "send error",
+			sendErr:      errors.New("broker unavailable"),
+			expectedErr:  "broker unavailable",
+			transactions: []string{"begin", "abort"},
+		},
+		{
+			name: "dropped batch",
+			sendErr: sarama.ProducerErrors{
+				&sarama.ProducerError{Err: sarama.ErrMessageSizeTooLarge},
+			},
+			transactions: []string{"begin", "abort"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Kafka{
+				Brokers:         []string{"127.0.0.1"},
+				Topic:           "telegraf",
+				TransactionalID: "telegraf-1",
+				producerFunc:    NewMockProducer,
+				Log:             testutil.Logger{},
+			}
+			s := &influx.Serializer{}
+			require.NoError(t, s.Init())
+			plugin.SetSerializer(s)
+			require.NoError(t, plugin.Connect())
+
+			producer := &MockProducer{sendErr: tt.sendErr}
+			plugin.producer = producer
+
+			err := plugin.Write(input)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.transactions, producer.transactions)
+		})
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Kafka
+		expected string
+	}{
+		{
+			name: "idempotent writes without acks from all replicas",
+			plugin: &Kafka{
+				WriteConfig: kafka.WriteConfig{IdempotentWrites: true, RequiredAcks: 1, MaxRetry: 3},
+			},
+			expected: "'idempotent_writes' requires 'required_acks = -1'",
+		},
+		{
+			name: "idempotent writes without retries",
+			plugin: &Kafka{
+				WriteConfig: kafka.WriteConfig{IdempotentWrites: true, RequiredAcks: -1},
+			},
+			expected: "'idempotent_writes' requires 'max_retry' to be at least 1",
+		},
+		{
+			name: "idempotent writes with old version",
+			plugin: &Kafka{
+				WriteConfig: kafka.WriteConfig{
+					Config:           kafka.Config{Version: "0.10.2.0"},
+					IdempotentWrites: true,
+					RequiredAcks:     -1,
+					MaxRetry:         3,
+				},
+			},
+			expected: "'idempotent_writes' requires Kafka version 0.11.0 or later",
+		},
+		{
+			name: "zstd with old version",
+			plugin: &Kafka{
+				WriteConfig: kafka.WriteConfig{
+					Config: kafka.Config{Version: "2.0.0", CompressionCodec: 4},
+				},
+			},
+			expected: "zstd compression requires Kafka version 2.1.0 or later",
+		},
+		{
+			name:     "invalid topic creation",
+			plugin:   &Kafka{TopicCreation: "always"},
+			expected: "unknown topic_creation option: always",
+		},
+		{
+			name: "invalid partitions",
+			plugin: &Kafka{
+				TopicCreation:          "explicit",
+				TopicReplicationFactor: -1,
+			},
+			expected: "'topic_partitions' must be positive",
+		},
+		{
+			name: "invalid replication factor",
+			plugin: &Kafka{
+				TopicCreation:   "explicit",
+				TopicPartitions: 3,
+			},
+			expected: "'topic_replication_factor' must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestTopicCreationConfig(t *testing.T) {
+	for _, creation := range []string{"", "broker", "none", "explicit"} {
+		t.Run(creation, func(t *testing.T) {
+			plugin := &Kafka{
+				TopicCreation:          creation,
+				TopicPartitions:        -1,
+				TopicReplicationFactor: -1,
+				Log:                    testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+			allowed := creation == "" || creation == "broker"
+			require.Equal(t, allowed, plugin.saramaConfig.Metadata.AllowAutoTopicCreation)
+		})
+	}
+}
+
+type MockAdmin struct {
+	created  []string
+	existing map[string]bool
+	sarama.ClusterAdmin
+}
+
+func (a *MockAdmin) CreateTopic(topic string, _ *sarama.TopicDetail, _ bool) error {
+	a.created = append(a.created, topic)
+	if a.existing[topic] {
+		return &sarama.TopicError{Err: sarama.ErrTopicAlreadyExists}
+	}
+	return nil
+}
+
+func (*MockAdmin) Close() error {
+	return nil
+}
+
+func TestTopicCreationExplicit(t *testing.T) {
+	admin := &MockAdmin{existing: map[string]bool{"telegraf": true}}
+	plugin := &Kafka{
+		Brokers:                []string{"127.0.0.1"},
+		Topic:                  "telegraf",
+		TopicTag:               "topic",
+		TopicCreation:          "explicit",
+		TopicPartitions:        3,
+		TopicReplicationFactor: 2,
+		producerFunc:           NewMockProducer,
+		adminFunc: func([]string, *sarama.Config) (sarama.ClusterAdmin, error) {
+			return admin, nil
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+	plugin.SetSerializer(s)
+	require.NoError(t, plugin.Connect())
+
+	producer := &MockProducer{}
+	plugin.producer = producer
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"time_idle": 42.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"topic": "xyzzy"}, map[string]interface{}{"time_idle": 23.0}, time.Unix(0, 0)),
+		testutil.MustMetric("mem", map[string]string{"topic": "xyzzy"}, map[string]interface{}{"free": 1.0}, time.Unix(0, 0)),
+	}
+	require.NoError(t, plugin.Write(input))
+	require.Equal(t, []string{"telegraf", "xyzzy"}, admin.created)
+	require.Len(t, producer.sent, 3)
+
+	// Topics must only be created once
+	require.NoError(t, plugin.Write(input))
+	require.Equal(t, []string{"telegraf", "xyzzy"}, admin.created)
+	require.Len(t, producer.sent, 6)
+}
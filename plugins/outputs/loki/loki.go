@@ -48,9 +48,14 @@ type Loki struct {
 	GZipRequest        bool              `toml:"gzip_request"`
 	MetricNameLabel    string            `toml:"metric_name_label"`
 	SanitizeLabelNames bool              `toml:"sanitize_label_names"`
-
-	url    string
-	client *http.Client
+	TenantID           string            `toml:"tenant_id"`
+	Encoding           string            `toml:"encoding"`
+	LineField          string            `toml:"line_field"`
+	MetadataTags       []string          `toml:"structured_metadata_tags"`
+
+	url          string
+	client       *http.Client
+	metadataTags map[string]bool
 	tls.ClientConfig
 }
 
@@ -95,6 +100,23 @@ func (l *Loki) Connect() (err error) {
 		l.Endpoint = defaultEndpoint
 	}
 
+	switch l.Encoding {
+	case "":
+		l.Encoding = "json"
+	case "json":
+	case "protobuf":
+		if l.GZipRequest {
+			return errors.New("gzip compression is not supported for protobuf encoding")
+		}
+	default:
+		return fmt.Errorf("invalid encoding %q", l.Encoding)
+	}
+
+	l.metadataTags = make(map[string]bool, len(l.MetadataTags))
+	for _, tag := range l.MetadataTags {
+		l.metadataTags[tag] = true
+	}
+
 	l.url = fmt.Sprintf("%s%s", l.Domain, l.Endpoint)
 
 	if l.Timeout == 0 {
@@ -128,28 +150,65 @@ func (l *Loki) Write(metrics []telegraf.Metric) error {
 			m.AddTag(l.MetricNameLabel, m.Name())
 		}
 
-		tags := m.TagList()
+		// Tags configured as structured metadata are not used as labels to
+		// avoid high-cardinality streams
+		var metadata map[string]string
+		tags := make([]*telegraf.Tag, 0, len(m.TagList()))
+		for _, t := range m.TagList() {
+			if l.metadataTags[t.Key] {
+				if metadata == nil {
+					metadata = make(map[string]string)
+				}
+				metadata[t.Key] = t.Value
+				continue
+			}
+			tags = append(tags, t)
+		}
 		if l.SanitizeLabelNames {
 			for _, t := range tags {
 				t.Key = sanitizeLabelName(t.Key)
 			}
 		}
 
+		// Use the configured field as log line for event-like metrics and
+		// keep the remaining fields as structured metadata
 		var line string
-		for _, f := range m.FieldList() {
-			line += fmt.Sprintf("%s=\"%v\" ", f.Key, f.Value)
+		if v, found := m.GetField(l.LineField); l.LineField != "" && found {
+			line = fmt.Sprint(v)
+			for _, f := range m.FieldList() {
+				if f.Key == l.LineField {
+					continue
+				}
+				if metadata == nil {
+					metadata = make(map[string]string)
+				}
+				metadata[f.Key] = fmt.Sprint(f.Value)
+			}
+		} else {
+			for _, f := range m.FieldList() {
+				line += fmt.Sprintf("%s=\"%v\" ", f.Key, f.Value)
+			}
 		}
 
-		s.insertLog(tags, Log{strconv.FormatInt(m.Time().UnixNano(), 10), line})
+		s.insertEntry(tags, Log{strconv.FormatInt(m.Time().UnixNano(), 10), line}, metadata)
 	}
 
 	return l.writeMetrics(s)
 }
 
 func (l *Loki) writeMetrics(s Streams) error {
-	bs, err := json.Marshal(s)
-	if err != nil {
-		return fmt.Errorf("json.Marshal: %w", err)
+	var bs []byte
+	var err error
+	if l.Encoding == "protobuf" {
+		bs, err = s.marshalProtobuf()
+		if err != nil {
+			return fmt.Errorf("encoding protobuf failed: %w", err)
+		}
+	} else {
+		bs, err = json.Marshal(s)
+		if err != nil {
+			return fmt.Errorf("json.Marshal: %w", err)
+		}
 	}
 
 	var reqBodyBuffer io.Reader = bytes.NewBuffer(bs)
@@ -187,8 +246,16 @@ func (l *Loki) writeMetrics(s Streams) error {
 		req.Header.Set(k, v)
 	}
 
+	if l.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", l.TenantID)
+	}
+
 	req.Header.Set("User-Agent", internal.ProductToken())
-	req.Header.Set("Content-Type", "application/json")
+	if l.Encoding == "protobuf" {
+		req.Header.Set("Content-Type", "application/x-protobuf")
+	} else {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	if l.GZipRequest {
 		req.Header.Set("Content-Encoding", "gzip")
 	}
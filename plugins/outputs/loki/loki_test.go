@@ -628,3 +628,110 @@ func TestSanitizeLabelName(t *testing.T) {
 		})
 	}
 }
+
+func TestTenantID(t *testing.T) {
+	var tenant string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = r.Header.Get("X-Scope-OrgID")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	l := Loki{
+		Domain:   ts.URL,
+		TenantID: "team-a",
+	}
+	require.NoError(t, l.Connect())
+	require.NoError(t, l.Write([]telegraf.Metric{getMetric()}))
+	require.Equal(t, "team-a", tenant)
+}
+
+func TestInvalidEncoding(t *testing.T) {
+	l := Loki{
+		Domain:   "http://localhost:3100",
+		Encoding: "xml",
+	}
+	require.ErrorContains(t, l.Connect(), `invalid encoding "xml"`)
+
+	l = Loki{
+		Domain:      "http://localhost:3100",
+		Encoding:    "protobuf",
+		GZipRequest: true,
+	}
+	require.ErrorContains(t, l.Connect(), "gzip compression is not supported")
+}
+
+func TestLineFieldAndStructuredMetadata(t *testing.T) {
+	var payload []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		if payload, err = io.ReadAll(r.Body); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	l := Loki{
+		Domain:          ts.URL,
+		MetricNameLabel: "__name",
+		LineField:       "message",
+		MetadataTags:    []string{"procid"},
+	}
+	require.NoError(t, l.Connect())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"syslog",
+			map[string]string{"appname": "sshd", "procid": "1234"},
+			map[string]interface{}{"message": "session opened", "severity_code": 6},
+			time.Unix(123, 0),
+		),
+		testutil.MustMetric(
+			"syslog",
+			map[string]string{"appname": "sshd"},
+			map[string]interface{}{"facility_code": 4},
+			time.Unix(124, 0),
+		),
+	}
+	require.NoError(t, l.Write(input))
+
+	expected := `{"streams":[{"stream":{"__name":"syslog","appname":"sshd"},"values":[` +
+		`["123000000000","session opened",{"procid":"1234","severity_code":"6"}],` +
+		`["124000000000","facility_code=\"4\" "]]}]}`
+	require.JSONEq(t, expected, string(payload))
+}
+
+func TestProtobufEncoding(t *testing.T) {
+	var contentType string
+	var payload []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		var err error
+		if payload, err = io.ReadAll(r.Body); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	l := Loki{
+		Domain:          ts.URL,
+		Encoding:        "protobuf",
+		MetricNameLabel: "__name",
+	}
+	require.NoError(t, l.Connect())
+	require.NoError(t, l.Write([]telegraf.Metric{getMetric()}))
+
+	require.Equal(t, "application/x-protobuf", contentType)
+	expected, err := Streams{
+		"": &Stream{
+			Labels: map[string]string{"__name": "log", "key1": "value1"},
+			Logs:   []Log{{"123000000000", `line="my log" field="3.14" `}},
+		},
+	}.marshalProtobuf()
+	require.NoError(t, err)
+	require.Equal(t, expected, payload)
+}
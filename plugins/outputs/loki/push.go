@@ -0,0 +1,118 @@
+package loki
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the messages of the Loki push API, see
+// https://github.com/grafana/loki/blob/main/pkg/push/push.proto
+const (
+	fieldPushRequestStreams      = 1
+	fieldStreamLabels            = 1
+	fieldStreamEntries           = 2
+	fieldEntryTimestamp          = 1
+	fieldEntryLine               = 2
+	fieldEntryStructuredMetadata = 3
+	fieldLabelPairName           = 1
+	fieldLabelPairValue          = 2
+	fieldTimestampSeconds        = 1
+	fieldTimestampNanos          = 2
+)
+
+// marshalProtobuf encodes the streams as snappy-compressed PushRequest
+// message of the Loki push API
+func (s Streams) marshalProtobuf() ([]byte, error) {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var req []byte
+	for _, k := range keys {
+		stream, err := s[k].marshalProtobuf()
+		if err != nil {
+			return nil, err
+		}
+		req = protowire.AppendTag(req, fieldPushRequestStreams, protowire.BytesType)
+		req = protowire.AppendBytes(req, stream)
+	}
+
+	return snappy.Encode(nil, req), nil
+}
+
+func (s *Stream) marshalProtobuf() ([]byte, error) {
+	var buf []byte
+	buf = protowire.AppendTag(buf, fieldStreamLabels, protowire.BytesType)
+	buf = protowire.AppendString(buf, labelsString(s.Labels))
+
+	for i, l := range s.Logs {
+		if len(l) != 2 {
+			return nil, fmt.Errorf("invalid log %v", l)
+		}
+		ts, err := strconv.ParseInt(l[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q: %w", l[0], err)
+		}
+
+		var timestamp []byte
+		timestamp = protowire.AppendTag(timestamp, fieldTimestampSeconds, protowire.VarintType)
+		timestamp = protowire.AppendVarint(timestamp, uint64(ts/1e9))
+		timestamp = protowire.AppendTag(timestamp, fieldTimestampNanos, protowire.VarintType)
+		timestamp = protowire.AppendVarint(timestamp, uint64(ts%1e9))
+
+		var entry []byte
+		entry = protowire.AppendTag(entry, fieldEntryTimestamp, protowire.BytesType)
+		entry = protowire.AppendBytes(entry, timestamp)
+		entry = protowire.AppendTag(entry, fieldEntryLine, protowire.BytesType)
+		entry = protowire.AppendString(entry, l[1])
+		if i < len(s.Metadata) {
+			names := make([]string, 0, len(s.Metadata[i]))
+			for name := range s.Metadata[i] {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				var pair []byte
+				pair = protowire.AppendTag(pair, fieldLabelPairName, protowire.BytesType)
+				pair = protowire.AppendString(pair, name)
+				pair = protowire.AppendTag(pair, fieldLabelPairValue, protowire.BytesType)
+				pair = protowire.AppendString(pair, s.Metadata[i][name])
+				entry = protowire.AppendTag(entry, fieldEntryStructuredMetadata, protowire.BytesType)
+				entry = protowire.AppendBytes(entry, pair)
+			}
+		}
+
+		buf = protowire.AppendTag(buf, fieldStreamEntries, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, entry)
+	}
+
+	return buf, nil
+}
+
+// labelsString formats the labels as Prometheus label set, e.g.
+// {host="a", service="b"}
+func labelsString(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(name + "=" + strconv.Quote(labels[name]))
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
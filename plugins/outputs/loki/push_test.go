@@ -0,0 +1,88 @@
+package loki
+
+import (
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// consumeFields returns the bytes of the given field of a message
+func consumeFields(t *testing.T, msg []byte, field protowire.Number) [][]byte {
+	var values [][]byte
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		require.GreaterOrEqual(t, n, 0)
+		msg = msg[n:]
+
+		switch typ {
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(msg)
+			require.GreaterOrEqual(t, n, 0)
+			if num == field {
+				values = append(values, v)
+			}
+			msg = msg[n:]
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(msg)
+			require.GreaterOrEqual(t, n, 0)
+			if num == field {
+				values = append(values, protowire.AppendVarint(nil, v))
+			}
+			msg = msg[n:]
+		default:
+			require.FailNowf(t, "unexpected wire type", "%v", typ)
+		}
+	}
+	return values
+}
+
+func TestMarshalProtobuf(t *testing.T) {
+	s := Streams{
+		"key": &Stream{
+			Labels:   map[string]string{"host": "a", "app": `say "hi"`},
+			Logs:     []Log{{"1500000000", "first"}, {"2000000001", "second"}},
+			Metadata: []map[string]string{nil, {"trace_id": "abc"}},
+		},
+	}
+
+	compressed, err := s.marshalProtobuf()
+	require.NoError(t, err)
+	req, err := snappy.Decode(nil, compressed)
+	require.NoError(t, err)
+
+	streams := consumeFields(t, req, fieldPushRequestStreams)
+	require.Len(t, streams, 1)
+
+	labels := consumeFields(t, streams[0], fieldStreamLabels)
+	require.Equal(t, [][]byte{[]byte(`{app="say \"hi\"", host="a"}`)}, labels)
+
+	entries := consumeFields(t, streams[0], fieldStreamEntries)
+	require.Len(t, entries, 2)
+
+	timestamp := consumeFields(t, entries[0], fieldEntryTimestamp)[0]
+	seconds, _ := protowire.ConsumeVarint(consumeFields(t, timestamp, fieldTimestampSeconds)[0])
+	nanos, _ := protowire.ConsumeVarint(consumeFields(t, timestamp, fieldTimestampNanos)[0])
+	require.Equal(t, uint64(1), seconds)
+	require.Equal(t, uint64(500000000), nanos)
+	require.Equal(t, [][]byte{[]byte("first")}, consumeFields(t, entries[0], fieldEntryLine))
+	require.Empty(t, consumeFields(t, entries[0], fieldEntryStructuredMetadata))
+
+	require.Equal(t, [][]byte{[]byte("second")}, consumeFields(t, entries[1], fieldEntryLine))
+	metadata := consumeFields(t, entries[1], fieldEntryStructuredMetadata)
+	require.Len(t, metadata, 1)
+	require.Equal(t, [][]byte{[]byte("trace_id")}, consumeFields(t, metadata[0], fieldLabelPairName))
+	require.Equal(t, [][]byte{[]byte("abc")}, consumeFields(t, metadata[0], fieldLabelPairValue))
+}
+
+func TestMarshalProtobufInvalidTimestamp(t *testing.T) {
+	s := Streams{
+		"key": &Stream{
+			Labels: map[string]string{"host": "a"},
+			Logs:   []Log{{"abc", "first"}},
+		},
+	}
+	_, err := s.marshalProtobuf()
+	require.ErrorContains(t, err, "invalid timestamp")
+}
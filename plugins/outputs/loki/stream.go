@@ -14,8 +14,9 @@ type (
 	Streams map[string]*Stream
 
 	Stream struct {
-		Labels map[string]string `json:"stream"`
-		Logs   []Log             `json:"values"`
+		Labels   map[string]string   `json:"stream"`
+		Logs     []Log               `json:"values"`
+		Metadata []map[string]string `json:"-"`
 	}
 
 	Request struct {
@@ -24,6 +25,12 @@ type (
 )
 
 func (s Streams) insertLog(ts []*telegraf.Tag, l Log) {
+	s.insertEntry(ts, l, nil)
+}
+
+// insertEntry adds the log with the given structured metadata to the stream
+// identified by the tags
+func (s Streams) insertEntry(ts []*telegraf.Tag, l Log, metadata map[string]string) {
 	key := uniqKeyFromTagList(ts)
 
 	if _, ok := s[key]; !ok {
@@ -31,6 +38,7 @@ func (s Streams) insertLog(ts []*telegraf.Tag, l Log) {
 	}
 
 	s[key].Logs = append(s[key].Logs, l)
+	s[key].Metadata = append(s[key].Metadata, metadata)
 }
 
 func (s Streams) MarshalJSON() ([]byte, error) {
@@ -45,6 +53,30 @@ func (s Streams) MarshalJSON() ([]byte, error) {
 	return json.Marshal(r)
 }
 
+// MarshalJSON adds the structured metadata of a log, if any, as third element
+// of the log values
+func (s Stream) MarshalJSON() ([]byte, error) {
+	values := make([][]interface{}, 0, len(s.Logs))
+	for i, l := range s.Logs {
+		v := make([]interface{}, 0, len(l)+1)
+		for _, e := range l {
+			v = append(v, e)
+		}
+		if i < len(s.Metadata) && len(s.Metadata[i]) > 0 {
+			v = append(v, s.Metadata[i])
+		}
+		values = append(values, v)
+	}
+
+	return json.Marshal(struct {
+		Labels map[string]string `json:"stream"`
+		Values [][]interface{}   `json:"values"`
+	}{
+		Labels: s.Labels,
+		Values: values,
+	})
+}
+
 func uniqKeyFromTagList(ts []*telegraf.Tag) (k string) {
 	for _, t := range ts {
 		k += fmt.Sprintf("%s-%s-",
@@ -2,6 +2,7 @@
 package mqtt
 
 import (
+	"bytes"
 	// Blank import to support go:embed compile directive
 	_ "embed"
 	"errors"
@@ -38,12 +39,17 @@ type MQTT struct {
 	Layout          string          `toml:"layout"`
 	HomieDeviceName string          `toml:"homie_device_name"`
 	HomieNodeID     string          `toml:"homie_node_id"`
+	ContentEncoding string          `toml:"content_encoding"`
+	PersistenceDir  string          `toml:"persistence_directory"`
+	PersistenceMax  int             `toml:"persistence_max_messages"`
 	Log             telegraf.Logger `toml:"-"`
 	mqtt.MqttConfig
 
 	client     mqtt.Client
 	serializer telegraf.Serializer
 	template   *template.Template
+	encoder    internal.ContentEncoder
+	store      *store
 
 	homieDeviceNameGenerator *template.Template
 	homieNodeIDGenerator     *template.Template
@@ -116,6 +122,47 @@ func (m *MQTT) Init() error {
 		return fmt.Errorf("invalid layout %q", m.Layout)
 	}
 
+	// Compression only applies to serialized metrics, the other layouts send
+	// plain values
+	switch m.ContentEncoding {
+	case "", "identity":
+		m.ContentEncoding = "identity"
+	case "gzip", "zstd":
+		if m.Layout != "batch" && m.Layout != "non-batch" {
+			return fmt.Errorf("content encoding is not supported for layout %q", m.Layout)
+		}
+	default:
+		return fmt.Errorf("invalid content encoding %q", m.ContentEncoding)
+	}
+	m.encoder, err = internal.NewContentEncoder(m.ContentEncoding)
+	if err != nil {
+		return fmt.Errorf("creating encoder failed: %w", err)
+	}
+
+	// Hint the encoding to MQTT v5 subscribers
+	if m.ContentEncoding != "identity" && m.Protocol == "5" {
+		if m.PublishPropertiesV5 == nil {
+			m.PublishPropertiesV5 = &mqtt.PublishProperties{}
+		}
+		if m.PublishPropertiesV5.UserProperties == nil {
+			m.PublishPropertiesV5.UserProperties = make(map[string]string, 1)
+		}
+		m.PublishPropertiesV5.UserProperties["content-encoding"] = m.ContentEncoding
+	}
+
+	if m.PersistenceDir != "" {
+		if m.PersistenceMax < 0 {
+			return fmt.Errorf("invalid persistence_max_messages %d", m.PersistenceMax)
+		}
+		m.store, err = newStore(m.PersistenceDir, m.PersistenceMax)
+		if err != nil {
+			return fmt.Errorf("opening persistence directory failed: %w", err)
+		}
+		if n := m.store.len(); n > 0 {
+			m.Log.Infof("Found %d persisted messages", n)
+		}
+	}
+
 	return nil
 }
 
@@ -131,8 +178,17 @@ func (m *MQTT) Connect() error {
 	}
 	m.client = client
 
-	_, err = m.client.Connect()
-	return err
+	if _, err := m.client.Connect(); err != nil {
+		return err
+	}
+
+	// Resume publishing the messages persisted during a broker outage
+	if m.store != nil && m.store.len() > 0 {
+		if err := m.forward(); err != nil {
+			m.Log.Warnf("Publishing persisted messages failed: %v", err)
+		}
+	}
+	return nil
 }
 
 func (m *MQTT) SetSerializer(serializer telegraf.Serializer) {
@@ -177,11 +233,34 @@ func (m *MQTT) Write(metrics []telegraf.Metric) error {
 		return fmt.Errorf("unknown layout %q", m.Layout)
 	}
 
-	for _, msg := range topicMessages {
+	if m.ContentEncoding != "identity" {
+		for i, msg := range topicMessages {
+			payload, err := m.encoder.Encode(msg.payload)
+			if err != nil {
+				return fmt.Errorf("encoding message for topic %q failed: %w", msg.topic, err)
+			}
+			// The encoder reuses its buffer so copy the payload
+			topicMessages[i].payload = bytes.Clone(payload)
+		}
+	}
+
+	// Persisted messages must be published first to keep the order
+	if m.store != nil && m.store.len() > 0 {
+		if err := m.forward(); err != nil {
+			m.Log.Debugf("Publishing persisted messages failed: %v", err)
+			return m.persist(topicMessages)
+		}
+	}
+
+	for i, msg := range topicMessages {
 		if err := m.client.Publish(msg.topic, msg.payload); err != nil {
 			// We do receive a timeout error if the remote broker is down,
 			// so let's retry the metrics in this case and drop them otherwise.
 			if errors.Is(err, internal.ErrTimeout) {
+				if m.store != nil {
+					m.Log.Warnf("Could not publish message to MQTT server, persisting messages: %v", err)
+					return m.persist(topicMessages[i:])
+				}
 				return fmt.Errorf("could not publish message to MQTT server: %w", err)
 			}
 			m.Log.Warnf("Could not publish message to MQTT server: %v", err)
@@ -191,6 +270,51 @@ func (m *MQTT) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
+// forward publishes the persisted messages in order and stops at the first
+// timeout as the broker is likely down
+func (m *MQTT) forward() error {
+	var published int
+	defer func() {
+		if published > 0 {
+			m.Log.Debugf("Published %d persisted messages", published)
+		}
+	}()
+
+	for m.store.len() > 0 {
+		msg, err := m.store.peek()
+		if err != nil {
+			m.Log.Errorf("Reading persisted message failed, dropping it: %v", err)
+		} else if err := m.client.Publish(msg.topic, msg.payload); err != nil {
+			if errors.Is(err, internal.ErrTimeout) {
+				return err
+			}
+			m.Log.Warnf("Could not publish persisted message to MQTT server: %v", err)
+		} else {
+			published++
+		}
+		if err := m.store.pop(); err != nil {
+			return fmt.Errorf("removing persisted message failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// persist stores the messages for publishing them after the broker is back
+func (m *MQTT) persist(msgs []message) error {
+	var dropped int
+	for _, msg := range msgs {
+		n, err := m.store.push(msg)
+		if err != nil {
+			return fmt.Errorf("persisting message failed: %w", err)
+		}
+		dropped += n
+	}
+	if dropped > 0 {
+		m.Log.Warnf("Dropped %d oldest persisted messages due to the limit of %d messages", dropped, m.PersistenceMax)
+	}
+	return nil
+}
+
 func (m *MQTT) collectNonBatch(metrics []telegraf.Metric) []message {
 	collection := make([]message, 0, len(metrics))
 	for _, metric := range metrics {
@@ -335,6 +459,7 @@ func (m *MQTT) generateTopic(metric telegraf.Metric) (string, error) {
 func init() {
 	outputs.Add("mqtt", func() telegraf.Output {
 		return &MQTT{
+			PersistenceMax: 100000,
 			MqttConfig: mqtt.MqttConfig{
 				KeepAlive:     30,
 				Timeout:       config.Duration(5 * time.Second),
@@ -1,7 +1,11 @@
 package mqtt
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"sync"
 	"testing"
@@ -13,6 +17,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/common/mqtt"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
@@ -935,3 +940,140 @@ func TestGenerateTopicName(t *testing.T) {
 		})
 	}
 }
+
+// fakeClient records the published messages and simulates a broker outage
+type fakeClient struct {
+	mqtt.Client
+	down      bool
+	published []message
+}
+
+func (c *fakeClient) Publish(topic string, data []byte) error {
+	if c.down {
+		return internal.ErrTimeout
+	}
+	c.published = append(c.published, message{topic, data})
+	return nil
+}
+
+func newPersistencePlugin(t *testing.T, dir string, client *fakeClient) *MQTT {
+	s := &serializers_influx.Serializer{}
+	require.NoError(t, s.Init())
+
+	plugin := &MQTT{
+		Topic:          "telegraf/{{ .Name }}",
+		PersistenceDir: dir,
+		PersistenceMax: 100,
+		MqttConfig: mqtt.MqttConfig{
+			Servers: []string{"tcp://localhost:1883"},
+		},
+		Log:    testutil.Logger{},
+		client: client,
+	}
+	plugin.SetSerializer(s)
+	require.NoError(t, plugin.Init())
+	return plugin
+}
+
+func TestPersistence(t *testing.T) {
+	dir := t.TempDir()
+	metrics := []telegraf.Metric{
+		metric.New("first", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("second", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+	}
+
+	// Persist the messages while the broker is down
+	client := &fakeClient{down: true}
+	plugin := newPersistencePlugin(t, dir, client)
+	require.NoError(t, plugin.Write(metrics))
+	require.Equal(t, 2, plugin.store.len())
+	require.Empty(t, client.published)
+
+	// Resume with a new instance after the broker is back
+	client = &fakeClient{}
+	plugin = newPersistencePlugin(t, dir, client)
+	require.Equal(t, 2, plugin.store.len())
+
+	latest := metric.New("third", map[string]string{}, map[string]interface{}{"value": 3}, time.Unix(0, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{latest}))
+	require.Zero(t, plugin.store.len())
+
+	expected := []message{
+		{"telegraf/first", []byte("first value=1i 0\n")},
+		{"telegraf/second", []byte("second value=2i 0\n")},
+		{"telegraf/third", []byte("third value=3i 0\n")},
+	}
+	require.Equal(t, expected, client.published)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestPersistenceLimit(t *testing.T) {
+	client := &fakeClient{down: true}
+	plugin := newPersistencePlugin(t, t.TempDir(), client)
+	plugin.store.maxMessages = 2
+
+	for i := range 3 {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(0, 0))
+		require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	}
+	require.Equal(t, 2, plugin.store.len())
+
+	client.down = false
+	require.NoError(t, plugin.forward())
+	expected := []message{
+		{"telegraf/test", []byte("test value=1i 0\n")},
+		{"telegraf/test", []byte("test value=2i 0\n")},
+	}
+	require.Equal(t, expected, client.published)
+}
+
+func TestWithoutPersistence(t *testing.T) {
+	client := &fakeClient{down: true}
+	plugin := newPersistencePlugin(t, "", client)
+	require.Nil(t, plugin.store)
+
+	m := metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.ErrorIs(t, plugin.Write([]telegraf.Metric{m}), internal.ErrTimeout)
+}
+
+func TestContentEncoding(t *testing.T) {
+	client := &fakeClient{}
+	plugin := newPersistencePlugin(t, "", client)
+	plugin.ContentEncoding = "gzip"
+	plugin.Protocol = "5"
+	require.NoError(t, plugin.Init())
+	require.Equal(t, "gzip", plugin.PublishPropertiesV5.UserProperties["content-encoding"])
+
+	metrics := []telegraf.Metric{
+		metric.New("first", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("second", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.Len(t, client.published, 2)
+
+	for i, expected := range []string{"first value=1i 0\n", "second value=2i 0\n"} {
+		reader, err := gzip.NewReader(bytes.NewReader(client.published[i].payload))
+		require.NoError(t, err)
+		actual, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(actual))
+	}
+}
+
+func TestContentEncodingInvalid(t *testing.T) {
+	plugin := &MQTT{
+		MqttConfig:      mqtt.MqttConfig{Servers: []string{"tcp://localhost:1883"}},
+		ContentEncoding: "snappy",
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid content encoding "snappy"`)
+
+	plugin = &MQTT{
+		MqttConfig:      mqtt.MqttConfig{Servers: []string{"tcp://localhost:1883"}},
+		Layout:          "field",
+		ContentEncoding: "zstd",
+	}
+	require.ErrorContains(t, plugin.Init(), `content encoding is not supported for layout "field"`)
+}
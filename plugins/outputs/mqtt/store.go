@@ -0,0 +1,109 @@
+package mqtt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const storeSuffix = ".msg"
+
+// store persists unpublished messages on disk, one file per message named by
+// a monotonic sequence number to preserve the publishing order
+type store struct {
+	dir         string
+	maxMessages int
+	files       []string
+	next        uint64
+}
+
+func newStore(dir string, maxMessages int) (*store, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("creating directory failed: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading directory failed: %w", err)
+	}
+
+	s := &store{dir: dir, maxMessages: maxMessages}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, storeSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, storeSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		s.files = append(s.files, name)
+		s.next = max(s.next, seq+1)
+	}
+	slices.Sort(s.files)
+
+	return s, nil
+}
+
+func (s *store) len() int {
+	return len(s.files)
+}
+
+// push appends the message to the store and returns the number of old messages
+// dropped due to the size limit
+func (s *store) push(msg message) (int, error) {
+	buf := binary.AppendUvarint(nil, uint64(len(msg.topic)))
+	buf = append(buf, msg.topic...)
+	buf = append(buf, msg.payload...)
+
+	// Write to a temporary file first to not leave partial messages behind
+	name := fmt.Sprintf("%020d%s", s.next, storeSuffix)
+	tmp := filepath.Join(s.dir, name+".tmp")
+	if err := os.WriteFile(tmp, buf, 0640); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, name)); err != nil {
+		return 0, err
+	}
+	s.files = append(s.files, name)
+	s.next++
+
+	var dropped int
+	for s.maxMessages > 0 && len(s.files) > s.maxMessages {
+		if err := s.pop(); err != nil {
+			return dropped, err
+		}
+		dropped++
+	}
+	return dropped, nil
+}
+
+// peek returns the oldest message of the store
+func (s *store) peek() (message, error) {
+	buf, err := os.ReadFile(filepath.Join(s.dir, s.files[0]))
+	if err != nil {
+		return message{}, err
+	}
+	n, size := binary.Uvarint(buf)
+	if size <= 0 || uint64(len(buf)-size) < n {
+		return message{}, errors.New("corrupted message")
+	}
+	return message{
+		topic:   string(buf[size : size+int(n)]),
+		payload: buf[size+int(n):],
+	}, nil
+}
+
+// pop removes the oldest message from the store
+func (s *store) pop() error {
+	if err := os.Remove(filepath.Join(s.dir, s.files[0])); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	s.files = s.files[1:]
+	return nil
+}
@@ -7,14 +7,17 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"text/template"
 	"time"
 
+	"github.com/Masterminds/sprig/v3"
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -40,6 +43,14 @@ type NATS struct {
 	jetstreamClient       jetstream.JetStream
 	jetstreamStreamConfig *jetstream.StreamConfig
 	serializer            telegraf.Serializer
+	subjectTemplate       *template.Template
+	subjectPrefix         string
+}
+
+type message struct {
+	subject string
+	id      string
+	payload []byte
 }
 
 // StreamConfig is the configuration for creating stream
@@ -79,6 +90,7 @@ type StreamConfig struct {
 	Metadata              map[string]string                 `toml:"metadata"`
 	AsyncPublish          bool                              `toml:"async_publish"`
 	AsyncAckTimeout       *config.Duration                  `toml:"async_ack_timeout"`
+	MessageID             bool                              `toml:"message_id"`
 	DisableStreamCreation bool                              `toml:"disable_stream_creation"`
 }
 
@@ -244,6 +256,18 @@ func (n *NATS) getJetstreamConfig() (*jetstream.StreamConfig, error) {
 }
 
 func (n *NATS) Init() error {
+	if n.Subject == "" {
+		return errors.New("subject cannot be empty")
+	}
+	if strings.Contains(n.Subject, "{{") {
+		tmpl, err := template.New("subject").Funcs(sprig.TxtFuncMap()).Parse(n.Subject)
+		if err != nil {
+			return fmt.Errorf("creating subject template failed: %w", err)
+		}
+		n.subjectTemplate = tmpl
+		n.subjectPrefix = staticSubjectPrefix(n.Subject)
+	}
+
 	if n.Jetstream != nil {
 		if strings.TrimSpace(n.Jetstream.Name) == "" {
 			return errors.New("stream cannot be empty")
@@ -254,13 +278,25 @@ func (n *NATS) Init() error {
 			n.Jetstream.AsyncAckTimeout = &to
 		}
 
-		if len(n.Jetstream.Subjects) == 0 {
-			n.Jetstream.Subjects = []string{n.Subject}
-		}
-		// If the overall-subject is already present anywhere in the Jetstream subject we go from there,
-		// otherwise we should append the overall-subject as the last element.
-		if !choice.Contains(n.Subject, n.Jetstream.Subjects) {
-			n.Jetstream.Subjects = append(n.Jetstream.Subjects, n.Subject)
+		if n.subjectTemplate != nil {
+			// The stream has to capture all subjects possibly generated by
+			// the template so use a wildcard if no subjects are given.
+			if len(n.Jetstream.Subjects) == 0 {
+				subject, err := subjectWildcard(n.Subject)
+				if err != nil {
+					return err
+				}
+				n.Jetstream.Subjects = []string{subject}
+			}
+		} else {
+			if len(n.Jetstream.Subjects) == 0 {
+				n.Jetstream.Subjects = []string{n.Subject}
+			}
+			// If the overall-subject is already present anywhere in the Jetstream subject we go from there,
+			// otherwise we should append the overall-subject as the last element.
+			if !choice.Contains(n.Subject, n.Jetstream.Subjects) {
+				n.Jetstream.Subjects = append(n.Jetstream.Subjects, n.Subject)
+			}
 		}
 		var err error
 		n.jetstreamStreamConfig, err = n.getJetstreamConfig()
@@ -276,16 +312,20 @@ func (n *NATS) Close() error {
 	return nil
 }
 
-func (n *NATS) publishMessage(buf []byte) (jetstream.PubAckFuture, error) {
+func (n *NATS) publishMessage(msg message) (jetstream.PubAckFuture, error) {
 	if n.Jetstream != nil {
+		opts := []jetstream.PublishOpt{jetstream.WithExpectStream(n.Jetstream.Name)}
+		if n.Jetstream.MessageID && msg.id != "" {
+			opts = append(opts, jetstream.WithMsgID(msg.id))
+		}
 		if n.Jetstream.AsyncPublish {
-			paf, err := n.jetstreamClient.PublishAsync(n.Subject, buf, jetstream.WithExpectStream(n.Jetstream.Name))
+			paf, err := n.jetstreamClient.PublishAsync(msg.subject, msg.payload, opts...)
 			return paf, err
 		}
-		_, err := n.jetstreamClient.Publish(context.Background(), n.Subject, buf, jetstream.WithExpectStream(n.Jetstream.Name))
+		_, err := n.jetstreamClient.Publish(context.Background(), msg.subject, msg.payload, opts...)
 		return nil, err
 	}
-	err := n.conn.Publish(n.Subject, buf)
+	err := n.conn.Publish(msg.subject, msg.payload)
 	return nil, err
 }
 
@@ -294,49 +334,29 @@ func (n *NATS) Write(metrics []telegraf.Metric) error {
 		return nil
 	}
 
-	msgCount := len(metrics)
+	var messages []message
 	if n.UseBatchFormat {
-		msgCount = 1
+		messages = n.collectBatch(metrics)
+	} else {
+		messages = n.collectNonBatch(metrics)
 	}
 
 	var pafs []jetstream.PubAckFuture
 	if n.Jetstream != nil && n.Jetstream.AsyncPublish {
-		pafs = make([]jetstream.PubAckFuture, msgCount)
+		pafs = make([]jetstream.PubAckFuture, 0, len(messages))
 	}
 
-	if n.UseBatchFormat {
-		buf, err := n.serializer.SerializeBatch(metrics)
-		if err != nil {
-			n.Log.Debugf("Could not serialize batch of metrics: %v", err)
-			return nil
-		}
-		paf, err := n.publishMessage(buf)
+	for _, msg := range messages {
+		paf, err := n.publishMessage(msg)
 		if err != nil {
 			return fmt.Errorf("failed to send NATS message: %w", err)
 		}
-		if n.Jetstream != nil && n.Jetstream.AsyncPublish {
-			pafs[0] = paf
-		}
-	} else {
-		for i, metric := range metrics {
-			buf, err := n.serializer.Serialize(metric)
-			if err != nil {
-				n.Log.Debugf("Could not serialize metric: %v", err)
-				continue
-			}
-
-			paf, err := n.publishMessage(buf)
-			if err != nil {
-				return fmt.Errorf("failed to send NATS message: %w", err)
-			}
-
-			if n.Jetstream != nil && n.Jetstream.AsyncPublish {
-				pafs[i] = paf
-			}
+		if pafs != nil {
+			pafs = append(pafs, paf)
 		}
 	}
 
-	if pafs != nil {
+	if len(pafs) > 0 {
 		// Check Ack from async publish
 		select {
 		case <-n.jetstreamClient.PublishAsyncComplete():
@@ -355,6 +375,135 @@ func (n *NATS) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
+func (n *NATS) collectNonBatch(metrics []telegraf.Metric) []message {
+	messages := make([]message, 0, len(metrics))
+	for _, m := range metrics {
+		subject, err := n.generateSubject(m)
+		if err != nil {
+			n.Log.Warnf("Generating subject failed: %v", err)
+			continue
+		}
+
+		buf, err := n.serializer.Serialize(m)
+		if err != nil {
+			n.Log.Debugf("Could not serialize metric: %v", err)
+			continue
+		}
+
+		var id string
+		if n.Jetstream != nil && n.Jetstream.MessageID {
+			id = metric.IdempotencyKey(m)
+		}
+		messages = append(messages, message{subject: subject, id: id, payload: buf})
+	}
+	return messages
+}
+
+func (n *NATS) collectBatch(metrics []telegraf.Metric) []message {
+	// Group the metrics by subject keeping the order of the subjects
+	var subjects []string
+	groups := make(map[string][]telegraf.Metric)
+	for _, m := range metrics {
+		subject, err := n.generateSubject(m)
+		if err != nil {
+			n.Log.Warnf("Generating subject failed: %v", err)
+			continue
+		}
+		if _, found := groups[subject]; !found {
+			subjects = append(subjects, subject)
+		}
+		groups[subject] = append(groups[subject], m)
+	}
+
+	messages := make([]message, 0, len(subjects))
+	for _, subject := range subjects {
+		group := groups[subject]
+		buf, err := n.serializer.SerializeBatch(group)
+		if err != nil {
+			n.Log.Debugf("Could not serialize batch of metrics for subject %q: %v", subject, err)
+			continue
+		}
+
+		var id string
+		if n.Jetstream != nil && n.Jetstream.MessageID {
+			id = metric.BatchIdempotencyKey(group)
+		}
+		messages = append(messages, message{subject: subject, id: id, payload: buf})
+	}
+	return messages
+}
+
+// generateSubject renders the subject template for the given metric. Empty
+// tokens, e.g. due to missing tags, are omitted from the resulting subject.
+// The subject must consist of the static tokens of the template followed by
+// at least one generated token to match the subjects of the stream.
+func (n *NATS) generateSubject(m telegraf.Metric) (string, error) {
+	if n.subjectTemplate == nil {
+		return n.Subject, nil
+	}
+
+	if wm, ok := m.(telegraf.UnwrappableMetric); ok {
+		m = wm.Unwrap()
+	}
+
+	var b strings.Builder
+	if err := n.subjectTemplate.Execute(&b, m); err != nil {
+		return "", err
+	}
+
+	tokens := make([]string, 0, strings.Count(b.String(), ".")+1)
+	for _, t := range strings.Split(b.String(), ".") {
+		if t == "" {
+			continue
+		}
+		if strings.ContainsAny(t, " \t\r\n*>") {
+			return "", fmt.Errorf("invalid token %q in subject %q", t, b.String())
+		}
+		tokens = append(tokens, t)
+	}
+	if len(tokens) == 0 {
+		return "", fmt.Errorf("empty subject for metric %q", m.Name())
+	}
+	subject := strings.Join(tokens, ".")
+	if !strings.HasPrefix(subject, n.subjectPrefix) {
+		return "", fmt.Errorf("subject %q does not contain tokens after the static prefix %q", subject, n.subjectPrefix)
+	}
+	return subject, nil
+}
+
+// staticSubjectPrefix returns the static tokens of the template before the
+// first placeholder including the trailing separator.
+func staticSubjectPrefix(subject string) string {
+	prefix := subject[:strings.Index(subject, "{{")]
+	i := strings.LastIndex(prefix, ".")
+	if i < 0 {
+		return ""
+	}
+
+	var tokens []string
+	for _, t := range strings.Split(prefix[:i], ".") {
+		if t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	if len(tokens) == 0 {
+		return ""
+	}
+	return strings.Join(tokens, ".") + "."
+}
+
+// subjectWildcard returns the subject filter matching all subjects generated
+// by the given template. The static tokens before the first placeholder are
+// kept while the remainder is matched by the '>' wildcard as the number of
+// generated tokens may vary.
+func subjectWildcard(subject string) (string, error) {
+	prefix := staticSubjectPrefix(subject)
+	if prefix == "" {
+		return "", errors.New("subject template must start with a static token or jetstream subjects must be set")
+	}
+	return prefix + ">", nil
+}
+
 func init() {
 	outputs.Add("nats", func() telegraf.Output {
 		return &NATS{}
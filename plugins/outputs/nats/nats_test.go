@@ -14,6 +14,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -250,6 +251,48 @@ func TestConnectAndWriteIntegration(t *testing.T) {
 	}
 }
 
+func TestMessageIDIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	natsServicePort := "4222"
+	container := testutil.Container{
+		Image:        "nats:latest",
+		ExposedPorts: []string{natsServicePort},
+		Cmd:          []string{"--js"},
+		WaitingFor:   wait.ForListeningPort(nat.Port(natsServicePort)),
+	}
+	require.NoError(t, container.Start(), "failed to start container")
+	defer container.Terminate()
+
+	plugin := &NATS{
+		Servers: []string{"nats://" + container.Address + ":" + container.Ports[natsServicePort]},
+		Name:    "telegraf",
+		Subject: "telegraf",
+		Jetstream: &StreamConfig{
+			Name:      "my-telegraf-stream",
+			MessageID: true,
+		},
+		serializer: &influx.Serializer{},
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// Retrying with a changed batch must not duplicate messages in the stream
+	metrics := []telegraf.Metric{testutil.TestMetric(1.0, "first"), testutil.TestMetric(2.0, "second")}
+	require.NoError(t, plugin.Write(metrics))
+	require.NoError(t, plugin.Write(metrics[1:]))
+
+	stream, err := plugin.jetstreamClient.Stream(t.Context(), "my-telegraf-stream")
+	require.NoError(t, err)
+	si, err := stream.Info(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), si.State.Msgs)
+}
+
 func TestConfigParsing(t *testing.T) {
 	// Define test cases
 	testCases := []struct {
@@ -263,6 +306,7 @@ func TestConfigParsing(t *testing.T) {
 		{name: "Valid JS Config", path: filepath.Join("testcases", "js-config.conf")},
 		{name: "Valid JS Async Publish", path: filepath.Join("testcases", "js-async-pub.conf")},
 		{name: "Subjects warning", path: filepath.Join("testcases", "js-subjects.conf")},
+		{name: "Subject template", path: filepath.Join("testcases", "js-subject-template.conf")},
 		{name: "Invalid JS", path: filepath.Join("testcases", "js-no-stream.conf"), wantErr: true},
 	}
 
@@ -290,6 +334,172 @@ func TestConfigParsing(t *testing.T) {
 	}
 }
 
+func TestSubjectTemplate(t *testing.T) {
+	plugin := &NATS{
+		Subject: `telegraf.{{ .Tag "host" }}.{{ .Name }}`,
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New("cpu", map[string]string{"host": "server01"}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	subject, err := plugin.generateSubject(m)
+	require.NoError(t, err)
+	require.Equal(t, "telegraf.server01.cpu", subject)
+
+	// Missing tags are omitted
+	m = metric.New("mem", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	subject, err = plugin.generateSubject(m)
+	require.NoError(t, err)
+	require.Equal(t, "telegraf.mem", subject)
+
+	// Wildcards are not allowed for publishing
+	m = metric.New("disk", map[string]string{"host": "*"}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	_, err = plugin.generateSubject(m)
+	require.ErrorContains(t, err, "invalid token")
+}
+
+func TestSubjectTemplateStaticPrefixOnly(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	plugin := &NATS{
+		Subject: `metrics.{{ .Tag "region" }}`,
+		Jetstream: &StreamConfig{
+			Name: "telegraf",
+		},
+		serializer: serializer,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, []string{"metrics.>"}, plugin.jetstreamStreamConfig.Subjects)
+
+	// Subjects consisting of the static prefix only are not captured by the
+	// stream, so those metrics are dropped
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	_, err := plugin.generateSubject(m)
+	require.ErrorContains(t, err, `subject "metrics" does not contain tokens after the static prefix "metrics."`)
+
+	metrics := []telegraf.Metric{
+		m,
+		metric.New("cpu", map[string]string{"region": "eu"}, map[string]interface{}{"value": 23.0}, time.Unix(0, 0)),
+	}
+	messages := plugin.collectNonBatch(metrics)
+	require.Len(t, messages, 1)
+	require.Equal(t, "metrics.eu", messages[0].subject)
+
+	plugin.UseBatchFormat = true
+	messages = plugin.collectBatch(metrics)
+	require.Len(t, messages, 1)
+	require.Equal(t, "metrics.eu", messages[0].subject)
+}
+
+func TestSubjectTemplateJetstream(t *testing.T) {
+	tests := []struct {
+		name     string
+		subject  string
+		subjects []string
+		expected []string
+		err      string
+	}{
+		{
+			name:     "static",
+			subject:  "telegraf",
+			expected: []string{"telegraf"},
+		},
+		{
+			name:     "template",
+			subject:  `telegraf.metrics.{{ .Tag "host" }}.{{ .Name }}`,
+			expected: []string{"telegraf.metrics.>"},
+		},
+		{
+			name:     "explicit subjects",
+			subject:  `{{ .Name }}.telegraf`,
+			subjects: []string{"cpu.*", "mem.*"},
+			expected: []string{"cpu.*", "mem.*"},
+		},
+		{
+			name:    "template without static token",
+			subject: `{{ .Name }}.telegraf`,
+			err:     "subject template must start with a static token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &NATS{
+				Subject: tt.subject,
+				Jetstream: &StreamConfig{
+					Name:     "telegraf",
+					Subjects: tt.subjects,
+				},
+				Log: testutil.Logger{},
+			}
+			err := plugin.Init()
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, plugin.jetstreamStreamConfig.Subjects)
+		})
+	}
+}
+
+func TestSubjectTemplateTrackingMetric(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	plugin := &NATS{
+		Subject:    `telegraf.{{ .Tag "host" }}.{{ .Name }}`,
+		serializer: serializer,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var delivered bool
+	input := metric.New("cpu", map[string]string{"host": "server01"}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	m, _ := metric.WithTracking(input, func(info telegraf.DeliveryInfo) { delivered = info.Delivered() })
+
+	messages := plugin.collectNonBatch([]telegraf.Metric{m})
+	require.Len(t, messages, 1)
+	require.Equal(t, "telegraf.server01.cpu", messages[0].subject)
+	require.Equal(t, "cpu,host=server01 value=42 0\n", string(messages[0].payload))
+
+	m.Accept()
+	require.True(t, delivered)
+}
+
+func TestCollectBatchBySubject(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	plugin := &NATS{
+		Subject:        `telegraf.{{ .Name }}`,
+		UseBatchFormat: true,
+		Jetstream: &StreamConfig{
+			Name:      "telegraf",
+			MessageID: true,
+		},
+		serializer: serializer,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+	}
+	messages := plugin.collectBatch(metrics)
+	require.Len(t, messages, 2)
+	require.Equal(t, "telegraf.cpu", messages[0].subject)
+	require.Equal(t, "cpu value=1 0\ncpu value=3 0\n", string(messages[0].payload))
+	require.NotEmpty(t, messages[0].id)
+	require.Equal(t, "telegraf.mem", messages[1].subject)
+	require.Equal(t, "mem value=2 0\n", string(messages[1].payload))
+	require.NotEqual(t, messages[0].id, messages[1].id)
+}
+
 func createStream(t *testing.T, server string, cfg *nats.StreamConfig) {
 	t.Helper()
 
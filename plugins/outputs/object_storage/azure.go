@@ -0,0 +1,70 @@
+package object_storage
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+)
+
+type azureClient struct {
+	client          *azblob.Client
+	container       string
+	blockSize       int64
+	encryptionScope string
+}
+
+func (o *ObjectStorage) newAzureClient() (*azureClient, error) {
+	options := &azblob.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Retry: policy.RetryOptions{MaxRetries: int32(o.MaxRetries)},
+		},
+	}
+
+	var client *azblob.Client
+	if !o.ConnectionString.Empty() {
+		connectionString, err := o.ConnectionString.Get()
+		if err != nil {
+			return nil, err
+		}
+		client, err = azblob.NewClientFromConnectionString(connectionString.String(), options)
+		connectionString.Destroy()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		cred, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, err
+		}
+		client, err = azblob.NewClient(o.EndpointURL, cred, options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &azureClient{
+		client:          client,
+		container:       o.Bucket,
+		blockSize:       int64(o.PartSize),
+		encryptionScope: o.KMSKeyID,
+	}, nil
+}
+
+func (c *azureClient) upload(ctx context.Context, key string, data []byte) error {
+	// Objects larger than the block size are uploaded as multiple blocks
+	options := &azblob.UploadBufferOptions{BlockSize: c.blockSize}
+	if c.encryptionScope != "" {
+		options.CPKScopeInfo = &blob.CPKScopeInfo{EncryptionScope: &c.encryptionScope}
+	}
+
+	_, err := c.client.UploadBuffer(ctx, c.container, key, data, options)
+	return err
+}
+
+func (*azureClient) close() error {
+	return nil
+}
@@ -0,0 +1,60 @@
+package object_storage
+
+import (
+	"context"
+	"errors"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+type gcsClient struct {
+	client     *storage.Client
+	bucket     string
+	chunkSize  int
+	kmsKeyName string
+}
+
+func (o *ObjectStorage) newGCSClient() (*gcsClient, error) {
+	var opts []option.ClientOption
+	if o.CredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(o.CredentialsFile))
+	}
+	if o.EndpointURL != "" {
+		opts = append(opts, option.WithEndpoint(o.EndpointURL))
+	}
+
+	client, err := storage.NewClient(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Object names are unique so retrying uploads cannot overwrite data
+	client.SetRetry(
+		storage.WithMaxAttempts(o.MaxRetries+1),
+		storage.WithPolicy(storage.RetryAlways),
+	)
+
+	return &gcsClient{
+		client:     client,
+		bucket:     o.Bucket,
+		chunkSize:  int(o.PartSize),
+		kmsKeyName: o.KMSKeyID,
+	}, nil
+}
+
+func (c *gcsClient) upload(ctx context.Context, key string, data []byte) error {
+	// Objects larger than the chunk size are uploaded using resumable uploads
+	w := c.client.Bucket(c.bucket).Object(key).NewWriter(ctx)
+	w.ChunkSize = c.chunkSize
+	w.KMSKeyName = c.kmsKeyName
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Join(err, w.Close())
+	}
+	return w.Close()
+}
+
+func (c *gcsClient) close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,320 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package object_storage
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Interval for checking the age of the objects being accumulated
+const checkInterval = time.Second
+
+type ObjectStorage struct {
+	Service          string          `toml:"service"`
+	Bucket           string          `toml:"bucket"`
+	KeyPrefix        string          `toml:"key_prefix"`
+	Partition        string          `toml:"partition"`
+	ObjectSuffix     string          `toml:"object_suffix"`
+	MaxObjectSize    config.Size     `toml:"max_object_size"`
+	MaxObjectAge     config.Duration `toml:"max_object_age"`
+	PartSize         config.Size     `toml:"part_size"`
+	MaxRetries       int             `toml:"max_retries"`
+	KMSKeyID         string          `toml:"kms_key_id"`
+	Timeout          config.Duration `toml:"timeout"`
+	ForcePathStyle   bool            `toml:"force_path_style"`
+	CredentialsFile  string          `toml:"credentials_file"`
+	ConnectionString config.Secret   `toml:"connection_string"`
+	Log              telegraf.Logger `toml:"-"`
+	common_aws.CredentialConfig
+
+	client         client
+	partition      *template.Template
+	serializerFunc telegraf.SerializerFunc
+
+	// objects contains the objects being accumulated per partition and
+	// pending the objects ready for uploading
+	objects map[string]*object
+	pending []*pendingObject
+	mu      sync.Mutex
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// client uploads objects to the storage service
+type client interface {
+	upload(ctx context.Context, key string, data []byte) error
+	close() error
+}
+
+type object struct {
+	created    time.Time
+	serializer telegraf.Serializer
+	buf        bytes.Buffer
+}
+
+type pendingObject struct {
+	key  string
+	data []byte
+}
+
+func (*ObjectStorage) SampleConfig() string {
+	return sampleConfig
+}
+
+func (o *ObjectStorage) SetSerializerFunc(sf telegraf.SerializerFunc) {
+	o.serializerFunc = sf
+}
+
+func (o *ObjectStorage) Init() error {
+	switch o.Service {
+	case "":
+		return errors.New("no service specified")
+	case "s3":
+		if o.PartSize < 5*1024*1024 {
+			return errors.New("'part_size' must be at least 5MiB for S3")
+		}
+	case "gcs":
+	case "azure":
+		if o.EndpointURL == "" && o.ConnectionString.Empty() {
+			return errors.New("either 'endpoint_url' or 'connection_string' must be specified for Azure")
+		}
+	default:
+		return fmt.Errorf("invalid service %q", o.Service)
+	}
+
+	if o.Bucket == "" {
+		return errors.New("no bucket specified")
+	}
+	if o.Partition == "" {
+		return errors.New("no partition specified")
+	}
+	if o.MaxObjectSize <= 0 {
+		return errors.New("'max_object_size' must be positive")
+	}
+	if o.MaxObjectAge <= 0 {
+		return errors.New("'max_object_age' must be positive")
+	}
+	if o.PartSize <= 0 {
+		return errors.New("'part_size' must be positive")
+	}
+	if o.MaxRetries < 0 {
+		return errors.New("'max_retries' must not be negative")
+	}
+
+	tmpl, err := template.New("partition").Parse(o.Partition)
+	if err != nil {
+		return fmt.Errorf("parsing partition template failed: %w", err)
+	}
+	o.partition = tmpl
+	o.objects = make(map[string]*object)
+
+	return nil
+}
+
+func (o *ObjectStorage) Connect() error {
+	var c client
+	var err error
+	switch o.Service {
+	case "s3":
+		c, err = o.newS3Client()
+	case "gcs":
+		c, err = o.newGCSClient()
+	case "azure":
+		c, err = o.newAzureClient()
+	}
+	if err != nil {
+		return fmt.Errorf("creating %s client failed: %w", o.Service, err)
+	}
+	o.client = c
+
+	// Upload the objects exceeding the maximum age even if no metrics arrive
+	ctx, cancel := context.WithCancel(context.Background())
+	o.cancel = cancel
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				o.mu.Lock()
+				o.sealExpired(now)
+				if err := o.uploadPending(); err != nil {
+					o.Log.Errorf("%v, retrying later", err)
+				}
+				o.mu.Unlock()
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (o *ObjectStorage) Close() error {
+	if o.cancel != nil {
+		o.cancel()
+		o.wg.Wait()
+		o.cancel = nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.client == nil {
+		return nil
+	}
+
+	// Upload all objects regardless of their size and age
+	for partition := range o.objects {
+		o.seal(partition)
+	}
+	err := o.uploadPending()
+	if err != nil {
+		err = fmt.Errorf("%w, dropping %d objects", err, len(o.pending))
+		o.pending = nil
+	}
+
+	return errors.Join(err, o.client.close())
+}
+
+func (o *ObjectStorage) Write(metrics []telegraf.Metric) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// Reject new metrics as long as previous objects cannot be uploaded to
+	// keep the metrics in the output buffer instead of accumulating them
+	if err := o.uploadPending(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var buf bytes.Buffer
+	for _, raw := range metrics {
+		m := raw
+		if wm, ok := raw.(telegraf.UnwrappableMetric); ok {
+			m = wm.Unwrap()
+		}
+
+		buf.Reset()
+		if err := o.partition.Execute(&buf, m); err != nil {
+			o.Log.Errorf("Cannot create partition for metric %v: %v", m, err)
+			continue
+		}
+		partition := buf.String()
+
+		obj, found := o.objects[partition]
+		if !found {
+			serializer, err := o.serializerFunc()
+			if err != nil {
+				return fmt.Errorf("creating serializer failed: %w", err)
+			}
+			obj = &object{created: now, serializer: serializer}
+			o.objects[partition] = obj
+		}
+
+		serialized, err := obj.serializer.Serialize(m)
+		if err != nil {
+			o.Log.Debugf("Could not serialize metric: %v", err)
+			continue
+		}
+		obj.buf.Write(serialized)
+
+		if obj.buf.Len() >= int(o.MaxObjectSize) {
+			o.seal(partition)
+		}
+	}
+	o.sealExpired(now)
+
+	// The metrics are accepted at this point, failed uploads are retried
+	// with the next write
+	if err := o.uploadPending(); err != nil {
+		o.Log.Errorf("%v, retrying with next write", err)
+	}
+
+	return nil
+}
+
+// seal finishes the object of the given partition and queues it for uploading
+func (o *ObjectStorage) seal(partition string) {
+	obj := o.objects[partition]
+	delete(o.objects, partition)
+	if obj.buf.Len() == 0 {
+		return
+	}
+
+	// Use a random suffix to avoid collisions between multiple instances
+	// writing to the same partition
+	id, err := uuid.NewV4()
+	if err != nil {
+		o.Log.Errorf("Generating object ID failed: %v", err)
+	}
+	name := fmt.Sprintf("%d-%s%s", obj.created.UnixNano(), id.String()[:8], o.ObjectSuffix)
+
+	o.pending = append(o.pending, &pendingObject{
+		key:  path.Join(o.KeyPrefix, partition, name),
+		data: obj.buf.Bytes(),
+	})
+}
+
+// sealExpired queues all objects exceeding the maximum age for uploading
+func (o *ObjectStorage) sealExpired(now time.Time) {
+	for partition, obj := range o.objects {
+		if now.Sub(obj.created) >= time.Duration(o.MaxObjectAge) {
+			o.seal(partition)
+		}
+	}
+}
+
+// uploadPending uploads the queued objects in order and stops at the first
+// failing upload to retry it later
+func (o *ObjectStorage) uploadPending() error {
+	for len(o.pending) > 0 {
+		p := o.pending[0]
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
+		err := o.client.upload(ctx, p.key, p.data)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("uploading object %q failed: %w", p.key, err)
+		}
+		o.Log.Debugf("Uploaded object %q with %d bytes", p.key, len(p.data))
+
+		o.pending[0] = nil
+		o.pending = o.pending[1:]
+	}
+	return nil
+}
+
+func init() {
+	outputs.Add("object_storage", func() telegraf.Output {
+		return &ObjectStorage{
+			Partition:     `{{.Name}}/{{.Time.UTC.Format "2006/01/02/15"}}`,
+			MaxObjectSize: config.Size(64 * 1024 * 1024),
+			MaxObjectAge:  config.Duration(5 * time.Minute),
+			PartSize:      config.Size(8 * 1024 * 1024),
+			MaxRetries:    3,
+			Timeout:       config.Duration(5 * time.Minute),
+		}
+	})
+}
@@ -0,0 +1,227 @@
+package object_storage
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeClient records the uploaded objects
+type fakeClient struct {
+	objects   map[string]string
+	uploadErr error
+	closed    bool
+}
+
+func (c *fakeClient) upload(_ context.Context, key string, data []byte) error {
+	if c.uploadErr != nil {
+		return c.uploadErr
+	}
+	if c.objects == nil {
+		c.objects = make(map[string]string)
+	}
+	c.objects[key] = string(data)
+	return nil
+}
+
+func (c *fakeClient) close() error {
+	c.closed = true
+	return nil
+}
+
+// keys returns the uploaded object keys with the object name stripped
+func (c *fakeClient) keys(t *testing.T) []string {
+	re := regexp.MustCompile(`/\d+-[0-9a-f]{8}(\.lp)?$`)
+	keys := make([]string, 0, len(c.objects))
+	for k := range c.objects {
+		require.Regexp(t, re, k)
+		keys = append(keys, re.ReplaceAllString(k, ""))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func newPlugin(t *testing.T, c *fakeClient) *ObjectStorage {
+	plugin := outputs.Outputs["object_storage"]().(*ObjectStorage)
+	plugin.Service = "s3"
+	plugin.Bucket = "telegraf"
+	plugin.Log = testutil.Logger{}
+	plugin.SetSerializerFunc(func() (telegraf.Serializer, error) {
+		s := &influx.Serializer{}
+		err := s.Init()
+		return s, err
+	})
+	require.NoError(t, plugin.Init())
+	plugin.client = c
+	return plugin
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*ObjectStorage)
+		expected string
+	}{
+		{
+			name:     "no service",
+			modify:   func(o *ObjectStorage) { o.Service = "" },
+			expected: "no service specified",
+		},
+		{
+			name:     "invalid service",
+			modify:   func(o *ObjectStorage) { o.Service = "ftp" },
+			expected: `invalid service "ftp"`,
+		},
+		{
+			name:     "no bucket",
+			modify:   func(o *ObjectStorage) { o.Bucket = "" },
+			expected: "no bucket specified",
+		},
+		{
+			name:     "small part size for s3",
+			modify:   func(o *ObjectStorage) { o.PartSize = config.Size(1024 * 1024) },
+			expected: "'part_size' must be at least 5MiB for S3",
+		},
+		{
+			name:     "azure without endpoint",
+			modify:   func(o *ObjectStorage) { o.Service = "azure" },
+			expected: "either 'endpoint_url' or 'connection_string' must be specified",
+		},
+		{
+			name:     "no max age",
+			modify:   func(o *ObjectStorage) { o.MaxObjectAge = 0 },
+			expected: "'max_object_age' must be positive",
+		},
+		{
+			name:     "invalid partition",
+			modify:   func(o *ObjectStorage) { o.Partition = "{{.Name" },
+			expected: "parsing partition template failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := outputs.Outputs["object_storage"]().(*ObjectStorage)
+			plugin.Service = "s3"
+			plugin.Bucket = "telegraf"
+			plugin.Log = testutil.Logger{}
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestPartitioning(t *testing.T) {
+	c := &fakeClient{}
+	plugin := newPlugin(t, c)
+	plugin.KeyPrefix = "metrics"
+	plugin.ObjectSuffix = ".lp"
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0)),
+		metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"value": 2.0}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 3.0}, time.Unix(1700003600, 0)),
+		metric.New("cpu", map[string]string{"host": "c"}, map[string]interface{}{"value": 4.0}, time.Unix(1700000010, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// Nothing must be uploaded before reaching a threshold
+	require.Empty(t, c.objects)
+
+	require.NoError(t, plugin.Close())
+	require.True(t, c.closed)
+	require.Equal(t, []string{
+		"metrics/cpu/2023/11/14/22",
+		"metrics/cpu/2023/11/14/23",
+		"metrics/mem/2023/11/14/22",
+	}, c.keys(t))
+
+	contents := make([]string, 0, len(c.objects))
+	for _, v := range c.objects {
+		contents = append(contents, v)
+	}
+	sort.Strings(contents)
+	require.Equal(t, []string{
+		"cpu,host=a value=1 1700000000000000000\ncpu,host=c value=4 1700000010000000000\n",
+		"cpu,host=b value=3 1700003600000000000\n",
+		"mem,host=a value=2 1700000000000000000\n",
+	}, contents)
+}
+
+func TestSizeThreshold(t *testing.T) {
+	c := &fakeClient{}
+	plugin := newPlugin(t, c)
+	plugin.MaxObjectSize = 100
+
+	m := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m, m}))
+	require.Empty(t, c.objects)
+
+	// The third metric exceeds the maximum size
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Len(t, c.objects, 1)
+	for _, v := range c.objects {
+		require.Len(t, v, 117)
+	}
+	require.Empty(t, plugin.objects)
+}
+
+func TestAgeThreshold(t *testing.T) {
+	c := &fakeClient{}
+	plugin := newPlugin(t, c)
+
+	m := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Empty(t, c.objects)
+
+	// Age the object and trigger the upload with the next write
+	for _, obj := range plugin.objects {
+		obj.created = obj.created.Add(-time.Duration(plugin.MaxObjectAge))
+	}
+	require.NoError(t, plugin.Write(nil))
+	require.Len(t, c.objects, 1)
+	require.Empty(t, plugin.objects)
+}
+
+func TestUploadRetry(t *testing.T) {
+	c := &fakeClient{uploadErr: errors.New("service unavailable")}
+	plugin := newPlugin(t, c)
+	plugin.MaxObjectSize = 10
+
+	m := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0))
+
+	// The metrics are accepted and the object is kept for retrying
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Len(t, plugin.pending, 1)
+
+	// New metrics must be rejected while the upload is failing
+	require.ErrorContains(t, plugin.Write([]telegraf.Metric{m}), "service unavailable")
+	require.Len(t, plugin.pending, 1)
+
+	c.uploadErr = nil
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Len(t, c.objects, 2)
+	require.Empty(t, plugin.pending)
+}
+
+func TestCloseFailed(t *testing.T) {
+	c := &fakeClient{uploadErr: errors.New("service unavailable")}
+	plugin := newPlugin(t, c)
+
+	m := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.ErrorContains(t, plugin.Close(), "dropping 1 objects")
+	require.True(t, c.closed)
+}
@@ -0,0 +1,62 @@
+package object_storage
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type s3Client struct {
+	uploader *manager.Uploader
+	bucket   string
+	kmsKeyID string
+}
+
+func (o *ObjectStorage) newS3Client() (*s3Client, error) {
+	cfg, err := o.CredentialConfig.Credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	svc := s3.NewFromConfig(cfg, func(opts *s3.Options) {
+		if o.EndpointURL != "" {
+			opts.BaseEndpoint = aws.String(o.EndpointURL)
+		}
+		opts.UsePathStyle = o.ForcePathStyle
+		opts.RetryMaxAttempts = o.MaxRetries + 1
+	})
+
+	// Objects larger than the part size are uploaded using multipart uploads
+	uploader := manager.NewUploader(svc, func(u *manager.Uploader) {
+		u.PartSize = int64(o.PartSize)
+	})
+
+	return &s3Client{
+		uploader: uploader,
+		bucket:   o.Bucket,
+		kmsKeyID: o.KMSKeyID,
+	}, nil
+}
+
+func (c *s3Client) upload(ctx context.Context, key string, data []byte) error {
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	}
+	if c.kmsKeyID != "" {
+		input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		input.SSEKMSKeyId = aws.String(c.kmsKeyID)
+	}
+
+	_, err := c.uploader.Upload(ctx, input)
+	return err
+}
+
+func (*s3Client) close() error {
+	return nil
+}
@@ -0,0 +1,156 @@
+package opentelemetry
+
+import (
+	"math"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	// Scale used for computing the bucket indices, the scale is reduced
+	// until the buckets fit into the maximum number of buckets
+	exponentialMaxScale = 20
+	exponentialMinScale = -10
+
+	// Maximum number of positive and negative buckets, matching the default
+	// of the OpenTelemetry SDKs
+	exponentialMaxSize = 160
+)
+
+// convertToExponentialHistograms replaces all histograms with explicit bounds
+// by exponential histograms. As the distribution within the explicit buckets
+// is unknown, the observations of each bucket are assigned to the
+// exponential bucket containing the upper bound of the explicit bucket.
+func convertToExponentialHistograms(md pmetric.Metrics) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				m := metrics.At(k)
+				if m.Type() != pmetric.MetricTypeHistogram {
+					continue
+				}
+				convertHistogram(m)
+			}
+		}
+	}
+}
+
+func convertHistogram(m pmetric.Metric) {
+	histogram := pmetric.NewHistogram()
+	m.Histogram().MoveTo(histogram)
+
+	exponential := m.SetEmptyExponentialHistogram()
+	exponential.SetAggregationTemporality(histogram.AggregationTemporality())
+	for i := 0; i < histogram.DataPoints().Len(); i++ {
+		convertDataPoint(histogram.DataPoints().At(i), exponential.DataPoints().AppendEmpty())
+	}
+}
+
+func convertDataPoint(src pmetric.HistogramDataPoint, dst pmetric.ExponentialHistogramDataPoint) {
+	src.Attributes().CopyTo(dst.Attributes())
+	dst.SetStartTimestamp(src.StartTimestamp())
+	dst.SetTimestamp(src.Timestamp())
+	dst.SetFlags(src.Flags())
+	dst.SetCount(src.Count())
+	if src.HasSum() {
+		dst.SetSum(src.Sum())
+	}
+	if src.HasMin() {
+		dst.SetMin(src.Min())
+	}
+	if src.HasMax() {
+		dst.SetMax(src.Max())
+	}
+
+	// Collect the bucket counts at the maximum scale. The last explicit
+	// bucket is unbounded, so use the bucket above the last bound. Without
+	// any bounds, the mean is the best guess for all observations.
+	bounds := src.ExplicitBounds()
+	counts := src.BucketCounts()
+	positive := make(map[int64]uint64)
+	negative := make(map[int64]uint64)
+	var zero uint64
+	for i := 0; i < counts.Len(); i++ {
+		count := counts.At(i)
+		if count == 0 {
+			continue
+		}
+
+		var v float64
+		var next int64
+		switch {
+		case bounds.Len() == 0:
+			if src.HasSum() && src.Count() > 0 {
+				v = src.Sum() / float64(src.Count())
+			}
+		case i < bounds.Len():
+			v = bounds.At(i)
+		default:
+			v = bounds.At(bounds.Len() - 1)
+			next = 1
+		}
+
+		switch {
+		case v > 0:
+			positive[exponentialIndex(v)+next] += count
+		case v < 0 && next == 0:
+			negative[exponentialIndex(-v)] += count
+		default:
+			zero += count
+		}
+	}
+
+	// Reduce the scale until all buckets fit, merging pairs of buckets
+	scale := int64(exponentialMaxScale)
+	for scale > exponentialMinScale && (span(positive, exponentialMaxScale-scale) > exponentialMaxSize ||
+		span(negative, exponentialMaxScale-scale) > exponentialMaxSize) {
+		scale--
+	}
+
+	dst.SetScale(int32(scale))
+	dst.SetZeroCount(zero)
+	fillBuckets(dst.Positive(), positive, exponentialMaxScale-scale)
+	fillBuckets(dst.Negative(), negative, exponentialMaxScale-scale)
+}
+
+// exponentialIndex returns the index of the bucket containing the given
+// positive value at the maximum scale. Buckets are upper-inclusive.
+func exponentialIndex(v float64) int64 {
+	return int64(math.Ceil(math.Log2(v)*(1<<exponentialMaxScale))) - 1
+}
+
+// span returns the number of buckets required for the given indices when
+// reducing the scale by the given shift
+func span(indices map[int64]uint64, shift int64) int64 {
+	if len(indices) == 0 {
+		return 0
+	}
+	first, last := int64(math.MaxInt64), int64(math.MinInt64)
+	for idx := range indices {
+		first = min(first, idx>>shift)
+		last = max(last, idx>>shift)
+	}
+	return last - first + 1
+}
+
+func fillBuckets(dst pmetric.ExponentialHistogramDataPointBuckets, indices map[int64]uint64, shift int64) {
+	n := span(indices, shift)
+	if n == 0 {
+		return
+	}
+
+	offset := int64(math.MaxInt64)
+	for idx := range indices {
+		offset = min(offset, idx>>shift)
+	}
+	counts := make([]uint64, n)
+	for idx, count := range indices {
+		counts[idx>>shift-offset] += count
+	}
+
+	dst.SetOffset(int32(offset))
+	dst.BucketCounts().FromRaw(counts)
+}
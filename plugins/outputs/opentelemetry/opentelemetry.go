@@ -5,7 +5,9 @@ import (
 	"context"
 	ntls "crypto/tls"
 	_ "embed"
+	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb-observability/common"
@@ -19,6 +21,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -33,11 +36,14 @@ type OpenTelemetry struct {
 	ServiceAddress string `toml:"service_address"`
 
 	tls.ClientConfig
-	Timeout     config.Duration   `toml:"timeout"`
-	Compression string            `toml:"compression"`
-	Headers     map[string]string `toml:"headers"`
-	Attributes  map[string]string `toml:"attributes"`
-	Coralogix   *CoralogixConfig  `toml:"coralogix"`
+	Timeout           config.Duration   `toml:"timeout"`
+	Compression       string            `toml:"compression"`
+	ResourceTags      []string          `toml:"resource_tags"`
+	ResourceTagNames  map[string]string `toml:"resource_tag_names"`
+	HistogramEncoding string            `toml:"histogram_encoding"`
+	Headers           map[string]string `toml:"headers"`
+	Attributes        map[string]string `toml:"attributes"`
+	Coralogix         *CoralogixConfig  `toml:"coralogix"`
 
 	Log telegraf.Logger `toml:"-"`
 
@@ -45,6 +51,8 @@ type OpenTelemetry struct {
 	grpcClientConn       *grpc.ClientConn
 	metricsServiceClient pmetricotlp.GRPCClient
 	callOptions          []grpc.CallOption
+	resourceTags         filter.Filter
+	retryAfter           time.Time
 }
 
 type CoralogixConfig struct {
@@ -57,6 +65,24 @@ func (*OpenTelemetry) SampleConfig() string {
 	return sampleConfig
 }
 
+func (o *OpenTelemetry) Init() error {
+	switch o.HistogramEncoding {
+	case "":
+		o.HistogramEncoding = "explicit"
+	case "explicit", "exponential":
+	default:
+		return fmt.Errorf("invalid 'histogram_encoding' %q", o.HistogramEncoding)
+	}
+
+	f, err := filter.Compile(o.ResourceTags)
+	if err != nil {
+		return fmt.Errorf("compiling 'resource_tags' failed: %w", err)
+	}
+	o.resourceTags = f
+
+	return nil
+}
+
 func (o *OpenTelemetry) Connect() error {
 	logger := &otelLogger{o.Log}
 
@@ -122,16 +148,21 @@ func (o *OpenTelemetry) Close() error {
 	return nil
 }
 
-// Split metrics up by timestamp and send to Google Cloud Stackdriver
+// Split metrics up by timestamp and send to the OpenTelemetry endpoint
 func (o *OpenTelemetry) Write(metrics []telegraf.Metric) error {
-	metricBatch := make(map[int64][]telegraf.Metric)
+	// Honor the backoff requested by the server for retry-able errors
+	if wait := time.Until(o.retryAfter); wait > 0 {
+		return fmt.Errorf("server requested to retry after %s", wait.Round(time.Millisecond))
+	}
+
+	metricBatch := make(map[int64][]int)
 	timestamps := make([]int64, 0, len(metrics))
-	for _, metric := range metrics {
+	for i, metric := range metrics {
 		timestamp := metric.Time().UnixNano()
 		if existingSlice, ok := metricBatch[timestamp]; ok {
-			metricBatch[timestamp] = append(existingSlice, metric)
+			metricBatch[timestamp] = append(existingSlice, i)
 		} else {
-			metricBatch[timestamp] = []telegraf.Metric{metric}
+			metricBatch[timestamp] = []int{i}
 			timestamps = append(timestamps, timestamp)
 		}
 	}
@@ -140,17 +171,55 @@ func (o *OpenTelemetry) Write(metrics []telegraf.Metric) error {
 	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
 
 	o.Log.Debugf("Received %d metrics and split into %d groups by timestamp", len(metrics), len(metricBatch))
+	accepted := make([]int, 0, len(metrics))
+	var rejected []int
 	for _, timestamp := range timestamps {
-		if err := o.sendBatch(metricBatch[timestamp]); err != nil {
+		indices := metricBatch[timestamp]
+		batch := make([]telegraf.Metric, 0, len(indices))
+		for _, i := range indices {
+			batch = append(batch, metrics[i])
+		}
+
+		err := o.sendBatch(batch)
+		if err == nil {
+			accepted = append(accepted, indices...)
+			continue
+		}
+
+		// Drop the metrics rejected by the server as invalid, the request
+		// will never succeed. Keep all other metrics for retrying.
+		if !o.handleError(err) {
+			o.Log.Errorf("Dropping %d metrics rejected by the server: %v", len(indices), err)
+			rejected = append(rejected, indices...)
+			continue
+		}
+		if len(accepted) == 0 && len(rejected) == 0 {
 			return err
 		}
+		return &internal.PartialWriteError{
+			Err:           err,
+			MetricsAccept: accepted,
+			MetricsReject: rejected,
+		}
+	}
+
+	if len(rejected) > 0 {
+		return &internal.PartialWriteError{
+			Err:           fmt.Errorf("server rejected %d metrics", len(rejected)),
+			MetricsAccept: accepted,
+			MetricsReject: rejected,
+		}
 	}
 
 	return nil
 }
 
 func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
-	batch := o.metricsConverter.NewBatch()
+	// Group the metrics by their resource attributes taken from the tags as
+	// the converter derives the resource from semantic-convention tags only
+	resources := make(map[string]map[string]string)
+	batches := make(map[string]*influx2otel.MetricsBatch)
+	keys := make([]string, 0, 1)
 	for _, metric := range metrics {
 		var vType common.InfluxMetricValueType
 		switch metric.Type() {
@@ -168,14 +237,34 @@ func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
 			o.Log.Warnf("Unrecognized metric type %v", metric.Type())
 			continue
 		}
-		err := batch.AddPoint(metric.Name(), metric.Tags(), metric.Fields(), metric.Time(), vType)
+
+		tags, resource := o.splitTags(metric)
+		key := resourceKey(resource)
+		batch, found := batches[key]
+		if !found {
+			batch = o.metricsConverter.NewBatch()
+			batches[key] = batch
+			resources[key] = resource
+			keys = append(keys, key)
+		}
+		err := batch.AddPoint(metric.Name(), tags, metric.Fields(), metric.Time(), vType)
 		if err != nil {
 			o.Log.Warnf("Failed to add point: %v", err)
 			continue
 		}
 	}
 
-	md := pmetricotlp.NewExportRequestFromMetrics(batch.GetMetrics())
+	md := pmetricotlp.NewExportRequest()
+	for _, key := range keys {
+		rms := batches[key].GetMetrics().ResourceMetrics()
+		for i := 0; i < rms.Len(); i++ {
+			attrs := rms.At(i).Resource().Attributes()
+			for k, v := range resources[key] {
+				attrs.PutStr(k, v)
+			}
+		}
+		rms.MoveAndAppendTo(md.Metrics().ResourceMetrics())
+	}
 	if md.Metrics().ResourceMetrics().Len() == 0 {
 		return nil
 	}
@@ -188,14 +277,60 @@ func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
 		}
 	}
 
+	if o.HistogramEncoding == "exponential" {
+		convertToExponentialHistograms(md.Metrics())
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
 
 	if len(o.Headers) > 0 {
 		ctx = metadata.NewOutgoingContext(ctx, metadata.New(o.Headers))
 	}
 	defer cancel()
-	_, err := o.metricsServiceClient.Export(ctx, md, o.callOptions...)
-	return err
+	resp, err := o.metricsServiceClient.Export(ctx, md, o.callOptions...)
+	if err != nil {
+		return err
+	}
+
+	// The server accepted the request but rejected some of the data points,
+	// those must not be retried according to the OTLP specification
+	if ps := resp.PartialSuccess(); ps.RejectedDataPoints() > 0 || ps.ErrorMessage() != "" {
+		o.Log.Warnf("Server rejected %d data points: %s", ps.RejectedDataPoints(), ps.ErrorMessage())
+	}
+
+	return nil
+}
+
+// splitTags separates the tags selected as resource attributes from the
+// data point tags and renames them according to the configured mapping
+func (o *OpenTelemetry) splitTags(metric telegraf.Metric) (tags, resource map[string]string) {
+	if o.resourceTags == nil {
+		return metric.Tags(), nil
+	}
+
+	tags = make(map[string]string, len(metric.TagList()))
+	resource = make(map[string]string)
+	for _, tag := range metric.TagList() {
+		if !o.resourceTags.Match(tag.Key) {
+			tags[tag.Key] = tag.Value
+			continue
+		}
+		name := tag.Key
+		if n, found := o.ResourceTagNames[name]; found {
+			name = n
+		}
+		resource[name] = tag.Value
+	}
+	return tags, resource
+}
+
+func resourceKey(resource map[string]string) string {
+	keys := make([]string, 0, len(resource))
+	for k, v := range resource {
+		keys = append(keys, k+"="+v)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "\x00")
 }
 
 const (
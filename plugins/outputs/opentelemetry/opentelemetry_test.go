@@ -12,13 +12,18 @@ import (
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -81,6 +86,196 @@ func TestOpenTelemetry(t *testing.T) {
 	require.JSONEq(t, string(expectJSON), string(gotJSON))
 }
 
+func newTestPlugin(t *testing.T, m *mockOtelService) *OpenTelemetry {
+	metricsConverter, err := influx2otel.NewLineProtocolToOtelMetrics(common.NoopLogger{})
+	require.NoError(t, err)
+	return &OpenTelemetry{
+		ServiceAddress:       m.Address(),
+		Timeout:              config.Duration(time.Second),
+		Headers:              map[string]string{"test": "header1"},
+		metricsConverter:     metricsConverter,
+		grpcClientConn:       m.GrpcClient(),
+		metricsServiceClient: pmetricotlp.NewGRPCClient(m.GrpcClient()),
+		Log:                  testutil.Logger{},
+	}
+}
+
+func TestResourceTags(t *testing.T) {
+	expect := pmetric.NewMetrics()
+	for _, host := range []string{"a", "b"} {
+		rm := expect.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().PutStr("host.name", host)
+		rm.Resource().Attributes().PutStr("region", "eu")
+		ilm := rm.ScopeMetrics().AppendEmpty()
+		m := ilm.Metrics().AppendEmpty()
+		m.SetName("cpu_temp")
+		m.SetEmptyGauge()
+		dp := m.Gauge().DataPoints().AppendEmpty()
+		dp.Attributes().PutStr("foo", "bar")
+		dp.SetTimestamp(pcommon.Timestamp(1622848686000000000))
+		dp.SetDoubleValue(87.332)
+	}
+
+	m := newMockOtelService(t)
+	t.Cleanup(m.Cleanup)
+
+	plugin := newTestPlugin(t, m)
+	plugin.ResourceTags = []string{"host", "reg*"}
+	plugin.ResourceTagNames = map[string]string{"host": "host.name"}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu_temp",
+			map[string]string{"foo": "bar", "host": "a", "region": "eu"},
+			map[string]interface{}{"gauge": 87.332},
+			time.Unix(0, 1622848686000000000),
+		),
+		testutil.MustMetric(
+			"cpu_temp",
+			map[string]string{"foo": "bar", "host": "b", "region": "eu"},
+			map[string]interface{}{"gauge": 87.332},
+			time.Unix(0, 1622848686000000000),
+		),
+	}
+	require.NoError(t, plugin.Write(input))
+
+	marshaller := pmetric.JSONMarshaler{}
+	expectJSON, err := marshaller.MarshalMetrics(expect)
+	require.NoError(t, err)
+	gotJSON, err := marshaller.MarshalMetrics(m.GotMetrics())
+	require.NoError(t, err)
+	require.JSONEq(t, string(expectJSON), string(gotJSON))
+}
+
+func TestExponentialHistogram(t *testing.T) {
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("latency")
+	m.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	dp := m.Histogram().DataPoints().AppendEmpty()
+	dp.Attributes().PutStr("foo", "bar")
+	dp.SetTimestamp(pcommon.Timestamp(1622848686000000000))
+	dp.SetCount(10)
+	dp.SetSum(25)
+	dp.ExplicitBounds().FromRaw([]float64{1, 2, 4})
+	dp.BucketCounts().FromRaw([]uint64{1, 2, 3, 4})
+
+	convertToExponentialHistograms(md)
+
+	require.Equal(t, pmetric.MetricTypeExponentialHistogram, m.Type())
+	require.Equal(t, pmetric.AggregationTemporalityCumulative, m.ExponentialHistogram().AggregationTemporality())
+	require.Equal(t, 1, m.ExponentialHistogram().DataPoints().Len())
+	actual := m.ExponentialHistogram().DataPoints().At(0)
+	require.Equal(t, map[string]any{"foo": "bar"}, actual.Attributes().AsRaw())
+	require.Equal(t, pcommon.Timestamp(1622848686000000000), actual.Timestamp())
+	require.Equal(t, uint64(10), actual.Count())
+	require.InDelta(t, 25.0, actual.Sum(), 0)
+	require.Zero(t, actual.ZeroCount())
+	require.Zero(t, actual.Negative().BucketCounts().Len())
+
+	// At scale 6 the bounds 1, 2 and 4 are the upper bounds of the buckets
+	// with index -1, 63 and 127, the remaining observations are assigned to
+	// the bucket above
+	require.Equal(t, int32(6), actual.Scale())
+	require.Equal(t, int32(-1), actual.Positive().Offset())
+	expected := make([]uint64, 130)
+	expected[0] = 1
+	expected[64] = 2
+	expected[128] = 3
+	expected[129] = 4
+	require.Equal(t, expected, actual.Positive().BucketCounts().AsRaw())
+}
+
+func TestRetryDelay(t *testing.T) {
+	m := newMockOtelService(t)
+	t.Cleanup(m.Cleanup)
+
+	st, err := status.New(codes.ResourceExhausted, "slow down").WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(time.Hour),
+	})
+	require.NoError(t, err)
+	m.err = st.Err()
+
+	plugin := newTestPlugin(t, m)
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu_temp",
+			map[string]string{"foo": "bar"},
+			map[string]interface{}{"gauge": 87.332},
+			time.Unix(0, 1622848686000000000),
+		),
+	}
+
+	// The metrics must be kept and no further requests must be sent before
+	// the retry delay elapsed
+	err = plugin.Write(input)
+	require.ErrorContains(t, err, "slow down")
+	var writeErr *internal.PartialWriteError
+	require.NotErrorAs(t, err, &writeErr)
+
+	require.ErrorContains(t, plugin.Write(input), "server requested to retry after")
+	require.Equal(t, 1, m.calls)
+}
+
+func TestInvalidArgument(t *testing.T) {
+	m := newMockOtelService(t)
+	t.Cleanup(m.Cleanup)
+	m.err = status.Error(codes.InvalidArgument, "invalid data")
+
+	plugin := newTestPlugin(t, m)
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu_temp",
+			map[string]string{"foo": "bar"},
+			map[string]interface{}{"gauge": 87.332},
+			time.Unix(0, 1622848686000000000),
+		),
+		testutil.MustMetric(
+			"cpu_temp",
+			map[string]string{"foo": "bar"},
+			map[string]interface{}{"gauge": 87.332},
+			time.Unix(0, 1622848687000000000),
+		),
+	}
+
+	// Invalid metrics must be dropped
+	err := plugin.Write(input)
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Empty(t, writeErr.MetricsAccept)
+	require.Equal(t, []int{0, 1}, writeErr.MetricsReject)
+	require.Equal(t, 2, m.calls)
+}
+
+func TestPartialSuccess(t *testing.T) {
+	m := newMockOtelService(t)
+	t.Cleanup(m.Cleanup)
+	m.response = pmetricotlp.NewExportResponse()
+	m.response.PartialSuccess().SetRejectedDataPoints(1)
+	m.response.PartialSuccess().SetErrorMessage("value out of range")
+
+	plugin := newTestPlugin(t, m)
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu_temp",
+			map[string]string{"foo": "bar"},
+			map[string]interface{}{"gauge": 87.332},
+			time.Unix(0, 1622848686000000000),
+		),
+	}
+
+	// Partially rejected requests must not be retried
+	require.NoError(t, plugin.Write(input))
+	require.Equal(t, 1, m.calls)
+}
+
 var _ pmetricotlp.GRPCServer = (*mockOtelService)(nil)
 
 type mockOtelService struct {
@@ -90,7 +285,10 @@ type mockOtelService struct {
 	grpcServer *grpc.Server
 	grpcClient *grpc.ClientConn
 
-	metrics pmetric.Metrics
+	metrics  pmetric.Metrics
+	response pmetricotlp.ExportResponse
+	err      error
+	calls    int
 }
 
 func newMockOtelService(t *testing.T) *mockOtelService {
@@ -137,10 +335,17 @@ func (m *mockOtelService) Address() string {
 }
 
 func (m *mockOtelService) Export(ctx context.Context, request pmetricotlp.ExportRequest) (pmetricotlp.ExportResponse, error) {
+	m.calls++
 	m.metrics = pmetric.NewMetrics()
 	request.Metrics().CopyTo(m.metrics)
 	ctxMetadata, ok := metadata.FromIncomingContext(ctx)
 	require.Equal(m.t, []string{"header1"}, ctxMetadata.Get("test"))
 	require.True(m.t, ok)
+	if m.err != nil {
+		return pmetricotlp.NewExportResponse(), m.err
+	}
+	if m.response != (pmetricotlp.ExportResponse{}) {
+		return m.response, nil
+	}
 	return pmetricotlp.NewExportResponse(), nil
 }
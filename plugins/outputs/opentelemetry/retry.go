@@ -0,0 +1,34 @@
+package opentelemetry
+
+import (
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// handleError records the backoff delay requested by the server and reports
+// if the request can be retried. Requests rejected as invalid will never
+// succeed so only those are considered permanent failures. All other errors
+// are retried to not lose data due to e.g. misconfigured credentials.
+func (o *OpenTelemetry) handleError(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok {
+		return true
+	}
+
+	// Servers can hint the time to wait before retrying e.g. when throttling
+	// the client, see the OTLP specification for details
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			delay := info.GetRetryDelay().AsDuration()
+			if delay > 0 {
+				o.Log.Debugf("Server requested to retry after %s", delay)
+				o.retryAfter = time.Now().Add(delay)
+			}
+		}
+	}
+
+	return st.Code() != codes.InvalidArgument
+}
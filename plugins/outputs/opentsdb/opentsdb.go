@@ -3,17 +3,23 @@ package opentsdb
 
 import (
 	_ "embed"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"net"
+	"net/http"
 	"net/url"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
 
@@ -38,14 +44,19 @@ type OpenTSDB struct {
 	Host string `toml:"host"`
 	Port int    `toml:"port"`
 
-	HTTPBatchSize int    `toml:"http_batch_size"`
-	HTTPPath      string `toml:"http_path"`
+	HTTPBatchSize   int             `toml:"http_batch_size"`
+	HTTPMaxBodySize config.Size     `toml:"http_max_body_size"`
+	HTTPPath        string          `toml:"http_path"`
+	ContentEncoding string          `toml:"content_encoding"`
+	Timeout         config.Duration `toml:"timeout"`
 
 	Debug bool `toml:"debug"`
 
 	Separator string `toml:"separator"`
 
 	Log telegraf.Logger `toml:"-"`
+
+	http *openTSDBHttp
 }
 
 func ToLineFormat(tags map[string]string) string {
@@ -61,6 +72,17 @@ func (*OpenTSDB) SampleConfig() string {
 	return sampleConfig
 }
 
+func (o *OpenTSDB) Init() error {
+	if err := choice.Check(o.ContentEncoding, []string{"gzip", "identity"}); err != nil {
+		return fmt.Errorf("config option content_encoding: %w", err)
+	}
+	if o.HTTPBatchSize < 0 {
+		return fmt.Errorf("invalid http_batch_size %d", o.HTTPBatchSize)
+	}
+
+	return nil
+}
+
 func (o *OpenTSDB) Connect() error {
 	if !strings.HasPrefix(o.Host, "http") && !strings.HasPrefix(o.Host, "tcp") {
 		o.Host = "tcp://" + o.Host
@@ -71,6 +93,33 @@ func (o *OpenTSDB) Connect() error {
 		return fmt.Errorf("error in parsing host url: %w", err)
 	}
 
+	if u.Scheme == "http" || u.Scheme == "https" {
+		encoder, err := internal.NewContentEncoder(o.ContentEncoding)
+		if err != nil {
+			return fmt.Errorf("creating content encoder failed: %w", err)
+		}
+
+		// Always request the details to be able to only retry the failed
+		// data points
+		endpoint := url.URL{
+			Scheme:   u.Scheme,
+			User:     u.User,
+			Host:     fmt.Sprintf("%s:%d", u.Hostname(), o.Port),
+			Path:     o.HTTPPath,
+			RawQuery: "details",
+		}
+		o.http = &openTSDBHttp{
+			URL:             endpoint.String(),
+			BatchSize:       o.HTTPBatchSize,
+			MaxBodySize:     int(o.HTTPMaxBodySize),
+			ContentEncoding: o.ContentEncoding,
+			Debug:           o.Debug,
+			client:          &http.Client{Timeout: time.Duration(o.Timeout)},
+			encoder:         encoder,
+			log:             o.Log,
+		}
+	}
+
 	uri := fmt.Sprintf("%s:%d", u.Host, o.Port)
 	tcpAddr, err := net.ResolveTCPAddr("tcp", uri)
 	if err != nil {
@@ -102,24 +151,20 @@ func (o *OpenTSDB) Write(metrics []telegraf.Metric) error {
 	return errors.New("unknown scheme in host parameter")
 }
 
-func (o *OpenTSDB) WriteHTTP(metrics []telegraf.Metric, u *url.URL) error {
-	http := openTSDBHttp{
-		Host:      u.Host,
-		Port:      o.Port,
-		Scheme:    u.Scheme,
-		User:      u.User,
-		BatchSize: o.HTTPBatchSize,
-		Path:      o.HTTPPath,
-		Debug:     o.Debug,
-		log:       o.Log,
+func (o *OpenTSDB) WriteHTTP(metrics []telegraf.Metric, _ *url.URL) error {
+	if o.http == nil {
+		return errors.New("not connected")
 	}
 
-	for _, m := range metrics {
+	// Serialize all data points upfront to be able to split the batch by the
+	// resulting request size
+	points := make([]httpPoint, 0, len(metrics))
+	for i, m := range metrics {
 		now := m.Time().UnixNano() / 1000000000
 		tags := cleanTags(m.Tags())
 
-		for fieldName, value := range m.Fields() {
-			switch fv := value.(type) {
+		for _, field := range m.FieldList() {
+			switch fv := field.Value.(type) {
 			case int64:
 			case uint64:
 			case float64:
@@ -128,25 +173,64 @@ func (o *OpenTSDB) WriteHTTP(metrics []telegraf.Metric, u *url.URL) error {
 					continue
 				}
 			default:
-				o.Log.Debugf("OpenTSDB does not support metric value: [%s] of type [%T].", value, value)
+				o.Log.Debugf("OpenTSDB does not support metric value: [%s] of type [%T].", field.Value, field.Value)
 				continue
 			}
 
 			metric := &HTTPMetric{
 				Metric: sanitize(fmt.Sprintf("%s%s%s%s",
-					o.Prefix, m.Name(), o.Separator, fieldName)),
+					o.Prefix, m.Name(), o.Separator, field.Key)),
 				Tags:      tags,
 				Timestamp: now,
-				Value:     value,
+				Value:     field.Value,
 			}
+			data, err := json.Marshal(metric)
+			if err != nil {
+				o.Log.Errorf("Serializing metric %q failed: %v", metric.Metric, err)
+				continue
+			}
+			points = append(points, httpPoint{index: i, key: metric.key(), data: data})
+		}
+	}
 
-			if err := http.sendDataPoint(metric); err != nil {
-				return err
+	// Determine the state of each metric, metrics with a failed data point
+	// are kept for the next write to only retry the failed part of the batch
+	retry := make([]bool, len(metrics))
+	reject := make([]bool, len(metrics))
+	for _, chunk := range o.http.split(points) {
+		result := o.http.send(chunk)
+		for _, p := range chunk {
+			switch {
+			case result.retry, result.failed[p.key]:
+				retry[p.index] = true
+			case result.reject:
+				reject[p.index] = true
 			}
 		}
 	}
 
-	return http.flush()
+	var accepted, rejected []int
+	for i := range metrics {
+		switch {
+		case retry[i]:
+		case reject[i]:
+			rejected = append(rejected, i)
+		default:
+			accepted = append(accepted, i)
+		}
+	}
+	if len(accepted) == len(metrics) {
+		return nil
+	}
+	if len(accepted) == 0 && len(rejected) == 0 {
+		return errors.New("writing metrics failed")
+	}
+
+	return &internal.PartialWriteError{
+		Err:           fmt.Errorf("writing %d of %d metrics failed", len(metrics)-len(accepted), len(metrics)),
+		MetricsAccept: accepted,
+		MetricsReject: rejected,
+	}
 }
 
 func (o *OpenTSDB) WriteTelnet(metrics []telegraf.Metric, u *url.URL) error {
@@ -252,8 +336,11 @@ func sanitize(value string) string {
 func init() {
 	outputs.Add("opentsdb", func() telegraf.Output {
 		return &OpenTSDB{
-			HTTPPath:  defaultHTTPPath,
-			Separator: defaultSeparator,
+			HTTPPath:        defaultHTTPPath,
+			HTTPMaxBodySize: config.Size(512 * 1024),
+			ContentEncoding: "gzip",
+			Timeout:         config.Duration(5 * time.Second),
+			Separator:       defaultSeparator,
 		}
 	})
 }
@@ -2,15 +2,15 @@ package opentsdb
 
 import (
 	"bytes"
-	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httputil"
-	"net/url"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 )
 
 type HTTPMetric struct {
@@ -20,168 +20,176 @@ type HTTPMetric struct {
 	Tags      map[string]string `json:"tags"`
 }
 
-type openTSDBHttp struct {
-	Host      string
-	Port      int
-	Scheme    string
-	User      *url.Userinfo
-	BatchSize int
-	Path      string
-	Debug     bool
-
-	log telegraf.Logger
-
-	metricCounter int
-	body          requestBody
+// key returns an identifier of the data point used to match the data points
+// returned in the error details of the API to the originating metric
+func (m *HTTPMetric) key() string {
+	return fmt.Sprintf("%s %d %s", m.Metric, m.Timestamp, ToLineFormat(m.Tags))
 }
 
-type requestBody struct {
-	b bytes.Buffer
-	g *gzip.Writer
-
-	dbgB bytes.Buffer
-
-	w   io.Writer
-	enc *json.Encoder
-
-	empty bool
+// httpPoint is a serialized data point referencing the originating metric
+type httpPoint struct {
+	index int
+	key   string
+	data  []byte
 }
 
-func (r *requestBody) reset(debug bool) {
-	r.b.Reset()
-	r.dbgB.Reset()
-
-	if r.g == nil {
-		r.g = gzip.NewWriter(&r.b)
-	} else {
-		r.g.Reset(&r.b)
-	}
-
-	if debug {
-		r.w = io.MultiWriter(r.g, &r.dbgB)
-	} else {
-		r.w = r.g
-	}
-
-	r.enc = json.NewEncoder(r.w)
-
-	//nolint:errcheck // unable to propagate error
-	io.WriteString(r.w, "[")
-
-	r.empty = true
+// httpResponse is the summary returned by the put API if details are requested
+type httpResponse struct {
+	Success int64 `json:"success"`
+	Failed  int64 `json:"failed"`
+	Errors  []struct {
+		Datapoint HTTPMetric `json:"datapoint"`
+		Error     string     `json:"error"`
+	} `json:"errors"`
 }
 
-func (r *requestBody) addMetric(metric *HTTPMetric) error {
-	if !r.empty {
-		if _, err := io.WriteString(r.w, ","); err != nil {
-			return err
-		}
-	}
-
-	if err := r.enc.Encode(metric); err != nil {
-		return fmt.Errorf("metric serialization error %w", err)
-	}
-
-	r.empty = false
+// httpResult contains the outcome of sending a chunk of data points
+type httpResult struct {
+	// failed contains the keys of the data points not written and should be
+	// retried
+	failed map[string]bool
+	// retry denotes that none of the data points was written and all of them
+	// should be retried
+	retry bool
+	// reject denotes that none of the data points was written and they should
+	// not be retried
+	reject bool
+}
 
-	return nil
+type openTSDBHttp struct {
+	URL             string
+	BatchSize       int
+	MaxBodySize     int
+	ContentEncoding string
+	Debug           bool
+
+	client  *http.Client
+	encoder internal.ContentEncoder
+	log     telegraf.Logger
 }
 
-func (r *requestBody) close() error {
-	if _, err := io.WriteString(r.w, "]"); err != nil {
-		return err
+// split splits the given data points into chunks limited by the number of
+// data points and the serialized size of the request body
+func (o *openTSDBHttp) split(points []httpPoint) [][]httpPoint {
+	var chunks [][]httpPoint
+
+	// Account for the enclosing brackets of the JSON array
+	start, size := 0, 2
+	for i, p := range points {
+		full := o.BatchSize > 0 && i-start >= o.BatchSize
+		oversize := o.MaxBodySize > 0 && size+len(p.data) > o.MaxBodySize
+		if i > start && (full || oversize) {
+			chunks = append(chunks, points[start:i])
+			start, size = i, 2
+		}
+		// Account for the separating comma
+		size += len(p.data) + 1
 	}
-
-	if err := r.g.Close(); err != nil {
-		return fmt.Errorf("error when closing gzip writer: %w", err)
+	if start < len(points) {
+		chunks = append(chunks, points[start:])
 	}
 
-	return nil
+	return chunks
 }
 
-func (o *openTSDBHttp) sendDataPoint(metric *HTTPMetric) error {
-	if o.metricCounter == 0 {
-		o.body.reset(o.Debug)
-	}
-
-	if err := o.body.addMetric(metric); err != nil {
-		return err
-	}
-
-	o.metricCounter++
-	if o.metricCounter == o.BatchSize {
-		if err := o.flush(); err != nil {
-			return err
+func (o *openTSDBHttp) send(chunk []httpPoint) httpResult {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, p := range chunk {
+		if i > 0 {
+			buf.WriteByte(',')
 		}
-
-		o.metricCounter = 0
+		buf.Write(p.data)
 	}
+	buf.WriteByte(']')
 
-	return nil
-}
-
-func (o *openTSDBHttp) flush() error {
-	if o.metricCounter == 0 {
-		return nil
+	if o.Debug {
+		fmt.Printf("Body:\n%s\n\n", buf.String())
 	}
 
-	if err := o.body.close(); err != nil {
-		return err
+	body, err := o.encoder.Encode(buf.Bytes())
+	if err != nil {
+		o.log.Errorf("Encoding request body failed: %v", err)
+		return httpResult{retry: true}
 	}
 
-	u := url.URL{
-		Scheme: o.Scheme,
-		User:   o.User,
-		Host:   fmt.Sprintf("%s:%d", o.Host, o.Port),
-		Path:   o.Path,
+	req, err := http.NewRequest("POST", o.URL, bytes.NewReader(body))
+	if err != nil {
+		o.log.Errorf("Building request failed: %v", err)
+		return httpResult{retry: true}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.ContentEncoding == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
 	}
 
 	if o.Debug {
-		u.RawQuery = "details"
+		dump, err := httputil.DumpRequestOut(req, false)
+		if err == nil {
+			fmt.Printf("Sending metrics:\n%s", dump)
+		}
 	}
 
-	req, err := http.NewRequest("POST", u.String(), &o.body.b)
+	resp, err := o.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("error when building request: %w", err)
+		o.log.Errorf("Sending metrics failed: %v", err)
+		return httpResult{retry: true}
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Content-Encoding", "gzip")
+	defer resp.Body.Close()
 
 	if o.Debug {
-		dump, err := httputil.DumpRequestOut(req, false)
-		if err != nil {
-			return fmt.Errorf("error when dumping request: %w", err)
+		dump, err := httputil.DumpResponse(resp, false)
+		if err == nil {
+			fmt.Printf("Received response\n%s\n\n", dump)
 		}
-
-		fmt.Printf("Sending metrics:\n%s", dump)
-		fmt.Printf("Body:\n%s\n\n", o.body.dbgB.String())
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	// Read the full body so the client can reuse the connection
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("error when sending metrics: %w", err)
+		o.log.Errorf("Reading response failed: %v", err)
+		return httpResult{retry: true}
 	}
-	defer resp.Body.Close()
-
 	if o.Debug {
-		dump, err := httputil.DumpResponse(resp, true)
-		if err != nil {
-			return fmt.Errorf("error when dumping response: %w", err)
+		fmt.Printf("%s\n\n", respBody)
+	}
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return httpResult{}
+	case resp.StatusCode == http.StatusBadRequest:
+		// OpenTSDB reports the data points that could not be stored in the
+		// details of the response, all other data points were written
+		failed, err := o.parseDetails(respBody)
+		if err == nil {
+			return httpResult{failed: failed}
 		}
+		o.log.Errorf("Received status %d, dropping metrics: %v", resp.StatusCode, err)
+		return httpResult{reject: true}
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		o.log.Errorf("Received status %d, dropping metrics to avoid overflowing buffer", resp.StatusCode)
+		return httpResult{reject: true}
+	}
+
+	o.log.Errorf("Sending metrics failed with status %d", resp.StatusCode)
+	return httpResult{retry: true}
+}
 
-		fmt.Printf("Received response\n%s\n\n", dump)
-	} else {
-		// Important so http client reuse connection for next request if need be.
-		//nolint:errcheck // cannot fail with io.Discard
-		io.Copy(io.Discard, resp.Body)
+func (o *openTSDBHttp) parseDetails(buf []byte) (map[string]bool, error) {
+	var details httpResponse
+	if err := json.Unmarshal(buf, &details); err != nil {
+		return nil, fmt.Errorf("parsing details failed: %w", err)
+	}
+	if details.Failed == 0 || len(details.Errors) == 0 {
+		return nil, errors.New("no details on failed data points")
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		if resp.StatusCode < 400 || resp.StatusCode > 499 {
-			return fmt.Errorf("error sending metrics (status %d)", resp.StatusCode)
-		}
-		o.log.Errorf("Received %d status code. Dropping metrics to avoid overflowing buffer.", resp.StatusCode)
+	failed := make(map[string]bool, len(details.Errors))
+	for _, e := range details.Errors {
+		o.log.Debugf("Writing data point %q failed: %s", e.Datapoint.Metric, e.Error)
+		failed[e.Datapoint.key()] = true
 	}
+	o.log.Errorf("Writing %d data points failed, %d succeeded", details.Failed, details.Success)
 
-	return nil
+	return failed, nil
 }
@@ -1,18 +1,28 @@
 package opentsdb
 
 import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
 	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -125,6 +135,232 @@ func TestSanitize(t *testing.T) {
 	}
 }
 
+func TestInitFail(t *testing.T) {
+	plugin := outputs.Outputs["opentsdb"]().(*OpenTSDB)
+	plugin.ContentEncoding = "zstd"
+	require.ErrorContains(t, plugin.Init(), "config option content_encoding")
+
+	plugin = outputs.Outputs["opentsdb"]().(*OpenTSDB)
+	plugin.HTTPBatchSize = -1
+	require.ErrorContains(t, plugin.Init(), "invalid http_batch_size -1")
+}
+
+// server simulates the OpenTSDB put API recording the received data points
+// and failing all data points with the given metric names
+type server struct {
+	failing map[string]bool
+	status  int
+
+	requests [][]HTTPMetric
+	sync.Mutex
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api/put" || r.URL.RawQuery != "details" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body = gz
+	}
+	var points []HTTPMetric
+	if err := json.NewDecoder(body).Decode(&points); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.Lock()
+	s.requests = append(s.requests, points)
+	s.Unlock()
+
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+
+	var response httpResponse
+	for _, p := range points {
+		if !s.failing[p.Metric] {
+			response.Success++
+			continue
+		}
+		response.Failed++
+		response.Errors = append(response.Errors, struct {
+			Datapoint HTTPMetric `json:"datapoint"`
+			Error     string     `json:"error"`
+		}{Datapoint: p, Error: "Unknown metric"})
+	}
+
+	if response.Failed > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func newTestPlugin(t *testing.T, u string) *OpenTSDB {
+	addr, err := url.Parse(u)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(addr.Port())
+	require.NoError(t, err)
+
+	plugin := outputs.Outputs["opentsdb"]().(*OpenTSDB)
+	plugin.Host = "http://" + addr.Hostname()
+	plugin.Port = port
+	plugin.Log = &testutil.Logger{}
+	return plugin
+}
+
+func TestWriteHTTP(t *testing.T) {
+	tests := []struct {
+		name             string
+		encoding         string
+		batchSize        int
+		maxBodySize      config.Size
+		expectedRequests int
+	}{
+		{
+			name:             "single request",
+			encoding:         "gzip",
+			expectedRequests: 1,
+		},
+		{
+			name:             "identity encoding",
+			encoding:         "identity",
+			expectedRequests: 1,
+		},
+		{
+			name:             "split by batch size",
+			encoding:         "gzip",
+			batchSize:        2,
+			expectedRequests: 3,
+		},
+		{
+			name:             "split by body size",
+			encoding:         "gzip",
+			maxBodySize:      80,
+			expectedRequests: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &server{}
+			ts := httptest.NewServer(srv)
+			defer ts.Close()
+
+			plugin := newTestPlugin(t, ts.URL)
+			plugin.ContentEncoding = tt.encoding
+			plugin.HTTPBatchSize = tt.batchSize
+			plugin.HTTPMaxBodySize = tt.maxBodySize
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Connect())
+			defer plugin.Close()
+
+			input := []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"idle": 42.0, "user": int64(3)}, time.Unix(10, 0)),
+				metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"idle": 40.0, "user": uint64(5)}, time.Unix(10, 0)),
+				metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"free": int64(1024), "status": "ok"}, time.Unix(10, 0)),
+			}
+			require.NoError(t, plugin.Write(input))
+
+			require.Len(t, srv.requests, tt.expectedRequests)
+			var names []string
+			for _, req := range srv.requests {
+				for _, p := range req {
+					names = append(names, p.Metric)
+				}
+			}
+			require.ElementsMatch(t, []string{"cpu_idle", "cpu_user", "cpu_idle", "cpu_user", "mem_free"}, names)
+		})
+	}
+}
+
+func TestWriteHTTPPartialFailure(t *testing.T) {
+	srv := &server{failing: map[string]bool{"mem_free": true}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	plugin := newTestPlugin(t, ts.URL)
+	plugin.HTTPBatchSize = 2
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"idle": 42.0}, time.Unix(10, 0)),
+		metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"free": int64(1024)}, time.Unix(10, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"idle": 40.0}, time.Unix(10, 0)),
+	}
+	err := plugin.Write(input)
+
+	// Only the metric with the failed data point must be retried
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Equal(t, []int{0, 2}, writeErr.MetricsAccept)
+	require.Empty(t, writeErr.MetricsReject)
+}
+
+func TestWriteHTTPStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		rejected []int
+	}{
+		{
+			name:   "server error",
+			status: http.StatusServiceUnavailable,
+		},
+		{
+			name:     "client error",
+			status:   http.StatusRequestEntityTooLarge,
+			rejected: []int{0, 1},
+		},
+		{
+			name:     "bad request without details",
+			status:   http.StatusBadRequest,
+			rejected: []int{0, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &server{status: tt.status}
+			ts := httptest.NewServer(srv)
+			defer ts.Close()
+
+			plugin := newTestPlugin(t, ts.URL)
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Connect())
+			defer plugin.Close()
+
+			input := []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"idle": 42.0}, time.Unix(10, 0)),
+				metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"idle": 40.0}, time.Unix(10, 0)),
+			}
+			err := plugin.Write(input)
+			require.Error(t, err)
+
+			var writeErr *internal.PartialWriteError
+			if tt.rejected == nil {
+				require.False(t, errors.As(err, &writeErr))
+				return
+			}
+			require.ErrorAs(t, err, &writeErr)
+			require.Empty(t, writeErr.MetricsAccept)
+			require.Equal(t, tt.rejected, writeErr.MetricsReject)
+		})
+	}
+}
+
 func BenchmarkHttpSend(b *testing.B) {
 	const batchSize = 50
 	const metricsCount = 4 * batchSize
@@ -158,7 +394,9 @@ func BenchmarkHttpSend(b *testing.B) {
 		Prefix:        "",
 		HTTPBatchSize: batchSize,
 		HTTPPath:      "/api/put",
+		Log:           &testutil.Logger{},
 	}
+	require.NoError(b, o.Connect())
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -6,6 +6,7 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -41,6 +42,7 @@ type Postgresql struct {
 	TagTableSuffix             string                  `toml:"tag_table_suffix"`
 	ForeignTagConstraint       bool                    `toml:"foreign_tag_constraint"`
 	TagsAsJsonb                bool                    `toml:"tags_as_jsonb"`
+	TagsAsJsonbIndex           bool                    `toml:"tags_as_jsonb_index"`
 	FieldsAsJsonb              bool                    `toml:"fields_as_jsonb"`
 	TimestampColumnName        string                  `toml:"timestamp_column_name"`
 	TimestampColumnType        string                  `toml:"timestamp_column_type"`
@@ -48,6 +50,8 @@ type Postgresql struct {
 	AddColumnTemplates         []*sqltemplate.Template `toml:"add_column_templates"`
 	TagTableCreateTemplates    []*sqltemplate.Template `toml:"tag_table_create_templates"`
 	TagTableAddColumnTemplates []*sqltemplate.Template `toml:"tag_table_add_column_templates"`
+	SchemaUpdatePolicy         string                  `toml:"schema_update_policy"`
+	CopyThreshold              int                     `toml:"copy_threshold"`
 	Uint64Type                 string                  `toml:"uint64_type"`
 	RetryMaxBackoff            config.Duration         `toml:"retry_max_backoff"`
 	TagCacheSize               int                     `toml:"tag_cache_size"`
@@ -82,6 +86,20 @@ func (p *Postgresql) Init() error {
 	if p.TagCacheSize < 0 {
 		return errors.New("invalid tag_cache_size")
 	}
+	if p.CopyThreshold < 0 {
+		return errors.New("invalid copy_threshold")
+	}
+
+	switch p.SchemaUpdatePolicy {
+	case "":
+		p.SchemaUpdatePolicy = "add_column"
+	case "add_column", "new_table_version", "error":
+	default:
+		return fmt.Errorf("unknown schema update policy %q", p.SchemaUpdatePolicy)
+	}
+	if p.TagsAsJsonbIndex && !p.TagsAsJsonb {
+		p.Logger.Warn("Ignoring 'tags_as_jsonb_index' as 'tags_as_jsonb' is disabled")
+	}
 
 	// Set the time-column name
 	if p.TimestampColumnName == "" {
@@ -429,7 +447,10 @@ func (p *Postgresql) writeMetricsFromMeasure(ctx context.Context, db dbh, tableS
 		}
 	}
 
-	fullTableName := utils.FullTableName(p.Schema, tableSource.Name())
+	fullTableName := utils.FullTableName(p.Schema, tableSource.TableName())
+	if len(tableSource.metrics) < p.CopyThreshold {
+		return insertRows(ctx, db, fullTableName, tableSource)
+	}
 	if _, err := db.CopyFrom(ctx, fullTableName, tableSource.ColumnNames(), tableSource); err != nil {
 		return err
 	}
@@ -437,6 +458,66 @@ func (p *Postgresql) writeMetricsFromMeasure(ctx context.Context, db dbh, tableS
 	return nil
 }
 
+// PostgreSQL supports at most 65535 parameters per statement.
+const maxInsertParameters = 65535
+
+// insertRows writes the rows of the table source using multi-row INSERT statements instead of COPY.
+func insertRows(ctx context.Context, db dbh, table pgx.Identifier, tableSource *TableSource) error {
+	columns := tableSource.ColumnNames()
+	quoted := make([]string, 0, len(columns))
+	for _, name := range columns {
+		quoted = append(quoted, utils.QuoteIdentifier(name))
+	}
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", table.Sanitize(), strings.Join(quoted, ", "))
+	maxRows := max(maxInsertParameters/len(columns), 1)
+
+	var sql strings.Builder
+	var args []interface{}
+	var rows int
+	flush := func() error {
+		if rows == 0 {
+			return nil
+		}
+		if _, err := db.Exec(ctx, sql.String(), args...); err != nil {
+			return err
+		}
+		sql.Reset()
+		args = args[:0]
+		rows = 0
+		return nil
+	}
+
+	for tableSource.Next() {
+		values, err := tableSource.Values()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			sql.WriteString(prefix)
+		} else {
+			sql.WriteString(", ")
+		}
+		sql.WriteString("(")
+		for i := range values {
+			if i > 0 {
+				sql.WriteString(", ")
+			}
+			sql.WriteString("$" + strconv.Itoa(len(args)+i+1))
+		}
+		sql.WriteString(")")
+		args = append(args, values...)
+		rows++
+
+		if rows >= maxRows {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
 func writeTagTable(ctx context.Context, db dbh, tableSource *TableSource) error {
 	ttsrc := NewTagTableSource(tableSource)
 
@@ -483,6 +564,7 @@ func newPostgresql() *Postgresql {
 		TagTableSuffix:             "_tag",
 		TagCacheSize:               100000,
 		Uint64Type:                 PgNumeric,
+		SchemaUpdatePolicy:         "add_column",
 		CreateTemplates:            []*sqltemplate.Template{{}},
 		AddColumnTemplates:         []*sqltemplate.Template{{}},
 		TagTableCreateTemplates:    []*sqltemplate.Template{{}},
@@ -1212,3 +1212,47 @@ func TestLongColumnNamesClipIntegration(t *testing.T) {
 		require.EqualValues(t, expected[i], actual)
 	}
 }
+
+func TestInitInvalidSchemaUpdatePolicy(t *testing.T) {
+	p := newPostgresql()
+	p.SchemaUpdatePolicy = "drop_table"
+	require.ErrorContains(t, p.Init(), `unknown schema update policy "drop_table"`)
+}
+
+func TestWriteIntegration_copyThreshold(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	p, err := newPostgresqlTest(t)
+	require.NoError(t, err)
+	p.CopyThreshold = 3
+	require.NoError(t, p.Connect())
+
+	metrics := []telegraf.Metric{
+		newMetric(t, "_a", MSS{"tag": "foo"}, MSI{"v": 1}),
+		newMetric(t, "_b", MSS{"tag": "bar"}, MSI{"v": 2}),
+		newMetric(t, "_a", MSS{"tag": "foo"}, MSI{"v": 3}),
+		newMetric(t, "_b", MSS{"tag": "bar"}, MSI{"v": 4}),
+		newMetric(t, "_b", MSS{"tag": "bar"}, MSI{"v": 5}),
+	}
+	require.NoError(t, p.Write(metrics))
+
+	dumpA := dbTableDump(t, p.db, "_a")
+	require.Len(t, dumpA, 2)
+	require.EqualValues(t, 1, dumpA[0]["v"])
+	require.EqualValues(t, 3, dumpA[1]["v"])
+	require.Len(t, dbTableDump(t, p.db, "_b"), 3)
+
+	var inserts, copies int
+	for _, log := range p.Logger.Logs() {
+		if strings.Contains(log.String(), "INSERT INTO") && strings.Contains(log.String(), t.Name()+"_a") {
+			inserts++
+		}
+		if strings.Contains(log.String(), "CopyFrom") && strings.Contains(log.String(), t.Name()+"_b") {
+			copies++
+		}
+	}
+	require.Equal(t, 1, inserts)
+	require.Equal(t, 1, copies)
+}
@@ -3,6 +3,7 @@ package postgresql
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -28,6 +29,9 @@ type TableManager struct {
 	tables      map[string]*tableState
 	tablesMutex sync.Mutex
 
+	// map[tableName]version of the latest table version when using versioned tables
+	versions map[string]int
+
 	// Map to track which columns are already logged
 	loggedLongColumnWarn map[string]bool
 	loggedLongColumnErr  map[string]bool
@@ -39,6 +43,7 @@ func NewTableManager(postgresql *Postgresql) *TableManager {
 	return &TableManager{
 		Postgresql:           postgresql,
 		tables:               make(map[string]*tableState),
+		versions:             make(map[string]int),
 		loggedLongColumnWarn: make(map[string]bool),
 		loggedLongColumnErr:  make(map[string]bool),
 	}
@@ -52,6 +57,7 @@ func (tm *TableManager) ClearTableCache() {
 		tbl.columns = nil
 		tbl.Unlock()
 	}
+	tm.versions = make(map[string]int)
 	tm.tablesMutex.Unlock()
 
 	if tm.tagsCache != nil {
@@ -75,19 +81,37 @@ func (tm *TableManager) table(name string) *tableState {
 // If the schema does not match, and schema updates are disabled:
 // If a field missing from the DB, the field is omitted.
 // If a tag is missing from the DB, the metric is dropped.
+//
+// Missing columns of existing tables are handled according to the schema update policy. With "add_column" the columns
+// are added using the add-column templates, with "new_table_version" a new version of the metric table containing all
+// columns is created and with "error" the metrics are rejected.
 func (tm *TableManager) MatchSource(ctx context.Context, db dbh, rowSource *TableSource) error {
-	metricTable := tm.table(rowSource.Name())
+	tableName := rowSource.Name()
+	if tm.SchemaUpdatePolicy == "new_table_version" {
+		version, err := tm.tableVersion(ctx, db, tableName)
+		if err != nil {
+			return fmt.Errorf("determining version of table %q: %w", tableName, err)
+		}
+		tableName = versionedTableName(tableName, version)
+	}
+
+	metricTable := tm.table(tableName)
 	var tagTable *tableState
 	if tm.TagsAsForeignKeys {
-		tagTable = tm.table(metricTable.name + tm.TagTableSuffix)
+		// Tag tables are not versioned but shared across all versions of the metric table
+		tagTable = tm.table(rowSource.Name() + tm.TagTableSuffix)
 
+		addColumnTemplates := tm.TagTableAddColumnTemplates
+		if tm.SchemaUpdatePolicy == "error" {
+			addColumnTemplates = nil
+		}
 		missingCols, err := tm.EnsureStructure(
 			ctx,
 			db,
 			tagTable,
 			rowSource.TagTableColumns(),
 			tm.TagTableCreateTemplates,
-			tm.TagTableAddColumnTemplates,
+			addColumnTemplates,
 			metricTable,
 			tagTable,
 		)
@@ -100,11 +124,16 @@ func (tm *TableManager) MatchSource(ctx context.Context, db dbh, rowSource *Tabl
 
 		if len(missingCols) > 0 {
 			colDefs := make([]string, 0, len(missingCols))
+			for _, col := range missingCols {
+				colDefs = append(colDefs, col.Name+" "+col.Type)
+			}
+			if tm.SchemaUpdatePolicy == "error" {
+				return fmt.Errorf("table %q is missing tag columns: %s", tagTable.name, strings.Join(colDefs, ", "))
+			}
 			for _, col := range missingCols {
 				if err := rowSource.DropColumn(col); err != nil {
 					return fmt.Errorf("metric/table mismatch: Unable to omit field/column from %q: %w", tagTable.name, err)
 				}
-				colDefs = append(colDefs, col.Name+" "+col.Type)
 			}
 			tm.Logger.Errorf("Table %q is missing tag columns (dropping metrics): %s",
 				tagTable.name,
@@ -112,30 +141,43 @@ func (tm *TableManager) MatchSource(ctx context.Context, db dbh, rowSource *Tabl
 		}
 	}
 
+	addColumnTemplates := tm.AddColumnTemplates
+	if tm.SchemaUpdatePolicy != "add_column" {
+		addColumnTemplates = nil
+	}
 	missingCols, err := tm.EnsureStructure(
 		ctx,
 		db,
 		metricTable,
 		rowSource.MetricTableColumns(),
 		tm.CreateTemplates,
-		tm.AddColumnTemplates,
+		addColumnTemplates,
 		metricTable,
 		tagTable,
 	)
+	if err == nil && tm.SchemaUpdatePolicy == "new_table_version" && hasValidColumns(missingCols) {
+		metricTable, missingCols, err = tm.newTableVersion(ctx, db, rowSource, metricTable, tagTable)
+	}
 	if err != nil {
 		if isTempError(err) {
 			return err
 		}
 		tm.Postgresql.Logger.Errorf("Permanent error updating schema for %s: %v", metricTable.name, err)
 	}
+	rowSource.tableName = metricTable.name
 
 	if len(missingCols) > 0 {
 		colDefs := make([]string, 0, len(missingCols))
+		for _, col := range missingCols {
+			colDefs = append(colDefs, col.Name+" "+col.Type)
+		}
+		if tm.SchemaUpdatePolicy == "error" {
+			return fmt.Errorf("table %q is missing columns: %s", metricTable.name, strings.Join(colDefs, ", "))
+		}
 		for _, col := range missingCols {
 			if err := rowSource.DropColumn(col); err != nil {
 				return fmt.Errorf("metric/table mismatch: Unable to omit field/column from %q: %w", metricTable.name, err)
 			}
-			colDefs = append(colDefs, col.Name+" "+col.Type)
 		}
 		tm.Logger.Errorf("Table %q is missing columns (omitting fields): %s",
 			metricTable.name,
@@ -145,6 +187,114 @@ func (tm *TableManager) MatchSource(ctx context.Context, db dbh, rowSource *Tabl
 	return nil
 }
 
+// tableVersion returns the latest version of the table with the given name. The existing versions are looked up in
+// the database on first use.
+func (tm *TableManager) tableVersion(ctx context.Context, db dbh, name string) (int, error) {
+	tm.tablesMutex.Lock()
+	version, ok := tm.versions[name]
+	tm.tablesMutex.Unlock()
+	if ok {
+		return version, nil
+	}
+
+	pattern := likeEscaper.Replace(name) + `\_v%`
+	rows, err := db.Query(ctx, `
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = $1 AND table_name LIKE $2`, tm.Schema, pattern)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	version = 1
+	for rows.Next() {
+		var tableName string
+		if err := rows.Scan(&tableName); err != nil {
+			return 0, err
+		}
+		v, err := strconv.Atoi(strings.TrimPrefix(tableName, name+"_v"))
+		if err == nil && v > version {
+			version = v
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	tm.tablesMutex.Lock()
+	if version > tm.versions[name] {
+		tm.versions[name] = version
+	}
+	version = tm.versions[name]
+	tm.tablesMutex.Unlock()
+
+	return version, nil
+}
+
+// newTableVersion creates the next version of the metric table containing the columns of the current version as well
+// as the columns of the row source.
+func (tm *TableManager) newTableVersion(
+	ctx context.Context,
+	db dbh,
+	rowSource *TableSource,
+	current, tagTable *tableState,
+) (*tableState, []utils.Column, error) {
+	current.RLock()
+	columns := colMapToSlice(current.columns)
+	current.RUnlock()
+
+	existing := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		existing[col.Name] = true
+	}
+	for _, col := range rowSource.MetricTableColumns() {
+		if !existing[col.Name] {
+			columns = append(columns, col)
+		}
+	}
+
+	name := rowSource.Name()
+	tm.tablesMutex.Lock()
+	version := tm.versions[name] + 1
+	tm.tablesMutex.Unlock()
+
+	next := tm.table(versionedTableName(name, version))
+	missingCols, err := tm.EnsureStructure(ctx, db, next, columns, tm.CreateTemplates, nil, next, tagTable)
+	if err != nil {
+		return next, missingCols, err
+	}
+
+	tm.tablesMutex.Lock()
+	if version > tm.versions[name] {
+		tm.versions[name] = version
+	}
+	tm.tablesMutex.Unlock()
+	tm.Logger.Infof("Created table %q to add new columns of %q", next.name, current.name)
+
+	return next, missingCols, nil
+}
+
+// versionedTableName returns the name of the given version of a table. The first version uses the plain name.
+func versionedTableName(name string, version int) string {
+	if version <= 1 {
+		return name
+	}
+	return name + "_v" + strconv.Itoa(version)
+}
+
+// hasValidColumns reports whether any of the columns could be added to a table.
+func hasValidColumns(columns []utils.Column) bool {
+	for _, col := range columns {
+		if validateColumnName(col.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // EnsureStructure ensures that the table identified by tableName contains the provided columns.
 //
 // createTemplates and addColumnTemplates are the templates which are executed in the event of table create or alter
@@ -389,6 +539,19 @@ func (tm *TableManager) update(ctx context.Context,
 		}
 	}
 
+	// Index the JSONB tags column of new tables to speed up filtering by tags
+	if len(state.columns) == 0 && tm.TagsAsJsonbIndex {
+		for _, col := range missingCols {
+			if col.Role != utils.TagColType || col.Name != tm.tagsJSONColumn.Name || col.Type != PgJSONb {
+				continue
+			}
+			stmt := fmt.Sprintf("CREATE INDEX ON %s USING GIN (%s)", tmplTable.String(), sqltemplate.QuoteIdentifier(col.Name))
+			if _, err := tx.Exec(ctx, stmt); err != nil {
+				return fmt.Errorf("creating index on tags column: %w", err)
+			}
+		}
+	}
+
 	// We need to be able to determine the role of the column when reading the structure back (because of the templates).
 	// For some columns we can determine this by the column name (time, tag_id, etc). However tags and fields can have any
 	// name, and look the same. So we add a comment to tag columns, and through process of elimination what remains are
@@ -513,3 +513,95 @@ func TestTableManager_TimeWithTimezone(t *testing.T) {
 
 	require.Equal(t, 1, stmtCount)
 }
+
+func TestTableManagerIntegration_schemaUpdatePolicyNewTableVersion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	p, err := newPostgresqlTest(t)
+	require.NoError(t, err)
+	p.SchemaUpdatePolicy = "new_table_version"
+	require.NoError(t, p.Connect())
+
+	metrics := []telegraf.Metric{
+		newMetric(t, "", MSS{"tag": "foo"}, MSI{"a": 1}),
+	}
+	tsrc := NewTableSources(p.Postgresql, metrics)[t.Name()]
+	require.NoError(t, p.tableManager.MatchSource(ctx, p.db, tsrc))
+	require.Equal(t, t.Name(), tsrc.TableName())
+
+	metrics = []telegraf.Metric{
+		newMetric(t, "", MSS{"tag": "foo"}, MSI{"b": 2}),
+	}
+	tsrc = NewTableSources(p.Postgresql, metrics)[t.Name()]
+	require.NoError(t, p.tableManager.MatchSource(ctx, p.db, tsrc))
+	require.Equal(t, t.Name()+"_v2", tsrc.TableName())
+	require.NotContains(t, p.tableManager.table(t.Name()).columns, "b")
+	require.Contains(t, p.tableManager.table(t.Name()+"_v2").columns, "a")
+	require.Contains(t, p.tableManager.table(t.Name()+"_v2").columns, "b")
+
+	// A new instance must pick up the latest version
+	p.tableManager = NewTableManager(p.Postgresql)
+	metrics = []telegraf.Metric{
+		newMetric(t, "", MSS{"tag": "foo"}, MSI{"a": 3}),
+	}
+	tsrc = NewTableSources(p.Postgresql, metrics)[t.Name()]
+	require.NoError(t, p.tableManager.MatchSource(ctx, p.db, tsrc))
+	require.Equal(t, t.Name()+"_v2", tsrc.TableName())
+}
+
+func TestTableManagerIntegration_schemaUpdatePolicyError(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	p, err := newPostgresqlTest(t)
+	require.NoError(t, err)
+	p.SchemaUpdatePolicy = "error"
+	require.NoError(t, p.Connect())
+
+	metrics := []telegraf.Metric{
+		newMetric(t, "", MSS{"tag": "foo"}, MSI{"a": 1}),
+	}
+	tsrc := NewTableSources(p.Postgresql, metrics)[t.Name()]
+	require.NoError(t, p.tableManager.MatchSource(ctx, p.db, tsrc))
+
+	metrics = []telegraf.Metric{
+		newMetric(t, "", MSS{"tag": "foo"}, MSI{"a": 1, "b": 2}),
+	}
+	tsrc = NewTableSources(p.Postgresql, metrics)[t.Name()]
+	require.ErrorContains(t, p.tableManager.MatchSource(ctx, p.db, tsrc), "is missing columns: b bigint")
+	require.NotContains(t, p.tableManager.table(t.Name()).columns, "b")
+}
+
+func TestTableManagerIntegration_tagsAsJsonbIndex(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	p, err := newPostgresqlTest(t)
+	require.NoError(t, err)
+	p.TagsAsJsonb = true
+	p.TagsAsJsonbIndex = true
+	require.NoError(t, p.Connect())
+
+	metrics := []telegraf.Metric{
+		newMetric(t, "", MSS{"tag": "foo"}, MSI{"a": 1}),
+	}
+	tsrc := NewTableSources(p.Postgresql, metrics)[t.Name()]
+	require.NoError(t, p.tableManager.MatchSource(ctx, p.db, tsrc))
+
+	var indexDef string
+	row := p.db.QueryRow(ctx, "SELECT indexdef FROM pg_indexes WHERE schemaname = $1 AND tablename = $2", p.Schema, t.Name())
+	require.NoError(t, row.Scan(&indexDef))
+	require.Contains(t, indexDef, "USING gin (tags)")
+}
+
+func TestVersionedTableName(t *testing.T) {
+	require.Equal(t, "cpu", versionedTableName("cpu", 0))
+	require.Equal(t, "cpu", versionedTableName("cpu", 1))
+	require.Equal(t, "cpu_v2", versionedTableName("cpu", 2))
+	require.Equal(t, `cpu\_v%`, likeEscaper.Replace("cpu")+`\_v%`)
+	require.Equal(t, `my\_cpu\%\\`, likeEscaper.Replace(`my_cpu%\`))
+}
@@ -62,6 +62,10 @@ type TableSource struct {
 	fieldColumns *columnList
 
 	droppedTagColumns []string
+
+	// tableName is the name of the table to write to if it differs from the
+	// measurement name, e.g. for versioned tables
+	tableName string
 }
 
 func NewTableSources(p *Postgresql, metrics []telegraf.Metric) map[string]*TableSource {
@@ -128,6 +132,14 @@ func (tsrc *TableSource) Name() string {
 	return tsrc.metrics[0].Name()
 }
 
+// TableName returns the name of the table the metrics are written to.
+func (tsrc *TableSource) TableName() string {
+	if tsrc.tableName != "" {
+		return tsrc.tableName
+	}
+	return tsrc.Name()
+}
+
 // TagColumns returns the superset of all tags of all metrics.
 func (tsrc *TableSource) TagColumns() []utils.Column {
 	var cols []utils.Column
@@ -7,12 +7,14 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -26,18 +28,62 @@ type RedisTimeSeries struct {
 	Username            config.Secret   `toml:"username"`
 	Password            config.Secret   `toml:"password"`
 	Database            int             `toml:"database"`
+	ClusterMode         bool            `toml:"cluster_mode"`
 	ConvertStringFields bool            `toml:"convert_string_fields"`
+	Retention           config.Duration `toml:"retention"`
+	DuplicatePolicy     string          `toml:"duplicate_policy"`
+	LabelTags           []string        `toml:"label_tags"`
+	BatchSize           int             `toml:"batch_size"`
 	Timeout             config.Duration `toml:"timeout"`
 	Log                 telegraf.Logger `toml:"-"`
 	tls.ClientConfig
-	client *redis.Client
+
+	client      redis.UniversalClient
+	labelFilter filter.Filter
+	created     map[string]bool
 }
 
-func (r *RedisTimeSeries) Connect() error {
+// sample is a single value of a series
+type sample struct {
+	key       string
+	timestamp int64
+	value     float64
+}
+
+func (*RedisTimeSeries) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *RedisTimeSeries) Init() error {
 	if r.Address == "" {
 		return errors.New("redis address must be specified")
 	}
+	if r.ClusterMode && r.Database != 0 {
+		return errors.New("database selection is not supported in cluster mode")
+	}
+	if r.BatchSize < 1 {
+		return fmt.Errorf("invalid batch size %d", r.BatchSize)
+	}
+
+	r.DuplicatePolicy = strings.ToUpper(r.DuplicatePolicy)
+	switch r.DuplicatePolicy {
+	case "", "BLOCK", "FIRST", "LAST", "MIN", "MAX", "SUM":
+	default:
+		return fmt.Errorf("invalid duplicate policy %q", r.DuplicatePolicy)
+	}
+
+	if len(r.LabelTags) > 0 {
+		f, err := filter.Compile(r.LabelTags)
+		if err != nil {
+			return fmt.Errorf("creating label filter failed: %w", err)
+		}
+		r.labelFilter = f
+	}
 
+	return nil
+}
+
+func (r *RedisTimeSeries) Connect() error {
 	username, err := r.Username.Get()
 	if err != nil {
 		return fmt.Errorf("getting username failed: %w", err)
@@ -50,12 +96,21 @@ func (r *RedisTimeSeries) Connect() error {
 	}
 	defer password.Destroy()
 
-	r.client = redis.NewClient(&redis.Options{
-		Addr:     r.Address,
-		Username: username.String(),
-		Password: password.String(),
-		DB:       r.Database,
+	tlsConfig, err := r.ClientConfig.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("creating TLS config failed: %w", err)
+	}
+
+	r.client = redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:         []string{r.Address},
+		Username:      username.String(),
+		Password:      password.String(),
+		DB:            r.Database,
+		TLSConfig:     tlsConfig,
+		IsClusterMode: r.ClusterMode,
 	})
+	r.created = make(map[string]bool)
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.Timeout))
 	defer cancel()
 	return r.client.Ping(ctx).Err()
@@ -65,58 +120,145 @@ func (r *RedisTimeSeries) Close() error {
 	return r.client.Close()
 }
 
-func (*RedisTimeSeries) Description() string {
-	return "Plugin for sending metrics to RedisTimeSeries"
-}
-
-func (*RedisTimeSeries) SampleConfig() string {
-	return sampleConfig
-}
 func (r *RedisTimeSeries) Write(metrics []telegraf.Metric) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.Timeout))
 	defer cancel()
 
+	// Collect the samples and the labels of series not created yet
+	samples := make([]sample, 0, len(metrics))
+	labels := make(map[string]map[string]string)
 	for _, m := range metrics {
-		for name, fv := range m.Fields() {
-			key := m.Name() + "_" + name
-
-			var value float64
-			switch v := fv.(type) {
-			case float64:
-				value = v
-			case string:
-				if !r.ConvertStringFields {
-					r.Log.Debugf("Dropping string field %q of metric %q", name, m.Name())
-					continue
-				}
-				var err error
-				value, err = strconv.ParseFloat(v, 64)
-				if err != nil {
-					r.Log.Debugf("Converting string field %q of metric %q failed: %v", name, m.Name(), err)
-					continue
-				}
-			default:
-				var err error
-				value, err = internal.ToFloat64(v)
-				if err != nil {
-					r.Log.Errorf("Converting field %q (%T) of metric %q failed: %v", name, v, m.Name(), err)
-					continue
-				}
+		for _, field := range m.FieldList() {
+			key := m.Name() + "_" + field.Key
+
+			value, ok := r.convert(m.Name(), field)
+			if !ok {
+				continue
 			}
+			samples = append(samples, sample{key: key, timestamp: m.Time().UnixMilli(), value: value})
 
-			resp := r.client.TSAddWithArgs(ctx, key, m.Time().UnixMilli(), value, &redis.TSOptions{Labels: m.Tags()})
-			if err := resp.Err(); err != nil {
-				return fmt.Errorf("adding sample %q failed: %w", key, err)
+			if _, found := labels[key]; !found && !r.created[key] {
+				labels[key] = r.labels(m)
 			}
 		}
 	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	// Create the missing series and add the samples in one round-trip. The
+	// creation fails for series already existing, e.g. written by another
+	// instance, so ignore those errors.
+	pipe := r.client.Pipeline()
+	creates := make(map[string]*redis.StatusCmd, len(labels))
+	for key, l := range labels {
+		creates[key] = pipe.TSCreateWithArgs(ctx, key, &redis.TSOptions{
+			Retention:       int(time.Duration(r.Retention).Milliseconds()),
+			DuplicatePolicy: r.DuplicatePolicy,
+			Labels:          l,
+		})
+	}
+	adds := make([]*redis.IntSliceCmd, 0, len(samples)/r.BatchSize+1)
+	for _, batch := range r.batches(samples) {
+		args := make([][]interface{}, 0, len(batch))
+		for _, s := range batch {
+			args = append(args, []interface{}{s.key, s.timestamp, s.value})
+		}
+		adds = append(adds, pipe.TSMAdd(ctx, args))
+	}
+	// Errors are checked for each command below
+	_, _ = pipe.Exec(ctx)
+
+	for key, cmd := range creates {
+		if err := cmd.Err(); err != nil && !strings.Contains(err.Error(), "key already exists") {
+			return fmt.Errorf("creating series %q failed: %w", key, err)
+		}
+		r.created[key] = true
+	}
+	for _, cmd := range adds {
+		if err := cmd.Err(); err != nil {
+			// Series might have been deleted on the server side so recreate
+			// all series with the next write
+			if strings.Contains(err.Error(), "key does not exist") {
+				clear(r.created)
+			}
+			return fmt.Errorf("adding samples failed: %w", err)
+		}
+	}
 	return nil
 }
 
+func (r *RedisTimeSeries) convert(name string, field *telegraf.Field) (float64, bool) {
+	switch v := field.Value.(type) {
+	case float64:
+		return v, true
+	case string:
+		if !r.ConvertStringFields {
+			r.Log.Debugf("Dropping string field %q of metric %q", field.Key, name)
+			return 0, false
+		}
+		value, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			r.Log.Debugf("Converting string field %q of metric %q failed: %v", field.Key, name, err)
+			return 0, false
+		}
+		return value, true
+	default:
+		value, err := internal.ToFloat64(v)
+		if err != nil {
+			r.Log.Errorf("Converting field %q (%T) of metric %q failed: %v", field.Key, v, name, err)
+			return 0, false
+		}
+		return value, true
+	}
+}
+
+func (r *RedisTimeSeries) labels(m telegraf.Metric) map[string]string {
+	labels := make(map[string]string, len(m.TagList()))
+	for _, tag := range m.TagList() {
+		if r.labelFilter == nil || r.labelFilter.Match(tag.Key) {
+			labels[tag.Key] = tag.Value
+		}
+	}
+	return labels
+}
+
+// batches splits the samples into chunks of at most the batch size. In
+// cluster mode all keys of a TS.MADD command must be located in the same hash
+// slot so the samples are grouped by slot first.
+func (r *RedisTimeSeries) batches(samples []sample) [][]sample {
+	groups := [][]sample{samples}
+	if r.ClusterMode {
+		index := make(map[uint16]int)
+		groups = groups[:0]
+		for _, s := range samples {
+			slot := keySlot(s.key)
+			idx, found := index[slot]
+			if !found {
+				idx = len(groups)
+				index[slot] = idx
+				groups = append(groups, nil)
+			}
+			groups[idx] = append(groups[idx], s)
+		}
+	}
+
+	batches := make([][]sample, 0, len(groups))
+	for _, group := range groups {
+		for len(group) > r.BatchSize {
+			batches = append(batches, group[:r.BatchSize])
+			group = group[r.BatchSize:]
+		}
+		batches = append(batches, group)
+	}
+	return batches
+}
+
 func init() {
 	outputs.Add("redistimeseries", func() telegraf.Output {
 		return &RedisTimeSeries{
 			ConvertStringFields: true,
+			BatchSize:           1000,
 			Timeout:             config.Duration(10 * time.Second),
 		}
 	})
@@ -36,8 +36,11 @@ func TestConnectAndWriteIntegration(t *testing.T) {
 	redis := &RedisTimeSeries{
 		Address:             fmt.Sprintf("%s:%s", container.Address, container.Ports[servicePort]),
 		ConvertStringFields: true,
+		BatchSize:           1000,
 		Timeout:             config.Duration(10 * time.Second),
+		Log:                 testutil.Logger{},
 	}
+	require.NoError(t, redis.Init())
 	// Verify that we can connect to the RedisTimeSeries server
 	require.NoError(t, redis.Connect())
 	// Verify that we can successfully write data to the RedisTimeSeries server
@@ -58,6 +61,7 @@ func TestCases(t *testing.T) {
 	outputs.Add("redistimeseries", func() telegraf.Output {
 		return &RedisTimeSeries{
 			ConvertStringFields: true,
+			BatchSize:           1000,
 			Timeout:             config.Duration(10 * time.Second),
 		}
 	})
@@ -120,6 +124,7 @@ func TestCases(t *testing.T) {
 			plugin := cfg.Outputs[0].Output.(*RedisTimeSeries)
 			plugin.Address = address
 			plugin.Log = testutil.Logger{}
+			require.NoError(t, plugin.Init())
 
 			// Connect and write the metric(s)
 			require.NoError(t, plugin.Connect())
@@ -168,3 +173,85 @@ func getAllRecords(testContext context.Context, address string) []string {
 
 	return records
 }
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *RedisTimeSeries
+		expected string
+	}{
+		{
+			name:     "no address",
+			plugin:   &RedisTimeSeries{BatchSize: 1000},
+			expected: "redis address must be specified",
+		},
+		{
+			name:     "invalid batch size",
+			plugin:   &RedisTimeSeries{Address: "127.0.0.1:6379"},
+			expected: "invalid batch size 0",
+		},
+		{
+			name:     "invalid duplicate policy",
+			plugin:   &RedisTimeSeries{Address: "127.0.0.1:6379", BatchSize: 1000, DuplicatePolicy: "newest"},
+			expected: `invalid duplicate policy "NEWEST"`,
+		},
+		{
+			name:     "database in cluster mode",
+			plugin:   &RedisTimeSeries{Address: "127.0.0.1:6379", BatchSize: 1000, ClusterMode: true, Database: 1},
+			expected: "database selection is not supported in cluster mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestKeySlot(t *testing.T) {
+	require.Equal(t, uint16(12739), keySlot("123456789"))
+	require.Equal(t, uint16(12182), keySlot("foo"))
+	require.Equal(t, keySlot("user1000"), keySlot("{user1000}.following"))
+	require.Equal(t, keySlot("{user1000}.following"), keySlot("{user1000}.followers"))
+	require.Equal(t, keySlot("{}foo"), keySlot("{}foo"))
+	require.NotEqual(t, keySlot("foo"), keySlot("{}foo"))
+}
+
+func TestBatches(t *testing.T) {
+	samples := []sample{
+		{key: "{a}_x", timestamp: 1, value: 1},
+		{key: "{b}_x", timestamp: 1, value: 2},
+		{key: "{a}_y", timestamp: 1, value: 3},
+		{key: "{b}_y", timestamp: 1, value: 4},
+		{key: "{a}_z", timestamp: 1, value: 5},
+	}
+
+	plugin := &RedisTimeSeries{BatchSize: 2}
+	require.Equal(t, [][]sample{samples[0:2], samples[2:4], samples[4:5]}, plugin.batches(samples))
+
+	plugin.ClusterMode = true
+	expected := [][]sample{
+		{samples[0], samples[2]},
+		{samples[4]},
+		{samples[1], samples[3]},
+	}
+	require.Equal(t, expected, plugin.batches(samples))
+}
+
+func TestLabels(t *testing.T) {
+	m := testutil.MustMetric(
+		"weather",
+		map[string]string{"location": "somewhere", "sensor": "abc"},
+		map[string]interface{}{"temperature": 23.1},
+		time.Unix(0, 0),
+	)
+
+	plugin := &RedisTimeSeries{Address: "127.0.0.1:6379", BatchSize: 1000}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, map[string]string{"location": "somewhere", "sensor": "abc"}, plugin.labels(m))
+
+	plugin.LabelTags = []string{"loc*"}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, map[string]string{"location": "somewhere"}, plugin.labels(m))
+}
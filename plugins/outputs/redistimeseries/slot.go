@@ -0,0 +1,33 @@
+package redistimeseries
+
+import "strings"
+
+// Number of hash slots of a Redis cluster
+const slotCount = 16384
+
+// keySlot computes the cluster hash slot of the given key honoring hash-tags
+// as described in https://redis.io/docs/latest/operate/oss_and_stack/reference/cluster-spec/
+func keySlot(key string) uint16 {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return crc16(key) % slotCount
+}
+
+// crc16 implements the CRC16-CCITT (XMODEM) checksum used by Redis cluster
+func crc16(data string) uint16 {
+	var crc uint16
+	for i := range len(data) {
+		crc ^= uint16(data[i]) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
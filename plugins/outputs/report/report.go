@@ -0,0 +1,427 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package report
+
+import (
+	"bufio"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var (
+	invalidFilenameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+	invalidSheetChars    = regexp.MustCompile(`[\[\]:*?/\\]`)
+)
+
+type Report struct {
+	Directory       string          `toml:"directory"`
+	Prefix          string          `toml:"prefix"`
+	Format          string          `toml:"format"`
+	RollupInterval  config.Duration `toml:"rollup_interval"`
+	TagColumns      []string        `toml:"tag_columns"`
+	FieldColumns    []string        `toml:"field_columns"`
+	TimestampFormat string          `toml:"timestamp_format"`
+	Timezone        string          `toml:"timezone"`
+	MaxRows         int             `toml:"max_rows"`
+	Log             telegraf.Logger `toml:"-"`
+
+	location *time.Location
+
+	// Rows of the current report period per measurement
+	tables map[string]*table
+	start  time.Time
+	mu     sync.Mutex
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// table contains the rows of a measurement together with all tag and field
+// keys seen within the report period
+type table struct {
+	tags    map[string]bool
+	fields  map[string]bool
+	rows    []row
+	dropped int
+}
+
+type row struct {
+	ts     time.Time
+	tags   map[string]string
+	fields map[string]interface{}
+}
+
+func (*Report) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Report) Init() error {
+	if r.Directory == "" {
+		return errors.New("'directory' required")
+	}
+
+	switch r.Format {
+	case "":
+		r.Format = "csv"
+	case "csv", "xlsx":
+	default:
+		return fmt.Errorf("invalid format %q", r.Format)
+	}
+
+	if r.RollupInterval <= 0 {
+		return errors.New("'rollup_interval' must be positive")
+	}
+	if r.MaxRows < 0 {
+		return errors.New("'max_rows' must not be negative")
+	}
+
+	if r.TimestampFormat == "" {
+		r.TimestampFormat = time.RFC3339
+	}
+
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return fmt.Errorf("loading timezone %q failed: %w", r.Timezone, err)
+	}
+	r.location = loc
+
+	r.tables = make(map[string]*table)
+
+	return nil
+}
+
+func (r *Report) Connect() error {
+	if err := os.MkdirAll(r.Directory, 0750); err != nil {
+		return fmt.Errorf("creating directory failed: %w", err)
+	}
+
+	r.start = r.periodStart(time.Now())
+	r.done = make(chan struct{})
+
+	// Write the reports at the end of each period even if no new metrics
+	// arrive
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for {
+			r.mu.Lock()
+			end := r.start.Add(time.Duration(r.RollupInterval))
+			r.mu.Unlock()
+
+			timer := time.NewTimer(time.Until(end))
+			select {
+			case <-r.done:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			r.mu.Lock()
+			r.rotate(time.Now())
+			r.mu.Unlock()
+		}
+	}()
+
+	return nil
+}
+
+func (r *Report) Close() error {
+	if r.done != nil {
+		close(r.done)
+	}
+	r.wg.Wait()
+
+	// Write the incomplete reports to not lose the data
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.writeReports()
+}
+
+func (r *Report) Write(metrics []telegraf.Metric) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rotate(time.Now())
+
+	for _, m := range metrics {
+		t, found := r.tables[m.Name()]
+		if !found {
+			t = &table{
+				tags:   make(map[string]bool),
+				fields: make(map[string]bool),
+			}
+			r.tables[m.Name()] = t
+		}
+
+		if r.MaxRows > 0 && len(t.rows) >= r.MaxRows {
+			if t.dropped == 0 {
+				r.Log.Warnf("Maximum number of rows reached for %q; dropping metrics until the end of the period", m.Name())
+			}
+			t.dropped++
+			continue
+		}
+
+		tags := m.Tags()
+		fields := m.Fields()
+		for k := range tags {
+			t.tags[k] = true
+		}
+		for k := range fields {
+			t.fields[k] = true
+		}
+		t.rows = append(t.rows, row{ts: m.Time(), tags: tags, fields: fields})
+	}
+
+	return nil
+}
+
+// periodStart returns the start of the report period containing the given
+// time with periods being aligned to the configured timezone
+func (r *Report) periodStart(t time.Time) time.Time {
+	_, offset := t.In(r.location).Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(time.Duration(r.RollupInterval)).Add(-shift)
+}
+
+// rotate writes the reports and starts a new period if the current period
+// ended, the caller must hold the lock
+func (r *Report) rotate(now time.Time) {
+	if now.Before(r.start.Add(time.Duration(r.RollupInterval))) {
+		return
+	}
+
+	if err := r.writeReports(); err != nil {
+		r.Log.Errorf("Writing reports failed: %v", err)
+	}
+	r.tables = make(map[string]*table)
+	r.start = r.periodStart(now)
+}
+
+// writeReports writes the reports of the current period, the caller must hold
+// the lock
+func (r *Report) writeReports() error {
+	if len(r.tables) == 0 {
+		return nil
+	}
+
+	for name, t := range r.tables {
+		if t.dropped > 0 {
+			r.Log.Warnf("Dropped %d metrics of %q exceeding the maximum number of rows", t.dropped, name)
+		}
+	}
+
+	suffix := r.start.In(r.location).Format("20060102T150405")
+	names := slices.Sorted(maps.Keys(r.tables))
+
+	switch r.Format {
+	case "xlsx":
+		sheets := make([]sheet, 0, len(names))
+		seen := make(map[string]bool, len(names))
+		for _, name := range names {
+			sheets = append(sheets, sheet{
+				name: sheetName(name, seen),
+				rows: r.buildRows(r.tables[name], false),
+			})
+		}
+		return r.writeFile(r.Prefix+suffix+".xlsx", func(w io.Writer) error {
+			return writeXLSX(w, sheets)
+		})
+	default:
+		var errs []error
+		for _, name := range names {
+			rows := r.buildRows(r.tables[name], true)
+			fn := r.Prefix + invalidFilenameChars.ReplaceAllString(name, "_") + "_" + suffix + ".csv"
+			err := r.writeFile(fn, func(w io.Writer) error {
+				return writeCSV(w, rows)
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("writing report for %q failed: %w", name, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// buildRows creates the rows of the report including the header with the
+// time being the first column followed by tag and field columns
+func (r *Report) buildRows(t *table, asText bool) [][]interface{} {
+	tagColumns := r.TagColumns
+	if len(tagColumns) == 0 {
+		tagColumns = slices.Sorted(maps.Keys(t.tags))
+	}
+	fieldColumns := r.FieldColumns
+	if len(fieldColumns) == 0 {
+		fieldColumns = slices.Sorted(maps.Keys(t.fields))
+	}
+
+	header := make([]interface{}, 0, 1+len(tagColumns)+len(fieldColumns))
+	header = append(header, "time")
+	for _, c := range tagColumns {
+		header = append(header, c)
+	}
+	for _, c := range fieldColumns {
+		header = append(header, c)
+	}
+
+	rows := make([][]interface{}, 0, len(t.rows)+1)
+	rows = append(rows, header)
+	for _, data := range t.rows {
+		values := make([]interface{}, 0, len(header))
+		values = append(values, r.formatTime(data.ts))
+		for _, c := range tagColumns {
+			if v, found := data.tags[c]; found {
+				values = append(values, v)
+			} else {
+				values = append(values, nil)
+			}
+		}
+		for _, c := range fieldColumns {
+			v := data.fields[c]
+			if asText && v != nil {
+				v = formatValue(v)
+			}
+			values = append(values, v)
+		}
+		rows = append(rows, values)
+	}
+	return rows
+}
+
+func (r *Report) formatTime(ts time.Time) string {
+	switch r.TimestampFormat {
+	case "unix":
+		return strconv.FormatInt(ts.Unix(), 10)
+	case "unix_ms":
+		return strconv.FormatInt(ts.UnixMilli(), 10)
+	case "unix_us":
+		return strconv.FormatInt(ts.UnixMicro(), 10)
+	case "unix_ns":
+		return strconv.FormatInt(ts.UnixNano(), 10)
+	}
+	return ts.In(r.location).Format(r.TimestampFormat)
+}
+
+// writeFile atomically writes the report file with the given name using the
+// writer function. Existing reports, e.g. of a period interrupted by a restart,
+// are not overwritten but the new report gets a numbered suffix.
+func (r *Report) writeFile(name string, write func(io.Writer) error) error {
+	fn := filepath.Join(r.Directory, name)
+	ext := filepath.Ext(name)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(fn); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		fn = filepath.Join(r.Directory, fmt.Sprintf("%s_%d%s", name[:len(name)-len(ext)], i, ext))
+	}
+
+	tmp, err := os.CreateTemp(r.Directory, ".report-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	if err := write(w); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), fn); err != nil {
+		return err
+	}
+	r.Log.Debugf("Wrote report %q", fn)
+	return nil
+}
+
+func writeCSV(w io.Writer, rows [][]interface{}) error {
+	writer := csv.NewWriter(w)
+	record := make([]string, 0)
+	for _, values := range rows {
+		record = record[:0]
+		for _, v := range values {
+			if v == nil {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, v.(string))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// sheetName returns a valid and unique name for a worksheet, names must not
+// exceed 31 characters, must not contain any of []:*?/\ and are compared
+// case-insensitive
+func sheetName(name string, seen map[string]bool) string {
+	base := []rune(invalidSheetChars.ReplaceAllString(name, "_"))
+	if len(base) == 0 {
+		base = []rune("sheet")
+	}
+	if len(base) > 31 {
+		base = base[:31]
+	}
+
+	candidate := string(base)
+	for i := 1; seen[strings.ToLower(candidate)]; i++ {
+		suffix := "_" + strconv.Itoa(i)
+		candidate = string(base[:min(len(base), 31-len(suffix))]) + suffix
+	}
+	seen[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+func init() {
+	outputs.Add("report", func() telegraf.Output {
+		return &Report{
+			Prefix:         "report_",
+			Format:         "csv",
+			RollupInterval: config.Duration(24 * time.Hour),
+			MaxRows:        100000,
+		}
+	})
+}
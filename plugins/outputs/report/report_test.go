@@ -0,0 +1,246 @@
+package report
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*Report)
+		expected string
+	}{
+		{
+			name:     "no directory",
+			modify:   func(r *Report) { r.Directory = "" },
+			expected: "'directory' required",
+		},
+		{
+			name:     "invalid format",
+			modify:   func(r *Report) { r.Format = "pdf" },
+			expected: `invalid format "pdf"`,
+		},
+		{
+			name:     "invalid interval",
+			modify:   func(r *Report) { r.RollupInterval = 0 },
+			expected: "'rollup_interval' must be positive",
+		},
+		{
+			name:     "invalid max rows",
+			modify:   func(r *Report) { r.MaxRows = -1 },
+			expected: "'max_rows' must not be negative",
+		},
+		{
+			name:     "invalid timezone",
+			modify:   func(r *Report) { r.Timezone = "Mars/Olympus" },
+			expected: `loading timezone "Mars/Olympus" failed`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := outputs.Outputs["report"]().(*Report)
+			plugin.Directory = t.TempDir()
+			plugin.Log = &testutil.Logger{}
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	dir := t.TempDir()
+
+	plugin := outputs.Outputs["report"]().(*Report)
+	plugin.Directory = dir
+	plugin.TimestampFormat = "unix"
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 98.5, "usage_user": 1.5},
+			time.Unix(1718279100, 0),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "b", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 90.25},
+			time.Unix(1718279110, 0),
+		),
+		metric.New(
+			"disk,io",
+			map[string]string{"device": "sda"},
+			map[string]interface{}{"reads": int64(42), "ok": true, "model": "SSD, fast"},
+			time.Unix(1718279120, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.NoError(t, plugin.Close())
+
+	expected := map[string][][]string{
+		"cpu": {
+			{"time", "cpu", "host", "usage_idle", "usage_user"},
+			{"1718279100", "", "a", "98.5", "1.5"},
+			{"1718279110", "cpu0", "b", "90.25", ""},
+		},
+		"disk_io": {
+			{"time", "device", "model", "ok", "reads"},
+			{"1718279120", "sda", "SSD, fast", "true", "42"},
+		},
+	}
+	for name, records := range expected {
+		matches, err := filepath.Glob(filepath.Join(dir, "report_"+name+"_*.csv"))
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+
+		f, err := os.Open(matches[0])
+		require.NoError(t, err)
+		actual, err := csv.NewReader(f).ReadAll()
+		f.Close()
+		require.NoError(t, err)
+		require.Equal(t, records, actual)
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	dir := t.TempDir()
+
+	plugin := outputs.Outputs["report"]().(*Report)
+	plugin.Directory = dir
+	plugin.Format = "xlsx"
+	plugin.TimestampFormat = "2006-01-02 15:04:05"
+	plugin.TagColumns = []string{"host"}
+	plugin.FieldColumns = []string{"usage_idle", "ok"}
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a&b", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 98.5, "usage_user": 1.5, "ok": true},
+			time.Unix(1718279100, 0),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "a&b"},
+			map[string]interface{}{"usage_idle": int64(3)},
+			time.Unix(1718279100, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.NoError(t, plugin.Close())
+
+	matches, err := filepath.Glob(filepath.Join(dir, "report_*.xlsx"))
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+
+	archive, err := zip.OpenReader(matches[0])
+	require.NoError(t, err)
+	defer archive.Close()
+
+	files := make(map[string]string, len(archive.File))
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		buf, err := io.ReadAll(r)
+		r.Close()
+		require.NoError(t, err)
+		files[f.Name] = string(buf)
+	}
+	require.Contains(t, files, "[Content_Types].xml")
+	require.Contains(t, files, "_rels/.rels")
+	require.Contains(t, files, "xl/_rels/workbook.xml.rels")
+	require.Contains(t, files["xl/workbook.xml"], `<sheet name="cpu" sheetId="1" r:id="rId1"/>`)
+	require.Contains(t, files["xl/workbook.xml"], `<sheet name="mem" sheetId="2" r:id="rId2"/>`)
+
+	expected := `<row r="1">` +
+		`<c r="A1" t="inlineStr"><is><t>time</t></is></c>` +
+		`<c r="B1" t="inlineStr"><is><t>host</t></is></c>` +
+		`<c r="C1" t="inlineStr"><is><t>usage_idle</t></is></c>` +
+		`<c r="D1" t="inlineStr"><is><t>ok</t></is></c>` +
+		`</row><row r="2">` +
+		`<c r="A2" t="inlineStr"><is><t>2024-06-13 11:45:00</t></is></c>` +
+		`<c r="B2" t="inlineStr"><is><t>a&amp;b</t></is></c>` +
+		`<c r="C2"><v>98.5</v></c>` +
+		`<c r="D2" t="b"><v>1</v></c>` +
+		`</row>`
+	require.Contains(t, files["xl/worksheets/sheet1.xml"], expected)
+	require.Contains(t, files["xl/worksheets/sheet2.xml"], `<c r="C2"><v>3</v></c>`)
+}
+
+func TestRotate(t *testing.T) {
+	dir := t.TempDir()
+
+	plugin := outputs.Outputs["report"]().(*Report)
+	plugin.Directory = dir
+	plugin.RollupInterval = config.Duration(time.Hour)
+	plugin.MaxRows = 1
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m, m}))
+
+	// The row limit must drop the second metric
+	plugin.mu.Lock()
+	require.Len(t, plugin.tables["cpu"].rows, 1)
+	require.Equal(t, 1, plugin.tables["cpu"].dropped)
+
+	// Ending the period must write the report and start a new period
+	start := plugin.start
+	plugin.rotate(start.Add(90 * time.Minute))
+	require.Empty(t, plugin.tables)
+	require.Equal(t, start.Add(time.Hour), plugin.start)
+	plugin.mu.Unlock()
+
+	name := "report_cpu_" + start.UTC().Format("20060102T150405") + ".csv"
+	require.FileExists(t, filepath.Join(dir, name))
+
+	// Existing reports must not be overwritten
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	plugin.mu.Lock()
+	plugin.start = start
+	plugin.mu.Unlock()
+	require.NoError(t, plugin.Close())
+	require.FileExists(t, filepath.Join(dir, "report_cpu_"+start.UTC().Format("20060102T150405")+"_1.csv"))
+}
+
+func TestColumnName(t *testing.T) {
+	require.Equal(t, "A", columnName(0))
+	require.Equal(t, "Z", columnName(25))
+	require.Equal(t, "AA", columnName(26))
+	require.Equal(t, "AZ", columnName(51))
+	require.Equal(t, "BA", columnName(52))
+	require.Equal(t, "ZZ", columnName(701))
+	require.Equal(t, "AAA", columnName(702))
+}
+
+func TestSheetName(t *testing.T) {
+	seen := make(map[string]bool)
+	require.Equal(t, "cpu", sheetName("cpu", seen))
+	require.Equal(t, "CPU_1", sheetName("CPU", seen))
+	require.Equal(t, "a_b_c", sheetName("a/b:c", seen))
+	require.Equal(t, "abcdefghijklmnopqrstuvwxyz01234", sheetName("abcdefghijklmnopqrstuvwxyz0123456789", seen))
+	require.Equal(t, "abcdefghijklmnopqrstuvwxyz012_1", sheetName("abcdefghijklmnopqrstuvwxyz0123456789", seen))
+}
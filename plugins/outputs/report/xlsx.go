@@ -0,0 +1,153 @@
+package report
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The following implements a minimal writer for Office Open XML spreadsheets
+// (XLSX) only supporting plain worksheets with inline strings, numbers and
+// booleans without any styling.
+
+const (
+	xlsxContentTypesHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>
+`
+	xlsxWorksheetHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxWorksheetFooter = `</sheetData></worksheet>`
+)
+
+// sheet is a named table with the first row being the header
+type sheet struct {
+	name string
+	rows [][]interface{}
+}
+
+// writeXLSX writes the given sheets as workbook to the writer
+func writeXLSX(w io.Writer, sheets []sheet) error {
+	zw := zip.NewWriter(w)
+
+	var contentTypes, workbook, workbookRels strings.Builder
+	contentTypes.WriteString(xlsxContentTypesHeader)
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	workbookRels.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+`)
+
+	for i, s := range sheets {
+		id := i + 1
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" `+
+			`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`+"\n", id)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeXML(s.name), id, id)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" `+
+			`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" `+
+			`Target="worksheets/sheet%d.xml"/>`+"\n", id, id)
+
+		f, err := zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", id))
+		if err != nil {
+			return err
+		}
+		if err := writeWorksheet(f, s.rows); err != nil {
+			return fmt.Errorf("writing sheet %q failed: %w", s.name, err)
+		}
+	}
+	contentTypes.WriteString("</Types>\n")
+	workbook.WriteString("</sheets></workbook>\n")
+	workbookRels.WriteString("</Relationships>\n")
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypes.String()},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.String()},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, p.content); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func writeWorksheet(w io.Writer, rows [][]interface{}) error {
+	var buf strings.Builder
+	buf.WriteString(xlsxWorksheetHeader)
+	for i, row := range rows {
+		fmt.Fprintf(&buf, `<row r="%d">`, i+1)
+		for j, value := range row {
+			ref := columnName(j) + strconv.Itoa(i+1)
+			switch v := value.(type) {
+			case nil:
+				continue
+			case int64:
+				fmt.Fprintf(&buf, `<c r="%s"><v>%d</v></c>`, ref, v)
+			case uint64:
+				fmt.Fprintf(&buf, `<c r="%s"><v>%d</v></c>`, ref, v)
+			case float64:
+				fmt.Fprintf(&buf, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(v, 'g', -1, 64))
+			case bool:
+				b := 0
+				if v {
+					b = 1
+				}
+				fmt.Fprintf(&buf, `<c r="%s" t="b"><v>%d</v></c>`, ref, b)
+			default:
+				fmt.Fprintf(&buf, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, escapeXML(fmt.Sprint(v)))
+			}
+		}
+		buf.WriteString("</row>")
+
+		// Flush the buffer regularly to limit memory usage
+		if buf.Len() > 64*1024 {
+			if _, err := io.WriteString(w, buf.String()); err != nil {
+				return err
+			}
+			buf.Reset()
+		}
+	}
+	buf.WriteString(xlsxWorksheetFooter)
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// columnName returns the spreadsheet column name for the given zero-based
+// index, e.g. "A" for 0 and "AA" for 26
+func columnName(idx int) string {
+	var name []byte
+	for idx >= 0 {
+		name = append([]byte{byte('A' + idx%26)}, name...)
+		idx = idx/26 - 1
+	}
+	return string(name)
+}
+
+func escapeXML(s string) string {
+	var buf strings.Builder
+	if err := xml.EscapeText(&buf, []byte(s)); err != nil {
+		return s
+	}
+	return buf.String()
+}
@@ -0,0 +1,123 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package router
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Router struct {
+	Mode    string          `toml:"mode"`
+	Default []string        `toml:"default"`
+	Routes  []*route        `toml:"route"`
+	Log     telegraf.Logger `toml:"-"`
+}
+
+type route struct {
+	Condition string   `toml:"condition"`
+	Outputs   []string `toml:"outputs"`
+
+	filter *models.Filter
+}
+
+func (*Router) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Router) Init() error {
+	switch r.Mode {
+	case "":
+		r.Mode = "first"
+	case "first", "all":
+	default:
+		return fmt.Errorf("invalid mode %q", r.Mode)
+	}
+
+	if len(r.Routes) == 0 {
+		return errors.New("no routes defined")
+	}
+	for i, rt := range r.Routes {
+		if rt.Condition == "" {
+			return fmt.Errorf("missing condition for route %d", i+1)
+		}
+		if len(rt.Outputs) == 0 {
+			return fmt.Errorf("missing outputs for route %d", i+1)
+		}
+
+		rt.filter = &models.Filter{MetricPass: rt.Condition}
+		if err := rt.filter.Compile(); err != nil {
+			return fmt.Errorf("compiling condition of route %d failed: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (*Router) Connect() error {
+	return nil
+}
+
+func (*Router) Close() error {
+	return nil
+}
+
+// Write is never called with metrics as the agent routes the metrics to the
+// target outputs directly.
+func (*Router) Write([]telegraf.Metric) error {
+	return nil
+}
+
+// Targets returns the aliases of all outputs referenced by the routes
+func (r *Router) Targets() []string {
+	targets := slices.Clone(r.Default)
+	for _, rt := range r.Routes {
+		for _, alias := range rt.Outputs {
+			if !slices.Contains(targets, alias) {
+				targets = append(targets, alias)
+			}
+		}
+	}
+	return targets
+}
+
+// Route returns the aliases of the outputs of the matching routes or the
+// default outputs if no route matches
+func (r *Router) Route(metric telegraf.Metric) []string {
+	var targets []string
+	for i, rt := range r.Routes {
+		ok, err := rt.filter.Select(metric)
+		if err != nil {
+			// Errors are expected e.g. for accessing non-existing tags so
+			// treat those as not matching.
+			r.Log.Debugf("Evaluating condition of route %d failed: %v", i+1, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		targets = append(targets, rt.Outputs...)
+		if r.Mode == "first" {
+			return targets
+		}
+	}
+
+	if len(targets) == 0 {
+		return r.Default
+	}
+	return targets
+}
+
+func init() {
+	outputs.Add("router", func() telegraf.Output {
+		return &Router{}
+	})
+}
@@ -0,0 +1,137 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Router
+		expected string
+	}{
+		{
+			name:     "invalid mode",
+			plugin:   &Router{Mode: "some", Routes: []*route{{Condition: "true", Outputs: []string{"a"}}}},
+			expected: `invalid mode "some"`,
+		},
+		{
+			name:     "no routes",
+			plugin:   &Router{},
+			expected: "no routes defined",
+		},
+		{
+			name:     "missing condition",
+			plugin:   &Router{Routes: []*route{{Outputs: []string{"a"}}}},
+			expected: "missing condition for route 1",
+		},
+		{
+			name:     "missing outputs",
+			plugin:   &Router{Routes: []*route{{Condition: "true"}}},
+			expected: "missing outputs for route 1",
+		},
+		{
+			name:     "invalid condition",
+			plugin:   &Router{Routes: []*route{{Condition: "name", Outputs: []string{"a"}}}},
+			expected: "compiling condition of route 1 failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestTargets(t *testing.T) {
+	plugin := &Router{
+		Default: []string{"fallback"},
+		Routes: []*route{
+			{Condition: `name == "cpu"`, Outputs: []string{"a", "b"}},
+			{Condition: `name == "mem"`, Outputs: []string{"b", "c"}},
+		},
+	}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, []string{"fallback", "a", "b", "c"}, plugin.Targets())
+}
+
+func TestRoute(t *testing.T) {
+	routes := []*route{
+		{Condition: `"env" in tags && tags.env == "prod"`, Outputs: []string{"prod"}},
+		{Condition: `"error" in fields`, Outputs: []string{"errors"}},
+		{Condition: `tags.region == "eu"`, Outputs: []string{"eu"}},
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		metric   telegraf.Metric
+		expected []string
+	}{
+		{
+			name: "first match",
+			mode: "first",
+			metric: metric.New(
+				"app",
+				map[string]string{"env": "prod"},
+				map[string]interface{}{"error": "failed"},
+				time.Unix(0, 0),
+			),
+			expected: []string{"prod"},
+		},
+		{
+			name: "fan-out",
+			mode: "all",
+			metric: metric.New(
+				"app",
+				map[string]string{"env": "prod", "region": "eu"},
+				map[string]interface{}{"error": "failed"},
+				time.Unix(0, 0),
+			),
+			expected: []string{"prod", "errors", "eu"},
+		},
+		{
+			name: "missing tag does not match",
+			mode: "all",
+			metric: metric.New(
+				"app",
+				map[string]string{},
+				map[string]interface{}{"error": "failed"},
+				time.Unix(0, 0),
+			),
+			expected: []string{"errors"},
+		},
+		{
+			name: "default",
+			mode: "first",
+			metric: metric.New(
+				"app",
+				map[string]string{"env": "dev"},
+				map[string]interface{}{"value": 42},
+				time.Unix(0, 0),
+			),
+			expected: []string{"fallback"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Router{
+				Mode:    tt.mode,
+				Default: []string{"fallback"},
+				Routes:  routes,
+				Log:     testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+			require.Equal(t, tt.expected, plugin.Route(tt.metric))
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	mssql "github.com/microsoft/go-mssqldb"
+)
+
+// maxParameters returns the maximum number of placeholders allowed in a
+// single statement of the driver.
+func (p *SQL) maxParameters() int {
+	switch p.Driver {
+	case "mssql":
+		return 2100
+	case "sqlite":
+		return 32766
+	}
+	return 65535
+}
+
+// rowsPerStatement returns the maximum number of rows with the given number
+// of columns in a single statement of the driver.
+func (p *SQL) rowsPerStatement(columns int) int {
+	n := max(p.maxParameters()/columns, 1)
+
+	// SQL Server additionally limits the number of row value expressions
+	if p.Driver == "mssql" {
+		n = min(n, 1000)
+	}
+	return n
+}
+
+// sendMultiRow inserts rows with identical table and columns using
+// statements with multiple value lists, limited by the number of parameters
+// and rows supported by the driver.
+func (p *SQL) sendMultiRow(rows []*row) error {
+	perStatement := p.rowsPerStatement(len(rows[0].columns))
+
+	for start := 0; start < len(rows); start += perStatement {
+		end := min(start+perStatement, len(rows))
+		params := make([]interface{}, 0, (end-start)*len(rows[0].columns))
+		for _, r := range rows[start:end] {
+			params = append(params, r.values...)
+		}
+		sql := p.insertQuery(rows[0], end-start)
+		if _, err := p.db.Exec(sql, params...); err != nil {
+			return fmt.Errorf("execution failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// sendBulk inserts rows with identical table and columns using the
+// bulk-loading mechanism of the driver.
+func (p *SQL) sendBulk(rows []*row) error {
+	values := make([][]interface{}, 0, len(rows))
+	for _, r := range rows {
+		values = append(values, r.values)
+	}
+
+	switch p.Driver {
+	case "clickhouse":
+		// Prepared statements within a transaction are sent as native batch
+		return p.sendBatch(p.insertQuery(rows[0], 1), values)
+	case "mssql":
+		return p.sendBulkCopy(rows[0].table, rows[0].columns, values)
+	case "pgx":
+		return p.sendCopy(rows[0].table, rows[0].columns, values)
+	}
+	return fmt.Errorf("bulk insert not supported by driver %q", p.Driver)
+}
+
+// sendCopy uses the postgres 'COPY FROM' protocol
+func (p *SQL) sendCopy(table string, columns []string, values [][]interface{}) error {
+	ctx := context.Background()
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("getting connection failed: %w", err)
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("unexpected connection type")
+		}
+		if _, err := c.Conn().CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(values)); err != nil {
+			return fmt.Errorf("copy failed: %w", err)
+		}
+		return nil
+	})
+}
+
+// sendBulkCopy uses the SQL Server bulk copy protocol
+func (p *SQL) sendBulkCopy(table string, columns []string, values [][]interface{}) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin failed: %w", err)
+	}
+
+	stmt, err := tx.Prepare(mssql.CopyIn(quoteIdent(table), mssql.BulkOptions{}, columns...))
+	if err != nil {
+		if errRollback := tx.Rollback(); errRollback != nil {
+			return fmt.Errorf("prepare failed: %w, unable to rollback: %w", err, errRollback)
+		}
+		return fmt.Errorf("prepare failed: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, params := range values {
+		if _, err := stmt.Exec(params...); err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				return fmt.Errorf("execution failed: %w, unable to rollback: %w", err, errRollback)
+			}
+			return fmt.Errorf("execution failed: %w", err)
+		}
+	}
+
+	// Executing the statement without parameters flushes the buffered rows
+	if _, err := stmt.Exec(); err != nil {
+		if errRollback := tx.Rollback(); errRollback != nil {
+			return fmt.Errorf("flushing failed: %w, unable to rollback: %w", err, errRollback)
+		}
+		return fmt.Errorf("flushing failed: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	return nil
+}
@@ -5,8 +5,10 @@ import (
 	"cmp"
 	gosql "database/sql"
 	_ "embed"
+	"errors"
 	"fmt"
 	"iter"
+	"maps"
 	"net/url"
 	"slices"
 	"strconv"
@@ -22,6 +24,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
 
@@ -50,6 +53,46 @@ type ConvertStruct struct {
 	ConversionStyle string `toml:"conversion_style"`
 }
 
+// TableMapping directs metrics with a matching name to the given table and
+// renames the tag and field columns according to the column mapping.
+type TableMapping struct {
+	Measurement []string          `toml:"measurement"`
+	Table       string            `toml:"table"`
+	Columns     map[string]string `toml:"columns"`
+
+	filter filter.Filter
+}
+
+func (m *TableMapping) tableName(name string) string {
+	if m == nil || m.Table == "" {
+		return name
+	}
+	return m.Table
+}
+
+func (m *TableMapping) columnName(key string) string {
+	if m == nil {
+		return key
+	}
+	if column, found := m.Columns[key]; found {
+		return column
+	}
+	return key
+}
+
+// row contains the columns and values of a metric. The first "keys" columns
+// are the timestamp and tag columns identifying the row.
+type row struct {
+	table   string
+	columns []string
+	values  []interface{}
+	keys    int
+}
+
+func (r *row) cacheKey() string {
+	return r.table + "\n" + strings.Join(r.columns, "\n")
+}
+
 type SQL struct {
 	Driver                string          `toml:"driver"`
 	DataSourceName        config.Secret   `toml:"data_source_name"`
@@ -59,7 +102,10 @@ type SQL struct {
 	TableUpdateTemplate   string          `toml:"table_update_template"`
 	InitSQL               string          `toml:"init_sql"`
 	BatchTx               bool            `toml:"batch_transactions"`
+	ConflictHandling      string          `toml:"conflict_handling"`
+	InsertStrategy        string          `toml:"insert_strategy"`
 	Convert               ConvertStruct   `toml:"convert"`
+	TableMappings         []*TableMapping `toml:"table_mapping"`
 	ConnectionMaxIdleTime config.Duration `toml:"connection_max_idle_time"`
 	ConnectionMaxLifetime config.Duration `toml:"connection_max_lifetime"`
 	ConnectionMaxIdle     int             `toml:"connection_max_idle"`
@@ -106,6 +152,63 @@ func (p *SQL) Init() error {
 		return fmt.Errorf("unknown driver %q", p.Driver)
 	}
 
+	switch p.ConflictHandling {
+	case "":
+		p.ConflictHandling = "error"
+	case "error", "ignore", "update":
+		if p.ConflictHandling != "error" && p.Driver == "clickhouse" {
+			return errors.New("conflict handling is not supported by clickhouse, use a ReplacingMergeTree table instead")
+		}
+	default:
+		return fmt.Errorf("invalid 'conflict_handling' %q", p.ConflictHandling)
+	}
+
+	switch p.InsertStrategy {
+	case "":
+		p.InsertStrategy = "individual"
+	case "individual":
+	case "multirow":
+		if p.Driver == "clickhouse" {
+			return errors.New("insert strategy 'multirow' is not supported by clickhouse, use 'bulk' instead")
+		}
+	case "bulk":
+		switch p.Driver {
+		case "clickhouse", "mssql", "pgx":
+		default:
+			return fmt.Errorf("insert strategy 'bulk' is not supported by driver %q", p.Driver)
+		}
+		if p.ConflictHandling != "error" {
+			return errors.New("conflict handling is not supported with insert strategy 'bulk'")
+		}
+	default:
+		return fmt.Errorf("invalid 'insert_strategy' %q", p.InsertStrategy)
+	}
+
+	for i, m := range p.TableMappings {
+		if len(m.Measurement) == 0 {
+			return fmt.Errorf("'measurement' missing in table mapping %d", i+1)
+		}
+		mapped := make(map[string]string, len(m.Columns))
+		for _, key := range slices.Sorted(maps.Keys(m.Columns)) {
+			column := m.Columns[key]
+			if column == "" {
+				return fmt.Errorf("empty column name for %q in table mapping %d", key, i+1)
+			}
+			if other, found := mapped[column]; found {
+				return fmt.Errorf("%q and %q map to the same column %q in table mapping %d", other, key, column, i+1)
+			}
+			if column == p.TimestampColumn {
+				return fmt.Errorf("%q maps to the timestamp column %q in table mapping %d", key, column, i+1)
+			}
+			mapped[column] = key
+		}
+		f, err := filter.Compile(m.Measurement)
+		if err != nil {
+			return fmt.Errorf("compiling measurement filter of table mapping %d failed: %w", i+1, err)
+		}
+		m.filter = f
+	}
+
 	return nil
 }
 
@@ -202,30 +305,49 @@ func (p *SQL) deriveDatatype(value interface{}) string {
 	return datatype
 }
 
+// tableMapping returns the first table mapping matching the given metric
+// name or nil if there is none.
+func (p *SQL) tableMapping(name string) *TableMapping {
+	for _, m := range p.TableMappings {
+		if m.filter == nil || m.filter.Match(name) {
+			return m
+		}
+	}
+	return nil
+}
+
 func (p *SQL) generateCreateTable(metric telegraf.Metric) string {
+	mapping := p.tableMapping(metric.Name())
+	tablename := mapping.tableName(metric.Name())
+
 	columns := make([]string, 0, len(metric.TagList())+len(metric.FieldList())+1)
 	tagColumnNames := make([]string, 0, len(metric.TagList()))
+	keyColumnNames := make([]string, 0, len(metric.TagList())+1)
 
 	if p.TimestampColumn != "" {
 		columns = append(columns, fmt.Sprintf("%s %s", quoteIdent(p.TimestampColumn), p.Convert.Timestamp))
+		keyColumnNames = append(keyColumnNames, quoteIdent(p.TimestampColumn))
 	}
 
 	for _, tag := range metric.TagList() {
-		columns = append(columns, fmt.Sprintf("%s %s", quoteIdent(tag.Key), p.Convert.Text))
-		tagColumnNames = append(tagColumnNames, quoteIdent(tag.Key))
+		column := quoteIdent(mapping.columnName(tag.Key))
+		columns = append(columns, fmt.Sprintf("%s %s", column, p.Convert.Text))
+		tagColumnNames = append(tagColumnNames, column)
+		keyColumnNames = append(keyColumnNames, column)
 	}
 
 	var datatype string
 	for _, field := range metric.FieldList() {
 		datatype = p.deriveDatatype(field.Value)
-		columns = append(columns, fmt.Sprintf("%s %s", quoteIdent(field.Key), datatype))
+		columns = append(columns, fmt.Sprintf("%s %s", quoteIdent(mapping.columnName(field.Key)), datatype))
 	}
 
 	query := p.TableTemplate
-	query = strings.ReplaceAll(query, "{TABLE}", quoteIdent(metric.Name()))
-	query = strings.ReplaceAll(query, "{TABLELITERAL}", quoteStr(metric.Name()))
+	query = strings.ReplaceAll(query, "{TABLE}", quoteIdent(tablename))
+	query = strings.ReplaceAll(query, "{TABLELITERAL}", quoteStr(tablename))
 	query = strings.ReplaceAll(query, "{COLUMNS}", strings.Join(columns, ","))
 	query = strings.ReplaceAll(query, "{TAG_COLUMN_NAMES}", strings.Join(tagColumnNames, ","))
+	query = strings.ReplaceAll(query, "{KEY_COLUMN_NAMES}", strings.Join(keyColumnNames, ","))
 	query = strings.ReplaceAll(query, "{TIMESTAMP_COLUMN_NAME}", quoteIdent(p.TimestampColumn))
 
 	return query
@@ -239,32 +361,120 @@ func (p *SQL) generateAddColumn(tablename, column, columnType string) string {
 	return query
 }
 
-func (p *SQL) generateInsert(tablename string, columns []string) string {
-	placeholders := make([]string, 0, len(columns))
-	quotedColumns := make([]string, 0, len(columns))
-	for _, column := range columns {
+// generateInsert creates the statement for inserting the given number of
+// rows with the columns of r, applying the configured conflict handling.
+func (p *SQL) generateInsert(r *row, rows int) string {
+	quotedColumns := make([]string, 0, len(r.columns))
+	for _, column := range r.columns {
 		quotedColumns = append(quotedColumns, quoteIdent(column))
 	}
-	if p.Driver == "pgx" {
-		// Postgres uses $1 $2 $3 as placeholders
-		for i := 0; i < len(columns); i++ {
-			placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
-		}
-	} else {
-		// Everything else uses ? ? ? as placeholders
-		for i := 0; i < len(columns); i++ {
-			placeholders = append(placeholders, "?")
+	table := quoteIdent(r.table)
+	keys := quotedColumns[:r.keys]
+	fields := quotedColumns[r.keys:]
+
+	// Without key columns there is nothing to detect conflicts on
+	if p.ConflictHandling != "error" && len(keys) > 0 && (p.Driver == "mssql" || p.Driver == "snowflake") {
+		return p.generateMerge(table, quotedColumns, keys, fields, rows)
+	}
+
+	values := make([]string, 0, rows)
+	for i := range rows {
+		placeholders := make([]string, 0, len(r.columns))
+		if p.Driver == "pgx" {
+			// Postgres uses $1 $2 $3 as placeholders
+			for j := range len(r.columns) {
+				placeholders = append(placeholders, fmt.Sprintf("$%d", i*len(r.columns)+j+1))
+			}
+		} else {
+			// Everything else uses ? ? ? as placeholders
+			for range len(r.columns) {
+				placeholders = append(placeholders, "?")
+			}
 		}
+		values = append(values, "("+strings.Join(placeholders, ",")+")")
 	}
 
-	return fmt.Sprintf("INSERT INTO %s (%s) VALUES(%s)",
-		quoteIdent(tablename),
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES%s",
+		table,
 		strings.Join(quotedColumns, ","),
-		strings.Join(placeholders, ","))
+		strings.Join(values, ","))
+	if p.ConflictHandling == "error" || len(keys) == 0 {
+		return query
+	}
+
+	switch p.Driver {
+	case "mysql":
+		// Use a no-op update instead of 'INSERT IGNORE' for ignoring rows as
+		// the latter also suppresses errors not related to duplicates.
+		updates := []string{keys[0] + "=" + keys[0]}
+		if p.ConflictHandling == "update" && len(fields) > 0 {
+			updates = make([]string, 0, len(fields))
+			for _, column := range fields {
+				updates = append(updates, column+"=VALUES("+column+")")
+			}
+		}
+		return query + " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ",")
+	default:
+		query += " ON CONFLICT (" + strings.Join(keys, ",") + ") DO "
+		if p.ConflictHandling == "ignore" || len(fields) == 0 {
+			return query + "NOTHING"
+		}
+		updates := make([]string, 0, len(fields))
+		for _, column := range fields {
+			updates = append(updates, column+"=excluded."+column)
+		}
+		return query + "UPDATE SET " + strings.Join(updates, ",")
+	}
+}
+
+// generateMerge creates a MERGE statement for databases not supporting the
+// 'ON CONFLICT' clause. The source rows are selected from the placeholders.
+func (p *SQL) generateMerge(table string, columns, keys, fields []string, rows int) string {
+	selects := make([]string, 0, rows)
+	for i := range rows {
+		placeholders := make([]string, 0, len(columns))
+		for _, column := range columns {
+			if i == 0 {
+				placeholders = append(placeholders, "? AS "+column)
+			} else {
+				placeholders = append(placeholders, "?")
+			}
+		}
+		selects = append(selects, "SELECT "+strings.Join(placeholders, ","))
+	}
+
+	conditions := make([]string, 0, len(keys))
+	for _, column := range keys {
+		conditions = append(conditions, table+"."+column+"=src."+column)
+	}
+	sources := make([]string, 0, len(columns))
+	for _, column := range columns {
+		sources = append(sources, "src."+column)
+	}
+
+	query := fmt.Sprintf("MERGE INTO %s USING (%s) AS src ON (%s)",
+		table,
+		strings.Join(selects, " UNION ALL "),
+		strings.Join(conditions, " AND "))
+	if p.ConflictHandling == "update" && len(fields) > 0 {
+		updates := make([]string, 0, len(fields))
+		for _, column := range fields {
+			updates = append(updates, column+"=src."+column)
+		}
+		query += " WHEN MATCHED THEN UPDATE SET " + strings.Join(updates, ",")
+	}
+	query += fmt.Sprintf(" WHEN NOT MATCHED THEN INSERT (%s) VALUES(%s)",
+		strings.Join(columns, ","),
+		strings.Join(sources, ","))
+
+	// SQL Server requires MERGE statements to be terminated
+	if p.Driver == "mssql" {
+		query += ";"
+	}
+	return query
 }
 
-func (p *SQL) createTable(metric telegraf.Metric) error {
-	tablename := metric.Name()
+func (p *SQL) createTable(tablename string, metric telegraf.Metric) error {
 	stmt := p.generateCreateTable(metric)
 	if _, err := p.db.Exec(stmt); err != nil {
 		return fmt.Errorf("creating table failed: %w", err)
@@ -341,31 +551,37 @@ func (p *SQL) updateTableCache(tablename string) error {
 	return nil
 }
 
-func (p *SQL) processMetric(metric telegraf.Metric) (string, []string, []interface{}) {
+func (p *SQL) processMetric(metric telegraf.Metric) *row {
+	mapping := p.tableMapping(metric.Name())
+
 	// Preallocate the columns and values. Note we always allocate for the
 	// timestamp column even if we don't need it but that's not an issue.
 	entries := len(metric.TagList()) + len(metric.FieldList()) + 1
-	columns := make([]string, 0, entries)
-	values := make([]interface{}, 0, entries)
+	r := &row{
+		table:   mapping.tableName(metric.Name()),
+		columns: make([]string, 0, entries),
+		values:  make([]interface{}, 0, entries),
+	}
 	if p.TimestampColumn != "" {
-		columns = append(columns, p.TimestampColumn)
-		values = append(values, metric.Time())
+		r.columns = append(r.columns, p.TimestampColumn)
+		r.values = append(r.values, metric.Time())
 	}
 	// Tags are already sorted so we can add them without modification
 	for _, tag := range metric.TagList() {
-		columns = append(columns, tag.Key)
-		values = append(values, tag.Value)
+		r.columns = append(r.columns, mapping.columnName(tag.Key))
+		r.values = append(r.values, tag.Value)
 	}
+	r.keys = len(r.columns)
 	// Fields are not sorted so sort them
 	fields := slices.SortedFunc(
 		iterSlice(metric.FieldList()),
 		func(a, b *telegraf.Field) int { return cmp.Compare(a.Key, b.Key) },
 	)
 	for _, field := range fields {
-		columns = append(columns, field.Key)
-		values = append(values, field.Value)
+		r.columns = append(r.columns, mapping.columnName(field.Key))
+		r.values = append(r.values, field.Value)
 	}
-	return strings.Join(append([]string{metric.Name()}, columns...), "\n"), columns, values
+	return r
 }
 
 func (p *SQL) sendIndividual(sql string, values []interface{}) error {
@@ -430,34 +646,46 @@ func (p *SQL) sendBatch(sql string, values [][]interface{}) error {
 
 func (p *SQL) Write(metrics []telegraf.Metric) error {
 	batchedQueries := make(map[string][][]interface{})
+	var groups [][]*row
+	groupIndex := make(map[string]int)
 
 	for _, metric := range metrics {
-		tablename := metric.Name()
+		r := p.processMetric(metric)
 		// create table if needed
-		if _, found := p.tables[tablename]; !found && !p.tableExists(tablename) {
-			if err := p.createTable(metric); err != nil {
+		if _, found := p.tables[r.table]; !found && !p.tableExists(r.table) {
+			if err := p.createTable(r.table, metric); err != nil {
 				return err
 			}
 		}
-		cacheKey, columns, values := p.processMetric(metric)
-		sql, found := p.queryCache[cacheKey]
-		if !found {
-			sql = p.generateInsert(tablename, columns)
-			p.queryCache[cacheKey] = sql
-		}
 		// Modifying the table schema is opt-in
 		if p.TableUpdateTemplate != "" {
-			for i := range len(columns) {
-				if err := p.createColumn(tablename, columns[i], p.deriveDatatype(values[i])); err != nil {
+			for i := range len(r.columns) {
+				if err := p.createColumn(r.table, r.columns[i], p.deriveDatatype(r.values[i])); err != nil {
 					return err
 				}
 			}
 		}
+
+		// Rows with the same table and columns are grouped for the multirow
+		// and bulk insert strategies
+		if p.InsertStrategy != "individual" {
+			key := r.cacheKey()
+			i, found := groupIndex[key]
+			if !found {
+				i = len(groups)
+				groupIndex[key] = i
+				groups = append(groups, nil)
+			}
+			groups[i] = append(groups[i], r)
+			continue
+		}
+
+		sql := p.insertQuery(r, 1)
 		// Using BatchTx is opt-in
 		if p.BatchTx {
-			batchedQueries[sql] = append(batchedQueries[sql], values)
+			batchedQueries[sql] = append(batchedQueries[sql], r.values)
 		} else {
-			if err := p.sendIndividual(sql, values); err != nil {
+			if err := p.sendIndividual(sql, r.values); err != nil {
 				return err
 			}
 		}
@@ -471,9 +699,34 @@ func (p *SQL) Write(metrics []telegraf.Metric) error {
 		}
 	}
 
+	for _, rows := range groups {
+		var err error
+		switch p.InsertStrategy {
+		case "multirow":
+			err = p.sendMultiRow(rows)
+		case "bulk":
+			err = p.sendBulk(rows)
+		}
+		if err != nil {
+			return fmt.Errorf("inserting into table %q failed: %w", rows[0].table, err)
+		}
+	}
+
 	return nil
 }
 
+// insertQuery returns the cached insert statement for the given number of
+// rows with the columns of r.
+func (p *SQL) insertQuery(r *row, rows int) string {
+	cacheKey := strconv.Itoa(rows) + "\n" + r.cacheKey()
+	sql, found := p.queryCache[cacheKey]
+	if !found {
+		sql = p.generateInsert(r, rows)
+		p.queryCache[cacheKey] = sql
+	}
+	return sql
+}
+
 // Convert a DSN possibly using v1 parameters to clickhouse-go v2 format
 func (p *SQL) convertClickHouseDsn() {
 	dsnBuffer, err := p.DataSourceName.Get()
@@ -1100,3 +1100,193 @@ func TestTimestampOnUpdateSchema(t *testing.T) {
 	results := p.deriveDatatype(ts)
 	require.Equal(t, expected, results)
 }
+
+func TestGenerateInsertConflictHandling(t *testing.T) {
+	r := &row{
+		table:   "metric",
+		columns: []string{"timestamp", "host", "value"},
+		keys:    2,
+	}
+
+	tests := []struct {
+		name     string
+		driver   string
+		conflict string
+		rows     int
+		expected string
+	}{
+		{
+			name:     "pgx error",
+			driver:   "pgx",
+			conflict: "error",
+			rows:     2,
+			expected: `INSERT INTO "metric" ("timestamp","host","value") VALUES($1,$2,$3),($4,$5,$6)`,
+		},
+		{
+			name:     "pgx ignore",
+			driver:   "pgx",
+			conflict: "ignore",
+			rows:     1,
+			expected: `INSERT INTO "metric" ("timestamp","host","value") VALUES($1,$2,$3) ON CONFLICT ("timestamp","host") DO NOTHING`,
+		},
+		{
+			name:     "sqlite update",
+			driver:   "sqlite",
+			conflict: "update",
+			rows:     1,
+			expected: `INSERT INTO "metric" ("timestamp","host","value") VALUES(?,?,?) ` +
+				`ON CONFLICT ("timestamp","host") DO UPDATE SET "value"=excluded."value"`,
+		},
+		{
+			name:     "mysql ignore",
+			driver:   "mysql",
+			conflict: "ignore",
+			rows:     1,
+			expected: `INSERT INTO "metric" ("timestamp","host","value") VALUES(?,?,?) ON DUPLICATE KEY UPDATE "timestamp"="timestamp"`,
+		},
+		{
+			name:     "mysql update",
+			driver:   "mysql",
+			conflict: "update",
+			rows:     2,
+			expected: `INSERT INTO "metric" ("timestamp","host","value") VALUES(?,?,?),(?,?,?) ON DUPLICATE KEY UPDATE "value"=VALUES("value")`,
+		},
+		{
+			name:     "mssql update",
+			driver:   "mssql",
+			conflict: "update",
+			rows:     2,
+			expected: `MERGE INTO "metric" USING (SELECT ? AS "timestamp",? AS "host",? AS "value" UNION ALL SELECT ?,?,?) AS src ` +
+				`ON ("metric"."timestamp"=src."timestamp" AND "metric"."host"=src."host") ` +
+				`WHEN MATCHED THEN UPDATE SET "value"=src."value" ` +
+				`WHEN NOT MATCHED THEN INSERT ("timestamp","host","value") VALUES(src."timestamp",src."host",src."value");`,
+		},
+		{
+			name:     "snowflake ignore",
+			driver:   "snowflake",
+			conflict: "ignore",
+			rows:     1,
+			expected: `MERGE INTO "metric" USING (SELECT ? AS "timestamp",? AS "host",? AS "value") AS src ` +
+				`ON ("metric"."timestamp"=src."timestamp" AND "metric"."host"=src."host") ` +
+				`WHEN NOT MATCHED THEN INSERT ("timestamp","host","value") VALUES(src."timestamp",src."host",src."value")`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &SQL{
+				Driver:           tt.driver,
+				ConflictHandling: tt.conflict,
+			}
+			require.Equal(t, tt.expected, p.generateInsert(r, tt.rows))
+		})
+	}
+}
+
+func TestRowsPerStatement(t *testing.T) {
+	tests := []struct {
+		driver   string
+		columns  int
+		expected int
+	}{
+		{driver: "mssql", columns: 2, expected: 1000},
+		{driver: "mssql", columns: 10, expected: 210},
+		{driver: "mssql", columns: 3000, expected: 1},
+		{driver: "sqlite", columns: 2, expected: 16383},
+		{driver: "pgx", columns: 2, expected: 32767},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s with %d columns", tt.driver, tt.columns), func(t *testing.T) {
+			p := &SQL{Driver: tt.driver}
+			require.Equal(t, tt.expected, p.rowsPerStatement(tt.columns))
+		})
+	}
+}
+
+func TestInvalidInsertOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *SQL
+		expected string
+	}{
+		{
+			name:     "unknown conflict handling",
+			plugin:   &SQL{Driver: "pgx", ConflictHandling: "replace"},
+			expected: `invalid 'conflict_handling' "replace"`,
+		},
+		{
+			name: "conflict handling with clickhouse",
+			plugin: &SQL{
+				Driver:           "clickhouse",
+				DataSourceName:   config.NewSecret([]byte("tcp://localhost:9000")),
+				ConflictHandling: "update",
+			},
+			expected: "conflict handling is not supported by clickhouse",
+		},
+		{
+			name:     "unknown insert strategy",
+			plugin:   &SQL{Driver: "pgx", InsertStrategy: "fast"},
+			expected: `invalid 'insert_strategy' "fast"`,
+		},
+		{
+			name:     "bulk with unsupported driver",
+			plugin:   &SQL{Driver: "mysql", InsertStrategy: "bulk"},
+			expected: `insert strategy 'bulk' is not supported by driver "mysql"`,
+		},
+		{
+			name:     "bulk with conflict handling",
+			plugin:   &SQL{Driver: "pgx", InsertStrategy: "bulk", ConflictHandling: "ignore"},
+			expected: "conflict handling is not supported with insert strategy 'bulk'",
+		},
+		{
+			name: "mapping without measurement",
+			plugin: &SQL{
+				Driver:        "pgx",
+				TableMappings: []*TableMapping{{Table: "foo"}},
+			},
+			expected: "'measurement' missing in table mapping 1",
+		},
+		{
+			name: "mapping with empty column",
+			plugin: &SQL{
+				Driver: "pgx",
+				TableMappings: []*TableMapping{{
+					Measurement: []string{"cpu"},
+					Columns:     map[string]string{"usage_idle": ""},
+				}},
+			},
+			expected: `empty column name for "usage_idle" in table mapping 1`,
+		},
+		{
+			name: "mapping with duplicate column",
+			plugin: &SQL{
+				Driver: "pgx",
+				TableMappings: []*TableMapping{{
+					Measurement: []string{"cpu"},
+					Columns:     map[string]string{"usage_idle": "idle", "idle_time": "idle"},
+				}},
+			},
+			expected: `"idle_time" and "usage_idle" map to the same column "idle" in table mapping 1`,
+		},
+		{
+			name: "mapping to timestamp column",
+			plugin: &SQL{
+				Driver:          "pgx",
+				TimestampColumn: "timestamp",
+				TableMappings: []*TableMapping{{
+					Measurement: []string{"cpu"},
+					Columns:     map[string]string{"time": "timestamp"},
+				}},
+			},
+			expected: `"time" maps to the timestamp column "timestamp" in table mapping 1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -300,3 +301,120 @@ func TestSqliteSendBatch(t *testing.T) {
 	require.Equal(t, "string2", k)
 	require.False(t, rows4.Next())
 }
+
+func TestSqliteConflictHandling(t *testing.T) {
+	tests := []struct {
+		name     string
+		conflict string
+		expected int64
+	}{
+		{
+			name:     "ignore",
+			conflict: "ignore",
+			expected: 1,
+		},
+		{
+			name:     "update",
+			conflict: "update",
+			expected: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbfile := filepath.Join(t.TempDir(), "db")
+
+			p := &SQL{
+				Driver:            "sqlite",
+				DataSourceName:    config.NewSecret([]byte(dbfile)),
+				Convert:           defaultConvert,
+				TimestampColumn:   "timestamp",
+				TableTemplate:     "CREATE TABLE {TABLE}({COLUMNS}, UNIQUE({KEY_COLUMN_NAMES}))",
+				ConflictHandling:  tt.conflict,
+				InsertStrategy:    "multirow",
+				ConnectionMaxIdle: 2,
+				Log:               testutil.Logger{},
+			}
+			require.NoError(t, p.Init())
+			require.NoError(t, p.Connect())
+			defer p.Close()
+
+			first := testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(1)}, ts)
+			other := testutil.MustMetric("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": int64(1)}, ts)
+			second := testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(2)}, ts)
+			require.NoError(t, p.Write([]telegraf.Metric{first, other}))
+			require.NoError(t, p.Write([]telegraf.Metric{second}))
+
+			db, err := gosql.Open("sqlite", dbfile)
+			require.NoError(t, err)
+			defer db.Close()
+
+			var count int
+			require.NoError(t, db.QueryRow("select count(*) from cpu").Scan(&count))
+			require.Equal(t, 2, count)
+
+			var value int64
+			require.NoError(t, db.QueryRow("select value from cpu where host = 'a'").Scan(&value))
+			require.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestSqliteTableMapping(t *testing.T) {
+	dbfile := filepath.Join(t.TempDir(), "db")
+
+	p := &SQL{
+		Driver:          "sqlite",
+		DataSourceName:  config.NewSecret([]byte(dbfile)),
+		Convert:         defaultConvert,
+		TimestampColumn: "timestamp",
+		InsertStrategy:  "multirow",
+		TableMappings: []*TableMapping{
+			{
+				Measurement: []string{"metric_*"},
+				Table:       "metrics",
+				Columns:     map[string]string{"tag_one": "source"},
+			},
+		},
+		ConnectionMaxIdle: 2,
+		Log:               testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Connect())
+	defer p.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("metric_one", map[string]string{"tag_one": "a"}, map[string]interface{}{"value": int64(1)}, ts),
+		testutil.MustMetric("metric_two", map[string]string{"source": "b"}, map[string]interface{}{"value": int64(2)}, ts),
+		testutil.MustMetric("metric three", map[string]string{"tag four": "c"}, map[string]interface{}{"value": int64(3)}, ts),
+	}
+	require.NoError(t, p.Write(metrics))
+
+	db, err := gosql.Open("sqlite", dbfile)
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows, err := db.Query("select sql from sqlite_master")
+	require.NoError(t, err)
+	defer rows.Close()
+	var sql string
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Scan(&sql))
+	require.Equal(t, `CREATE TABLE "metrics"("timestamp" TIMESTAMP,"source" TEXT,"value" INT)`, sql)
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Scan(&sql))
+	require.Equal(t, `CREATE TABLE "metric three"("timestamp" TIMESTAMP,"tag four" TEXT,"value" INT)`, sql)
+	require.False(t, rows.Next())
+
+	var sources []string
+	result, err := db.Query("select source from metrics order by value")
+	require.NoError(t, err)
+	defer result.Close()
+	for result.Next() {
+		var source string
+		require.NoError(t, result.Scan(&source))
+		sources = append(sources, source)
+	}
+	require.NoError(t, result.Err())
+	require.Equal(t, []string{"a", "b"}, sources)
+}
@@ -0,0 +1,453 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package timescaledb
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type TimescaleDB struct {
+	Connection           config.Secret   `toml:"connection"`
+	Schema               string          `toml:"schema"`
+	TimeColumn           string          `toml:"time_column"`
+	ChunkTimeInterval    config.Duration `toml:"chunk_time_interval"`
+	PartitionTag         string          `toml:"partition_tag"`
+	PartitionCount       int             `toml:"partition_count"`
+	CompressAfter        config.Duration `toml:"compress_after"`
+	CompressionSegmentBy []string        `toml:"compression_segment_by"`
+	Timeout              config.Duration `toml:"timeout"`
+	Log                  telegraf.Logger `toml:"-"`
+
+	dbConfig *pgxpool.Config
+	db       *pgxpool.Pool
+	tables   map[string]map[string]string
+}
+
+func (*TimescaleDB) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *TimescaleDB) Init() error {
+	if t.Schema == "" {
+		t.Schema = "public"
+	}
+	if t.TimeColumn == "" {
+		t.TimeColumn = "time"
+	}
+	if t.ChunkTimeInterval <= 0 {
+		return errors.New("chunk_time_interval must be positive")
+	}
+	if t.CompressAfter < 0 {
+		return errors.New("compress_after must not be negative")
+	}
+	if t.PartitionTag != "" && t.PartitionCount < 1 {
+		return fmt.Errorf("invalid partition_count %d", t.PartitionCount)
+	}
+	if len(t.CompressionSegmentBy) > 0 && t.CompressAfter == 0 {
+		t.Log.Warn("Ignoring 'compression_segment_by' as compression is disabled")
+	}
+
+	connection, err := t.Connection.Get()
+	if err != nil {
+		return fmt.Errorf("getting connection failed: %w", err)
+	}
+	defer connection.Destroy()
+
+	t.dbConfig, err = pgxpool.ParseConfig(connection.String())
+	if err != nil {
+		return fmt.Errorf("parsing connection failed: %w", err)
+	}
+	if _, ok := t.dbConfig.ConnConfig.RuntimeParams["application_name"]; !ok {
+		t.dbConfig.ConnConfig.RuntimeParams["application_name"] = "telegraf"
+	}
+
+	return nil
+}
+
+func (t *TimescaleDB) Connect() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(t.Timeout))
+	defer cancel()
+
+	db, err := pgxpool.ConnectConfig(ctx, t.dbConfig)
+	if err != nil {
+		return &internal.StartupError{
+			Err:   err,
+			Retry: true,
+		}
+	}
+	t.db = db
+	t.tables = make(map[string]map[string]string)
+
+	return nil
+}
+
+func (t *TimescaleDB) Close() error {
+	if t.db != nil {
+		t.db.Close()
+	}
+	return nil
+}
+
+func (t *TimescaleDB) Write(metrics []telegraf.Metric) error {
+	// Group the metrics by table keeping the batch indices
+	batches := make(map[string][]int)
+	order := make([]string, 0)
+	for i, m := range metrics {
+		if _, found := batches[m.Name()]; !found {
+			order = append(order, m.Name())
+		}
+		batches[m.Name()] = append(batches[m.Name()], i)
+	}
+
+	var accepted, rejected []int
+	for _, name := range order {
+		indices := batches[name]
+		batch := make([]telegraf.Metric, 0, len(indices))
+		for _, idx := range indices {
+			batch = append(batch, metrics[idx])
+		}
+
+		if err := t.writeTable(name, batch); err != nil {
+			if !isPermanentError(err) {
+				if len(accepted) == 0 && len(rejected) == 0 {
+					return err
+				}
+				return &internal.PartialWriteError{
+					Err:           err,
+					MetricsAccept: accepted,
+					MetricsReject: rejected,
+				}
+			}
+			t.Log.Errorf("Writing to table %q failed, dropping %d metrics: %v", name, len(batch), err)
+			rejected = append(rejected, indices...)
+			continue
+		}
+		accepted = append(accepted, indices...)
+	}
+
+	if len(rejected) > 0 {
+		return &internal.PartialWriteError{
+			Err:           fmt.Errorf("dropped %d metrics", len(rejected)),
+			MetricsAccept: accepted,
+			MetricsReject: rejected,
+		}
+	}
+	return nil
+}
+
+func (t *TimescaleDB) writeTable(name string, metrics []telegraf.Metric) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(t.Timeout))
+	defer cancel()
+
+	// Collect the columns required by the metrics
+	required := make(map[string]string)
+	tagColumns := make([]string, 0)
+	fieldColumns := make([]string, 0)
+	if t.PartitionTag != "" {
+		required[t.PartitionTag] = "text"
+		tagColumns = append(tagColumns, t.PartitionTag)
+	}
+	for _, m := range metrics {
+		for _, tag := range m.TagList() {
+			if _, found := required[tag.Key]; !found {
+				required[tag.Key] = "text"
+				tagColumns = append(tagColumns, tag.Key)
+			}
+		}
+		for _, field := range m.FieldList() {
+			if _, found := required[field.Key]; found {
+				continue
+			}
+			dt, ok := columnType(field.Value)
+			if !ok {
+				continue
+			}
+			required[field.Key] = dt
+			fieldColumns = append(fieldColumns, field.Key)
+		}
+	}
+	if _, found := required[t.TimeColumn]; found {
+		return &permanentError{fmt.Errorf("tag or field conflicts with time column %q", t.TimeColumn)}
+	}
+
+	if err := t.ensureTable(ctx, name, required); err != nil {
+		return err
+	}
+
+	// Tags are always text while fields are converted to the type of existing
+	// columns
+	existing := t.tables[name]
+	columns := make([]string, 0, len(required)+1)
+	columns = append(columns, t.TimeColumn)
+	columns = append(columns, tagColumns...)
+	columns = append(columns, fieldColumns...)
+
+	rows := make([][]interface{}, 0, len(metrics))
+	for _, m := range metrics {
+		row := make([]interface{}, len(columns))
+		row[0] = m.Time().UTC()
+		for i, column := range columns[1:] {
+			if i < len(tagColumns) {
+				if v, found := m.GetTag(column); found {
+					row[i+1] = v
+				}
+				continue
+			}
+			if v, found := m.GetField(column); found {
+				cv, err := convert(v, existing[column])
+				if err != nil {
+					t.Log.Debugf("Dropping field %q of metric %q: %v", column, name, err)
+					continue
+				}
+				row[i+1] = cv
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	// CopyFrom uses the binary COPY protocol
+	_, err := t.db.CopyFrom(ctx, pgx.Identifier{t.Schema, name}, columns, pgx.CopyFromRows(rows))
+	if err != nil {
+		// Force a reload of the table structure as the table might have been
+		// changed externally
+		delete(t.tables, name)
+		return fmt.Errorf("copying metrics failed: %w", err)
+	}
+	return nil
+}
+
+// ensureTable creates the hypertable if it does not exist and adds missing
+// columns to existing tables
+func (t *TimescaleDB) ensureTable(ctx context.Context, name string, required map[string]string) error {
+	columns, found := t.tables[name]
+	if !found {
+		var err error
+		columns, err = t.queryColumns(ctx, name)
+		if err != nil {
+			return fmt.Errorf("querying columns of table %q failed: %w", name, err)
+		}
+	}
+
+	// Create the hypertable with all required columns
+	if len(columns) == 0 {
+		for _, stmt := range t.createTableStatements(name, required) {
+			if _, err := t.db.Exec(ctx, stmt.sql, stmt.args...); err != nil {
+				return fmt.Errorf("creating hypertable %q failed: %w", name, err)
+			}
+		}
+		columns = make(map[string]string, len(required)+1)
+		columns[t.TimeColumn] = "timestamp with time zone"
+		for column, dt := range required {
+			columns[column] = dt
+		}
+		t.tables[name] = columns
+		t.Log.Debugf("Created hypertable %q", name)
+		return nil
+	}
+	t.tables[name] = columns
+
+	// Add missing columns
+	missing := make([]string, 0)
+	for column := range required {
+		if _, found := columns[column]; !found {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	slices.Sort(missing)
+
+	table := pgx.Identifier{t.Schema, name}.Sanitize()
+	clauses := make([]string, 0, len(missing))
+	for _, column := range missing {
+		clauses = append(clauses, "ADD COLUMN IF NOT EXISTS "+pgx.Identifier{column}.Sanitize()+" "+required[column])
+	}
+	if _, err := t.db.Exec(ctx, "ALTER TABLE "+table+" "+strings.Join(clauses, ", ")); err != nil {
+		return fmt.Errorf("adding columns to table %q failed: %w", name, err)
+	}
+	for _, column := range missing {
+		columns[column] = required[column]
+	}
+	t.Log.Debugf("Added columns %s to hypertable %q", strings.Join(missing, ", "), name)
+
+	return nil
+}
+
+func (t *TimescaleDB) queryColumns(ctx context.Context, name string) (map[string]string, error) {
+	rows, err := t.db.Query(ctx,
+		"SELECT column_name, data_type FROM information_schema.columns WHERE table_schema = $1 AND table_name = $2",
+		t.Schema, name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]string)
+	for rows.Next() {
+		var column, dt string
+		if err := rows.Scan(&column, &dt); err != nil {
+			return nil, err
+		}
+		columns[column] = dt
+	}
+	return columns, rows.Err()
+}
+
+type statement struct {
+	sql  string
+	args []interface{}
+}
+
+// createTableStatements returns the statements for creating the table,
+// converting it to a hypertable and setting up compression
+func (t *TimescaleDB) createTableStatements(name string, required map[string]string) []statement {
+	table := pgx.Identifier{t.Schema, name}.Sanitize()
+
+	names := make([]string, 0, len(required))
+	for column := range required {
+		names = append(names, column)
+	}
+	slices.Sort(names)
+
+	definitions := make([]string, 0, len(required)+1)
+	definitions = append(definitions, pgx.Identifier{t.TimeColumn}.Sanitize()+" timestamp with time zone NOT NULL")
+	for _, column := range names {
+		definitions = append(definitions, pgx.Identifier{column}.Sanitize()+" "+required[column])
+	}
+
+	stmts := []statement{{sql: "CREATE TABLE IF NOT EXISTS " + table + " (" + strings.Join(definitions, ", ") + ")"}}
+
+	if t.PartitionTag != "" {
+		stmts = append(stmts, statement{
+			sql: "SELECT create_hypertable($1::text::regclass, $2::name, partitioning_column => $3::name, " +
+				"number_partitions => $4::integer, chunk_time_interval => $5::interval, if_not_exists => TRUE)",
+			args: []interface{}{table, t.TimeColumn, t.PartitionTag, t.PartitionCount, time.Duration(t.ChunkTimeInterval)},
+		})
+	} else {
+		stmts = append(stmts, statement{
+			sql:  "SELECT create_hypertable($1::text::regclass, $2::name, chunk_time_interval => $3::interval, if_not_exists => TRUE)",
+			args: []interface{}{table, t.TimeColumn, time.Duration(t.ChunkTimeInterval)},
+		})
+	}
+
+	if t.CompressAfter > 0 {
+		settings := "timescaledb.compress, timescaledb.compress_orderby = " +
+			quoteLiteral(pgx.Identifier{t.TimeColumn}.Sanitize()+" DESC")
+		if len(t.CompressionSegmentBy) > 0 {
+			segmentBy := make([]string, 0, len(t.CompressionSegmentBy))
+			for _, column := range t.CompressionSegmentBy {
+				segmentBy = append(segmentBy, pgx.Identifier{column}.Sanitize())
+			}
+			settings += ", timescaledb.compress_segmentby = " + quoteLiteral(strings.Join(segmentBy, ", "))
+		}
+		stmts = append(stmts,
+			statement{sql: "ALTER TABLE " + table + " SET (" + settings + ")"},
+			statement{
+				sql:  "SELECT add_compression_policy($1::text::regclass, $2::interval, if_not_exists => TRUE)",
+				args: []interface{}{table, time.Duration(t.CompressAfter)},
+			},
+		)
+	}
+
+	return stmts
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// columnType returns the PostgreSQL column type for the given field value
+func columnType(value interface{}) (string, bool) {
+	switch value.(type) {
+	case float64:
+		return "double precision", true
+	case int64:
+		return "bigint", true
+	case uint64:
+		return "numeric", true
+	case bool:
+		return "boolean", true
+	case string:
+		return "text", true
+	}
+	return "", false
+}
+
+// convert the field value to the given column type
+func convert(value interface{}, dt string) (interface{}, error) {
+	switch dt {
+	case "double precision", "real":
+		return internal.ToFloat64(value)
+	case "bigint", "integer", "smallint":
+		return internal.ToInt64(value)
+	case "numeric":
+		if _, ok := value.(string); ok {
+			return internal.ToFloat64(value)
+		}
+		return value, nil
+	case "boolean":
+		return internal.ToBool(value)
+	case "text", "character varying":
+		return internal.ToString(value)
+	}
+	return value, nil
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// isPermanentError checks if retrying the write cannot succeed, e.g. due to
+// invalid data or a schema mismatch
+func isPermanentError(err error) bool {
+	var perr *permanentError
+	if errors.As(err, &perr) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	// Data exceptions (22) and integrity constraint violations (23)
+	return strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")
+}
+
+func init() {
+	outputs.Add("timescaledb", func() telegraf.Output {
+		return &TimescaleDB{
+			Schema:            "public",
+			TimeColumn:        "time",
+			ChunkTimeInterval: config.Duration(7 * 24 * time.Hour),
+			PartitionCount:    4,
+			Timeout:           config.Duration(5 * time.Second),
+		}
+	})
+}
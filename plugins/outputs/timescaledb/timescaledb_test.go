@@ -0,0 +1,218 @@
+package timescaledb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newPlugin() *TimescaleDB {
+	plugin := outputs.Outputs["timescaledb"]().(*TimescaleDB)
+	plugin.Log = testutil.Logger{}
+	return plugin
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*TimescaleDB)
+		expected string
+	}{
+		{
+			name:     "zero chunk interval",
+			modify:   func(p *TimescaleDB) { p.ChunkTimeInterval = 0 },
+			expected: "chunk_time_interval must be positive",
+		},
+		{
+			name:     "negative compression age",
+			modify:   func(p *TimescaleDB) { p.CompressAfter = config.Duration(-time.Hour) },
+			expected: "compress_after must not be negative",
+		},
+		{
+			name: "invalid partition count",
+			modify: func(p *TimescaleDB) {
+				p.PartitionTag = "host"
+				p.PartitionCount = 0
+			},
+			expected: "invalid partition_count 0",
+		},
+		{
+			name:     "invalid connection",
+			modify:   func(p *TimescaleDB) { p.Connection = config.NewSecret([]byte("host=localhost port=abc")) },
+			expected: "parsing connection failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newPlugin()
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestCreateTableStatements(t *testing.T) {
+	plugin := newPlugin()
+	plugin.PartitionTag = "host"
+	plugin.CompressAfter = config.Duration(24 * time.Hour)
+	plugin.CompressionSegmentBy = []string{"host", "cpu"}
+	require.NoError(t, plugin.Init())
+
+	stmts := plugin.createTableStatements("cpu", map[string]string{
+		"host":  "text",
+		"cpu":   "text",
+		"usage": "double precision",
+	})
+	require.Len(t, stmts, 4)
+
+	require.Equal(t,
+		`CREATE TABLE IF NOT EXISTS "public"."cpu" ("time" timestamp with time zone NOT NULL, "cpu" text, "host" text, "usage" double precision)`,
+		stmts[0].sql,
+	)
+	require.Contains(t, stmts[1].sql, "partitioning_column => $3::name")
+	require.Equal(t, []interface{}{`"public"."cpu"`, "time", "host", 4, 7 * 24 * time.Hour}, stmts[1].args)
+	require.Equal(t,
+		`ALTER TABLE "public"."cpu" SET (timescaledb.compress, timescaledb.compress_orderby = '"time" DESC', `+
+			`timescaledb.compress_segmentby = '"host", "cpu"')`,
+		stmts[2].sql,
+	)
+	require.Equal(t, []interface{}{`"public"."cpu"`, 24 * time.Hour}, stmts[3].args)
+
+	// Without partitioning and compression
+	plugin = newPlugin()
+	require.NoError(t, plugin.Init())
+	stmts = plugin.createTableStatements("mem", map[string]string{"used": "bigint"})
+	require.Len(t, stmts, 2)
+	require.NotContains(t, stmts[1].sql, "partitioning_column")
+	require.Equal(t, []interface{}{`"public"."mem"`, "time", 7 * 24 * time.Hour}, stmts[1].args)
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		dt       string
+		expected interface{}
+		err      bool
+	}{
+		{value: int64(42), dt: "double precision", expected: float64(42)},
+		{value: 3.7, dt: "bigint", expected: int64(3)},
+		{value: "23", dt: "bigint", expected: int64(23)},
+		{value: "foo", dt: "bigint", err: true},
+		{value: true, dt: "text", expected: "true"},
+		{value: uint64(5), dt: "numeric", expected: uint64(5)},
+		{value: "on", dt: "boolean", err: true},
+		{value: "true", dt: "boolean", expected: true},
+		{value: 1.5, dt: "", expected: 1.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v to %s", tt.value, tt.dt), func(t *testing.T) {
+			actual, err := convert(tt.value, tt.dt)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestIsPermanentError(t *testing.T) {
+	require.False(t, isPermanentError(errors.New("connection refused")))
+	require.False(t, isPermanentError(&pgconn.PgError{Code: "57P01"}))
+	require.True(t, isPermanentError(fmt.Errorf("copying failed: %w", &pgconn.PgError{Code: "22P02"})))
+	require.True(t, isPermanentError(&permanentError{errors.New("conflict")}))
+}
+
+func TestWriteIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	servicePort := "5432"
+	container := testutil.Container{
+		Image:        "timescale/timescaledb:latest-pg16",
+		ExposedPorts: []string{servicePort},
+		Env: map[string]string{
+			"POSTGRES_PASSWORD": "postgres",
+		},
+		WaitingFor: wait.ForAll(
+			wait.ForListeningPort(nat.Port(servicePort)),
+			wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+		),
+	}
+	require.NoError(t, container.Start(), "failed to start container")
+	defer container.Terminate()
+
+	plugin := newPlugin()
+	plugin.Connection = config.NewSecret([]byte(fmt.Sprintf(
+		"host=%s port=%s user=postgres password=postgres sslmode=disable",
+		container.Address, container.Ports[servicePort],
+	)))
+	plugin.PartitionTag = "host"
+	plugin.CompressAfter = config.Duration(24 * time.Hour)
+	plugin.CompressionSegmentBy = []string{"host"}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	ctx := context.Background()
+	_, err := plugin.db.Exec(ctx, "CREATE EXTENSION IF NOT EXISTS timescaledb")
+	require.NoError(t, err)
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 42.5}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "b"}, map[string]interface{}{"usage": 23.0}, time.Unix(10, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// New columns must be added to the existing hypertable
+	metrics = []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "a", "core": "0"}, map[string]interface{}{"usage": int64(7), "idle": true}, time.Unix(20, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	var hypertables int
+	row := plugin.db.QueryRow(ctx, "SELECT count(*) FROM timescaledb_information.hypertables WHERE hypertable_name = 'cpu'")
+	require.NoError(t, row.Scan(&hypertables))
+	require.Equal(t, 1, hypertables)
+
+	var dimensions int
+	row = plugin.db.QueryRow(ctx, "SELECT count(*) FROM timescaledb_information.dimensions WHERE hypertable_name = 'cpu'")
+	require.NoError(t, row.Scan(&dimensions))
+	require.Equal(t, 2, dimensions)
+
+	var policies int
+	row = plugin.db.QueryRow(ctx, "SELECT count(*) FROM timescaledb_information.jobs WHERE proc_name = 'policy_compression' AND hypertable_name = 'cpu'")
+	require.NoError(t, row.Scan(&policies))
+	require.Equal(t, 1, policies)
+
+	rows, err := plugin.db.Query(ctx, `SELECT host, core, usage, idle FROM cpu ORDER BY time`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var actual []string
+	for rows.Next() {
+		var host string
+		var core *string
+		var usage float64
+		var idle *bool
+		require.NoError(t, rows.Scan(&host, &core, &usage, &idle))
+		actual = append(actual, fmt.Sprintf("%s %v %v %v", host, core != nil, usage, idle != nil && *idle))
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []string{"a false 42.5 false", "b false 23 false", "a true 7 true"}, actual)
+}
@@ -0,0 +1,307 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package victoriametrics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type VictoriaMetrics struct {
+	URLs            []string          `toml:"urls"`
+	Username        config.Secret     `toml:"username"`
+	Password        config.Secret     `toml:"password"`
+	Token           config.Secret     `toml:"token"`
+	ContentEncoding string            `toml:"content_encoding"`
+	ExtraLabels     map[string]string `toml:"extra_labels"`
+	Log             telegraf.Logger   `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client  *http.Client
+	encoder internal.ContentEncoder
+	targets []string
+	next    int
+}
+
+// series is a line of the JSON line import format holding all samples of a
+// series within a write
+type series struct {
+	Metric     map[string]string `json:"metric"`
+	Values     []float64         `json:"values"`
+	Timestamps []int64           `json:"timestamps"`
+}
+
+func (*VictoriaMetrics) SampleConfig() string {
+	return sampleConfig
+}
+
+func (v *VictoriaMetrics) Init() error {
+	if len(v.URLs) == 0 {
+		return errors.New("no URL specified")
+	}
+	if !v.Token.Empty() && (!v.Username.Empty() || !v.Password.Empty()) {
+		return errors.New("either use 'token' or 'username' and 'password'")
+	}
+
+	switch v.ContentEncoding {
+	case "", "identity", "gzip", "zstd":
+	default:
+		return fmt.Errorf("invalid content encoding %q", v.ContentEncoding)
+	}
+	encoder, err := internal.NewContentEncoder(v.ContentEncoding)
+	if err != nil {
+		return fmt.Errorf("creating encoder failed: %w", err)
+	}
+	v.encoder = encoder
+
+	// Extra labels are added by the server using query parameters
+	params := url.Values{}
+	for _, k := range slices.Sorted(maps.Keys(v.ExtraLabels)) {
+		params.Add("extra_label", k+"="+v.ExtraLabels[k])
+	}
+
+	v.targets = make([]string, 0, len(v.URLs))
+	for _, raw := range v.URLs {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("parsing URL %q failed: %w", raw, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("invalid scheme in URL %q", raw)
+		}
+		u = u.JoinPath("api", "v1", "import")
+		u.RawQuery = params.Encode()
+		v.targets = append(v.targets, u.String())
+	}
+
+	return nil
+}
+
+func (v *VictoriaMetrics) Connect() error {
+	client, err := v.HTTPClientConfig.CreateClient(context.Background(), v.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	v.client = client
+
+	return nil
+}
+
+func (v *VictoriaMetrics) Close() error {
+	if v.client != nil {
+		v.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+func (v *VictoriaMetrics) Write(metrics []telegraf.Metric) error {
+	body, err := v.serialize(metrics)
+	if err != nil {
+		return fmt.Errorf("serializing metrics failed: %w", err)
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	body, err = v.encoder.Encode(body)
+	if err != nil {
+		return fmt.Errorf("encoding body failed: %w", err)
+	}
+
+	// Distribute the writes across the nodes and try the remaining nodes in
+	// order if a node fails
+	errs := make([]error, 0, len(v.targets))
+	for i := range v.targets {
+		idx := (v.next + i) % len(v.targets)
+		err := v.send(v.targets[idx], body)
+		if err == nil {
+			v.next = (idx + 1) % len(v.targets)
+			return nil
+		}
+
+		var perr *permanentError
+		if errors.As(err, &perr) {
+			v.next = (idx + 1) % len(v.targets)
+			v.Log.Errorf("Writing to %q failed: %v. Metrics are lost.", v.URLs[idx], err)
+			return nil
+		}
+		v.Log.Debugf("Writing to %q failed: %v", v.URLs[idx], err)
+		errs = append(errs, fmt.Errorf("writing to %q failed: %w", v.URLs[idx], err))
+	}
+	v.next = (v.next + 1) % len(v.targets)
+
+	return errors.Join(errs...)
+}
+
+// serialize converts the metrics to the JSON line import format with one
+// series per metric name, field and tag-set
+func (v *VictoriaMetrics) serialize(metrics []telegraf.Metric) ([]byte, error) {
+	index := make(map[string]*series)
+	order := make([]*series, 0, len(metrics))
+
+	var key strings.Builder
+	for _, m := range metrics {
+		ts := m.Time().UnixMilli()
+		for _, field := range m.FieldList() {
+			value, ok := toFloat(field.Value)
+			if !ok {
+				v.Log.Tracef("Skipping field %q of metric %q with unsupported value %v", field.Key, m.Name(), field.Value)
+				continue
+			}
+
+			name := m.Name() + "_" + field.Key
+			key.Reset()
+			key.WriteString(name)
+			for _, tag := range m.TagList() {
+				key.WriteString("\x00" + tag.Key + "=" + tag.Value)
+			}
+			s, found := index[key.String()]
+			if !found {
+				labels := make(map[string]string, len(m.TagList())+1)
+				for _, tag := range m.TagList() {
+					labels[tag.Key] = tag.Value
+				}
+				labels["__name__"] = name
+				s = &series{Metric: labels}
+				index[key.String()] = s
+				order = append(order, s)
+			}
+			s.Values = append(s.Values, value)
+			s.Timestamps = append(s.Timestamps, ts)
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, s := range order {
+		if err := encoder.Encode(s); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (v *VictoriaMetrics) send(target string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/stream+json")
+	req.Header.Set("User-Agent", internal.ProductToken())
+	if v.ContentEncoding != "" && v.ContentEncoding != "identity" {
+		req.Header.Set("Content-Encoding", v.ContentEncoding)
+	}
+
+	if !v.Token.Empty() {
+		token, err := v.Token.Get()
+		if err != nil {
+			return fmt.Errorf("getting token failed: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.String())
+		token.Destroy()
+	} else if !v.Username.Empty() || !v.Password.Empty() {
+		username, err := v.Username.Get()
+		if err != nil {
+			return fmt.Errorf("getting username failed: %w", err)
+		}
+		password, err := v.Password.Get()
+		if err != nil {
+			username.Destroy()
+			return fmt.Errorf("getting password failed: %w", err)
+		}
+		req.SetBasicAuth(username.String(), password.String())
+		username.Destroy()
+		password.Destroy()
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+
+	var line string
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, 1024))
+	if scanner.Scan() {
+		line = scanner.Text()
+	}
+	err = fmt.Errorf("received status %d: %s", resp.StatusCode, line)
+
+	// Malformed data is rejected by all nodes, so retrying is pointless
+	if resp.StatusCode == http.StatusBadRequest {
+		return &permanentError{err: err}
+	}
+	return err
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	var f float64
+	switch v := value.(type) {
+	case float64:
+		f = v
+	case int64:
+		f = float64(v)
+	case uint64:
+		f = float64(v)
+	case bool:
+		if v {
+			f = 1
+		}
+	default:
+		return 0, false
+	}
+
+	// Non-finite values cannot be represented in JSON
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+func init() {
+	outputs.Add("victoriametrics", func() telegraf.Output {
+		return &VictoriaMetrics{
+			ContentEncoding: "zstd",
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
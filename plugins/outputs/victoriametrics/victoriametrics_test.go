@@ -0,0 +1,273 @@
+package victoriametrics
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// server records the series received by a fake VictoriaMetrics node
+type server struct {
+	*httptest.Server
+	status int
+
+	sync.Mutex
+	requests []*http.Request
+	series   []series
+}
+
+func newServer(t *testing.T, status int) *server {
+	s := &server{status: status}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Lock()
+		defer s.Unlock()
+		s.requests = append(s.requests, r)
+
+		if s.status != http.StatusNoContent {
+			w.WriteHeader(s.status)
+			return
+		}
+
+		var reader io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "zstd" {
+			decoder, err := zstd.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			defer decoder.Close()
+			reader = decoder
+		}
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			var line series
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			s.series = append(s.series, line)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newPlugin(urls ...string) *VictoriaMetrics {
+	plugin := outputs.Outputs["victoriametrics"]().(*VictoriaMetrics)
+	plugin.URLs = urls
+	plugin.Log = testutil.Logger{}
+	return plugin
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *VictoriaMetrics
+		expected string
+	}{
+		{
+			name:     "no URL",
+			plugin:   newPlugin(),
+			expected: "no URL specified",
+		},
+		{
+			name:     "invalid scheme",
+			plugin:   newPlugin("tcp://localhost:8428"),
+			expected: "invalid scheme",
+		},
+		{
+			name: "invalid encoding",
+			plugin: func() *VictoriaMetrics {
+				p := newPlugin("http://localhost:8428")
+				p.ContentEncoding = "snappy"
+				return p
+			}(),
+			expected: "invalid content encoding",
+		},
+		{
+			name: "token and username",
+			plugin: func() *VictoriaMetrics {
+				p := newPlugin("http://localhost:8428")
+				p.Token = config.NewSecret([]byte("token"))
+				p.Username = config.NewSecret([]byte("user"))
+				return p
+			}(),
+			expected: "either use 'token' or 'username' and 'password'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	srv := newServer(t, http.StatusNoContent)
+
+	plugin := newPlugin(srv.URL)
+	plugin.ExtraLabels = map[string]string{"env": "prod", "dc": "eu"}
+	plugin.Token = config.NewSecret([]byte("secret"))
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	input := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage": 42.5, "count": int64(3), "ok": true, "state": "idle"},
+			time.Unix(10, 0),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage": 23.0},
+			time.Unix(20, 0),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"usage": uint64(7)},
+			time.Unix(10, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(input))
+
+	expected := []series{
+		{
+			Metric:     map[string]string{"__name__": "cpu_count", "host": "a"},
+			Values:     []float64{3},
+			Timestamps: []int64{10000},
+		},
+		{
+			Metric:     map[string]string{"__name__": "cpu_ok", "host": "a"},
+			Values:     []float64{1},
+			Timestamps: []int64{10000},
+		},
+		{
+			Metric:     map[string]string{"__name__": "cpu_usage", "host": "a"},
+			Values:     []float64{42.5, 23},
+			Timestamps: []int64{10000, 20000},
+		},
+		{
+			Metric:     map[string]string{"__name__": "cpu_usage", "host": "b"},
+			Values:     []float64{7},
+			Timestamps: []int64{10000},
+		},
+	}
+
+	srv.Lock()
+	defer srv.Unlock()
+	require.Len(t, srv.requests, 1)
+	req := srv.requests[0]
+	require.Equal(t, "/api/v1/import", req.URL.Path)
+	require.Equal(t, []string{"dc=eu", "env=prod"}, req.URL.Query()["extra_label"])
+	require.Equal(t, "zstd", req.Header.Get("Content-Encoding"))
+	require.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+	require.ElementsMatch(t, expected, srv.series)
+}
+
+func TestWriteRoundRobin(t *testing.T) {
+	servers := []*server{
+		newServer(t, http.StatusNoContent),
+		newServer(t, http.StatusNoContent),
+		newServer(t, http.StatusNoContent),
+	}
+
+	plugin := newPlugin(servers[0].URL, servers[1].URL, servers[2].URL)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	for range 6 {
+		require.NoError(t, plugin.Write([]telegraf.Metric{testutil.TestMetric(1.0)}))
+	}
+
+	for _, s := range servers {
+		s.Lock()
+		require.Len(t, s.requests, 2)
+		s.Unlock()
+	}
+}
+
+func TestWriteFailover(t *testing.T) {
+	failing := newServer(t, http.StatusServiceUnavailable)
+	healthy := newServer(t, http.StatusNoContent)
+
+	plugin := newPlugin(failing.URL, healthy.URL)
+	plugin.ContentEncoding = "identity"
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{testutil.TestMetric(1.0)}))
+
+	failing.Lock()
+	require.Len(t, failing.requests, 1)
+	failing.Unlock()
+	healthy.Lock()
+	require.Len(t, healthy.requests, 1)
+	require.Empty(t, healthy.requests[0].Header.Get("Content-Encoding"))
+	require.Len(t, healthy.series, 1)
+	healthy.Unlock()
+}
+
+func TestWriteAllFailing(t *testing.T) {
+	first := newServer(t, http.StatusServiceUnavailable)
+	second := newServer(t, http.StatusInternalServerError)
+
+	plugin := newPlugin(first.URL, second.URL)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	err := plugin.Write([]telegraf.Metric{testutil.TestMetric(1.0)})
+	require.ErrorContains(t, err, "received status 503")
+	require.ErrorContains(t, err, "received status 500")
+}
+
+func TestWriteBadRequestDropped(t *testing.T) {
+	first := newServer(t, http.StatusBadRequest)
+	second := newServer(t, http.StatusNoContent)
+
+	plugin := newPlugin(first.URL, second.URL)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{testutil.TestMetric(1.0)}))
+
+	second.Lock()
+	defer second.Unlock()
+	require.Empty(t, second.requests)
+}
+
+func TestSerializeSkipsUnsupported(t *testing.T) {
+	plugin := newPlugin("http://localhost:8428")
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"text": "foo"}, time.Unix(0, 0)),
+	}
+	body, err := plugin.serialize(input)
+	require.NoError(t, err)
+	require.Empty(t, bytes.TrimSpace(body))
+}
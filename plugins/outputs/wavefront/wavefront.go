@@ -16,6 +16,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/common/submission"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	serializers_wavefront "github.com/influxdata/telegraf/plugins/serializers/wavefront"
 )
@@ -51,8 +52,10 @@ type Wavefront struct {
 	SourceOverride           []string                  `toml:"source_override"`
 
 	common_http.HTTPClientConfig
+	submission.RequestConfig
 
 	sender wavefront.Sender
+	queue  *submission.Queue
 	Log    telegraf.Logger `toml:"-"`
 }
 
@@ -119,6 +122,12 @@ func (w *Wavefront) Connect() error {
 
 	w.sender = sender
 
+	queue, err := w.RequestConfig.NewQueue()
+	if err != nil {
+		return err
+	}
+	w.queue = queue
+
 	if w.ConvertPaths && w.MetricSeparator == "_" {
 		w.ConvertPaths = false
 	}
@@ -137,7 +146,7 @@ func (w *Wavefront) Write(metrics []telegraf.Metric) error {
 					// The internal buffer in the Wavefront SDK is full. To prevent data loss,
 					// we flush the buffer (which is a blocking operation) and try again.
 					w.Log.Debug("SDK buffer overrun, forcibly flushing the buffer")
-					if err = w.sender.Flush(); err != nil {
+					if err = w.flush(); err != nil {
 						return fmt.Errorf("wavefront flushing error: %w", err)
 					}
 					// Try again.
@@ -155,11 +164,25 @@ func (w *Wavefront) Write(metrics []telegraf.Metric) error {
 	}
 	if w.ImmediateFlush {
 		w.Log.Debugf("Flushing batch of %d points", len(metrics))
-		return w.sender.Flush()
+		return w.flush()
 	}
 	return nil
 }
 
+// flush sends the buffered points applying the rate limit and retrying on
+// retryable errors. The SDK keeps the points in its buffer on failure, so
+// flushing again resends them.
+func (w *Wavefront) flush() error {
+	return w.queue.Do(context.Background(), func(context.Context) error {
+		err := w.sender.Flush()
+		if err != nil && isRetryable(err) {
+			w.Log.Debugf("Flushing failed: %v", err)
+			return &submission.RetryableError{Err: err}
+		}
+		return err
+	})
+}
+
 func (w *Wavefront) buildMetrics(m telegraf.Metric) []*serializers_wavefront.MetricPoint {
 	ret := make([]*serializers_wavefront.MetricPoint, 0)
 
@@ -363,6 +386,7 @@ func init() {
 			SendInternalMetrics:  true,
 			HTTPMaximumBatchSize: 10000,
 			HTTPClientConfig:     common_http.HTTPClientConfig{Timeout: config.Duration(10 * time.Second)},
+			RequestConfig:        submission.RequestConfig{RetryBackoff: config.Duration(time.Second)},
 			CSPBaseURL:           "https://console.cloud.vmware.com",
 		}
 	})
@@ -1,8 +1,13 @@
 package wavefront
 
 import (
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -430,3 +435,46 @@ func BenchmarkReplacer(b *testing.B) {
 		serializers_wavefront.Sanitize(false, testString)
 	}
 }
+
+func TestFlushRetry(t *testing.T) {
+	var calls atomic.Int32
+	var received []string
+	var mu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		mu.Lock()
+		received = append(received, string(body))
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	plugin := outputs.Outputs["wavefront"]().(*Wavefront)
+	plugin.URL = ts.URL
+	plugin.SendInternalMetrics = false
+	plugin.MaxRetries = 1
+	plugin.RetryBackoff = config.Duration(time.Millisecond)
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.EqualValues(t, 2, calls.Load())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 1)
+	require.Contains(t, received[0], `"cpu" 42`)
+}
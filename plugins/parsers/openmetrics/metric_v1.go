@@ -18,7 +18,7 @@ func (p *Parser) extractMetricsV1(ometrics *MetricFamily) []telegraf.Metric {
 	// for "simple" types like Gauge and Counter but a telegraf metric with
 	// multiple fields for "complex" types like Summary or Histogram.
 	var metrics []telegraf.Metric
-	metricName := ometrics.GetName()
+	metricName, unit := p.applyUnitPolicy(ometrics.GetName(), ometrics.GetUnit())
 	metricType := ometrics.GetType()
 	for _, om := range ometrics.GetMetrics() {
 		// Extract the timestamp of the metric if it exists and should
@@ -27,8 +27,8 @@ func (p *Parser) extractMetricsV1(ometrics *MetricFamily) []telegraf.Metric {
 
 		// Convert the labels to tags
 		tags := getTagsFromLabels(om, p.DefaultTags)
-		if ometrics.Unit != "" {
-			tags["unit"] = ometrics.Unit
+		if unit != "" {
+			tags["unit"] = unit
 		}
 
 		// Iterate over the metric points and construct a metric for each
@@ -89,6 +89,10 @@ func (p *Parser) extractMetricsV1(ometrics *MetricFamily) []telegraf.Metric {
 					continue
 				}
 				fields := map[string]interface{}{"counter": value}
+				// Expose the creation time to allow detecting counter resets
+				if ts := omp.GetCounterValue().GetCreated(); ts != nil {
+					fields["created"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Second)
+				}
 				metrics = append(metrics, metric.New(metricName, tags, fields, t, telegraf.Counter))
 			case MetricType_STATE_SET:
 				stateset := omp.GetStateSetValue()
@@ -125,7 +129,7 @@ func (p *Parser) extractMetricsV1(ometrics *MetricFamily) []telegraf.Metric {
 					}
 				}
 				if ts := histogram.GetCreated(); ts != nil {
-					fields["created"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Nanosecond)
+					fields["created"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Second)
 				}
 				for _, b := range histogram.Buckets {
 					fname := strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64)
@@ -17,7 +17,7 @@ func (p *Parser) extractMetricsV2(ometrics *MetricFamily) []telegraf.Metric {
 	// with one field each. The process will filter NaNs in values and skip
 	// the corresponding metrics.
 	var metrics []telegraf.Metric
-	metricName := ometrics.GetName()
+	metricName, unit := p.applyUnitPolicy(ometrics.GetName(), ometrics.GetUnit())
 	metricType := ometrics.GetType()
 	for _, om := range ometrics.GetMetrics() {
 		// Extract the timestamp of the metric if it exists and should
@@ -26,8 +26,8 @@ func (p *Parser) extractMetricsV2(ometrics *MetricFamily) []telegraf.Metric {
 
 		// Convert the labels to tags
 		tags := getTagsFromLabels(om, p.DefaultTags)
-		if ometrics.Unit != "" {
-			tags["unit"] = ometrics.Unit
+		if unit != "" {
+			tags["unit"] = unit
 		}
 
 		// Construct the metrics
@@ -87,6 +87,10 @@ func (p *Parser) extractMetricsV2(ometrics *MetricFamily) []telegraf.Metric {
 					continue
 				}
 				fields := map[string]interface{}{metricName: value}
+				// Expose the creation time to allow detecting counter resets
+				if ts := omp.GetCounterValue().GetCreated(); ts != nil {
+					fields[metricName+"_created"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Second)
+				}
 				metrics = append(metrics, metric.New("openmetric", tags, fields, t, telegraf.Counter))
 			case MetricType_STATE_SET:
 				stateset := omp.GetStateSetValue()
@@ -123,7 +127,7 @@ func (p *Parser) extractMetricsV2(ometrics *MetricFamily) []telegraf.Metric {
 					}
 				}
 				if ts := histogram.GetCreated(); ts != nil {
-					histFields[metricName+"_created"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Nanosecond)
+					histFields[metricName+"_created"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Second)
 				}
 				metrics = append(metrics, metric.New("openmetric", tags, histFields, t, telegraf.Histogram))
 
@@ -168,7 +172,7 @@ func (p *Parser) extractMetricsV2(ometrics *MetricFamily) []telegraf.Metric {
 					}
 				}
 				if ts := summary.GetCreated(); ts != nil {
-					summaryFields[metricName+"_created"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Nanosecond)
+					summaryFields[metricName+"_created"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Second)
 				}
 				metrics = append(metrics, metric.New("openmetric", tags, summaryFields, t, telegraf.Summary))
 
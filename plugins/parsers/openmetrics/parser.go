@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"mime"
 	"net/http"
+	"strings"
 
 	"github.com/prometheus/common/expfmt"
 	"google.golang.org/protobuf/proto"
@@ -35,11 +36,23 @@ func AcceptsContent(header http.Header) bool {
 type Parser struct {
 	IgnoreTimestamp bool              `toml:"openmetrics_ignore_timestamp"`
 	MetricVersion   int               `toml:"openmetrics_metric_version"`
+	UnitPolicy      string            `toml:"openmetrics_unit_policy"`
 	Header          http.Header       `toml:"-"` // set by the input plugin
 	DefaultTags     map[string]string `toml:"-"`
 	Log             telegraf.Logger   `toml:"-"`
 }
 
+func (p *Parser) Init() error {
+	switch p.UnitPolicy {
+	case "":
+		p.UnitPolicy = "tag"
+	case "tag", "suffix", "ignore":
+	default:
+		return fmt.Errorf("invalid unit policy %q", p.UnitPolicy)
+	}
+	return nil
+}
+
 func (p *Parser) SetDefaultTags(tags map[string]string) {
 	p.DefaultTags = tags
 }
@@ -118,6 +131,27 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	return metrics[0], nil
 }
 
+// applyUnitPolicy returns the metric-family name and the value of the unit
+// tag for the given family according to the configured unit policy
+func (p *Parser) applyUnitPolicy(name, unit string) (string, string) {
+	if unit == "" {
+		return name, ""
+	}
+
+	switch p.UnitPolicy {
+	case "suffix":
+		// The specification requires the unit to be a suffix of the name so
+		// only append it for non-compliant exporters
+		if !strings.HasSuffix(name, "_"+unit) {
+			name += "_" + unit
+		}
+		return name, ""
+	case "ignore":
+		return name, ""
+	}
+	return name, unit
+}
+
 func getTagsFromLabels(m *Metric, defaultTags map[string]string) map[string]string {
 	result := make(map[string]string, len(defaultTags)+len(m.Labels))
 
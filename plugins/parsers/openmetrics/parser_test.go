@@ -151,6 +151,34 @@ func TestCases(t *testing.T) {
 	}
 }
 
+func TestInvalidUnitPolicy(t *testing.T) {
+	parser := &Parser{UnitPolicy: "foo"}
+	require.ErrorContains(t, parser.Init(), "invalid unit policy")
+}
+
+func TestUnitPolicy(t *testing.T) {
+	tests := []struct {
+		policy       string
+		name         string
+		expectedName string
+		expectedTag  string
+	}{
+		{"tag", "process_cpu_seconds", "process_cpu_seconds", "seconds"},
+		{"suffix", "process_cpu_seconds", "process_cpu_seconds", ""},
+		{"suffix", "process_cpu", "process_cpu_seconds", ""},
+		{"ignore", "process_cpu", "process_cpu", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy+"_"+tt.name, func(t *testing.T) {
+			parser := &Parser{UnitPolicy: tt.policy}
+			require.NoError(t, parser.Init())
+			name, tag := parser.applyUnitPolicy(tt.name, "seconds")
+			require.Equal(t, tt.expectedName, name)
+			require.Equal(t, tt.expectedTag, tag)
+		})
+	}
+}
+
 func BenchmarkParsingMetricVersion1(b *testing.B) {
 	plugin := &Parser{MetricVersion: 1}
 
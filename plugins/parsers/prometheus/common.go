@@ -1,7 +1,11 @@
 package prometheus
 
 import (
+	"strings"
+	"time"
+
 	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/influxdata/telegraf"
 )
@@ -21,6 +25,35 @@ func mapValueType(mt dto.MetricType) telegraf.ValueType {
 	}
 }
 
+// applyUnitPolicy returns the metric-family name and the value of the unit
+// tag for the given family according to the configured unit policy
+func (p *Parser) applyUnitPolicy(name, unit string) (string, string) {
+	if unit == "" {
+		return name, ""
+	}
+
+	switch p.UnitPolicy {
+	case "tag":
+		return name, unit
+	case "suffix":
+		// Counter names end with "_total" so the unit must precede it
+		base, found := strings.CutSuffix(name, "_total")
+		if !strings.HasSuffix(base, "_"+unit) {
+			base += "_" + unit
+		}
+		if found {
+			base += "_total"
+		}
+		return base, ""
+	}
+	return name, ""
+}
+
+// createdSeconds converts the given creation timestamp to seconds since epoch
+func createdSeconds(ts *timestamppb.Timestamp) float64 {
+	return float64(ts.GetSeconds()) + float64(ts.GetNanos())/float64(time.Second)
+}
+
 func getTagsFromLabels(m *dto.Metric, defaultTags map[string]string) map[string]string {
 	result := make(map[string]string, len(defaultTags)+len(m.Label))
 	for key, value := range defaultTags {
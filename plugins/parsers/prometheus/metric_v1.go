@@ -19,7 +19,7 @@ func (p *Parser) extractMetricsV1(prommetrics *dto.MetricFamily) []telegraf.Metr
 	// for "simple" types like Gauge and Counter but a telegraf metric with
 	// multiple fields for "complex" types like Summary or Histogram.
 	var metrics []telegraf.Metric
-	metricName := prommetrics.GetName()
+	metricName, unit := p.applyUnitPolicy(prommetrics.GetName(), prommetrics.GetUnit())
 	metricType := prommetrics.GetType()
 	for _, pm := range prommetrics.Metric {
 		// Extract the timestamp of the metric if it exists and should
@@ -31,6 +31,9 @@ func (p *Parser) extractMetricsV1(prommetrics *dto.MetricFamily) []telegraf.Metr
 
 		// Convert the labels to tags
 		tags := getTagsFromLabels(pm, p.DefaultTags)
+		if unit != "" {
+			tags["unit"] = unit
+		}
 
 		// Construct the metrics
 		switch metricType {
@@ -41,6 +44,9 @@ func (p *Parser) extractMetricsV1(prommetrics *dto.MetricFamily) []telegraf.Metr
 			fields := make(map[string]interface{}, len(summary.Quantile)+2)
 			fields["count"] = float64(summary.GetSampleCount())
 			fields["sum"] = summary.GetSampleSum()
+			if ts := summary.GetCreatedTimestamp(); ts != nil {
+				fields["created"] = createdSeconds(ts)
+			}
 			for _, q := range summary.Quantile {
 				if v := q.GetValue(); !math.IsNaN(v) {
 					fname := strconv.FormatFloat(q.GetQuantile(), 'g', -1, 64)
@@ -53,11 +59,22 @@ func (p *Parser) extractMetricsV1(prommetrics *dto.MetricFamily) []telegraf.Metr
 
 			// Collect the fields
 			fields := make(map[string]interface{}, len(histogram.Bucket)+2)
-			fields["count"] = float64(pm.GetHistogram().GetSampleCount())
-			fields["sum"] = pm.GetHistogram().GetSampleSum()
-			for _, b := range histogram.Bucket {
-				fname := strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64)
-				fields[fname] = float64(b.GetCumulativeCount())
+			fields["count"] = histogramCount(histogram)
+			fields["sum"] = histogram.GetSampleSum()
+			if ts := histogram.GetCreatedTimestamp(); ts != nil {
+				fields["created"] = createdSeconds(ts)
+			}
+			if p.useNativeHistogram(histogram) {
+				for _, b := range nativeBuckets(histogram) {
+					fname := strconv.FormatFloat(b.upper, 'g', -1, 64)
+					fields[fname] = b.count
+				}
+				fields["+Inf"] = histogramCount(histogram)
+			} else {
+				for _, b := range histogram.Bucket {
+					fname := strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64)
+					fields[fname] = float64(b.GetCumulativeCount())
+				}
 			}
 			metrics = append(metrics, metric.New(metricName, tags, fields, t, telegraf.Histogram))
 		default:
@@ -75,6 +92,10 @@ func (p *Parser) extractMetricsV1(prommetrics *dto.MetricFamily) []telegraf.Metr
 			}
 			if fname != "" && !math.IsNaN(v) {
 				fields := map[string]interface{}{fname: v}
+				// Expose the creation time to allow detecting counter resets
+				if ts := pm.GetCounter().GetCreatedTimestamp(); ts != nil {
+					fields["created"] = createdSeconds(ts)
+				}
 				vtype := mapValueType(metricType)
 				metrics = append(metrics, metric.New(metricName, tags, fields, t, vtype))
 			}
@@ -18,7 +18,7 @@ func (p *Parser) extractMetricsV2(prommetrics *dto.MetricFamily) []telegraf.Metr
 	// with one field each. The process will filter NaNs in values and skip
 	// the corresponding metrics.
 	var metrics []telegraf.Metric
-	metricName := prommetrics.GetName()
+	metricName, unit := p.applyUnitPolicy(prommetrics.GetName(), prommetrics.GetUnit())
 	metricType := prommetrics.GetType()
 	for _, pm := range prommetrics.Metric {
 		// Extract the timestamp of the metric if it exists and should
@@ -30,6 +30,9 @@ func (p *Parser) extractMetricsV2(prommetrics *dto.MetricFamily) []telegraf.Metr
 
 		// Convert the labels to tags
 		tags := getTagsFromLabels(pm, p.DefaultTags)
+		if unit != "" {
+			tags["unit"] = unit
+		}
 
 		// Construct the metrics
 		switch metricType {
@@ -40,6 +43,9 @@ func (p *Parser) extractMetricsV2(prommetrics *dto.MetricFamily) []telegraf.Metr
 			summaryFields := make(map[string]interface{})
 			summaryFields[metricName+"_count"] = float64(summary.GetSampleCount())
 			summaryFields[metricName+"_sum"] = summary.GetSampleSum()
+			if ts := summary.GetCreatedTimestamp(); ts != nil {
+				summaryFields[metricName+"_created"] = createdSeconds(ts)
+			}
 			metrics = append(metrics, metric.New("prometheus", tags, summaryFields, t, telegraf.Summary))
 
 			// Add one metric per quantile
@@ -57,23 +63,35 @@ func (p *Parser) extractMetricsV2(prommetrics *dto.MetricFamily) []telegraf.Metr
 
 			// Add an overall metric containing the number of samples and and its sum
 			histFields := make(map[string]interface{})
-			histFields[metricName+"_count"] = float64(histogram.GetSampleCount())
+			histFields[metricName+"_count"] = histogramCount(histogram)
 			histFields[metricName+"_sum"] = histogram.GetSampleSum()
+			if ts := histogram.GetCreatedTimestamp(); ts != nil {
+				histFields[metricName+"_created"] = createdSeconds(ts)
+			}
 			metrics = append(metrics, metric.New("prometheus", tags, histFields, t, telegraf.Histogram))
 
 			// Add one metric per histogram bucket
+			var buckets []bucket
+			if p.useNativeHistogram(histogram) {
+				buckets = nativeBuckets(histogram)
+			} else {
+				buckets = make([]bucket, 0, len(histogram.Bucket))
+				for _, b := range histogram.Bucket {
+					buckets = append(buckets, bucket{upper: b.GetUpperBound(), count: float64(b.GetCumulativeCount())})
+				}
+			}
 			var infSeen bool
-			for _, b := range histogram.Bucket {
+			for _, b := range buckets {
 				bucketTags := tags
-				bucketTags["le"] = strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64)
+				bucketTags["le"] = strconv.FormatFloat(b.upper, 'g', -1, 64)
 				bucketFields := map[string]interface{}{
-					metricName + "_bucket": float64(b.GetCumulativeCount()),
+					metricName + "_bucket": b.count,
 				}
 				m := metric.New("prometheus", bucketTags, bucketFields, t, telegraf.Histogram)
 				metrics = append(metrics, m)
 
 				// Record if any of the buckets marks an infinite upper bound
-				infSeen = infSeen || math.IsInf(b.GetUpperBound(), +1)
+				infSeen = infSeen || math.IsInf(b.upper, +1)
 			}
 
 			// Infinity bucket is required for proper function of histogram in prometheus
@@ -81,7 +99,7 @@ func (p *Parser) extractMetricsV2(prommetrics *dto.MetricFamily) []telegraf.Metr
 				infTags := tags
 				infTags["le"] = "+Inf"
 				infFields := map[string]interface{}{
-					metricName + "_bucket": float64(histogram.GetSampleCount()),
+					metricName + "_bucket": histogramCount(histogram),
 				}
 				m := metric.New("prometheus", infTags, infFields, t, telegraf.Histogram)
 				metrics = append(metrics, m)
@@ -97,6 +115,10 @@ func (p *Parser) extractMetricsV2(prommetrics *dto.MetricFamily) []telegraf.Metr
 			}
 			if !math.IsNaN(v) {
 				fields := map[string]interface{}{metricName: v}
+				// Expose the creation time to allow detecting counter resets
+				if ts := pm.GetCounter().GetCreatedTimestamp(); ts != nil {
+					fields[metricName+"_created"] = createdSeconds(ts)
+				}
 				vtype := mapValueType(metricType)
 				metrics = append(metrics, metric.New("prometheus", tags, fields, t, vtype))
 			}
@@ -0,0 +1,125 @@
+package prometheus
+
+import (
+	"math"
+	"slices"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// bucket of a histogram with the given inclusive upper bound and the
+// cumulative count of all observations less or equal to the bound
+type bucket struct {
+	upper float64
+	count float64
+}
+
+// sparseBucket is a bucket of a native histogram identified by its index
+type sparseBucket struct {
+	index int32
+	count float64
+}
+
+// isNativeHistogram returns true if the histogram contains native (sparse)
+// buckets, see https://prometheus.io/docs/specs/native_histograms/
+func isNativeHistogram(h *dto.Histogram) bool {
+	return h.GetZeroThreshold() > 0 || h.GetZeroCount() > 0 || h.GetZeroCountFloat() > 0 ||
+		len(h.GetNegativeSpan()) > 0 || len(h.GetPositiveSpan()) > 0
+}
+
+// useNativeHistogram determines if the histogram should be represented using
+// its native buckets according to the configured preference. Histograms
+// without classic buckets always use the native ones if available.
+func (p *Parser) useNativeHistogram(h *dto.Histogram) bool {
+	if !isNativeHistogram(h) {
+		return false
+	}
+	return p.HistogramPreference == "native" || len(h.GetBucket()) == 0
+}
+
+// histogramCount returns the number of observations of integer and float
+// histograms
+func histogramCount(h *dto.Histogram) float64 {
+	if h.SampleCountFloat != nil {
+		return h.GetSampleCountFloat()
+	}
+	return float64(h.GetSampleCount())
+}
+
+// nativeBuckets converts the exponential buckets of a native histogram to
+// cumulative buckets ordered by their upper bound as used for classic
+// histograms. The buckets do not include the +Inf bucket.
+func nativeBuckets(h *dto.Histogram) []bucket {
+	schema := h.GetSchema()
+	negative := expandSpans(h.GetNegativeSpan(), h.GetNegativeDelta(), h.GetNegativeCount())
+	positive := expandSpans(h.GetPositiveSpan(), h.GetPositiveDelta(), h.GetPositiveCount())
+
+	buckets := make([]bucket, 0, len(negative)+len(positive)+1)
+	var cumulative float64
+
+	// Negative buckets with a higher index cover values with a larger
+	// magnitude so they come first. The upper bound of a negative bucket is
+	// the negated lower bound of the corresponding positive bucket.
+	slices.Reverse(negative)
+	for _, b := range negative {
+		cumulative += b.count
+		buckets = append(buckets, bucket{upper: -bucketBound(schema, b.index-1), count: cumulative})
+	}
+
+	zeroCount := float64(h.GetZeroCount())
+	if h.ZeroCountFloat != nil {
+		zeroCount = h.GetZeroCountFloat()
+	}
+	if zeroCount > 0 || h.GetZeroThreshold() > 0 {
+		cumulative += zeroCount
+		buckets = append(buckets, bucket{upper: h.GetZeroThreshold(), count: cumulative})
+	}
+
+	for _, b := range positive {
+		cumulative += b.count
+		buckets = append(buckets, bucket{upper: bucketBound(schema, b.index), count: cumulative})
+	}
+
+	return buckets
+}
+
+// expandSpans returns the buckets described by the given spans ordered by
+// index. Integer histograms encode the counts as deltas to the previous
+// bucket while float histograms contain absolute counts.
+func expandSpans(spans []*dto.BucketSpan, deltas []int64, counts []float64) []sparseBucket {
+	var buckets []sparseBucket
+	var index int32
+	var current int64
+	var n int
+	for i, span := range spans {
+		// The offset of the first span is the starting index, the following
+		// offsets are relative to the end of the previous span
+		if i == 0 {
+			index = span.GetOffset()
+		} else {
+			index += span.GetOffset()
+		}
+		for range span.GetLength() {
+			var count float64
+			switch {
+			case n < len(deltas):
+				current += deltas[n]
+				count = float64(current)
+			case n < len(counts):
+				count = counts[n]
+			default:
+				return buckets
+			}
+			buckets = append(buckets, sparseBucket{index: index, count: count})
+			index++
+			n++
+		}
+	}
+	return buckets
+}
+
+// bucketBound returns the upper bound of the positive bucket with the given
+// index for the exponential schema, i.e. base^index with base=2^(2^-schema)
+func bucketBound(schema, index int32) float64 {
+	return math.Exp2(float64(index) * math.Exp2(-float64(schema)))
+}
@@ -19,11 +19,34 @@ func AcceptsContent(header http.Header) bool {
 }
 
 type Parser struct {
-	IgnoreTimestamp bool              `toml:"prometheus_ignore_timestamp"`
-	MetricVersion   int               `toml:"prometheus_metric_version"`
-	Header          http.Header       `toml:"-"` // set by the prometheus input
-	DefaultTags     map[string]string `toml:"-"`
-	Log             telegraf.Logger   `toml:"-"`
+	IgnoreTimestamp bool   `toml:"prometheus_ignore_timestamp"`
+	MetricVersion   int    `toml:"prometheus_metric_version"`
+	UnitPolicy      string `toml:"prometheus_unit_policy"`
+	// HistogramPreference selects the "classic" or "native" buckets of
+	// histograms exposing both
+	HistogramPreference string            `toml:"prometheus_histogram_preference"`
+	Header              http.Header       `toml:"-"` // set by the prometheus input
+	DefaultTags         map[string]string `toml:"-"`
+	Log                 telegraf.Logger   `toml:"-"`
+}
+
+func (p *Parser) Init() error {
+	switch p.UnitPolicy {
+	case "":
+		p.UnitPolicy = "ignore"
+	case "tag", "suffix", "ignore":
+	default:
+		return fmt.Errorf("invalid unit policy %q", p.UnitPolicy)
+	}
+
+	switch p.HistogramPreference {
+	case "":
+		p.HistogramPreference = "classic"
+	case "classic", "native":
+	default:
+		return fmt.Errorf("invalid histogram preference %q", p.HistogramPreference)
+	}
+	return nil
 }
 
 func (p *Parser) SetDefaultTags(tags map[string]string) {
@@ -1,15 +1,24 @@
 package prometheus
 
 import (
+	"bytes"
 	"net/http"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/testutil"
 	test "github.com/influxdata/telegraf/testutil/plugin_input"
@@ -151,6 +160,271 @@ func TestCases(t *testing.T) {
 	}
 }
 
+func TestInvalidUnitPolicy(t *testing.T) {
+	parser := &Parser{UnitPolicy: "foo"}
+	require.ErrorContains(t, parser.Init(), "invalid unit policy")
+}
+
+func TestCreatedAndUnit(t *testing.T) {
+	mf := &dto.MetricFamily{
+		Name: proto.String("process_cpu_total"),
+		Type: dto.MetricType_COUNTER.Enum(),
+		Unit: proto.String("seconds"),
+		Metric: []*dto.Metric{
+			{
+				Counter: &dto.Counter{
+					Value:            proto.Float64(42),
+					CreatedTimestamp: timestamppb.New(time.Unix(1705509488, 500000000)),
+				},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	_, err := protodelim.MarshalTo(&buf, mf)
+	require.NoError(t, err)
+
+	header := http.Header{}
+	header.Set("Content-Type", string(expfmt.NewFormat(expfmt.TypeProtoDelim)))
+
+	tests := []struct {
+		name     string
+		version  int
+		policy   string
+		expected telegraf.Metric
+	}{
+		{
+			name:    "v1 ignore",
+			version: 1,
+			expected: metric.New(
+				"process_cpu_total",
+				map[string]string{},
+				map[string]interface{}{"counter": float64(42), "created": 1705509488.5},
+				time.Unix(0, 0),
+				telegraf.Counter,
+			),
+		},
+		{
+			name:    "v1 tag",
+			version: 1,
+			policy:  "tag",
+			expected: metric.New(
+				"process_cpu_total",
+				map[string]string{"unit": "seconds"},
+				map[string]interface{}{"counter": float64(42), "created": 1705509488.5},
+				time.Unix(0, 0),
+				telegraf.Counter,
+			),
+		},
+		{
+			name:    "v2 suffix",
+			version: 2,
+			policy:  "suffix",
+			expected: metric.New(
+				"prometheus",
+				map[string]string{},
+				map[string]interface{}{
+					"process_cpu_seconds_total":         float64(42),
+					"process_cpu_seconds_total_created": 1705509488.5,
+				},
+				time.Unix(0, 0),
+				telegraf.Counter,
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &Parser{
+				MetricVersion: tt.version,
+				UnitPolicy:    tt.policy,
+				Header:        header,
+			}
+			require.NoError(t, parser.Init())
+			actual, err := parser.Parse(buf.Bytes())
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, actual, testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestInvalidHistogramPreference(t *testing.T) {
+	parser := &Parser{HistogramPreference: "foo"}
+	require.ErrorContains(t, parser.Init(), "invalid histogram preference")
+}
+
+func TestNativeHistogram(t *testing.T) {
+	// Histogram exposing classic and native buckets with the native buckets
+	// at indices -1, 0, 1 and 3 of schema 0 and a zero bucket
+	histogram := &dto.Histogram{
+		SampleCount:   proto.Uint64(7),
+		SampleSum:     proto.Float64(10),
+		Schema:        proto.Int32(0),
+		ZeroThreshold: proto.Float64(0.001),
+		ZeroCount:     proto.Uint64(1),
+		NegativeSpan:  []*dto.BucketSpan{{Offset: proto.Int32(1), Length: proto.Uint32(1)}},
+		NegativeDelta: []int64{1},
+		PositiveSpan: []*dto.BucketSpan{
+			{Offset: proto.Int32(0), Length: proto.Uint32(2)},
+			{Offset: proto.Int32(1), Length: proto.Uint32(1)},
+		},
+		PositiveDelta: []int64{2, -1, 1},
+	}
+	classic := []*dto.Bucket{
+		{UpperBound: proto.Float64(1), CumulativeCount: proto.Uint64(4)},
+		{UpperBound: proto.Float64(10), CumulativeCount: proto.Uint64(7)},
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", string(expfmt.NewFormat(expfmt.TypeProtoDelim)))
+
+	tests := []struct {
+		name       string
+		version    int
+		preference string
+		classic    bool
+		expected   []telegraf.Metric
+	}{
+		{
+			name:    "v1 classic preference",
+			version: 1,
+			classic: true,
+			expected: []telegraf.Metric{
+				metric.New(
+					"rpc_duration",
+					map[string]string{},
+					map[string]interface{}{"count": float64(7), "sum": float64(10), "1": float64(4), "10": float64(7)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+			},
+		},
+		{
+			name:       "v1 native preference",
+			version:    1,
+			preference: "native",
+			classic:    true,
+			expected: []telegraf.Metric{
+				metric.New(
+					"rpc_duration",
+					map[string]string{},
+					map[string]interface{}{
+						"count": float64(7),
+						"sum":   float64(10),
+						"-1":    float64(1),
+						"0.001": float64(2),
+						"1":     float64(4),
+						"2":     float64(5),
+						"8":     float64(7),
+						"+Inf":  float64(7),
+					},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+			},
+		},
+		{
+			name:    "v2 native only",
+			version: 2,
+			expected: []telegraf.Metric{
+				metric.New(
+					"prometheus",
+					map[string]string{},
+					map[string]interface{}{"rpc_duration_count": float64(7), "rpc_duration_sum": float64(10)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New(
+					"prometheus",
+					map[string]string{"le": "-1"},
+					map[string]interface{}{"rpc_duration_bucket": float64(1)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New(
+					"prometheus",
+					map[string]string{"le": "0.001"},
+					map[string]interface{}{"rpc_duration_bucket": float64(2)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New(
+					"prometheus",
+					map[string]string{"le": "1"},
+					map[string]interface{}{"rpc_duration_bucket": float64(4)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New(
+					"prometheus",
+					map[string]string{"le": "2"},
+					map[string]interface{}{"rpc_duration_bucket": float64(5)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New(
+					"prometheus",
+					map[string]string{"le": "8"},
+					map[string]interface{}{"rpc_duration_bucket": float64(7)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New(
+					"prometheus",
+					map[string]string{"le": "+Inf"},
+					map[string]interface{}{"rpc_duration_bucket": float64(7)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := proto.Clone(histogram).(*dto.Histogram)
+			if tt.classic {
+				h.Bucket = classic
+			}
+			mf := &dto.MetricFamily{
+				Name:   proto.String("rpc_duration"),
+				Type:   dto.MetricType_HISTOGRAM.Enum(),
+				Metric: []*dto.Metric{{Histogram: h}},
+			}
+			var buf bytes.Buffer
+			_, err := protodelim.MarshalTo(&buf, mf)
+			require.NoError(t, err)
+
+			parser := &Parser{
+				MetricVersion:       tt.version,
+				HistogramPreference: tt.preference,
+				Header:              header,
+			}
+			require.NoError(t, parser.Init())
+			actual, err := parser.Parse(buf.Bytes())
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, tt.expected, actual, testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestNativeHistogramFloatBuckets(t *testing.T) {
+	h := &dto.Histogram{
+		SampleCountFloat: proto.Float64(3.5),
+		SampleSum:        proto.Float64(4),
+		Schema:           proto.Int32(1),
+		ZeroThreshold:    proto.Float64(0),
+		PositiveSpan:     []*dto.BucketSpan{{Offset: proto.Int32(2), Length: proto.Uint32(2)}},
+		PositiveCount:    []float64{1.5, 2},
+	}
+	require.True(t, isNativeHistogram(h))
+	require.InDelta(t, 3.5, histogramCount(h), 1e-9)
+
+	buckets := nativeBuckets(h)
+	require.Len(t, buckets, 2)
+	require.InDelta(t, 2, buckets[0].upper, 1e-9)
+	require.InDelta(t, 1.5, buckets[0].count, 1e-9)
+	require.InDelta(t, 2.8284271247461903, buckets[1].upper, 1e-9)
+	require.InDelta(t, 3.5, buckets[1].count, 1e-9)
+}
+
 func BenchmarkParsingMetricVersion1(b *testing.B) {
 	plugin := &Parser{MetricVersion: 1}
 
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.batch_stats
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/batch_stats" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.branch
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/branch" // register plugin
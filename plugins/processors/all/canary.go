@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.canary
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/canary" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.smooth
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/smooth" // register plugin
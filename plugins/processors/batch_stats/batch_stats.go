@@ -0,0 +1,223 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package batch_stats
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type BatchStats struct {
+	Period        config.Duration `toml:"period"`
+	IncludeFields []string        `toml:"include_fields"`
+	ExcludeFields []string        `toml:"exclude_fields"`
+	GroupBy       []string        `toml:"group_by"`
+	Stats         []string        `toml:"stats"`
+	Suffix        string          `toml:"suffix"`
+	Log           telegraf.Logger `toml:"-"`
+
+	fields filter.Filter
+	acc    telegraf.Accumulator
+	groups map[uint64]*group
+	done   chan struct{}
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+}
+
+// group holds the statistics of all metrics of a measurement sharing the
+// same values of the group-by tags
+type group struct {
+	name   string
+	tags   map[string]string
+	fields map[string]*stats
+}
+
+// stats are the running statistics of a field computed with Welford's
+// algorithm
+type stats struct {
+	count int64
+	mean  float64
+	m2    float64
+}
+
+func (*BatchStats) SampleConfig() string {
+	return sampleConfig
+}
+
+func (b *BatchStats) Init() error {
+	if b.Period <= 0 {
+		return errors.New("'period' must be positive")
+	}
+	if len(b.Stats) == 0 {
+		b.Stats = []string{"count", "mean", "stddev"}
+	}
+	if err := choice.CheckSlice(b.Stats, []string{"count", "mean", "stddev"}); err != nil {
+		return fmt.Errorf("config option stats: %w", err)
+	}
+	if b.Suffix == "" {
+		return errors.New("'suffix' must not be empty")
+	}
+
+	f, err := filter.NewIncludeExcludeFilter(b.IncludeFields, b.ExcludeFields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	b.fields = f
+	b.groups = make(map[uint64]*group)
+
+	return nil
+}
+
+func (b *BatchStats) Start(acc telegraf.Accumulator) error {
+	b.acc = acc
+	b.done = make(chan struct{})
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(b.Period))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.done:
+				return
+			case now := <-ticker.C:
+				b.emit(now)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (b *BatchStats) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	b.update(m)
+	acc.AddMetric(m)
+	return nil
+}
+
+func (b *BatchStats) Stop() {
+	close(b.done)
+	b.wg.Wait()
+
+	// Emit the statistics of the incomplete period
+	b.emit(time.Now())
+}
+
+func (b *BatchStats) update(m telegraf.Metric) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var g *group
+	for _, field := range m.FieldList() {
+		if !b.fields.Match(field.Key) {
+			continue
+		}
+		v, ok := toFloat(field.Value)
+		if !ok || math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+
+		if g == nil {
+			g = b.group(m)
+		}
+		s, found := g.fields[field.Key]
+		if !found {
+			s = &stats{}
+			g.fields[field.Key] = s
+		}
+		s.count++
+		delta := v - s.mean
+		s.mean += delta / float64(s.count)
+		s.m2 += delta * (v - s.mean)
+	}
+}
+
+// group returns the group of the metric, creating it if necessary
+func (b *BatchStats) group(m telegraf.Metric) *group {
+	tags := make(map[string]string, len(b.GroupBy))
+	for _, key := range b.GroupBy {
+		if v, found := m.GetTag(key); found {
+			tags[key] = v
+		}
+	}
+
+	id := metric.New(m.Name(), tags, nil, time.Time{}).HashID()
+	g, found := b.groups[id]
+	if !found {
+		g = &group{
+			name:   m.Name(),
+			tags:   tags,
+			fields: make(map[string]*stats),
+		}
+		b.groups[id] = g
+	}
+	return g
+}
+
+// emit adds the statistics of the current period as metrics and starts a new
+// period
+func (b *BatchStats) emit(now time.Time) {
+	b.mu.Lock()
+	groups := b.groups
+	b.groups = make(map[uint64]*group, len(groups))
+	b.mu.Unlock()
+
+	for _, g := range groups {
+		fields := make(map[string]interface{}, len(g.fields)*len(b.Stats))
+		for key, s := range g.fields {
+			for _, stat := range b.Stats {
+				switch stat {
+				case "count":
+					fields[key+"_count"] = s.count
+				case "mean":
+					fields[key+"_mean"] = s.mean
+				case "stddev":
+					// The sample standard deviation requires at least two values
+					if s.count > 1 {
+						fields[key+"_stddev"] = math.Sqrt(s.m2 / float64(s.count-1))
+					}
+				}
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		b.acc.AddFields(g.name+b.Suffix, fields, g.tags, now)
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func init() {
+	processors.AddStreaming("batch_stats", func() telegraf.StreamingProcessor {
+		return &BatchStats{
+			Period: config.Duration(10 * time.Second),
+			Suffix: "_stats",
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package batch_stats
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &BatchStats{Suffix: "_stats"}
+	require.ErrorContains(t, plugin.Init(), "'period' must be positive")
+
+	plugin = &BatchStats{Period: config.Duration(time.Second), Suffix: "_stats", Stats: []string{"median"}}
+	require.ErrorContains(t, plugin.Init(), "config option stats")
+
+	plugin = &BatchStats{Period: config.Duration(time.Second)}
+	require.ErrorContains(t, plugin.Init(), "'suffix' must not be empty")
+}
+
+func TestStatistics(t *testing.T) {
+	plugin := &BatchStats{
+		Period:        config.Duration(time.Hour),
+		ExcludeFields: []string{"ignored"},
+		GroupBy:       []string{"region"},
+		Suffix:        "_stats",
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a", "region": "eu"},
+			map[string]interface{}{"usage": 10.0, "ignored": 1.0, "state": "ok"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "b", "region": "eu"},
+			map[string]interface{}{"usage": 20.0, "threads": int64(4)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "c", "region": "eu"},
+			map[string]interface{}{"usage": 30.0, "threads": uint64(8)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "d", "region": "us"},
+			map[string]interface{}{"usage": 50.0, "nan": math.NaN()},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"used": int64(100)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"used": int64(300)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"status",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"state": "ok"},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu_stats",
+			map[string]string{"region": "eu"},
+			map[string]interface{}{
+				"usage_count":    int64(3),
+				"usage_mean":     20.0,
+				"usage_stddev":   10.0,
+				"threads_count":  int64(2),
+				"threads_mean":   6.0,
+				"threads_stddev": math.Sqrt(8),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cpu_stats",
+			map[string]string{"region": "us"},
+			map[string]interface{}{
+				"usage_count": int64(1),
+				"usage_mean":  50.0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mem_stats",
+			map[string]string{},
+			map[string]interface{}{
+				"used_count":  int64(2),
+				"used_mean":   200.0,
+				"used_stddev": math.Sqrt(20000),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	// The input metrics must be passed through unmodified
+	actual := acc.GetTelegrafMetrics()
+	require.Len(t, actual, len(input)+len(expected))
+	testutil.RequireMetricsEqual(t, input, actual[:len(input)], testutil.IgnoreTime())
+	testutil.RequireMetricsEqual(t, expected, actual[len(input):],
+		testutil.IgnoreTime(), testutil.SortMetrics(), cmpopts.EquateApprox(0, 1e-9))
+}
+
+func TestPeriods(t *testing.T) {
+	plugin := &BatchStats{
+		Period: config.Duration(50 * time.Millisecond),
+		Stats:  []string{"count"},
+		Suffix: "_stats",
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	m := metric.New("test", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.NoError(t, plugin.Add(m, &acc))
+
+	expected := metric.New("test_stats", map[string]string{}, map[string]interface{}{"value_count": int64(1)}, time.Unix(0, 0))
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 2
+	}, time.Second, 10*time.Millisecond)
+	testutil.RequireMetricEqual(t, expected, acc.GetTelegrafMetrics()[1], testutil.IgnoreTime())
+
+	// Statistics are reset after each period, so no further metrics are
+	// expected without new input
+	time.Sleep(200 * time.Millisecond)
+	require.Equal(t, uint64(2), acc.NMetrics())
+}
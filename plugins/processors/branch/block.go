@@ -0,0 +1,155 @@
+package branch
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+)
+
+type block struct {
+	Condition    string            `toml:"condition"`
+	Drop         bool              `toml:"drop"`
+	Rename       string            `toml:"rename"`
+	RenameTags   map[string]string `toml:"rename_tags"`
+	RenameFields map[string]string `toml:"rename_fields"`
+	RemoveTags   []string          `toml:"remove_tags"`
+	RemoveFields []string          `toml:"remove_fields"`
+	AddTags      map[string]string `toml:"add_tags"`
+	Route        string            `toml:"route"`
+
+	program      cel.Program
+	removeTags   filter.Filter
+	removeFields filter.Filter
+}
+
+func (b *block) init(env *cel.Env) error {
+	if b.Condition != "" {
+		ast, issues := env.Compile(b.Condition)
+		if issues.Err() != nil {
+			return fmt.Errorf("compiling condition failed: %w", issues.Err())
+		}
+		if ast.OutputType() != cel.BoolType {
+			return errors.New("condition needs to return a boolean")
+		}
+		program, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize))
+		if err != nil {
+			return fmt.Errorf("creating program failed: %w", err)
+		}
+		b.program = program
+	}
+
+	var err error
+	b.removeTags, err = filter.Compile(b.RemoveTags)
+	if err != nil {
+		return fmt.Errorf("creating tag filter failed: %w", err)
+	}
+	b.removeFields, err = filter.Compile(b.RemoveFields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+
+	return nil
+}
+
+// matches evaluates the condition of the block for the given metric, blocks
+// without condition always match
+func (b *block) matches(m telegraf.Metric) (bool, error) {
+	if b.program == nil {
+		return true, nil
+	}
+
+	result, _, err := b.program.Eval(map[string]interface{}{
+		"name":   m.Name(),
+		"tags":   m.Tags(),
+		"fields": m.Fields(),
+		"time":   m.Time(),
+	})
+	if err != nil {
+		return false, err
+	}
+	if r, ok := result.Value().(bool); ok {
+		return r, nil
+	}
+	return false, fmt.Errorf("invalid result type %T", result.Value())
+}
+
+// apply modifies the metric according to the actions of the block, renames
+// are applied before removing and adding tags
+func (b *block) apply(m telegraf.Metric, routeTag string) {
+	for oldKey, newKey := range b.RenameTags {
+		if value, found := m.GetTag(oldKey); found {
+			m.RemoveTag(oldKey)
+			m.AddTag(newKey, value)
+		}
+	}
+	for oldKey, newKey := range b.RenameFields {
+		if value, found := m.GetField(oldKey); found {
+			m.RemoveField(oldKey)
+			m.AddField(newKey, value)
+		}
+	}
+
+	// Collect the keys first as removing modifies the underlying lists
+	if b.removeTags != nil {
+		var keys []string
+		for _, tag := range m.TagList() {
+			if b.removeTags.Match(tag.Key) {
+				keys = append(keys, tag.Key)
+			}
+		}
+		for _, key := range keys {
+			m.RemoveTag(key)
+		}
+	}
+	if b.removeFields != nil {
+		var keys []string
+		for _, field := range m.FieldList() {
+			if b.removeFields.Match(field.Key) {
+				keys = append(keys, field.Key)
+			}
+		}
+		for _, key := range keys {
+			m.RemoveField(key)
+		}
+	}
+
+	for k, v := range b.AddTags {
+		m.AddTag(k, v)
+	}
+	if b.Rename != "" {
+		m.SetName(b.Rename)
+	}
+	if b.Route != "" {
+		m.AddTag(routeTag, b.Route)
+	}
+}
+
+// newEnvironment creates the CEL environment for the conditions providing the
+// same variables and functions as the 'metricpass' selector
+func newEnvironment() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.VariableDecls(
+			decls.NewVariable("name", types.StringType),
+			decls.NewVariable("tags", types.NewMapType(types.StringType, types.StringType)),
+			decls.NewVariable("fields", types.NewMapType(types.StringType, types.DynType)),
+			decls.NewVariable("time", types.TimestampType),
+		),
+		cel.Function(
+			"now",
+			cel.Overload("now", nil, cel.TimestampType),
+			cel.SingletonFunctionBinding(func(_ ...ref.Val) ref.Val { return types.Timestamp{Time: time.Now()} }),
+		),
+		ext.Encoders(),
+		ext.Math(),
+		ext.Strings(),
+	)
+}
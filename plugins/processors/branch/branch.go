@@ -0,0 +1,95 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package branch
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Branch struct {
+	Blocks   []block         `toml:"block"`
+	RouteTag string          `toml:"route_tag"`
+	Log      telegraf.Logger `toml:"-"`
+}
+
+func (*Branch) SampleConfig() string {
+	return sampleConfig
+}
+
+func (b *Branch) Init() error {
+	if len(b.Blocks) == 0 {
+		return errors.New("no blocks defined")
+	}
+	if b.RouteTag == "" {
+		b.RouteTag = "route"
+	}
+
+	env, err := newEnvironment()
+	if err != nil {
+		return fmt.Errorf("creating environment failed: %w", err)
+	}
+
+	for i := range b.Blocks {
+		// A block without condition acts as the "else" branch so following
+		// blocks would never be evaluated
+		if b.Blocks[i].Condition == "" && i < len(b.Blocks)-1 {
+			return fmt.Errorf("block %d without condition must be the last block", i+1)
+		}
+		if err := b.Blocks[i].init(env); err != nil {
+			return fmt.Errorf("initialization of block %d failed: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (b *Branch) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		if b.applyBlocks(m) {
+			out = append(out, m)
+		} else {
+			m.Drop()
+		}
+	}
+	return out
+}
+
+// applyBlocks applies the actions of the first matching block and returns
+// false if the metric should be dropped
+func (b *Branch) applyBlocks(m telegraf.Metric) bool {
+	for i := range b.Blocks {
+		blk := &b.Blocks[i]
+		matches, err := blk.matches(m)
+		if err != nil {
+			// Evaluation errors, e.g. due to accessing non-existing tags or
+			// fields, are treated like a non-matching condition
+			b.Log.Debugf("Evaluating condition of block %d failed: %v", i+1, err)
+			continue
+		}
+		if !matches {
+			continue
+		}
+
+		if blk.Drop {
+			return false
+		}
+		blk.apply(m, b.RouteTag)
+		return true
+	}
+
+	return true
+}
+
+func init() {
+	processors.Add("branch", func() telegraf.Processor {
+		return &Branch{RouteTag: "route"}
+	})
+}
@@ -0,0 +1,268 @@
+package branch
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var testmetrics = []telegraf.Metric{
+	metric.New(
+		"cpu",
+		map[string]string{"host": "server01", "cpu": "cpu-total"},
+		map[string]interface{}{"usage_idle": 98.5, "usage_user": 1.5},
+		time.Unix(0, 0),
+	),
+	metric.New(
+		"cpu",
+		map[string]string{"host": "server01", "cpu": "cpu0"},
+		map[string]interface{}{"usage_idle": 12.0, "usage_user": 88.0},
+		time.Unix(0, 0),
+	),
+	metric.New(
+		"mem",
+		map[string]string{"host": "server01"},
+		map[string]interface{}{"used_percent": 42.0},
+		time.Unix(0, 0),
+	),
+	metric.New(
+		"disk",
+		map[string]string{"host": "server01", "path": "/boot"},
+		map[string]interface{}{"used_percent": 91.0},
+		time.Unix(0, 0),
+	),
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		blocks   []block
+		expected string
+	}{
+		{
+			name:     "no blocks",
+			expected: "no blocks defined",
+		},
+		{
+			name:     "invalid condition",
+			blocks:   []block{{Condition: "name =="}},
+			expected: "compiling condition failed",
+		},
+		{
+			name:     "non-boolean condition",
+			blocks:   []block{{Condition: "name"}},
+			expected: "condition needs to return a boolean",
+		},
+		{
+			name:     "else block not last",
+			blocks:   []block{{Drop: true}, {Condition: `name == "cpu"`}},
+			expected: "block 1 without condition must be the last block",
+		},
+		{
+			name:     "invalid tag pattern",
+			blocks:   []block{{Condition: `name == "cpu"`, RemoveTags: []string{"[abc"}}},
+			expected: "creating tag filter failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Branch{Blocks: tt.blocks, Log: testutil.Logger{}}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		blocks   []block
+		expected []telegraf.Metric
+	}{
+		{
+			name: "first matching block only",
+			blocks: []block{
+				{
+					Condition:  `name == "cpu" && tags.cpu == "cpu-total"`,
+					Rename:     "cpu_total",
+					RemoveTags: []string{"cpu"},
+				},
+				{
+					Condition: `name == "cpu"`,
+					AddTags:   map[string]string{"total": "false"},
+				},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"cpu_total",
+					map[string]string{"host": "server01"},
+					map[string]interface{}{"usage_idle": 98.5, "usage_user": 1.5},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"cpu",
+					map[string]string{"host": "server01", "cpu": "cpu0", "total": "false"},
+					map[string]interface{}{"usage_idle": 12.0, "usage_user": 88.0},
+					time.Unix(0, 0),
+				),
+				testmetrics[2],
+				testmetrics[3],
+			},
+		},
+		{
+			name: "else block",
+			blocks: []block{
+				{
+					Condition: `name == "cpu"`,
+					Route:     "metrics",
+				},
+				{
+					Route: "other",
+				},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"cpu",
+					map[string]string{"host": "server01", "cpu": "cpu-total", "route": "metrics"},
+					map[string]interface{}{"usage_idle": 98.5, "usage_user": 1.5},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"cpu",
+					map[string]string{"host": "server01", "cpu": "cpu0", "route": "metrics"},
+					map[string]interface{}{"usage_idle": 12.0, "usage_user": 88.0},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"mem",
+					map[string]string{"host": "server01", "route": "other"},
+					map[string]interface{}{"used_percent": 42.0},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"disk",
+					map[string]string{"host": "server01", "path": "/boot", "route": "other"},
+					map[string]interface{}{"used_percent": 91.0},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "drop",
+			blocks: []block{
+				{
+					Condition: `name == "cpu" && tags.cpu != "cpu-total"`,
+					Drop:      true,
+				},
+			},
+			expected: []telegraf.Metric{testmetrics[0], testmetrics[2], testmetrics[3]},
+		},
+		{
+			name: "rename tags and fields",
+			blocks: []block{
+				{
+					Condition:    `fields.used_percent > 90.0`,
+					RenameTags:   map[string]string{"path": "mountpoint"},
+					RenameFields: map[string]string{"used_percent": "usage"},
+					RemoveFields: []string{"inodes_*"},
+					AddTags:      map[string]string{"alert": "true"},
+				},
+			},
+			expected: []telegraf.Metric{
+				testmetrics[0],
+				testmetrics[1],
+				testmetrics[2],
+				metric.New(
+					"disk",
+					map[string]string{"host": "server01", "mountpoint": "/boot", "alert": "true"},
+					map[string]interface{}{"usage": 91.0},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "evaluation error falls through",
+			blocks: []block{
+				{
+					Condition: `tags.path == "/boot"`,
+					Drop:      true,
+				},
+				{
+					Condition: `name == "mem"`,
+					AddTags:   map[string]string{"matched": "true"},
+				},
+			},
+			expected: []telegraf.Metric{
+				testmetrics[0],
+				testmetrics[1],
+				metric.New(
+					"mem",
+					map[string]string{"host": "server01", "matched": "true"},
+					map[string]interface{}{"used_percent": 42.0},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Branch{Blocks: tt.blocks, Log: testutil.Logger{}}
+			require.NoError(t, plugin.Init())
+
+			input := make([]telegraf.Metric, 0, len(testmetrics))
+			for _, m := range testmetrics {
+				input = append(input, m.Copy())
+			}
+			actual := plugin.Apply(input...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestTracking(t *testing.T) {
+	inputRaw := testmetrics
+
+	// Create tracking metrics as inputs for the test
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, len(inputRaw))
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+	input := make([]telegraf.Metric, 0, len(inputRaw))
+	for _, m := range inputRaw {
+		tm, _ := metric.WithTracking(m.Copy(), notify)
+		input = append(input, tm)
+	}
+
+	// Setup the plugin dropping all cpu metrics
+	plugin := &Branch{
+		Blocks: []block{{Condition: `name == "cpu"`, Drop: true}},
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Process expected metrics and compare with resulting metrics
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{testmetrics[2], testmetrics[3]}, actual)
+
+	// Simulate output acknowledging delivery
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	// Check delivery
+	require.Eventuallyf(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(input) == len(delivered)
+	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(input))
+}
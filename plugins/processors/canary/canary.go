@@ -0,0 +1,278 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package canary
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Canary struct {
+	MetricName string          `toml:"metric_name"`
+	KeyTags    []string        `toml:"key_tags"`
+	Period     config.Duration `toml:"period"`
+	StatsName  string          `toml:"stats_name"`
+	Window     uint64          `toml:"window"`
+	Expiry     config.Duration `toml:"expiry"`
+	Drop       bool            `toml:"drop"`
+	Log        telegraf.Logger `toml:"-"`
+
+	acc    telegraf.Accumulator
+	series map[string]*series
+	mu     sync.Mutex
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// series contains the tracking state of a single canary series
+type series struct {
+	tags     map[string]string
+	runID    string
+	highest  uint64
+	missing  map[uint64]bool
+	lastSeen time.Time
+
+	// Statistics of the current period
+	received   int64
+	gaps       int64
+	late       int64
+	duplicates int64
+	restarts   int64
+	latencySum time.Duration
+	latencyMax time.Duration
+	latencyCnt int64
+}
+
+func (*Canary) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *Canary) Init() error {
+	if c.MetricName == "" {
+		return errors.New("'metric_name' required")
+	}
+	if c.StatsName == "" {
+		return errors.New("'stats_name' required")
+	}
+	if c.Period <= 0 {
+		return errors.New("'period' must be positive")
+	}
+	if c.Window < 1 {
+		return errors.New("'window' must be positive")
+	}
+
+	return nil
+}
+
+func (c *Canary) Start(acc telegraf.Accumulator) error {
+	c.acc = acc
+	c.series = make(map[string]*series)
+	c.done = make(chan struct{})
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(c.Period))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-ticker.C:
+				c.emit(time.Now())
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (c *Canary) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	if m.Name() != c.MetricName {
+		acc.AddMetric(m)
+		return nil
+	}
+
+	if err := c.verify(m, time.Now()); err != nil {
+		c.Log.Errorf("Verifying canary failed: %v", err)
+	}
+
+	if c.Drop {
+		m.Drop()
+		return nil
+	}
+	acc.AddMetric(m)
+
+	return nil
+}
+
+func (c *Canary) verify(m telegraf.Metric, now time.Time) error {
+	raw, found := m.GetField("sequence")
+	if !found {
+		return errors.New("missing sequence field")
+	}
+	seq, ok := raw.(uint64)
+	if !ok {
+		v, ok := raw.(int64)
+		if !ok || v < 0 {
+			return fmt.Errorf("invalid sequence %v (%T)", raw, raw)
+		}
+		seq = uint64(v)
+	}
+	runID, _ := m.GetField("run_id")
+	run, _ := runID.(string)
+
+	key, tags := c.key(m)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, found := c.series[key]
+	if !found {
+		s = &series{tags: tags, missing: make(map[uint64]bool)}
+		c.series[key] = s
+	}
+	s.lastSeen = now
+
+	if raw, found := m.GetField("sent"); found {
+		if sent, ok := raw.(int64); ok {
+			latency := now.Sub(time.Unix(0, sent))
+			s.latencySum += latency
+			s.latencyCnt++
+			if latency > s.latencyMax {
+				s.latencyMax = latency
+			}
+		}
+	}
+
+	switch {
+	case !found || run != s.runID:
+		// First canary of the series or the source restarted, so start
+		// tracking from the current sequence
+		if found {
+			s.restarts++
+		}
+		s.runID = run
+		s.highest = seq
+		clear(s.missing)
+		s.received++
+	case seq > s.highest:
+		// Sequence numbers skipped are counted as gaps, if they arrive later
+		// they are counted as late
+		start := s.highest + 1
+		if seq-start > c.Window {
+			start = seq - c.Window
+		}
+		for i := start; i < seq; i++ {
+			s.missing[i] = true
+		}
+		s.gaps += int64(seq - s.highest - 1)
+		s.highest = seq
+		s.received++
+
+		// Forget about sequence numbers outside of the window
+		for i := range s.missing {
+			if seq-i > c.Window {
+				delete(s.missing, i)
+			}
+		}
+	case s.missing[seq]:
+		delete(s.missing, seq)
+		s.late++
+		s.received++
+	case s.highest-seq >= c.Window:
+		s.late++
+		s.received++
+	default:
+		s.duplicates++
+	}
+
+	return nil
+}
+
+// key returns the series identifier and the key tags of the given metric
+func (c *Canary) key(m telegraf.Metric) (string, map[string]string) {
+	tags := make(map[string]string, len(c.KeyTags))
+	var b strings.Builder
+	for _, name := range c.KeyTags {
+		v, found := m.GetTag(name)
+		if !found {
+			continue
+		}
+		tags[name] = v
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(v)
+		b.WriteByte(0)
+	}
+	return b.String(), tags
+}
+
+func (c *Canary) Stop() {
+	close(c.done)
+	c.wg.Wait()
+
+	c.emit(time.Now())
+}
+
+// emit adds the statistics of all series and resets them for the next period
+func (c *Canary) emit(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, s := range c.series {
+		if c.Expiry > 0 && now.Sub(s.lastSeen) > time.Duration(c.Expiry) {
+			delete(c.series, key)
+			continue
+		}
+
+		fields := map[string]interface{}{
+			"received":   s.received,
+			"gaps":       s.gaps,
+			"late":       s.late,
+			"duplicates": s.duplicates,
+			"restarts":   s.restarts,
+			"missing":    int64(len(s.missing)),
+			"sequence":   s.highest,
+		}
+		if s.latencyCnt > 0 {
+			fields["latency_avg_ms"] = float64(s.latencySum) / float64(s.latencyCnt) / float64(time.Millisecond)
+			fields["latency_max_ms"] = float64(s.latencyMax) / float64(time.Millisecond)
+		}
+		c.acc.AddMetric(metric.New(c.StatsName, s.tags, fields, now))
+
+		s.received = 0
+		s.gaps = 0
+		s.late = 0
+		s.duplicates = 0
+		s.restarts = 0
+		s.latencySum = 0
+		s.latencyMax = 0
+		s.latencyCnt = 0
+	}
+}
+
+func init() {
+	processors.AddStreaming("canary", func() telegraf.StreamingProcessor {
+		return &Canary{
+			MetricName: "canary",
+			KeyTags:    []string{"source", "series"},
+			Period:     config.Duration(10 * time.Second),
+			StatsName:  "canary_stats",
+			Window:     1000,
+			Expiry:     config.Duration(10 * time.Minute),
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package canary
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func canary(runID string, seq uint64) telegraf.Metric {
+	return metric.New(
+		"canary",
+		map[string]string{"source": "edge01", "series": "0", "host": "relay"},
+		map[string]interface{}{"sequence": seq, "run_id": runID},
+		time.Unix(0, 0),
+	)
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*Canary)
+		expected string
+	}{
+		{
+			name:     "missing metric name",
+			modify:   func(p *Canary) { p.MetricName = "" },
+			expected: "'metric_name' required",
+		},
+		{
+			name:     "missing stats name",
+			modify:   func(p *Canary) { p.StatsName = "" },
+			expected: "'stats_name' required",
+		},
+		{
+			name:     "invalid period",
+			modify:   func(p *Canary) { p.Period = 0 },
+			expected: "'period' must be positive",
+		},
+		{
+			name:     "invalid window",
+			modify:   func(p *Canary) { p.Window = 0 },
+			expected: "'window' must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := processors.Processors["canary"]().(*Canary)
+			plugin.Log = &testutil.Logger{}
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	plugin := processors.Processors["canary"]().(*Canary)
+	plugin.Period = config.Duration(time.Hour)
+	plugin.Window = 5
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	// Sequence 1, 2, 5 (gap of 3 and 4), 3 (late), 5 (duplicate), restart
+	// with 1 and 2, gap of 10 exceeding the window followed by a late arrival
+	// outside of the window
+	for _, m := range []telegraf.Metric{
+		canary("a", 1),
+		canary("a", 2),
+		canary("a", 5),
+		canary("a", 3),
+		canary("a", 5),
+		canary("b", 1),
+		canary("b", 2),
+		canary("b", 13),
+		canary("b", 3),
+	} {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	// All canary metrics must be passed
+	require.Len(t, acc.GetTelegrafMetrics(), 10)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"canary_stats",
+			map[string]string{"source": "edge01", "series": "0"},
+			map[string]interface{}{
+				"received":   int64(8),
+				"gaps":       int64(12),
+				"late":       int64(2),
+				"duplicates": int64(1),
+				"restarts":   int64(1),
+				"missing":    int64(5),
+				"sequence":   uint64(13),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics()[9:], testutil.IgnoreTime())
+}
+
+func TestDrop(t *testing.T) {
+	plugin := processors.Processors["canary"]().(*Canary)
+	plugin.Period = config.Duration(time.Hour)
+	plugin.Drop = true
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	other := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.NoError(t, plugin.Add(canary("a", 1), &acc))
+	require.NoError(t, plugin.Add(other, &acc))
+	plugin.Stop()
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 2)
+	require.Equal(t, "cpu", metrics[0].Name())
+	require.Equal(t, "canary_stats", metrics[1].Name())
+}
+
+func TestLatencyAndExpiry(t *testing.T) {
+	plugin := processors.Processors["canary"]().(*Canary)
+	plugin.Period = config.Duration(time.Hour)
+	plugin.Expiry = config.Duration(time.Minute)
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	now := time.Now()
+	m := canary("a", 1)
+	m.AddField("sent", now.Add(-2*time.Second).UnixNano())
+	require.NoError(t, plugin.verify(m, now))
+	m = canary("a", 2)
+	m.AddField("sent", now.UnixNano())
+	require.NoError(t, plugin.verify(m, now))
+
+	plugin.emit(now)
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	avg, found := metrics[0].GetField("latency_avg_ms")
+	require.True(t, found)
+	require.InDelta(t, 1000.0, avg, 0.001)
+	highest, found := metrics[0].GetField("latency_max_ms")
+	require.True(t, found)
+	require.InDelta(t, 2000.0, highest, 0.001)
+
+	// Series without canaries must be removed after the expiry
+	acc.ClearMetrics()
+	plugin.emit(now.Add(2 * time.Minute))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -8,10 +8,12 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/internal/process"
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
@@ -25,12 +27,24 @@ type Execd struct {
 	Command      []string        `toml:"command"`
 	Environment  []string        `toml:"environment"`
 	RestartDelay config.Duration `toml:"restart_delay"`
+	Protocol     string          `toml:"protocol"`
+	BatchSize    int             `toml:"batch_size"`
+	BatchTimeout config.Duration `toml:"batch_timeout"`
+	AckTimeout   config.Duration `toml:"ack_timeout"`
 	Log          telegraf.Logger `toml:"-"`
 
 	parser     telegraf.Parser
 	serializer telegraf.Serializer
 	acc        telegraf.Accumulator
 	process    *process.Process
+
+	// State of the batching protocol, protected by pendingMtx
+	batch      []telegraf.Metric
+	seq        uint64
+	pending    map[uint64]*pendingBatch
+	pendingMtx sync.Mutex
+	done       chan struct{}
+	wg         sync.WaitGroup
 }
 
 func (*Execd) SampleConfig() string {
@@ -41,6 +55,25 @@ func (e *Execd) Init() error {
 	if len(e.Command) == 0 {
 		return errors.New("no command specified")
 	}
+
+	if e.Protocol == "" {
+		e.Protocol = "v1"
+	}
+	if err := choice.Check(e.Protocol, []string{"v1", "v2"}); err != nil {
+		return fmt.Errorf("config option protocol: %w", err)
+	}
+	if e.Protocol == "v2" {
+		if e.BatchSize < 1 {
+			return fmt.Errorf("invalid batch size %d", e.BatchSize)
+		}
+		if e.BatchTimeout <= 0 {
+			return errors.New("'batch_timeout' must be positive")
+		}
+		if e.AckTimeout <= 0 {
+			return errors.New("'ack_timeout' must be positive")
+		}
+	}
+
 	return nil
 }
 
@@ -64,6 +97,10 @@ func (e *Execd) Start(acc telegraf.Accumulator) error {
 	e.process.RestartDelay = time.Duration(e.RestartDelay)
 	e.process.ReadStdoutFn = e.cmdReadOut
 	e.process.ReadStderrFn = e.cmdReadErr
+	if e.Protocol == "v2" {
+		e.pending = make(map[uint64]*pendingBatch)
+		e.process.ReadStdoutFn = e.cmdReadBatches
+	}
 
 	if err = e.process.Start(); err != nil {
 		// if there was only one argument, and it contained spaces, warn the user
@@ -76,10 +113,18 @@ func (e *Execd) Start(acc telegraf.Accumulator) error {
 		return fmt.Errorf("failed to start process %s: %w", e.Command, err)
 	}
 
+	if e.Protocol == "v2" {
+		e.startFlusher()
+	}
+
 	return nil
 }
 
 func (e *Execd) Add(m telegraf.Metric, _ telegraf.Accumulator) error {
+	if e.Protocol == "v2" {
+		return e.addToBatch(m)
+	}
+
 	b, err := e.serializer.Serialize(m)
 	if err != nil {
 		return fmt.Errorf("metric serializing error: %w", err)
@@ -98,7 +143,13 @@ func (e *Execd) Add(m telegraf.Metric, _ telegraf.Accumulator) error {
 }
 
 func (e *Execd) Stop() {
+	if e.Protocol == "v2" {
+		e.stopFlusher()
+	}
 	e.process.Stop()
+	if e.Protocol == "v2" {
+		e.rejectPending()
+	}
 }
 
 func (e *Execd) cmdReadOut(out io.Reader) {
@@ -179,6 +230,10 @@ func init() {
 	processors.AddStreaming("execd", func() telegraf.StreamingProcessor {
 		return &Execd{
 			RestartDelay: config.Duration(10 * time.Second),
+			Protocol:     "v1",
+			BatchSize:    1000,
+			BatchTimeout: config.Duration(100 * time.Millisecond),
+			AckTimeout:   config.Duration(time.Minute),
 		}
 	})
 }
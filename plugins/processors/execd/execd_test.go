@@ -1,9 +1,11 @@
 package execd
 
 import (
+	"bufio"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -389,10 +391,218 @@ func TestTracking(t *testing.T) {
 	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(expected))
 }
 
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*Execd)
+		expected string
+	}{
+		{
+			name:     "no command",
+			modify:   func(e *Execd) { e.Command = nil },
+			expected: "no command specified",
+		},
+		{
+			name:     "invalid protocol",
+			modify:   func(e *Execd) { e.Protocol = "v3" },
+			expected: "config option protocol",
+		},
+		{
+			name:     "invalid batch size",
+			modify:   func(e *Execd) { e.BatchSize = 0 },
+			expected: "invalid batch size 0",
+		},
+		{
+			name:     "invalid batch timeout",
+			modify:   func(e *Execd) { e.BatchTimeout = 0 },
+			expected: "'batch_timeout' must be positive",
+		},
+		{
+			name:     "invalid ack timeout",
+			modify:   func(e *Execd) { e.AckTimeout = 0 },
+			expected: "'ack_timeout' must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Execd{
+				Command:      []string{"cat"},
+				Protocol:     "v2",
+				BatchSize:    1000,
+				BatchTimeout: config.Duration(100 * time.Millisecond),
+				AckTimeout:   config.Duration(time.Minute),
+				Log:          testutil.Logger{},
+			}
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestBatchProtocol(t *testing.T) {
+	tests := []struct {
+		name  string
+		crash bool
+	}{
+		{
+			name: "normal operation",
+		},
+		{
+			name:  "resend after restart",
+			crash: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+
+			// Create a testing notifier
+			var mu sync.Mutex
+			var delivered []telegraf.DeliveryInfo
+			notify := func(di telegraf.DeliveryInfo) {
+				mu.Lock()
+				defer mu.Unlock()
+				delivered = append(delivered, di)
+			}
+
+			var input, expected []telegraf.Metric
+			for i := range 25 {
+				m := metric.New(
+					"test",
+					map[string]string{"city": "Toronto"},
+					map[string]interface{}{"count": i},
+					now.Add(time.Duration(i)),
+				)
+				tm, _ := metric.WithTracking(m, notify)
+				input = append(input, tm)
+
+				e := m.Copy()
+				e.AddField("count", 2*i)
+				expected = append(expected, e)
+			}
+
+			// Setup the plugin
+			exe, err := os.Executable()
+			require.NoError(t, err)
+
+			command := []string{exe, "-case", "batch", "-field", "count"}
+			if tt.crash {
+				command = append(command, "-crash-marker", filepath.Join(t.TempDir(), "crashed"))
+			}
+			plugin := &Execd{
+				Command:      command,
+				Environment:  []string{"PLUGINS_PROCESSORS_EXECD_MODE=application"},
+				RestartDelay: config.Duration(10 * time.Millisecond),
+				Protocol:     "v2",
+				BatchSize:    10,
+				BatchTimeout: config.Duration(50 * time.Millisecond),
+				AckTimeout:   config.Duration(time.Minute),
+				Log:          testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			parser := &influx.Parser{}
+			require.NoError(t, parser.Init())
+			plugin.SetParser(parser)
+
+			serializer := &serializers_influx.Serializer{}
+			require.NoError(t, serializer.Init())
+			plugin.SetSerializer(serializer)
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			defer plugin.Stop()
+
+			for _, m := range input {
+				require.NoError(t, plugin.Add(m, &acc))
+			}
+
+			// The last incomplete batch is sent after the timeout
+			require.Eventually(t, func() bool {
+				return int(acc.NMetrics()) >= len(expected)
+			}, 3*time.Second, 10*time.Millisecond)
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+			// All input metrics must be delivered once acknowledged
+			require.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(delivered) == len(input)
+			}, time.Second, 10*time.Millisecond)
+			for _, di := range delivered {
+				require.True(t, di.Delivered())
+			}
+		})
+	}
+}
+
+func TestBatchProtocolAckTimeout(t *testing.T) {
+	// Create a testing notifier
+	var mu sync.Mutex
+	var delivered []telegraf.DeliveryInfo
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	// Setup the plugin with a process never acknowledging any batch
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	plugin := &Execd{
+		Command:      []string{exe, "-case", "noack"},
+		Environment:  []string{"PLUGINS_PROCESSORS_EXECD_MODE=application"},
+		RestartDelay: config.Duration(10 * time.Millisecond),
+		Protocol:     "v2",
+		BatchSize:    10,
+		BatchTimeout: config.Duration(50 * time.Millisecond),
+		AckTimeout:   config.Duration(100 * time.Millisecond),
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+	plugin.SetParser(parser)
+
+	serializer := &serializers_influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	for i := range 15 {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{"count": i}, time.Unix(0, 0))
+		tm, _ := metric.WithTracking(m, notify)
+		require.NoError(t, plugin.Add(tm, &acc))
+	}
+
+	// All batches must be dropped after the timeout
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(delivered) == 15
+	}, 3*time.Second, 10*time.Millisecond)
+	for _, di := range delivered {
+		require.False(t, di.Delivered())
+	}
+
+	plugin.pendingMtx.Lock()
+	defer plugin.pendingMtx.Unlock()
+	require.Empty(t, plugin.pending)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
 func TestMain(m *testing.M) {
-	var testcase, field string
-	flag.StringVar(&testcase, "case", "", "test-case to mock [multiply, long]")
+	var testcase, field, marker string
+	flag.StringVar(&testcase, "case", "", "test-case to mock [multiply, long, batch, noack]")
 	flag.StringVar(&field, "field", "count", "name of the field to multiply")
+	flag.StringVar(&marker, "crash-marker", "", "crash once after receiving the first batch if marker file does not exist")
 	flag.Parse()
 
 	if os.Getenv("PLUGINS_PROCESSORS_EXECD_MODE") != "application" || testcase == "" {
@@ -404,6 +614,10 @@ func TestMain(m *testing.M) {
 		os.Exit(runTestCaseMultiply(field))
 	case "long":
 		os.Exit(runTestCaseLong(field))
+	case "batch":
+		os.Exit(runTestCaseBatch(field, marker))
+	case "noack":
+		os.Exit(runTestCaseNoAck())
 	}
 	os.Exit(5)
 }
@@ -490,3 +704,77 @@ func runTestCaseLong(field string) int {
 		fmt.Fprint(os.Stdout, string(b))
 	}
 }
+
+func runTestCaseBatch(field, marker string) int {
+	parser := &influx.Parser{}
+	if err := parser.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "initialization ERR %v\n", err)
+		return 1
+	}
+	serializer := &serializers_influx.Serializer{}
+	if err := serializer.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "initialization ERR %v\n", err)
+		return 1
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0
+			}
+			fmt.Fprintf(os.Stderr, "ERR %v\n", err)
+			return 1
+		}
+		var seq uint64
+		var length int
+		if _, err := fmt.Sscanf(header, "BATCH %d %d\n", &seq, &length); err != nil {
+			fmt.Fprintf(os.Stderr, "header ERR %v\n", err)
+			return 1
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			fmt.Fprintf(os.Stderr, "payload ERR %v\n", err)
+			return 1
+		}
+
+		// Simulate a crash without acknowledging the batch
+		if marker != "" {
+			if _, err := os.Stat(marker); errors.Is(err, os.ErrNotExist) {
+				if err := os.WriteFile(marker, nil, 0600); err != nil {
+					fmt.Fprintf(os.Stderr, "marker ERR %v\n", err)
+				}
+				return 1
+			}
+		}
+
+		metrics, err := parser.Parse(payload)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "parse ERR %v\n", err)
+			return 1
+		}
+		for _, m := range metrics {
+			if v, found := m.GetField(field); found {
+				if iv, ok := v.(int64); ok {
+					m.AddField(field, iv*2)
+				}
+			}
+		}
+		result, err := serializer.SerializeBatch(metrics)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERR %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stdout, "ACK %d %d\n%s", seq, len(result), result)
+	}
+}
+
+func runTestCaseNoAck() int {
+	// Consume all batches without ever acknowledging them
+	if _, err := io.Copy(io.Discard, os.Stdin); err != nil {
+		fmt.Fprintf(os.Stderr, "ERR %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,267 @@
+package execd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// pendingBatch is a batch sent to the process but not yet acknowledged
+type pendingBatch struct {
+	metrics []telegraf.Metric
+	data    []byte
+	// stdin of the process instance the batch was sent to
+	target io.Writer
+	// time the batch was last sent to the process
+	sent time.Time
+}
+
+func (e *Execd) startFlusher() {
+	e.done = make(chan struct{})
+
+	// Periodically send incomplete batches to limit the latency and drop
+	// batches the process failed to acknowledge
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(e.BatchTimeout))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.done:
+				return
+			case <-ticker.C:
+				if err := e.flush(); err != nil {
+					e.Log.Errorf("Sending batch failed: %v", err)
+				}
+				e.expirePending()
+			}
+		}
+	}()
+}
+
+func (e *Execd) stopFlusher() {
+	close(e.done)
+	e.wg.Wait()
+
+	if err := e.flush(); err != nil {
+		e.Log.Errorf("Sending batch failed: %v", err)
+	}
+}
+
+func (e *Execd) addToBatch(m telegraf.Metric) error {
+	e.pendingMtx.Lock()
+	e.batch = append(e.batch, m)
+	full := len(e.batch) >= e.BatchSize
+	e.pendingMtx.Unlock()
+
+	if !full {
+		return nil
+	}
+	return e.flush()
+}
+
+// flush sends the current batch to the process
+func (e *Execd) flush() error {
+	batch, err := e.nextBatch()
+	if batch == nil || err != nil {
+		return err
+	}
+
+	// Write without holding the lock to not block reading acknowledgments
+	// while the process is busy
+	if _, err := batch.target.Write(batch.data); err != nil {
+		return fmt.Errorf("error writing to process stdin: %w", err)
+	}
+	return nil
+}
+
+// nextBatch turns the current batch into a pending batch waiting for its
+// acknowledgment, returns nil if there is nothing to send
+func (e *Execd) nextBatch() (*pendingBatch, error) {
+	e.pendingMtx.Lock()
+	defer e.pendingMtx.Unlock()
+
+	if len(e.batch) == 0 {
+		return nil, nil
+	}
+	metrics := e.batch
+	e.batch = nil
+
+	payload, err := e.serializer.SerializeBatch(metrics)
+	if err != nil {
+		// The batch will never be processed successfully so drop it
+		for _, m := range metrics {
+			m.Reject()
+		}
+		return nil, fmt.Errorf("metric serializing error: %w", err)
+	}
+
+	// Keep the batch until it is acknowledged to be able to resend it in
+	// case the process is restarted
+	e.seq++
+	batch := &pendingBatch{
+		metrics: metrics,
+		data:    append(fmt.Appendf(nil, "BATCH %d %d\n", e.seq, len(payload)), payload...),
+		target:  e.process.Stdin,
+		sent:    time.Now(),
+	}
+	e.pending[e.seq] = batch
+	return batch, nil
+}
+
+// resend sends all unacknowledged batches sent to a previous instance of the
+// process again in order
+func (e *Execd) resend() {
+	// Keep the order of the batches as acknowledgments might have been
+	// received out of order
+	stdin := e.process.Stdin
+	now := time.Now()
+	e.pendingMtx.Lock()
+	batches := make([]*pendingBatch, 0, len(e.pending))
+	for _, seq := range slices.Sorted(maps.Keys(e.pending)) {
+		if batch := e.pending[seq]; batch.target != stdin {
+			batch.target = stdin
+			batch.sent = now
+			batches = append(batches, batch)
+		}
+	}
+	e.pendingMtx.Unlock()
+
+	if len(batches) == 0 {
+		return
+	}
+	e.Log.Infof("Resending %d unacknowledged batches", len(batches))
+
+	for _, batch := range batches {
+		if _, err := stdin.Write(batch.data); err != nil {
+			e.Log.Errorf("Resending batches failed: %v", err)
+			return
+		}
+	}
+}
+
+// acknowledge marks the batch with the given sequence number as delivered
+func (e *Execd) acknowledge(seq uint64) {
+	e.pendingMtx.Lock()
+	batch, found := e.pending[seq]
+	delete(e.pending, seq)
+	e.pendingMtx.Unlock()
+
+	if !found {
+		e.Log.Debugf("Received acknowledgment for unknown batch %d", seq)
+		return
+	}
+	for _, m := range batch.metrics {
+		m.Accept()
+	}
+}
+
+// expirePending drops all batches not acknowledged within the timeout to
+// not accumulate batches in case the process fails to acknowledge them
+func (e *Execd) expirePending() {
+	deadline := time.Now().Add(-time.Duration(e.AckTimeout))
+
+	e.pendingMtx.Lock()
+	defer e.pendingMtx.Unlock()
+
+	var expired int
+	for seq, batch := range e.pending {
+		if batch.sent.After(deadline) {
+			continue
+		}
+		for _, m := range batch.metrics {
+			m.Reject()
+		}
+		delete(e.pending, seq)
+		expired++
+	}
+	if expired > 0 {
+		e.Log.Warnf("Dropping %d batches not acknowledged within %s", expired, time.Duration(e.AckTimeout))
+	}
+}
+
+// rejectPending drops all batches not acknowledged by the process
+func (e *Execd) rejectPending() {
+	e.pendingMtx.Lock()
+	defer e.pendingMtx.Unlock()
+
+	if len(e.pending) > 0 {
+		e.Log.Warnf("Dropping %d unacknowledged batches", len(e.pending))
+	}
+	for seq, batch := range e.pending {
+		for _, m := range batch.metrics {
+			m.Reject()
+		}
+		delete(e.pending, seq)
+	}
+}
+
+// cmdReadBatches reads the acknowledgments of the process, each consisting
+// of a header line "ACK <sequence number> <length>" followed by the given
+// number of bytes containing the resulting metrics of the batch
+func (e *Execd) cmdReadBatches(out io.Reader) {
+	// If the process was restarted, all unacknowledged batches are lost.
+	// Resend them concurrently to not block reading the acknowledgments.
+	go e.resend()
+
+	reader := bufio.NewReader(out)
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				e.Log.Errorf("Error reading stdout: %s", err)
+			}
+			return
+		}
+
+		seq, length, err := parseHeader(header)
+		if err != nil {
+			// We lost the framing so there is no way to recover
+			e.acc.AddError(fmt.Errorf("reading acknowledgment failed: %w", err))
+			return
+		}
+
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			e.Log.Errorf("Error reading payload of batch %d: %s", seq, err)
+			return
+		}
+
+		if length > 0 {
+			metrics, err := e.parser.Parse(payload)
+			if err != nil {
+				e.acc.AddError(fmt.Errorf("parsing result of batch %d failed: %w", seq, err))
+			}
+			for _, m := range metrics {
+				e.acc.AddMetric(m)
+			}
+		}
+		e.acknowledge(seq)
+	}
+}
+
+func parseHeader(line string) (seq uint64, length int, err error) {
+	parts := strings.Fields(line)
+	if len(parts) != 3 || parts[0] != "ACK" {
+		return 0, 0, fmt.Errorf("invalid header %q", strings.TrimSpace(line))
+	}
+	seq, err = strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid sequence number %q: %w", parts[1], err)
+	}
+	length, err = strconv.Atoi(parts[2])
+	if err != nil || length < 0 {
+		return 0, 0, fmt.Errorf("invalid length %q", parts[2])
+	}
+	return seq, length, nil
+}
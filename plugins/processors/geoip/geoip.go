@@ -0,0 +1,311 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package geoip
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// providers maps the provider name to the function for opening a database
+var providers = map[string]func(buf []byte) (reader, error){
+	"maxmind":     openMaxMind,
+	"ip2location": openIP2Location,
+}
+
+var availableTags = []string{
+	"country_code",
+	"country",
+	"region",
+	"city",
+	"latitude",
+	"longitude",
+	"asn",
+	"as_org",
+}
+
+type GeoIP struct {
+	Provider      string          `toml:"provider"`
+	Databases     []string        `toml:"databases"`
+	CheckInterval config.Duration `toml:"check_interval"`
+	CacheSize     int             `toml:"cache_size"`
+	Lookups       []lookupEntry   `toml:"lookup"`
+	Log           telegraf.Logger `toml:"-"`
+
+	open      func(buf []byte) (reader, error)
+	databases []*database
+	cache     *lru.Cache[string, *location]
+	lastCheck time.Time
+}
+
+type lookupEntry struct {
+	Tag    string   `toml:"tag"`
+	Field  string   `toml:"field"`
+	Prefix string   `toml:"prefix"`
+	Tags   []string `toml:"tags"`
+}
+
+// location contains the information found for an IP address
+type location struct {
+	CountryCode string
+	Country     string
+	Region      string
+	City        string
+	Latitude    float64
+	Longitude   float64
+	ASN         uint
+	ASOrg       string
+}
+
+// reader is the interface implemented by the database providers
+type reader interface {
+	lookup(ip net.IP) (*location, error)
+}
+
+type database struct {
+	filename string
+	modtime  time.Time
+	reader   reader
+}
+
+func (*GeoIP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GeoIP) Init() error {
+	if len(g.Databases) == 0 {
+		return errors.New("no databases specified")
+	}
+	if len(g.Lookups) == 0 {
+		return errors.New("no lookups specified")
+	}
+
+	if g.Provider == "" {
+		g.Provider = "maxmind"
+	}
+	open, found := providers[g.Provider]
+	if !found {
+		return fmt.Errorf("invalid provider %q", g.Provider)
+	}
+	g.open = open
+
+	for i, l := range g.Lookups {
+		if (l.Tag == "") == (l.Field == "") {
+			return fmt.Errorf("lookup %d: either 'tag' or 'field' must be specified", i+1)
+		}
+		if len(l.Tags) == 0 {
+			return fmt.Errorf("lookup %d: no tags specified", i+1)
+		}
+		if err := choice.CheckSlice(l.Tags, availableTags); err != nil {
+			return fmt.Errorf("lookup %d: config option tags: %w", i+1, err)
+		}
+	}
+
+	if g.CacheSize <= 0 {
+		g.CacheSize = 1000
+	}
+	cache, err := lru.New[string, *location](g.CacheSize)
+	if err != nil {
+		return fmt.Errorf("creating cache failed: %w", err)
+	}
+	g.cache = cache
+
+	g.databases = make([]*database, 0, len(g.Databases))
+	for _, fn := range g.Databases {
+		db := &database{filename: fn}
+		if err := g.load(db); err != nil {
+			return err
+		}
+		g.databases = append(g.databases, db)
+	}
+	g.lastCheck = time.Now()
+
+	return nil
+}
+
+func (g *GeoIP) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	if g.CheckInterval > 0 && time.Since(g.lastCheck) >= time.Duration(g.CheckInterval) {
+		g.reloadChanged()
+		g.lastCheck = time.Now()
+	}
+
+	for _, m := range in {
+		for _, l := range g.Lookups {
+			var address string
+			if l.Tag != "" {
+				address, _ = m.GetTag(l.Tag)
+			} else if v, found := m.GetField(l.Field); found {
+				address, _ = v.(string)
+			}
+			if address == "" {
+				continue
+			}
+
+			loc, err := g.lookup(address)
+			if err != nil {
+				g.Log.Debugf("Looking up %q failed: %v", address, err)
+				continue
+			}
+
+			for _, name := range l.Tags {
+				if value := loc.value(name); value != "" {
+					m.AddTag(l.Prefix+name, value)
+				}
+			}
+		}
+	}
+
+	return in
+}
+
+func (g *GeoIP) lookup(address string) (*location, error) {
+	if loc, found := g.cache.Get(address); found {
+		return loc, nil
+	}
+
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, errors.New("invalid IP address")
+	}
+
+	// Merge the results of all databases with earlier databases taking
+	// precedence for each information
+	loc := &location{}
+	for _, db := range g.databases {
+		result, err := db.reader.lookup(ip)
+		if err != nil {
+			return nil, fmt.Errorf("database %q: %w", db.filename, err)
+		}
+		loc.merge(result)
+	}
+	g.cache.Add(address, loc)
+
+	return loc, nil
+}
+
+func (g *GeoIP) load(db *database) error {
+	info, err := os.Stat(db.filename)
+	if err != nil {
+		return fmt.Errorf("accessing database %q failed: %w", db.filename, err)
+	}
+
+	// Read the whole database to not keep the file open so it can safely be
+	// replaced by update tools
+	buf, err := os.ReadFile(db.filename)
+	if err != nil {
+		return fmt.Errorf("reading database %q failed: %w", db.filename, err)
+	}
+	r, err := g.open(buf)
+	if err != nil {
+		return fmt.Errorf("opening database %q failed: %w", db.filename, err)
+	}
+
+	db.reader = r
+	db.modtime = info.ModTime()
+	return nil
+}
+
+func (g *GeoIP) reloadChanged() {
+	var changed bool
+	for _, db := range g.databases {
+		info, err := os.Stat(db.filename)
+		if err != nil {
+			g.Log.Errorf("Accessing database %q failed: %v", db.filename, err)
+			continue
+		}
+		if info.ModTime().Equal(db.modtime) {
+			continue
+		}
+
+		g.Log.Debugf("Reloading database %q", db.filename)
+		if err := g.load(db); err != nil {
+			// Keep the previous database as the file might be written partially
+			g.Log.Errorf("Reloading database failed: %v", err)
+			continue
+		}
+		changed = true
+	}
+
+	// Invalidate the cached results as they might be outdated
+	if changed {
+		g.cache.Purge()
+	}
+}
+
+func (l *location) merge(other *location) {
+	if l.CountryCode == "" {
+		l.CountryCode = other.CountryCode
+	}
+	if l.Country == "" {
+		l.Country = other.Country
+	}
+	if l.Region == "" {
+		l.Region = other.Region
+	}
+	if l.City == "" {
+		l.City = other.City
+	}
+	if l.Latitude == 0 && l.Longitude == 0 {
+		l.Latitude = other.Latitude
+		l.Longitude = other.Longitude
+	}
+	if l.ASN == 0 {
+		l.ASN = other.ASN
+	}
+	if l.ASOrg == "" {
+		l.ASOrg = other.ASOrg
+	}
+}
+
+func (l *location) value(name string) string {
+	switch name {
+	case "country_code":
+		return l.CountryCode
+	case "country":
+		return l.Country
+	case "region":
+		return l.Region
+	case "city":
+		return l.City
+	case "latitude":
+		if l.Latitude == 0 && l.Longitude == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(l.Latitude, 'f', -1, 64)
+	case "longitude":
+		if l.Latitude == 0 && l.Longitude == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(l.Longitude, 'f', -1, 64)
+	case "asn":
+		if l.ASN == 0 {
+			return ""
+		}
+		return strconv.FormatUint(uint64(l.ASN), 10)
+	case "as_org":
+		return l.ASOrg
+	}
+	return ""
+}
+
+func init() {
+	processors.Add("geoip", func() telegraf.Processor {
+		return &GeoIP{
+			CheckInterval: config.Duration(time.Minute),
+		}
+	})
+}
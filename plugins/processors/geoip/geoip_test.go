@@ -0,0 +1,226 @@
+package geoip
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// mockReader uses a JSON file mapping addresses to locations as database
+type mockReader struct {
+	entries map[string]*location
+	lookups int
+}
+
+func (r *mockReader) lookup(ip net.IP) (*location, error) {
+	r.lookups++
+	if loc, found := r.entries[ip.String()]; found {
+		return loc, nil
+	}
+	return nil, errors.New("not found")
+}
+
+var mockReaders []*mockReader
+
+func init() {
+	providers["mock"] = func(buf []byte) (reader, error) {
+		r := &mockReader{}
+		if err := json.Unmarshal(buf, &r.entries); err != nil {
+			return nil, err
+		}
+		mockReaders = append(mockReaders, r)
+		return r, nil
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *GeoIP
+		expected string
+	}{
+		{
+			name:     "no databases",
+			plugin:   &GeoIP{},
+			expected: "no databases specified",
+		},
+		{
+			name: "invalid provider",
+			plugin: &GeoIP{
+				Provider:  "foo",
+				Databases: []string{"test.db"},
+				Lookups:   []lookupEntry{{Tag: "ip", Tags: []string{"city"}}},
+			},
+			expected: `invalid provider "foo"`,
+		},
+		{
+			name: "tag and field",
+			plugin: &GeoIP{
+				Databases: []string{"test.db"},
+				Lookups:   []lookupEntry{{Tag: "ip", Field: "ip", Tags: []string{"city"}}},
+			},
+			expected: "lookup 1: either 'tag' or 'field' must be specified",
+		},
+		{
+			name: "invalid tag",
+			plugin: &GeoIP{
+				Databases: []string{"test.db"},
+				Lookups:   []lookupEntry{{Tag: "ip", Tags: []string{"planet"}}},
+			},
+			expected: "lookup 1: config option tags",
+		},
+		{
+			name: "missing database",
+			plugin: &GeoIP{
+				Databases: []string{"non-existing.mmdb"},
+				Lookups:   []lookupEntry{{Tag: "ip", Tags: []string{"city"}}},
+			},
+			expected: `accessing database "non-existing.mmdb" failed`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = &testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestMultipleLookups(t *testing.T) {
+	tmpdir := t.TempDir()
+	cityDB := filepath.Join(tmpdir, "city.json")
+	asnDB := filepath.Join(tmpdir, "asn.json")
+	require.NoError(t, os.WriteFile(cityDB, []byte(`{
+		"81.2.69.160": {"CountryCode": "GB", "City": "London", "Latitude": 51.5142, "Longitude": -0.0931},
+		"89.160.20.128": {"CountryCode": "SE", "City": "Linköping"}
+	}`), 0600))
+	require.NoError(t, os.WriteFile(asnDB, []byte(`{
+		"81.2.69.160": {"CountryCode": "XX", "ASN": 20712, "ASOrg": "Andrews & Arnold Ltd"},
+		"89.160.20.128": {"ASN": 29518, "ASOrg": "Bredband2 AB"}
+	}`), 0600))
+
+	plugin := &GeoIP{
+		Provider:  "mock",
+		Databases: []string{cityDB, asnDB},
+		Lookups: []lookupEntry{
+			{
+				Tag:    "src",
+				Prefix: "src_",
+				Tags:   []string{"country_code", "asn", "latitude", "longitude"},
+			},
+			{
+				Field:  "dst",
+				Prefix: "dst_",
+				Tags:   []string{"country_code", "city", "as_org"},
+			},
+		},
+		Log: &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New(
+			"flow",
+			map[string]string{"src": "81.2.69.160"},
+			map[string]interface{}{"dst": "89.160.20.128", "bytes": 1024},
+			time.Unix(1719410485, 0),
+		),
+		metric.New(
+			"flow",
+			map[string]string{"src": "invalid"},
+			map[string]interface{}{"dst": "10.0.0.1", "bytes": 512},
+			time.Unix(1719410485, 0),
+		),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"flow",
+			map[string]string{
+				"src":              "81.2.69.160",
+				"src_country_code": "GB",
+				"src_asn":          "20712",
+				"src_latitude":     "51.5142",
+				"src_longitude":    "-0.0931",
+				"dst_country_code": "SE",
+				"dst_city":         "Linköping",
+				"dst_as_org":       "Bredband2 AB",
+			},
+			map[string]interface{}{"dst": "89.160.20.128", "bytes": 1024},
+			time.Unix(1719410485, 0),
+		),
+		metric.New(
+			"flow",
+			map[string]string{"src": "invalid"},
+			map[string]interface{}{"dst": "10.0.0.1", "bytes": 512},
+			time.Unix(1719410485, 0),
+		),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestCacheAndReload(t *testing.T) {
+	mockReaders = nil
+
+	fn := filepath.Join(t.TempDir(), "city.json")
+	require.NoError(t, os.WriteFile(fn, []byte(`{"81.2.69.160": {"City": "London"}}`), 0600))
+
+	plugin := &GeoIP{
+		Provider:      "mock",
+		Databases:     []string{fn},
+		CheckInterval: 0,
+		Lookups:       []lookupEntry{{Tag: "ip", Tags: []string{"city"}}},
+		Log:           &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.Len(t, mockReaders, 1)
+
+	newMetric := func() telegraf.Metric {
+		return metric.New("test", map[string]string{"ip": "81.2.69.160"}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	}
+
+	// Repeated lookups should be served from the cache
+	for range 3 {
+		actual := plugin.Apply(newMetric())
+		require.Equal(t, "London", actual[0].Tags()["city"])
+	}
+	require.Equal(t, 1, mockReaders[0].lookups)
+
+	// Update the database and make sure the modification time differs
+	require.NoError(t, os.WriteFile(fn, []byte(`{"81.2.69.160": {"City": "Manchester"}}`), 0600))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(fn, future, future))
+
+	// Without a check interval, no reload should happen
+	actual := plugin.Apply(newMetric())
+	require.Equal(t, "London", actual[0].Tags()["city"])
+	require.Len(t, mockReaders, 1)
+
+	// Enabling the check should reload the database and invalidate the cache
+	plugin.CheckInterval = 1
+	actual = plugin.Apply(newMetric())
+	require.Equal(t, "Manchester", actual[0].Tags()["city"])
+	require.Len(t, mockReaders, 2)
+	require.Equal(t, 1, mockReaders[1].lookups)
+
+	// A broken database should not replace the current one
+	require.NoError(t, os.WriteFile(fn, []byte(`{"81.2.69.160": `), 0600))
+	future = future.Add(time.Minute)
+	require.NoError(t, os.Chtimes(fn, future, future))
+	plugin.cache.Purge()
+	actual = plugin.Apply(newMetric())
+	require.Equal(t, "Manchester", actual[0].Tags()["city"])
+}
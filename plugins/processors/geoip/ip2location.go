@@ -0,0 +1,74 @@
+package geoip
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/ip2location/ip2location-go/v9"
+)
+
+// Message returned by the library for information not contained in the
+// database or invalid input
+const (
+	ip2locationNotSupported   = "This parameter is unavailable for selected data file. Please upgrade the data file."
+	ip2locationInvalidAddress = "Invalid IP address."
+)
+
+type ip2locationReader struct {
+	db *ip2location.DB
+}
+
+// bufferReader allows to open the database from memory
+type bufferReader struct {
+	*bytes.Reader
+}
+
+func (*bufferReader) Close() error {
+	return nil
+}
+
+func openIP2Location(buf []byte) (reader, error) {
+	db, err := ip2location.OpenDBWithReader(&bufferReader{bytes.NewReader(buf)})
+	if err != nil {
+		return nil, err
+	}
+	return &ip2locationReader{db: db}, nil
+}
+
+func (r *ip2locationReader) lookup(ip net.IP) (*location, error) {
+	record, err := r.db.Get_all(ip.String())
+	if err != nil {
+		return nil, err
+	}
+	if record.Country_short == ip2locationInvalidAddress {
+		return nil, errors.New("invalid IP address")
+	}
+
+	loc := &location{
+		CountryCode: ip2locationValue(record.Country_short),
+		Country:     ip2locationValue(record.Country_long),
+		Region:      ip2locationValue(record.Region),
+		City:        ip2locationValue(record.City),
+		Latitude:    ip2locationCoordinate(record.Latitude),
+		Longitude:   ip2locationCoordinate(record.Longitude),
+	}
+
+	return loc, nil
+}
+
+// ip2locationCoordinate converts the coordinate to float64 without
+// introducing spurious digits due to the limited precision of float32
+func ip2locationCoordinate(v float32) float64 {
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'f', -1, 32), 64)
+	return f
+}
+
+func ip2locationValue(v string) string {
+	// Unknown values are reported as dash
+	if v == ip2locationNotSupported || v == "-" {
+		return ""
+	}
+	return v
+}
@@ -0,0 +1,62 @@
+package geoip
+
+import (
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// maxmindRecord covers the information contained in the City, Country and
+// ASN databases so the same record can be used for all database types
+type maxmindRecord struct {
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Location struct {
+		Latitude  float64 `maxminddb:"latitude"`
+		Longitude float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+	ASN   uint   `maxminddb:"autonomous_system_number"`
+	ASOrg string `maxminddb:"autonomous_system_organization"`
+}
+
+type maxmindReader struct {
+	db *maxminddb.Reader
+}
+
+func openMaxMind(buf []byte) (reader, error) {
+	db, err := maxminddb.FromBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+	return &maxmindReader{db: db}, nil
+}
+
+func (r *maxmindReader) lookup(ip net.IP) (*location, error) {
+	var record maxmindRecord
+	if err := r.db.Lookup(ip, &record); err != nil {
+		return nil, err
+	}
+
+	loc := &location{
+		CountryCode: record.Country.ISOCode,
+		Country:     record.Country.Names["en"],
+		City:        record.City.Names["en"],
+		Latitude:    record.Location.Latitude,
+		Longitude:   record.Location.Longitude,
+		ASN:         record.ASN,
+		ASOrg:       record.ASOrg,
+	}
+	if len(record.Subdivisions) > 0 {
+		loc.Region = record.Subdivisions[0].Names["en"]
+	}
+
+	return loc, nil
+}
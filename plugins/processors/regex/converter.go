@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/filter"
@@ -36,15 +37,18 @@ func (ct converterType) String() string {
 }
 
 type converter struct {
-	Key         string `toml:"key"`
-	Pattern     string `toml:"pattern"`
-	Replacement string `toml:"replacement"`
-	ResultKey   string `toml:"result_key"`
-	Append      bool   `toml:"append"`
+	Key          string            `toml:"key"`
+	Pattern      string            `toml:"pattern"`
+	Replacement  string            `toml:"replacement"`
+	ResultKey    string            `toml:"result_key"`
+	Append       bool              `toml:"append"`
+	Destinations map[string]string `toml:"destinations"`
+	LookupFile   string            `toml:"lookup_file"`
 
 	filter filter.Filter
 	re     *regexp.Regexp
 	groups []string
+	lookup *lookupTable
 	apply  func(m telegraf.Metric)
 }
 
@@ -80,6 +84,9 @@ func (c *converter) setup(ct converterType, log telegraf.Logger) error {
 			if allNamed {
 				log.Debugf("%s: Using named-group mode...", ct)
 				c.groups = groups[1:]
+				if err := c.checkDestinations(); err != nil {
+					return err
+				}
 			} else {
 				msg := "Neither 'result_key' nor 'replacement' given with unnamed or mixed groups;"
 				msg += " using explicit, empty replacement!"
@@ -88,6 +95,17 @@ func (c *converter) setup(ct converterType, log telegraf.Logger) error {
 		} else {
 			log.Debugf("%s: Using explicit mode...", ct)
 		}
+
+		if len(c.Destinations) > 0 && len(c.groups) == 0 {
+			return errors.New("'destinations' can only be used in named-group mode")
+		}
+
+		if c.LookupFile != "" {
+			c.lookup = &lookupTable{filename: c.LookupFile, interval: lookupCheckInterval, log: log}
+			if err := c.lookup.load(); err != nil {
+				return err
+			}
+		}
 	case convertTagRename, convertFieldRename:
 		switch c.ResultKey {
 		case "":
@@ -116,6 +134,49 @@ func (c *converter) setup(ct converterType, log telegraf.Logger) error {
 	return nil
 }
 
+func (c *converter) checkDestinations() error {
+	for group, dest := range c.Destinations {
+		if !slices.Contains(c.groups, group) {
+			return fmt.Errorf("destination for unknown group %q", group)
+		}
+		switch dest {
+		case "tag", "field":
+		default:
+			return fmt.Errorf("invalid destination %q for group %q", dest, group)
+		}
+	}
+	return nil
+}
+
+// addGroups adds the named-group matches to the metric, honoring the
+// configured destination of each group and using the given destination as
+// fallback for groups without explicit setting.
+func (c *converter) addGroups(m telegraf.Metric, matches []string, fallback string) {
+	for i, match := range matches[1:] {
+		if match == "" {
+			continue
+		}
+		name := c.groups[i]
+		match = c.lookup.replace(match)
+
+		if c.Append {
+			if v, ok := m.GetTag(name); ok {
+				match = v + match
+			}
+		}
+
+		dest := fallback
+		if d, found := c.Destinations[name]; found {
+			dest = d
+		}
+		if dest == "field" {
+			m.AddField(name, match)
+		} else {
+			m.AddTag(name, match)
+		}
+	}
+}
+
 func (c *converter) applyTags(m telegraf.Metric) {
 	for _, tag := range m.TagList() {
 		if !c.filter.Match(tag.Key) || !c.re.MatchString(tag.Value) {
@@ -124,19 +185,7 @@ func (c *converter) applyTags(m telegraf.Metric) {
 
 		// Handle named groups
 		if len(c.groups) > 0 {
-			matches := c.re.FindStringSubmatch(tag.Value)
-			for i, match := range matches[1:] {
-				if match == "" {
-					continue
-				}
-				name := c.groups[i]
-				if c.Append {
-					if v, ok := m.GetTag(name); ok {
-						match = v + match
-					}
-				}
-				m.AddTag(name, match)
-			}
+			c.addGroups(m, c.re.FindStringSubmatch(tag.Value), "tag")
 			continue
 		}
 
@@ -146,7 +195,7 @@ func (c *converter) applyTags(m telegraf.Metric) {
 			newKey = c.ResultKey
 		}
 
-		newValue := c.re.ReplaceAllString(tag.Value, c.Replacement)
+		newValue := c.lookup.replace(c.re.ReplaceAllString(tag.Value, c.Replacement))
 		if c.Append {
 			if v, ok := m.GetTag(newKey); ok {
 				newValue = v + newValue
@@ -169,19 +218,7 @@ func (c *converter) applyFields(m telegraf.Metric) {
 
 		// Handle named groups
 		if len(c.groups) > 0 {
-			matches := c.re.FindStringSubmatch(value)
-			for i, match := range matches[1:] {
-				if match == "" {
-					continue
-				}
-				name := c.groups[i]
-				if c.Append {
-					if v, ok := m.GetTag(name); ok {
-						match = v + match
-					}
-				}
-				m.AddField(name, match)
-			}
+			c.addGroups(m, c.re.FindStringSubmatch(value), "field")
 			continue
 		}
 
@@ -191,7 +228,7 @@ func (c *converter) applyFields(m telegraf.Metric) {
 			newKey = c.ResultKey
 		}
 
-		newValue := c.re.ReplaceAllString(value, c.Replacement)
+		newValue := c.lookup.replace(c.re.ReplaceAllString(value, c.Replacement))
 		m.AddField(newKey, newValue)
 	}
 }
@@ -0,0 +1,142 @@
+package regex
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// lookupCheckInterval is the minimum time between two checks of the lookup
+// file for modifications.
+const lookupCheckInterval = 10 * time.Second
+
+// lookupTable maps the result of a conversion to a replacement value. The
+// table is read from a file and reloaded whenever the file's modification
+// time changes.
+type lookupTable struct {
+	filename string
+	interval time.Duration
+	log      telegraf.Logger
+
+	modtime   time.Time
+	lastCheck time.Time
+	failing   bool
+	entries   map[string]string
+}
+
+func (l *lookupTable) load() error {
+	info, err := os.Stat(l.filename)
+	if err != nil {
+		return fmt.Errorf("accessing lookup file %q failed: %w", l.filename, err)
+	}
+
+	var entries map[string]string
+	switch strings.ToLower(filepath.Ext(l.filename)) {
+	case ".json":
+		entries, err = readJSONLookup(l.filename)
+	default:
+		entries, err = readCSVLookup(l.filename)
+	}
+	if err != nil {
+		return err
+	}
+
+	l.entries = entries
+	l.modtime = info.ModTime()
+	return nil
+}
+
+func (l *lookupTable) reloadIfChanged() {
+	if l == nil {
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(l.lastCheck) < l.interval {
+		return
+	}
+	l.lastCheck = now
+
+	info, err := os.Stat(l.filename)
+	if err != nil {
+		// Only report the error once until the file is accessible again to
+		// avoid flooding the log
+		if !l.failing {
+			l.log.Errorf("Accessing lookup file %q failed: %v", l.filename, err)
+			l.failing = true
+		}
+		return
+	}
+	if l.failing {
+		l.log.Infof("Lookup file %q is accessible again", l.filename)
+		l.failing = false
+	}
+	if info.ModTime().Equal(l.modtime) {
+		return
+	}
+
+	l.log.Debugf("Reloading lookup file %q", l.filename)
+	if err := l.load(); err != nil {
+		// Keep the previous table on errors, the file might be written partially
+		l.log.Errorf("Reloading lookup file failed: %v", err)
+	}
+}
+
+func (l *lookupTable) replace(value string) string {
+	if l == nil {
+		return value
+	}
+	if v, found := l.entries[value]; found {
+		return v
+	}
+	return value
+}
+
+func readJSONLookup(fn string) (map[string]string, error) {
+	buf, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, fmt.Errorf("loading %q failed: %w", fn, err)
+	}
+
+	var entries map[string]string
+	if err := json.Unmarshal(buf, &entries); err != nil {
+		return nil, fmt.Errorf("parsing %q failed: %w", fn, err)
+	}
+	return entries, nil
+}
+
+func readCSVLookup(fn string) (map[string]string, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, fmt.Errorf("loading %q failed: %w", fn, err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	entries := make(map[string]string)
+	line := 0
+	for {
+		line++
+		data, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("reading line %d in %q failed: %w", line, fn, err)
+		}
+		entries[data[0]] = data[1]
+	}
+	return entries, nil
+}
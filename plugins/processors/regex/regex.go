@@ -75,6 +75,14 @@ func (r *Regex) Init() error {
 }
 
 func (r *Regex) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	// Check the lookup files once per batch instead of once per metric
+	for _, c := range r.Tags {
+		c.lookup.reloadIfChanged()
+	}
+	for _, c := range r.Fields {
+		c.lookup.reloadIfChanged()
+	}
+
 	for _, metric := range in {
 		for _, c := range r.Tags {
 			c.apply(metric)
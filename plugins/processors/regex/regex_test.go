@@ -1,6 +1,8 @@
 package regex
 
 import (
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -820,6 +822,255 @@ func TestNamedGroups(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, actual)
 }
 
+func TestNamedGroupsDestinations(t *testing.T) {
+	regex := Regex{
+		Fields: []converter{
+			{
+				Key:          "request",
+				Pattern:      `^/api/(?P<method>\w+)[/?].*category=(?P<search_category>\w+)&(?:.*)`,
+				Destinations: map[string]string{"method": "tag"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, regex.Init())
+
+	input := testutil.MustMetric(
+		"access_log",
+		map[string]string{"verb": "GET"},
+		map[string]interface{}{
+			"request": "/api/search/?category=plugins&q=regex&sort=asc",
+		},
+		time.Unix(1695243874, 0),
+	)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"access_log",
+			map[string]string{
+				"verb":   "GET",
+				"method": "search",
+			},
+			map[string]interface{}{
+				"request":         "/api/search/?category=plugins&q=regex&sort=asc",
+				"search_category": "plugins",
+			},
+			time.Unix(1695243874, 0),
+		),
+	}
+	actual := regex.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestInvalidDestinations(t *testing.T) {
+	tests := []struct {
+		name      string
+		converter converter
+		expected  string
+	}{
+		{
+			name: "unknown group",
+			converter: converter{
+				Key:          "request",
+				Pattern:      `^/api/(?P<method>\w+)`,
+				Destinations: map[string]string{"foo": "tag"},
+			},
+			expected: `destination for unknown group "foo"`,
+		},
+		{
+			name: "invalid type",
+			converter: converter{
+				Key:          "request",
+				Pattern:      `^/api/(?P<method>\w+)`,
+				Destinations: map[string]string{"method": "name"},
+			},
+			expected: `invalid destination "name" for group "method"`,
+		},
+		{
+			name: "explicit mode",
+			converter: converter{
+				Key:          "request",
+				Pattern:      `^/api/(?P<method>\w+)`,
+				Replacement:  "${method}",
+				Destinations: map[string]string{"method": "tag"},
+			},
+			expected: "'destinations' can only be used in named-group mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regex := Regex{
+				Fields: []converter{tt.converter},
+				Log:    testutil.Logger{},
+			}
+			require.ErrorContains(t, regex.Init(), tt.expected)
+		})
+	}
+}
+
+func TestLookupFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		updated  string
+	}{
+		{
+			name:     "json",
+			filename: "lut.json",
+			content:  `{"2xx": "success", "4xx": "client error"}`,
+			updated:  `{"2xx": "ok"}`,
+		},
+		{
+			name:     "csv",
+			filename: "lut.csv",
+			content:  "# value,replacement\n2xx,success\n4xx,client error\n",
+			updated:  "2xx,ok\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := filepath.Join(t.TempDir(), tt.filename)
+			require.NoError(t, os.WriteFile(fn, []byte(tt.content), 0600))
+
+			regex := Regex{
+				Tags: []converter{
+					{
+						Key:         "resp_code",
+						Pattern:     "^(\\d)\\d\\d$",
+						Replacement: "${1}xx",
+						ResultKey:   "status",
+						LookupFile:  fn,
+					},
+				},
+				Log: testutil.Logger{},
+			}
+			require.NoError(t, regex.Init())
+
+			input := []telegraf.Metric{
+				metric.New("access_log", map[string]string{"resp_code": "200"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+				metric.New("access_log", map[string]string{"resp_code": "404"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+				metric.New("access_log", map[string]string{"resp_code": "503"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+			}
+			expected := []telegraf.Metric{
+				metric.New(
+					"access_log",
+					map[string]string{"resp_code": "200", "status": "success"},
+					map[string]interface{}{"value": 1},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"access_log",
+					map[string]string{"resp_code": "404", "status": "client error"},
+					map[string]interface{}{"value": 1},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"access_log",
+					map[string]string{"resp_code": "503", "status": "5xx"},
+					map[string]interface{}{"value": 1},
+					time.Unix(0, 0),
+				),
+			}
+			actual := regex.Apply(input...)
+			testutil.RequireMetricsEqual(t, expected, actual)
+
+			// Update the file and make sure the modification time differs
+			require.NoError(t, os.WriteFile(fn, []byte(tt.updated), 0600))
+			future := time.Now().Add(time.Minute)
+			require.NoError(t, os.Chtimes(fn, future, future))
+
+			// Skip the check interval to force checking the file
+			regex.Tags[0].lookup.lastCheck = time.Time{}
+
+			input = []telegraf.Metric{
+				metric.New("access_log", map[string]string{"resp_code": "200"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+			}
+			expected = []telegraf.Metric{
+				metric.New(
+					"access_log",
+					map[string]string{"resp_code": "200", "status": "ok"},
+					map[string]interface{}{"value": 1},
+					time.Unix(0, 0),
+				),
+			}
+			actual = regex.Apply(input...)
+			testutil.RequireMetricsEqual(t, expected, actual)
+		})
+	}
+}
+
+func TestLookupFileMissing(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "lut.csv")
+	require.NoError(t, os.WriteFile(fn, []byte("2xx,success\n"), 0600))
+
+	logger := &testutil.CaptureLogger{}
+	regex := Regex{
+		Tags: []converter{
+			{
+				Key:         "resp_code",
+				Pattern:     "^(\\d)\\d\\d$",
+				Replacement: "${1}xx",
+				ResultKey:   "status",
+				LookupFile:  fn,
+			},
+		},
+		Log: logger,
+	}
+	require.NoError(t, regex.Init())
+	regex.Tags[0].lookup.interval = 0
+
+	// Remove the file and make sure the error is only reported once while
+	// the previous table is still used
+	require.NoError(t, os.Remove(fn))
+	for range 3 {
+		m := metric.New("access_log", map[string]string{"resp_code": "200"}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+		actual := regex.Apply(m)
+		require.Len(t, actual, 1)
+		status, found := actual[0].GetTag("status")
+		require.True(t, found)
+		require.Equal(t, "success", status)
+	}
+	require.Len(t, logger.Errors(), 1)
+
+	// Restore the file and remove it again to get a new error
+	require.NoError(t, os.WriteFile(fn, []byte("2xx,ok\n"), 0600))
+	regex.Apply(metric.New("access_log", map[string]string{"resp_code": "200"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)))
+	require.NoError(t, os.Remove(fn))
+	regex.Apply(metric.New("access_log", map[string]string{"resp_code": "200"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)))
+	require.Len(t, logger.Errors(), 2)
+}
+
+func TestLookupFileCheckInterval(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "lut.csv")
+	require.NoError(t, os.WriteFile(fn, []byte("2xx,success\n"), 0600))
+
+	logger := &testutil.CaptureLogger{}
+	regex := Regex{
+		Tags: []converter{
+			{
+				Key:         "resp_code",
+				Pattern:     "^(\\d)\\d\\d$",
+				Replacement: "${1}xx",
+				ResultKey:   "status",
+				LookupFile:  fn,
+			},
+		},
+		Log: logger,
+	}
+	require.NoError(t, regex.Init())
+
+	// The file must not be checked again within the interval
+	regex.Apply(metric.New("access_log", map[string]string{"resp_code": "200"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)))
+	require.NoError(t, os.Remove(fn))
+	for range 3 {
+		regex.Apply(metric.New("access_log", map[string]string{"resp_code": "200"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)))
+	}
+	require.Empty(t, logger.Errors())
+}
+
 func TestNoMatches(t *testing.T) {
 	tests := []struct {
 		message        string
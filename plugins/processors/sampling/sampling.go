@@ -0,0 +1,110 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package sampling
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Sampling struct {
+	Mode      string             `toml:"mode"`
+	Rate      float64            `toml:"rate"`
+	Rates     map[string]float64 `toml:"rates"`
+	KeyTags   []string           `toml:"key_tags"`
+	RateField string             `toml:"rate_field"`
+	Log       telegraf.Logger    `toml:"-"`
+
+	// Function returning a random number in [0, 1), can be replaced for testing
+	random func() float64
+}
+
+func (*Sampling) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Sampling) Init() error {
+	if err := choice.Check(s.Mode, []string{"random", "consistent"}); err != nil {
+		return fmt.Errorf("config option mode: %w", err)
+	}
+	if s.Rate <= 0 || s.Rate > 1 {
+		return fmt.Errorf("rate %v out of range", s.Rate)
+	}
+	for name, rate := range s.Rates {
+		if rate <= 0 || rate > 1 {
+			return fmt.Errorf("rate %v for %q out of range", rate, name)
+		}
+	}
+	if s.Mode == "consistent" && len(s.KeyTags) == 0 {
+		return errors.New("'key_tags' required for mode \"consistent\"")
+	}
+
+	if s.random == nil {
+		s.random = rand.Float64
+	}
+
+	return nil
+}
+
+func (s *Sampling) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := in[:0]
+	for _, m := range in {
+		rate := s.Rate
+		if r, found := s.Rates[m.Name()]; found {
+			rate = r
+		}
+
+		// Skip the computation if all metrics are kept anyway
+		if rate < 1 && s.value(m) >= rate {
+			m.Drop()
+			continue
+		}
+
+		if s.RateField != "" {
+			m.AddField(s.RateField, rate)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// value returns a number in [0, 1) used for the sampling decision of the
+// metric. In consistent mode, the number is derived from the key tags so that
+// all metrics with the same tag values get the same decision for the same rate.
+func (s *Sampling) value(m telegraf.Metric) float64 {
+	if s.Mode != "consistent" {
+		return s.random()
+	}
+
+	h := fnv.New64a()
+	for _, key := range s.KeyTags {
+		v, _ := m.GetTag(key)
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	return float64(h.Sum64()) / (math.MaxUint64 + 1.0)
+}
+
+func newSampling() *Sampling {
+	return &Sampling{
+		Mode:    "random",
+		Rate:    1.0,
+		KeyTags: []string{"host"},
+	}
+}
+
+func init() {
+	processors.Add("sampling", func() telegraf.Processor {
+		return newSampling()
+	})
+}
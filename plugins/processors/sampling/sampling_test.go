@@ -0,0 +1,159 @@
+package sampling
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*Sampling)
+		expected string
+	}{
+		{
+			name:     "invalid mode",
+			modify:   func(s *Sampling) { s.Mode = "foo" },
+			expected: "config option mode",
+		},
+		{
+			name:     "rate zero",
+			modify:   func(s *Sampling) { s.Rate = 0 },
+			expected: "rate 0 out of range",
+		},
+		{
+			name:     "rate too large",
+			modify:   func(s *Sampling) { s.Rate = 1.5 },
+			expected: "rate 1.5 out of range",
+		},
+		{
+			name:     "invalid measurement rate",
+			modify:   func(s *Sampling) { s.Rates = map[string]float64{"cpu": -0.1} },
+			expected: `rate -0.1 for "cpu" out of range`,
+		},
+		{
+			name: "no key tags",
+			modify: func(s *Sampling) {
+				s.Mode = "consistent"
+				s.KeyTags = nil
+			},
+			expected: "'key_tags' required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newSampling()
+			plugin.Log = &testutil.Logger{}
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestRandom(t *testing.T) {
+	// Use a deterministic sequence of random numbers
+	values := []float64{0.05, 0.5, 0.95, 0.3, 0.15}
+	var idx int
+
+	plugin := newSampling()
+	plugin.Rate = 0.4
+	plugin.Rates = map[string]float64{"mem": 0.1}
+	plugin.RateField = "sample_rate"
+	plugin.Log = &testutil.Logger{}
+	plugin.random = func() float64 {
+		v := values[idx%len(values)]
+		idx++
+		return v
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, now),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2}, now),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 3}, now),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 4}, now),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"value": 5}, now),
+	}
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1, "sample_rate": 0.4}, now),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 4, "sample_rate": 0.4}, now),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestConsistent(t *testing.T) {
+	plugin := newSampling()
+	plugin.Mode = "consistent"
+	plugin.Rate = 0.5
+	plugin.Rates = map[string]float64{"mem": 0.25}
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	// Generate metrics of different measurements for many hosts
+	now := time.Now()
+	input := make([]telegraf.Metric, 0, 2000)
+	for i := range 1000 {
+		host := fmt.Sprintf("host%d", i)
+		input = append(input,
+			metric.New("cpu", map[string]string{"host": host}, map[string]interface{}{"value": i}, now),
+			metric.New("mem", map[string]string{"host": host}, map[string]interface{}{"value": i}, now),
+		)
+	}
+	actual := plugin.Apply(input...)
+
+	cpu := make(map[string]bool)
+	var mem int
+	for _, m := range actual {
+		host, _ := m.GetTag("host")
+		switch m.Name() {
+		case "cpu":
+			cpu[host] = true
+		case "mem":
+			// The hosts kept for the lower rate must be a subset of the
+			// hosts kept for the higher rate
+			require.Contains(t, cpu, host)
+			mem++
+		}
+	}
+	require.InDelta(t, 500, len(cpu), 75)
+	require.InDelta(t, 250, mem, 75)
+
+	// The decision must be the same for every metric of a host
+	for _, m := range input[:20] {
+		host, _ := m.GetTag("host")
+		kept := len(plugin.Apply(m.Copy())) > 0
+		if m.Name() == "cpu" {
+			require.Equal(t, cpu[host], kept, host)
+		}
+	}
+}
+
+func TestTracking(t *testing.T) {
+	plugin := newSampling()
+	plugin.Rate = 0.5
+	plugin.Log = &testutil.Logger{}
+	plugin.random = func() float64 { return 0.7 }
+	require.NoError(t, plugin.Init())
+
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) {
+		delivered++
+	}
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Now())
+	tm, _ := metric.WithTracking(m, notify)
+
+	actual := plugin.Apply(tm)
+	require.Empty(t, actual)
+	require.Equal(t, 1, delivered)
+}
@@ -0,0 +1,231 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package smooth
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Smooth struct {
+	IncludeFields []string        `toml:"include_fields"`
+	ExcludeFields []string        `toml:"exclude_fields"`
+	Method        string          `toml:"method"`
+	Alpha         float64         `toml:"alpha"`
+	Window        int             `toml:"window"`
+	Suffix        string          `toml:"suffix"`
+	OutlierSigma  float64         `toml:"outlier_sigma"`
+	Expiry        config.Duration `toml:"expiry"`
+	Log           telegraf.Logger `toml:"-"`
+
+	fields    filter.Filter
+	series    map[uint64]*seriesState
+	cleanedUp time.Time
+}
+
+type seriesState struct {
+	lastSeen time.Time
+	fields   map[string]*fieldState
+}
+
+type fieldState struct {
+	// Last accepted raw values, oldest first
+	values []float64
+	// Current value of the moving average
+	average float64
+	// Indicator for the previous value being rejected as outlier
+	rejected bool
+}
+
+func (*Smooth) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Smooth) Init() error {
+	if err := choice.Check(s.Method, []string{"ewma", "median"}); err != nil {
+		return fmt.Errorf("config option method: %w", err)
+	}
+	if s.Alpha <= 0 || s.Alpha > 1 {
+		return fmt.Errorf("alpha %v out of range", s.Alpha)
+	}
+	if s.Window < 1 {
+		return fmt.Errorf("invalid window %d", s.Window)
+	}
+	if s.OutlierSigma < 0 {
+		return errors.New("'outlier_sigma' must not be negative")
+	}
+	if s.OutlierSigma > 0 && s.Window < 2 {
+		return errors.New("outlier rejection requires a window of at least two values")
+	}
+
+	f, err := filter.NewIncludeExcludeFilter(s.IncludeFields, s.ExcludeFields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	s.fields = f
+	s.series = make(map[uint64]*seriesState)
+	s.cleanedUp = time.Now()
+
+	return nil
+}
+
+func (s *Smooth) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+
+	out := in[:0]
+	for _, m := range in {
+		id := m.HashID()
+		series, found := s.series[id]
+		if !found {
+			series = &seriesState{fields: make(map[string]*fieldState)}
+			s.series[id] = series
+		}
+		series.lastSeen = now
+
+		// Iterate over a copy as fields might be removed
+		for _, field := range slices.Clone(m.FieldList()) {
+			if !s.fields.Match(field.Key) {
+				continue
+			}
+			v, ok := toFloat(field.Value)
+			if !ok || math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+
+			state, found := series.fields[field.Key]
+			if !found {
+				state = &fieldState{average: v}
+				series.fields[field.Key] = state
+			}
+
+			if s.isOutlier(state, v) {
+				s.Log.Tracef("Rejecting outlier %v of field %q in %q", v, field.Key, m.Name())
+				m.RemoveField(field.Key)
+				continue
+			}
+			smoothed := s.update(state, v)
+
+			if s.Suffix == "" {
+				m.AddField(field.Key, smoothed)
+			} else {
+				m.AddField(field.Key+s.Suffix, smoothed)
+			}
+		}
+
+		// Drop metrics where all fields were rejected
+		if len(m.FieldList()) == 0 {
+			m.Drop()
+			continue
+		}
+		out = append(out, m)
+	}
+
+	s.cleanup(now)
+
+	return out
+}
+
+// isOutlier checks if the value deviates more than the configured number of
+// standard deviations from the mean of the window. Only single values are
+// rejected, i.e. a value following a rejected one is always accepted.
+func (s *Smooth) isOutlier(state *fieldState, v float64) bool {
+	if s.OutlierSigma == 0 || len(state.values) < s.Window {
+		return false
+	}
+	if state.rejected {
+		state.rejected = false
+		return false
+	}
+
+	var mean float64
+	for _, x := range state.values {
+		mean += x
+	}
+	mean /= float64(len(state.values))
+
+	var variance float64
+	for _, x := range state.values {
+		variance += (x - mean) * (x - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(state.values)-1))
+
+	state.rejected = math.Abs(v-mean) > s.OutlierSigma*stddev
+	return state.rejected
+}
+
+// update adds the value to the state and returns the smoothed value
+func (s *Smooth) update(state *fieldState, v float64) float64 {
+	if len(state.values) == s.Window {
+		state.values = state.values[1:]
+	}
+	state.values = append(state.values, v)
+
+	switch s.Method {
+	case "median":
+		sorted := slices.Clone(state.values)
+		slices.Sort(sorted)
+		n := len(sorted)
+		if n%2 == 1 {
+			return sorted[n/2]
+		}
+		return (sorted[n/2-1] + sorted[n/2]) / 2
+	default:
+		state.average += s.Alpha * (v - state.average)
+		return state.average
+	}
+}
+
+// cleanup removes the state of series not seen within the expiry time
+func (s *Smooth) cleanup(now time.Time) {
+	// No need to cleanup too often
+	if s.Expiry <= 0 || now.Sub(s.cleanedUp) < time.Duration(s.Expiry) {
+		return
+	}
+	s.cleanedUp = now
+
+	for id, series := range s.series {
+		if now.Sub(series.lastSeen) >= time.Duration(s.Expiry) {
+			delete(s.series, id)
+		}
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func newSmooth() *Smooth {
+	return &Smooth{
+		Method: "ewma",
+		Alpha:  0.5,
+		Window: 5,
+		Expiry: config.Duration(time.Hour),
+	}
+}
+
+func init() {
+	processors.Add("smooth", func() telegraf.Processor {
+		return newSmooth()
+	})
+}
@@ -0,0 +1,186 @@
+package smooth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*Smooth)
+		expected string
+	}{
+		{
+			name:     "invalid method",
+			modify:   func(s *Smooth) { s.Method = "foo" },
+			expected: "config option method",
+		},
+		{
+			name:     "alpha zero",
+			modify:   func(s *Smooth) { s.Alpha = 0 },
+			expected: "alpha 0 out of range",
+		},
+		{
+			name:     "alpha too large",
+			modify:   func(s *Smooth) { s.Alpha = 1.5 },
+			expected: "alpha 1.5 out of range",
+		},
+		{
+			name:     "invalid window",
+			modify:   func(s *Smooth) { s.Window = 0 },
+			expected: "invalid window 0",
+		},
+		{
+			name:     "negative sigma",
+			modify:   func(s *Smooth) { s.OutlierSigma = -1 },
+			expected: "'outlier_sigma' must not be negative",
+		},
+		{
+			name: "window too small for outliers",
+			modify: func(s *Smooth) {
+				s.Window = 1
+				s.OutlierSigma = 3
+			},
+			expected: "requires a window of at least two values",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newSmooth()
+			plugin.Log = &testutil.Logger{}
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestSmoothing(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		method   string
+		suffix   string
+		input    []telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "ewma",
+			method: "ewma",
+			input: []telegraf.Metric{
+				metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 10.0, "status": "ok"}, now),
+				metric.New("test", map[string]string{"id": "b"}, map[string]interface{}{"value": int64(100)}, now),
+				metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 20.0, "status": "ok"}, now),
+				metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 30.0, "status": "ok"}, now),
+				metric.New("test", map[string]string{"id": "b"}, map[string]interface{}{"value": int64(0)}, now),
+			},
+			expected: []telegraf.Metric{
+				metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 10.0, "status": "ok"}, now),
+				metric.New("test", map[string]string{"id": "b"}, map[string]interface{}{"value": 100.0}, now),
+				metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 15.0, "status": "ok"}, now),
+				metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 22.5, "status": "ok"}, now),
+				metric.New("test", map[string]string{"id": "b"}, map[string]interface{}{"value": 50.0}, now),
+			},
+		},
+		{
+			name:   "median with suffix",
+			method: "median",
+			suffix: "_smoothed",
+			input: []telegraf.Metric{
+				metric.New("test", nil, map[string]interface{}{"value": 10.0}, now),
+				metric.New("test", nil, map[string]interface{}{"value": 30.0}, now),
+				metric.New("test", nil, map[string]interface{}{"value": 20.0}, now),
+				metric.New("test", nil, map[string]interface{}{"value": 100.0}, now),
+			},
+			expected: []telegraf.Metric{
+				metric.New("test", nil, map[string]interface{}{"value": 10.0, "value_smoothed": 10.0}, now),
+				metric.New("test", nil, map[string]interface{}{"value": 30.0, "value_smoothed": 20.0}, now),
+				metric.New("test", nil, map[string]interface{}{"value": 20.0, "value_smoothed": 20.0}, now),
+				metric.New("test", nil, map[string]interface{}{"value": 100.0, "value_smoothed": 30.0}, now),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newSmooth()
+			plugin.Method = tt.method
+			plugin.Window = 3
+			plugin.Suffix = tt.suffix
+			plugin.Log = &testutil.Logger{}
+			require.NoError(t, plugin.Init())
+
+			actual := plugin.Apply(tt.input...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestOutlierRejection(t *testing.T) {
+	now := time.Now()
+
+	plugin := newSmooth()
+	plugin.Alpha = 1.0
+	plugin.Window = 4
+	plugin.OutlierSigma = 3
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var input []telegraf.Metric
+	for _, v := range []float64{10, 11, 9, 10, 50, 10, 80, 81} {
+		input = append(input, metric.New("test", nil, map[string]interface{}{"value": v, "other": int64(1)}, now))
+	}
+	// Add a metric only containing an outlier
+	input = append(input,
+		metric.New("test", nil, map[string]interface{}{"value": 80.0}, now),
+		metric.New("test", nil, map[string]interface{}{"value": 1000.0}, now),
+	)
+
+	// The single outliers 50 and 1000 must be rejected, while the level
+	// change to 80 is accepted at the second value
+	expected := []telegraf.Metric{
+		metric.New("test", nil, map[string]interface{}{"value": 10.0, "other": 1.0}, now),
+		metric.New("test", nil, map[string]interface{}{"value": 11.0, "other": 1.0}, now),
+		metric.New("test", nil, map[string]interface{}{"value": 9.0, "other": 1.0}, now),
+		metric.New("test", nil, map[string]interface{}{"value": 10.0, "other": 1.0}, now),
+		metric.New("test", nil, map[string]interface{}{"other": 1.0}, now),
+		metric.New("test", nil, map[string]interface{}{"value": 10.0, "other": 1.0}, now),
+		metric.New("test", nil, map[string]interface{}{"other": 1.0}, now),
+		metric.New("test", nil, map[string]interface{}{"value": 81.0, "other": 1.0}, now),
+		metric.New("test", nil, map[string]interface{}{"value": 80.0}, now),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestTracking(t *testing.T) {
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) { delivered++ }
+
+	plugin := newSmooth()
+	plugin.Window = 2
+	plugin.OutlierSigma = 1
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var input []telegraf.Metric
+	for _, v := range []float64{10, 11, 1000} {
+		m := metric.New("test", nil, map[string]interface{}{"value": v}, time.Now())
+		tm, _ := metric.WithTracking(m, notify)
+		input = append(input, tm)
+	}
+
+	// The outlier metric is dropped and must be marked as delivered
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 2)
+	require.Equal(t, 1, delivered)
+}
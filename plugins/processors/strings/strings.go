@@ -4,10 +4,17 @@ package strings
 import (
 	_ "embed"
 	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
 	"unicode"
 	"unicode/utf8"
 
+	"github.com/Masterminds/sprig/v3"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
@@ -18,6 +25,9 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+// Formatting directives of the format_number function
+var formatVerbRe = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]*)?([a-zA-Z%])`)
+
 type Strings struct {
 	Lowercase    []converter `toml:"lowercase"`
 	Uppercase    []converter `toml:"uppercase"`
@@ -31,6 +41,15 @@ type Strings struct {
 	Left         []converter `toml:"left"`
 	Base64Decode []converter `toml:"base64decode"`
 	ValidUTF8    []converter `toml:"valid_utf8"`
+	Split        []converter `toml:"split"`
+	Substring    []converter `toml:"substring"`
+	PadLeft      []converter `toml:"pad_left"`
+	PadRight     []converter `toml:"pad_right"`
+	FormatNumber []converter `toml:"format_number"`
+	BaseConvert  []converter `toml:"base_convert"`
+	Template     []converter `toml:"template"`
+
+	Log telegraf.Logger `toml:"-"`
 
 	converters []converter
 	init       bool
@@ -38,6 +57,10 @@ type Strings struct {
 
 type convertFunc func(s string) string
 
+// convertValueFunc converts non-string field values, the boolean return
+// value denotes if the value could be converted
+type convertValueFunc func(v interface{}) (string, bool)
+
 type converter struct {
 	Field       string `toml:"field"`
 	FieldKey    string `toml:"field_key"`
@@ -52,14 +75,78 @@ type converter struct {
 	New         string `toml:"new"`
 	Width       int    `toml:"width"`
 	Replacement string `toml:"replacement"`
-
-	fn convertFunc
+	Delimiter   string `toml:"delimiter"`
+	Index       int    `toml:"index"`
+	Start       int    `toml:"start"`
+	Length      int    `toml:"length"`
+	Padding     string `toml:"padding"`
+	Format      string `toml:"format"`
+	FromBase    int    `toml:"from_base"`
+	ToBase      int    `toml:"to_base"`
+	Template    string `toml:"template"`
+	DestType    string `toml:"dest_type"`
+
+	fn      convertFunc
+	valueFn convertValueFunc
+	tmpl    *template.Template
+	log     telegraf.Logger
 }
 
 func (*Strings) SampleConfig() string {
 	return sampleConfig
 }
 
+func (s *Strings) Init() error {
+	for _, c := range s.Split {
+		if c.Delimiter == "" {
+			return errors.New("'delimiter' required for split")
+		}
+	}
+	for _, c := range s.Substring {
+		if c.Length < 0 {
+			return errors.New("'length' of substring cannot be negative")
+		}
+	}
+	for _, c := range slices.Concat(s.PadLeft, s.PadRight) {
+		if c.Width <= 0 {
+			return errors.New("'width' required for padding")
+		}
+		if utf8.RuneCountInString(c.Padding) > 1 {
+			return fmt.Errorf("padding %q must be a single character", c.Padding)
+		}
+	}
+	for _, c := range s.FormatNumber {
+		if _, err := formatVerb(c.Format); err != nil {
+			return fmt.Errorf("invalid format %q: %w", c.Format, err)
+		}
+	}
+	for _, c := range s.BaseConvert {
+		if c.FromBase != 0 && (c.FromBase < 2 || c.FromBase > 36) {
+			return fmt.Errorf("'from_base' %d not in range [2,36]", c.FromBase)
+		}
+		if c.ToBase != 0 && (c.ToBase < 2 || c.ToBase > 36) {
+			return fmt.Errorf("'to_base' %d not in range [2,36]", c.ToBase)
+		}
+	}
+	for _, c := range s.Template {
+		if c.Dest == "" {
+			return errors.New("'dest' required for template")
+		}
+		switch c.DestType {
+		case "", "field", "tag":
+		default:
+			return fmt.Errorf("invalid 'dest_type' %q", c.DestType)
+		}
+		if _, err := newTemplate(c.Template); err != nil {
+			return fmt.Errorf("creating template failed: %w", err)
+		}
+	}
+
+	s.initOnce()
+
+	return nil
+}
+
 func (s *Strings) Apply(in ...telegraf.Metric) []telegraf.Metric {
 	s.initOnce()
 
@@ -133,6 +220,10 @@ func (c *converter) convertField(metric telegraf.Metric) {
 		}
 		if fv, ok := value.(string); ok {
 			metric.AddField(dest, c.fn(fv))
+		} else if c.valueFn != nil {
+			if v, ok := c.valueFn(value); ok {
+				metric.AddField(dest, v)
+			}
 		}
 	}
 }
@@ -166,7 +257,36 @@ func (c *converter) convertMeasurement(metric telegraf.Metric) {
 	metric.SetName(c.fn(metric.Name()))
 }
 
+func (c *converter) applyTemplate(metric telegraf.Metric) {
+	m := metric
+	if wm, ok := metric.(telegraf.UnwrappableMetric); ok {
+		m = wm.Unwrap()
+	}
+	tm, ok := m.(telegraf.TemplateMetric)
+	if !ok {
+		c.log.Errorf("metric of type %T is not a template metric", metric)
+		return
+	}
+
+	var b strings.Builder
+	if err := c.tmpl.Execute(&b, tm); err != nil {
+		c.log.Errorf("failed to execute template for %q: %v", c.Dest, err)
+		return
+	}
+
+	if c.DestType == "tag" {
+		metric.AddTag(c.Dest, b.String())
+	} else {
+		metric.AddField(c.Dest, b.String())
+	}
+}
+
 func (c *converter) convert(metric telegraf.Metric) {
+	if c.tmpl != nil {
+		c.applyTemplate(metric)
+		return
+	}
+
 	if c.Field != "" {
 		c.convertField(metric)
 	}
@@ -279,9 +399,183 @@ func (s *Strings) initOnce() {
 		s.converters = append(s.converters, c)
 	}
 
+	for _, c := range s.Split {
+		c.fn = func(s string) string {
+			parts := strings.Split(s, c.Delimiter)
+			idx := c.Index
+			if idx < 0 {
+				idx += len(parts)
+			}
+			if idx < 0 || idx >= len(parts) {
+				return s
+			}
+			return parts[idx]
+		}
+		s.converters = append(s.converters, c)
+	}
+	for _, c := range s.Substring {
+		c.fn = func(s string) string {
+			runes := []rune(s)
+			start := c.Start
+			if start < 0 {
+				start += len(runes)
+			}
+			if start < 0 || start >= len(runes) {
+				return s
+			}
+			end := len(runes)
+			if c.Length > 0 && start+c.Length < end {
+				end = start + c.Length
+			}
+			return string(runes[start:end])
+		}
+		s.converters = append(s.converters, c)
+	}
+	for _, c := range s.PadLeft {
+		padding := c.Padding
+		if padding == "" {
+			padding = " "
+		}
+		c.fn = func(s string) string {
+			if n := c.Width - utf8.RuneCountInString(s); n > 0 {
+				return strings.Repeat(padding, n) + s
+			}
+			return s
+		}
+		s.converters = append(s.converters, c)
+	}
+	for _, c := range s.PadRight {
+		padding := c.Padding
+		if padding == "" {
+			padding = " "
+		}
+		c.fn = func(s string) string {
+			if n := c.Width - utf8.RuneCountInString(s); n > 0 {
+				return s + strings.Repeat(padding, n)
+			}
+			return s
+		}
+		s.converters = append(s.converters, c)
+	}
+	for _, c := range s.FormatNumber {
+		verb, err := formatVerb(c.Format)
+		if err != nil {
+			// Invalid formats are rejected in Init
+			continue
+		}
+		c.valueFn = func(v interface{}) (string, bool) {
+			return formatNumber(c.Format, verb, v)
+		}
+		c.fn = func(s string) string {
+			var v interface{}
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				v = i
+			} else if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+				v = u
+			} else if f, err := strconv.ParseFloat(s, 64); err == nil {
+				v = f
+			} else {
+				return s
+			}
+			if r, ok := formatNumber(c.Format, verb, v); ok {
+				return r
+			}
+			return s
+		}
+		s.converters = append(s.converters, c)
+	}
+	for _, c := range s.BaseConvert {
+		toBase := c.ToBase
+		if toBase == 0 {
+			toBase = 10
+		}
+		c.valueFn = func(v interface{}) (string, bool) {
+			switch v := v.(type) {
+			case int64:
+				return strconv.FormatInt(v, toBase), true
+			case uint64:
+				return strconv.FormatUint(v, toBase), true
+			}
+			return "", false
+		}
+		c.fn = func(s string) string {
+			if v, err := strconv.ParseInt(s, c.FromBase, 64); err == nil {
+				return strconv.FormatInt(v, toBase)
+			}
+			if v, err := strconv.ParseUint(s, c.FromBase, 64); err == nil {
+				return strconv.FormatUint(v, toBase)
+			}
+			return s
+		}
+		s.converters = append(s.converters, c)
+	}
+	for _, c := range s.Template {
+		tmpl, err := newTemplate(c.Template)
+		if err != nil {
+			// Invalid templates are rejected in Init
+			continue
+		}
+		c.tmpl = tmpl
+		c.log = s.Log
+		s.converters = append(s.converters, c)
+	}
+
 	s.init = true
 }
 
+func newTemplate(text string) (*template.Template, error) {
+	return template.New("template").Funcs(sprig.TxtFuncMap()).Parse(text)
+}
+
+// formatVerb returns the verb of the single formatting directive in the
+// given format string
+func formatVerb(format string) (byte, error) {
+	var verb byte
+	for _, m := range formatVerbRe.FindAllStringSubmatch(format, -1) {
+		if m[1] == "%" {
+			continue
+		}
+		if verb != 0 {
+			return 0, errors.New("more than one formatting directive")
+		}
+		verb = m[1][0]
+	}
+
+	switch verb {
+	case 0:
+		return 0, errors.New("missing formatting directive")
+	case 'b', 'c', 'd', 'o', 'x', 'X', 'e', 'E', 'f', 'F', 'g', 'G', 's', 'v', 'q':
+		return verb, nil
+	}
+	return 0, fmt.Errorf("unsupported verb %q", verb)
+}
+
+// formatNumber formats the given numeric value after converting it to the
+// type expected by the formatting verb
+func formatNumber(format string, verb byte, v interface{}) (string, bool) {
+	var f float64
+	switch n := v.(type) {
+	case int64:
+		f = float64(n)
+	case uint64:
+		f = float64(n)
+	case float64:
+		f = n
+	default:
+		return "", false
+	}
+
+	switch verb {
+	case 'b', 'c', 'd', 'o', 'x', 'X':
+		if _, isFloat := v.(float64); isFloat {
+			v = int64(f)
+		}
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		v = f
+	}
+	return fmt.Sprintf(format, v), true
+}
+
 func init() {
 	processors.Add("strings", func() telegraf.Processor {
 		return &Strings{}
@@ -1199,3 +1199,214 @@ func TestTrackedMetricNotLost(t *testing.T) {
 		return len(input) == len(delivered)
 	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(expected))
 }
+
+func TestStringOperations(t *testing.T) {
+	input := metric.New("device",
+		map[string]string{
+			"path":     "/var/log/syslog",
+			"id":       "42",
+			"register": "0x1F",
+		},
+		map[string]interface{}{
+			"serial":      "AB12345678",
+			"temperature": 21.456,
+			"count":       int64(255),
+			"label":       "pump",
+			"status":      "ok",
+		},
+		time.Unix(0, 0),
+	)
+
+	tests := []struct {
+		name     string
+		plugin   *Strings
+		expected telegraf.Metric
+	}{
+		{
+			name: "split last element",
+			plugin: &Strings{
+				Split: []converter{{Tag: "path", Dest: "file", Delimiter: "/", Index: -1}},
+			},
+			expected: metric.New("device",
+				map[string]string{"file": "syslog"},
+				map[string]interface{}{},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "split index out of range",
+			plugin: &Strings{
+				Split: []converter{{Tag: "path", Dest: "file", Delimiter: "/", Index: 10}},
+			},
+			expected: metric.New("device",
+				map[string]string{"file": "/var/log/syslog"},
+				map[string]interface{}{},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "substring",
+			plugin: &Strings{
+				Substring: []converter{
+					{Field: "serial", Dest: "model", Start: 0, Length: 2},
+					{Field: "serial", Dest: "number", Start: -4},
+				},
+			},
+			expected: metric.New("device",
+				map[string]string{},
+				map[string]interface{}{"model": "AB", "number": "5678"},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "padding",
+			plugin: &Strings{
+				PadLeft:  []converter{{Tag: "id", Width: 6, Padding: "0"}},
+				PadRight: []converter{{Field: "label", Width: 6, Padding: "."}},
+			},
+			expected: metric.New("device",
+				map[string]string{"id": "000042"},
+				map[string]interface{}{"label": "pump.."},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "format number",
+			plugin: &Strings{
+				FormatNumber: []converter{
+					{Field: "temperature", Dest: "temperature_text", Format: "%.1f °C"},
+					{Field: "count", Dest: "count_text", Format: "%05d"},
+					{Tag: "id", Dest: "id_text", Format: "%.2f"},
+					{Field: "status", Format: "%d"},
+				},
+			},
+			expected: metric.New("device",
+				map[string]string{"id_text": "42.00"},
+				map[string]interface{}{
+					"temperature_text": "21.5 °C",
+					"count_text":       "00255",
+					"status":           "ok",
+				},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "base conversion",
+			plugin: &Strings{
+				BaseConvert: []converter{
+					{Tag: "register", Dest: "register_dec"},
+					{Field: "count", Dest: "count_hex", ToBase: 16},
+					{Field: "status", Dest: "status_bin", FromBase: 10, ToBase: 2},
+				},
+			},
+			expected: metric.New("device",
+				map[string]string{"register_dec": "31"},
+				map[string]interface{}{
+					"count_hex":  "ff",
+					"status_bin": "ok",
+				},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "template",
+			plugin: &Strings{
+				Template: []converter{
+					{Dest: "description", Template: `{{.Field "label"}} #{{.Tag "id"}} ({{.Name | upper}})`},
+					{Dest: "location", DestType: "tag", Template: `{{.Tag "path" | base}}`},
+				},
+			},
+			expected: metric.New("device",
+				map[string]string{"location": "syslog"},
+				map[string]interface{}{"description": "pump #42 (DEVICE)"},
+				time.Unix(0, 0),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+
+			// Only check the changed or added tags and fields
+			m := input.Copy()
+			actual := tt.plugin.Apply(m)[0]
+			for k, v := range tt.expected.Tags() {
+				tv, found := actual.GetTag(k)
+				require.Truef(t, found, "tag %q not found", k)
+				require.Equal(t, v, tv)
+			}
+			for k, v := range tt.expected.Fields() {
+				fv, found := actual.GetField(k)
+				require.Truef(t, found, "field %q not found", k)
+				require.Equal(t, v, fv)
+			}
+		})
+	}
+}
+
+func TestStringOperationsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Strings
+		expected string
+	}{
+		{
+			name:     "split without delimiter",
+			plugin:   &Strings{Split: []converter{{Field: "x"}}},
+			expected: "'delimiter' required for split",
+		},
+		{
+			name:     "negative substring length",
+			plugin:   &Strings{Substring: []converter{{Field: "x", Length: -1}}},
+			expected: "'length' of substring cannot be negative",
+		},
+		{
+			name:     "padding without width",
+			plugin:   &Strings{PadLeft: []converter{{Field: "x"}}},
+			expected: "'width' required for padding",
+		},
+		{
+			name:     "multi-character padding",
+			plugin:   &Strings{PadRight: []converter{{Field: "x", Width: 5, Padding: "ab"}}},
+			expected: `padding "ab" must be a single character`,
+		},
+		{
+			name:     "format without directive",
+			plugin:   &Strings{FormatNumber: []converter{{Field: "x", Format: "100%%"}}},
+			expected: "missing formatting directive",
+		},
+		{
+			name:     "format with multiple directives",
+			plugin:   &Strings{FormatNumber: []converter{{Field: "x", Format: "%d %d"}}},
+			expected: "more than one formatting directive",
+		},
+		{
+			name:     "format with unsupported verb",
+			plugin:   &Strings{FormatNumber: []converter{{Field: "x", Format: "%p"}}},
+			expected: "unsupported verb 'p'",
+		},
+		{
+			name:     "invalid base",
+			plugin:   &Strings{BaseConvert: []converter{{Field: "x", ToBase: 64}}},
+			expected: "'to_base' 64 not in range [2,36]",
+		},
+		{
+			name:     "template without destination",
+			plugin:   &Strings{Template: []converter{{Template: "{{.Name}}"}}},
+			expected: "'dest' required for template",
+		},
+		{
+			name:     "invalid template",
+			plugin:   &Strings{Template: []converter{{Dest: "x", Template: "{{.Name"}}},
+			expected: "creating template failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
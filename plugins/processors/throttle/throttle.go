@@ -0,0 +1,175 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package throttle
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Throttle struct {
+	Limit      int64           `toml:"limit"`
+	Period     config.Duration `toml:"period"`
+	Action     string          `toml:"action"`
+	CountField string          `toml:"count_field"`
+	Log        telegraf.Logger `toml:"-"`
+
+	acc    telegraf.Accumulator
+	series map[uint64]*window
+	mu     sync.Mutex
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// window contains the state of a single series within the current period
+type window struct {
+	start   time.Time
+	count   int64
+	merged  int64
+	summary telegraf.Metric
+}
+
+func (*Throttle) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *Throttle) Init() error {
+	if t.Limit < 1 {
+		return fmt.Errorf("invalid limit %d", t.Limit)
+	}
+	if t.Period <= 0 {
+		return errors.New("'period' must be positive")
+	}
+	if err := choice.Check(t.Action, []string{"drop", "summarize"}); err != nil {
+		return fmt.Errorf("config option action: %w", err)
+	}
+	if t.Action == "summarize" && t.CountField == "" {
+		return errors.New("'count_field' required for action \"summarize\"")
+	}
+
+	return nil
+}
+
+func (t *Throttle) Start(acc telegraf.Accumulator) error {
+	t.acc = acc
+	t.series = make(map[uint64]*window)
+	t.done = make(chan struct{})
+
+	// Periodically close expired windows to emit the summaries and to free
+	// the state of series that are not reporting anymore
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(t.Period))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-t.done:
+				return
+			case <-ticker.C:
+				t.expire(time.Now())
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (t *Throttle) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	now := time.Now()
+	id := m.HashID()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w, found := t.series[id]
+	if !found || now.Sub(w.start) >= time.Duration(t.Period) {
+		if found {
+			t.flush(w)
+		}
+		w = &window{start: now}
+		t.series[id] = w
+	}
+
+	w.count++
+	if w.count <= t.Limit {
+		acc.AddMetric(m)
+		return nil
+	}
+
+	if t.Action == "drop" {
+		m.Drop()
+		return nil
+	}
+
+	// Merge the excess metric into the summary keeping the latest values
+	w.merged++
+	if w.summary == nil {
+		w.summary = m
+	} else {
+		for _, field := range m.FieldList() {
+			w.summary.AddField(field.Key, field.Value)
+		}
+		w.summary.SetTime(m.Time())
+		m.Drop()
+	}
+	w.summary.AddField(t.CountField, w.merged)
+
+	return nil
+}
+
+func (t *Throttle) Stop() {
+	close(t.done)
+	t.wg.Wait()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, w := range t.series {
+		t.flush(w)
+	}
+	t.series = make(map[uint64]*window)
+}
+
+func (t *Throttle) expire(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id, w := range t.series {
+		if now.Sub(w.start) >= time.Duration(t.Period) {
+			t.flush(w)
+			delete(t.series, id)
+		}
+	}
+}
+
+// flush emits the summary of the given window if any
+func (t *Throttle) flush(w *window) {
+	if w.summary == nil {
+		return
+	}
+	t.acc.AddMetric(w.summary)
+	w.summary = nil
+}
+
+func init() {
+	processors.AddStreaming("throttle", func() telegraf.StreamingProcessor {
+		return &Throttle{
+			Limit:      10,
+			Period:     config.Duration(time.Second),
+			Action:     "drop",
+			CountField: "throttled",
+		}
+	})
+}
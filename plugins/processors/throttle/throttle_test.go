@@ -0,0 +1,182 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*Throttle)
+		expected string
+	}{
+		{
+			name:     "invalid limit",
+			modify:   func(p *Throttle) { p.Limit = 0 },
+			expected: "invalid limit 0",
+		},
+		{
+			name:     "invalid period",
+			modify:   func(p *Throttle) { p.Period = 0 },
+			expected: "'period' must be positive",
+		},
+		{
+			name:     "invalid action",
+			modify:   func(p *Throttle) { p.Action = "foo" },
+			expected: "config option action",
+		},
+		{
+			name: "missing count field",
+			modify: func(p *Throttle) {
+				p.Action = "summarize"
+				p.CountField = ""
+			},
+			expected: "'count_field' required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := processors.Processors["throttle"]().(*Throttle)
+			plugin.Log = &testutil.Logger{}
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestDrop(t *testing.T) {
+	plugin := processors.Processors["throttle"]().(*Throttle)
+	plugin.Limit = 2
+	plugin.Period = config.Duration(time.Hour)
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 1}, now),
+		metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 2}, now),
+		metric.New("test", map[string]string{"id": "b"}, map[string]interface{}{"value": 3}, now),
+		metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 4}, now),
+		metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 5}, now),
+	}
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 1}, now),
+		metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 2}, now),
+		metric.New("test", map[string]string{"id": "b"}, map[string]interface{}{"value": 3}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestSummarize(t *testing.T) {
+	plugin := processors.Processors["throttle"]().(*Throttle)
+	plugin.Limit = 1
+	plugin.Period = config.Duration(time.Hour)
+	plugin.Action = "summarize"
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 1}, now),
+		metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 2}, now.Add(time.Millisecond)),
+		metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 3, "extra": true}, now.Add(2*time.Millisecond)),
+		metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 4}, now.Add(3*time.Millisecond)),
+	}
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+
+	// The summary must only be emitted at the end of the window
+	require.Equal(t, uint64(1), acc.NMetrics())
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{"id": "a"}, map[string]interface{}{"value": 1}, now),
+		metric.New(
+			"test",
+			map[string]string{"id": "a"},
+			map[string]interface{}{"value": 4, "extra": true, "throttled": int64(3)},
+			now.Add(3*time.Millisecond),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestWindowExpiry(t *testing.T) {
+	plugin := processors.Processors["throttle"]().(*Throttle)
+	plugin.Limit = 1
+	plugin.Period = config.Duration(50 * time.Millisecond)
+	plugin.Action = "summarize"
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	now := time.Now()
+	require.NoError(t, plugin.Add(metric.New("test", nil, map[string]interface{}{"value": 1}, now), &acc))
+	require.NoError(t, plugin.Add(metric.New("test", nil, map[string]interface{}{"value": 2}, now), &acc))
+
+	// Wait for the window to be closed and the summary to be emitted
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 2
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// A new window must pass the metric again
+	require.NoError(t, plugin.Add(metric.New("test", nil, map[string]interface{}{"value": 3}, now), &acc))
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, now),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 2, "throttled": int64(1)}, now),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 3}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestTracking(t *testing.T) {
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) { delivered++ }
+
+	plugin := processors.Processors["throttle"]().(*Throttle)
+	plugin.Limit = 1
+	plugin.Period = config.Duration(time.Hour)
+	plugin.Log = &testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	now := time.Now()
+	for i := range 3 {
+		m := metric.New("test", nil, map[string]interface{}{"value": i}, now)
+		tm, _ := metric.WithTracking(m, notify)
+		require.NoError(t, plugin.Add(tm, &acc))
+	}
+	plugin.Stop()
+
+	// Dropped metrics must be marked as delivered
+	require.Equal(t, 2, delivered)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
@@ -0,0 +1,117 @@
+package selfstat
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// HistogramBuckets are the upper bounds of the buckets used by all latency
+// histograms. The bounds cover the range of short serialization runs up to
+// slow gathering cycles.
+var HistogramBuckets = []time.Duration{
+	10 * time.Microsecond,
+	50 * time.Microsecond,
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+}
+
+// Histogram records the distribution of durations in a fixed set of buckets.
+// In contrast to timing stats, histograms are cumulative and are never reset.
+type Histogram struct {
+	measurement string
+	field       string
+	tags        map[string]string
+	bounds      []time.Duration
+
+	// counts holds the non-cumulative number of observations per bucket with
+	// the last element counting the observations above the largest bound
+	counts []int64
+	count  int64
+	sum    time.Duration
+	mu     sync.Mutex
+}
+
+// Observe adds the given durations to the histogram, negative durations are
+// recorded as zero
+func (h *Histogram) Observe(durations ...time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, d := range durations {
+		d = max(d, 0)
+		i := 0
+		for i < len(h.bounds) && d > h.bounds[i] {
+			i++
+		}
+		h.counts[i]++
+		h.count++
+		h.sum += d
+	}
+}
+
+// Name is the name of the measurement
+func (h *Histogram) Name() string {
+	return h.measurement
+}
+
+// FieldName is the base name of the histogram fields
+func (h *Histogram) FieldName() string {
+	return h.field
+}
+
+// metrics returns the histogram in the format used by Prometheus histograms,
+// i.e. a metric per bucket with an 'le' tag and a '<field>_bucket' field
+// containing the cumulative count, and a metric with the '<field>_sum' and
+// '<field>_count' fields. All durations are in seconds.
+func (h *Histogram) metrics(now time.Time) []telegraf.Metric {
+	h.mu.Lock()
+	counts := make([]int64, len(h.counts))
+	copy(counts, h.counts)
+	count, sum := h.count, h.sum
+	h.mu.Unlock()
+
+	metrics := make([]telegraf.Metric, 0, len(counts)+1)
+	var cumulative int64
+	for i, c := range counts {
+		cumulative += c
+		le := "+Inf"
+		if i < len(h.bounds) {
+			le = strconv.FormatFloat(h.bounds[i].Seconds(), 'f', -1, 64)
+		}
+		tags := h.copyTags()
+		tags["le"] = le
+		fields := map[string]interface{}{h.field + "_bucket": cumulative}
+		metrics = append(metrics, metric.New(h.measurement, tags, fields, now, telegraf.Histogram))
+	}
+
+	fields := map[string]interface{}{
+		h.field + "_sum":   sum.Seconds(),
+		h.field + "_count": count,
+	}
+	metrics = append(metrics, metric.New(h.measurement, h.copyTags(), fields, now, telegraf.Histogram))
+
+	return metrics
+}
+
+func (h *Histogram) copyTags() map[string]string {
+	m := make(map[string]string, len(h.tags)+1)
+	for k, v := range h.tags {
+		m[k] = v
+	}
+	return m
+}
@@ -75,11 +75,26 @@ func RegisterTiming(measurement, field string, tags map[string]string) Stat {
 	return registry.registerTiming("internal_"+measurement, field, tags)
 }
 
+// RegisterHistogram registers a latency histogram for the given measurement,
+// field, and tags in the selfstat registry. If given an identical measurement,
+// it will return the histogram that's already been registered.
+//
+// Histograms are not returned by Metrics() but by HistogramMetrics() as they
+// produce a series per bucket.
+func RegisterHistogram(measurement, field string, tags map[string]string) *Histogram {
+	return registry.registerHistogram("internal_"+measurement, field, tags)
+}
+
 // Unregister removes the specified statistic from the registry
 func Unregister(measurement, field string, tags map[string]string) {
 	registry.remove("internal_"+measurement, field, tags)
 }
 
+// UnregisterHistogram removes the specified histogram from the registry
+func UnregisterHistogram(measurement, field string, tags map[string]string) {
+	registry.removeHistogram("internal_"+measurement, field, tags)
+}
+
 // Metrics returns all registered stats as telegraf metrics.
 func Metrics() []telegraf.Metric {
 	registry.mu.Lock()
@@ -107,9 +122,24 @@ func Metrics() []telegraf.Metric {
 	return metrics
 }
 
+// HistogramMetrics returns all registered histograms as telegraf metrics.
+func HistogramMetrics() []telegraf.Metric {
+	registry.mu.Lock()
+	now := time.Now()
+	metrics := make([]telegraf.Metric, 0, len(registry.histograms)*(len(HistogramBuckets)+2))
+	for _, histograms := range registry.histograms {
+		for _, h := range histograms {
+			metrics = append(metrics, h.metrics(now)...)
+		}
+	}
+	registry.mu.Unlock()
+	return metrics
+}
+
 type Registry struct {
-	stats map[uint64]map[string]Stat
-	mu    sync.Mutex
+	stats      map[uint64]map[string]Stat
+	histograms map[uint64]map[string]*Histogram
+	mu         sync.Mutex
 }
 
 func (r *Registry) register(measurement, field string, tags map[string]string) Stat {
@@ -158,6 +188,34 @@ func (r *Registry) registerTiming(measurement, field string, tags map[string]str
 	return s
 }
 
+func (r *Registry) registerHistogram(measurement, field string, tags map[string]string) *Histogram {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := key(measurement, tags)
+	if h, ok := r.histograms[key][field]; ok {
+		return h
+	}
+
+	t := make(map[string]string, len(tags))
+	for k, v := range tags {
+		t[k] = v
+	}
+
+	h := &Histogram{
+		measurement: measurement,
+		field:       field,
+		tags:        t,
+		bounds:      HistogramBuckets,
+		counts:      make([]int64, len(HistogramBuckets)+1),
+	}
+	if _, ok := r.histograms[key]; !ok {
+		r.histograms[key] = make(map[string]*Histogram)
+	}
+	r.histograms[key][field] = h
+	return h
+}
+
 func (r *Registry) remove(measurement, field string, tags map[string]string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -172,6 +230,20 @@ func (r *Registry) remove(measurement, field string, tags map[string]string) {
 	}
 }
 
+func (r *Registry) removeHistogram(measurement, field string, tags map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := key(measurement, tags)
+	if _, found := r.histograms[key]; !found {
+		return
+	}
+	delete(r.histograms[key], field)
+	if len(r.histograms[key]) == 0 {
+		delete(r.histograms, key)
+	}
+}
+
 func (r *Registry) get(key uint64, field string) (Stat, bool) {
 	if _, ok := r.stats[key]; !ok {
 		return nil, false
@@ -211,6 +283,7 @@ func key(measurement string, tags map[string]string) uint64 {
 
 func init() {
 	registry = &Registry{
-		stats: make(map[uint64]map[string]Stat),
+		stats:      make(map[uint64]map[string]Stat),
+		histograms: make(map[uint64]map[string]*Histogram),
 	}
 }
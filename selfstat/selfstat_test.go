@@ -3,9 +3,12 @@ package selfstat
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -19,7 +22,8 @@ var (
 // testCleanup resets the global registry for test cleanup & unlocks the test lock
 func testCleanup() {
 	registry = &Registry{
-		stats: make(map[uint64]map[string]Stat),
+		stats:      make(map[uint64]map[string]Stat),
+		histograms: make(map[uint64]map[string]*Histogram),
 	}
 	testLock.Unlock()
 }
@@ -211,3 +215,58 @@ func TestRegisterCopy(t *testing.T) {
 	tags["new"] = "value"
 	require.NotEqual(t, tags, stat.Tags())
 }
+
+func TestRegisterHistogram(t *testing.T) {
+	testLock.Lock()
+	defer testCleanup()
+
+	buckets := HistogramBuckets
+	HistogramBuckets = []time.Duration{time.Millisecond, time.Second}
+	defer func() { HistogramBuckets = buckets }()
+
+	h := RegisterHistogram("test", "latency_seconds", map[string]string{"test": "foo"})
+	require.Same(t, h, RegisterHistogram("test", "latency_seconds", map[string]string{"test": "foo"}))
+	h.Observe(-time.Second)
+	h.Observe(0)
+	h.Observe(time.Millisecond)
+	h.Observe(749 * time.Millisecond)
+	h.Observe(2 * time.Second)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"internal_test",
+			map[string]string{"test": "foo", "le": "0.001"},
+			map[string]interface{}{"latency_seconds_bucket": int64(3)},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		metric.New(
+			"internal_test",
+			map[string]string{"test": "foo", "le": "1"},
+			map[string]interface{}{"latency_seconds_bucket": int64(4)},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		metric.New(
+			"internal_test",
+			map[string]string{"test": "foo", "le": "+Inf"},
+			map[string]interface{}{"latency_seconds_bucket": int64(5)},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		metric.New(
+			"internal_test",
+			map[string]string{"test": "foo"},
+			map[string]interface{}{
+				"latency_seconds_sum":   float64(2.75),
+				"latency_seconds_count": int64(5),
+			},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, HistogramMetrics(), testutil.IgnoreTime())
+
+	UnregisterHistogram("test", "latency_seconds", map[string]string{"test": "foo"})
+	require.Empty(t, HistogramMetrics())
+}